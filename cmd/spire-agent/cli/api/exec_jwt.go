@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+)
+
+const defaultJWTSVIDEnvVar = "JWT_SVID"
+
+func NewExecJWTCommand() cli.Command {
+	return newExecJWTCommand(common_cli.DefaultEnv, newWorkloadClient)
+}
+
+func newExecJWTCommand(env *common_cli.Env, clientMaker workloadClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, new(execJWTCommand))
+}
+
+type execJWTCommand struct {
+	audience common_cli.CommaStringsFlag
+	spiffeID string
+	envVar   string
+	flags    *flag.FlagSet
+}
+
+func (c *execJWTCommand) name() string {
+	return "exec jwt"
+}
+
+func (c *execJWTCommand) synopsis() string {
+	return "Fetches a JWT SVID from the Workload API and runs a command with it in an environment variable"
+}
+
+func (c *execJWTCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
+	if len(c.audience) == 0 {
+		return errors.New("audience must be specified")
+	}
+	if c.envVar == "" {
+		return errors.New("environment variable name must be specified")
+	}
+	args := c.flags.Args()
+	if len(args) == 0 {
+		return errors.New("command to run must be specified")
+	}
+
+	svidResp, err := fetchJWTSVID(ctx, client, c.audience, c.spiffeID)
+	if err != nil {
+		return err
+	}
+	svid, err := singleJWTSVID(svidResp)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) // nolint: gosec // running the given command is the purpose of this command
+	cmd.Env = append(os.Environ(), c.envVar+"="+svid.Svid)
+	cmd.Stdin = env.Stdin
+	cmd.Stdout = env.Stdout
+	cmd.Stderr = env.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %q: %w", args[0], err)
+	}
+	return nil
+}
+
+func (c *execJWTCommand) appendFlags(fs *flag.FlagSet) {
+	fs.Var(&c.audience, "audience", "comma separated list of audience values")
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID subject (optional)")
+	fs.StringVar(&c.envVar, "envVar", defaultJWTSVIDEnvVar, "Environment variable the JWT-SVID token is passed to the command in")
+	c.flags = fs
+}
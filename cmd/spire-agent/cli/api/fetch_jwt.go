@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
@@ -21,9 +22,10 @@ func newFetchJWTCommand(env *common_cli.Env, clientMaker workloadClientMaker) cl
 }
 
 type fetchJWTCommand struct {
-	audience common_cli.CommaStringsFlag
-	spiffeID string
-	printer  cliprinter.Printer
+	audience  common_cli.CommaStringsFlag
+	spiffeID  string
+	writePath string
+	printer   cliprinter.Printer
 }
 
 func (c *fetchJWTCommand) name() string {
@@ -43,11 +45,22 @@ func (c *fetchJWTCommand) run(ctx context.Context, env *common_cli.Env, client *
 	if err != nil {
 		return err
 	}
-	svidResp, err := c.fetchJWTSVID(ctx, client)
+	svidResp, err := fetchJWTSVID(ctx, client, c.audience, c.spiffeID)
 	if err != nil {
 		return err
 	}
 
+	if c.writePath != "" {
+		svid, err := singleJWTSVID(svidResp)
+		if err != nil {
+			return err
+		}
+		// The token is a bearer credential, so it is only readable by the owner
+		if err := os.WriteFile(c.writePath, []byte(svid.Svid), 0600); err != nil {
+			return fmt.Errorf("failed to write JWT-SVID: %w", err)
+		}
+	}
+
 	c.printer.MustPrintProto(svidResp, bundlesResp)
 	return nil
 }
@@ -55,19 +68,33 @@ func (c *fetchJWTCommand) run(ctx context.Context, env *common_cli.Env, client *
 func (c *fetchJWTCommand) appendFlags(fs *flag.FlagSet) {
 	fs.Var(&c.audience, "audience", "comma separated list of audience values")
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID subject (optional)")
+	fs.StringVar(&c.writePath, "write", "", "Write the JWT-SVID token to the specified file (optional)")
 
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, printPrettyResult)
 }
 
-func (c *fetchJWTCommand) fetchJWTSVID(ctx context.Context, client *workloadClient) (*workload.JWTSVIDResponse, error) {
+func fetchJWTSVID(ctx context.Context, client *workloadClient, audience []string, spiffeID string) (*workload.JWTSVIDResponse, error) {
 	ctx, cancel := client.prepareContext(ctx)
 	defer cancel()
 	return client.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{
-		Audience: c.audience,
-		SpiffeId: c.spiffeID,
+		Audience: audience,
+		SpiffeId: spiffeID,
 	})
 }
 
+// singleJWTSVID returns the only JWT-SVID in the response. Workloads entitled
+// to more than one identity have to select one with -spiffeID.
+func singleJWTSVID(resp *workload.JWTSVIDResponse) (*workload.JWTSVID, error) {
+	switch len(resp.Svids) {
+	case 0:
+		return nil, errors.New("no JWT-SVID was returned")
+	case 1:
+		return resp.Svids[0], nil
+	default:
+		return nil, fmt.Errorf("%d JWT-SVIDs were returned; use -spiffeID to select one", len(resp.Svids))
+	}
+}
+
 func (c *fetchJWTCommand) fetchJWTBundles(ctx context.Context, client *workloadClient) (*workload.JWTBundlesResponse, error) {
 	ctx, cancel := client.prepareContext(ctx)
 	defer cancel()
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+var (
+	testJWTSVID = &workload.JWTSVID{
+		SpiffeId: "spiffe://example.org/workload",
+		Svid:     "header.payload.signature",
+	}
+	otherJWTSVID = &workload.JWTSVID{
+		SpiffeId: "spiffe://example.org/other",
+		Svid:     "other.payload.signature",
+	}
+)
+
+func TestFetchJWTWrite(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		svids     []*workload.JWTSVID
+		expectReq *workload.JWTSVIDRequest
+		expectErr string
+	}{
+		{
+			name:      "single SVID",
+			args:      []string{"-audience", "aud"},
+			svids:     []*workload.JWTSVID{testJWTSVID},
+			expectReq: &workload.JWTSVIDRequest{Audience: []string{"aud"}},
+		},
+		{
+			name:      "selected SVID",
+			args:      []string{"-audience", "aud", "-spiffeID", testJWTSVID.SpiffeId},
+			svids:     []*workload.JWTSVID{testJWTSVID},
+			expectReq: &workload.JWTSVIDRequest{Audience: []string{"aud"}, SpiffeId: testJWTSVID.SpiffeId},
+		},
+		{
+			name:      "multiple SVIDs",
+			args:      []string{"-audience", "aud"},
+			svids:     []*workload.JWTSVID{testJWTSVID, otherJWTSVID},
+			expectErr: "2 JWT-SVIDs were returned; use -spiffeID to select one\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeJWTWorkloadClient{svids: tt.svids}
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd := newFetchJWTCommand(&common_cli.Env{
+				Stdin:  new(bytes.Buffer),
+				Stdout: stdout,
+				Stderr: stderr,
+			}, client.maker)
+
+			path := filepath.Join(t.TempDir(), "token")
+			rc := cmd.Run(append(tt.args, "-write", path, "-format", "json"))
+			if tt.expectErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expectErr, stderr.String())
+				require.NoFileExists(t, path)
+				return
+			}
+
+			require.Equal(t, 0, rc, stderr.String())
+			require.Equal(t, tt.expectReq.Audience, client.req.Audience)
+			require.Equal(t, tt.expectReq.SpiffeId, client.req.SpiffeId)
+			token, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, testJWTSVID.Svid, string(token))
+		})
+	}
+}
+
+func TestExecJWT(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on a POSIX shell")
+	}
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		svids     []*workload.JWTSVID
+		expectOut string
+		expectErr string
+	}{
+		{
+			name:      "default environment variable",
+			args:      []string{"-audience", "aud", "--", "sh", "-c", `printf %s "$JWT_SVID"`},
+			svids:     []*workload.JWTSVID{testJWTSVID},
+			expectOut: testJWTSVID.Svid,
+		},
+		{
+			name:      "custom environment variable",
+			args:      []string{"-audience", "aud", "-envVar", "TOKEN", "sh", "-c", `printf %s "$TOKEN"`},
+			svids:     []*workload.JWTSVID{testJWTSVID},
+			expectOut: testJWTSVID.Svid,
+		},
+		{
+			name:      "missing audience",
+			args:      []string{"sh", "-c", "true"},
+			expectErr: "audience must be specified\n",
+		},
+		{
+			name:      "missing command",
+			args:      []string{"-audience", "aud"},
+			expectErr: "command to run must be specified\n",
+		},
+		{
+			name:      "multiple SVIDs",
+			args:      []string{"-audience", "aud", "sh", "-c", "true"},
+			svids:     []*workload.JWTSVID{testJWTSVID, otherJWTSVID},
+			expectErr: "2 JWT-SVIDs were returned; use -spiffeID to select one\n",
+		},
+		{
+			name:      "command fails",
+			args:      []string{"-audience", "aud", "sh", "-c", "exit 3"},
+			svids:     []*workload.JWTSVID{testJWTSVID},
+			expectErr: "failed to run \"sh\": exit status 3\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeJWTWorkloadClient{svids: tt.svids}
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd := newExecJWTCommand(&common_cli.Env{
+				Stdin:  new(bytes.Buffer),
+				Stdout: stdout,
+				Stderr: stderr,
+			}, client.maker)
+
+			rc := cmd.Run(tt.args)
+			if tt.expectErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expectErr, stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc, stderr.String())
+			require.Equal(t, tt.expectOut, stdout.String())
+		})
+	}
+}
+
+type fakeJWTWorkloadClient struct {
+	workload.SpiffeWorkloadAPIClient
+
+	svids []*workload.JWTSVID
+	req   *workload.JWTSVIDRequest
+}
+
+func (c *fakeJWTWorkloadClient) maker(context.Context, net.Addr, time.Duration) (*workloadClient, error) {
+	return &workloadClient{SpiffeWorkloadAPIClient: c}, nil
+}
+
+func (c *fakeJWTWorkloadClient) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest, opts ...grpc.CallOption) (*workload.JWTSVIDResponse, error) {
+	c.req = req
+	return &workload.JWTSVIDResponse{Svids: c.svids}, nil
+}
+
+func (c *fakeJWTWorkloadClient) FetchJWTBundles(ctx context.Context, req *workload.JWTBundlesRequest, opts ...grpc.CallOption) (workload.SpiffeWorkloadAPI_FetchJWTBundlesClient, error) {
+	return &fakeJWTBundlesStream{}, nil
+}
+
+type fakeJWTBundlesStream struct {
+	grpc.ClientStream
+}
+
+func (s *fakeJWTBundlesStream) Recv() (*workload.JWTBundlesResponse, error) {
+	return &workload.JWTBundlesResponse{}, nil
+}
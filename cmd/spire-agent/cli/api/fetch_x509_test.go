@@ -0,0 +1,51 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchX509WriteFederatedBundlesInOrder(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/workload"))
+
+	// Enough trust domains that map iteration order is unlikely to match the
+	// sorted order by chance.
+	var federatedDomains []string
+	federatedBundles := make(map[string][]*x509.Certificate)
+	for c := 'a'; c <= 'z'; c++ {
+		trustDomain := fmt.Sprintf("spiffe://%c.org", c)
+		federatedDomains = append(federatedDomains, trustDomain)
+		// Only the raw bytes are written out, so they identify the bundle.
+		federatedBundles[trustDomain] = []*x509.Certificate{{Raw: []byte(trustDomain)}}
+	}
+
+	dir := t.TempDir()
+	cmd := &fetchX509Command{writePath: dir}
+	require.NoError(t, cmd.writeResponse([]*X509SVID{
+		{
+			SPIFFEID:         svid.ID.String(),
+			Certificates:     svid.Certificates,
+			PrivateKey:       svid.PrivateKey,
+			Bundle:           ca.X509Authorities(),
+			FederatedBundles: federatedBundles,
+		},
+	}))
+
+	for j, trustDomain := range federatedDomains {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("federated_bundle.0.%d.pem", j)))
+		require.NoError(t, err)
+		block, _ := pem.Decode(data)
+		require.NotNil(t, block)
+		require.Equal(t, trustDomain, string(block.Bytes))
+	}
+}
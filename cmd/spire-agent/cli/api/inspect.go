@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/proto/private/agent/inspect"
+)
+
+func NewInspectCommand() cli.Command {
+	return newInspectCommand(common_cli.DefaultEnv)
+}
+
+func newInspectCommand(env *common_cli.Env) *inspectCommand {
+	return &inspectCommand{
+		env:     env,
+		timeout: common_cli.DurationFlag(5 * time.Second),
+	}
+}
+
+// inspectCommand attests a workload through the agent admin API and prints
+// the selectors discovered and the identities the workload is entitled to
+type inspectCommand struct {
+	inspectCommandOS // os specific
+
+	env *common_cli.Env
+
+	pid     int
+	timeout common_cli.DurationFlag
+}
+
+func (c *inspectCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *inspectCommand) Synopsis() string {
+	return "Attests a workload process and prints the selectors discovered and the identities it is entitled to"
+}
+
+func (c *inspectCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(); err != nil {
+		_ = c.env.ErrPrintf("Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *inspectCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.IntVar(&c.pid, "pid", 0, "ID of the workload process to inspect")
+	fs.Var(&c.timeout, "timeout", "Time to wait for a response")
+	c.addOSFlags(fs)
+	return fs.Parse(args)
+}
+
+func (c *inspectCommand) run() error {
+	if c.pid <= 0 {
+		return errors.New("a PID greater than zero is required")
+	}
+
+	addr, err := c.getAddr()
+	if err != nil {
+		return err
+	}
+	target, err := util.GetTargetName(addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeout))
+	defer cancel()
+
+	conn, err := util.GRPCDialContext(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := inspect.NewInspectClient(conn).InspectWorkload(ctx, &inspect.InspectWorkloadRequest{
+		Pid: int32(c.pid),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to inspect workload: %w", err)
+	}
+
+	c.printResponse(resp)
+	return nil
+}
+
+func (c *inspectCommand) printResponse(resp *inspect.InspectWorkloadResponse) {
+	c.env.Printf("Found %d %s for PID %d\n", len(resp.Selectors), pluralize("selector", "selectors", len(resp.Selectors)), c.pid)
+	for _, s := range resp.Selectors {
+		c.env.Printf("Selector         : %s:%s\n", s.Type, s.Value)
+	}
+	c.env.Println()
+
+	if len(resp.Identities) == 0 {
+		c.env.Println("No registration entries match the selectors; the workload will not receive any SVID")
+		return
+	}
+
+	c.env.Printf("Found %d %s\n", len(resp.Identities), pluralize("identity", "identities", len(resp.Identities)))
+	for _, identity := range resp.Identities {
+		c.env.Printf("Entry ID         : %s\n", identity.EntryId)
+		c.env.Printf("SPIFFE ID        : %s\n", identity.SpiffeId)
+		c.env.Printf("Parent ID        : %s\n", identity.ParentId)
+		for _, s := range identity.Selectors {
+			c.env.Printf("Selector         : %s:%s\n", s.Type, s.Value)
+		}
+		if identity.X509SvidExpiresAt > 0 {
+			c.env.Printf("X509-SVID expiry : %s\n", time.Unix(identity.X509SvidExpiresAt, 0).UTC())
+		} else {
+			c.env.Println("X509-SVID expiry : (not cached yet)")
+		}
+		c.env.Println()
+	}
+}
+
+func pluralize(singular, plural string, n int) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"errors"
+	"flag"
+	"net"
+
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+// inspectCommandOS has posix specific implementation
+// that complements inspectCommand
+type inspectCommandOS struct {
+	adminSocketPath string
+}
+
+func (c *inspectCommandOS) addOSFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.adminSocketPath, "adminSocketPath", "", "Path to the SPIRE Agent admin API socket (as configured by admin_socket_path)")
+}
+
+func (c *inspectCommandOS) getAddr() (net.Addr, error) {
+	if c.adminSocketPath == "" {
+		return nil, errors.New("the path to the admin API socket is required")
+	}
+	return util.GetUnixAddrWithAbsPath(c.adminSocketPath)
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/test/spiretest"
+	"google.golang.org/grpc"
+)
+
+var (
+	inspectUsage = `Usage of inspect:
+  -adminSocketPath string
+    	Path to the SPIRE Agent admin API socket (as configured by admin_socket_path)
+  -pid int
+    	ID of the workload process to inspect
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	adminAddrArg     = "-adminSocketPath"
+	adminAddrMissing = "Error: the path to the admin API socket is required\n"
+)
+
+func startAdminServer(t *testing.T, registerFn func(srv *grpc.Server)) string {
+	return spiretest.StartGRPCServer(t, registerFn).String()
+}
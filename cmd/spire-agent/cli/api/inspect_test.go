@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/agent/inspect"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type inspectTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	cmd *inspectCommand
+}
+
+func setupInspectTest() *inspectTest {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	return &inspectTest{
+		stdout: stdout,
+		stderr: stderr,
+		cmd: newInspectCommand(&common_cli.Env{
+			Stdin:  new(bytes.Buffer),
+			Stdout: stdout,
+			Stderr: stderr,
+		}),
+	}
+}
+
+func TestInspectSynopsis(t *testing.T) {
+	test := setupInspectTest()
+	require.Equal(t, "Attests a workload process and prints the selectors discovered and the identities it is entitled to", test.cmd.Synopsis())
+}
+
+func TestInspectHelp(t *testing.T) {
+	test := setupInspectTest()
+
+	require.Empty(t, test.cmd.Help())
+	require.Equal(t, inspectUsage, test.stderr.String())
+}
+
+func TestInspect(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		resp      *inspect.InspectWorkloadResponse
+		err       error
+		expectReq *inspect.InspectWorkloadRequest
+		expectOut string
+		expectErr string
+	}{
+		{
+			name:      "missing pid",
+			expectErr: "Error: a PID greater than zero is required\n",
+		},
+		{
+			name:      "missing admin address",
+			args:      []string{"-pid", "1000"},
+			expectErr: adminAddrMissing,
+		},
+		{
+			name:      "server error",
+			args:      []string{"-pid", "1000"},
+			err:       status.Error(codes.Internal, "oh no"),
+			expectErr: "Error: failed to inspect workload: rpc error: code = Internal desc = oh no\n",
+		},
+		{
+			name: "no identities",
+			args: []string{"-pid", "1000"},
+			resp: &inspect.InspectWorkloadResponse{
+				Selectors: []*inspect.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			expectReq: &inspect.InspectWorkloadRequest{Pid: 1000},
+			expectOut: `Found 1 selector for PID 1000
+Selector         : unix:uid:1000
+
+No registration entries match the selectors; the workload will not receive any SVID
+`,
+		},
+		{
+			name: "identities",
+			args: []string{"-pid", "1000"},
+			resp: &inspect.InspectWorkloadResponse{
+				Selectors: []*inspect.Selector{
+					{Type: "unix", Value: "uid:1000"},
+					{Type: "unix", Value: "user:alice"},
+				},
+				Identities: []*inspect.Identity{
+					{
+						EntryId:           "entry-1",
+						SpiffeId:          "spiffe://example.org/workload",
+						ParentId:          "spiffe://example.org/agent",
+						Selectors:         []*inspect.Selector{{Type: "unix", Value: "uid:1000"}},
+						X509SvidExpiresAt: expiresAt.Unix(),
+					},
+					{
+						EntryId:   "entry-2",
+						SpiffeId:  "spiffe://example.org/other",
+						ParentId:  "spiffe://example.org/agent",
+						Selectors: []*inspect.Selector{{Type: "unix", Value: "user:alice"}},
+					},
+				},
+			},
+			expectReq: &inspect.InspectWorkloadRequest{Pid: 1000},
+			expectOut: `Found 2 selectors for PID 1000
+Selector         : unix:uid:1000
+Selector         : unix:user:alice
+
+Found 2 identities
+Entry ID         : entry-1
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/agent
+Selector         : unix:uid:1000
+X509-SVID expiry : 2030-01-02 03:04:05 +0000 UTC
+
+Entry ID         : entry-2
+SPIFFE ID        : spiffe://example.org/other
+Parent ID        : spiffe://example.org/agent
+Selector         : unix:user:alice
+X509-SVID expiry : (not cached yet)
+
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupInspectTest()
+
+			server := &fakeInspectServer{resp: tt.resp, err: tt.err}
+			args := tt.args
+			if tt.expectErr != adminAddrMissing {
+				addr := startAdminServer(t, func(srv *grpc.Server) {
+					inspect.RegisterInspectServer(srv, server)
+				})
+				args = append(args, adminAddrArg, addr)
+			}
+
+			code := test.cmd.Run(args)
+			if tt.expectErr != "" {
+				require.Equal(t, 1, code)
+				require.Equal(t, tt.expectErr, test.stderr.String())
+				return
+			}
+			require.Equal(t, 0, code)
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, tt.expectOut, test.stdout.String())
+			spiretest.AssertProtoEqual(t, tt.expectReq, server.req)
+		})
+	}
+}
+
+type fakeInspectServer struct {
+	inspect.UnimplementedInspectServer
+
+	resp *inspect.InspectWorkloadResponse
+	err  error
+	req  *inspect.InspectWorkloadRequest
+}
+
+func (s *fakeInspectServer) InspectWorkload(_ context.Context, req *inspect.InspectWorkloadRequest) (*inspect.InspectWorkloadResponse, error) {
+	s.req = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.resp, nil
+}
@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"errors"
+	"flag"
+	"net"
+
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+// inspectCommandOS has windows specific implementation
+// that complements inspectCommand
+type inspectCommandOS struct {
+	adminNamedPipeName string
+}
+
+func (c *inspectCommandOS) addOSFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.adminNamedPipeName, "adminNamedPipeName", "", "Pipe name of the SPIRE Agent admin API named pipe (as configured by admin_named_pipe_name)")
+}
+
+func (c *inspectCommandOS) getAddr() (net.Addr, error) {
+	if c.adminNamedPipeName == "" {
+		return nil, errors.New("the name of the admin API named pipe is required")
+	}
+	return util.GetNamedPipeAddr(c.adminNamedPipeName), nil
+}
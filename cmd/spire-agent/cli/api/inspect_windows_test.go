@@ -0,0 +1,29 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/test/spiretest"
+	"google.golang.org/grpc"
+)
+
+var (
+	inspectUsage = `Usage of inspect:
+  -adminNamedPipeName string
+    	Pipe name of the SPIRE Agent admin API named pipe (as configured by admin_named_pipe_name)
+  -pid int
+    	ID of the workload process to inspect
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	adminAddrArg     = "-adminNamedPipeName"
+	adminAddrMissing = "Error: the name of the admin API named pipe is required\n"
+)
+
+func startAdminServer(t *testing.T, registerFn func(srv *grpc.Server)) string {
+	return util.GetPipeName(spiretest.StartGRPCServer(t, registerFn).String())
+}
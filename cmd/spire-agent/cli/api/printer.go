@@ -2,10 +2,28 @@ package api
 
 import (
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
 	"time"
 )
 
+// x509SVIDUpdateJSON is the JSON representation of an X509-SVID update
+// received from the Workload API.
+type x509SVIDUpdateJSON struct {
+	ReceivedAt time.Time      `json:"received_at"`
+	SVIDs      []x509SVIDJSON `json:"svids"`
+}
+
+type x509SVIDJSON struct {
+	SPIFFEID      string    `json:"spiffe_id"`
+	SerialNumber  string    `json:"serial_number"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+	FederatesWith []string  `json:"federates_with,omitempty"`
+}
+
 func printX509SVIDResponse(svids []*X509SVID, respTime time.Duration) {
 	lenMsg := fmt.Sprintf("Received %d svid", len(svids))
 	if len(svids) != 1 {
@@ -51,3 +69,27 @@ func printX509FederatedBundle(trustDomain string, bundle []*x509.Certificate) {
 		fmt.Printf("[%s] CA #%v Valid Until:\t%v\n", trustDomain, num, ca.NotAfter)
 	}
 }
+
+// printX509SVIDResponseJSON prints the SVIDs as a single line of JSON so the
+// output can be consumed by scripts.
+func printX509SVIDResponseJSON(svids []*X509SVID, receivedAt time.Time) error {
+	update := x509SVIDUpdateJSON{
+		ReceivedAt: receivedAt.UTC(),
+		SVIDs:      make([]x509SVIDJSON, 0, len(svids)),
+	}
+	for _, svid := range svids {
+		leaf := svid.Certificates[0]
+		s := x509SVIDJSON{
+			SPIFFEID:     svid.SPIFFEID,
+			SerialNumber: leaf.SerialNumber.String(),
+			NotBefore:    leaf.NotBefore.UTC(),
+			NotAfter:     leaf.NotAfter.UTC(),
+		}
+		for trustDomain := range svid.FederatedBundles {
+			s.FederatesWith = append(s.FederatesWith, trustDomain)
+		}
+		sort.Strings(s.FederatesWith)
+		update.SVIDs = append(update.SVIDs, s)
+	}
+	return json.NewEncoder(os.Stdout).Encode(update)
+}
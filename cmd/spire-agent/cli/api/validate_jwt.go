@@ -2,16 +2,21 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/square/go-jose.v2/jwt"
 )
 
 func NewValidateJWTCommand() cli.Command {
@@ -23,8 +28,9 @@ func newValidateJWTCommand(env *common_cli.Env, clientMaker workloadClientMaker)
 }
 
 type validateJWTCommand struct {
-	audience string
-	svid     string
+	audience   string
+	svid       string
+	bundlePath string
 }
 
 func (*validateJWTCommand) name() string {
@@ -38,6 +44,7 @@ func (*validateJWTCommand) synopsis() string {
 func (c *validateJWTCommand) appendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.audience, "audience", "", "expected audience value")
 	fs.StringVar(&c.svid, "svid", "", "JWT SVID")
+	fs.StringVar(&c.bundlePath, "bundlePath", "", "Path to a SPIFFE bundle or JWKS of the trust domain of the SVID to validate against, instead of the bundles cached by the agent (optional)")
 }
 
 func (c *validateJWTCommand) run(ctx context.Context, env *common_cli.Env, client *workloadClient) error {
@@ -48,7 +55,17 @@ func (c *validateJWTCommand) run(ctx context.Context, env *common_cli.Env, clien
 		return errors.New("svid must be specified")
 	}
 
-	resp, err := c.validateJWTSVID(ctx, client)
+	keyID, err := jwtSVIDKeyID(c.svid)
+	if err != nil {
+		return err
+	}
+
+	var spiffeID, claims string
+	if c.bundlePath != "" {
+		spiffeID, claims, err = c.validateJWTSVIDWithBundleFile()
+	} else {
+		spiffeID, claims, err = c.validateJWTSVIDWithAgent(ctx, client)
+	}
 	if err != nil {
 		return err
 	}
@@ -56,14 +73,53 @@ func (c *validateJWTCommand) run(ctx context.Context, env *common_cli.Env, clien
 	if err := env.Println("SVID is valid."); err != nil {
 		return err
 	}
-	if err := env.Println("SPIFFE ID :", resp.SpiffeId); err != nil {
+	if err := env.Println("SPIFFE ID :", spiffeID); err != nil {
+		return err
+	}
+	if err := env.Println("Key ID    :", keyID); err != nil {
 		return err
 	}
+	return env.Println("Claims    :", claims)
+}
+
+func (c *validateJWTCommand) validateJWTSVIDWithAgent(ctx context.Context, client *workloadClient) (string, string, error) {
+	resp, err := c.validateJWTSVID(ctx, client)
+	if err != nil {
+		return "", "", err
+	}
 	claims, err := protojson.Marshal(resp.Claims)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal claims: %w", err)
+		return "", "", fmt.Errorf("unable to unmarshal claims: %w", err)
+	}
+	return resp.SpiffeId, string(claims), nil
+}
+
+// validateJWTSVIDWithBundleFile validates the SVID against the bundle file.
+// The bundle is assumed to belong to the trust domain of the SVID subject.
+func (c *validateJWTCommand) validateJWTSVIDWithBundleFile() (string, string, error) {
+	unverified, err := jwtsvid.ParseInsecure(c.svid, []string{c.audience})
+	if err != nil {
+		return "", "", fmt.Errorf("SVID is not valid: %w", err)
+	}
+
+	bundleBytes, err := os.ReadFile(c.bundlePath)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read bundle: %w", err)
+	}
+	bundle, err := spiffebundle.Parse(unverified.ID.TrustDomain(), bundleBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse bundle: %w", err)
+	}
+
+	svid, err := jwtsvid.ParseAndValidate(c.svid, bundle, []string{c.audience})
+	if err != nil {
+		return "", "", fmt.Errorf("SVID is not valid: %w", err)
+	}
+	claims, err := json.Marshal(svid.Claims)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to marshal claims: %w", err)
 	}
-	return env.Println("Claims    :", string(claims))
+	return svid.ID.String(), string(claims), nil
 }
 
 func (c *validateJWTCommand) validateJWTSVID(ctx context.Context, client *workloadClient) (*workload.ValidateJWTSVIDResponse, error) {
@@ -81,3 +137,15 @@ func (c *validateJWTCommand) validateJWTSVID(ctx context.Context, client *worklo
 	}
 	return resp, nil
 }
+
+// jwtSVIDKeyID returns the ID of the key that signed the JWT-SVID.
+func jwtSVIDKeyID(token string) (string, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return "", fmt.Errorf("SVID is not valid: unable to parse JWT: %w", err)
+	}
+	if len(tok.Headers) != 1 {
+		return "", fmt.Errorf("SVID is not valid: expected a single token header; got %d", len(tok.Headers))
+	}
+	return tok.Headers[0].KeyID, nil
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestValidateJWT(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	id := spiffeid.RequireFromPath(td, "/workload")
+	ca := testca.New(t, td)
+	otherCA := testca.New(t, td)
+	svid := ca.CreateJWTSVID(id, []string{"aud"})
+	keyID := keyIDOf(t, ca)
+
+	dir := t.TempDir()
+	bundlePath := writeBundle(t, dir, "bundle.json", ca)
+	otherBundlePath := writeBundle(t, dir, "other.json", otherCA)
+	malformedBundlePath := filepath.Join(dir, "malformed.json")
+	require.NoError(t, os.WriteFile(malformedBundlePath, []byte("{"), 0600))
+
+	for _, tt := range []struct {
+		name         string
+		args         []string
+		validateErr  error
+		expectReq    *workload.ValidateJWTSVIDRequest
+		expectOut    []string
+		expectStderr string
+	}{
+		{
+			name:      "validated by the agent",
+			args:      []string{"-audience", "aud", "-svid", svid.Marshal()},
+			expectReq: &workload.ValidateJWTSVIDRequest{Audience: "aud", Svid: svid.Marshal()},
+			expectOut: []string{
+				"SVID is valid.\n",
+				"SPIFFE ID : spiffe://example.org/workload\n",
+				"Key ID    : " + keyID + "\n",
+				`Claims    : {"sub":"spiffe://example.org/workload"}`,
+			},
+		},
+		{
+			name:         "rejected by the agent",
+			args:         []string{"-audience", "aud", "-svid", svid.Marshal()},
+			validateErr:  status.Error(codes.InvalidArgument, "no keys found"),
+			expectStderr: "SVID is not valid: no keys found\n",
+		},
+		{
+			name: "validated with bundle file",
+			args: []string{"-audience", "aud", "-svid", svid.Marshal(), "-bundlePath", bundlePath},
+			expectOut: []string{
+				"SVID is valid.\n",
+				"SPIFFE ID : spiffe://example.org/workload\n",
+				"Key ID    : " + keyID + "\n",
+				`"sub":"spiffe://example.org/workload"`,
+			},
+		},
+		{
+			name:         "not signed by bundle file",
+			args:         []string{"-audience", "aud", "-svid", svid.Marshal(), "-bundlePath", otherBundlePath},
+			expectStderr: `SVID is not valid: jwtsvid: no JWT authority "` + keyID + `" found for trust domain "example.org"` + "\n",
+		},
+		{
+			name:         "audience mismatch with bundle file",
+			args:         []string{"-audience", "other", "-svid", svid.Marshal(), "-bundlePath", bundlePath},
+			expectStderr: "SVID is not valid: jwtsvid: expected audience in [\"other\"] (audience=[\"aud\"])\n",
+		},
+		{
+			name:         "malformed bundle file",
+			args:         []string{"-audience", "aud", "-svid", svid.Marshal(), "-bundlePath", malformedBundlePath},
+			expectStderr: "unable to parse bundle: spiffebundle: unable to parse JWKS: unexpected end of JSON input\n",
+		},
+		{
+			name:         "malformed SVID",
+			args:         []string{"-audience", "aud", "-svid", "not-a-jwt"},
+			expectStderr: "SVID is not valid: unable to parse JWT: square/go-jose: compact JWS format must have three parts\n",
+		},
+		{
+			name:         "missing audience",
+			args:         []string{"-svid", svid.Marshal()},
+			expectStderr: "audience must be specified\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeValidateWorkloadClient{err: tt.validateErr}
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd := newValidateJWTCommand(&common_cli.Env{
+				Stdin:  new(bytes.Buffer),
+				Stdout: stdout,
+				Stderr: stderr,
+			}, client.maker)
+
+			rc := cmd.Run(tt.args)
+			if tt.expectStderr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expectStderr, stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc, stderr.String())
+			for _, out := range tt.expectOut {
+				require.Contains(t, stdout.String(), out)
+			}
+			if tt.expectReq != nil {
+				require.Equal(t, tt.expectReq.Audience, client.req.Audience)
+				require.Equal(t, tt.expectReq.Svid, client.req.Svid)
+			} else {
+				require.Nil(t, client.req, "agent should not have been called")
+			}
+		})
+	}
+}
+
+func keyIDOf(t *testing.T, ca *testca.CA) string {
+	for keyID := range ca.JWTAuthorities() {
+		return keyID
+	}
+	require.FailNow(t, "CA has no JWT authorities")
+	return ""
+}
+
+func writeBundle(t *testing.T, dir, name string, ca *testca.CA) string {
+	bundleBytes, err := ca.Bundle().Marshal()
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, bundleBytes, 0600))
+	return path
+}
+
+type fakeValidateWorkloadClient struct {
+	workload.SpiffeWorkloadAPIClient
+
+	err error
+	req *workload.ValidateJWTSVIDRequest
+}
+
+func (c *fakeValidateWorkloadClient) maker(context.Context, net.Addr, time.Duration) (*workloadClient, error) {
+	return &workloadClient{SpiffeWorkloadAPIClient: c}, nil
+}
+
+func (c *fakeValidateWorkloadClient) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWTSVIDRequest, opts ...grpc.CallOption) (*workload.ValidateJWTSVIDResponse, error) {
+	c.req = req
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &workload.ValidateJWTSVIDResponse{
+		SpiffeId: "spiffe://example.org/workload",
+		Claims: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"sub": structpb.NewStringValue("spiffe://example.org/workload"),
+		}},
+	}, nil
+}
@@ -16,6 +16,7 @@ import (
 
 type WatchCLI struct {
 	config *common.ConfigOS
+	json   bool
 }
 
 func (WatchCLI) Synopsis() string {
@@ -49,7 +50,7 @@ func (w *WatchCLI) Run(args []string) int {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	if err := workloadapi.WatchX509Context(ctx, newWatcher(), clientOption); err != nil {
+	if err := workloadapi.WatchX509Context(ctx, newWatcher(w.json), clientOption); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
@@ -61,6 +62,7 @@ func (w *WatchCLI) parseConfig(args []string) error {
 	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
 	c := &common.ConfigOS{}
 	c.AddOSFlags(fs)
+	fs.BoolVar(&w.json, "json", false, "Print each update as a single line of JSON")
 
 	w.config = c
 	return fs.Parse(args)
@@ -68,11 +70,13 @@ func (w *WatchCLI) parseConfig(args []string) error {
 
 type watcher struct {
 	updateTime time.Time
+	json       bool
 }
 
-func newWatcher() *watcher {
+func newWatcher(json bool) *watcher {
 	return &watcher{
 		updateTime: time.Now(),
+		json:       json,
 	}
 }
 
@@ -98,7 +102,13 @@ func (w *watcher) OnX509ContextUpdate(x509Context *workloadapi.X509Context) {
 			FederatedBundles: federatedBundles,
 		})
 	}
-	printX509SVIDResponse(svids, time.Since(w.updateTime))
+	if w.json {
+		if err := printX509SVIDResponseJSON(svids, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	} else {
+		printX509SVIDResponse(svids, time.Since(w.updateTime))
+	}
 	w.updateTime = time.Now()
 }
 
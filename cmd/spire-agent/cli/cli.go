@@ -7,6 +7,7 @@ import (
 	"github.com/spiffe/spire/cmd/spire-agent/cli/api"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/sidecar"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/validate"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/version"
@@ -30,6 +31,12 @@ func (cc *CLI) Run(args []string) int {
 		"api fetch jwt": func() (cli.Command, error) {
 			return api.NewFetchJWTCommand(), nil
 		},
+		"api exec jwt": func() (cli.Command, error) {
+			return api.NewExecJWTCommand(), nil
+		},
+		"api inspect": func() (cli.Command, error) {
+			return api.NewInspectCommand(), nil
+		},
 		"api validate jwt": func() (cli.Command, error) {
 			return api.NewValidateJWTCommand(), nil
 		},
@@ -42,6 +49,9 @@ func (cc *CLI) Run(args []string) int {
 		"healthcheck": func() (cli.Command, error) {
 			return healthcheck.NewHealthCheckCommand(), nil
 		},
+		"sidecar": func() (cli.Command, error) {
+			return sidecar.NewSidecarCommand(), nil
+		},
 		"validate": func() (cli.Command, error) {
 			return validate.NewValidateCommand(), nil
 		},
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/mitchellh/cli"
+	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/util"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -29,6 +30,17 @@ type healthCheckCommand struct {
 
 	shallow bool
 	verbose bool
+	ready   bool
+}
+
+// degradedError is returned when the agent is serving the Workload API but
+// is not ready (e.g. it has not synchronized with the server recently).
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string {
+	return e.err.Error()
 }
 
 func (c *healthCheckCommand) Help() string {
@@ -46,8 +58,13 @@ func (c *healthCheckCommand) Run(args []string) int {
 		return 1
 	}
 	if err := c.run(); err != nil {
-		// Ignore error since a failure to write to stderr cannot very well be
+		// Ignore errors since a failure to write to stderr cannot very well be
 		// reported
+		var degradedErr degradedError
+		if errors.As(err, &degradedErr) {
+			_ = c.env.ErrPrintf("Agent is running but degraded: %v\n", err)
+			return 2
+		}
 		_ = c.env.ErrPrintf("Agent is unhealthy: %v\n", err)
 		return 1
 	}
@@ -62,6 +79,7 @@ func (c *healthCheckCommand) parseFlags(args []string) error {
 	fs.SetOutput(c.env.Stderr)
 	fs.BoolVar(&c.shallow, "shallow", false, "Perform a less stringent health check")
 	fs.BoolVar(&c.verbose, "verbose", false, "Print verbose information")
+	fs.BoolVar(&c.ready, "ready", false, "Also check that the agent is ready (i.e. recently synchronized with the server)")
 	c.addOSFlags(fs)
 	return fs.Parse(args)
 }
@@ -86,7 +104,26 @@ func (c *healthCheckCommand) run() error {
 	defer conn.Close()
 
 	healthClient := grpc_health_v1.NewHealthClient(conn)
-	resp, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err := c.check(healthClient, ""); err != nil {
+		return err
+	}
+
+	if c.ready {
+		if c.verbose {
+			c.env.Printf("Checking agent readiness...\n")
+		}
+		if err := c.check(healthClient, healthv1.ReadyService); err != nil {
+			return degradedError{err: err}
+		}
+	}
+
+	return nil
+}
+
+func (c *healthCheckCommand) check(healthClient grpc_health_v1.HealthClient, service string) error {
+	resp, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{
+		Service: service,
+	})
 	if err != nil {
 		if c.verbose {
 			// Ignore error since a failure to write to stderr cannot very well
@@ -12,6 +12,8 @@ import (
 
 var (
 	usage = `Usage of health:
+  -ready
+    	Also check that the agent is ready (i.e. recently synchronized with the server)
   -shallow
     	Perform a less stringent health check
   -socketPath string
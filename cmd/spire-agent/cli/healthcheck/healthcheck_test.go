@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/mitchellh/cli"
+	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -116,20 +117,72 @@ func TestFailsIfServiceStatusOther(t *testing.T) {
 `, test.stderr.String(), "stderr")
 }
 
+func TestSucceedsIfReady(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-ready", "-verbose"})
+	require.Equal(t, 0, code, "exit code")
+	require.Equal(t, `Checking agent health...
+Checking agent readiness...
+Agent is healthy.
+`, test.stdout.String(), "stdout")
+	require.Empty(t, test.stderr.String(), "stderr")
+}
+
+func TestFailsIfNotReady(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, healthServer{
+			status: grpc_health_v1.HealthCheckResponse_SERVING,
+			serviceStatus: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				healthv1.ReadyService: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+			},
+		})
+	})
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-ready"})
+	require.Equal(t, 2, code, "exit code")
+	require.Empty(t, test.stdout.String(), "stdout")
+	require.Equal(t, `Agent is running but degraded: agent returned status "NOT_SERVING"
+`, test.stderr.String(), "stderr")
+}
+
+func TestReadyFailsIfNotLive(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+	})
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-ready"})
+	require.Equal(t, 1, code, "exit code")
+	require.Empty(t, test.stdout.String(), "stdout")
+	require.Equal(t, `Agent is unhealthy: agent returned status "NOT_SERVING"
+`, test.stderr.String(), "stderr")
+}
+
 func withStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) healthServer {
 	return healthServer{status: status}
 }
 
 type healthServer struct {
 	grpc_health_v1.UnimplementedHealthServer
-	status grpc_health_v1.HealthCheckResponse_ServingStatus
-	err    error
+	status        grpc_health_v1.HealthCheckResponse_ServingStatus
+	serviceStatus map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+	err           error
 }
 
-func (s healthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+func (s healthServer) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
+	if status, ok := s.serviceStatus[req.Service]; ok {
+		return &grpc_health_v1.HealthCheckResponse{
+			Status: status,
+		}, nil
+	}
 	return &grpc_health_v1.HealthCheckResponse{
 		Status: s.status,
 	}, nil
@@ -15,6 +15,8 @@ var (
 	usage = `Usage of health:
   -namedPipeName string
     	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
+  -ready
+    	Also check that the agent is ready (i.e. recently synchronized with the server)
   -shallow
     	Perform a less stringent health check
   -verbose
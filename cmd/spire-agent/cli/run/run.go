@@ -2,6 +2,7 @@ package run
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"flag"
@@ -11,11 +12,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -23,15 +22,23 @@ import (
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/cli"
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/fips"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/common/x509util"
 )
 
 const (
@@ -65,18 +72,46 @@ type agentConfig struct {
 	LogFile                       string    `hcl:"log_file"`
 	LogFormat                     string    `hcl:"log_format"`
 	LogLevel                      string    `hcl:"log_level"`
+	LogSourceLocation             bool      `hcl:"log_source_location"`
+	RequirePluginChecksums        bool      `hcl:"require_plugin_checksums"`
+	RequiredWorkloadAttestors     []string  `hcl:"required_workload_attestors"`
 	SDS                           sdsConfig `hcl:"sds"`
 	ServerAddress                 string    `hcl:"server_address"`
+	ServerAddresses               []string  `hcl:"server_addresses"`
 	ServerPort                    int       `hcl:"server_port"`
+	ServerSRVName                 string    `hcl:"server_srv_name"`
 	SocketPath                    string    `hcl:"socket_path"`
 	TrustBundlePath               string    `hcl:"trust_bundle_path"`
 	TrustBundleURL                string    `hcl:"trust_bundle_url"`
 	TrustDomain                   string    `hcl:"trust_domain"`
+	UDSGroup                      string    `hcl:"uds_group"`
+	UDSMode                       string    `hcl:"uds_mode"`
+	WorkloadX509SVIDKeyType       string    `hcl:"workload_x509_svid_key_type"`
+	X509PoPTLSCertificatePath     string    `hcl:"x509pop_tls_certificate_path"`
+	X509PoPTLSPrivateKeyPath      string    `hcl:"x509pop_tls_private_key_path"`
+	BootstrapSVIDPath             string    `hcl:"bootstrap_svid_path"`
+	BootstrapKeyPath              string    `hcl:"bootstrap_key_path"`
 	AllowUnauthenticatedVerifiers bool      `hcl:"allow_unauthenticated_verifiers"`
 	AllowedForeignJWTClaims       []string  `hcl:"allowed_foreign_jwt_claims"`
+	AllowedForeignJWTTrustDomains []string  `hcl:"allowed_foreign_jwt_trust_domains"`
+	AllowedJWTAudiences           []string  `hcl:"allowed_jwt_audiences"`
+	AllowedJWTSVIDClockSkew       string    `hcl:"allowed_jwt_svid_clock_skew"`
 
 	AuthorizedDelegates []string `hcl:"authorized_delegates"`
 
+	DegradedMode degradedModeConfig `hcl:"degraded_mode"`
+
+	RotationEvents rotationEventsConfig `hcl:"rotation_events"`
+
+	GRPC grpcConfig `hcl:"grpc"`
+
+	WorkloadAPICallerPolicy callerPolicyConfig      `hcl:"workload_api_caller_policy"`
+	WorkloadAPIRateLimit    workloadRateLimitConfig `hcl:"workload_api_rate_limit"`
+
+	WorkloadX509SVIDDNSNames map[string][]string `hcl:"workload_x509_svid_dns_names"`
+
+	AdditionalSockets []additionalSocketConfig `hcl:"additional_sockets"`
+
 	ConfigPath string
 	ExpandEnv  bool
 
@@ -97,11 +132,72 @@ type sdsConfig struct {
 	DisableSPIFFECertValidation bool   `hcl:"disable_spiffe_cert_validation"`
 }
 
+type callerPolicyConfig struct {
+	AllowedCgroups []string `hcl:"allowed_cgroups"`
+	AllowedGIDs    []string `hcl:"allowed_gids"`
+	AllowedUIDs    []string `hcl:"allowed_uids"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type additionalSocketConfig struct {
+	Address                 string             `hcl:"address"`
+	Selectors               []string           `hcl:"selectors"`
+	SocketPath              string             `hcl:"socket_path"`
+	Type                    string             `hcl:"type"`
+	UDSGroup                string             `hcl:"uds_group"`
+	UDSMode                 string             `hcl:"uds_mode"`
+	WorkloadAPICallerPolicy callerPolicyConfig `hcl:"workload_api_caller_policy"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type workloadRateLimitConfig struct {
+	PerCallerLimit int `hcl:"per_caller_limit"`
+	PerCallerBurst int `hcl:"per_caller_burst"`
+	GlobalLimit    int `hcl:"global_limit"`
+	GlobalBurst    int `hcl:"global_burst"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type grpcConfig struct {
+	Compression          string `hcl:"compression"`
+	KeepaliveTime        string `hcl:"keepalive_time"`
+	KeepaliveTimeout     string `hcl:"keepalive_timeout"`
+	MaxConcurrentStreams int    `hcl:"max_concurrent_streams"`
+	MaxRecvMessageSize   int    `hcl:"max_recv_message_size"`
+	MaxSendMessageSize   int    `hcl:"max_send_message_size"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type degradedModeConfig struct {
+	GracePeriod         string `hcl:"grace_period"`
+	ServeCachedSVIDs    *bool  `hcl:"serve_cached_svids"`
+	BundleExpiryWarning string `hcl:"bundle_expiry_warning"`
+	MaxRetryInterval    string `hcl:"max_retry_interval"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type rotationEventsConfig struct {
+	SocketPath string `hcl:"socket_path"`
+	UDSGroup   string `hcl:"uds_group"`
+	UDSMode    string `hcl:"uds_mode"`
+
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
 type experimentalConfig struct {
 	SyncInterval       string `hcl:"sync_interval"`
+	LazySVIDs          bool   `hcl:"lazy_svids"`
+	PrewarmSVIDs       bool   `hcl:"prewarm_svids"`
 	NamedPipeName      string `hcl:"named_pipe_name"`
 	AdminNamedPipeName string `hcl:"admin_named_pipe_name"`
 
+	WorkloadAttestationCacheTTL string `hcl:"workload_attestation_cache_ttl"`
+
 	Flags fflag.RawConfig `hcl:"feature_flags"`
 
 	UnusedKeys []string `hcl:",unusedKeys"`
@@ -166,6 +262,46 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 	return NewAgentConfig(input, logOptions, allowUnknownConfig)
 }
 
+// LoadReloadableConfig re-reads the configuration and returns the portion of
+// it that can be applied to a running agent.
+func LoadReloadableConfig(name string, args []string, output io.Writer) (*agent.ReloadableConfig, error) {
+	cliInput, err := parseFlags(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := mergeInput(fileInput, cliInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(input); err != nil {
+		return nil, err
+	}
+
+	logLevel, err := logrus.ParseLevel(input.Agent.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := input.Agent.getAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &agent.ReloadableConfig{
+		LogLevel:      logLevel,
+		ServerAddress: serverAddress(input.Agent),
+		BindAddress:   addr,
+		PluginConfigs: *input.Plugins,
+	}, nil
+}
+
 func (cmd *Command) Run(args []string) int {
 	c, err := LoadConfig(commandName, args, cmd.logOptions, cmd.env.Stderr, cmd.allowUnknownConfig)
 	if err != nil {
@@ -183,6 +319,18 @@ func (cmd *Command) Run(args []string) int {
 		}
 	}
 
+	for _, socket := range c.AdditionalWorkloadAPISockets {
+		// Create uds dir and parents if not exists
+		socketDir := filepath.Dir(socket.Addr.String())
+		if _, statErr := os.Stat(socketDir); os.IsNotExist(statErr) {
+			c.Log.WithField("dir", socketDir).Infof("Creating spire agent UDS directory")
+			if err := os.MkdirAll(socketDir, 0755); err != nil {
+				fmt.Fprintln(cmd.env.Stderr, err)
+				return 1
+			}
+		}
+	}
+
 	// Set umask before starting up the agent
 	common_cli.SetUmask(c.Log)
 
@@ -198,19 +346,33 @@ func (cmd *Command) Run(args []string) int {
 		}
 	}
 
-	a := agent.New(c)
-
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	if c.RotationEventsAddress != nil {
+		// Create uds dir and parents if not exists
+		rotationEventsDir := filepath.Dir(c.RotationEventsAddress.String())
+		if _, statErr := os.Stat(rotationEventsDir); os.IsNotExist(statErr) {
+			c.Log.WithField("dir", rotationEventsDir).Infof("Creating rotation events UDS directory")
+			if err := os.MkdirAll(rotationEventsDir, 0755); err != nil {
+				fmt.Fprintln(cmd.env.Stderr, err)
+				return 1
+			}
+		}
+	}
 
-	err = a.Run(ctx)
-	if err != nil {
-		c.Log.WithError(err).Error("Agent crashed")
-		return 1
+	c.ReloadConfig = func() (*agent.ReloadableConfig, error) {
+		return LoadReloadableConfig(commandName, args, io.Discard)
 	}
 
-	c.Log.Info("Agent stopped gracefully")
-	return 0
+	a := agent.New(c)
+
+	return common_cli.RunService("spire-agent", func(ctx context.Context) int {
+		if err := a.Run(ctx); err != nil {
+			c.Log.WithError(err).Error("Agent crashed")
+			return 1
+		}
+
+		c.Log.Info("Agent stopped gracefully")
+		return 0
+	})
 }
 
 func (*Command) Synopsis() string {
@@ -222,14 +384,29 @@ func (c *agentConfig) validate() error {
 		return errors.New("agent section must be configured")
 	}
 
-	if c.ServerAddress == "" {
+	serverConfigs := 0
+	for _, set := range []bool{c.ServerAddress != "", len(c.ServerAddresses) > 0, c.ServerSRVName != ""} {
+		if set {
+			serverConfigs++
+		}
+	}
+	switch {
+	case serverConfigs == 0:
 		return errors.New("server_address must be configured")
+	case serverConfigs > 1:
+		return errors.New("only one of server_address, server_addresses or server_srv_name can be configured")
 	}
 
-	if c.ServerPort == 0 {
+	if c.ServerAddress != "" && c.ServerPort == 0 {
 		return errors.New("server_port must be configured")
 	}
 
+	for _, addr := range c.ServerAddresses {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid server address %q in server_addresses: %w", addr, err)
+		}
+	}
+
 	if c.TrustDomain == "" {
 		return errors.New("trust_domain must be configured")
 	}
@@ -412,16 +589,41 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		}
 	}
 
-	serverHostPort := net.JoinHostPort(c.Agent.ServerAddress, strconv.Itoa(c.Agent.ServerPort))
-	ac.ServerAddress = fmt.Sprintf("dns:///%s", serverHostPort)
+	if c.Agent.Experimental.WorkloadAttestationCacheTTL != "" {
+		var err error
+		ac.WorkloadAttestationCacheTTL, err = time.ParseDuration(c.Agent.Experimental.WorkloadAttestationCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse workload_attestation_cache_ttl: %w", err)
+		}
+		if ac.WorkloadAttestationCacheTTL < 0 {
+			return nil, errors.New("workload_attestation_cache_ttl cannot be negative")
+		}
+	}
+
+	ac.LazySVIDs = c.Agent.Experimental.LazySVIDs
+	ac.PrewarmSVIDs = c.Agent.Experimental.PrewarmSVIDs
+
+	if c.Agent.WorkloadX509SVIDKeyType != "" {
+		keyType, err := keyTypeFromString(c.Agent.WorkloadX509SVIDKeyType)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing workload_x509_svid_key_type: %w", err)
+		}
+		ac.WorkloadKeyType = keyType
+	}
+
+	ac.ServerAddress = serverAddress(c.Agent)
 
 	logOptions = append(logOptions,
 		log.WithLevel(c.Agent.LogLevel),
 		log.WithFormat(c.Agent.LogFormat),
 	)
+	if c.Agent.LogSourceLocation {
+		logOptions = append(logOptions, log.WithSourceLocation())
+	}
 	var reopenableFile *log.ReopenableFile
 	if c.Agent.LogFile != "" {
-		reopenableFile, err := log.NewReopenableFile(c.Agent.LogFile)
+		var err error
+		reopenableFile, err = log.NewReopenableFile(c.Agent.LogFile)
 		if err != nil {
 			return nil, err
 		}
@@ -437,18 +639,50 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		ac.LogReopener = log.ReopenOnSignal(logger, reopenableFile)
 	}
 
+	if err := fips.Check(); err != nil {
+		return nil, err
+	}
+	if fips.Enabled() {
+		logger.Info("FIPS mode enabled")
+	}
+
 	td, err := common_cli.ParseTrustDomain(c.Agent.TrustDomain, logger)
 	if err != nil {
 		return nil, err
 	}
 	ac.TrustDomain = td
 
+	for rawID, dnsNames := range c.Agent.WorkloadX509SVIDDNSNames {
+		id, err := idutil.MemberFromString(ac.TrustDomain, rawID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID in workload_x509_svid_dns_names: %w", err)
+		}
+		for _, dnsName := range dnsNames {
+			if err := x509util.ValidateDNS(dnsName); err != nil {
+				return nil, fmt.Errorf("invalid DNS name %q in workload_x509_svid_dns_names: %w", dnsName, err)
+			}
+		}
+		if ac.WorkloadDNSNames == nil {
+			ac.WorkloadDNSNames = make(map[spiffeid.ID][]string)
+		}
+		ac.WorkloadDNSNames[id] = dnsNames
+	}
+
 	addr, err := c.Agent.getAddr()
 	if err != nil {
 		return nil, err
 	}
 	ac.BindAddress = addr
 
+	if c.Agent.UDSMode != "" {
+		mode, err := util.ParseSocketMode(c.Agent.UDSMode)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse uds_mode: %w", err)
+		}
+		ac.BindAddressMode = mode
+	}
+	ac.BindAddressGroup = c.Agent.UDSGroup
+
 	if c.Agent.hasAdminAddr() {
 		adminAddr, err := c.Agent.getAdminAddr()
 		if err != nil {
@@ -456,7 +690,39 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		}
 		ac.AdminBindAddress = adminAddr
 	}
+
+	if c.Agent.RotationEvents.SocketPath != "" {
+		rotationEventsAddr, err := c.Agent.getRotationEventsAddr()
+		if err != nil {
+			return nil, err
+		}
+		ac.RotationEventsAddress = rotationEventsAddr
+		if c.Agent.RotationEvents.UDSMode != "" {
+			ac.RotationEventsMode, err = util.ParseSocketMode(c.Agent.RotationEvents.UDSMode)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse rotation_events uds_mode: %w", err)
+			}
+		}
+		ac.RotationEventsGroup = c.Agent.RotationEvents.UDSGroup
+	}
 	ac.JoinToken = c.Agent.JoinToken
+
+	if c.Agent.X509PoPTLSCertificatePath != "" || c.Agent.X509PoPTLSPrivateKeyPath != "" {
+		cert, err := loadX509PoPTLSCertificate(c.Agent.X509PoPTLSCertificatePath, c.Agent.X509PoPTLSPrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		ac.X509PoPTLSCertificate = cert
+	}
+
+	if c.Agent.BootstrapSVIDPath != "" || c.Agent.BootstrapKeyPath != "" {
+		svid, err := loadBootstrapSVID(c.Agent.BootstrapSVIDPath, c.Agent.BootstrapKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		ac.BootstrapSVID = svid
+	}
+
 	ac.DataDir = c.Agent.DataDir
 	ac.DefaultSVIDName = c.Agent.SDS.DefaultSVIDName
 	ac.DefaultBundleName = c.Agent.SDS.DefaultBundleName
@@ -477,8 +743,40 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	ac.ProfilingNames = c.Agent.ProfilingNames
 
 	ac.AllowedForeignJWTClaims = c.Agent.AllowedForeignJWTClaims
+	ac.AllowedJWTAudiences = c.Agent.AllowedJWTAudiences
+
+	if c.Agent.AllowedForeignJWTTrustDomains != nil {
+		ac.AllowedForeignJWTTrustDomains = make([]spiffeid.TrustDomain, 0, len(c.Agent.AllowedForeignJWTTrustDomains))
+		for _, name := range c.Agent.AllowedForeignJWTTrustDomains {
+			td, err := spiffeid.TrustDomainFromString(name)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse allowed_foreign_jwt_trust_domains %q: %w", name, err)
+			}
+			ac.AllowedForeignJWTTrustDomains = append(ac.AllowedForeignJWTTrustDomains, td)
+		}
+	}
+
+	if c.Agent.AllowedJWTSVIDClockSkew != "" {
+		var err error
+		ac.AllowedJWTSVIDClockSkew, err = time.ParseDuration(c.Agent.AllowedJWTSVIDClockSkew)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse allowed_jwt_svid_clock_skew: %w", err)
+		}
+		if ac.AllowedJWTSVIDClockSkew < 0 {
+			return nil, errors.New("allowed_jwt_svid_clock_skew cannot be negative")
+		}
+	}
 
 	ac.PluginConfigs = *c.Plugins
+
+	for _, name := range c.Agent.RequiredWorkloadAttestors {
+		pluginConfig, ok := ac.PluginConfigs["WorkloadAttestor"][name]
+		if !ok || !pluginConfig.IsEnabled() {
+			return nil, fmt.Errorf("required workload attestor %q is not configured", name)
+		}
+	}
+	ac.RequiredWorkloadAttestors = c.Agent.RequiredWorkloadAttestors
+	ac.RequirePluginChecksums = c.Agent.RequirePluginChecksums
 	ac.Telemetry = c.Telemetry
 	ac.HealthChecks = c.HealthChecks
 
@@ -498,6 +796,44 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 
 	ac.AuthorizedDelegates = c.Agent.AuthorizedDelegates
 
+	ac.WorkloadAPICallerPolicy, err = c.Agent.WorkloadAPICallerPolicy.toCallerPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	ac.AdditionalWorkloadAPISockets, err = c.Agent.getAdditionalSockets(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := c.Agent.WorkloadAPIRateLimit
+	if rateLimit.PerCallerLimit < 0 || rateLimit.PerCallerBurst < 0 || rateLimit.GlobalLimit < 0 || rateLimit.GlobalBurst < 0 {
+		return nil, errors.New("workload_api_rate_limit values cannot be negative")
+	}
+	ac.WorkloadAPIRateLimits = endpoints.RateLimitConfig{
+		PerCaller: endpoints.RateLimit{
+			Limit: rateLimit.PerCallerLimit,
+			Burst: rateLimit.PerCallerBurst,
+		},
+		Global: endpoints.RateLimit{
+			Limit: rateLimit.GlobalLimit,
+			Burst: rateLimit.GlobalBurst,
+		},
+	}
+
+	grpcOptions, maxStreams, err := parseGRPCConfig(c.Agent.GRPC)
+	if err != nil {
+		return nil, err
+	}
+	ac.ServerGRPCOptions = *grpcOptions
+	ac.WorkloadAPIMaxConcurrentStreams = maxStreams
+
+	degradedMode, err := parseDegradedModeConfig(c.Agent.DegradedMode)
+	if err != nil {
+		return nil, err
+	}
+	ac.DegradedMode = *degradedMode
+
 	if cmp.Diff(experimentalConfig{}, c.Agent.Experimental) != "" {
 		logger.Warn("Experimental features have been enabled. Please see doc/upgrading.md for upgrade and compatibility considerations for experimental features.")
 	}
@@ -509,6 +845,96 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	return ac, nil
 }
 
+// parseDegradedModeConfig returns how the agent behaves while it cannot
+// synchronize with the server.
+func parseDegradedModeConfig(c degradedModeConfig) (*manager.DegradedModeConfig, error) {
+	config := &manager.DegradedModeConfig{}
+	if c.ServeCachedSVIDs != nil {
+		config.WithholdSVIDs = !*c.ServeCachedSVIDs
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{name: "grace_period", value: c.GracePeriod, dest: &config.GracePeriod},
+		{name: "bundle_expiry_warning", value: c.BundleExpiryWarning, dest: &config.BundleExpiryWarning},
+		{name: "max_retry_interval", value: c.MaxRetryInterval, dest: &config.MaxRetryInterval},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse degraded_mode %s %q: %w", d.name, d.value, err)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("degraded_mode %s must be positive", d.name)
+		}
+		*d.dest = duration
+	}
+
+	return config, nil
+}
+
+// parseGRPCConfig returns the options for the connection to the server and
+// the maximum number of concurrent streams per Workload API connection.
+func parseGRPCConfig(c grpcConfig) (*client.GRPCOptions, uint32, error) {
+	options := &client.GRPCOptions{}
+
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{name: "keepalive_time", value: c.KeepaliveTime, dest: &options.KeepaliveTime},
+		{name: "keepalive_timeout", value: c.KeepaliveTimeout, dest: &options.KeepaliveTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not parse grpc %s %q: %w", d.name, d.value, err)
+		}
+		if duration < 0 {
+			return nil, 0, fmt.Errorf("grpc %s must not be negative", d.name)
+		}
+		*d.dest = duration
+	}
+
+	switch {
+	case c.MaxConcurrentStreams < 0:
+		return nil, 0, errors.New("grpc max_concurrent_streams must not be negative")
+	case c.MaxRecvMessageSize < 0:
+		return nil, 0, errors.New("grpc max_recv_message_size must not be negative")
+	case c.MaxSendMessageSize < 0:
+		return nil, 0, errors.New("grpc max_send_message_size must not be negative")
+	}
+	if err := client.ValidateCompression(c.Compression); err != nil {
+		return nil, 0, fmt.Errorf("invalid grpc compression: %w", err)
+	}
+	options.MaxRecvMsgSize = c.MaxRecvMessageSize
+	options.MaxSendMsgSize = c.MaxSendMessageSize
+	options.Compression = c.Compression
+
+	return options, uint32(c.MaxConcurrentStreams), nil
+}
+
+func serverAddress(c *agentConfig) string {
+	switch {
+	case len(c.ServerAddresses) > 0:
+		return client.StaticTarget(c.ServerAddresses)
+	case c.ServerSRVName != "":
+		return client.SRVTarget(c.ServerSRVName)
+	}
+	serverHostPort := net.JoinHostPort(c.ServerAddress, strconv.Itoa(c.ServerPort))
+	return fmt.Sprintf("dns:///%s", serverHostPort)
+}
+
 func validateConfig(c *Config) error {
 	if c.Plugins == nil {
 		return errors.New("plugins section must be configured")
@@ -538,6 +964,37 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		detectedUnknown("agent", a.UnusedKeys)
 	}
 
+	if a := c.Agent; a != nil && len(a.WorkloadAPICallerPolicy.UnusedKeys) != 0 {
+		detectedUnknown("workload_api_caller_policy", a.WorkloadAPICallerPolicy.UnusedKeys)
+	}
+
+	if a := c.Agent; a != nil {
+		for _, socket := range a.AdditionalSockets {
+			if len(socket.UnusedKeys) != 0 {
+				detectedUnknown("additional_sockets", socket.UnusedKeys)
+			}
+			if len(socket.WorkloadAPICallerPolicy.UnusedKeys) != 0 {
+				detectedUnknown("additional_sockets.workload_api_caller_policy", socket.WorkloadAPICallerPolicy.UnusedKeys)
+			}
+		}
+	}
+
+	if a := c.Agent; a != nil && len(a.WorkloadAPIRateLimit.UnusedKeys) != 0 {
+		detectedUnknown("workload_api_rate_limit", a.WorkloadAPIRateLimit.UnusedKeys)
+	}
+
+	if a := c.Agent; a != nil && len(a.GRPC.UnusedKeys) != 0 {
+		detectedUnknown("grpc", a.GRPC.UnusedKeys)
+	}
+
+	if a := c.Agent; a != nil && len(a.DegradedMode.UnusedKeys) != 0 {
+		detectedUnknown("degraded_mode", a.DegradedMode.UnusedKeys)
+	}
+
+	if a := c.Agent; a != nil && len(a.RotationEvents.UnusedKeys) != 0 {
+		detectedUnknown("rotation_events", a.RotationEvents.UnusedKeys)
+	}
+
 	// TODO: Re-enable unused key detection for telemetry. See
 	// https://github.com/spiffe/spire/issues/1101 for more information
 	//
@@ -609,3 +1066,70 @@ func parseTrustBundle(path string) ([]*x509.Certificate, error) {
 
 	return bundle, nil
 }
+
+func (c callerPolicyConfig) toCallerPolicy() (*endpoints.CallerPolicy, error) {
+	if len(c.AllowedUIDs) == 0 && len(c.AllowedGIDs) == 0 && len(c.AllowedCgroups) == 0 {
+		return nil, nil
+	}
+	allowedUIDs, err := endpoints.ParseIDRanges(c.AllowedUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed_uids in workload_api_caller_policy: %w", err)
+	}
+	allowedGIDs, err := endpoints.ParseIDRanges(c.AllowedGIDs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed_gids in workload_api_caller_policy: %w", err)
+	}
+	if err := endpoints.ValidateCgroupPatterns(c.AllowedCgroups); err != nil {
+		return nil, fmt.Errorf("invalid allowed_cgroups in workload_api_caller_policy: %w", err)
+	}
+	return &endpoints.CallerPolicy{
+		AllowedUIDs:    allowedUIDs,
+		AllowedGIDs:    allowedGIDs,
+		AllowedCgroups: c.AllowedCgroups,
+	}, nil
+}
+
+func loadX509PoPTLSCertificate(certPath, keyPath string) (*tls.Certificate, error) {
+	switch {
+	case certPath == "":
+		return nil, errors.New("x509pop_tls_certificate_path must be configured with x509pop_tls_private_key_path")
+	case keyPath == "":
+		return nil, errors.New("x509pop_tls_private_key_path must be configured with x509pop_tls_certificate_path")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load x509pop_tls certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+func loadBootstrapSVID(svidPath, keyPath string) (*tls.Certificate, error) {
+	switch {
+	case svidPath == "":
+		return nil, errors.New("bootstrap_svid_path must be configured with bootstrap_key_path")
+	case keyPath == "":
+		return nil, errors.New("bootstrap_key_path must be configured with bootstrap_svid_path")
+	}
+
+	svid, err := tls.LoadX509KeyPair(svidPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load bootstrap SVID: %w", err)
+	}
+	return &svid, nil
+}
+
+func keyTypeFromString(s string) (keymanager.KeyType, error) {
+	switch strings.ToLower(s) {
+	case "rsa-2048":
+		return keymanager.RSA2048, nil
+	case "rsa-4096":
+		return keymanager.RSA4096, nil
+	case "ec-p256":
+		return keymanager.ECP256, nil
+	case "ec-p384":
+		return keymanager.ECP384, nil
+	default:
+		return keymanager.KeyTypeUnset, fmt.Errorf("key type %q is unknown; must be one of [rsa-2048, rsa-4096, ec-p256, ec-p384]", s)
+	}
+}
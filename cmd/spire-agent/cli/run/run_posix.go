@@ -9,10 +9,14 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
+	selectorutil "github.com/spiffe/spire/pkg/common/selector"
 	"github.com/spiffe/spire/pkg/common/util"
+	spire_common "github.com/spiffe/spire/proto/spire/common"
 )
 
 func (c *agentConfig) addOSFlags(flags *flag.FlagSet) {
@@ -47,10 +51,169 @@ func (c *agentConfig) getAdminAddr() (net.Addr, error) {
 	}, nil
 }
 
+func (c *agentConfig) getRotationEventsAddr() (net.Addr, error) {
+	return util.GetUnixAddrWithAbsPath(c.RotationEvents.SocketPath)
+}
+
 func (c *agentConfig) hasAdminAddr() bool {
 	return c.AdminSocketPath != ""
 }
 
+func (c *agentConfig) getAdditionalSockets(addr net.Addr) ([]endpoints.Socket, error) {
+	var sockets []endpoints.Socket
+	inUse := map[string]bool{addr.String(): true}
+	for _, socketConfig := range c.AdditionalSockets {
+		var socket *endpoints.Socket
+		var err error
+		switch socketConfig.Type {
+		case "", "unix":
+			socket, err = c.getAdditionalUDS(socketConfig)
+		case "abstract":
+			socket, err = getAdditionalAbstractSocket(socketConfig)
+		case "tcp":
+			socket, err = getAdditionalTCPSocket(socketConfig)
+		default:
+			return nil, fmt.Errorf("invalid additional socket type %q: expected one of \"unix\", \"abstract\" or \"tcp\"", socketConfig.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if inUse[socket.Addr.String()] {
+			return nil, fmt.Errorf("invalid additional socket %q: socket address is already in use", socket.Addr)
+		}
+		inUse[socket.Addr.String()] = true
+
+		sockets = append(sockets, *socket)
+	}
+	return sockets, nil
+}
+
+func (c *agentConfig) getAdditionalUDS(socketConfig additionalSocketConfig) (*endpoints.Socket, error) {
+	switch {
+	case socketConfig.SocketPath == "":
+		return nil, errors.New("socket_path is required for each of the additional_sockets")
+	case socketConfig.Address != "":
+		return nil, fmt.Errorf("invalid additional socket %q: address is not supported by unix sockets; use socket_path instead", socketConfig.SocketPath)
+	case len(socketConfig.Selectors) > 0:
+		return nil, fmt.Errorf("invalid additional socket %q: selectors are only supported by tcp sockets", socketConfig.SocketPath)
+	}
+
+	socketAddr, err := util.GetUnixAddrWithAbsPath(socketConfig.SocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional socket %q: %w", socketConfig.SocketPath, err)
+	}
+
+	if c.hasAdminAddr() {
+		adminSocketPathAbs, err := filepath.Abs(c.AdminSocketPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for admin_socket_path: %w", err)
+		}
+		if strings.HasPrefix(adminSocketPathAbs, filepath.Dir(socketAddr.String())+"/") {
+			return nil, fmt.Errorf("invalid additional socket %q: admin socket cannot be in the same directory or a subdirectory as that containing the socket", socketConfig.SocketPath)
+		}
+	}
+
+	socket := &endpoints.Socket{
+		Addr:  socketAddr,
+		Group: socketConfig.UDSGroup,
+	}
+	if socketConfig.UDSMode != "" {
+		socket.Mode, err = util.ParseSocketMode(socketConfig.UDSMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional socket %q: could not parse uds_mode: %w", socketConfig.SocketPath, err)
+		}
+	}
+	socket.CallerPolicy, err = socketConfig.WorkloadAPICallerPolicy.toCallerPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional socket %q: %w", socketConfig.SocketPath, err)
+	}
+	return socket, nil
+}
+
+// getAdditionalAbstractSocket returns a UDS in the abstract namespace, which
+// has no file on disk, for sandboxes where sharing a socket file with the
+// workloads is not possible. Callers are attested like for any other UDS.
+func getAdditionalAbstractSocket(socketConfig additionalSocketConfig) (*endpoints.Socket, error) {
+	switch {
+	case runtime.GOOS != "linux":
+		return nil, errors.New("invalid additional socket: abstract sockets are only supported on Linux")
+	case socketConfig.Address == "":
+		return nil, errors.New("address is required for abstract additional_sockets")
+	case socketConfig.SocketPath != "" || socketConfig.UDSMode != "" || socketConfig.UDSGroup != "":
+		return nil, fmt.Errorf("invalid additional socket %q: socket_path, uds_mode and uds_group are not supported by abstract sockets", socketConfig.Address)
+	case len(socketConfig.Selectors) > 0:
+		return nil, fmt.Errorf("invalid additional socket %q: selectors are only supported by tcp sockets", socketConfig.Address)
+	}
+
+	callerPolicy, err := socketConfig.WorkloadAPICallerPolicy.toCallerPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional socket %q: %w", socketConfig.Address, err)
+	}
+	return &endpoints.Socket{
+		Addr: &net.UnixAddr{
+			Net:  "unix",
+			Name: "@" + strings.TrimPrefix(socketConfig.Address, "@"),
+		},
+		CallerPolicy: callerPolicy,
+	}, nil
+}
+
+// getAdditionalTCPSocket returns a loopback TCP socket. Callers connected
+// through TCP cannot be attested, so they are all given the configured
+// selectors.
+func getAdditionalTCPSocket(socketConfig additionalSocketConfig) (*endpoints.Socket, error) {
+	switch {
+	case socketConfig.Address == "":
+		return nil, errors.New("address is required for tcp additional_sockets")
+	case socketConfig.SocketPath != "" || socketConfig.UDSMode != "" || socketConfig.UDSGroup != "":
+		return nil, fmt.Errorf("invalid additional socket %q: socket_path, uds_mode and uds_group are not supported by tcp sockets", socketConfig.Address)
+	case len(socketConfig.WorkloadAPICallerPolicy.AllowedUIDs) > 0 || len(socketConfig.WorkloadAPICallerPolicy.AllowedGIDs) > 0:
+		return nil, fmt.Errorf("invalid additional socket %q: workload_api_caller_policy is not supported by tcp sockets", socketConfig.Address)
+	case len(socketConfig.Selectors) == 0:
+		return nil, fmt.Errorf("invalid additional socket %q: selectors are required for tcp sockets", socketConfig.Address)
+	}
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", socketConfig.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid additional socket %q: %w", socketConfig.Address, err)
+	}
+	if tcpAddr.IP == nil || !tcpAddr.IP.IsLoopback() {
+		return nil, fmt.Errorf("invalid additional socket %q: address must be a loopback IP address", socketConfig.Address)
+	}
+	if tcpAddr.Port == 0 {
+		return nil, fmt.Errorf("invalid additional socket %q: a port is required", socketConfig.Address)
+	}
+
+	socket := &endpoints.Socket{
+		Addr: tcpAddr,
+	}
+	for _, s := range socketConfig.Selectors {
+		selector, err := parseSelector(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional socket %q: %w", socketConfig.Address, err)
+		}
+		socket.Selectors = append(socket.Selectors, selector)
+	}
+	return socket, nil
+}
+
+// parseSelector parses a selector in the type:value format.
+func parseSelector(s string) (*spire_common.Selector, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("selector %q must be in the type:value format", s)
+	}
+	selector := &spire_common.Selector{
+		Type:  parts[0],
+		Value: parts[1],
+	}
+	if err := selectorutil.Validate(selector); err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+	}
+	return selector, nil
+}
+
 // validateOS performs posix specific validations of the agent config
 func (c *agentConfig) validateOS() error {
 	if c.Experimental.NamedPipeName != "" {
@@ -5,11 +5,16 @@ package run
 
 import (
 	"bytes"
+	"net"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/hashicorp/hcl/hcl/printer"
+	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
+	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +55,12 @@ func TestParseConfigGood(t *testing.T) {
 	assert.Equal(t, "example.org", c.Agent.TrustDomain)
 	assert.Equal(t, true, c.Agent.AllowUnauthenticatedVerifiers)
 	assert.Equal(t, []string{"c1", "c2", "c3"}, c.Agent.AllowedForeignJWTClaims)
+	require.Len(t, c.Agent.AdditionalSockets, 1)
+	assert.Equal(t, "/tmp/spire-agent/restricted/api.sock", c.Agent.AdditionalSockets[0].SocketPath)
+	assert.Equal(t, "0770", c.Agent.AdditionalSockets[0].UDSMode)
+	assert.Equal(t, "workloads", c.Agent.AdditionalSockets[0].UDSGroup)
+	assert.Equal(t, []string{"1000-1999"}, c.Agent.AdditionalSockets[0].WorkloadAPICallerPolicy.AllowedUIDs)
+	assert.Empty(t, c.Agent.AdditionalSockets[0].UnusedKeys)
 
 	// Check for plugins configurations
 	pluginConfigs := *c.Plugins
@@ -228,5 +239,313 @@ func newAgentConfigCasesOS() []newAgentConfigCase {
 				require.Nil(t, c.AdminBindAddress)
 			},
 		},
+		{
+			msg: "additional_sockets should be correctly configured",
+			input: func(c *Config) {
+				c.Agent.SocketPath = "/tmp/workload/workload.sock"
+				c.Agent.AdditionalSockets = []additionalSocketConfig{
+					{
+						SocketPath: "/tmp/restricted/workload.sock",
+						UDSMode:    "0770",
+						UDSGroup:   "workloads",
+						WorkloadAPICallerPolicy: callerPolicyConfig{
+							AllowedUIDs: []string{"1000-1999"},
+						},
+					},
+					{
+						SocketPath: "/tmp/open/workload.sock",
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []endpoints.Socket{
+					{
+						Addr:  &net.UnixAddr{Net: "unix", Name: "/tmp/restricted/workload.sock"},
+						Mode:  0770,
+						Group: "workloads",
+						CallerPolicy: &endpoints.CallerPolicy{
+							AllowedUIDs: []endpoints.IDRange{{Min: 1000, Max: 1999}},
+						},
+					},
+					{
+						Addr: &net.UnixAddr{Net: "unix", Name: "/tmp/open/workload.sock"},
+					},
+				}, c.AdditionalWorkloadAPISockets)
+			},
+		},
+		{
+			msg: "additional_sockets not provided",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.AdditionalWorkloadAPISockets)
+			},
+		},
+		{
+			msg:         "additional_sockets without socket_path",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{UDSMode: "0770"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "additional_sockets with the same path as socket_path",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketPath = "/tmp/workload/workload.sock"
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{SocketPath: "/tmp/workload/workload.sock"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "additional_sockets with the same folder as admin_socket_path",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.SocketPath = "/tmp/workload/workload.sock"
+				c.Agent.AdminSocketPath = "/tmp/admin/admin.sock"
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{SocketPath: "/tmp/admin/workload.sock"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "additional_sockets with invalid uds_mode",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{SocketPath: "/tmp/restricted/workload.sock", UDSMode: "0999"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "additional_sockets with invalid workload_api_caller_policy",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{
+					{
+						SocketPath: "/tmp/restricted/workload.sock",
+						WorkloadAPICallerPolicy: callerPolicyConfig{
+							AllowedGIDs: []string{"1999-1000"},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "abstract additional_sockets are only supported on Linux",
+			expectError: runtime.GOOS != "linux",
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{
+					{
+						Type:    "abstract",
+						Address: "spire-agent/workload",
+						WorkloadAPICallerPolicy: callerPolicyConfig{
+							AllowedUIDs: []string{"1000"},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				if runtime.GOOS != "linux" {
+					require.Nil(t, c)
+					return
+				}
+				require.Equal(t, []endpoints.Socket{
+					{
+						Addr: &net.UnixAddr{Net: "unix", Name: "@spire-agent/workload"},
+						CallerPolicy: &endpoints.CallerPolicy{
+							AllowedUIDs: []endpoints.IDRange{{Min: 1000, Max: 1000}},
+						},
+					},
+				}, c.AdditionalWorkloadAPISockets)
+			},
+		},
+		{
+			msg:         "abstract additional_sockets without address",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "abstract"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "abstract additional_sockets with uds_mode",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "abstract", Address: "spire-agent", UDSMode: "0770"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "tcp additional_sockets should be correctly configured",
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{
+					{
+						Type:      "tcp",
+						Address:   "127.0.0.1:8443",
+						Selectors: []string{"unix:uid:1000", "docker:label:app:db"},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []endpoints.Socket{
+					{
+						Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8443},
+						Selectors: []*common.Selector{
+							{Type: "unix", Value: "uid:1000"},
+							{Type: "docker", Value: "label:app:db"},
+						},
+					},
+				}, c.AdditionalWorkloadAPISockets)
+			},
+		},
+		{
+			msg:         "tcp additional_sockets with a non-loopback address",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "tcp", Address: "0.0.0.0:8443", Selectors: []string{"unix:uid:1000"}}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "tcp additional_sockets without a port",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "tcp", Address: "127.0.0.1:0", Selectors: []string{"unix:uid:1000"}}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "tcp additional_sockets without selectors",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "tcp", Address: "127.0.0.1:8443"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "tcp additional_sockets with an invalid selector",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "tcp", Address: "127.0.0.1:8443", Selectors: []string{"unix"}}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "tcp additional_sockets with workload_api_caller_policy",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{
+					{
+						Type:      "tcp",
+						Address:   "127.0.0.1:8443",
+						Selectors: []string{"unix:uid:1000"},
+						WorkloadAPICallerPolicy: callerPolicyConfig{
+							AllowedUIDs: []string{"1000"},
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "unix additional_sockets with selectors",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{SocketPath: "/tmp/restricted/workload.sock", Selectors: []string{"unix:uid:1000"}}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "additional_sockets with an unknown type",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AdditionalSockets = []additionalSocketConfig{{Type: "vsock", Address: "3:8443"}}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "rotation_events are not served by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.RotationEventsAddress)
+			},
+		},
+		{
+			msg: "rotation_events should be correctly configured",
+			input: func(c *Config) {
+				c.Agent.RotationEvents = rotationEventsConfig{
+					SocketPath: "/tmp/rotation-events/events.sock",
+					UDSMode:    "0700",
+					UDSGroup:   "loggers",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "/tmp/rotation-events/events.sock", c.RotationEventsAddress.String())
+				require.Equal(t, "unix", c.RotationEventsAddress.Network())
+				require.Equal(t, os.FileMode(0700), c.RotationEventsMode)
+				require.Equal(t, "loggers", c.RotationEventsGroup)
+			},
+		},
+		{
+			msg:         "rotation_events with invalid uds_mode",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.RotationEvents = rotationEventsConfig{
+					SocketPath: "/tmp/rotation-events/events.sock",
+					UDSMode:    "0999",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 	}
 }
+
+func TestLoadReloadableConfig(t *testing.T) {
+	rc, err := LoadReloadableConfig("run", []string{
+		"-config", "../../../../test/fixture/config/agent_good_posix.conf",
+		"-logLevel", "DEBUG",
+	}, os.Stderr)
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, rc.LogLevel)
+	assert.Equal(t, "dns:///127.0.0.1:8081", rc.ServerAddress)
+	assert.Equal(t, "/tmp/spire-agent/public/api.sock", rc.BindAddress.String())
+	assert.Len(t, rc.PluginConfigs["plugin_type_agent"], 3)
+
+	_, err = LoadReloadableConfig("run", []string{
+		"-config", "../../../../test/fixture/config/agent_good_posix.conf",
+		"-logLevel", "NOT-A-LEVEL",
+	}, os.Stderr)
+	require.Error(t, err)
+}
@@ -9,10 +9,16 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/test/spiretest"
@@ -555,6 +561,244 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Equal(t, "dns:///192.168.1.1:1337", c.ServerAddress)
 			},
 		},
+		{
+			msg: "server_addresses should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.ServerAddress = ""
+				c.Agent.ServerPort = 0
+				c.Agent.ServerAddresses = []string{"192.168.1.1:1337", "192.168.1.2:1337"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "spire-static:///192.168.1.1:1337,192.168.1.2:1337", c.ServerAddress)
+			},
+		},
+		{
+			msg:         "server_addresses without a port should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.ServerAddress = ""
+				c.Agent.ServerAddresses = []string{"192.168.1.1"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "server_srv_name should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.ServerAddress = ""
+				c.Agent.ServerPort = 0
+				c.Agent.ServerSRVName = "_spire-server._tcp.example.org"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "spire-srv:///_spire-server._tcp.example.org", c.ServerAddress)
+			},
+		},
+		{
+			msg:         "server_address and server_srv_name should not be configured together",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.ServerSRVName = "_spire-server._tcp.example.org"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "workload_api_caller_policy should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.WorkloadAPICallerPolicy.AllowedUIDs = []string{"0", "1000-1999"}
+				c.Agent.WorkloadAPICallerPolicy.AllowedGIDs = []string{"100"}
+				c.Agent.WorkloadAPICallerPolicy.AllowedCgroups = []string{"/kubepods/*/pod*/*"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, &endpoints.CallerPolicy{
+					AllowedUIDs:    []endpoints.IDRange{{Min: 0, Max: 0}, {Min: 1000, Max: 1999}},
+					AllowedGIDs:    []endpoints.IDRange{{Min: 100, Max: 100}},
+					AllowedCgroups: []string{"/kubepods/*/pod*/*"},
+				}, c.WorkloadAPICallerPolicy)
+			},
+		},
+		{
+			msg: "workload_api_caller_policy is not set by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.WorkloadAPICallerPolicy)
+			},
+		},
+		{
+			msg:         "invalid workload_api_caller_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadAPICallerPolicy.AllowedUIDs = []string{"1999-1000"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid cgroup pattern in workload_api_caller_policy should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadAPICallerPolicy.AllowedCgroups = []string{"/docker/["}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "workload_api_rate_limit should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.WorkloadAPIRateLimit.PerCallerLimit = 10
+				c.Agent.WorkloadAPIRateLimit.PerCallerBurst = 20
+				c.Agent.WorkloadAPIRateLimit.GlobalLimit = 100
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, endpoints.RateLimitConfig{
+					PerCaller: endpoints.RateLimit{Limit: 10, Burst: 20},
+					Global:    endpoints.RateLimit{Limit: 100},
+				}, c.WorkloadAPIRateLimits)
+			},
+		},
+		{
+			msg:         "negative workload_api_rate_limit should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadAPIRateLimit.GlobalBurst = -1
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "grpc options should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.GRPC = grpcConfig{
+					Compression:          "gzip",
+					KeepaliveTime:        "5m",
+					KeepaliveTimeout:     "20s",
+					MaxConcurrentStreams: 100,
+					MaxRecvMessageSize:   16 << 20,
+					MaxSendMessageSize:   8 << 20,
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, client.GRPCOptions{
+					KeepaliveTime:    5 * time.Minute,
+					KeepaliveTimeout: 20 * time.Second,
+					MaxRecvMsgSize:   16 << 20,
+					MaxSendMsgSize:   8 << 20,
+					Compression:      "gzip",
+				}, c.ServerGRPCOptions)
+				require.Equal(t, uint32(100), c.WorkloadAPIMaxConcurrentStreams)
+			},
+		},
+		{
+			msg: "grpc options should default to zero",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, client.GRPCOptions{}, c.ServerGRPCOptions)
+				require.Zero(t, c.WorkloadAPIMaxConcurrentStreams)
+			},
+		},
+		{
+			msg:         "invalid grpc keepalive_timeout should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.GRPC.KeepaliveTimeout = "not-a-duration"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative grpc max_concurrent_streams should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.GRPC.MaxConcurrentStreams = -1
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "unsupported grpc compression should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.GRPC.Compression = "snappy"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "degraded_mode should be correctly parsed",
+			input: func(c *Config) {
+				serveCachedSVIDs := false
+				c.Agent.DegradedMode = degradedModeConfig{
+					GracePeriod:         "5m",
+					ServeCachedSVIDs:    &serveCachedSVIDs,
+					BundleExpiryWarning: "72h",
+					MaxRetryInterval:    "1m",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, manager.DegradedModeConfig{
+					GracePeriod:         5 * time.Minute,
+					WithholdSVIDs:       true,
+					BundleExpiryWarning: 72 * time.Hour,
+					MaxRetryInterval:    time.Minute,
+				}, c.DegradedMode)
+			},
+		},
+		{
+			msg: "degraded_mode should default to serving cached SVIDs",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, manager.DegradedModeConfig{}, c.DegradedMode)
+			},
+		},
+		{
+			msg:         "invalid degraded_mode grace_period should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.DegradedMode.GracePeriod = "not-a-duration"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "non-positive degraded_mode max_retry_interval should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.DegradedMode.MaxRetryInterval = "0s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "lazy_svids should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.Experimental.LazySVIDs = true
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.True(t, c.LazySVIDs)
+			},
+		},
+		{
+			msg: "prewarm_svids should be correctly parsed",
+			input: func(c *Config) {
+				c.Agent.Experimental.PrewarmSVIDs = true
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.True(t, c.PrewarmSVIDs)
+			},
+		},
 		{
 			msg: "trust_domain should be correctly parsed",
 			input: func(c *Config) {
@@ -601,6 +845,95 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Equal(t, "foo", c.JoinToken)
 			},
 		},
+		{
+			msg: "x509pop_tls certificate should be correctly loaded",
+			input: func(c *Config) {
+				c.Agent.X509PoPTLSCertificatePath = "../../../../test/fixture/nodeattestor/x509pop/leaf.pem"
+				c.Agent.X509PoPTLSPrivateKeyPath = "../../../../test/fixture/nodeattestor/x509pop/leaf-key.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.X509PoPTLSCertificate)
+				require.Len(t, c.X509PoPTLSCertificate.Certificate, 1)
+			},
+		},
+		{
+			msg: "x509pop_tls certificate should default to nil",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.X509PoPTLSCertificate)
+			},
+		},
+		{
+			msg:         "x509pop_tls_certificate_path without x509pop_tls_private_key_path should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.X509PoPTLSCertificatePath = "../../../../test/fixture/nodeattestor/x509pop/leaf.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "x509pop_tls_private_key_path without x509pop_tls_certificate_path should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.X509PoPTLSPrivateKeyPath = "../../../../test/fixture/nodeattestor/x509pop/leaf-key.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "x509pop_tls certificate that does not match the key should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.X509PoPTLSCertificatePath = "../../../../test/fixture/nodeattestor/x509pop/root-crt.pem"
+				c.Agent.X509PoPTLSPrivateKeyPath = "../../../../test/fixture/nodeattestor/x509pop/leaf-key.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "bootstrap SVID should be correctly loaded",
+			input: func(c *Config) {
+				c.Agent.BootstrapSVIDPath = "../../../../test/fixture/nodeattestor/x509pop/leaf.pem"
+				c.Agent.BootstrapKeyPath = "../../../../test/fixture/nodeattestor/x509pop/leaf-key.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.BootstrapSVID)
+				require.Len(t, c.BootstrapSVID.Certificate, 1)
+			},
+		},
+		{
+			msg: "bootstrap SVID should default to nil",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c.BootstrapSVID)
+			},
+		},
+		{
+			msg:         "bootstrap_svid_path without bootstrap_key_path should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.BootstrapSVIDPath = "../../../../test/fixture/nodeattestor/x509pop/leaf.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "bootstrap_key_path without bootstrap_svid_path should return an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.BootstrapKeyPath = "../../../../test/fixture/nodeattestor/x509pop/leaf-key.pem"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "data_dir should be correctly configured",
 			input: func(c *Config) {
@@ -622,6 +955,114 @@ func TestNewAgentConfig(t *testing.T) {
 				l := c.Log.(*log.Logger)
 				require.Equal(t, logrus.WarnLevel, l.Level)
 				require.IsType(t, &logrus.TextFormatter{}, l.Formatter)
+				require.False(t, l.ReportCaller)
+			},
+		},
+		{
+			msg: "log_source_location adds the caller to log entries",
+			input: func(c *Config) {
+				c.Agent.LogSourceLocation = true
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.Log)
+
+				l := c.Log.(*log.Logger)
+				require.True(t, l.ReportCaller)
+			},
+		},
+		{
+			msg: "require_plugin_checksums is correctly set",
+			input: func(c *Config) {
+				c.Agent.RequirePluginChecksums = true
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.True(t, c.RequirePluginChecksums)
+			},
+		},
+		{
+			msg: "uds_mode and uds_group are correctly parsed",
+			input: func(c *Config) {
+				c.Agent.UDSMode = "0770"
+				c.Agent.UDSGroup = "workloads"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, os.FileMode(0770), c.BindAddressMode)
+				require.Equal(t, "workloads", c.BindAddressGroup)
+			},
+		},
+		{
+			msg:         "invalid uds_mode returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.UDSMode = "0999"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "workload_x509_svid_dns_names is set",
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDDNSNames = map[string][]string{
+					"spiffe://example.org/web": {"host1.web.example.org"},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, map[spiffeid.ID][]string{
+					spiffeid.RequireFromString("spiffe://example.org/web"): {"host1.web.example.org"},
+				}, c.WorkloadDNSNames)
+			},
+		},
+		{
+			msg:         "invalid DNS name in workload_x509_svid_dns_names returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDDNSNames = map[string][]string{
+					"spiffe://example.org/web": {"host1-.example.org"},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "SPIFFE ID outside of the trust domain in workload_x509_svid_dns_names returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDDNSNames = map[string][]string{
+					"spiffe://other.org/web": {"host1.web.example.org"},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "workload_x509_svid_key_type is not set",
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDKeyType = ""
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, keymanager.KeyTypeUnset, c.WorkloadKeyType)
+			},
+		},
+		{
+			msg: "workload_x509_svid_key_type is correctly parsed",
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDKeyType = "rsa-2048"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, keymanager.RSA2048, c.WorkloadKeyType)
+			},
+		},
+		{
+			msg:         "invalid workload_x509_svid_key_type returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.WorkloadX509SVIDKeyType = "rsa-1024"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
 			},
 		},
 		{
@@ -699,6 +1140,80 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "workload_attestation_cache_ttl parses a duration",
+			input: func(c *Config) {
+				c.Agent.Experimental.WorkloadAttestationCacheTTL = "5s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, 5*time.Second, c.WorkloadAttestationCacheTTL)
+			},
+		},
+		{
+			msg:         "invalid workload_attestation_cache_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.WorkloadAttestationCacheTTL = "moo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "negative workload_attestation_cache_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.Experimental.WorkloadAttestationCacheTTL = "-1s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "required_workload_attestors are configured plugins",
+			input: func(c *Config) {
+				c.Agent.RequiredWorkloadAttestors = []string{"unix", "k8s"}
+				c.Plugins = &catalog.HCLPluginConfigMap{
+					"WorkloadAttestor": {
+						"unix": {},
+						"k8s":  {},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []string{"unix", "k8s"}, c.RequiredWorkloadAttestors)
+			},
+		},
+		{
+			msg:         "required_workload_attestors not configured returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.RequiredWorkloadAttestors = []string{"unix", "k8s"}
+				c.Plugins = &catalog.HCLPluginConfigMap{
+					"WorkloadAttestor": {
+						"unix": {},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "required_workload_attestors disabled returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.RequiredWorkloadAttestors = []string{"unix"}
+				c.Plugins = &catalog.HCLPluginConfigMap{
+					"WorkloadAttestor": {
+						"unix": {Enabled: new(bool)},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "allowed_foreign_jwt_claims provided",
 			input: func(c *Config) {
@@ -708,6 +1223,76 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Equal(t, []string{"c1", "c2"}, c.AllowedForeignJWTClaims)
 			},
 		},
+		{
+			msg: "allowed_jwt_audiences provided",
+			input: func(c *Config) {
+				c.Agent.AllowedJWTAudiences = []string{"aud1", "aud2"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []string{"aud1", "aud2"}, c.AllowedJWTAudiences)
+			},
+		},
+		{
+			msg: "allowed_foreign_jwt_trust_domains provided",
+			input: func(c *Config) {
+				c.Agent.AllowedForeignJWTTrustDomains = []string{"domain1.test", "spiffe://domain2.test"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []spiffeid.TrustDomain{
+					spiffeid.RequireTrustDomainFromString("domain1.test"),
+					spiffeid.RequireTrustDomainFromString("domain2.test"),
+				}, c.AllowedForeignJWTTrustDomains)
+			},
+		},
+		{
+			msg: "allowed_foreign_jwt_trust_domains is empty",
+			input: func(c *Config) {
+				c.Agent.AllowedForeignJWTTrustDomains = []string{}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.NotNil(t, c.AllowedForeignJWTTrustDomains)
+				require.Empty(t, c.AllowedForeignJWTTrustDomains)
+			},
+		},
+		{
+			msg:         "allowed_foreign_jwt_trust_domains is invalid",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AllowedForeignJWTTrustDomains = []string{"Invalid Domain"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "allowed_jwt_svid_clock_skew provided",
+			input: func(c *Config) {
+				c.Agent.AllowedJWTSVIDClockSkew = "30s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, 30*time.Second, c.AllowedJWTSVIDClockSkew)
+			},
+		},
+		{
+			msg:         "allowed_jwt_svid_clock_skew is not a duration",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AllowedJWTSVIDClockSkew = "abc"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "allowed_jwt_svid_clock_skew is negative",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AllowedJWTSVIDClockSkew = "-1s"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "SDS configurables are provided",
 			input: func(c *Config) {
@@ -729,6 +1314,8 @@ func TestNewAgentConfig(t *testing.T) {
 			},
 			test: func(t *testing.T, c *agent.Config) {
 				require.Empty(t, c.AllowedForeignJWTClaims)
+				require.Nil(t, c.AllowedJWTAudiences)
+				require.Nil(t, c.AllowedForeignJWTTrustDomains)
 			},
 		},
 		{
@@ -786,6 +1373,18 @@ func TestNewAgentConfig(t *testing.T) {
 	}
 }
 
+func TestNewAgentConfigLogFile(t *testing.T) {
+	input := defaultValidConfig()
+	input.Agent.LogFile = filepath.Join(t.TempDir(), "agent.log")
+
+	ac, err := NewAgentConfig(input, nil, false)
+	require.NoError(t, err)
+	defer ac.Log.(*log.Logger).Close()
+
+	// The log file can be reopened on signal to support log rotation
+	require.NotNil(t, ac.LogReopener)
+}
+
 // defaultValidConfig returns the bare minimum config required to
 // pass validation etc
 func defaultValidConfig() *Config {
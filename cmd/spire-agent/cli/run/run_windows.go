@@ -9,6 +9,7 @@ import (
 	"net"
 
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
 	"github.com/spiffe/spire/pkg/common/util"
 )
 
@@ -28,10 +29,18 @@ func (c *agentConfig) getAdminAddr() (net.Addr, error) {
 	return util.GetNamedPipeAddr(c.Experimental.AdminNamedPipeName), nil
 }
 
+func (c *agentConfig) getRotationEventsAddr() (net.Addr, error) {
+	return nil, errors.New("rotation_events is not supported in this platform")
+}
+
 func (c *agentConfig) hasAdminAddr() bool {
 	return c.Experimental.AdminNamedPipeName != ""
 }
 
+func (c *agentConfig) getAdditionalSockets(net.Addr) ([]endpoints.Socket, error) {
+	return nil, nil
+}
+
 // validateOS performs windows specific validations of the agent config
 func (c *agentConfig) validateOS() error {
 	if c.SocketPath != "" {
@@ -40,5 +49,14 @@ func (c *agentConfig) validateOS() error {
 	if c.AdminSocketPath != "" {
 		return errors.New("invalid configuration: admin_socket_path is not supported in this platform; please use admin_named_pipe_name instead")
 	}
+	if c.UDSMode != "" || c.UDSGroup != "" {
+		return errors.New("invalid configuration: uds_mode and uds_group are not supported in this platform")
+	}
+	if len(c.AdditionalSockets) > 0 {
+		return errors.New("invalid configuration: additional_sockets are not supported in this platform")
+	}
+	if c.RotationEvents.SocketPath != "" {
+		return errors.New("invalid configuration: rotation_events is not supported in this platform")
+	}
 	return nil
 }
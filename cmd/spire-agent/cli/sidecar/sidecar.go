@@ -0,0 +1,219 @@
+package sidecar
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+func NewSidecarCommand() cli.Command {
+	return newSidecarCommand(common_cli.DefaultEnv)
+}
+
+func newSidecarCommand(env *common_cli.Env) *sidecarCommand {
+	return &sidecarCommand{
+		env:   env,
+		watch: workloadapi.WatchX509Context,
+	}
+}
+
+type sidecarCommand struct {
+	common.ConfigOS // os specific
+
+	env *common_cli.Env
+
+	certFile         string
+	keyFile          string
+	bundleFile       string
+	spiffeID         string
+	federatedBundles bool
+	reloadCommand    []string
+
+	watch func(ctx context.Context, watcher workloadapi.X509ContextWatcher, options ...workloadapi.ClientOption) error
+}
+
+func (c *sidecarCommand) Help() string {
+	// ignoring parsing errors since "-h" is always supported by the flags package
+	_ = c.parseFlags([]string{"-h"})
+	return ""
+}
+
+func (c *sidecarCommand) Synopsis() string {
+	return "Writes rotating X509-SVIDs and bundles to disk and runs a reload command on rotation"
+}
+
+func (c *sidecarCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		return 1
+	}
+	if err := c.run(); err != nil {
+		_ = c.env.ErrPrintln(err)
+		return 1
+	}
+	return 0
+}
+
+func (c *sidecarCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet("sidecar", flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	fs.StringVar(&c.certFile, "certFile", "", "File to write the X509-SVID certificate chain to")
+	fs.StringVar(&c.keyFile, "keyFile", "", "File to write the X509-SVID private key to")
+	fs.StringVar(&c.bundleFile, "bundleFile", "", "File to write the trust bundle to")
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID of the X509-SVID to write (optional, defaults to the first X509-SVID received)")
+	fs.BoolVar(&c.federatedBundles, "federatedBundles", false, "Also write the bundles of federated trust domains to the bundle file")
+	c.AddOSFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c.reloadCommand = fs.Args()
+	return nil
+}
+
+func (c *sidecarCommand) run() error {
+	switch {
+	case c.certFile == "":
+		return errors.New("certFile must be specified")
+	case c.keyFile == "":
+		return errors.New("keyFile must be specified")
+	case c.bundleFile == "":
+		return errors.New("bundleFile must be specified")
+	}
+	if c.spiffeID != "" {
+		if _, err := spiffeid.FromString(c.spiffeID); err != nil {
+			return fmt.Errorf("invalid spiffeID: %w", err)
+		}
+	}
+
+	addr, err := c.GetAddr()
+	if err != nil {
+		return err
+	}
+	clientOption, err := util.GetWorkloadAPIClientOption(addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	err = c.watch(ctx, &watcher{ctx: ctx, c: c}, clientOption)
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// update writes the X509-SVID and bundles in the X509 context to disk and
+// runs the reload command, if any.
+func (c *sidecarCommand) update(ctx context.Context, x509Context *workloadapi.X509Context) error {
+	svid, err := c.selectSVID(x509Context)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := x509Context.Bundles.GetX509BundleForTrustDomain(svid.ID.TrustDomain())
+	if err != nil {
+		return err
+	}
+	authorities := bundle.X509Authorities()
+	if c.federatedBundles {
+		authorities = append(authorities, federatedAuthorities(x509Context, svid.ID.TrustDomain())...)
+	}
+
+	certsPEM, keyPEM, err := svid.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal X509-SVID: %w", err)
+	}
+
+	// The key is written first so that the certificate never refers to a
+	// key that is not on disk yet.
+	if err := diskutil.AtomicWriteFile(c.keyFile, keyPEM, 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := diskutil.AtomicWriteFile(c.certFile, certsPEM, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate chain: %w", err)
+	}
+	if err := diskutil.AtomicWriteFile(c.bundleFile, pemutil.EncodeCertificates(authorities), 0644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	_ = c.env.Printf("Wrote X509-SVID %s expiring at %s\n", svid.ID, svid.Certificates[0].NotAfter.UTC())
+
+	if len(c.reloadCommand) == 0 {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, c.reloadCommand[0], c.reloadCommand[1:]...) // nolint: gosec // running the given command is the purpose of this command
+	cmd.Stdout = c.env.Stdout
+	cmd.Stderr = c.env.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("reload command failed: %w", err)
+	}
+	return nil
+}
+
+func (c *sidecarCommand) selectSVID(x509Context *workloadapi.X509Context) (*x509svid.SVID, error) {
+	if c.spiffeID == "" {
+		if len(x509Context.SVIDs) == 0 {
+			return nil, errors.New("no X509-SVID received")
+		}
+		return x509Context.DefaultSVID(), nil
+	}
+	for _, svid := range x509Context.SVIDs {
+		if svid.ID.String() == c.spiffeID {
+			return svid, nil
+		}
+	}
+	return nil, fmt.Errorf("no X509-SVID received for %q", c.spiffeID)
+}
+
+// federatedAuthorities returns the X.509 authorities of the trust domains
+// other than td, sorted by trust domain so the output is consistent.
+func federatedAuthorities(x509Context *workloadapi.X509Context, td spiffeid.TrustDomain) []*x509.Certificate {
+	bundles := x509Context.Bundles.Bundles()
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].TrustDomain().String() < bundles[j].TrustDomain().String()
+	})
+
+	var authorities []*x509.Certificate
+	for _, bundle := range bundles {
+		if bundle.TrustDomain() != td {
+			authorities = append(authorities, bundle.X509Authorities()...)
+		}
+	}
+	return authorities
+}
+
+type watcher struct {
+	ctx context.Context
+	c   *sidecarCommand
+}
+
+func (w *watcher) OnX509ContextUpdate(x509Context *workloadapi.X509Context) {
+	if err := w.c.update(w.ctx, x509Context); err != nil {
+		_ = w.c.env.ErrPrintf("Failed to update X509-SVID: %v\n", err)
+	}
+}
+
+func (w *watcher) OnX509ContextWatchError(err error) {
+	if w.ctx.Err() != nil {
+		// The sidecar is shutting down
+		return
+	}
+	_ = w.c.env.ErrPrintf("Failed to watch the Workload API: %v\n", err)
+}
@@ -0,0 +1,184 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	td        = spiffeid.RequireTrustDomainFromString("example.org")
+	federated = spiffeid.RequireTrustDomainFromString("federated.org")
+	workload  = spiffeid.RequireFromPath(td, "/workload")
+	other     = spiffeid.RequireFromPath(td, "/other")
+)
+
+type sidecarTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+	dir    string
+
+	cmd *sidecarCommand
+}
+
+func setupTest(t *testing.T, x509Context *workloadapi.X509Context) *sidecarTest {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newSidecarCommand(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	cmd.watch = func(ctx context.Context, watcher workloadapi.X509ContextWatcher, options ...workloadapi.ClientOption) error {
+		watcher.OnX509ContextUpdate(x509Context)
+		return nil
+	}
+	return &sidecarTest{
+		stdout: stdout,
+		stderr: stderr,
+		dir:    t.TempDir(),
+		cmd:    cmd,
+	}
+}
+
+func (s *sidecarTest) args(extra ...string) []string {
+	return append([]string{
+		"-certFile", filepath.Join(s.dir, "svid.pem"),
+		"-keyFile", filepath.Join(s.dir, "svid.key"),
+		"-bundleFile", filepath.Join(s.dir, "bundle.pem"),
+	}, extra...)
+}
+
+func TestSidecarSynopsis(t *testing.T) {
+	test := setupTest(t, nil)
+	require.Equal(t, "Writes rotating X509-SVIDs and bundles to disk and runs a reload command on rotation", test.cmd.Synopsis())
+}
+
+func TestSidecarMissingFlags(t *testing.T) {
+	for _, tt := range []struct {
+		args      []string
+		expectErr string
+	}{
+		{
+			args:      []string{"-keyFile", "svid.key", "-bundleFile", "bundle.pem"},
+			expectErr: "certFile must be specified\n",
+		},
+		{
+			args:      []string{"-certFile", "svid.pem", "-bundleFile", "bundle.pem"},
+			expectErr: "keyFile must be specified\n",
+		},
+		{
+			args:      []string{"-certFile", "svid.pem", "-keyFile", "svid.key"},
+			expectErr: "bundleFile must be specified\n",
+		},
+		{
+			args:      []string{"-certFile", "svid.pem", "-keyFile", "svid.key", "-bundleFile", "bundle.pem", "-spiffeID", "not-an-id"},
+			expectErr: "invalid spiffeID: scheme is missing or invalid\n",
+		},
+	} {
+		test := setupTest(t, nil)
+		require.Equal(t, 1, test.cmd.Run(tt.args))
+		require.Equal(t, tt.expectErr, test.stderr.String())
+	}
+}
+
+func TestSidecarWritesSVID(t *testing.T) {
+	ca := testca.New(t, td)
+	federatedCA := testca.New(t, federated)
+	workloadSVID := ca.CreateX509SVID(workload)
+	otherSVID := ca.CreateX509SVID(other)
+	x509Context := &workloadapi.X509Context{
+		SVIDs:   []*x509svid.SVID{workloadSVID, otherSVID},
+		Bundles: x509bundle.NewSet(ca.X509Bundle(), federatedCA.X509Bundle()),
+	}
+
+	for _, tt := range []struct {
+		name              string
+		args              []string
+		expectSVID        *x509svid.SVID
+		expectAuthorities int
+		expectErr         string
+	}{
+		{
+			name:              "default SVID",
+			expectSVID:        workloadSVID,
+			expectAuthorities: len(ca.X509Authorities()),
+		},
+		{
+			name:              "selected SVID",
+			args:              []string{"-spiffeID", other.String()},
+			expectSVID:        otherSVID,
+			expectAuthorities: len(ca.X509Authorities()),
+		},
+		{
+			name:              "with federated bundles",
+			args:              []string{"-federatedBundles"},
+			expectSVID:        workloadSVID,
+			expectAuthorities: len(ca.X509Authorities()) + len(federatedCA.X509Authorities()),
+		},
+		{
+			name:      "unknown SVID",
+			args:      []string{"-spiffeID", "spiffe://example.org/unknown"},
+			expectErr: "Failed to update X509-SVID: no X509-SVID received for \"spiffe://example.org/unknown\"\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, x509Context)
+			require.Equal(t, 0, test.cmd.Run(test.args(tt.args...)))
+			if tt.expectErr != "" {
+				require.Equal(t, tt.expectErr, test.stderr.String())
+				require.NoFileExists(t, filepath.Join(test.dir, "svid.pem"))
+				return
+			}
+			require.Empty(t, test.stderr.String())
+
+			certs, err := pemutil.LoadCertificates(filepath.Join(test.dir, "svid.pem"))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectSVID.Certificates, certs)
+
+			key, err := pemutil.LoadPrivateKey(filepath.Join(test.dir, "svid.key"))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectSVID.PrivateKey.Public(), key.(crypto.Signer).Public())
+
+			authorities, err := pemutil.LoadCertificates(filepath.Join(test.dir, "bundle.pem"))
+			require.NoError(t, err)
+			require.Len(t, authorities, tt.expectAuthorities)
+			require.Equal(t, ca.X509Authorities(), authorities[:len(ca.X509Authorities())])
+		})
+	}
+}
+
+func TestSidecarRunsReloadCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on a POSIX shell")
+	}
+
+	ca := testca.New(t, td)
+	x509Context := &workloadapi.X509Context{
+		SVIDs:   []*x509svid.SVID{ca.CreateX509SVID(workload)},
+		Bundles: x509bundle.NewSet(ca.X509Bundle()),
+	}
+
+	test := setupTest(t, x509Context)
+	marker := filepath.Join(test.dir, "reloaded")
+	require.Equal(t, 0, test.cmd.Run(test.args("--", "sh", "-c", `test -s "$0" && touch "$1"`, filepath.Join(test.dir, "svid.pem"), marker)))
+	require.Empty(t, test.stderr.String())
+	require.FileExists(t, marker)
+
+	test = setupTest(t, x509Context)
+	require.Equal(t, 0, test.cmd.Run(test.args("sh", "-c", "exit 1")))
+	require.Equal(t, "Failed to update X509-SVID: reload command failed: exit status 1\n", test.stderr.String())
+}
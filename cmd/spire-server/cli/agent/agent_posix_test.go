@@ -7,9 +7,23 @@ var (
 	listUsage = `Usage of agent list:
   -matchSelectorsOn string
     	The match mode used when filtering by selectors. Options: exact, any, superset and subset (default "superset")
+  -nodeAlias string
+    	The SPIFFE ID of a node alias. Only lists the agents that currently map to it
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -socketPath string
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`
+	mintUsage = `Usage of agent mint:
+  -selector value
+    	A colon-delimited type:value selector of the agent. Can be used more than once
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+  -spiffeID string
+    	The SPIFFE ID of the agent. Must be in the reserved agent namespace
+  -write string
+    	Directory to write the agent SVID and key to instead of stdout
 `
 )
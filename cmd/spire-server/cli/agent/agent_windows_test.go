@@ -9,7 +9,21 @@ var (
     	The match mode used when filtering by selectors. Options: exact, any, superset and subset (default "superset")
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -nodeAlias string
+    	The SPIFFE ID of a node alias. Only lists the agents that currently map to it
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
+`
+	mintUsage = `Usage of agent mint:
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -selector value
+    	A colon-delimited type:value selector of the agent. Can be used more than once
+  -spiffeID string
+    	The SPIFFE ID of the agent. Must be in the reserved agent namespace
+  -write string
+    	Directory to write the agent SVID and key to instead of stdout
 `
 )
@@ -8,7 +8,9 @@ import (
 
 	"github.com/mitchellh/cli"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
@@ -24,6 +26,12 @@ type listCommand struct {
 
 	// Match used when filtering agents by selectors
 	matchSelectorsOn string
+
+	// SPIFFE ID of a node alias the agents must map to
+	nodeAlias string
+
+	// Output format, either pretty or json
+	output string
 }
 
 // NewListCommand creates a new "list" subcommand for "agent" command.
@@ -47,18 +55,52 @@ func (listCommand) Synopsis() string {
 
 // Run lists attested agents
 func (c *listCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
+
+	var agents []*types.Agent
+	var err error
+	if c.nodeAlias != "" {
+		if len(c.selectors) > 0 {
+			return errors.New("the -nodeAlias and -selector flags can't be combined")
+		}
+		agents, err = c.listNodeAliasAgents(ctx, serverClient)
+	} else {
+		agents, err = c.listAgentsBySelectors(ctx, serverClient.NewAgentClient())
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.output == util.OutputJSON {
+		return util.PrintJSON(env, &agentv1.ListAgentsResponse{Agents: agents})
+	}
+
+	if len(agents) == 0 {
+		return env.Printf("No attested agents found\n")
+	}
+
+	msg := fmt.Sprintf("Found %d attested ", len(agents))
+	msg = util.Pluralizer(msg, "agent", "agents", len(agents))
+	env.Printf(msg + ":\n\n")
+
+	return printAgents(env, agents...)
+}
+
+func (c *listCommand) listAgentsBySelectors(ctx context.Context, agentClient agentv1.AgentClient) ([]*types.Agent, error) {
 	filter := &agentv1.ListAgentsRequest_Filter{}
 	if len(c.selectors) > 0 {
 		matchBehavior, err := parseToSelectorMatch(c.matchSelectorsOn)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		selectors := make([]*types.Selector, len(c.selectors))
 		for i, sel := range c.selectors {
 			selector, err := util.ParseSelector(sel)
 			if err != nil {
-				return fmt.Errorf("error parsing selector %q: %w", sel, err)
+				return nil, fmt.Errorf("error parsing selector %q: %w", sel, err)
 			}
 			selectors[i] = selector
 		}
@@ -68,8 +110,60 @@ func (c *listCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 		}
 	}
 
+	return listAgents(ctx, agentClient, filter)
+}
+
+// listNodeAliasAgents lists the agents that currently map to the configured
+// node alias, i.e. the agents holding every selector of at least one of the
+// node alias entries with that SPIFFE ID.
+func (c *listCommand) listNodeAliasAgents(ctx context.Context, serverClient util.ServerClient) ([]*types.Agent, error) {
+	id, err := spiffeid.FromString(c.nodeAlias)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing node alias %q: %w", c.nodeAlias, err)
+	}
+	serverID, err := idutil.ServerID(id.TrustDomain())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := serverClient.NewEntryClient().ListEntries(ctx, &entryv1.ListEntriesRequest{
+		Filter: &entryv1.ListEntriesRequest_Filter{
+			BySpiffeId: &types.SPIFFEID{TrustDomain: id.TrustDomain().String(), Path: id.Path()},
+			ByParentId: &types.SPIFFEID{TrustDomain: serverID.TrustDomain().String(), Path: serverID.Path()},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching node alias %q: %w", c.nodeAlias, err)
+	}
+	if len(resp.Entries) == 0 {
+		return nil, fmt.Errorf("no node alias found with SPIFFE ID %q", c.nodeAlias)
+	}
+
 	agentClient := serverClient.NewAgentClient()
+	seen := make(map[string]bool)
+	var agents []*types.Agent
+	for _, alias := range resp.Entries {
+		aliasAgents, err := listAgents(ctx, agentClient, &agentv1.ListAgentsRequest_Filter{
+			BySelectorMatch: &types.SelectorMatch{
+				Selectors: alias.Selectors,
+				Match:     types.SelectorMatch_MATCH_SUPERSET,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, agent := range aliasAgents {
+			key := agent.Id.String()
+			if !seen[key] {
+				seen[key] = true
+				agents = append(agents, agent)
+			}
+		}
+	}
+	return agents, nil
+}
 
+func listAgents(ctx context.Context, agentClient agentv1.AgentClient, filter *agentv1.ListAgentsRequest_Filter) ([]*types.Agent, error) {
 	pageToken := ""
 	var agents []*types.Agent
 	for {
@@ -79,28 +173,21 @@ func (c *listCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 			Filter:    filter,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		agents = append(agents, listResponse.Agents...)
 		if pageToken = listResponse.NextPageToken; pageToken == "" {
 			break
 		}
 	}
-
-	if len(agents) == 0 {
-		return env.Printf("No attested agents found\n")
-	}
-
-	msg := fmt.Sprintf("Found %d attested ", len(agents))
-	msg = util.Pluralizer(msg, "agent", "agents", len(agents))
-	env.Printf(msg + ":\n\n")
-
-	return printAgents(env, agents...)
+	return agents, nil
 }
 
 func (c *listCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset and subset")
+	fs.StringVar(&c.nodeAlias, "nodeAlias", "", "The SPIFFE ID of a node alias. Only lists the agents that currently map to it")
 	fs.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
+	util.AddOutputFlag(fs, &c.output)
 }
 
 func printAgents(env *common_cli.Env, agents ...*types.Agent) error {
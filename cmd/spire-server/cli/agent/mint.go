@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+)
+
+type mintCommand struct {
+	// SPIFFE ID of the agent
+	spiffeID string
+
+	// Selectors of the agent
+	selectors common_cli.StringsFlag
+
+	// Directory to write the SVID and key to
+	write string
+}
+
+// NewMintCommand creates a new "mint" subcommand for "agent" command.
+func NewMintCommand() cli.Command {
+	return NewMintCommandWithEnv(common_cli.DefaultEnv)
+}
+
+// NewMintCommandWithEnv creates a new "mint" subcommand for "agent" command
+// using the environment specified
+func NewMintCommandWithEnv(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(mintCommand))
+}
+
+func (*mintCommand) Name() string {
+	return "agent mint"
+}
+
+func (*mintCommand) Synopsis() string {
+	return "Mints an agent SVID to bootstrap an agent without node attestation"
+}
+
+// Run mints an agent SVID for a new key and records the agent as attested
+// with the given selectors
+func (c *mintCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.spiffeID == "" {
+		return errors.New("a SPIFFE ID is required")
+	}
+
+	id, err := spiffeid.FromString(c.spiffeID)
+	if err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("unable to generate key: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		URIs: []*url.URL{id.URL()},
+	}, key)
+	if err != nil {
+		return fmt.Errorf("unable to generate CSR: %w", err)
+	}
+
+	bootstrapClient := serverClient.NewAgentBootstrapClient()
+	resp, err := bootstrapClient.MintAgentX509SVID(ctx, &agentbootstrap.MintAgentX509SVIDRequest{
+		SpiffeId:  id.String(),
+		Csr:       csr,
+		Selectors: c.selectors,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.CertChain) == 0 {
+		return errors.New("server response missing SVID chain")
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	svidPEM := new(bytes.Buffer)
+	for _, certDER := range resp.CertChain {
+		_ = pem.Encode(svidPEM, &pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: certDER,
+		})
+	}
+
+	keyPEM := new(bytes.Buffer)
+	_ = pem.Encode(keyPEM, &pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: keyBytes,
+	})
+
+	if c.write == "" {
+		if err := env.Printf("Agent X509-SVID:\n%s\n", svidPEM.String()); err != nil {
+			return err
+		}
+		return env.Printf("Private key:\n%s\n", keyPEM.String())
+	}
+
+	svidPath := env.JoinPath(c.write, "agent_svid.pem")
+	keyPath := env.JoinPath(c.write, "agent_key.pem")
+
+	if err := os.WriteFile(svidPath, svidPEM.Bytes(), 0644); err != nil { // nolint: gosec // expected permission
+		return fmt.Errorf("unable to write agent SVID: %w", err)
+	}
+	if err := env.Printf("Agent X509-SVID written to %s\n", svidPath); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyPath, keyPEM.Bytes(), 0600); err != nil {
+		return fmt.Errorf("unable to write key: %w", err)
+	}
+	return env.Printf("Private key written to %s\n", keyPath)
+}
+
+func (c *mintCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent. Must be in the reserved agent namespace")
+	fs.Var(&c.selectors, "selector", "A colon-delimited type:value selector of the agent. Can be used more than once")
+	fs.StringVar(&c.write, "write", "", "Directory to write the agent SVID and key to instead of stdout")
+}
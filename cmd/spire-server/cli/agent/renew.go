@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+)
+
+type renewCommand struct {
+	// SPIFFE ID of the agent being renewed
+	spiffeID string
+}
+
+// NewRenewCommand creates a new "renew" subcommand for "agent" command.
+func NewRenewCommand() cli.Command {
+	return NewRenewCommandWithEnv(common_cli.DefaultEnv)
+}
+
+// NewRenewCommandWithEnv creates a new "renew" subcommand for "agent" command
+// using the environment specified
+func NewRenewCommandWithEnv(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(renewCommand))
+}
+
+func (*renewCommand) Name() string {
+	return "agent renew"
+}
+
+func (*renewCommand) Synopsis() string {
+	return "Forces an attested agent to renew its SVID on its next sync"
+}
+
+// Run requests the renewal of the SVID of an agent given its SPIFFE ID
+func (c *renewCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.spiffeID == "" {
+		return errors.New("a SPIFFE ID is required")
+	}
+
+	id, err := spiffeid.FromString(c.spiffeID)
+	if err != nil {
+		return err
+	}
+
+	renewalClient := serverClient.NewAgentRenewalClient()
+	resp, err := renewalClient.RequestAgentRenewal(ctx, &agentrenewal.RequestAgentRenewalRequest{
+		SpiffeId: id.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return env.Printf("Agent SVID renewal requested (serial number %s)\n", resp.X509SvidSerialNumber)
+}
+
+func (c *renewCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent to renew (agent identity)")
+}
@@ -3,13 +3,16 @@ package agent
 import (
 	"errors"
 	"flag"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/server/api"
 
 	"golang.org/x/net/context"
@@ -18,6 +21,9 @@ import (
 type showCommand struct {
 	// SPIFFE ID of the agent being showed
 	spiffeID string
+
+	// Output format, either pretty or json
+	output string
 }
 
 // NewShowCommand creates a new "show" subcommand for "agent" command.
@@ -44,6 +50,9 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 	if c.spiffeID == "" {
 		return errors.New("a SPIFFE ID is required")
 	}
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
 
 	id, err := spiffeid.FromString(c.spiffeID)
 	if err != nil {
@@ -56,12 +65,32 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 		return err
 	}
 
+	if c.output == util.OutputJSON {
+		return util.PrintJSON(env, agent)
+	}
+
 	env.Printf("Found an attested agent given its SPIFFE ID\n\n")
+	return printAgentDetails(env, agent)
+}
 
-	if err := printAgents(env, agent); err != nil {
+// printAgentDetails prints the agent, including its selectors. Unlike
+// printAgents, the banned state is always printed.
+func printAgentDetails(env *common_cli.Env, agent *types.Agent) error {
+	id, err := idutil.IDFromProto(agent.Id)
+	if err != nil {
 		return err
 	}
 
+	serialNumber := agent.X509SvidSerialNumber
+	if serialNumber == "" {
+		serialNumber = "-"
+	}
+
+	env.Printf("SPIFFE ID         : %s\n", id.String())
+	env.Printf("Attestation type  : %s\n", agent.AttestationType)
+	env.Printf("Expiration time   : %s\n", time.Unix(agent.X509SvidExpiresAt, 0))
+	env.Printf("Serial number     : %s\n", serialNumber)
+	env.Printf("Banned            : %t\n", agent.Banned)
 	for _, s := range agent.Selectors {
 		env.Printf("Selectors         : %s:%s\n", s.Type, s.Value)
 	}
@@ -70,4 +99,5 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 
 func (c *showCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent to show (agent identity)")
+	util.AddOutputFlag(fs, &c.output)
 }
@@ -25,7 +25,7 @@ func TestShowHelp(t *testing.T) {
 
 	require.Equal(t, `Usage of bundle show:
   -format string
-    	The format to show the bundle. Either "pem" or "spiffe". (default "pem")`+common.AddrUsage, test.stderr.String())
+    	The format to show the bundle. Either "pem", "spiffe" or "jwks" (JWT authorities only). (default "pem")`+common.AddrUsage, test.stderr.String())
 }
 
 func TestShowSynopsis(t *testing.T) {
@@ -55,6 +55,16 @@ func TestShow(t *testing.T) {
 			args:        []string{"-format", util.FormatSPIFFE},
 			expectedOut: cert1JWKS,
 		},
+		{
+			name:        "jwks",
+			args:        []string{"-format", util.FormatJWKS},
+			expectedOut: emptyJWKS,
+		},
+		{
+			name:          "invalid format",
+			args:          []string{"-format", "invalid"},
+			expectedError: "Error: invalid format: \"invalid\"\n",
+		},
 		{
 			name:          "server fails",
 			serverErr:     errors.New("some error"),
@@ -361,7 +371,7 @@ func TestCountHelp(t *testing.T) {
 	test := setupTest(t, NewCountCommandWithEnv)
 	test.client.Help()
 
-	require.Equal(t, `Usage of bundle count:`+common.AddrUsage, test.stderr.String())
+	require.Equal(t, `Usage of bundle count:`+common.AddrOutputUsage, test.stderr.String())
 }
 
 func TestCountSynopsis(t *testing.T) {
@@ -449,7 +459,7 @@ func TestListHelp(t *testing.T) {
 
 	require.Equal(t, `Usage of bundle list:
   -format string
-    	The format to list federated bundles. Either "pem" or "spiffe". (default "pem")
+    	The format to list federated bundles. Either "pem", "spiffe" or "jwks" (JWT authorities only). (default "pem")
   -id string
     	SPIFFE ID of the trust domain`+common.AddrUsage, test.stderr.String())
 }
@@ -491,6 +501,11 @@ func TestList(t *testing.T) {
 			args:           []string{"-format", util.FormatSPIFFE},
 			expectedStdout: allBundlesJWKS,
 		},
+		{
+			name:           "all bundles (jwt authorities)",
+			args:           []string{"-format", util.FormatJWKS},
+			expectedStdout: allBundlesJWTAuthorities,
+		},
 		{
 			name:           "one bundle (default)",
 			args:           []string{"-id", "spiffe://domain2.test"},
@@ -517,6 +532,11 @@ func TestList(t *testing.T) {
 			args:           []string{"-id", "spiffe://domain2.test", "-format", util.FormatSPIFFE},
 			expectedStdout: cert2JWKS,
 		},
+		{
+			name:           "one bundle (jwt authorities)",
+			args:           []string{"-id", "spiffe://domain1.test", "-format", util.FormatJWKS},
+			expectedStdout: key1JWTAuthorities,
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -18,6 +18,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	"github.com/zeebo/errs"
+	"gopkg.in/square/go-jose.v2"
 )
 
 const (
@@ -151,7 +152,7 @@ func printBundleWithFormat(out io.Writer, bundle *types.Bundle, format string, h
 		return errors.New("no bundle provided")
 	}
 
-	format, err := validateFormat(format)
+	format, err := validateOutputFormat(format)
 	if err != nil {
 		return err
 	}
@@ -162,11 +163,43 @@ func printBundleWithFormat(out io.Writer, bundle *types.Bundle, format string, h
 		}
 	}
 
-	if format == util.FormatPEM {
+	switch format {
+	case util.FormatPEM:
 		return printX509Authorities(out, bundle.X509Authorities)
+	case util.FormatJWKS:
+		return printJWTAuthorities(out, bundle.JwtAuthorities)
+	default:
+		return printBundle(out, bundle)
+	}
+}
+
+// printJWTAuthorities prints the JWT authorities as a plain JWKS document,
+// as expected by JWT validators that are not SPIFFE aware
+func printJWTAuthorities(out io.Writer, jwtAuthorities []*types.JWTKey) error {
+	jwks := jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{},
+	}
+	for i, jwtAuthority := range jwtAuthorities {
+		publicKey, err := x509.ParsePKIXPublicKey(jwtAuthority.PublicKey)
+		if err != nil {
+			return fmt.Errorf("unable to parse JWT signing key %d: %w", i, err)
+		}
+		jwks.Keys = append(jwks.Keys, jose.JSONWebKey{
+			Key:   publicKey,
+			KeyID: jwtAuthority.KeyId,
+		})
+	}
+
+	docBytes, err := json.MarshalIndent(jwks, "", "    ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(docBytes)); err != nil {
+		return errs.Wrap(err)
 	}
 
-	return printBundle(out, bundle)
+	return nil
 }
 
 // validateFormat validates that the provided format is a valid format.
@@ -187,3 +220,13 @@ func validateFormat(format string) (string, error) {
 
 	return format, nil
 }
+
+// validateOutputFormat validates that the provided format is a valid format
+// to print bundles. In addition to the formats accepted by validateFormat,
+// bundles can be printed as a plain JWKS document with the JWT authorities.
+func validateOutputFormat(format string) (string, error) {
+	if strings.ToLower(format) == util.FormatJWKS {
+		return util.FormatJWKS, nil
+	}
+	return validateFormat(format)
+}
@@ -126,6 +126,33 @@ diIqWtxAqBLFrx8zNS4=
 -----END CERTIFICATE-----
 `
 
+	emptyJWKS = `{
+    "keys": []
+}
+`
+
+	key1JWTAuthorities = `{
+    "keys": [
+        {
+            "kty": "EC",
+            "kid": "KID",
+            "crv": "P-256",
+            "x": "fK-wKTnKL7KFLM27lqq5DC-bxrVaH6rDV-IcCSEOeL4",
+            "y": "wq-g3TQWxYlV51TCPH030yXsRxvujD4hUUaIQrXk4KI"
+        }
+    ]
+}
+`
+
+	allBundlesJWTAuthorities = `****************************************
+* spiffe://domain1.test
+****************************************
+` + key1JWTAuthorities + `
+****************************************
+* spiffe://domain2.test
+****************************************
+` + emptyJWKS
+
 	allBundlesJWKS = `****************************************
 * spiffe://domain1.test
 ****************************************
@@ -6,11 +6,14 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	"github.com/spiffe/spire/cmd/spire-server/cli/bundle"
+	"github.com/spiffe/spire/cmd/spire-server/cli/denylist"
 	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
 	"github.com/spiffe/spire/cmd/spire-server/cli/federation"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-server/cli/jwt"
+	"github.com/spiffe/spire/cmd/spire-server/cli/loadtest"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	"github.com/spiffe/spire/cmd/spire-server/cli/stats"
 	"github.com/spiffe/spire/cmd/spire-server/cli/token"
 	"github.com/spiffe/spire/cmd/spire-server/cli/validate"
 	"github.com/spiffe/spire/cmd/spire-server/cli/x509"
@@ -41,6 +44,12 @@ func (cc *CLI) Run(args []string) int {
 		"agent list": func() (cli.Command, error) {
 			return agent.NewListCommand(), nil
 		},
+		"agent mint": func() (cli.Command, error) {
+			return agent.NewMintCommand(), nil
+		},
+		"agent renew": func() (cli.Command, error) {
+			return agent.NewRenewCommand(), nil
+		},
 		"agent show": func() (cli.Command, error) {
 			return agent.NewShowCommand(), nil
 		},
@@ -59,6 +68,15 @@ func (cc *CLI) Run(args []string) int {
 		"bundle delete": func() (cli.Command, error) {
 			return bundle.NewDeleteCommand(), nil
 		},
+		"denylist add": func() (cli.Command, error) {
+			return denylist.NewAddCommand(), nil
+		},
+		"denylist list": func() (cli.Command, error) {
+			return denylist.NewListCommand(), nil
+		},
+		"denylist remove": func() (cli.Command, error) {
+			return denylist.NewRemoveCommand(), nil
+		},
 		"entry count": func() (cli.Command, error) {
 			return entry.NewCountCommand(), nil
 		},
@@ -68,12 +86,33 @@ func (cc *CLI) Run(args []string) int {
 		"entry update": func() (cli.Command, error) {
 			return entry.NewUpdateCommand(), nil
 		},
+		"entry diff": func() (cli.Command, error) {
+			return entry.NewDiffCommand(), nil
+		},
+		"entry dnsnames": func() (cli.Command, error) {
+			return entry.NewDNSNamesCommand(), nil
+		},
 		"entry delete": func() (cli.Command, error) {
 			return entry.NewDeleteCommand(), nil
 		},
+		"entry import-k8s": func() (cli.Command, error) {
+			return entry.NewImportK8sCommand(), nil
+		},
+		"entry restore": func() (cli.Command, error) {
+			return entry.NewRestoreCommand(), nil
+		},
 		"entry show": func() (cli.Command, error) {
 			return entry.NewShowCommand(), nil
 		},
+		"entry template create": func() (cli.Command, error) {
+			return entry.NewTemplateCreateCommand(), nil
+		},
+		"entry template delete": func() (cli.Command, error) {
+			return entry.NewTemplateDeleteCommand(), nil
+		},
+		"entry template list": func() (cli.Command, error) {
+			return entry.NewTemplateListCommand(), nil
+		},
 		"federation create": func() (cli.Command, error) {
 			return federation.NewCreateCommand(), nil
 		},
@@ -92,12 +131,24 @@ func (cc *CLI) Run(args []string) int {
 		"federation update": func() (cli.Command, error) {
 			return federation.NewUpdateCommand(), nil
 		},
+		"loadtest": func() (cli.Command, error) {
+			return loadtest.NewLoadtestCommand(), nil
+		},
 		"run": func() (cli.Command, error) {
 			return run.NewRunCommand(cc.LogOptions, cc.AllowUnknownConfig), nil
 		},
+		"stats": func() (cli.Command, error) {
+			return stats.NewStatsCommand(), nil
+		},
 		"token generate": func() (cli.Command, error) {
 			return token.NewGenerateCommand(), nil
 		},
+		"token list": func() (cli.Command, error) {
+			return token.NewListCommand(), nil
+		},
+		"token revoke": func() (cli.Command, error) {
+			return token.NewRevokeCommand(), nil
+		},
 		"healthcheck": func() (cli.Command, error) {
 			return healthcheck.NewHealthCheckCommand(), nil
 		},
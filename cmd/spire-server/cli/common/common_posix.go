@@ -11,6 +11,12 @@ var (
 	AddrUsage = `
   -socketPath string
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`
+	AddrOutputUsage = `
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
 	AddrValue = "/does-not-exist.sock"
 )
@@ -15,6 +15,12 @@ var (
 	AddrUsage = `
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+`
+	AddrOutputUsage = `
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
 `
 	AddrValue = "\\does-not-exist"
 )
@@ -0,0 +1,134 @@
+package denylist
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/denylist"
+)
+
+// NewListCommand creates a new "denylist list" subcommand.
+func NewListCommand() cli.Command {
+	return newListCommand(common_cli.DefaultEnv)
+}
+
+func newListCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(listCommand))
+}
+
+// NewAddCommand creates a new "denylist add" subcommand.
+func NewAddCommand() cli.Command {
+	return newAddCommand(common_cli.DefaultEnv)
+}
+
+func newAddCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(addCommand))
+}
+
+// NewRemoveCommand creates a new "denylist remove" subcommand.
+func NewRemoveCommand() cli.Command {
+	return newRemoveCommand(common_cli.DefaultEnv)
+}
+
+func newRemoveCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(removeCommand))
+}
+
+type listCommand struct{}
+
+func (*listCommand) Name() string {
+	return "denylist list"
+}
+
+func (*listCommand) Synopsis() string {
+	return "Lists the patterns of the SVID denylist"
+}
+
+func (*listCommand) AppendFlags(*flag.FlagSet) {}
+
+func (*listCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	resp, err := serverClient.NewDenylistClient().ListPatterns(ctx, &denylist.ListPatternsRequest{})
+	if err != nil {
+		return err
+	}
+	return printPatterns(env, resp.Patterns)
+}
+
+type addCommand struct {
+	// Pattern to add to the denylist
+	pattern string
+}
+
+func (*addCommand) Name() string {
+	return "denylist add"
+}
+
+func (*addCommand) Synopsis() string {
+	return "Adds a pattern to the SVID denylist"
+}
+
+func (c *addCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.pattern, "pattern", "", "The pattern of the SPIFFE IDs to deny SVIDs for")
+}
+
+func (c *addCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.pattern == "" {
+		return errors.New("a pattern is required")
+	}
+
+	resp, err := serverClient.NewDenylistClient().AddPattern(ctx, &denylist.AddPatternRequest{Pattern: c.pattern})
+	if err != nil {
+		return err
+	}
+	env.Println("Pattern added to the SVID denylist. The change is lost when the server configuration is reloaded.")
+	return printPatterns(env, resp.Patterns)
+}
+
+type removeCommand struct {
+	// Pattern to remove from the denylist
+	pattern string
+}
+
+func (*removeCommand) Name() string {
+	return "denylist remove"
+}
+
+func (*removeCommand) Synopsis() string {
+	return "Removes a pattern from the SVID denylist"
+}
+
+func (c *removeCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.pattern, "pattern", "", "The pattern to remove")
+}
+
+func (c *removeCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.pattern == "" {
+		return errors.New("a pattern is required")
+	}
+
+	resp, err := serverClient.NewDenylistClient().RemovePattern(ctx, &denylist.RemovePatternRequest{Pattern: c.pattern})
+	if err != nil {
+		return err
+	}
+	env.Println("Pattern removed from the SVID denylist. The change is lost when the server configuration is reloaded.")
+	return printPatterns(env, resp.Patterns)
+}
+
+func printPatterns(env *common_cli.Env, patterns []string) error {
+	msg := fmt.Sprintf("Found %v ", len(patterns))
+	msg = util.Pluralizer(msg, "pattern", "patterns", len(patterns))
+	if err := env.Println(msg); err != nil {
+		return err
+	}
+	for _, pattern := range patterns {
+		if err := env.Println(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
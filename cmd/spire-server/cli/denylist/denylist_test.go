@@ -0,0 +1,189 @@
+package denylist
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/denylist"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListSynopsis(t *testing.T) {
+	require.Equal(t, "Lists the patterns of the SVID denylist", NewListCommand().Synopsis())
+}
+
+func TestAddHelp(t *testing.T) {
+	test := setupTest(t, newAddCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of denylist add:
+  -pattern string
+    	The pattern of the SPIFFE IDs to deny SVIDs for`+common.AddrUsage, test.stderr.String())
+}
+
+func TestList(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.server.patterns = []string{"spiffe://example.org/a", "spiffe://example.org/b/**"}
+
+	rc := test.client.Run(test.args())
+	require.Equal(t, 0, rc, test.stderr.String())
+	require.Equal(t, `Found 2 patterns
+spiffe://example.org/a
+spiffe://example.org/b/**
+`, test.stdout.String())
+}
+
+func TestAdd(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing pattern",
+			expErr: "Error: a pattern is required\n",
+		},
+		{
+			name:   "Server error",
+			args:   []string{"-pattern", "["},
+			expErr: "Error: rpc error: code = InvalidArgument desc = invalid pattern\n",
+		},
+		{
+			name: "Success",
+			args: []string{"-pattern", "spiffe://example.org/b/**"},
+			expOut: `Pattern added to the SVID denylist. The change is lost when the server configuration is reloaded.
+Found 1 pattern
+spiffe://example.org/b/**
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newAddCommand)
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing pattern",
+			expErr: "Error: a pattern is required\n",
+		},
+		{
+			name:   "Pattern not found",
+			args:   []string{"-pattern", "spiffe://example.org/c"},
+			expErr: "Error: rpc error: code = NotFound desc = pattern not found\n",
+		},
+		{
+			name: "Success",
+			args: []string{"-pattern", "spiffe://example.org/a"},
+			expOut: `Pattern removed from the SVID denylist. The change is lost when the server configuration is reloaded.
+Found 0 patterns
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newRemoveCommand)
+			test.server.patterns = []string{"spiffe://example.org/a"}
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+type denylistTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	addr   string
+	server *fakeDenylistServer
+
+	client cli.Command
+}
+
+func (d *denylistTest) args(extra ...string) []string {
+	return append([]string{common.AddrArg, d.addr}, extra...)
+}
+
+func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *denylistTest {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	client := newClient(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	server := new(fakeDenylistServer)
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		denylist.RegisterDenylistServer(s, server)
+	})
+
+	return &denylistTest{
+		stdout: stdout,
+		stderr: stderr,
+		addr:   common.GetAddr(addr),
+		server: server,
+		client: client,
+	}
+}
+
+type fakeDenylistServer struct {
+	denylist.UnimplementedDenylistServer
+
+	patterns []string
+}
+
+func (f *fakeDenylistServer) ListPatterns(ctx context.Context, req *denylist.ListPatternsRequest) (*denylist.ListPatternsResponse, error) {
+	return &denylist.ListPatternsResponse{Patterns: f.patterns}, nil
+}
+
+func (f *fakeDenylistServer) AddPattern(ctx context.Context, req *denylist.AddPatternRequest) (*denylist.AddPatternResponse, error) {
+	if req.Pattern == "[" {
+		return nil, status.Error(codes.InvalidArgument, "invalid pattern")
+	}
+	f.patterns = append(f.patterns, req.Pattern)
+	return &denylist.AddPatternResponse{Patterns: f.patterns}, nil
+}
+
+func (f *fakeDenylistServer) RemovePattern(ctx context.Context, req *denylist.RemovePatternRequest) (*denylist.RemovePatternResponse, error) {
+	for i, pattern := range f.patterns {
+		if pattern == req.Pattern {
+			f.patterns = append(f.patterns[:i], f.patterns[i+1:]...)
+			return &denylist.RemovePatternResponse{Patterns: f.patterns}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "pattern not found")
+}
@@ -13,7 +13,10 @@ import (
 	"golang.org/x/net/context"
 )
 
-type countCommand struct{}
+type countCommand struct {
+	// Output format, either pretty or json
+	output string
+}
 
 // NewCountCommand creates a new "count" subcommand for "entry" command.
 func NewCountCommand() cli.Command {
@@ -36,12 +39,20 @@ func (countCommand) Synopsis() string {
 
 // Run counts attested entries
 func (c *countCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
+
 	entryClient := serverClient.NewEntryClient()
 	countResponse, err := entryClient.CountEntries(ctx, &entryv1.CountEntriesRequest{})
 	if err != nil {
 		return err
 	}
 
+	if c.output == util.OutputJSON {
+		return util.PrintJSON(env, countResponse)
+	}
+
 	count := int(countResponse.Count)
 	msg := fmt.Sprintf("%d registration ", count)
 	msg = util.Pluralizer(msg, "entry", "entries", count)
@@ -51,4 +62,5 @@ func (c *countCommand) Run(ctx context.Context, env *common_cli.Env, serverClien
 }
 
 func (c *countCommand) AppendFlags(fs *flag.FlagSet) {
+	util.AddOutputFlag(fs, &c.output)
 }
@@ -14,7 +14,7 @@ func TestCountHelp(t *testing.T) {
 	test := setupTest(t, NewCountCommandWithEnv)
 	test.client.Help()
 
-	require.Equal(t, `Usage of entry count:`+common.AddrUsage, test.stderr.String())
+	require.Equal(t, `Usage of entry count:`+common.AddrOutputUsage, test.stderr.String())
 }
 
 func TestCountSynopsis(t *testing.T) {
@@ -56,6 +56,20 @@ func TestCount(t *testing.T) {
 			fakeCountResp: fakeResp0,
 			expOut:        "0 registration entries\n",
 		},
+		{
+			name:          "JSON output",
+			args:          []string{"-output", "json"},
+			fakeCountResp: fakeResp2,
+			expOut: `{
+  "count": 2
+}
+`,
+		},
+		{
+			name:   "Invalid output format",
+			args:   []string{"-output", "yaml"},
+			expErr: "Error: invalid output format \"yaml\": expected \"pretty\" or \"json\"\n",
+		},
 		{
 			name:      "Server error",
 			serverErr: status.Error(codes.Internal, "internal server error"),
@@ -295,6 +295,7 @@ Entry ID         : (none)
 SPIFFE ID        : spiffe://example.org/already-exist
 Parent ID        : spiffe://example.org/spire/server
 Revision         : 0
+Node alias       : true
 TTL              : default
 Selector         : unix:uid:1
 
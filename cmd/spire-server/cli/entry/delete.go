@@ -26,6 +26,9 @@ func newDeleteCommand(env *common_cli.Env) cli.Command {
 type deleteCommand struct {
 	// ID of the record to delete
 	entryID string
+
+	// Path to a file containing the registration entries to delete
+	path string
 }
 
 func (*deleteCommand) Name() string {
@@ -38,6 +41,7 @@ func (*deleteCommand) Synopsis() string {
 
 func (c *deleteCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the record to delete")
+	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). The entries are deleted by their entry ID. If set to '-', read the JSON from stdin.")
 }
 
 func (c *deleteCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
@@ -45,6 +49,10 @@ func (c *deleteCommand) Run(ctx context.Context, env *common_cli.Env, serverClie
 		return err
 	}
 
+	if c.path != "" {
+		return c.deleteFromFile(ctx, env, serverClient)
+	}
+
 	req := &entryv1.BatchDeleteEntryRequest{Ids: []string{c.entryID}}
 	resp, err := serverClient.NewEntryClient().BatchDeleteEntry(ctx, req)
 	if err != nil {
@@ -61,8 +69,55 @@ func (c *deleteCommand) Run(ctx context.Context, env *common_cli.Env, serverClie
 	}
 }
 
+// deleteFromFile deletes the entries in the data file in a single batch,
+// reporting the result for each one of them.
+func (c *deleteCommand) deleteFromFile(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	entries, err := parseFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Id == "" {
+			return errors.New("an entry ID is required for every entry in the data file")
+		}
+		ids = append(ids, e.Id)
+	}
+
+	resp, err := serverClient.NewEntryClient().BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, r := range resp.Results {
+		if r.Status.Code == int32(codes.OK) {
+			env.Printf("Deleted entry with ID: %s\n", r.Id)
+			continue
+		}
+		failed = true
+		env.ErrPrintf("Failed to delete entry with ID %s (code: %s, msg: %q)\n",
+			r.Id,
+			codes.Code(r.Status.Code),
+			r.Status.Message)
+	}
+
+	if failed {
+		return errors.New("failed to delete one or more entries")
+	}
+	return nil
+}
+
 // Perform basic validation.
 func (c *deleteCommand) validate() error {
+	if c.path != "" {
+		if c.entryID != "" {
+			return errors.New("the -entryID flag can't be combined with -data")
+		}
+		return nil
+	}
+
 	if c.entryID == "" {
 		return errors.New("an entry ID is required")
 	}
@@ -16,6 +16,8 @@ func TestDeleteHelp(t *testing.T) {
 	test.client.Help()
 
 	require.Equal(t, `Usage of entry delete:
+  -data string
+    	Path to a file containing registration JSON (optional). The entries are deleted by their entry ID. If set to '-', read the JSON from stdin.
   -entryID string
     	The Registration Entry ID of the record to delete`+common.AddrUsage, test.stderr.String())
 }
@@ -50,6 +52,22 @@ func TestDelete(t *testing.T) {
 		},
 	}
 
+	fileIDs := []string{"entry-id-1", "entry-id-2", "entry-id-3"}
+	fakeRespFile := &entryv1.BatchDeleteEntryResponse{
+		Results: []*entryv1.BatchDeleteEntryResponse_Result{
+			{Id: "entry-id-1", Status: &types.Status{Code: int32(codes.OK), Message: "OK"}},
+			{Id: "entry-id-2", Status: &types.Status{Code: int32(codes.NotFound), Message: "entry not found"}},
+			{Id: "entry-id-3", Status: &types.Status{Code: int32(codes.OK), Message: "OK"}},
+		},
+	}
+	fakeRespFileOK := &entryv1.BatchDeleteEntryResponse{
+		Results: []*entryv1.BatchDeleteEntryResponse_Result{
+			{Id: "entry-id-1", Status: &types.Status{Code: int32(codes.OK), Message: "OK"}},
+			{Id: "entry-id-2", Status: &types.Status{Code: int32(codes.OK), Message: "OK"}},
+			{Id: "entry-id-3", Status: &types.Status{Code: int32(codes.OK), Message: "OK"}},
+		},
+	}
+
 	for _, tt := range []struct {
 		name string
 		args []string
@@ -86,6 +104,35 @@ func TestDelete(t *testing.T) {
 			fakeResp: fakeRespOK,
 			expOut:   "Deleted entry with ID: entry-id\n",
 		},
+		{
+			name:   "Entry ID and data file",
+			args:   []string{"-entryID", "entry-id", "-data", "../../../../test/fixture/registration/good-for-update.json"},
+			expErr: "Error: the -entryID flag can't be combined with -data\n",
+		},
+		{
+			name:   "Data file without entry IDs",
+			args:   []string{"-data", "../../../../test/fixture/registration/good.json"},
+			expErr: "Error: an entry ID is required for every entry in the data file\n",
+		},
+		{
+			name:     "Delete from data file with failures",
+			args:     []string{"-data", "../../../../test/fixture/registration/good-for-update.json"},
+			expReq:   &entryv1.BatchDeleteEntryRequest{Ids: fileIDs},
+			fakeResp: fakeRespFile,
+			expErr: `Failed to delete entry with ID entry-id-2 (code: NotFound, msg: "entry not found")
+Error: failed to delete one or more entries
+`,
+		},
+		{
+			name:     "Delete from data file succeeds",
+			args:     []string{"-data", "../../../../test/fixture/registration/good-for-update.json"},
+			expReq:   &entryv1.BatchDeleteEntryRequest{Ids: fileIDs},
+			fakeResp: fakeRespFileOK,
+			expOut: `Deleted entry with ID: entry-id-1
+Deleted entry with ID: entry-id-2
+Deleted entry with ID: entry-id-3
+`,
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,346 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"google.golang.org/grpc/codes"
+
+	"golang.org/x/net/context"
+)
+
+// NewDiffCommand creates a new "diff" subcommand for "entry" command.
+func NewDiffCommand() cli.Command {
+	return newDiffCommand(common_cli.DefaultEnv)
+}
+
+func newDiffCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(diffCommand))
+}
+
+type diffCommand struct {
+	// Path to the data file holding the desired registration entries
+	path string
+
+	// Only entries whose SPIFFE ID starts with this prefix are reconciled
+	spiffeIDPrefix string
+
+	// Whether or not the changes are applied
+	apply bool
+}
+
+// entryChanges holds the changes needed to reconcile the registration
+// entries in the server with the desired ones
+type entryChanges struct {
+	create []*types.Entry
+	update []entryUpdate
+	delete []*types.Entry
+}
+
+type entryUpdate struct {
+	entry  *types.Entry
+	fields []string
+}
+
+func (*diffCommand) Name() string {
+	return "entry diff"
+}
+
+func (*diffCommand) Synopsis() string {
+	return "Compares registration entries in a data file with the ones in the server, optionally applying the changes"
+}
+
+func (c *diffCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.path, "data", "", "Path to a file containing the desired registration JSON. If set to '-', read the JSON from stdin.")
+	f.StringVar(&c.spiffeIDPrefix, "spiffeIDPrefix", "", "Only reconcile entries whose SPIFFE ID starts with this prefix. Entries in the server outside of the prefix are left untouched")
+	f.BoolVar(&c.apply, "apply", false, "If set, the entries are created, updated and deleted in the server so they match the data file")
+}
+
+func (c *diffCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.path == "" {
+		return errors.New("a data file is required")
+	}
+
+	desired, err := parseFile(c.path)
+	if err != nil {
+		return err
+	}
+	for _, e := range desired {
+		if !c.inScope(e) {
+			return fmt.Errorf("entry with SPIFFE ID %q does not match the SPIFFE ID prefix %q", protoToIDString(e.SpiffeId), c.spiffeIDPrefix)
+		}
+	}
+
+	client := serverClient.NewEntryClient()
+	current, err := c.fetchEntries(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	changes := diffEntries(desired, current)
+	printChanges(changes, env)
+
+	if !c.apply {
+		return nil
+	}
+	return applyChanges(ctx, client, changes, env)
+}
+
+// fetchEntries lists the entries in the server that are in scope
+func (c *diffCommand) fetchEntries(ctx context.Context, client entryv1.EntryClient) ([]*types.Entry, error) {
+	pageToken := ""
+	var entries []*types.Entry
+
+	for {
+		resp, err := client.ListEntries(ctx, &entryv1.ListEntriesRequest{
+			PageSize:  listEntriesRequestPageSize,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching entries: %w", err)
+		}
+		for _, e := range resp.Entries {
+			if c.inScope(e) {
+				entries = append(entries, e)
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *diffCommand) inScope(e *types.Entry) bool {
+	if c.spiffeIDPrefix == "" {
+		return true
+	}
+	id, err := idutil.IDFromProto(e.SpiffeId)
+	return err == nil && strings.HasPrefix(id.String(), c.spiffeIDPrefix)
+}
+
+// diffEntries computes the changes needed for the current entries to match
+// the desired ones. Desired entries are matched to current entries by entry
+// ID, if set, or else by SPIFFE ID, parent ID and selectors.
+func diffEntries(desired, current []*types.Entry) *entryChanges {
+	byID := make(map[string]*types.Entry)
+	byKey := make(map[string]*types.Entry)
+	for _, e := range current {
+		byID[e.Id] = e
+		byKey[entryKey(e)] = e
+	}
+
+	changes := new(entryChanges)
+	matched := make(map[string]bool)
+	for _, d := range desired {
+		c, ok := byID[d.Id]
+		if d.Id == "" || !ok {
+			c, ok = byKey[entryKey(d)]
+		}
+		if !ok || matched[c.Id] {
+			changes.create = append(changes.create, d)
+			continue
+		}
+		matched[c.Id] = true
+
+		if fields := changedFields(d, c); len(fields) > 0 {
+			e := cloneEntry(d)
+			e.Id = c.Id
+			changes.update = append(changes.update, entryUpdate{entry: e, fields: fields})
+		}
+	}
+
+	for _, c := range current {
+		if !matched[c.Id] {
+			changes.delete = append(changes.delete, c)
+		}
+	}
+
+	return changes
+}
+
+// changedFields returns the names of the fields that differ between the
+// desired and the current entry
+func changedFields(desired, current *types.Entry) []string {
+	var fields []string
+	if protoToIDString(desired.SpiffeId) != protoToIDString(current.SpiffeId) {
+		fields = append(fields, "spiffe_id")
+	}
+	if protoToIDString(desired.ParentId) != protoToIDString(current.ParentId) {
+		fields = append(fields, "parent_id")
+	}
+	if !equalStringSets(selectorStrings(desired.Selectors), selectorStrings(current.Selectors)) {
+		fields = append(fields, "selectors")
+	}
+	if desired.Ttl != current.Ttl {
+		fields = append(fields, "ttl")
+	}
+	if !equalStringSets(desired.FederatesWith, current.FederatesWith) {
+		fields = append(fields, "federates_with")
+	}
+	if desired.Admin != current.Admin {
+		fields = append(fields, "admin")
+	}
+	if desired.Downstream != current.Downstream {
+		fields = append(fields, "downstream")
+	}
+	if desired.ExpiresAt != current.ExpiresAt {
+		fields = append(fields, "expires_at")
+	}
+	if !equalStringSets(desired.DnsNames, current.DnsNames) {
+		fields = append(fields, "dns_names")
+	}
+	if desired.StoreSvid != current.StoreSvid {
+		fields = append(fields, "store_svid")
+	}
+	return fields
+}
+
+func printChanges(changes *entryChanges, env *common_cli.Env) {
+	if len(changes.create) > 0 {
+		env.Println(util.Pluralizer(fmt.Sprintf("Found %d ", len(changes.create)), "entry", "entries", len(changes.create)) + " to create")
+		for _, e := range changes.create {
+			printEntry(e, env.Printf)
+		}
+	}
+
+	if len(changes.update) > 0 {
+		env.Println(util.Pluralizer(fmt.Sprintf("Found %d ", len(changes.update)), "entry", "entries", len(changes.update)) + " to update")
+		for _, u := range changes.update {
+			env.Printf("Changed fields   : %s\n", strings.Join(u.fields, ", "))
+			printEntry(u.entry, env.Printf)
+		}
+	}
+
+	if len(changes.delete) > 0 {
+		env.Println(util.Pluralizer(fmt.Sprintf("Found %d ", len(changes.delete)), "entry", "entries", len(changes.delete)) + " to delete")
+		for _, e := range changes.delete {
+			printEntry(e, env.Printf)
+		}
+	}
+
+	env.Printf("%d to create, %d to update, %d to delete\n", len(changes.create), len(changes.update), len(changes.delete))
+}
+
+// applyChanges creates, updates and deletes the entries in the server,
+// reporting the entries that failed to be changed
+func applyChanges(ctx context.Context, client entryv1.EntryClient, changes *entryChanges, env *common_cli.Env) error {
+	failed := false
+
+	if len(changes.create) > 0 {
+		_, failedCreate, err := createEntries(ctx, client, changes.create)
+		if err != nil {
+			return err
+		}
+		for _, r := range failedCreate {
+			failed = true
+			env.ErrPrintf("Failed to create the following entry (code: %s, msg: %q):\n",
+				codes.Code(r.Status.Code),
+				r.Status.Message)
+			printEntry(r.Entry, env.ErrPrintf)
+		}
+	}
+
+	if len(changes.update) > 0 {
+		entries := make([]*types.Entry, 0, len(changes.update))
+		for _, u := range changes.update {
+			entries = append(entries, u.entry)
+		}
+		_, failedUpdate, err := updateEntries(ctx, client, entries, nil)
+		if err != nil {
+			return err
+		}
+		for _, r := range failedUpdate {
+			failed = true
+			env.ErrPrintf("Failed to update the following entry (code: %s, msg: %q):\n",
+				codes.Code(r.Status.Code),
+				r.Status.Message)
+			printEntry(r.Entry, env.ErrPrintf)
+		}
+	}
+
+	if len(changes.delete) > 0 {
+		ids := make([]string, 0, len(changes.delete))
+		for _, e := range changes.delete {
+			ids = append(ids, e.Id)
+		}
+		resp, err := client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+		if err != nil {
+			return err
+		}
+		for _, r := range resp.Results {
+			if r.Status.Code == int32(codes.OK) {
+				continue
+			}
+			failed = true
+			env.ErrPrintf("Failed to delete entry with ID %s (code: %s, msg: %q)\n",
+				r.Id,
+				codes.Code(r.Status.Code),
+				r.Status.Message)
+		}
+	}
+
+	if failed {
+		return errors.New("failed to apply one or more changes")
+	}
+
+	env.Println("Changes applied")
+	return nil
+}
+
+// entryKey identifies an entry by its SPIFFE ID, parent ID and selectors
+func entryKey(e *types.Entry) string {
+	selectors := selectorStrings(e.Selectors)
+	sort.Strings(selectors)
+	return fmt.Sprintf("%s|%s|%s", protoToIDString(e.SpiffeId), protoToIDString(e.ParentId), strings.Join(selectors, ","))
+}
+
+func selectorStrings(selectors []*types.Selector) []string {
+	out := make([]string, 0, len(selectors))
+	for _, s := range selectors {
+		out = append(out, fmt.Sprintf("%s:%s", s.Type, s.Value))
+	}
+	return out
+}
+
+func equalStringSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneEntry(e *types.Entry) *types.Entry {
+	return &types.Entry{
+		Id:            e.Id,
+		SpiffeId:      e.SpiffeId,
+		ParentId:      e.ParentId,
+		Selectors:     e.Selectors,
+		Ttl:           e.Ttl,
+		FederatesWith: e.FederatesWith,
+		Admin:         e.Admin,
+		Downstream:    e.Downstream,
+		ExpiresAt:     e.ExpiresAt,
+		DnsNames:      e.DnsNames,
+		StoreSvid:     e.StoreSvid,
+	}
+}
@@ -0,0 +1,205 @@
+package entry
+
+import (
+	"errors"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDiffHelp(t *testing.T) {
+	test := setupTest(t, newDiffCommand)
+	test.client.Help()
+
+	require.Equal(t, diffUsage, test.stderr.String())
+}
+
+func TestDiffSynopsis(t *testing.T) {
+	test := setupTest(t, newDiffCommand)
+	require.Equal(t, "Compares registration entries in a data file with the ones in the server, optionally applying the changes", test.client.Synopsis())
+}
+
+func TestDiff(t *testing.T) {
+	dataFile := "../../../../test/fixture/registration/good.json"
+
+	desired, err := parseFile(dataFile)
+	require.NoError(t, err)
+	blog, database, storeSVID := desired[0], desired[1], desired[2]
+
+	// The blog entry has a different TTL, the database entry is up to date,
+	// the store SVID entry is missing and the old entry is no longer desired
+	currentBlog := cloneEntry(blog)
+	currentBlog.Id = "blog-id"
+	currentBlog.Ttl = 100
+	currentDatabase := cloneEntry(database)
+	currentDatabase.Id = "database-id"
+	currentStoreSVID := cloneEntry(storeSVID)
+	currentStoreSVID.Id = "storesvid-id"
+	currentOld := &types.Entry{
+		Id:        "old-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/Old"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/join_token/TokenDatabase"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:2222"}},
+		Ttl:       200,
+	}
+	currentOther := &types.Entry{
+		Id:        "other-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "other.org", Path: "/workload"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "other.org", Path: "/agent"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:3333"}},
+	}
+
+	updatedBlog := cloneEntry(blog)
+	updatedBlog.Id = "blog-id"
+
+	listEntriesResp := &entryv1.ListEntriesResponse{
+		Entries: []*types.Entry{currentBlog, currentDatabase, currentOld, currentOther},
+	}
+
+	diffOut := `Found 1 entry to create
+Entry ID         : (none)
+SPIFFE ID        : spiffe://example.org/storesvid
+Parent ID        : spiffe://example.org/spire/agent/join_token/TokenDatabase
+Revision         : 0
+TTL              : 200
+Selector         : type:key1:value
+Selector         : type:key2:value
+StoreSvid        : true
+
+Found 1 entry to update
+Changed fields   : ttl
+Entry ID         : blog-id
+SPIFFE ID        : spiffe://example.org/Blog
+Parent ID        : spiffe://example.org/spire/agent/join_token/TokenBlog
+Revision         : 0
+TTL              : 200
+Selector         : unix:uid:1111
+Admin            : true
+
+Found 1 entry to delete
+Entry ID         : old-id
+SPIFFE ID        : spiffe://example.org/Old
+Parent ID        : spiffe://example.org/spire/agent/join_token/TokenDatabase
+Revision         : 0
+TTL              : 200
+Selector         : unix:uid:2222
+
+1 to create, 1 to update, 1 to delete
+`
+
+	okStatus := &types.Status{Code: int32(codes.OK), Message: "OK"}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		listEntriesResp      *entryv1.ListEntriesResponse
+		expBatchCreateReq    *entryv1.BatchCreateEntryRequest
+		batchCreateResp      *entryv1.BatchCreateEntryResponse
+		expBatchUpdateReq    *entryv1.BatchUpdateEntryRequest
+		batchUpdateResp      *entryv1.BatchUpdateEntryResponse
+		expBatchDeleteReq    *entryv1.BatchDeleteEntryRequest
+		batchDeleteEntryResp *entryv1.BatchDeleteEntryResponse
+		serverErr            error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing data file",
+			expErr: "Error: a data file is required\n",
+		},
+		{
+			name:   "Entries outside of the SPIFFE ID prefix",
+			args:   []string{"-data", dataFile, "-spiffeIDPrefix", "spiffe://example.org/D"},
+			expErr: "Error: entry with SPIFFE ID \"spiffe://example.org/Blog\" does not match the SPIFFE ID prefix \"spiffe://example.org/D\"\n",
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-data", dataFile},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: error fetching entries: rpc error: code = Unknown desc = server-error\n",
+		},
+		{
+			name: "No changes",
+			args: []string{"-data", dataFile, "-spiffeIDPrefix", "spiffe://example.org/"},
+			listEntriesResp: &entryv1.ListEntriesResponse{
+				Entries: []*types.Entry{updatedBlog, currentDatabase, currentStoreSVID, currentOther},
+			},
+			expOut: "0 to create, 0 to update, 0 to delete\n",
+		},
+		{
+			name:            "Diff",
+			args:            []string{"-data", dataFile, "-spiffeIDPrefix", "spiffe://example.org/"},
+			listEntriesResp: listEntriesResp,
+			expOut:          diffOut,
+		},
+		{
+			name:              "Apply",
+			args:              []string{"-data", dataFile, "-spiffeIDPrefix", "spiffe://example.org/", "-apply"},
+			listEntriesResp:   listEntriesResp,
+			expBatchCreateReq: &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{storeSVID}},
+			batchCreateResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{{Status: okStatus, Entry: storeSVID}},
+			},
+			expBatchUpdateReq: &entryv1.BatchUpdateEntryRequest{Entries: []*types.Entry{updatedBlog}},
+			batchUpdateResp: &entryv1.BatchUpdateEntryResponse{
+				Results: []*entryv1.BatchUpdateEntryResponse_Result{{Status: okStatus, Entry: updatedBlog}},
+			},
+			expBatchDeleteReq: &entryv1.BatchDeleteEntryRequest{Ids: []string{"old-id"}},
+			batchDeleteEntryResp: &entryv1.BatchDeleteEntryResponse{
+				Results: []*entryv1.BatchDeleteEntryResponse_Result{{Status: okStatus, Id: "old-id"}},
+			},
+			expOut: diffOut + "Changes applied\n",
+		},
+		{
+			name:              "Apply with failures",
+			args:              []string{"-data", dataFile, "-spiffeIDPrefix", "spiffe://example.org/", "-apply"},
+			listEntriesResp:   listEntriesResp,
+			expBatchCreateReq: &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{storeSVID}},
+			batchCreateResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{{Status: okStatus, Entry: storeSVID}},
+			},
+			expBatchUpdateReq: &entryv1.BatchUpdateEntryRequest{Entries: []*types.Entry{updatedBlog}},
+			batchUpdateResp: &entryv1.BatchUpdateEntryResponse{
+				Results: []*entryv1.BatchUpdateEntryResponse_Result{{Status: okStatus, Entry: updatedBlog}},
+			},
+			expBatchDeleteReq: &entryv1.BatchDeleteEntryRequest{Ids: []string{"old-id"}},
+			batchDeleteEntryResp: &entryv1.BatchDeleteEntryResponse{
+				Results: []*entryv1.BatchDeleteEntryResponse_Result{
+					{Status: &types.Status{Code: int32(codes.NotFound), Message: "entry not found"}, Id: "old-id"},
+				},
+			},
+			expErr: `Failed to delete entry with ID old-id (code: NotFound, msg: "entry not found")
+Error: failed to apply one or more changes
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newDiffCommand)
+			test.server.err = tt.serverErr
+			test.server.expListEntriesReq = &entryv1.ListEntriesRequest{PageSize: listEntriesRequestPageSize}
+			test.server.listEntriesResp = tt.listEntriesResp
+			test.server.expBatchCreateEntryReq = tt.expBatchCreateReq
+			test.server.batchCreateEntryResp = tt.batchCreateResp
+			test.server.expBatchUpdateEntryReq = tt.expBatchUpdateReq
+			test.server.batchUpdateEntryResp = tt.batchUpdateResp
+			test.server.expBatchDeleteEntryReq = tt.expBatchDeleteReq
+			test.server.batchDeleteEntryResp = tt.batchDeleteEntryResp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+
+	"golang.org/x/net/context"
+)
+
+// NewDNSNamesCommand creates a new "dnsnames" subcommand for "entry" command.
+func NewDNSNamesCommand() cli.Command {
+	return newDNSNamesCommand(common_cli.DefaultEnv)
+}
+
+func newDNSNamesCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(dnsNamesCommand))
+}
+
+type dnsNamesCommand struct {
+	// ID of the entry
+	entryID string
+
+	// Patterns of the DNS names agents can request for the entry
+	patterns common_cli.StringsFlag
+
+	// Remove the patterns of the entry
+	clear bool
+}
+
+func (*dnsNamesCommand) Name() string {
+	return "entry dnsnames"
+}
+
+func (*dnsNamesCommand) Synopsis() string {
+	return "Shows or sets the patterns of the DNS names agents can request for a registration entry"
+}
+
+func (c *dnsNamesCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the record")
+	f.Var(&c.patterns, "pattern", "A pattern of the DNS names agents can request, e.g. *.web.example.org. Replaces the current patterns. Can be used more than once")
+	f.BoolVar(&c.clear, "clear", false, "Remove the DNS name patterns of the entry")
+}
+
+func (c *dnsNamesCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	client := serverClient.NewEntryDNSNamesClient()
+	if !c.clear && len(c.patterns) == 0 {
+		resp, err := client.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{
+			EntryId: c.entryID,
+		})
+		if err != nil {
+			return err
+		}
+		printDNSNamePatterns(resp.Patterns, env)
+		return nil
+	}
+
+	resp, err := client.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+		EntryId:  c.entryID,
+		Patterns: c.patterns,
+	})
+	if err != nil {
+		return err
+	}
+	printDNSNamePatterns(resp.Patterns, env)
+	return nil
+}
+
+// Perform basic validation.
+func (c *dnsNamesCommand) validate() error {
+	switch {
+	case c.entryID == "":
+		return errors.New("an entry ID is required")
+	case c.clear && len(c.patterns) > 0:
+		return errors.New("the -pattern flag can't be combined with -clear")
+	}
+	return nil
+}
+
+func printDNSNamePatterns(patterns []string, env *common_cli.Env) {
+	msg := fmt.Sprintf("Found %v DNS name ", len(patterns))
+	msg = util.Pluralizer(msg, "pattern", "patterns", len(patterns))
+
+	env.Println(msg)
+	for _, pattern := range patterns {
+		env.Printf("DNS name pattern : %s\n", pattern)
+	}
+}
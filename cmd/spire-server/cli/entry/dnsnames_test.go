@@ -0,0 +1,143 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSNamesHelp(t *testing.T) {
+	test := setupTest(t, newDNSNamesCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of entry dnsnames:
+  -clear
+    	Remove the DNS name patterns of the entry
+  -entryID string
+    	The Registration Entry ID of the record
+  -pattern value
+    	A pattern of the DNS names agents can request, e.g. *.web.example.org. Replaces the current patterns. Can be used more than once`+common.AddrUsage, test.stderr.String())
+}
+
+func TestDNSNamesSynopsis(t *testing.T) {
+	test := setupTest(t, newDNSNamesCommand)
+	require.Equal(t, "Shows or sets the patterns of the DNS names agents can request for a registration entry", test.client.Synopsis())
+}
+
+func TestDNSNames(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expGetReq *entrydnsnames.GetDNSNamePatternsRequest
+		expSetReq *entrydnsnames.SetDNSNamePatternsRequest
+		patterns  []string
+		serverErr error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Empty entry ID",
+			expErr: "Error: an entry ID is required\n",
+		},
+		{
+			name:   "Pattern and clear",
+			args:   []string{"-entryID", "entry-id", "-pattern", "*.web.example.org", "-clear"},
+			expErr: "Error: the -pattern flag can't be combined with -clear\n",
+		},
+		{
+			name:      "Show patterns",
+			args:      []string{"-entryID", "entry-id"},
+			expGetReq: &entrydnsnames.GetDNSNamePatternsRequest{EntryId: "entry-id"},
+			patterns:  []string{"*.web.example.org", "web.example.org"},
+			expOut: `Found 2 DNS name patterns
+DNS name pattern : *.web.example.org
+DNS name pattern : web.example.org
+`,
+		},
+		{
+			name:      "Show no patterns",
+			args:      []string{"-entryID", "entry-id"},
+			expGetReq: &entrydnsnames.GetDNSNamePatternsRequest{EntryId: "entry-id"},
+			expOut:    "Found 0 DNS name patterns\n",
+		},
+		{
+			name: "Set patterns",
+			args: []string{"-entryID", "entry-id", "-pattern", "*.web.example.org"},
+			expSetReq: &entrydnsnames.SetDNSNamePatternsRequest{
+				EntryId:  "entry-id",
+				Patterns: []string{"*.web.example.org"},
+			},
+			patterns: []string{"*.web.example.org"},
+			expOut: `Found 1 DNS name pattern
+DNS name pattern : *.web.example.org
+`,
+		},
+		{
+			name:      "Clear patterns",
+			args:      []string{"-entryID", "entry-id", "-clear"},
+			expSetReq: &entrydnsnames.SetDNSNamePatternsRequest{EntryId: "entry-id"},
+			expOut:    "Found 0 DNS name patterns\n",
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-entryID", "entry-id"},
+			expGetReq: &entrydnsnames.GetDNSNamePatternsRequest{EntryId: "entry-id"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newDNSNamesCommand)
+			test.dnsNamesServer.err = tt.serverErr
+			test.dnsNamesServer.expGetReq = tt.expGetReq
+			test.dnsNamesServer.expSetReq = tt.expSetReq
+			test.dnsNamesServer.patterns = tt.patterns
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+type fakeEntryDNSNamesServer struct {
+	entrydnsnames.UnimplementedEntryDNSNamesServer
+
+	t   *testing.T
+	err error
+
+	expGetReq *entrydnsnames.GetDNSNamePatternsRequest
+	expSetReq *entrydnsnames.SetDNSNamePatternsRequest
+
+	patterns []string
+}
+
+func (f *fakeEntryDNSNamesServer) GetDNSNamePatterns(ctx context.Context, req *entrydnsnames.GetDNSNamePatternsRequest) (*entrydnsnames.GetDNSNamePatternsResponse, error) {
+	spiretest.AssertProtoEqual(f.t, f.expGetReq, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &entrydnsnames.GetDNSNamePatternsResponse{Patterns: f.patterns}, nil
+}
+
+func (f *fakeEntryDNSNamesServer) SetDNSNamePatterns(ctx context.Context, req *entrydnsnames.SetDNSNamePatternsRequest) (*entrydnsnames.SetDNSNamePatternsResponse, error) {
+	spiretest.AssertProtoEqual(f.t, f.expSetReq, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &entrydnsnames.SetDNSNamePatternsResponse{Patterns: f.patterns}, nil
+}
@@ -0,0 +1,320 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"google.golang.org/grpc/codes"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultSPIFFEIDAnnotation = "spiffe.io/spiffe-id"
+
+	// federatesWithAnnotation is the annotation used by the
+	// k8s-workload-registrar for the trust domains an entry federates with
+	federatesWithAnnotation = "spiffe.io/federatesWith"
+)
+
+// NewImportK8sCommand creates a new "import-k8s" subcommand for "entry" command.
+func NewImportK8sCommand() cli.Command {
+	return newImportK8sCommand(common_cli.DefaultEnv)
+}
+
+func newImportK8sCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(importK8sCommand))
+}
+
+type importK8sCommand struct {
+	// Paths to the manifests to scan. If set to '-', read from stdin.
+	manifests StringsFlag
+
+	// SPIFFE ID of the parent of the created entries
+	parentID string
+
+	// Annotation holding the SPIFFE ID of a workload
+	annotation string
+
+	// Namespace of the workloads that do not specify one
+	namespace string
+
+	// TTL for certificates issued to the workloads
+	ttl int
+
+	// Print the entries instead of creating them
+	dryRun bool
+}
+
+// k8sObject holds the fields of a Kubernetes workload manifest needed to
+// register it. Lists, as output by kubectl, hold the objects in Items.
+type k8sObject struct {
+	Kind     string      `json:"kind"`
+	Metadata k8sMetadata `json:"metadata"`
+	Spec     struct {
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+		Template struct {
+			Metadata k8sMetadata `json:"metadata"`
+			Spec     struct {
+				ServiceAccountName string `json:"serviceAccountName"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Items []k8sObject `json:"items"`
+}
+
+type k8sMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (*importK8sCommand) Name() string {
+	return "entry import-k8s"
+}
+
+func (*importK8sCommand) Synopsis() string {
+	return "Creates registration entries for annotated Kubernetes Deployments and StatefulSets"
+}
+
+func (c *importK8sCommand) AppendFlags(f *flag.FlagSet) {
+	f.Var(&c.manifests, "manifests", "Path to a file containing Kubernetes manifests in YAML or JSON. If set to '-', read from stdin. Can be used more than once")
+	f.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of the parent of the entries, e.g. a node alias for the cluster nodes")
+	f.StringVar(&c.annotation, "annotation", defaultSPIFFEIDAnnotation, "The annotation holding the SPIFFE ID, or SPIFFE ID path, of a workload")
+	f.StringVar(&c.namespace, "namespace", "default", "The namespace of the workloads whose manifests do not specify one")
+	f.IntVar(&c.ttl, "ttl", 0, "The lifetime, in seconds, for SVIDs issued based on the entries")
+	f.BoolVar(&c.dryRun, "dryRun", false, "Print the entries that would be created without creating them")
+}
+
+func (c *importK8sCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	parentID, err := spiffeid.FromString(c.parentID)
+	if err != nil {
+		return fmt.Errorf("invalid parent ID: %w", err)
+	}
+
+	var objects []k8sObject
+	for _, path := range c.manifests {
+		parsed, err := readK8sManifests(env.Stdin, path)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, parsed...)
+	}
+
+	var entries []*types.Entry
+	for _, object := range objects {
+		entry, err := c.entryFromObject(parentID, object)
+		if err != nil {
+			return fmt.Errorf("%s %s/%s: %w", object.Kind, c.objectNamespace(object), object.Metadata.Name, err)
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return env.Println("No annotated Deployments or StatefulSets found")
+	}
+
+	if c.dryRun {
+		for _, entry := range entries {
+			printEntry(entry, env.Printf)
+		}
+		return nil
+	}
+
+	succeeded, failed, err := createEntries(ctx, serverClient.NewEntryClient(), entries)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range succeeded {
+		printEntry(r.Entry, env.Printf)
+	}
+
+	// Entries that are already registered are not an error, so the command
+	// can be run again as new workloads are annotated.
+	var errored int
+	for _, r := range failed {
+		if codes.Code(r.Status.Code) == codes.AlreadyExists {
+			env.Printf("Entry already exists for SPIFFE ID %s\n\n", protoToIDString(r.Entry.SpiffeId))
+			continue
+		}
+		errored++
+		env.ErrPrintf("Failed to create the following entry (code: %s, msg: %q):\n",
+			codes.Code(r.Status.Code),
+			r.Status.Message)
+		printEntry(r.Entry, env.ErrPrintf)
+	}
+
+	if errored > 0 {
+		return errors.New("failed to create one or more entries")
+	}
+	return nil
+}
+
+func (c *importK8sCommand) validate() error {
+	switch {
+	case len(c.manifests) == 0:
+		return errors.New("at least one manifests file is required")
+	case c.parentID == "":
+		return errors.New("a parent ID is required")
+	case c.annotation == "":
+		return errors.New("an annotation is required")
+	case c.ttl < 0:
+		return errors.New("a positive TTL is required")
+	}
+	return nil
+}
+
+// entryFromObject returns the registration entry of an annotated Deployment
+// or StatefulSet. Other objects, and workloads without the annotation, are
+// skipped by returning a nil entry. The entry selects the pods of the
+// workload by namespace, service account and the labels of the workload
+// selector, as attested by the k8s workload attestor.
+func (c *importK8sCommand) entryFromObject(parentID spiffeid.ID, object k8sObject) (*types.Entry, error) {
+	if object.Kind != "Deployment" && object.Kind != "StatefulSet" {
+		return nil, nil
+	}
+
+	// The annotation is looked up on the pods first, as the
+	// k8s-workload-registrar does, and then on the workload itself.
+	annotations := object.Spec.Template.Metadata.Annotations
+	value, ok := annotations[c.annotation]
+	if !ok {
+		annotations = object.Metadata.Annotations
+		value, ok = annotations[c.annotation]
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	spiffeID, err := annotationSPIFFEID(parentID.TrustDomain(), value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(object.Spec.Selector.MatchLabels) == 0 {
+		return nil, errors.New("workload selector has no matchLabels")
+	}
+
+	serviceAccount := object.Spec.Template.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+
+	selectors := []*types.Selector{
+		{Type: "k8s", Value: "ns:" + c.objectNamespace(object)},
+		{Type: "k8s", Value: "sa:" + serviceAccount},
+	}
+	labels := make([]string, 0, len(object.Spec.Selector.MatchLabels))
+	for label := range object.Spec.Selector.MatchLabels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		selectors = append(selectors, &types.Selector{
+			Type:  "k8s",
+			Value: fmt.Sprintf("pod-label:%s:%s", label, object.Spec.Selector.MatchLabels[label]),
+		})
+	}
+
+	var federatesWith []string
+	if value := annotations[federatesWithAnnotation]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			td, err := spiffeid.TrustDomainFromString(strings.TrimSpace(name))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: %w", federatesWithAnnotation, err)
+			}
+			federatesWith = append(federatesWith, td.IDString())
+		}
+	}
+
+	return &types.Entry{
+		ParentId: &types.SPIFFEID{
+			TrustDomain: parentID.TrustDomain().String(),
+			Path:        parentID.Path(),
+		},
+		SpiffeId: &types.SPIFFEID{
+			TrustDomain: spiffeID.TrustDomain().String(),
+			Path:        spiffeID.Path(),
+		},
+		Selectors:     selectors,
+		FederatesWith: federatesWith,
+		Ttl:           int32(c.ttl),
+	}, nil
+}
+
+func (c *importK8sCommand) objectNamespace(object k8sObject) string {
+	if object.Metadata.Namespace != "" {
+		return object.Metadata.Namespace
+	}
+	return c.namespace
+}
+
+// annotationSPIFFEID returns the SPIFFE ID in the annotation value, which is
+// either a SPIFFE ID or, as with the k8s-workload-registrar, a path in the
+// trust domain.
+func annotationSPIFFEID(td spiffeid.TrustDomain, value string) (spiffeid.ID, error) {
+	if strings.HasPrefix(value, "spiffe://") {
+		id, err := spiffeid.FromString(value)
+		if err != nil {
+			return spiffeid.ID{}, fmt.Errorf("invalid SPIFFE ID annotation: %w", err)
+		}
+		return id, nil
+	}
+	id, err := spiffeid.FromPath(td, "/"+strings.TrimPrefix(value, "/"))
+	if err != nil {
+		return spiffeid.ID{}, fmt.Errorf("invalid SPIFFE ID annotation: %w", err)
+	}
+	return id, nil
+}
+
+// readK8sManifests reads the objects in a file of YAML or JSON manifests.
+// Lists are flattened into the objects they hold.
+func readK8sManifests(stdin io.Reader, path string) ([]k8sObject, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var objects []k8sObject
+	decoder := k8syaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var object k8sObject
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifests %q: %w", path, err)
+		}
+		if strings.HasSuffix(object.Kind, "List") {
+			objects = append(objects, object.Items...)
+			continue
+		}
+		objects = append(objects, object)
+	}
+}
@@ -0,0 +1,259 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const k8sManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: web
+spec:
+  selector:
+    matchLabels:
+      app: frontend
+      tier: web
+  template:
+    metadata:
+      annotations:
+        spiffe.io/spiffe-id: ns/web/frontend
+    spec:
+      serviceAccountName: frontend
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  annotations:
+    spiffe.io/spiffe-id: spiffe://example.org/db
+    spiffe.io/federatesWith: domain1.test, domain2.test
+spec:
+  selector:
+    matchLabels:
+      app: db
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: not-annotated
+spec:
+  selector:
+    matchLabels:
+      app: not-annotated
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  annotations:
+    spiffe.io/spiffe-id: ns/web/frontend
+`
+
+func TestImportK8sHelp(t *testing.T) {
+	test := setupTest(t, newImportK8sCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of entry import-k8s:
+  -annotation string
+    	The annotation holding the SPIFFE ID, or SPIFFE ID path, of a workload (default "spiffe.io/spiffe-id")
+  -dryRun
+    	Print the entries that would be created without creating them
+  -manifests value
+    	Path to a file containing Kubernetes manifests in YAML or JSON. If set to '-', read from stdin. Can be used more than once
+  -namespace string
+    	The namespace of the workloads whose manifests do not specify one (default "default")
+  -parentID string
+    	The SPIFFE ID of the parent of the entries, e.g. a node alias for the cluster nodes`+common.AddrUsage+`  -ttl int
+    	The lifetime, in seconds, for SVIDs issued based on the entries
+`, test.stderr.String())
+}
+
+func TestImportK8sSynopsis(t *testing.T) {
+	test := setupTest(t, newImportK8sCommand)
+	require.Equal(t, "Creates registration entries for annotated Kubernetes Deployments and StatefulSets", test.client.Synopsis())
+}
+
+func TestImportK8s(t *testing.T) {
+	manifestsPath := filepath.Join(t.TempDir(), "manifests.yaml")
+	require.NoError(t, os.WriteFile(manifestsPath, []byte(k8sManifests), 0600))
+
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/k8s-nodes"}
+	frontend := &types.Entry{
+		ParentId: parentID,
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/web/frontend"},
+		Selectors: []*types.Selector{
+			{Type: "k8s", Value: "ns:web"},
+			{Type: "k8s", Value: "sa:frontend"},
+			{Type: "k8s", Value: "pod-label:app:frontend"},
+			{Type: "k8s", Value: "pod-label:tier:web"},
+		},
+		Ttl: 60,
+	}
+	db := &types.Entry{
+		ParentId: parentID,
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/db"},
+		Selectors: []*types.Selector{
+			{Type: "k8s", Value: "ns:prod"},
+			{Type: "k8s", Value: "sa:default"},
+			{Type: "k8s", Value: "pod-label:app:db"},
+		},
+		FederatesWith: []string{"spiffe://domain1.test", "spiffe://domain2.test"},
+		Ttl:           60,
+	}
+
+	frontendOut := `Entry ID         : (none)
+SPIFFE ID        : spiffe://example.org/ns/web/frontend
+Parent ID        : spiffe://example.org/k8s-nodes
+Revision         : 0
+TTL              : 60
+Selector         : k8s:ns:web
+Selector         : k8s:sa:frontend
+Selector         : k8s:pod-label:app:frontend
+Selector         : k8s:pod-label:tier:web
+
+`
+	dbOut := `Entry ID         : (none)
+SPIFFE ID        : spiffe://example.org/db
+Parent ID        : spiffe://example.org/k8s-nodes
+Revision         : 0
+TTL              : 60
+Selector         : k8s:ns:prod
+Selector         : k8s:sa:default
+Selector         : k8s:pod-label:app:db
+FederatesWith    : spiffe://domain1.test
+FederatesWith    : spiffe://domain2.test
+
+`
+
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		stdin string
+
+		expReq *entryv1.BatchCreateEntryRequest
+		resp   *entryv1.BatchCreateEntryResponse
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing manifests",
+			args:   []string{"-parentID", "spiffe://example.org/k8s-nodes"},
+			expErr: "Error: at least one manifests file is required\n",
+		},
+		{
+			name:   "Missing parent ID",
+			args:   []string{"-manifests", manifestsPath},
+			expErr: "Error: a parent ID is required\n",
+		},
+		{
+			name:   "Invalid parent ID",
+			args:   []string{"-manifests", manifestsPath, "-parentID", "k8s-nodes"},
+			expErr: "Error: invalid parent ID: scheme is missing or invalid\n",
+		},
+		{
+			name:   "Invalid manifests",
+			args:   []string{"-manifests", "-", "-parentID", "spiffe://example.org/k8s-nodes"},
+			stdin:  "kind: [",
+			expErr: "Error: failed to parse manifests \"-\": error converting YAML to JSON: yaml: line 1: did not find expected node content\n",
+		},
+		{
+			name: "Invalid annotation",
+			args: []string{"-manifests", "-", "-parentID", "spiffe://example.org/k8s-nodes"},
+			stdin: `{"kind": "Deployment", "metadata": {"name": "bad", "annotations": {"spiffe.io/spiffe-id": "spiffe://"}},
+				"spec": {"selector": {"matchLabels": {"app": "bad"}}}}`,
+			expErr: "Error: Deployment default/bad: invalid SPIFFE ID annotation: trust domain is missing\n",
+		},
+		{
+			name:   "Missing matchLabels",
+			args:   []string{"-manifests", "-", "-parentID", "spiffe://example.org/k8s-nodes"},
+			stdin:  `{"kind": "Deployment", "metadata": {"name": "bad", "annotations": {"spiffe.io/spiffe-id": "bad"}}}`,
+			expErr: "Error: Deployment default/bad: workload selector has no matchLabels\n",
+		},
+		{
+			name:   "No annotated workloads",
+			args:   []string{"-manifests", manifestsPath, "-parentID", "spiffe://example.org/k8s-nodes", "-annotation", "example.org/id"},
+			expOut: "No annotated Deployments or StatefulSets found\n",
+		},
+		{
+			name:   "Dry run",
+			args:   []string{"-manifests", manifestsPath, "-parentID", "spiffe://example.org/k8s-nodes", "-namespace", "prod", "-ttl", "60", "-dryRun"},
+			expOut: frontendOut + dbOut,
+		},
+		{
+			name: "List from stdin",
+			args: []string{"-manifests", "-", "-parentID", "spiffe://example.org/k8s-nodes", "-namespace", "prod", "-ttl", "60", "-dryRun"},
+			stdin: `{"apiVersion": "v1", "kind": "List", "items": [
+				{"kind": "StatefulSet", "metadata": {"name": "db", "annotations": {"spiffe.io/spiffe-id": "spiffe://example.org/db",
+					"spiffe.io/federatesWith": "domain1.test,domain2.test"}},
+				"spec": {"selector": {"matchLabels": {"app": "db"}}}}]}`,
+			expOut: dbOut,
+		},
+		{
+			name:   "Create entries",
+			args:   []string{"-manifests", manifestsPath, "-parentID", "spiffe://example.org/k8s-nodes", "-namespace", "prod", "-ttl", "60"},
+			expReq: &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{frontend, db}},
+			resp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					{
+						Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+						Entry:  frontend,
+					},
+					{
+						Status: &types.Status{Code: int32(codes.AlreadyExists), Message: "similar entry already exists"},
+					},
+				},
+			},
+			expOut: frontendOut + "Entry already exists for SPIFFE ID spiffe://example.org/db\n\n",
+		},
+		{
+			name:   "Create entries fails",
+			args:   []string{"-manifests", manifestsPath, "-parentID", "spiffe://example.org/k8s-nodes", "-namespace", "prod", "-ttl", "60"},
+			expReq: &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{frontend, db}},
+			resp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					{
+						Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+						Entry:  frontend,
+					},
+					{
+						Status: &types.Status{Code: int32(codes.Internal), Message: "failed to create entry"},
+					},
+				},
+			},
+			expOut: frontendOut,
+			expErr: `Failed to create the following entry (code: Internal, msg: "failed to create entry"):
+` + dbOut + "Error: failed to create one or more entries\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newImportK8sCommand)
+			test.stdin.WriteString(tt.stdin)
+			test.server.expBatchCreateEntryReq = tt.expReq
+			test.server.batchCreateEntryResp = tt.resp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				require.Equal(t, tt.expOut, test.stdout.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+
+	"golang.org/x/net/context"
+)
+
+// NewRestoreCommand creates a new "restore" subcommand for "entry" command.
+func NewRestoreCommand() cli.Command {
+	return newRestoreCommand(common_cli.DefaultEnv)
+}
+
+func newRestoreCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(restoreCommand))
+}
+
+type restoreCommand struct {
+	// ID of the deleted entry to restore
+	entryID string
+
+	// List the deleted entries instead of restoring one
+	list bool
+}
+
+func (*restoreCommand) Name() string {
+	return "entry restore"
+}
+
+func (*restoreCommand) Synopsis() string {
+	return "Lists or restores deleted registration entries"
+}
+
+func (c *restoreCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the deleted record to restore")
+	f.BoolVar(&c.list, "list", false, "List the deleted entries that can be restored")
+}
+
+func (c *restoreCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	client := serverClient.NewEntryRestoreClient()
+	if c.list {
+		resp, err := client.ListDeletedEntries(ctx, &entryrestore.ListDeletedEntriesRequest{})
+		if err != nil {
+			return err
+		}
+		printDeletedEntries(resp.Entries, env)
+		return nil
+	}
+
+	resp, err := client.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: c.entryID})
+	if err != nil {
+		return err
+	}
+	return env.Printf("Restored entry with ID: %s\n", resp.Entry.Id)
+}
+
+// Perform basic validation.
+func (c *restoreCommand) validate() error {
+	switch {
+	case c.list && c.entryID != "":
+		return errors.New("the -entryID flag can't be combined with -list")
+	case !c.list && c.entryID == "":
+		return errors.New("an entry ID is required")
+	}
+	return nil
+}
+
+func printDeletedEntries(entries []*entryrestore.DeletedEntry, env *common_cli.Env) {
+	msg := fmt.Sprintf("Found %v deleted ", len(entries))
+	msg = util.Pluralizer(msg, "entry", "entries", len(entries))
+
+	env.Println(msg)
+	for _, d := range entries {
+		env.Printf("Entry ID         : %s\n", printableEntryID(d.Entry.Id))
+		env.Printf("SPIFFE ID        : %s\n", d.Entry.SpiffeId)
+		env.Printf("Parent ID        : %s\n", d.Entry.ParentId)
+		for _, s := range d.Entry.Selectors {
+			env.Printf("Selector         : %s\n", s)
+		}
+		env.Printf("Deleted at       : %s\n", time.Unix(d.DeletedAt, 0).UTC())
+		env.Printf("Restorable until : %s\n", time.Unix(d.ExpiresAt, 0).UTC())
+		env.Printf("\n")
+	}
+}
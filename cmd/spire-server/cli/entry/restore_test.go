@@ -0,0 +1,155 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreHelp(t *testing.T) {
+	test := setupTest(t, newRestoreCommand)
+	test.client.Help()
+
+	require.Equal(t, `Usage of entry restore:
+  -entryID string
+    	The Registration Entry ID of the deleted record to restore
+  -list
+    	List the deleted entries that can be restored`+common.AddrUsage, test.stderr.String())
+}
+
+func TestRestoreSynopsis(t *testing.T) {
+	test := setupTest(t, newRestoreCommand)
+	require.Equal(t, "Lists or restores deleted registration entries", test.client.Synopsis())
+}
+
+func TestRestore(t *testing.T) {
+	deletedAt := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	entry := &entryrestore.Entry{
+		Id:        "entry-id",
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  "spiffe://example.org/parent",
+		Selectors: []string{"unix:uid:1000", "unix:gid:1000"},
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expRestoreReq *entryrestore.RestoreEntryRequest
+		listResp      *entryrestore.ListDeletedEntriesResponse
+		serverErr     error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Empty entry ID",
+			expErr: "Error: an entry ID is required\n",
+		},
+		{
+			name:   "Entry ID and list",
+			args:   []string{"-entryID", "entry-id", "-list"},
+			expErr: "Error: the -entryID flag can't be combined with -list\n",
+		},
+		{
+			name: "List deleted entries",
+			args: []string{"-list"},
+			listResp: &entryrestore.ListDeletedEntriesResponse{
+				Entries: []*entryrestore.DeletedEntry{
+					{
+						Entry:     entry,
+						DeletedAt: deletedAt.Unix(),
+						ExpiresAt: deletedAt.Add(24 * time.Hour).Unix(),
+					},
+				},
+			},
+			expOut: `Found 1 deleted entry
+Entry ID         : entry-id
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/parent
+Selector         : unix:uid:1000
+Selector         : unix:gid:1000
+Deleted at       : 2022-03-01 10:00:00 +0000 UTC
+Restorable until : 2022-03-02 10:00:00 +0000 UTC
+
+`,
+		},
+		{
+			name:     "List no deleted entries",
+			args:     []string{"-list"},
+			listResp: &entryrestore.ListDeletedEntriesResponse{},
+			expOut:   "Found 0 deleted entries\n",
+		},
+		{
+			name:      "List server error",
+			args:      []string{"-list"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+		{
+			name:          "Restore succeeds",
+			args:          []string{"-entryID", "entry-id"},
+			expRestoreReq: &entryrestore.RestoreEntryRequest{Id: "entry-id"},
+			expOut:        "Restored entry with ID: entry-id\n",
+		},
+		{
+			name:          "Restore server error",
+			args:          []string{"-entryID", "entry-id"},
+			expRestoreReq: &entryrestore.RestoreEntryRequest{Id: "entry-id"},
+			serverErr:     errors.New("server-error"),
+			expErr:        "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newRestoreCommand)
+			test.restoreServer.err = tt.serverErr
+			test.restoreServer.expRestoreEntryReq = tt.expRestoreReq
+			test.restoreServer.restoreEntryResp = &entryrestore.RestoreEntryResponse{Entry: entry}
+			test.restoreServer.listDeletedEntriesResp = tt.listResp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+type fakeEntryRestoreServer struct {
+	entryrestore.UnimplementedEntryRestoreServer
+
+	t   *testing.T
+	err error
+
+	expRestoreEntryReq *entryrestore.RestoreEntryRequest
+
+	listDeletedEntriesResp *entryrestore.ListDeletedEntriesResponse
+	restoreEntryResp       *entryrestore.RestoreEntryResponse
+}
+
+func (f *fakeEntryRestoreServer) ListDeletedEntries(ctx context.Context, req *entryrestore.ListDeletedEntriesRequest) (*entryrestore.ListDeletedEntriesResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.listDeletedEntriesResp, nil
+}
+
+func (f *fakeEntryRestoreServer) RestoreEntry(ctx context.Context, req *entryrestore.RestoreEntryRequest) (*entryrestore.RestoreEntryResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.AssertProtoEqual(f.t, f.expRestoreEntryReq, req)
+	return f.restoreEntryResp, nil
+}
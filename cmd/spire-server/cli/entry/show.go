@@ -4,12 +4,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	commonutil "github.com/spiffe/spire/pkg/common/util"
 
 	"golang.org/x/net/context"
@@ -40,17 +44,29 @@ type showCommand struct {
 	// Workload spiffeID
 	spiffeID string
 
+	// SPIFFE ID of the agent to show the authorized entries of
+	agentID string
+
+	// Prefix the workload spiffeID must start with
+	spiffeIDPrefix string
+
 	// List of SPIFFE IDs of trust domains the registration entry is federated with
 	federatesWith StringsFlag
 
 	// Whether or not the entry is for a downstream SPIRE server
 	downstream bool
 
+	// Whether or not to only show node alias entries
+	node bool
+
 	// Match used when filtering by federates with
 	matchFederatesWithOn string
 
 	// Match used when filtering by selectors
 	matchSelectorsOn string
+
+	// Output format, either pretty or json
+	output string
 }
 
 func (c *showCommand) Name() string {
@@ -65,11 +81,15 @@ func (c *showCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "The Entry ID of the records to show")
 	f.StringVar(&c.parentID, "parentID", "", "The Parent ID of the records to show")
 	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the records to show")
+	f.StringVar(&c.agentID, "agentID", "", "The SPIFFE ID of an agent. Shows the entries the agent is authorized for, including the ones obtained through node aliases")
+	f.StringVar(&c.spiffeIDPrefix, "spiffeIDPrefix", "", "Only show records whose SPIFFE ID starts with this prefix")
 	f.BoolVar(&c.downstream, "downstream", false, "A boolean value that, when set, indicates that the entry describes a downstream SPIRE server")
+	f.BoolVar(&c.node, "node", false, "If set, only show node alias entries, which are applied to matching nodes rather than workloads")
 	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
 	f.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain an entry is federate with. Can be used more than once")
 	f.StringVar(&c.matchFederatesWithOn, "matchFederatesWithOn", "superset", "The match mode used when filtering by federates with. Options: exact, any, superset and subset")
 	f.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset and subset")
+	util.AddOutputFlag(f, &c.output)
 }
 
 // Run executes all logic associated with a single invocation of the
@@ -79,12 +99,21 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 		return err
 	}
 
-	entries, err := c.fetchEntries(ctx, serverClient.NewEntryClient())
+	var entries []*types.Entry
+	var err error
+	if c.agentID != "" {
+		entries, err = c.fetchAuthorizedEntries(ctx, serverClient.NewAgentClient(), serverClient.NewEntryClient())
+	} else {
+		entries, err = c.fetchEntries(ctx, serverClient.NewEntryClient())
+	}
 	if err != nil {
 		return err
 	}
 
 	commonutil.SortTypesEntries(entries)
+	if c.output == util.OutputJSON {
+		return util.PrintJSON(env, &entryv1.ListEntriesResponse{Entries: entries})
+	}
 	printEntries(entries, env)
 	return nil
 }
@@ -93,11 +122,26 @@ func (c *showCommand) Run(ctx context.Context, env *common_cli.Env, serverClient
 func (c *showCommand) validate() error {
 	// If entryID is given, it should be the only constraint
 	if c.entryID != "" {
-		if c.parentID != "" || c.spiffeID != "" || len(c.selectors) > 0 {
+		if c.parentID != "" || c.spiffeID != "" || c.spiffeIDPrefix != "" || len(c.selectors) > 0 || c.node {
 			return errors.New("the -entryID flag can't be combined with others")
 		}
 	}
 
+	// If agentID is given, it should be the only constraint
+	if c.agentID != "" {
+		if c.entryID != "" || c.parentID != "" || c.spiffeID != "" || c.spiffeIDPrefix != "" || len(c.selectors) > 0 || len(c.federatesWith) > 0 || c.downstream || c.node {
+			return errors.New("the -agentID flag can't be combined with others")
+		}
+	}
+
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
+
+	if c.spiffeID != "" && c.spiffeIDPrefix != "" {
+		return errors.New("the -spiffeID and -spiffeIDPrefix flags can't be combined")
+	}
+
 	return nil
 }
 
@@ -160,6 +204,84 @@ func (c *showCommand) fetchEntries(ctx context.Context, client entryv1.EntryClie
 		}
 	}
 
+	entries, err := listEntries(ctx, client, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.filterNodeAliases(c.filterBySPIFFEIDPrefix(entries)), nil
+}
+
+// fetchAuthorizedEntries returns the entries the configured agent is
+// authorized for, resolved the same way the server does: the node aliases
+// whose selectors are a subset of the agent selectors, the entries parented by
+// the agent or by those aliases, and every entry descending from them.
+func (c *showCommand) fetchAuthorizedEntries(ctx context.Context, agentClient agentv1.AgentClient, entryClient entryv1.EntryClient) ([]*types.Entry, error) {
+	id, err := spiffeid.FromString(c.agentID)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing agent ID %q: %w", c.agentID, err)
+	}
+	serverID, err := idutil.ServerID(id.TrustDomain())
+	if err != nil {
+		return nil, err
+	}
+	agentID := &types.SPIFFEID{TrustDomain: id.TrustDomain().String(), Path: id.Path()}
+
+	agent, err := agentClient.GetAgent(ctx, &agentv1.GetAgentRequest{
+		Id:         agentID,
+		OutputMask: &types.AgentMask{Selectors: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching agent %q: %w", c.agentID, err)
+	}
+
+	var entries []*types.Entry
+	parentIDs := []*types.SPIFFEID{agentID}
+	if len(agent.Selectors) > 0 {
+		aliases, err := listEntries(ctx, entryClient, &entryv1.ListEntriesRequest_Filter{
+			ByParentId: &types.SPIFFEID{TrustDomain: serverID.TrustDomain().String(), Path: serverID.Path()},
+			BySelectors: &types.SelectorMatch{
+				Selectors: agent.Selectors,
+				Match:     types.SelectorMatch_MATCH_SUBSET,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, alias := range aliases {
+			entries = append(entries, alias)
+			parentIDs = append(parentIDs, alias.SpiffeId)
+		}
+	}
+
+	crawled := make(map[string]bool)
+	for len(parentIDs) > 0 {
+		parentID := parentIDs[0]
+		parentIDs = parentIDs[1:]
+
+		key := protoToIDString(parentID)
+		if crawled[key] {
+			continue
+		}
+		crawled[key] = true
+
+		children, err := listEntries(ctx, entryClient, &entryv1.ListEntriesRequest_Filter{
+			ByParentId: parentID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			entries = append(entries, child)
+			parentIDs = append(parentIDs, child.SpiffeId)
+		}
+	}
+
+	return entries, nil
+}
+
+// listEntries lists all the entries matching the filter, going through every
+// page of results.
+func listEntries(ctx context.Context, client entryv1.EntryClient, filter *entryv1.ListEntriesRequest_Filter) ([]*types.Entry, error) {
 	pageToken := ""
 	var entries []*types.Entry
 
@@ -181,6 +303,41 @@ func (c *showCommand) fetchEntries(ctx context.Context, client entryv1.EntryClie
 	return entries, nil
 }
 
+// filterBySPIFFEIDPrefix drops the entries whose SPIFFE ID does not start with
+// the configured prefix. The Entry API has no prefix filter, so this is done
+// client side.
+func (c *showCommand) filterBySPIFFEIDPrefix(entries []*types.Entry) []*types.Entry {
+	if c.spiffeIDPrefix == "" {
+		return entries
+	}
+
+	var filtered []*types.Entry
+	for _, e := range entries {
+		id, err := idutil.IDFromProto(e.SpiffeId)
+		if err == nil && strings.HasPrefix(id.String(), c.spiffeIDPrefix) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// filterNodeAliases drops the entries that are not node aliases when only
+// node aliases are requested. The Entry API has no such filter, so this is
+// done client side.
+func (c *showCommand) filterNodeAliases(entries []*types.Entry) []*types.Entry {
+	if !c.node {
+		return entries
+	}
+
+	var filtered []*types.Entry
+	for _, e := range entries {
+		if isNodeAlias(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
 // fetchByEntryID uses the configured EntryID to fetch the appropriate registration entry
 func (c *showCommand) fetchByEntryID(ctx context.Context, id string, client entryv1.EntryClient) (*types.Entry, error) {
 	entry, err := client.GetEntry(ctx, &entryv1.GetEntryRequest{Id: id})
@@ -5,11 +5,14 @@ import (
 	"testing"
 	"time"
 
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestShowHelp(t *testing.T) {
@@ -42,6 +45,15 @@ func TestShow(t *testing.T) {
 		Entries: getEntries(3)[2:],
 	}
 
+	fakeRespWithNodeAlias := &entryv1.ListEntriesResponse{
+		Entries: append(getEntries(1), &types.Entry{
+			Id:        "00000000-0000-0000-0000-000000000004",
+			ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/cluster"},
+			Selectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:demo"}},
+		}),
+	}
+
 	for _, tt := range []struct {
 		name string
 		args []string
@@ -84,6 +96,25 @@ func TestShow(t *testing.T) {
 			serverErr: status.Error(codes.NotFound, "no such registration entry"),
 			expErr:    "Error: error fetching entry ID non-existent-id: rpc error: code = NotFound desc = no such registration entry\n",
 		},
+		{
+			name: "List node aliases",
+			args: []string{"-node"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter:   &entryv1.ListEntriesRequest_Filter{},
+			},
+			fakeListResp: fakeRespWithNodeAlias,
+			expOut: `Found 1 entry
+Entry ID         : 00000000-0000-0000-0000-000000000004
+SPIFFE ID        : spiffe://example.org/cluster
+Parent ID        : spiffe://example.org/spire/server
+Revision         : 0
+Node alias       : true
+TTL              : default
+Selector         : k8s_psat:cluster:demo
+
+`,
+		},
 		{
 			name:   "List by entry ID and other fields",
 			args:   []string{"-entryID", "entry-id", "-spiffeID", "spiffe://example.org/workload"},
@@ -124,6 +155,24 @@ func TestShow(t *testing.T) {
 				getPrintedEntry(2),
 			),
 		},
+		{
+			name: "List by SPIFFE ID prefix",
+			args: []string{"-spiffeIDPrefix", "spiffe://example.org/daugh"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter:   &entryv1.ListEntriesRequest_Filter{},
+			},
+			fakeListResp: fakeRespAll,
+			expOut: fmt.Sprintf("Found 2 entries\n%s%s",
+				getPrintedEntry(1),
+				getPrintedEntry(2),
+			),
+		},
+		{
+			name:   "List by SPIFFE ID and SPIFFE ID prefix",
+			args:   []string{"-spiffeID", "spiffe://example.org/daughter", "-spiffeIDPrefix", "spiffe://example.org/"},
+			expErr: "Error: the -spiffeID and -spiffeIDPrefix flags can't be combined\n",
+		},
 		{
 			name:   "List by SPIFFE ID using invalid ID",
 			args:   []string{"-spiffeID", "invalid-id"},
@@ -364,6 +413,137 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShowByAgentID(t *testing.T) {
+	agentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/x509pop/node1"}
+	serverID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"}
+	agentSelectors := []*types.Selector{
+		{Type: "x509pop", Value: "ca:abc"},
+		{Type: "x509pop", Value: "subject:cn:node1"},
+	}
+
+	alias := &types.Entry{
+		Id:        "alias",
+		ParentId:  serverID,
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/cluster"},
+		Selectors: agentSelectors[:1],
+	}
+	entries := []*types.Entry{
+		{
+			Id:        "workload-a",
+			ParentId:  agentID,
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-a"},
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+		{
+			Id:        "workload-b",
+			ParentId:  alias.SpiffeId,
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-b"},
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+		},
+		{
+			Id:        "nested",
+			ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-b"},
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/nested"},
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+		},
+		{
+			Id:        "other-agent",
+			ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/x509pop/node2"},
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/other"},
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1003"}},
+		},
+	}
+
+	listEntries := func(req *entryv1.ListEntriesRequest) (*entryv1.ListEntriesResponse, error) {
+		if req.Filter.BySelectors != nil {
+			spiretest.AssertProtoEqual(t, &entryv1.ListEntriesRequest_Filter{
+				ByParentId: serverID,
+				BySelectors: &types.SelectorMatch{
+					Selectors: agentSelectors,
+					Match:     types.SelectorMatch_MATCH_SUBSET,
+				},
+			}, req.Filter)
+			return &entryv1.ListEntriesResponse{Entries: []*types.Entry{alias}}, nil
+		}
+		resp := &entryv1.ListEntriesResponse{}
+		for _, entry := range entries {
+			if proto.Equal(entry.ParentId, req.Filter.ByParentId) {
+				resp.Entries = append(resp.Entries, entry)
+			}
+		}
+		return resp, nil
+	}
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		agent     *types.Agent
+		agentErr  error
+		expIDs    []string
+		expNotIDs []string
+		expErr    string
+	}{
+		{
+			name:      "agent with node alias",
+			args:      []string{"-agentID", "spiffe://example.org/spire/agent/x509pop/node1"},
+			agent:     &types.Agent{Id: agentID, Selectors: agentSelectors},
+			expIDs:    []string{"alias", "workload-a", "workload-b", "nested"},
+			expNotIDs: []string{"other-agent"},
+		},
+		{
+			name:      "agent without selectors",
+			args:      []string{"-agentID", "spiffe://example.org/spire/agent/x509pop/node1"},
+			agent:     &types.Agent{Id: agentID},
+			expIDs:    []string{"workload-a"},
+			expNotIDs: []string{"alias", "workload-b", "nested", "other-agent"},
+		},
+		{
+			name:     "agent not found",
+			args:     []string{"-agentID", "spiffe://example.org/spire/agent/x509pop/node1"},
+			agentErr: status.Error(codes.NotFound, "agent not found"),
+			expErr:   "Error: error fetching agent \"spiffe://example.org/spire/agent/x509pop/node1\": rpc error: code = NotFound desc = agent not found\n",
+		},
+		{
+			name:   "invalid agent ID",
+			args:   []string{"-agentID", "invalid-id"},
+			expErr: "Error: error parsing agent ID \"invalid-id\": scheme is missing or invalid\n",
+		},
+		{
+			name:   "agent ID combined with other flags",
+			args:   []string{"-agentID", "spiffe://example.org/spire/agent/x509pop/node1", "-parentID", "spiffe://example.org/father"},
+			expErr: "Error: the -agentID flag can't be combined with others\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newShowCommand)
+			test.server.listEntries = listEntries
+			test.agentServer.err = tt.agentErr
+			test.agentServer.expGetAgentReq = &agentv1.GetAgentRequest{
+				Id:         agentID,
+				OutputMask: &types.AgentMask{Selectors: true},
+			}
+			test.agentServer.getAgentResp = tt.agent
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Contains(t, test.stdout.String(), fmt.Sprintf("Found %d ", len(tt.expIDs)))
+			for _, id := range tt.expIDs {
+				require.Contains(t, test.stdout.String(), fmt.Sprintf("Entry ID         : %s\n", id))
+			}
+			for _, id := range tt.expNotIDs {
+				require.NotContains(t, test.stdout.String(), fmt.Sprintf("Entry ID         : %s\n", id))
+			}
+		})
+	}
+}
+
 // registrationEntries returns `count` registration entry records. At most 4.
 func getEntries(count int) []*types.Entry {
 	selectors := []*types.Selector{
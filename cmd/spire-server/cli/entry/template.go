@@ -0,0 +1,188 @@
+package entry
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+
+	"golang.org/x/net/context"
+)
+
+// NewTemplateCreateCommand creates a new "template create" subcommand for
+// "entry" command.
+func NewTemplateCreateCommand() cli.Command {
+	return newTemplateCreateCommand(common_cli.DefaultEnv)
+}
+
+func newTemplateCreateCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(templateCreateCommand))
+}
+
+// NewTemplateListCommand creates a new "template list" subcommand for "entry"
+// command.
+func NewTemplateListCommand() cli.Command {
+	return newTemplateListCommand(common_cli.DefaultEnv)
+}
+
+func newTemplateListCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(templateListCommand))
+}
+
+// NewTemplateDeleteCommand creates a new "template delete" subcommand for
+// "entry" command.
+func NewTemplateDeleteCommand() cli.Command {
+	return newTemplateDeleteCommand(common_cli.DefaultEnv)
+}
+
+func newTemplateDeleteCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(templateDeleteCommand))
+}
+
+type templateCreateCommand struct {
+	// Selectors an agent must have for the template to be expanded
+	nodeSelectors StringsFlag
+
+	// SPIFFE ID template of the entries
+	spiffeID string
+
+	// Selectors of the entries
+	selectors StringsFlag
+
+	// TTL for x509 SVIDs issued for the entries
+	ttl int
+
+	// DNSNames of the entries
+	dnsNames StringsFlag
+}
+
+func (*templateCreateCommand) Name() string {
+	return "entry template create"
+}
+
+func (*templateCreateCommand) Synopsis() string {
+	return "Creates entry templates"
+}
+
+func (c *templateCreateCommand) AppendFlags(f *flag.FlagSet) {
+	f.Var(&c.nodeSelectors, "nodeSelector", "A colon-delimited type:value selector agents must have for the template to be expanded. Can be used more than once")
+	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID template of the entries")
+	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector of the entries. The value can be a template. Can be used more than once")
+	f.IntVar(&c.ttl, "ttl", 0, "The lifetime, in seconds, for SVIDs issued based on the entries")
+	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on the entries, where appropriate. Can be used more than once")
+}
+
+func (c *templateCreateCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	resp, err := serverClient.NewEntryTemplatesClient().CreateEntryTemplate(ctx, &entrytemplate.CreateEntryTemplateRequest{
+		Template: &entrytemplate.EntryTemplate{
+			NodeSelectors: c.nodeSelectors,
+			SpiffeId:      c.spiffeID,
+			Selectors:     c.selectors,
+			Ttl:           int32(c.ttl),
+			DnsNames:      c.dnsNames,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	env.Println("Entry template created successfully.")
+	printEntryTemplate(resp.Template, env)
+	return nil
+}
+
+// Perform basic validation.
+func (c *templateCreateCommand) validate() error {
+	switch {
+	case len(c.nodeSelectors) < 1:
+		return errors.New("at least one node selector is required")
+	case c.spiffeID == "":
+		return errors.New("a SPIFFE ID is required")
+	case len(c.selectors) < 1:
+		return errors.New("at least one selector is required")
+	case c.ttl < 0:
+		return errors.New("a positive TTL is required")
+	}
+	return nil
+}
+
+type templateListCommand struct{}
+
+func (*templateListCommand) Name() string {
+	return "entry template list"
+}
+
+func (*templateListCommand) Synopsis() string {
+	return "Lists entry templates"
+}
+
+func (*templateListCommand) AppendFlags(*flag.FlagSet) {}
+
+func (*templateListCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	resp, err := serverClient.NewEntryTemplatesClient().ListEntryTemplates(ctx, &entrytemplate.ListEntryTemplatesRequest{})
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Found %v entry ", len(resp.Templates))
+	msg = util.Pluralizer(msg, "template", "templates", len(resp.Templates))
+	env.Println(msg)
+	for _, t := range resp.Templates {
+		printEntryTemplate(t, env)
+	}
+	return nil
+}
+
+type templateDeleteCommand struct {
+	// ID of the entry template to delete
+	id string
+}
+
+func (*templateDeleteCommand) Name() string {
+	return "entry template delete"
+}
+
+func (*templateDeleteCommand) Synopsis() string {
+	return "Deletes entry templates"
+}
+
+func (c *templateDeleteCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.id, "id", "", "The ID of the entry template to delete")
+}
+
+func (c *templateDeleteCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.id == "" {
+		return errors.New("an entry template ID is required")
+	}
+
+	if _, err := serverClient.NewEntryTemplatesClient().DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{Id: c.id}); err != nil {
+		return err
+	}
+	return env.Printf("Deleted entry template with ID: %s\n", c.id)
+}
+
+func printEntryTemplate(t *entrytemplate.EntryTemplate, env *common_cli.Env) {
+	env.Printf("Template ID   : %s\n", t.Id)
+	for _, s := range t.NodeSelectors {
+		env.Printf("Node Selector : %s\n", s)
+	}
+	env.Printf("SPIFFE ID     : %s\n", t.SpiffeId)
+	for _, s := range t.Selectors {
+		env.Printf("Selector      : %s\n", s)
+	}
+	if t.Ttl > 0 {
+		env.Printf("TTL           : %d\n", t.Ttl)
+	}
+	for _, dnsName := range t.DnsNames {
+		env.Printf("DNS name      : %s\n", dnsName)
+	}
+	env.Printf("\n")
+}
@@ -0,0 +1,245 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntryTemplate = &entrytemplate.EntryTemplate{
+	Id:            "template-id",
+	NodeSelectors: []string{"k8s_psat:cluster:prod"},
+	SpiffeId:      "spiffe://example.org/workload{{ .AgentPath }}",
+	Selectors:     []string{"k8s:ns:default", "k8s:sa:default"},
+	Ttl:           60,
+	DnsNames:      []string{"example.org"},
+}
+
+const testEntryTemplateOutput = `Template ID   : template-id
+Node Selector : k8s_psat:cluster:prod
+SPIFFE ID     : spiffe://example.org/workload{{ .AgentPath }}
+Selector      : k8s:ns:default
+Selector      : k8s:sa:default
+TTL           : 60
+DNS name      : example.org
+
+`
+
+func TestTemplateCreateHelp(t *testing.T) {
+	test := setupTest(t, newTemplateCreateCommand)
+	test.client.Help()
+
+	require.Equal(t, templateCreateUsage, test.stderr.String())
+}
+
+func TestTemplateCreateSynopsis(t *testing.T) {
+	test := setupTest(t, newTemplateCreateCommand)
+	require.Equal(t, "Creates entry templates", test.client.Synopsis())
+}
+
+func TestTemplateCreate(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expReq    *entrytemplate.CreateEntryTemplateRequest
+		serverErr error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing node selectors",
+			args:   []string{"-spiffeID", "spiffe://example.org/workload", "-selector", "k8s:ns:default"},
+			expErr: "Error: at least one node selector is required\n",
+		},
+		{
+			name:   "Missing SPIFFE ID",
+			args:   []string{"-nodeSelector", "k8s_psat:cluster:prod", "-selector", "k8s:ns:default"},
+			expErr: "Error: a SPIFFE ID is required\n",
+		},
+		{
+			name:   "Missing selectors",
+			args:   []string{"-nodeSelector", "k8s_psat:cluster:prod", "-spiffeID", "spiffe://example.org/workload"},
+			expErr: "Error: at least one selector is required\n",
+		},
+		{
+			name:   "Negative TTL",
+			args:   []string{"-nodeSelector", "k8s_psat:cluster:prod", "-spiffeID", "spiffe://example.org/workload", "-selector", "k8s:ns:default", "-ttl", "-10"},
+			expErr: "Error: a positive TTL is required\n",
+		},
+		{
+			name: "Create succeeds",
+			args: []string{
+				"-nodeSelector", "k8s_psat:cluster:prod",
+				"-spiffeID", "spiffe://example.org/workload{{ .AgentPath }}",
+				"-selector", "k8s:ns:default",
+				"-selector", "k8s:sa:default",
+				"-ttl", "60",
+				"-dns", "example.org",
+			},
+			expReq: &entrytemplate.CreateEntryTemplateRequest{
+				Template: &entrytemplate.EntryTemplate{
+					NodeSelectors: []string{"k8s_psat:cluster:prod"},
+					SpiffeId:      "spiffe://example.org/workload{{ .AgentPath }}",
+					Selectors:     []string{"k8s:ns:default", "k8s:sa:default"},
+					Ttl:           60,
+					DnsNames:      []string{"example.org"},
+				},
+			},
+			expOut: "Entry template created successfully.\n" + testEntryTemplateOutput,
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-nodeSelector", "k8s_psat:cluster:prod", "-spiffeID", "spiffe://example.org/workload", "-selector", "k8s:ns:default"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newTemplateCreateCommand)
+			test.templateServer.err = tt.serverErr
+			test.templateServer.expCreateEntryTemplateReq = tt.expReq
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+func TestTemplateList(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		templates []*entrytemplate.EntryTemplate
+		serverErr error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:      "List templates",
+			templates: []*entrytemplate.EntryTemplate{testEntryTemplate},
+			expOut:    "Found 1 entry template\n" + testEntryTemplateOutput,
+		},
+		{
+			name:   "No templates",
+			expOut: "Found 0 entry templates\n",
+		},
+		{
+			name:      "Server error",
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newTemplateListCommand)
+			test.templateServer.err = tt.serverErr
+			test.templateServer.templates = tt.templates
+
+			rc := test.client.Run(test.args())
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+func TestTemplateDelete(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		expReq    *entrytemplate.DeleteEntryTemplateRequest
+		serverErr error
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:   "Missing ID",
+			expErr: "Error: an entry template ID is required\n",
+		},
+		{
+			name:   "Delete succeeds",
+			args:   []string{"-id", "template-id"},
+			expReq: &entrytemplate.DeleteEntryTemplateRequest{Id: "template-id"},
+			expOut: "Deleted entry template with ID: template-id\n",
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-id", "template-id"},
+			expReq:    &entrytemplate.DeleteEntryTemplateRequest{Id: "template-id"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newTemplateDeleteCommand)
+			test.templateServer.err = tt.serverErr
+			test.templateServer.expDeleteEntryTemplateReq = tt.expReq
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expOut, test.stdout.String())
+		})
+	}
+}
+
+type fakeEntryTemplatesServer struct {
+	entrytemplate.UnimplementedEntryTemplatesServer
+
+	t   *testing.T
+	err error
+
+	expCreateEntryTemplateReq *entrytemplate.CreateEntryTemplateRequest
+	expDeleteEntryTemplateReq *entrytemplate.DeleteEntryTemplateRequest
+
+	templates []*entrytemplate.EntryTemplate
+}
+
+func (f *fakeEntryTemplatesServer) CreateEntryTemplate(ctx context.Context, req *entrytemplate.CreateEntryTemplateRequest) (*entrytemplate.CreateEntryTemplateResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.AssertProtoEqual(f.t, f.expCreateEntryTemplateReq, req)
+	return &entrytemplate.CreateEntryTemplateResponse{Template: testEntryTemplate}, nil
+}
+
+func (f *fakeEntryTemplatesServer) ListEntryTemplates(ctx context.Context, req *entrytemplate.ListEntryTemplatesRequest) (*entrytemplate.ListEntryTemplatesResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &entrytemplate.ListEntryTemplatesResponse{Templates: f.templates}, nil
+}
+
+func (f *fakeEntryTemplatesServer) DeleteEntryTemplate(ctx context.Context, req *entrytemplate.DeleteEntryTemplateRequest) (*entrytemplate.DeleteEntryTemplateResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.AssertProtoEqual(f.t, f.expDeleteEntryTemplateReq, req)
+	return &entrytemplate.DeleteEntryTemplateResponse{Template: testEntryTemplate}, nil
+}
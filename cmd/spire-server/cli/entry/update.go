@@ -3,8 +3,10 @@ package entry
 import (
 	"errors"
 	"flag"
+	"fmt"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
@@ -50,6 +52,11 @@ type updateCommand struct {
 	// List of SPIFFE IDs of trust domains the registration entry is federated with
 	federatesWith StringsFlag
 
+	// Trust domains to add to or remove from the ones the registration entry
+	// is federated with, leaving the rest unchanged
+	addFederatesWith    StringsFlag
+	removeFederatesWith StringsFlag
+
 	// Whether or not the registration entry is for an "admin" workload
 	admin bool
 
@@ -61,6 +68,12 @@ type updateCommand struct {
 
 	// storeSVID determines if the issued SVID must be stored through an SVIDStore plugin
 	storeSVID bool
+
+	// partial, when set, only updates the fields set through flags
+	partial bool
+
+	// flags is used to determine which flags were set for partial updates
+	flags *flag.FlagSet
 }
 
 func (*updateCommand) Name() string {
@@ -79,11 +92,15 @@ func (c *updateCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.")
 	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
 	f.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain to federate with. Can be used more than once")
+	f.Var(&c.addFederatesWith, "addFederatesWith", "SPIFFE ID of a trust domain to add to the ones the entry federates with, leaving the rest unchanged. Implies -partial. Can be used more than once")
+	f.Var(&c.removeFederatesWith, "removeFederatesWith", "SPIFFE ID of a trust domain to remove from the ones the entry federates with, leaving the rest unchanged. Implies -partial. Can be used more than once")
 	f.BoolVar(&c.admin, "admin", false, "If set, the SPIFFE ID in this entry will be granted access to the SPIRE Server's management APIs")
 	f.BoolVar(&c.downstream, "downstream", false, "A boolean value that, when set, indicates that the entry describes a downstream SPIRE server")
 	f.BoolVar(&c.storeSVID, "storeSVID", false, "A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin")
 	f.Int64Var(&c.entryExpiry, "entryExpiry", 0, "An expiry, from epoch in seconds, for the resulting registration entry to be pruned")
 	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once")
+	f.BoolVar(&c.partial, "partial", false, "If set, only the fields set through flags are updated and the rest of the entry is left unchanged")
+	c.flags = f
 }
 
 func (c *updateCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
@@ -92,17 +109,27 @@ func (c *updateCommand) Run(ctx context.Context, env *common_cli.Env, serverClie
 	}
 
 	var entries []*types.Entry
+	var inputMask *types.EntryMask
 	var err error
-	if c.path != "" {
+	switch {
+	case c.path != "":
 		entries, err = parseFile(c.path)
-	} else {
+	case c.partial:
+		entries, inputMask, err = c.parsePartialConfig()
+	default:
 		entries, err = c.parseConfig()
 	}
 	if err != nil {
 		return err
 	}
 
-	succeeded, failed, err := updateEntries(ctx, serverClient.NewEntryClient(), entries)
+	if c.changesFederatesWith() {
+		if err := c.applyFederatesWithChanges(ctx, serverClient.NewEntryClient(), entries[0], inputMask); err != nil {
+			return err
+		}
+	}
+
+	succeeded, failed, err := updateEntries(ctx, serverClient.NewEntryClient(), entries, inputMask)
 	if err != nil {
 		return err
 	}
@@ -132,6 +159,12 @@ func (c *updateCommand) Run(ctx context.Context, env *common_cli.Env, serverClie
 func (c *updateCommand) validate() (err error) {
 	// If a path is set, we have all we need
 	if c.path != "" {
+		if c.partial {
+			return errors.New("partial updates are not supported with a data file")
+		}
+		if c.changesFederatesWith() {
+			return errors.New("adding or removing federated trust domains is not supported with a data file")
+		}
 		return nil
 	}
 
@@ -139,6 +172,20 @@ func (c *updateCommand) validate() (err error) {
 		return errors.New("entry ID is required")
 	}
 
+	if c.changesFederatesWith() {
+		if len(c.federatesWith) > 0 {
+			return errors.New("federatesWith cannot be combined with addFederatesWith or removeFederatesWith")
+		}
+		c.partial = true
+	}
+
+	if c.partial {
+		if c.ttl < 0 {
+			return errors.New("a positive TTL is required")
+		}
+		return nil
+	}
+
 	if len(c.selectors) < 1 {
 		return errors.New("at least one selector is required")
 	}
@@ -196,9 +243,128 @@ func (c *updateCommand) parseConfig() ([]*types.Entry, error) {
 	return []*types.Entry{e}, nil
 }
 
-func updateEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry) (succeeded, failed []*entryv1.BatchUpdateEntryResponse_Result, err error) {
+// parsePartialConfig builds a registration entry holding the fields set
+// through flags, along with the mask selecting those fields.
+func (c *updateCommand) parsePartialConfig() ([]*types.Entry, *types.EntryMask, error) {
+	e := &types.Entry{Id: c.entryID}
+	mask := &types.EntryMask{}
+
+	var err error
+	c.flags.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch f.Name {
+		case "parentID":
+			e.ParentId, err = idStringToProto(c.parentID)
+			mask.ParentId = true
+		case "spiffeID":
+			e.SpiffeId, err = idStringToProto(c.spiffeID)
+			mask.SpiffeId = true
+		case "selector":
+			for _, s := range c.selectors {
+				var cs *types.Selector
+				cs, err = util.ParseSelector(s)
+				if err != nil {
+					return
+				}
+				e.Selectors = append(e.Selectors, cs)
+			}
+			mask.Selectors = true
+		case "ttl":
+			e.Ttl = int32(c.ttl)
+			mask.Ttl = true
+		case "federatesWith":
+			e.FederatesWith = c.federatesWith
+			mask.FederatesWith = true
+		case "admin":
+			e.Admin = c.admin
+			mask.Admin = true
+		case "downstream":
+			e.Downstream = c.downstream
+			mask.Downstream = true
+		case "entryExpiry":
+			e.ExpiresAt = c.entryExpiry
+			mask.ExpiresAt = true
+		case "dns":
+			e.DnsNames = c.dnsNames
+			mask.DnsNames = true
+		case "storeSVID":
+			e.StoreSvid = c.storeSVID
+			mask.StoreSvid = true
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*types.Entry{e}, mask, nil
+}
+
+func (c *updateCommand) changesFederatesWith() bool {
+	return len(c.addFederatesWith) > 0 || len(c.removeFederatesWith) > 0
+}
+
+// applyFederatesWithChanges sets the trust domains the entry federates with
+// to the ones the entry currently federates with, plus the added ones and
+// minus the removed ones.
+func (c *updateCommand) applyFederatesWithChanges(ctx context.Context, client entryv1.EntryClient, e *types.Entry, mask *types.EntryMask) error {
+	added, err := parseTrustDomains(c.addFederatesWith)
+	if err != nil {
+		return err
+	}
+	removed, err := parseTrustDomains(c.removeFederatesWith)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.GetEntry(ctx, &entryv1.GetEntryRequest{
+		Id:         e.Id,
+		OutputMask: &types.EntryMask{FederatesWith: true},
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching entry: %w", err)
+	}
+	currentTDs, err := parseTrustDomains(current.FederatesWith)
+	if err != nil {
+		return err
+	}
+
+	isRemoved := make(map[spiffeid.TrustDomain]bool, len(removed))
+	for _, td := range removed {
+		isRemoved[td] = true
+	}
+
+	federatesWith := []string{}
+	seen := make(map[spiffeid.TrustDomain]bool)
+	for _, td := range append(currentTDs, added...) {
+		if isRemoved[td] || seen[td] {
+			continue
+		}
+		seen[td] = true
+		federatesWith = append(federatesWith, td.String())
+	}
+
+	e.FederatesWith = federatesWith
+	mask.FederatesWith = true
+	return nil
+}
+
+func parseTrustDomains(trustDomains []string) ([]spiffeid.TrustDomain, error) {
+	tds := make([]spiffeid.TrustDomain, 0, len(trustDomains))
+	for _, trustDomain := range trustDomains {
+		td, err := spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid federated trust domain %q: %w", trustDomain, err)
+		}
+		tds = append(tds, td)
+	}
+	return tds, nil
+}
+
+func updateEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry, inputMask *types.EntryMask) (succeeded, failed []*entryv1.BatchUpdateEntryResponse_Result, err error) {
 	resp, err := c.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
-		Entries: entries,
+		Entries:   entries,
+		InputMask: inputMask,
 	})
 	if err != nil {
 		return nil, nil, err
@@ -260,6 +260,52 @@ DNS name         : unu1000
 DNS name         : ung1000
 StoreSvid        : true
 
+`, time.Unix(1552410266, 0).UTC()),
+		},
+		{
+			name:   "Partial update with data file",
+			args:   []string{"-data", "../../../../test/fixture/registration/good-for-update.json", "-partial"},
+			expErr: "Error: partial updates are not supported with a data file\n",
+		},
+		{
+			name: "Partial update succeeds using command line arguments",
+			args: []string{
+				"-entryID", "entry-id",
+				"-ttl", "60",
+				"-admin=false",
+				"-selector", "zebra:zebra:2000",
+				"-partial",
+			},
+			expReq: &entryv1.BatchUpdateEntryRequest{
+				Entries: []*types.Entry{
+					{
+						Id:        "entry-id",
+						Ttl:       60,
+						Selectors: []*types.Selector{{Type: "zebra", Value: "zebra:2000"}},
+					},
+				},
+				InputMask: &types.EntryMask{
+					Ttl:       true,
+					Admin:     true,
+					Selectors: true,
+				},
+			},
+			fakeResp: fakeRespOKFromCmd,
+			expOut: fmt.Sprintf(`Entry ID         : entry-id
+SPIFFE ID        : spiffe://example.org/workload
+Parent ID        : spiffe://example.org/parent
+Revision         : 0
+Downstream       : true
+TTL              : 60
+Expiration time  : %s
+Selector         : zebra:zebra:2000
+Selector         : alpha:alpha:2000
+FederatesWith    : spiffe://domaina.test
+FederatesWith    : spiffe://domainb.test
+DNS name         : unu1000
+DNS name         : ung1000
+Admin            : true
+
 `, time.Unix(1552410266, 0).UTC()),
 		},
 		{
@@ -340,3 +386,112 @@ Error: failed to update one or more entries
 		})
 	}
 }
+
+func TestUpdateFederatesWith(t *testing.T) {
+	entry := &types.Entry{
+		Id:            "entry-id",
+		SpiffeId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+		Selectors:     []*types.Selector{{Type: "unix", Value: "uid:1"}},
+		FederatesWith: []string{"domainb.test", "domainc.test"},
+	}
+	fakeResp := &entryv1.BatchUpdateEntryResponse{
+		Results: []*entryv1.BatchUpdateEntryResponse_Result{
+			{
+				Entry:  entry,
+				Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+			},
+		},
+	}
+	expGetReq := &entryv1.GetEntryRequest{
+		Id:         "entry-id",
+		OutputMask: &types.EntryMask{FederatesWith: true},
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		getResp   *types.Entry
+		expGetReq *entryv1.GetEntryRequest
+		expReq    *entryv1.BatchUpdateEntryRequest
+		serverErr error
+
+		expErr string
+	}{
+		{
+			name:   "Combined with federatesWith",
+			args:   []string{"-entryID", "entry-id", "-federatesWith", "spiffe://domaina.test", "-addFederatesWith", "spiffe://domainb.test"},
+			expErr: "Error: federatesWith cannot be combined with addFederatesWith or removeFederatesWith\n",
+		},
+		{
+			name:   "With data file",
+			args:   []string{"-data", "../../../../test/fixture/registration/good-for-update.json", "-removeFederatesWith", "spiffe://domainb.test"},
+			expErr: "Error: adding or removing federated trust domains is not supported with a data file\n",
+		},
+		{
+			name:   "Invalid trust domain",
+			args:   []string{"-entryID", "entry-id", "-addFederatesWith", "spiffe://Domain B"},
+			expErr: "Error: invalid federated trust domain \"spiffe://Domain B\": trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores\n",
+		},
+		{
+			name:      "Entry cannot be fetched",
+			args:      []string{"-entryID", "entry-id", "-addFederatesWith", "spiffe://domainb.test"},
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: error fetching entry: rpc error: code = Unknown desc = server-error\n",
+		},
+		{
+			name: "Add and remove trust domains",
+			args: []string{
+				"-entryID", "entry-id",
+				"-addFederatesWith", "spiffe://domainb.test",
+				"-addFederatesWith", "spiffe://domainc.test",
+				"-removeFederatesWith", "domaina.test",
+			},
+			getResp:   &types.Entry{Id: "entry-id", FederatesWith: []string{"domaina.test", "domainb.test"}},
+			expGetReq: expGetReq,
+			expReq: &entryv1.BatchUpdateEntryRequest{
+				Entries: []*types.Entry{
+					{Id: "entry-id", FederatesWith: []string{"domainb.test", "domainc.test"}},
+				},
+				InputMask: &types.EntryMask{FederatesWith: true},
+			},
+		},
+		{
+			name: "Combined with other partial updates",
+			args: []string{
+				"-entryID", "entry-id",
+				"-ttl", "60",
+				"-removeFederatesWith", "spiffe://domaina.test",
+			},
+			getResp:   &types.Entry{Id: "entry-id", FederatesWith: []string{"domaina.test"}},
+			expGetReq: expGetReq,
+			expReq: &entryv1.BatchUpdateEntryRequest{
+				Entries: []*types.Entry{
+					{Id: "entry-id", Ttl: 60, FederatesWith: []string{}},
+				},
+				InputMask: &types.EntryMask{Ttl: true, FederatesWith: true},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newUpdateCommand)
+			test.server.err = tt.serverErr
+			test.server.expGetEntryReq = tt.expGetReq
+			test.server.getEntryResp = tt.getResp
+			test.server.expBatchUpdateEntryReq = tt.expReq
+			test.server.batchUpdateEntryResp = fakeResp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Contains(t, test.stdout.String(), "FederatesWith    : domainb.test\n")
+		})
+	}
+}
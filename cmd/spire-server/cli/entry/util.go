@@ -9,6 +9,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/proto/spire/common"
 )
@@ -23,6 +24,10 @@ func printEntry(e *types.Entry, printf func(string, ...interface{}) error) {
 		_ = printf("Downstream       : %t\n", e.Downstream)
 	}
 
+	if isNodeAlias(e) {
+		_ = printf("Node alias       : true\n")
+	}
+
 	if e.Ttl == 0 {
 		_ = printf("TTL              : default\n")
 	} else {
@@ -76,6 +81,12 @@ func printableEntryID(id string) string {
 }
 
 // protoToIDString converts a SPIFFE ID from the given *types.SPIFFEID to string
+// isNodeAlias returns true if the entry is a node alias, i.e. an entry
+// parented by the server that applies to the agents holding its selectors.
+func isNodeAlias(e *types.Entry) bool {
+	return e.ParentId != nil && e.ParentId.Path == idutil.ServerIDPath
+}
+
 func protoToIDString(id *types.SPIFFEID) string {
 	if id == nil {
 		return ""
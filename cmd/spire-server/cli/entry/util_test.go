@@ -7,10 +7,14 @@ import (
 	"testing"
 
 	"github.com/mitchellh/cli"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/cli/common"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -135,8 +139,12 @@ type entryTest struct {
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 
-	addr   string
-	server *fakeEntryServer
+	addr           string
+	server         *fakeEntryServer
+	agentServer    *fakeAgentServer
+	dnsNamesServer *fakeEntryDNSNamesServer
+	restoreServer  *fakeEntryRestoreServer
+	templateServer *fakeEntryTemplatesServer
 
 	client cli.Command
 }
@@ -170,6 +178,10 @@ type fakeEntryServer struct {
 	batchDeleteEntryResp *entryv1.BatchDeleteEntryResponse
 	batchCreateEntryResp *entryv1.BatchCreateEntryResponse
 	batchUpdateEntryResp *entryv1.BatchUpdateEntryResponse
+
+	// listEntries, when set, handles ListEntries instead of the expected
+	// request and response above, for commands that list more than once
+	listEntries func(*entryv1.ListEntriesRequest) (*entryv1.ListEntriesResponse, error)
 }
 
 func (f fakeEntryServer) CountEntries(ctx context.Context, req *entryv1.CountEntriesRequest) (*entryv1.CountEntriesResponse, error) {
@@ -183,6 +195,9 @@ func (f fakeEntryServer) ListEntries(ctx context.Context, req *entryv1.ListEntri
 	if f.err != nil {
 		return nil, f.err
 	}
+	if f.listEntries != nil {
+		return f.listEntries(req)
+	}
 	spiretest.AssertProtoEqual(f.t, f.expListEntriesReq, req)
 	return f.listEntriesResp, nil
 }
@@ -219,6 +234,24 @@ func (f fakeEntryServer) BatchUpdateEntry(ctx context.Context, req *entryv1.Batc
 	return f.batchUpdateEntryResp, nil
 }
 
+type fakeAgentServer struct {
+	agentv1.UnimplementedAgentServer
+
+	t   *testing.T
+	err error
+
+	expGetAgentReq *agentv1.GetAgentRequest
+	getAgentResp   *types.Agent
+}
+
+func (f *fakeAgentServer) GetAgent(ctx context.Context, req *agentv1.GetAgentRequest) (*types.Agent, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	spiretest.AssertProtoEqual(f.t, f.expGetAgentReq, req)
+	return f.getAgentResp, nil
+}
+
 func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entryTest {
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
@@ -231,17 +264,29 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entry
 	})
 
 	server := &fakeEntryServer{t: t}
+	agentServer := &fakeAgentServer{t: t}
+	dnsNamesServer := &fakeEntryDNSNamesServer{t: t}
+	restoreServer := &fakeEntryRestoreServer{t: t}
+	templateServer := &fakeEntryTemplatesServer{t: t}
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		entryv1.RegisterEntryServer(s, server)
+		agentv1.RegisterAgentServer(s, agentServer)
+		entrydnsnames.RegisterEntryDNSNamesServer(s, dnsNamesServer)
+		entryrestore.RegisterEntryRestoreServer(s, restoreServer)
+		entrytemplate.RegisterEntryTemplatesServer(s, templateServer)
 	})
 
 	test := &entryTest{
-		addr:   common.GetAddr(addr),
-		stdin:  stdin,
-		stdout: stdout,
-		stderr: stderr,
-		server: server,
-		client: client,
+		addr:           common.GetAddr(addr),
+		stdin:          stdin,
+		stdout:         stdout,
+		stderr:         stderr,
+		server:         server,
+		agentServer:    agentServer,
+		dnsNamesServer: dnsNamesServer,
+		restoreServer:  restoreServer,
+		templateServer: templateServer,
+		client:         client,
 	}
 
 	t.Cleanup(func() {
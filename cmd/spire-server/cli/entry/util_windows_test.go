@@ -31,8 +31,20 @@ const (
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
   -ttl int
     	The lifetime, in seconds, for SVIDs issued based on this registration entry
+`
+	diffUsage = `Usage of entry diff:
+  -apply
+    	If set, the entries are created, updated and deleted in the server so they match the data file
+  -data string
+    	Path to a file containing the desired registration JSON. If set to '-', read the JSON from stdin.
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -spiffeIDPrefix string
+    	Only reconcile entries whose SPIFFE ID starts with this prefix. Entries in the server outside of the prefix are left untouched
 `
 	showUsage = `Usage of entry show:
+  -agentID string
+    	The SPIFFE ID of an agent. Shows the entries the agent is authorized for, including the ones obtained through node aliases
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
   -entryID string
@@ -45,14 +57,36 @@ const (
     	The match mode used when filtering by selectors. Options: exact, any, superset and subset (default "superset")
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -node
+    	If set, only show node alias entries, which are applied to matching nodes rather than workloads
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
   -parentID string
     	The Parent ID of the records to show
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -spiffeID string
     	The SPIFFE ID of the records to show
+  -spiffeIDPrefix string
+    	Only show records whose SPIFFE ID starts with this prefix
+`
+	templateCreateUsage = `Usage of entry template create:
+  -dns value
+    	A DNS name that will be included in SVIDs issued based on the entries, where appropriate. Can be used more than once
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -nodeSelector value
+    	A colon-delimited type:value selector agents must have for the template to be expanded. Can be used more than once
+  -selector value
+    	A colon-delimited type:value selector of the entries. The value can be a template. Can be used more than once
+  -spiffeID string
+    	The SPIFFE ID template of the entries
+  -ttl int
+    	The lifetime, in seconds, for SVIDs issued based on the entries
 `
 	updateUsage = `Usage of entry update:
+  -addFederatesWith value
+    	SPIFFE ID of a trust domain to add to the ones the entry federates with, leaving the rest unchanged. Implies -partial. Can be used more than once
   -admin
     	If set, the SPIFFE ID in this entry will be granted access to the SPIRE Server's management APIs
   -data string
@@ -71,6 +105,10 @@ const (
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -parentID string
     	The SPIFFE ID of this record's parent
+  -partial
+    	If set, only the fields set through flags are updated and the rest of the entry is left unchanged
+  -removeFederatesWith value
+    	SPIFFE ID of a trust domain to remove from the ones the entry federates with, leaving the rest unchanged. Implies -partial. Can be used more than once
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -spiffeID string
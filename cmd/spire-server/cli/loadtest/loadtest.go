@@ -0,0 +1,435 @@
+package loadtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	"github.com/spiffe/spire/pkg/agent/client"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	commonutil "github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/common/x509util"
+)
+
+const (
+	opAttest = "attest"
+	opRenew  = "renew"
+)
+
+type loadtestCommand struct {
+	// Address of the server API that agents connect to
+	serverAddr string
+
+	// Number of simulated agents
+	agents int
+
+	// Number of agents attesting and renewing at the same time
+	concurrency int
+
+	// Number of times each agent renews its SVID after attesting
+	renewals int
+
+	// Keep the attested agents instead of deleting them when done
+	keepAgents bool
+}
+
+// NewLoadtestCommand creates a new "loadtest" command.
+func NewLoadtestCommand() cli.Command {
+	return NewLoadtestCommandWithEnv(common_cli.DefaultEnv)
+}
+
+// NewLoadtestCommandWithEnv creates a new "loadtest" command using the
+// environment specified.
+func NewLoadtestCommandWithEnv(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, &loadtestCommand{})
+}
+
+func (*loadtestCommand) Name() string {
+	return "loadtest"
+}
+
+func (*loadtestCommand) Synopsis() string {
+	return "Simulates agents attesting and renewing SVIDs against the server, reporting throughput and latencies"
+}
+
+// Run simulates agents attesting with join tokens and renewing their SVIDs
+// through the same API that agents use, and reports the throughput and
+// latency percentiles of each operation. The join tokens are created, and
+// the attested agents deleted, through the admin API.
+func (c *loadtestCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	switch {
+	case c.serverAddr == "":
+		return errors.New("a server address is required")
+	case c.agents <= 0:
+		return errors.New("the number of agents must be positive")
+	case c.concurrency <= 0:
+		return errors.New("the concurrency must be positive")
+	case c.renewals < 0:
+		return errors.New("the number of renewals cannot be negative")
+	}
+
+	bundle, err := serverClient.NewBundleClient().GetBundle(ctx, &bundlev1.GetBundleRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to get bundle: %w", err)
+	}
+	td, err := spiffeid.TrustDomainFromString(bundle.TrustDomain)
+	if err != nil {
+		return fmt.Errorf("invalid bundle trust domain: %w", err)
+	}
+	var roots []*x509.Certificate
+	for _, authority := range bundle.X509Authorities {
+		cert, err := x509.ParseCertificate(authority.Asn1)
+		if err != nil {
+			return fmt.Errorf("invalid bundle X.509 authority: %w", err)
+		}
+		roots = append(roots, cert)
+	}
+
+	agentClient := serverClient.NewAgentClient()
+	tokens := make([]string, 0, c.agents)
+	for i := 0; i < c.agents; i++ {
+		resp, err := agentClient.CreateJoinToken(ctx, &agentv1.CreateJoinTokenRequest{
+			Ttl: int32(time.Hour.Seconds()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create join token: %w", err)
+		}
+		tokens = append(tokens, resp.Value)
+	}
+
+	if err := env.Printf("Simulating %d agents (concurrency %d, %d renewals each) against %s\n", c.agents, c.concurrency, c.renewals, c.serverAddr); err != nil {
+		return err
+	}
+
+	r := &results{}
+	start := time.Now()
+	tokenCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for token := range tokenCh {
+				c.simulateAgent(ctx, td, roots, token, r)
+			}
+		}()
+	}
+	for _, token := range tokens {
+		tokenCh <- token
+	}
+	close(tokenCh)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if err := c.printResults(env, r, elapsed); err != nil {
+		return err
+	}
+
+	if !c.keepAgents && len(r.agentIDs) > 0 {
+		for _, id := range r.agentIDs {
+			if _, err := agentClient.DeleteAgent(ctx, &agentv1.DeleteAgentRequest{
+				Id: &types.SPIFFEID{TrustDomain: id.TrustDomain().String(), Path: id.Path()},
+			}); err != nil {
+				return fmt.Errorf("failed to delete agent %q: %w", id, err)
+			}
+		}
+		if err := env.Printf("Deleted %d simulated agents\n", len(r.agentIDs)); err != nil {
+			return err
+		}
+	}
+
+	if len(r.agentIDs) == 0 {
+		return fmt.Errorf("no agent attested successfully: %w", r.firstErr[opAttest])
+	}
+	return nil
+}
+
+func (c *loadtestCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.serverAddr, "serverAddr", "localhost:8081", "Address of the server API that agents connect to")
+	fs.IntVar(&c.agents, "agents", 100, "Number of simulated agents")
+	fs.IntVar(&c.concurrency, "concurrency", 10, "Number of agents attesting and renewing at the same time")
+	fs.IntVar(&c.renewals, "renewals", 10, "Number of times each agent renews its SVID after attesting")
+	fs.BoolVar(&c.keepAgents, "keepAgents", false, "Keep the attested agents instead of deleting them when done")
+}
+
+// simulateAgent attests an agent with the join token and renews its SVID,
+// recording the latency of each call.
+func (c *loadtestCommand) simulateAgent(ctx context.Context, td spiffeid.TrustDomain, roots []*x509.Certificate, token string, r *results) {
+	getBundle := func() []*x509.Certificate { return roots }
+
+	conn, err := client.DialServer(ctx, client.DialServerConfig{
+		Address:     c.serverAddr,
+		TrustDomain: td,
+		GetBundle:   getBundle,
+	})
+	if err != nil {
+		r.fail(opAttest, err)
+		return
+	}
+	svid, err := attest(ctx, agentv1.NewAgentClient(conn), token, r)
+	conn.Close()
+	if err != nil {
+		r.fail(opAttest, err)
+		return
+	}
+
+	id, err := x509svid(svid)
+	if err != nil {
+		r.fail(opAttest, err)
+		return
+	}
+	r.addAgent(id)
+
+	if c.renewals == 0 {
+		return
+	}
+
+	var mtx sync.Mutex
+	conn, err = client.DialServer(ctx, client.DialServerConfig{
+		Address:     c.serverAddr,
+		TrustDomain: td,
+		GetBundle:   getBundle,
+		GetAgentCertificate: func() *tls.Certificate {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return svid
+		},
+	})
+	if err != nil {
+		r.fail(opRenew, err)
+		return
+	}
+	defer conn.Close()
+
+	agentClient := agentv1.NewAgentClient(conn)
+	for i := 0; i < c.renewals; i++ {
+		renewed, err := renew(ctx, agentClient, r)
+		if err != nil {
+			r.fail(opRenew, err)
+			continue
+		}
+		mtx.Lock()
+		svid = renewed
+		mtx.Unlock()
+	}
+}
+
+func attest(ctx context.Context, agentClient agentv1.AgentClient, token string, r *results) (*tls.Certificate, error) {
+	key, csr, err := newCSR()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	stream, err := agentClient.AttestAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&agentv1.AttestAgentRequest{
+		Step: &agentv1.AttestAgentRequest_Params_{
+			Params: &agentv1.AttestAgentRequest_Params{
+				Data: &types.AttestationData{
+					Type:    "join_token",
+					Payload: []byte(token),
+				},
+				Params: &agentv1.AgentX509SVIDParams{
+					Csr: csr,
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	r.observe(opAttest, time.Since(start))
+	_ = stream.CloseSend()
+
+	return tlsCertificate(resp.GetResult().GetSvid(), key)
+}
+
+func renew(ctx context.Context, agentClient agentv1.AgentClient, r *results) (*tls.Certificate, error) {
+	key, csr, err := newCSR()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := agentClient.RenewAgent(ctx, &agentv1.RenewAgentRequest{
+		Params: &agentv1.AgentX509SVIDParams{
+			Csr: csr,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.observe(opRenew, time.Since(start))
+
+	return tlsCertificate(resp.Svid, key)
+}
+
+func newCSR() (*ecdsa.PrivateKey, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate key: %w", err)
+	}
+	csr, err := commonutil.MakeCSRWithoutURISAN(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate CSR: %w", err)
+	}
+	return key, csr, nil
+}
+
+func tlsCertificate(svid *types.X509SVID, key *ecdsa.PrivateKey) (*tls.Certificate, error) {
+	if len(svid.GetCertChain()) == 0 {
+		return nil, errors.New("server response missing SVID chain")
+	}
+	return &tls.Certificate{
+		Certificate: svid.CertChain,
+		PrivateKey:  key,
+	}, nil
+}
+
+func x509svid(svid *tls.Certificate) (spiffeid.ID, error) {
+	certs, err := x509util.RawCertsToCertificates(svid.Certificate)
+	if err != nil {
+		return spiffeid.ID{}, err
+	}
+	if len(certs[0].URIs) != 1 {
+		return spiffeid.ID{}, errors.New("agent SVID does not have exactly one URI SAN")
+	}
+	return spiffeid.FromURI(certs[0].URIs[0])
+}
+
+func (c *loadtestCommand) printResults(env *common_cli.Env, r *results, elapsed time.Duration) error {
+	if err := env.Printf("Attested agents: %d/%d\n", len(r.agentIDs), c.agents); err != nil {
+		return err
+	}
+	if err := env.Printf("Elapsed time:    %s\n\n", elapsed.Round(time.Millisecond)); err != nil {
+		return err
+	}
+	if err := env.Printf("%-10s %8s %8s %12s %10s %10s %10s %10s\n", "Operation", "Count", "Errors", "Throughput", "p50", "p90", "p99", "Max"); err != nil {
+		return err
+	}
+	for _, op := range []string{opAttest, opRenew} {
+		s := r.summarize(op, elapsed)
+		if err := env.Printf("%-10s %8d %8d %10.1f/s %10s %10s %10s %10s\n", op, s.count, s.errors, s.throughput,
+			formatLatency(s.p50), formatLatency(s.p90), formatLatency(s.p99), formatLatency(s.max)); err != nil {
+			return err
+		}
+	}
+	for _, op := range []string{opAttest, opRenew} {
+		if err := r.firstErr[op]; err != nil {
+			if err := env.Printf("First %s error: %v\n", op, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Microsecond * 100).String()
+}
+
+// results collects the outcome of the calls made by the simulated agents
+type results struct {
+	mtx       sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	firstErr  map[string]error
+	agentIDs  []spiffeid.ID
+}
+
+type summary struct {
+	count      int
+	errors     int
+	throughput float64
+	p50        time.Duration
+	p90        time.Duration
+	p99        time.Duration
+	max        time.Duration
+}
+
+func (r *results) observe(op string, latency time.Duration) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.latencies == nil {
+		r.latencies = make(map[string][]time.Duration)
+	}
+	r.latencies[op] = append(r.latencies[op], latency)
+}
+
+func (r *results) fail(op string, err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.errors == nil {
+		r.errors = make(map[string]int)
+		r.firstErr = make(map[string]error)
+	}
+	r.errors[op]++
+	if r.firstErr[op] == nil {
+		r.firstErr[op] = err
+	}
+}
+
+func (r *results) addAgent(id spiffeid.ID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.agentIDs = append(r.agentIDs, id)
+}
+
+// summarize returns the number of successful and failed calls of the
+// operation, its throughput over the elapsed time, and the latency
+// percentiles of the successful calls.
+func (r *results) summarize(op string, elapsed time.Duration) summary {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	latencies := append([]time.Duration(nil), r.latencies[op]...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	s := summary{
+		count:  len(latencies),
+		errors: r.errors[op],
+		p50:    percentile(latencies, 50),
+		p90:    percentile(latencies, 90),
+		p99:    percentile(latencies, 99),
+	}
+	if len(latencies) > 0 {
+		s.max = latencies[len(latencies)-1]
+	}
+	if elapsed > 0 {
+		s.throughput = float64(s.count) / elapsed.Seconds()
+	}
+	return s
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package loadtest_test
+
+var (
+	usage = `Usage of loadtest:
+  -agents int
+    	Number of simulated agents (default 100)
+  -concurrency int
+    	Number of agents attesting and renewing at the same time (default 10)
+  -keepAgents
+    	Keep the attested agents instead of deleting them when done
+  -renewals int
+    	Number of times each agent renews its SVID after attesting (default 10)
+  -serverAddr string
+    	Address of the server API that agents connect to (default "localhost:8081")
+  -socketPath string
+    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+`
+)
@@ -0,0 +1,298 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	"github.com/spiffe/spire/cmd/spire-server/cli/loadtest"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	serverID    = spiffeid.RequireFromPath(trustDomain, "/spire/server")
+)
+
+func TestHelp(t *testing.T) {
+	test := setupTest(t)
+
+	test.client.Help()
+	require.Equal(t, usage, test.stderr.String())
+}
+
+func TestSynopsis(t *testing.T) {
+	test := setupTest(t)
+	require.Equal(t, "Simulates agents attesting and renewing SVIDs against the server, reporting throughput and latencies", test.client.Synopsis())
+}
+
+func TestLoadtest(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		args               []string
+		attestErr          error
+		renewErr           error
+		expectedReturnCode int
+		expectedStdout     []string
+		expectedStderr     string
+		expectedDeleted    int
+	}{
+		{
+			name: "success",
+			args: []string{"-agents", "5", "-concurrency", "2", "-renewals", "3"},
+			expectedStdout: []string{
+				"Simulating 5 agents (concurrency 2, 3 renewals each) against ",
+				"Attested agents: 5/5\n",
+				"attest            5        0 ",
+				"renew            15        0 ",
+				"Deleted 5 simulated agents\n",
+			},
+			expectedDeleted: 5,
+		},
+		{
+			name: "keep agents",
+			args: []string{"-agents", "2", "-renewals", "0", "-keepAgents"},
+			expectedStdout: []string{
+				"Attested agents: 2/2\n",
+				"attest            2        0 ",
+				"renew             0        0 ",
+			},
+		},
+		{
+			name:     "renewals fail",
+			args:     []string{"-agents", "2", "-renewals", "2"},
+			renewErr: status.Error(codes.Internal, "renew failed"),
+			expectedStdout: []string{
+				"Attested agents: 2/2\n",
+				"renew             0        4 ",
+				"First renew error: rpc error: code = Internal desc = renew failed\n",
+				"Deleted 2 simulated agents\n",
+			},
+			expectedDeleted: 2,
+		},
+		{
+			name:      "all attestations fail",
+			args:      []string{"-agents", "2"},
+			attestErr: status.Error(codes.PermissionDenied, "failed to attest"),
+			expectedStdout: []string{
+				"Attested agents: 0/2\n",
+				"attest            0        2 ",
+			},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: no agent attested successfully: rpc error: code = PermissionDenied desc = failed to attest\n",
+		},
+		{
+			name:               "invalid concurrency",
+			args:               []string{"-concurrency", "0"},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: the concurrency must be positive\n",
+		},
+		{
+			name:               "wrong UDS path",
+			args:               []string{common.AddrArg, common.AddrValue},
+			expectedReturnCode: 1,
+			expectedStderr:     common.AddrError,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t)
+			test.server.attestErr = tt.attestErr
+			test.server.renewErr = tt.renewErr
+
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			for _, expected := range tt.expectedStdout {
+				require.Contains(t, test.stdout.String(), expected)
+			}
+			require.Equal(t, tt.expectedStderr, test.stderr.String())
+			require.Equal(t, tt.expectedReturnCode, returnCode)
+			require.Len(t, test.server.deleted, tt.expectedDeleted)
+		})
+	}
+}
+
+type loadtestTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	args   []string
+	server *fakeServer
+
+	client cli.Command
+}
+
+func setupTest(t *testing.T) *loadtestTest {
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	server := &fakeServer{
+		caCert: caCert,
+		caKey:  caKey,
+	}
+
+	// The server API is served over TLS using an SVID for the server ID
+	serverCert, serverKey := testca.CreateX509SVID(t, caCert, caKey, serverID,
+		testca.WithIPAddresses(net.ParseIP("127.0.0.1")))
+	tlsServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{serverCert.Raw},
+			PrivateKey:  serverKey,
+		}},
+		ClientAuth: tls.RequestClientCert,
+		MinVersion: tls.VersionTLS12,
+	})))
+	agentv1.RegisterAgentServer(tlsServer, fakeAgentServer{server: server})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	spiretest.ServeGRPCServerOnListener(t, tlsServer, listener)
+
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		agentv1.RegisterAgentServer(s, fakeAgentServer{server: server})
+		bundlev1.RegisterBundleServer(s, fakeBundleServer{server: server})
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	client := loadtest.NewLoadtestCommandWithEnv(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	return &loadtestTest{
+		stdout: stdout,
+		stderr: stderr,
+		args:   []string{common.AddrArg, common.GetAddr(addr), "-serverAddr", listener.Addr().String()},
+		server: server,
+		client: client,
+	}
+}
+
+type fakeServer struct {
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+
+	attestErr error
+	renewErr  error
+
+	mtx     sync.Mutex
+	tokens  int
+	deleted []*types.SPIFFEID
+}
+
+func (s *fakeServer) signCSR(csrDER []byte, id spiffeid.ID) (*types.X509SVID, error) {
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "malformed CSR: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: serial,
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		URIs:         []*url.URL{id.URL()},
+	}, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		return nil, err
+	}
+	return &types.X509SVID{
+		Id:        &types.SPIFFEID{TrustDomain: id.TrustDomain().String(), Path: id.Path()},
+		CertChain: [][]byte{certDER},
+	}, nil
+}
+
+type fakeAgentServer struct {
+	agentv1.UnimplementedAgentServer
+	server *fakeServer
+}
+
+func (s fakeAgentServer) CreateJoinToken(context.Context, *agentv1.CreateJoinTokenRequest) (*types.JoinToken, error) {
+	s.server.mtx.Lock()
+	defer s.server.mtx.Unlock()
+	s.server.tokens++
+	return &types.JoinToken{Value: fmt.Sprintf("token-%d", s.server.tokens)}, nil
+}
+
+func (s fakeAgentServer) DeleteAgent(_ context.Context, req *agentv1.DeleteAgentRequest) (*emptypb.Empty, error) {
+	s.server.mtx.Lock()
+	defer s.server.mtx.Unlock()
+	s.server.deleted = append(s.server.deleted, req.Id)
+	return &emptypb.Empty{}, nil
+}
+
+func (s fakeAgentServer) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if s.server.attestErr != nil {
+		return s.server.attestErr
+	}
+	params := req.GetParams()
+	if params.GetData().GetType() != "join_token" {
+		return status.Error(codes.InvalidArgument, "unexpected attestation type")
+	}
+	id := spiffeid.RequireFromPath(trustDomain, "/spire/agent/join_token/"+string(params.Data.Payload))
+	svid, err := s.server.signCSR(params.GetParams().GetCsr(), id)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&agentv1.AttestAgentResponse{
+		Step: &agentv1.AttestAgentResponse_Result_{
+			Result: &agentv1.AttestAgentResponse_Result{
+				Svid: svid,
+			},
+		},
+	})
+}
+
+func (s fakeAgentServer) RenewAgent(_ context.Context, req *agentv1.RenewAgentRequest) (*agentv1.RenewAgentResponse, error) {
+	if s.server.renewErr != nil {
+		return nil, s.server.renewErr
+	}
+	svid, err := s.server.signCSR(req.GetParams().GetCsr(), spiffeid.RequireFromPath(trustDomain, "/spire/agent/renewed"))
+	if err != nil {
+		return nil, err
+	}
+	return &agentv1.RenewAgentResponse{Svid: svid}, nil
+}
+
+type fakeBundleServer struct {
+	bundlev1.UnimplementedBundleServer
+	server *fakeServer
+}
+
+func (s fakeBundleServer) GetBundle(context.Context, *bundlev1.GetBundleRequest) (*types.Bundle, error) {
+	return &types.Bundle{
+		TrustDomain:     trustDomain.String(),
+		X509Authorities: []*types.X509Certificate{{Asn1: s.server.caCert.Raw}},
+	}, nil
+}
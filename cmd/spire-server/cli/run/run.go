@@ -3,18 +3,19 @@ package run
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -27,14 +28,27 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/configfile"
 	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/fips"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
+	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
+	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 )
@@ -65,26 +79,56 @@ type Config struct {
 }
 
 type serverConfig struct {
-	AdminIDs        []string           `hcl:"admin_ids"`
-	AgentTTL        string             `hcl:"agent_ttl"`
-	AuditLogEnabled bool               `hcl:"audit_log_enabled"`
-	BindAddress     string             `hcl:"bind_address"`
-	BindPort        int                `hcl:"bind_port"`
-	CAKeyType       string             `hcl:"ca_key_type"`
-	CASubject       *caSubjectConfig   `hcl:"ca_subject"`
-	CATTL           string             `hcl:"ca_ttl"`
-	DataDir         string             `hcl:"data_dir"`
-	DefaultSVIDTTL  string             `hcl:"default_svid_ttl"`
-	Experimental    experimentalConfig `hcl:"experimental"`
-	Federation      *federationConfig  `hcl:"federation"`
-	JWTIssuer       string             `hcl:"jwt_issuer"`
-	JWTKeyType      string             `hcl:"jwt_key_type"`
-	LogFile         string             `hcl:"log_file"`
-	LogLevel        string             `hcl:"log_level"`
-	LogFormat       string             `hcl:"log_format"`
-	RateLimit       rateLimitConfig    `hcl:"ratelimit"`
-	SocketPath      string             `hcl:"socket_path"`
-	TrustDomain     string             `hcl:"trust_domain"`
+	AdditionalListeners     map[string]listenerConfig               `hcl:"additional_listener"`
+	AdminIDs                []string                                `hcl:"admin_ids"`
+	AgentMaxRenewalAge      string                                  `hcl:"agent_max_renewal_age"`
+	AgentTTL                string                                  `hcl:"agent_ttl"`
+	AttestationWebhooks     map[string]webhookConfig                `hcl:"attestation_webhook"`
+	AuditLogEnabled         bool                                    `hcl:"audit_log_enabled"`
+	AuditLogFile            string                                  `hcl:"audit_log_file"`
+	AuditLogTimestamping    *auditLogTimestampingConfig             `hcl:"audit_log_timestamping"`
+	AuditLogSyslog          *auditLogSyslogConfig                   `hcl:"audit_log_syslog"`
+	BindAddress             string                                  `hcl:"bind_address"`
+	BindPort                int                                     `hcl:"bind_port"`
+	BundleRefreshHint       string                                  `hcl:"bundle_refresh_hint"`
+	CAActivationOverlap     string                                  `hcl:"ca_activation_overlap"`
+	CAKeyType               string                                  `hcl:"ca_key_type"`
+	CAPathLen               *int                                    `hcl:"ca_path_len"`
+	CAPreparationLeadTime   string                                  `hcl:"ca_preparation_lead_time"`
+	CASubject               *caSubjectConfig                        `hcl:"ca_subject"`
+	CATTL                   string                                  `hcl:"ca_ttl"`
+	CAUpstreamFallback      bool                                    `hcl:"ca_upstream_fallback"`
+	CSRExtensionAllowlist   []string                                `hcl:"csr_extension_allowlist"`
+	DataDir                 string                                  `hcl:"data_dir"`
+	DataStoreSlowThreshold  string                                  `hcl:"datastore_slow_operation_threshold"`
+	DefaultSVIDTTL          string                                  `hcl:"default_svid_ttl"`
+	DownstreamAuthzWebhook  *webhookConfig                          `hcl:"downstream_authorization_webhook"`
+	DownstreamCAPathLen     *int                                    `hcl:"downstream_ca_path_len"`
+	EntryNamespaces         map[string]entryNamespaceConfig         `hcl:"entry_namespace"`
+	Experimental            experimentalConfig                      `hcl:"experimental"`
+	Federation              *federationConfig                       `hcl:"federation"`
+	GRPC                    grpcConfig                              `hcl:"grpc"`
+	IssuanceQuota           issuanceQuotaConfig                     `hcl:"issuance_quota"`
+	JWTAudienceRestrictions map[string]jwtAudienceRestrictionConfig `hcl:"jwt_audience_restriction"`
+	JWTIssuer               string                                  `hcl:"jwt_issuer"`
+	JWTKeyType              string                                  `hcl:"jwt_key_type"`
+	LogFile                 string                                  `hcl:"log_file"`
+	LogLevel                string                                  `hcl:"log_level"`
+	LogFormat               string                                  `hcl:"log_format"`
+	LogSourceLocation       bool                                    `hcl:"log_source_location"`
+	MaxSVIDTTL              string                                  `hcl:"max_svid_ttl"`
+	RateLimit               rateLimitConfig                         `hcl:"ratelimit"`
+	RequirePluginChecksums  bool                                    `hcl:"require_plugin_checksums"`
+	ShutdownDrainTimeout    string                                  `hcl:"shutdown_drain_timeout"`
+	SocketPath              string                                  `hcl:"socket_path"`
+	SPIFFEIDPathPolicy      *spiffeIDPathPolicyConfig               `hcl:"spiffe_id_path_policy"`
+	SVIDDenylist            []string                                `hcl:"svid_denylist"`
+	TLSCipherSuites         []string                                `hcl:"tls_cipher_suites"`
+	TLSMaxVersion           string                                  `hcl:"tls_max_version"`
+	TLSMinVersion           string                                  `hcl:"tls_min_version"`
+	TrustDomain             string                                  `hcl:"trust_domain"`
+	UDSGroup                string                                  `hcl:"uds_group"`
+	UDSMode                 string                                  `hcl:"uds_mode"`
 
 	ConfigPath string
 	ExpandEnv  bool
@@ -101,6 +145,9 @@ type serverConfig struct {
 type experimentalConfig struct {
 	AuthOpaPolicyEngine *authpolicy.OpaEngineConfig `hcl:"auth_opa_policy_engine"`
 	CacheReloadInterval string                      `hcl:"cache_reload_interval"`
+	BundleCacheExpiry   string                      `hcl:"bundle_cache_expiry"`
+	AdminReadAfterWrite bool                        `hcl:"admin_read_after_write"`
+	LeaderElection      bool                        `hcl:"leader_election"`
 
 	Flags fflag.RawConfig `hcl:"feature_flags"`
 
@@ -109,6 +156,36 @@ type experimentalConfig struct {
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
 
+type listenerConfig struct {
+	AttestationCABundlePath string   `hcl:"attestation_ca_bundle_path"`
+	BindAddress             string   `hcl:"bind_address"`
+	BindPort                int      `hcl:"bind_port"`
+	RequireClientCert       bool     `hcl:"require_client_cert"`
+	TLSCipherSuites         []string `hcl:"tls_cipher_suites"`
+	TLSMaxVersion           string   `hcl:"tls_max_version"`
+	TLSMinVersion           string   `hcl:"tls_min_version"`
+	UnusedKeys              []string `hcl:",unusedKeys"`
+}
+
+type entryNamespaceConfig struct {
+	AdminIDs            []string `hcl:"admin_ids"`
+	AgentIDPathPrefixes []string `hcl:"agent_id_path_prefixes"`
+	SPIFFEIDPathPrefix  string   `hcl:"spiffe_id_path_prefix"`
+	UnusedKeys          []string `hcl:",unusedKeys"`
+}
+
+type spiffeIDPathPolicyConfig struct {
+	AllowedPrefixes []string `hcl:"allowed_prefixes"`
+	AllowedPatterns []string `hcl:"allowed_patterns"`
+	UnusedKeys      []string `hcl:",unusedKeys"`
+}
+
+type webhookConfig struct {
+	Timeout    string   `hcl:"timeout"`
+	URL        string   `hcl:"url"`
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
 type caSubjectConfig struct {
 	Country      []string `hcl:"country"`
 	Organization []string `hcl:"organization"`
@@ -123,10 +200,14 @@ type federationConfig struct {
 }
 
 type bundleEndpointConfig struct {
-	Address    string                    `hcl:"address"`
-	Port       int                       `hcl:"port"`
-	ACME       *bundleEndpointACMEConfig `hcl:"acme"`
-	UnusedKeys []string                  `hcl:",unusedKeys"`
+	Address         string                    `hcl:"address"`
+	Port            int                       `hcl:"port"`
+	RefreshHint     string                    `hcl:"refresh_hint"`
+	ACME            *bundleEndpointACMEConfig `hcl:"acme"`
+	TLSCipherSuites []string                  `hcl:"tls_cipher_suites"`
+	TLSMaxVersion   string                    `hcl:"tls_max_version"`
+	TLSMinVersion   string                    `hcl:"tls_min_version"`
+	UnusedKeys      []string                  `hcl:",unusedKeys"`
 }
 
 type bundleEndpointACMEConfig struct {
@@ -157,12 +238,48 @@ type httpsSPIFFEProfileConfig struct {
 type httpsWebProfileConfig struct {
 }
 
+type grpcConfig struct {
+	KeepaliveMinTime     string   `hcl:"keepalive_min_time"`
+	KeepaliveTime        string   `hcl:"keepalive_time"`
+	KeepaliveTimeout     string   `hcl:"keepalive_timeout"`
+	MaxConcurrentStreams int      `hcl:"max_concurrent_streams"`
+	MaxRecvMessageSize   int      `hcl:"max_recv_message_size"`
+	MaxSendMessageSize   int      `hcl:"max_send_message_size"`
+	UnusedKeys           []string `hcl:",unusedKeys"`
+}
+
 type rateLimitConfig struct {
 	Attestation *bool    `hcl:"attestation"`
 	Signing     *bool    `hcl:"signing"`
 	UnusedKeys  []string `hcl:",unusedKeys"`
 }
 
+type auditLogTimestampingConfig struct {
+	TSAURL        string   `hcl:"tsa_url"`
+	BatchSize     int      `hcl:"batch_size"`
+	BatchInterval string   `hcl:"batch_interval"`
+	UnusedKeys    []string `hcl:",unusedKeys"`
+}
+
+type auditLogSyslogConfig struct {
+	Network    string   `hcl:"network"`
+	Address    string   `hcl:"address"`
+	Tag        string   `hcl:"tag"`
+	UnusedKeys []string `hcl:",unusedKeys"`
+}
+
+type jwtAudienceRestrictionConfig struct {
+	AllowedAudiences   []string `hcl:"allowed_audiences"`
+	SPIFFEIDPathPrefix string   `hcl:"spiffe_id_path_prefix"`
+	UnusedKeys         []string `hcl:",unusedKeys"`
+}
+
+type issuanceQuotaConfig struct {
+	SigningsPerMinutePerAgent int      `hcl:"signings_per_minute_per_agent"`
+	SigningsPerMinutePerEntry int      `hcl:"signings_per_minute_per_entry"`
+	UnusedKeys                []string `hcl:",unusedKeys"`
+}
+
 func NewRunCommand(logOptions []log.Option, allowUnknownConfig bool) cli.Command {
 	return newRunCommand(common_cli.DefaultEnv, logOptions, allowUnknownConfig)
 }
@@ -223,6 +340,40 @@ func LoadConfig(name string, args []string, logOptions []log.Option, output io.W
 	return NewServerConfig(input, logOptions, allowUnknownConfig)
 }
 
+// LoadReloadableConfig re-reads the configuration and returns the portion of
+// it that can be applied to a running server.
+func LoadReloadableConfig(name string, args []string, output io.Writer) (*server.ReloadableConfig, error) {
+	cliInput, err := parseFlags(name, args, output)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := mergeInput(fileInput, cliInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(input); err != nil {
+		return nil, err
+	}
+
+	logLevel, err := logrus.ParseLevel(input.Server.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server.ReloadableConfig{
+		LogLevel:      logLevel,
+		PluginConfigs: *input.Plugins,
+		SVIDDenylist:  input.Server.SVIDDenylist,
+	}, nil
+}
+
 // Run the SPIFFE Server
 func (cmd *Command) Run(args []string) int {
 	c, err := LoadConfig(commandName, args, cmd.logOptions, cmd.env.Stderr, cmd.allowUnknownConfig)
@@ -234,19 +385,21 @@ func (cmd *Command) Run(args []string) int {
 	// Set umask before starting up the server
 	common_cli.SetUmask(c.Log)
 
-	s := server.New(*c)
+	c.ReloadConfig = func() (*server.ReloadableConfig, error) {
+		return LoadReloadableConfig(commandName, args, io.Discard)
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	s := server.New(*c)
 
-	err = s.Run(ctx)
-	if err != nil {
-		c.Log.WithError(err).Error("Server crashed")
-		return 1
-	}
+	return common_cli.RunService("spire-server", func(ctx context.Context) int {
+		if err := s.Run(ctx); err != nil {
+			c.Log.WithError(err).Error("Server crashed")
+			return 1
+		}
 
-	c.Log.Info("Server stopped gracefully")
-	return 0
+		c.Log.Info("Server stopped gracefully")
+		return 0
+	})
 }
 
 // Synopsis of the command
@@ -283,7 +436,13 @@ func ParseFile(path string, expandEnv bool) (*Config, error) {
 		data = os.ExpandEnv(data)
 	}
 
-	if err := hcl.Decode(&c, data); err != nil {
+	// Merge the files listed in the include setting, if any
+	file, err := configfile.Parse(path, data, expandEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hcl.DecodeObject(&c, file); err != nil {
 		return nil, fmt.Errorf("unable to decode configuration at %q: %w", path, err)
 	}
 
@@ -347,9 +506,13 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		log.WithLevel(c.Server.LogLevel),
 		log.WithFormat(c.Server.LogFormat),
 	)
+	if c.Server.LogSourceLocation {
+		logOptions = append(logOptions, log.WithSourceLocation())
+	}
 	var reopenableFile *log.ReopenableFile
 	if c.Server.LogFile != "" {
-		reopenableFile, err := log.NewReopenableFile(c.Server.LogFile)
+		var err error
+		reopenableFile, err = log.NewReopenableFile(c.Server.LogFile)
 		if err != nil {
 			return nil, err
 		}
@@ -365,6 +528,34 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.LogReopener = log.ReopenOnSignal(logger, reopenableFile)
 	}
 
+	if err := fips.Check(); err != nil {
+		return nil, err
+	}
+	if fips.Enabled() {
+		logger.Info("FIPS mode enabled")
+	}
+
+	sc.AuditLogSinks.File = c.Server.AuditLogFile
+	if ts := c.Server.AuditLogTimestamping; ts != nil {
+		sc.AuditLogSinks.Timestamping = &audit.TimestampConfig{
+			TSAURL:    ts.TSAURL,
+			BatchSize: ts.BatchSize,
+		}
+		if ts.BatchInterval != "" {
+			sc.AuditLogSinks.Timestamping.BatchInterval, err = time.ParseDuration(ts.BatchInterval)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse audit_log_timestamping.batch_interval: %w", err)
+			}
+		}
+	}
+	if sl := c.Server.AuditLogSyslog; sl != nil {
+		sc.AuditLogSinks.Syslog = &audit.SyslogConfig{
+			Network: sl.Network,
+			Address: sl.Address,
+			Tag:     sl.Tag,
+		}
+	}
+
 	ip := net.ParseIP(c.Server.BindAddress)
 	if ip == nil {
 		return nil, fmt.Errorf("could not parse bind_address %q", c.Server.BindAddress)
@@ -374,6 +565,25 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		Port: c.Server.BindPort,
 	}
 
+	sc.BindAddressTLSPolicy, err = parseTLSPolicy(c.Server.TLSMinVersion, c.Server.TLSMaxVersion, c.Server.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	listenerNames := make([]string, 0, len(c.Server.AdditionalListeners))
+	for name := range c.Server.AdditionalListeners {
+		listenerNames = append(listenerNames, name)
+	}
+	sort.Strings(listenerNames)
+
+	for _, name := range listenerNames {
+		tcpListener, err := parseListenerConfig(c.Server.AdditionalListeners[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional_listener %q: %w", name, err)
+		}
+		sc.AdditionalListeners = append(sc.AdditionalListeners, *tcpListener)
+	}
+
 	c.Server.setDefaultsIfNeeded()
 
 	addr, err := c.Server.getAddr()
@@ -382,7 +592,27 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 	sc.BindLocalAddress = addr
 
+	if c.Server.UDSMode != "" {
+		mode, err := util.ParseSocketMode(c.Server.UDSMode)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse uds_mode: %w", err)
+		}
+		sc.BindLocalAddressMode = mode
+	}
+	sc.BindLocalAddressGroup = c.Server.UDSGroup
+
 	sc.DataDir = c.Server.DataDir
+
+	if c.Server.DataStoreSlowThreshold != "" {
+		threshold, err := time.ParseDuration(c.Server.DataStoreSlowThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse datastore_slow_operation_threshold %q: %w", c.Server.DataStoreSlowThreshold, err)
+		}
+		if threshold < 0 {
+			return nil, fmt.Errorf("datastore_slow_operation_threshold %q must not be negative", c.Server.DataStoreSlowThreshold)
+		}
+		sc.DataStoreSlowOperationThreshold = threshold
+	}
 	sc.AuditLogEnabled = c.Server.AuditLogEnabled
 
 	td, err := spiffeid.TrustDomainFromString(c.Server.TrustDomain)
@@ -402,6 +632,11 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	}
 	sc.RateLimit.Signing = *c.Server.RateLimit.Signing
 
+	sc.IssuanceQuotas = svidv1.IssuanceQuotas{
+		SigningsPerMinutePerAgent: c.Server.IssuanceQuota.SigningsPerMinutePerAgent,
+		SigningsPerMinutePerEntry: c.Server.IssuanceQuota.SigningsPerMinutePerEntry,
+	}
+
 	if c.Server.Federation != nil {
 		if c.Server.Federation.BundleEndpoint != nil {
 			sc.Federation.BundleEndpoint = &bundle.EndpointConfig{
@@ -411,6 +646,22 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 				},
 			}
 
+			if c.Server.Federation.BundleEndpoint.RefreshHint != "" {
+				refreshHint, err := time.ParseDuration(c.Server.Federation.BundleEndpoint.RefreshHint)
+				if err != nil {
+					return nil, fmt.Errorf("could not parse bundle endpoint refresh hint %q: %w", c.Server.Federation.BundleEndpoint.RefreshHint, err)
+				}
+				if refreshHint <= 0 {
+					return nil, fmt.Errorf("bundle endpoint refresh hint must be positive; got %q", c.Server.Federation.BundleEndpoint.RefreshHint)
+				}
+				sc.Federation.BundleEndpoint.RefreshHint = refreshHint
+			}
+
+			sc.Federation.BundleEndpoint.TLSPolicy, err = parseTLSPolicy(c.Server.Federation.BundleEndpoint.TLSMinVersion, c.Server.Federation.BundleEndpoint.TLSMaxVersion, c.Server.Federation.BundleEndpoint.TLSCipherSuites)
+			if err != nil {
+				return nil, fmt.Errorf("invalid federation bundle endpoint: %w", err)
+			}
+
 			if acme := c.Server.Federation.BundleEndpoint.ACME; acme != nil {
 				sc.Federation.BundleEndpoint.ACME = &bundle.ACMEConfig{
 					DirectoryURL: acme.DirectoryURL,
@@ -461,6 +712,101 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.AdminIDs = append(sc.AdminIDs, id)
 	}
 
+	namespaceNames := make([]string, 0, len(c.Server.EntryNamespaces))
+	for name := range c.Server.EntryNamespaces {
+		namespaceNames = append(namespaceNames, name)
+	}
+	sort.Strings(namespaceNames)
+
+	namespaceAdminIDs := make(map[spiffeid.ID]string)
+	for _, name := range namespaceNames {
+		nsConfig := c.Server.EntryNamespaces[name]
+		if !strings.HasPrefix(nsConfig.SPIFFEIDPathPrefix, "/") {
+			return nil, fmt.Errorf("entry_namespace %q: spiffe_id_path_prefix must start with a slash", name)
+		}
+		ns := entryv1.Namespace{
+			Name:       name,
+			PathPrefix: nsConfig.SPIFFEIDPathPrefix,
+		}
+		for _, prefix := range nsConfig.AgentIDPathPrefixes {
+			if !idutil.IsAgentPath(prefix) {
+				return nil, fmt.Errorf("entry_namespace %q: agent ID path prefix %q must start with /spire/agent/", name, prefix)
+			}
+			ns.AgentPathPrefixes = append(ns.AgentPathPrefixes, prefix)
+		}
+		for _, adminID := range nsConfig.AdminIDs {
+			id, err := spiffeid.FromString(adminID)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("entry_namespace %q: could not parse admin ID %q: %w", name, adminID, err)
+			case !id.MemberOf(sc.TrustDomain):
+				return nil, fmt.Errorf("entry_namespace %q: admin ID %q does not belong to trust domain %q", name, id, sc.TrustDomain)
+			}
+			if other, ok := namespaceAdminIDs[id]; ok {
+				return nil, fmt.Errorf("entry_namespace %q: admin ID %q is already scoped to entry_namespace %q", name, id, other)
+			}
+			namespaceAdminIDs[id] = name
+			ns.AdminIDs = append(ns.AdminIDs, id)
+		}
+		sc.EntryNamespaces = append(sc.EntryNamespaces, ns)
+	}
+
+	restrictionNames := make([]string, 0, len(c.Server.JWTAudienceRestrictions))
+	for name := range c.Server.JWTAudienceRestrictions {
+		restrictionNames = append(restrictionNames, name)
+	}
+	sort.Strings(restrictionNames)
+
+	for _, name := range restrictionNames {
+		arConfig := c.Server.JWTAudienceRestrictions[name]
+		if !strings.HasPrefix(arConfig.SPIFFEIDPathPrefix, "/") {
+			return nil, fmt.Errorf("jwt_audience_restriction %q: spiffe_id_path_prefix must start with a slash", name)
+		}
+		if len(arConfig.AllowedAudiences) == 0 {
+			return nil, fmt.Errorf("jwt_audience_restriction %q: allowed_audiences must not be empty", name)
+		}
+		sc.JWTAudienceRestrictions = append(sc.JWTAudienceRestrictions, svidv1.AudienceRestriction{
+			Name:             name,
+			PathPrefix:       arConfig.SPIFFEIDPathPrefix,
+			AllowedAudiences: arConfig.AllowedAudiences,
+		})
+	}
+
+	sc.SVIDDenylist = c.Server.SVIDDenylist
+
+	sc.CSRExtensionAllowlist, err = ca.ParseCSRExtensionAllowlist(c.Server.CSRExtensionAllowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csr_extension_allowlist: %w", err)
+	}
+
+	if p := c.Server.SPIFFEIDPathPolicy; p != nil {
+		sc.IDPathPolicy, err = api.NewIDPathPolicy(p.AllowedPrefixes, p.AllowedPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spiffe_id_path_policy: %w", err)
+		}
+	}
+
+	webhookNames := make([]string, 0, len(c.Server.AttestationWebhooks))
+	for name := range c.Server.AttestationWebhooks {
+		webhookNames = append(webhookNames, name)
+	}
+	sort.Strings(webhookNames)
+
+	for _, name := range webhookNames {
+		webhook, err := parseWebhookConfig(name, c.Server.AttestationWebhooks[name])
+		if err != nil {
+			return nil, fmt.Errorf("attestation_webhook %q: %w", name, err)
+		}
+		sc.AttestationWebhooks = append(sc.AttestationWebhooks, *webhook)
+	}
+
+	if c.Server.DownstreamAuthzWebhook != nil {
+		sc.DownstreamAuthorizationWebhook, err = parseDownstreamWebhookConfig(*c.Server.DownstreamAuthzWebhook)
+		if err != nil {
+			return nil, fmt.Errorf("downstream_authorization_webhook: %w", err)
+		}
+	}
+
 	if c.Server.AgentTTL != "" {
 		ttl, err := time.ParseDuration(c.Server.AgentTTL)
 		if err != nil {
@@ -469,6 +815,31 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.AgentTTL = ttl
 	}
 
+	if c.Server.AgentMaxRenewalAge != "" {
+		maxRenewalAge, err := time.ParseDuration(c.Server.AgentMaxRenewalAge)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse agent_max_renewal_age %q: %w", c.Server.AgentMaxRenewalAge, err)
+		}
+		if maxRenewalAge < 0 {
+			return nil, fmt.Errorf("agent_max_renewal_age %q must not be negative", c.Server.AgentMaxRenewalAge)
+		}
+		sc.AgentMaxRenewalAge = maxRenewalAge
+	}
+
+	gc, err := parseGRPCConfig(c.Server.GRPC)
+	if err != nil {
+		return nil, err
+	}
+	sc.GRPC = *gc
+
+	if c.Server.ShutdownDrainTimeout != "" {
+		timeout, err := time.ParseDuration(c.Server.ShutdownDrainTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse shutdown drain timeout %q: %w", c.Server.ShutdownDrainTimeout, err)
+		}
+		sc.ShutdownDrainTimeout = timeout
+	}
+
 	if c.Server.DefaultSVIDTTL != "" {
 		ttl, err := time.ParseDuration(c.Server.DefaultSVIDTTL)
 		if err != nil {
@@ -477,6 +848,37 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.SVIDTTL = ttl
 	}
 
+	if c.Server.BundleRefreshHint != "" {
+		refreshHint, err := time.ParseDuration(c.Server.BundleRefreshHint)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bundle_refresh_hint %q: %w", c.Server.BundleRefreshHint, err)
+		}
+		if refreshHint <= 0 {
+			return nil, fmt.Errorf("bundle_refresh_hint %q must be positive", c.Server.BundleRefreshHint)
+		}
+		sc.BundleRefreshHint = refreshHint
+	}
+
+	if c.Server.MaxSVIDTTL != "" {
+		maxTTL, err := time.ParseDuration(c.Server.MaxSVIDTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse max_svid_ttl %q: %w", c.Server.MaxSVIDTTL, err)
+		}
+		if maxTTL <= 0 {
+			return nil, fmt.Errorf("max_svid_ttl %q must be positive", c.Server.MaxSVIDTTL)
+		}
+		sc.MaxSVIDTTL = maxTTL
+
+		defaultTTL := sc.SVIDTTL
+		if defaultTTL <= 0 {
+			defaultTTL = ca.DefaultX509SVIDTTL
+		}
+		if defaultTTL > maxTTL {
+			sc.Log.Warnf("The default_svid_ttl (%v) is greater than the max_svid_ttl. "+
+				"SVIDs will be issued with a TTL of %v.", printDuration(defaultTTL), printDuration(maxTTL))
+		}
+	}
+
 	if c.Server.CATTL != "" {
 		ttl, err := time.ParseDuration(c.Server.CATTL)
 		if err != nil {
@@ -485,9 +887,47 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.CATTL = ttl
 	}
 
+	sc.CAUpstreamFallback = c.Server.CAUpstreamFallback
+
+	if c.Server.CAPreparationLeadTime != "" {
+		leadTime, err := time.ParseDuration(c.Server.CAPreparationLeadTime)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse ca_preparation_lead_time %q: %w", c.Server.CAPreparationLeadTime, err)
+		}
+		if leadTime < 0 {
+			return nil, fmt.Errorf("ca_preparation_lead_time %q must not be negative", c.Server.CAPreparationLeadTime)
+		}
+		sc.CAPreparationLeadTime = leadTime
+	}
+
+	if c.Server.CAActivationOverlap != "" {
+		overlap, err := time.ParseDuration(c.Server.CAActivationOverlap)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse ca_activation_overlap %q: %w", c.Server.CAActivationOverlap, err)
+		}
+		if overlap < 0 {
+			return nil, fmt.Errorf("ca_activation_overlap %q must not be negative", c.Server.CAActivationOverlap)
+		}
+		if sc.CAPreparationLeadTime > 0 && overlap > sc.CAPreparationLeadTime {
+			return nil, fmt.Errorf("ca_activation_overlap %q must not be greater than ca_preparation_lead_time %q", c.Server.CAActivationOverlap, c.Server.CAPreparationLeadTime)
+		}
+		sc.CAActivationOverlap = overlap
+	}
+
 	// If the configured TTLs can lead to surprises, then do our best to log an
 	// accurate message and guide the user to resolution
-	if !hasCompatibleTTLs(sc.CATTL, sc.SVIDTTL) {
+	switch {
+	case sc.CAActivationOverlap > 0:
+		// SVIDs signed by an X509 CA just before the next one is activated
+		// are only guaranteed to be valid until the old X509 CA expires.
+		if sc.SVIDTTL > sc.CAActivationOverlap {
+			sc.Log.Warnf("The default_svid_ttl is greater than the ca_activation_overlap. "+
+				"SVIDs with shorter lifetimes may be issued. "+
+				"Please set the default_svid_ttl to %v or less, or the ca_activation_overlap to %v or more, "+
+				"to guarantee the full default_svid_ttl lifetime when CA rotations are scheduled.",
+				printDuration(sc.CAActivationOverlap), printDuration(sc.SVIDTTL))
+		}
+	case !hasCompatibleTTLs(sc.CATTL, sc.SVIDTTL):
 		msgCATTLTooSmall := fmt.Sprintf(
 			"The default_svid_ttl is too high for the configured ca_ttl value. "+
 				"SVIDs with shorter lifetimes may be issued. "+
@@ -563,7 +1003,11 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.CASubject = defaultCASubject
 	}
 
+	sc.CAPathLen = c.Server.CAPathLen
+	sc.DownstreamCAPathLen = c.Server.DownstreamCAPathLen
+
 	sc.PluginConfigs = *c.Plugins
+	sc.RequirePluginChecksums = c.Server.RequirePluginChecksums
 	sc.Telemetry = c.Telemetry
 	sc.HealthChecks = c.HealthChecks
 
@@ -585,6 +1029,21 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.CacheReloadInterval = interval
 	}
 
+	if c.Server.Experimental.BundleCacheExpiry != "" {
+		expiry, err := time.ParseDuration(c.Server.Experimental.BundleCacheExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bundle cache expiry: %w", err)
+		}
+		if expiry <= 0 {
+			return nil, errors.New("bundle cache expiry must be greater than zero")
+		}
+		sc.BundleCacheExpiry = expiry
+	}
+
+	sc.AdminReadAfterWrite = c.Server.Experimental.AdminReadAfterWrite
+
+	sc.LeaderElection = c.Server.Experimental.LeaderElection
+
 	sc.AuthOpaPolicyEngineConfig = c.Server.Experimental.AuthOpaPolicyEngine
 
 	for _, f := range c.Server.Experimental.Flags {
@@ -635,6 +1094,137 @@ func parseBundleEndpointProfile(config federatesWithConfig) (trustDomainConfig *
 	}, nil
 }
 
+func parseWebhookConfig(name string, c webhookConfig) (*attestationwebhook.Config, error) {
+	u, err := url.Parse(c.URL)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("could not parse url: %w", err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return nil, fmt.Errorf("url must use the HTTP or HTTPS protocol; URL found: %q", c.URL)
+	case u.Host == "":
+		return nil, fmt.Errorf("url must have a host; URL found: %q", c.URL)
+	}
+
+	webhook := &attestationwebhook.Config{
+		Name: name,
+		URL:  c.URL,
+	}
+	if c.Timeout != "" {
+		webhook.Timeout, err = time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse timeout %q: %w", c.Timeout, err)
+		}
+	}
+	return webhook, nil
+}
+
+func parseDownstreamWebhookConfig(c webhookConfig) (*downstreamwebhook.Config, error) {
+	webhook, err := parseWebhookConfig("", c)
+	if err != nil {
+		return nil, err
+	}
+	return &downstreamwebhook.Config{
+		URL:     webhook.URL,
+		Timeout: webhook.Timeout,
+	}, nil
+}
+
+func parseTLSPolicy(minVersion, maxVersion string, cipherSuites []string) (tlspolicy.Policy, error) {
+	var policy tlspolicy.Policy
+	var err error
+	policy.MinVersion, err = tlspolicy.ParseVersion(minVersion)
+	if err != nil {
+		return tlspolicy.Policy{}, fmt.Errorf("invalid tls_min_version: %w", err)
+	}
+	policy.MaxVersion, err = tlspolicy.ParseVersion(maxVersion)
+	if err != nil {
+		return tlspolicy.Policy{}, fmt.Errorf("invalid tls_max_version: %w", err)
+	}
+	policy.CipherSuites, err = tlspolicy.ParseCipherSuites(cipherSuites)
+	if err != nil {
+		return tlspolicy.Policy{}, fmt.Errorf("invalid tls_cipher_suites: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return tlspolicy.Policy{}, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	return policy, nil
+}
+
+func parseListenerConfig(c listenerConfig) (*endpoints.TCPListener, error) {
+	ip := net.ParseIP(c.BindAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("could not parse bind_address %q", c.BindAddress)
+	}
+	if c.BindPort == 0 {
+		return nil, errors.New("bind_port must be configured")
+	}
+
+	tlsPolicy, err := parseTLSPolicy(c.TLSMinVersion, c.TLSMaxVersion, c.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	var attestationCAs []*x509.Certificate
+	if c.AttestationCABundlePath != "" {
+		cas, err := pemutil.LoadCertificates(c.AttestationCABundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load attestation_ca_bundle_path: %w", err)
+		}
+		attestationCAs = cas
+	}
+
+	return &endpoints.TCPListener{
+		Addr: &net.TCPAddr{
+			IP:   ip,
+			Port: c.BindPort,
+		},
+		TLSPolicy:         tlsPolicy,
+		RequireClientCert: c.RequireClientCert,
+		AttestationCAs:    attestationCAs,
+	}, nil
+}
+
+func parseGRPCConfig(c grpcConfig) (*endpoints.GRPCConfig, error) {
+	gc := &endpoints.GRPCConfig{}
+
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{name: "keepalive_min_time", value: c.KeepaliveMinTime, dest: &gc.KeepaliveMinTime},
+		{name: "keepalive_time", value: c.KeepaliveTime, dest: &gc.KeepaliveTime},
+		{name: "keepalive_timeout", value: c.KeepaliveTimeout, dest: &gc.KeepaliveTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse grpc %s %q: %w", d.name, d.value, err)
+		}
+		if duration < 0 {
+			return nil, fmt.Errorf("grpc %s must not be negative", d.name)
+		}
+		*d.dest = duration
+	}
+
+	switch {
+	case c.MaxConcurrentStreams < 0:
+		return nil, errors.New("grpc max_concurrent_streams must not be negative")
+	case c.MaxRecvMessageSize < 0:
+		return nil, errors.New("grpc max_recv_message_size must not be negative")
+	case c.MaxSendMessageSize < 0:
+		return nil, errors.New("grpc max_send_message_size must not be negative")
+	}
+	gc.MaxConcurrentStreams = uint32(c.MaxConcurrentStreams)
+	gc.MaxRecvMsgSize = c.MaxRecvMessageSize
+	gc.MaxSendMsgSize = c.MaxSendMessageSize
+
+	return gc, nil
+}
+
 func validateConfig(c *Config) error {
 	if c.Server == nil {
 		return errors.New("server section must be configured")
@@ -656,6 +1246,56 @@ func validateConfig(c *Config) error {
 		return errors.New("plugins section must be configured")
 	}
 
+	if c.Server.AuditLogFile != "" && !c.Server.AuditLogEnabled {
+		return errors.New("audit_log_file requires audit_log_enabled to be set")
+	}
+
+	if sl := c.Server.AuditLogSyslog; sl != nil {
+		if !c.Server.AuditLogEnabled {
+			return errors.New("audit_log_syslog requires audit_log_enabled to be set")
+		}
+		if (sl.Network == "") != (sl.Address == "") {
+			return errors.New("audit_log_syslog.network and audit_log_syslog.address must be set together")
+		}
+	}
+
+	if ts := c.Server.AuditLogTimestamping; ts != nil {
+		if c.Server.AuditLogFile == "" {
+			return errors.New("audit_log_timestamping requires audit_log_file to be set")
+		}
+		if u, err := url.Parse(ts.TSAURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("audit_log_timestamping.tsa_url must be an http or https URL")
+		}
+		if ts.BatchSize < 0 {
+			return errors.New("audit_log_timestamping.batch_size must not be negative")
+		}
+	}
+
+	if c.Server.CAPathLen != nil && *c.Server.CAPathLen < 0 {
+		return errors.New("ca_path_len must not be negative")
+	}
+
+	if c.Server.DownstreamCAPathLen != nil {
+		if *c.Server.DownstreamCAPathLen < 0 {
+			return errors.New("downstream_ca_path_len must not be negative")
+		}
+		if c.Server.CAPathLen != nil && *c.Server.DownstreamCAPathLen >= *c.Server.CAPathLen {
+			return errors.New("downstream_ca_path_len must be less than ca_path_len")
+		}
+	}
+
+	if err := ca.ValidateDenylistPatterns(c.Server.SVIDDenylist); err != nil {
+		return fmt.Errorf("invalid svid_denylist: %w", err)
+	}
+
+	if c.Server.IssuanceQuota.SigningsPerMinutePerAgent < 0 {
+		return errors.New("issuance_quota.signings_per_minute_per_agent must not be negative")
+	}
+
+	if c.Server.IssuanceQuota.SigningsPerMinutePerEntry < 0 {
+		return errors.New("issuance_quota.signings_per_minute_per_entry must not be negative")
+	}
+
 	if c.Server.Federation != nil {
 		if c.Server.Federation.BundleEndpoint != nil &&
 			c.Server.Federation.BundleEndpoint.ACME != nil {
@@ -697,18 +1337,80 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 	}
 
 	if c.Server != nil {
-		if len(c.Server.UnusedKeys) != 0 {
-			detectedUnknown("server", c.Server.UnusedKeys)
+		// The HCL decoder reports repeated additional_listener,
+		// entry_namespace, attestation_webhook and jwt_audience_restriction
+		// blocks, and their labels, as unused keys of the server section
+		var unusedKeys []string
+		for _, key := range c.Server.UnusedKeys {
+			_, isListener := c.Server.AdditionalListeners[key]
+			_, isNamespace := c.Server.EntryNamespaces[key]
+			_, isWebhook := c.Server.AttestationWebhooks[key]
+			_, isRestriction := c.Server.JWTAudienceRestrictions[key]
+			isBlock := key == "additional_listener" || key == "entry_namespace" || key == "attestation_webhook" || key == "jwt_audience_restriction"
+			if !isListener && !isNamespace && !isWebhook && !isRestriction && !isBlock {
+				unusedKeys = append(unusedKeys, key)
+			}
+		}
+		if len(unusedKeys) != 0 {
+			detectedUnknown("server", unusedKeys)
 		}
 
 		if cs := c.Server.CASubject; cs != nil && len(cs.UnusedKeys) != 0 {
 			detectedUnknown("ca_subject", cs.UnusedKeys)
 		}
 
+		for name, listener := range c.Server.AdditionalListeners {
+			if len(listener.UnusedKeys) != 0 {
+				detectedUnknown(fmt.Sprintf("additional_listener %q", name), listener.UnusedKeys)
+			}
+		}
+
+		for name, ns := range c.Server.EntryNamespaces {
+			if len(ns.UnusedKeys) != 0 {
+				detectedUnknown(fmt.Sprintf("entry_namespace %q", name), ns.UnusedKeys)
+			}
+		}
+
+		for name, webhook := range c.Server.AttestationWebhooks {
+			if len(webhook.UnusedKeys) != 0 {
+				detectedUnknown(fmt.Sprintf("attestation_webhook %q", name), webhook.UnusedKeys)
+			}
+		}
+
+		for name, restriction := range c.Server.JWTAudienceRestrictions {
+			if len(restriction.UnusedKeys) != 0 {
+				detectedUnknown(fmt.Sprintf("jwt_audience_restriction %q", name), restriction.UnusedKeys)
+			}
+		}
+
+		if g := c.Server.GRPC; len(g.UnusedKeys) != 0 {
+			detectedUnknown("grpc", g.UnusedKeys)
+		}
+
 		if rl := c.Server.RateLimit; len(rl.UnusedKeys) != 0 {
 			detectedUnknown("ratelimit", rl.UnusedKeys)
 		}
 
+		if ts := c.Server.AuditLogTimestamping; ts != nil && len(ts.UnusedKeys) != 0 {
+			detectedUnknown("audit_log_timestamping", ts.UnusedKeys)
+		}
+
+		if sl := c.Server.AuditLogSyslog; sl != nil && len(sl.UnusedKeys) != 0 {
+			detectedUnknown("audit_log_syslog", sl.UnusedKeys)
+		}
+
+		if iq := c.Server.IssuanceQuota; len(iq.UnusedKeys) != 0 {
+			detectedUnknown("issuance_quota", iq.UnusedKeys)
+		}
+
+		if p := c.Server.SPIFFEIDPathPolicy; p != nil && len(p.UnusedKeys) != 0 {
+			detectedUnknown("spiffe_id_path_policy", p.UnusedKeys)
+		}
+
+		if w := c.Server.DownstreamAuthzWebhook; w != nil && len(w.UnusedKeys) != 0 {
+			detectedUnknown("downstream_authorization_webhook", w.UnusedKeys)
+		}
+
 		// TODO: Re-enable unused key detection for experimental config. See
 		// https://github.com/spiffe/spire/issues/1101 for more information
 		//
@@ -2,8 +2,10 @@ package run
 
 import (
 	"bytes"
+	"crypto/tls"
 	"crypto/x509/pkix"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,9 +19,17 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
+	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
+	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -64,6 +74,18 @@ func TestParseConfigGood(t *testing.T) {
 	_, ok := trustDomainConfig.EndpointProfile.(bundleClient.HTTPSWebProfile)
 	assert.True(t, ok)
 	assert.True(t, c.Server.AuditLogEnabled)
+	assert.Equal(t, map[string]listenerConfig{
+		"ipv6": {
+			BindAddress:   "::1",
+			BindPort:      8082,
+			TLSMinVersion: "1.3",
+		},
+		"internal": {
+			BindAddress:       "127.0.0.2",
+			BindPort:          8083,
+			RequireClientCert: true,
+		},
+	}, c.Server.AdditionalListeners)
 	testParseConfigGoodOS(t, c)
 
 	// Check for plugins configurations
@@ -453,465 +475,1354 @@ func TestNewServerConfig(t *testing.T) {
 			},
 		},
 		{
-			msg: "data_dir should be correctly configured",
+			msg: "additional listeners should be correctly parsed",
 			input: func(c *Config) {
-				c.Server.DataDir = "foo"
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"ipv6": {
+						BindAddress:   "::",
+						BindPort:      8082,
+						TLSMinVersion: "1.3",
+						TLSMaxVersion: "1.3",
+					},
+					"internal": {
+						BindAddress:       "10.0.0.1",
+						BindPort:          8083,
+						RequireClientCert: true,
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "foo", c.DataDir)
+				require.Equal(t, []endpoints.TCPListener{
+					{
+						Addr:              &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8083},
+						RequireClientCert: true,
+					},
+					{
+						Addr: &net.TCPAddr{IP: net.ParseIP("::"), Port: 8082},
+						TLSPolicy: tlspolicy.Policy{
+							MinVersion: tls.VersionTLS13,
+							MaxVersion: tls.VersionTLS13,
+						},
+					},
+				}, c.AdditionalListeners)
 			},
 		},
 		{
-			msg: "trust_domain should be correctly parsed",
+			msg: "grpc options should be correctly parsed",
 			input: func(c *Config) {
-				c.Server.TrustDomain = "foo"
+				c.Server.GRPC = grpcConfig{
+					KeepaliveMinTime:     "1m",
+					KeepaliveTime:        "2m",
+					KeepaliveTimeout:     "20s",
+					MaxConcurrentStreams: 100,
+					MaxRecvMessageSize:   16 << 20,
+					MaxSendMessageSize:   32 << 20,
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "spiffe://foo", c.TrustDomain.IDString())
+				require.Equal(t, endpoints.GRPCConfig{
+					KeepaliveMinTime:     time.Minute,
+					KeepaliveTime:        2 * time.Minute,
+					KeepaliveTimeout:     20 * time.Second,
+					MaxConcurrentStreams: 100,
+					MaxRecvMsgSize:       16 << 20,
+					MaxSendMsgSize:       32 << 20,
+				}, c.GRPC)
 			},
 		},
 		{
-			msg:         "invalid trust_domain should return an error",
-			expectError: true,
+			msg: "grpc options should default to zero",
 			input: func(c *Config) {
-				c.Server.TrustDomain = "i'm invalid"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Nil(t, c)
+				require.Equal(t, endpoints.GRPCConfig{}, c.GRPC)
 			},
 		},
 		{
-			msg: "jwt_issuer is correctly configured",
+			msg:         "invalid grpc keepalive_time should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.JWTIssuer = "ISSUER"
+				c.Server.GRPC.KeepaliveTime = "not-a-duration"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "ISSUER", c.JWTIssuer)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "logger gets set correctly",
+			msg:         "negative grpc max_recv_message_size should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.LogLevel = "WARN"
-				c.Server.LogFormat = "TEXT"
+				c.Server.GRPC.MaxRecvMessageSize = -1
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.NotNil(t, c.Log)
-
-				l := c.Log.(*log.Logger)
-				require.Equal(t, logrus.WarnLevel, l.Level)
-				require.IsType(t, &logrus.TextFormatter{}, l.Formatter)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "log_level and log_format are case insensitive",
+			msg: "additional listener attestation_ca_bundle_path should be correctly loaded",
 			input: func(c *Config) {
-				c.Server.LogLevel = "wArN"
-				c.Server.LogFormat = "TeXt"
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"attestation": {
+						BindAddress:             "::",
+						BindPort:                8082,
+						AttestationCABundlePath: "../../../../test/fixture/certs/ca.pem",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.NotNil(t, c.Log)
-
-				l := c.Log.(*log.Logger)
-				require.Equal(t, logrus.WarnLevel, l.Level)
-				require.IsType(t, &logrus.TextFormatter{}, l.Formatter)
+				cas, err := pemutil.LoadCertificates("../../../../test/fixture/certs/ca.pem")
+				require.NoError(t, err)
+				require.Len(t, c.AdditionalListeners, 1)
+				require.Equal(t, cas, c.AdditionalListeners[0].AttestationCAs)
 			},
 		},
 		{
-			msg:         "invalid log_level returns an error",
+			msg:         "additional listener with invalid attestation_ca_bundle_path should return an error",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.LogLevel = "not-a-valid-level"
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"bad": {
+						BindAddress:             "::",
+						BindPort:                8082,
+						AttestationCABundlePath: "/does/not/exist",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg:         "invalid log_format returns an error",
+			msg:         "additional listener with invalid bind_address should return an error",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.LogFormat = "not-a-valid-format"
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"bad": {
+						BindAddress: "this-is-not-an-ip-address",
+						BindPort:    8082,
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "bundle endpoint is parsed and configured correctly",
+			msg:         "additional listener without bind_port should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.Federation = &federationConfig{
-					BundleEndpoint: &bundleEndpointConfig{
-						Address: "192.168.1.1",
-						Port:    1337,
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"bad": {
+						BindAddress: "::",
 					},
 				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, "192.168.1.1", c.Federation.BundleEndpoint.Address.IP.String())
-				require.Equal(t, 1337, c.Federation.BundleEndpoint.Address.Port)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "bundle federates with section is parsed and configured correctly",
+			msg:         "additional listener with cipher suites for TLS 1.3 only should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.Federation = &federationConfig{
-					FederatesWith: map[string]federatesWithConfig{
-						"domain1.test": httpsSPIFFEConfigTest(t),
-						"domain2.test": webPKIConfigTest(t),
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"bad": {
+						BindAddress:     "::",
+						BindPort:        8082,
+						TLSMinVersion:   "1.3",
+						TLSCipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
 					},
 				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{
-					spiffeid.RequireTrustDomainFromString("domain1.test"): {
-						EndpointURL: "https://192.168.1.1:1337",
-						EndpointProfile: bundleClient.HTTPSSPIFFEProfile{
-							EndpointSPIFFEID: spiffeid.RequireFromString("spiffe://domain1.test/bundle/endpoint"),
-						},
-					},
-					spiffeid.RequireTrustDomainFromString("domain2.test"): {
-						EndpointURL:     "https://192.168.1.1:1337",
-						EndpointProfile: bundleClient.HTTPSWebProfile{},
-					},
-				}, c.Federation.FederatesWith)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "default_svid_ttl is correctly parsed",
+			msg: "TLS settings of the server listener should be correctly parsed",
 			input: func(c *Config) {
-				c.Server.DefaultSVIDTTL = "1m"
+				c.Server.TLSMinVersion = "1.2"
+				c.Server.TLSMaxVersion = "1.3"
+				c.Server.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, time.Minute, c.SVIDTTL)
+				require.Equal(t, tlspolicy.Policy{
+					MinVersion:   tls.VersionTLS12,
+					MaxVersion:   tls.VersionTLS13,
+					CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+				}, c.BindAddressTLSPolicy)
 			},
 		},
 		{
-			msg:         "invalid default_svid_ttl returns an error",
+			msg: "TLS settings of the server listener are not set by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, tlspolicy.Policy{}, c.BindAddressTLSPolicy)
+			},
+		},
+		{
+			msg:         "server listener with tls_max_version lower than tls_min_version should return an error",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.DefaultSVIDTTL = "b"
+				c.Server.TLSMinVersion = "1.3"
+				c.Server.TLSMaxVersion = "1.2"
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "ca_key_type and jwt_key_type are set as default",
+			msg:         "server listener with insecure cipher suites should return an error",
+			expectError: true,
 			input: func(c *Config) {
+				c.Server.TLSCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "rsa-2048 ca_key_type is correctly parsed and is set as default for jwt key",
+			msg:         "additional listener with unsupported tls_min_version should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.CAKeyType = "rsa-2048"
+				c.Server.AdditionalListeners = map[string]listenerConfig{
+					"bad": {
+						BindAddress:   "::",
+						BindPort:      8082,
+						TLSMinVersion: "1.1",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.RSA2048, c.CAKeyType)
-				require.Equal(t, keymanager.RSA2048, c.JWTKeyType)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "rsa-4096 ca_key_type is correctly parsed and is set as default for jwt key",
+			msg: "data_dir should be correctly configured",
 			input: func(c *Config) {
-				c.Server.CAKeyType = "rsa-4096"
+				c.Server.DataDir = "foo"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.RSA4096, c.CAKeyType)
-				require.Equal(t, keymanager.RSA4096, c.JWTKeyType)
+				require.Equal(t, "foo", c.DataDir)
 			},
 		},
 		{
-			msg: "ec-p256 ca_key_type is correctly parsed and is set as default for jwt key",
+			msg: "shutdown_drain_timeout should be correctly parsed",
 			input: func(c *Config) {
-				c.Server.CAKeyType = "ec-p256"
+				c.Server.ShutdownDrainTimeout = "30s"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+				require.Equal(t, 30*time.Second, c.ShutdownDrainTimeout)
 			},
 		},
 		{
-			msg: "ec-p384 ca_key_type is correctly parsed and is set as default for jwt key",
+			msg:         "invalid shutdown_drain_timeout should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.CAKeyType = "ec-p384"
+				c.Server.ShutdownDrainTimeout = "b"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP384, c.CAKeyType)
-				require.Equal(t, keymanager.ECP384, c.JWTKeyType)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg:         "unsupported ca_key_type is rejected",
+			msg: "uds_mode and uds_group should be correctly parsed",
+			input: func(c *Config) {
+				c.Server.UDSMode = "0660"
+				c.Server.UDSGroup = "spire"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, os.FileMode(0660), c.BindLocalAddressMode)
+				require.Equal(t, "spire", c.BindLocalAddressGroup)
+			},
+		},
+		{
+			msg:         "invalid uds_mode should return an error",
 			expectError: true,
 			input: func(c *Config) {
-				c.Server.CAKeyType = "rsa-1024"
+				c.Server.UDSMode = "rw-rw----"
 			},
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "rsa-2048 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			msg: "trust_domain should be correctly parsed",
 			input: func(c *Config) {
-				c.Server.JWTKeyType = "rsa-2048"
+				c.Server.TrustDomain = "foo"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.RSA2048, c.JWTKeyType)
+				require.Equal(t, "spiffe://foo", c.TrustDomain.IDString())
 			},
 		},
 		{
-			msg: "rsa-4096 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			msg:         "invalid trust_domain should return an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.JWTKeyType = "rsa-4096"
+				c.Server.TrustDomain = "i'm invalid"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.RSA4096, c.JWTKeyType)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "ec-p256 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			msg: "jwt_issuer is correctly configured",
 			input: func(c *Config) {
-				c.Server.JWTKeyType = "ec-p256"
+				c.Server.JWTIssuer = "ISSUER"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+				require.Equal(t, "ISSUER", c.JWTIssuer)
 			},
 		},
 		{
-			msg: "ec-p384 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			msg: "logger gets set correctly",
 			input: func(c *Config) {
-				c.Server.JWTKeyType = "ec-p384"
+				c.Server.LogLevel = "WARN"
+				c.Server.LogFormat = "TEXT"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.ECP256, c.CAKeyType)
-				require.Equal(t, keymanager.ECP384, c.JWTKeyType)
+				require.NotNil(t, c.Log)
+
+				l := c.Log.(*log.Logger)
+				require.Equal(t, logrus.WarnLevel, l.Level)
+				require.IsType(t, &logrus.TextFormatter{}, l.Formatter)
+				require.False(t, l.ReportCaller)
 			},
 		},
 		{
-			msg:         "unsupported jwt_key_type is rejected",
-			expectError: true,
+			msg: "log_source_location adds the caller to log entries",
 			input: func(c *Config) {
-				c.Server.JWTKeyType = "rsa-1024"
+				c.Server.LogSourceLocation = true
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Nil(t, c)
+				require.NotNil(t, c.Log)
+
+				l := c.Log.(*log.Logger)
+				require.True(t, l.ReportCaller)
 			},
 		},
 		{
-			msg: "override jwt_key_type from the default ca_key_type",
+			msg: "ca_upstream_fallback is correctly set",
 			input: func(c *Config) {
-				c.Server.CAKeyType = "rsa-2048"
-				c.Server.JWTKeyType = "ec-p256"
+				c.Server.CAUpstreamFallback = true
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, keymanager.RSA2048, c.CAKeyType)
-				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+				require.True(t, c.CAUpstreamFallback)
 			},
 		},
 		{
-			msg: "ca_ttl is correctly parsed",
+			msg: "require_plugin_checksums is correctly set",
 			input: func(c *Config) {
-				c.Server.CATTL = "1h"
+				c.Server.RequirePluginChecksums = true
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, time.Hour, c.CATTL)
+				require.True(t, c.RequirePluginChecksums)
 			},
 		},
 		{
-			msg:         "invalid ca_ttl returns an error",
-			expectError: true,
+			msg: "log_level and log_format are case insensitive",
 			input: func(c *Config) {
-				c.Server.CATTL = "b"
+				c.Server.LogLevel = "wArN"
+				c.Server.LogFormat = "TeXt"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Nil(t, c)
+				require.NotNil(t, c.Log)
+
+				l := c.Log.(*log.Logger)
+				require.Equal(t, logrus.WarnLevel, l.Level)
+				require.IsType(t, &logrus.TextFormatter{}, l.Formatter)
 			},
 		},
 		{
-			msg: "ca_subject is defaulted when unset",
+			msg:         "invalid log_level returns an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.CASubject = nil
+				c.Server.LogLevel = "not-a-valid-level"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, defaultCASubject, c.CASubject)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "ca_subject is defaulted when set but empty",
+			msg:         "invalid log_format returns an error",
+			expectError: true,
 			input: func(c *Config) {
-				c.Server.CASubject = &caSubjectConfig{}
+				c.Server.LogFormat = "not-a-valid-format"
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, defaultCASubject, c.CASubject)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "ca_subject is overridable",
+			msg: "bundle endpoint is parsed and configured correctly",
 			input: func(c *Config) {
-				c.Server.CASubject = &caSubjectConfig{
-					Organization: []string{"foo"},
-					Country:      []string{"us"},
-					CommonName:   "bar",
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						Address: "192.168.1.1",
+						Port:    1337,
+					},
 				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, pkix.Name{
-					Organization: []string{"foo"},
-					Country:      []string{"us"},
-					CommonName:   "bar",
-				}, c.CASubject)
+				require.Equal(t, "192.168.1.1", c.Federation.BundleEndpoint.Address.IP.String())
+				require.Equal(t, 1337, c.Federation.BundleEndpoint.Address.Port)
 			},
 		},
 		{
-			msg: "attestation rate limit is on by default",
+			msg: "bundle endpoint refresh hint is parsed correctly",
 			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						Address:     "192.168.1.1",
+						Port:        1337,
+						RefreshHint: "10m",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.True(t, c.RateLimit.Attestation)
+				require.Equal(t, 10*time.Minute, c.Federation.BundleEndpoint.RefreshHint)
 			},
 		},
 		{
-			msg: "attestation rate limits can be explicitly disabled",
+			msg:         "invalid bundle endpoint refresh hint returns an error",
+			expectError: true,
 			input: func(c *Config) {
-				value := false
-				c.Server.RateLimit.Attestation = &value
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						RefreshHint: "foo",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.False(t, c.RateLimit.Attestation)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "attestation rate limits can be explicitly enabled",
+			msg: "bundle endpoint TLS settings are parsed correctly",
 			input: func(c *Config) {
-				value := true
-				c.Server.RateLimit.Attestation = &value
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						Address:       "192.168.1.1",
+						Port:          1337,
+						TLSMinVersion: "1.3",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.True(t, c.RateLimit.Attestation)
+				require.Equal(t, tlspolicy.Policy{MinVersion: tls.VersionTLS13}, c.Federation.BundleEndpoint.TLSPolicy)
 			},
 		},
 		{
-			msg: "signing rate limit is on by default",
+			msg:         "invalid bundle endpoint TLS settings return an error",
+			expectError: true,
 			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						Address:       "192.168.1.1",
+						Port:          1337,
+						TLSMaxVersion: "1.0",
+					},
+				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.True(t, c.RateLimit.Signing)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "signing rate limit can be explicitly disabled",
+			msg:         "non-positive bundle endpoint refresh hint returns an error",
+			expectError: true,
 			input: func(c *Config) {
-				value := false
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						RefreshHint: "0s",
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "bundle federates with section is parsed and configured correctly",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": httpsSPIFFEConfigTest(t),
+						"domain2.test": webPKIConfigTest(t),
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{
+					spiffeid.RequireTrustDomainFromString("domain1.test"): {
+						EndpointURL: "https://192.168.1.1:1337",
+						EndpointProfile: bundleClient.HTTPSSPIFFEProfile{
+							EndpointSPIFFEID: spiffeid.RequireFromString("spiffe://domain1.test/bundle/endpoint"),
+						},
+					},
+					spiffeid.RequireTrustDomainFromString("domain2.test"): {
+						EndpointURL:     "https://192.168.1.1:1337",
+						EndpointProfile: bundleClient.HTTPSWebProfile{},
+					},
+				}, c.Federation.FederatesWith)
+			},
+		},
+		{
+			msg: "default_svid_ttl is correctly parsed",
+			input: func(c *Config) {
+				c.Server.DefaultSVIDTTL = "1m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Minute, c.SVIDTTL)
+			},
+		},
+		{
+			msg:         "invalid default_svid_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.DefaultSVIDTTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "bundle_refresh_hint is correctly parsed",
+			input: func(c *Config) {
+				c.Server.BundleRefreshHint = "5m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 5*time.Minute, c.BundleRefreshHint)
+			},
+		},
+		{
+			msg:         "invalid bundle_refresh_hint returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.BundleRefreshHint = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "bundle_refresh_hint must be positive",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.BundleRefreshHint = "-1m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "max_svid_ttl is correctly parsed",
+			input: func(c *Config) {
+				c.Server.DefaultSVIDTTL = "1m"
+				c.Server.MaxSVIDTTL = "2h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 2*time.Hour, c.MaxSVIDTTL)
+			},
+		},
+		{
+			msg:         "invalid max_svid_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MaxSVIDTTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "max_svid_ttl must be positive",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.MaxSVIDTTL = "0s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "warn when default_svid_ttl is greater than max_svid_ttl",
+			input: func(c *Config) {
+				c.Server.MaxSVIDTTL = "30m"
+			},
+			logOptions: func(t *testing.T) []log.Option {
+				return []log.Option{
+					func(logger *log.Logger) error {
+						logger.SetOutput(io.Discard)
+						hook := test.NewLocal(logger.Logger)
+						t.Cleanup(func() {
+							spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+								{
+									Level:   logrus.WarnLevel,
+									Message: "The default_svid_ttl (1h) is greater than the max_svid_ttl. SVIDs will be issued with a TTL of 30m.",
+								},
+							})
+						})
+						return nil
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 30*time.Minute, c.MaxSVIDTTL)
+			},
+		},
+		{
+			msg: "ca_key_type and jwt_key_type are set as default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "rsa-2048 ca_key_type is correctly parsed and is set as default for jwt key",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "rsa-2048"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.RSA2048, c.CAKeyType)
+				require.Equal(t, keymanager.RSA2048, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "rsa-4096 ca_key_type is correctly parsed and is set as default for jwt key",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "rsa-4096"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.RSA4096, c.CAKeyType)
+				require.Equal(t, keymanager.RSA4096, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ec-p256 ca_key_type is correctly parsed and is set as default for jwt key",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "ec-p256"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ec-p384 ca_key_type is correctly parsed and is set as default for jwt key",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "ec-p384"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP384, c.CAKeyType)
+				require.Equal(t, keymanager.ECP384, c.JWTKeyType)
+			},
+		},
+		{
+			msg:         "unsupported ca_key_type is rejected",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CAKeyType = "rsa-1024"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "rsa-2048 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "rsa-2048"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.RSA2048, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "rsa-4096 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "rsa-4096"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.RSA4096, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ec-p256 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "ec-p256"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ec-p384 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "ec-p384"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.ECP384, c.JWTKeyType)
+			},
+		},
+		{
+			msg:         "unsupported jwt_key_type is rejected",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "rsa-1024"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "override jwt_key_type from the default ca_key_type",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "rsa-2048"
+				c.Server.JWTKeyType = "ec-p256"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.RSA2048, c.CAKeyType)
+				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ca_ttl is correctly parsed",
+			input: func(c *Config) {
+				c.Server.CATTL = "1h"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Hour, c.CATTL)
+			},
+		},
+		{
+			msg:         "invalid ca_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CATTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "ca_subject is defaulted when unset",
+			input: func(c *Config) {
+				c.Server.CASubject = nil
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, defaultCASubject, c.CASubject)
+			},
+		},
+		{
+			msg: "ca_subject is defaulted when set but empty",
+			input: func(c *Config) {
+				c.Server.CASubject = &caSubjectConfig{}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, defaultCASubject, c.CASubject)
+			},
+		},
+		{
+			msg: "ca_subject is overridable",
+			input: func(c *Config) {
+				c.Server.CASubject = &caSubjectConfig{
+					Organization: []string{"foo"},
+					Country:      []string{"us"},
+					CommonName:   "bar",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, pkix.Name{
+					Organization: []string{"foo"},
+					Country:      []string{"us"},
+					CommonName:   "bar",
+				}, c.CASubject)
+			},
+		},
+		{
+			msg: "attestation rate limit is on by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.RateLimit.Attestation)
+			},
+		},
+		{
+			msg: "attestation rate limits can be explicitly disabled",
+			input: func(c *Config) {
+				value := false
+				c.Server.RateLimit.Attestation = &value
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.False(t, c.RateLimit.Attestation)
+			},
+		},
+		{
+			msg: "attestation rate limits can be explicitly enabled",
+			input: func(c *Config) {
+				value := true
+				c.Server.RateLimit.Attestation = &value
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.RateLimit.Attestation)
+			},
+		},
+		{
+			msg: "signing rate limit is on by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.RateLimit.Signing)
+			},
+		},
+		{
+			msg: "signing rate limit can be explicitly disabled",
+			input: func(c *Config) {
+				value := false
 				c.Server.RateLimit.Signing = &value
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.False(t, c.RateLimit.Signing)
+				require.False(t, c.RateLimit.Signing)
+			},
+		},
+		{
+			msg: "signing rate limit can be explicitly enabled",
+			input: func(c *Config) {
+				value := true
+				c.Server.RateLimit.Signing = &value
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.RateLimit.Signing)
+			},
+		},
+		{
+			msg: "warn_on_long_trust_domain",
+			input: func(c *Config) {
+				c.Server.TrustDomain = strings.Repeat("a", 256)
+			},
+			logOptions: func(t *testing.T) []log.Option {
+				return []log.Option{
+					func(logger *log.Logger) error {
+						logger.SetOutput(io.Discard)
+						hook := test.NewLocal(logger.Logger)
+						t.Cleanup(func() {
+							spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+								{
+									Data:  map[string]interface{}{"trust_domain": strings.Repeat("a", 256)},
+									Level: logrus.WarnLevel,
+									Message: "Configured trust domain name should be less than 255 characters to be " +
+										"SPIFFE compliant; a longer trust domain name may impact interoperability",
+								},
+							})
+						})
+						return nil
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				assert.NotNil(t, c)
+			},
+		},
+		{
+			msg: "cache_reload_interval is correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.CacheReloadInterval = "1m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Minute, c.CacheReloadInterval)
+			},
+		},
+		{
+			msg:         "invalid cache_reload_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.CacheReloadInterval = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "ca_path_len and downstream_ca_path_len are correctly set",
+			input: func(c *Config) {
+				c.Server.CAPathLen = intPtr(2)
+				c.Server.DownstreamCAPathLen = intPtr(0)
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, intPtr(2), c.CAPathLen)
+				require.Equal(t, intPtr(0), c.DownstreamCAPathLen)
+			},
+		},
+		{
+			msg: "ca_path_len and downstream_ca_path_len are unset by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.CAPathLen)
+				require.Nil(t, c.DownstreamCAPathLen)
+			},
+		},
+		{
+			msg: "bundle_cache_expiry is correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.BundleCacheExpiry = "10s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 10*time.Second, c.BundleCacheExpiry)
+			},
+		},
+		{
+			msg:         "invalid bundle_cache_expiry returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.BundleCacheExpiry = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "non-positive bundle_cache_expiry returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.BundleCacheExpiry = "0s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "admin_read_after_write is enabled",
+			input: func(c *Config) {
+				c.Server.Experimental.AdminReadAfterWrite = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.AdminReadAfterWrite)
+			},
+		},
+		{
+			msg: "leader_election is enabled",
+			input: func(c *Config) {
+				c.Server.Experimental.LeaderElection = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.LeaderElection)
+			},
+		},
+		{
+			msg: "audit_log_enabled is enabled",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.AuditLogEnabled)
+			},
+		},
+		{
+			msg: "audit_log_enabled is disabled",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = false
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.False(t, c.AuditLogEnabled)
+			},
+		},
+		{
+			msg: "audit log sinks are configured but not opened",
+			input: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogFile = "/non-existent/audit.log"
+				c.Server.AuditLogTimestamping = &auditLogTimestampingConfig{
+					TSAURL:        "https://tsa.example.org",
+					BatchSize:     10,
+					BatchInterval: "30s",
+				}
+				c.Server.AuditLogSyslog = &auditLogSyslogConfig{
+					Network: "udp",
+					Address: "localhost:514",
+					Tag:     "spire",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, audit.SinkConfig{
+					File: "/non-existent/audit.log",
+					Timestamping: &audit.TimestampConfig{
+						TSAURL:        "https://tsa.example.org",
+						BatchSize:     10,
+						BatchInterval: 30 * time.Second,
+					},
+					Syslog: &audit.SyslogConfig{
+						Network: "udp",
+						Address: "localhost:514",
+						Tag:     "spire",
+					},
+				}, c.AuditLogSinks)
+			},
+		},
+		{
+			msg: "admin IDs are set",
+			input: func(c *Config) {
+				c.Server.AdminIDs = []string{
+					"spiffe://example.org/my/admin1",
+					"spiffe://example.org/my/admin2",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, []spiffeid.ID{
+					spiffeid.RequireFromString("spiffe://example.org/my/admin1"),
+					spiffeid.RequireFromString("spiffe://example.org/my/admin2"),
+				}, c.AdminIDs)
+			},
+		},
+		{
+			msg: "admin ID does not belong to the trust domain",
+			input: func(c *Config) {
+				c.Server.AdminIDs = []string{
+					"spiffe://otherdomain.test/my/admin",
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry namespaces are set",
+			input: func(c *Config) {
+				c.Server.EntryNamespaces = map[string]entryNamespaceConfig{
+					"team-a": {
+						AdminIDs:            []string{"spiffe://example.org/team-a/admin"},
+						AgentIDPathPrefixes: []string{"/spire/agent/k8s_psat/team-a/"},
+						SPIFFEIDPathPrefix:  "/team-a/",
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, []entryv1.Namespace{
+					{
+						Name:              "team-a",
+						AdminIDs:          []spiffeid.ID{spiffeid.RequireFromString("spiffe://example.org/team-a/admin")},
+						PathPrefix:        "/team-a/",
+						AgentPathPrefixes: []string{"/spire/agent/k8s_psat/team-a/"},
+					},
+				}, c.EntryNamespaces)
+			},
+		},
+		{
+			msg: "entry namespace agent ID path prefix is not an agent path",
+			input: func(c *Config) {
+				c.Server.EntryNamespaces = map[string]entryNamespaceConfig{
+					"team-a": {
+						AdminIDs:            []string{"spiffe://example.org/team-a/admin"},
+						AgentIDPathPrefixes: []string{"/team-b/"},
+						SPIFFEIDPathPrefix:  "/team-a/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry namespace path prefix does not start with a slash",
+			input: func(c *Config) {
+				c.Server.EntryNamespaces = map[string]entryNamespaceConfig{
+					"team-a": {
+						AdminIDs:           []string{"spiffe://example.org/team-a/admin"},
+						SPIFFEIDPathPrefix: "team-a/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry namespace admin ID does not belong to the trust domain",
+			input: func(c *Config) {
+				c.Server.EntryNamespaces = map[string]entryNamespaceConfig{
+					"team-a": {
+						AdminIDs:           []string{"spiffe://otherdomain.test/team-a/admin"},
+						SPIFFEIDPathPrefix: "/team-a/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry namespace admin ID scoped to more than one namespace",
+			input: func(c *Config) {
+				c.Server.EntryNamespaces = map[string]entryNamespaceConfig{
+					"team-a": {
+						AdminIDs:           []string{"spiffe://example.org/admin"},
+						SPIFFEIDPathPrefix: "/team-a/",
+					},
+					"team-b": {
+						AdminIDs:           []string{"spiffe://example.org/admin"},
+						SPIFFEIDPathPrefix: "/team-b/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt audience restrictions are set",
+			input: func(c *Config) {
+				c.Server.JWTAudienceRestrictions = map[string]jwtAudienceRestrictionConfig{
+					"payments": {
+						AllowedAudiences:   []string{"bank", "ledger"},
+						SPIFFEIDPathPrefix: "/payments/",
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, []svidv1.AudienceRestriction{
+					{
+						Name:             "payments",
+						PathPrefix:       "/payments/",
+						AllowedAudiences: []string{"bank", "ledger"},
+					},
+				}, c.JWTAudienceRestrictions)
+			},
+		},
+		{
+			msg: "jwt audience restriction path prefix does not start with a slash",
+			input: func(c *Config) {
+				c.Server.JWTAudienceRestrictions = map[string]jwtAudienceRestrictionConfig{
+					"payments": {
+						AllowedAudiences:   []string{"bank"},
+						SPIFFEIDPathPrefix: "payments/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "jwt audience restriction without allowed audiences",
+			input: func(c *Config) {
+				c.Server.JWTAudienceRestrictions = map[string]jwtAudienceRestrictionConfig{
+					"payments": {
+						SPIFFEIDPathPrefix: "/payments/",
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "svid_denylist is set",
+			input: func(c *Config) {
+				c.Server.SVIDDenylist = []string{"spiffe://example.org/compromised/*"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, []string{"spiffe://example.org/compromised/*"}, c.SVIDDenylist)
+			},
+		},
+		{
+			msg: "csr_extension_allowlist is empty by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Empty(t, c.CSRExtensionAllowlist)
+			},
+		},
+		{
+			msg: "csr_extension_allowlist is set",
+			input: func(c *Config) {
+				c.Server.CSRExtensionAllowlist = []string{"1.3.6.1.4.1.311.20.2.3"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, ca.CSRExtensionAllowlist{{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}}, c.CSRExtensionAllowlist)
+			},
+		},
+		{
+			msg:         "csr_extension_allowlist OIDs must be well formed",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CSRExtensionAllowlist = []string{"1.3.foo"}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "spiffe_id_path_policy is not set by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c.IDPathPolicy)
+			},
+		},
+		{
+			msg: "spiffe_id_path_policy is set",
+			input: func(c *Config) {
+				c.Server.SPIFFEIDPathPolicy = &spiffeIDPathPolicyConfig{
+					AllowedPrefixes: []string{"/ns/"},
+					AllowedPatterns: []string{"/team-[a-z]+/.+"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.NotNil(t, c.IDPathPolicy)
+				td := spiffeid.RequireTrustDomainFromString("example.org")
+				require.NoError(t, c.IDPathPolicy.Check(spiffeid.RequireFromPath(td, "/ns/foo/workload")))
+				require.NoError(t, c.IDPathPolicy.Check(spiffeid.RequireFromPath(td, "/team-a/workload")))
+				require.Error(t, c.IDPathPolicy.Check(spiffeid.RequireFromPath(td, "/workload")))
+			},
+		},
+		{
+			msg: "spiffe_id_path_policy prefixes must start with a slash",
+			input: func(c *Config) {
+				c.Server.SPIFFEIDPathPolicy = &spiffeIDPathPolicyConfig{
+					AllowedPrefixes: []string{"ns/"},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "spiffe_id_path_policy patterns must be well formed",
+			input: func(c *Config) {
+				c.Server.SPIFFEIDPathPolicy = &spiffeIDPathPolicyConfig{
+					AllowedPatterns: []string{"/ns/[a-z"},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "issuance quotas are not set by default",
+			input: func(c *Config) {
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, svidv1.IssuanceQuotas{}, c.IssuanceQuotas)
 			},
 		},
 		{
-			msg: "signing rate limit can be explicitly enabled",
+			msg: "issuance quotas are set",
 			input: func(c *Config) {
-				value := true
-				c.Server.RateLimit.Signing = &value
+				c.Server.IssuanceQuota.SigningsPerMinutePerAgent = 600
+				c.Server.IssuanceQuota.SigningsPerMinutePerEntry = 10
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.True(t, c.RateLimit.Signing)
+				require.Equal(t, svidv1.IssuanceQuotas{
+					SigningsPerMinutePerAgent: 600,
+					SigningsPerMinutePerEntry: 10,
+				}, c.IssuanceQuotas)
 			},
 		},
 		{
-			msg: "warn_on_long_trust_domain",
+			msg: "attestation webhooks are set",
 			input: func(c *Config) {
-				c.Server.TrustDomain = strings.Repeat("a", 256)
-			},
-			logOptions: func(t *testing.T) []log.Option {
-				return []log.Option{
-					func(logger *log.Logger) error {
-						logger.SetOutput(io.Discard)
-						hook := test.NewLocal(logger.Logger)
-						t.Cleanup(func() {
-							spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
-								{
-									Data:  map[string]interface{}{"trust_domain": strings.Repeat("a", 256)},
-									Level: logrus.WarnLevel,
-									Message: "Configured trust domain name should be less than 255 characters to be " +
-										"SPIFFE compliant; a longer trust domain name may impact interoperability",
-								},
-							})
-						})
-						return nil
+				c.Server.AttestationWebhooks = map[string]webhookConfig{
+					"siem": {
+						URL:     "https://siem.example.org/spire",
+						Timeout: "10s",
+					},
+					"audit": {
+						URL: "http://localhost:8080/attestations",
 					},
 				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				assert.NotNil(t, c)
+				require.Equal(t, []attestationwebhook.Config{
+					{
+						Name: "audit",
+						URL:  "http://localhost:8080/attestations",
+					},
+					{
+						Name:    "siem",
+						URL:     "https://siem.example.org/spire",
+						Timeout: 10 * time.Second,
+					},
+				}, c.AttestationWebhooks)
 			},
 		},
 		{
-			msg: "cache_reload_interval is correctly parsed",
+			msg: "attestation webhook url must use HTTP or HTTPS",
 			input: func(c *Config) {
-				c.Server.Experimental.CacheReloadInterval = "1m"
+				c.Server.AttestationWebhooks = map[string]webhookConfig{
+					"siem": {URL: "ftp://siem.example.org/spire"},
+				}
 			},
+			expectError: true,
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, time.Minute, c.CacheReloadInterval)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg:         "invalid cache_reload_interval returns an error",
-			expectError: true,
+			msg: "attestation webhook url must have a host",
 			input: func(c *Config) {
-				c.Server.Experimental.CacheReloadInterval = "b"
+				c.Server.AttestationWebhooks = map[string]webhookConfig{
+					"siem": {URL: "https:///spire"},
+				}
 			},
+			expectError: true,
 			test: func(t *testing.T, c *server.Config) {
 				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "audit_log_enabled is enabled",
+			msg: "attestation webhook timeout is invalid",
 			input: func(c *Config) {
-				c.Server.AuditLogEnabled = true
+				c.Server.AttestationWebhooks = map[string]webhookConfig{
+					"siem": {URL: "https://siem.example.org/spire", Timeout: "forever"},
+				}
 			},
+			expectError: true,
 			test: func(t *testing.T, c *server.Config) {
-				require.True(t, c.AuditLogEnabled)
+				require.Nil(t, c)
 			},
 		},
 		{
-			msg: "audit_log_enabled is disabled",
-			input: func(c *Config) {
-				c.Server.AuditLogEnabled = false
-			},
+			msg:   "downstream authorization webhook is not set by default",
+			input: func(c *Config) {},
 			test: func(t *testing.T, c *server.Config) {
-				require.False(t, c.AuditLogEnabled)
+				require.Nil(t, c.DownstreamAuthorizationWebhook)
 			},
 		},
 		{
-			msg: "admin IDs are set",
+			msg: "downstream authorization webhook is set",
 			input: func(c *Config) {
-				c.Server.AdminIDs = []string{
-					"spiffe://example.org/my/admin1",
-					"spiffe://example.org/my/admin2",
+				c.Server.DownstreamAuthzWebhook = &webhookConfig{
+					URL:     "https://policy.example.org/downstream",
+					Timeout: "2s",
 				}
 			},
 			test: func(t *testing.T, c *server.Config) {
-				require.Equal(t, []spiffeid.ID{
-					spiffeid.RequireFromString("spiffe://example.org/my/admin1"),
-					spiffeid.RequireFromString("spiffe://example.org/my/admin2"),
-				}, c.AdminIDs)
+				require.Equal(t, &downstreamwebhook.Config{
+					URL:     "https://policy.example.org/downstream",
+					Timeout: 2 * time.Second,
+				}, c.DownstreamAuthorizationWebhook)
 			},
 		},
 		{
-			msg: "admin ID does not belong to the trust domain",
+			msg: "downstream authorization webhook url must use HTTP or HTTPS",
 			input: func(c *Config) {
-				c.Server.AdminIDs = []string{
-					"spiffe://otherdomain.test/my/admin",
-				}
+				c.Server.DownstreamAuthzWebhook = &webhookConfig{URL: "ftp://policy.example.org/downstream"}
 			},
 			expectError: true,
 			test: func(t *testing.T, c *server.Config) {
@@ -946,6 +1857,18 @@ func TestNewServerConfig(t *testing.T) {
 	}
 }
 
+func TestNewServerConfigLogFile(t *testing.T) {
+	input := defaultValidConfig()
+	input.Server.LogFile = filepath.Join(t.TempDir(), "server.log")
+
+	sc, err := NewServerConfig(input, nil, false)
+	require.NoError(t, err)
+	defer sc.Log.(*log.Logger).Close()
+
+	// The log file can be reopened on signal to support log rotation
+	require.NotNil(t, sc.LogReopener)
+}
+
 // defaultValidConfig returns the bare minimum config required to
 // pass validation etc
 func defaultValidConfig() *Config {
@@ -995,6 +1918,98 @@ func TestValidateConfig(t *testing.T) {
 			applyConf:   func(c *Config) { c.Plugins = nil },
 			expectedErr: "plugins section must be configured",
 		},
+		{
+			name: "audit_log_file requires audit_log_enabled",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = false
+				c.Server.AuditLogFile = "audit.log"
+			},
+			expectedErr: "audit_log_file requires audit_log_enabled to be set",
+		},
+		{
+			name: "audit_log_timestamping requires audit_log_file",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogTimestamping = &auditLogTimestampingConfig{TSAURL: "https://tsa.example.org"}
+			},
+			expectedErr: "audit_log_timestamping requires audit_log_file to be set",
+		},
+		{
+			name: "audit_log_syslog requires audit_log_enabled",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = false
+				c.Server.AuditLogSyslog = &auditLogSyslogConfig{}
+			},
+			expectedErr: "audit_log_syslog requires audit_log_enabled to be set",
+		},
+		{
+			name: "audit_log_syslog network and address must be set together",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogSyslog = &auditLogSyslogConfig{Address: "localhost:514"}
+			},
+			expectedErr: "audit_log_syslog.network and audit_log_syslog.address must be set together",
+		},
+		{
+			name: "audit_log_timestamping.tsa_url must be an http or https URL",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogFile = "audit.log"
+				c.Server.AuditLogTimestamping = &auditLogTimestampingConfig{TSAURL: "tsa.example.org"}
+			},
+			expectedErr: "audit_log_timestamping.tsa_url must be an http or https URL",
+		},
+		{
+			name: "audit_log_timestamping.batch_size must not be negative",
+			applyConf: func(c *Config) {
+				c.Server.AuditLogEnabled = true
+				c.Server.AuditLogFile = "audit.log"
+				c.Server.AuditLogTimestamping = &auditLogTimestampingConfig{TSAURL: "https://tsa.example.org", BatchSize: -1}
+			},
+			expectedErr: "audit_log_timestamping.batch_size must not be negative",
+		},
+		{
+			name: "svid_denylist patterns must be well formed",
+			applyConf: func(c *Config) {
+				c.Server.SVIDDenylist = []string{"spiffe://example.org/["}
+			},
+			expectedErr: `invalid svid_denylist: malformed pattern "spiffe://example.org/[": syntax error in pattern`,
+		},
+		{
+			name: "ca_path_len must not be negative",
+			applyConf: func(c *Config) {
+				c.Server.CAPathLen = intPtr(-1)
+			},
+			expectedErr: "ca_path_len must not be negative",
+		},
+		{
+			name: "downstream_ca_path_len must not be negative",
+			applyConf: func(c *Config) {
+				c.Server.DownstreamCAPathLen = intPtr(-1)
+			},
+			expectedErr: "downstream_ca_path_len must not be negative",
+		},
+		{
+			name: "downstream_ca_path_len must be less than ca_path_len",
+			applyConf: func(c *Config) {
+				c.Server.CAPathLen = intPtr(1)
+				c.Server.DownstreamCAPathLen = intPtr(1)
+			},
+			expectedErr: "downstream_ca_path_len must be less than ca_path_len",
+		},
+		{
+			name: "issuance_quota.signings_per_minute_per_agent must not be negative",
+			applyConf: func(c *Config) {
+				c.Server.IssuanceQuota.SigningsPerMinutePerAgent = -1
+			},
+			expectedErr: "issuance_quota.signings_per_minute_per_agent must not be negative",
+		},
+		{
+			name: "issuance_quota.signings_per_minute_per_entry must not be negative",
+			applyConf: func(c *Config) {
+				c.Server.IssuanceQuota.SigningsPerMinutePerEntry = -1
+			},
+			expectedErr: "issuance_quota.signings_per_minute_per_entry must not be negative",
+		},
 		{
 			name: "if ACME is used, federation.bundle_endpoint.acme.domain_name must be configured",
 			applyConf: func(c *Config) {
@@ -1104,6 +2119,86 @@ func TestWarnOnUnknownConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			msg:      "in nested entry_namespace block",
+			confFile: "server_bad_nested_entry_namespace_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: `entry_namespace "team-a"`,
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in nested jwt_audience_restriction block",
+			confFile: "server_bad_nested_jwt_audience_restriction_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: `jwt_audience_restriction "payments"`,
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in nested attestation_webhook block",
+			confFile: "server_bad_nested_attestation_webhook_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: `attestation_webhook "siem"`,
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in audit_log_timestamping block",
+			confFile: "server_bad_audit_log_timestamping_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "audit_log_timestamping",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in audit_log_syslog block",
+			confFile: "server_bad_audit_log_syslog_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "audit_log_syslog",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in issuance_quota block",
+			confFile: "server_bad_issuance_quota_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "issuance_quota",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in downstream_authorization_webhook block",
+			confFile: "server_bad_downstream_authorization_webhook_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "downstream_authorization_webhook",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
+		{
+			msg:      "in spiffe_id_path_policy block",
+			confFile: "server_bad_spiffe_id_path_policy_block.conf",
+			expectedLogEntries: []logEntry{
+				{
+					section: "spiffe_id_path_policy",
+					keys:    "unknown_option1,unknown_option2",
+				},
+			},
+		},
 		{
 			msg:      "in ratelimit block",
 			confFile: "server_bad_ratelimit_block.conf",
@@ -1287,6 +2382,26 @@ func TestWarnOnUnknownConfig(t *testing.T) {
 
 // TestLogOptions verifies the log options given to newAgentConfig are applied, and are overridden
 // by values from the config file
+func TestWarnOnUnknownConfigIgnoresBlockLabels(t *testing.T) {
+	c, err := ParseFile(configFile, false)
+	require.NoError(t, err)
+	c.Server.UnusedKeys = []string{"additional_listener", "ipv6", "internal", "unknown_option"}
+
+	log, hook := test.NewNullLogger()
+	err = checkForUnknownConfig(c, log)
+	assert.EqualError(t, err, "unknown configuration detected")
+	spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Unknown configuration detected",
+			Data: logrus.Fields{
+				"section": "server",
+				"keys":    "unknown_option",
+			},
+		},
+	})
+}
+
 func TestLogOptions(t *testing.T) {
 	fd, err := os.CreateTemp("", "test")
 	require.NoError(t, err)
@@ -1492,6 +2607,41 @@ func TestExpandEnv(t *testing.T) {
 	}
 }
 
+func TestParseFileWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	pluginsPath := filepath.Join(dir, "plugins.conf")
+	require.NoError(t, os.WriteFile(pluginsPath, []byte(`
+plugins {
+	DataStore "sql" {
+		plugin_data {
+			database_type = "sqlite3"
+		}
+	}
+	KeyManager "memory" {
+		plugin_data {}
+	}
+}`), 0600))
+	configPath := filepath.Join(dir, "server.conf")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+include = ["plugins.conf"]
+server {
+	trust_domain = "example.org"
+}
+plugins {
+	NodeAttestor "join_token" {
+		plugin_data {}
+	}
+}`), 0600))
+
+	c, err := ParseFile(configPath, false)
+	require.NoError(t, err)
+	require.Equal(t, "example.org", c.Server.TrustDomain)
+	require.Empty(t, c.UnusedKeys)
+	require.Contains(t, (*c.Plugins)["DataStore"], "sql")
+	require.Contains(t, (*c.Plugins)["KeyManager"], "memory")
+	require.Contains(t, (*c.Plugins)["NodeAttestor"], "join_token")
+}
+
 func TestAgentTTL(t *testing.T) {
 	for _, c := range []struct {
 		agentTTL         string
@@ -1514,6 +2664,131 @@ func TestAgentTTL(t *testing.T) {
 	}
 }
 
+func TestAgentMaxRenewalAge(t *testing.T) {
+	for _, c := range []struct {
+		maxRenewalAge    string
+		expectedDuration time.Duration
+		expectedErr      string
+	}{
+		{
+			maxRenewalAge:    "720h",
+			expectedDuration: 720 * time.Hour,
+		},
+		{
+			maxRenewalAge:    "",
+			expectedDuration: 0,
+		},
+		{
+			maxRenewalAge: "forever",
+			expectedErr:   `could not parse agent_max_renewal_age "forever"`,
+		},
+		{
+			maxRenewalAge: "-1h",
+			expectedErr:   `agent_max_renewal_age "-1h" must not be negative`,
+		},
+	} {
+		config := defaultValidConfig()
+		config.Server.AgentMaxRenewalAge = c.maxRenewalAge
+		sconfig, err := NewServerConfig(config, []log.Option{}, false)
+		if c.expectedErr != "" {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, c.expectedDuration, sconfig.AgentMaxRenewalAge)
+	}
+}
+
+func TestCARotationSchedule(t *testing.T) {
+	for _, c := range []struct {
+		leadTime       string
+		overlap        string
+		expectLeadTime time.Duration
+		expectOverlap  time.Duration
+		expectedErr    string
+	}{
+		{
+			leadTime:       "720h",
+			overlap:        "168h",
+			expectLeadTime: 720 * time.Hour,
+			expectOverlap:  168 * time.Hour,
+		},
+		{
+			overlap:       "168h",
+			expectOverlap: 168 * time.Hour,
+		},
+		{},
+		{
+			leadTime:    "soon",
+			expectedErr: `could not parse ca_preparation_lead_time "soon"`,
+		},
+		{
+			leadTime:    "-1h",
+			expectedErr: `ca_preparation_lead_time "-1h" must not be negative`,
+		},
+		{
+			overlap:     "a while",
+			expectedErr: `could not parse ca_activation_overlap "a while"`,
+		},
+		{
+			overlap:     "-1h",
+			expectedErr: `ca_activation_overlap "-1h" must not be negative`,
+		},
+		{
+			leadTime:    "24h",
+			overlap:     "48h",
+			expectedErr: `ca_activation_overlap "48h" must not be greater than ca_preparation_lead_time "24h"`,
+		},
+	} {
+		config := defaultValidConfig()
+		config.Server.CAPreparationLeadTime = c.leadTime
+		config.Server.CAActivationOverlap = c.overlap
+		sconfig, err := NewServerConfig(config, []log.Option{}, false)
+		if c.expectedErr != "" {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, c.expectLeadTime, sconfig.CAPreparationLeadTime)
+		assert.Equal(t, c.expectOverlap, sconfig.CAActivationOverlap)
+	}
+}
+
+func TestDataStoreSlowOperationThreshold(t *testing.T) {
+	for _, c := range []struct {
+		threshold       string
+		expectThreshold time.Duration
+		expectedErr     string
+	}{
+		{
+			threshold:       "500ms",
+			expectThreshold: 500 * time.Millisecond,
+		},
+		{},
+		{
+			threshold:   "slow",
+			expectedErr: `could not parse datastore_slow_operation_threshold "slow"`,
+		},
+		{
+			threshold:   "-1s",
+			expectedErr: `datastore_slow_operation_threshold "-1s" must not be negative`,
+		},
+	} {
+		config := defaultValidConfig()
+		config.Server.DataStoreSlowThreshold = c.threshold
+		sconfig, err := NewServerConfig(config, []log.Option{}, false)
+		if c.expectedErr != "" {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, c.expectThreshold, sconfig.DataStoreSlowOperationThreshold)
+	}
+}
+
 func httpsSPIFFEConfigTest(t *testing.T) federatesWithConfig {
 	configString := `bundle_endpoint_url = "https://192.168.1.1:1337"
 	bundle_endpoint_profile "https_spiffe" {
@@ -1533,3 +2808,15 @@ func webPKIConfigTest(t *testing.T) federatesWithConfig {
 
 	return *webPKIConfig
 }
+
+func TestCAPathLenConfig(t *testing.T) {
+	c := new(serverConfig)
+	require.NoError(t, hcl.Decode(c, `ca_path_len = 1
+	downstream_ca_path_len = 0`))
+	require.Equal(t, intPtr(1), c.CAPathLen)
+	require.Equal(t, intPtr(0), c.DownstreamCAPathLen)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -31,5 +31,8 @@ func (c *Config) validateOS() error {
 	if c.Server.SocketPath != "" {
 		return errors.New("invalid configuration: socket_path is not supported in this platform; please use named_pipe_name instead")
 	}
+	if c.Server.UDSMode != "" || c.Server.UDSGroup != "" {
+		return errors.New("invalid configuration: uds_mode and uds_group are not supported in this platform")
+	}
 	return nil
 }
@@ -0,0 +1,276 @@
+package stats
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"golang.org/x/net/context"
+)
+
+type statsCommand struct {
+	// Registration entry filters, as supported by "entry show"
+	entryParentID             string
+	entrySpiffeID             string
+	entrySelectors            common_cli.StringsFlag
+	entryMatchSelectorsOn     string
+	entryFederatesWith        common_cli.StringsFlag
+	entryMatchFederatesWithOn string
+
+	// Agent filters, as supported by the ListAgents RPC
+	agentAttestationType  string
+	agentBanned           string
+	agentSelectors        common_cli.StringsFlag
+	agentMatchSelectorsOn string
+
+	// Output format, either pretty or json
+	output string
+}
+
+// stats is the JSON representation of the counts.
+type stats struct {
+	RegistrationEntries int32 `json:"registration_entries"`
+	AttestedAgents      int32 `json:"attested_agents"`
+	FederatedBundles    int32 `json:"federated_bundles"`
+}
+
+// NewStatsCommand creates a new "stats" command.
+func NewStatsCommand() cli.Command {
+	return NewStatsCommandWithEnv(common_cli.DefaultEnv)
+}
+
+// NewStatsCommandWithEnv creates a new "stats" command using the environment
+// specified.
+func NewStatsCommandWithEnv(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(statsCommand))
+}
+
+func (*statsCommand) Name() string {
+	return "stats"
+}
+
+func (statsCommand) Synopsis() string {
+	return "Prints the number of registration entries, attested agents and federated bundles"
+}
+
+// Run prints the server stats. The counts are obtained through the Count
+// RPCs so callers do not need to page through every record.
+func (c *statsCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
+
+	entryFilter, err := c.entryFilter()
+	if err != nil {
+		return err
+	}
+	agentFilter, err := c.agentFilter()
+	if err != nil {
+		return err
+	}
+
+	entries, err := serverClient.NewEntryCountClient().CountFilteredEntries(ctx, &count.CountFilteredEntriesRequest{
+		Filter: entryFilter,
+	})
+	if err != nil {
+		return err
+	}
+
+	agents, err := serverClient.NewAgentCountClient().CountFilteredAgents(ctx, &count.CountFilteredAgentsRequest{
+		Filter: agentFilter,
+	})
+	if err != nil {
+		return err
+	}
+
+	bundles, err := serverClient.NewBundleCountClient().CountFederatedBundles(ctx, &count.CountFederatedBundlesRequest{})
+	if err != nil {
+		return err
+	}
+
+	if c.output == util.OutputJSON {
+		out, err := json.MarshalIndent(stats{
+			RegistrationEntries: entries.Count,
+			AttestedAgents:      agents.Count,
+			FederatedBundles:    bundles.Count,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		return env.Println(string(out))
+	}
+
+	_ = env.Printf("Registration entries: %d\n", entries.Count)
+	_ = env.Printf("Attested agents:      %d\n", agents.Count)
+	_ = env.Printf("Federated bundles:    %d\n", bundles.Count)
+	return nil
+}
+
+func (c *statsCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.entryParentID, "entryParentID", "", "Only count the registration entries with this Parent ID")
+	fs.StringVar(&c.entrySpiffeID, "entrySpiffeID", "", "Only count the registration entries with this SPIFFE ID")
+	fs.Var(&c.entrySelectors, "entrySelector", "Only count the registration entries with this colon-delimited type:value selector. Can be used more than once")
+	fs.StringVar(&c.entryMatchSelectorsOn, "entryMatchSelectorsOn", "superset", "The match mode used when filtering entries by selectors. Options: exact, any, superset and subset")
+	fs.Var(&c.entryFederatesWith, "entryFederatesWith", "Only count the registration entries that federate with this trust domain. Can be used more than once")
+	fs.StringVar(&c.entryMatchFederatesWithOn, "entryMatchFederatesWithOn", "superset", "The match mode used when filtering entries by federates with. Options: exact, any, superset and subset")
+	fs.StringVar(&c.agentAttestationType, "agentAttestationType", "", "Only count the agents attested with this node attestor")
+	fs.StringVar(&c.agentBanned, "agentBanned", "", "If set to true or false, only count the agents that are or are not banned")
+	fs.Var(&c.agentSelectors, "agentSelector", "Only count the agents with this colon-delimited type:value selector. Can be used more than once")
+	fs.StringVar(&c.agentMatchSelectorsOn, "agentMatchSelectorsOn", "superset", "The match mode used when filtering agents by selectors. Options: exact, any, superset and subset")
+	util.AddOutputFlag(fs, &c.output)
+}
+
+// entryFilter returns the ListEntries filter set through the flags, or nil
+// if every entry should be counted.
+func (c *statsCommand) entryFilter() (*entryv1.ListEntriesRequest_Filter, error) {
+	filter := &entryv1.ListEntriesRequest_Filter{}
+	set := false
+
+	if c.entryParentID != "" {
+		id, err := idStringToProto(c.entryParentID)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing parent ID %q: %w", c.entryParentID, err)
+		}
+		filter.ByParentId = id
+		set = true
+	}
+
+	if c.entrySpiffeID != "" {
+		id, err := idStringToProto(c.entrySpiffeID)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing SPIFFE ID %q: %w", c.entrySpiffeID, err)
+		}
+		filter.BySpiffeId = id
+		set = true
+	}
+
+	if len(c.entrySelectors) > 0 {
+		selectors, err := parseSelectorMatch(c.entrySelectors, c.entryMatchSelectorsOn)
+		if err != nil {
+			return nil, err
+		}
+		filter.BySelectors = selectors
+		set = true
+	}
+
+	if len(c.entryFederatesWith) > 0 {
+		match, err := parseToFederatesWithMatch(c.entryMatchFederatesWithOn)
+		if err != nil {
+			return nil, err
+		}
+		filter.ByFederatesWith = &types.FederatesWithMatch{
+			TrustDomains: c.entryFederatesWith,
+			Match:        match,
+		}
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// agentFilter returns the ListAgents filter set through the flags, or nil if
+// every agent should be counted.
+func (c *statsCommand) agentFilter() (*agentv1.ListAgentsRequest_Filter, error) {
+	filter := &agentv1.ListAgentsRequest_Filter{
+		ByAttestationType: c.agentAttestationType,
+	}
+	set := c.agentAttestationType != ""
+
+	if c.agentBanned != "" {
+		banned, err := strconv.ParseBool(c.agentBanned)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing -agentBanned %q: %w", c.agentBanned, err)
+		}
+		filter.ByBanned = wrapperspb.Bool(banned)
+		set = true
+	}
+
+	if len(c.agentSelectors) > 0 {
+		selectors, err := parseSelectorMatch(c.agentSelectors, c.agentMatchSelectorsOn)
+		if err != nil {
+			return nil, err
+		}
+		filter.BySelectorMatch = selectors
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+func idStringToProto(id string) (*types.SPIFFEID, error) {
+	idType, err := spiffeid.FromString(id)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SPIFFEID{
+		TrustDomain: idType.TrustDomain().String(),
+		Path:        idType.Path(),
+	}, nil
+}
+
+func parseSelectorMatch(values []string, match string) (*types.SelectorMatch, error) {
+	matchBehavior, err := parseToSelectorMatch(match)
+	if err != nil {
+		return nil, err
+	}
+
+	selectors := make([]*types.Selector, len(values))
+	for i, sel := range values {
+		selector, err := util.ParseSelector(sel)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing selector %q: %w", sel, err)
+		}
+		selectors[i] = selector
+	}
+	return &types.SelectorMatch{
+		Selectors: selectors,
+		Match:     matchBehavior,
+	}, nil
+}
+
+func parseToSelectorMatch(match string) (types.SelectorMatch_MatchBehavior, error) {
+	switch match {
+	case "exact":
+		return types.SelectorMatch_MATCH_EXACT, nil
+	case "any":
+		return types.SelectorMatch_MATCH_ANY, nil
+	case "superset":
+		return types.SelectorMatch_MATCH_SUPERSET, nil
+	case "subset":
+		return types.SelectorMatch_MATCH_SUBSET, nil
+	default:
+		return types.SelectorMatch_MATCH_SUPERSET, fmt.Errorf("match behavior %q unknown", match)
+	}
+}
+
+func parseToFederatesWithMatch(match string) (types.FederatesWithMatch_MatchBehavior, error) {
+	switch match {
+	case "exact":
+		return types.FederatesWithMatch_MATCH_EXACT, nil
+	case "any":
+		return types.FederatesWithMatch_MATCH_ANY, nil
+	case "superset":
+		return types.FederatesWithMatch_MATCH_SUPERSET, nil
+	case "subset":
+		return types.FederatesWithMatch_MATCH_SUBSET, nil
+	default:
+		return types.FederatesWithMatch_MATCH_SUPERSET, fmt.Errorf("match behavior %q unknown", match)
+	}
+}
@@ -0,0 +1,240 @@
+package stats_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/cli/common"
+	"github.com/spiffe/spire/cmd/spire-server/cli/stats"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestHelp(t *testing.T) {
+	test := setupTest(t)
+
+	test.client.Help()
+	require.Equal(t, statsUsage, test.stderr.String())
+}
+
+func TestSynopsis(t *testing.T) {
+	test := setupTest(t)
+	require.Equal(t, "Prints the number of registration entries, attested agents and federated bundles", test.client.Synopsis())
+}
+
+func TestStats(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		args               []string
+		agentErr           error
+		expectedEntryReq   *count.CountFilteredEntriesRequest
+		expectedAgentReq   *count.CountFilteredAgentsRequest
+		expectedReturnCode int
+		expectedStdout     string
+		expectedStderr     string
+	}{
+		{
+			name:             "success",
+			expectedEntryReq: &count.CountFilteredEntriesRequest{},
+			expectedAgentReq: &count.CountFilteredAgentsRequest{},
+			expectedStdout: `Registration entries: 3
+Attested agents:      2
+Federated bundles:    1
+`,
+		},
+		{
+			name:             "json output",
+			args:             []string{"-output", "json"},
+			expectedEntryReq: &count.CountFilteredEntriesRequest{},
+			expectedAgentReq: &count.CountFilteredAgentsRequest{},
+			expectedStdout: `{
+  "registration_entries": 3,
+  "attested_agents": 2,
+  "federated_bundles": 1
+}
+`,
+		},
+		{
+			name: "entry filters",
+			args: []string{
+				"-entryParentID", "spiffe://example.org/parent",
+				"-entrySpiffeID", "spiffe://example.org/workload",
+				"-entrySelector", "unix:uid:1000",
+				"-entryMatchSelectorsOn", "exact",
+				"-entryFederatesWith", "spiffe://domain.test",
+			},
+			expectedEntryReq: &count.CountFilteredEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					BySpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+					BySelectors: &types.SelectorMatch{
+						Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+						Match:     types.SelectorMatch_MATCH_EXACT,
+					},
+					ByFederatesWith: &types.FederatesWithMatch{
+						TrustDomains: []string{"spiffe://domain.test"},
+						Match:        types.FederatesWithMatch_MATCH_SUPERSET,
+					},
+				},
+			},
+			expectedAgentReq: &count.CountFilteredAgentsRequest{},
+			expectedStdout: `Registration entries: 3
+Attested agents:      2
+Federated bundles:    1
+`,
+		},
+		{
+			name: "agent filters",
+			args: []string{
+				"-agentAttestationType", "join_token",
+				"-agentBanned", "false",
+				"-agentSelector", "k8s_psat:cluster:demo",
+				"-agentMatchSelectorsOn", "any",
+			},
+			expectedEntryReq: &count.CountFilteredEntriesRequest{},
+			expectedAgentReq: &count.CountFilteredAgentsRequest{
+				Filter: &agentv1.ListAgentsRequest_Filter{
+					ByAttestationType: "join_token",
+					ByBanned:          wrapperspb.Bool(false),
+					BySelectorMatch: &types.SelectorMatch{
+						Selectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:demo"}},
+						Match:     types.SelectorMatch_MATCH_ANY,
+					},
+				},
+			},
+			expectedStdout: `Registration entries: 3
+Attested agents:      2
+Federated bundles:    1
+`,
+		},
+		{
+			name:               "invalid banned filter",
+			args:               []string{"-agentBanned", "maybe"},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: error parsing -agentBanned \"maybe\": strconv.ParseBool: parsing \"maybe\": invalid syntax\n",
+		},
+		{
+			name:               "invalid match behavior",
+			args:               []string{"-entrySelector", "unix:uid:1000", "-entryMatchSelectorsOn", "none"},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: match behavior \"none\" unknown\n",
+		},
+		{
+			name:               "invalid output",
+			args:               []string{"-output", "yaml"},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: invalid output format \"yaml\": expected \"pretty\" or \"json\"\n",
+		},
+		{
+			name:               "server error",
+			agentErr:           status.Error(codes.Internal, "internal server error"),
+			expectedEntryReq:   &count.CountFilteredEntriesRequest{},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+		{
+			name:               "wrong UDS path",
+			args:               []string{common.AddrArg, common.AddrValue},
+			expectedReturnCode: 1,
+			expectedStderr:     common.AddrError,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t)
+			test.server.agentErr = tt.agentErr
+
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, tt.expectedStdout, test.stdout.String())
+			require.Equal(t, tt.expectedStderr, test.stderr.String())
+			require.Equal(t, tt.expectedReturnCode, returnCode)
+			spiretest.AssertProtoEqual(t, tt.expectedEntryReq, test.server.entryReq)
+			spiretest.AssertProtoEqual(t, tt.expectedAgentReq, test.server.agentReq)
+		})
+	}
+}
+
+type statsTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	args   []string
+	server *fakeServer
+
+	client cli.Command
+}
+
+func setupTest(t *testing.T) *statsTest {
+	server := &fakeServer{}
+
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		count.RegisterAgentCountServer(s, fakeAgentCountServer{server: server})
+		count.RegisterBundleCountServer(s, fakeBundleCountServer{})
+		count.RegisterEntryCountServer(s, fakeEntryCountServer{server: server})
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	client := stats.NewStatsCommandWithEnv(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	return &statsTest{
+		stdout: stdout,
+		stderr: stderr,
+		args:   []string{common.AddrArg, common.GetAddr(addr)},
+		server: server,
+		client: client,
+	}
+}
+
+type fakeServer struct {
+	agentErr error
+	agentReq *count.CountFilteredAgentsRequest
+	entryReq *count.CountFilteredEntriesRequest
+}
+
+type fakeAgentCountServer struct {
+	count.UnimplementedAgentCountServer
+	server *fakeServer
+}
+
+func (s fakeAgentCountServer) CountFilteredAgents(_ context.Context, req *count.CountFilteredAgentsRequest) (*count.CountFilteredAgentsResponse, error) {
+	if s.server.agentErr != nil {
+		return nil, s.server.agentErr
+	}
+	s.server.agentReq = req
+	return &count.CountFilteredAgentsResponse{Count: 2}, nil
+}
+
+type fakeBundleCountServer struct {
+	count.UnimplementedBundleCountServer
+}
+
+func (fakeBundleCountServer) CountFederatedBundles(context.Context, *count.CountFederatedBundlesRequest) (*count.CountFederatedBundlesResponse, error) {
+	return &count.CountFederatedBundlesResponse{Count: 1}, nil
+}
+
+type fakeEntryCountServer struct {
+	count.UnimplementedEntryCountServer
+	server *fakeServer
+}
+
+func (s fakeEntryCountServer) CountFilteredEntries(_ context.Context, req *count.CountFilteredEntriesRequest) (*count.CountFilteredEntriesResponse, error) {
+	s.server.entryReq = req
+	return &count.CountFilteredEntriesResponse{Count: 3}, nil
+}
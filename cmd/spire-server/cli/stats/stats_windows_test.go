@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package stats_test
+
+var (
+	statsUsage = `Usage of stats:
+  -agentAttestationType string
+    	Only count the agents attested with this node attestor
+  -agentBanned string
+    	If set to true or false, only count the agents that are or are not banned
+  -agentMatchSelectorsOn string
+    	The match mode used when filtering agents by selectors. Options: exact, any, superset and subset (default "superset")
+  -agentSelector value
+    	Only count the agents with this colon-delimited type:value selector. Can be used more than once
+  -entryFederatesWith value
+    	Only count the registration entries that federate with this trust domain. Can be used more than once
+  -entryMatchFederatesWithOn string
+    	The match mode used when filtering entries by federates with. Options: exact, any, superset and subset (default "superset")
+  -entryMatchSelectorsOn string
+    	The match mode used when filtering entries by selectors. Options: exact, any, superset and subset (default "superset")
+  -entryParentID string
+    	Only count the registration entries with this Parent ID
+  -entrySelector value
+    	Only count the registration entries with this colon-delimited type:value selector. Can be used more than once
+  -entrySpiffeID string
+    	Only count the registration entries with this SPIFFE ID
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output string
+    	Desired output format (pretty, json) (default "pretty")
+`
+)
@@ -1,7 +1,11 @@
 package token
 
 import (
+	"bytes"
+	"errors"
 	"flag"
+	"fmt"
+	"os"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -9,6 +13,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"google.golang.org/protobuf/proto"
 
 	"golang.org/x/net/context"
 )
@@ -27,6 +32,15 @@ type generateCommand struct {
 
 	// Token TTL in seconds
 	TTL int
+
+	// Number of tokens to generate
+	Count int
+
+	// Output format, either pretty or json
+	output string
+
+	// Optional file to write the tokens to instead of stdout
+	write string
 }
 
 func (g *generateCommand) Name() string {
@@ -38,22 +52,52 @@ func (g *generateCommand) Synopsis() string {
 }
 
 func (g *generateCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := util.ValidateOutput(g.output); err != nil {
+		return err
+	}
+
+	if g.Count < 1 {
+		return errors.New("count must be at least 1")
+	}
+
 	id, err := getID(g.SpiffeID)
 	if err != nil {
 		return err
 	}
 
 	c := serverClient.NewAgentClient()
-	resp, err := c.CreateJoinToken(ctx, &agentv1.CreateJoinTokenRequest{
-		AgentId: id,
-		Ttl:     int32(g.TTL),
-	})
-	if err != nil {
-		return err
+	var tokens []*types.JoinToken
+	for i := 0; i < g.Count; i++ {
+		resp, err := c.CreateJoinToken(ctx, &agentv1.CreateJoinTokenRequest{
+			AgentId: id,
+			Ttl:     int32(g.TTL),
+		})
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, resp)
 	}
 
-	if err := env.Printf("Token: %s\n", resp.Value); err != nil {
-		return err
+	if g.write == "" {
+		if err := printTokens(env, tokens, g.output); err != nil {
+			return err
+		}
+	} else {
+		buf := new(bytes.Buffer)
+		if err := printTokens(&common_cli.Env{Stdout: buf}, tokens, g.output); err != nil {
+			return err
+		}
+		tokensPath := env.JoinPath(g.write)
+		if err := os.WriteFile(tokensPath, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("unable to write tokens: %w", err)
+		}
+		if g.output != util.OutputJSON {
+			env.Printf("Tokens written to %s\n", tokensPath)
+		}
+	}
+
+	if g.output == util.OutputJSON {
+		return nil
 	}
 
 	if g.SpiffeID == "" {
@@ -64,6 +108,28 @@ func (g *generateCommand) Run(ctx context.Context, env *common_cli.Env, serverCl
 	return nil
 }
 
+// printTokens prints the generated tokens. A single token is printed as a
+// JSON object and multiple tokens as a JSON array.
+func printTokens(env *common_cli.Env, tokens []*types.JoinToken, output string) error {
+	if output == util.OutputJSON {
+		if len(tokens) == 1 {
+			return util.PrintJSON(env, tokens[0])
+		}
+		ms := make([]proto.Message, 0, len(tokens))
+		for _, token := range tokens {
+			ms = append(ms, token)
+		}
+		return util.PrintJSONArray(env, ms)
+	}
+
+	for _, token := range tokens {
+		if err := env.Printf("Token: %s\n", token.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getID(spiffeID string) (*types.SPIFFEID, error) {
 	if spiffeID == "" {
 		return nil, nil
@@ -81,5 +147,8 @@ func getID(spiffeID string) (*types.SPIFFEID, error) {
 
 func (g *generateCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.IntVar(&g.TTL, "ttl", 600, "Token TTL in seconds")
+	fs.IntVar(&g.Count, "count", 1, "Number of tokens to generate")
 	fs.StringVar(&g.SpiffeID, "spiffeID", "", "Additional SPIFFE ID to assign the token owner (optional)")
+	fs.StringVar(&g.write, "write", "", "File to write the tokens to instead of stdout (optional)")
+	util.AddOutputFlag(fs, &g.output)
 }
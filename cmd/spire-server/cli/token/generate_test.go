@@ -2,6 +2,9 @@ package token
 
 import (
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mitchellh/cli"
@@ -9,6 +12,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/cli/common"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -53,6 +57,61 @@ func TestCreateToken(t *testing.T) {
 			},
 			token: "token",
 		},
+		{
+			name: "create token with JSON output",
+			args: []string{
+				"-spiffeID", "spiffe://example.org/agent",
+				"-output", "json",
+			},
+			expectedReq: &agentv1.CreateJoinTokenRequest{
+				AgentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+				Ttl:     600,
+			},
+			expectedStdout: `{
+  "value": "token",
+  "expires_at": "0"
+}
+`,
+			token: "token",
+		},
+		{
+			name:           "invalid output format",
+			args:           []string{"-output", "yaml"},
+			expectedStderr: "Error: invalid output format \"yaml\": expected \"pretty\" or \"json\"\n",
+		},
+		{
+			name:           "create multiple tokens",
+			args:           []string{"-count", "3"},
+			expectedStdout: "Token: token\nToken: token\nToken: token\nWarning: Missing SPIFFE ID.\n",
+			expectedReq: &agentv1.CreateJoinTokenRequest{
+				Ttl: 600,
+			},
+			token: "token",
+		},
+		{
+			name: "create multiple tokens with JSON output",
+			args: []string{"-count", "2", "-output", "json"},
+			expectedReq: &agentv1.CreateJoinTokenRequest{
+				Ttl: 600,
+			},
+			expectedStdout: `[
+  {
+    "value": "token",
+    "expires_at": "0"
+  },
+  {
+    "value": "token",
+    "expires_at": "0"
+  }
+]
+`,
+			token: "token",
+		},
+		{
+			name:           "invalid count",
+			args:           []string{"-count", "0"},
+			expectedStderr: "Error: count must be at least 1\n",
+		},
 		{
 			name: "malformed spiffe ID",
 			args: []string{
@@ -76,7 +135,7 @@ func TestCreateToken(t *testing.T) {
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			test := setupTest(t)
+			test := setupTest(t, newGenerateCommand)
 			test.server.token = tt.token
 			test.server.expectReq = tt.expectedReq
 			test.server.err = tt.serverErr
@@ -95,13 +154,64 @@ func TestCreateToken(t *testing.T) {
 	}
 }
 
+func TestCreateTokenWrite(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		expectedStdout string
+		expectedFile   string
+	}{
+		{
+			name:           "pretty",
+			args:           []string{"-count", "2", "-spiffeID", "spiffe://example.org/agent"},
+			expectedStdout: "Tokens written to %s\n",
+			expectedFile:   "Token: token\nToken: token\n",
+		},
+		{
+			name: "json",
+			args: []string{"-spiffeID", "spiffe://example.org/agent", "-output", "json"},
+			expectedFile: `{
+  "value": "token",
+  "expires_at": "0"
+}
+`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tokensPath := filepath.Join(spiretest.TempDir(t), "tokens")
+
+			test := setupTest(t, newGenerateCommand)
+			test.server.token = "token"
+			test.server.expectReq = &agentv1.CreateJoinTokenRequest{
+				AgentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+				Ttl:     600,
+			}
+
+			rc := test.client.Run(test.args(append(tt.args, "-write", tokensPath)...))
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, 0, rc)
+			if tt.expectedStdout != "" {
+				require.Equal(t, fmt.Sprintf(tt.expectedStdout, tokensPath), test.stdout.String())
+			} else {
+				require.Empty(t, test.stdout.String())
+			}
+
+			data, err := os.ReadFile(tokensPath)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFile, string(data))
+		})
+	}
+}
+
 type tokenTest struct {
 	stdin  *bytes.Buffer
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 
-	addr   string
-	server *fakeAgentServer
+	addr            string
+	server          *fakeAgentServer
+	joinTokenServer *fakeJoinTokensServer
 
 	client cli.Command
 }
@@ -110,30 +220,33 @@ func (t *tokenTest) args(extra ...string) []string {
 	return append([]string{common.AddrArg, t.addr}, extra...)
 }
 
-func setupTest(t *testing.T) *tokenTest {
+func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *tokenTest {
 	server := &fakeAgentServer{t: t}
+	joinTokenServer := &fakeJoinTokensServer{}
 
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		agentv1.RegisterAgentServer(s, server)
+		jointoken.RegisterJoinTokensServer(s, joinTokenServer)
 	})
 
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
 	stderr := new(bytes.Buffer)
 
-	client := newGenerateCommand(&common_cli.Env{
+	client := newClient(&common_cli.Env{
 		Stdin:  stdin,
 		Stdout: stdout,
 		Stderr: stderr,
 	})
 
 	return &tokenTest{
-		addr:   common.GetAddr(addr),
-		stderr: stderr,
-		stdin:  stdin,
-		stdout: stdout,
-		server: server,
-		client: client,
+		addr:            common.GetAddr(addr),
+		stderr:          stderr,
+		stdin:           stdin,
+		stdout:          stdout,
+		server:          server,
+		joinTokenServer: joinTokenServer,
+		client:          client,
 	}
 }
 
@@ -156,3 +269,23 @@ func (f *fakeAgentServer) CreateJoinToken(ctx context.Context, req *agentv1.Crea
 		Value: f.token,
 	}, nil
 }
+
+type fakeJoinTokensServer struct {
+	jointoken.UnimplementedJoinTokensServer
+
+	tokens []*jointoken.JoinToken
+}
+
+func (f *fakeJoinTokensServer) ListJoinTokens(ctx context.Context, req *jointoken.ListJoinTokensRequest) (*jointoken.ListJoinTokensResponse, error) {
+	return &jointoken.ListJoinTokensResponse{Tokens: f.tokens}, nil
+}
+
+func (f *fakeJoinTokensServer) RevokeJoinToken(ctx context.Context, req *jointoken.RevokeJoinTokenRequest) (*jointoken.RevokeJoinTokenResponse, error) {
+	for i, token := range f.tokens {
+		if token.Value == req.Value {
+			f.tokens = append(f.tokens[:i], f.tokens[i+1:]...)
+			return &jointoken.RevokeJoinTokenResponse{}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "join token not found")
+}
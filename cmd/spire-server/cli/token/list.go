@@ -0,0 +1,64 @@
+package token
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
+
+	"golang.org/x/net/context"
+)
+
+func NewListCommand() cli.Command {
+	return newListCommand(common_cli.DefaultEnv)
+}
+
+func newListCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(listCommand))
+}
+
+type listCommand struct {
+	// Output format, either pretty or json
+	output string
+}
+
+func (*listCommand) Name() string {
+	return "token list"
+}
+
+func (*listCommand) Synopsis() string {
+	return "Lists the join tokens that have not been used yet"
+}
+
+func (c *listCommand) AppendFlags(fs *flag.FlagSet) {
+	util.AddOutputFlag(fs, &c.output)
+}
+
+func (c *listCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if err := util.ValidateOutput(c.output); err != nil {
+		return err
+	}
+
+	resp, err := serverClient.NewJoinTokensClient().ListJoinTokens(ctx, &jointoken.ListJoinTokensRequest{})
+	if err != nil {
+		return err
+	}
+
+	if c.output == util.OutputJSON {
+		return util.PrintJSON(env, resp)
+	}
+
+	msg := fmt.Sprintf("Found %v join ", len(resp.Tokens))
+	msg = util.Pluralizer(msg, "token", "tokens", len(resp.Tokens))
+	env.Println(msg)
+	for _, token := range resp.Tokens {
+		env.Printf("Token           : %s\n", token.Value)
+		env.Printf("Expiration time : %s\n", time.Unix(token.ExpiresAt, 0))
+		env.Println()
+	}
+	return nil
+}
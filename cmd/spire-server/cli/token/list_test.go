@@ -0,0 +1,126 @@
+package token
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/proto/private/server/jointoken"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSynopsis(t *testing.T) {
+	require.Equal(t, "Lists the join tokens that have not been used yet", NewListCommand().Synopsis())
+}
+
+func TestList(t *testing.T) {
+	tokens := []*jointoken.JoinToken{
+		{Value: "token-1", ExpiresAt: 1000},
+		{Value: "token-2", ExpiresAt: 2000},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		tokens         []*jointoken.JoinToken
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "no tokens",
+			expectedStdout: "Found 0 join tokens\n",
+		},
+		{
+			name:   "pretty",
+			tokens: tokens,
+			expectedStdout: fmt.Sprintf(`Found 2 join tokens
+Token           : token-1
+Expiration time : %s
+
+Token           : token-2
+Expiration time : %s
+
+`, time.Unix(1000, 0), time.Unix(2000, 0)),
+		},
+		{
+			name:   "json",
+			args:   []string{"-output", "json"},
+			tokens: tokens,
+			expectedStdout: `{
+  "tokens": [
+    {
+      "value": "token-1",
+      "expires_at": "1000"
+    },
+    {
+      "value": "token-2",
+      "expires_at": "2000"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:           "invalid output format",
+			args:           []string{"-output", "yaml"},
+			expectedStderr: "Error: invalid output format \"yaml\": expected \"pretty\" or \"json\"\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newListCommand)
+			test.joinTokenServer.tokens = tt.tokens
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expectedStderr != "" {
+				require.Equal(t, tt.expectedStderr, test.stderr.String())
+				require.Equal(t, 1, rc)
+				return
+			}
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expectedStdout, test.stdout.String())
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "revoke token",
+			args:           []string{"-token", "token-1"},
+			expectedStdout: "Join token revoked\n",
+		},
+		{
+			name:           "missing token",
+			expectedStderr: "Error: a join token is required\n",
+		},
+		{
+			name:           "token not found",
+			args:           []string{"-token", "unknown"},
+			expectedStderr: "Error: rpc error: code = NotFound desc = join token not found\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newRevokeCommand)
+			test.joinTokenServer.tokens = []*jointoken.JoinToken{{Value: "token-1"}}
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expectedStderr != "" {
+				require.Equal(t, tt.expectedStderr, test.stderr.String())
+				require.Equal(t, 1, rc)
+				return
+			}
+			require.Empty(t, test.stderr.String())
+			require.Equal(t, 0, rc)
+			require.Equal(t, tt.expectedStdout, test.stdout.String())
+			require.Empty(t, test.joinTokenServer.tokens)
+		})
+	}
+}
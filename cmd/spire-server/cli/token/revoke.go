@@ -0,0 +1,49 @@
+package token
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
+
+	"golang.org/x/net/context"
+)
+
+func NewRevokeCommand() cli.Command {
+	return newRevokeCommand(common_cli.DefaultEnv)
+}
+
+func newRevokeCommand(env *common_cli.Env) cli.Command {
+	return util.AdaptCommand(env, new(revokeCommand))
+}
+
+type revokeCommand struct {
+	// Join token to revoke
+	token string
+}
+
+func (*revokeCommand) Name() string {
+	return "token revoke"
+}
+
+func (*revokeCommand) Synopsis() string {
+	return "Revokes a join token that has not been used yet"
+}
+
+func (c *revokeCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.token, "token", "", "The join token to revoke")
+}
+
+func (c *revokeCommand) Run(ctx context.Context, env *common_cli.Env, serverClient util.ServerClient) error {
+	if c.token == "" {
+		return errors.New("a join token is required")
+	}
+
+	if _, err := serverClient.NewJoinTokensClient().RevokeJoinToken(ctx, &jointoken.RevokeJoinTokenRequest{Value: c.token}); err != nil {
+		return err
+	}
+	return env.Println("Join token revoked")
+}
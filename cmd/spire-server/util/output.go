@@ -0,0 +1,79 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	OutputPretty = "pretty"
+	OutputJSON   = "json"
+)
+
+// AddOutputFlag adds the -output flag, used to select between human readable
+// and JSON output, to the given flag set.
+func AddOutputFlag(f *flag.FlagSet, output *string) {
+	f.StringVar(output, "output", OutputPretty, "Desired output format (pretty, json)")
+}
+
+// ValidateOutput returns an error if the value of the -output flag is not
+// supported.
+func ValidateOutput(output string) error {
+	switch output {
+	case OutputPretty, OutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q: expected %q or %q", output, OutputPretty, OutputJSON)
+	}
+}
+
+// PrintJSON prints the message as indented JSON. Field names are the ones in
+// the proto definitions so the output is stable for scripts.
+func PrintJSON(env *common_cli.Env, m proto.Message) error {
+	data, err := protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	// protojson output is intentionally unstable, so it is reformatted
+	out := new(bytes.Buffer)
+	if err := json.Indent(out, data, "", "  "); err != nil {
+		return err
+	}
+	return env.Println(out.String())
+}
+
+// PrintJSONArray prints the messages as an indented JSON array, formatted
+// like PrintJSON.
+func PrintJSONArray(env *common_cli.Env, ms []proto.Message) error {
+	items := make([]json.RawMessage, 0, len(ms))
+	for _, m := range ms {
+		data, err := protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		}.Marshal(m)
+		if err != nil {
+			return err
+		}
+		items = append(items, data)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	out := new(bytes.Buffer)
+	if err := json.Indent(out, data, "", "  "); err != nil {
+		return err
+	}
+	return env.Println(out.String())
+}
@@ -19,6 +19,14 @@ import (
 	api_types "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/denylist"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -29,6 +37,7 @@ const (
 	DefaultNamedPipeName = "\\spire-server\\private\\api"
 	FormatPEM            = "pem"
 	FormatSPIFFE         = "spiffe"
+	FormatJWKS           = "jwks"
 )
 
 func Dial(addr net.Addr) (*grpc.ClientConn, error) {
@@ -48,6 +57,16 @@ type ServerClient interface {
 	NewSVIDClient() svidv1.SVIDClient
 	NewTrustDomainClient() trustdomainv1.TrustDomainClient
 	NewHealthClient() grpc_health_v1.HealthClient
+	NewAgentBootstrapClient() agentbootstrap.AgentBootstrapClient
+	NewAgentRenewalClient() agentrenewal.AgentRenewalClient
+	NewEntryDNSNamesClient() entrydnsnames.EntryDNSNamesClient
+	NewEntryRestoreClient() entryrestore.EntryRestoreClient
+	NewEntryTemplatesClient() entrytemplate.EntryTemplatesClient
+	NewDenylistClient() denylist.DenylistClient
+	NewJoinTokensClient() jointoken.JoinTokensClient
+	NewEntryCountClient() count.EntryCountClient
+	NewAgentCountClient() count.AgentCountClient
+	NewBundleCountClient() count.BundleCountClient
 }
 
 func NewServerClient(addr net.Addr) (ServerClient, error) {
@@ -90,6 +109,46 @@ func (c *serverClient) NewHealthClient() grpc_health_v1.HealthClient {
 	return grpc_health_v1.NewHealthClient(c.conn)
 }
 
+func (c *serverClient) NewAgentBootstrapClient() agentbootstrap.AgentBootstrapClient {
+	return agentbootstrap.NewAgentBootstrapClient(c.conn)
+}
+
+func (c *serverClient) NewAgentRenewalClient() agentrenewal.AgentRenewalClient {
+	return agentrenewal.NewAgentRenewalClient(c.conn)
+}
+
+func (c *serverClient) NewEntryDNSNamesClient() entrydnsnames.EntryDNSNamesClient {
+	return entrydnsnames.NewEntryDNSNamesClient(c.conn)
+}
+
+func (c *serverClient) NewEntryRestoreClient() entryrestore.EntryRestoreClient {
+	return entryrestore.NewEntryRestoreClient(c.conn)
+}
+
+func (c *serverClient) NewEntryTemplatesClient() entrytemplate.EntryTemplatesClient {
+	return entrytemplate.NewEntryTemplatesClient(c.conn)
+}
+
+func (c *serverClient) NewDenylistClient() denylist.DenylistClient {
+	return denylist.NewDenylistClient(c.conn)
+}
+
+func (c *serverClient) NewJoinTokensClient() jointoken.JoinTokensClient {
+	return jointoken.NewJoinTokensClient(c.conn)
+}
+
+func (c *serverClient) NewEntryCountClient() count.EntryCountClient {
+	return count.NewEntryCountClient(c.conn)
+}
+
+func (c *serverClient) NewAgentCountClient() count.AgentCountClient {
+	return count.NewAgentCountClient(c.conn)
+}
+
+func (c *serverClient) NewBundleCountClient() count.BundleCountClient {
+	return count.NewBundleCountClient(c.conn)
+}
+
 // Pluralizer concatenates `singular` to `msg` when `val` is one, and
 // `plural` on all other occasions. It is meant to facilitate friendlier
 // CLI output.
@@ -4,24 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	_ "net/http/pprof" //nolint: gosec // import registers routes on DefaultServeMux
 	"os"
 	"path"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	admin_api "github.com/spiffe/spire/pkg/agent/api"
+	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	node_attestor "github.com/spiffe/spire/pkg/agent/attestor/node"
 	workload_attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
 	"github.com/spiffe/spire/pkg/agent/catalog"
 	"github.com/spiffe/spire/pkg/agent/endpoints"
 	"github.com/spiffe/spire/pkg/agent/manager"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
+	"github.com/spiffe/spire/pkg/agent/rotationevents"
 	"github.com/spiffe/spire/pkg/agent/svid/store"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/profiling"
+	"github.com/spiffe/spire/pkg/common/systemd"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/uptime"
 	"github.com/spiffe/spire/pkg/common/util"
@@ -33,6 +38,18 @@ import (
 
 type Agent struct {
 	c *Config
+
+	// lastSync returns the time of the last successful synchronization with
+	// the server. It is set once the cache manager has been initialized.
+	lastSync func() time.Time
+
+	// bindAddrMtx protects the Workload API bind address, which can change
+	// when the configuration is reloaded.
+	bindAddrMtx sync.RWMutex
+
+	// serverAddrMtx protects the server address, which can change when the
+	// configuration is reloaded.
+	serverAddrMtx sync.RWMutex
 }
 
 // Run the agent
@@ -64,10 +81,11 @@ func (a *Agent) Run(ctx context.Context) error {
 	uptime.ReportMetrics(ctx, metrics)
 
 	cat, err := catalog.Load(ctx, catalog.Config{
-		Log:          a.c.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
-		Metrics:      metrics,
-		TrustDomain:  a.c.TrustDomain,
-		PluginConfig: a.c.PluginConfigs,
+		Log:                    a.c.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
+		Metrics:                metrics,
+		TrustDomain:            a.c.TrustDomain,
+		PluginConfig:           a.c.PluginConfigs,
+		RequirePluginChecksums: a.c.RequirePluginChecksums,
 	})
 	if err != nil {
 		return err
@@ -83,20 +101,29 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	svidStoreCache := a.newSVIDStoreCache()
 
-	manager, err := a.newManager(ctx, cat, metrics, as, svidStoreCache)
+	var rotationEvents *rotationevents.Broker
+	if a.c.RotationEventsAddress != nil {
+		rotationEvents = rotationevents.NewBroker()
+	}
+
+	manager, err := a.newManager(ctx, cat, metrics, as, svidStoreCache, rotationEvents)
 	if err != nil {
 		return err
 	}
 
 	storeService := a.newSVIDStoreService(svidStoreCache, cat, metrics)
 	workloadAttestor := workload_attestor.New(&workload_attestor.Config{
-		Catalog: cat,
-		Log:     a.c.Log.WithField(telemetry.SubsystemName, telemetry.WorkloadAttestor),
-		Metrics: metrics,
+		Catalog:  cat,
+		Log:      a.c.Log.WithField(telemetry.SubsystemName, telemetry.WorkloadAttestor),
+		Metrics:  metrics,
+		CacheTTL: a.c.WorkloadAttestationCacheTTL,
+
+		RequiredAttestors: a.c.RequiredWorkloadAttestors,
 	})
 
 	endpoints := a.newEndpoints(metrics, manager, workloadAttestor)
 
+	a.lastSync = manager.GetLastSync
 	if err := healthChecker.AddCheck("agent", a); err != nil {
 		return fmt.Errorf("failed adding healthcheck: %w", err)
 	}
@@ -106,7 +133,7 @@ func (a *Agent) Run(ctx context.Context) error {
 		storeService.Run,
 		endpoints.ListenAndServe,
 		metrics.ListenAndServe,
-		util.SerialRun(a.waitForTestDial, healthChecker.ListenAndServe),
+		util.SerialRun(a.waitForTestDial, a.notifyReady, healthChecker.ListenAndServe),
 	}
 
 	if a.c.AdminBindAddress != nil {
@@ -114,10 +141,28 @@ func (a *Agent) Run(ctx context.Context) error {
 		tasks = append(tasks, adminEndpoints.ListenAndServe)
 	}
 
+	if rotationEvents != nil {
+		tasks = append(tasks, rotationevents.New(&rotationevents.Config{
+			Addr:   a.c.RotationEventsAddress,
+			Mode:   a.c.RotationEventsMode,
+			Group:  a.c.RotationEventsGroup,
+			Broker: rotationEvents,
+			Log:    a.c.Log.WithField(telemetry.SubsystemName, telemetry.RotationEvents),
+		}).ListenAndServe)
+	}
+
 	if a.c.LogReopener != nil {
 		tasks = append(tasks, a.c.LogReopener)
 	}
 
+	if a.c.ReloadConfig != nil {
+		tasks = append(tasks, a.reloadConfigOnSignal(reloadTargets{
+			plugins:   cat,
+			manager:   manager,
+			endpoints: endpoints,
+		}))
+	}
+
 	err = util.RunTasks(ctx, tasks...)
 	if errors.Is(err, context.Canceled) {
 		err = nil
@@ -192,25 +237,38 @@ func (a *Agent) attest(ctx context.Context, cat catalog.Catalog, metrics telemet
 		BundleCachePath:   a.bundleCachePath(),
 		SVIDCachePath:     a.agentSVIDPath(),
 		Log:               a.c.Log.WithField(telemetry.SubsystemName, telemetry.Attestor),
-		ServerAddress:     a.c.ServerAddress,
+		ServerAddress:     a.serverAddress(),
+		GRPCOptions:       a.c.ServerGRPCOptions,
+
+		X509PoPTLSCertificate: a.c.X509PoPTLSCertificate,
+		BootstrapSVID:         a.c.BootstrapSVID,
 	}
 	return node_attestor.New(&config).Attest(ctx)
 }
 
-func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, as *node_attestor.AttestationResult, cache *storecache.Cache) (manager.Manager, error) {
+func (a *Agent) newManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, as *node_attestor.AttestationResult, cache *storecache.Cache, rotationEvents *rotationevents.Broker) (manager.Manager, error) {
 	config := &manager.Config{
-		SVID:            as.SVID,
-		SVIDKey:         as.Key,
-		Bundle:          as.Bundle,
-		Catalog:         cat,
-		TrustDomain:     a.c.TrustDomain,
-		ServerAddr:      a.c.ServerAddress,
-		Log:             a.c.Log.WithField(telemetry.SubsystemName, telemetry.Manager),
-		Metrics:         metrics,
-		BundleCachePath: a.bundleCachePath(),
-		SVIDCachePath:   a.agentSVIDPath(),
-		SyncInterval:    a.c.SyncInterval,
-		SVIDStoreCache:  cache,
+		SVID:             as.SVID,
+		SVIDKey:          as.Key,
+		Bundle:           as.Bundle,
+		Catalog:          cat,
+		TrustDomain:      a.c.TrustDomain,
+		ServerAddr:       a.serverAddress(),
+		Log:              a.c.Log.WithField(telemetry.SubsystemName, telemetry.Manager),
+		Metrics:          metrics,
+		BundleCachePath:  a.bundleCachePath(),
+		SVIDCachePath:    a.agentSVIDPath(),
+		SyncInterval:     a.c.SyncInterval,
+		SVIDStoreCache:   cache,
+		LazySVIDs:        a.c.LazySVIDs,
+		WorkloadKeyType:  a.c.WorkloadKeyType,
+		WorkloadDNSNames: a.c.WorkloadDNSNames,
+		GRPCOptions:      a.c.ServerGRPCOptions,
+		DegradedMode:     a.c.DegradedMode,
+		RotationEvents:   rotationEvents,
+	}
+	if a.c.PrewarmSVIDs {
+		config.WorkloadSVIDsPath = a.workloadSVIDsPath()
 	}
 
 	mgr := manager.New(config)
@@ -245,8 +303,11 @@ func (a *Agent) newSVIDStoreService(cache *storecache.Cache, cat catalog.Catalog
 func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, attestor workload_attestor.Attestor) endpoints.Server {
 	return endpoints.New(endpoints.Config{
 		BindAddr:                      a.c.BindAddress,
+		BindAddrMode:                  a.c.BindAddressMode,
+		BindAddrGroup:                 a.c.BindAddressGroup,
 		Attestor:                      attestor,
 		Manager:                       mgr,
+		MaxSyncAge:                    healthv1.MaxSyncAge(a.c.SyncInterval),
 		Log:                           a.c.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
 		Metrics:                       metrics,
 		DefaultSVIDName:               a.c.DefaultSVIDName,
@@ -255,7 +316,14 @@ func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, att
 		DisableSPIFFECertValidation:   a.c.DisableSPIFFECertValidation,
 		AllowUnauthenticatedVerifiers: a.c.AllowUnauthenticatedVerifiers,
 		AllowedForeignJWTClaims:       a.c.AllowedForeignJWTClaims,
+		AllowedJWTAudiences:           a.c.AllowedJWTAudiences,
+		AllowedForeignJWTTrustDomains: a.c.AllowedForeignJWTTrustDomains,
+		AllowedJWTSVIDClockSkew:       a.c.AllowedJWTSVIDClockSkew,
 		TrustDomain:                   a.c.TrustDomain,
+		CallerPolicy:                  a.c.WorkloadAPICallerPolicy,
+		RateLimits:                    a.c.WorkloadAPIRateLimits,
+		MaxConcurrentStreams:          a.c.WorkloadAPIMaxConcurrentStreams,
+		AdditionalSockets:             a.c.AdditionalWorkloadAPISockets,
 	})
 }
 
@@ -272,6 +340,33 @@ func (a *Agent) newAdminEndpoints(mgr manager.Manager, attestor workload_attesto
 
 	return admin_api.New(config)
 }
+
+// bindAddress returns the address the Workload API is bound to.
+func (a *Agent) bindAddress() net.Addr {
+	a.bindAddrMtx.RLock()
+	defer a.bindAddrMtx.RUnlock()
+	return a.c.BindAddress
+}
+
+func (a *Agent) setBindAddress(addr net.Addr) {
+	a.bindAddrMtx.Lock()
+	defer a.bindAddrMtx.Unlock()
+	a.c.BindAddress = addr
+}
+
+// serverAddress returns the address of the SPIRE server.
+func (a *Agent) serverAddress() string {
+	a.serverAddrMtx.RLock()
+	defer a.serverAddrMtx.RUnlock()
+	return a.c.ServerAddress
+}
+
+func (a *Agent) setServerAddress(addr string) {
+	a.serverAddrMtx.Lock()
+	defer a.serverAddrMtx.Unlock()
+	a.c.ServerAddress = addr
+}
+
 func (a *Agent) bundleCachePath() string {
 	return path.Join(a.c.DataDir, "bundle.der")
 }
@@ -280,11 +375,25 @@ func (a *Agent) agentSVIDPath() string {
 	return path.Join(a.c.DataDir, "agent_svid.der")
 }
 
+func (a *Agent) workloadSVIDsPath() string {
+	return path.Join(a.c.DataDir, "workload_svids.cache")
+}
+
+// notifyReady notifies systemd (when started with Type=notify) that the
+// agent is ready. It is run once the agent has attested and the Workload API
+// is being served. This function always returns nil.
+func (a *Agent) notifyReady(context.Context) error {
+	if err := systemd.Notify(systemd.Ready); err != nil {
+		a.c.Log.WithError(err).Warn("Failed to notify systemd of readiness")
+	}
+	return nil
+}
+
 // waitForTestDial calls health.WaitForTestDial to wait for a connection to the
 // SPIRE Agent API socket. This function always returns nil, even if
 // health.WaitForTestDial exited due to a timeout.
 func (a *Agent) waitForTestDial(ctx context.Context) error {
-	health.WaitForTestDial(ctx, a.c.BindAddress)
+	health.WaitForTestDial(ctx, a.bindAddress())
 	return nil
 }
 
@@ -292,15 +401,16 @@ func (a *Agent) waitForTestDial(ctx context.Context) error {
 func (a *Agent) CheckHealth() health.State {
 	err := a.checkWorkloadAPI()
 
-	// Both liveness and readiness checks are done by
-	// agents ability to create new Workload API client
-	// for the X509SVID service.
-	// TODO: Better live check for agent.
+	// Liveness is determined by the agent's ability to create a new Workload
+	// API client for the X509SVID service. To be ready, the agent must also
+	// have recently synchronized with the server.
+	syncErr := a.checkSync()
 	return health.State{
-		Ready: err == nil,
+		Ready: err == nil && syncErr == nil,
 		Live:  err == nil,
 		ReadyDetails: agentHealthDetails{
 			WorkloadAPIErr: errString(err),
+			SyncErr:        errString(syncErr),
 		},
 		LiveDetails: agentHealthDetails{
 			WorkloadAPIErr: errString(err),
@@ -309,7 +419,7 @@ func (a *Agent) CheckHealth() health.State {
 }
 
 func (a *Agent) checkWorkloadAPI() error {
-	clientOption, err := util.GetWorkloadAPIClientOption(a.c.BindAddress)
+	clientOption, err := util.GetWorkloadAPIClientOption(a.bindAddress())
 	if err != nil {
 		a.c.Log.WithError(err).Error("Failed to get Workload API client options for health check")
 		return err
@@ -323,8 +433,19 @@ func (a *Agent) checkWorkloadAPI() error {
 	return nil
 }
 
+func (a *Agent) checkSync() error {
+	if a.lastSync == nil {
+		return errors.New("agent has not synchronized with the server yet")
+	}
+	if syncAge := time.Since(a.lastSync()); syncAge > healthv1.MaxSyncAge(a.c.SyncInterval) {
+		return fmt.Errorf("agent has not synchronized with the server in %s", syncAge.Truncate(time.Second))
+	}
+	return nil
+}
+
 type agentHealthDetails struct {
 	WorkloadAPIErr string `json:"make_new_x509_err,omitempty"`
+	SyncErr        string `json:"sync_err,omitempty"`
 }
 
 func errString(err error) string {
@@ -325,7 +325,7 @@ func (s *Service) SubscribeToJWTBundles(req *delegatedidentityv1.SubscribeToJWTB
 	// send initial update....
 	jwtbundles := make(map[string][]byte)
 	for td, bundle := range subscriber.Value() {
-		jwksBytes, err := bundleutil.Marshal(bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+		jwksBytes, err := bundleutil.Marshal(bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS(), bundleutil.WithRefreshHintAndSequence())
 		if err != nil {
 			return err
 		}
@@ -346,7 +346,7 @@ func (s *Service) SubscribeToJWTBundles(req *delegatedidentityv1.SubscribeToJWTB
 				return err
 			}
 			for td, bundle := range subscriber.Next() {
-				jwksBytes, err := bundleutil.Marshal(bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+				jwksBytes, err := bundleutil.Marshal(bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS(), bundleutil.WithRefreshHintAndSequence())
 				if err != nil {
 					return err
 				}
@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	debugv1 "github.com/spiffe/spire/pkg/agent/api/debug/v1"
 	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
+	inspectv1 "github.com/spiffe/spire/pkg/agent/api/inspect/v1"
 	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -25,7 +26,10 @@ type Endpoints struct {
 
 func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	unaryInterceptor, streamInterceptor := middleware.Interceptors(
-		middleware.WithLogger(e.c.Log),
+		middleware.Chain(
+			middleware.WithLogger(e.c.Log),
+			middleware.WithRequestID(),
+		),
 	)
 
 	server := grpc.NewServer(
@@ -36,6 +40,7 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 
 	e.registerDebugAPI(server)
 	e.registerDelegatedIdentityAPI(server)
+	e.registerInspectAPI(server)
 
 	l, err := e.createListener()
 	if err != nil {
@@ -86,3 +91,13 @@ func (e *Endpoints) registerDelegatedIdentityAPI(server *grpc.Server) {
 
 	delegatedidentityv1.RegisterService(server, service)
 }
+
+func (e *Endpoints) registerInspectAPI(server *grpc.Server) {
+	service := inspectv1.New(inspectv1.Config{
+		Log:      e.c.Log.WithField(telemetry.SubsystemName, telemetry.InspectAPI),
+		Manager:  e.c.Manager,
+		Attestor: e.c.Attestor,
+	})
+
+	inspectv1.RegisterService(server, service)
+}
@@ -3,7 +3,10 @@ package health
 import (
 	"context"
 	"net"
+	"sync"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -16,6 +19,33 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const (
+	// ReadyService is the service name used to check agent readiness. On top
+	// of the Workload API being served, a ready agent has recently
+	// synchronized with the server.
+	ReadyService = "spire.agent.ready"
+
+	// DefaultMaxSyncAge is how long the agent can go without synchronizing
+	// with the server before it is no longer considered ready, unless the
+	// synchronization interval calls for longer.
+	DefaultMaxSyncAge = 5 * time.Minute
+
+	// maxMissedSyncs is the number of synchronization intervals the agent
+	// can miss before it is no longer considered ready.
+	maxMissedSyncs = 60
+)
+
+// MaxSyncAge returns how long the agent can go without synchronizing with the
+// server before it is no longer considered ready, given its synchronization
+// interval. It is never shorter than DefaultMaxSyncAge, so readiness does not
+// flap while synchronization backs off after errors.
+func MaxSyncAge(syncInterval time.Duration) time.Duration {
+	if maxSyncAge := maxMissedSyncs * syncInterval; maxSyncAge > DefaultMaxSyncAge {
+		return maxSyncAge
+	}
+	return DefaultMaxSyncAge
+}
+
 // RegisterService registers the service on the gRPC server.
 func RegisterService(s *grpc.Server, service *Service) {
 	grpc_health_v1.RegisterHealthServer(s, service)
@@ -25,12 +55,32 @@ func RegisterService(s *grpc.Server, service *Service) {
 type Config struct {
 	// Addr is the Workload API socket address
 	Addr net.Addr
+
+	// LastSync returns the time of the last successful synchronization with
+	// the server. Required to check readiness.
+	LastSync func() time.Time
+
+	// MaxSyncAge is how old the last synchronization can be for the agent
+	// to be ready. Defaults to DefaultMaxSyncAge.
+	MaxSyncAge time.Duration
+
+	// Clock is used to determine how old the last synchronization is
+	Clock clock.Clock
 }
 
 // New creates a new Health service
 func New(config Config) *Service {
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
+	if config.MaxSyncAge == 0 {
+		config.MaxSyncAge = DefaultMaxSyncAge
+	}
 	return &Service{
-		addr: config.Addr,
+		addr:       config.Addr,
+		lastSync:   config.LastSync,
+		maxSyncAge: config.MaxSyncAge,
+		clock:      config.Clock,
 	}
 }
 
@@ -38,18 +88,41 @@ func New(config Config) *Service {
 type Service struct {
 	grpc_health_v1.UnimplementedHealthServer
 
+	lastSync   func() time.Time
+	maxSyncAge time.Duration
+	clock      clock.Clock
+
+	mu   sync.RWMutex
 	addr net.Addr
 }
 
+// SetAddr sets the Workload API socket address used to check the health of
+// the agent.
+func (s *Service) SetAddr(addr net.Addr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addr = addr
+}
+
 func (s *Service) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
 	log := rpccontext.Logger(ctx)
 
-	// Ensure per-service health is not being requested.
-	if req.Service != "" {
+	// Ensure per-service health is not being requested, other than readiness.
+	switch req.Service {
+	case "":
+	case ReadyService:
+		if s.lastSync == nil {
+			return nil, api.MakeErr(log, codes.Unimplemented, "readiness check is not available", nil)
+		}
+	default:
 		return nil, api.MakeErr(log, codes.InvalidArgument, "per-service health is not supported", nil)
 	}
 
-	clientOption, err := util.GetWorkloadAPIClientOption(s.addr)
+	s.mu.RLock()
+	addr := s.addr
+	s.mu.RUnlock()
+
+	clientOption, err := util.GetWorkloadAPIClientOption(addr)
 	if err != nil {
 		return nil, api.MakeErr(log, codes.InvalidArgument, "could not get Workload API client options", err)
 	}
@@ -69,6 +142,16 @@ func (s *Service) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequ
 		}).Warn("Health check failed")
 	}
 
+	if healthStatus == grpc_health_v1.HealthCheckResponse_SERVING && req.Service == ReadyService {
+		if syncAge := s.clock.Now().Sub(s.lastSync()); syncAge > s.maxSyncAge {
+			healthStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			log.WithFields(logrus.Fields{
+				telemetry.Reason:      "agent has not synchronized with the server recently",
+				telemetry.ElapsedTime: syncAge,
+			}).Warn("Readiness check failed")
+		}
+	}
+
 	return &grpc_health_v1.HealthCheckResponse{
 		Status: healthStatus,
 	}, nil
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/x509"
 	"testing"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
@@ -33,11 +35,19 @@ func TestServiceCheck(t *testing.T) {
 	ca := testca.New(t, td)
 	x509SVID := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/workload"))
 	bundle := ca.X509Bundle()
+	clk := clock.NewMock()
+	syncedAgo := func(d time.Duration) func() time.Time {
+		return func() time.Time {
+			return clk.Now().Add(-d)
+		}
+	}
 
 	for _, tt := range []struct {
 		name                string
 		wlapiCode           codes.Code
 		service             string
+		lastSync            func() time.Time
+		maxSyncAge          time.Duration
 		expectCode          codes.Code
 		expectMsg           string
 		expectServingStatus grpc_health_v1.HealthCheckResponse_ServingStatus
@@ -70,6 +80,68 @@ func TestServiceCheck(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:                "ready",
+			service:             health.ReadyService,
+			lastSync:            syncedAgo(time.Minute),
+			expectCode:          codes.OK,
+			expectServingStatus: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name:                "not ready when last sync is too old",
+			service:             health.ReadyService,
+			lastSync:            syncedAgo(10 * time.Minute),
+			expectCode:          codes.OK,
+			expectServingStatus: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Readiness check failed",
+					Data: logrus.Fields{
+						"elapsed_time": "10m0s",
+						"reason":       "agent has not synchronized with the server recently",
+					},
+				},
+			},
+		},
+		{
+			name:                "ready with a longer max sync age",
+			service:             health.ReadyService,
+			lastSync:            syncedAgo(10 * time.Minute),
+			maxSyncAge:          health.MaxSyncAge(time.Minute),
+			expectCode:          codes.OK,
+			expectServingStatus: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name:                "not ready when workload API is unavailable",
+			service:             health.ReadyService,
+			wlapiCode:           codes.Unavailable,
+			lastSync:            syncedAgo(time.Minute),
+			expectCode:          codes.OK,
+			expectServingStatus: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Health check failed",
+					Data: logrus.Fields{
+						"error":  "rpc error: code = Unavailable desc = ",
+						"reason": "unable to fetch X.509 context from Workload API",
+					},
+				},
+			},
+		},
+		{
+			name:       "readiness not available",
+			service:    health.ReadyService,
+			expectCode: codes.Unimplemented,
+			expectMsg:  "readiness check is not available",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Readiness check is not available",
+				},
+			},
+		},
 		{
 			name:       "service name not supported",
 			service:    "WHATEVER",
@@ -94,7 +166,10 @@ func TestServiceCheck(t *testing.T) {
 			}
 
 			service := health.New(health.Config{
-				Addr: spiretest.StartWorkloadAPI(t, wlAPI),
+				Addr:       spiretest.StartWorkloadAPI(t, wlAPI),
+				LastSync:   tt.lastSync,
+				MaxSyncAge: tt.maxSyncAge,
+				Clock:      clk,
 			})
 
 			conn, done := spiretest.NewAPIServer(t,
@@ -150,3 +225,9 @@ func (w fakeWorkloadAPI) FetchX509SVID(_ *workload.X509SVIDRequest, stream workl
 		},
 	})
 }
+
+func TestMaxSyncAge(t *testing.T) {
+	require.Equal(t, health.DefaultMaxSyncAge, health.MaxSyncAge(0))
+	require.Equal(t, health.DefaultMaxSyncAge, health.MaxSyncAge(5*time.Second))
+	require.Equal(t, time.Hour, health.MaxSyncAge(time.Minute))
+}
@@ -0,0 +1,92 @@
+package inspect
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	workload_attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/private/agent/inspect"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers the inspect service on the provided server
+func RegisterService(s *grpc.Server, service *Service) {
+	inspect.RegisterInspectServer(s, service)
+}
+
+// Config configurations for the inspect service
+type Config struct {
+	Log      logrus.FieldLogger
+	Manager  manager.Manager
+	Attestor workload_attestor.Attestor
+}
+
+// New creates a new inspect service
+func New(config Config) *Service {
+	return &Service{
+		log:      config.Log,
+		manager:  config.Manager,
+		attestor: config.Attestor,
+	}
+}
+
+// Service implements the inspect server
+type Service struct {
+	inspect.UnsafeInspectServer
+
+	log      logrus.FieldLogger
+	manager  manager.Manager
+	attestor workload_attestor.Attestor
+}
+
+// InspectWorkload attests the workload running in the given process and
+// returns the selectors discovered and the identities the workload is
+// entitled to
+func (s *Service) InspectWorkload(ctx context.Context, req *inspect.InspectWorkloadRequest) (*inspect.InspectWorkloadResponse, error) {
+	if req.Pid <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pid %d: must be greater than zero", req.Pid)
+	}
+
+	log := s.log.WithField(telemetry.PID, req.Pid)
+	selectors := s.attestor.Attest(ctx, int(req.Pid))
+	identities := s.manager.MatchingIdentities(selectors)
+	log.WithField(telemetry.Count, len(identities)).Debug("Inspected workload")
+
+	resp := &inspect.InspectWorkloadResponse{
+		Selectors: protoFromSelectors(selectors),
+	}
+	for _, identity := range identities {
+		resp.Identities = append(resp.Identities, protoFromIdentity(identity))
+	}
+	return resp, nil
+}
+
+func protoFromIdentity(identity cache.Identity) *inspect.Identity {
+	out := &inspect.Identity{
+		EntryId:   identity.Entry.EntryId,
+		SpiffeId:  identity.Entry.SpiffeId,
+		ParentId:  identity.Entry.ParentId,
+		Selectors: protoFromSelectors(identity.Entry.Selectors),
+	}
+	if len(identity.SVID) > 0 {
+		out.X509SvidExpiresAt = identity.SVID[0].NotAfter.Unix()
+	}
+	return out
+}
+
+func protoFromSelectors(selectors []*common.Selector) []*inspect.Selector {
+	out := make([]*inspect.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		out = append(out, &inspect.Selector{
+			Type:  s.Type,
+			Value: s.Value,
+		})
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package inspect
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/proto/private/agent/inspect"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestInspectWorkload(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	workloadSelectors := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "unix", Value: "user:alice"},
+	}
+	withSVID := cache.Identity{
+		Entry: &common.RegistrationEntry{
+			EntryId:   "entry-1",
+			SpiffeId:  "spiffe://example.org/workload",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+		SVID: []*x509.Certificate{{NotAfter: expiresAt}},
+	}
+	withoutSVID := cache.Identity{
+		Entry: &common.RegistrationEntry{
+			EntryId:   "entry-2",
+			SpiffeId:  "spiffe://example.org/other",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "user:alice"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		pid        int32
+		selectors  []*common.Selector
+		identities []cache.Identity
+		expectCode codes.Code
+		expectMsg  string
+		expectResp *inspect.InspectWorkloadResponse
+	}{
+		{
+			name:       "invalid pid",
+			pid:        -1,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid pid -1: must be greater than zero",
+		},
+		{
+			name:       "no selectors",
+			pid:        1000,
+			expectResp: &inspect.InspectWorkloadResponse{},
+		},
+		{
+			name:      "no identities",
+			pid:       1000,
+			selectors: workloadSelectors,
+			expectResp: &inspect.InspectWorkloadResponse{
+				Selectors: []*inspect.Selector{
+					{Type: "unix", Value: "uid:1000"},
+					{Type: "unix", Value: "user:alice"},
+				},
+			},
+		},
+		{
+			name:       "identities",
+			pid:        1000,
+			selectors:  workloadSelectors,
+			identities: []cache.Identity{withSVID, withoutSVID},
+			expectResp: &inspect.InspectWorkloadResponse{
+				Selectors: []*inspect.Selector{
+					{Type: "unix", Value: "uid:1000"},
+					{Type: "unix", Value: "user:alice"},
+				},
+				Identities: []*inspect.Identity{
+					{
+						EntryId:           "entry-1",
+						SpiffeId:          "spiffe://example.org/workload",
+						ParentId:          "spiffe://example.org/agent",
+						Selectors:         []*inspect.Selector{{Type: "unix", Value: "uid:1000"}},
+						X509SvidExpiresAt: expiresAt.Unix(),
+					},
+					{
+						EntryId:   "entry-2",
+						SpiffeId:  "spiffe://example.org/other",
+						ParentId:  "spiffe://example.org/agent",
+						Selectors: []*inspect.Selector{{Type: "unix", Value: "user:alice"}},
+					},
+				},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			attestor := &fakeAttestor{selectors: tt.selectors}
+			manager := &fakeManager{identities: tt.identities}
+			client := setupClient(t, attestor, manager)
+
+			resp, err := client.InspectWorkload(context.Background(), &inspect.InspectWorkloadRequest{Pid: tt.pid})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			require.Equal(t, int(tt.pid), attestor.pid)
+			require.Equal(t, tt.selectors, manager.selectors)
+		})
+	}
+}
+
+func setupClient(t *testing.T, attestor *fakeAttestor, manager *fakeManager) inspect.InspectClient {
+	log, _ := test.NewNullLogger()
+
+	service := New(Config{
+		Log:      log,
+		Manager:  manager,
+		Attestor: attestor,
+	})
+
+	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.WithLogger(log))
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	RegisterService(server, service)
+	addr := spiretest.ServeGRPCServerOnTempUDSSocket(t, server)
+
+	conn, err := grpc.Dial("unix:"+addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return inspect.NewInspectClient(conn)
+}
+
+type fakeAttestor struct {
+	selectors []*common.Selector
+	pid       int
+}
+
+func (a *fakeAttestor) Attest(ctx context.Context, pid int) []*common.Selector {
+	a.pid = pid
+	return a.selectors
+}
+
+type fakeManager struct {
+	manager.Manager
+
+	identities []cache.Identity
+	selectors  []*common.Selector
+}
+
+func (m *fakeManager) MatchingIdentities(selectors []*common.Selector) []cache.Identity {
+	m.selectors = selectors
+	return m.identities
+}
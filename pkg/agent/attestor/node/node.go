@@ -2,6 +2,7 @@ package attestor
 
 import (
 	"context"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -9,7 +10,9 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/spiffe/spire/pkg/agent/catalog"
 	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/manager"
@@ -22,6 +25,7 @@ import (
 	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
 	telemetry_common "github.com/spiffe/spire/pkg/common/telemetry/common"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/zeebo/errs"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -52,6 +56,17 @@ type Config struct {
 	SVIDCachePath     string
 	Log               logrus.FieldLogger
 	ServerAddress     string
+	GRPCOptions       client.GRPCOptions
+
+	// X509PoPTLSCertificate is an optional externally issued certificate
+	// presented to the server during the TLS handshake to attest with the
+	// "x509pop_tls" attestation type instead of the node attestor plugin.
+	X509PoPTLSCertificate *tls.Certificate
+
+	// BootstrapSVID is an optional agent SVID minted by an administrator and
+	// delivered out-of-band. It is used instead of performing node
+	// attestation when no SVID has been cached yet.
+	BootstrapSVID *tls.Certificate
 }
 
 type attestor struct {
@@ -82,6 +97,19 @@ func (a *attestor) Attest(ctx context.Context) (res *AttestationResult, err erro
 	}
 
 	switch {
+	case svid == nil && a.c.BootstrapSVID != nil:
+		bootstrapSVID, bootstrapKey, bootstrapErr := a.loadBootstrapSVID(bundle)
+		if bootstrapErr == nil {
+			svid, key = bootstrapSVID, bootstrapKey
+			log.WithField(telemetry.SPIFFEID, svid[0].URIs[0].String()).Info("SVID is not found. Using bootstrap SVID")
+			break
+		}
+		log.WithError(bootstrapErr).Warn("Bootstrap SVID cannot be used. Starting node attestation")
+		svid, bundle, err = a.newSVID(ctx, key, bundle)
+		if err != nil {
+			return nil, err
+		}
+		log.WithField(telemetry.SPIFFEID, svid[0].URIs[0].String()).Info("Node attestation was successful")
 	case svid == nil:
 		log.Info("SVID is not found. Starting node attestation")
 		svid, bundle, err = a.newSVID(ctx, key, bundle)
@@ -108,6 +136,11 @@ func (a *attestor) loadSVID(ctx context.Context) ([]*x509.Certificate, keymanage
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to load private key: %w", err)
 	}
+	if bootstrapKey, err := a.bootstrapKey(); err == nil {
+		// The cached SVID may still be the bootstrap SVID, whose key does not
+		// live in the key manager.
+		allKeys = append(allKeys, bootstrapKey)
+	}
 
 	svid := a.readSVIDFromDisk()
 	svidKey, svidKeyExists := findKeyForSVID(allKeys, svid)
@@ -136,6 +169,68 @@ func (a *attestor) loadSVID(ctx context.Context) ([]*x509.Certificate, keymanage
 	return nil, svidKey, nil
 }
 
+// loadBootstrapSVID returns the configured bootstrap SVID and its key. The
+// SVID must be unexpired, chain up to the bundle and identify an agent in the
+// trust domain.
+func (a *attestor) loadBootstrapSVID(bundle *bundleutil.Bundle) ([]*x509.Certificate, keymanager.Key, error) {
+	if bundle == nil {
+		return nil, nil, errors.New("a trust bundle is required to verify the bootstrap SVID")
+	}
+
+	key, err := a.bootstrapKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svid, err := x509util.RawCertsToCertificates(a.c.BootstrapSVID.Certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse bootstrap SVID: %w", err)
+	}
+	if len(svid) == 0 {
+		return nil, nil, errors.New("bootstrap SVID is empty")
+	}
+	if IsSVIDExpired(svid, time.Now) {
+		return nil, nil, fmt.Errorf("bootstrap SVID expired at %s", svid[0].NotAfter)
+	}
+
+	bundleSource := x509bundle.FromX509Authorities(a.c.TrustDomain, bundle.RootCAs())
+	id, _, err := x509svid.Verify(svid, bundleSource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify bootstrap SVID: %w", err)
+	}
+	if !id.MemberOf(a.c.TrustDomain) || !idutil.IsAgentPath(id.Path()) {
+		return nil, nil, fmt.Errorf("bootstrap SVID %q is not an agent SVID in trust domain %q", id, a.c.TrustDomain)
+	}
+
+	equal, err := cryptoutil.PublicKeyEqual(svid[0].PublicKey, key.Public())
+	if err != nil || !equal {
+		return nil, nil, errors.New("bootstrap SVID does not match the bootstrap key")
+	}
+	return svid, key, nil
+}
+
+// bootstrapKey returns the private key of the bootstrap SVID, if configured.
+func (a *attestor) bootstrapKey() (keymanager.Key, error) {
+	if a.c.BootstrapSVID == nil {
+		return nil, errors.New("no bootstrap SVID configured")
+	}
+	signer, ok := a.c.BootstrapSVID.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("bootstrap key type %T is not a signer", a.c.BootstrapSVID.PrivateKey)
+	}
+	return bootstrapSVIDKey{Signer: signer}, nil
+}
+
+// bootstrapSVIDKey adapts the private key of the bootstrap SVID, which is not
+// held by the key manager, to a key manager key.
+type bootstrapSVIDKey struct {
+	crypto.Signer
+}
+
+func (bootstrapSVIDKey) ID() string {
+	return "bootstrap-svid"
+}
+
 // IsSVIDExpired returns true if the X.509 SVID provided is expired
 func IsSVIDExpired(svid []*x509.Certificate, timeNow func() time.Time) bool {
 	if len(svid) == 0 {
@@ -188,8 +283,13 @@ func (a *attestor) newSVID(ctx context.Context, key keymanager.Key, bundle *bund
 	counter := telemetry_agent.StartNodeAttestorNewSVIDCall(a.c.Metrics)
 	defer counter.Done(&err)
 
-	attestor := nodeattestor.JoinToken(a.c.Log, a.c.JoinToken)
-	if a.c.JoinToken == "" {
+	var attestor nodeattestor.NodeAttestor
+	switch {
+	case a.c.JoinToken != "":
+		attestor = nodeattestor.JoinToken(a.c.Log, a.c.JoinToken)
+	case a.c.X509PoPTLSCertificate != nil:
+		attestor = nodeattestor.X509PoPTLS(a.c.Log, a.c.X509PoPTLSCertificate.Certificate[0])
+	default:
 		attestor = a.c.Catalog.GetNodeAttestor()
 	}
 	telemetry_common.AddAttestorType(counter, attestor.Name())
@@ -221,9 +321,11 @@ func (a *attestor) newSVID(ctx context.Context, key keymanager.Key, bundle *bund
 func (a *attestor) serverConn(ctx context.Context, bundle *bundleutil.Bundle) (*grpc.ClientConn, error) {
 	if bundle != nil {
 		return client.DialServer(ctx, client.DialServerConfig{
-			Address:     a.c.ServerAddress,
-			TrustDomain: a.c.TrustDomain,
-			GetBundle:   bundle.RootCAs,
+			Address:           a.c.ServerAddress,
+			TrustDomain:       a.c.TrustDomain,
+			GetBundle:         bundle.RootCAs,
+			GRPCOptions:       a.c.GRPCOptions,
+			ClientCertificate: a.c.X509PoPTLSCertificate,
 		})
 	}
 
@@ -262,13 +364,17 @@ func (a *attestor) serverConn(ctx context.Context, bundle *bundleutil.Bundle) (*
 			return nil
 		},
 	}
+	if a.c.X509PoPTLSCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*a.c.X509PoPTLSCertificate}
+	}
 
-	return grpc.DialContext(ctx, a.c.ServerAddress,
+	options := append([]grpc.DialOption{
 		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
 		grpc.FailOnNonTempDialError(true),
 		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
 		grpc.WithReturnConnectionError(),
-	)
+	}, a.c.GRPCOptions.DialOptions()...)
+	return grpc.DialContext(ctx, a.c.ServerAddress, options...)
 }
 
 func findKeyForSVID(keys []keymanager.Key, svid []*x509.Certificate) (keymanager.Key, bool) {
@@ -1,6 +1,7 @@
 package attestor_test
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/rand"
@@ -33,12 +34,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 )
 
 var (
-	caKey       = testkey.MustEC256()
-	serverKey   = testkey.MustEC256()
-	trustDomain = spiffeid.RequireTrustDomainFromString("domain.test")
+	caKey        = testkey.MustEC256()
+	serverKey    = testkey.MustEC256()
+	externalKey  = testkey.MustEC256()
+	bootstrapKey = testkey.MustEC256()
+	trustDomain  = spiffeid.RequireTrustDomainFromString("domain.test")
 )
 
 func TestAttestor(t *testing.T) {
@@ -52,6 +56,24 @@ func TestAttestor(t *testing.T) {
 	serverCert := createServerCertificate(t, caCert)
 	agentCert := createAgentCertificate(t, caCert, agentKey, "/test/foo")
 	expiredCert := createExpiredCertificate(t, caCert, agentKey)
+	externalCert := createExternalCertificate(t)
+	externalTLSCert := &tls.Certificate{
+		Certificate: [][]byte{externalCert.Raw},
+		PrivateKey:  externalKey,
+	}
+	bootstrapCert := createAgentCertificate(t, caCert, bootstrapKey, "/test/foo")
+	bootstrapSVID := &tls.Certificate{
+		Certificate: [][]byte{bootstrapCert.Raw},
+		PrivateKey:  bootstrapKey,
+	}
+	expiredBootstrapSVID := &tls.Certificate{
+		Certificate: [][]byte{createExpiredCertificate(t, caCert, bootstrapKey).Raw},
+		PrivateKey:  bootstrapKey,
+	}
+	untrustedBootstrapSVID := &tls.Certificate{
+		Certificate: [][]byte{externalCert.Raw},
+		PrivateKey:  externalKey,
+	}
 	bundle := &types.Bundle{
 		TrustDomain:     trustDomain.String(),
 		X509Authorities: []*types.X509Certificate{{Asn1: caCert.Raw}},
@@ -68,6 +90,7 @@ func TestAttestor(t *testing.T) {
 				PrivateKey:  serverKey,
 			},
 		},
+		ClientAuth: tls.RequestClientCert,
 		MinVersion: tls.VersionTLS12,
 	}
 
@@ -80,6 +103,8 @@ func TestAttestor(t *testing.T) {
 		err                         string
 		keepAgentKey                bool
 		failFetchingAttestationData bool
+		x509PoPTLSCertificate       *tls.Certificate
+		bootstrapSVID               *tls.Certificate
 		agentService                *fakeAgentService
 		bundleService               *fakeBundleService
 	}{
@@ -198,6 +223,70 @@ func TestAttestor(t *testing.T) {
 				bundle: bundle,
 			},
 		},
+		{
+			name:                  "success with x509pop_tls",
+			bootstrapBundle:       caCert,
+			x509PoPTLSCertificate: externalTLSCert,
+			agentService: &fakeAgentService{
+				svid:           svid,
+				x509PoPTLSLeaf: externalCert.Raw,
+			},
+			bundleService: &fakeBundleService{
+				bundle: bundle,
+			},
+		},
+		{
+			name:                  "insecure bootstrap with x509pop_tls",
+			insecureBootstrap:     true,
+			x509PoPTLSCertificate: externalTLSCert,
+			agentService: &fakeAgentService{
+				svid:           svid,
+				x509PoPTLSLeaf: externalCert.Raw,
+			},
+			bundleService: &fakeBundleService{
+				bundle: bundle,
+			},
+		},
+		{
+			name:            "success with bootstrap SVID",
+			bootstrapBundle: caCert,
+			bootstrapSVID:   bootstrapSVID,
+			agentService: &fakeAgentService{
+				svid:            svid,
+				failAttestAgent: true,
+			},
+		},
+		{
+			name:          "success with cached bootstrap SVID",
+			cachedBundle:  caCert.Raw,
+			cachedSVID:    bootstrapCert.Raw,
+			bootstrapSVID: bootstrapSVID,
+			agentService: &fakeAgentService{
+				svid:            svid,
+				failAttestAgent: true,
+			},
+		},
+		{
+			name:            "expired bootstrap SVID falls back to node attestation",
+			bootstrapBundle: caCert,
+			bootstrapSVID:   expiredBootstrapSVID,
+			agentService: &fakeAgentService{
+				svid: svid,
+			},
+			bundleService: &fakeBundleService{
+				bundle: bundle,
+			},
+		},
+		{
+			name:            "untrusted bootstrap SVID falls back to node attestation",
+			bootstrapBundle: caCert,
+			bootstrapSVID:   untrustedBootstrapSVID,
+			agentService: &fakeAgentService{
+				svid:            svid,
+				failAttestAgent: true,
+			},
+			err: "attestation failed by test",
+		},
 		{
 			name:            "success with join token",
 			bootstrapBundle: caCert,
@@ -338,6 +427,9 @@ func TestAttestor(t *testing.T) {
 				TrustBundle:       makeTrustBundle(testCase.bootstrapBundle),
 				InsecureBootstrap: testCase.insecureBootstrap,
 				ServerAddress:     listener.Addr().String(),
+
+				X509PoPTLSCertificate: testCase.x509PoPTLSCertificate,
+				BootstrapSVID:         testCase.bootstrapSVID,
 			})
 
 			// perform attestation
@@ -369,17 +461,24 @@ type fakeAgentService struct {
 	failAttestAgent    bool
 	challengeResponses []string
 	joinToken          string
+	x509PoPTLSLeaf     []byte
 	svid               *types.X509SVID
 
 	agentv1.AgentServer
 }
 
 func (s *fakeAgentService) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
-	_, err := stream.Recv()
+	req, err := stream.Recv()
 	if err != nil {
 		return err
 	}
 
+	if s.x509PoPTLSLeaf != nil {
+		if err := checkX509PoPTLSAttestation(stream.Context(), req, s.x509PoPTLSLeaf); err != nil {
+			return err
+		}
+	}
+
 	if s.failAttestAgent {
 		return errors.New("attestation failed by test")
 	}
@@ -416,6 +515,23 @@ func (s *fakeAgentService) AttestAgent(stream agentv1.Agent_AttestAgentServer) e
 	})
 }
 
+func checkX509PoPTLSAttestation(ctx context.Context, req *agentv1.AttestAgentRequest, leaf []byte) error {
+	data := req.GetParams().GetData()
+	if data.GetType() != "x509pop_tls" || !bytes.Equal(data.GetPayload(), leaf) {
+		return errors.New("unexpected attestation data")
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return errors.New("no peer information")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 || !bytes.Equal(tlsInfo.State.PeerCertificates[0].Raw, leaf) {
+		return errors.New("client certificate was not presented")
+	}
+	return nil
+}
+
 type fakeBundleService struct {
 	bundle       *types.Bundle
 	getBundleErr error
@@ -482,6 +598,13 @@ func createExpiredCertificate(t *testing.T, caCert *x509.Certificate, agentKey c
 	return createCertificate(t, tmpl, caCert, agentKey, caKey)
 }
 
+func createExternalCertificate(t *testing.T) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		BasicConstraintsValid: true,
+	}
+	return createCertificate(t, tmpl, tmpl, externalKey, externalKey)
+}
+
 func createCertificate(t *testing.T, tmpl, parent *x509.Certificate, certKey, parentKey crypto.Signer) *x509.Certificate {
 	now := time.Now()
 	tmpl.SerialNumber = big.NewInt(0)
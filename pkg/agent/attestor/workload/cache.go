@@ -0,0 +1,91 @@
+package attestor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// processStartTime returns the start time of the process, which along with
+// the PID identifies a process, since PIDs are reused once processes exit.
+// Overridden in tests.
+var processStartTime = defaultProcessStartTime
+
+func defaultProcessStartTime(pid int) (int64, error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0, err
+	}
+	return p.CreateTime()
+}
+
+// attestationCache caches the selectors of workloads, keyed by PID, for a
+// short TTL. Each cached result is tied to the start time of the process it
+// was attested for, so results are not served to a different process that
+// reused the PID.
+type attestationCache struct {
+	ttl time.Duration
+	clk clock.Clock
+
+	mtx       sync.Mutex
+	entries   map[int]cachedAttestation
+	lastPrune time.Time
+}
+
+type cachedAttestation struct {
+	startTime int64
+	selectors []*common.Selector
+	expiresAt time.Time
+}
+
+func newAttestationCache(ttl time.Duration, clk clock.Clock) *attestationCache {
+	return &attestationCache{
+		ttl:       ttl,
+		clk:       clk,
+		entries:   make(map[int]cachedAttestation),
+		lastPrune: clk.Now(),
+	}
+}
+
+// Get returns the cached selectors for the process, if any. A cached result
+// for a process with a different start time means the PID was reused, so
+// the result is evicted.
+func (c *attestationCache) Get(pid int, startTime int64) ([]*common.Selector, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entry, ok := c.entries[pid]
+	if !ok {
+		return nil, false
+	}
+	if entry.startTime != startTime || !c.clk.Now().Before(entry.expiresAt) {
+		delete(c.entries, pid)
+		return nil, false
+	}
+	return append([]*common.Selector(nil), entry.selectors...), true
+}
+
+// Set caches the selectors of the process
+func (c *attestationCache) Set(pid int, startTime int64, selectors []*common.Selector) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.clk.Now()
+	if now.Sub(c.lastPrune) >= c.ttl {
+		for pid, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, pid)
+			}
+		}
+		c.lastPrune = now
+	}
+
+	c.entries[pid] = cachedAttestation{
+		startTime: startTime,
+		selectors: append([]*common.Selector(nil), selectors...),
+		expiresAt: now.Add(c.ttl),
+	}
+}
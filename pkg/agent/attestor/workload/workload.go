@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/agent/catalog"
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
@@ -14,7 +16,8 @@ import (
 )
 
 type attestor struct {
-	c *Config
+	c     *Config
+	cache *attestationCache
 }
 
 type Attestor interface {
@@ -26,31 +29,94 @@ func New(config *Config) Attestor {
 }
 
 func newAttestor(config *Config) *attestor {
-	return &attestor{c: config}
+	wla := &attestor{c: config}
+	if config.CacheTTL > 0 {
+		clk := config.Clock
+		if clk == nil {
+			clk = clock.New()
+		}
+		wla.cache = newAttestationCache(config.CacheTTL, clk)
+	}
+	return wla
 }
 
 type Config struct {
 	Catalog catalog.Catalog
 	Log     logrus.FieldLogger
 	Metrics telemetry.Metrics
+
+	// CacheTTL is how long the selectors of a process are cached. Zero
+	// disables caching.
+	CacheTTL time.Duration
+
+	// Clock is used to expire cached selectors. Defaults to the real clock.
+	Clock clock.Clock
+
+	// RequiredAttestors are the names of the workload attestor plugins that
+	// must all produce selectors for a process. If any of them fails or
+	// produces no selectors, the process is attested with no selectors.
+	RequiredAttestors []string
+}
+
+type attestorResult struct {
+	name      string
+	selectors []*common.Selector
 }
 
 // Attest invokes all workload attestor plugins against the provided PID. If an error
 // is encountered, it is logged and selectors from the failing plugin are discarded.
+// If any of the required attestors does not produce selectors, no selectors
+// are returned.
+// When caching is enabled, the selectors of a process are reused for the
+// cache TTL, as long as the PID has not been reused by a different process.
 func (wla *attestor) Attest(ctx context.Context, pid int) []*common.Selector {
+	if wla.cache == nil {
+		selectors, _ := wla.attest(ctx, pid)
+		return selectors
+	}
+
+	log := wla.c.Log.WithField(telemetry.PID, pid)
+
+	startTime, err := processStartTime(pid)
+	if err != nil {
+		log.WithError(err).Debug("Unable to identify process; not caching workload attestation")
+		selectors, _ := wla.attest(ctx, pid)
+		return selectors
+	}
+
+	if selectors, ok := wla.cache.Get(pid, startTime); ok {
+		telemetry_workload.IncrAttestationCacheHitCounter(wla.c.Metrics)
+		return selectors
+	}
+
+	selectors, complete := wla.attest(ctx, pid)
+
+	// Only cache complete results for the same process that was attested,
+	// since the process may have exited and its PID been reused meanwhile.
+	if complete {
+		if current, err := processStartTime(pid); err == nil && current == startTime {
+			wla.cache.Set(pid, startTime, selectors)
+		}
+	}
+	return selectors
+}
+
+// attest invokes the workload attestor plugins. The returned boolean is
+// false if any of the plugins failed.
+func (wla *attestor) attest(ctx context.Context, pid int) ([]*common.Selector, bool) {
 	counter := telemetry_workload.StartAttestationCall(wla.c.Metrics)
 	defer counter.Done(nil)
 
 	log := wla.c.Log.WithField(telemetry.PID, pid)
 
 	plugins := wla.c.Catalog.GetWorkloadAttestors()
-	sChan := make(chan []*common.Selector)
+	sChan := make(chan attestorResult)
 	errChan := make(chan error)
 
 	for _, p := range plugins {
 		go func(p workloadattestor.WorkloadAttestor) {
 			if selectors, err := wla.invokeAttestor(ctx, p, pid); err == nil {
-				sChan <- selectors
+				sChan <- attestorResult{name: p.Name(), selectors: selectors}
 			} else {
 				errChan <- err
 			}
@@ -59,15 +125,38 @@ func (wla *attestor) Attest(ctx context.Context, pid int) []*common.Selector {
 
 	// Collect the results
 	selectors := []*common.Selector{}
+	attested := make(map[string]bool)
+	complete := true
 	for i := 0; i < len(plugins); i++ {
 		select {
-		case s := <-sChan:
-			selectors = append(selectors, s...)
+		case r := <-sChan:
+			selectors = append(selectors, r.selectors...)
+			if len(r.selectors) > 0 {
+				attested[r.name] = true
+			}
 		case err := <-errChan:
 			log.WithError(err).Error("Failed to collect all selectors for PID")
+			complete = false
 		}
 	}
 
+	// Fail closed if any of the required attestors did not vouch for the
+	// process. The result is not cached so the process can be attested again
+	// once, e.g., its container metadata is available.
+	var missing []string
+	for _, name := range wla.c.RequiredAttestors {
+		if !attested[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		if pid != os.Getpid() {
+			log.WithField(telemetry.Attestor, missing).Warn("Required workload attestors did not produce selectors for PID; no identities will be issued")
+		}
+		selectors = []*common.Selector{}
+		complete = false
+	}
+
 	telemetry_workload.AddDiscoveredSelectorsSample(wla.c.Metrics, float32(len(selectors)))
 	// The agent health check currently exercises the Workload API. Since this
 	// can happen with some frequency, it has a tendency to fill up logs with
@@ -76,7 +165,7 @@ func (wla *attestor) Attest(ctx context.Context, pid int) []*common.Selector {
 	if pid != os.Getpid() {
 		log.WithField(telemetry.Selectors, selectors).Debug("PID attested to have selectors")
 	}
-	return selectors
+	return selectors, complete
 }
 
 // invokeAttestor invokes attestation against the supplied plugin. Should be called from a goroutine.
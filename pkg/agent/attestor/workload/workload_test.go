@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_workload "github.com/spiffe/spire/pkg/common/telemetry/agent/workloadapi"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeagentcatalog"
 	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/fakes/fakeworkloadattestor"
@@ -128,3 +131,105 @@ func (s *WorkloadAttestorTestSuite) TestAttestWorkloadMetrics() {
 
 	s.Require().Equal(expected.AllMetrics(), metrics.AllMetrics())
 }
+
+func (s *WorkloadAttestorTestSuite) TestAttestWorkloadCache() {
+	startTimes := map[int]int64{2: 100, 3: 100}
+	processStartTime = func(pid int) (int64, error) {
+		startTime, ok := startTimes[pid]
+		if !ok {
+			return 0, errors.New("no such process")
+		}
+		return startTime, nil
+	}
+	s.T().Cleanup(func() {
+		processStartTime = defaultProcessStartTime
+	})
+
+	pids := map[int32][]string{
+		2: {"bar"},
+		4: {"bar"},
+	}
+	s.catalog.SetWorkloadAttestors(fakeworkloadattestor.New(s.T(), "fake1", pids))
+
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(s.T())
+	s.attestor = newAttestor(&Config{
+		Catalog:  s.catalog,
+		Log:      log,
+		Metrics:  telemetry.Blackhole{},
+		CacheTTL: time.Second,
+		Clock:    clk,
+	})
+
+	spiretest.AssertProtoListEqual(s.T(), selectors1, s.attestor.Attest(ctx, 2))
+
+	// Changes in the selectors are not observed while cached
+	pids[2] = []string{"baz"}
+	spiretest.AssertProtoListEqual(s.T(), selectors1, s.attestor.Attest(ctx, 2))
+
+	// The PID was reused by another process
+	startTimes[2] = 200
+	selectors := []*common.Selector{{Type: "fake1", Value: "baz"}}
+	spiretest.AssertProtoListEqual(s.T(), selectors, s.attestor.Attest(ctx, 2))
+
+	// The cached selectors expire
+	pids[2] = []string{"qux"}
+	clk.Add(time.Second)
+	selectors = []*common.Selector{{Type: "fake1", Value: "qux"}}
+	spiretest.AssertProtoListEqual(s.T(), selectors, s.attestor.Attest(ctx, 2))
+
+	// Failed attestations are not cached
+	s.Empty(s.attestor.Attest(ctx, 3))
+	pids[3] = []string{"bar"}
+	spiretest.AssertProtoListEqual(s.T(), selectors1, s.attestor.Attest(ctx, 3))
+
+	// Processes that cannot be identified are not cached
+	spiretest.AssertProtoListEqual(s.T(), selectors1, s.attestor.Attest(ctx, 4))
+	pids[4] = []string{"baz"}
+	selectors = []*common.Selector{{Type: "fake1", Value: "baz"}}
+	spiretest.AssertProtoListEqual(s.T(), selectors, s.attestor.Attest(ctx, 4))
+}
+
+func (s *WorkloadAttestorTestSuite) TestAttestWorkloadRequiredAttestors() {
+	s.catalog.SetWorkloadAttestors(
+		fakeworkloadattestor.New(s.T(), "fake1", attestor1Pids),
+		fakeworkloadattestor.New(s.T(), "fake2", attestor2Pids),
+	)
+
+	log, hook := test.NewNullLogger()
+	s.attestor = newAttestor(&Config{
+		Catalog:           s.catalog,
+		Log:               log,
+		Metrics:           telemetry.Blackhole{},
+		RequiredAttestors: []string{"fake1", "fake2"},
+	})
+
+	// attestor2 produces no selectors
+	s.Empty(s.attestor.Attest(ctx, 2))
+	spiretest.AssertLogs(s.T(), hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Required workload attestors did not produce selectors for PID; no identities will be issued",
+			Data: logrus.Fields{
+				telemetry.PID:      "2",
+				telemetry.Attestor: "[fake2]",
+			},
+		},
+	})
+
+	// attestor1 fails
+	s.Empty(s.attestor.Attest(ctx, 3))
+
+	// both have selectors
+	selectors := s.attestor.Attest(ctx, 4)
+	util.SortSelectors(selectors)
+	combined := make([]*common.Selector, 0, len(selectors1)+len(selectors2))
+	combined = append(combined, selectors1...)
+	combined = append(combined, selectors2...)
+	util.SortSelectors(combined)
+	spiretest.AssertProtoListEqual(s.T(), combined, selectors)
+
+	// a required attestor that is not configured is never satisfied
+	s.attestor.c.RequiredAttestors = []string{"fake1", "fake3"}
+	s.Empty(s.attestor.Attest(ctx, 4))
+}
@@ -2,7 +2,6 @@ package catalog
 
 import (
 	"context"
-	"io"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -42,6 +41,10 @@ type Config struct {
 	TrustDomain  spiffeid.TrustDomain
 	PluginConfig HCLPluginConfigMap
 	Metrics      telemetry.Metrics
+
+	// RequirePluginChecksums, if true, fails to load external plugins that
+	// do not have a checksum configured.
+	RequirePluginChecksums bool
 }
 
 type Repository struct {
@@ -50,8 +53,8 @@ type Repository struct {
 	svidStoreRepository
 	workloadAttestorRepository
 
-	log           logrus.FieldLogger
-	catalogCloser io.Closer
+	log     logrus.FieldLogger
+	plugins *catalog.LoadedPlugins
 }
 
 func (repo *Repository) Plugins() map[string]catalog.PluginRepo {
@@ -69,18 +72,30 @@ func (repo *Repository) Services() []catalog.ServiceRepo {
 
 func (repo *Repository) Close() {
 	repo.log.Debug("Closing catalog")
-	if err := repo.catalogCloser.Close(); err == nil {
+	if err := repo.plugins.Close(); err == nil {
 		repo.log.Info("Catalog closed")
 	} else {
 		repo.log.WithError(err).Error("Failed to close catalog")
 	}
 }
 
+// Reconfigure reconfigures the loaded plugins using the given plugin
+// configuration. Only the plugin data can be changed; see
+// catalog.LoadedPlugins.Reconfigure for details. Plugins that fail to
+// reconfigure keep running with their current configuration.
+func (repo *Repository) Reconfigure(ctx context.Context, pluginConfig HCLPluginConfigMap) error {
+	pluginConfigs, err := catalog.PluginConfigsFromHCL(stripJoinTokenOverride(pluginConfig))
+	if err != nil {
+		return err
+	}
+	return repo.plugins.Reconfigure(ctx, pluginConfigs)
+}
+
 func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	// DEPRECATE: make this an error in SPIRE 1.5
 	if c, ok := config.PluginConfig[nodeAttestorType][jointoken.PluginName]; ok && c.IsEnabled() && c.IsExternal() {
 		config.Log.Warn("The built-in join_token node attestor cannot be overridden by an external plugin. The external plugin will be ignored; this will be a configuration error in a future release.")
-		config.PluginConfig[nodeAttestorType][jointoken.PluginName] = catalog.HCLPluginConfig{}
+		config.PluginConfig = stripJoinTokenOverride(config.PluginConfig)
 	}
 
 	pluginConfigs, err := catalog.PluginConfigsFromHCL(config.PluginConfig)
@@ -92,12 +107,13 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	repo := &Repository{
 		log: config.Log,
 	}
-	repo.catalogCloser, err = catalog.Load(ctx, catalog.Config{
+	repo.plugins, err = catalog.Load(ctx, catalog.Config{
 		Log: config.Log,
 		CoreConfig: catalog.CoreConfig{
 			TrustDomain: config.TrustDomain,
 		},
-		PluginConfigs: pluginConfigs,
+		PluginConfigs:          pluginConfigs,
+		RequirePluginChecksums: config.RequirePluginChecksums,
 		HostServices: []pluginsdk.ServiceServer{
 			metricsv1.MetricsServiceServer(metricsservice.V1(config.Metrics)),
 		},
@@ -111,3 +127,22 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 
 	return repo, nil
 }
+
+// stripJoinTokenOverride returns a copy of the plugin configuration where an
+// external join_token node attestor is replaced by the built-in one.
+func stripJoinTokenOverride(pluginConfig HCLPluginConfigMap) HCLPluginConfigMap {
+	if c, ok := pluginConfig[nodeAttestorType][jointoken.PluginName]; !ok || !c.IsEnabled() || !c.IsExternal() {
+		return pluginConfig
+	}
+	stripped := make(HCLPluginConfigMap, len(pluginConfig))
+	for pluginType, pluginsForType := range pluginConfig {
+		stripped[pluginType] = pluginsForType
+	}
+	nodeAttestors := make(map[string]catalog.HCLPluginConfig, len(pluginConfig[nodeAttestorType]))
+	for name, c := range pluginConfig[nodeAttestorType] {
+		nodeAttestors[name] = c
+	}
+	nodeAttestors[jointoken.PluginName] = catalog.HCLPluginConfig{}
+	stripped[nodeAttestorType] = nodeAttestors
+	return stripped
+}
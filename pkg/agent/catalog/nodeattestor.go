@@ -8,8 +8,10 @@ import (
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/jointoken"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/k8s/psat"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/k8s/sat"
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/oidc"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/sshpop"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/tpmdevid"
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/vsphere"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/x509pop"
 	"github.com/spiffe/spire/pkg/common/catalog"
 )
@@ -40,8 +42,10 @@ func (repo *nodeAttestorRepository) BuiltIns() []catalog.BuiltIn {
 		jointoken.BuiltIn(),
 		psat.BuiltIn(),
 		sat.BuiltIn(),
+		oidc.BuiltIn(),
 		sshpop.BuiltIn(),
 		tpmdevid.BuiltIn(),
+		vsphere.BuiltIn(),
 		x509pop.BuiltIn(),
 	}
 }
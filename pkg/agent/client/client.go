@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,20 +18,39 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/api/rpccontext"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 var (
 	ErrUnableToGetStream = errors.New("unable to get a stream")
+
+	// ErrEntrySyncUnsupported is returned by SyncEntries when the server
+	// does not support streaming the authorized entries
+	ErrEntrySyncUnsupported = errors.New("server does not support entry sync")
 )
 
 const rpcTimeout = 30 * time.Second
 
+// entryOutputMask is the mask of the entry fields used by the agent
+var entryOutputMask = &types.EntryMask{
+	SpiffeId:       true,
+	Selectors:      true,
+	FederatesWith:  true,
+	Admin:          true,
+	Downstream:     true,
+	RevisionNumber: true,
+	StoreSvid:      true,
+}
+
 type X509SVID struct {
 	CertChain []byte
 	ExpiresAt int64
@@ -44,10 +64,21 @@ type JWTSVID struct {
 
 type Client interface {
 	FetchUpdates(ctx context.Context) (*Update, error)
+
+	// SyncEntries streams the authorized entries from the server until the
+	// context is canceled or the stream fails, calling updated each time
+	// they change. While the stream is open, FetchUpdates uses the streamed
+	// entries instead of fetching them.
+	SyncEntries(ctx context.Context, updated func()) error
+
 	RenewSVID(ctx context.Context, csr []byte) (*X509SVID, error)
 	NewX509SVIDs(ctx context.Context, csrs map[string][]byte) (map[string]*X509SVID, error)
 	NewJWTSVID(ctx context.Context, entryID string, audience []string) (*JWTSVID, error)
 
+	// SetAddr changes the address of the server. Requests already in
+	// progress are not affected.
+	SetAddr(addr string)
+
 	// Release releases any resources that were held by this Client, if any.
 	Release()
 }
@@ -63,6 +94,9 @@ type Config struct {
 
 	// RotMtx is used to prevent the creation of new connections during SVID rotations
 	RotMtx *sync.RWMutex
+
+	// GRPCOptions tune the connection to the server
+	GRPCOptions GRPCOptions
 }
 
 type client struct {
@@ -70,11 +104,27 @@ type client struct {
 	connections *nodeConn
 	m           sync.Mutex
 
+	// bundles holds the last bundle received for each trust domain, so the
+	// server can skip sending bundles that have not changed
+	bundles    map[string]cachedBundle
+	bundlesMtx sync.Mutex
+
+	// serverVersion holds the last version reported by the server, used to
+	// log when the agent starts talking to a different server version
+	serverVersion    string
+	serverVersionMtx sync.Mutex
+
+	// synced holds the entries streamed by SyncEntries, or nil when no
+	// stream is open
+	synced    *syncedEntries
+	syncedMtx sync.Mutex
+
 	// Constructor used for testing purposes.
-	createNewEntryClient  func(grpc.ClientConnInterface) entryv1.EntryClient
-	createNewBundleClient func(grpc.ClientConnInterface) bundlev1.BundleClient
-	createNewSVIDClient   func(grpc.ClientConnInterface) svidv1.SVIDClient
-	createNewAgentClient  func(grpc.ClientConnInterface) agentv1.AgentClient
+	createNewEntryClient     func(grpc.ClientConnInterface) entryv1.EntryClient
+	createNewEntrySyncClient func(grpc.ClientConnInterface) entrysync.EntrySyncClient
+	createNewBundleClient    func(grpc.ClientConnInterface) bundlev1.BundleClient
+	createNewSVIDClient      func(grpc.ClientConnInterface) svidv1.SVIDClient
+	createNewAgentClient     func(grpc.ClientConnInterface) agentv1.AgentClient
 
 	// Constructor used for testing purposes.
 	dialContext func(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
@@ -87,11 +137,12 @@ func New(c *Config) Client {
 
 func newClient(c *Config) *client {
 	return &client{
-		c:                     c,
-		createNewEntryClient:  entryv1.NewEntryClient,
-		createNewBundleClient: bundlev1.NewBundleClient,
-		createNewSVIDClient:   svidv1.NewSVIDClient,
-		createNewAgentClient:  agentv1.NewAgentClient,
+		c:                        c,
+		createNewEntryClient:     entryv1.NewEntryClient,
+		createNewEntrySyncClient: entrysync.NewEntrySyncClient,
+		createNewBundleClient:    bundlev1.NewBundleClient,
+		createNewSVIDClient:      svidv1.NewSVIDClient,
+		createNewAgentClient:     agentv1.NewAgentClient,
 	}
 }
 
@@ -102,9 +153,13 @@ func (c *client) FetchUpdates(ctx context.Context) (*Update, error) {
 	c.c.RotMtx.RLock()
 	defer c.c.RotMtx.RUnlock()
 
-	protoEntries, err := c.fetchEntries(ctx)
-	if err != nil {
-		return nil, err
+	protoEntries, renewAgentSVID, ok := c.syncedEntries()
+	if !ok {
+		var err error
+		protoEntries, renewAgentSVID, err = c.fetchEntries(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	regEntries := make(map[string]*common.RegistrationEntry)
@@ -149,11 +204,111 @@ func (c *client) FetchUpdates(ctx context.Context) (*Update, error) {
 	}
 
 	return &Update{
-		Entries: regEntries,
-		Bundles: bundles,
+		Entries:        regEntries,
+		Bundles:        bundles,
+		RenewAgentSVID: renewAgentSVID,
 	}, nil
 }
 
+// syncedEntries is the state of an entry sync stream
+type syncedEntries struct {
+	// conn is the connection the stream was opened on
+	conn           *nodeConn
+	entries        map[string]*types.Entry
+	renewAgentSVID bool
+}
+
+func (c *client) SyncEntries(ctx context.Context, updated func()) error {
+	syncClient, connection, err := c.newEntrySyncClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer connection.Release()
+
+	stream, err := syncClient.SyncAuthorizedEntries(ctx, &entrysync.SyncAuthorizedEntriesRequest{
+		OutputMask: entryOutputMask,
+	})
+	if err != nil {
+		return c.syncEntriesErr(ctx, connection, err)
+	}
+	defer c.setSyncedEntries(nil)
+
+	header, err := stream.Header()
+	if err != nil {
+		return c.syncEntriesErr(ctx, connection, err)
+	}
+	c.observeServerVersion(header)
+
+	entries := make(map[string]*types.Entry)
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return c.syncEntriesErr(ctx, connection, err)
+		}
+
+		// The map is read by FetchUpdates, so it is replaced rather than
+		// modified
+		next := make(map[string]*types.Entry, len(entries)+len(resp.Entries))
+		for id, entry := range entries {
+			next[id] = entry
+		}
+		for _, entry := range resp.Entries {
+			next[entry.Id] = entry
+		}
+		for _, id := range resp.DeletedEntryIds {
+			delete(next, id)
+		}
+		entries = next
+
+		c.setSyncedEntries(&syncedEntries{
+			conn:           connection,
+			entries:        entries,
+			renewAgentSVID: resp.RenewAgentSvid,
+		})
+		updated()
+	}
+}
+
+func (c *client) syncEntriesErr(ctx context.Context, connection *nodeConn, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case status.Code(err) == codes.Unimplemented:
+		return ErrEntrySyncUnsupported
+	}
+	c.release(connection)
+	c.c.Log.WithError(err).Error("Failed to sync authorized entries")
+	return fmt.Errorf("failed to sync authorized entries: %w", err)
+}
+
+func (c *client) setSyncedEntries(synced *syncedEntries) {
+	c.syncedMtx.Lock()
+	defer c.syncedMtx.Unlock()
+	c.synced = synced
+}
+
+// syncedEntries returns the entries streamed by SyncEntries, and whether the
+// server asked the agent to renew its SVID. They are only returned while the
+// stream is open on the current connection, since the stream keeps using the
+// SVID of the connection it was opened on, e.g. after the SVID is rotated.
+func (c *client) syncedEntries() ([]*types.Entry, bool, bool) {
+	c.m.Lock()
+	current := c.connections
+	c.m.Unlock()
+
+	c.syncedMtx.Lock()
+	defer c.syncedMtx.Unlock()
+	if c.synced == nil || c.synced.conn != current {
+		return nil, false, false
+	}
+
+	entries := make([]*types.Entry, 0, len(c.synced.entries))
+	for _, entry := range c.synced.entries {
+		entries = append(entries, entry)
+	}
+	return entries, c.synced.renewAgentSVID, true
+}
+
 func (c *client) RenewSVID(ctx context.Context, csr []byte) (*X509SVID, error) {
 	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
 	defer cancel()
@@ -245,7 +400,11 @@ func (c *client) NewJWTSVID(ctx context.Context, entryID string, audience []stri
 	})
 	if err != nil {
 		c.release(connection)
-		c.c.Log.WithError(err).Error("Failed to fetch JWT SVID")
+		log := c.c.Log.WithError(err)
+		if requestID, ok := rpccontext.RequestID(ctx); ok {
+			log = log.WithField(telemetry.RequestID, requestID)
+		}
+		log.Error("Failed to fetch JWT SVID")
 		return nil, fmt.Errorf("failed to fetch JWT SVID: %w", err)
 	}
 
@@ -268,6 +427,21 @@ func (c *client) NewJWTSVID(ctx context.Context, entryID string, audience []stri
 	}, nil
 }
 
+// SetAddr changes the address of the server. The current connection is
+// released so that the next request dials the new address.
+func (c *client) SetAddr(addr string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if addr == c.c.Addr {
+		return
+	}
+	c.c.Addr = addr
+	if c.connections != nil {
+		c.connections.Release()
+		c.connections = nil
+	}
+}
+
 // Release the underlying connection.
 func (c *client) Release() {
 	c.release(nil)
@@ -300,35 +474,58 @@ func (c *client) dial(ctx context.Context) (*grpc.ClientConn, error) {
 			}
 			return agentCert
 		},
+		GRPCOptions: c.c.GRPCOptions,
 		dialContext: c.dialContext,
 	})
 }
 
-func (c *client) fetchEntries(ctx context.Context) ([]*types.Entry, error) {
+func (c *client) fetchEntries(ctx context.Context) ([]*types.Entry, bool, error) {
 	entryClient, connection, err := c.newEntryClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer connection.Release()
 
+	var header metadata.MD
 	resp, err := entryClient.GetAuthorizedEntries(ctx, &entryv1.GetAuthorizedEntriesRequest{
-		OutputMask: &types.EntryMask{
-			SpiffeId:       true,
-			Selectors:      true,
-			FederatesWith:  true,
-			Admin:          true,
-			Downstream:     true,
-			RevisionNumber: true,
-			StoreSvid:      true,
-		},
-	})
+		OutputMask: entryOutputMask,
+	}, grpc.Header(&header))
 	if err != nil {
 		c.release(connection)
 		c.c.Log.WithError(err).Error("Failed to fetch authorized entries")
-		return nil, fmt.Errorf("failed to fetch authorized entries: %w", err)
+		return nil, false, fmt.Errorf("failed to fetch authorized entries: %w", err)
+	}
+
+	c.observeServerVersion(header)
+
+	renewAgentSVID := len(header.Get(nodeutil.RenewAgentSVIDHeader)) > 0
+	return resp.Entries, renewAgentSVID, err
+}
+
+// observeServerVersion logs the version and capabilities reported by the
+// server when they differ from the ones reported before. Servers that do not
+// report their version predate version negotiation.
+func (c *client) observeServerVersion(header metadata.MD) {
+	serverVersion := "unknown"
+	if values := header.Get(nodeutil.ServerVersionHeader); len(values) > 0 && values[0] != "" {
+		serverVersion = values[0]
 	}
 
-	return resp.Entries, err
+	c.serverVersionMtx.Lock()
+	defer c.serverVersionMtx.Unlock()
+	if serverVersion == c.serverVersion {
+		return
+	}
+	c.serverVersion = serverVersion
+
+	if serverVersion == "unknown" {
+		c.c.Log.Info("SPIRE Server did not report its version; it predates version negotiation")
+		return
+	}
+	c.c.Log.WithFields(logrus.Fields{
+		telemetry.Version:      serverVersion,
+		telemetry.Capabilities: strings.Join(header.Get(nodeutil.CapabilitiesHeader), ","),
+	}).Info("Negotiated version with SPIRE Server")
 }
 
 func (c *client) fetchBundles(ctx context.Context, federatedBundles []string) ([]*types.Bundle, error) {
@@ -341,7 +538,9 @@ func (c *client) fetchBundles(ctx context.Context, federatedBundles []string) ([
 	var bundles []*types.Bundle
 
 	// Get bundle
-	bundle, err := bundleClient.GetBundle(ctx, &bundlev1.GetBundleRequest{})
+	bundle, err := c.getBundle(ctx, c.c.TrustDomain.String(), func(ctx context.Context, opts ...grpc.CallOption) (*types.Bundle, error) {
+		return bundleClient.GetBundle(ctx, &bundlev1.GetBundleRequest{}, opts...)
+	})
 	if err != nil {
 		c.release(connection)
 		c.c.Log.WithError(err).Error("Failed to fetch bundle")
@@ -354,13 +553,16 @@ func (c *client) fetchBundles(ctx context.Context, federatedBundles []string) ([
 		if err != nil {
 			return nil, err
 		}
-		bundle, err := bundleClient.GetFederatedBundle(ctx, &bundlev1.GetFederatedBundleRequest{
-			TrustDomain: federatedTD.String(),
+		bundle, err := c.getBundle(ctx, federatedTD.String(), func(ctx context.Context, opts ...grpc.CallOption) (*types.Bundle, error) {
+			return bundleClient.GetFederatedBundle(ctx, &bundlev1.GetFederatedBundleRequest{
+				TrustDomain: federatedTD.String(),
+			}, opts...)
 		})
 		switch status.Code(err) {
 		case codes.OK:
 			bundles = append(bundles, bundle)
 		case codes.NotFound:
+			c.forgetBundle(federatedTD.String())
 			c.c.Log.WithError(err).WithField(telemetry.FederatedBundle, b).Warn("Federated bundle not found")
 		default:
 			c.c.Log.WithError(err).WithField(telemetry.FederatedBundle, b).Error("Failed to fetch federated bundle")
@@ -371,6 +573,48 @@ func (c *client) fetchBundles(ctx context.Context, federatedBundles []string) ([
 	return bundles, nil
 }
 
+type cachedBundle struct {
+	digest string
+	bundle *types.Bundle
+}
+
+// getBundle fetches the bundle of a trust domain using the given call. The
+// digest of the bundle received last is sent along, and that bundle is
+// returned without being transferred again when the server reports that it
+// has not changed.
+func (c *client) getBundle(ctx context.Context, td string, get func(context.Context, ...grpc.CallOption) (*types.Bundle, error)) (*types.Bundle, error) {
+	c.bundlesMtx.Lock()
+	cached := c.bundles[td]
+	c.bundlesMtx.Unlock()
+
+	var header metadata.MD
+	ctx = metadata.AppendToOutgoingContext(ctx, nodeutil.BundleDigestHeader, cached.digest)
+	bundle, err := get(ctx, grpc.Header(&header))
+	if err != nil {
+		return nil, err
+	}
+
+	if cached.bundle != nil && len(header.Get(nodeutil.BundleUnchangedHeader)) > 0 {
+		return cached.bundle, nil
+	}
+
+	if digests := header.Get(nodeutil.BundleDigestHeader); len(digests) > 0 {
+		c.bundlesMtx.Lock()
+		if c.bundles == nil {
+			c.bundles = make(map[string]cachedBundle)
+		}
+		c.bundles[td] = cachedBundle{digest: digests[0], bundle: bundle}
+		c.bundlesMtx.Unlock()
+	}
+	return bundle, nil
+}
+
+func (c *client) forgetBundle(td string) {
+	c.bundlesMtx.Lock()
+	delete(c.bundles, td)
+	c.bundlesMtx.Unlock()
+}
+
 func (c *client) fetchSVIDs(ctx context.Context, params []*svidv1.NewX509SVIDParams) ([]*types.X509SVID, error) {
 	svidClient, connection, err := c.newSVIDClient(ctx)
 	if err != nil {
@@ -420,6 +664,21 @@ func (c *client) newEntryClient(ctx context.Context) (entryv1.EntryClient, *node
 	return c.createNewEntryClient(c.connections.conn), c.connections, nil
 }
 
+func (c *client) newEntrySyncClient(ctx context.Context) (entrysync.EntrySyncClient, *nodeConn, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.connections == nil {
+		conn, err := c.dial(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.connections = newNodeConn(conn)
+	}
+	c.connections.AddRef()
+	return c.createNewEntrySyncClient(c.connections.conn), c.connections, nil
+}
+
 func (c *client) newBundleClient(ctx context.Context) (bundlev1.BundleClient, *nodeConn, error) {
 	c.m.Lock()
 	defer c.m.Unlock()
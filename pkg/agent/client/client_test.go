@@ -5,11 +5,13 @@ import (
 	"crypto"
 	"crypto/x509"
 	"errors"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
@@ -17,12 +19,18 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/version"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/testing/protocmp"
 )
@@ -190,9 +198,185 @@ func TestFetchUpdates(t *testing.T) {
 		entry := testEntries[0]
 		assert.Equal(t, entry, update.Entries[entry.EntryId])
 	}
+	assert.False(t, update.RenewAgentSVID)
 	assertConnectionIsNotNil(t, client)
 }
 
+func TestFetchUpdatesRenewAgentSVID(t *testing.T) {
+	client, tc := createClient()
+
+	tc.entryClient.header = metadata.Pairs(nodeutil.RenewAgentSVIDHeader, "true")
+	tc.bundleClient.agentBundle = &types.Bundle{
+		TrustDomain:     "example.org",
+		X509Authorities: []*types.X509Certificate{{Asn1: []byte{10, 20, 30, 40}}},
+	}
+
+	update, err := client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.True(t, update.RenewAgentSVID)
+}
+
+func TestFetchUpdatesServerVersion(t *testing.T) {
+	client, tc := createClient()
+	log, logHook := test.NewNullLogger()
+	client.c.Log = log
+	tc.bundleClient.agentBundle = &types.Bundle{TrustDomain: "example.org"}
+
+	// Servers that predate version negotiation are logged once
+	_, err := client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	_, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "SPIRE Server did not report its version; it predates version negotiation",
+		},
+	})
+
+	// A change in the server version is logged once
+	logHook.Reset()
+	tc.entryClient.header = metadata.Pairs(
+		nodeutil.ServerVersionHeader, "1.3.2",
+		nodeutil.CapabilitiesHeader, nodeutil.Capabilities(),
+	)
+	_, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	_, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Negotiated version with SPIRE Server",
+			Data: logrus.Fields{
+				telemetry.Version:      "1.3.2",
+				telemetry.Capabilities: nodeutil.Capabilities(),
+			},
+		},
+	})
+}
+
+func TestSyncEntries(t *testing.T) {
+	client, tc := createClient()
+	tc.bundleClient.agentBundle = &types.Bundle{TrustDomain: "example.org"}
+	tc.entryClient.err = status.Error(codes.Internal, "entries must be streamed")
+
+	entry1 := &types.Entry{
+		Id:        "ENTRYID1",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/id1"},
+		Selectors: []*types.Selector{{Type: "S", Value: "1"}},
+	}
+	entry2 := &types.Entry{
+		Id:        "ENTRYID2",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/id2"},
+		Selectors: []*types.Selector{{Type: "S", Value: "2"}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updated := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- client.SyncEntries(ctx, func() { updated <- struct{}{} })
+	}()
+
+	entryIDs := func(update *Update) []string {
+		var ids []string
+		for id := range update.Entries {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	// The streamed entries are used instead of fetching them
+	tc.entrySyncClient.responses <- &entrysync.SyncAuthorizedEntriesResponse{
+		Entries:        []*types.Entry{entry1, entry2},
+		RenewAgentSvid: true,
+	}
+	<-updated
+	update, err := client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ENTRYID1", "ENTRYID2"}, entryIDs(update))
+	assert.True(t, update.RenewAgentSVID)
+
+	// Deleted entries are removed
+	tc.entrySyncClient.responses <- &entrysync.SyncAuthorizedEntriesResponse{
+		DeletedEntryIds: []string{"ENTRYID2"},
+	}
+	<-updated
+	update, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ENTRYID1"}, entryIDs(update))
+	assert.False(t, update.RenewAgentSVID)
+
+	// The streamed entries are not used once the connection the stream was
+	// opened on is released, e.g. after the agent SVID is rotated
+	client.Release()
+	_, err = client.FetchUpdates(context.Background())
+	require.EqualError(t, err, "failed to fetch authorized entries: rpc error: code = Internal desc = entries must be streamed")
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	_, _, ok := client.syncedEntries()
+	assert.False(t, ok)
+}
+
+func TestSyncEntriesUnsupported(t *testing.T) {
+	client, tc := createClient()
+	tc.entrySyncClient.err = status.Error(codes.Unimplemented, "unknown service")
+
+	err := client.SyncEntries(context.Background(), func() {})
+	require.ErrorIs(t, err, ErrEntrySyncUnsupported)
+	assertConnectionIsNotNil(t, client)
+}
+
+func TestSyncEntriesReleaseConnectionIfItFails(t *testing.T) {
+	client, tc := createClient()
+	tc.entrySyncClient.err = status.Error(codes.Unavailable, "server is gone")
+
+	err := client.SyncEntries(context.Background(), func() {})
+	require.EqualError(t, err, "failed to sync authorized entries: rpc error: code = Unavailable desc = server is gone")
+	assertConnectionIsNil(t, client)
+}
+
+func TestFetchUpdatesUnchangedBundle(t *testing.T) {
+	client, tc := createClient()
+
+	tc.bundleClient.sendDigests = true
+	tc.bundleClient.agentBundle = &types.Bundle{
+		TrustDomain:     "example.org",
+		X509Authorities: []*types.X509Certificate{{Asn1: []byte{10, 20, 30, 40}}},
+	}
+	expectBundles := map[string]*common.Bundle{
+		"spiffe://example.org": {
+			TrustDomainId: "spiffe://example.org",
+			RootCas:       []*common.Certificate{{DerBytes: []byte{10, 20, 30, 40}}},
+		},
+	}
+
+	update, err := client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expectBundles, update.Bundles)
+	require.NotEmpty(t, client.bundles["example.org"].digest)
+
+	// The server only sends the trust domain when the bundle is unchanged,
+	// so the bundle received before is used
+	update, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expectBundles, update.Bundles)
+
+	// The new bundle is used when it changes
+	tc.bundleClient.agentBundle = &types.Bundle{
+		TrustDomain:     "example.org",
+		X509Authorities: []*types.X509Certificate{{Asn1: []byte{50, 60, 70, 80}}},
+	}
+	update, err = client.FetchUpdates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*common.Certificate{{DerBytes: []byte{50, 60, 70, 80}}}, update.Bundles["spiffe://example.org"].RootCas)
+}
+
 func TestRenewSVID(t *testing.T) {
 	client, tc := createClient()
 
@@ -712,12 +896,77 @@ func TestFetchJWTSVID(t *testing.T) {
 }
 
 // createClient creates a sample client with mocked components for testing purposes
+func TestGRPCOptions(t *testing.T) {
+	assert.Empty(t, GRPCOptions{}.DialOptions())
+	assert.Len(t, GRPCOptions{KeepaliveTime: time.Minute}.DialOptions(), 1)
+	assert.Len(t, GRPCOptions{MaxRecvMsgSize: 1024, MaxSendMsgSize: 1024}.DialOptions(), 1)
+
+	client, _ := createClient()
+	client.c.GRPCOptions = GRPCOptions{
+		KeepaliveTime:  time.Minute,
+		MaxRecvMsgSize: 16 << 20,
+	}
+
+	var dialOptions []grpc.DialOption
+	client.dialContext = func(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		dialOptions = opts
+		return grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	conn, err := client.dial(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The default dial options plus the keepalive and call options
+	assert.Len(t, dialOptions, 9)
+}
+
+func TestSetAddr(t *testing.T) {
+	client, _ := createClient()
+	client.c.Addr = "dns:///server-a:8081"
+
+	var dialedAddrs []string
+	client.dialContext = func(ctx context.Context, addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		dialedAddrs = append(dialedAddrs, addr)
+		return grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	_, r, err := client.newAgentClient(context.Background())
+	require.NoError(t, err)
+	r.Release()
+
+	// Setting the same address keeps the current connection
+	client.SetAddr("dns:///server-a:8081")
+	assertConnectionIsNotNil(t, client)
+
+	// A new address releases the connection and the next request dials it
+	client.SetAddr("dns:///server-b:8081")
+	assertConnectionIsNil(t, client)
+
+	_, r, err = client.newAgentClient(context.Background())
+	require.NoError(t, err)
+	r.Release()
+	client.Release()
+
+	assert.Equal(t, []string{"dns:///server-a:8081", "dns:///server-b:8081"}, dialedAddrs)
+}
+
+func TestVersionHeaders(t *testing.T) {
+	md, ok := metadata.FromOutgoingContext(withVersionHeaders(context.Background()))
+	require.True(t, ok)
+	assert.Equal(t, []string{version.Version()}, md.Get(nodeutil.AgentVersionHeader))
+	assert.True(t, nodeutil.HasCapability(md, nodeutil.CapabilityRenewAgentSVID))
+	assert.True(t, nodeutil.HasCapability(md, nodeutil.CapabilityBundleDigest))
+}
+
 func createClient() (*client, *testClient) {
 	tc := &testClient{
 		agentClient:  &fakeAgentClient{},
 		bundleClient: &fakeBundleClient{},
 		entryClient:  &fakeEntryClient{},
-		svidClient:   &fakeSVIDClient{},
+		entrySyncClient: &fakeEntrySyncClient{
+			responses: make(chan *entrysync.SyncAuthorizedEntriesResponse),
+		},
+		svidClient: &fakeSVIDClient{},
 	}
 
 	client := newClient(&Config{
@@ -735,6 +984,9 @@ func createClient() (*client, *testClient) {
 	client.createNewEntryClient = func(conn grpc.ClientConnInterface) entryv1.EntryClient {
 		return tc.entryClient
 	}
+	client.createNewEntrySyncClient = func(conn grpc.ClientConnInterface) entrysync.EntrySyncClient {
+		return tc.entrySyncClient
+	}
 	client.createNewSVIDClient = func(conn grpc.ClientConnInterface) svidv1.SVIDClient {
 		return tc.svidClient
 	}
@@ -765,6 +1017,7 @@ func assertConnectionIsNotNil(t *testing.T, client *client) {
 type fakeEntryClient struct {
 	entryv1.EntryClient
 	entries []*types.Entry
+	header  metadata.MD
 	err     error
 }
 
@@ -784,6 +1037,12 @@ func (c *fakeEntryClient) GetAuthorizedEntries(ctx context.Context, in *entryv1.
 		return nil, status.Error(codes.InvalidArgument, "invalid output mask requested")
 	}
 
+	for _, opt := range opts {
+		if headerOpt, ok := opt.(grpc.HeaderCallOption); ok {
+			*headerOpt.HeaderAddr = c.header
+		}
+	}
+
 	return &entryv1.GetAuthorizedEntriesResponse{
 		Entries: c.entries,
 	}, nil
@@ -797,6 +1056,10 @@ type fakeBundleClient struct {
 	bundleErr          error
 	federatedBundleErr error
 
+	// sendDigests makes GetBundle behave like the server, sending the
+	// bundle digest and skipping the bundle when the caller has it
+	sendDigests bool
+
 	simulateRelease func()
 }
 
@@ -809,6 +1072,27 @@ func (c *fakeBundleClient) GetBundle(ctx context.Context, in *bundlev1.GetBundle
 		go c.simulateRelease()
 	}
 
+	if c.sendDigests {
+		digest, err := nodeutil.BundleDigest(c.agentBundle)
+		if err != nil {
+			return nil, err
+		}
+		header := metadata.Pairs(nodeutil.BundleDigestHeader, digest)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		unchanged := len(md.Get(nodeutil.BundleDigestHeader)) > 0 && md.Get(nodeutil.BundleDigestHeader)[0] == digest
+		if unchanged {
+			header.Set(nodeutil.BundleUnchangedHeader, "true")
+		}
+		for _, opt := range opts {
+			if headerOpt, ok := opt.(grpc.HeaderCallOption); ok {
+				*headerOpt.HeaderAddr = header
+			}
+		}
+		if unchanged {
+			return &types.Bundle{TrustDomain: c.agentBundle.TrustDomain}, nil
+		}
+	}
+
 	return c.agentBundle, nil
 }
 
@@ -898,9 +1182,46 @@ func (c *fakeAgentClient) RenewAgent(ctx context.Context, in *agentv1.RenewAgent
 	}, nil
 }
 
+type fakeEntrySyncClient struct {
+	entrysync.EntrySyncClient
+	header    metadata.MD
+	responses chan *entrysync.SyncAuthorizedEntriesResponse
+	err       error
+}
+
+func (c *fakeEntrySyncClient) SyncAuthorizedEntries(ctx context.Context, in *entrysync.SyncAuthorizedEntriesRequest, opts ...grpc.CallOption) (entrysync.EntrySync_SyncAuthorizedEntriesClient, error) {
+	if diff := cmp.Diff(in.OutputMask, entryOutputMask, protocmp.Transform()); diff != "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid output mask requested")
+	}
+	return &fakeEntrySyncStream{ctx: ctx, client: c}, nil
+}
+
+type fakeEntrySyncStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	client *fakeEntrySyncClient
+}
+
+func (s *fakeEntrySyncStream) Header() (metadata.MD, error) {
+	return s.client.header, nil
+}
+
+func (s *fakeEntrySyncStream) Recv() (*entrysync.SyncAuthorizedEntriesResponse, error) {
+	if s.client.err != nil {
+		return nil, s.client.err
+	}
+	select {
+	case resp := <-s.client.responses:
+		return resp, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
 type testClient struct {
-	agentClient  *fakeAgentClient
-	bundleClient *fakeBundleClient
-	entryClient  *fakeEntryClient
-	svidClient   *fakeSVIDClient
+	agentClient     *fakeAgentClient
+	bundleClient    *fakeBundleClient
+	entryClient     *fakeEntryClient
+	entrySyncClient *fakeEntrySyncClient
+	svidClient      *fakeSVIDClient
 }
@@ -13,10 +13,16 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/version"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -24,6 +30,65 @@ const (
 	roundRobinServiceConfig = `{ "loadBalancingConfig": [ { "round_robin": {} } ] }`
 )
 
+// GRPCOptions tune the gRPC connection to the SPIRE server. Zero values use
+// the gRPC defaults.
+type GRPCOptions struct {
+	// KeepaliveTime is how long the connection can be idle before the agent
+	// pings the server. Keepalive pings are disabled when zero.
+	KeepaliveTime time.Duration
+
+	// KeepaliveTimeout is how long the agent waits for a ping response
+	// before closing the connection.
+	KeepaliveTimeout time.Duration
+
+	// MaxRecvMsgSize is the maximum size, in bytes, of received messages.
+	MaxRecvMsgSize int
+
+	// MaxSendMsgSize is the maximum size, in bytes, of sent messages.
+	MaxSendMsgSize int
+
+	// Compression is the name of the compressor used for the messages
+	// exchanged with the server (e.g. "gzip"). Messages are not compressed
+	// when empty.
+	Compression string
+}
+
+// ValidateCompression returns an error if the compressor is not supported.
+func ValidateCompression(name string) error {
+	switch name {
+	case "", gzip.Name:
+		return nil
+	default:
+		return fmt.Errorf("unsupported compression %q", name)
+	}
+}
+
+// DialOptions returns the gRPC dial options for the options.
+func (o GRPCOptions) DialOptions() []grpc.DialOption {
+	var options []grpc.DialOption
+	if o.KeepaliveTime > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    o.KeepaliveTime,
+			Timeout: o.KeepaliveTimeout,
+		}))
+	}
+
+	var callOptions []grpc.CallOption
+	if o.MaxRecvMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(o.MaxRecvMsgSize))
+	}
+	if o.MaxSendMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(o.MaxSendMsgSize))
+	}
+	if o.Compression != "" {
+		callOptions = append(callOptions, grpc.UseCompressor(o.Compression))
+	}
+	if len(callOptions) > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(callOptions...))
+	}
+	return options
+}
+
 type DialServerConfig struct {
 	// Address is the SPIRE server address
 	Address string
@@ -38,6 +103,14 @@ type DialServerConfig struct {
 	// certificate to present to the server during the TLS handshake.
 	GetAgentCertificate func() *tls.Certificate
 
+	// ClientCertificate is an optional externally issued certificate to
+	// present to the server during the TLS handshake. It is only used when
+	// GetAgentCertificate is not set, i.e., during node attestation.
+	ClientCertificate *tls.Certificate
+
+	// GRPCOptions tune the connection to the server.
+	GRPCOptions GRPCOptions
+
 	// dialContext is an optional constructor for the grpc client connection.
 	dialContext func(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
 }
@@ -53,6 +126,9 @@ func DialServer(ctx context.Context, config DialServerConfig) (*grpc.ClientConn,
 	var tlsConfig *tls.Config
 	if config.GetAgentCertificate == nil {
 		tlsConfig = tlsconfig.TLSClientConfig(bundleSource, authorizer)
+		if config.ClientCertificate != nil {
+			tlsConfig.Certificates = []tls.Certificate{*config.ClientCertificate}
+		}
 	} else {
 		tlsConfig = tlsconfig.MTLSClientConfig(newX509SVIDSource(config.GetAgentCertificate), bundleSource, authorizer)
 	}
@@ -63,13 +139,16 @@ func DialServer(ctx context.Context, config DialServerConfig) (*grpc.ClientConn,
 	if config.dialContext == nil {
 		config.dialContext = grpc.DialContext
 	}
-	client, err := config.dialContext(ctx, config.Address,
+	options := append([]grpc.DialOption{
 		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
 		grpc.FailOnNonTempDialError(true),
 		grpc.WithBlock(),
 		grpc.WithReturnConnectionError(),
 		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
-	)
+		grpc.WithChainUnaryInterceptor(unaryVersionInterceptor, middleware.UnaryClientRequestIDInterceptor),
+		grpc.WithChainStreamInterceptor(streamVersionInterceptor, middleware.StreamClientRequestIDInterceptor),
+	}, config.GRPCOptions.DialOptions()...)
+	client, err := config.dialContext(ctx, config.Address, options...)
 	switch {
 	case err == nil:
 	case errors.Is(err, context.Canceled):
@@ -82,6 +161,25 @@ func DialServer(ctx context.Context, config DialServerConfig) (*grpc.ClientConn,
 	return client, nil
 }
 
+// unaryVersionInterceptor sends the agent version and capabilities to the
+// server on every unary call.
+func unaryVersionInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withVersionHeaders(ctx), method, req, reply, cc, opts...)
+}
+
+// streamVersionInterceptor sends the agent version and capabilities to the
+// server on every streaming call.
+func streamVersionInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withVersionHeaders(ctx), desc, cc, method, opts...)
+}
+
+func withVersionHeaders(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		nodeutil.AgentVersionHeader, version.Version(),
+		nodeutil.CapabilitiesHeader, nodeutil.Capabilities(),
+	)
+}
+
 type bundleSource struct {
 	td     spiffeid.TrustDomain
 	getter func() []*x509.Certificate
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// StaticScheme is the gRPC resolver scheme used to reach one of a static
+	// list of SPIRE server addresses, e.g.
+	// "spire-static:///server1:8081,server2:8081".
+	StaticScheme = "spire-static"
+
+	// SRVScheme is the gRPC resolver scheme used to discover the SPIRE server
+	// addresses with a DNS SRV lookup, e.g.
+	// "spire-srv:///_spire-server._tcp.example.org".
+	SRVScheme = "spire-srv"
+
+	srvLookupTimeout = 10 * time.Second
+)
+
+func init() {
+	resolver.Register(staticBuilder{})
+	resolver.Register(srvBuilder{lookupSRV: net.DefaultResolver.LookupSRV})
+}
+
+// StaticTarget returns the dial target for the given list of server
+// addresses, each in the "host:port" form.
+func StaticTarget(addresses []string) string {
+	return fmt.Sprintf("%s:///%s", StaticScheme, strings.Join(addresses, ","))
+}
+
+// SRVTarget returns the dial target used to discover the server addresses
+// using the given DNS SRV name.
+func SRVTarget(name string) string {
+	return fmt.Sprintf("%s:///%s", SRVScheme, name)
+}
+
+type staticBuilder struct{}
+
+func (staticBuilder) Scheme() string { return StaticScheme }
+
+func (staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	var addrs []resolver.Address
+	for _, addr := range strings.Split(targetEndpoint(target), ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("invalid server address %q: %w", addr, err)
+		}
+		addrs = append(addrs, resolver.Address{Addr: addr})
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no server addresses in target")
+	}
+	if err := cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		return nil, err
+	}
+	return nopResolver{}, nil
+}
+
+type nopResolver struct{}
+
+func (nopResolver) ResolveNow(resolver.ResolveNowOptions) {}
+func (nopResolver) Close()                                {}
+
+type srvBuilder struct {
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+func (srvBuilder) Scheme() string { return SRVScheme }
+
+func (b srvBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := targetEndpoint(target)
+	if name == "" {
+		return nil, errors.New("no SRV name in target")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &srvResolver{
+		name:      name,
+		cc:        cc,
+		lookupSRV: b.lookupSRV,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	r.resolve()
+	return r, nil
+}
+
+// srvResolver resolves the server addresses from DNS SRV records. Records
+// are looked up again every time gRPC asks for a new resolution, which
+// happens when connections to the resolved addresses fail.
+type srvResolver struct {
+	name      string
+	cc        resolver.ClientConn
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx sync.Mutex
+	wg  sync.WaitGroup
+}
+
+func (r *srvResolver) ResolveNow(resolver.ResolveNowOptions) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.resolve()
+	}()
+}
+
+func (r *srvResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *srvResolver) resolve() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.ctx, srvLookupTimeout)
+	defer cancel()
+
+	_, records, err := r.lookupSRV(ctx, "", "", r.name)
+	if err != nil {
+		r.cc.ReportError(fmt.Errorf("failed to look up SRV records for %q: %w", r.name, err))
+		return
+	}
+
+	addrs := make([]resolver.Address, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		addrs = append(addrs, resolver.Address{
+			Addr: net.JoinHostPort(host, strconv.Itoa(int(record.Port))),
+		})
+	}
+	if len(addrs) == 0 {
+		r.cc.ReportError(fmt.Errorf("no SRV records found for %q", r.name))
+		return
+	}
+	if err := r.cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		r.cc.ReportError(err)
+	}
+}
+
+func targetEndpoint(target resolver.Target) string {
+	if target.URL.Opaque != "" {
+		return target.URL.Opaque
+	}
+	return strings.TrimPrefix(target.URL.Path, "/")
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+func TestStaticResolver(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		target        string
+		expectedAddrs []string
+		expectedErr   string
+	}{
+		{
+			name:          "multiple addresses",
+			target:        StaticTarget([]string{"server1:8081", "server2:8082"}),
+			expectedAddrs: []string{"server1:8081", "server2:8082"},
+		},
+		{
+			name:        "address without port",
+			target:      StaticTarget([]string{"server1:8081", "server2"}),
+			expectedErr: `invalid server address "server2": address server2: missing port in address`,
+		},
+		{
+			name:        "no addresses",
+			target:      StaticTarget(nil),
+			expectedErr: "no server addresses in target",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cc := new(fakeClientConn)
+			r, err := staticBuilder{}.Build(parseTarget(t, tt.target), cc, resolver.BuildOptions{})
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer r.Close()
+			assert.Equal(t, tt.expectedAddrs, cc.addrs())
+		})
+	}
+}
+
+func TestSRVResolver(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "server1.example.org.", Port: 8081},
+		{Target: "server2.example.org.", Port: 8082},
+	}
+	var lookupErr error
+	var lookedUp string
+	builder := srvBuilder{
+		lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			lookedUp = name
+			return "", records, lookupErr
+		},
+	}
+
+	cc := new(fakeClientConn)
+	r, err := builder.Build(parseTarget(t, SRVTarget("_spire-server._tcp.example.org")), cc, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, "_spire-server._tcp.example.org", lookedUp)
+	assert.Equal(t, []string{"server1.example.org:8081", "server2.example.org:8082"}, cc.addrs())
+
+	// A failed lookup is reported and the last known addresses are kept
+	lookupErr = errors.New("oh no")
+	r.(*srvResolver).resolve()
+	assert.EqualError(t, cc.err, `failed to look up SRV records for "_spire-server._tcp.example.org": oh no`)
+	assert.Equal(t, []string{"server1.example.org:8081", "server2.example.org:8082"}, cc.addrs())
+
+	// Records are looked up again on resolution
+	lookupErr = nil
+	records = records[1:]
+	r.(*srvResolver).resolve()
+	assert.Equal(t, []string{"server2.example.org:8082"}, cc.addrs())
+}
+
+func parseTarget(t *testing.T, target string) resolver.Target {
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	return resolver.Target{URL: *u}
+}
+
+type fakeClientConn struct {
+	state resolver.State
+	err   error
+}
+
+func (c *fakeClientConn) UpdateState(state resolver.State) error {
+	c.state = state
+	return nil
+}
+
+func (c *fakeClientConn) ReportError(err error) {
+	c.err = err
+}
+
+func (c *fakeClientConn) NewAddress([]resolver.Address) {}
+
+func (c *fakeClientConn) NewServiceConfig(string) {}
+
+func (c *fakeClientConn) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return nil
+}
+
+func (c *fakeClientConn) addrs() []string {
+	var addrs []string
+	for _, addr := range c.state.Addresses {
+		addrs = append(addrs, addr.Addr)
+	}
+	return addrs
+}
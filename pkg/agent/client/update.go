@@ -5,4 +5,8 @@ import "github.com/spiffe/spire/proto/spire/common"
 type Update struct {
 	Entries map[string]*common.RegistrationEntry
 	Bundles map[string]*common.Bundle
+
+	// RenewAgentSVID is set when the server requested the agent to renew
+	// its X509-SVID
+	RenewAgentSVID bool
 }
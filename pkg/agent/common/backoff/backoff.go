@@ -22,12 +22,18 @@ const (
 // to have the same behavioral pattern, though with different bounds based on given
 // interval.
 func NewBackoff(clk clock.Clock, interval time.Duration) BackOff {
+	return NewBackoffWithMaxInterval(clk, interval, _maxIntervalMultiple*interval)
+}
+
+// NewBackoffWithMaxInterval returns a new backoff calculator like NewBackoff,
+// but that backs off up to the given maximum interval.
+func NewBackoffWithMaxInterval(clk clock.Clock, interval, maxInterval time.Duration) BackOff {
 	b := &backoff.ExponentialBackOff{
 		Clock:               clk,
 		InitialInterval:     interval,
 		RandomizationFactor: _jitter,
 		Multiplier:          _backoffMultiplier,
-		MaxInterval:         _maxIntervalMultiple * interval,
+		MaxInterval:         maxInterval,
 		MaxElapsedTime:      _noMaxElapsedTime,
 	}
 	b.Reset()
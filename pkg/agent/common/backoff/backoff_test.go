@@ -30,6 +30,17 @@ func TestBackOff(t *testing.T) {
 	inRange(t, expectedResults[0], b)
 }
 
+func TestBackOffWithMaxInterval(t *testing.T) {
+	mockClk := clock.NewMock(t)
+	b := NewBackoffWithMaxInterval(mockClk, time.Second, 3*time.Second)
+
+	for _, d := range []int{1000, 1500, 2250, 3000, 3000} {
+		expected := time.Duration(d) * time.Millisecond
+		inRange(t, expected, b)
+		mockClk.Add(expected)
+	}
+}
+
 func inRange(t *testing.T, expected time.Duration, b BackOff) {
 	var minInterval = expected - time.Duration(_jitter*float64(expected))
 	var maxInterval = expected + time.Duration(_jitter*float64(expected))
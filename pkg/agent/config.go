@@ -2,12 +2,18 @@ package agent
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"net"
+	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/endpoints"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -17,12 +23,32 @@ type Config struct {
 	// Address to bind the workload api to
 	BindAddress net.Addr
 
+	// BindAddressMode is the file mode applied to the workload api socket.
+	// If zero, the socket is accessible by all users.
+	BindAddressMode os.FileMode
+
+	// BindAddressGroup, if set, is the group (name or ID) that owns the
+	// workload api socket.
+	BindAddressGroup string
+
 	// Directory to store runtime data
 	DataDir string
 
 	// Directory to bind the admin api to
 	AdminBindAddress net.Addr
 
+	// RotationEventsAddress, if set, is the socket that workload X509-SVID
+	// rotation events are streamed on
+	RotationEventsAddress net.Addr
+
+	// RotationEventsMode is the file mode applied to the rotation events
+	// socket. If zero, the socket is accessible by the owner and group.
+	RotationEventsMode os.FileMode
+
+	// RotationEventsGroup, if set, is the group (name or ID) that owns the
+	// rotation events socket.
+	RotationEventsGroup string
+
 	// The Validation Context resource name to use when fetching X.509 bundle together with federated bundles with Envoy SDS
 	DefaultAllBundlesName string
 
@@ -44,17 +70,52 @@ type Config struct {
 	// Configurations for agent plugins
 	PluginConfigs catalog.HCLPluginConfigMap
 
+	// If true, external plugins without a configured checksum fail to load
+	RequirePluginChecksums bool
+
 	Log logrus.FieldLogger
 
 	// LogReopener facilitates handling a signal to rotate log file.
 	LogReopener func(context.Context) error
 
+	// ReloadConfig, if set, is used to re-read the configuration when the
+	// agent is signaled to reload it.
+	ReloadConfig func() (*ReloadableConfig, error)
+
 	// Address of SPIRE server
 	ServerAddress string
 
 	// SyncInterval controls how often the agent sync synchronizer waits
 	SyncInterval time.Duration
 
+	// DegradedMode controls how the agent behaves while it cannot
+	// synchronize with the server
+	DegradedMode manager.DegradedModeConfig
+
+	// LazySVIDs defers signing X509-SVIDs for an entry until a workload
+	// asks for it
+	LazySVIDs bool
+
+	// PrewarmSVIDs persists the workload SVIDs to the data directory and
+	// serves them on startup while they are renewed in the background
+	PrewarmSVIDs bool
+
+	// WorkloadAttestationCacheTTL is how long the selectors of a workload
+	// process are cached. Zero disables caching.
+	WorkloadAttestationCacheTTL time.Duration
+
+	// RequiredWorkloadAttestors are the names of the workload attestor
+	// plugins that must all produce selectors for a workload to be issued
+	// identities
+	RequiredWorkloadAttestors []string
+
+	// WorkloadKeyType is the type of key generated for workload X509-SVIDs
+	WorkloadKeyType keymanager.KeyType
+
+	// WorkloadDNSNames are requested in the CSRs of the workload X509-SVIDs
+	// with the SPIFFE ID they are keyed by
+	WorkloadDNSNames map[spiffeid.ID][]string
+
 	// Trust domain and associated CA bundle
 	TrustDomain spiffeid.TrustDomain
 	TrustBundle []*x509.Certificate
@@ -62,6 +123,14 @@ type Config struct {
 	// Join token to use for attestation, if needed
 	JoinToken string
 
+	// Externally issued certificate presented during the TLS handshake to
+	// attest with the "x509pop_tls" attestation type, if needed
+	X509PoPTLSCertificate *tls.Certificate
+
+	// Agent SVID minted by an administrator and delivered out-of-band, used
+	// instead of performing node attestation, if needed
+	BootstrapSVID *tls.Certificate
+
 	// If true enables profiling.
 	ProfilingEnabled bool
 
@@ -82,7 +151,36 @@ type Config struct {
 	// List of allowed claims response when calling ValidateJWTSVID using a foreign identity
 	AllowedForeignJWTClaims []string
 
+	// AllowedJWTAudiences, if not empty, restricts the audiences that can be
+	// used to validate JWT-SVIDs through the Workload API
+	AllowedJWTAudiences []string
+
+	// AllowedForeignJWTTrustDomains, if not nil, restricts the foreign trust
+	// domains of the JWT-SVIDs that can be validated through the Workload API
+	AllowedForeignJWTTrustDomains []spiffeid.TrustDomain
+
+	// AllowedJWTSVIDClockSkew is the clock skew tolerated when validating JWT-SVIDs
+	AllowedJWTSVIDClockSkew time.Duration
+
 	AuthorizedDelegates []string
+
+	// WorkloadAPICallerPolicy restricts the local processes allowed to
+	// connect to the Workload API
+	WorkloadAPICallerPolicy *endpoints.CallerPolicy
+
+	// AdditionalWorkloadAPISockets are extra sockets the workload api is
+	// served on, each with its own permissions and caller policy
+	AdditionalWorkloadAPISockets []endpoints.Socket
+
+	// WorkloadAPIRateLimits are the rate limits applied to Workload API calls
+	WorkloadAPIRateLimits endpoints.RateLimitConfig
+
+	// WorkloadAPIMaxConcurrentStreams is the maximum number of concurrent
+	// streams per Workload API connection
+	WorkloadAPIMaxConcurrentStreams uint32
+
+	// ServerGRPCOptions tune the gRPC connection to the server
+	ServerGRPCOptions client.GRPCOptions
 }
 
 func New(c *Config) *Agent {
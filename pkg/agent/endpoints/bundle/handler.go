@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/private/agent/bundle"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type Attestor interface {
+	Attest(ctx context.Context) ([]*common.Selector, error)
+}
+
+type Manager interface {
+	SubscribeToCacheChanges(key cache.Selectors) cache.Subscriber
+}
+
+type Config struct {
+	Attestor Attestor
+	Manager  Manager
+}
+
+// Handler implements the Bundle API, which is served to workloads alongside
+// the Workload API
+type Handler struct {
+	bundle.UnsafeBundleServer
+
+	c Config
+}
+
+func New(config Config) *Handler {
+	return &Handler{c: config}
+}
+
+// FetchSPIFFEBundles attests the caller and streams the trust bundles it is
+// entitled to, i.e. the same bundles the Workload API serves to it, in the
+// SPIFFE bundle format.
+func (h *Handler) FetchSPIFFEBundles(req *bundle.FetchSPIFFEBundlesRequest, stream bundle.Bundle_FetchSPIFFEBundlesServer) error {
+	ctx := stream.Context()
+	log := rpccontext.Logger(ctx)
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		log.WithError(err).Error("Workload attestation failed")
+		return status.Error(codes.Internal, "workload attestation failed")
+	}
+
+	subscriber := h.c.Manager.SubscribeToCacheChanges(selectors)
+	defer subscriber.Finish()
+
+	var previousResp *bundle.FetchSPIFFEBundlesResponse
+	for {
+		select {
+		case update := <-subscriber.Updates():
+			if !update.HasIdentity() {
+				log.WithField(telemetry.Registered, false).Error("No identity issued")
+				return status.Error(codes.PermissionDenied, "no identity issued")
+			}
+
+			resp, err := composeSPIFFEBundlesResponse(update)
+			if err != nil {
+				log.WithError(err).Error("Could not serialize SPIFFE bundles response")
+				return status.Errorf(codes.Unavailable, "could not serialize response: %v", err)
+			}
+			if proto.Equal(resp, previousResp) {
+				continue
+			}
+			if err := stream.Send(resp); err != nil {
+				log.WithError(err).Error("Failed to send SPIFFE bundles response")
+				return err
+			}
+			previousResp = resp
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func composeSPIFFEBundlesResponse(update *cache.WorkloadUpdate) (*bundle.FetchSPIFFEBundlesResponse, error) {
+	if update.Bundle == nil {
+		// This should be purely defensive since the cache should always supply
+		// a bundle.
+		return nil, errors.New("bundle not available")
+	}
+
+	bundles := make(map[string][]byte)
+	bundleBytes, err := bundleutil.Marshal(update.Bundle, bundleutil.WithRefreshHintAndSequence())
+	if err != nil {
+		return nil, err
+	}
+	bundles[update.Bundle.TrustDomainID()] = bundleBytes
+
+	for _, federatedBundle := range update.FederatedBundles {
+		bundleBytes, err := bundleutil.Marshal(federatedBundle, bundleutil.WithRefreshHintAndSequence())
+		if err != nil {
+			return nil, err
+		}
+		bundles[federatedBundle.TrustDomainID()] = bundleBytes
+	}
+
+	return &bundle.FetchSPIFFEBundlesResponse{
+		Bundles: bundles,
+	}, nil
+}
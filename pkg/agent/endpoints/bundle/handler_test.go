@@ -0,0 +1,164 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/proto/private/agent/bundle"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	td          = spiffeid.RequireTrustDomainFromString("example.org")
+	federatedTD = spiffeid.RequireTrustDomainFromString("federated.org")
+)
+
+func TestFetchSPIFFEBundles(t *testing.T) {
+	ca := testca.New(t, td)
+	federatedCA := testca.New(t, federatedTD)
+	localBundle := bundleFromCA(t, ca)
+	federatedBundle := bundleFromCA(t, federatedCA)
+	identity := cache.Identity{Entry: &common.RegistrationEntry{SpiffeId: "spiffe://example.org/workload"}}
+
+	for _, tt := range []struct {
+		name       string
+		attestErr  error
+		updates    []*cache.WorkloadUpdate
+		expectCode codes.Code
+		expectMsg  string
+		expectTDs  []spiffeid.TrustDomain
+	}{
+		{
+			name:       "attestation fails",
+			attestErr:  errors.New("ohno"),
+			expectCode: codes.Internal,
+			expectMsg:  "workload attestation failed",
+		},
+		{
+			name:       "no identity issued",
+			updates:    []*cache.WorkloadUpdate{{Bundle: localBundle}},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+		},
+		{
+			name: "bundles of the trust domain and federated trust domains",
+			updates: []*cache.WorkloadUpdate{{
+				Identities:       []cache.Identity{identity},
+				Bundle:           localBundle,
+				FederatedBundles: map[spiffeid.TrustDomain]*cache.Bundle{federatedTD: federatedBundle},
+			}},
+			expectTDs: []spiffeid.TrustDomain{td, federatedTD},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := setupClient(t, &fakeAttestor{err: tt.attestErr}, &fakeManager{updates: tt.updates})
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			stream, err := client.FetchSPIFFEBundles(ctx, &bundle.FetchSPIFFEBundlesRequest{})
+			require.NoError(t, err)
+			resp, err := stream.Recv()
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, resp.Bundles, len(tt.expectTDs))
+			for _, expectTD := range tt.expectTDs {
+				bundleBytes, ok := resp.Bundles[expectTD.IDString()]
+				require.True(t, ok, "missing bundle for %s", expectTD)
+
+				// The bundle is a SPIFFE bundle holding both kinds of authorities
+				spiffeBundle, err := spiffebundle.Parse(expectTD, bundleBytes)
+				require.NoError(t, err)
+				assert.NotEmpty(t, spiffeBundle.X509Authorities())
+				assert.NotEmpty(t, spiffeBundle.JWTAuthorities())
+			}
+		})
+	}
+}
+
+func bundleFromCA(t *testing.T, ca *testca.CA) *cache.Bundle {
+	b, err := bundleutil.BundleFromProto(&common.Bundle{TrustDomainId: ca.Bundle().TrustDomain().IDString()})
+	require.NoError(t, err)
+	for _, rootCA := range ca.X509Authorities() {
+		b.AppendRootCA(rootCA)
+	}
+	for keyID, key := range ca.JWTAuthorities() {
+		require.NoError(t, b.AppendJWTSigningKey(keyID, key))
+	}
+	return b
+}
+
+func setupClient(t *testing.T, attestor *fakeAttestor, manager *fakeManager) bundle.BundleClient {
+	log, _ := test.NewNullLogger()
+
+	handler := New(Config{
+		Attestor: attestor,
+		Manager:  manager,
+	})
+
+	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.WithLogger(log))
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	bundle.RegisterBundleServer(server, handler)
+	addr := spiretest.ServeGRPCServerOnTempUDSSocket(t, server)
+
+	conn, err := grpc.Dial("unix:"+addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return bundle.NewBundleClient(conn)
+}
+
+type fakeAttestor struct {
+	err error
+}
+
+func (a *fakeAttestor) Attest(ctx context.Context) ([]*common.Selector, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return []*common.Selector{{Type: "unix", Value: "uid:1000"}}, nil
+}
+
+type fakeManager struct {
+	updates []*cache.WorkloadUpdate
+}
+
+func (m *fakeManager) SubscribeToCacheChanges(cache.Selectors) cache.Subscriber {
+	ch := make(chan *cache.WorkloadUpdate, len(m.updates))
+	for _, update := range m.updates {
+		ch <- update
+	}
+	return &fakeSubscriber{ch: ch}
+}
+
+type fakeSubscriber struct {
+	ch chan *cache.WorkloadUpdate
+}
+
+func (s *fakeSubscriber) Updates() <-chan *cache.WorkloadUpdate {
+	return s.ch
+}
+
+func (s *fakeSubscriber) Finish() {}
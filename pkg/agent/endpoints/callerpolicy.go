@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spiffe/spire/pkg/agent/common/cgroups"
+	"github.com/spiffe/spire/pkg/common/peertracker"
+)
+
+// CallerPolicy restricts the local processes that are allowed to connect to
+// the Workload API. It is evaluated when a connection is accepted, before the
+// caller is attested.
+type CallerPolicy struct {
+	// AllowedUIDs are the user IDs allowed to connect. If empty, callers are
+	// not restricted by user ID.
+	AllowedUIDs []IDRange
+
+	// AllowedGIDs are the group IDs allowed to connect. If empty, callers are
+	// not restricted by group ID.
+	AllowedGIDs []IDRange
+
+	// AllowedCgroups are path.Match patterns for the cgroup paths of the
+	// callers allowed to connect, i.e. the container contexts they run in.
+	// The caller is allowed if any of its cgroup paths matches. If empty,
+	// callers are not restricted by cgroup. Only supported on Linux.
+	AllowedCgroups []string
+
+	// fs is used to read the cgroups of the caller. Defaults to the OS
+	// filesystem.
+	fs cgroups.FileSystem
+}
+
+// IDRange is an inclusive range of user or group IDs.
+type IDRange struct {
+	Min uint32
+	Max uint32
+}
+
+func (r IDRange) contains(id uint32) bool {
+	return id >= r.Min && id <= r.Max
+}
+
+// ParseIDRanges parses a list of IDs or ID ranges (e.g. "1000" or
+// "1000-1999").
+func ParseIDRanges(values []string) ([]IDRange, error) {
+	var ranges []IDRange
+	for _, value := range values {
+		r, err := parseIDRange(value)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func parseIDRange(value string) (IDRange, error) {
+	minValue, maxValue, isRange := strings.Cut(value, "-")
+	lo, err := strconv.ParseUint(strings.TrimSpace(minValue), 10, 32)
+	if err != nil {
+		return IDRange{}, fmt.Errorf("invalid ID range %q: %w", value, err)
+	}
+	if !isRange {
+		return IDRange{Min: uint32(lo), Max: uint32(lo)}, nil
+	}
+	hi, err := strconv.ParseUint(strings.TrimSpace(maxValue), 10, 32)
+	if err != nil {
+		return IDRange{}, fmt.Errorf("invalid ID range %q: %w", value, err)
+	}
+	if hi < lo {
+		return IDRange{}, fmt.Errorf("invalid ID range %q: upper bound is lower than lower bound", value)
+	}
+	return IDRange{Min: uint32(lo), Max: uint32(hi)}, nil
+}
+
+// Authorize returns an error if the caller is not allowed by the policy.
+func (p *CallerPolicy) Authorize(caller peertracker.CallerInfo) error {
+	if !inRanges(p.AllowedUIDs, caller.UID) {
+		return fmt.Errorf("caller uid %d is not allowed", caller.UID)
+	}
+	if !inRanges(p.AllowedGIDs, caller.GID) {
+		return fmt.Errorf("caller gid %d is not allowed", caller.GID)
+	}
+	if len(p.AllowedCgroups) > 0 {
+		return p.authorizeCgroups(caller.PID)
+	}
+	return nil
+}
+
+// ValidateCgroupPatterns returns an error if any of the patterns is malformed.
+func ValidateCgroupPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (p *CallerPolicy) authorizeCgroups(pid int32) error {
+	fs := p.fs
+	if fs == nil {
+		fs = cgroups.OSFileSystem{}
+	}
+	callerCgroups, err := cgroups.GetCgroups(pid, fs)
+	if err != nil {
+		return fmt.Errorf("unable to read cgroups of caller pid %d: %w", pid, err)
+	}
+	for _, cgroup := range callerCgroups {
+		for _, pattern := range p.AllowedCgroups {
+			if ok, _ := path.Match(pattern, cgroup.GroupPath); ok {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("caller pid %d is not in an allowed cgroup", pid)
+}
+
+func inRanges(ranges []IDRange, id uint32) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if r.contains(id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package endpoints
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/peertracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIDRanges(t *testing.T) {
+	ranges, err := ParseIDRanges([]string{"0", "1000-1999", " 5 - 6 "})
+	require.NoError(t, err)
+	assert.Equal(t, []IDRange{{Min: 0, Max: 0}, {Min: 1000, Max: 1999}, {Min: 5, Max: 6}}, ranges)
+
+	_, err = ParseIDRanges([]string{"root"})
+	assert.EqualError(t, err, `invalid ID range "root": strconv.ParseUint: parsing "root": invalid syntax`)
+
+	_, err = ParseIDRanges([]string{"1000-"})
+	assert.EqualError(t, err, `invalid ID range "1000-": strconv.ParseUint: parsing "": invalid syntax`)
+
+	_, err = ParseIDRanges([]string{"2000-1000"})
+	assert.EqualError(t, err, `invalid ID range "2000-1000": upper bound is lower than lower bound`)
+}
+
+func TestCallerPolicyAuthorize(t *testing.T) {
+	policy := &CallerPolicy{
+		AllowedUIDs: []IDRange{{Min: 0, Max: 0}, {Min: 1000, Max: 1999}},
+		AllowedGIDs: []IDRange{{Min: 100, Max: 100}},
+	}
+
+	assert.NoError(t, policy.Authorize(peertracker.CallerInfo{UID: 0, GID: 100}))
+	assert.NoError(t, policy.Authorize(peertracker.CallerInfo{UID: 1500, GID: 100}))
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{UID: 2000, GID: 100}), "caller uid 2000 is not allowed")
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{UID: 1000, GID: 101}), "caller gid 101 is not allowed")
+
+	// Empty lists do not restrict the caller
+	policy = &CallerPolicy{AllowedGIDs: []IDRange{{Min: 100, Max: 100}}}
+	assert.NoError(t, policy.Authorize(peertracker.CallerInfo{UID: 12345, GID: 100}))
+}
+
+func TestCallerPolicyAuthorizeCgroups(t *testing.T) {
+	policy := &CallerPolicy{
+		AllowedCgroups: []string{"/kubepods/*/pod*/*", "/docker/*"},
+		fs: fakeFileSystem{
+			"/proc/1/cgroup": "12:memory:/kubepods/burstable/pod1234/abcd\n11:cpu:/kubepods/burstable/pod1234/abcd\n",
+			"/proc/2/cgroup": "0::/docker/abcd\n",
+			"/proc/3/cgroup": "0::/system.slice/sshd.service\n",
+			"/proc/4/cgroup": "malformed\n",
+		},
+	}
+
+	assert.NoError(t, policy.Authorize(peertracker.CallerInfo{PID: 1}))
+	assert.NoError(t, policy.Authorize(peertracker.CallerInfo{PID: 2}))
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{PID: 3}), "caller pid 3 is not in an allowed cgroup")
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{PID: 4}), `unable to read cgroups of caller pid 4: cgroup entry contains 1 colons, but expected at least 2 colons: "malformed"`)
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{PID: 5}), "unable to read cgroups of caller pid 5: file does not exist")
+
+	// The cgroup is checked in addition to the uid and gid
+	policy.AllowedUIDs = []IDRange{{Min: 1000, Max: 1000}}
+	assert.EqualError(t, policy.Authorize(peertracker.CallerInfo{PID: 1, UID: 0}), "caller uid 0 is not allowed")
+}
+
+func TestValidateCgroupPatterns(t *testing.T) {
+	assert.NoError(t, ValidateCgroupPatterns([]string{"/docker/*", "/kubepods/*/pod*/*"}))
+	assert.EqualError(t, ValidateCgroupPatterns([]string{"/docker/["}), `invalid cgroup pattern "/docker/[": syntax error in pattern`)
+}
+
+type fakeFileSystem map[string]string
+
+func (fs fakeFileSystem) Open(name string) (io.ReadCloser, error) {
+	data, ok := fs[name]
+	if !ok {
+		return nil, errors.New("file does not exist")
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
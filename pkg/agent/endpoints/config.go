@@ -2,6 +2,8 @@ package endpoints
 
 import (
 	"net"
+	"os"
+	"time"
 
 	discovery_v2 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
@@ -10,21 +12,36 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
+	"github.com/spiffe/spire/pkg/agent/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/agent/manager"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	bundle_pb "github.com/spiffe/spire/proto/private/agent/bundle"
+	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Config struct {
 	BindAddr net.Addr
 
+	// BindAddrMode is the file mode applied to the UDS (Unix only). Defaults
+	// to 0777 when unset.
+	BindAddrMode os.FileMode
+
+	// BindAddrGroup, if set, is the group (name or ID) that owns the UDS
+	// (Unix only).
+	BindAddrGroup string
+
 	Attestor attestor.Attestor
 
 	Manager manager.Manager
 
+	// MaxSyncAge is how old the last synchronization with the server can be
+	// for the agent to pass the readiness check.
+	MaxSyncAge time.Duration
+
 	Log logrus.FieldLogger
 
 	Metrics telemetry.Metrics
@@ -45,12 +62,64 @@ type Config struct {
 
 	AllowedForeignJWTClaims []string
 
+	// AllowedJWTAudiences, if not empty, restricts the audiences that can be
+	// used to validate JWT-SVIDs
+	AllowedJWTAudiences []string
+
+	// AllowedForeignJWTTrustDomains, if not nil, restricts the foreign trust
+	// domains of the JWT-SVIDs that can be validated
+	AllowedForeignJWTTrustDomains []spiffeid.TrustDomain
+
+	// AllowedJWTSVIDClockSkew is the clock skew tolerated when validating
+	// JWT-SVIDs through the Workload API
+	AllowedJWTSVIDClockSkew time.Duration
+
 	TrustDomain spiffeid.TrustDomain
 
+	// CallerPolicy, if set, restricts the local processes that are allowed
+	// to connect to the Workload API (Unix only).
+	CallerPolicy *CallerPolicy
+
+	// RateLimits are the rate limits applied to Workload API calls
+	RateLimits RateLimitConfig
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams per
+	// Workload API connection. The gRPC default is used when zero.
+	MaxConcurrentStreams uint32
+
+	// AdditionalSockets are extra sockets the Workload and SDS APIs are
+	// served on, alongside BindAddr (Unix only).
+	AdditionalSockets []Socket
+
 	// Hooks used by the unit tests to assert that the configuration provided
 	// to each handler is correct and return fake handlers.
 	newWorkloadAPIServer func(workload.Config) workload_pb.SpiffeWorkloadAPIServer
 	newSDSv2Server       func(sdsv2.Config) discovery_v2.SecretDiscoveryServiceServer
 	newSDSv3Server       func(sdsv3.Config) secret_v3.SecretDiscoveryServiceServer
 	newHealthServer      func(healthv1.Config) grpc_health_v1.HealthServer
+	newBundleServer      func(bundle.Config) bundle_pb.BundleServer
+}
+
+// Socket is an additional socket the Workload and SDS APIs are served on.
+// The permissions and caller policy of BindAddr do not apply to it.
+type Socket struct {
+	// Addr is the address of the socket: a UDS, an abstract UDS (Linux
+	// only) whose name starts with '@', or a loopback TCP address.
+	Addr net.Addr
+
+	// Mode is the file mode applied to the UDS. Defaults to 0777 when unset.
+	// It does not apply to abstract UDS.
+	Mode os.FileMode
+
+	// Group, if set, is the group (name or ID) that owns the UDS. It does
+	// not apply to abstract UDS.
+	Group string
+
+	// CallerPolicy, if set, restricts the local processes that are allowed
+	// to connect through the UDS.
+	CallerPolicy *CallerPolicy
+
+	// Selectors are the selectors given to the callers connected through a
+	// TCP socket, which cannot be attested. Required for TCP sockets.
+	Selectors []*common.Selector
 }
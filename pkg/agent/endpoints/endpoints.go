@@ -4,34 +4,62 @@ import (
 	"context"
 	"errors"
 	"net"
+	"os"
+	"sync"
 
 	discovery_v2 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
 	"github.com/sirupsen/logrus"
 	workload_pb "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
+	"github.com/spiffe/spire/pkg/agent/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/common/api/middleware"
-	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	bundle_pb "github.com/spiffe/spire/proto/private/agent/bundle"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Server interface {
 	ListenAndServe(ctx context.Context) error
+
+	// SetBindAddress moves the Workload and SDS APIs to the given address.
+	SetBindAddress(addr net.Addr) error
+}
+
+// addrSetter is implemented by services that need to know the address the
+// Workload API is bound to (e.g. the health service).
+type addrSetter interface {
+	SetAddr(addr net.Addr)
 }
 
 type Endpoints struct {
 	addr              net.Addr
+	addrMode          os.FileMode
+	addrGroup         string
+	callerPolicy      *CallerPolicy
+	rateLimits        RateLimitConfig
+	maxStreams        uint32
+	additionalSockets []Socket
 	log               logrus.FieldLogger
 	metrics           telemetry.Metrics
 	workloadAPIServer workload_pb.SpiffeWorkloadAPIServer
 	sdsv2Server       discovery_v2.SecretDiscoveryServiceServer
 	sdsv3Server       secret_v3.SecretDiscoveryServiceServer
 	healthServer      grpc_health_v1.HealthServer
+	bundleServer      bundle_pb.BundleServer
+
+	// Fields protected by mu, set while serving.
+	mu       sync.Mutex
+	server   *grpc.Server
+	listener net.Listener
+	retired  map[net.Listener]struct{}
+	errChan  chan error
+	serving  sync.WaitGroup
 
 	hooks struct {
 		// test hook used to indicate that is listening
@@ -63,16 +91,46 @@ func New(c Config) *Endpoints {
 		}
 	}
 
+	if c.newBundleServer == nil {
+		c.newBundleServer = func(c bundle.Config) bundle_pb.BundleServer {
+			return bundle.New(c)
+		}
+	}
+
+	if c.BindAddrMode == 0 {
+		// By default, any local process can connect to the Workload API
+		c.BindAddrMode = os.ModePerm
+	}
+
 	allowedClaims := make(map[string]struct{}, len(c.AllowedForeignJWTClaims))
 	for _, claim := range c.AllowedForeignJWTClaims {
 		allowedClaims[claim] = struct{}{}
 	}
 
+	var allowedAudiences map[string]struct{}
+	if len(c.AllowedJWTAudiences) > 0 {
+		allowedAudiences = make(map[string]struct{}, len(c.AllowedJWTAudiences))
+		for _, audience := range c.AllowedJWTAudiences {
+			allowedAudiences[audience] = struct{}{}
+		}
+	}
+
+	var allowedTrustDomains map[spiffeid.TrustDomain]struct{}
+	if c.AllowedForeignJWTTrustDomains != nil {
+		allowedTrustDomains = make(map[spiffeid.TrustDomain]struct{}, len(c.AllowedForeignJWTTrustDomains))
+		for _, td := range c.AllowedForeignJWTTrustDomains {
+			allowedTrustDomains[td] = struct{}{}
+		}
+	}
+
 	workloadAPIServer := c.newWorkloadAPIServer(workload.Config{
 		Manager:                       c.Manager,
 		Attestor:                      attestor,
 		AllowUnauthenticatedVerifiers: c.AllowUnauthenticatedVerifiers,
 		AllowedForeignJWTClaims:       allowedClaims,
+		AllowedJWTAudiences:           allowedAudiences,
+		AllowedForeignJWTTrustDomains: allowedTrustDomains,
+		AllowedJWTSVIDClockSkew:       c.AllowedJWTSVIDClockSkew,
 		TrustDomain:                   c.TrustDomain,
 	})
 
@@ -93,35 +151,54 @@ func New(c Config) *Endpoints {
 	})
 
 	healthServer := c.newHealthServer(healthv1.Config{
-		Addr: c.BindAddr,
+		Addr:       c.BindAddr,
+		LastSync:   c.Manager.GetLastSync,
+		MaxSyncAge: c.MaxSyncAge,
+	})
+
+	bundleServer := c.newBundleServer(bundle.Config{
+		Attestor: attestor,
+		Manager:  c.Manager,
 	})
 
 	return &Endpoints{
 		addr:              c.BindAddr,
+		addrMode:          c.BindAddrMode,
+		addrGroup:         c.BindAddrGroup,
+		callerPolicy:      c.CallerPolicy,
+		rateLimits:        c.RateLimits,
+		maxStreams:        c.MaxConcurrentStreams,
+		additionalSockets: c.AdditionalSockets,
 		log:               c.Log,
 		metrics:           c.Metrics,
 		workloadAPIServer: workloadAPIServer,
 		sdsv2Server:       sdsv2Server,
 		sdsv3Server:       sdsv3Server,
 		healthServer:      healthServer,
+		bundleServer:      bundleServer,
 	}
 }
 
 func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	unaryInterceptor, streamInterceptor := middleware.Interceptors(
-		Middleware(e.log, e.metrics),
+		Middleware(e.log, e.metrics, e.rateLimits),
 	)
 
-	server := grpc.NewServer(
-		grpc.Creds(peertracker.NewCredentials()),
+	options := []grpc.ServerOption{
+		grpc.Creds(newEndpointCredentials()),
 		grpc.UnaryInterceptor(unaryInterceptor),
 		grpc.StreamInterceptor(streamInterceptor),
-	)
+	}
+	if e.maxStreams > 0 {
+		options = append(options, grpc.MaxConcurrentStreams(e.maxStreams))
+	}
+	server := grpc.NewServer(options...)
 
 	workload_pb.RegisterSpiffeWorkloadAPIServer(server, e.workloadAPIServer)
 	discovery_v2.RegisterSecretDiscoveryServiceServer(server, e.sdsv2Server)
 	secret_v3.RegisterSecretDiscoveryServiceServer(server, e.sdsv3Server)
 	grpc_health_v1.RegisterHealthServer(server, e.healthServer)
+	bundle_pb.RegisterBundleServer(server, e.bundleServer)
 
 	l, err := e.createListener()
 	if err != nil {
@@ -129,32 +206,122 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	}
 	defer l.Close()
 
+	listeners := []net.Listener{l}
+	for _, socket := range e.additionalSockets {
+		sl, err := e.createSocketListener(socket)
+		if err != nil {
+			return err
+		}
+		defer sl.Close()
+		listeners = append(listeners, sl)
+	}
+
 	// Update the listening address with the actual address.
 	// If a TCP address was specified with port 0, this will
 	// update the address with the actual port that is used
 	// to listen.
+	e.mu.Lock()
 	e.addr = l.Addr()
-	e.log.WithFields(logrus.Fields{
-		telemetry.Network: e.addr.Network(),
-		telemetry.Address: e.addr,
-	}).Info("Starting Workload and SDS APIs")
+	e.server = server
+	e.listener = l
+	e.retired = make(map[net.Listener]struct{})
+	e.errChan = make(chan error, 1)
+	e.mu.Unlock()
+
+	for _, l := range listeners {
+		e.log.WithFields(logrus.Fields{
+			telemetry.Network: l.Addr().Network(),
+			telemetry.Address: l.Addr(),
+		}).Info("Starting Workload and SDS APIs")
+	}
 	e.triggerListeningHook()
-	errChan := make(chan error)
-	go func() { errChan <- server.Serve(l) }()
+
+	e.mu.Lock()
+	for _, l := range listeners {
+		e.serve(l)
+	}
+	e.mu.Unlock()
 
 	select {
-	case err = <-errChan:
+	case err = <-e.errChan:
+		// Stop serving on the remaining listeners, if any
+		server.Stop()
 	case <-ctx.Done():
 		e.log.Info("Stopping Workload and SDS APIs")
 		server.Stop()
-		err = <-errChan
-		if errors.Is(err, grpc.ErrServerStopped) {
-			err = nil
-		}
 	}
+	e.serving.Wait()
+
+	e.mu.Lock()
+	e.listener.Close()
+	e.server = nil
+	e.mu.Unlock()
 	return err
 }
 
+// SetBindAddress starts serving the Workload and SDS APIs on the given
+// address and then stops listening on the current one. Connections already
+// accepted on the current address are kept open.
+func (e *Endpoints) SetBindAddress(addr net.Addr) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.server == nil {
+		return errors.New("the Workload and SDS APIs are not being served")
+	}
+	if addr.Network() == e.addr.Network() && addr.String() == e.addr.String() {
+		return nil
+	}
+
+	prevAddr := e.addr
+	e.addr = addr
+	l, err := e.createListener()
+	if err != nil {
+		e.addr = prevAddr
+		return err
+	}
+
+	e.retired[e.listener] = struct{}{}
+	e.listener.Close()
+	e.listener = l
+	e.addr = l.Addr()
+	if setter, ok := e.healthServer.(addrSetter); ok {
+		setter.SetAddr(e.addr)
+	}
+	e.serve(l)
+
+	e.log.WithFields(logrus.Fields{
+		telemetry.Network: l.Addr().Network(),
+		telemetry.Address: l.Addr(),
+	}).Info("Moved Workload and SDS APIs to a new address")
+	return nil
+}
+
+// serve serves the APIs on the listener in a new goroutine. The result of
+// serving is reported on errChan unless the listener has been retired by
+// SetBindAddress. Must be called with mu held.
+func (e *Endpoints) serve(l net.Listener) {
+	server, errChan := e.server, e.errChan
+	e.serving.Add(1)
+	go func() {
+		defer e.serving.Done()
+		err := server.Serve(l)
+
+		e.mu.Lock()
+		_, retired := e.retired[l]
+		delete(e.retired, l)
+		e.mu.Unlock()
+		if retired {
+			return
+		}
+
+		select {
+		case errChan <- err:
+		default:
+		}
+	}()
+}
+
 func (e *Endpoints) triggerListeningHook() {
 	if e.hooks.listening != nil {
 		e.hooks.listening <- struct{}{}
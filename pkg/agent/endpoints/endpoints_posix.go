@@ -7,29 +7,48 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 
 	"github.com/spiffe/spire/pkg/common/peertracker"
+	"github.com/spiffe/spire/pkg/common/util"
 )
 
-func (e *Endpoints) createUDSListener() (net.Listener, error) {
+func (e *Endpoints) createUDSListener(socket Socket) (net.Listener, error) {
+	abstract := isAbstractSocket(socket.Addr)
+
 	// Remove uds if already exists
-	os.Remove(e.addr.String())
+	if !abstract {
+		os.Remove(socket.Addr.String())
+	}
 
 	unixListener := &peertracker.ListenerFactory{
 		Log: e.log,
 	}
+	if socket.CallerPolicy != nil {
+		unixListener.AuthorizeCaller = socket.CallerPolicy.Authorize
+	}
 
-	unixAddr, ok := e.addr.(*net.UnixAddr)
+	unixAddr, ok := socket.Addr.(*net.UnixAddr)
 	if !ok {
-		return nil, fmt.Errorf("create UDS listener: address is type %T, not net.UnixAddr", e.addr)
+		return nil, fmt.Errorf("create UDS listener: address is type %T, not net.UnixAddr", socket.Addr)
 	}
-	l, err := unixListener.ListenUnix(e.addr.Network(), unixAddr)
+	l, err := unixListener.ListenUnix(socket.Addr.Network(), unixAddr)
 	if err != nil {
 		return nil, fmt.Errorf("create UDS listener: %w", err)
 	}
 
-	if err := os.Chmod(e.addr.String(), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to change UDS permissions: %w", err)
+	// Abstract sockets are not files, so they have no permissions
+	if abstract {
+		return l, nil
+	}
+
+	mode := socket.Mode
+	if mode == 0 {
+		mode = os.ModePerm
+	}
+	if err := util.SetSocketPermissions(socket.Addr.String(), mode, socket.Group); err != nil {
+		l.Close()
+		return nil, err
 	}
 	return l, nil
 }
@@ -37,10 +56,32 @@ func (e *Endpoints) createUDSListener() (net.Listener, error) {
 func (e *Endpoints) createListener() (net.Listener, error) {
 	switch e.addr.Network() {
 	case "unix":
-		return e.createUDSListener()
+		return e.createUDSListener(Socket{
+			Addr:         e.addr,
+			Mode:         e.addrMode,
+			Group:        e.addrGroup,
+			CallerPolicy: e.callerPolicy,
+		})
 	case "pipe":
 		return nil, peertracker.ErrUnsupportedPlatform
 	default:
 		return nil, net.UnknownNetworkError(e.addr.Network())
 	}
 }
+
+func (e *Endpoints) createSocketListener(socket Socket) (net.Listener, error) {
+	switch socket.Addr.Network() {
+	case "unix":
+		return e.createUDSListener(socket)
+	case "tcp":
+		return e.createTCPListener(socket)
+	default:
+		return nil, net.UnknownNetworkError(socket.Addr.Network())
+	}
+}
+
+// isAbstractSocket returns true if the address is of a UDS in the abstract
+// namespace (Linux only), whose names start with '@' in Go.
+func isAbstractSocket(addr net.Addr) bool {
+	return strings.HasPrefix(addr.String(), "@")
+}
@@ -4,11 +4,28 @@
 package endpoints
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	workload_pb "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 func getTestAddr(t *testing.T) net.Addr {
@@ -17,3 +34,304 @@ func getTestAddr(t *testing.T) net.Addr {
 		Name: filepath.Join(spiretest.TempDir(t), "agent.sock"),
 	}
 }
+
+func TestAdditionalSockets(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	log, _ := test.NewNullLogger()
+	addr := getTestAddr(t)
+	restrictedAddr := getTestAddr(t)
+	deniedAddr := getTestAddr(t)
+
+	// A UID that is not the one of the test process
+	otherUID := uint32(os.Getuid() + 1)
+
+	endpoints := New(Config{
+		BindAddr: addr,
+		Log:      log,
+		Metrics:  fakemetrics.New(),
+		Attestor: FakeAttestor{},
+		Manager:  FakeManager{},
+		AdditionalSockets: []Socket{
+			{
+				Addr: restrictedAddr,
+				Mode: 0770,
+			},
+			{
+				Addr:         deniedAddr,
+				CallerPolicy: &CallerPolicy{AllowedUIDs: []IDRange{{Min: otherUID, Max: otherUID}}},
+			},
+		},
+		newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
+			return FakeWorkloadAPIServer{Attestor: c.Attestor.(PeerTrackerAttestor)}
+		},
+	})
+	endpoints.hooks.listening = make(chan struct{})
+
+	ctx, cancelServe := context.WithCancel(ctx)
+	defer cancelServe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- endpoints.ListenAndServe(ctx)
+	}()
+	defer func() {
+		cancelServe()
+		assert.NoError(t, <-errCh)
+	}()
+	waitForListening(t, endpoints, errCh)
+
+	assertSocketMode(t, addr, os.ModePerm)
+	assertSocketMode(t, restrictedAddr, 0770)
+	assertSocketMode(t, deniedAddr, os.ModePerm)
+
+	fetchJWTSVID := func(addr net.Addr) error {
+		target, err := util.GetTargetName(addr)
+		require.NoError(t, err)
+		conn, err := util.GRPCDialContext(ctx, target)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		callCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("workload.spiffe.io", "true"))
+		_, err = workload_pb.NewSpiffeWorkloadAPIClient(conn).FetchJWTSVID(callCtx, &workload_pb.JWTSVIDRequest{}, grpc.WaitForReady(false))
+		return err
+	}
+
+	assert.NoError(t, fetchJWTSVID(addr))
+	assert.NoError(t, fetchJWTSVID(restrictedAddr))
+	assert.Error(t, fetchJWTSVID(deniedAddr), "caller policy of the socket was not enforced")
+}
+
+func TestAdditionalTCPAndAbstractSockets(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	log, hook := test.NewNullLogger()
+	tcpAddr := getFreeTCPAddr(t)
+	sockets := []Socket{
+		{
+			Addr:      tcpAddr,
+			Selectors: []*common.Selector{{Type: "tcp", Value: "static"}},
+		},
+	}
+	var abstractAddr net.Addr
+	if runtime.GOOS == "linux" {
+		abstractAddr = &net.UnixAddr{Net: "unix", Name: fmt.Sprintf("@spire-agent-test-%d", os.Getpid())}
+		sockets = append(sockets, Socket{Addr: abstractAddr})
+	}
+
+	endpoints := New(Config{
+		BindAddr:          getTestAddr(t),
+		Log:               log,
+		Metrics:           fakemetrics.New(),
+		Attestor:          FakeAttestor{},
+		Manager:           FakeManager{},
+		AdditionalSockets: sockets,
+		newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
+			return selectorsWorkloadAPIServer{Attestor: c.Attestor.(PeerTrackerAttestor)}
+		},
+	})
+	endpoints.hooks.listening = make(chan struct{})
+
+	ctx, cancelServe := context.WithCancel(ctx)
+	defer cancelServe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- endpoints.ListenAndServe(ctx)
+	}()
+	defer func() {
+		cancelServe()
+		assert.NoError(t, <-errCh)
+	}()
+	waitForListening(t, endpoints, errCh)
+
+	spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level: logrus.WarnLevel,
+			Message: "Serving the Workload and SDS APIs over TCP; callers cannot be attested " +
+				"and any local process can obtain the identities granted to the selectors of the socket",
+			Data: logrus.Fields{telemetry.Address: tcpAddr.String()},
+		},
+	})
+
+	fetchSelectors := func(target string) []string {
+		conn, err := util.GRPCDialContext(ctx, target)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		callCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("workload.spiffe.io", "true"))
+		resp, err := workload_pb.NewSpiffeWorkloadAPIClient(conn).FetchJWTSVID(callCtx, &workload_pb.JWTSVIDRequest{})
+		require.NoError(t, err)
+		var selectors []string
+		for _, svid := range resp.Svids {
+			selectors = append(selectors, svid.SpiffeId)
+		}
+		return selectors
+	}
+
+	// Callers connected through TCP are given the selectors of the socket
+	assert.Equal(t, []string{"tcp:static"}, fetchSelectors(tcpAddr.String()))
+
+	// Callers connected through an abstract UDS are attested
+	if abstractAddr != nil {
+		assert.Equal(t, []string{"Type:Value"}, fetchSelectors("unix:"+abstractAddr.String()))
+	}
+}
+
+func TestAdditionalTCPSocketRequiresLoopbackAndSelectors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		socket Socket
+		expErr string
+	}{
+		{
+			name: "not loopback",
+			socket: Socket{
+				Addr:      &net.TCPAddr{IP: net.IPv4zero},
+				Selectors: []*common.Selector{{Type: "tcp", Value: "static"}},
+			},
+			expErr: "create TCP listener: address 0.0.0.0:0 is not a loopback address",
+		},
+		{
+			name:   "no selectors",
+			socket: Socket{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}},
+			expErr: "create TCP listener: selectors are required for address 127.0.0.1:0",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := test.NewNullLogger()
+			endpoints := New(Config{
+				BindAddr:          getTestAddr(t),
+				Log:               log,
+				Metrics:           fakemetrics.New(),
+				Attestor:          FakeAttestor{},
+				Manager:           FakeManager{},
+				AdditionalSockets: []Socket{tt.socket},
+			})
+
+			err := endpoints.ListenAndServe(context.Background())
+			require.EqualError(t, err, tt.expErr)
+		})
+	}
+}
+
+func TestAdditionalSocketsFailure(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	endpoints := New(Config{
+		BindAddr: getTestAddr(t),
+		Log:      log,
+		Metrics:  fakemetrics.New(),
+		Attestor: FakeAttestor{},
+		Manager:  FakeManager{},
+		AdditionalSockets: []Socket{
+			{Addr: &net.UnixAddr{Net: "unix", Name: filepath.Join(spiretest.TempDir(t), "missing", "agent.sock")}},
+		},
+	})
+
+	err := endpoints.ListenAndServe(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "create UDS listener")
+}
+
+func TestSetBindAddress(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	log, _ := test.NewNullLogger()
+	addr := getTestAddr(t)
+	newAddr := getTestAddr(t)
+
+	endpoints := New(Config{
+		BindAddr: addr,
+		Log:      log,
+		Metrics:  fakemetrics.New(),
+		Attestor: FakeAttestor{},
+		Manager:  FakeManager{},
+		newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
+			return FakeWorkloadAPIServer{Attestor: c.Attestor.(PeerTrackerAttestor)}
+		},
+	})
+	endpoints.hooks.listening = make(chan struct{})
+
+	// Moving the APIs fails if they are not being served
+	require.EqualError(t, endpoints.SetBindAddress(newAddr), "the Workload and SDS APIs are not being served")
+
+	ctx, cancelServe := context.WithCancel(ctx)
+	defer cancelServe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- endpoints.ListenAndServe(ctx)
+	}()
+	defer func() {
+		cancelServe()
+		assert.NoError(t, <-errCh)
+	}()
+	waitForListening(t, endpoints, errCh)
+
+	dial := func(addr net.Addr) *grpc.ClientConn {
+		target, err := util.GetTargetName(addr)
+		require.NoError(t, err)
+		conn, err := util.GRPCDialContext(ctx, target, grpc.WithBlock())
+		require.NoError(t, err)
+		return conn
+	}
+	fetchJWTSVID := func(conn *grpc.ClientConn) error {
+		callCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("workload.spiffe.io", "true"))
+		_, err := workload_pb.NewSpiffeWorkloadAPIClient(conn).FetchJWTSVID(callCtx, &workload_pb.JWTSVIDRequest{}, grpc.WaitForReady(false))
+		return err
+	}
+
+	conn := dial(addr)
+	defer conn.Close()
+	require.NoError(t, fetchJWTSVID(conn))
+
+	require.NoError(t, endpoints.SetBindAddress(newAddr))
+
+	// The connection accepted on the previous address is kept open
+	assert.NoError(t, fetchJWTSVID(conn))
+
+	// The APIs are served on the new address only
+	newConn := dial(newAddr)
+	defer newConn.Close()
+	assert.NoError(t, fetchJWTSVID(newConn))
+	_, err := os.Stat(addr.String())
+	assert.True(t, os.IsNotExist(err), "previous socket should have been removed")
+	assertSocketMode(t, newAddr, os.ModePerm)
+}
+
+func assertSocketMode(t *testing.T, addr net.Addr, mode os.FileMode) {
+	info, err := os.Stat(addr.String())
+	require.NoError(t, err)
+	assert.Equal(t, mode, info.Mode().Perm())
+}
+
+func getFreeTCPAddr(t *testing.T) *net.TCPAddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().(*net.TCPAddr)
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// selectorsWorkloadAPIServer returns the selectors of the caller as the
+// SPIFFE IDs of the JWT-SVIDs.
+type selectorsWorkloadAPIServer struct {
+	Attestor PeerTrackerAttestor
+	*workload_pb.UnimplementedSpiffeWorkloadAPIServer
+}
+
+func (s selectorsWorkloadAPIServer) FetchJWTSVID(ctx context.Context, in *workload_pb.JWTSVIDRequest) (*workload_pb.JWTSVIDResponse, error) {
+	selectors, err := s.Attestor.Attest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(workload_pb.JWTSVIDResponse)
+	for _, selector := range selectors {
+		resp.Svids = append(resp.Svids, &workload_pb.JWTSVID{SpiffeId: selector.Type + ":" + selector.Value})
+	}
+	return resp, nil
+}
@@ -12,17 +12,21 @@ import (
 	discovery_v2 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	discovery_v3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	secret_v3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
+	"github.com/gofrs/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	workload_pb "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	healthv1 "github.com/spiffe/spire/pkg/agent/api/health/v1"
 	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
+	"github.com/spiffe/spire/pkg/agent/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv2"
 	"github.com/spiffe/spire/pkg/agent/endpoints/sdsv3"
 	"github.com/spiffe/spire/pkg/agent/endpoints/workload"
 	"github.com/spiffe/spire/pkg/agent/manager"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
+	bundle_pb "github.com/spiffe/spire/proto/private/agent/bundle"
 	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +38,8 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const testRequestID = "test-request-id"
+
 func TestEndpoints(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
@@ -54,10 +60,14 @@ func TestEndpoints(t *testing.T) {
 				spiretest.AssertGRPCStatus(t, err, codes.InvalidArgument, "security header missing from request")
 			},
 			expectedMetrics: []fakemetrics.MetricItem{
+				// In-flight gauge incremented before the connection is tracked
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "in_flight"}, Val: 1},
 				// Global connection counter and then the increment/decrement of the connection gauge
 				{Type: fakemetrics.IncrCounterType, Key: []string{"workload_api", "connection"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 0},
+				// In-flight gauge decremented before the call counter is done
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "in_flight"}, Val: 0},
 				// Call counter
 				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "workload_api", "fetch_jwtsvid"}, Val: 1, Labels: []metrics.Label{
 					{Name: "status", Value: "InvalidArgument"},
@@ -65,6 +75,10 @@ func TestEndpoints(t *testing.T) {
 				{Type: fakemetrics.MeasureSinceWithLabelsType, Key: []string{"rpc", "workload_api", "fetch_jwtsvid", "elapsed_time"}, Val: 0, Labels: []metrics.Label{
 					{Name: "status", Value: "InvalidArgument"},
 				}},
+				// Error counter
+				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "workload_api", "fetch_jwtsvid", "errors"}, Val: 1, Labels: []metrics.Label{
+					{Name: "status", Value: "InvalidArgument"},
+				}},
 			},
 			allowedClaims: []string{"c1"},
 			expectClaims:  map[string]struct{}{"c1": {}},
@@ -80,14 +94,19 @@ func TestEndpoints(t *testing.T) {
 			expectedLogs: []spiretest.LogEntry{
 				logEntryWithPID(logrus.InfoLevel, "Success",
 					"method", "FetchJWTSVID",
+					"caller_request_id", testRequestID,
 					"service", "WorkloadAPI",
 				),
 			},
 			expectedMetrics: []fakemetrics.MetricItem{
+				// In-flight gauge incremented before the connection is tracked
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "in_flight"}, Val: 1},
 				// Global connection counter and then the increment/decrement of the connection gauge
 				{Type: fakemetrics.IncrCounterType, Key: []string{"workload_api", "connection"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 0},
+				// In-flight gauge decremented before the call counter is done
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "in_flight"}, Val: 0},
 				// Call counter
 				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "workload_api", "fetch_jwtsvid"}, Val: 1, Labels: []metrics.Label{
 					{Name: "status", Value: "OK"},
@@ -97,6 +116,38 @@ func TestEndpoints(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name: "bundle api fails without security header",
+			do: func(t *testing.T, conn *grpc.ClientConn) {
+				bundleClient := bundle_pb.NewBundleClient(conn)
+				// Drop the security header added to ctx by previous cases
+				ctx := metadata.NewOutgoingContext(ctx, metadata.MD{})
+				stream, err := bundleClient.FetchSPIFFEBundles(ctx, &bundle_pb.FetchSPIFFEBundlesRequest{})
+				require.NoError(t, err)
+				_, err = stream.Recv()
+				spiretest.AssertGRPCStatus(t, err, codes.InvalidArgument, "security header missing from request")
+			},
+			expectedMetrics: bundleAPIMetrics("InvalidArgument"),
+		},
+		{
+			name: "bundle api has peertracker attestor plumbed",
+			do: func(t *testing.T, conn *grpc.ClientConn) {
+				bundleClient := bundle_pb.NewBundleClient(conn)
+				ctx := metadata.NewOutgoingContext(ctx, metadata.Pairs("workload.spiffe.io", "true"))
+				stream, err := bundleClient.FetchSPIFFEBundles(ctx, &bundle_pb.FetchSPIFFEBundlesRequest{})
+				require.NoError(t, err)
+				_, err = stream.Recv()
+				require.NoError(t, err)
+			},
+			expectedLogs: []spiretest.LogEntry{
+				logEntryWithPID(logrus.InfoLevel, "Success",
+					"method", "FetchSPIFFEBundles",
+					"caller_request_id", testRequestID,
+					"service", "WorkloadAPI.Bundle",
+				),
+			},
+			expectedMetrics: bundleAPIMetrics("OK"),
+		},
 		{
 			name: "sds v2 api has peertracker attestor plumbed",
 			do: func(t *testing.T, conn *grpc.ClientConn) {
@@ -107,14 +158,19 @@ func TestEndpoints(t *testing.T) {
 			expectedLogs: []spiretest.LogEntry{
 				logEntryWithPID(logrus.InfoLevel, "Success",
 					"method", "FetchSecrets",
+					"caller_request_id", testRequestID,
 					"service", "SDS.v2",
 				),
 			},
 			expectedMetrics: []fakemetrics.MetricItem{
+				// In-flight gauge incremented before the connection is tracked
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "sds", "v2", "in_flight"}, Val: 1},
 				// Global connection counter and then the increment/decrement of the connection gauge
 				{Type: fakemetrics.IncrCounterType, Key: []string{"sds_api", "connection"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"sds_api", "connections"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"sds_api", "connections"}, Val: 0},
+				// In-flight gauge decremented before the call counter is done
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "sds", "v2", "in_flight"}, Val: 0},
 				// Call counter
 				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "sds", "v2", "fetch_secrets"}, Val: 1, Labels: []metrics.Label{
 					{Name: "status", Value: "OK"},
@@ -134,14 +190,19 @@ func TestEndpoints(t *testing.T) {
 			expectedLogs: []spiretest.LogEntry{
 				logEntryWithPID(logrus.InfoLevel, "Success",
 					"method", "FetchSecrets",
+					"caller_request_id", testRequestID,
 					"service", "SDS.v3",
 				),
 			},
 			expectedMetrics: []fakemetrics.MetricItem{
+				// In-flight gauge incremented before the connection is tracked
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "sds", "v3", "in_flight"}, Val: 1},
 				// Global connection counter and then the increment/decrement of the connection gauge
 				{Type: fakemetrics.IncrCounterType, Key: []string{"sds_api", "connection"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"sds_api", "connections"}, Val: 1},
 				{Type: fakemetrics.SetGaugeType, Key: []string{"sds_api", "connections"}, Val: 0},
+				// In-flight gauge decremented before the call counter is done
+				{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "sds", "v3", "in_flight"}, Val: 0},
 				// Call counter
 				{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "sds", "v3", "fetch_secrets"}, Val: 1, Labels: []metrics.Label{
 					{Name: "status", Value: "OK"},
@@ -168,12 +229,14 @@ func TestEndpoints(t *testing.T) {
 				DefaultAllBundlesName:       "DefaultAllBundlesName",
 				DisableSPIFFECertValidation: true,
 				AllowedForeignJWTClaims:     tt.allowedClaims,
+				AllowedJWTSVIDClockSkew:     time.Minute,
 
 				// Assert the provided config and return a fake Workload API server
 				newWorkloadAPIServer: func(c workload.Config) workload_pb.SpiffeWorkloadAPIServer {
 					attestor, ok := c.Attestor.(PeerTrackerAttestor)
 					require.True(t, ok, "attestor was not a PeerTrackerAttestor wrapper")
 					assert.Equal(t, FakeManager{}, c.Manager)
+					assert.Equal(t, time.Minute, c.AllowedJWTSVIDClockSkew)
 					if tt.expectClaims != nil {
 						assert.Equal(t, tt.expectClaims, c.AllowedForeignJWTClaims)
 					} else {
@@ -207,9 +270,19 @@ func TestEndpoints(t *testing.T) {
 				// Assert the provided config and return a fake health server
 				newHealthServer: func(c healthv1.Config) grpc_health_v1.HealthServer {
 					assert.Equal(t, addr.String(), c.Addr.String())
+					assert.NotNil(t, c.LastSync)
 					return FakeHealthServer{}
 				},
+
+				// Assert the provided config and return a fake bundle server
+				newBundleServer: func(c bundle.Config) bundle_pb.BundleServer {
+					attestor, ok := c.Attestor.(PeerTrackerAttestor)
+					require.True(t, ok, "attestor was not a PeerTrackerAttestor wrapper")
+					assert.Equal(t, FakeManager{}, c.Manager)
+					return FakeBundleServer{Attestor: attestor}
+				},
 			})
+			assert.Equal(t, os.ModePerm, endpoints.addrMode)
 			endpoints.hooks.listening = make(chan struct{})
 
 			ctx, cancel := context.WithCancel(ctx)
@@ -226,12 +299,22 @@ func TestEndpoints(t *testing.T) {
 			waitForListening(t, endpoints, errCh)
 			target, err := util.GetTargetName(endpoints.addr)
 			require.NoError(t, err)
-			conn, err := util.GRPCDialContext(ctx, target, grpc.WithBlock())
+			conn, err := util.GRPCDialContext(ctx, target, grpc.WithBlock(), grpc.WithUnaryInterceptor(withTestRequestID), grpc.WithStreamInterceptor(withTestRequestIDStream))
 			require.NoError(t, err)
 			defer conn.Close()
 
 			tt.do(t, conn)
 
+			// Request IDs are generated by the endpoints; the one sent by
+			// the client is logged as the caller request ID
+			for _, entry := range hook.AllEntries() {
+				if requestID, ok := entry.Data[telemetry.RequestID]; ok {
+					_, err := uuid.FromString(requestID.(string))
+					assert.NoError(t, err, "expected a generated request ID")
+					delete(entry.Data, telemetry.RequestID)
+				}
+			}
+
 			spiretest.AssertLogs(t, hook.AllEntries(), append([]spiretest.LogEntry{
 				{
 					Level:   logrus.InfoLevel,
@@ -287,6 +370,18 @@ func (s FakeSDSv3Server) FetchSecrets(ctx context.Context, in *discovery_v3.Disc
 	return &discovery_v3.DiscoveryResponse{}, nil
 }
 
+type FakeBundleServer struct {
+	Attestor PeerTrackerAttestor
+	bundle_pb.UnimplementedBundleServer
+}
+
+func (s FakeBundleServer) FetchSPIFFEBundles(req *bundle_pb.FetchSPIFFEBundlesRequest, stream bundle_pb.Bundle_FetchSPIFFEBundlesServer) error {
+	if err := attest(stream.Context(), s.Attestor); err != nil {
+		return err
+	}
+	return stream.Send(&bundle_pb.FetchSPIFFEBundlesResponse{})
+}
+
 type FakeHealthServer struct {
 	*grpc_health_v1.UnimplementedHealthServer
 }
@@ -306,6 +401,36 @@ func attest(ctx context.Context, attestor PeerTrackerAttestor) error {
 	return nil
 }
 
+// withTestRequestID sends a fixed request ID, which the endpoints log as the
+// caller request ID.
+func withTestRequestID(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(metadata.AppendToOutgoingContext(ctx, middleware.RequestIDHeader, testRequestID), method, req, reply, cc, opts...)
+}
+
+func withTestRequestIDStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(metadata.AppendToOutgoingContext(ctx, middleware.RequestIDHeader, testRequestID), desc, cc, method, opts...)
+}
+
+// bundleAPIMetrics returns the metrics emitted for a FetchSPIFFEBundles call
+// that ends with the given status. Connections are counted as Workload API
+// connections.
+func bundleAPIMetrics(code string) []fakemetrics.MetricItem {
+	labels := []metrics.Label{{Name: "status", Value: code}}
+	items := []fakemetrics.MetricItem{
+		{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "bundle", "in_flight"}, Val: 1},
+		{Type: fakemetrics.IncrCounterType, Key: []string{"workload_api", "connection"}, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: []string{"workload_api", "connections"}, Val: 0},
+		{Type: fakemetrics.SetGaugeType, Key: []string{"rpc", "workload_api", "bundle", "in_flight"}, Val: 0},
+		{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "workload_api", "bundle", "fetch_spiffe_bundles"}, Val: 1, Labels: labels},
+		{Type: fakemetrics.MeasureSinceWithLabelsType, Key: []string{"rpc", "workload_api", "bundle", "fetch_spiffe_bundles", "elapsed_time"}, Val: 0, Labels: labels},
+	}
+	if code != "OK" {
+		items = append(items, fakemetrics.MetricItem{Type: fakemetrics.IncrCounterWithLabelsType, Key: []string{"rpc", "workload_api", "bundle", "fetch_spiffe_bundles", "errors"}, Val: 1, Labels: labels})
+	}
+	return items
+}
+
 func logEntryWithPID(level logrus.Level, msg string, keyvalues ...interface{}) spiretest.LogEntry {
 	data := logrus.Fields{
 		telemetry.PID: fmt.Sprint(os.Getpid()),
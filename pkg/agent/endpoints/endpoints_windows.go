@@ -33,3 +33,7 @@ func (e *Endpoints) createListener() (net.Listener, error) {
 		return nil, net.UnknownNetworkError(e.addr.Network())
 	}
 }
+
+func (e *Endpoints) createSocketListener(Socket) (net.Listener, error) {
+	return nil, peertracker.ErrUnsupportedPlatform
+}
@@ -26,7 +26,7 @@ type connectionMetrics struct {
 func (m *connectionMetrics) Preprocess(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
 	if names, ok := rpccontext.Names(ctx); ok {
 		switch names.RawService {
-		case middleware.WorkloadAPIServiceName:
+		case middleware.WorkloadAPIServiceName, middleware.AgentBundleServiceName:
 			workloadAPITelemetry.IncrConnectionCounter(m.metrics)
 			workloadAPITelemetry.SetConnectionTotalGauge(m.metrics, atomic.AddInt32(&m.workloadAPIConns, 1))
 		case middleware.EnvoySDSv2ServiceName, middleware.EnvoySDSv3ServiceName:
@@ -44,7 +44,7 @@ func (m *connectionMetrics) Preprocess(ctx context.Context, fullMethod string, r
 func (m *connectionMetrics) Postprocess(ctx context.Context, fullMethod string, handlerInvoked bool, rpcErr error) {
 	if names, ok := rpccontext.Names(ctx); ok {
 		switch names.RawService {
-		case middleware.WorkloadAPIServiceName:
+		case middleware.WorkloadAPIServiceName, middleware.AgentBundleServiceName:
 			workloadAPITelemetry.SetConnectionTotalGauge(m.metrics, atomic.AddInt32(&m.workloadAPIConns, -1))
 		case middleware.EnvoySDSv2ServiceName, middleware.EnvoySDSv3ServiceName:
 			sdsAPITelemetry.SetSDSAPIConnectionTotalGauge(m.metrics, atomic.AddInt32(&m.sdsAPIConns, -1))
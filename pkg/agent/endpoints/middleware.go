@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/agent/api/rpccontext"
 	"github.com/spiffe/spire/pkg/common/api/middleware"
@@ -16,15 +17,18 @@ import (
 
 const (
 	workloadAPIMethodPrefix = "/SpiffeWorkloadAPI/"
+	bundleAPIMethodPrefix   = "/" + middleware.AgentBundleServiceName + "/"
 )
 
-func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics) middleware.Middleware {
+func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, rateLimits RateLimitConfig) middleware.Middleware {
 	return middleware.Chain(
 		middleware.WithLogger(log),
+		middleware.WithRequestID(),
 		middleware.WithMetrics(metrics),
 		withPerServiceConnectionMetrics(metrics),
 		middleware.Preprocess(addWatcherPID),
 		middleware.Preprocess(verifySecurityHeader),
+		withRateLimits(rateLimits, clock.New()),
 	)
 }
 
@@ -45,8 +49,11 @@ func verifySecurityHeader(ctx context.Context, fullMethod string, req interface{
 	return ctx, nil
 }
 
+// isWorkloadAPIMethod returns true for the methods of the Workload API and of
+// the Bundle API, which is served to workloads alongside it and has the same
+// requirements.
 func isWorkloadAPIMethod(fullMethod string) bool {
-	return strings.HasPrefix(fullMethod, workloadAPIMethodPrefix)
+	return strings.HasPrefix(fullMethod, workloadAPIMethodPrefix) || strings.HasPrefix(fullMethod, bundleAPIMethodPrefix)
 }
 
 func hasSecurityHeader(ctx context.Context) bool {
@@ -15,6 +15,12 @@ type PeerTrackerAttestor struct {
 }
 
 func (a PeerTrackerAttestor) Attest(ctx context.Context) ([]*common.Selector, error) {
+	// Callers connected through a TCP socket cannot be attested; they are
+	// given the selectors of the socket instead.
+	if selectors, ok := staticSelectorsFromContext(ctx); ok {
+		return selectors, nil
+	}
+
 	watcher, ok := peertracker.WatcherFromContext(ctx)
 	if !ok {
 		return nil, status.Error(codes.Internal, "peer tracker watcher missing from context")
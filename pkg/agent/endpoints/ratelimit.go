@@ -0,0 +1,139 @@
+package endpoints
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/peertracker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// callerLimiterGCInterval is the interval at which per-caller limiters
+	// are garbage collected.
+	callerLimiterGCInterval = time.Minute
+)
+
+// RateLimitConfig holds the rate limits applied to Workload API calls.
+type RateLimitConfig struct {
+	// PerCaller limits the calls made by each caller. Callers are identified
+	// by user ID on Linux and by process ID on other platforms.
+	PerCaller RateLimit
+
+	// Global limits the calls made by all callers combined.
+	Global RateLimit
+}
+
+// RateLimit is a token bucket rate limit.
+type RateLimit struct {
+	// Limit is the number of calls allowed per second. Zero disables the
+	// limit.
+	Limit int
+
+	// Burst is the number of calls allowed at once. Defaults to Limit.
+	Burst int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Limit > 0
+}
+
+func (l RateLimit) newLimiter() *rate.Limiter {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = l.Limit
+	}
+	return rate.NewLimiter(rate.Limit(l.Limit), burst)
+}
+
+// withRateLimits returns a middleware that rejects Workload API calls with
+// ResourceExhausted once a caller, or all callers combined, exceed the
+// configured limits. Calls are rejected instead of delayed so a misbehaving
+// workload does not tie up the agent.
+func withRateLimits(c RateLimitConfig, clk clock.Clock) middleware.Middleware {
+	if !c.PerCaller.enabled() && !c.Global.enabled() {
+		return middleware.Chain()
+	}
+
+	var global *rate.Limiter
+	if c.Global.enabled() {
+		global = c.Global.newLimiter()
+	}
+
+	var perCaller *perCallerLimiter
+	if c.PerCaller.enabled() {
+		perCaller = newPerCallerLimiter(c.PerCaller, clk)
+	}
+
+	return middleware.Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+		if !isWorkloadAPIMethod(fullMethod) {
+			return ctx, nil
+		}
+		now := clk.Now()
+		if perCaller != nil {
+			if caller, ok := peertracker.CallerFromContext(ctx); ok && !perCaller.allow(callerLimiterKey(caller), now) {
+				return nil, status.Error(codes.ResourceExhausted, "workload API rate limit exceeded for caller")
+			}
+		}
+		if global != nil && !global.AllowN(now, 1) {
+			return nil, status.Error(codes.ResourceExhausted, "workload API rate limit exceeded")
+		}
+		return ctx, nil
+	})
+}
+
+// perCallerLimiter keeps a limiter per caller, keyed by callerLimiterKey.
+// Limiters for callers that have not made calls since the previous garbage
+// collection are dropped.
+type perCallerLimiter struct {
+	limit RateLimit
+	clk   clock.Clock
+
+	mtx      sync.Mutex
+	previous map[int64]*rate.Limiter
+	current  map[int64]*rate.Limiter
+	lastGC   time.Time
+}
+
+func newPerCallerLimiter(limit RateLimit, clk clock.Clock) *perCallerLimiter {
+	return &perCallerLimiter{
+		limit:   limit,
+		clk:     clk,
+		current: make(map[int64]*rate.Limiter),
+		lastGC:  clk.Now(),
+	}
+}
+
+func (lim *perCallerLimiter) allow(key int64, now time.Time) bool {
+	return lim.getLimiter(key, now).AllowN(now, 1)
+}
+
+func (lim *perCallerLimiter) getLimiter(key int64, now time.Time) *rate.Limiter {
+	lim.mtx.Lock()
+	defer lim.mtx.Unlock()
+
+	if limiter, ok := lim.current[key]; ok {
+		return limiter
+	}
+
+	if limiter, ok := lim.previous[key]; ok {
+		lim.current[key] = limiter
+		delete(lim.previous, key)
+		return limiter
+	}
+
+	if now.Sub(lim.lastGC) >= callerLimiterGCInterval {
+		lim.previous = lim.current
+		lim.current = make(map[int64]*rate.Limiter)
+		lim.lastGC = now
+	}
+
+	limiter := lim.limit.newLimiter()
+	lim.current[key] = limiter
+	return limiter
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package endpoints
+
+import "github.com/spiffe/spire/pkg/common/peertracker"
+
+// callerLimiterKey returns the key of the per-caller limiter of the caller.
+// The peer tracker only reports the user ID of callers on Linux, so callers
+// are limited per process on other platforms.
+func callerLimiterKey(caller peertracker.CallerInfo) int64 {
+	return int64(caller.PID)
+}
@@ -0,0 +1,13 @@
+//go:build linux
+// +build linux
+
+package endpoints
+
+import "github.com/spiffe/spire/pkg/common/peertracker"
+
+// callerLimiterKey returns the key of the per-caller limiter of the caller.
+// Callers are limited per user ID so a workload cannot get a fresh limit by
+// making its calls from new processes.
+func callerLimiterKey(caller peertracker.CallerInfo) int64 {
+	return int64(caller.UID)
+}
@@ -0,0 +1,24 @@
+//go:build linux
+// +build linux
+
+package endpoints
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/test/clock"
+)
+
+func TestRateLimitsPerCallerUID(t *testing.T) {
+	m := withRateLimits(RateLimitConfig{
+		PerCaller: RateLimit{Limit: 1, Burst: 2},
+	}, clock.NewMock(t))
+
+	// Processes of the same user share the limit
+	assertAllowed(t, m, withCaller(1000, 1), fetchJWTSVIDMethod)
+	assertAllowed(t, m, withCaller(1000, 2), fetchJWTSVIDMethod)
+	assertRejected(t, m, withCaller(1000, 3), fetchJWTSVIDMethod, "workload API rate limit exceeded for caller")
+
+	// Other users are not affected
+	assertAllowed(t, m, withCaller(1001, 3), fetchJWTSVIDMethod)
+}
@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/peertracker"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+const fetchJWTSVIDMethod = "/SpiffeWorkloadAPI/FetchJWTSVID"
+
+func TestRateLimitsPerCaller(t *testing.T) {
+	clk := clock.NewMock(t)
+	m := withRateLimits(RateLimitConfig{
+		PerCaller: RateLimit{Limit: 1, Burst: 2},
+	}, clk)
+
+	caller1 := withCaller(1000, 1)
+	caller2 := withCaller(1001, 2)
+
+	// Burst is allowed, then calls are rejected until tokens replenish
+	assertAllowed(t, m, caller1, fetchJWTSVIDMethod)
+	assertAllowed(t, m, caller1, fetchJWTSVIDMethod)
+	assertRejected(t, m, caller1, fetchJWTSVIDMethod, "workload API rate limit exceeded for caller")
+
+	// Other callers are not affected
+	assertAllowed(t, m, caller2, fetchJWTSVIDMethod)
+
+	// Non-Workload API methods are not limited
+	assertAllowed(t, m, caller1, "/grpc.health.v1.Health/Check")
+
+	clk.Add(time.Second)
+	assertAllowed(t, m, caller1, fetchJWTSVIDMethod)
+	assertRejected(t, m, caller1, fetchJWTSVIDMethod, "workload API rate limit exceeded for caller")
+}
+
+func TestRateLimitsGlobal(t *testing.T) {
+	clk := clock.NewMock(t)
+	m := withRateLimits(RateLimitConfig{
+		Global: RateLimit{Limit: 2},
+	}, clk)
+
+	// Burst defaults to the limit and is shared by all callers
+	assertAllowed(t, m, withCaller(1000, 1), fetchJWTSVIDMethod)
+	assertAllowed(t, m, withCaller(1001, 2), fetchJWTSVIDMethod)
+	assertRejected(t, m, withCaller(1002, 3), fetchJWTSVIDMethod, "workload API rate limit exceeded")
+
+	clk.Add(time.Second)
+	assertAllowed(t, m, withCaller(1002, 3), fetchJWTSVIDMethod)
+}
+
+func TestRateLimitsDisabled(t *testing.T) {
+	m := withRateLimits(RateLimitConfig{}, clock.NewMock(t))
+	for i := 0; i < 100; i++ {
+		assertAllowed(t, m, withCaller(1000, 1), fetchJWTSVIDMethod)
+	}
+}
+
+func TestPerCallerLimiterGC(t *testing.T) {
+	clk := clock.NewMock(t)
+	lim := newPerCallerLimiter(RateLimit{Limit: 1}, clk)
+
+	limiter1 := lim.getLimiter(1, clk.Now())
+	require.Same(t, limiter1, lim.getLimiter(1, clk.Now()))
+
+	// After the GC interval, creating a new limiter moves the existing ones
+	// to the previous set. They are kept if used before the next GC.
+	clk.Add(callerLimiterGCInterval)
+	limiter2 := lim.getLimiter(2, clk.Now())
+	require.Same(t, limiter1, lim.getLimiter(1, clk.Now()))
+
+	// Limiters not used since the previous GC are dropped.
+	clk.Add(callerLimiterGCInterval)
+	lim.getLimiter(3, clk.Now())
+	clk.Add(callerLimiterGCInterval)
+	lim.getLimiter(4, clk.Now())
+	require.NotSame(t, limiter2, lim.getLimiter(2, clk.Now()))
+}
+
+func assertAllowed(t *testing.T, m middleware.Middleware, ctx context.Context, method string) {
+	_, err := m.Preprocess(ctx, method, nil)
+	require.NoError(t, err)
+}
+
+func assertRejected(t *testing.T, m middleware.Middleware, ctx context.Context, method, msg string) {
+	_, err := m.Preprocess(ctx, method, nil)
+	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, msg)
+}
+
+func withCaller(uid uint32, pid int32) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: peertracker.AuthInfo{
+			Caller: peertracker.CallerInfo{UID: uid, PID: pid},
+		},
+	})
+}
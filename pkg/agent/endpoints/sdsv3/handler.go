@@ -41,6 +41,7 @@ type Attestor interface {
 type Manager interface {
 	SubscribeToCacheChanges(key cache.Selectors) cache.Subscriber
 	FetchWorkloadUpdate(selectors []*common.Selector) *cache.WorkloadUpdate
+	RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error)
 }
 
 type Config struct {
@@ -157,6 +158,11 @@ func (h *Handler) StreamSecrets(stream secret_v3.SecretDiscoveryService_StreamSe
 			}
 
 		case upd = <-updch:
+			upd, err = h.c.Manager.RenderDNSNameTemplates(stream.Context(), selectors, upd)
+			if err != nil {
+				log.WithError(err).Error("Failed to render DNS name templates")
+				return status.Errorf(codes.Unavailable, "failed to render DNS name templates: %v", err)
+			}
 			versionCounter++
 			versionInfo = strconv.FormatInt(versionCounter, 10)
 			if lastReq == nil {
@@ -218,7 +224,11 @@ func (h *Handler) FetchSecrets(ctx context.Context, req *discovery_v3.DiscoveryR
 		return nil, err
 	}
 
-	upd := h.c.Manager.FetchWorkloadUpdate(selectors)
+	upd, err := h.c.Manager.RenderDNSNameTemplates(ctx, selectors, h.c.Manager.FetchWorkloadUpdate(selectors))
+	if err != nil {
+		log.WithError(err).Error("Failed to render DNS name templates")
+		return nil, status.Errorf(codes.Unavailable, "failed to render DNS name templates: %v", err)
+	}
 
 	resp, err := h.buildResponse("", req, upd)
 	if err != nil {
@@ -1313,6 +1313,10 @@ func (m *FakeManager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.W
 	return m.upd
 }
 
+func (m *FakeManager) RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error) {
+	return update, nil
+}
+
 func (m *FakeManager) SetWorkloadUpdate(upd *cache.WorkloadUpdate) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,113 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/spiffe/spire/pkg/common/peertracker"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const staticSelectorsAuthType = "spire-static-selectors"
+
+// createTCPListener listens on a loopback TCP address. The processes
+// connecting through TCP cannot be attested, so they are all given the
+// selectors of the socket.
+func (e *Endpoints) createTCPListener(socket Socket) (net.Listener, error) {
+	tcpAddr, ok := socket.Addr.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("create TCP listener: address is type %T, not net.TCPAddr", socket.Addr)
+	}
+	if !tcpAddr.IP.IsLoopback() {
+		return nil, fmt.Errorf("create TCP listener: address %s is not a loopback address", tcpAddr)
+	}
+	if len(socket.Selectors) == 0 {
+		return nil, fmt.Errorf("create TCP listener: selectors are required for address %s", tcpAddr)
+	}
+
+	l, err := net.ListenTCP("tcp", tcpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("create TCP listener: %w", err)
+	}
+
+	e.log.WithField(telemetry.Address, l.Addr()).Warn("Serving the Workload and SDS APIs over TCP; callers cannot be attested " +
+		"and any local process can obtain the identities granted to the selectors of the socket")
+
+	return &staticSelectorsListener{
+		Listener:  l,
+		selectors: socket.Selectors,
+	}, nil
+}
+
+// staticSelectorsListener gives the connections it accepts the selectors of
+// the socket.
+type staticSelectorsListener struct {
+	net.Listener
+	selectors []*common.Selector
+}
+
+func (l *staticSelectorsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &staticSelectorsConn{
+		Conn: conn,
+		info: staticSelectorsAuthInfo{selectors: l.selectors},
+	}, nil
+}
+
+type staticSelectorsConn struct {
+	net.Conn
+	info staticSelectorsAuthInfo
+}
+
+type staticSelectorsAuthInfo struct {
+	selectors []*common.Selector
+}
+
+func (staticSelectorsAuthInfo) AuthType() string {
+	return staticSelectorsAuthType
+}
+
+func staticSelectorsFromContext(ctx context.Context) ([]*common.Selector, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(staticSelectorsAuthInfo)
+	if !ok {
+		return nil, false
+	}
+	return info.selectors, true
+}
+
+// endpointCredentials authenticates the callers through the peertracker,
+// except for the ones connected through a TCP socket, which are given the
+// selectors of the socket.
+type endpointCredentials struct {
+	credentials.TransportCredentials
+}
+
+func newEndpointCredentials() credentials.TransportCredentials {
+	return endpointCredentials{
+		TransportCredentials: peertracker.NewCredentials(),
+	}
+}
+
+func (c endpointCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if staticConn, ok := conn.(*staticSelectorsConn); ok {
+		return staticConn, staticConn.info, nil
+	}
+	return c.TransportCredentials.ServerHandshake(conn)
+}
+
+func (c endpointCredentials) Clone() credentials.TransportCredentials {
+	return endpointCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+	}
+}
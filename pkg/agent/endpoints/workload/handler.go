@@ -34,6 +34,7 @@ type Manager interface {
 	MatchingIdentities([]*common.Selector) []cache.Identity
 	FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, error)
 	FetchWorkloadUpdate([]*common.Selector) *cache.WorkloadUpdate
+	RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error)
 }
 
 type Attestor interface {
@@ -47,6 +48,18 @@ type Config struct {
 	AllowUnauthenticatedVerifiers bool
 	AllowedForeignJWTClaims       map[string]struct{}
 	TrustDomain                   spiffeid.TrustDomain
+
+	// AllowedJWTAudiences, if not nil, are the only audiences that can be
+	// used to validate JWT-SVIDs
+	AllowedJWTAudiences map[string]struct{}
+
+	// AllowedForeignJWTTrustDomains, if not nil, are the only foreign trust
+	// domains whose JWT-SVIDs can be validated
+	AllowedForeignJWTTrustDomains map[spiffeid.TrustDomain]struct{}
+
+	// AllowedJWTSVIDClockSkew is the clock skew tolerated when validating
+	// the time based claims of JWT-SVIDs
+	AllowedJWTSVIDClockSkew time.Duration
 }
 
 type Handler struct {
@@ -168,6 +181,13 @@ func (h *Handler) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWT
 
 	log = log.WithField(telemetry.Audience, req.Audience)
 
+	if h.c.AllowedJWTAudiences != nil {
+		if _, ok := h.c.AllowedJWTAudiences[req.Audience]; !ok {
+			log.Warn("Audience is not allowed for JWT-SVID validation")
+			return nil, status.Errorf(codes.PermissionDenied, "audience %q is not allowed", req.Audience)
+		}
+	}
+
 	selectors, err := h.c.Attestor.Attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
@@ -180,12 +200,20 @@ func (h *Handler) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWT
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	id, claims, err := jwtsvid.ValidateToken(ctx, req.Svid, keyStore, []string{req.Audience})
+	id, claims, err := jwtsvid.ValidateTokenWithClockSkew(ctx, req.Svid, keyStore, []string{req.Audience}, h.c.AllowedJWTSVIDClockSkew)
 	if err != nil {
 		log.WithError(err).Warn("Failed to validate JWT")
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	log.WithField(telemetry.SPIFFEID, id).Debug("Successfully validated JWT")
+	log = log.WithField(telemetry.SPIFFEID, id)
+
+	if !id.MemberOf(h.c.TrustDomain) && h.c.AllowedForeignJWTTrustDomains != nil {
+		if _, ok := h.c.AllowedForeignJWTTrustDomains[id.TrustDomain()]; !ok {
+			log.Warn("Trust domain is not allowed for JWT-SVID validation")
+			return nil, status.Errorf(codes.PermissionDenied, "JWT-SVIDs from trust domain %q are not allowed", id.TrustDomain())
+		}
+	}
+	log.Debug("Successfully validated JWT")
 
 	if !id.MemberOf(h.c.TrustDomain) {
 		for claim := range claims {
@@ -230,6 +258,11 @@ func (h *Handler) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.Spi
 	for {
 		select {
 		case update := <-subscriber.Updates():
+			update, err = h.c.Manager.RenderDNSNameTemplates(ctx, selectors, update)
+			if err != nil {
+				log.WithError(err).Error("Failed to render DNS name templates")
+				return status.Errorf(codes.Unavailable, "failed to render DNS name templates: %v", err)
+			}
 			if err := sendX509SVIDResponse(update, stream, log, quietLogging); err != nil {
 				return err
 			}
@@ -414,7 +447,7 @@ func composeJWTBundlesResponse(update *cache.WorkloadUpdate) (*workload.JWTBundl
 	}
 
 	bundles := make(map[string][]byte)
-	jwksBytes, err := bundleutil.Marshal(update.Bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+	jwksBytes, err := bundleutil.Marshal(update.Bundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS(), bundleutil.WithRefreshHintAndSequence())
 	if err != nil {
 		return nil, err
 	}
@@ -422,7 +455,7 @@ func composeJWTBundlesResponse(update *cache.WorkloadUpdate) (*workload.JWTBundl
 
 	if update.HasIdentity() {
 		for _, federatedBundle := range update.FederatedBundles {
-			jwksBytes, err := bundleutil.Marshal(federatedBundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS())
+			jwksBytes, err := bundleutil.Marshal(federatedBundle, bundleutil.NoX509SVIDKeys(), bundleutil.StandardJWKS(), bundleutil.WithRefreshHintAndSequence())
 			if err != nil {
 				return nil, err
 			}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/bundle/jwtbundle"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	workloadPB "github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -55,6 +56,8 @@ func TestFetchX509SVID(t *testing.T) {
 	for _, tt := range []struct {
 		name       string
 		updates    []*cache.WorkloadUpdate
+		rendered   *cache.WorkloadUpdate
+		renderErr  error
 		attestErr  error
 		asPID      int
 		expectCode codes.Code
@@ -158,12 +161,68 @@ func TestFetchX509SVID(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with rendered DNS name templates",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID1),
+					},
+					Bundle: utilBundleFromBundle(t, bundle),
+				},
+			},
+			rendered: &cache.WorkloadUpdate{
+				Identities: []cache.Identity{
+					identityFromX509SVID(x509SVID2),
+				},
+				Bundle: utilBundleFromBundle(t, bundle),
+			},
+			expectCode: codes.OK,
+			expectResp: &workloadPB.X509SVIDResponse{
+				Svids: []*workloadPB.X509SVID{
+					{
+						SpiffeId:    x509SVID2.ID.String(),
+						X509Svid:    x509util.DERFromCertificates(x509SVID2.Certificates),
+						X509SvidKey: pkcs8FromSigner(t, x509SVID2.PrivateKey),
+						Bundle:      x509util.DERFromCertificates(bundle.X509Authorities()),
+					},
+				},
+				FederatedBundles: map[string][]byte{},
+			},
+		},
+		{
+			name: "fails to render DNS name templates",
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identityFromX509SVID(x509SVID1),
+					},
+					Bundle: utilBundleFromBundle(t, bundle),
+				},
+			},
+			renderErr:  errors.New("ohno"),
+			expectCode: codes.Unavailable,
+			expectMsg:  "failed to render DNS name templates: ohno",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to render DNS name templates",
+					Data: logrus.Fields{
+						"service":       "WorkloadAPI",
+						"method":        "FetchX509SVID",
+						logrus.ErrorKey: "ohno",
+					},
+				},
+			},
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			params := testParams{
 				CA:         ca,
 				Updates:    tt.updates,
+				Rendered:   tt.rendered,
+				RenderErr:  tt.renderErr,
 				AttestErr:  tt.attestErr,
 				ExpectLogs: tt.expectLogs,
 				AsPID:      tt.asPID,
@@ -864,6 +923,87 @@ func TestFetchJWTBundles_MultipleUpdates(t *testing.T) {
 		})
 }
 
+func TestFetchJWTBundles_KeyRotation(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("domain.test")
+	ca := testca.New(t, td)
+
+	x509SVID := ca.CreateX509SVID(workloadID)
+
+	rotate := func(bundle *spiffebundle.Bundle, keyID string) *spiffebundle.Bundle {
+		rotated := bundle.Clone()
+		require.NoError(t, rotated.AddJWTAuthority(keyID, x509SVID.PrivateKey.Public()))
+		return rotated
+	}
+
+	bundle := ca.Bundle()
+	federatedBundle := spiffebundle.New(spiffeid.RequireTrustDomainFromString("domain2.test"))
+	federatedBundle.AddX509Authority(ca.X509Authorities()[0])
+	require.NoError(t, federatedBundle.AddJWTAuthority("federated", x509SVID.PrivateKey.Public()))
+
+	// The local JWT signing key is rotated first, then the federated one
+	rotatedBundle := rotate(bundle, "rotated-local")
+	rotatedFederatedBundle := rotate(federatedBundle, "rotated-federated")
+
+	update := func(bundle, federatedBundle *spiffebundle.Bundle) *cache.WorkloadUpdate {
+		return &cache.WorkloadUpdate{
+			Identities: []cache.Identity{
+				identityFromX509SVID(x509SVID),
+			},
+			Bundle: utilBundleFromBundle(t, bundle),
+			FederatedBundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
+				federatedBundle.TrustDomain(): utilBundleFromBundle(t, federatedBundle),
+			},
+		}
+	}
+
+	keyIDs := func(bundles ...*spiffebundle.Bundle) map[string][]string {
+		out := make(map[string][]string)
+		for _, bundle := range bundles {
+			for keyID := range bundle.JWTAuthorities() {
+				out[bundle.TrustDomain().IDString()] = append(out[bundle.TrustDomain().IDString()], keyID)
+			}
+		}
+		return out
+	}
+
+	params := testParams{
+		CA: ca,
+		Updates: []*cache.WorkloadUpdate{
+			update(bundle, federatedBundle),
+			update(rotatedBundle, federatedBundle),
+			update(rotatedBundle, rotatedFederatedBundle),
+		},
+	}
+
+	runTest(t, params,
+		func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient) {
+			stream, err := client.FetchJWTBundles(ctx, &workloadPB.JWTBundlesRequest{})
+			require.NoError(t, err)
+
+			for _, expected := range []map[string][]string{
+				keyIDs(bundle, federatedBundle),
+				keyIDs(rotatedBundle, federatedBundle),
+				keyIDs(rotatedBundle, rotatedFederatedBundle),
+			} {
+				resp, err := stream.Recv()
+				spiretest.RequireGRPCStatus(t, err, codes.OK, "")
+
+				actual := make(map[string][]string)
+				for tdID, jwks := range resp.Bundles {
+					jwtBundle, err := jwtbundle.Parse(spiffeid.RequireTrustDomainFromString(tdID), jwks)
+					require.NoError(t, err)
+					for keyID := range jwtBundle.JWTAuthorities() {
+						actual[tdID] = append(actual[tdID], keyID)
+					}
+				}
+				require.Len(t, actual, len(expected))
+				for tdID, expectedKeyIDs := range expected {
+					require.ElementsMatch(t, expectedKeyIDs, actual[tdID], "unexpected JWT keys for %s", tdID)
+				}
+			}
+		})
+}
+
 func TestFetchJWTBundles_SpuriousUpdates(t *testing.T) {
 	td := spiffeid.RequireTrustDomainFromString("domain.test")
 	ca := testca.New(t, td)
@@ -967,17 +1107,91 @@ func TestValidateJWTSVID(t *testing.T) {
 		},
 	}}
 
+	successResponse := &workloadPB.ValidateJWTSVIDResponse{
+		SpiffeId: "spiffe://domain.test/workload",
+		Claims: &structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				"aud": {
+					Kind: &structpb.Value_ListValue{
+						ListValue: &structpb.ListValue{
+							Values: []*structpb.Value{
+								{
+									Kind: &structpb.Value_StringValue{
+										StringValue: "AUDIENCE",
+									},
+								},
+							},
+						},
+					},
+				},
+				"exp": {
+					Kind: &structpb.Value_NumberValue{
+						NumberValue: svid.Claims["exp"].(float64),
+					},
+				},
+				"iat": {
+					Kind: &structpb.Value_NumberValue{
+						NumberValue: svid.Claims["iat"].(float64),
+					},
+				},
+				"iss": {
+					Kind: &structpb.Value_StringValue{
+						StringValue: "FAKECA",
+					},
+				},
+				"sub": {
+					Kind: &structpb.Value_StringValue{
+						StringValue: "spiffe://domain.test/workload",
+					},
+				},
+			},
+		},
+	}
+
+	federatedResponse := &workloadPB.ValidateJWTSVIDResponse{
+		SpiffeId: "spiffe://domain2.test/federated-workload",
+		Claims: &structpb.Struct{
+			Fields: map[string]*structpb.Value{
+				"aud": {
+					Kind: &structpb.Value_ListValue{
+						ListValue: &structpb.ListValue{
+							Values: []*structpb.Value{
+								{
+									Kind: &structpb.Value_StringValue{
+										StringValue: "AUDIENCE",
+									},
+								},
+							},
+						},
+					},
+				},
+				"exp": {
+					Kind: &structpb.Value_NumberValue{
+						NumberValue: federatedSVID.Claims["exp"].(float64),
+					},
+				},
+				"sub": {
+					Kind: &structpb.Value_StringValue{
+						StringValue: "spiffe://domain2.test/federated-workload",
+					},
+				},
+			},
+		},
+	}
+
 	for _, tt := range []struct {
-		name                    string
-		svid                    string
-		audience                string
-		updates                 []*cache.WorkloadUpdate
-		attestErr               error
-		expectCode              codes.Code
-		expectMsg               string
-		expectLogs              []spiretest.LogEntry
-		expectResponse          *workloadPB.ValidateJWTSVIDResponse
-		allowedForeignJWTClaims map[string]struct{}
+		name                          string
+		svid                          string
+		audience                      string
+		updates                       []*cache.WorkloadUpdate
+		attestErr                     error
+		expectCode                    codes.Code
+		expectMsg                     string
+		expectLogs                    []spiretest.LogEntry
+		expectResponse                *workloadPB.ValidateJWTSVIDResponse
+		allowedForeignJWTClaims       map[string]struct{}
+		allowedJWTAudiences           map[string]struct{}
+		allowedForeignJWTTrustDomains map[spiffeid.TrustDomain]struct{}
 	}{
 		{
 			name:       "missing required audience",
@@ -1050,85 +1264,77 @@ func TestValidateJWTSVID(t *testing.T) {
 			},
 		},
 		{
-			name:       "success",
-			audience:   "AUDIENCE",
-			svid:       svid.Marshal(),
-			updates:    updatesWithBundleOnly,
-			expectCode: codes.OK,
-			expectResponse: &workloadPB.ValidateJWTSVIDResponse{
-				SpiffeId: "spiffe://domain.test/workload",
-				Claims: &structpb.Struct{
-					Fields: map[string]*structpb.Value{
-						"aud": {
-							Kind: &structpb.Value_ListValue{
-								ListValue: &structpb.ListValue{
-									Values: []*structpb.Value{
-										{
-											Kind: &structpb.Value_StringValue{
-												StringValue: "AUDIENCE",
-											},
-										},
-									},
-								},
-							},
-						},
-						"exp": {
-							Kind: &structpb.Value_NumberValue{
-								NumberValue: svid.Claims["exp"].(float64),
-							},
-						},
-						"iat": {
-							Kind: &structpb.Value_NumberValue{
-								NumberValue: svid.Claims["iat"].(float64),
-							},
-						},
-						"iss": {
-							Kind: &structpb.Value_StringValue{
-								StringValue: "FAKECA",
-							},
-						},
-						"sub": {
-							Kind: &structpb.Value_StringValue{
-								StringValue: "spiffe://domain.test/workload",
-							},
-						},
+			name:           "success",
+			audience:       "AUDIENCE",
+			svid:           svid.Marshal(),
+			updates:        updatesWithBundleOnly,
+			expectCode:     codes.OK,
+			expectResponse: successResponse,
+		},
+		{
+			name:           "success with federated SVID",
+			audience:       "AUDIENCE",
+			svid:           federatedSVID.Marshal(),
+			updates:        updatesWithFederatedBundle,
+			expectCode:     codes.OK,
+			expectResponse: federatedResponse,
+		},
+		{
+			name:                          "success with federated SVID from allowed trust domain",
+			audience:                      "AUDIENCE",
+			svid:                          federatedSVID.Marshal(),
+			updates:                       updatesWithFederatedBundle,
+			expectCode:                    codes.OK,
+			allowedForeignJWTTrustDomains: map[spiffeid.TrustDomain]struct{}{td2: {}},
+			expectResponse:                federatedResponse,
+		},
+		{
+			name:                          "federated SVID from trust domain not allowed",
+			audience:                      "AUDIENCE",
+			svid:                          federatedSVID.Marshal(),
+			updates:                       updatesWithFederatedBundle,
+			expectCode:                    codes.PermissionDenied,
+			expectMsg:                     `JWT-SVIDs from trust domain "domain2.test" are not allowed`,
+			allowedForeignJWTTrustDomains: map[spiffeid.TrustDomain]struct{}{},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Trust domain is not allowed for JWT-SVID validation",
+					Data: logrus.Fields{
+						"audience":  "AUDIENCE",
+						"service":   "WorkloadAPI",
+						"method":    "ValidateJWTSVID",
+						"spiffe_id": "spiffe://domain2.test/federated-workload",
 					},
 				},
 			},
 		},
 		{
-			name:       "success with federated SVID",
-			audience:   "AUDIENCE",
-			svid:       federatedSVID.Marshal(),
-			updates:    updatesWithFederatedBundle,
-			expectCode: codes.OK,
-			expectResponse: &workloadPB.ValidateJWTSVIDResponse{
-				SpiffeId: "spiffe://domain2.test/federated-workload",
-				Claims: &structpb.Struct{
-					Fields: map[string]*structpb.Value{
-						"aud": {
-							Kind: &structpb.Value_ListValue{
-								ListValue: &structpb.ListValue{
-									Values: []*structpb.Value{
-										{
-											Kind: &structpb.Value_StringValue{
-												StringValue: "AUDIENCE",
-											},
-										},
-									},
-								},
-							},
-						},
-						"exp": {
-							Kind: &structpb.Value_NumberValue{
-								NumberValue: federatedSVID.Claims["exp"].(float64),
-							},
-						},
-						"sub": {
-							Kind: &structpb.Value_StringValue{
-								StringValue: "spiffe://domain2.test/federated-workload",
-							},
-						},
+			name:                          "SVID from own trust domain when no foreign trust domain is allowed",
+			audience:                      "AUDIENCE",
+			svid:                          svid.Marshal(),
+			updates:                       updatesWithBundleOnly,
+			expectCode:                    codes.OK,
+			allowedForeignJWTTrustDomains: map[spiffeid.TrustDomain]struct{}{},
+			allowedJWTAudiences:           map[string]struct{}{"AUDIENCE": {}},
+			expectResponse:                successResponse,
+		},
+		{
+			name:                "audience not allowed",
+			audience:            "OTHER",
+			svid:                svid.Marshal(),
+			updates:             updatesWithBundleOnly,
+			expectCode:          codes.PermissionDenied,
+			expectMsg:           `audience "OTHER" is not allowed`,
+			allowedJWTAudiences: map[string]struct{}{"AUDIENCE": {}},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Audience is not allowed for JWT-SVID validation",
+					Data: logrus.Fields{
+						"audience": "OTHER",
+						"service":  "WorkloadAPI",
+						"method":   "ValidateJWTSVID",
 					},
 				},
 			},
@@ -1205,10 +1411,12 @@ func TestValidateJWTSVID(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			params := testParams{
-				Updates:                 tt.updates,
-				AttestErr:               tt.attestErr,
-				ExpectLogs:              tt.expectLogs,
-				AllowedForeignJWTClaims: tt.allowedForeignJWTClaims,
+				Updates:                       tt.updates,
+				AttestErr:                     tt.attestErr,
+				ExpectLogs:                    tt.expectLogs,
+				AllowedForeignJWTClaims:       tt.allowedForeignJWTClaims,
+				AllowedJWTAudiences:           tt.allowedJWTAudiences,
+				AllowedForeignJWTTrustDomains: tt.allowedForeignJWTTrustDomains,
 			}
 			runTest(t, params,
 				func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient) {
@@ -1231,12 +1439,16 @@ type testParams struct {
 	CA                            *testca.CA
 	Identities                    []cache.Identity
 	Updates                       []*cache.WorkloadUpdate
+	Rendered                      *cache.WorkloadUpdate
+	RenderErr                     error
 	AttestErr                     error
 	ManagerErr                    error
 	ExpectLogs                    []spiretest.LogEntry
 	AsPID                         int
 	AllowUnauthenticatedVerifiers bool
 	AllowedForeignJWTClaims       map[string]struct{}
+	AllowedJWTAudiences           map[string]struct{}
+	AllowedForeignJWTTrustDomains map[spiffeid.TrustDomain]struct{}
 }
 
 func runTest(t *testing.T, params testParams, fn func(ctx context.Context, client workloadPB.SpiffeWorkloadAPIClient)) {
@@ -1246,6 +1458,8 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 		ca:         params.CA,
 		identities: params.Identities,
 		updates:    params.Updates,
+		rendered:   params.Rendered,
+		renderErr:  params.RenderErr,
 		err:        params.ManagerErr,
 	}
 
@@ -1255,6 +1469,8 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 		Attestor:                      &FakeAttestor{err: params.AttestErr},
 		AllowUnauthenticatedVerifiers: params.AllowUnauthenticatedVerifiers,
 		AllowedForeignJWTClaims:       params.AllowedForeignJWTClaims,
+		AllowedJWTAudiences:           params.AllowedJWTAudiences,
+		AllowedForeignJWTTrustDomains: params.AllowedForeignJWTTrustDomains,
 	})
 
 	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.Chain(
@@ -1296,6 +1512,8 @@ type FakeManager struct {
 	ca          *testca.CA
 	identities  []cache.Identity
 	updates     []*cache.WorkloadUpdate
+	rendered    *cache.WorkloadUpdate
+	renderErr   error
 	subscribers int32
 	err         error
 }
@@ -1326,6 +1544,17 @@ func (m *FakeManager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.W
 	return m.updates[0]
 }
 
+func (m *FakeManager) RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error) {
+	switch {
+	case m.renderErr != nil:
+		return nil, m.renderErr
+	case m.rendered != nil:
+		return m.rendered, nil
+	default:
+		return update, nil
+	}
+}
+
 func (m *FakeManager) Subscribers() int {
 	return int(atomic.LoadInt32(&m.subscribers))
 }
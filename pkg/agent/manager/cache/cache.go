@@ -11,6 +11,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
@@ -118,6 +119,22 @@ type Cache struct {
 
 	// bundles holds the trust bundles, keyed by trust domain id (i.e. "spiffe://domain.test")
 	bundles map[spiffeid.TrustDomain]*bundleutil.Bundle
+
+	// subscribers is the number of active subscribers to workload updates
+	subscribers int
+
+	// lazySVIDs, when true, defers signing X509-SVIDs for an entry until a
+	// workload matching the entry selectors asks for its identities. The
+	// entries themselves are still synchronized in full.
+	lazySVIDs bool
+
+	// svidDemands receives a value when a workload asks for an entry that
+	// does not have an X509-SVID yet. Only used when lazySVIDs is true.
+	svidDemands chan struct{}
+
+	// withheld, when true, withholds the cached identities from workloads,
+	// e.g. while the agent cannot synchronize with the server.
+	withheld bool
 }
 
 // StaleEntry holds stale entries with SVIDs expiration time
@@ -142,7 +159,36 @@ func New(log logrus.FieldLogger, trustDomain spiffeid.TrustDomain, bundle *Bundl
 		bundles: map[spiffeid.TrustDomain]*bundleutil.Bundle{
 			trustDomain: bundle,
 		},
+		svidDemands: make(chan struct{}, 1),
+	}
+}
+
+// SetLazySVIDs controls whether X509-SVIDs are signed for every entry
+// (the default) or only for the entries that have been asked for by a
+// workload. It must be called before the cache is used.
+func (c *Cache) SetLazySVIDs(lazy bool) {
+	c.lazySVIDs = lazy
+}
+
+// SetIdentitiesWithheld controls whether the cached identities are withheld
+// from workloads. Subscribers are notified of the change, so workloads stop
+// or resume receiving their identities right away.
+func (c *Cache) SetIdentitiesWithheld(withheld bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.withheld == withheld {
+		return
 	}
+	c.withheld = withheld
+	c.notifyAll()
+}
+
+// SVIDDemands returns a channel that receives a value when a workload asks
+// for an entry that does not have an X509-SVID yet, so the caller can sign
+// it without waiting for the next synchronization.
+func (c *Cache) SVIDDemands() <-chan struct{} {
+	return c.svidDemands
 }
 
 // Identities is only used by manager tests
@@ -185,6 +231,8 @@ func (c *Cache) MatchingIdentities(selectors []*common.Selector) []Identity {
 	set, setDone := allocSelectorSet(selectors...)
 	defer setDone()
 
+	c.demandSVIDs(set)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.matchingIdentities(set)
@@ -194,6 +242,8 @@ func (c *Cache) FetchWorkloadUpdate(selectors []*common.Selector) *WorkloadUpdat
 	set, setDone := allocSelectorSet(selectors...)
 	defer setDone()
 
+	c.demandSVIDs(set)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.buildWorkloadUpdate(set)
@@ -207,6 +257,11 @@ func (c *Cache) SubscribeToWorkloadUpdates(selectors []*common.Selector) Subscri
 	for s := range sub.set {
 		c.addSelectorIndexSub(s, sub)
 	}
+	c.subscribers++
+	telemetry_agent.SetCacheManagerSubscribersGauge(c.metrics, c.subscribers)
+	if c.lazySVIDs {
+		c.markDemanded(sub.set)
+	}
 	c.notify(sub)
 	return sub
 }
@@ -417,6 +472,32 @@ func (c *Cache) UpdateSVIDs(update *UpdateSVIDs) {
 	}
 }
 
+// Snapshot returns the bundles, registration entries and X509-SVIDs held by
+// the cache, as the updates that would restore them into an empty cache.
+func (c *Cache) Snapshot() (*UpdateEntries, *UpdateSVIDs) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := &UpdateEntries{
+		Bundles:             make(map[spiffeid.TrustDomain]*bundleutil.Bundle, len(c.bundles)),
+		RegistrationEntries: make(map[string]*common.RegistrationEntry, len(c.records)),
+	}
+	for td, bundle := range c.bundles {
+		entries.Bundles[td] = bundle
+	}
+
+	svids := &UpdateSVIDs{
+		X509SVIDs: make(map[string]*X509SVID),
+	}
+	for id, record := range c.records {
+		entries.RegistrationEntries[id] = record.entry
+		if record.svid != nil {
+			svids.X509SVIDs[id] = record.svid
+		}
+	}
+	return entries, svids
+}
+
 // GetStaleEntries obtains a list of stale entries
 func (c *Cache) GetStaleEntries() []*StaleEntry {
 	c.mu.Lock()
@@ -431,6 +512,12 @@ func (c *Cache) GetStaleEntries() []*StaleEntry {
 			continue
 		}
 
+		if c.lazySVIDs && !cachedEntry.demanded {
+			// No workload has asked for this entry yet. Keep it stale so it
+			// is signed once a workload does.
+			continue
+		}
+
 		var expiresAt time.Time
 		if cachedEntry.svid != nil {
 			expiresAt = cachedEntry.svid.Chain[0].NotAfter
@@ -445,6 +532,48 @@ func (c *Cache) GetStaleEntries() []*StaleEntry {
 	return staleEntries
 }
 
+// demandSVIDs marks the records for the given selector set as demanded when
+// X509-SVIDs are signed lazily.
+func (c *Cache) demandSVIDs(set selectorSet) {
+	if !c.lazySVIDs {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.markDemanded(set)
+}
+
+// markDemanded marks the records whose selectors are a subset of the given
+// selector set as demanded by a workload. If any of these records still
+// needs an X509-SVID, a demand is signaled. Callers must hold the write lock.
+func (c *Cache) markDemanded(set selectorSet) {
+	needsSVID := false
+	for s := range set {
+		index := c.getSelectorIndexForRead(s)
+		if index == nil {
+			continue
+		}
+		for record := range index.records {
+			if record.demanded || !set.In(record.entry.Selectors...) {
+				continue
+			}
+			record.demanded = true
+			if record.svid == nil {
+				needsSVID = true
+			}
+		}
+	}
+
+	if needsSVID {
+		select {
+		case c.svidDemands <- struct{}{}:
+		default:
+			// A demand is already pending
+		}
+	}
+}
+
 func (c *Cache) updateOrCreateRecord(newEntry *common.RegistrationEntry) (*cacheRecord, *common.RegistrationEntry) {
 	var existingEntry *common.RegistrationEntry
 	record, recordExists := c.records[newEntry.EntryId]
@@ -551,6 +680,8 @@ func (c *Cache) unsubscribe(sub *subscriber) {
 	for selector := range sub.set {
 		c.delSelectorIndexSub(selector, sub)
 	}
+	c.subscribers--
+	telemetry_agent.SetCacheManagerSubscribersGauge(c.metrics, c.subscribers)
 }
 
 func (c *Cache) notifyAll() {
@@ -604,6 +735,10 @@ func (c *Cache) getSubscribers(set selectorSet) (subscriberSet, func()) {
 }
 
 func (c *Cache) matchingIdentities(set selectorSet) []Identity {
+	if c.withheld {
+		return nil
+	}
+
 	records, recordsDone := c.getRecordsForSelectors(set)
 	defer recordsDone()
 
@@ -711,6 +846,10 @@ type cacheRecord struct {
 	entry *common.RegistrationEntry
 	svid  *X509SVID
 	subs  map[*subscriber]struct{}
+
+	// demanded is true if a workload has asked for the identity of this
+	// record. Only used when X509-SVIDs are signed lazily.
+	demanded bool
 }
 
 func newCacheRecord() *cacheRecord {
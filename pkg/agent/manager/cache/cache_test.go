@@ -11,7 +11,9 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -109,6 +111,32 @@ func TestCountSVIDs(t *testing.T) {
 	require.Equal(t, 1, cache.CountSVIDs())
 }
 
+func TestSnapshot(t *testing.T) {
+	cache := newTestCache()
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "B")
+	updateEntries := &UpdateEntries{
+		Bundles:             makeBundles(bundleV1, otherBundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar),
+	}
+	cache.UpdateEntries(updateEntries, nil)
+	updateSVIDs := &UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo),
+	}
+	cache.UpdateSVIDs(updateSVIDs)
+
+	entries, svids := cache.Snapshot()
+	assert.Equal(t, updateEntries, entries)
+	assert.Equal(t, updateSVIDs, svids)
+
+	// The snapshot restores the identities into an empty cache
+	restored := newTestCache()
+	restored.UpdateEntries(entries, nil)
+	restored.UpdateSVIDs(svids)
+	assert.Equal(t, cache.MatchingIdentities(makeSelectors("A", "B")), restored.MatchingIdentities(makeSelectors("A", "B")))
+}
+
 func TestBundleChanges(t *testing.T) {
 	cache := newTestCache()
 
@@ -302,6 +330,26 @@ func TestSubscriberIsNotNotifiedIfNothingChanges(t *testing.T) {
 	assertNoWorkloadUpdate(t, sub)
 }
 
+func TestSubscribersMetrics(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	metrics := fakemetrics.New()
+	cache := New(log, trustDomain1, bundleV1, metrics)
+
+	sub1 := cache.SubscribeToWorkloadUpdates(makeSelectors("A"))
+	sub2 := cache.SubscribeToWorkloadUpdates(makeSelectors("B"))
+	sub1.Finish()
+	// Finishing a subscriber more than once does not affect the count
+	sub1.Finish()
+	sub2.Finish()
+
+	expected := fakemetrics.New()
+	telemetry_agent.SetCacheManagerSubscribersGauge(expected, 1)
+	telemetry_agent.SetCacheManagerSubscribersGauge(expected, 2)
+	telemetry_agent.SetCacheManagerSubscribersGauge(expected, 1)
+	telemetry_agent.SetCacheManagerSubscribersGauge(expected, 0)
+	assert.Equal(t, expected.AllMetrics(), metrics.AllMetrics())
+}
+
 func TestSubscriberNotifiedOnSVIDChanges(t *testing.T) {
 	cache := newTestCache()
 
@@ -329,6 +377,43 @@ func TestSubscriberNotifiedOnSVIDChanges(t *testing.T) {
 	})
 }
 
+func TestIdentitiesWithheld(t *testing.T) {
+	cache := newTestCache()
+
+	foo := makeRegistrationEntry("FOO", "A")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo),
+	}, nil)
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo),
+	})
+
+	sub := cache.SubscribeToWorkloadUpdates(makeSelectors("A"))
+	defer sub.Finish()
+	assertAnyWorkloadUpdate(t, sub)
+
+	// Withholding the identities notifies subscribers with no identities
+	cache.SetIdentitiesWithheld(true)
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle: bundleV1,
+	})
+	assert.Empty(t, cache.MatchingIdentities(makeSelectors("A")))
+	assert.Empty(t, cache.FetchWorkloadUpdate(makeSelectors("A")).Identities)
+	assert.Equal(t, 1, cache.CountSVIDs(), "SVIDs should be kept while withheld")
+
+	// Setting the same value again does not notify subscribers
+	cache.SetIdentitiesWithheld(true)
+	assertNoWorkloadUpdate(t, sub)
+
+	// Identities are served again once they are no longer withheld
+	cache.SetIdentitiesWithheld(false)
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle:     bundleV1,
+		Identities: []Identity{{Entry: foo}},
+	})
+}
+
 func TestSubcriberNotificationsOnSelectorChanges(t *testing.T) {
 	cache := newTestCache()
 
@@ -594,6 +679,46 @@ func TestGetStaleEntries(t *testing.T) {
 	assert.Empty(t, cache.GetStaleEntries())
 }
 
+func TestGetStaleEntriesWithLazySVIDs(t *testing.T) {
+	cache := newTestCache()
+	cache.SetLazySVIDs(true)
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "B")
+	baz := makeRegistrationEntry("BAZ", "C")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar, baz),
+	}, func(existingEntry, newEntry *common.RegistrationEntry, svid *X509SVID) bool {
+		return true
+	})
+
+	// No workload has asked for the entries yet
+	assert.Empty(t, cache.GetStaleEntries())
+	assertNoSVIDDemand(t, cache)
+
+	// Subscribing demands the entries matching the workload selectors
+	sub := cache.SubscribeToWorkloadUpdates(makeSelectors("A"))
+	defer sub.Finish()
+	assertSVIDDemand(t, cache)
+	assert.Equal(t, []*StaleEntry{{Entry: foo}}, cache.GetStaleEntries())
+
+	// Fetching identities demands the entries as well
+	assert.Empty(t, cache.MatchingIdentities(makeSelectors("B")))
+	assertSVIDDemand(t, cache)
+	assert.Empty(t, cache.FetchWorkloadUpdate(makeSelectors("C")).Identities)
+	assertSVIDDemand(t, cache)
+	assert.ElementsMatch(t, []*StaleEntry{{Entry: foo}, {Entry: bar}, {Entry: baz}}, cache.GetStaleEntries())
+
+	// Once signed, the entries are no longer demanded again
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo, bar, baz),
+	})
+	assert.Empty(t, cache.GetStaleEntries())
+	assert.Len(t, cache.MatchingIdentities(makeSelectors("A", "B")), 2)
+	assertNoSVIDDemand(t, cache)
+}
+
 func TestSubscriberNotNotifiedOnDifferentSVIDChanges(t *testing.T) {
 	cache := newTestCache()
 
@@ -700,6 +825,22 @@ func distinctSelectors(id, n int) []*common.Selector {
 	return out
 }
 
+func assertSVIDDemand(t *testing.T, cache *Cache) {
+	select {
+	case <-cache.SVIDDemands():
+	default:
+		assert.Fail(t, "expected an SVID demand")
+	}
+}
+
+func assertNoSVIDDemand(t *testing.T, cache *Cache) {
+	select {
+	case <-cache.SVIDDemands():
+		assert.Fail(t, "unexpected SVID demand")
+	default:
+	}
+}
+
 func assertNoWorkloadUpdate(t *testing.T, sub Subscriber) {
 	select {
 	case update := <-sub.Updates():
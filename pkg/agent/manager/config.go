@@ -9,9 +9,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/catalog"
+	"github.com/spiffe/spire/pkg/agent/client"
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/agent/rotationevents"
 	"github.com/spiffe/spire/pkg/agent/svid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 )
@@ -33,6 +35,40 @@ type Config struct {
 	RotationInterval time.Duration
 	SVIDStoreCache   *storecache.Cache
 
+	// LazySVIDs defers signing the X509-SVID for an entry until a workload
+	// asks for it.
+	LazySVIDs bool
+
+	// WorkloadKeyType is the type of key generated for workload X509-SVIDs.
+	// Defaults to EC P-256.
+	WorkloadKeyType keymanager.KeyType
+
+	// WorkloadDNSNames are requested in the CSRs of the workload X509-SVIDs
+	// with the SPIFFE ID they are keyed by. The server only includes the DNS
+	// names allowed for the entry.
+	WorkloadDNSNames map[spiffeid.ID][]string
+
+	// X509SVIDSigningWorkers is the maximum number of batches of workload
+	// X509-SVID CSRs generated and submitted to the server concurrently.
+	// Defaults to 4.
+	X509SVIDSigningWorkers int
+
+	// GRPCOptions tune the connection to the server
+	GRPCOptions client.GRPCOptions
+
+	// WorkloadSVIDsPath, if set, is where the workload SVIDs are persisted
+	// after each synchronization. On startup, the persisted SVIDs are served
+	// right away while they are renewed in the background.
+	WorkloadSVIDsPath string
+
+	// DegradedMode controls how the agent behaves while it cannot
+	// synchronize with the server
+	DegradedMode DegradedModeConfig
+
+	// RotationEvents, if set, is published an event for every workload
+	// X509-SVID cached by the manager
+	RotationEvents *rotationevents.Broker
+
 	// Clk is the clock the manager will use to get time
 	Clk clock.Clock
 }
@@ -51,11 +87,28 @@ func newManager(c *Config) *manager {
 		c.RotationInterval = svid.DefaultRotatorInterval
 	}
 
+	if c.WorkloadKeyType == keymanager.KeyTypeUnset {
+		c.WorkloadKeyType = keymanager.ECP256
+	}
+
+	if c.X509SVIDSigningWorkers <= 0 {
+		c.X509SVIDSigningWorkers = defaultX509SVIDSigningWorkers
+	}
+
 	if c.Clk == nil {
 		c.Clk = clock.New()
 	}
 
+	if c.DegradedMode.GracePeriod == 0 {
+		c.DegradedMode.GracePeriod = defaultDegradedGracePeriod
+	}
+
+	if c.DegradedMode.BundleExpiryWarning == 0 {
+		c.DegradedMode.BundleExpiryWarning = defaultBundleExpiryWarning
+	}
+
 	cache := cache.New(c.Log.WithField(telemetry.SubsystemName, telemetry.CacheManager), c.TrustDomain, c.Bundle, c.Metrics)
+	cache.SetLazySVIDs(c.LazySVIDs)
 
 	rotCfg := &svid.RotatorConfig{
 		SVIDKeyManager: keymanager.ForSVID(c.Catalog.GetKeyManager()),
@@ -68,6 +121,7 @@ func newManager(c *Config) *manager {
 		TrustDomain:    c.TrustDomain,
 		Interval:       c.RotationInterval,
 		Clk:            c.Clk,
+		GRPCOptions:    c.GRPCOptions,
 	}
 	svidRotator, client := svid.NewRotator(rotCfg)
 
@@ -81,6 +135,8 @@ func newManager(c *Config) *manager {
 		client:          client,
 		clk:             c.Clk,
 		svidStoreCache:  c.SVIDStoreCache,
+		templatedSVIDs:  make(map[string]*templatedSVID),
+		syncRequested:   make(chan struct{}, 1),
 	}
 
 	return m
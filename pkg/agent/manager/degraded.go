@@ -0,0 +1,113 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_agent "github.com/spiffe/spire/pkg/common/telemetry/agent"
+)
+
+const (
+	// defaultDegradedGracePeriod is how long synchronizations can fail
+	// before the agent enters degraded mode
+	defaultDegradedGracePeriod = time.Minute
+
+	// defaultBundleExpiryWarning is how long before they expire the bundle
+	// authorities are warned about while in degraded mode
+	defaultBundleExpiryWarning = 24 * time.Hour
+)
+
+// DegradedModeConfig controls how the agent behaves while it cannot
+// synchronize with the server, e.g. at intermittently connected sites.
+type DegradedModeConfig struct {
+	// GracePeriod is how long synchronizations can fail before the agent
+	// enters degraded mode. Defaults to one minute.
+	GracePeriod time.Duration
+
+	// WithholdSVIDs stops serving the cached SVIDs to workloads while in
+	// degraded mode. By default, the cached SVIDs are served until they
+	// expire.
+	WithholdSVIDs bool
+
+	// BundleExpiryWarning is how long before they expire the X.509
+	// authorities of the trust bundle are warned about while in degraded
+	// mode, since they cannot be rotated. Defaults to 24 hours.
+	BundleExpiryWarning time.Duration
+
+	// MaxRetryInterval caps the interval between synchronization attempts
+	// while they fail. Defaults to 24 times the synchronization interval.
+	MaxRetryInterval time.Duration
+}
+
+// observeSyncFailure is called by the synchronizer when a synchronization
+// fails. The agent enters degraded mode once synchronizations have been
+// failing for the grace period.
+func (m *manager) observeSyncFailure() {
+	now := m.clk.Now()
+	if m.syncFailingSince.IsZero() {
+		m.syncFailingSince = now
+	}
+
+	if m.degradedSince.IsZero() {
+		if now.Sub(m.syncFailingSince) < m.c.DegradedMode.GracePeriod {
+			return
+		}
+		m.degradedSince = now
+
+		log := m.c.Log
+		if lastSync := m.GetLastSync(); !lastSync.IsZero() {
+			log = log.WithField(telemetry.LastSync, lastSync.Format(time.RFC3339))
+		}
+		log.Warn("Unable to synchronize with the server; entering degraded mode")
+		if m.c.DegradedMode.WithholdSVIDs {
+			m.c.Log.Warn("Withholding cached SVIDs from workloads while in degraded mode")
+			m.cache.SetIdentitiesWithheld(true)
+		}
+		telemetry_agent.SetManagerDegradedGauge(m.c.Metrics, true)
+	}
+
+	telemetry_agent.SetManagerDegradedSecondsGauge(m.c.Metrics, now.Sub(m.degradedSince))
+	m.checkBundleExpiry(now)
+}
+
+// observeSyncSuccess is called by the synchronizer when a synchronization
+// succeeds, leaving degraded mode if the agent was in it.
+func (m *manager) observeSyncSuccess() {
+	m.syncFailingSince = time.Time{}
+	if m.degradedSince.IsZero() {
+		return
+	}
+
+	m.c.Log.WithField(telemetry.ElapsedTime, m.clk.Now().Sub(m.degradedSince).String()).Info("Synchronized with the server; leaving degraded mode")
+	m.degradedSince = time.Time{}
+	if m.c.DegradedMode.WithholdSVIDs {
+		m.cache.SetIdentitiesWithheld(false)
+	}
+	telemetry_agent.SetManagerDegradedGauge(m.c.Metrics, false)
+	telemetry_agent.SetManagerDegradedSecondsGauge(m.c.Metrics, 0)
+}
+
+// checkBundleExpiry warns about the X.509 authorities of the trust bundle
+// that expire soon, since they cannot be rotated while in degraded mode.
+func (m *manager) checkBundleExpiry(now time.Time) {
+	bundle := m.cache.Bundle()
+	if bundle == nil {
+		return
+	}
+
+	for _, rootCA := range bundle.RootCAs() {
+		if rootCA.NotAfter.Sub(now) > m.c.DegradedMode.BundleExpiryWarning {
+			continue
+		}
+		log := m.c.Log.WithFields(logrus.Fields{
+			telemetry.Subject:    rootCA.Subject.String(),
+			telemetry.Expiration: rootCA.NotAfter.Format(time.RFC3339),
+		})
+		if now.Before(rootCA.NotAfter) {
+			log.Warn("Trust bundle authority expires soon and cannot be rotated while in degraded mode")
+		} else {
+			log.Warn("Trust bundle authority has expired and cannot be rotated while in degraded mode")
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradedMode(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, logHook := test.NewNullLogger()
+	metrics := fakemetrics.New()
+
+	rootCA, _ := testca.CreateCACertificate(t, nil, nil, testca.WithLifetime(clk.Now(), clk.Now().Add(12*time.Hour)))
+	bundle := bundleutil.BundleFromRootCA(trustDomain, rootCA)
+
+	m := newDegradedTestManager(clk, log, metrics, bundle, DegradedModeConfig{
+		GracePeriod:         time.Minute,
+		WithholdSVIDs:       true,
+		BundleExpiryWarning: 24 * time.Hour,
+	})
+
+	entry := &common.RegistrationEntry{
+		EntryId:   "ENTRY",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	m.cache.UpdateEntries(&cache.UpdateEntries{
+		Bundles:             map[spiffeid.TrustDomain]*bundleutil.Bundle{trustDomain: bundle},
+		RegistrationEntries: map[string]*common.RegistrationEntry{entry.EntryId: entry},
+	}, nil)
+	m.cache.UpdateSVIDs(&cache.UpdateSVIDs{
+		X509SVIDs: map[string]*cache.X509SVID{entry.EntryId: {}},
+	})
+	require.Len(t, m.cache.MatchingIdentities(entry.Selectors), 1)
+
+	// Failures within the grace period do not enter degraded mode
+	m.observeSyncFailure()
+	clk.Add(30 * time.Second)
+	m.observeSyncFailure()
+	require.True(t, m.degradedSince.IsZero())
+	require.Empty(t, metrics.AllMetrics())
+	require.Len(t, m.cache.MatchingIdentities(entry.Selectors), 1)
+
+	// Failing past the grace period enters degraded mode, withholds the
+	// SVIDs and warns about the bundle authority expiring soon
+	clk.Add(30 * time.Second)
+	logHook.Reset()
+	m.observeSyncFailure()
+	require.False(t, m.degradedSince.IsZero())
+	require.Empty(t, m.cache.MatchingIdentities(entry.Selectors))
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Unable to synchronize with the server; entering degraded mode",
+		},
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Withholding cached SVIDs from workloads while in degraded mode",
+		},
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Trust bundle authority expires soon and cannot be rotated while in degraded mode",
+			Data: logrus.Fields{
+				telemetry.Subject:    rootCA.Subject.String(),
+				telemetry.Expiration: rootCA.NotAfter.Format(time.RFC3339),
+			},
+		},
+	})
+
+	// Time in degraded mode is tracked on every failure
+	clk.Add(time.Minute)
+	m.observeSyncFailure()
+
+	// A successful synchronization leaves degraded mode
+	clk.Add(time.Minute)
+	logHook.Reset()
+	m.observeSyncSuccess()
+	require.True(t, m.degradedSince.IsZero())
+	require.True(t, m.syncFailingSince.IsZero())
+	require.Len(t, m.cache.MatchingIdentities(entry.Selectors), 1)
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Synchronized with the server; leaving degraded mode",
+			Data: logrus.Fields{
+				telemetry.ElapsedTime: "2m0s",
+			},
+		},
+	})
+
+	degradedKey := []string{telemetry.Manager, telemetry.Degraded}
+	secondsKey := []string{telemetry.Manager, telemetry.Degraded, telemetry.Seconds}
+	assert.Equal(t, []fakemetrics.MetricItem{
+		{Type: fakemetrics.SetGaugeType, Key: degradedKey, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: secondsKey, Val: 0},
+		{Type: fakemetrics.SetGaugeType, Key: secondsKey, Val: 60},
+		{Type: fakemetrics.SetGaugeType, Key: degradedKey, Val: 0},
+		{Type: fakemetrics.SetGaugeType, Key: secondsKey, Val: 0},
+	}, metrics.AllMetrics())
+}
+
+func TestDegradedModeServesCachedSVIDsByDefault(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, _ := test.NewNullLogger()
+
+	m := newDegradedTestManager(clk, log, fakemetrics.New(), bundleutil.BundleFromRootCAs(trustDomain, nil), DegradedModeConfig{
+		GracePeriod:         time.Minute,
+		BundleExpiryWarning: time.Hour,
+	})
+
+	entry := &common.RegistrationEntry{
+		EntryId:   "ENTRY",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	m.cache.UpdateEntries(&cache.UpdateEntries{
+		RegistrationEntries: map[string]*common.RegistrationEntry{entry.EntryId: entry},
+	}, nil)
+	m.cache.UpdateSVIDs(&cache.UpdateSVIDs{
+		X509SVIDs: map[string]*cache.X509SVID{entry.EntryId: {}},
+	})
+
+	m.observeSyncFailure()
+	clk.Add(time.Minute)
+	m.observeSyncFailure()
+	require.False(t, m.degradedSince.IsZero())
+	require.Len(t, m.cache.MatchingIdentities(entry.Selectors), 1)
+}
+
+func newDegradedTestManager(clk *clock.Mock, log logrus.FieldLogger, metrics *fakemetrics.FakeMetrics, bundle *cache.Bundle, config DegradedModeConfig) *manager {
+	return &manager{
+		c: &Config{
+			Log:          log,
+			Metrics:      metrics,
+			DegradedMode: config,
+		},
+		mtx:   new(sync.RWMutex),
+		cache: cache.New(log, trustDomain, bundle, metrics),
+		clk:   clk,
+	}
+}
@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/dnstemplate"
+	"github.com/spiffe/spire/pkg/common/rotationutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// templatedSVID is an X509-SVID signed for the DNS names rendered from the DNS
+// name templates of an entry.
+type templatedSVID struct {
+	svid *cache.X509SVID
+
+	// revision is the revision number of the entry, and entrySVIDSerial the
+	// serial number of the cached X509-SVID of the entry, the X509-SVID was
+	// signed for. The X509-SVID is signed again when either changes, so it
+	// is rotated along with the cached X509-SVID of the entry.
+	revision        int64
+	entrySVIDSerial string
+}
+
+// RenderDNSNameTemplates returns the workload update with the X509-SVIDs of
+// the entries with DNS name templates replaced by X509-SVIDs signed for the
+// DNS names rendered from the workload selectors. The update is returned
+// as-is if no entry has DNS name templates.
+func (m *manager) RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error) {
+	var identities []cache.Identity
+	for i, identity := range update.Identities {
+		dnsNames, err := renderDNSNameTemplates(identity.Entry, selectors)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render DNS name templates for %q: %w", identity.Entry.SpiffeId, err)
+		}
+		if len(dnsNames) == 0 || len(identity.SVID) == 0 {
+			continue
+		}
+
+		svid, err := m.fetchTemplatedSVID(ctx, identity, dnsNames)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch X509-SVID with rendered DNS names for %q: %w", identity.Entry.SpiffeId, err)
+		}
+
+		if identities == nil {
+			identities = append([]cache.Identity(nil), update.Identities...)
+		}
+		identities[i].SVID = svid.Chain
+		identities[i].PrivateKey = svid.PrivateKey
+	}
+	if identities == nil {
+		return update, nil
+	}
+
+	return &cache.WorkloadUpdate{
+		Identities:       identities,
+		Bundle:           update.Bundle,
+		FederatedBundles: update.FederatedBundles,
+	}, nil
+}
+
+// fetchTemplatedSVID returns an X509-SVID for the entry of the identity with
+// the rendered DNS names. X509-SVIDs are shared by the workloads the DNS names
+// render the same for, and are only signed again when the cached X509-SVID of
+// the entry is rotated.
+func (m *manager) fetchTemplatedSVID(ctx context.Context, identity cache.Identity, dnsNames []string) (*cache.X509SVID, error) {
+	entryID := identity.Entry.EntryId
+	entrySVIDSerial := identity.SVID[0].SerialNumber.String()
+	key := entryID + "/" + strings.Join(dnsNames, ",")
+	now := m.clk.Now()
+
+	m.templatedSVIDsMtx.Lock()
+	defer m.templatedSVIDsMtx.Unlock()
+
+	m.pruneTemplatedSVIDs(now)
+
+	cached := m.templatedSVIDs[key]
+	if cached != nil && cached.revision == identity.Entry.RevisionNumber && cached.entrySVIDSerial == entrySVIDSerial {
+		return cached.svid, nil
+	}
+
+	svid, err := m.signTemplatedSVID(ctx, identity.Entry, dnsNames)
+	switch {
+	case err == nil:
+	case cached == nil:
+		return nil, err
+	default:
+		m.c.Log.WithError(err).WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId).Warn("Unable to renew X509-SVID with rendered DNS names; returning cached copy")
+		return cached.svid, nil
+	}
+
+	m.templatedSVIDs[key] = &templatedSVID{
+		svid:            svid,
+		revision:        identity.Entry.RevisionNumber,
+		entrySVIDSerial: entrySVIDSerial,
+	}
+	return svid, nil
+}
+
+func (m *manager) signTemplatedSVID(ctx context.Context, entry *common.RegistrationEntry, dnsNames []string) (*cache.X509SVID, error) {
+	spiffeID, err := spiffeid.FromString(entry.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The DNS names configured for the SPIFFE ID are requested too, as they
+	// are for the cached X509-SVID of the entry.
+	csrDNSNames := append(append([]string(nil), dnsNames...), m.c.WorkloadDNSNames[spiffeID]...)
+	privateKey, csr, err := newCSR(spiffeID, m.c.WorkloadKeyType, csrDNSNames)
+	if err != nil {
+		return nil, err
+	}
+
+	svids, err := m.client.NewX509SVIDs(ctx, map[string][]byte{entry.EntryId: csr})
+	if err != nil {
+		return nil, err
+	}
+	svid, ok := svids[entry.EntryId]
+	if !ok {
+		return nil, fmt.Errorf("no X509-SVID returned for entry %q", entry.EntryId)
+	}
+
+	chain, err := x509.ParseCertificates(svid.CertChain)
+	if err != nil {
+		return nil, err
+	}
+	return &cache.X509SVID{
+		Chain:      chain,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// pruneTemplatedSVIDs removes the expired X509-SVIDs, which are left behind
+// when workloads go away. The caller must hold templatedSVIDsMtx.
+func (m *manager) pruneTemplatedSVIDs(now time.Time) {
+	for key, cached := range m.templatedSVIDs {
+		if rotationutil.X509Expired(now, cached.svid.Chain[0]) {
+			delete(m.templatedSVIDs, key)
+		}
+	}
+}
+
+// renderDNSNameTemplates returns the DNS names rendered from the DNS name
+// templates of the entry, or nil if the entry has no DNS name templates.
+func renderDNSNameTemplates(entry *common.RegistrationEntry, selectors []*common.Selector) ([]string, error) {
+	var dnsNames []string
+	for _, dnsName := range entry.DnsNames {
+		if !dnstemplate.IsTemplate(dnsName) {
+			continue
+		}
+		rendered, err := dnstemplate.Render(dnsName, selectors)
+		if err != nil {
+			return nil, err
+		}
+		dnsNames = append(dnsNames, rendered)
+	}
+	return dnsNames, nil
+}
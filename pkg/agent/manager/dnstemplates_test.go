@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/agent/client"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDNSNameTemplates(t *testing.T) {
+	clk := clock.NewMock(t)
+	log, logHook := test.NewNullLogger()
+	ca := testca.New(t, trustDomain)
+	workloadID := spiffeid.RequireFromPath(trustDomain, "/workload")
+
+	fakeClient := &fakeDNSTemplateClient{ca: ca}
+	m := &manager{
+		c: &Config{
+			Log:             log,
+			WorkloadKeyType: keymanager.ECP256,
+			WorkloadDNSNames: map[spiffeid.ID][]string{
+				workloadID: {"configured.example.org"},
+			},
+		},
+		client:         fakeClient,
+		clk:            clk,
+		templatedSVIDs: make(map[string]*templatedSVID),
+	}
+
+	templateEntry := &common.RegistrationEntry{
+		EntryId:  "TEMPLATE",
+		SpiffeId: workloadID.String(),
+		DnsNames: []string{"static.example.org", "{{ .PodName }}.{{ .Namespace }}.svc"},
+	}
+	plainEntry := &common.RegistrationEntry{
+		EntryId:  "PLAIN",
+		SpiffeId: "spiffe://example.org/plain",
+		DnsNames: []string{"static.example.org"},
+	}
+
+	entrySVID := ca.CreateX509SVID(workloadID)
+	plainSVID := ca.CreateX509SVID(spiffeid.RequireFromPath(trustDomain, "/plain"))
+	newUpdate := func(entrySVID *x509svid.SVID) *cache.WorkloadUpdate {
+		return &cache.WorkloadUpdate{
+			Identities: []cache.Identity{
+				{Entry: plainEntry, SVID: plainSVID.Certificates, PrivateKey: plainSVID.PrivateKey},
+				{Entry: templateEntry, SVID: entrySVID.Certificates, PrivateKey: entrySVID.PrivateKey},
+			},
+		}
+	}
+	pod0 := []*common.Selector{
+		{Type: "k8s", Value: "ns:payments"},
+		{Type: "k8s", Value: "pod-name:api-0"},
+	}
+	pod1 := []*common.Selector{
+		{Type: "k8s", Value: "ns:payments"},
+		{Type: "k8s", Value: "pod-name:api-1"},
+	}
+	ctx := context.Background()
+
+	// Updates without DNS name templates are returned as-is
+	plainUpdate := &cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{Entry: plainEntry, SVID: plainSVID.Certificates, PrivateKey: plainSVID.PrivateKey},
+		},
+	}
+	update, err := m.RenderDNSNameTemplates(ctx, pod0, plainUpdate)
+	require.NoError(t, err)
+	require.Same(t, plainUpdate, update)
+	require.Empty(t, fakeClient.requests)
+
+	// The X509-SVID of the entry with DNS name templates is replaced by one
+	// signed for the rendered DNS names and the configured DNS names
+	update, err = m.RenderDNSNameTemplates(ctx, pod0, newUpdate(entrySVID))
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"api-0.payments.svc", "configured.example.org"}}, fakeClient.requests)
+	require.Len(t, update.Identities, 2)
+	require.Equal(t, plainSVID.Certificates, update.Identities[0].SVID)
+	require.Equal(t, fakeClient.signed[0], update.Identities[1].SVID)
+	require.Equal(t, templateEntry, update.Identities[1].Entry)
+	pod0SVID := update.Identities[1].SVID
+
+	// The X509-SVID is reused for the same rendered DNS names
+	update, err = m.RenderDNSNameTemplates(ctx, pod0, newUpdate(entrySVID))
+	require.NoError(t, err)
+	require.Len(t, fakeClient.requests, 1)
+	require.Equal(t, pod0SVID, update.Identities[1].SVID)
+
+	// Workloads the DNS names render differently for get their own X509-SVID
+	update, err = m.RenderDNSNameTemplates(ctx, pod1, newUpdate(entrySVID))
+	require.NoError(t, err)
+	require.Equal(t, []string{"api-1.payments.svc", "configured.example.org"}, fakeClient.requests[1])
+	require.Equal(t, fakeClient.signed[1], update.Identities[1].SVID)
+
+	// The X509-SVID is signed again when the X509-SVID of the entry rotates
+	rotatedSVID := ca.CreateX509SVID(workloadID)
+	update, err = m.RenderDNSNameTemplates(ctx, pod0, newUpdate(rotatedSVID))
+	require.NoError(t, err)
+	require.Len(t, fakeClient.requests, 3)
+	require.Equal(t, fakeClient.signed[2], update.Identities[1].SVID)
+	pod0SVID = update.Identities[1].SVID
+
+	// The cached X509-SVID is returned if it cannot be signed again
+	fakeClient.err = errors.New("ohno")
+	logHook.Reset()
+	update, err = m.RenderDNSNameTemplates(ctx, pod0, newUpdate(entrySVID))
+	require.NoError(t, err)
+	require.Equal(t, pod0SVID, update.Identities[1].SVID)
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Unable to renew X509-SVID with rendered DNS names; returning cached copy",
+			Data: logrus.Fields{
+				telemetry.SPIFFEID: workloadID.String(),
+				logrus.ErrorKey:    "ohno",
+			},
+		},
+	})
+
+	// Signing fails when there is no cached X509-SVID
+	_, err = m.RenderDNSNameTemplates(ctx, []*common.Selector{
+		{Type: "k8s", Value: "ns:payments"},
+		{Type: "k8s", Value: "pod-name:api-2"},
+	}, newUpdate(entrySVID))
+	require.EqualError(t, err, `unable to fetch X509-SVID with rendered DNS names for "spiffe://example.org/workload": ohno`)
+
+	// Rendering fails when the workload lacks a selector the template uses
+	_, err = m.RenderDNSNameTemplates(ctx, []*common.Selector{
+		{Type: "k8s", Value: "ns:payments"},
+	}, newUpdate(entrySVID))
+	require.EqualError(t, err, `unable to render DNS name templates for "spiffe://example.org/workload": DNS name template "{{ .PodName }}.{{ .Namespace }}.svc" rendered invalid DNS name ".payments.svc": label is empty`)
+
+	// Expired X509-SVIDs are pruned
+	require.Len(t, m.templatedSVIDs, 2)
+	clk.Add(2 * time.Hour)
+	m.templatedSVIDsMtx.Lock()
+	m.pruneTemplatedSVIDs(clk.Now())
+	m.templatedSVIDsMtx.Unlock()
+	require.Empty(t, m.templatedSVIDs)
+}
+
+type fakeDNSTemplateClient struct {
+	client.Client
+
+	ca       *testca.CA
+	err      error
+	requests [][]string
+	signed   [][]*x509.Certificate
+}
+
+func (c *fakeDNSTemplateClient) NewX509SVIDs(ctx context.Context, csrs map[string][]byte) (map[string]*client.X509SVID, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	svids := make(map[string]*client.X509SVID, len(csrs))
+	for entryID, csrBytes := range csrs {
+		csr, err := x509.ParseCertificateRequest(csrBytes)
+		if err != nil {
+			return nil, err
+		}
+		c.requests = append(c.requests, csr.DNSNames)
+
+		id, err := spiffeid.FromURI(csr.URIs[0])
+		if err != nil {
+			return nil, err
+		}
+		svid := c.ca.CreateX509SVID(id)
+		c.signed = append(c.signed, svid.Certificates)
+		svids[entryID] = &client.X509SVID{
+			CertChain: x509util.DERFromCertificates(svid.Certificates),
+		}
+	}
+	return svids, nil
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"github.com/spiffe/spire/pkg/agent/common/backoff"
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/agent/svid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
@@ -66,6 +68,11 @@ type Manager interface {
 	// FetchWorkloadUpdates gets the latest workload update for the selectors
 	FetchWorkloadUpdate(selectors []*common.Selector) *cache.WorkloadUpdate
 
+	// RenderDNSNameTemplates returns the workload update with the X509-SVIDs
+	// of the entries with DNS name templates replaced by X509-SVIDs signed for
+	// the DNS names rendered from the workload selectors.
+	RenderDNSNameTemplates(ctx context.Context, selectors []*common.Selector, update *cache.WorkloadUpdate) (*cache.WorkloadUpdate, error)
+
 	// FetchJWTSVID returns a JWT SVID for the specified SPIFFEID and audience. If there
 	// is no JWT cached, the manager will get one signed upstream.
 	FetchJWTSVID(ctx context.Context, spiffeID spiffeid.ID, audience []string) (*client.JWTSVID, error)
@@ -78,6 +85,9 @@ type Manager interface {
 
 	// GetBundle get latest cached bundle
 	GetBundle() *cache.Bundle
+
+	// SetServerAddr changes the address used to reach the server
+	SetServerAddr(addr string)
 }
 
 type manager struct {
@@ -96,6 +106,10 @@ type manager struct {
 	// fetch attempt
 	backoff backoff.BackOff
 
+	// Earliest time a cached X509-SVID is due for rotation, as of the last
+	// synchronization. Only accessed by the synchronizer.
+	nextRotation time.Time
+
 	client client.Client
 
 	clk clock.Clock
@@ -103,20 +117,54 @@ type manager struct {
 	// Saves last success sync
 	lastSync time.Time
 
+	// Time of the first of the consecutive failed synchronizations, and
+	// time the degraded mode was entered. Zero when synchronizing normally.
+	// Only accessed by the synchronizer.
+	syncFailingSince time.Time
+	degradedSince    time.Time
+
 	// Cache for 'storable' SVIDs
 	svidStoreCache *storecache.Cache
+
+	// Key used to encrypt the persisted workload SVIDs and digest of the
+	// last persisted workload SVIDs
+	workloadSVIDsKey    []byte
+	workloadSVIDsDigest [sha256.Size]byte
+
+	// X509-SVIDs signed for the DNS names rendered from the DNS name
+	// templates of entries, keyed by entry ID and rendered DNS names
+	templatedSVIDsMtx sync.Mutex
+	templatedSVIDs    map[string]*templatedSVID
+
+	// syncRequested is signaled when the entries streamed from the server
+	// change, so they are synchronized without waiting for the next
+	// synchronization
+	syncRequested chan struct{}
 }
 
 func (m *manager) Initialize(ctx context.Context) error {
 	m.storeSVID(m.svid.State().SVID)
 	m.storeBundle(m.cache.Bundle())
 
-	m.backoff = backoff.NewBackoff(m.clk, m.c.SyncInterval)
+	if m.c.DegradedMode.MaxRetryInterval > 0 {
+		m.backoff = backoff.NewBackoffWithMaxInterval(m.clk, m.c.SyncInterval, m.c.DegradedMode.MaxRetryInterval)
+	} else {
+		m.backoff = backoff.NewBackoff(m.clk, m.c.SyncInterval)
+	}
+
+	prewarmed := m.loadWorkloadSVIDs(ctx)
 
 	err := m.synchronize(ctx)
-	if nodeutil.ShouldAgentReattest(err) {
+	switch {
+	case nodeutil.ShouldAgentReattest(err):
 		m.c.Log.WithError(err).Error("Agent needs to re-attest: removing SVID and shutting down")
 		m.deleteSVID()
+	case err != nil && prewarmed:
+		// Keep serving the persisted SVIDs; they are renewed by the
+		// synchronizer once the server can be reached.
+		m.c.Log.WithError(err).Warn("Failed to synchronize with the server; serving persisted workload SVIDs")
+		m.observeSyncFailure()
+		return nil
 	}
 	return err
 }
@@ -126,6 +174,7 @@ func (m *manager) Run(ctx context.Context) error {
 
 	err := util.RunTasks(ctx,
 		m.runSynchronizer,
+		m.runEntrySync,
 		m.runSVIDObserver,
 		m.runBundleObserver,
 		m.svid.Run)
@@ -222,7 +271,11 @@ func (m *manager) getEntryID(spiffeID string) string {
 func (m *manager) runSynchronizer(ctx context.Context) error {
 	for {
 		select {
-		case <-m.clk.After(m.backoff.NextBackOff()):
+		case <-m.clk.After(m.nextSyncDelay()):
+		case <-m.cache.SVIDDemands():
+			// A workload is waiting on an X509-SVID that has not been
+			// signed yet; don't wait for the next synchronization.
+		case <-m.syncRequested:
 		case <-ctx.Done():
 			return nil
 		}
@@ -235,12 +288,29 @@ func (m *manager) runSynchronizer(ctx context.Context) error {
 		case err != nil:
 			// Just log the error and wait for next synchronization
 			m.c.Log.WithError(err).Error("Synchronize failed")
+			m.observeSyncFailure()
 		default:
 			m.backoff.Reset()
+			m.observeSyncSuccess()
 		}
 	}
 }
 
+// nextSyncDelay returns how long to wait for the next synchronization. It
+// is shortened when a cached X509-SVID is due for rotation earlier, so that
+// SVIDs with a TTL shorter than the synchronization interval, e.g. because of
+// the TTL of their registration entry, are rotated in time.
+func (m *manager) nextSyncDelay() time.Duration {
+	delay := m.backoff.NextBackOff()
+	if m.nextRotation.IsZero() {
+		return delay
+	}
+	if untilRotation := m.nextRotation.Sub(m.clk.Now()); untilRotation > 0 && untilRotation < delay {
+		return untilRotation
+	}
+	return delay
+}
+
 func (m *manager) setLastSync() {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
@@ -262,6 +332,64 @@ func (m *manager) GetBundle() *cache.Bundle {
 	return m.cache.Bundle()
 }
 
+func (m *manager) SetServerAddr(addr string) {
+	m.client.SetAddr(addr)
+}
+
+// runEntrySync streams the authorized entries from the server, requesting a
+// synchronization each time they change. The stream is reopened when the agent
+// SVID is rotated, since it keeps using the SVID it was opened with. When the
+// server does not support streaming, the entries are polled on each
+// synchronization and streaming is attempted again after the next rotation.
+func (m *manager) runEntrySync(ctx context.Context) error {
+	svidStream := m.SubscribeToSVIDChanges()
+	for {
+		syncCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- m.client.SyncEntries(syncCtx, m.requestSync)
+		}()
+
+		var err error
+		select {
+		case <-svidStream.Changes():
+			svidStream.Next()
+			cancel()
+			<-done
+			continue
+		case err = <-done:
+			cancel()
+		}
+
+		var retry <-chan time.Time
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, client.ErrEntrySyncUnsupported):
+			m.c.Log.Debug("SPIRE Server does not support entry sync; polling for entry changes")
+		default:
+			retry = m.clk.After(m.c.SyncInterval)
+		}
+
+		select {
+		case <-retry:
+		case <-svidStream.Changes():
+			svidStream.Next()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// requestSync requests a synchronization without waiting for the next one.
+// Requests made while one is pending are coalesced.
+func (m *manager) requestSync() {
+	select {
+	case m.syncRequested <- struct{}{}:
+	default:
+	}
+}
+
 func (m *manager) runSVIDObserver(ctx context.Context) error {
 	svidStream := m.SubscribeToSVIDChanges()
 	for {
@@ -306,6 +434,68 @@ func (m *manager) storeBundle(bundle *bundleutil.Bundle) {
 	}
 }
 
+// loadWorkloadSVIDs derives the key used to encrypt the persisted workload
+// SVIDs and restores them into the cache. Returns true if any X509-SVID was
+// restored.
+func (m *manager) loadWorkloadSVIDs(ctx context.Context) bool {
+	if m.c.WorkloadSVIDsPath == "" {
+		return false
+	}
+
+	log := m.c.Log.WithField(telemetry.Path, m.c.WorkloadSVIDsPath)
+	key, err := keymanager.ForCache(m.c.Catalog.GetKeyManager()).EncryptionKey(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Could not derive the workload SVIDs encryption key; workload SVIDs will not be persisted")
+		return false
+	}
+	m.workloadSVIDsKey = key
+
+	entries, svids, err := ReadWorkloadSVIDs(m.c.WorkloadSVIDsPath, key, m.clk.Now())
+	switch {
+	case errors.Is(err, ErrNotCached):
+		log.Debug("No persisted workload SVIDs found")
+		return false
+	case err != nil:
+		log.WithError(err).Warn("Could not load persisted workload SVIDs")
+		return false
+	}
+
+	// The bundle of the agent trust domain is already in the cache and may
+	// be more recent than the persisted one.
+	if bundle := m.cache.Bundle(); bundle != nil {
+		entries.Bundles[m.c.TrustDomain] = bundle
+	}
+
+	m.cache.UpdateEntries(entries, nil)
+	m.cache.UpdateSVIDs(svids)
+	log.WithField(telemetry.Count, len(svids.X509SVIDs)).Info("Loaded persisted workload SVIDs")
+	return len(svids.X509SVIDs) > 0
+}
+
+// storeWorkloadSVIDs persists the workload SVIDs in the cache, if they
+// changed since they were last persisted.
+func (m *manager) storeWorkloadSVIDs() {
+	if m.workloadSVIDsKey == nil {
+		return
+	}
+
+	data, err := MarshalWorkloadSVIDs(m.cache.Snapshot())
+	if err != nil {
+		m.c.Log.WithError(err).Warn("Could not marshal workload SVIDs")
+		return
+	}
+
+	digest := sha256.Sum256(data)
+	if digest == m.workloadSVIDsDigest {
+		return
+	}
+	if err := StoreWorkloadSVIDs(m.c.WorkloadSVIDsPath, m.workloadSVIDsKey, data); err != nil {
+		m.c.Log.WithError(err).Warn("Could not store workload SVIDs")
+		return
+	}
+	m.workloadSVIDsDigest = digest
+}
+
 func (m *manager) deleteSVID() {
 	if err := DeleteSVID(m.svidCachePath); err != nil {
 		m.c.Log.WithError(err).Error("Failed to remove SVID")
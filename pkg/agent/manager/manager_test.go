@@ -4,11 +4,15 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"path"
 	"sync"
 	"sync/atomic"
@@ -26,11 +30,14 @@ import (
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
 	"github.com/spiffe/spire/pkg/agent/manager/storecache"
 	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/agent/rotationevents"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/limits"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeagentcatalog"
@@ -45,6 +52,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -268,6 +276,94 @@ func TestHappyPathWithoutSyncNorRotation(t *testing.T) {
 	})
 }
 
+func TestPrewarmWorkloadSVIDs(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:        api.addr,
+		SVID:              baseSVID,
+		SVIDKey:           baseSVIDKey,
+		Log:               testLogger,
+		TrustDomain:       trustDomain,
+		SVIDCachePath:     path.Join(dir, "svid.der"),
+		BundleCachePath:   path.Join(dir, "bundle.der"),
+		WorkloadSVIDsPath: path.Join(dir, "workload_svids.cache"),
+		Bundle:            api.bundle,
+		Metrics:           &telemetry.Blackhole{},
+		Clk:               clk,
+		Catalog:           cat,
+		SVIDStoreCache:    storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	// Without persisted SVIDs, initialization fails if the server cannot be
+	// reached
+	unreachable := *c
+	unreachable.ServerAddr = ""
+	require.Error(t, newManager(&unreachable).Initialize(context.Background()))
+
+	m, closer := initializeAndRunNewManager(t, c)
+	expected := m.MatchingIdentities(cache.Selectors{{Type: "unix", Value: "uid:1111"}})
+	require.Len(t, expected, 2)
+	closer()
+
+	// The persisted SVIDs are served even though the server cannot be
+	// reached
+	m = newManager(&unreachable)
+	require.NoError(t, m.Initialize(context.Background()))
+	actual := m.MatchingIdentities(cache.Selectors{{Type: "unix", Value: "uid:1111"}})
+	compareRegistrationEntries(t, regEntriesFromIdentities(expected), regEntriesFromIdentities(actual))
+	actualByID := identitiesByEntryID(actual)
+	for id, identity := range identitiesByEntryID(expected) {
+		require.Equal(t, identity.SVID, actualByID[id].SVID)
+		require.Equal(t, identity.PrivateKey, actualByID[id].PrivateKey)
+	}
+	require.Equal(t, api.bundle, m.GetBundle())
+
+	// The persisted SVIDs are encrypted
+	sealed, err := os.ReadFile(c.WorkloadSVIDsPath)
+	require.NoError(t, err)
+	require.False(t, json.Valid(sealed))
+
+	// The persisted SVIDs cannot be loaded with another key manager
+	otherCat := fakeagentcatalog.New()
+	otherCat.SetKeyManager(fakeagentkeymanager.New(t, spiretest.TempDir(t)))
+	otherKM := unreachable
+	otherKM.Catalog = otherCat
+	require.Error(t, newManager(&otherKM).Initialize(context.Background()))
+
+	// Tampered SVIDs are not loaded
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(c.WorkloadSVIDsPath, tampered, 0600))
+	require.Error(t, newManager(&unreachable).Initialize(context.Background()))
+	require.NoError(t, os.WriteFile(c.WorkloadSVIDsPath, sealed, 0600))
+
+	// Expired SVIDs are not served
+	clk.Add(time.Hour)
+	m = newManager(&unreachable)
+	require.Error(t, m.Initialize(context.Background()))
+	require.Empty(t, m.MatchingIdentities(cache.Selectors{{Type: "unix", Value: "uid:1111"}}))
+}
+
 func TestSVIDRotation(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -519,6 +615,366 @@ func TestSynchronization(t *testing.T) {
 	require.Equal(t, clk.Now(), m.GetLastSync())
 }
 
+func TestSynchronizationPublishesRotationEvents(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 3,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	broker := rotationevents.NewBroker()
+	events, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		RotationInterval: time.Hour,
+		SyncInterval:     time.Hour,
+		Clk:              clk,
+		Catalog:          cat,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		RotationEvents:   broker,
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	receiveEvents := func() map[string]rotationevents.Event {
+		received := make(map[string]rotationevents.Event)
+		for len(received) < 3 {
+			select {
+			case event := <-events:
+				received[event.EntryID] = event
+			default:
+				t.Fatalf("expected 3 rotation events; got %d", len(received))
+			}
+		}
+		return received
+	}
+
+	// Newly cached SVIDs have no previous expiration
+	issued := receiveEvents()
+	identities := identitiesByEntryID(m.cache.Identities())
+	for entryID, event := range issued {
+		identity, ok := identities[entryID]
+		require.True(t, ok, "event for unknown entry %q", entryID)
+		require.Equal(t, identity.Entry.SpiffeId, event.SPIFFEID)
+		require.Equal(t, identity.SVID[0].NotAfter.Unix(), event.ExpiresAt)
+		require.Zero(t, event.PreviousExpiresAt)
+	}
+
+	// No events are published while the SVIDs are not rotated
+	clk.Add(time.Second)
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Empty(t, events)
+
+	// Rotated SVIDs carry the expiration of the replaced SVID
+	clk.Add(time.Second)
+	require.NoError(t, m.synchronize(context.Background()))
+	rotated := receiveEvents()
+	for entryID, event := range rotated {
+		require.Equal(t, issued[entryID].ExpiresAt, event.PreviousExpiresAt)
+		require.Greater(t, event.ExpiresAt, event.PreviousExpiresAt)
+	}
+}
+
+func TestSynchronizationFollowsSVIDRotation(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 300,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		RotationInterval: time.Hour,
+		SyncInterval:     time.Hour,
+		Clk:              clk,
+		Catalog:          cat,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	// The SVIDs are valid for five minutes, so the next synchronization
+	// must happen at their half-life instead of after the sync interval.
+	require.Equal(t, 150*time.Second, m.nextSyncDelay())
+
+	// The SVIDs are still good after a minute; the delay counts down to
+	// their rotation time.
+	clk.Add(time.Minute)
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Equal(t, 90*time.Second, m.nextSyncDelay())
+
+	// Once rotated, the delay follows the new SVIDs.
+	clk.Add(90 * time.Second)
+	require.NoError(t, m.synchronize(context.Background()))
+	require.Equal(t, 150*time.Second, m.nextSyncDelay())
+}
+
+func TestWorkloadKeyType(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		keyType   keymanager.KeyType
+		assertKey func(t *testing.T, key crypto.Signer)
+	}{
+		{
+			name: "default",
+			assertKey: func(t *testing.T, key crypto.Signer) {
+				require.IsType(t, &ecdsa.PrivateKey{}, key)
+				require.Equal(t, elliptic.P256(), key.(*ecdsa.PrivateKey).Curve)
+			},
+		},
+		{
+			name:    "ec-p384",
+			keyType: keymanager.ECP384,
+			assertKey: func(t *testing.T, key crypto.Signer) {
+				require.IsType(t, &ecdsa.PrivateKey{}, key)
+				require.Equal(t, elliptic.P384(), key.(*ecdsa.PrivateKey).Curve)
+			},
+		},
+		{
+			name:    "rsa-2048",
+			keyType: keymanager.RSA2048,
+			assertKey: func(t *testing.T, key crypto.Signer) {
+				require.IsType(t, &rsa.PrivateKey{}, key)
+				require.Equal(t, 2048, key.(*rsa.PrivateKey).N.BitLen())
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := spiretest.TempDir(t)
+			km := fakeagentkeymanager.New(t, dir)
+
+			clk := clock.NewMock(t)
+			api := newMockAPI(t, &mockAPIConfig{
+				km: km,
+				getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+					return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+				},
+				batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+					return makeBatchNewX509SVIDEntries("resp1", "resp2")
+				},
+				svidTTL: 200,
+				clk:     clk,
+			})
+
+			baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+			cat := fakeagentcatalog.New()
+			cat.SetKeyManager(km)
+
+			c := &Config{
+				ServerAddr:      api.addr,
+				SVID:            baseSVID,
+				SVIDKey:         baseSVIDKey,
+				Log:             testLogger,
+				TrustDomain:     trustDomain,
+				SVIDCachePath:   path.Join(dir, "svid.der"),
+				BundleCachePath: path.Join(dir, "bundle.der"),
+				Bundle:          api.bundle,
+				Metrics:         &telemetry.Blackhole{},
+				Clk:             clk,
+				Catalog:         cat,
+				SVIDStoreCache:  storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+				WorkloadKeyType: tt.keyType,
+			}
+
+			m := newManager(c)
+			require.NoError(t, m.Initialize(context.Background()))
+
+			identities := m.cache.Identities()
+			require.NotEmpty(t, identities)
+			for _, identity := range identities {
+				tt.assertKey(t, identity.PrivateKey)
+				require.Equal(t, identity.PrivateKey.Public(), identity.SVID[0].PublicKey)
+			}
+		})
+	}
+}
+
+func TestSynchronizationSignsStaleEntriesInBatches(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	// Enough entries to need three batches
+	var regEntries []*common.RegistrationEntry
+	var entries []*types.Entry
+	for i := 0; i < 2*limits.SignLimitPerIP+1; i++ {
+		spiffeID := spiffeid.RequireFromPath(trustDomain, fmt.Sprintf("/workload-%d", i))
+		regEntries = append(regEntries, &common.RegistrationEntry{
+			EntryId:  fmt.Sprintf("entry-%d", i),
+			SpiffeId: spiffeID.String(),
+		})
+		entries = append(entries, &types.Entry{
+			Id:        fmt.Sprintf("entry-%d", i),
+			SpiffeId:  api.ProtoFromID(spiffeID),
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1111"}},
+		})
+	}
+
+	clk := clock.NewMock(t)
+	mockAPI := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return &entryv1.GetAuthorizedEntriesResponse{Entries: entries}, nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return regEntries
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := mockAPI.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:             mockAPI.addr,
+		SVID:                   baseSVID,
+		SVIDKey:                baseSVIDKey,
+		Log:                    testLogger,
+		TrustDomain:            trustDomain,
+		SVIDCachePath:          path.Join(dir, "svid.der"),
+		BundleCachePath:        path.Join(dir, "bundle.der"),
+		Bundle:                 mockAPI.bundle,
+		Metrics:                &telemetry.Blackhole{},
+		Clk:                    clk,
+		Catalog:                cat,
+		SVIDStoreCache:         storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		X509SVIDSigningWorkers: 2,
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	// Every stale entry is signed in a single synchronization
+	require.Len(t, m.cache.Identities(), len(entries))
+	for _, identity := range m.cache.Identities() {
+		require.NotEmpty(t, identity.SVID)
+	}
+	require.ElementsMatch(t, []int{limits.SignLimitPerIP, limits.SignLimitPerIP, 1}, mockAPI.batchSizes)
+}
+
+func TestBatchCSRRequests(t *testing.T) {
+	m := &manager{c: &Config{Log: testLogger}}
+
+	csrs := []csrRequest{
+		{EntryID: "entry-1"},
+		{EntryID: "entry-2"},
+		{EntryID: "entry-1"},
+		{EntryID: "entry-3"},
+	}
+	require.Equal(t, [][]csrRequest{
+		{{EntryID: "entry-1"}, {EntryID: "entry-2"}},
+		{{EntryID: "entry-3"}},
+	}, m.batchCSRRequests(csrs, 2))
+	require.Nil(t, m.batchCSRRequests(nil, 2))
+}
+
+func TestWorkloadDNSNames(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	webID := spiffeid.RequireFromString(regEntriesMap["resp1"][0].SpiffeId)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		Clk:              clk,
+		Catalog:          cat,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		WorkloadDNSNames: map[spiffeid.ID][]string{
+			webID: {"host1.web.example.org"},
+		},
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	// The mock server signs the DNS names requested in the CSR, which are
+	// only requested for the workload they are configured for
+	identities := m.cache.Identities()
+	require.Greater(t, len(identities), 1)
+	for _, identity := range identities {
+		if identity.Entry.SpiffeId == webID.String() {
+			require.Equal(t, []string{"host1.web.example.org"}, identity.SVID[0].DNSNames)
+		} else {
+			require.Empty(t, identity.SVID[0].DNSNames)
+		}
+	}
+}
+
 func TestSynchronizationClearsStaleCacheEntries(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -721,6 +1177,70 @@ func TestSubscribersGetUpToDateBundle(t *testing.T) {
 	})
 }
 
+func TestEntrySync(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	pushEntries := make(chan []*types.Entry)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(h *mockAPI, count int32, req *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1"), nil
+		},
+		batchNewX509SVIDEntries: func(h *mockAPI, count int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		syncAuthorizedEntries: func(h *mockAPI, stream entrysync.EntrySync_SyncAuthorizedEntriesServer) error {
+			for {
+				select {
+				case entries := <-pushEntries:
+					if err := stream.Send(&entrysync.SyncAuthorizedEntriesResponse{Entries: entries}); err != nil {
+						return err
+					}
+				case <-stream.Context().Done():
+					return nil
+				}
+			}
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		SVIDCachePath:    path.Join(dir, "svid.der"),
+		BundleCachePath:  path.Join(dir, "bundle.der"),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		RotationInterval: time.Hour,
+		SyncInterval:     time.Hour,
+		Clk:              clk,
+		Catalog:          cat,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+	}
+
+	m := newManager(c)
+	defer initializeAndRunManager(t, m)()
+	require.Len(t, m.cache.Identities(), len(regEntriesMap["resp1"]))
+
+	// The entries pushed by the server are synchronized without waiting for
+	// the next synchronization
+	pushEntries <- makeGetAuthorizedEntriesResponse(t, "resp1", "resp2").Entries
+	require.Eventually(t, func() bool {
+		return len(m.cache.Identities()) == len(regEntriesMap["resp1"])+len(regEntriesMap["resp2"])
+	}, time.Minute, 10*time.Millisecond)
+	require.EqualValues(t, 1, atomic.LoadInt32(&api.getAuthorizedEntriesCount))
+}
+
 func TestSurvivesCARotation(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	km := fakeagentkeymanager.New(t, dir)
@@ -1063,6 +1583,7 @@ type mockAPIConfig struct {
 	getAuthorizedEntries    func(api *mockAPI, count int32, req *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error)
 	batchNewX509SVIDEntries func(api *mockAPI, count int32) []*common.RegistrationEntry
 	newJWTSVID              func(api *mockAPI, req *svidv1.NewJWTSVIDRequest) (*svidv1.NewJWTSVIDResponse, error)
+	syncAuthorizedEntries   func(api *mockAPI, stream entrysync.EntrySync_SyncAuthorizedEntriesServer) error
 
 	svidTTL int
 	clk     clock.Clock
@@ -1087,11 +1608,17 @@ type mockAPI struct {
 	clk clock.Clock
 
 	// Add latests SVIDs per entry, to verify returned SVIDs are valid
-	lastestSVIDs map[string][]*x509.Certificate
+	lastestSVIDsMtx sync.Mutex
+	lastestSVIDs    map[string][]*x509.Certificate
+
+	// Size of each BatchNewX509SVID request received
+	batchSizesMtx sync.Mutex
+	batchSizes    []int
 
 	agentv1.UnimplementedAgentServer
 	bundlev1.UnimplementedBundleServer
 	entryv1.UnimplementedEntryServer
+	entrysync.UnimplementedEntrySyncServer
 	svidv1.UnimplementedSVIDServer
 }
 
@@ -1118,6 +1645,7 @@ func newMockAPI(t *testing.T, config *mockAPIConfig) *mockAPI {
 	agentv1.RegisterAgentServer(server, h)
 	bundlev1.RegisterBundleServer(server, h)
 	entryv1.RegisterEntryServer(server, h)
+	entrysync.RegisterEntrySyncServer(server, h)
 	svidv1.RegisterSVIDServer(server, h)
 
 	listener, err := net.Listen("tcp", "localhost:")
@@ -1160,9 +1688,20 @@ func (h *mockAPI) GetAuthorizedEntries(ctx context.Context, req *entryv1.GetAuth
 	return nil, errors.New("no GetAuthorizedEntries implementation for test")
 }
 
+func (h *mockAPI) SyncAuthorizedEntries(req *entrysync.SyncAuthorizedEntriesRequest, stream entrysync.EntrySync_SyncAuthorizedEntriesServer) error {
+	if h.c.syncAuthorizedEntries != nil {
+		return h.c.syncAuthorizedEntries(h, stream)
+	}
+	return status.Error(codes.Unimplemented, "no SyncAuthorizedEntries implementation for test")
+}
+
 func (h *mockAPI) BatchNewX509SVID(ctx context.Context, req *svidv1.BatchNewX509SVIDRequest) (*svidv1.BatchNewX509SVIDResponse, error) {
 	count := atomic.AddInt32(&h.batchNewX509SVIDCount, 1)
 
+	h.batchSizesMtx.Lock()
+	h.batchSizes = append(h.batchSizes, len(req.Params))
+	h.batchSizesMtx.Unlock()
+
 	var entries map[string]*common.RegistrationEntry
 	if h.c.batchNewX509SVIDEntries != nil {
 		entries = regEntriesAsMap(h.c.batchNewX509SVIDEntries(h, count))
@@ -1179,7 +1718,9 @@ func (h *mockAPI) BatchNewX509SVID(ctx context.Context, req *svidv1.BatchNewX509
 		svid := h.newSVIDFromCSR(spiffeid.RequireFromString(entry.SpiffeId), param.Csr)
 
 		// Keep latests SVIDs per entry
+		h.lastestSVIDsMtx.Lock()
 		h.lastestSVIDs[entry.EntryId] = svid
+		h.lastestSVIDsMtx.Unlock()
 
 		resp.Results = append(resp.Results, &svidv1.BatchNewX509SVIDResponse_Result{
 			Status: api.OK(),
@@ -1313,6 +1854,7 @@ func createSVIDFromCSR(t *testing.T, clk clock.Clock, ca *x509.Certificate, caKe
 	require.NoError(t, err)
 	tmpl.PublicKey = req.PublicKey
 	tmpl.NotAfter = tmpl.NotBefore.Add(time.Duration(ttl) * time.Second)
+	tmpl.DNSNames = req.DNSNames
 
 	svid, _, err := util.Sign(tmpl, ca, caKey)
 	require.NoError(t, err)
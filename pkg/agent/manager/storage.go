@@ -2,11 +2,25 @@ package manager
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/diskutil"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/proto"
 )
 
 // ReadBundle returns the bundle located at bundleCachePath. Returns nil
@@ -76,3 +90,186 @@ func StoreSVID(svidCachePath string, svidChain []*x509.Certificate) error {
 func DeleteSVID(svidCachePath string) error {
 	return os.Remove(svidCachePath)
 }
+
+// workloadSVIDsAAD is authenticated along with the encrypted workload SVIDs
+// so data sealed for another purpose with the same key is not accepted.
+var workloadSVIDsAAD = []byte("spire-agent workload SVIDs v1")
+
+// workloadSVIDs is the plaintext representation of the workload SVID cache.
+type workloadSVIDs struct {
+	// Bundles holds the marshaled bundles, keyed by trust domain.
+	Bundles map[string][]byte `json:"bundles"`
+
+	// Entries holds the marshaled registration entries and their X509-SVIDs.
+	Entries []workloadSVIDEntry `json:"entries"`
+}
+
+type workloadSVIDEntry struct {
+	Entry      []byte   `json:"entry"`
+	CertChain  [][]byte `json:"cert_chain,omitempty"`
+	PrivateKey []byte   `json:"private_key,omitempty"`
+}
+
+// MarshalWorkloadSVIDs marshals the bundles, registration entries and
+// X509-SVIDs of a cache snapshot so they can be stored with StoreWorkloadSVIDs.
+func MarshalWorkloadSVIDs(entries *cache.UpdateEntries, svids *cache.UpdateSVIDs) ([]byte, error) {
+	out := workloadSVIDs{
+		Bundles: make(map[string][]byte, len(entries.Bundles)),
+	}
+	for td, bundle := range entries.Bundles {
+		data, err := proto.Marshal(bundle.Proto())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bundle for %q: %w", td, err)
+		}
+		out.Bundles[td.String()] = data
+	}
+
+	for id, entry := range entries.RegistrationEntries {
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry %q: %w", id, err)
+		}
+		cached := workloadSVIDEntry{Entry: data}
+		if svid, ok := svids.X509SVIDs[id]; ok {
+			key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal private key for entry %q: %w", id, err)
+			}
+			cached.CertChain = x509util.RawCertsFromCertificates(svid.Chain)
+			cached.PrivateKey = key
+		}
+		out.Entries = append(out.Entries, cached)
+	}
+
+	// Keep the output stable so unchanged snapshots marshal identically
+	sort.Slice(out.Entries, func(i, j int) bool {
+		return bytes.Compare(out.Entries[i].Entry, out.Entries[j].Entry) < 0
+	})
+
+	return json.Marshal(out)
+}
+
+// ReadWorkloadSVIDs decrypts with key the bundles, registration entries and
+// X509-SVIDs stored at workloadSVIDsPath, and returns them as the updates that
+// restore them into the cache. Expired X509-SVIDs are dropped.
+func ReadWorkloadSVIDs(workloadSVIDsPath string, key []byte, now time.Time) (*cache.UpdateEntries, *cache.UpdateSVIDs, error) {
+	sealed, err := os.ReadFile(workloadSVIDsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotCached
+		}
+		return nil, nil, fmt.Errorf("error reading workload SVIDs at %s: %w", workloadSVIDsPath, err)
+	}
+
+	data, err := openWorkloadSVIDs(key, sealed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decrypting workload SVIDs at %s: %w", workloadSVIDsPath, err)
+	}
+
+	var in workloadSVIDs
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, nil, fmt.Errorf("error parsing workload SVIDs at %s: %w", workloadSVIDsPath, err)
+	}
+
+	entries := &cache.UpdateEntries{
+		Bundles:             make(map[spiffeid.TrustDomain]*bundleutil.Bundle, len(in.Bundles)),
+		RegistrationEntries: make(map[string]*common.RegistrationEntry, len(in.Entries)),
+	}
+	for _, data := range in.Bundles {
+		bundleProto := new(common.Bundle)
+		if err := proto.Unmarshal(data, bundleProto); err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached bundle: %w", err)
+		}
+		bundle, err := bundleutil.BundleFromProto(bundleProto)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached bundle: %w", err)
+		}
+		td, err := spiffeid.TrustDomainFromString(bundle.TrustDomainID())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached bundle: %w", err)
+		}
+		entries.Bundles[td] = bundle
+	}
+
+	svids := &cache.UpdateSVIDs{
+		X509SVIDs: make(map[string]*cache.X509SVID),
+	}
+	for _, cached := range in.Entries {
+		entry := new(common.RegistrationEntry)
+		if err := proto.Unmarshal(cached.Entry, entry); err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached entry: %w", err)
+		}
+		entries.RegistrationEntries[entry.EntryId] = entry
+
+		if len(cached.CertChain) == 0 {
+			continue
+		}
+		chain, err := x509util.RawCertsToCertificates(cached.CertChain)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached X509-SVID for entry %q: %w", entry.EntryId, err)
+		}
+		if !now.Before(chain[0].NotAfter) {
+			continue
+		}
+		key, err := x509.ParsePKCS8PrivateKey(cached.PrivateKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing cached private key for entry %q: %w", entry.EntryId, err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("cached private key for entry %q is not a signer", entry.EntryId)
+		}
+		svids.X509SVIDs[entry.EntryId] = &cache.X509SVID{
+			Chain:      chain,
+			PrivateKey: signer,
+		}
+	}
+
+	return entries, svids, nil
+}
+
+// StoreWorkloadSVIDs encrypts the marshaled workload SVIDs with key and
+// writes them to disk into workloadSVIDsPath.
+func StoreWorkloadSVIDs(workloadSVIDsPath string, key, data []byte) error {
+	sealed, err := sealWorkloadSVIDs(key, data)
+	if err != nil {
+		return fmt.Errorf("error encrypting workload SVIDs: %w", err)
+	}
+	return diskutil.AtomicWriteFile(workloadSVIDsPath, sealed, 0600)
+}
+
+// sealWorkloadSVIDs encrypts data with AES-GCM. The random nonce is prepended
+// to the ciphertext.
+func sealWorkloadSVIDs(key, data []byte) ([]byte, error) {
+	aead, err := newWorkloadSVIDsAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, workloadSVIDsAAD), nil
+}
+
+// openWorkloadSVIDs decrypts and authenticates data sealed by
+// sealWorkloadSVIDs.
+func openWorkloadSVIDs(key, sealed []byte) ([]byte, error) {
+	aead, err := newWorkloadSVIDsAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("data is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, workloadSVIDsAAD)
+}
+
+func newWorkloadSVIDsAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -2,15 +2,16 @@ package manager
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
+	"crypto"
 	"crypto/x509"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/agent/rotationevents"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/rotationutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -20,6 +21,10 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
+// defaultX509SVIDSigningWorkers is the default number of batches of CSRs
+// submitted to the server concurrently.
+const defaultX509SVIDSigningWorkers = 4
+
 type csrRequest struct {
 	EntryID              string
 	SpiffeID             string
@@ -40,6 +45,8 @@ type Cache interface {
 // synchronize fetches the authorized entries from the server, updates the
 // cache, and fetches missing/expiring SVIDs.
 func (m *manager) synchronize(ctx context.Context) (err error) {
+	m.nextRotation = time.Time{}
+
 	cacheUpdate, storeUpdate, err := m.fetchEntries(ctx)
 	if err != nil {
 		return err
@@ -48,6 +55,7 @@ func (m *manager) synchronize(ctx context.Context) (err error) {
 	if err := m.updateCache(ctx, cacheUpdate, m.c.Log.WithField(telemetry.CacheType, "workload"), "", m.cache); err != nil {
 		return err
 	}
+	telemetry_agent.SetCacheManagerX509SVIDsGauge(m.c.Metrics, m.cache.CountSVIDs())
 
 	if err := m.updateCache(ctx, storeUpdate, m.c.Log.WithField(telemetry.CacheType, "svid_store"), "svid_store", m.svidStoreCache); err != nil {
 		return err
@@ -55,6 +63,7 @@ func (m *manager) synchronize(ctx context.Context) (err error) {
 
 	// Set last success sync
 	m.setLastSync()
+	m.storeWorkloadSVIDs()
 	return nil
 }
 
@@ -83,12 +92,15 @@ func (m *manager) updateCache(ctx context.Context, update *cache.UpdateEntries,
 			outdated++
 		default:
 			// SVID is good
+			m.trackRotation(svid)
 			return false
 		}
 
 		return true
 	})
 
+	telemetry_agent.SetCacheManagerEntriesGauge(m.c.Metrics, cacheType, len(update.RegistrationEntries))
+
 	// TODO: this values are not real, we may remove
 	if expiring > 0 {
 		telemetry_agent.AddCacheManagerExpiredSVIDsSample(m.c.Metrics, cacheType, float32(expiring))
@@ -106,11 +118,6 @@ func (m *manager) updateCache(ctx context.Context, update *cache.UpdateEntries,
 			telemetry.Limit: limits.SignLimitPerIP,
 		}).Debug("Renewing stale entries")
 		for _, staleEntry := range staleEntries {
-			// we've exceeded the CSR limit, don't make any more CSRs
-			if len(csrs) >= limits.SignLimitPerIP {
-				break
-			}
-
 			csrs = append(csrs, csrRequest{
 				EntryID:              staleEntry.Entry.EntryId,
 				SpiffeID:             staleEntry.Entry.SpiffeId,
@@ -118,44 +125,160 @@ func (m *manager) updateCache(ctx context.Context, update *cache.UpdateEntries,
 			})
 		}
 
+		// The SVIDs of the batches that succeeded are cached even if another
+		// batch failed, so they are not signed again on the next sync.
 		update, err := m.fetchSVIDs(ctx, csrs)
-		if err != nil {
-			return err
+		telemetry_agent.IncrCacheManagerRenewedX509SVIDsCounter(m.c.Metrics, cacheType, len(update.X509SVIDs))
+		for _, svid := range update.X509SVIDs {
+			m.trackRotation(svid)
 		}
 		// the values in `update` now belong to the cache. DO NOT MODIFY.
 		c.UpdateSVIDs(update)
+		if cacheType == "" {
+			m.publishRotationEvents(csrs, update)
+		}
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// publishRotationEvents publishes an event for each of the workload
+// X509-SVIDs in the update, if rotation events are enabled.
+func (m *manager) publishRotationEvents(csrs []csrRequest, update *cache.UpdateSVIDs) {
+	if m.c.RotationEvents == nil || len(update.X509SVIDs) == 0 {
+		return
+	}
+
+	events := make([]rotationevents.Event, 0, len(update.X509SVIDs))
+	for _, csr := range csrs {
+		svid, ok := update.X509SVIDs[csr.EntryID]
+		if !ok || len(svid.Chain) == 0 {
+			continue
+		}
+		event := rotationevents.Event{
+			EntryID:   csr.EntryID,
+			SPIFFEID:  csr.SpiffeID,
+			ExpiresAt: svid.Chain[0].NotAfter.Unix(),
+		}
+		if !csr.CurrentSVIDExpiresAt.IsZero() {
+			event.PreviousExpiresAt = csr.CurrentSVIDExpiresAt.Unix()
+		}
+		events = append(events, event)
+	}
+	m.c.RotationEvents.Publish(events...)
+}
+
+// trackRotation records when the given X509-SVID is due for rotation if it
+// is earlier than any other cached X509-SVID.
+func (m *manager) trackRotation(svid *cache.X509SVID) {
+	if len(svid.Chain) == 0 {
+		return
+	}
+	rotationTime := rotationutil.X509RotationTime(svid.Chain[0])
+	if m.nextRotation.IsZero() || rotationTime.Before(m.nextRotation) {
+		m.nextRotation = rotationTime
+	}
+}
+
+// fetchSVIDs signs X509-SVIDs for the given CSR requests. The requests are
+// split in batches that fit within the server signing limit, which are
+// processed concurrently by a bounded pool of workers, so the keys are
+// generated and the CSRs submitted in parallel. The SVIDs of the batches that
+// succeeded are always returned, along with the first error encountered.
 func (m *manager) fetchSVIDs(ctx context.Context, csrs []csrRequest) (_ *cache.UpdateSVIDs, err error) {
-	// Put all the CSRs in an array to make just one call with all the CSRs.
 	counter := telemetry_agent.StartManagerFetchSVIDsUpdatesCall(m.c.Metrics)
 	defer counter.Done(&err)
 
-	csrsIn := make(map[string][]byte)
+	batches := m.batchCSRRequests(csrs, limits.SignLimitPerIP)
+
+	workers := m.c.X509SVIDSigningWorkers
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+
+	update := &cache.UpdateSVIDs{
+		X509SVIDs: make(map[string]*cache.X509SVID, len(csrs)),
+	}
+
+	var firstErr error
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	batchCh := make(chan []csrRequest)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchCh {
+				svids, batchErr := m.fetchSVIDsBatch(ctx, batch)
+
+				mtx.Lock()
+				for entryID, svid := range svids {
+					update.X509SVIDs[entryID] = svid
+				}
+				if batchErr != nil && firstErr == nil {
+					firstErr = batchErr
+				}
+				mtx.Unlock()
+			}
+		}()
+	}
+	for _, batch := range batches {
+		batchCh <- batch
+	}
+	close(batchCh)
+	wg.Wait()
+
+	return update, firstErr
+}
+
+// batchCSRRequests splits the CSR requests in batches of at most batchSize
+// requests. Requests for an entry that was already seen are dropped.
+func (m *manager) batchCSRRequests(csrs []csrRequest, batchSize int) [][]csrRequest {
+	var batches [][]csrRequest
+	var batch []csrRequest
+	seen := make(map[string]struct{}, len(csrs))
+	for _, csr := range csrs {
+		// Since entryIDs are unique, this shouldn't happen. Log just in case
+		if _, ok := seen[csr.EntryID]; ok {
+			m.c.Log.WithField("spiffe_id", csr.SpiffeID).Warnf("Ignoring duplicate X509-SVID renewal for entry ID: %q", csr.EntryID)
+			continue
+		}
+		seen[csr.EntryID] = struct{}{}
+
+		batch = append(batch, csr)
+		if len(batch) == batchSize {
+			batches = append(batches, batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// fetchSVIDsBatch generates the keys and CSRs for a batch of CSR requests and
+// submits them to the server in a single call.
+func (m *manager) fetchSVIDsBatch(ctx context.Context, csrs []csrRequest) (map[string]*cache.X509SVID, error) {
+	csrsIn := make(map[string][]byte, len(csrs))
 
-	privateKeys := make(map[string]*ecdsa.PrivateKey, len(csrs))
+	privateKeys := make(map[string]crypto.Signer, len(csrs))
 	for _, csr := range csrs {
 		log := m.c.Log.WithField("spiffe_id", csr.SpiffeID)
 		if !csr.CurrentSVIDExpiresAt.IsZero() {
 			log = log.WithField("expires_at", csr.CurrentSVIDExpiresAt.Format(time.RFC3339))
 		}
 
-		// Since entryIDs are unique, this shouldn't happen. Log just in case
-		if _, ok := privateKeys[csr.EntryID]; ok {
-			log.Warnf("Ignoring duplicate X509-SVID renewal for entry ID: %q", csr.EntryID)
-			continue
-		}
-
 		log.Info("Renewing X509-SVID")
 
 		spiffeID, err := spiffeid.FromString(csr.SpiffeID)
 		if err != nil {
 			return nil, err
 		}
-		privateKey, csrBytes, err := newCSR(spiffeID)
+		privateKey, csrBytes, err := newCSR(spiffeID, m.c.WorkloadKeyType, m.c.WorkloadDNSNames[spiffeID])
 		if err != nil {
 			return nil, err
 		}
@@ -184,9 +307,7 @@ func (m *manager) fetchSVIDs(ctx context.Context, csrs []csrRequest) (_ *cache.U
 		}
 	}
 
-	return &cache.UpdateSVIDs{
-		X509SVIDs: byEntryID,
-	}, nil
+	return byEntryID, nil
 }
 
 // fetchEntries fetches entries that the agent is entitled to, divided in lists, one for regular entries and
@@ -201,6 +322,11 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 		return nil, nil, err
 	}
 
+	if update.RenewAgentSVID {
+		m.c.Log.Info("Server requested the agent SVID to be renewed")
+		m.svid.RequestRotation()
+	}
+
 	bundles, err := parseBundles(update.Bundles)
 	if err != nil {
 		return nil, nil, err
@@ -227,12 +353,12 @@ func (m *manager) fetchEntries(ctx context.Context) (_ *cache.UpdateEntries, _ *
 		}, nil
 }
 
-func newCSR(spiffeID spiffeid.ID) (pk *ecdsa.PrivateKey, csr []byte, err error) {
-	pk, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+func newCSR(spiffeID spiffeid.ID, keyType keymanager.KeyType, dnsNames []string) (pk crypto.Signer, csr []byte, err error) {
+	pk, err = keyType.GenerateSigner()
 	if err != nil {
 		return
 	}
-	csr, err = util.MakeCSR(pk, spiffeID)
+	csr, err = util.MakeCSRWithDNSNames(pk, spiffeID, dnsNames)
 	if err != nil {
 		return nil, nil, err
 	}
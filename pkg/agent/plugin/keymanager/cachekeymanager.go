@@ -0,0 +1,68 @@
+package keymanager
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	cacheKeyID = "workload-svids-cache"
+
+	// cacheKeyLabel is signed by the cache key to derive the encryption key.
+	cacheKeyLabel = "spire-agent workload SVIDs cache encryption key"
+)
+
+// CacheKeyManager is a wrapper around the key manager specifically used for
+// deriving the key that encrypts the workload SVIDs persisted by the agent.
+type CacheKeyManager interface {
+	// EncryptionKey returns a 256-bit symmetric key derived from a key held
+	// by the key manager. The same key is returned for as long as the key
+	// manager holds the underlying key.
+	EncryptionKey(ctx context.Context) ([]byte, error)
+}
+
+// Returns a CacheKeyManager over the given KeyManager
+func ForCache(km KeyManager) CacheKeyManager {
+	return cacheKeyManager{km: km}
+}
+
+type cacheKeyManager struct {
+	km KeyManager
+}
+
+func (c cacheKeyManager) EncryptionKey(ctx context.Context) ([]byte, error) {
+	key, err := c.km.GetKey(ctx, cacheKeyID)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		key, err = c.km.GenerateKey(ctx, cacheKeyID, RSA2048)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate cache key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unable to get cache key: %w", err)
+	}
+
+	// RSA PKCS #1 v1.5 signatures are deterministic, so signing a fixed label
+	// yields the same secret every time without the key leaving the key
+	// manager.
+	if _, ok := key.Public().(*rsa.PublicKey); !ok {
+		return nil, errors.New("cache key is not an RSA key")
+	}
+	digest := sha256.Sum256([]byte(cacheKeyLabel))
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign with cache key: %w", err)
+	}
+
+	encryptionKey := sha256.Sum256(signature)
+	return encryptionKey[:], nil
+}
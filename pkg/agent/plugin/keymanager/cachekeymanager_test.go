@@ -0,0 +1,45 @@
+package keymanager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/agent/plugin/keymanager"
+	"github.com/spiffe/spire/test/fakes/fakeagentkeymanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKeyManager(t *testing.T) {
+	km := fakeagentkeymanager.New(t, "")
+
+	cacheKM := keymanager.ForCache(km)
+
+	// Derive the key (generates the underlying key)
+	keyA, err := cacheKM.EncryptionKey(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, keyA, 32)
+
+	keys, err := km.GetKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "workload-svids-cache", keys[0].ID())
+
+	// Derive the key again (reuses the underlying key)
+	keyB, err := cacheKM.EncryptionKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, keyA, keyB)
+
+	// Regenerating the underlying key changes the derived key
+	_, err = km.GenerateKey(context.Background(), "workload-svids-cache", keymanager.RSA2048)
+	require.NoError(t, err)
+	keyC, err := cacheKM.EncryptionKey(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, keyA, keyC)
+
+	// Keys that are not RSA cannot be used
+	_, err = km.GenerateKey(context.Background(), "workload-svids-cache", keymanager.ECP256)
+	require.NoError(t, err)
+	_, err = cacheKM.EncryptionKey(context.Background())
+	require.EqualError(t, err, "cache key is not an RSA key")
+}
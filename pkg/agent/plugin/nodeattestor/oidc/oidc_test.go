@@ -0,0 +1,63 @@
+package oidc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
+	nodeattestortest "github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/test"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	streamBuilder = nodeattestortest.ServerStream(oidc.PluginName)
+)
+
+func TestAidAttestation(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("TOKEN\n"), 0600))
+
+	t.Run("not configured", func(t *testing.T) {
+		attestor := loadAttestor(t)
+		err := attestor.Attest(context.Background(), streamBuilder.Build())
+		spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "nodeattestor(oidc): not configured")
+	})
+
+	t.Run("token does not exist", func(t *testing.T) {
+		missingPath := filepath.Join(dir, "missing")
+		attestor := loadAttestor(t, plugintest.Configure(`token_path = "`+missingPath+`"`))
+		err := attestor.Attest(context.Background(), streamBuilder.Build())
+		spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "nodeattestor(oidc): unable to load token from "+missingPath)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		attestor := loadAttestor(t, plugintest.Configure(`token_path = "`+tokenPath+`"`))
+		err := attestor.Attest(context.Background(), streamBuilder.ExpectAndBuild([]byte(`{"token":"TOKEN"}`)))
+		require.NoError(t, err)
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	var err error
+	loadAttestor(t, plugintest.CaptureConfigureError(&err), plugintest.Configure("blah"))
+	spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "unable to decode configuration")
+
+	loadAttestor(t, plugintest.CaptureConfigureError(&err), plugintest.Configure(""))
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "token_path is required")
+
+	loadAttestor(t, plugintest.CaptureConfigureError(&err), plugintest.Configure(`token_path = "token"`))
+	require.NoError(t, err)
+}
+
+func loadAttestor(t *testing.T, options ...plugintest.Option) nodeattestor.NodeAttestor {
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), attestor, options...)
+	return attestor
+}
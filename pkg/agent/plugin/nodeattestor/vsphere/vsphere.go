@@ -0,0 +1,125 @@
+package vsphere
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/vsphere"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRPCToolPath = "vmware-rpctool"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(vsphere.PluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// GuestInfoKey is the guestinfo variable the identity document is read
+	// from.
+	GuestInfoKey string `hcl:"guestinfo_key"`
+
+	// RPCToolPath is the path to the VMware Tools vmware-rpctool binary,
+	// used to read the guestinfo variable.
+	RPCToolPath string `hcl:"rpctool_path"`
+}
+
+// Plugin implements node attestation for agents running in vSphere VMs. It
+// sends the identity document stored in the guestinfo of the VM.
+type Plugin struct {
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	mu     sync.RWMutex
+	config *Config
+
+	hooks struct {
+		getGuestInfo func(ctx context.Context, rpcToolPath, key string) (string, error)
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.getGuestInfo = getGuestInfo
+	return p
+}
+
+func (p *Plugin) AidAttestation(stream nodeattestorv1.NodeAttestor_AidAttestationServer) error {
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	document, err := p.hooks.getGuestInfo(stream.Context(), config.RPCToolPath, config.GuestInfoKey)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to read identity document from guestinfo: %v", err)
+	}
+	if document == "" {
+		return status.Errorf(codes.Internal, "no identity document in guestinfo %q", config.GuestInfoKey)
+	}
+
+	return stream.Send(&nodeattestorv1.PayloadOrChallengeResponse{
+		Data: &nodeattestorv1.PayloadOrChallengeResponse_Payload{
+			Payload: []byte(document),
+		},
+	})
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+
+	if config.GuestInfoKey == "" {
+		config.GuestInfoKey = vsphere.DefaultGuestInfoKey
+	}
+	if !strings.HasPrefix(config.GuestInfoKey, "guestinfo.") {
+		return nil, status.Errorf(codes.InvalidArgument, "guestinfo_key %q must start with \"guestinfo.\"", config.GuestInfoKey)
+	}
+	if config.RPCToolPath == "" {
+		config.RPCToolPath = defaultRPCToolPath
+	}
+
+	p.setConfig(config)
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*Config, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) setConfig(config *Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+}
+
+func getGuestInfo(ctx context.Context, rpcToolPath, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, rpcToolPath, "info-get "+key).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
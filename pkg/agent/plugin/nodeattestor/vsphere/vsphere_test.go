@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor"
+	nodeattestortest "github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/test"
+	"github.com/spiffe/spire/pkg/common/plugin/vsphere"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	streamBuilder = nodeattestortest.ServerStream(vsphere.PluginName)
+)
+
+func TestAidAttestation(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		config            string
+		document          string
+		documentErr       error
+		expectRPCToolPath string
+		expectKey         string
+		expectCode        codes.Code
+		expectMsg         string
+	}{
+		{
+			name:              "guestinfo lookup fails",
+			documentErr:       errors.New("oh no"),
+			expectRPCToolPath: defaultRPCToolPath,
+			expectKey:         vsphere.DefaultGuestInfoKey,
+			expectCode:        codes.Internal,
+			expectMsg:         "nodeattestor(vsphere): unable to read identity document from guestinfo: oh no",
+		},
+		{
+			name:              "empty guestinfo",
+			expectRPCToolPath: defaultRPCToolPath,
+			expectKey:         vsphere.DefaultGuestInfoKey,
+			expectCode:        codes.Internal,
+			expectMsg:         `nodeattestor(vsphere): no identity document in guestinfo "guestinfo.spire.identity"`,
+		},
+		{
+			name:              "success",
+			document:          "DOCUMENT",
+			expectRPCToolPath: defaultRPCToolPath,
+			expectKey:         vsphere.DefaultGuestInfoKey,
+		},
+		{
+			name: "success with custom guestinfo key and rpctool path",
+			config: `
+				guestinfo_key = "guestinfo.custom"
+				rpctool_path = "/usr/bin/vmware-rpctool"
+			`,
+			document:          "DOCUMENT",
+			expectRPCToolPath: "/usr/bin/vmware-rpctool",
+			expectKey:         "guestinfo.custom",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.hooks.getGuestInfo = func(ctx context.Context, rpcToolPath, key string) (string, error) {
+				require.Equal(t, tt.expectRPCToolPath, rpcToolPath)
+				require.Equal(t, tt.expectKey, key)
+				return tt.document, tt.documentErr
+			}
+
+			attestor := new(nodeattestor.V1)
+			plugintest.Load(t, builtin(p), attestor, plugintest.Configure(tt.config))
+
+			stream := streamBuilder.Build()
+			if tt.expectCode == codes.OK {
+				stream = streamBuilder.ExpectAndBuild([]byte(tt.document))
+			}
+			err := attestor.Attest(context.Background(), stream)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAidAttestationNotConfigured(t *testing.T) {
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), attestor)
+
+	err := attestor.Attest(context.Background(), streamBuilder.Build())
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "nodeattestor(vsphere): not configured")
+}
+
+func TestConfigure(t *testing.T) {
+	var err error
+	plugintest.Load(t, BuiltIn(), nil, plugintest.CaptureConfigureError(&err), plugintest.Configure("blah"))
+	spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "unable to decode configuration")
+
+	plugintest.Load(t, BuiltIn(), nil, plugintest.CaptureConfigureError(&err), plugintest.Configure(`guestinfo_key = "spire.identity"`))
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, `guestinfo_key "spire.identity" must start with "guestinfo."`)
+
+	plugintest.Load(t, BuiltIn(), nil, plugintest.CaptureConfigureError(&err), plugintest.Configure(""))
+	require.NoError(t, err)
+}
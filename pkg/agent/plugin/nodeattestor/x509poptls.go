@@ -0,0 +1,41 @@
+package nodeattestor
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/plugin"
+	"google.golang.org/grpc/codes"
+)
+
+// X509PoPTLS returns a node attestor that attests using the externally
+// issued certificate presented as the client certificate during the TLS
+// handshake with the server. The handshake proves possession of the private
+// key so the server does not issue a challenge. The attestation payload is
+// the DER encoded leaf certificate.
+func X509PoPTLS(log logrus.FieldLogger, leaf []byte) NodeAttestor {
+	return x509PoPTLS{
+		Facade: plugin.FixedFacade("x509pop_tls", "NodeAttestor", log),
+		leaf:   leaf,
+	}
+}
+
+type x509PoPTLS struct {
+	plugin.Facade
+	leaf []byte
+}
+
+func (plugin x509PoPTLS) Attest(ctx context.Context, serverStream ServerStream) error {
+	challenge, err := serverStream.SendAttestationData(ctx, AttestationData{
+		Type:    plugin.Name(),
+		Payload: plugin.leaf,
+	})
+	switch {
+	case err != nil:
+		return err
+	case challenge != nil:
+		return plugin.Error(codes.Internal, "server issued unexpected challenge")
+	default:
+		return nil
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
@@ -114,6 +115,11 @@ type HCLConfig struct {
 	// ReloadInterval controls how often TLS and token configuration is loaded
 	// from the disk.
 	ReloadInterval string `hcl:"reload_interval"`
+
+	// PodListCacheTTL controls how long the pod list retrieved from the
+	// kubelet is shared between attestations. If unset, the pod list is
+	// retrieved for every attestation.
+	PodListCacheTTL string `hcl:"pod_list_cache_ttl"`
 }
 
 // k8sConfig holds the configuration distilled from HCL
@@ -129,6 +135,7 @@ type k8sConfig struct {
 	KubeletCAPath           string
 	NodeName                string
 	ReloadInterval          time.Duration
+	PodListCacheTTL         time.Duration
 
 	Client     *kubeletClient
 	LastReload time.Time
@@ -145,6 +152,11 @@ type Plugin struct {
 
 	mu     sync.RWMutex
 	config *k8sConfig
+
+	podListMu    sync.Mutex
+	podList      *corev1.PodList
+	podListTime  time.Time
+	podListGroup singleflight.Group
 }
 
 func New() *Plugin {
@@ -185,25 +197,24 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 	for attempt := 1; ; attempt++ {
 		log = log.With(telemetry.Attempt, attempt)
 
-		list, err := config.Client.GetPodList()
+		list, cached, err := p.getPodList(config, true)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, item := range list.Items {
-			item := item
-			if item.UID != podUID {
-				continue
-			}
-
-			status, lookup := lookUpContainerInPod(containerID, item.Status)
-			switch lookup {
-			case containerInPod:
-				return &workloadattestorv1.AttestResponse{
-					SelectorValues: getSelectorValuesFromPodInfo(&item, status),
-				}, nil
-			case containerNotInPod:
+		selectorValues, ok := lookUpContainerInPodList(podUID, containerID, list)
+		if !ok && cached {
+			// The pod may have been created after the pod list was cached.
+			list, _, err = p.getPodList(config, false)
+			if err != nil {
+				return nil, err
 			}
+			selectorValues, ok = lookUpContainerInPodList(podUID, containerID, list)
+		}
+		if ok {
+			return &workloadattestorv1.AttestResponse{
+				SelectorValues: selectorValues,
+			}, nil
 		}
 
 		// if the container was not located after the maximum number of attempts then the search is over.
@@ -260,6 +271,15 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 		reloadInterval = defaultReloadInterval
 	}
 
+	// Determine pod list cache TTL
+	var podListCacheTTL time.Duration
+	if config.PodListCacheTTL != "" {
+		podListCacheTTL, err = time.ParseDuration(config.PodListCacheTTL)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to parse pod list cache TTL: %v", err)
+		}
+	}
+
 	// Determine which kubelet port to hit. Default to the secure port if none
 	// is specified (this is backwards compatible because the read-only-port
 	// config value has always been required, so it should already be set in
@@ -294,6 +314,7 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 		KubeletCAPath:           config.KubeletCAPath,
 		NodeName:                nodeName,
 		ReloadInterval:          reloadInterval,
+		PodListCacheTTL:         podListCacheTTL,
 	}
 	if err := p.reloadKubeletClient(c); err != nil {
 		return nil, err
@@ -308,6 +329,12 @@ func (p *Plugin) setConfig(config *k8sConfig) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.config = config
+
+	// The cached pod list may have been retrieved with a different kubelet
+	// client configuration.
+	p.podListMu.Lock()
+	defer p.podListMu.Unlock()
+	p.podList = nil
 }
 
 func (p *Plugin) getConfig() (*k8sConfig, error) {
@@ -323,6 +350,42 @@ func (p *Plugin) getConfig() (*k8sConfig, error) {
 	return p.config, nil
 }
 
+// getPodList returns the pod list from the kubelet. If pod list caching is
+// enabled and useCache is true, a pod list cached within the TTL is returned
+// instead, in which case cached is true. Concurrent retrievals of the pod list
+// are coalesced when caching is enabled.
+func (p *Plugin) getPodList(config *k8sConfig, useCache bool) (list *corev1.PodList, cached bool, err error) {
+	if config.PodListCacheTTL <= 0 {
+		list, err = config.Client.GetPodList()
+		return list, false, err
+	}
+
+	if useCache {
+		p.podListMu.Lock()
+		list, listTime := p.podList, p.podListTime
+		p.podListMu.Unlock()
+		if list != nil && p.clock.Now().Sub(listTime) < config.PodListCacheTTL {
+			return list, true, nil
+		}
+	}
+
+	result, err, _ := p.podListGroup.Do("", func() (interface{}, error) {
+		list, err := config.Client.GetPodList()
+		if err != nil {
+			return nil, err
+		}
+		p.podListMu.Lock()
+		defer p.podListMu.Unlock()
+		p.podList = list
+		p.podListTime = p.clock.Now()
+		return list, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return result.(*corev1.PodList), false, nil
+}
+
 func (p *Plugin) getPodUIDAndContainerIDFromCGroups(pid int32) (types.UID, string, error) {
 	cgroups, err := cgroups.GetCgroups(pid, p.fs)
 	if err != nil {
@@ -614,6 +677,25 @@ func canonicalizePodUID(uid string) types.UID {
 	}, uid))
 }
 
+// lookUpContainerInPodList looks up the container in the pod list and returns
+// the selector values for it, if found.
+func lookUpContainerInPodList(podUID types.UID, containerID string, list *corev1.PodList) ([]string, bool) {
+	for _, item := range list.Items {
+		item := item
+		if item.UID != podUID {
+			continue
+		}
+
+		status, lookup := lookUpContainerInPod(containerID, item.Status)
+		switch lookup {
+		case containerInPod:
+			return getSelectorValuesFromPodInfo(&item, status), true
+		case containerNotInPod:
+		}
+	}
+	return nil, false
+}
+
 func lookUpContainerInPod(containerID string, status corev1.PodStatus) (*corev1.ContainerStatus, containerLookup) {
 	for _, status := range status.ContainerStatuses {
 		// TODO: should we be keying off of the status or is the lack of a
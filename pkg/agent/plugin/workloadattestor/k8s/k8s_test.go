@@ -281,6 +281,29 @@ func (s *Suite) TestAttestWithPidNotInPod() {
 	s.Require().Empty(selectors)
 }
 
+func (s *Suite) TestAttestWithCachedPodList() {
+	s.startInsecureKubelet()
+	p := s.loadPlugin(fmt.Sprintf(`
+		kubelet_read_only_port = %d
+		max_poll_attempts = 5
+		poll_retry_interval = "1s"
+		pod_list_cache_ttl = "1m"
+`, s.kubeletPort()))
+
+	// The pod list is retrieved from the kubelet and then served from the
+	// cache.
+	s.requireAttestSuccessWithPod(p)
+	s.requireAttestSuccess(p, testPodSelectors)
+
+	// The pod list is retrieved again when the pod is not in the cached list.
+	s.requireAttestSuccessWithKindPod(p)
+
+	// The cached pod list expires after the TTL. The kubelet is not
+	// configured to return another pod list, so the attestation fails.
+	s.clock.Add(time.Minute)
+	s.requireAttestFailure(p, codes.Internal, "unable to decode kubelet response")
+}
+
 func (s *Suite) TestAttestOverSecurePortViaTokenAuth() {
 	// start up a secure kubelet with host networking and require token auth
 	s.startSecureKubelet(true, "default-token")
@@ -364,6 +387,7 @@ func (s *Suite) TestConfigure() {
 		MaxPollAttempts   int
 		PollRetryInterval time.Duration
 		ReloadInterval    time.Duration
+		PodListCacheTTL   time.Duration
 	}
 
 	testCases := []struct {
@@ -386,6 +410,21 @@ func (s *Suite) TestConfigure() {
 				ReloadInterval:    defaultReloadInterval,
 			},
 		},
+		{
+			name: "pod list cache TTL",
+			hcl: `
+				kubelet_read_only_port = 12345
+				pod_list_cache_ttl = "5s"
+			`,
+			config: &config{
+				Insecure:          true,
+				KubeletURL:        "http://127.0.0.1:12345",
+				MaxPollAttempts:   defaultMaxPollAttempts,
+				PollRetryInterval: defaultPollRetryInterval,
+				ReloadInterval:    defaultReloadInterval,
+				PodListCacheTTL:   5 * time.Second,
+			},
+		},
 		{
 			name: "secure defaults",
 			hcl:  ``,
@@ -512,6 +551,14 @@ func (s *Suite) TestConfigure() {
 			`,
 			err: "unable to parse reload interval",
 		},
+		{
+			name: "invalid pod list cache TTL",
+			hcl: `
+				kubelet_read_only_port = 10255
+				pod_list_cache_ttl = "blah"
+			`,
+			err: "unable to parse pod list cache TTL",
+		},
 		{
 			name: "cert but no key",
 			hcl: `
@@ -610,6 +657,7 @@ func (s *Suite) TestConfigure() {
 			assert.Equal(t, testCase.config.MaxPollAttempts, c.MaxPollAttempts)
 			assert.Equal(t, testCase.config.PollRetryInterval, c.PollRetryInterval)
 			assert.Equal(t, testCase.config.ReloadInterval, c.ReloadInterval)
+			assert.Equal(t, testCase.config.PodListCacheTTL, c.PodListCacheTTL)
 		})
 	}
 }
@@ -0,0 +1,72 @@
+//go:build !windows
+// +build !windows
+
+package unix
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var pythonExeRE = regexp.MustCompile(`^python[0-9.]*$`)
+
+// interpreterEntrypoint returns the script or jar an interpreter process
+// runs, given the path to the interpreter binary and the command line of the
+// process. It returns an empty string if the binary is not a supported
+// interpreter or the process does not run a script or jar, e.g. when code is
+// passed on the command line.
+func interpreterEntrypoint(exePath string, args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	args = args[1:]
+
+	exe := filepath.Base(exePath)
+	switch {
+	case pythonExeRE.MatchString(exe):
+		return firstNonOptionArg(args, []string{"-W", "-X"}, []string{"-c", "-m"})
+	case exe == "node" || exe == "nodejs":
+		return firstNonOptionArg(args, []string{"-r", "--require", "--loader", "--import"}, []string{"-e", "--eval", "-p", "--print"})
+	case exe == "java":
+		for i, arg := range args {
+			if arg == "-jar" && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// firstNonOptionArg returns the first argument that is not an option or the
+// value of an option. Options in withValue consume the following argument.
+// If an option in noScript is found first, an empty string is returned.
+func firstNonOptionArg(args []string, withValue, noScript []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		case containsString(noScript, arg):
+			return ""
+		case containsString(withValue, arg):
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -39,6 +39,11 @@ type processInfo interface {
 	Groups() ([]string, error)
 	Exe() (string, error)
 	NamespacedExe() string
+	NamespacedPath(path string) string
+	CmdlineSlice() ([]string, error)
+	Cwd() (string, error)
+	SELinuxContext() (string, error)
+	AppArmorProfile() (string, error)
 }
 
 type PSProcessInfo struct {
@@ -49,6 +54,12 @@ func (ps PSProcessInfo) NamespacedExe() string {
 	return getProcPath(ps.Pid, "exe")
 }
 
+// NamespacedPath returns the path to a file in the mount namespace of the
+// process, given the path seen by the process.
+func (ps PSProcessInfo) NamespacedPath(path string) string {
+	return getProcPath(ps.Pid, filepath.Join("root", path))
+}
+
 // Groups returns the supplementary group IDs
 // This is a custom implementation that only works for linux until the next issue is fixed
 // https://github.com/shirou/gopsutil/issues/913
@@ -87,9 +98,45 @@ func (ps PSProcessInfo) Groups() ([]string, error) {
 	return []string{}, nil
 }
 
+// SELinuxContext returns the SELinux context of the process, or an empty
+// string if SELinux is not enabled.
+func (ps PSProcessInfo) SELinuxContext() (string, error) {
+	if _, err := os.Stat("/sys/fs/selinux/enforce"); err != nil {
+		return "", nil
+	}
+	return readProcAttr(ps.Pid, "selinux")
+}
+
+// AppArmorProfile returns the AppArmor profile the process is confined by,
+// including the profile mode (e.g. "nginx (enforce)"), or an empty string
+// if AppArmor is not enabled.
+func (ps PSProcessInfo) AppArmorProfile() (string, error) {
+	enabled, err := os.ReadFile("/sys/module/apparmor/parameters/enabled")
+	if err != nil || strings.TrimSpace(string(enabled)) != "Y" {
+		return "", nil
+	}
+	return readProcAttr(ps.Pid, "apparmor")
+}
+
+// readProcAttr reads the security label of the process for the given LSM.
+// Kernels supporting LSM stacking expose the label of each LSM in its own
+// directory; older kernels only expose the label of the active LSM.
+func readProcAttr(pid int32, lsm string) (string, error) {
+	data, err := os.ReadFile(getProcPath(pid, filepath.Join("attr", lsm, "current")))
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(getProcPath(pid, filepath.Join("attr", "current")))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\x00\n"), nil
+}
+
 type Configuration struct {
-	DiscoverWorkloadPath bool  `hcl:"discover_workload_path"`
-	WorkloadSizeLimit    int64 `hcl:"workload_size_limit"`
+	DiscoverWorkloadPath          bool  `hcl:"discover_workload_path"`
+	WorkloadSizeLimit             int64 `hcl:"workload_size_limit"`
+	DiscoverSecurityContext       bool  `hcl:"discover_security_context"`
+	DiscoverInterpreterEntrypoint bool  `hcl:"discover_interpreter_entrypoint"`
 }
 
 type Plugin struct {
@@ -187,6 +234,27 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 		}
 	}
 
+	// the entrypoint of interpreter processes is behind a config flag for
+	// the same reason as the workload path.
+	if config.DiscoverInterpreterEntrypoint {
+		entrypointValues, err := p.getEntrypointSelectorValues(proc, config.WorkloadSizeLimit)
+		if err != nil {
+			return nil, err
+		}
+		selectorValues = append(selectorValues, entrypointValues...)
+	}
+
+	// the SELinux context and AppArmor profile are behind a config flag
+	// since reading them can require permissions that might not be
+	// available.
+	if config.DiscoverSecurityContext {
+		securityValues, err := p.getSecurityContextSelectorValues(proc)
+		if err != nil {
+			return nil, err
+		}
+		selectorValues = append(selectorValues, securityValues...)
+	}
+
 	return &workloadattestorv1.AttestResponse{
 		SelectorValues: selectorValues,
 	}, nil
@@ -280,6 +348,75 @@ func (p *Plugin) getNamespacedPath(proc processInfo) string {
 	return proc.NamespacedExe()
 }
 
+func (p *Plugin) getEntrypointSelectorValues(proc processInfo, sizeLimit int64) ([]string, error) {
+	processPath, err := p.getPath(proc)
+	if err != nil {
+		return nil, err
+	}
+	args, err := proc.CmdlineSlice()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "command line lookup: %v", err)
+	}
+
+	entrypoint := interpreterEntrypoint(processPath, args)
+	if entrypoint == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(entrypoint) {
+		cwd, err := proc.Cwd()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "working directory lookup: %v", err)
+		}
+		entrypoint = filepath.Join(cwd, entrypoint)
+	}
+	entrypoint = filepath.Clean(entrypoint)
+
+	selectorValues := []string{makeSelectorValue("entrypoint_path", entrypoint)}
+	if sizeLimit >= 0 {
+		sha256Digest, err := util.GetSHA256Digest(proc.NamespacedPath(entrypoint), sizeLimit)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		selectorValues = append(selectorValues, makeSelectorValue("entrypoint_sha256", sha256Digest))
+	}
+	return selectorValues, nil
+}
+
+func (p *Plugin) getSecurityContextSelectorValues(proc processInfo) ([]string, error) {
+	var selectorValues []string
+
+	selinuxContext, err := proc.SELinuxContext()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "SELinux context lookup: %v", err)
+	}
+	if selinuxContext != "" {
+		selectorValues = append(selectorValues, makeSelectorValue("selinux_context", selinuxContext))
+		// The context is formatted as user:role:type:level
+		if parts := strings.SplitN(selinuxContext, ":", 4); len(parts) >= 3 {
+			selectorValues = append(selectorValues, makeSelectorValue("selinux_type", parts[2]))
+		}
+	}
+
+	appArmorProfile, err := proc.AppArmorProfile()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "AppArmor profile lookup: %v", err)
+	}
+	if appArmorProfile != "" {
+		// Confined processes have the profile mode appended to the profile
+		// name, e.g. "nginx (enforce)"
+		profile, mode := appArmorProfile, ""
+		if i := strings.LastIndex(appArmorProfile, " ("); i > 0 && strings.HasSuffix(appArmorProfile, ")") {
+			profile, mode = appArmorProfile[:i], appArmorProfile[i+2:len(appArmorProfile)-1]
+		}
+		selectorValues = append(selectorValues, makeSelectorValue("apparmor_profile", profile))
+		if mode != "" {
+			selectorValues = append(selectorValues, makeSelectorValue("apparmor_mode", mode))
+		}
+	}
+
+	return selectorValues, nil
+}
+
 func makeSelectorValue(kind, value string) string {
 	return fmt.Sprintf("%s:%s", kind, value)
 }
@@ -227,10 +227,123 @@ func (s *Suite) TestAttest() {
 			expectCode: codes.Internal,
 			expectMsg:  "workloadattestor(unix): supplementary GIDs lookup: some error for PID 14",
 		},
+		{
+			name:   "SELinux context",
+			pid:    15,
+			config: "discover_security_context = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"selinux_context:system_u:system_r:httpd_t:s0",
+				"selinux_type:httpd_t",
+			},
+		},
+		{
+			name:   "AppArmor profile",
+			pid:    16,
+			config: "discover_security_context = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"apparmor_profile:nginx",
+				"apparmor_mode:enforce",
+			},
+		},
+		{
+			name:   "unconfined AppArmor profile",
+			pid:    17,
+			config: "discover_security_context = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"apparmor_profile:unconfined",
+			},
+		},
+		{
+			name: "security context not discovered",
+			pid:  15,
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+			},
+		},
+		{
+			name:       "fail to get SELinux context",
+			pid:        18,
+			config:     "discover_security_context = true",
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(unix): SELinux context lookup: unable to get SELinux context for PID 18",
+		},
+		{
+			name:       "fail to get AppArmor profile",
+			pid:        19,
+			config:     "discover_security_context = true",
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(unix): AppArmor profile lookup: unable to get AppArmor profile for PID 19",
+		},
+		{
+			name:   "interpreter entrypoint",
+			pid:    20,
+			config: "discover_interpreter_entrypoint = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"entrypoint_path:/srv/app/main.py",
+				"entrypoint_sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7",
+			},
+		},
+		{
+			name:   "interpreter entrypoint, disabled hashing",
+			pid:    20,
+			config: "discover_interpreter_entrypoint = true\nworkload_size_limit = -1",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+				"entrypoint_path:/srv/app/main.py",
+			},
+		},
+		{
+			name:   "not an interpreter",
+			pid:    12,
+			config: "discover_interpreter_entrypoint = true",
+			selectorValues: []string{
+				"uid:1000",
+				"user:u1000",
+				"gid:2000",
+				"group:g2000",
+			},
+		},
+		{
+			name:       "fail to hash interpreter entrypoint",
+			pid:        21,
+			config:     "discover_interpreter_entrypoint = true",
+			expectCode: codes.Internal,
+			expectMsg:  fmt.Sprintf("workloadattestor(unix): SHA256 digest: open %s: no such file or directory", filepath.Join(s.dir, "missing.jar")),
+		},
+		{
+			name:       "fail to get command line",
+			pid:        22,
+			config:     "discover_interpreter_entrypoint = true",
+			expectCode: codes.Internal,
+			expectMsg:  "workloadattestor(unix): command line lookup: unable to get command line for PID 22",
+		},
 	}
 
-	// prepare the "exe" for hashing
+	// prepare the "exe" and the interpreter entrypoint for hashing
 	s.writeFile("exe", []byte("data"))
+	s.writeFile("main.py", []byte("data"))
 
 	for _, testCase := range testCases {
 		testCase := testCase
@@ -259,6 +372,31 @@ func (s *Suite) TestAttest() {
 	}
 }
 
+func TestInterpreterEntrypoint(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		exe        string
+		args       []string
+		entrypoint string
+	}{
+		{name: "python script", exe: "/usr/bin/python3.10", args: []string{"python3", "-u", "-W", "ignore", "/srv/app.py", "-v"}, entrypoint: "/srv/app.py"},
+		{name: "python module", exe: "/usr/bin/python3", args: []string{"python3", "-m", "http.server"}},
+		{name: "python command", exe: "/usr/bin/python", args: []string{"python", "-c", "print(1)"}},
+		{name: "python interactive", exe: "/usr/bin/python", args: []string{"python"}},
+		{name: "node script", exe: "/usr/local/bin/node", args: []string{"node", "-r", "dotenv/config", "server.js"}, entrypoint: "server.js"},
+		{name: "node eval", exe: "/usr/local/bin/node", args: []string{"node", "-e", "console.log(1)"}},
+		{name: "java jar", exe: "/usr/bin/java", args: []string{"java", "-cp", "lib", "-jar", "app.jar"}, entrypoint: "app.jar"},
+		{name: "java class", exe: "/usr/bin/java", args: []string{"java", "-cp", "lib", "com.example.Main"}},
+		{name: "not an interpreter", exe: "/usr/bin/nginx", args: []string{"nginx", "-c", "/etc/nginx.conf"}},
+		{name: "no command line", exe: "/usr/bin/python"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.entrypoint, interpreterEntrypoint(tt.exe, tt.args))
+		})
+	}
+}
+
 func (s *Suite) writeFile(path string, data []byte) {
 	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, path), data, 0600))
 }
@@ -296,7 +434,7 @@ func (p fakeProcess) Uids() ([]int32, error) {
 		return nil, fmt.Errorf("unable to get UIDs for PID %d", p.pid)
 	case 3:
 		return []int32{1999}, nil
-	case 4, 5, 6, 7, 9, 10, 11, 12, 13, 14:
+	case 4, 5, 6, 7, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22:
 		return []int32{1000}, nil
 	case 8:
 		return []int32{1000, 1100}, nil
@@ -313,7 +451,7 @@ func (p fakeProcess) Gids() ([]int32, error) {
 		return nil, fmt.Errorf("unable to get GIDs for PID %d", p.pid)
 	case 6:
 		return []int32{2999}, nil
-	case 3, 7, 9, 10, 11, 12, 13, 14:
+	case 3, 7, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22:
 		return []int32{2000}, nil
 	case 8:
 		return []int32{2000, 2100}, nil
@@ -341,6 +479,10 @@ func (p fakeProcess) Exe() (string, error) {
 		return filepath.Join(p.dir, "unreadable-exe"), nil
 	case 11, 12:
 		return filepath.Join(p.dir, "exe"), nil
+	case 20, 22:
+		return "/usr/bin/python3.10", nil
+	case 21:
+		return "/usr/lib/jvm/bin/java", nil
 	default:
 		return "", fmt.Errorf("unhandled exe test case %d", p.pid)
 	}
@@ -355,6 +497,58 @@ func (p fakeProcess) NamespacedExe() string {
 	}
 }
 
+func (p fakeProcess) NamespacedPath(path string) string {
+	return filepath.Join(p.dir, filepath.Base(path))
+}
+
+func (p fakeProcess) CmdlineSlice() ([]string, error) {
+	switch p.pid {
+	case 12:
+		return []string{filepath.Join(p.dir, "exe")}, nil
+	case 20:
+		return []string{"python3", "-u", "main.py", "--port", "8080"}, nil
+	case 21:
+		return []string{"java", "-Xmx1g", "-jar", "/srv/app/missing.jar"}, nil
+	case 22:
+		return nil, fmt.Errorf("unable to get command line for PID %d", p.pid)
+	default:
+		return nil, fmt.Errorf("unhandled command line test case %d", p.pid)
+	}
+}
+
+func (p fakeProcess) Cwd() (string, error) {
+	switch p.pid {
+	case 20:
+		return "/srv/app", nil
+	default:
+		return "", fmt.Errorf("unhandled cwd test case %d", p.pid)
+	}
+}
+
+func (p fakeProcess) SELinuxContext() (string, error) {
+	switch p.pid {
+	case 15:
+		return "system_u:system_r:httpd_t:s0", nil
+	case 18:
+		return "", fmt.Errorf("unable to get SELinux context for PID %d", p.pid)
+	default:
+		return "", nil
+	}
+}
+
+func (p fakeProcess) AppArmorProfile() (string, error) {
+	switch p.pid {
+	case 16:
+		return "nginx (enforce)", nil
+	case 17:
+		return "unconfined", nil
+	case 19:
+		return "", fmt.Errorf("unable to get AppArmor profile for PID %d", p.pid)
+	default:
+		return "", nil
+	}
+}
+
 func newFakeProcess(pid int32, dir string) processInfo {
 	return fakeProcess{pid: pid, dir: dir}
 }
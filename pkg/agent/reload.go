@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// ReloadableConfig is the portion of the agent configuration that is
+// re-read when the agent is asked to reload its configuration.
+type ReloadableConfig struct {
+	// LogLevel is applied to the running agent logger.
+	LogLevel logrus.Level
+
+	// ServerAddress is the address of the SPIRE server.
+	ServerAddress string
+
+	// BindAddress is the address the Workload API is bound to.
+	BindAddress net.Addr
+
+	// PluginConfigs are the configurations for the agent plugins.
+	PluginConfigs catalog.HCLPluginConfigMap
+}
+
+// levelSetter is implemented by loggers that support changing their level
+// at runtime (e.g. *logrus.Logger).
+type levelSetter interface {
+	SetLevel(logrus.Level)
+}
+
+// pluginReconfigurer reconfigures the loaded plugins.
+type pluginReconfigurer interface {
+	Reconfigure(ctx context.Context, pluginConfig catalog.HCLPluginConfigMap) error
+}
+
+// serverAddrSetter changes the address used to reach the server.
+type serverAddrSetter interface {
+	SetServerAddr(addr string)
+}
+
+// bindAddressSetter moves the Workload API to a new address.
+type bindAddressSetter interface {
+	SetBindAddress(addr net.Addr) error
+}
+
+// reloadTargets are the running components the reloaded configuration is
+// applied to.
+type reloadTargets struct {
+	plugins   pluginReconfigurer
+	manager   serverAddrSetter
+	endpoints bindAddressSetter
+}
+
+// reloadOnSignal returns a task that reloads the agent configuration
+// each time a value is received on signalCh.
+func (a *Agent) reloadOnSignal(signalCh <-chan os.Signal, targets reloadTargets) func(context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-signalCh:
+				rc, err := a.c.ReloadConfig()
+				if err != nil {
+					// Never fail; keep running with the current configuration
+					a.c.Log.WithError(err).Error("Failed to reload configuration; keeping current configuration")
+					continue
+				}
+				a.reload(ctx, rc, targets)
+			}
+		}
+	}
+}
+
+// reload applies the given configuration to the running agent. Workload API
+// connections and the SVID cache are left untouched. Settings that fail to
+// apply are logged and the agent keeps running with the current ones.
+func (a *Agent) reload(ctx context.Context, rc *ReloadableConfig, targets reloadTargets) {
+	log := a.c.Log.WithField(telemetry.SubsystemName, telemetry.Reloader)
+
+	if setter, ok := a.c.Log.(levelSetter); ok {
+		setter.SetLevel(rc.LogLevel)
+		log.WithField(telemetry.LogLevel, rc.LogLevel.String()).Info("Log level reloaded")
+	}
+
+	if rc.ServerAddress != a.serverAddress() {
+		targets.manager.SetServerAddr(rc.ServerAddress)
+		a.setServerAddress(rc.ServerAddress)
+		log.WithField(telemetry.Address, rc.ServerAddress).Info("Server address reloaded")
+	}
+
+	if bindAddress := a.bindAddress(); rc.BindAddress != nil && rc.BindAddress.String() != bindAddress.String() {
+		if err := targets.endpoints.SetBindAddress(rc.BindAddress); err != nil {
+			log.WithError(err).WithField(telemetry.Address, rc.BindAddress.String()).Error("Failed to reload Workload API bind address; keeping current address")
+		} else {
+			a.setBindAddress(rc.BindAddress)
+			log.WithField(telemetry.Address, rc.BindAddress.String()).Info("Workload API bind address reloaded")
+		}
+	}
+
+	if err := targets.plugins.Reconfigure(ctx, rc.PluginConfigs); err != nil {
+		log.WithError(err).Error("Failed to reconfigure one or more plugins")
+	}
+
+	log.Info("Configuration reloaded")
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package agent
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reloadConfigOnSignal returns a task that reloads the agent configuration
+// when the agent receives a SIGHUP.
+func (a *Agent) reloadConfigOnSignal(targets reloadTargets) func(context.Context) error {
+	return func(ctx context.Context) error {
+		signalCh := make(chan os.Signal, 1)
+		signal.Notify(signalCh, syscall.SIGHUP)
+		defer signal.Stop(signalCh)
+		return a.reloadOnSignal(signalCh, targets)(ctx)
+	}
+}
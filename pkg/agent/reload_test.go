@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	log.SetLevel(logrus.InfoLevel)
+
+	bindAddr := &net.UnixAddr{Net: "unix", Name: "/tmp/agent.sock"}
+	plugins := pluginConfigs(t, `KeyManager "memory" { plugin_data {} }`)
+
+	a := New(&Config{
+		Log:           log,
+		ServerAddress: "dns:///server:8081",
+		BindAddress:   bindAddr,
+		PluginConfigs: plugins,
+	})
+
+	targets := &fakeReloadTargets{}
+	reloadTargets := reloadTargets{
+		plugins:   targets,
+		manager:   targets,
+		endpoints: targets,
+	}
+
+	t.Run("only log level changed", func(t *testing.T) {
+		hook.Reset()
+		a.reload(context.Background(), &ReloadableConfig{
+			LogLevel:      logrus.DebugLevel,
+			ServerAddress: "dns:///server:8081",
+			BindAddress:   bindAddr,
+			PluginConfigs: plugins,
+		}, reloadTargets)
+		require.Equal(t, logrus.DebugLevel, log.Level)
+		require.Empty(t, targets.serverAddr)
+		require.Nil(t, targets.bindAddr)
+		require.Equal(t, plugins, targets.pluginConfigs)
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "debug",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+
+	t.Run("server address, bind address and plugins changed", func(t *testing.T) {
+		hook.Reset()
+		otherBindAddr := &net.UnixAddr{Net: "unix", Name: "/tmp/other.sock"}
+		otherPlugins := pluginConfigs(t, `KeyManager "memory" { plugin_data { foo = "bar" } }`)
+		a.reload(context.Background(), &ReloadableConfig{
+			LogLevel:      logrus.InfoLevel,
+			ServerAddress: "dns:///other:8081",
+			BindAddress:   otherBindAddr,
+			PluginConfigs: otherPlugins,
+		}, reloadTargets)
+		require.Equal(t, "dns:///other:8081", targets.serverAddr)
+		require.Equal(t, otherBindAddr, targets.bindAddr)
+		require.Equal(t, otherPlugins, targets.pluginConfigs)
+		require.Equal(t, "dns:///other:8081", a.serverAddress())
+		require.Equal(t, otherBindAddr, a.bindAddress())
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "info",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Server address reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"address":        "dns:///other:8081",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Workload API bind address reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"address":        "/tmp/other.sock",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+
+	t.Run("failures keep the current configuration", func(t *testing.T) {
+		hook.Reset()
+		targets.bindAddrErr = errors.New("bind failed")
+		targets.reconfigureErr = errors.New("reconfigure failed")
+		a.reload(context.Background(), &ReloadableConfig{
+			LogLevel:      logrus.InfoLevel,
+			ServerAddress: "dns:///other:8081",
+			BindAddress:   bindAddr,
+			PluginConfigs: plugins,
+		}, reloadTargets)
+		require.Equal(t, "/tmp/other.sock", a.bindAddress().String())
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "info",
+				},
+			},
+			{
+				Level:   logrus.ErrorLevel,
+				Message: "Failed to reload Workload API bind address; keeping current address",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"address":        "/tmp/agent.sock",
+					logrus.ErrorKey:  "bind failed",
+				},
+			},
+			{
+				Level:   logrus.ErrorLevel,
+				Message: "Failed to reconfigure one or more plugins",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					logrus.ErrorKey:  "reconfigure failed",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	log, hook := test.NewNullLogger()
+
+	reloaded := make(chan struct{}, 1)
+	reloadErr := errors.New("oh no")
+	a := New(&Config{
+		Log: log,
+		ReloadConfig: func() (*ReloadableConfig, error) {
+			reloaded <- struct{}{}
+			return nil, reloadErr
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalCh := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- a.reloadOnSignal(signalCh, reloadTargets{})(ctx)
+	}()
+
+	signalCh <- syscall.SIGHUP
+	select {
+	case <-reloaded:
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for reload")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Failed to reload configuration; keeping current configuration",
+			Data: logrus.Fields{
+				logrus.ErrorKey: "oh no",
+			},
+		},
+	})
+}
+
+func pluginConfigs(t *testing.T, config string) catalog.HCLPluginConfigMap {
+	var hclConfigs catalog.HCLPluginConfigMap
+	require.NoError(t, hcl.Decode(&hclConfigs, config))
+	return hclConfigs
+}
+
+type fakeReloadTargets struct {
+	serverAddr     string
+	bindAddr       net.Addr
+	bindAddrErr    error
+	pluginConfigs  catalog.HCLPluginConfigMap
+	reconfigureErr error
+}
+
+func (f *fakeReloadTargets) Reconfigure(_ context.Context, pluginConfigs catalog.HCLPluginConfigMap) error {
+	if f.reconfigureErr != nil {
+		return f.reconfigureErr
+	}
+	f.pluginConfigs = pluginConfigs
+	return nil
+}
+
+func (f *fakeReloadTargets) SetServerAddr(addr string) {
+	f.serverAddr = addr
+}
+
+func (f *fakeReloadTargets) SetBindAddress(addr net.Addr) error {
+	if f.bindAddrErr != nil {
+		return f.bindAddrErr
+	}
+	f.bindAddr = addr
+	return nil
+}
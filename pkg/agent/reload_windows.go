@@ -0,0 +1,16 @@
+//go:build windows
+
+package agent
+
+import (
+	"context"
+)
+
+// reloadConfigOnSignal returns a noop task since windows does not have
+// signals as on *nix.
+func (a *Agent) reloadConfigOnSignal(reloadTargets) func(context.Context) error {
+	return func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package rotationevents
+
+import (
+	"sync"
+)
+
+// subscriberBuffer is the number of events buffered for a subscriber. A
+// subscriber that falls further behind is dropped.
+const subscriberBuffer = 256
+
+// Event is emitted when the agent caches a new X509-SVID for a registration
+// entry.
+type Event struct {
+	// EntryID is the ID of the registration entry
+	EntryID string `json:"entry_id"`
+
+	// SPIFFEID is the SPIFFE ID of the X509-SVID
+	SPIFFEID string `json:"spiffe_id"`
+
+	// ExpiresAt is when the new X509-SVID expires, in seconds since the
+	// Unix epoch
+	ExpiresAt int64 `json:"expires_at"`
+
+	// PreviousExpiresAt is when the replaced X509-SVID expires, in seconds
+	// since the Unix epoch. It is omitted when the entry had no X509-SVID.
+	PreviousExpiresAt int64 `json:"previous_expires_at,omitempty"`
+}
+
+// Broker fans out the published events to the subscribers.
+type Broker struct {
+	mtx  sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subs: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends the events to every subscriber. It never blocks; the
+// channel of a subscriber that cannot keep up is closed.
+func (b *Broker) Publish(events ...Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for ch := range b.subs {
+		if !trySend(ch, events) {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel that receives the published events and a
+// function that unsubscribes it. The channel is closed when the subscriber
+// is unsubscribed or dropped for falling behind.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mtx.Lock()
+	b.subs[ch] = struct{}{}
+	b.mtx.Unlock()
+
+	return ch, func() {
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func trySend(ch chan Event, events []Event) bool {
+	for _, event := range events {
+		select {
+		case ch <- event:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Subscribers returns the number of subscribers.
+func (b *Broker) Subscribers() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,45 @@
+package rotationevents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+
+	// Publishing without subscribers is a no-op
+	broker.Publish(Event{EntryID: "ENTRY0"})
+
+	events1, unsubscribe1 := broker.Subscribe()
+	events2, unsubscribe2 := broker.Subscribe()
+	require.Equal(t, 2, broker.Subscribers())
+
+	event1 := Event{EntryID: "ENTRY1", SPIFFEID: "spiffe://example.org/workload1", ExpiresAt: 2, PreviousExpiresAt: 1}
+	event2 := Event{EntryID: "ENTRY2", SPIFFEID: "spiffe://example.org/workload2", ExpiresAt: 3}
+	broker.Publish(event1, event2)
+	for _, events := range []<-chan Event{events1, events2} {
+		require.Equal(t, event1, <-events)
+		require.Equal(t, event2, <-events)
+	}
+
+	// Unsubscribing closes the channel and is idempotent
+	unsubscribe1()
+	unsubscribe1()
+	_, ok := <-events1
+	require.False(t, ok)
+	require.Equal(t, 1, broker.Subscribers())
+
+	// A subscriber that falls behind is dropped
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(event1)
+	}
+	require.Equal(t, 0, broker.Subscribers())
+	for i := 0; i < subscriberBuffer; i++ {
+		require.Equal(t, event1, <-events2)
+	}
+	_, ok = <-events2
+	require.False(t, ok)
+	unsubscribe2()
+}
@@ -0,0 +1,120 @@
+package rotationevents
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// writeTimeout bounds how long writing an event to a subscriber can take
+// before the subscriber is disconnected.
+const writeTimeout = 5 * time.Second
+
+type Config struct {
+	// Addr is the address of the UDS the events are served on
+	Addr net.Addr
+
+	// Mode is the file mode applied to the UDS. Defaults to 0770 when unset.
+	Mode os.FileMode
+
+	// Group, if set, is the group (name or ID) that owns the UDS.
+	Group string
+
+	Broker *Broker
+	Log    logrus.FieldLogger
+}
+
+// Server streams the events published to the broker to every process
+// connected to the UDS, as newline delimited JSON objects.
+type Server struct {
+	c *Config
+}
+
+func New(c *Config) *Server {
+	return &Server{c: c}
+}
+
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	l, err := s.createListener()
+	if err != nil {
+		return err
+	}
+	s.c.Log.WithField(telemetry.Address, s.c.Addr.String()).Info("Starting SVID rotation events server")
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serve(ctx, conn)
+		}()
+	}
+}
+
+// serve writes the events to the connection until the context is done, the
+// subscriber disconnects or falls behind.
+func (s *Server) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	events, unsubscribe := s.c.Broker.Subscribe()
+	defer unsubscribe()
+
+	// Subscribers are not expected to send anything; reading detects when
+	// they disconnect.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	encoder := json.NewEncoder(conn)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				s.c.Log.Warn("Disconnecting SVID rotation events subscriber that is not keeping up")
+				return
+			}
+			if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+			if err := encoder.Encode(event); err != nil {
+				s.c.Log.WithError(err).Debug("Failed to write SVID rotation event")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package rotationevents
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+func (s *Server) createListener() (net.Listener, error) {
+	// Remove uds if already exists
+	os.Remove(s.c.Addr.String())
+
+	l, err := net.Listen(s.c.Addr.Network(), s.c.Addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("error creating UDS listener: %w", err)
+	}
+
+	mode := s.c.Mode
+	if mode == 0 {
+		mode = 0770
+	}
+	if err := util.SetSocketPermissions(s.c.Addr.String(), mode, s.c.Group); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
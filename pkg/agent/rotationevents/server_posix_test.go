@@ -0,0 +1,76 @@
+//go:build !windows
+// +build !windows
+
+package rotationevents
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	socketPath := filepath.Join(spiretest.TempDir(t), "rotation-events.sock")
+	log, _ := test.NewNullLogger()
+	broker := NewBroker()
+
+	server := New(&Config{
+		Addr:   &net.UnixAddr{Net: "unix", Name: socketPath},
+		Broker: broker,
+		Log:    log,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe(ctx)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-errCh)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		var err error
+		conn, err = net.Dial("unix", socketPath)
+		return err == nil
+	}, time.Minute, 10*time.Millisecond)
+	defer conn.Close()
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0770), info.Mode().Perm())
+
+	// Wait for the connection to be subscribed before publishing
+	require.Eventually(t, func() bool {
+		return broker.Subscribers() == 1
+	}, time.Minute, 10*time.Millisecond)
+
+	event := Event{EntryID: "ENTRY", SPIFFEID: "spiffe://example.org/workload", ExpiresAt: 2, PreviousExpiresAt: 1}
+	broker.Publish(event)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	require.JSONEq(t, `{"entry_id":"ENTRY","spiffe_id":"spiffe://example.org/workload","expires_at":2,"previous_expires_at":1}`, line)
+
+	var received Event
+	require.NoError(t, json.Unmarshal([]byte(line), &received))
+	require.Equal(t, event, received)
+
+	// The subscription is dropped once the subscriber disconnects
+	conn.Close()
+	require.Eventually(t, func() bool {
+		return broker.Subscribers() == 0
+	}, time.Minute, 10*time.Millisecond)
+}
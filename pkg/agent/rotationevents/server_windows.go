@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package rotationevents
+
+import (
+	"net"
+
+	"github.com/spiffe/spire/pkg/common/peertracker"
+)
+
+func (s *Server) createListener() (net.Listener, error) {
+	return nil, peertracker.ErrUnsupportedPlatform
+}
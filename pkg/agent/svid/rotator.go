@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/andres-erbsen/clock"
 	observer "github.com/imkira/go-observer"
@@ -25,6 +26,10 @@ type Rotator interface {
 	Subscribe() observer.Stream
 	GetRotationMtx() *sync.RWMutex
 	SetRotationFinishedHook(func())
+
+	// RequestRotation requests the agent SVID to be rotated on the next
+	// rotation check, regardless of its expiration.
+	RequestRotation()
 }
 
 type Client interface {
@@ -51,6 +56,9 @@ type rotator struct {
 
 	// Hook that will be called when the SVID rotation finishes
 	rotationFinishedHook func()
+
+	// rotationRequested is set to 1 when a rotation has been requested
+	rotationRequested int32
 }
 
 type State struct {
@@ -123,9 +131,20 @@ func (r *rotator) SetRotationFinishedHook(f func()) {
 	r.rotationFinishedHook = f
 }
 
+func (r *rotator) RequestRotation() {
+	atomic.StoreInt32(&r.rotationRequested, 1)
+}
+
 func (r *rotator) rotateSVIDIfNeeded(ctx context.Context) (err error) {
-	if rotationutil.ShouldRotateX509(r.clk.Now(), r.state.Value().(State).SVID[0]) {
+	requested := atomic.LoadInt32(&r.rotationRequested) == 1
+	if requested || rotationutil.ShouldRotateX509(r.clk.Now(), r.state.Value().(State).SVID[0]) {
+		if requested {
+			r.c.Log.Info("Rotating agent SVID as requested by the server")
+		}
 		err = r.rotateSVID(ctx)
+		if err == nil {
+			atomic.CompareAndSwapInt32(&r.rotationRequested, 1, 0)
+		}
 	}
 	if r.rotationFinishedHook != nil {
 		r.rotationFinishedHook()
@@ -37,6 +37,9 @@ type RotatorConfig struct {
 
 	// Clk is the clock that the rotator will use to create a ticker
 	Clk clock.Clock
+
+	// GRPCOptions tune the connection to the server
+	GRPCOptions client.GRPCOptions
 }
 
 func NewRotator(c *RotatorConfig) (Rotator, client.Client) {
@@ -65,6 +68,7 @@ func newRotator(c *RotatorConfig) (*rotator, client.Client) {
 		Log:         c.Log,
 		Addr:        c.ServerAddr,
 		RotMtx:      rotMtx,
+		GRPCOptions: c.GRPCOptions,
 		KeysAndBundle: func() ([]*x509.Certificate, crypto.Signer, []*x509.Certificate) {
 			s := state.Value().(State)
 
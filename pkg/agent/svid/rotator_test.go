@@ -29,10 +29,11 @@ func TestRotator(t *testing.T) {
 	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
 
 	for _, tt := range []struct {
-		name         string
-		notAfter     time.Duration
-		checkAfter   time.Duration
-		shouldRotate bool
+		name            string
+		notAfter        time.Duration
+		checkAfter      time.Duration
+		requestRotation bool
+		shouldRotate    bool
 	}{
 		{
 			name:         "not expired at startup",
@@ -50,6 +51,12 @@ func TestRotator(t *testing.T) {
 			checkAfter:   2*time.Minute + time.Second,
 			shouldRotate: true,
 		},
+		{
+			name:            "rotation requested",
+			notAfter:        time.Minute,
+			requestRotation: true,
+			shouldRotate:    true,
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			svidKM := keymanager.ForSVID(fakeagentkeymanager.New(t, ""))
@@ -74,6 +81,9 @@ func TestRotator(t *testing.T) {
 				SVIDKey:        svidKey,
 			})
 			rotator.client = mockClient
+			if tt.requestRotation {
+				rotator.RequestRotation()
+			}
 
 			// Hook the rotation loop so we can determine when the rotator
 			// has finished a rotation evaluation (does not imply anything
@@ -186,6 +196,8 @@ func (c *fakeClient) RenewSVID(ctx context.Context, csrBytes []byte) (*client.X5
 	}, nil
 }
 
+func (c *fakeClient) SetAddr(string) {}
+
 func (c *fakeClient) Release() {
 	c.releaseCount++
 }
@@ -2,37 +2,73 @@ package middleware
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
+	"github.com/spiffe/spire/pkg/common/api"
 	"github.com/spiffe/spire/pkg/common/api/rpccontext"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // WithMetrics adds per-call metrics to each RPC call. It emits both a call
 // counter and sample with the call timing. RPC handlers can add their own
 // labels to be attached to the per-call metrics via the
 // rpccontext.AddMetricsLabel function. If unset, it also provides name
-// metadata on to the handler context.
+// metadata on to the handler context. Additionally, a per-service gauge with
+// the number of in-flight calls is emitted each time a call starts or ends,
+// and a per-call error counter labeled only by the gRPC status code is
+// incremented each time a call fails.
 func WithMetrics(metrics telemetry.Metrics) Middleware {
-	return metricsMiddleware{
+	return &metricsMiddleware{
 		metrics: metrics,
 	}
 }
 
 type metricsMiddleware struct {
 	metrics telemetry.Metrics
+
+	// inFlight holds a *int64 with the number of in-flight calls, keyed by
+	// service name.
+	inFlight sync.Map
 }
 
-func (m metricsMiddleware) Preprocess(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+func (m *metricsMiddleware) Preprocess(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
 	ctx, names := withNames(ctx, fullMethod)
+	m.addInFlight(names, 1)
 	counter := telemetry.StartCall(m.metrics, "rpc", names.MetricKey...)
 	return rpccontext.WithCallCounter(ctx, counter), nil
 }
 
-func (m metricsMiddleware) Postprocess(ctx context.Context, fullMethod string, handlerInvoked bool, rpcErr error) {
+func (m *metricsMiddleware) Postprocess(ctx context.Context, fullMethod string, handlerInvoked bool, rpcErr error) {
+	_, names := withNames(ctx, fullMethod)
+	m.addInFlight(names, -1)
+
 	counter, ok := rpccontext.CallCounter(ctx).(*telemetry.CallCounter)
 	if !ok {
 		LogMisconfiguration(ctx, "Metrics misconfigured; this is a bug")
 		return
 	}
 	counter.Done(&rpcErr)
+
+	if code := status.Code(rpcErr); code != codes.OK {
+		// The error counter does not carry the handler labels so that its
+		// cardinality stays low enough to alert on.
+		key := append([]string{"rpc"}, names.MetricKey...)
+		key = append(key, telemetry.Errors)
+		m.metrics.IncrCounterWithLabels(key, 1, []telemetry.Label{
+			{Name: telemetry.Status, Value: code.String()},
+		})
+	}
+}
+
+func (m *metricsMiddleware) addInFlight(names api.Names, delta int64) {
+	value, _ := m.inFlight.LoadOrStore(names.Service, new(int64))
+	inFlight := atomic.AddInt64(value.(*int64), delta)
+
+	// The metric key is the per-call key with the method name swapped out.
+	key := append([]string{"rpc"}, names.MetricKey[:len(names.MetricKey)-1]...)
+	key = append(key, telemetry.InFlight)
+	m.metrics.SetGauge(key, float32(inFlight))
 }
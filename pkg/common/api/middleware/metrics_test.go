@@ -62,7 +62,17 @@ func TestWithMetrics(t *testing.T) {
 
 			expectedLabels = append(expectedLabels, telemetry.Label{Name: "status", Value: tt.statusLabelValue})
 
-			assert.Equal(t, []fakemetrics.MetricItem{
+			expectedMetrics := []fakemetrics.MetricItem{
+				{
+					Type: fakemetrics.SetGaugeType,
+					Key:  []string{"rpc", "foo", "v1", "foo", "in_flight"},
+					Val:  1.00,
+				},
+				{
+					Type: fakemetrics.SetGaugeType,
+					Key:  []string{"rpc", "foo", "v1", "foo", "in_flight"},
+					Val:  0.00,
+				},
 				{
 					Type:   fakemetrics.IncrCounterWithLabelsType,
 					Key:    []string{"rpc", "foo", "v1", "foo", "some_method"},
@@ -75,7 +85,51 @@ func TestWithMetrics(t *testing.T) {
 					Val:    0.00, // This is the elapsed time on the call counter, which doesn't currently support injecting a clock.
 					Labels: expectedLabels,
 				},
-			}, metrics.AllMetrics())
+			}
+			if tt.rpcErr != nil {
+				expectedMetrics = append(expectedMetrics, fakemetrics.MetricItem{
+					Type:   fakemetrics.IncrCounterWithLabelsType,
+					Key:    []string{"rpc", "foo", "v1", "foo", "some_method", "errors"},
+					Val:    1.00,
+					Labels: []telemetry.Label{{Name: "status", Value: tt.statusLabelValue}},
+				})
+			}
+			assert.Equal(t, expectedMetrics, metrics.AllMetrics())
 		})
 	}
 }
+
+func TestWithMetricsInFlight(t *testing.T) {
+	const otherFullMethod = "/spire.api.server.bar.v1.Bar/SomeMethod"
+
+	metrics := fakemetrics.New()
+	m := middleware.WithMetrics(metrics)
+
+	ctx1, err := m.Preprocess(context.Background(), fakeFullMethod, nil)
+	require.NoError(t, err)
+	ctx2, err := m.Preprocess(context.Background(), fakeFullMethod, nil)
+	require.NoError(t, err)
+	ctx3, err := m.Preprocess(context.Background(), otherFullMethod, nil)
+	require.NoError(t, err)
+	m.Postprocess(ctx1, fakeFullMethod, true, nil)
+	m.Postprocess(ctx3, otherFullMethod, true, nil)
+	m.Postprocess(ctx2, fakeFullMethod, true, nil)
+
+	var gauges []fakemetrics.MetricItem
+	for _, item := range metrics.AllMetrics() {
+		if item.Type == fakemetrics.SetGaugeType {
+			gauges = append(gauges, item)
+		}
+	}
+
+	fooKey := []string{"rpc", "foo", "v1", "foo", "in_flight"}
+	barKey := []string{"rpc", "bar", "v1", "bar", "in_flight"}
+	assert.Equal(t, []fakemetrics.MetricItem{
+		{Type: fakemetrics.SetGaugeType, Key: fooKey, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: fooKey, Val: 2},
+		{Type: fakemetrics.SetGaugeType, Key: barKey, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: fooKey, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: barKey, Val: 0},
+		{Type: fakemetrics.SetGaugeType, Key: fooKey, Val: 0},
+	}, gauges)
+}
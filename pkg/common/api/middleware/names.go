@@ -21,6 +21,8 @@ const (
 	EnvoySDSv3ServiceShortName  = "SDS.v3"
 	HealthServiceName           = "grpc.health.v1.Health"
 	HealthServiceShortName      = "Health"
+	AgentBundleServiceName      = "spire.private.agent.bundle.Bundle"
+	AgentBundleServiceShortName = "WorkloadAPI.Bundle"
 )
 
 var (
@@ -30,6 +32,7 @@ var (
 		EnvoySDSv2ServiceName, EnvoySDSv2ServiceShortName,
 		EnvoySDSv3ServiceName, EnvoySDSv3ServiceShortName,
 		HealthServiceName, HealthServiceShortName,
+		AgentBundleServiceName, AgentBundleServiceShortName,
 	)
 
 	// namesCache caches parsed names
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/api/rpccontext"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the gRPC header carrying the ID of the request that
+// caused the call, so the request can be traced across the agent, the server
+// and its plugins.
+const RequestIDHeader = "spire-request-id"
+
+// maxRequestIDLen bounds the length of the request IDs accepted from callers.
+const maxRequestIDLen = 64
+
+// WithRequestID returns a middleware that provides the handler context with
+// a newly generated request ID. The request ID is added to the per-rpc logger,
+// sent along on the calls made while serving the request, and returned to the
+// caller in the response headers.
+//
+// Request IDs are never taken from callers, so they can be relied on to
+// identify calls in the audit log. The request ID sent by the caller through
+// the RequestIDHeader, if valid, is added to the per-rpc logger as a separate
+// field, so the call can be traced back to the caller logs.
+//
+// The WithRequestID middleware depends on the Logger middleware.
+func WithRequestID() Middleware {
+	return Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request ID: %w", err)
+		}
+		requestID := id.String()
+
+		// Setting the header fails when the call has no transport stream
+		// (e.g. in unit tests), which is harmless.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+
+		fields := logrus.Fields{
+			telemetry.RequestID: requestID,
+		}
+		if callerRequestID, ok := incomingRequestID(ctx); ok {
+			fields[telemetry.CallerRequestID] = callerRequestID
+		}
+
+		ctx = rpccontext.WithRequestID(ctx, requestID)
+		return rpccontext.WithLogger(ctx, rpccontext.Logger(ctx).WithFields(fields)), nil
+	})
+}
+
+// UnaryClientRequestIDInterceptor sends the request ID in the context, if
+// any, through the RequestIDHeader on unary calls.
+func UnaryClientRequestIDInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withOutgoingRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamClientRequestIDInterceptor sends the request ID in the context, if
+// any, through the RequestIDHeader on streaming calls.
+func StreamClientRequestIDInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withOutgoingRequestID(ctx), desc, cc, method, opts...)
+}
+
+func withOutgoingRequestID(ctx context.Context) context.Context {
+	if requestID, ok := rpccontext.RequestID(ctx); ok {
+		return metadata.AppendToOutgoingContext(ctx, RequestIDHeader, requestID)
+	}
+	return ctx
+}
+
+// incomingRequestID returns the request ID sent by the caller, if it is
+// valid. Request IDs end up in logs, so they are limited to a bounded number
+// of alphanumeric characters, dashes, dots and underscores.
+func incomingRequestID(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(RequestIDHeader)
+	if len(values) != 1 || values[0] == "" || len(values[0]) > maxRequestIDLen {
+		return "", false
+	}
+	for _, r := range values[0] {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+		default:
+			return "", false
+		}
+	}
+	return values[0], true
+}
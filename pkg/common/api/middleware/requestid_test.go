@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/spiffe/spire/pkg/common/api/rpccontext"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithRequestID(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		header         []string
+		expectCallerID string
+	}{
+		{
+			name: "no request ID sent",
+		},
+		{
+			name:           "request ID sent",
+			header:         []string{"abc-123_x.y"},
+			expectCallerID: "abc-123_x.y",
+		},
+		{
+			name:   "empty request ID sent",
+			header: []string{""},
+		},
+		{
+			name:   "request ID with invalid characters",
+			header: []string{"abc\ndef"},
+		},
+		{
+			name:   "request ID too long",
+			header: []string{strings.Repeat("a", 65)},
+		},
+		{
+			name:   "multiple request IDs sent",
+			header: []string{"abc", "def"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			ctx := rpccontext.WithLogger(context.Background(), log)
+			if tt.header != nil {
+				md := metadata.MD{}
+				md.Append(middleware.RequestIDHeader, tt.header...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			m := middleware.WithRequestID()
+			ctx, err := m.Preprocess(ctx, fakeFullMethod, nil)
+			require.NoError(t, err)
+
+			// The request ID is always generated, even if the caller sent one
+			requestID, ok := rpccontext.RequestID(ctx)
+			require.True(t, ok)
+			_, err = uuid.FromString(requestID)
+			assert.NoError(t, err, "expected a generated request ID")
+
+			rpccontext.Logger(ctx).Info("HELLO")
+			require.Len(t, hook.AllEntries(), 1)
+			assert.Equal(t, requestID, hook.LastEntry().Data[telemetry.RequestID])
+			if tt.expectCallerID != "" {
+				assert.Equal(t, tt.expectCallerID, hook.LastEntry().Data[telemetry.CallerRequestID])
+			} else {
+				assert.NotContains(t, hook.LastEntry().Data, telemetry.CallerRequestID)
+			}
+		})
+	}
+}
+
+func TestClientRequestIDInterceptors(t *testing.T) {
+	ctxWithID := rpccontext.WithRequestID(context.Background(), "abc")
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	require.NoError(t, middleware.UnaryClientRequestIDInterceptor(ctxWithID, fakeFullMethod, nil, nil, nil, invoker))
+	assert.Equal(t, []string{"abc"}, outgoing.Get(middleware.RequestIDHeader))
+
+	require.NoError(t, middleware.UnaryClientRequestIDInterceptor(context.Background(), fakeFullMethod, nil, nil, nil, invoker))
+	assert.Empty(t, outgoing.Get(middleware.RequestIDHeader))
+
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+	_, err := middleware.StreamClientRequestIDInterceptor(ctxWithID, nil, nil, fakeFullMethod, streamer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, outgoing.Get(middleware.RequestIDHeader))
+}
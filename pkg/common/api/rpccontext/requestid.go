@@ -0,0 +1,18 @@
+package rpccontext
+
+import (
+	"context"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the request it serves.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request served by the context, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
@@ -83,6 +83,29 @@ func TestPruneBundle(t *testing.T) {
 			expiration: test.currentTime,
 			changed:    true,
 		},
+		{
+			name: "keeps refresh hint and sequence number",
+			bundle: func() *common.Bundle {
+				b := createBundle(
+					[]*x509.Certificate{test.certNotExpired, test.certExpired},
+					[]*common.PublicKey{test.jwtKeyNotExpired},
+				)
+				b.RefreshHint = 300
+				b.SequenceNumber = 7
+				return b
+			}(),
+			newBundle: func() *common.Bundle {
+				b := createBundle(
+					[]*x509.Certificate{test.certNotExpired},
+					[]*common.PublicKey{test.jwtKeyNotExpired},
+				)
+				b.RefreshHint = 300
+				b.SequenceNumber = 7
+				return b
+			}(),
+			expiration: test.currentTime,
+			changed:    true,
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -120,8 +143,9 @@ func TestCommonBundleFromProto(t *testing.T) {
 		{
 			name: "success",
 			bundle: &types.Bundle{
-				TrustDomain: td.String(),
-				RefreshHint: 10,
+				TrustDomain:    td.String(),
+				RefreshHint:    10,
+				SequenceNumber: 3,
 				X509Authorities: []*types.X509Certificate{
 					{
 						Asn1: rootCA.Raw,
@@ -136,9 +160,10 @@ func TestCommonBundleFromProto(t *testing.T) {
 				},
 			},
 			expectBundle: &common.Bundle{
-				TrustDomainId: td.IDString(),
-				RefreshHint:   10,
-				RootCas:       []*common.Certificate{{DerBytes: rootCA.Raw}},
+				TrustDomainId:  td.IDString(),
+				RefreshHint:    10,
+				SequenceNumber: 3,
+				RootCas:        []*common.Certificate{{DerBytes: rootCA.Raw}},
 				JwtSigningKeys: []*common.PublicKey{
 					{
 						PkixBytes: pkixBytes,
@@ -9,10 +9,12 @@ import (
 )
 
 type marshalConfig struct {
-	refreshHint    time.Duration
-	noX509SVIDKeys bool
-	noJWTSVIDKeys  bool
-	standardJWKS   bool
+	refreshHint            time.Duration
+	sequenceNumber         uint64
+	noX509SVIDKeys         bool
+	noJWTSVIDKeys          bool
+	standardJWKS           bool
+	refreshHintAndSequence bool
 }
 
 type MarshalOption interface {
@@ -49,7 +51,9 @@ func NoJWTSVIDKeys() MarshalOption {
 	})
 }
 
-// StandardJWKS omits SPIFFE-specific parameters from the marshaled bundle
+// StandardJWKS omits SPIFFE-specific parameters from the marshaled bundle.
+// The keys are marshaled without their SPIFFE "use" and the refresh hint and
+// sequence number are left out, unless WithRefreshHintAndSequence is given.
 func StandardJWKS() MarshalOption {
 	return marshalOption(func(c *marshalConfig) error {
 		c.standardJWKS = true
@@ -57,9 +61,21 @@ func StandardJWKS() MarshalOption {
 	})
 }
 
+// WithRefreshHintAndSequence keeps the refresh hint and sequence number in
+// bundles marshaled with StandardJWKS, so consumers of the JWKS know how often
+// to refresh it and can tell when it is stale. Unknown JWK Set members are
+// ignored by standard JWKS consumers (RFC 7517 section 5).
+func WithRefreshHintAndSequence() MarshalOption {
+	return marshalOption(func(c *marshalConfig) error {
+		c.refreshHintAndSequence = true
+		return nil
+	})
+}
+
 func Marshal(bundle *Bundle, opts ...MarshalOption) ([]byte, error) {
 	c := &marshalConfig{
-		refreshHint: bundle.RefreshHint(),
+		refreshHint:    bundle.RefreshHint(),
+		sequenceNumber: bundle.SequenceNumber(),
 	}
 	for _, opt := range opts {
 		if err := opt.configure(c); err != nil {
@@ -96,9 +112,10 @@ func Marshal(bundle *Bundle, opts ...MarshalOption) ([]byte, error) {
 	}
 
 	var out interface{} = jwks
-	if !c.standardJWKS {
+	if !c.standardJWKS || c.refreshHintAndSequence {
 		out = bundleDoc{
 			JSONWebKeySet: jwks,
+			Sequence:      c.sequenceNumber,
 			RefreshHint:   int(c.refreshHint / time.Second),
 		}
 	}
@@ -13,10 +13,11 @@ func TestMarshal(t *testing.T) {
 	rootCA := createCACertificate(t)
 
 	testCases := []struct {
-		name  string
-		empty bool
-		opts  []MarshalOption
-		out   string
+		name     string
+		empty    bool
+		sequence uint64
+		opts     []MarshalOption
+		out      string
 	}{
 		{
 			name:  "empty bundle",
@@ -31,6 +32,12 @@ func TestMarshal(t *testing.T) {
 			},
 			out: `{"keys":null, "spiffe_refresh_hint": 10}`,
 		},
+		{
+			name:     "with sequence number",
+			empty:    true,
+			sequence: 42,
+			out:      `{"keys":null, "spiffe_refresh_hint": 60, "spiffe_sequence": 42}`,
+		},
 		{
 			name: "without X509 SVID keys",
 			opts: []MarshalOption{
@@ -124,6 +131,28 @@ func TestMarshal(t *testing.T) {
 				]
 			}`, x5c(rootCA)),
 		},
+		{
+			name:     "as standard JWKS with refresh hint and sequence number",
+			sequence: 42,
+			opts: []MarshalOption{
+				StandardJWKS(),
+				WithRefreshHintAndSequence(),
+				NoX509SVIDKeys(),
+			},
+			out: `{
+				"keys": [
+					{
+						"kid": "FOO",
+						"kty": "EC",
+						"crv": "P-256",
+						"x": "kkEn5E2Hd_rvCRDCVMNj3deN0ADij9uJVmN-El0CJz0",
+						"y": "qNrnjhtzrtTR0bRgI2jPIC1nEgcWNX63YcZOEzyo1iA"
+					}
+				],
+				"spiffe_refresh_hint": 60,
+				"spiffe_sequence": 42
+			}`,
+		},
 	}
 
 	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
@@ -133,6 +162,7 @@ func TestMarshal(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			bundle := New(trustDomain)
 			bundle.SetRefreshHint(time.Minute)
+			bundle.SetSequenceNumber(testCase.sequence)
 			if !testCase.empty {
 				bundle.AppendRootCA(rootCA)
 				require.NoError(t, bundle.AppendJWTSigningKey("FOO", testKey.Public()))
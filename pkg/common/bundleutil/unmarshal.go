@@ -29,6 +29,7 @@ func Unmarshal(trustDomain spiffeid.TrustDomain, data []byte) (*Bundle, error) {
 func unmarshal(trustDomain spiffeid.TrustDomain, doc *bundleDoc) (*Bundle, error) {
 	bundle := New(trustDomain)
 	bundle.SetRefreshHint(time.Second * time.Duration(doc.RefreshHint))
+	bundle.SetSequenceNumber(doc.Sequence)
 
 	for i, key := range doc.Keys {
 		switch key.Use {
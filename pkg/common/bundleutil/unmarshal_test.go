@@ -3,6 +3,7 @@ package bundleutil
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/stretchr/testify/require"
@@ -22,6 +23,16 @@ func TestUnmarshal(t *testing.T) {
 			doc:    "{}",
 			bundle: New(trustDomain),
 		},
+		{
+			name: "refresh hint and sequence number",
+			doc:  `{"keys": [], "spiffe_refresh_hint": 300, "spiffe_sequence": 42}`,
+			bundle: func() *Bundle {
+				b := New(trustDomain)
+				b.SetRefreshHint(5 * time.Minute)
+				b.SetSequenceNumber(42)
+				return b
+			}(),
+		},
 		{
 			name: "entry missing use",
 			doc: `{
@@ -142,7 +142,7 @@ func startPipeServer(server *grpc.Server, log logrus.FieldLogger) (_ *pipeConn,
 	}()
 
 	// Dial the server
-	conn, err := grpc.Dial("IGNORED", grpc.WithBlock(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(pipeNet.DialContext))
+	conn, err := grpc.Dial("IGNORED", append(pluginDialOptions(), grpc.WithBlock(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(pipeNet.DialContext))...)
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
@@ -107,17 +107,22 @@ type Config struct {
 
 	// CoreConfig is the core configuration provided to each plugin.
 	CoreConfig CoreConfig
+
+	// RequirePluginChecksums, if true, fails to load external plugins that
+	// do not have a checksum configured.
+	RequirePluginChecksums bool
 }
 
 // Load loads and configures plugins defined in the configuration. The given
 // catalog is populated with plugin and service facades for versions
-// implemented by the loaded plugins. The returned io.Closer can be used to
-// close down the loaded plugins, at which point, all facades bound to the
-// given catalog are considered invalidated. If any plugin fails to load or
-// configure, all plugins are unloaded, the catalog is cleared, and the
-// function returns an error.
-func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err error) {
+// implemented by the loaded plugins. The returned LoadedPlugins can be used to
+// reconfigure the loaded plugins or to close them down, at which point, all
+// facades bound to the given catalog are considered invalidated. If any
+// plugin fails to load or configure, all plugins are unloaded, the catalog is
+// cleared, and the function returns an error.
+func Load(ctx context.Context, config Config, cat Catalog) (_ *LoadedPlugins, err error) {
 	closers := make(closerGroup, 0)
+	var loaded []*loadedPlugin
 	defer func() {
 		// If loading fails, clear out the catalog and close down all plugins
 		// that have been loaded thus far.
@@ -157,7 +162,7 @@ func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err err
 			continue
 		}
 
-		plugin, err := loadPlugin(ctx, pluginRepo.BuiltIns(), pluginConfig, pluginLog, config.HostServices)
+		plugin, err := loadPlugin(ctx, pluginRepo.BuiltIns(), pluginConfig, pluginLog, config.HostServices, config.RequirePluginChecksums)
 		if err != nil {
 			pluginLog.WithError(err).Error("Failed to load plugin")
 			return nil, fmt.Errorf("failed to load plugin %q: %w", pluginConfig.Name, err)
@@ -188,6 +193,11 @@ func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err err
 
 		pluginLog.Info("Plugin loaded")
 		pluginCounts[pluginConfig.Type]++
+		loaded = append(loaded, &loadedPlugin{
+			config:     pluginConfig,
+			configurer: configurer,
+			log:        pluginLog,
+		})
 	}
 
 	// Make sure all of the plugin constraints are satisfied
@@ -197,7 +207,12 @@ func Load(ctx context.Context, config Config, cat Catalog) (_ io.Closer, err err
 		}
 	}
 
-	return closers, nil
+	return &LoadedPlugins{
+		closers:    closers,
+		coreConfig: config.CoreConfig,
+		log:        config.Log,
+		plugins:    loaded,
+	}, nil
 }
 
 func makePluginLog(log logrus.FieldLogger, pluginConfig PluginConfig) logrus.FieldLogger {
@@ -208,16 +223,17 @@ func makePluginLog(log logrus.FieldLogger, pluginConfig PluginConfig) logrus.Fie
 	})
 }
 
-func loadPlugin(ctx context.Context, builtIns []BuiltIn, pluginConfig PluginConfig, pluginLog logrus.FieldLogger, hostServices []pluginsdk.ServiceServer) (*pluginImpl, error) {
+func loadPlugin(ctx context.Context, builtIns []BuiltIn, pluginConfig PluginConfig, pluginLog logrus.FieldLogger, hostServices []pluginsdk.ServiceServer, requireChecksum bool) (*pluginImpl, error) {
 	if pluginConfig.IsExternal() {
 		return loadExternal(ctx, externalConfig{
-			Name:         pluginConfig.Name,
-			Type:         pluginConfig.Type,
-			Path:         pluginConfig.Path,
-			Args:         pluginConfig.Args,
-			Checksum:     pluginConfig.Checksum,
-			Log:          pluginLog,
-			HostServices: hostServices,
+			Name:            pluginConfig.Name,
+			Type:            pluginConfig.Type,
+			Path:            pluginConfig.Path,
+			Args:            pluginConfig.Args,
+			Checksum:        pluginConfig.Checksum,
+			RequireChecksum: requireChecksum,
+			Log:             pluginLog,
+			HostServices:    hostServices,
 		})
 	}
 
@@ -61,6 +61,26 @@ func TestExternalPlugin(t *testing.T) {
 		})
 	})
 
+	t.Run("without checksum when required", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			mutateConfig: func(config *catalog.Config) {
+				config.RequirePluginChecksums = true
+				config.PluginConfigs[0].Checksum = ""
+			},
+			expectErr: `failed to load plugin "test": plugin checksum is required but not configured`,
+		})
+	})
+
+	t.Run("with checksum when required", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			mutateConfig: func(config *catalog.Config) {
+				config.RequirePluginChecksums = true
+			},
+			expectPluginClient:  true,
+			expectServiceClient: true,
+		})
+	})
+
 	t.Run("bad checksum", func(t *testing.T) {
 		testLoad(t, pluginPath, loadTest{
 			mutateConfig: func(config *catalog.Config) {
@@ -194,6 +214,14 @@ func testPlugin(t *testing.T, pluginPath string) {
 			expectServiceClient: true,
 		})
 	})
+	t.Run("unsupported plugin version", func(t *testing.T) {
+		testLoad(t, pluginPath, loadTest{
+			mutatePluginRepo: func(pluginRepo *PluginRepo) {
+				pluginRepo.versions = []catalog.Version{SomePluginV2Version{}}
+			},
+			expectErr: `failed to bind plugin "test": no supported plugin interface found in: ["test.SomePlugin" "test.SomeService"]; expected one of: ["test.SomePluginV2"]`,
+		})
+	})
 	t.Run("unknown type", func(t *testing.T) {
 		testLoad(t, pluginPath, loadTest{
 			mutateConfig: func(config *catalog.Config) {
@@ -518,6 +546,18 @@ func (v SomePluginVersion) New() catalog.Facade { return new(SomePluginFacade) }
 
 func (v SomePluginVersion) Deprecated() bool { return v.deprecated }
 
+type SomePluginV2Facade struct {
+	SomePluginFacade
+}
+
+func (f *SomePluginV2Facade) GRPCServiceName() string { return "test.SomePluginV2" }
+
+type SomePluginV2Version struct{}
+
+func (v SomePluginV2Version) New() catalog.Facade { return new(SomePluginV2Facade) }
+
+func (v SomePluginV2Version) Deprecated() bool { return false }
+
 type SomeService interface {
 	catalog.PluginInfo
 	ServiceEcho(ctx context.Context, in string) (string, error)
@@ -34,6 +34,10 @@ type externalConfig struct {
 	// Checksum is the hex-encoded SHA256 hash of the plugin binary.
 	Checksum string
 
+	// RequireChecksum, if true, fails to load the plugin if Checksum is not
+	// set.
+	RequireChecksum bool
+
 	// Log is the logger to be wired to the external plugin.
 	Log logrus.FieldLogger
 
@@ -63,6 +67,9 @@ func loadExternal(ctx context.Context, config externalConfig) (*pluginImpl, erro
 			return nil, err
 		}
 	} else {
+		if config.RequireChecksum {
+			return nil, errors.New("plugin checksum is required but not configured")
+		}
 		config.Log.Warn("Plugin checksum not configured")
 	}
 
@@ -86,8 +93,9 @@ func loadExternal(ctx context.Context, config externalConfig) (*pluginImpl, erro
 		Plugins: map[string]goplugin.Plugin{
 			config.Name: &hcClientPlugin{config: config},
 		},
-		Logger:       logger,
-		SecureConfig: secureConfig,
+		Logger:          logger,
+		SecureConfig:    secureConfig,
+		GRPCDialOptions: pluginDialOptions(),
 	})
 
 	// Ensure the loaded plugin is killed if there is a failure.
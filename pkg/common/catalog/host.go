@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	"github.com/spiffe/spire/pkg/common/api/middleware"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -26,6 +27,15 @@ func newHostServer(pluginName string, hostServices []pluginsdk.ServiceServer) *g
 	return s
 }
 
+// pluginDialOptions returns the options used to dial plugins. The ID of the
+// request that caused a plugin call is sent along so plugins can log it.
+func pluginDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(middleware.UnaryClientRequestIDInterceptor),
+		grpc.WithChainStreamInterceptor(middleware.StreamClientRequestIDInterceptor),
+	}
+}
+
 func streamPluginInterceptor(name string) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		return handler(srv, streamWrapper{ctx: WithPluginName(ss.Context(), name), ServerStream: ss})
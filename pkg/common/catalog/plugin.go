@@ -98,7 +98,7 @@ func (p *pluginImpl) bindRepos(pluginRepo bindablePluginRepo, serviceRepos []bin
 
 	switch {
 	case impl == nil:
-		return nil, fmt.Errorf("no supported plugin interface found in: %q", p.grpcServiceNames)
+		return nil, fmt.Errorf("no supported plugin interface found in: %q; expected one of: %q", p.grpcServiceNames, versionGRPCServiceNames(pluginRepo))
 	case len(grpcServiceNames) > 0:
 		for _, grpcServiceName := range sortStringSet(grpcServiceNames) {
 			p.log.WithField(telemetry.PluginService, grpcServiceName).Warn("Unsupported plugin service found")
@@ -150,6 +150,14 @@ func warnIfDeprecated(log logrus.FieldLogger, thisVersion, latestVersion Version
 	}
 }
 
+func versionGRPCServiceNames(repo bindablePluginRepo) []string {
+	var grpcServiceNames []string
+	for _, version := range repo.Versions() {
+		grpcServiceNames = append(grpcServiceNames, version.New().GRPCServiceName())
+	}
+	return grpcServiceNames
+}
+
 func grpcServiceNameSet(grpcServiceNames []string) map[string]struct{} {
 	set := make(map[string]struct{})
 	for _, grpcServiceName := range grpcServiceNames {
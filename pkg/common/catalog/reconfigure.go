@@ -0,0 +1,112 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zeebo/errs"
+)
+
+// LoadedPlugins is the set of plugins loaded by Load.
+type LoadedPlugins struct {
+	closers    closerGroup
+	coreConfig CoreConfig
+	log        logrus.FieldLogger
+
+	mu      sync.Mutex
+	plugins []*loadedPlugin
+}
+
+type loadedPlugin struct {
+	config     PluginConfig
+	configurer Configurer
+	log        logrus.FieldLogger
+}
+
+// Close unloads the loaded plugins.
+func (p *LoadedPlugins) Close() error {
+	return p.closers.Close()
+}
+
+// Reconfigure configures the loaded plugins with the plugin data from the
+// given plugin configurations. Only plugins whose plugin data has changed are
+// reconfigured. Plugins cannot be added, removed or have how they are launched
+// changed while running; such changes are logged and otherwise ignored. A
+// plugin that fails to reconfigure keeps running with its current
+// configuration.
+func (p *LoadedPlugins) Reconfigure(ctx context.Context, pluginConfigs []PluginConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	configs := make(map[string]PluginConfig)
+	for _, pluginConfig := range pluginConfigs {
+		if pluginConfig.Disabled {
+			continue
+		}
+		configs[pluginKey(pluginConfig)] = pluginConfig
+	}
+
+	var errGroup errs.Group
+	for _, plugin := range p.plugins {
+		key := pluginKey(plugin.config)
+		pluginConfig, ok := configs[key]
+		if !ok {
+			plugin.log.Warn("Plugin removed from the configuration; restart to unload it")
+			continue
+		}
+		delete(configs, key)
+
+		if pluginConfig.Path != plugin.config.Path || pluginConfig.Checksum != plugin.config.Checksum || !stringsEqual(pluginConfig.Args, plugin.config.Args) {
+			plugin.log.Warn("Plugin launch configuration changed; restart to apply it")
+		}
+
+		if pluginConfig.Data == plugin.config.Data {
+			continue
+		}
+
+		if err := p.reconfigurePlugin(ctx, plugin, pluginConfig.Data); err != nil {
+			plugin.log.WithError(err).Error("Failed to reconfigure plugin; keeping current configuration")
+			errGroup.Add(err)
+			continue
+		}
+		plugin.config.Data = pluginConfig.Data
+		plugin.log.Info("Plugin reconfigured")
+	}
+
+	for _, pluginConfig := range configs {
+		makePluginLog(p.log, pluginConfig).Warn("Plugin added to the configuration; restart to load it")
+	}
+
+	return errGroup.Err()
+}
+
+func (p *LoadedPlugins) reconfigurePlugin(ctx context.Context, plugin *loadedPlugin, data string) error {
+	if plugin.configurer == nil {
+		if data != "" {
+			return fmt.Errorf("failed to reconfigure plugin %q: no supported configuration interface found", plugin.config.Name)
+		}
+		return nil
+	}
+	if err := plugin.configurer.Configure(ctx, p.coreConfig, data); err != nil {
+		return fmt.Errorf("failed to reconfigure plugin %q: %w", plugin.config.Name, err)
+	}
+	return nil
+}
+
+func pluginKey(pluginConfig PluginConfig) string {
+	return pluginConfig.Type + "/" + pluginConfig.Name
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
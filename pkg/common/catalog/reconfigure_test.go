@@ -0,0 +1,125 @@
+package catalog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	log_test "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	"github.com/spiffe/spire-plugin-sdk/private/proto/test"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/catalog/testplugin"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconfigure(t *testing.T) {
+	log, hook := log_test.NewNullLogger()
+
+	pluginRepo := &PluginRepo{
+		binder:      func(f SomePlugin) {},
+		clear:       func() {},
+		versions:    []catalog.Version{SomePluginVersion{}},
+		constraints: catalog.Constraints{Min: 1, Max: 1},
+		builtIns:    []catalog.BuiltIn{testplugin.BuiltIn(true)},
+	}
+	repo := &Repo{
+		plugins: map[string]catalog.PluginRepo{"SomePlugin": pluginRepo},
+	}
+
+	pluginConfig := catalog.PluginConfig{Name: "test", Type: "SomePlugin", Data: "GOOD"}
+	loaded, err := catalog.Load(context.Background(), catalog.Config{
+		Log:           log,
+		CoreConfig:    coreConfig,
+		PluginConfigs: []catalog.PluginConfig{pluginConfig},
+		HostServices: []pluginsdk.ServiceServer{
+			test.SomeHostServiceServiceServer(testplugin.SomeHostService{}),
+		},
+	}, repo)
+	require.NoError(t, err)
+	defer loaded.Close()
+
+	pluginFields := logrus.Fields{
+		"plugin_name": "test",
+		"plugin_type": "SomePlugin",
+		"external":    "false",
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		hook.Reset()
+		require.NoError(t, loaded.Reconfigure(context.Background(), []catalog.PluginConfig{pluginConfig}))
+		require.Empty(t, hook.AllEntries())
+	})
+
+	t.Run("data changed", func(t *testing.T) {
+		hook.Reset()
+		changed := pluginConfig
+		changed.Data = "GOOD AGAIN"
+		require.NoError(t, loaded.Reconfigure(context.Background(), []catalog.PluginConfig{changed}))
+		spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Plugin reconfigured",
+				Data:    pluginFields,
+			},
+		})
+	})
+
+	t.Run("configure fails", func(t *testing.T) {
+		hook.Reset()
+		changed := pluginConfig
+		changed.Data = "BAD"
+		err := loaded.Reconfigure(context.Background(), []catalog.PluginConfig{changed})
+		require.EqualError(t, err, `failed to reconfigure plugin "test": rpc error: code = InvalidArgument desc = bad config`)
+		spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.ErrorLevel,
+				Message: "Failed to reconfigure plugin; keeping current configuration",
+				Data: logrus.Fields{
+					"plugin_name":   "test",
+					"plugin_type":   "SomePlugin",
+					"external":      "false",
+					logrus.ErrorKey: `failed to reconfigure plugin "test": rpc error: code = InvalidArgument desc = bad config`,
+				},
+			},
+		})
+	})
+
+	t.Run("plugins added and removed", func(t *testing.T) {
+		hook.Reset()
+		added := catalog.PluginConfig{Name: "other", Type: "SomePlugin"}
+		require.NoError(t, loaded.Reconfigure(context.Background(), []catalog.PluginConfig{added}))
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.WarnLevel,
+				Message: "Plugin removed from the configuration; restart to unload it",
+				Data:    pluginFields,
+			},
+			{
+				Level:   logrus.WarnLevel,
+				Message: "Plugin added to the configuration; restart to load it",
+				Data: logrus.Fields{
+					"plugin_name": "other",
+					"plugin_type": "SomePlugin",
+					"external":    "false",
+				},
+			},
+		})
+	})
+
+	t.Run("launch configuration changed", func(t *testing.T) {
+		hook.Reset()
+		changed := pluginConfig
+		changed.Data = "GOOD AGAIN"
+		changed.Args = []string{"-foo"}
+		require.NoError(t, loaded.Reconfigure(context.Background(), []catalog.PluginConfig{changed}))
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.WarnLevel,
+				Message: "Plugin launch configuration changed; restart to apply it",
+				Data:    pluginFields,
+			},
+		})
+	})
+}
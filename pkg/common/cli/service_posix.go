@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+)
+
+// RunService calls run with a context that is canceled when the process is
+// asked to stop via SIGINT or SIGTERM, returning its exit code. The service
+// name is only used on Windows.
+func RunService(name string, run func(ctx context.Context) int) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return run(ctx)
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// RunService calls run with a context that is canceled when the process is
+// asked to stop, returning its exit code. When the process was started by the
+// Windows service control manager, it runs as the named service and stop and
+// shutdown requests from the service control manager cancel the context.
+// Otherwise, the context is canceled on interrupt or SIGTERM.
+func RunService(name string, run func(ctx context.Context) int) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return run(ctx)
+	}
+
+	handler := newServiceHandler(ctx, run)
+	if err := svc.Run(name, handler); err != nil {
+		return 1
+	}
+	return handler.exitCode
+}
+
+type serviceHandler struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	run      func(ctx context.Context) int
+	exitCode int
+}
+
+func newServiceHandler(ctx context.Context, run func(ctx context.Context) int) *serviceHandler {
+	ctx, cancel := context.WithCancel(ctx)
+	return &serviceHandler{
+		ctx:    ctx,
+		cancel: cancel,
+		run:    run,
+	}
+}
+
+// Execute implements svc.Handler.
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	defer h.cancel()
+
+	changes <- svc.Status{State: svc.StartPending}
+
+	done := make(chan int, 1)
+	go func() {
+		done <- h.run(h.ctx)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case exitCode := <-done:
+			h.exitCode = exitCode
+			changes <- svc.Status{State: svc.StopPending}
+			return false, uint32(exitCode)
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				h.cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc"
+)
+
+func TestServiceHandlerStop(t *testing.T) {
+	handler := newServiceHandler(context.Background(), func(ctx context.Context) int {
+		<-ctx.Done()
+		return 3
+	})
+
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status, 10)
+	resultCh := make(chan uint32, 1)
+	go func() {
+		_, exitCode := handler.Execute(nil, requests, changes)
+		resultCh <- exitCode
+	}()
+
+	require.Equal(t, svc.StartPending, (<-changes).State)
+	require.Equal(t, svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}, <-changes)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: svc.Status{State: svc.Running}}
+	require.Equal(t, svc.Running, (<-changes).State)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Stop}
+	require.Equal(t, svc.StopPending, (<-changes).State)
+
+	require.Equal(t, uint32(3), <-resultCh)
+	require.Equal(t, 3, handler.exitCode)
+}
+
+func TestServiceHandlerRunReturns(t *testing.T) {
+	handler := newServiceHandler(context.Background(), func(ctx context.Context) int {
+		return 0
+	})
+
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status, 10)
+	_, exitCode := handler.Execute(nil, requests, changes)
+	require.Equal(t, uint32(0), exitCode)
+	require.Equal(t, 0, handler.exitCode)
+}
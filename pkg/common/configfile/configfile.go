@@ -0,0 +1,205 @@
+// Package configfile parses HCL or JSON configuration files that include
+// other configuration files through a top-level "include" setting.
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+const includeKey = "include"
+
+// Parse parses the configuration data read from path and merges into it the
+// files listed in its top-level "include" setting, recursively.
+//
+// Include paths are relative to the directory of the including file and can
+// be glob patterns, whose matches are included in lexical order. Blocks with
+// the same keys are merged. A setting defined in more than one file is an
+// error, so the result does not depend on the order of the files. When
+// expandEnv is true, environment variables in the included files are
+// expanded before they are parsed; data is expected to be already expanded.
+func Parse(path, data string, expandEnv bool) (*ast.File, error) {
+	p := &parser{
+		expandEnv: expandEnv,
+		visiting:  make(map[string]bool),
+	}
+	return p.parse(path, data)
+}
+
+type parser struct {
+	expandEnv bool
+	visiting  map[string]bool
+}
+
+func (p *parser) parse(path, data string) (*ast.File, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine absolute path of %q: %w", path, err)
+	}
+	if p.visiting[absPath] {
+		return nil, fmt.Errorf("configuration at %q is included recursively", path)
+	}
+	p.visiting[absPath] = true
+	defer delete(p.visiting, absPath)
+
+	file, err := hcl.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode configuration at %q: %w", path, err)
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("unable to decode configuration at %q: root should be an object", path)
+	}
+
+	includes, err := takeIncludes(list)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode configuration at %q: %w", path, err)
+	}
+
+	for _, include := range includes {
+		paths, err := resolveInclude(filepath.Dir(path), include)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve include %q in configuration at %q: %w", include, path, err)
+		}
+		for _, includePath := range paths {
+			includeList, err := p.parseFile(includePath)
+			if err != nil {
+				return nil, err
+			}
+			if err := mergeObjectLists(list, includeList, nil); err != nil {
+				return nil, fmt.Errorf("unable to merge configuration at %q: %w", includePath, err)
+			}
+		}
+	}
+
+	return file, nil
+}
+
+func (p *parser) parseFile(path string) (*ast.ObjectList, error) {
+	byteData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration at %q: %w", path, err)
+	}
+	data := string(byteData)
+	if p.expandEnv {
+		data = os.ExpandEnv(data)
+	}
+
+	file, err := p.parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return file.Node.(*ast.ObjectList), nil
+}
+
+// takeIncludes removes the top-level include settings from the list and
+// returns the paths they list.
+func takeIncludes(list *ast.ObjectList) ([]string, error) {
+	var includes []string
+	items := list.Items[:0]
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 || keyName(item.Keys[0]) != includeKey {
+			items = append(items, item)
+			continue
+		}
+
+		var include struct {
+			Include []string `hcl:"include"`
+		}
+		if err := hcl.DecodeObject(&include, &ast.ObjectList{Items: []*ast.ObjectItem{item}}); err != nil {
+			return nil, fmt.Errorf("invalid include: %w", err)
+		}
+		includes = append(includes, include.Include...)
+	}
+	list.Items = items
+	return includes, nil
+}
+
+func resolveInclude(dir, include string) ([]string, error) {
+	if !filepath.IsAbs(include) {
+		include = filepath.Join(dir, include)
+	}
+
+	paths, err := filepath.Glob(include)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 && !hasMeta(include) {
+		// Return the path so reading it reports a meaningful error
+		return []string{include}, nil
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
+
+// mergeObjectLists merges the items of src into dst. Items that are blocks
+// with the same keys as a block in dst are merged recursively. Any other item
+// with the same keys as an item in dst is a conflict.
+func mergeObjectLists(dst, src *ast.ObjectList, parents []string) error {
+	for _, srcItem := range src.Items {
+		keys := append(parents[:len(parents):len(parents)], keyNames(srcItem)...)
+
+		dstItem := findItem(dst, srcItem)
+		if dstItem == nil {
+			dst.Items = append(dst.Items, srcItem)
+			continue
+		}
+
+		dstObj, dstIsObj := dstItem.Val.(*ast.ObjectType)
+		srcObj, srcIsObj := srcItem.Val.(*ast.ObjectType)
+		if !dstIsObj || !srcIsObj {
+			return fmt.Errorf("%q is already set", strings.Join(keys, "."))
+		}
+		if err := mergeObjectLists(dstObj.List, srcObj.List, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findItem(list *ast.ObjectList, item *ast.ObjectItem) *ast.ObjectItem {
+	names := keyNames(item)
+	for _, candidate := range list.Items {
+		if equalStrings(keyNames(candidate), names) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func keyNames(item *ast.ObjectItem) []string {
+	names := make([]string, 0, len(item.Keys))
+	for _, key := range item.Keys {
+		names = append(names, keyName(key))
+	}
+	return names
+}
+
+func keyName(key *ast.ObjectKey) string {
+	if name, ok := key.Token.Value().(string); ok {
+		return name
+	}
+	return key.Token.Text
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
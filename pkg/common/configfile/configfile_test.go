@@ -0,0 +1,158 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Server struct {
+		TrustDomain string `hcl:"trust_domain"`
+		DataDir     string `hcl:"data_dir"`
+		LogLevel    string `hcl:"log_level"`
+	} `hcl:"server"`
+	Plugins map[string]map[string]struct {
+		PluginData map[string]interface{} `hcl:"plugin_data"`
+	} `hcl:"plugins"`
+	Telemetry struct {
+		Prometheus struct {
+			Port int `hcl:"port"`
+		} `hcl:"Prometheus"`
+	} `hcl:"telemetry"`
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "conf.d/10-keymanager.conf", `
+plugins {
+	KeyManager "disk" {
+		plugin_data {
+			keys_path = "/run/spire/keys.json"
+		}
+	}
+}`)
+	writeFile(t, dir, "conf.d/20-datastore.json", `{
+	"plugins": {
+		"DataStore": {
+			"sql": {
+				"plugin_data": {
+					"database_type": "sqlite3"
+				}
+			}
+		}
+	}
+}`)
+	writeFile(t, dir, "telemetry.conf", `
+include = ["nested.conf"]
+telemetry {
+	Prometheus {
+		port = 9988
+	}
+}`)
+	writeFile(t, dir, "nested.conf", `
+server {
+	log_level = "$LOG_LEVEL"
+}`)
+	t.Setenv("LOG_LEVEL", "DEBUG")
+
+	path := filepath.Join(dir, "server.conf")
+	file, err := Parse(path, `
+include = ["conf.d/*", "telemetry.conf"]
+server {
+	trust_domain = "example.org"
+	data_dir = "/run/spire/data"
+}`, true)
+	require.NoError(t, err)
+
+	var c testConfig
+	require.NoError(t, hcl.DecodeObject(&c, file))
+	require.Equal(t, "example.org", c.Server.TrustDomain)
+	require.Equal(t, "/run/spire/data", c.Server.DataDir)
+	require.Equal(t, "DEBUG", c.Server.LogLevel)
+	require.Equal(t, 9988, c.Telemetry.Prometheus.Port)
+	require.Equal(t, "/run/spire/keys.json", c.Plugins["KeyManager"]["disk"].PluginData["keys_path"])
+	require.Equal(t, "sqlite3", c.Plugins["DataStore"]["sql"].PluginData["database_type"])
+}
+
+func TestParseWithoutIncludes(t *testing.T) {
+	file, err := Parse("server.conf", `server { trust_domain = "example.org" }`, false)
+	require.NoError(t, err)
+
+	var c testConfig
+	require.NoError(t, hcl.DecodeObject(&c, file))
+	require.Equal(t, "example.org", c.Server.TrustDomain)
+}
+
+func TestParseErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "trust_domain.conf", `server { trust_domain = "other.org" }`)
+	writeFile(t, dir, "loop.conf", `include = ["server.conf"]`)
+	writeFile(t, dir, "bad.conf", `server {`)
+	path := filepath.Join(dir, "server.conf")
+	writeFile(t, dir, "server.conf", `include = ["loop.conf"]`)
+
+	for _, tt := range []struct {
+		name      string
+		data      string
+		expectErr string
+	}{
+		{
+			name:      "malformed configuration",
+			data:      `server {`,
+			expectErr: `unable to decode configuration at "` + path + `"`,
+		},
+		{
+			name:      "malformed include",
+			data:      `include = { path = "x" }`,
+			expectErr: `unable to decode configuration at "` + path + `": invalid include`,
+		},
+		{
+			name:      "missing include",
+			data:      `include = ["missing.conf"]`,
+			expectErr: `unable to read configuration at "` + filepath.Join(dir, "missing.conf") + `"`,
+		},
+		{
+			name:      "malformed included configuration",
+			data:      `include = ["bad.conf"]`,
+			expectErr: `unable to decode configuration at "` + filepath.Join(dir, "bad.conf") + `"`,
+		},
+		{
+			name:      "setting defined twice",
+			data:      "include = [\"trust_domain.conf\"]\nserver { trust_domain = \"example.org\" }",
+			expectErr: `unable to merge configuration at "` + filepath.Join(dir, "trust_domain.conf") + `": "server.trust_domain" is already set`,
+		},
+		{
+			name:      "recursive include",
+			data:      `include = ["loop.conf"]`,
+			expectErr: `configuration at "` + path + `" is included recursively`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(path, tt.data, false)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectErr)
+		})
+	}
+}
+
+func TestParseGlobWithoutMatches(t *testing.T) {
+	file, err := Parse(filepath.Join(t.TempDir(), "server.conf"), `
+include = ["conf.d/*.conf"]
+server { trust_domain = "example.org" }`, false)
+	require.NoError(t, err)
+
+	var c testConfig
+	require.NoError(t, hcl.DecodeObject(&c, file))
+	require.Equal(t, "example.org", c.Server.TrustDomain)
+}
+
+func writeFile(t *testing.T, dir, name, data string) {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+}
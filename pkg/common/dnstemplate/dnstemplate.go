@@ -0,0 +1,168 @@
+// Package dnstemplate renders and matches DNS name templates on registration
+// entries. Templates are rendered by the agent from the selectors of the
+// workload fetching the X509-SVID, since only the agent knows which workload
+// an X509-SVID is for. The server checks that the DNS names requested by the
+// agent are renderings of the entry templates.
+package dnstemplate
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+const (
+	// placeholder is rendered in place of every template value when
+	// validating a template.
+	placeholder = "placeholder"
+
+	// wildcard is rendered in place of every template value when matching a
+	// DNS name against a template. It is not valid in a DNS name, so it can
+	// only come from a template value.
+	wildcard = "*"
+)
+
+// Data is the data available to DNS name templates. It is populated from the
+// workload selectors. Any other selector can be referenced with the selector
+// function, which takes the selector type and key, e.g.
+// {{ selector "k8s:pod-label:app" }}.
+type Data struct {
+	// PodName is the value of the k8s:pod-name selector
+	PodName string
+
+	// Namespace is the value of the k8s:ns selector
+	Namespace string
+
+	// ServiceAccount is the value of the k8s:sa selector
+	ServiceAccount string
+
+	// NodeName is the value of the k8s:node-name selector
+	NodeName string
+
+	// selectors holds the workload selectors as "<type>:<value>" strings
+	selectors []string
+
+	// value, if set, is returned by every selector lookup
+	value string
+}
+
+// selector returns the remainder of the first selector that starts with the
+// given type and key, e.g. "web" for "k8s:pod-label:app" when the workload
+// has the selector "k8s:pod-label:app:web".
+func (d *Data) selector(typeAndKey string) (string, error) {
+	if d.value != "" {
+		return d.value, nil
+	}
+	prefix := typeAndKey + ":"
+	for _, s := range d.selectors {
+		if strings.HasPrefix(s, prefix) {
+			return s[len(prefix):], nil
+		}
+	}
+	return "", fmt.Errorf("no %q selector", typeAndKey)
+}
+
+// IsTemplate returns true if the DNS name is a template.
+func IsTemplate(dnsName string) bool {
+	return strings.Contains(dnsName, "{{")
+}
+
+// Validate validates a DNS name, which may be a template. Templates are
+// validated by rendering them with placeholder values.
+func Validate(dnsName string) error {
+	if !IsTemplate(dnsName) {
+		return x509util.ValidateDNS(dnsName)
+	}
+
+	rendered, err := render(dnsName, valueData(placeholder))
+	if err != nil {
+		return err
+	}
+	if err := x509util.ValidateDNS(rendered); err != nil {
+		return fmt.Errorf("DNS name template %q does not render a valid DNS name: %w", dnsName, err)
+	}
+	return nil
+}
+
+// Render renders a DNS name template with data from the workload selectors.
+func Render(dnsName string, selectors []*common.Selector) (string, error) {
+	data := &Data{
+		selectors: make([]string, 0, len(selectors)),
+	}
+	for _, s := range selectors {
+		data.selectors = append(data.selectors, s.Type+":"+s.Value)
+	}
+	data.PodName, _ = data.selector("k8s:pod-name")
+	data.Namespace, _ = data.selector("k8s:ns")
+	data.ServiceAccount, _ = data.selector("k8s:sa")
+	data.NodeName, _ = data.selector("k8s:node-name")
+
+	rendered, err := render(dnsName, data)
+	if err != nil {
+		return "", err
+	}
+	if err := x509util.ValidateDNS(rendered); err != nil {
+		return "", fmt.Errorf("DNS name template %q rendered invalid DNS name %q: %w", dnsName, rendered, err)
+	}
+	return rendered, nil
+}
+
+// Match returns true if the DNS name is a rendering of the template. Every
+// template value matches all or part of a single DNS label, so values with a
+// "." do not match.
+func Match(template, dnsName string) bool {
+	if x509util.ValidateDNS(dnsName) != nil {
+		return false
+	}
+	pattern, err := render(template, valueData(wildcard))
+	if err != nil {
+		return false
+	}
+
+	patternLabels := strings.Split(pattern, ".")
+	labels := strings.Split(dnsName, ".")
+	if len(patternLabels) != len(labels) {
+		return false
+	}
+	for i, patternLabel := range patternLabels {
+		// DNS labels have no characters special to path.Match other than
+		// the wildcard itself, which is only rendered for template values.
+		ok, err := path.Match(strings.ToLower(patternLabel), strings.ToLower(labels[i]))
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func render(dnsName string, data *Data) (string, error) {
+	tmpl, err := template.New("dns").
+		Funcs(template.FuncMap{"selector": data.selector}).
+		Option("missingkey=error").
+		Parse(dnsName)
+	if err != nil {
+		return "", fmt.Errorf("invalid DNS name template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("unable to render DNS name template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// valueData returns template data where every field, and every selector
+// lookup, renders the given value.
+func valueData(value string) *Data {
+	return &Data{
+		PodName:        value,
+		Namespace:      value,
+		ServiceAccount: value,
+		NodeName:       value,
+		value:          value,
+	}
+}
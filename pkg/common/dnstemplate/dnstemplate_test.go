@@ -0,0 +1,194 @@
+package dnstemplate
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		dnsName string
+		err     string
+	}{
+		{
+			name:    "plain DNS name",
+			dnsName: "foo.example.org",
+		},
+		{
+			name:    "invalid plain DNS name",
+			dnsName: "abc-",
+			err:     "label does not match regex: abc-",
+		},
+		{
+			name:    "template with fields",
+			dnsName: "{{ .PodName }}.{{ .Namespace }}.svc",
+		},
+		{
+			name:    "template with selector",
+			dnsName: `{{ selector "k8s:pod-label:app" }}.svc`,
+		},
+		{
+			name:    "malformed template",
+			dnsName: "{{ .PodName .svc",
+			err:     "invalid DNS name template: template: dns:1: unclosed action",
+		},
+		{
+			name:    "template with unknown field",
+			dnsName: "{{ .Unknown }}.svc",
+			err:     `unable to render DNS name template: template: dns:1:3: executing "dns" at <.Unknown>: can't evaluate field Unknown in type *dnstemplate.Data`,
+		},
+		{
+			name:    "template renders invalid DNS name",
+			dnsName: "{{ .PodName }}-.svc",
+			err:     `DNS name template "{{ .PodName }}-.svc" does not render a valid DNS name: label does not match regex: placeholder-`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.dnsName)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	selectors := []*common.Selector{
+		{Type: "k8s", Value: "ns:payments"},
+		{Type: "k8s", Value: "pod-name:api-0"},
+		{Type: "k8s", Value: "sa:api"},
+		{Type: "k8s", Value: "node-name:node-1"},
+		{Type: "k8s", Value: "pod-label:app:web"},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		dnsName   string
+		selectors []*common.Selector
+		expected  string
+		err       string
+	}{
+		{
+			name:      "fields",
+			dnsName:   "{{ .PodName }}.{{ .Namespace }}.svc",
+			selectors: selectors,
+			expected:  "api-0.payments.svc",
+		},
+		{
+			name:      "service account and node name",
+			dnsName:   "{{ .ServiceAccount }}.{{ .NodeName }}.example.org",
+			selectors: selectors,
+			expected:  "api.node-1.example.org",
+		},
+		{
+			name:      "selector function",
+			dnsName:   `{{ selector "k8s:pod-label:app" }}.{{ .Namespace }}.svc`,
+			selectors: selectors,
+			expected:  "web.payments.svc",
+		},
+		{
+			name:      "missing field selector",
+			dnsName:   "{{ .PodName }}.svc",
+			selectors: []*common.Selector{{Type: "k8s", Value: "ns:payments"}},
+			err:       `DNS name template "{{ .PodName }}.svc" rendered invalid DNS name ".svc": label is empty`,
+		},
+		{
+			name:      "missing selector",
+			dnsName:   `{{ selector "k8s:pod-label:tier" }}.svc`,
+			selectors: selectors,
+			err:       `unable to render DNS name template: template: dns:1:3: executing "dns" at <selector "k8s:pod-label:tier">: error calling selector: no "k8s:pod-label:tier" selector`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := Render(tt.dnsName, tt.selectors)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		template string
+		dnsName  string
+		expected bool
+	}{
+		{
+			name:     "rendering of fields",
+			template: "{{ .PodName }}.{{ .Namespace }}.svc",
+			dnsName:  "api-0.payments.svc",
+			expected: true,
+		},
+		{
+			name:     "rendering with different case",
+			template: "{{ .PodName }}.SVC",
+			dnsName:  "api-0.svc",
+			expected: true,
+		},
+		{
+			name:     "rendering of selector function",
+			template: `{{ selector "k8s:pod-label:app" }}.svc`,
+			dnsName:  "web.svc",
+			expected: true,
+		},
+		{
+			name:     "partial label",
+			template: "{{ .PodName }}-headless.svc",
+			dnsName:  "api-0-headless.svc",
+			expected: true,
+		},
+		{
+			name:     "partial label mismatch",
+			template: "{{ .PodName }}-headless.svc",
+			dnsName:  "api-0.svc",
+			expected: false,
+		},
+		{
+			name:     "value spanning labels",
+			template: "{{ .PodName }}.svc",
+			dnsName:  "api-0.payments.svc",
+			expected: false,
+		},
+		{
+			name:     "literal label mismatch",
+			template: "{{ .PodName }}.svc",
+			dnsName:  "api-0.example",
+			expected: false,
+		},
+		{
+			name:     "plain DNS name",
+			template: "foo.svc",
+			dnsName:  "foo.svc",
+			expected: true,
+		},
+		{
+			name:     "invalid DNS name",
+			template: "{{ .PodName }}.svc",
+			dnsName:  "*.svc",
+			expected: false,
+		},
+		{
+			name:     "malformed template",
+			template: "{{ .PodName .svc",
+			dnsName:  "api-0.svc",
+			expected: false,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Match(tt.template, tt.dnsName))
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// Package fips reports whether SPIRE was built in FIPS mode.
+//
+// FIPS mode is enabled by building with a Go toolchain that supports
+// BoringCrypto and GOEXPERIMENT=boringcrypto (see `make FIPS=1 build`). In
+// that mode all TLS configuration is restricted to FIPS-approved settings.
+package fips
+
+import "errors"
+
+// ErrUnavailable is returned by Check when SPIRE was built in FIPS mode but
+// the BoringCrypto module is not in use on this platform.
+var ErrUnavailable = errors.New("built in FIPS mode but the BoringCrypto module is not available on this platform")
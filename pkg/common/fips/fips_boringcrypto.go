@@ -0,0 +1,24 @@
+//go:build boringcrypto
+
+package fips
+
+import (
+	"crypto/boring"
+
+	// Restrict all TLS configuration to FIPS-approved settings.
+	_ "crypto/tls/fipsonly"
+)
+
+// Enabled returns true if SPIRE was built in FIPS mode.
+func Enabled() bool {
+	return true
+}
+
+// Check returns an error if SPIRE was built in FIPS mode but crypto
+// operations are not handled by the BoringCrypto module.
+func Check() error {
+	if !boring.Enabled() {
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !boringcrypto
+
+package fips
+
+// Enabled returns true if SPIRE was built in FIPS mode.
+func Enabled() bool {
+	return false
+}
+
+// Check returns an error if SPIRE was built in FIPS mode but crypto
+// operations are not handled by the BoringCrypto module.
+func Check() error {
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/InVisionApp/go-health/v2"
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/systemd"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -128,6 +129,14 @@ func (c *checker) ListenAndServe(ctx context.Context) error {
 		}()
 	}
 
+	if interval := systemd.WatchdogInterval(); interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.runWatchdog(ctx, interval/2)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -146,6 +155,31 @@ func (c *checker) ListenAndServe(ctx context.Context) error {
 	return nil
 }
 
+// runWatchdog notifies the systemd watchdog at the given interval for as long
+// as all subsystems are live. If a subsystem stops being live, the watchdog
+// is no longer notified so that systemd restarts the service.
+func (c *checker) runWatchdog(ctx context.Context, interval time.Duration) {
+	c.log.WithField(telemetry.Interval, interval).Info("Notifying the systemd watchdog")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if live, _ := c.LiveState(); !live {
+				c.log.Warn("Not notifying the systemd watchdog; one or more subsystems are not live")
+				continue
+			}
+			if err := systemd.Notify(systemd.Watchdog); err != nil {
+				c.log.WithError(err).Warn("Failed to notify the systemd watchdog")
+			}
+		}
+	}
+}
+
 // WaitForTestDial tries to create a client connection to the given target
 // with a blocking dial and a timeout specified in testDialTimeout.
 // Nothing is done with the connection, which is just closed in case it
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package health
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/systemd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWatchdog(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+
+	log, _ := logtest.NewNullLogger()
+	checker := NewChecker(Config{}, log).(*checker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.runWatchdog(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Minute)))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, systemd.Watchdog, string(buf[:n]))
+
+	cancel()
+	<-done
+}
@@ -235,6 +235,21 @@ func (s *TokenSuite) TestValidateExpiredToken() {
 	s.Require().Nil(claims)
 }
 
+func (s *TokenSuite) TestValidateWithClockSkew() {
+	token, err := s.signer.SignToken(fakeSpiffeID, fakeAudience, time.Now().Add(-2*time.Minute), ec256Key, "ec256Key")
+	s.Require().NoError(err)
+
+	// Expired beyond the default leeway
+	_, _, err = ValidateTokenWithClockSkew(ctx, token, s.bundle, fakeAudience[0:1], 0)
+	s.Require().EqualError(err, "token has expired")
+
+	// Within the allowed clock skew
+	spiffeID, claims, err := ValidateTokenWithClockSkew(ctx, token, s.bundle, fakeAudience[0:1], 2*time.Minute)
+	s.Require().NoError(err)
+	s.Require().Equal(fakeSpiffeID, spiffeID)
+	s.Require().NotEmpty(claims)
+}
+
 func (s *TokenSuite) TestValidateNoSubject() {
 	token := s.signToken(jose.ES256, jose.JSONWebKey{Key: ec256Key, KeyID: "ec256Key"}, jwt.Claims{
 		Audience: []string{"audience"},
@@ -40,6 +40,13 @@ func (t *keyStore) FindPublicKey(ctx context.Context, td spiffeid.TrustDomain, k
 }
 
 func ValidateToken(ctx context.Context, token string, keyStore KeyStore, audience []string) (spiffeid.ID, map[string]interface{}, error) {
+	return ValidateTokenWithClockSkew(ctx, token, keyStore, audience, 0)
+}
+
+// ValidateTokenWithClockSkew validates the token like ValidateToken, allowing
+// for the given clock skew, in addition to the default leeway of one minute,
+// when validating the time based claims of the token.
+func ValidateTokenWithClockSkew(ctx context.Context, token string, keyStore KeyStore, audience []string, clockSkew time.Duration) (spiffeid.ID, map[string]interface{}, error) {
 	tok, err := jwt.ParseSigned(token)
 	if err != nil {
 		return spiffeid.ID{}, nil, errs.New("unable to parse JWT token")
@@ -94,10 +101,10 @@ func ValidateToken(ctx context.Context, token string, keyStore KeyStore, audienc
 
 	// Now that the signature over the claims has been verified, validate the
 	// standard claims.
-	if err := claims.Validate(jwt.Expected{
+	if err := claims.ValidateWithLeeway(jwt.Expected{
 		Audience: audience,
 		Time:     time.Now(),
-	}); err != nil {
+	}, jwt.DefaultLeeway+clockSkew); err != nil {
 		// Convert expected validation errors for pretty errors
 		switch {
 		case errors.Is(err, jwt.ErrExpired):
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -104,3 +105,32 @@ func TestReopenableOutputFile(t *testing.T) {
 		}
 	}
 }
+
+func TestSourceLocation(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger, err := NewLogger(WithFormat(JSONFormat), WithSourceLocation())
+	require.NoError(t, err)
+	logger.SetOutput(buf)
+
+	logger.Warning("with source location")
+
+	var data map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "github.com/spiffe/spire/pkg/common/log.TestSourceLocation", data["func"])
+	assert.Contains(t, data["file"], "log_test.go:")
+}
+
+func TestSourceLocationOmittedForPluginLogs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger, err := NewLogger(WithFormat(JSONFormat), WithSourceLocation())
+	require.NoError(t, err)
+	logger.SetOutput(buf)
+
+	NewHCLogAdapter(logger, "plugin").Warn("from plugin")
+
+	var data map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "from plugin", data["msg"])
+	assert.NotContains(t, data, "func")
+	assert.NotContains(t, data, "file")
+}
@@ -3,6 +3,7 @@ package log
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -76,6 +77,37 @@ func WithFormat(format string) Option {
 	}
 }
 
+// WithSourceLocation adds the calling function, file and line to each log
+// entry. Entries logged through the HCLogAdapter (i.e. by plugins) are left
+// without a source location, since the caller is always the adapter itself.
+func WithSourceLocation() Option {
+	return func(logger *Logger) error {
+		logger.SetReportCaller(true)
+		logger.AddHook(sourceLocationHook{})
+		return nil
+	}
+}
+
+// hclogAdapterFuncPrefix is the prefix of the function names of the
+// HCLogAdapter methods, as reported by the runtime.
+var hclogAdapterFuncPrefix = reflect.TypeOf(HCLogAdapter{}).PkgPath() + ".(*HCLogAdapter)."
+
+// sourceLocationHook drops the caller from entries logged through the
+// HCLogAdapter, which would otherwise point at the adapter rather than at
+// the plugin source that emitted the log.
+type sourceLocationHook struct{}
+
+func (sourceLocationHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (sourceLocationHook) Fire(entry *logrus.Entry) error {
+	if entry.Caller != nil && strings.HasPrefix(entry.Caller.Function, hclogAdapterFuncPrefix) {
+		entry.Caller = nil
+	}
+	return nil
+}
+
 func WithLevel(logLevel string) Option {
 	return func(logger *Logger) error {
 		level, err := logrus.ParseLevel(logLevel)
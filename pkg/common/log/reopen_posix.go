@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -15,7 +17,7 @@ const (
 )
 
 // ReopenOnSignal returns a function compatible with RunTasks.
-func ReopenOnSignal(logger *Logger, reopener Reopener) func(context.Context) error {
+func ReopenOnSignal(logger logrus.FieldLogger, reopener Reopener) func(context.Context) error {
 	return func(ctx context.Context) error {
 		signalCh := make(chan os.Signal, 1)
 		signal.Notify(signalCh, reopenSignal)
@@ -25,7 +27,7 @@ func ReopenOnSignal(logger *Logger, reopener Reopener) func(context.Context) err
 
 func reopenOnSignal(
 	ctx context.Context,
-	logger *Logger,
+	logger logrus.FieldLogger,
 	reopener Reopener,
 	signalCh chan os.Signal,
 ) error {
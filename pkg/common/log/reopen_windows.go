@@ -4,11 +4,13 @@ package log
 
 import (
 	"context"
+
+	"github.com/sirupsen/logrus"
 )
 
 // ReopenOnSignal returns a noop function compatible with RunTasks since
 // windows does not have signals as on *nix.
-func ReopenOnSignal(logger *Logger, reopener Reopener) func(context.Context) error {
+func ReopenOnSignal(logger logrus.FieldLogger, reopener Reopener) func(context.Context) error {
 	return func(ctx context.Context) error {
 		<-ctx.Done()
 		return nil
@@ -1,14 +1,89 @@
 package nodeutil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+// RenewAgentSVIDHeader is the gRPC response header through which the Server
+// asks an Agent to renew its agent SVID when fetching its authorized entries.
+const RenewAgentSVIDHeader = "spire-renew-agent-svid"
+
+// BundleDigestHeader is the gRPC header carrying the digest of a bundle. The
+// Server returns the digest of the bundle it sends, and the Agent sends back
+// the digest of the bundle it already has when fetching the bundle again.
+const BundleDigestHeader = "spire-bundle-digest"
+
+// BundleUnchangedHeader is the gRPC response header through which the Server
+// tells an Agent that the bundle it already has is up to date. The bundle in
+// the response only has the trust domain set in that case.
+const BundleUnchangedHeader = "spire-bundle-unchanged"
+
+// AgentVersionHeader is the gRPC header through which an Agent sends its
+// version to the Server on every call.
+const AgentVersionHeader = "spire-agent-version"
+
+// ServerVersionHeader is the gRPC response header through which the Server
+// sends its version to the Agent.
+const ServerVersionHeader = "spire-server-version"
+
+// CapabilitiesHeader is the gRPC header through which the Agent and the Server
+// advertise the protocol capabilities they support, as a comma separated list.
+// A peer that does not send the header predates capability negotiation.
+const CapabilitiesHeader = "spire-capabilities"
+
+const (
+	// CapabilityRenewAgentSVID is the ability to request an agent SVID
+	// renewal through the RenewAgentSVIDHeader.
+	CapabilityRenewAgentSVID = "renew_agent_svid"
+
+	// CapabilityBundleDigest is the ability to skip sending unchanged bundles
+	// through the BundleDigestHeader and BundleUnchangedHeader.
+	CapabilityBundleDigest = "bundle_digest"
+)
+
+// Capabilities returns the protocol capabilities supported by this version of
+// SPIRE, in the format used by the CapabilitiesHeader.
+func Capabilities() string {
+	return strings.Join([]string{
+		CapabilityRenewAgentSVID,
+		CapabilityBundleDigest,
+	}, ",")
+}
+
+// HasCapability returns true if the peer advertised the given capability
+// through the CapabilitiesHeader in the metadata.
+func HasCapability(md metadata.MD, capability string) bool {
+	for _, value := range md.Get(CapabilitiesHeader) {
+		for _, c := range strings.Split(value, ",") {
+			if strings.TrimSpace(c) == capability {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BundleDigest returns the digest of a bundle, used to detect whether the
+// bundle an Agent has differs from the bundle on the Server.
+func BundleDigest(bundle *types.Bundle) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(bundle)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // IsAgentBanned determines if a given attested node is banned or not.
 // An agent is considered as "banned" if its X509 SVID serial number is empty.
 func IsAgentBanned(node *common.AttestedNode) bool {
@@ -10,6 +10,7 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
@@ -19,6 +20,40 @@ func TestIsAgentBanned(t *testing.T) {
 	require.False(t, nodeutil.IsAgentBanned(&common.AttestedNode{CertSerialNumber: "non-empty-serial"}))
 }
 
+func TestBundleDigest(t *testing.T) {
+	bundle := &types.Bundle{
+		TrustDomain:     "example.org",
+		X509Authorities: []*types.X509Certificate{{Asn1: []byte{1, 2, 3}}},
+		SequenceNumber:  1,
+	}
+
+	digest, err := nodeutil.BundleDigest(bundle)
+	require.NoError(t, err)
+	require.Len(t, digest, 64)
+
+	sameDigest, err := nodeutil.BundleDigest(proto.Clone(bundle).(*types.Bundle))
+	require.NoError(t, err)
+	require.Equal(t, digest, sameDigest)
+
+	bundle.SequenceNumber = 2
+	otherDigest, err := nodeutil.BundleDigest(bundle)
+	require.NoError(t, err)
+	require.NotEqual(t, digest, otherDigest)
+}
+
+func TestHasCapability(t *testing.T) {
+	md := metadata.Pairs(nodeutil.CapabilitiesHeader, nodeutil.Capabilities())
+	require.True(t, nodeutil.HasCapability(md, nodeutil.CapabilityRenewAgentSVID))
+	require.True(t, nodeutil.HasCapability(md, nodeutil.CapabilityBundleDigest))
+	require.False(t, nodeutil.HasCapability(md, "unknown"))
+
+	md = metadata.Pairs(nodeutil.CapabilitiesHeader, "foo, bar", nodeutil.CapabilitiesHeader, "baz")
+	require.True(t, nodeutil.HasCapability(md, "bar"))
+	require.True(t, nodeutil.HasCapability(md, "baz"))
+
+	require.False(t, nodeutil.HasCapability(metadata.MD{}, nodeutil.CapabilityBundleDigest))
+}
+
 func TestShouldAgentReattest(t *testing.T) {
 	agentExpired := &types.PermissionDeniedDetails{
 		Reason: types.PermissionDeniedDetails_AGENT_EXPIRED,
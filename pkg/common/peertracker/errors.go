@@ -3,6 +3,7 @@ package peertracker
 import "errors"
 
 var (
+	ErrCallerNotVisible     = errors.New("caller is not visible in the PID namespace of this process")
 	ErrInvalidConnection    = errors.New("invalid connection")
 	ErrUnsupportedPlatform  = errors.New("unsupported platform")
 	ErrUnsupportedTransport = errors.New("unsupported transport")
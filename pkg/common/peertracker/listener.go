@@ -10,15 +10,21 @@ import (
 var _ net.Listener = &Listener{}
 
 type ListenerFactory struct {
-	Log               logrus.FieldLogger
-	NewTracker        func(log logrus.FieldLogger) (PeerTracker, error)
+	Log        logrus.FieldLogger
+	NewTracker func(log logrus.FieldLogger) (PeerTracker, error)
+	// AuthorizeCaller is an optional callback used to reject callers before
+	// their connection is handed to the server. It is called once the caller
+	// is being watched, and the caller is rejected if it is no longer alive
+	// after the callback returns.
+	AuthorizeCaller   func(CallerInfo) error
 	ListenerFactoryOS // OS specific
 }
 
 type Listener struct {
-	l       net.Listener
-	log     logrus.FieldLogger
-	Tracker PeerTracker
+	l               net.Listener
+	log             logrus.FieldLogger
+	authorizeCaller func(CallerInfo) error
+	Tracker         PeerTracker
 }
 
 func newNoopLogger() *logrus.Logger {
@@ -60,6 +66,25 @@ func (l *Listener) Accept() (net.Conn, error) {
 			continue
 		}
 
+		if l.authorizeCaller != nil {
+			if err := l.authorizeCaller(caller); err != nil {
+				l.log.WithError(err).Warn("Connection rejected during accept")
+				watcher.Close()
+				conn.Close()
+				continue
+			}
+
+			// The policy reads process information by PID. Make sure the
+			// caller is still the process that was authorized, and that its
+			// PID was not reused while the policy was evaluated.
+			if err := watcher.IsAlive(); err != nil {
+				l.log.WithError(err).Warn("Connection rejected during accept")
+				watcher.Close()
+				conn.Close()
+				continue
+			}
+		}
+
 		wrappedConn := &Conn{
 			Conn: conn,
 			Info: AuthInfo{
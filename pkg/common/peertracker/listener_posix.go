@@ -35,8 +35,9 @@ func (lf *ListenerFactory) listenUnix(network string, laddr *net.UnixAddr) (*Lis
 	}
 
 	return &Listener{
-		l:       l,
-		Tracker: tracker,
-		log:     lf.Log,
+		l:               l,
+		Tracker:         tracker,
+		log:             lf.Log,
+		authorizeCaller: lf.AuthorizeCaller,
 	}, nil
 }
@@ -30,6 +30,35 @@ func newFailingMockTracker(_ logrus.FieldLogger) (PeerTracker, error) {
 	return failingMockTracker{}, nil
 }
 
+var errMockCallerExited = errors.New("caller exited")
+
+// exitingMockTracker returns watchers for callers that exit once the caller
+// policy has been evaluated.
+type exitingMockTracker struct {
+	watcher *exitingMockWatcher
+}
+
+func (exitingMockTracker) Close() {}
+func (t exitingMockTracker) NewWatcher(CallerInfo) (Watcher, error) {
+	return t.watcher, nil
+}
+
+type exitingMockWatcher struct {
+	exited chan struct{}
+	closed chan struct{}
+}
+
+func (w *exitingMockWatcher) Close()     { close(w.closed) }
+func (w *exitingMockWatcher) PID() int32 { return 1 }
+func (w *exitingMockWatcher) IsAlive() error {
+	select {
+	case <-w.exited:
+		return errMockCallerExited
+	default:
+		return nil
+	}
+}
+
 func TestListenerTestSuite(t *testing.T) {
 	suite.Run(t, new(ListenerTestSuite))
 }
@@ -134,6 +163,104 @@ func (p *ListenerTestSuite) TestAcceptDoesntFailWhenTrackerFails() {
 	}
 }
 
+func (p *ListenerTestSuite) TestAcceptRejectsUnauthorizedCaller() {
+	var err error
+	logger, hook := test.NewNullLogger()
+	logger.Level = logrus.WarnLevel
+	lf := ListenerFactory{
+		Log: logger,
+		AuthorizeCaller: func(caller CallerInfo) error {
+			return errors.New("caller not allowed")
+		},
+	}
+	p.ul, err = lf.ListenUnix(p.unixAddr.Network(), p.unixAddr)
+	p.Require().NoError(err)
+
+	clientDone := make(chan error)
+	peer := newFakePeer(p.T())
+	peer.connect(p.unixAddr, clientDone)
+
+	acceptCh := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := p.ul.Accept()
+		acceptCh <- conn
+	}()
+
+	p.Require().Eventually(func() bool {
+		logEntry := hook.LastEntry()
+		return logEntry != nil && logEntry.Message == "Connection rejected during accept"
+	}, time.Second, 10*time.Millisecond)
+	p.Require().EqualError(hook.LastEntry().Data["error"].(error), "caller not allowed")
+
+	p.Require().NoError(p.ul.Close())
+	p.ul = nil
+
+	// The rejected connection is never handed to the caller of Accept
+	select {
+	case conn := <-acceptCh:
+		p.Require().Nil(conn)
+	case <-time.After(time.Second):
+		p.Require().Fail("waited too long for listener to close")
+	}
+}
+
+func (p *ListenerTestSuite) TestAcceptRejectsCallerThatExitsDuringAuthorization() {
+	var err error
+	logger, hook := test.NewNullLogger()
+	logger.Level = logrus.WarnLevel
+	watcher := &exitingMockWatcher{
+		exited: make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	lf := ListenerFactory{
+		Log: logger,
+		NewTracker: func(logrus.FieldLogger) (PeerTracker, error) {
+			return exitingMockTracker{watcher: watcher}, nil
+		},
+		AuthorizeCaller: func(caller CallerInfo) error {
+			// The caller is authorized, but exits (and its PID may be
+			// reused) before the connection is accepted
+			close(watcher.exited)
+			return nil
+		},
+	}
+	p.ul, err = lf.ListenUnix(p.unixAddr.Network(), p.unixAddr)
+	p.Require().NoError(err)
+
+	clientDone := make(chan error)
+	peer := newFakePeer(p.T())
+	peer.connect(p.unixAddr, clientDone)
+
+	acceptCh := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := p.ul.Accept()
+		acceptCh <- conn
+	}()
+
+	p.Require().Eventually(func() bool {
+		logEntry := hook.LastEntry()
+		return logEntry != nil && logEntry.Message == "Connection rejected during accept"
+	}, time.Second, 10*time.Millisecond)
+	p.Require().Equal(errMockCallerExited, hook.LastEntry().Data["error"])
+
+	// The watcher of the rejected connection is released
+	select {
+	case <-watcher.closed:
+	case <-time.After(time.Second):
+		p.Require().Fail("watcher was not closed")
+	}
+
+	p.Require().NoError(p.ul.Close())
+	p.ul = nil
+
+	select {
+	case conn := <-acceptCh:
+		p.Require().Nil(conn)
+	case <-time.After(time.Second):
+		p.Require().Fail("waited too long for listener to close")
+	}
+}
+
 func (p *ListenerTestSuite) TestAcceptFailsWhenUnderlyingAcceptFails() {
 	lf := ListenerFactory{
 		NewTracker: newFailingMockTracker,
@@ -39,8 +39,9 @@ func (lf *ListenerFactory) listenPipe(pipe string, pipeConfig *winio.PipeConfig)
 	}
 
 	return &Listener{
-		l:       l,
-		Tracker: tracker,
-		log:     lf.Log,
+		l:               l,
+		Tracker:         tracker,
+		log:             lf.Log,
+		authorizeCaller: lf.AuthorizeCaller,
 	}, nil
 }
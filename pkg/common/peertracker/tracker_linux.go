@@ -47,9 +47,11 @@ type linuxWatcher struct {
 }
 
 func newLinuxWatcher(info CallerInfo, log logrus.FieldLogger) (*linuxWatcher, error) {
-	// If PID == 0, something is wrong...
+	// The kernel reports a PID of zero when the caller runs in a PID
+	// namespace that is not visible from ours, e.g. when the agent runs in a
+	// container that does not share the host PID namespace.
 	if info.PID == 0 {
-		return nil, errors.New("could not resolve caller information")
+		return nil, ErrCallerNotVisible
 	}
 
 	procPath := fmt.Sprintf("/proc/%v", info.PID)
@@ -74,6 +76,15 @@ func newLinuxWatcher(info CallerInfo, log logrus.FieldLogger) (*linuxWatcher, er
 		telemetry.StartTime: starttime,
 	})
 
+	// Callers in a nested PID namespace (e.g. in a container) know
+	// themselves by a different PID, which is logged to ease correlation.
+	if pidNS, pidInNS, ok := getPIDNamespace(info.PID); ok {
+		log = log.WithFields(logrus.Fields{
+			telemetry.PIDNamespace:   pidNS,
+			telemetry.PIDInNamespace: pidInNS,
+		})
+	}
+
 	return &linuxWatcher{
 		gid:       info.GID,
 		pid:       info.PID,
@@ -206,3 +217,43 @@ func getStarttime(pid int32) (string, error) {
 
 	return statFields[21], nil
 }
+
+// getPIDNamespace returns the PID namespace of the process and its PID in
+// that namespace. The boolean is false if the process shares the PID
+// namespace of the current process or if the namespace cannot be determined.
+func getPIDNamespace(pid int32) (string, string, bool) {
+	pidNS, err := os.Readlink(fmt.Sprintf("/proc/%v/ns/pid", pid))
+	if err != nil {
+		return "", "", false
+	}
+	selfPIDNS, err := os.Readlink("/proc/self/ns/pid")
+	if err != nil || pidNS == selfPIDNS {
+		return "", "", false
+	}
+
+	statusBytes, err := os.ReadFile(fmt.Sprintf("/proc/%v/status", pid))
+	if err != nil {
+		return "", "", false
+	}
+	pidInNS, ok := parseNSpid(string(statusBytes))
+	if !ok {
+		return "", "", false
+	}
+	return pidNS, pidInNS, true
+}
+
+// parseNSpid returns the PID of the process in its innermost PID namespace
+// from the NSpid field of the proc status data, available since Linux 4.1
+func parseNSpid(status string) (string, bool) {
+	for _, line := range strings.Split(status, "\n") {
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		pids := strings.Fields(strings.TrimPrefix(line, "NSpid:"))
+		if len(pids) == 0 {
+			return "", false
+		}
+		return pids[len(pids)-1], true
+	}
+	return "", false
+}
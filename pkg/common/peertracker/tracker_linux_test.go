@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +40,40 @@ func TestParseTaskStat(t *testing.T) {
 		assert.Equal(err, tt.err)
 	}
 }
+
+func TestParseNSpid(t *testing.T) {
+	tests := []struct {
+		status  string
+		pidInNS string
+		ok      bool
+	}{
+		{
+			status:  "Name:\tcat\nPid:\t4321\nNSpid:\t4321\n",
+			pidInNS: "4321",
+			ok:      true,
+		},
+		{
+			status:  "Name:\tcat\nPid:\t4321\nNSpid:\t4321\t12\t1\n",
+			pidInNS: "1",
+			ok:      true,
+		},
+		{
+			status: "Name:\tcat\nPid:\t4321\n",
+		},
+		{
+			status: "NSpid:\n",
+		},
+	}
+
+	assert := assert.New(t)
+	for _, tt := range tests {
+		pidInNS, ok := parseNSpid(tt.status)
+		assert.Equal(tt.pidInNS, pidInNS)
+		assert.Equal(tt.ok, ok)
+	}
+}
+
+func TestNewLinuxWatcherCallerNotVisible(t *testing.T) {
+	_, err := newLinuxWatcher(CallerInfo{PID: 0}, logrus.New())
+	assert.ErrorIs(t, err, ErrCallerNotVisible)
+}
@@ -0,0 +1,28 @@
+package oidc
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/idutil"
+)
+
+const (
+	PluginName = "oidc"
+)
+
+// AttestationData is the payload sent by the agent.
+type AttestationData struct {
+	// Token is the OIDC ID token of the node.
+	Token string `json:"token"`
+}
+
+// MakeAgentID makes the agent SPIFFE ID of a node attested with a token from
+// the named issuer. The ID is a claim of the token that identifies the node.
+func MakeAgentID(td spiffeid.TrustDomain, issuerName, id string) (spiffeid.ID, error) {
+	if strings.Contains(id, "/") {
+		return spiffeid.ID{}, errors.New("agent ID claim value cannot contain a slash")
+	}
+	return idutil.AgentID(td, "/"+PluginName+"/"+issuerName+"/"+id)
+}
@@ -0,0 +1,37 @@
+package vsphere
+
+import (
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	PluginName = "vsphere"
+
+	// DefaultGuestInfoKey is the guestinfo variable of the VM the identity
+	// document is read from.
+	DefaultGuestInfoKey = "guestinfo.spire.identity"
+
+	// DefaultAudience is the audience identity documents are expected to
+	// be issued for.
+	DefaultAudience = "spire-vsphere-node-attestor"
+)
+
+// IdentityDocument holds the claims of the identity document of a VM. The
+// document is a JWT signed by the automation that provisions the VM, which
+// stores it in the guestinfo of the VM.
+type IdentityDocument struct {
+	jwt.Claims
+
+	VMUUID       string   `json:"vm_uuid"`
+	Datacenter   string   `json:"datacenter,omitempty"`
+	Cluster      string   `json:"cluster,omitempty"`
+	ResourcePool string   `json:"resource_pool,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// MakeAgentID makes the agent SPIFFE ID of the VM with the given UUID.
+func MakeAgentID(td spiffeid.TrustDomain, vmUUID string) (spiffeid.ID, error) {
+	return idutil.AgentID(td, "/"+PluginName+"/"+vmUUID)
+}
@@ -264,6 +264,35 @@ func Fingerprint(cert *x509.Certificate) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// BuildSelectorValues builds the selector values for a verified X.509
+// certificate and the chains it was verified with.
+func BuildSelectorValues(leaf *x509.Certificate, chains [][]*x509.Certificate) []string {
+	selectorValues := []string{}
+
+	if leaf.Subject.CommonName != "" {
+		selectorValues = append(selectorValues, "subject:cn:"+leaf.Subject.CommonName)
+	}
+
+	// Used to avoid duplicating selectors.
+	fingerprints := map[string]*x509.Certificate{}
+	for _, chain := range chains {
+		// Iterate over all the certs in the chain (skip leaf at the 0 index)
+		for _, cert := range chain[1:] {
+			fp := Fingerprint(cert)
+			// If the same fingerprint is generated, continue with the next certificate, because
+			// a selector should have been already created for it.
+			if _, ok := fingerprints[fp]; ok {
+				continue
+			}
+			fingerprints[fp] = cert
+
+			selectorValues = append(selectorValues, "ca:fingerprint:"+fp)
+		}
+	}
+
+	return selectorValues
+}
+
 // MakeAgentID creates an agent ID from X.509 certificate data.
 func MakeAgentID(td spiffeid.TrustDomain, agentPathTemplate *agentpathtemplate.Template, cert *x509.Certificate) (spiffeid.ID, error) {
 	agentPath, err := agentPathTemplate.Execute(agentPathTemplateData{
@@ -13,6 +13,12 @@ func ShouldRotateX509(now time.Time, cert *x509.Certificate) bool {
 	return shouldRotate(now, cert.NotBefore, cert.NotAfter)
 }
 
+// X509RotationTime returns the time at which the given certificate is due
+// for rotation, i.e. once half of its lifetime has elapsed.
+func X509RotationTime(cert *x509.Certificate) time.Time {
+	return rotationTime(cert.NotBefore, cert.NotAfter)
+}
+
 // X509Expired returns true if the given X509 cert has expired
 func X509Expired(now time.Time, cert *x509.Certificate) bool {
 	return now.After(cert.NotAfter)
@@ -37,7 +43,10 @@ func JWTSVIDExpired(svid *client.JWTSVID, now time.Time) bool {
 }
 
 func shouldRotate(now, beginTime, expiryTime time.Time) bool {
-	ttl := expiryTime.Sub(now)
+	return !now.Before(rotationTime(beginTime, expiryTime))
+}
+
+func rotationTime(beginTime, expiryTime time.Time) time.Time {
 	lifetime := expiryTime.Sub(beginTime)
-	return ttl <= lifetime/2
+	return expiryTime.Add(-lifetime / 2)
 }
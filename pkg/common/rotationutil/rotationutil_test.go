@@ -31,6 +31,21 @@ func TestShouldRotateX509(t *testing.T) {
 	assert.True(t, ShouldRotateX509(mockClk.Now(), badCert))
 }
 
+func TestX509RotationTime(t *testing.T) {
+	mockClk := clock.NewMock(t)
+	temp, err := util.NewSVIDTemplate(mockClk, "spiffe://example.org/test")
+	require.NoError(t, err)
+	temp.NotBefore = mockClk.Now()
+	temp.NotAfter = mockClk.Now().Add(5 * time.Minute)
+	cert, _, err := util.SelfSign(temp)
+	require.NoError(t, err)
+
+	rotationTime := X509RotationTime(cert)
+	assert.True(t, mockClk.Now().Add(150*time.Second).Equal(rotationTime))
+	assert.False(t, ShouldRotateX509(rotationTime.Add(-time.Second), cert))
+	assert.True(t, ShouldRotateX509(rotationTime, cert))
+}
+
 func TestX509Expired(t *testing.T) {
 	// Cert that's valid for 1hr
 	mockClk := clock.NewMock(t)
@@ -0,0 +1,46 @@
+// Package systemd implements the systemd service notification protocol
+// (sd_notify), used by services started with Type=notify and by the systemd
+// watchdog.
+package systemd
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// Ready tells the service manager that service startup is finished.
+	Ready = "READY=1"
+
+	// Watchdog tells the service manager to update the watchdog timestamp.
+	Watchdog = "WATCHDOG=1"
+
+	notifySocketEnv = "NOTIFY_SOCKET"
+	watchdogUSecEnv = "WATCHDOG_USEC"
+	watchdogPIDEnv  = "WATCHDOG_PID"
+)
+
+// Notify sends the given state to the service manager. It is a no-op if the
+// process was not started by a service manager expecting notifications.
+func Notify(state string) error {
+	socketPath := os.Getenv(notifySocketEnv)
+	if socketPath == "" {
+		return nil
+	}
+	return notify(socketPath, state)
+}
+
+// WatchdogInterval returns the interval at which the service manager expects
+// to be notified with Watchdog. It returns zero if the watchdog is not
+// enabled for this process.
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv(watchdogPIDEnv); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv(watchdogUSecEnv), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package systemd
+
+import (
+	"net"
+)
+
+func notify(socketPath, state string) error {
+	// A leading "@" denotes a socket in the abstract namespace.
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
@@ -0,0 +1,84 @@
+//go:build !windows
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Run("no socket", func(t *testing.T) {
+		t.Setenv(notifySocketEnv, "")
+		require.NoError(t, Notify(Ready))
+	})
+
+	t.Run("socket", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "notify.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		t.Setenv(notifySocketEnv, socketPath)
+		require.NoError(t, Notify(Ready))
+
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, Ready, string(buf[:n]))
+	})
+
+	t.Run("socket does not exist", func(t *testing.T) {
+		t.Setenv(notifySocketEnv, filepath.Join(t.TempDir(), "notify.sock"))
+		require.Error(t, Notify(Ready))
+	})
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		usec     string
+		pid      string
+		expected time.Duration
+	}{
+		{
+			name: "not enabled",
+		},
+		{
+			name:     "enabled",
+			usec:     "30000000",
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "enabled for this process",
+			usec:     "30000000",
+			pid:      "self",
+			expected: 30 * time.Second,
+		},
+		{
+			name: "enabled for another process",
+			usec: "30000000",
+			pid:  "1",
+		},
+		{
+			name: "invalid",
+			usec: "soon",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pid == "self" {
+				tt.pid = strconv.Itoa(os.Getpid())
+			}
+			t.Setenv(watchdogUSecEnv, tt.usec)
+			t.Setenv(watchdogPIDEnv, tt.pid)
+			require.Equal(t, tt.expected, WatchdogInterval())
+		})
+	}
+}
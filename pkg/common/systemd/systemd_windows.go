@@ -0,0 +1,7 @@
+//go:build windows
+
+package systemd
+
+func notify(string, string) error {
+	return nil
+}
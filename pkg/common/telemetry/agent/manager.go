@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"time"
+
 	"github.com/spiffe/spire/pkg/common/telemetry"
 )
 
@@ -46,3 +48,59 @@ func AddCacheManagerOutdatedSVIDsSample(m telemetry.Metrics, cacheType string, c
 }
 
 // End Add Samples
+
+// Gauges (values that can go up and down)
+
+// SetCacheManagerEntriesGauge sets the number of registration entries synced
+// to the agent cache manager
+func SetCacheManagerEntriesGauge(m telemetry.Metrics, cacheType string, count int) {
+	key := []string{telemetry.CacheManager, telemetry.Entries}
+	if cacheType != "" {
+		key = append(key, cacheType)
+	}
+	m.SetGauge(key, float32(count))
+}
+
+// SetCacheManagerX509SVIDsGauge sets the number of X509-SVIDs held by the
+// agent cache manager
+func SetCacheManagerX509SVIDsGauge(m telemetry.Metrics, count int) {
+	m.SetGauge([]string{telemetry.CacheManager, telemetry.X509SVIDs}, float32(count))
+}
+
+// SetCacheManagerSubscribersGauge sets the number of active subscribers to
+// workload updates (i.e. Workload API streams) in the agent cache manager
+func SetCacheManagerSubscribersGauge(m telemetry.Metrics, count int) {
+	m.SetGauge([]string{telemetry.CacheManager, telemetry.Subscribers}, float32(count))
+}
+
+// SetManagerDegradedGauge sets whether the agent manager is in degraded
+// mode, i.e. unable to synchronize with the server
+func SetManagerDegradedGauge(m telemetry.Metrics, degraded bool) {
+	var val float32
+	if degraded {
+		val = 1
+	}
+	m.SetGauge([]string{telemetry.Manager, telemetry.Degraded}, val)
+}
+
+// SetManagerDegradedSecondsGauge sets the number of seconds the agent
+// manager has been in degraded mode
+func SetManagerDegradedSecondsGauge(m telemetry.Metrics, d time.Duration) {
+	m.SetGauge([]string{telemetry.Manager, telemetry.Degraded, telemetry.Seconds}, float32(d.Seconds()))
+}
+
+// End Gauges
+
+// Counters (literal increments, not call counters)
+
+// IncrCacheManagerRenewedX509SVIDsCounter counts the X509-SVIDs renewed by
+// the agent cache manager
+func IncrCacheManagerRenewedX509SVIDsCounter(m telemetry.Metrics, cacheType string, count int) {
+	key := []string{telemetry.CacheManager, telemetry.RenewedX509SVIDs}
+	if cacheType != "" {
+		key = append(key, cacheType)
+	}
+	m.IncrCounter(key, float32(count))
+}
+
+// End Counters
@@ -37,6 +37,12 @@ func SetConnectionTotalGauge(m telemetry.Metrics, connections int32) {
 	m.SetGauge([]string{telemetry.WorkloadAPI, telemetry.Connections}, float32(connections))
 }
 
+// IncrAttestationCacheHitCounter indicate Workload
+// API reused the cached selectors of a workload instead of attesting it
+func IncrAttestationCacheHitCounter(m telemetry.Metrics) {
+	m.IncrCounter([]string{telemetry.WorkloadAPI, telemetry.WorkloadAttestation, telemetry.Cache}, 1)
+}
+
 // End Counters
 
 // Add Samples (metric on count of some object, entries, event...)
@@ -1,6 +1,7 @@
 package telemetry
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -51,6 +52,16 @@ func (c *CallCounter) AddLabel(name, value string) {
 	c.mu.Unlock()
 }
 
+// Name returns the name of the call, i.e. its metric key joined by dots.
+func (c *CallCounter) Name() string {
+	return strings.Join(c.key, ".")
+}
+
+// Elapsed returns the time elapsed since the call started.
+func (c *CallCounter) Elapsed() time.Duration {
+	return time.Since(c.start)
+}
+
 // Done finishes the "call" and emits metrics. No other calls to the CallCounter
 // should be done during or after the call to Done. In other words, it is not
 // thread-safe and is intended to be the final call to the CallCounter struct.
@@ -23,11 +23,14 @@ type FileConfig struct {
 	AllowedLabels   []string `hcl:"AllowedLabels"`   // A list of metric labels to allow, with '.' as the separator
 	BlockedLabels   []string `hcl:"BlockedLabels"`   // A list of metric labels to block, with '.' as the separator
 
+	MetricPrefix string `hcl:"MetricPrefix"` // Prefix for metric names, overriding the service name
+
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
 
 type DogStatsdConfig struct {
 	Address    string   `hcl:"address"`
+	Tags       []string `hcl:"tags"` // Constant tags added to every metric, e.g. "env:prod"
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
 
@@ -38,7 +41,20 @@ type PrometheusConfig struct {
 }
 
 type StatsdConfig struct {
-	Address    string   `hcl:"address"`
+	Address string `hcl:"address"`
+
+	// SampleRate is the rate at which counters and samples are sent, in
+	// (0, 1]. Defaults to 1, i.e. every metric is sent.
+	SampleRate *float64 `hcl:"sample_rate"`
+
+	// SampleRates overrides the sample rate of the metrics with the given
+	// prefixes, with '.' as the separator. The longest matching prefix wins.
+	SampleRates map[string]float64 `hcl:"sample_rates"`
+
+	// MaxPacketSize is the maximum size of the UDP packets sent to statsd.
+	// Defaults to 1400 bytes.
+	MaxPacketSize int `hcl:"max_packet_size"`
+
 	UnusedKeys []string `hcl:",unusedKeys"`
 }
 
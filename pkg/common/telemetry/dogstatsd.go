@@ -18,6 +18,9 @@ func newDogStatsdRunner(c *MetricsConfig) (sinkRunner, error) {
 		if err != nil {
 			return nil, err
 		}
+		if len(dc.Tags) > 0 {
+			sink.SetTags(dc.Tags)
+		}
 
 		runner.loadedSinks = append(runner.loadedSinks, sink)
 	}
@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"context"
+	"net"
 	"testing"
 	"time"
 
@@ -34,6 +35,32 @@ func TestDogStatsdSinks(t *testing.T) {
 	assert.Equal(t, 2, len(dr.sinks()))
 }
 
+func TestDogStatsdTags(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	config := testDogStatsdConfig()
+	config.FileConfig.DogStatsd = []DogStatsdConfig{
+		{
+			Address: conn.LocalAddr().String(),
+			Tags:    []string{"env:prod", "cluster:demo"},
+		},
+	}
+
+	dr, err := newDogStatsdRunner(config)
+	require.NoError(t, err)
+	require.Len(t, dr.sinks(), 1)
+
+	dr.sinks()[0].IncrCounterWithLabels([]string{"foo"}, 1, []Label{{Name: "bar", Value: "baz"}})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Minute)))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, "foo:1|c|#env:prod,cluster:demo,bar:baz", string(buf[:n]))
+}
+
 func TestDogStatsdRun(t *testing.T) {
 	config := testDogStatsdConfig()
 	dr, err := newDogStatsdRunner(config)
@@ -71,7 +71,12 @@ func NewMetrics(c *MetricsConfig) (*MetricsImpl, error) {
 		fanout := metrics.FanoutSink{}
 		fanout = append(fanout, runner.sinks()...)
 
-		conf := metrics.DefaultConfig(c.ServiceName)
+		serviceName := c.ServiceName
+		if c.FileConfig.MetricPrefix != "" {
+			serviceName = c.FileConfig.MetricPrefix
+		}
+
+		conf := metrics.DefaultConfig(serviceName)
 		conf.EnableHostname = false
 		conf.EnableHostnameLabel = true
 		conf.EnableTypePrefix = runner.requiresTypePrefix()
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetricsMetricPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		metricPrefix string
+		expected     string
+	}{
+		{
+			name:     "defaults to service name",
+			expected: "foo",
+		},
+		{
+			name:         "overridden by metric prefix",
+			metricPrefix: "bar",
+			expected:     "bar",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := test.NewNullLogger()
+			m, err := NewMetrics(&MetricsConfig{
+				Logger:      log,
+				ServiceName: "foo",
+				FileConfig: FileConfig{
+					InMem:        &InMem{},
+					MetricPrefix: tt.metricPrefix,
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, m.metricsSinks, 1)
+			require.Equal(t, tt.expected, m.metricsSinks[0].ServiceName)
+		})
+	}
+}
@@ -10,6 +10,10 @@ const (
 	// Action functionality related to actions themselves, such as rate-limiting an action
 	Action = "action"
 
+	// Acquire functionality related to acquiring some entity; should be used
+	// with other tags to add clarity
+	Acquire = "acquire"
+
 	// Activate functionality related to activating some element (such as X509 CA manager);
 	// should be used with other tags to add clarity
 	Activate = "activate"
@@ -22,6 +26,10 @@ const (
 	// to add clarity
 	Attest = "attest"
 
+	// Consume functionality related to consuming some single-use entity; should be
+	// used with other tags to add clarity
+	Consume = "consume"
+
 	// Create functionality related to creating some entity; should be used with other tags
 	// to add clarity
 	Create = "create"
@@ -34,6 +42,13 @@ const (
 	// to add clarity
 	Delete = "delete"
 
+	// Exceeded functionality related to exceeding a limit or quota
+	Exceeded = "exceeded"
+
+	// Fallback functionality related to falling back to an alternative when
+	// the preferred option fails; should be used with other tags to add clarity
+	Fallback = "fallback"
+
 	// Fetch functionality related to fetching some entity; should be used with other tags
 	// to add clarity
 	Fetch = "fetch"
@@ -89,6 +104,10 @@ const (
 	// Reload functionality related to reloading of a cache
 	Reload = "reload"
 
+	// Restore functionality related to restoring some deleted entity; should be
+	// used with other tags to add clarity
+	Restore = "restore"
+
 	// Rotate functionality related to rotation of SVID; should be used with other tags
 	// to add clarity
 	Rotate = "rotate"
@@ -120,6 +139,10 @@ const (
 	// with other tags to add clarity
 	Update = "update"
 
+	// Upstream functionality related to the upstream authority; should be
+	// used with other tags to add clarity
+	Upstream = "upstream"
+
 	// Mint functionality related to minting identities
 	Mint = "mint"
 )
@@ -142,6 +165,9 @@ const (
 	// Attempt tags some count of attempts
 	Attempt = "attempt"
 
+	// AttestedAt tags the time an agent last attested
+	AttestedAt = "attested_at"
+
 	// Audience tags some audience for a token
 	Audience = "audience"
 
@@ -189,6 +215,9 @@ const (
 	// to add clarity
 	CallerPath = "caller_path"
 
+	// Capabilities tags the protocol capabilities advertised by a peer
+	Capabilities = "capabilities"
+
 	// CGroupPath tags a linux CGroup path, most likely for use in attestation
 	CGroupPath = "cgroup_path"
 
@@ -236,10 +265,19 @@ const (
 	// EndpointSpiffeID tags endpoint SPIFFE ID
 	EndpointSpiffeID = "endpoint_spiffe_id"
 
+	// Entries tags some count or list of registration entries
+	Entries = "entries"
+
+	// EntryTemplateID tags some entry template ID
+	EntryTemplateID = "entry_template_id"
+
 	// Error tag for some error that occurred. Limited usage, such as logging errors at
 	// non-error level.
 	Error = "error"
 
+	// Errors tags a count of failed operations, such as RPC calls
+	Errors = "errors"
+
 	// Expect tags an expected value, as opposed to the one received. Message should clarify
 	// what kind of value was expected, and a different field should show the received value
 	Expect = "expect"
@@ -287,6 +325,18 @@ const (
 	// IDType tags some type of ID (eg. registration ID, SPIFFE ID...)
 	IDType = "id_type"
 
+	// Idle tags something idle, such as idle database connections
+	Idle = "idle"
+
+	// InFlight tags the number of in-flight operations, such as RPC calls
+	InFlight = "in_flight"
+
+	// InUse tags something in use, such as database connections
+	InUse = "in_use"
+
+	// Interval tags an interval duration
+	Interval = "interval"
+
 	// IssuedAt tags an issuance timestamp
 	IssuedAt = "issued_at"
 
@@ -309,6 +359,21 @@ const (
 	// Kid tags some key ID
 	Kid = "kid"
 
+	// KeyManager tags the name of a KeyManager plugin
+	KeyManager = "key_manager"
+
+	// LastSync tags the time of the last successful synchronization
+	LastSync = "last_sync"
+
+	// Latency tags the time an API call took
+	Latency = "latency"
+
+	// LogLevel tags a logging level
+	LogLevel = "log_level"
+
+	// LeaseHolder tags the ID of the server holding a lease
+	LeaseHolder = "lease_holder"
+
 	// Mode tags a bundle deletion mode
 	Mode = "mode"
 
@@ -324,18 +389,34 @@ const (
 	// Nonce tags some nonce for communication
 	Nonce = "nonce"
 
+	// Offset tags some offset into a file
+	Offset = "offset"
+
+	// OpenConnections tags the number of open connections
+	OpenConnections = "open_connections"
+
 	// ParentID tags parent ID for an entry
 	ParentID = "parent_id"
 
 	// Path declares some logic path, likely on the file system
 	Path = "path"
 
+	// Pattern tags a pattern, e.g. of the SVID denylist
+	Pattern = "pattern"
+
 	// Peer ID is the SPIFFE ID of a peer
 	PeerID = "peer_id"
 
 	// PID declares some process ID
 	PID = "pid"
 
+	// PIDNamespace tags the PID namespace of a process
+	PIDNamespace = "pid_namespace"
+
+	// PIDInNamespace tags the process ID of a process as seen from its own
+	// PID namespace
+	PIDInNamespace = "pid_in_namespace"
+
 	// PluginName tags name of some plugin
 	PluginName = "plugin_name"
 
@@ -348,15 +429,24 @@ const (
 	// PluginType tags type of some plugin
 	PluginType = "plugin_type"
 
+	// Pending tags the number of pending operations
+	Pending = "pending"
+
 	// PodUID tags some pod UID, most likely for use in attestation
 	PodUID = "pod_uid"
 
+	// Pool tags some pool, such as a database connection pool
+	Pool = "pool"
+
 	// PreferredServiceName tags the preferred service name
 	PreferredServiceName = "preferred_service_name"
 
 	// Pruned flagging something has been pruned
 	Pruned = "pruned"
 
+	// Quota tags the kind of quota
+	Quota = "quota"
+
 	// ReadOnly tags something read-only
 	ReadOnly = "read_only"
 
@@ -389,9 +479,15 @@ const (
 	// RegistrationEntry tags a registration entry
 	RegistrationEntry = "registration_entry"
 
+	// RenewedX509SVIDs tags some count of renewed X509-SVIDs
+	RenewedX509SVIDs = "renewed_x509_svids"
+
 	// RequestID tags a request identifier
 	RequestID = "request_id"
 
+	// CallerRequestID tags the request identifier sent by the caller
+	CallerRequestID = "caller_request_id"
+
 	// ResourceNames tags some group of resources by name
 	ResourceNames = "resource_names"
 
@@ -432,6 +528,9 @@ const (
 	// SerialNumber tags a certificate serial number
 	SerialNumber = "serial_num"
 
+	// Shortened flagging something has been shortened, such as a TTL
+	Shortened = "shortened"
+
 	// Slot X509 CA Slot ID
 	Slot = "slot"
 
@@ -454,6 +553,9 @@ const (
 	// with other tags to add clarity
 	Subject = "subject"
 
+	// Subscribers tags some count of subscribers
+	Subscribers = "subscribers"
+
 	// SVIDResponseLatency tags latency for SVID response
 	SVIDResponseLatency = "svid_response_latency"
 
@@ -466,6 +568,9 @@ const (
 	// SVIDUpdated tags that for some entity the SVID was updated
 	SVIDUpdated = "svid_updated"
 
+	// Timeout tags a timeout duration
+	Timeout = "timeout"
+
 	// TTL functionality related to a time-to-live field; should be used
 	// with other tags to add clarity
 	TTL = "ttl"
@@ -495,6 +600,10 @@ const (
 	// VersionInfo tags some version information
 	VersionInfo = "version_info"
 
+	// WaitCount tags the total number of waits, such as waits for a database
+	// connection
+	WaitCount = "wait_count"
+
 	// WorkloadAttestation tags call of overall workload attestation
 	WorkloadAttestation = "workload_attestation"
 
@@ -509,12 +618,23 @@ const (
 
 	// X509CAs tags some count or list of X509 CAs
 	X509CAs = "x509_cas"
+
+	// X509SVIDs tags some count or list of X509-SVIDs
+	X509SVIDs = "x509_svids"
 )
 
 // Entity metric tags or labels that are typically an entity or
 // module in their own right, rather than descriptive of other
 // entities or modules
 const (
+	// Agent tags an agent
+	Agent = "agent"
+
+	// AgentRenewal functionality related to a renewal of the agent SVID
+	// requested by an administrator; should be used with other tags to add
+	// clarity
+	AgentRenewal = "agent_renewal"
+
 	// AgentSVID tag a node (agent) SVID
 	AgentSVID = "agent_svid"
 
@@ -553,15 +673,31 @@ const (
 	// Datastore functionality related to datastore plugin
 	Datastore = "datastore"
 
+	// Degraded functionality related to the degraded mode of the agent,
+	// entered when it cannot synchronize with the server
+	Degraded = "degraded"
+
 	// Deleted tags something as deleted
 	Deleted = "deleted"
 
+	// DeletedRegistrationEntry functionality related to deleted registration
+	// entries kept so they can be restored
+	DeletedRegistrationEntry = "deleted_registration_entry"
+
 	// Endpoints functionality related to agent/server endpoints
 	Endpoints = "endpoints"
 
 	// Entry tag for some stored entry
 	Entry = "entry"
 
+	// EntryDNSNamePatterns functionality related to the patterns of the DNS
+	// names agents can request for the X509-SVIDs of an entry
+	EntryDNSNamePatterns = "entry_dns_name_patterns"
+
+	// EntryTemplate functionality related to templates expanded to
+	// registration entries when matching agents attest
+	EntryTemplate = "entry_template"
+
 	// Event tag some event that has occurred, for a notifier, watcher, listener, etc.
 	Event = "event"
 
@@ -575,10 +711,20 @@ const (
 	// with other tags to add clarity
 	FederatedBundle = "federated_bundle"
 
+	// IssuanceQuota functionality related to SVID issuance quotas
+	IssuanceQuota = "issuance_quota"
+
 	// JoinToken functionality related to a join token; should be used
 	// with other tags to add clarity
 	JoinToken = "join_token"
 
+	// Lease functionality related to a lease on a duty shared by servers;
+	// should be used with other tags to add clarity
+	Lease = "lease"
+
+	// LeaseCoordinator functionality related to a lease coordinator
+	LeaseCoordinator = "lease_coordinator"
+
 	// JWTKey functionality related to a JWT key; should be used with other tags
 	// to add clarity. Should NEVER actually provide the key itself, use Key ID instead.
 	JWTKey = "jwt_key"
@@ -631,6 +777,12 @@ const (
 	// RegistrationManager functionality related to a registration manager
 	RegistrationManager = "registration_manager"
 
+	// Reloader functionality related to reloading configuration at runtime
+	Reloader = "reloader"
+
+	// RotationEvents functionality related to streaming SVID rotation events
+	RotationEvents = "rotation_events"
+
 	// Telemetry tags a telemetry module
 	Telemetry = "telemetry"
 
@@ -724,6 +876,9 @@ const (
 	// GetNodeSelectors functionality related to getting node selectors
 	GetNodeSelectors = "get_node_selectors"
 
+	// InspectAPI functionality related to inspect endpoints
+	InspectAPI = "inspect_api"
+
 	// CountAgents functionality related to counting agents
 	CountAgents = "count_agents"
 
@@ -1,7 +1,10 @@
 package server
 
 import (
+	"time"
+
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"google.golang.org/grpc/status"
 )
 
 // Call Counters (timing and success metrics)
@@ -40,8 +43,28 @@ func SetX509CARotateGauge(m telemetry.Metrics, trustDomain string, val float32)
 		})
 }
 
+// SetServerCAPendingX509SVIDGauge set gauge for the number of X509 SVIDs
+// the Server CA is currently signing.
+func SetServerCAPendingX509SVIDGauge(m telemetry.Metrics, pending int64) {
+	m.SetGauge([]string{telemetry.ServerCA, telemetry.Sign, telemetry.X509SVID, telemetry.Pending}, float32(pending))
+}
+
 // End Gauge
 
+// Timers
+
+// MeasureServerCASign emits the time taken by the Server CA to sign the given
+// kind of SVID, labeled with the outcome and the KeyManager holding the key
+// used to sign it.
+func MeasureServerCASign(m telemetry.Metrics, kind, keyManager string, start time.Time, err error) {
+	m.MeasureSinceWithLabels([]string{telemetry.ServerCA, telemetry.Sign, kind, telemetry.ElapsedTime}, start, []telemetry.Label{
+		{Name: telemetry.Status, Value: status.Code(err).String()},
+		{Name: telemetry.KeyManager, Value: keyManager},
+	})
+}
+
+// End Timers
+
 // Counters (literal increments, not call counters)
 
 // IncrActivateJWTKeyManagerCounter indicate activation
@@ -56,6 +79,18 @@ func IncrActivateX509CAManagerCounter(m telemetry.Metrics) {
 	m.IncrCounter([]string{telemetry.CA, telemetry.Manager, telemetry.X509CA, telemetry.Activate}, 1)
 }
 
+// IncrX509CATTLShortenedCounter indicate the X509 CA minted by
+// the upstream authority expires before the configured CA TTL
+func IncrX509CATTLShortenedCounter(m telemetry.Metrics) {
+	m.IncrCounter([]string{telemetry.CA, telemetry.Manager, telemetry.X509CA, telemetry.TTL, telemetry.Shortened}, 1)
+}
+
+// IncrX509CAUpstreamFallbackCounter indicate the X509 CA was
+// self-signed because the upstream authority failed to sign it
+func IncrX509CAUpstreamFallbackCounter(m telemetry.Metrics) {
+	m.IncrCounter([]string{telemetry.CA, telemetry.Manager, telemetry.X509CA, telemetry.Upstream, telemetry.Fallback}, 1)
+}
+
 // IncrManagerPrunedBundleCounter indicate manager
 // having pruned a bundle
 func IncrManagerPrunedBundleCounter(m telemetry.Metrics) {
@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Call Counters (timing and success metrics)
+// Allows adding labels in-code
+
+// StartFetchAgentRenewalCall return metric
+// for server's datastore, on fetching an agent renewal.
+func StartFetchAgentRenewalCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.AgentRenewal, telemetry.Fetch)
+}
+
+// StartSetAgentRenewalCall return metric
+// for server's datastore, on setting an agent renewal.
+func StartSetAgentRenewalCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.AgentRenewal, telemetry.Set)
+}
+
+// End Call Counters
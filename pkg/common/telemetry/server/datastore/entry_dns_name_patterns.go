@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Call Counters (timing and success metrics)
+// Allows adding labels in-code
+
+// StartFetchEntryDNSNamePatternsCall return metric
+// for server's datastore, on fetching the DNS name patterns of an entry.
+func StartFetchEntryDNSNamePatternsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryDNSNamePatterns, telemetry.Fetch)
+}
+
+// StartSetEntryDNSNamePatternsCall return metric
+// for server's datastore, on setting the DNS name patterns of an entry.
+func StartSetEntryDNSNamePatternsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.EntryDNSNamePatterns, telemetry.Set)
+}
+
+// End Call Counters
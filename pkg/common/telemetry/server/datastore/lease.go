@@ -0,0 +1,16 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Call Counters (timing and success metrics)
+// Allows adding labels in-code
+
+// StartAcquireLeaseCall return metric
+// for server's datastore, on acquiring a lease.
+func StartAcquireLeaseCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Lease, telemetry.Acquire)
+}
+
+// End Call Counters
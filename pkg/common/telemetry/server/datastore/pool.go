@@ -0,0 +1,29 @@
+package datastore
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Gauge (remember previous value set)
+
+// SetPoolGauges sets gauges describing the utilization of a database
+// connection pool. The read-only label distinguishes the read-only
+// connection pool from the read-write one.
+func SetPoolGauges(m telemetry.Metrics, readOnly bool, stats sql.DBStats) {
+	labels := []telemetry.Label{
+		{Name: telemetry.ReadOnly, Value: strconv.FormatBool(readOnly)},
+	}
+	setPoolGauge(m, telemetry.OpenConnections, stats.OpenConnections, labels)
+	setPoolGauge(m, telemetry.InUse, stats.InUse, labels)
+	setPoolGauge(m, telemetry.Idle, stats.Idle, labels)
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Pool, telemetry.WaitCount}, float32(stats.WaitCount), labels)
+}
+
+func setPoolGauge(m telemetry.Metrics, name string, val int, labels []telemetry.Label) {
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Pool, name}, float32(val), labels)
+}
+
+// End Gauge
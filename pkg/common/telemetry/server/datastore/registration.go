@@ -49,4 +49,16 @@ func StartUpdateRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Update)
 }
 
+// StartListDeletedRegistrationCall return metric
+// for server's datastore, on listing deleted registrations.
+func StartListDeletedRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.DeletedRegistrationEntry, telemetry.List)
+}
+
+// StartRestoreRegistrationCall return metric
+// for server's datastore, on restoring a deleted registration.
+func StartRestoreRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.DeletedRegistrationEntry, telemetry.Restore)
+}
+
 // End Call Counters
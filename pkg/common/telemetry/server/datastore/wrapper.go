@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/api/rpccontext"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -13,222 +15,318 @@ import (
 
 // WithMetrics wraps a datastore interface and provides per-call metrics. The
 // metrics produced include a call counter and elapsed time measurement with
-// labels for the status code.
-func WithMetrics(ds datastore.DataStore, metrics telemetry.Metrics) datastore.DataStore {
-	return metricsWrapper{ds: ds, m: metrics}
+// labels for the status code. If slowThreshold is positive, calls that take
+// longer than it are also logged.
+func WithMetrics(ds datastore.DataStore, metrics telemetry.Metrics, log logrus.FieldLogger, slowThreshold time.Duration) datastore.DataStore {
+	return metricsWrapper{ds: ds, m: metrics, log: log, slowThreshold: slowThreshold}
 }
 
 type metricsWrapper struct {
-	ds datastore.DataStore
-	m  telemetry.Metrics
+	ds            datastore.DataStore
+	m             telemetry.Metrics
+	log           logrus.FieldLogger
+	slowThreshold time.Duration
+}
+
+// done finishes the call, logging it if it took longer than the slow
+// operation threshold.
+func (w metricsWrapper) done(ctx context.Context, callCounter *telemetry.CallCounter, errp *error) {
+	if w.slowThreshold > 0 {
+		if elapsed := callCounter.Elapsed(); elapsed > w.slowThreshold {
+			log := w.log.WithFields(logrus.Fields{
+				telemetry.Method:      callCounter.Name(),
+				telemetry.ElapsedTime: elapsed,
+			})
+			if requestID, ok := rpccontext.RequestID(ctx); ok {
+				log = log.WithField(telemetry.RequestID, requestID)
+			}
+			if errp != nil && *errp != nil {
+				log = log.WithError(*errp)
+			}
+			log.Warn("Slow datastore operation")
+		}
+	}
+	callCounter.Done(errp)
+}
+
+func (w metricsWrapper) AcquireLease(ctx context.Context, lease *datastore.Lease, now time.Time) (_ *datastore.Lease, err error) {
+	callCounter := StartAcquireLeaseCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.AcquireLease(ctx, lease, now)
+}
+
+func (w metricsWrapper) FetchAgentRenewal(ctx context.Context, spiffeID string) (_ *datastore.AgentRenewal, err error) {
+	callCounter := StartFetchAgentRenewalCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.FetchAgentRenewal(ctx, spiffeID)
+}
+
+func (w metricsWrapper) SetAgentRenewal(ctx context.Context, renewal *datastore.AgentRenewal) (err error) {
+	callCounter := StartSetAgentRenewalCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.SetAgentRenewal(ctx, renewal)
+}
+
+func (w metricsWrapper) FetchEntryDNSNamePatterns(ctx context.Context, entryID string) (_ []string, err error) {
+	callCounter := StartFetchEntryDNSNamePatternsCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.FetchEntryDNSNamePatterns(ctx, entryID)
+}
+
+func (w metricsWrapper) SetEntryDNSNamePatterns(ctx context.Context, entryID string, patterns []string) (err error) {
+	callCounter := StartSetEntryDNSNamePatternsCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.SetEntryDNSNamePatterns(ctx, entryID, patterns)
 }
 
 func (w metricsWrapper) AppendBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
 	callCounter := StartAppendBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.AppendBundle(ctx, bundle)
 }
 
 func (w metricsWrapper) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (_ *common.AttestedNode, err error) {
 	callCounter := StartCreateNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateAttestedNode(ctx, node)
 }
 
 func (w metricsWrapper) CreateBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
 	callCounter := StartCreateBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateBundle(ctx, bundle)
 }
 
+func (w metricsWrapper) ConsumeJoinToken(ctx context.Context, token string, now time.Time) (_ *datastore.JoinToken, err error) {
+	callCounter := StartConsumeJoinTokenCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.ConsumeJoinToken(ctx, token, now)
+}
+
 func (w metricsWrapper) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
 	callCounter := StartCreateJoinTokenCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateJoinToken(ctx, token)
 }
 
 func (w metricsWrapper) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartCreateRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateRegistrationEntry(ctx, entry)
 }
 
 func (w metricsWrapper) CreateOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, _ bool, err error) {
 	callCounter := StartCreateRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateOrReturnRegistrationEntry(ctx, entry)
 }
 
 func (w metricsWrapper) CreateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (_ *datastore.FederationRelationship, err error) {
 	callCounter := StartCreateFederationRelationshipCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CreateFederationRelationship(ctx, fr)
 }
 
 func (w metricsWrapper) ListFederationRelationships(ctx context.Context, req *datastore.ListFederationRelationshipsRequest) (_ *datastore.ListFederationRelationshipsResponse, err error) {
 	callCounter := StartListFederationRelationshipsCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.ListFederationRelationships(ctx, req)
 }
 
 func (w metricsWrapper) DeleteAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
 	callCounter := StartDeleteNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.DeleteAttestedNode(ctx, spiffeID)
 }
 
 func (w metricsWrapper) DeleteBundle(ctx context.Context, trustDomain string, mode datastore.DeleteMode) (err error) {
 	callCounter := StartDeleteBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.DeleteBundle(ctx, trustDomain, mode)
 }
 
 func (w metricsWrapper) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (err error) {
 	callCounter := StartDeleteFederationRelationshipCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.DeleteFederationRelationship(ctx, trustDomain)
 }
 
 func (w metricsWrapper) DeleteJoinToken(ctx context.Context, token string) (err error) {
 	callCounter := StartDeleteJoinTokenCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.DeleteJoinToken(ctx, token)
 }
 
 func (w metricsWrapper) DeleteRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartDeleteRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.DeleteRegistrationEntry(ctx, entryID)
 }
 
+func (w metricsWrapper) ListDeletedRegistrationEntries(ctx context.Context) (_ []*datastore.DeletedRegistrationEntry, err error) {
+	callCounter := StartListDeletedRegistrationCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.ListDeletedRegistrationEntries(ctx)
+}
+
+func (w metricsWrapper) RestoreRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
+	callCounter := StartRestoreRegistrationCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.RestoreRegistrationEntry(ctx, entryID)
+}
+
+func (w metricsWrapper) CreateEntryTemplate(ctx context.Context, template *datastore.EntryTemplate) (_ *datastore.EntryTemplate, err error) {
+	callCounter := StartCreateEntryTemplateCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.CreateEntryTemplate(ctx, template)
+}
+
+func (w metricsWrapper) DeleteEntryTemplate(ctx context.Context, templateID string) (_ *datastore.EntryTemplate, err error) {
+	callCounter := StartDeleteEntryTemplateCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.DeleteEntryTemplate(ctx, templateID)
+}
+
+func (w metricsWrapper) ListEntryTemplates(ctx context.Context) (_ []*datastore.EntryTemplate, err error) {
+	callCounter := StartListEntryTemplatesCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.ListEntryTemplates(ctx)
+}
+
 func (w metricsWrapper) FetchAttestedNode(ctx context.Context, spiffeID string) (_ *common.AttestedNode, err error) {
 	callCounter := StartFetchNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.FetchAttestedNode(ctx, spiffeID)
 }
 
 func (w metricsWrapper) FetchBundle(ctx context.Context, trustDomain string) (_ *common.Bundle, err error) {
 	callCounter := StartFetchBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.FetchBundle(ctx, trustDomain)
 }
 
 func (w metricsWrapper) FetchJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
 	callCounter := StartFetchJoinTokenCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.FetchJoinToken(ctx, token)
 }
 
 func (w metricsWrapper) FetchRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartFetchRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.FetchRegistrationEntry(ctx, entryID)
 }
 
 func (w metricsWrapper) FetchFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (_ *datastore.FederationRelationship, err error) {
 	callCounter := StartFetchFederationRelationshipCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.FetchFederationRelationship(ctx, trustDomain)
 }
 
 func (w metricsWrapper) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency) (_ []*common.Selector, err error) {
 	callCounter := StartGetNodeSelectorsCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency)
 }
 
 func (w metricsWrapper) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (_ *datastore.ListAttestedNodesResponse, err error) {
 	callCounter := StartListNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.ListAttestedNodes(ctx, req)
 }
 
 func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (_ *datastore.ListBundlesResponse, err error) {
 	callCounter := StartListBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.ListBundles(ctx, req)
 }
 
 func (w metricsWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
 	callCounter := StartListNodeSelectorsCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.ListNodeSelectors(ctx, req)
 }
 
 func (w metricsWrapper) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (_ *datastore.ListRegistrationEntriesResponse, err error) {
 	callCounter := StartListRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.ListRegistrationEntries(ctx, req)
 }
 
 func (w metricsWrapper) CountAttestedNodes(ctx context.Context) (_ int32, err error) {
 	callCounter := StartCountNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CountAttestedNodes(ctx)
 }
 
 func (w metricsWrapper) CountBundles(ctx context.Context) (_ int32, err error) {
 	callCounter := StartCountBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.CountBundles(ctx)
 }
 
-func (w metricsWrapper) CountRegistrationEntries(ctx context.Context) (_ int32, err error) {
+func (w metricsWrapper) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (_ int32, err error) {
 	callCounter := StartCountRegistrationCall(w.m)
-	defer callCounter.Done(&err)
-	return w.ds.CountRegistrationEntries(ctx)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.CountRegistrationEntries(ctx, req)
 }
 
 func (w metricsWrapper) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (_ bool, err error) {
 	callCounter := StartPruneBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.PruneBundle(ctx, trustDomainID, expiresBefore)
 }
 
+func (w metricsWrapper) ListJoinTokens(ctx context.Context) (_ []*datastore.JoinToken, err error) {
+	callCounter := StartListJoinTokensCall(w.m)
+	defer w.done(ctx, callCounter, &err)
+	return w.ds.ListJoinTokens(ctx)
+}
+
 func (w metricsWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.Time) (err error) {
 	callCounter := StartPruneJoinTokenCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.PruneJoinTokens(ctx, expiresBefore)
 }
 
 func (w metricsWrapper) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) (err error) {
 	callCounter := StartPruneRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.PruneRegistrationEntries(ctx, expiresBefore)
 }
 
 func (w metricsWrapper) SetBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
 	callCounter := StartSetBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.SetBundle(ctx, bundle)
 }
 
 func (w metricsWrapper) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) (err error) {
 	callCounter := StartSetNodeSelectorsCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.SetNodeSelectors(ctx, spiffeID, selectors)
 }
 
 func (w metricsWrapper) UpdateAttestedNode(ctx context.Context, node *common.AttestedNode, mask *common.AttestedNodeMask) (_ *common.AttestedNode, err error) {
 	callCounter := StartUpdateNodeCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.UpdateAttestedNode(ctx, node, mask)
 }
 
 func (w metricsWrapper) UpdateBundle(ctx context.Context, bundle *common.Bundle, mask *common.BundleMask) (_ *common.Bundle, err error) {
 	callCounter := StartUpdateBundleCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.UpdateBundle(ctx, bundle, mask)
 }
 
 func (w metricsWrapper) UpdateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartUpdateRegistrationCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.UpdateRegistrationEntry(ctx, entry, mask)
 }
 
 func (w metricsWrapper) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (_ *datastore.FederationRelationship, err error) {
 	callCounter := StartUpdateFederationRelationshipCall(w.m)
-	defer callCounter.Done(&err)
+	defer w.done(ctx, callCounter, &err)
 	return w.ds.UpdateFederationRelationship(ctx, fr, mask)
 }
@@ -8,12 +8,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/api/rpccontext"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -22,7 +26,8 @@ import (
 func TestWithMetrics(t *testing.T) {
 	m := fakemetrics.New()
 	ds := &fakeDataStore{}
-	w := WithMetrics(ds, m)
+	log, _ := test.NewNullLogger()
+	w := WithMetrics(ds, m, log, 0)
 
 	// This map ensures that a unit-test is added for any additional
 	// datastore methods that are added.
@@ -37,6 +42,26 @@ func TestWithMetrics(t *testing.T) {
 		key        string
 		methodName string
 	}{
+		{
+			key:        "datastore.lease.acquire",
+			methodName: "AcquireLease",
+		},
+		{
+			key:        "datastore.agent_renewal.fetch",
+			methodName: "FetchAgentRenewal",
+		},
+		{
+			key:        "datastore.agent_renewal.set",
+			methodName: "SetAgentRenewal",
+		},
+		{
+			key:        "datastore.entry_dns_name_patterns.fetch",
+			methodName: "FetchEntryDNSNamePatterns",
+		},
+		{
+			key:        "datastore.entry_dns_name_patterns.set",
+			methodName: "SetEntryDNSNamePatterns",
+		},
 		{
 			key:        "datastore.bundle.append",
 			methodName: "AppendBundle",
@@ -53,6 +78,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry.count",
 			methodName: "CountRegistrationEntries",
 		},
+		{
+			key:        "datastore.join_token.consume",
+			methodName: "ConsumeJoinToken",
+		},
 		{
 			key:        "datastore.node.create",
 			methodName: "CreateAttestedNode",
@@ -129,6 +158,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.list",
 			methodName: "ListBundles",
 		},
+		{
+			key:        "datastore.join_token.list",
+			methodName: "ListJoinTokens",
+		},
 		{
 			key:        "datastore.node.selectors.list",
 			methodName: "ListNodeSelectors",
@@ -153,6 +186,26 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry.prune",
 			methodName: "PruneRegistrationEntries",
 		},
+		{
+			key:        "datastore.deleted_registration_entry.list",
+			methodName: "ListDeletedRegistrationEntries",
+		},
+		{
+			key:        "datastore.deleted_registration_entry.restore",
+			methodName: "RestoreRegistrationEntry",
+		},
+		{
+			key:        "datastore.entry_template.create",
+			methodName: "CreateEntryTemplate",
+		},
+		{
+			key:        "datastore.entry_template.delete",
+			methodName: "DeleteEntryTemplate",
+		},
+		{
+			key:        "datastore.entry_template.list",
+			methodName: "ListEntryTemplates",
+		},
 		{
 			key:        "datastore.bundle.set",
 			methodName: "SetBundle",
@@ -249,6 +302,42 @@ func TestWithMetrics(t *testing.T) {
 	}
 }
 
+func TestWithMetricsLogsSlowOperations(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	ds := slowDataStore{delay: 10 * time.Millisecond}
+
+	w := WithMetrics(ds, fakemetrics.New(), log, time.Hour)
+	_, err := w.FetchBundle(context.Background(), "spiffe://example.org")
+	require.NoError(t, err)
+	spiretest.AssertLogs(t, hook.AllEntries(), nil)
+
+	w = WithMetrics(ds, fakemetrics.New(), log, time.Millisecond)
+	_, err = w.FetchBundle(context.Background(), "spiffe://example.org")
+	require.NoError(t, err)
+	require.Len(t, hook.AllEntries(), 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Slow datastore operation", entry.Message)
+	assert.Equal(t, "datastore.bundle.fetch", entry.Data[telemetry.Method])
+	assert.GreaterOrEqual(t, entry.Data[telemetry.ElapsedTime], 10*time.Millisecond)
+	assert.NotContains(t, entry.Data, telemetry.RequestID)
+
+	// The ID of the request that caused the operation is logged, if any
+	_, err = w.FetchBundle(rpccontext.WithRequestID(context.Background(), "abc"), "spiffe://example.org")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", hook.LastEntry().Data[telemetry.RequestID])
+}
+
+type slowDataStore struct {
+	datastore.DataStore
+	delay time.Duration
+}
+
+func (ds slowDataStore) FetchBundle(context.Context, string) (*common.Bundle, error) {
+	time.Sleep(ds.delay)
+	return nil, nil
+}
+
 type fakeDataStore struct {
 	err error
 }
@@ -269,7 +358,7 @@ func (ds *fakeDataStore) CountBundles(context.Context) (int32, error) {
 	return 0, ds.err
 }
 
-func (ds *fakeDataStore) CountRegistrationEntries(context.Context) (int32, error) {
+func (ds *fakeDataStore) CountRegistrationEntries(context.Context, *datastore.CountRegistrationEntriesRequest) (int32, error) {
 	return 0, ds.err
 }
 
@@ -289,6 +378,10 @@ func (ds *fakeDataStore) ListFederationRelationships(context.Context, *datastore
 	return &datastore.ListFederationRelationshipsResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ConsumeJoinToken(context.Context, string, time.Time) (*datastore.JoinToken, error) {
+	return &datastore.JoinToken{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateJoinToken(context.Context, *datastore.JoinToken) error {
 	return ds.err
 }
@@ -361,10 +454,26 @@ func (ds *fakeDataStore) ListRegistrationEntries(context.Context, *datastore.Lis
 	return &datastore.ListRegistrationEntriesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) AcquireLease(context.Context, *datastore.Lease, time.Time) (*datastore.Lease, error) {
+	return &datastore.Lease{}, ds.err
+}
+
+func (ds *fakeDataStore) FetchAgentRenewal(context.Context, string) (*datastore.AgentRenewal, error) {
+	return &datastore.AgentRenewal{}, ds.err
+}
+
+func (ds *fakeDataStore) SetAgentRenewal(context.Context, *datastore.AgentRenewal) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) PruneBundle(context.Context, string, time.Time) (bool, error) {
 	return false, ds.err
 }
 
+func (ds *fakeDataStore) ListJoinTokens(context.Context) ([]*datastore.JoinToken, error) {
+	return []*datastore.JoinToken{}, ds.err
+}
+
 func (ds *fakeDataStore) PruneJoinTokens(context.Context, time.Time) error {
 	return ds.err
 }
@@ -373,6 +482,34 @@ func (ds *fakeDataStore) PruneRegistrationEntries(context.Context, time.Time) er
 	return ds.err
 }
 
+func (ds *fakeDataStore) ListDeletedRegistrationEntries(context.Context) ([]*datastore.DeletedRegistrationEntry, error) {
+	return []*datastore.DeletedRegistrationEntry{}, ds.err
+}
+
+func (ds *fakeDataStore) RestoreRegistrationEntry(context.Context, string) (*common.RegistrationEntry, error) {
+	return &common.RegistrationEntry{}, ds.err
+}
+
+func (ds *fakeDataStore) FetchEntryDNSNamePatterns(context.Context, string) ([]string, error) {
+	return []string{}, ds.err
+}
+
+func (ds *fakeDataStore) SetEntryDNSNamePatterns(context.Context, string, []string) error {
+	return ds.err
+}
+
+func (ds *fakeDataStore) CreateEntryTemplate(context.Context, *datastore.EntryTemplate) (*datastore.EntryTemplate, error) {
+	return &datastore.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) DeleteEntryTemplate(context.Context, string) (*datastore.EntryTemplate, error) {
+	return &datastore.EntryTemplate{}, ds.err
+}
+
+func (ds *fakeDataStore) ListEntryTemplates(context.Context) ([]*datastore.EntryTemplate, error) {
+	return []*datastore.EntryTemplate{}, ds.err
+}
+
 func (ds *fakeDataStore) SetBundle(context.Context, *common.Bundle) (*common.Bundle, error) {
 	return &common.Bundle{}, ds.err
 }
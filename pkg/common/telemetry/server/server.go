@@ -7,3 +7,11 @@ import "github.com/spiffe/spire/pkg/common/telemetry"
 func SetEntryDeletedGauge(m telemetry.Metrics, deleted int) {
 	m.SetGauge([]string{telemetry.Entry, telemetry.Deleted}, float32(deleted))
 }
+
+// IncrIssuanceQuotaExceededCounter indicates that an SVID was not signed
+// because the given issuance quota (agent or entry) was exceeded.
+func IncrIssuanceQuotaExceededCounter(m telemetry.Metrics, quota string) {
+	m.IncrCounterWithLabels([]string{telemetry.SVID, telemetry.IssuanceQuota, telemetry.Exceeded}, 1, []telemetry.Label{
+		{Name: telemetry.Quota, Value: quota},
+	})
+}
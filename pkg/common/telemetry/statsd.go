@@ -1,9 +1,31 @@
 package telemetry
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/armon/go-metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultStatsdMaxPacketSize is the default maximum size of the UDP
+	// packets sent to statsd, small enough to avoid fragmentation on
+	// typical networks
+	defaultStatsdMaxPacketSize = 1400
+
+	// maxStatsdMaxPacketSize is the largest payload of a UDP packet
+	maxStatsdMaxPacketSize = 65507
+
+	statsdFlushInterval = 100 * time.Millisecond
+	statsdRedialWait    = 5 * time.Second
+	statsdQueueSize     = 4096
 )
 
 type statsdRunner struct {
@@ -14,9 +36,9 @@ func newStatsdRunner(c *MetricsConfig) (sinkRunner, error) {
 	runner := &statsdRunner{}
 
 	for _, sc := range c.FileConfig.Statsd {
-		sink, err := metrics.NewStatsdSink(sc.Address)
+		sink, err := newStatsdSink(sc, c.Logger)
 		if err != nil {
-			return runner, nil
+			return nil, err
 		}
 
 		runner.loadedSinks = append(runner.loadedSinks, sink)
@@ -41,3 +63,214 @@ func (s *statsdRunner) run(context.Context) error {
 func (s *statsdRunner) requiresTypePrefix() bool {
 	return false
 }
+
+// statsdSink sends metrics to statsd over UDP using the plain statsd line
+// protocol. Counters and samples can be sampled, in which case the sample
+// rate is sent along with the metric so the aggregator can scale it back up.
+// Gauges are never sampled.
+type statsdSink struct {
+	addr          string
+	log           logrus.FieldLogger
+	sampleRate    float64
+	sampleRates   map[string]float64
+	maxPacketSize int
+	queue         chan string
+
+	// sample returns a number in [0.0,1.0) used to decide if a metric is
+	// sampled. Overridden in tests.
+	sample func() float64
+}
+
+func newStatsdSink(c StatsdConfig, log logrus.FieldLogger) (*statsdSink, error) {
+	if c.Address == "" {
+		return nil, errors.New("statsd address is required")
+	}
+
+	sampleRate := 1.0
+	if c.SampleRate != nil {
+		sampleRate = *c.SampleRate
+	}
+	if err := validateStatsdSampleRate(sampleRate); err != nil {
+		return nil, err
+	}
+	for prefix, rate := range c.SampleRates {
+		if err := validateStatsdSampleRate(rate); err != nil {
+			return nil, fmt.Errorf("%w for %q", err, prefix)
+		}
+	}
+
+	maxPacketSize := c.MaxPacketSize
+	switch {
+	case maxPacketSize == 0:
+		maxPacketSize = defaultStatsdMaxPacketSize
+	case maxPacketSize < 0 || maxPacketSize > maxStatsdMaxPacketSize:
+		return nil, fmt.Errorf("statsd max packet size must be between 1 and %d", maxStatsdMaxPacketSize)
+	}
+
+	s := &statsdSink{
+		addr:          c.Address,
+		log:           log,
+		sampleRate:    sampleRate,
+		sampleRates:   c.SampleRates,
+		maxPacketSize: maxPacketSize,
+		queue:         make(chan string, statsdQueueSize),
+		sample:        rand.Float64,
+	}
+	go s.flush()
+	return s, nil
+}
+
+func validateStatsdSampleRate(rate float64) error {
+	if rate <= 0 || rate > 1 {
+		return fmt.Errorf("statsd sample rate must be greater than 0 and at most 1, got %v", rate)
+	}
+	return nil
+}
+
+func (s *statsdSink) SetGauge(key []string, val float32) {
+	s.push(s.flattenKey(key), val, "g", 1)
+}
+
+func (s *statsdSink) SetGaugeWithLabels(key []string, val float32, labels []Label) {
+	s.push(s.flattenKeyLabels(key, labels), val, "g", 1)
+}
+
+// EmitKey is sent as a gauge, since key/value metrics are not part of the
+// plain statsd protocol
+func (s *statsdSink) EmitKey(key []string, val float32) {
+	s.push(s.flattenKey(key), val, "g", 1)
+}
+
+func (s *statsdSink) IncrCounter(key []string, val float32) {
+	s.pushSampled(s.flattenKey(key), val, "c")
+}
+
+func (s *statsdSink) IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	s.pushSampled(s.flattenKeyLabels(key, labels), val, "c")
+}
+
+func (s *statsdSink) AddSample(key []string, val float32) {
+	s.pushSampled(s.flattenKey(key), val, "ms")
+}
+
+func (s *statsdSink) AddSampleWithLabels(key []string, val float32, labels []Label) {
+	s.pushSampled(s.flattenKeyLabels(key, labels), val, "ms")
+}
+
+// rateFor returns the sample rate of the metric, which is the rate of the
+// longest configured prefix matching the metric name, or the default rate.
+func (s *statsdSink) rateFor(name string) float64 {
+	rate := s.sampleRate
+	longest := -1
+	for prefix, prefixRate := range s.sampleRates {
+		if len(prefix) > longest && (name == prefix || strings.HasPrefix(name, prefix+".")) {
+			rate = prefixRate
+			longest = len(prefix)
+		}
+	}
+	return rate
+}
+
+func (s *statsdSink) pushSampled(name string, val float32, metricType string) {
+	rate := s.rateFor(name)
+	if rate < 1 && s.sample() >= rate {
+		return
+	}
+	s.push(name, val, metricType, rate)
+}
+
+func (s *statsdSink) push(name string, val float32, metricType string, rate float64) {
+	line := fmt.Sprintf("%s:%s|%s", name, strconv.FormatFloat(float64(val), 'f', -1, 32), metricType)
+	if rate < 1 {
+		line += "|@" + strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+
+	// Metrics are dropped rather than blocking the caller when the queue is full
+	select {
+	case s.queue <- line + "\n":
+	default:
+	}
+}
+
+// Flattens the key for formatting, replacing the characters that are
+// reserved by the statsd line protocol
+func (s *statsdSink) flattenKey(parts []string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', ' ', '|', '@':
+			return '_'
+		default:
+			return r
+		}
+	}, strings.Join(parts, "."))
+}
+
+// Flattens the key along with the label values
+func (s *statsdSink) flattenKeyLabels(parts []string, labels []Label) string {
+	for _, label := range labels {
+		parts = append(parts, label.Value)
+	}
+	return s.flattenKey(parts)
+}
+
+// flush batches the queued metrics into packets of up to the max packet
+// size and sends them to statsd. Metrics are dropped while statsd cannot
+// be reached.
+func (s *statsdSink) flush() {
+	ticker := time.NewTicker(statsdFlushInterval)
+	defer ticker.Stop()
+
+	buf := new(bytes.Buffer)
+	for {
+		conn, err := net.Dial("udp", s.addr)
+		if err != nil {
+			s.log.WithError(err).WithField(Address, s.addr).Warn("Failed to connect to statsd")
+			s.drop(statsdRedialWait)
+			continue
+		}
+
+		err = s.send(conn, buf, ticker.C)
+		conn.Close()
+		buf.Reset()
+		s.log.WithError(err).WithField(Address, s.addr).Warn("Failed to send metrics to statsd")
+		s.drop(statsdRedialWait)
+	}
+}
+
+func (s *statsdSink) send(conn net.Conn, buf *bytes.Buffer, tick <-chan time.Time) error {
+	for {
+		select {
+		case line := <-s.queue:
+			if buf.Len() > 0 && buf.Len()+len(line) > s.maxPacketSize {
+				if _, err := conn.Write(buf.Bytes()); err != nil {
+					return err
+				}
+				buf.Reset()
+			}
+			buf.WriteString(line)
+		case <-tick:
+			if buf.Len() == 0 {
+				continue
+			}
+			if _, err := conn.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+}
+
+// drop discards queued metrics for the given duration, so they do not pile
+// up while statsd cannot be reached
+func (s *statsdSink) drop(d time.Duration) {
+	wait := time.After(d)
+	for {
+		select {
+		case <-s.queue:
+		case <-wait:
+			return
+		}
+	}
+}
+
+var _ Sink = (*statsdSink)(nil)
@@ -3,9 +3,12 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/hcl"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -58,6 +61,125 @@ func TestStatsdRun(t *testing.T) {
 	}
 }
 
+func TestStatsdConfigValidation(t *testing.T) {
+	zero := 0.0
+	for _, tt := range []struct {
+		name      string
+		config    StatsdConfig
+		expectErr string
+	}{
+		{
+			name:      "missing address",
+			config:    StatsdConfig{},
+			expectErr: "statsd address is required",
+		},
+		{
+			name:      "zero sample rate",
+			config:    StatsdConfig{Address: "localhost:8125", SampleRate: &zero},
+			expectErr: "statsd sample rate must be greater than 0 and at most 1, got 0",
+		},
+		{
+			name:      "prefix sample rate too large",
+			config:    StatsdConfig{Address: "localhost:8125", SampleRates: map[string]float64{"foo": 2}},
+			expectErr: `statsd sample rate must be greater than 0 and at most 1, got 2 for "foo"`,
+		},
+		{
+			name:      "max packet size too large",
+			config:    StatsdConfig{Address: "localhost:8125", MaxPacketSize: 70000},
+			expectErr: "statsd max packet size must be between 1 and 65507",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			config := testStatsdConfig()
+			config.FileConfig.Statsd = []StatsdConfig{tt.config}
+			_, err := newStatsdRunner(config)
+			require.EqualError(t, err, tt.expectErr)
+		})
+	}
+}
+
+func TestStatsdConfigHCL(t *testing.T) {
+	var config FileConfig
+	require.NoError(t, hcl.Decode(&config, `
+		Statsd = [
+			{
+				address = "localhost:8125"
+				sample_rate = 0.5
+				sample_rates = {
+					"foo.bar" = 0.1
+				}
+				max_packet_size = 512
+			}
+		]`))
+	require.Len(t, config.Statsd, 1)
+	require.Equal(t, "localhost:8125", config.Statsd[0].Address)
+	require.NotNil(t, config.Statsd[0].SampleRate)
+	require.Equal(t, 0.5, *config.Statsd[0].SampleRate)
+	require.Equal(t, map[string]float64{"foo.bar": 0.1}, config.Statsd[0].SampleRates)
+	require.Equal(t, 512, config.Statsd[0].MaxPacketSize)
+	require.Empty(t, config.Statsd[0].UnusedKeys)
+}
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rate := 0.5
+	log, _ := test.NewNullLogger()
+	sink, err := newStatsdSink(StatsdConfig{
+		Address:    conn.LocalAddr().String(),
+		SampleRate: &rate,
+		SampleRates: map[string]float64{
+			"foo":     0.25,
+			"foo.bar": 1,
+		},
+		MaxPacketSize: 64,
+	}, log)
+	require.NoError(t, err)
+
+	samples := []float64{0.9, 0.1, 0.1, 0.3}
+	sink.sample = func() float64 {
+		sample := samples[0]
+		samples = samples[1:]
+		return sample
+	}
+
+	// Dropped, since 0.9 is above the default sample rate
+	sink.IncrCounter([]string{"baz"}, 1)
+	// Sent with the default sample rate
+	sink.IncrCounterWithLabels([]string{"baz"}, 2, []Label{{Name: "label", Value: "a:b"}})
+	// Sent with the rate of the "foo" prefix
+	sink.AddSample([]string{"foo", "qux"}, 1.5)
+	// Sent without sampling, since "foo.bar" is the longest matching prefix
+	sink.AddSampleWithLabels([]string{"foo", "bar"}, 3, []Label{{Name: "label", Value: "c"}})
+	// Dropped, since 0.3 is above the rate of the "foo" prefix
+	sink.IncrCounter([]string{"foo"}, 1)
+	// Gauges are never sampled
+	sink.SetGauge([]string{"gauge"}, 4)
+	sink.EmitKey([]string{"key"}, 5)
+
+	expected := []string{
+		"baz.a_b:2|c|@0.5",
+		"foo.qux:1.5|ms|@0.25",
+		"foo.bar.c:3|ms",
+		"gauge:4|g",
+		"key:5|g",
+	}
+
+	var lines []string
+	buf := make([]byte, 65536)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Minute)))
+	for len(lines) < len(expected) {
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, n, 64)
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(buf[:n]), "\n"), "\n")...)
+	}
+	require.Equal(t, expected, lines)
+}
+
 func testStatsdConfigWithPort(port int) *MetricsConfig {
 	l, _ := test.NewNullLogger()
 
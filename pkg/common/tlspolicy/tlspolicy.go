@@ -0,0 +1,108 @@
+// Package tlspolicy provides the TLS version and cipher suite policy applied
+// to the TLS listeners of SPIRE.
+package tlspolicy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// Policy restricts the TLS versions and cipher suites accepted by a listener.
+type Policy struct {
+	// MinVersion is the minimum TLS version accepted. Defaults to TLS 1.2
+	// when unset.
+	MinVersion uint16
+
+	// MaxVersion is the maximum TLS version accepted. Defaults to the
+	// highest version supported when unset.
+	MaxVersion uint16
+
+	// CipherSuites are the cipher suites accepted for TLS 1.2 connections.
+	// The default secure cipher suites are used when empty. TLS 1.3 cipher
+	// suites are not configurable.
+	CipherSuites []uint16
+}
+
+// Validate returns an error if the policy cannot be satisfied.
+func (p Policy) Validate() error {
+	minVersion := p.minVersion()
+	if p.MaxVersion != 0 && p.MaxVersion < minVersion {
+		return errors.New("maximum TLS version cannot be lower than the minimum TLS version")
+	}
+	if len(p.CipherSuites) > 0 && minVersion >= tls.VersionTLS13 {
+		return errors.New("cipher suites cannot be configured when only TLS 1.3 is accepted")
+	}
+	return nil
+}
+
+// ApplyPolicy applies the policy to the given TLS configuration.
+func ApplyPolicy(config *tls.Config, policy Policy) {
+	config.MinVersion = policy.minVersion()
+	config.MaxVersion = policy.MaxVersion
+	config.CipherSuites = policy.CipherSuites
+}
+
+// ParseVersion parses a TLS version, either "1.2" or "1.3". An empty string
+// parses to zero, which leaves the default version in place.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q: must be one of \"1.2\" or \"1.3\"", version)
+	}
+}
+
+// ParseCipherSuites parses a list of TLS 1.2 cipher suite names, as named by
+// the crypto/tls package (e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256").
+// Cipher suites with known security issues are rejected.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	var suites []uint16
+	for _, name := range names {
+		suite, err := parseCipherSuite(name)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func parseCipherSuite(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name != name {
+			continue
+		}
+		if !supportsTLS12(suite) {
+			return 0, fmt.Errorf("cipher suite %q is only used with TLS 1.3 and is not configurable", name)
+		}
+		return suite.ID, nil
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return 0, fmt.Errorf("cipher suite %q is insecure and not allowed", name)
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher suite %q", name)
+}
+
+func supportsTLS12(suite *tls.CipherSuite) bool {
+	for _, version := range suite.SupportedVersions {
+		if version == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) minVersion() uint16 {
+	if p.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return p.MinVersion
+}
@@ -0,0 +1,87 @@
+package tlspolicy
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	version, err := ParseVersion("")
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	version, err = ParseVersion("1.2")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = ParseVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = ParseVersion("1.1")
+	assert.EqualError(t, err, `unsupported TLS version "1.1": must be one of "1.2" or "1.3"`)
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	suites, err := ParseCipherSuites(nil)
+	require.NoError(t, err)
+	assert.Empty(t, suites)
+
+	suites, err = ParseCipherSuites([]string{
+		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}, suites)
+
+	_, err = ParseCipherSuites([]string{"TLS_AES_128_GCM_SHA256"})
+	assert.EqualError(t, err, `cipher suite "TLS_AES_128_GCM_SHA256" is only used with TLS 1.3 and is not configurable`)
+
+	_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.EqualError(t, err, `cipher suite "TLS_RSA_WITH_RC4_128_SHA" is insecure and not allowed`)
+
+	_, err = ParseCipherSuites([]string{"TLS_UNKNOWN"})
+	assert.EqualError(t, err, `unknown cipher suite "TLS_UNKNOWN"`)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Policy{}.Validate())
+	assert.NoError(t, Policy{MinVersion: tls.VersionTLS13}.Validate())
+	assert.NoError(t, Policy{MaxVersion: tls.VersionTLS12}.Validate())
+	assert.NoError(t, Policy{
+		MaxVersion:   tls.VersionTLS13,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}.Validate())
+
+	assert.EqualError(t, Policy{
+		MinVersion: tls.VersionTLS13,
+		MaxVersion: tls.VersionTLS12,
+	}.Validate(), "maximum TLS version cannot be lower than the minimum TLS version")
+	assert.EqualError(t, Policy{
+		MinVersion:   tls.VersionTLS13,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}.Validate(), "cipher suites cannot be configured when only TLS 1.3 is accepted")
+}
+
+func TestApplyPolicy(t *testing.T) {
+	config := new(tls.Config)
+	ApplyPolicy(config, Policy{})
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Zero(t, config.MaxVersion)
+	assert.Nil(t, config.CipherSuites)
+
+	ApplyPolicy(config, Policy{
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS13,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	})
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MaxVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+}
@@ -16,8 +16,20 @@ func MakeCSR(privateKey interface{}, spiffeID spiffeid.ID) ([]byte, error) {
 			Country:      []string{"US"},
 			Organization: []string{"SPIRE"},
 		},
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
-		URIs:               []*url.URL{spiffeID.URL()},
+		URIs: []*url.URL{spiffeID.URL()},
+	})
+}
+
+// MakeCSRWithDNSNames creates a CSR for the SPIFFE ID that also requests the
+// given DNS names.
+func MakeCSRWithDNSNames(privateKey interface{}, spiffeID spiffeid.ID, dnsNames []string) ([]byte, error) {
+	return makeCSR(privateKey, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			Country:      []string{"US"},
+			Organization: []string{"SPIRE"},
+		},
+		URIs:     []*url.URL{spiffeID.URL()},
+		DNSNames: dnsNames,
 	})
 }
 
@@ -27,10 +39,11 @@ func MakeCSRWithoutURISAN(privateKey interface{}) ([]byte, error) {
 			Country:      []string{"US"},
 			Organization: []string{"SPIRE"},
 		},
-		SignatureAlgorithm: x509.ECDSAWithSHA256,
 	})
 }
 
+// makeCSR creates a CSR signed by the given private key. The signature
+// algorithm is left unset so it is chosen based on the type of the key.
 func makeCSR(privateKey interface{}, template *x509.CertificateRequest) ([]byte, error) {
 	csr, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
 	if err != nil {
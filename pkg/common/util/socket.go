@@ -0,0 +1,20 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ParseSocketMode parses an octal permission string (e.g. "0770") into a
+// file mode suitable for a Unix domain socket.
+func ParseSocketMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid socket mode %q: must be an octal number", s)
+	}
+	if mode&^uint64(os.ModePerm) != 0 {
+		return 0, fmt.Errorf("invalid socket mode %q: only permission bits are allowed", s)
+	}
+	return os.FileMode(mode), nil
+}
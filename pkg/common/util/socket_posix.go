@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// SetSocketPermissions sets the mode of the Unix domain socket at the given
+// path and, if group is not empty, changes its group ownership. The group
+// can be either a group name or a numeric group ID.
+func SetSocketPermissions(path string, mode os.FileMode, group string) error {
+	if group != "" {
+		gid, err := lookupGroupID(group)
+		if err != nil {
+			return err
+		}
+		if err := os.Chown(path, -1, gid); err != nil {
+			return fmt.Errorf("unable to change UDS group: %w", err)
+		}
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("unable to change UDS permissions: %w", err)
+	}
+	return nil
+}
+
+func lookupGroupID(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("unable to look up UDS group %q: %w", group, err)
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse ID %q of UDS group %q: %w", g.Gid, group, err)
+	}
+	return gid, nil
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+// +build !windows
+
+package util
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSocketPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	gid := os.Getgid()
+	group, err := user.LookupGroupId(strconv.Itoa(gid))
+	require.NoError(t, err)
+
+	assertPermissions := func(mode os.FileMode) {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, mode, info.Mode().Perm())
+		require.Equal(t, uint32(gid), info.Sys().(*syscall.Stat_t).Gid)
+	}
+
+	t.Run("mode only", func(t *testing.T) {
+		require.NoError(t, SetSocketPermissions(path, 0700, ""))
+		assertPermissions(0700)
+	})
+
+	t.Run("numeric group", func(t *testing.T) {
+		require.NoError(t, SetSocketPermissions(path, 0770, strconv.Itoa(gid)))
+		assertPermissions(0770)
+	})
+
+	t.Run("group name", func(t *testing.T) {
+		require.NoError(t, SetSocketPermissions(path, 0777, group.Name))
+		assertPermissions(0777)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		err := SetSocketPermissions(path, 0770, "spire-no-such-group")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unable to look up UDS group "spire-no-such-group"`)
+	})
+}
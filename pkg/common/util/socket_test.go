@@ -0,0 +1,27 @@
+package util
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSocketMode(t *testing.T) {
+	mode, err := ParseSocketMode("0770")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0770), mode)
+
+	mode, err = ParseSocketMode("777")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0777), mode)
+
+	_, err = ParseSocketMode("rwx")
+	require.EqualError(t, err, `invalid socket mode "rwx": must be an octal number`)
+
+	_, err = ParseSocketMode("0778")
+	require.EqualError(t, err, `invalid socket mode "0778": must be an octal number`)
+
+	_, err = ParseSocketMode("4770")
+	require.EqualError(t, err, `invalid socket mode "4770": only permission bits are allowed`)
+}
@@ -0,0 +1,135 @@
+// Package workloadapi provides helpers for consuming the SPIFFE Workload API
+// from SPIRE components and tools. It builds on the go-spiffe Workload API
+// client, taking care of resolving SPIRE socket and named pipe addresses and
+// of retrying one-off requests.
+package workloadapi
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/cenkalti/backoff/v3"
+	"github.com/spiffe/go-spiffe/v2/logger"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/spiffe/spire/pkg/common/util"
+)
+
+const (
+	defaultRetryInterval = time.Second
+	maxRetryInterval     = 30 * time.Second
+)
+
+// Config configures how the Workload API is reached.
+type Config struct {
+	// Addr is the address of the Workload API. If unset, the address is
+	// read from the SPIFFE_ENDPOINT_SOCKET environment variable.
+	Addr net.Addr
+
+	// Log receives the messages logged by the client, e.g. when watching
+	// the Workload API fails and is retried. A logrus.FieldLogger can be
+	// used. If unset, nothing is logged.
+	Log logger.Logger
+}
+
+func (c Config) clientOptions() ([]workloadapi.ClientOption, error) {
+	var opts []workloadapi.ClientOption
+	if c.Addr != nil {
+		opt, err := util.GetWorkloadAPIClientOption(c.Addr)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	if c.Log != nil {
+		opts = append(opts, workloadapi.WithLogger(c.Log))
+	}
+	return opts, nil
+}
+
+// NewClient returns a Workload API client. The client must be closed when
+// no longer in use.
+func NewClient(ctx context.Context, config Config) (*workloadapi.Client, error) {
+	opts, err := config.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return workloadapi.New(ctx, opts...)
+}
+
+// NewX509Source returns a source of X509-SVIDs and X.509 bundles that is
+// kept up to date by watching the Workload API. It blocks until the first
+// update is received or the context is done. The source must be closed when
+// no longer in use.
+func NewX509Source(ctx context.Context, config Config) (*workloadapi.X509Source, error) {
+	opts, err := config.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(opts...))
+}
+
+// NewJWTSource returns a source of JWT-SVIDs and JWT bundles that is kept up
+// to date by watching the Workload API. It blocks until the first update is
+// received or the context is done. The source must be closed when no longer
+// in use.
+func NewJWTSource(ctx context.Context, config Config) (*workloadapi.JWTSource, error) {
+	opts, err := config.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return workloadapi.NewJWTSource(ctx, workloadapi.WithClientOptions(opts...))
+}
+
+// WatchX509Context watches the Workload API for X509 context updates,
+// calling onUpdate with each update, until the context is done. Failures to
+// watch are logged and retried with backoff.
+func WatchX509Context(ctx context.Context, config Config, onUpdate func(*workloadapi.X509Context)) error {
+	client, err := NewClient(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.WatchX509Context(ctx, x509ContextWatcher(onUpdate))
+}
+
+// Retry calls fn until it succeeds or the context is done, backing off
+// exponentially between attempts. It is meant for one-off requests, like
+// fetching a JWT-SVID, that can fail while the agent is starting or the
+// workload registration has not been synced yet. When the context is done,
+// the error of the last attempt is returned.
+func Retry(ctx context.Context, clk clock.Clock, fn func(context.Context) error) error {
+	b := &backoff.ExponentialBackOff{
+		Clock:               clk,
+		InitialInterval:     defaultRetryInterval,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         maxRetryInterval,
+	}
+	b.Reset()
+
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clk.After(b.NextBackOff()):
+		}
+	}
+}
+
+type x509ContextWatcher func(*workloadapi.X509Context)
+
+func (w x509ContextWatcher) OnX509ContextUpdate(x509Context *workloadapi.X509Context) {
+	w(x509Context)
+}
+
+func (w x509ContextWatcher) OnX509ContextWatchError(error) {
+	// The client logs the error before retrying
+}
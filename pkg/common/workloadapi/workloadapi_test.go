@@ -0,0 +1,151 @@
+package workloadapi_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	goworkloadapi "github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/spiffe/spire/pkg/common/workloadapi"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakeworkloadapi"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	td         = spiffeid.RequireTrustDomainFromString("example.org")
+	workloadID = spiffeid.RequireFromPath(td, "/workload")
+)
+
+func TestNewX509Source(t *testing.T) {
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(workloadID)
+	api := fakeworkloadapi.New(t, fakeworkloadapi.FetchX509SVIDResponses(x509SVIDResponse(t, ca, svid)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	source, err := workloadapi.NewX509Source(ctx, workloadapi.Config{Addr: api.Addr()})
+	require.NoError(t, err)
+	defer source.Close()
+
+	gotSVID, err := source.GetX509SVID()
+	require.NoError(t, err)
+	require.Equal(t, workloadID, gotSVID.ID)
+	require.Equal(t, svid.Certificates, gotSVID.Certificates)
+
+	bundle, err := source.GetX509BundleForTrustDomain(td)
+	require.NoError(t, err)
+	require.Equal(t, ca.X509Authorities(), bundle.X509Authorities())
+}
+
+func TestWatchX509Context(t *testing.T) {
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(workloadID)
+	api := fakeworkloadapi.New(t, fakeworkloadapi.FetchX509SVIDResponses(x509SVIDResponse(t, ca, svid)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	updates := make(chan *goworkloadapi.X509Context, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- workloadapi.WatchX509Context(ctx, workloadapi.Config{Addr: api.Addr()}, func(x509Context *goworkloadapi.X509Context) {
+			updates <- x509Context
+		})
+	}()
+
+	select {
+	case x509Context := <-updates:
+		require.Len(t, x509Context.SVIDs, 1)
+		require.Equal(t, workloadID, x509Context.SVIDs[0].ID)
+	case <-ctx.Done():
+		require.FailNow(t, "timed out waiting for the X509 context update")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for the watch to stop")
+	}
+}
+
+func TestNewClientInvalidAddress(t *testing.T) {
+	_, err := workloadapi.NewClient(context.Background(), workloadapi.Config{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080},
+	})
+	require.Error(t, err)
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("succeeds after failures", func(t *testing.T) {
+		clk := clock.NewMock(t)
+		attempts := 0
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- workloadapi.Retry(context.Background(), clk, func(context.Context) error {
+				attempts++
+				if attempts < 3 {
+					return errors.New("oh no")
+				}
+				return nil
+			})
+		}()
+
+		for i := 0; i < 2; i++ {
+			clk.WaitForAfter(time.Minute, "timed out waiting for the retry to back off")
+			clk.Add(time.Minute)
+		}
+		require.NoError(t, <-errCh)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("returns last error when the context is done", func(t *testing.T) {
+		clk := clock.NewMock(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- workloadapi.Retry(ctx, clk, func(context.Context) error {
+				return errors.New("oh no")
+			})
+		}()
+
+		clk.WaitForAfter(time.Minute, "timed out waiting for the retry to back off")
+		cancel()
+		require.EqualError(t, <-errCh, "oh no")
+	})
+}
+
+func x509SVIDResponse(t *testing.T, ca *testca.CA, svid *x509svid.SVID) *workload.X509SVIDResponse {
+	var certs []byte
+	for _, cert := range svid.Certificates {
+		certs = append(certs, cert.Raw...)
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+
+	var bundle []byte
+	for _, cert := range ca.X509Authorities() {
+		bundle = append(bundle, cert.Raw...)
+	}
+
+	return &workload.X509SVIDResponse{
+		Svids: []*workload.X509SVID{
+			{
+				SpiffeId:    svid.ID.String(),
+				X509Svid:    certs,
+				X509SvidKey: key,
+				Bundle:      bundle,
+			},
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+)
+
+// bootstrapSVIDType is the attestation type recorded for agents bootstrapped
+// with an agent SVID minted by an administrator.
+const bootstrapSVIDType = "bootstrap_svid"
+
+// MintAgentX509SVID mints an agent X509-SVID that is delivered to the agent
+// out-of-band, so the agent can skip node attestation. The agent is recorded
+// as attested with the selectors given by the administrator.
+func (s *Service) MintAgentX509SVID(ctx context.Context, req *agentbootstrap.MintAgentX509SVIDRequest) (*agentbootstrap.MintAgentX509SVIDResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	agentID, err := spiffeid.FromString(req.SpiffeId)
+	if err == nil {
+		err = api.VerifyTrustDomainAgentID(s.td, agentID)
+	}
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.AgentID: agentID.String()})
+	log = log.WithField(telemetry.AgentID, agentID.String())
+
+	selectors, err := parseBootstrapSelectors(req.Selectors)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid selectors", err)
+	}
+
+	attestedNode, err := s.ds.FetchAttestedNode(ctx, agentID.String())
+	switch {
+	case err != nil:
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+	case attestedNode != nil && nodeutil.IsAgentBanned(attestedNode):
+		return nil, api.MakeErrWithReason(log, codes.PermissionDenied, api.ReasonAgentBanned, "agent is banned", nil)
+	case attestedNode != nil:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "agent already exists", nil)
+	}
+
+	svid, err := s.signSvid(ctx, agentID, req.Csr, log)
+	if err != nil {
+		return nil, err
+	}
+
+	// The node is created before its selectors so selectors are never left
+	// behind for an agent that does not exist. If the selectors cannot be
+	// set, the node is deleted so the administrator can mint the SVID again.
+	if _, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		AttestationDataType: bootstrapSVIDType,
+		SpiffeId:            agentID.String(),
+		CertNotAfter:        svid[0].NotAfter.Unix(),
+		CertSerialNumber:    svid[0].SerialNumber.String(),
+	}); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to create attested agent", err)
+	}
+	if err := s.ds.SetNodeSelectors(ctx, agentID.String(), selectors); err != nil {
+		if _, deleteErr := s.ds.DeleteAttestedNode(ctx, agentID.String()); deleteErr != nil {
+			log.WithError(deleteErr).Error("Failed to delete attested agent after failing to update selectors")
+		}
+		return nil, api.MakeErr(log, codes.Internal, "failed to update selectors", err)
+	}
+
+	s.expandEntryTemplates(ctx, log, agentID, selectors)
+
+	log.WithField(telemetry.SerialNumber, svid[0].SerialNumber.String()).Info("Agent bootstrap SVID minted")
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
+		telemetry.SerialNumber: svid[0].SerialNumber.String(),
+	})
+
+	resp := &agentbootstrap.MintAgentX509SVIDResponse{}
+	for _, cert := range svid {
+		resp.CertChain = append(resp.CertChain, cert.Raw)
+	}
+	return resp, nil
+}
+
+func parseBootstrapSelectors(in []string) ([]*common.Selector, error) {
+	var out []*common.Selector
+	for _, s := range in {
+		selectorType, value, ok := strings.Cut(s, ":")
+		if !ok || selectorType == "" || value == "" {
+			return nil, fmt.Errorf("%q is not of the form <type>:<value>", s)
+		}
+		out = append(out, &common.Selector{Type: selectorType, Value: value})
+	}
+	return out, nil
+}
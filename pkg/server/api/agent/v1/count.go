@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"google.golang.org/grpc/codes"
+)
+
+// countPageSize is the page size used to walk the agents that match a
+// filter, since the datastore can only count every attested node.
+const countPageSize = 1000
+
+// CountFilteredAgents returns the number of agents that match the given
+// filter, which supports the same fields as the ListAgents filter.
+func (s *Service) CountFilteredAgents(ctx context.Context, req *count.CountFilteredAgentsRequest) (*count.CountFilteredAgentsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Filter == nil {
+		total, err := s.ds.CountAttestedNodes(ctx)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to count agents", err)
+		}
+		rpccontext.AuditRPC(ctx)
+
+		return &count.CountFilteredAgentsResponse{Count: total}, nil
+	}
+
+	rpccontext.AddRPCAuditFields(ctx, fieldsFromFilterRequest(req.Filter))
+
+	listReq := &datastore.ListAttestedNodesRequest{
+		Pagination: &datastore.Pagination{
+			PageSize: countPageSize,
+		},
+	}
+	if err := applyListAgentsFilter(log, req.Filter, listReq); err != nil {
+		return nil, err
+	}
+
+	var total int32
+	for {
+		dsResp, err := s.ds.ListAttestedNodes(ctx, listReq)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to count agents", err)
+		}
+		total += int32(len(dsResp.Nodes))
+		if dsResp.Pagination == nil || dsResp.Pagination.Token == "" || len(dsResp.Nodes) == 0 {
+			break
+		}
+		listReq.Pagination.Token = dsResp.Pagination.Token
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &count.CountFilteredAgentsResponse{Count: total}, nil
+}
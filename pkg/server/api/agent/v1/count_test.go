@@ -0,0 +1,113 @@
+package agent_test
+
+import (
+	"errors"
+	"testing"
+
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCountFilteredAgents(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		filter      *agentv1.ListAgentsRequest_Filter
+		dsError     error
+		expectCount int32
+		expectCode  codes.Code
+		expectMsg   string
+	}{
+		{
+			name:        "no filter",
+			expectCount: 3,
+		},
+		{
+			name: "by attestation type",
+			filter: &agentv1.ListAgentsRequest_Filter{
+				ByAttestationType: "t1",
+			},
+			expectCount: 2,
+		},
+		{
+			name: "by banned",
+			filter: &agentv1.ListAgentsRequest_Filter{
+				ByBanned: wrapperspb.Bool(true),
+			},
+			expectCount: 1,
+		},
+		{
+			name: "by selectors",
+			filter: &agentv1.ListAgentsRequest_Filter{
+				BySelectorMatch: &types.SelectorMatch{
+					Selectors: []*types.Selector{{Type: "a", Value: "1"}},
+					Match:     types.SelectorMatch_MATCH_SUPERSET,
+				},
+			},
+			expectCount: 2,
+		},
+		{
+			name: "malformed selectors",
+			filter: &agentv1.ListAgentsRequest_Filter{
+				BySelectorMatch: &types.SelectorMatch{
+					Selectors: []*types.Selector{{Value: "1"}},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to parse selectors: missing selector type",
+		},
+		{
+			name: "ds error",
+			filter: &agentv1.ListAgentsRequest_Filter{
+				ByAttestationType: "t1",
+			},
+			dsError:    errors.New("some error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to count agents: some error",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			for _, node := range []*common.AttestedNode{
+				{
+					SpiffeId:            "spiffe://example.org/spire/agent/node1",
+					AttestationDataType: "t1",
+					CertSerialNumber:    "badcafe",
+					Selectors:           []*common.Selector{{Type: "a", Value: "1"}},
+				},
+				{
+					SpiffeId:            "spiffe://example.org/spire/agent/node2",
+					AttestationDataType: "t1",
+					CertSerialNumber:    "badcafe",
+					Selectors:           []*common.Selector{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}},
+				},
+				{
+					SpiffeId:            "spiffe://example.org/spire/agent/banned",
+					AttestationDataType: "t2",
+				},
+			} {
+				_, err := test.ds.CreateAttestedNode(ctx, node)
+				require.NoError(t, err)
+				require.NoError(t, test.ds.SetNodeSelectors(ctx, node.SpiffeId, node.Selectors))
+			}
+
+			test.ds.SetNextError(tt.dsError)
+			resp, err := test.countClient.CountFilteredAgents(ctx, &count.CountFilteredAgentsRequest{Filter: tt.filter})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectCount, resp.Count)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// expandEntryTemplates creates the entries of the entry templates that match
+// the selectors of an attested agent. Entries that already exist are left
+// untouched, so expanding the templates again when the agent re-attests is
+// harmless. Failures are logged and do not fail the attestation.
+func (s *Service) expandEntryTemplates(ctx context.Context, log logrus.FieldLogger, agentID spiffeid.ID, selectors []*common.Selector) {
+	templates, err := s.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to list entry templates")
+		return
+	}
+
+	for _, template := range templates {
+		if !api.EntryTemplateMatches(template, selectors) {
+			continue
+		}
+
+		log := log.WithField(telemetry.EntryTemplateID, template.ID)
+		entry, err := api.RenderEntryTemplate(template, agentID, selectors)
+		if err != nil {
+			log.WithError(err).Warn("Failed to render entry template")
+			continue
+		}
+
+		entry, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, entry)
+		switch {
+		case err != nil:
+			log.WithError(err).Error("Failed to create entry from entry template")
+		case !existing:
+			log.WithFields(logrus.Fields{
+				telemetry.RegistrationID: entry.EntryId,
+				telemetry.SPIFFEID:       entry.SpiffeId,
+			}).Info("Created entry from entry template")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
+	"google.golang.org/grpc/codes"
+)
+
+// ListJoinTokens lists the join tokens that have not been used or pruned.
+func (s *Service) ListJoinTokens(ctx context.Context, req *jointoken.ListJoinTokensRequest) (*jointoken.ListJoinTokensResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	tokens, err := s.ds.ListJoinTokens(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list join tokens", err)
+	}
+
+	resp := &jointoken.ListJoinTokensResponse{}
+	for _, token := range tokens {
+		resp.Tokens = append(resp.Tokens, &jointoken.JoinToken{
+			Value:     token.Token,
+			ExpiresAt: token.Expiry.Unix(),
+		})
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// RevokeJoinToken deletes a join token so it can no longer be used to attest
+// an agent. The registration entry created for the token, if any, is kept,
+// as it is when the token is used or expires.
+func (s *Service) RevokeJoinToken(ctx context.Context, req *jointoken.RevokeJoinTokenRequest) (*jointoken.RevokeJoinTokenResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Value == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing join token", nil)
+	}
+
+	token, err := s.ds.FetchJoinToken(ctx, req.Value)
+	switch {
+	case err != nil:
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch join token", err)
+	case token == nil:
+		return nil, api.MakeErr(log, codes.NotFound, "join token not found", nil)
+	}
+
+	if err := s.ds.DeleteJoinToken(ctx, req.Value); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to delete join token", err)
+	}
+
+	log.Info("Join token revoked")
+	rpccontext.AuditRPC(ctx)
+
+	return &jointoken.RevokeJoinTokenResponse{}, nil
+}
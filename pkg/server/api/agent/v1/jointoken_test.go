@@ -0,0 +1,113 @@
+package agent_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestListJoinTokens(t *testing.T) {
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+
+	expiry := time.Unix(1000, 0)
+	require.NoError(t, test.ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token-2", Expiry: expiry.Add(time.Minute)}))
+	require.NoError(t, test.ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token-1", Expiry: expiry}))
+
+	resp, err := test.joinTokenClient.ListJoinTokens(ctx, &jointoken.ListJoinTokensRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*jointoken.JoinToken{
+		{Value: "token-1", ExpiresAt: expiry.Unix()},
+		{Value: "token-2", ExpiresAt: expiry.Add(time.Minute).Unix()},
+	}, resp.Tokens)
+
+	test.ds.SetNextError(errors.New("some error"))
+	_, err = test.joinTokenClient.ListJoinTokens(ctx, &jointoken.ListJoinTokensRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list join tokens: some error")
+}
+
+func TestRevokeJoinToken(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		token      string
+		dsErrors   []error
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name:  "success",
+			token: "token",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Join token revoked",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "missing token",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "missing join token",
+		},
+		{
+			name:       "token not found",
+			token:      "unknown",
+			expectCode: codes.NotFound,
+			expectMsg:  "join token not found",
+		},
+		{
+			name:       "fails to fetch token",
+			token:      "token",
+			dsErrors:   []error{errors.New("some error")},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch join token: some error",
+		},
+		{
+			name:       "fails to delete token",
+			token:      "token",
+			dsErrors:   []error{nil, errors.New("some error")},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to delete join token: some error",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			require.NoError(t, test.ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token", Expiry: time.Unix(1000, 0)}))
+			for _, dsErr := range tt.dsErrors {
+				test.ds.AppendNextError(dsErr)
+			}
+
+			_, err := test.joinTokenClient.RevokeJoinToken(ctx, &jointoken.RevokeJoinTokenRequest{Value: tt.token})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+
+			token, err := test.ds.FetchJoinToken(ctx, tt.token)
+			require.NoError(t, err)
+			require.Nil(t, token)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"google.golang.org/grpc/codes"
+)
+
+// RequestAgentRenewal requests the agent to renew the agent SVID it is
+// currently using. The agent is asked to renew the next time it fetches its
+// authorized entries, i.e. on its next sync with any of the servers sharing
+// the datastore.
+func (s *Service) RequestAgentRenewal(ctx context.Context, req *agentrenewal.RequestAgentRenewalRequest) (*agentrenewal.RequestAgentRenewalResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	id, err := spiffeid.FromString(req.SpiffeId)
+	if err == nil {
+		err = api.VerifyTrustDomainAgentID(s.td, id)
+	}
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: id.String()})
+
+	log = log.WithField(telemetry.SPIFFEID, id.String())
+
+	attestedNode, err := s.ds.FetchAttestedNode(ctx, id.String())
+	switch {
+	case err != nil:
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+	case attestedNode == nil:
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", nil)
+	case attestedNode.CertSerialNumber == "":
+		return nil, api.MakeErrWithReason(log, codes.FailedPrecondition, api.ReasonAgentBanned, "agent is banned", nil)
+	}
+
+	if err := s.ds.SetAgentRenewal(ctx, &datastore.AgentRenewal{
+		SpiffeID:     attestedNode.SpiffeId,
+		SerialNumber: attestedNode.CertSerialNumber,
+	}); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to request agent renewal", err)
+	}
+
+	log.WithField(telemetry.SerialNumber, attestedNode.CertSerialNumber).Info("Agent SVID renewal requested")
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
+		telemetry.SerialNumber: attestedNode.CertSerialNumber,
+	})
+
+	return &agentrenewal.RequestAgentRenewalResponse{
+		X509SvidSerialNumber: attestedNode.CertSerialNumber,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"errors"
@@ -15,14 +16,21 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/plugin/x509pop"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
+	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -31,6 +39,10 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// x509PoPTLSType is the attestation type of agents attesting with an
+// externally issued client certificate presented during the TLS handshake.
+const x509PoPTLSType = "x509pop_tls"
+
 // Config is the service configuration
 type Config struct {
 	Catalog     catalog.Catalog
@@ -39,11 +51,24 @@ type Config struct {
 	ServerCA    ca.ServerCA
 	AgentTTL    time.Duration
 	TrustDomain spiffeid.TrustDomain
+
+	// AgentMaxRenewalAge, if positive, is how long after their last node
+	// attestation agents that can re-attest are allowed to keep renewing their
+	// SVID. Once exceeded, the agent is asked to re-attest.
+	AgentMaxRenewalAge time.Duration
+
+	// AttestationNotifier, if set, is notified of the result of every node
+	// attestation.
+	AttestationNotifier attestationwebhook.Notifier
 }
 
 // Service implements the v1 agent service
 type Service struct {
 	agentv1.UnsafeAgentServer
+	agentbootstrap.UnsafeAgentBootstrapServer
+	agentrenewal.UnsafeAgentRenewalServer
+	jointoken.UnsafeJoinTokensServer
+	count.UnsafeAgentCountServer
 
 	cat      catalog.Catalog
 	clk      clock.Clock
@@ -51,6 +76,9 @@ type Service struct {
 	ca       ca.ServerCA
 	td       spiffeid.TrustDomain
 	agentTTL time.Duration
+	notifier attestationwebhook.Notifier
+
+	agentMaxRenewalAge time.Duration
 }
 
 // New creates a new agent service
@@ -62,12 +90,19 @@ func New(config Config) *Service {
 		ca:       config.ServerCA,
 		td:       config.TrustDomain,
 		agentTTL: config.AgentTTL,
+		notifier: config.AttestationNotifier,
+
+		agentMaxRenewalAge: config.AgentMaxRenewalAge,
 	}
 }
 
 // RegisterService registers the agent service on the gRPC server/
 func RegisterService(s *grpc.Server, service *Service) {
 	agentv1.RegisterAgentServer(s, service)
+	agentbootstrap.RegisterAgentBootstrapServer(s, service)
+	agentrenewal.RegisterAgentRenewalServer(s, service)
+	jointoken.RegisterJoinTokensServer(s, service)
+	count.RegisterAgentCountServer(s, service)
 }
 
 // CountAgents returns the total number of agents.
@@ -93,26 +128,9 @@ func (s *Service) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest
 	}
 	// Parse proto filter into datastore request
 	if req.Filter != nil {
-		filter := req.Filter
-		rpccontext.AddRPCAuditFields(ctx, fieldsFromFilterRequest(filter))
-
-		var byBanned *bool
-		if filter.ByBanned != nil {
-			byBanned = &filter.ByBanned.Value
-		}
-
-		listReq.ByAttestationType = filter.ByAttestationType
-		listReq.ByBanned = byBanned
-
-		if filter.BySelectorMatch != nil {
-			selectors, err := api.SelectorsFromProto(filter.BySelectorMatch.Selectors)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "failed to parse selectors", err)
-			}
-			listReq.BySelectorMatch = &datastore.BySelectors{
-				Match:     datastore.MatchBehavior(filter.BySelectorMatch.Match),
-				Selectors: selectors,
-			}
+		rpccontext.AddRPCAuditFields(ctx, fieldsFromFilterRequest(req.Filter))
+		if err := applyListAgentsFilter(log, req.Filter, listReq); err != nil {
+			return nil, err
 		}
 	}
 
@@ -151,6 +169,31 @@ func (s *Service) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest
 	return resp, nil
 }
 
+// applyListAgentsFilter sets the datastore filters that correspond to the
+// given ListAgents filter.
+func applyListAgentsFilter(log logrus.FieldLogger, filter *agentv1.ListAgentsRequest_Filter, listReq *datastore.ListAttestedNodesRequest) error {
+	var byBanned *bool
+	if filter.ByBanned != nil {
+		byBanned = &filter.ByBanned.Value
+	}
+
+	listReq.ByAttestationType = filter.ByAttestationType
+	listReq.ByBanned = byBanned
+
+	if filter.BySelectorMatch != nil {
+		selectors, err := api.SelectorsFromProto(filter.BySelectorMatch.Selectors)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "failed to parse selectors", err)
+		}
+		listReq.BySelectorMatch = &datastore.BySelectors{
+			Match:     datastore.MatchBehavior(filter.BySelectorMatch.Match),
+			Selectors: selectors,
+		}
+	}
+
+	return nil
+}
+
 // GetAgent returns the agent associated with the given SpiffeID.
 func (s *Service) GetAgent(ctx context.Context, req *agentv1.GetAgentRequest) (*types.Agent, error) {
 	log := rpccontext.Logger(ctx)
@@ -168,10 +211,10 @@ func (s *Service) GetAgent(ctx context.Context, req *agentv1.GetAgentRequest) (*
 	}
 
 	if attestedNode == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "agent not found", err)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", err)
 	}
 
-	selectors, err := s.getSelectorsFromAgentID(ctx, attestedNode.SpiffeId)
+	selectors, err := s.getSelectorsFromAgentID(dscache.WithCache(ctx), attestedNode.SpiffeId)
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to get selectors from agent", err)
 	}
@@ -205,7 +248,7 @@ func (s *Service) DeleteAgent(ctx context.Context, req *agentv1.DeleteAgentReque
 		rpccontext.AuditRPC(ctx)
 		return &emptypb.Empty{}, nil
 	case codes.NotFound:
-		return nil, api.MakeErr(log, codes.NotFound, "agent not found", err)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", err)
 	default:
 		return nil, api.MakeErr(log, codes.Internal, "failed to remove agent", err)
 	}
@@ -238,14 +281,14 @@ func (s *Service) BanAgent(ctx context.Context, req *agentv1.BanAgentRequest) (*
 		rpccontext.AuditRPC(ctx)
 		return &emptypb.Empty{}, nil
 	case codes.NotFound:
-		return nil, api.MakeErr(log, codes.NotFound, "agent not found", err)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", err)
 	default:
 		return nil, api.MakeErr(log, codes.Internal, "failed to ban agent", err)
 	}
 }
 
 // AttestAgent attests the authenticity of the given agent.
-func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
+func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) (err error) {
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
@@ -269,14 +312,25 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 
 	log = log.WithField(telemetry.NodeAttestorType, params.Data.Type)
 
+	event := attestationwebhook.Event{AttestationType: params.Data.Type}
+	defer func() {
+		s.notifyAttestation(ctx, event, err)
+	}()
+
 	// attest
 	var attestResult *nodeattestor.AttestResult
-	if params.Data.Type == "join_token" {
+	switch params.Data.Type {
+	case "join_token":
 		attestResult, err = s.attestJoinToken(ctx, string(params.Data.Payload))
 		if err != nil {
 			return err
 		}
-	} else {
+	case x509PoPTLSType:
+		attestResult, err = s.attestX509PoPTLS(ctx, params.Data.Payload)
+		if err != nil {
+			return err
+		}
+	default:
 		attestResult, err = s.attestChallengeResponse(ctx, stream, params)
 		if err != nil {
 			return err
@@ -290,6 +344,8 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 
 	log = log.WithField(telemetry.AgentID, agentID)
 	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.AgentID: agentID})
+	event.AgentID = agentID.String()
+	event.Selectors = webhookSelectors(attestResult.Selectors)
 
 	// Ideally we'd do stronger validation that the ID is within the Node
 	// Attestors scoped area of the reserved agent namespace, but historically
@@ -311,7 +367,7 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 	}
 
 	if attestedNode != nil && nodeutil.IsAgentBanned(attestedNode) {
-		return api.MakeErr(log, codes.PermissionDenied, "failed to attest: agent is banned", nil)
+		return api.MakeErrWithReason(log, codes.PermissionDenied, api.ReasonAgentBanned, "failed to attest: agent is banned", nil)
 	}
 
 	// parse and sign CSR
@@ -325,11 +381,14 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 	if err != nil {
 		return api.MakeErr(log, codes.Internal, "failed to resolve selectors", err)
 	}
-	// store augmented selectors
-	err = s.ds.SetNodeSelectors(ctx, agentID.String(), append(attestResult.Selectors, resolvedSelectors...))
+	// store augmented selectors, caching them for the authorized entry
+	// lookups the agent makes right after attesting
+	agentSelectors := append(attestResult.Selectors, resolvedSelectors...)
+	err = s.ds.SetNodeSelectors(dscache.WithCache(ctx), agentID.String(), agentSelectors)
 	if err != nil {
 		return api.MakeErr(log, codes.Internal, "failed to update selectors", err)
 	}
+	event.Selectors = append(event.Selectors, webhookSelectors(resolvedSelectors)...)
 
 	// create or update attested entry
 	if attestedNode == nil {
@@ -339,6 +398,7 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 			CertNotAfter:        svid[0].NotAfter.Unix(),
 			CertSerialNumber:    svid[0].SerialNumber.String(),
 			CanReattest:         attestResult.CanReattest,
+			AttestedAt:          s.clk.Now().Unix(),
 		}
 		if _, err := s.ds.CreateAttestedNode(ctx, node); err != nil {
 			return api.MakeErr(log, codes.Internal, "failed to create attested agent", err)
@@ -349,12 +409,15 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 			CertNotAfter:     svid[0].NotAfter.Unix(),
 			CertSerialNumber: svid[0].SerialNumber.String(),
 			CanReattest:      attestResult.CanReattest,
+			AttestedAt:       s.clk.Now().Unix(),
 		}
 		if _, err := s.ds.UpdateAttestedNode(ctx, node, nil); err != nil {
 			return api.MakeErr(log, codes.Internal, "failed to update attested agent", err)
 		}
 	}
 
+	s.expandEntryTemplates(ctx, log, agentID, agentSelectors)
+
 	// build and send response
 	response := getAttestAgentResponse(agentID, svid)
 
@@ -371,6 +434,36 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 	return nil
 }
 
+// notifyAttestation notifies the result of a node attestation, if an
+// attestation notifier is configured.
+func (s *Service) notifyAttestation(ctx context.Context, event attestationwebhook.Event, err error) {
+	if s.notifier == nil {
+		return
+	}
+
+	event.Time = s.clk.Now().UTC()
+	event.Result = attestationwebhook.ResultSuccess
+	if err != nil {
+		event.Result = attestationwebhook.ResultFailure
+		event.Error = status.Convert(err).Message()
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		event.Address = p.Addr.String()
+	}
+	s.notifier.Notify(event)
+}
+
+func webhookSelectors(selectors []*common.Selector) []attestationwebhook.Selector {
+	var out []attestationwebhook.Selector
+	for _, selector := range selectors {
+		out = append(out, attestationwebhook.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	return out
+}
+
 // RenewAgent renews the SVID of the agent with the given SpiffeID.
 func (s *Service) RenewAgent(ctx context.Context, req *agentv1.RenewAgentRequest) (*agentv1.RenewAgentResponse, error) {
 	log := rpccontext.Logger(ctx)
@@ -396,6 +489,10 @@ func (s *Service) RenewAgent(ctx context.Context, req *agentv1.RenewAgentRequest
 		return nil, api.MakeErr(log, codes.InvalidArgument, "missing CSR", nil)
 	}
 
+	if err := s.checkRenewalAge(ctx, callerID, log); err != nil {
+		return nil, err
+	}
+
 	agentSVID, err := s.signSvid(ctx, callerID, req.Params.Csr, log)
 	if err != nil {
 		return nil, err
@@ -425,6 +522,40 @@ func (s *Service) RenewAgent(ctx context.Context, req *agentv1.RenewAgentRequest
 	}, nil
 }
 
+// checkRenewalAge fails with a PermissionDenied error asking the agent to
+// re-attest when the agent can re-attest and its last node attestation is
+// older than the maximum renewal age. Agents that cannot re-attest, or whose
+// attestation time is unknown, keep renewing their SVID.
+func (s *Service) checkRenewalAge(ctx context.Context, agentID spiffeid.ID, log logrus.FieldLogger) error {
+	if s.agentMaxRenewalAge <= 0 {
+		return nil
+	}
+
+	attestedNode, err := s.ds.FetchAttestedNode(ctx, agentID.String())
+	switch {
+	case err != nil:
+		return api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+	case attestedNode == nil:
+		return api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", nil)
+	case !attestedNode.CanReattest || attestedNode.AttestedAt == 0:
+		return nil
+	}
+
+	attestedAt := time.Unix(attestedNode.AttestedAt, 0)
+	if s.clk.Now().Sub(attestedAt) <= s.agentMaxRenewalAge {
+		return nil
+	}
+
+	log.WithField(telemetry.AttestedAt, attestedAt.UTC().Format(time.RFC3339)).Warn("Agent must re-attest; maximum renewal age exceeded")
+	st := status.New(codes.PermissionDenied, "agent must re-attest: maximum renewal age exceeded")
+	if detailed, err := st.WithDetails(&types.PermissionDeniedDetails{
+		Reason: types.PermissionDeniedDetails_AGENT_EXPIRED,
+	}); err == nil {
+		st = detailed
+	}
+	return api.WithReason(st.Err(), api.ReasonAgentExpired)
+}
+
 // CreateJoinToken returns a new JoinToken for an agent.
 func (s *Service) CreateJoinToken(ctx context.Context, req *agentv1.CreateJoinTokenRequest) (*types.JoinToken, error) {
 	log := rpccontext.Logger(ctx)
@@ -508,7 +639,7 @@ func (s *Service) updateAttestedNode(ctx context.Context, node *common.AttestedN
 	case codes.OK:
 		return nil
 	case codes.NotFound:
-		return api.MakeErr(log, codes.NotFound, "agent not found", err)
+		return api.MakeErrWithReason(log, codes.NotFound, api.ReasonAgentNotFound, "agent not found", err)
 	default:
 		return api.MakeErr(log, codes.Internal, "failed to update agent", err)
 	}
@@ -520,6 +651,10 @@ func (s *Service) signSvid(ctx context.Context, agentID spiffeid.ID, csr []byte,
 		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to parse CSR", err)
 	}
 
+	if err := api.VerifyX509SVIDCSR(parsedCsr, nil); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "CSR is not valid for an X509-SVID", err)
+	}
+
 	// Sign a new X509 SVID
 	x509Svid, err := s.ca.SignX509SVID(ctx, ca.X509SVIDParams{
 		SpiffeID:  agentID,
@@ -530,7 +665,7 @@ func (s *Service) signSvid(ctx context.Context, agentID spiffeid.ID, csr []byte,
 		TTL: s.agentTTL,
 	})
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "failed to sign X509 SVID", err)
+		return nil, makeSignErr(log, "failed to sign X509 SVID", err)
 	}
 
 	return x509Svid, nil
@@ -548,22 +683,18 @@ func (s *Service) getSelectorsFromAgentID(ctx context.Context, agentID string) (
 func (s *Service) attestJoinToken(ctx context.Context, token string) (*nodeattestor.AttestResult, error) {
 	log := rpccontext.Logger(ctx).WithField(telemetry.NodeAttestorType, "join_token")
 
-	joinToken, err := s.ds.FetchJoinToken(ctx, token)
+	// The token is consumed atomically, so it cannot be used by agents
+	// attesting concurrently, even through different servers.
+	joinToken, err := s.ds.ConsumeJoinToken(ctx, token, s.clk.Now())
 	switch {
+	case status.Code(err) == codes.FailedPrecondition:
+		return nil, api.MakeErr(log, codes.InvalidArgument, "join token expired", nil)
 	case err != nil:
-		return nil, api.MakeErr(log, codes.Internal, "failed to fetch join token", err)
+		return nil, api.MakeErr(log, codes.Internal, "failed to consume join token", err)
 	case joinToken == nil:
 		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to attest: join token does not exist or has already been used", nil)
 	}
 
-	err = s.ds.DeleteJoinToken(ctx, token)
-	switch {
-	case err != nil:
-		return nil, api.MakeErr(log, codes.Internal, "failed to delete join token", err)
-	case joinToken.Expiry.Before(s.clk.Now()):
-		return nil, api.MakeErr(log, codes.InvalidArgument, "join token expired", nil)
-	}
-
 	agentID, err := joinTokenID(s.td, token)
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to create join token ID", err)
@@ -574,6 +705,43 @@ func (s *Service) attestJoinToken(ctx context.Context, token string) (*nodeattes
 	}, nil
 }
 
+// attestX509PoPTLS attests an agent using the externally issued client
+// certificate presented during the TLS handshake. The handshake already
+// proved possession of the private key, so no challenge is issued. The
+// payload holds the DER encoded leaf certificate and must match the one
+// presented in the handshake.
+func (s *Service) attestX509PoPTLS(ctx context.Context, payload []byte) (*nodeattestor.AttestResult, error) {
+	log := rpccontext.Logger(ctx).WithField(telemetry.NodeAttestorType, x509PoPTLSType)
+
+	chains, ok := rpccontext.CallerAttestationChains(ctx)
+	if !ok || len(chains) == 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to attest: no client certificate issued by an attestation CA was presented", nil)
+	}
+
+	leaf := chains[0][0]
+	if !bytes.Equal(leaf.Raw, payload) {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to attest: attestation data does not match the client certificate", nil)
+	}
+
+	agentID, err := x509PoPTLSID(s.td, leaf)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to create agent ID", err)
+	}
+
+	var selectors []*common.Selector
+	for _, value := range x509pop.BuildSelectorValues(leaf, chains) {
+		selectors = append(selectors, &common.Selector{
+			Type:  x509PoPTLSType,
+			Value: value,
+		})
+	}
+
+	return &nodeattestor.AttestResult{
+		AgentID:   agentID.String(),
+		Selectors: selectors,
+	}, nil
+}
+
 func (s *Service) attestChallengeResponse(ctx context.Context, agentStream agentv1.Agent_AttestAgentServer, params *agentv1.AttestAgentRequest_Params) (*nodeattestor.AttestResult, error) {
 	attestorType := params.Data.Type
 	log := rpccontext.Logger(ctx).WithField(telemetry.NodeAttestorType, attestorType)
@@ -693,6 +861,23 @@ func fieldsFromFilterRequest(filter *agentv1.ListAgentsRequest_Filter) logrus.Fi
 	return fields
 }
 
+func x509PoPTLSID(td spiffeid.TrustDomain, cert *x509.Certificate) (spiffeid.ID, error) {
+	return spiffeid.FromSegments(td, "spire", "agent", x509PoPTLSType, x509pop.Fingerprint(cert))
+}
+
 func joinTokenID(td spiffeid.TrustDomain, token string) (spiffeid.ID, error) {
 	return spiffeid.FromSegments(td, "spire", "agent", "join_token", token)
 }
+
+// makeSignErr logs and returns the error for a failure to sign. The CA not
+// being able to sign yet is reported as Unavailable, so agents can retry.
+// SPIFFE IDs denied by the CA are reported as PermissionDenied.
+func makeSignErr(log logrus.FieldLogger, msg string, err error) error {
+	switch {
+	case errors.Is(err, ca.ErrNotAvailable):
+		return api.MakeErrWithReason(log, codes.Unavailable, api.ReasonCANotAvailable, msg, err)
+	case errors.Is(err, ca.ErrDenied):
+		return api.MakeErr(log, codes.PermissionDenied, "SVID issuance is denied", err)
+	}
+	return api.MakeErr(log, codes.Internal, msg, err)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,13 +18,21 @@ import (
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/plugin/x509pop"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	agent "github.com/spiffe/spire/pkg/server/api/agent/v1"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
+	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -31,6 +41,7 @@ import (
 	"github.com/spiffe/spire/test/fakes/fakeservercatalog"
 	"github.com/spiffe/spire/test/fakes/fakeservernodeattestor"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
 	"github.com/spiffe/spire/test/testkey"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -871,6 +882,268 @@ func TestListAgents(t *testing.T) {
 	}
 }
 
+func TestRequestAgentRenewal(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		spiffeID      string
+		dsErrors      []error
+		expectCode    codes.Code
+		expectMsg     string
+		expectRenewal *datastore.AgentRenewal
+		expectLogs    []spiretest.LogEntry
+	}{
+		{
+			name:          "success",
+			spiffeID:      agent1,
+			expectRenewal: &datastore.AgentRenewal{SpiffeID: agent1, SerialNumber: "1234"},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Agent SVID renewal requested",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID:     agent1,
+						telemetry.SerialNumber: "1234",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:       "success",
+						telemetry.Type:         "audit",
+						telemetry.SPIFFEID:     agent1,
+						telemetry.SerialNumber: "1234",
+					},
+				},
+			},
+		},
+		{
+			name:       "malformed ID",
+			spiffeID:   "agent-1",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid agent ID: scheme is missing or invalid",
+		},
+		{
+			name:       "not an agent ID",
+			spiffeID:   "spiffe://example.org/workload",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid agent ID: "spiffe://example.org/workload" is not an agent in trust domain "example.org"; path is not in the agent namespace`,
+		},
+		{
+			name:       "agent not found",
+			spiffeID:   agent2,
+			expectCode: codes.NotFound,
+			expectMsg:  "agent not found",
+		},
+		{
+			name:       "agent banned",
+			spiffeID:   "spiffe://example.org/spire/agent/banned",
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  "agent is banned",
+		},
+		{
+			name:       "fails to fetch agent",
+			spiffeID:   agent1,
+			dsErrors:   []error{errors.New("some error")},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch agent: some error",
+		},
+		{
+			name:       "fails to set renewal",
+			spiffeID:   agent1,
+			dsErrors:   []error{nil, errors.New("some error")},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to request agent renewal: some error",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+				SpiffeId:         agent1,
+				CertSerialNumber: "1234",
+			})
+			require.NoError(t, err)
+			_, err = test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+				SpiffeId: "spiffe://example.org/spire/agent/banned",
+			})
+			require.NoError(t, err)
+
+			for _, dsErr := range tt.dsErrors {
+				test.ds.AppendNextError(dsErr)
+			}
+
+			resp, err := test.renewalClient.RequestAgentRenewal(ctx, &agentrenewal.RequestAgentRenewalRequest{
+				SpiffeId: tt.spiffeID,
+			})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectRenewal.SerialNumber, resp.X509SvidSerialNumber)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+
+			renewal, err := test.ds.FetchAgentRenewal(ctx, tt.spiffeID)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectRenewal, renewal)
+		})
+	}
+}
+
+func TestMintAgentX509SVID(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name            string
+		spiffeID        string
+		csr             []byte
+		selectors       []string
+		dsErrors        []error
+		expectCode      codes.Code
+		expectMsg       string
+		expectSelectors []*common.Selector
+		expectNoAgent   bool
+	}{
+		{
+			name:            "success",
+			spiffeID:        agent2,
+			csr:             testCsr,
+			selectors:       []string{"bootstrap:rack:42", "bootstrap:site:offline"},
+			expectSelectors: []*common.Selector{{Type: "bootstrap", Value: "rack:42"}, {Type: "bootstrap", Value: "site:offline"}},
+		},
+		{
+			name:       "malformed ID",
+			spiffeID:   "agent-2",
+			csr:        testCsr,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid agent ID: scheme is missing or invalid",
+		},
+		{
+			name:       "not an agent ID",
+			spiffeID:   "spiffe://example.org/workload",
+			csr:        testCsr,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid agent ID: "spiffe://example.org/workload" is not an agent in trust domain "example.org"; path is not in the agent namespace`,
+		},
+		{
+			name:       "malformed selector",
+			spiffeID:   agent2,
+			csr:        testCsr,
+			selectors:  []string{"bootstrap"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid selectors: "bootstrap" is not of the form <type>:<value>`,
+		},
+		{
+			name:       "agent already exists",
+			spiffeID:   agent1,
+			csr:        testCsr,
+			expectCode: codes.AlreadyExists,
+			expectMsg:  "agent already exists",
+		},
+		{
+			name:       "agent banned",
+			spiffeID:   "spiffe://example.org/spire/agent/banned",
+			csr:        testCsr,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "agent is banned",
+		},
+		{
+			name:       "malformed CSR",
+			spiffeID:   agent2,
+			csr:        []byte("not a csr"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to parse CSR:",
+		},
+		{
+			name:       "fails to fetch agent",
+			spiffeID:   agent2,
+			csr:        testCsr,
+			dsErrors:   []error{errors.New("some error")},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch agent: some error",
+		},
+		{
+			name:          "fails to create attested agent",
+			spiffeID:      agent2,
+			csr:           testCsr,
+			dsErrors:      []error{nil, errors.New("some error")},
+			expectCode:    codes.Internal,
+			expectMsg:     "failed to create attested agent: some error",
+			expectNoAgent: true,
+		},
+		{
+			name:          "fails to update selectors",
+			spiffeID:      agent2,
+			csr:           testCsr,
+			selectors:     []string{"bootstrap:rack:42"},
+			dsErrors:      []error{nil, nil, errors.New("some error")},
+			expectCode:    codes.Internal,
+			expectMsg:     "failed to update selectors: some error",
+			expectNoAgent: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+				SpiffeId:         agent1,
+				CertSerialNumber: "1234",
+			})
+			require.NoError(t, err)
+			_, err = test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+				SpiffeId: "spiffe://example.org/spire/agent/banned",
+			})
+			require.NoError(t, err)
+
+			for _, dsErr := range tt.dsErrors {
+				test.ds.AppendNextError(dsErr)
+			}
+
+			resp, err := test.bootstrapClient.MintAgentX509SVID(ctx, &agentbootstrap.MintAgentX509SVIDRequest{
+				SpiffeId:  tt.spiffeID,
+				Csr:       tt.csr,
+				Selectors: tt.selectors,
+			})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				if tt.expectNoAgent {
+					attestedNode, err := test.ds.FetchAttestedNode(ctx, tt.spiffeID)
+					require.NoError(t, err)
+					require.Nil(t, attestedNode)
+					selectors, err := test.ds.GetNodeSelectors(ctx, tt.spiffeID, datastore.RequireCurrent)
+					require.NoError(t, err)
+					require.Empty(t, selectors)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, resp.CertChain)
+
+			svid, err := x509.ParseCertificate(resp.CertChain[0])
+			require.NoError(t, err)
+			require.Equal(t, tt.spiffeID, svid.URIs[0].String())
+
+			attestedNode, err := test.ds.FetchAttestedNode(ctx, tt.spiffeID)
+			require.NoError(t, err)
+			require.Equal(t, "bootstrap_svid", attestedNode.AttestationDataType)
+			require.Equal(t, svid.SerialNumber.String(), attestedNode.CertSerialNumber)
+			require.Equal(t, svid.NotAfter.Unix(), attestedNode.CertNotAfter)
+
+			selectors, err := test.ds.GetNodeSelectors(ctx, tt.spiffeID, datastore.RequireCurrent)
+			require.NoError(t, err)
+			spiretest.AssertProtoListEqual(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
 func TestBanAgent(t *testing.T) {
 	agentPath := "/spire/agent/agent-1"
 
@@ -1607,6 +1880,13 @@ func TestRenewAgent(t *testing.T) {
 		CertNotAfter:        12345,
 		CertSerialNumber:    "6789",
 	}
+	renewalTime := time.Unix(1700000000, 0)
+	reattestNode := func(attestedAt time.Time) *common.AttestedNode {
+		node := cloneAttestedNode(defaultNode)
+		node.CanReattest = true
+		node.AttestedAt = attestedAt.Unix()
+		return node
+	}
 
 	// Create a test CSR with empty template
 	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testKey)
@@ -1659,6 +1939,84 @@ func TestRenewAgent(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "success within max renewal age",
+			createNode: reattestNode(renewalTime.Add(-agentMaxRenewalAge)),
+			expectLogs: []spiretest.LogEntry{
+				renewingMessage,
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+						telemetry.Csr:    csrHash,
+					},
+				},
+			},
+			req: &agentv1.RenewAgentRequest{
+				Params: &agentv1.AgentX509SVIDParams{
+					Csr: csr,
+				},
+			},
+		},
+		{
+			name: "success past max renewal age when agent cannot re-attest",
+			createNode: func() *common.AttestedNode {
+				node := reattestNode(renewalTime.Add(-2 * agentMaxRenewalAge))
+				node.CanReattest = false
+				return node
+			}(),
+			expectLogs: []spiretest.LogEntry{
+				renewingMessage,
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+						telemetry.Csr:    csrHash,
+					},
+				},
+			},
+			req: &agentv1.RenewAgentRequest{
+				Params: &agentv1.AgentX509SVIDParams{
+					Csr: csr,
+				},
+			},
+		},
+		{
+			name:       "max renewal age exceeded",
+			createNode: reattestNode(renewalTime.Add(-agentMaxRenewalAge - time.Second)),
+			expectLogs: []spiretest.LogEntry{
+				renewingMessage,
+				{
+					Level:   logrus.WarnLevel,
+					Message: "Agent must re-attest; maximum renewal age exceeded",
+					Data: logrus.Fields{
+						telemetry.AttestedAt: renewalTime.Add(-agentMaxRenewalAge - time.Second).UTC().Format(time.RFC3339),
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.Csr:           csrHash,
+						telemetry.StatusCode:    "PermissionDenied",
+						telemetry.StatusMessage: "agent must re-attest: maximum renewal age exceeded",
+					},
+				},
+			},
+			req: &agentv1.RenewAgentRequest{
+				Params: &agentv1.AgentX509SVIDParams{
+					Csr: csr,
+				},
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "agent must re-attest: maximum renewal age exceeded",
+		},
 		{
 			name:       "rate limit fails",
 			createNode: cloneAttestedNode(defaultNode),
@@ -1843,7 +2201,7 @@ func TestRenewAgent(t *testing.T) {
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
 						telemetry.Csr:           csrHash,
-						telemetry.StatusCode:    "Internal",
+						telemetry.StatusCode:    "Unavailable",
 						telemetry.StatusMessage: "failed to sign X509 SVID: X509 CA is not available for signing",
 					},
 				},
@@ -1854,13 +2212,49 @@ func TestRenewAgent(t *testing.T) {
 					Csr: csr,
 				},
 			},
-			expectCode: codes.Internal,
+			expectCode: codes.Unavailable,
 			expectMsg:  "failed to sign X509 SVID: X509 CA is not available for signing",
 		},
+		{
+			name:       "failed to fetch attested node",
+			createNode: cloneAttestedNode(defaultNode),
+			dsError: []error{
+				errors.New("some error"),
+			},
+			expectLogs: []spiretest.LogEntry{
+				renewingMessage,
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch agent",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "some error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.Csr:           csrHash,
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to fetch agent: some error",
+					},
+				},
+			},
+			req: &agentv1.RenewAgentRequest{
+				Params: &agentv1.AgentX509SVIDParams{
+					Csr: csr,
+				},
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch agent: some error",
+		},
 		{
 			name:       "failed to update attested node",
 			createNode: cloneAttestedNode(defaultNode),
 			dsError: []error{
+				nil,
 				errors.New("some error"),
 			},
 			expectLogs: []spiretest.LogEntry{
@@ -1907,6 +2301,7 @@ func TestRenewAgent(t *testing.T) {
 				test.ca.SetX509CA(nil)
 			}
 
+			test.clk.Set(renewalTime)
 			test.rateLimiter.count = 1
 			test.rateLimiter.err = tt.rateLimiterErr
 			test.withCallerID = !tt.failCallerID
@@ -1928,6 +2323,9 @@ func TestRenewAgent(t *testing.T) {
 			if tt.expectCode != codes.OK {
 				require.Nil(t, resp)
 				spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+				if tt.expectCode == codes.PermissionDenied {
+					require.True(t, nodeutil.ShouldAgentReattest(fmt.Errorf("wrapped: %w", err)), "expected the agent to be asked to re-attest")
+				}
 				return
 			}
 
@@ -1961,6 +2359,31 @@ func TestRenewAgent(t *testing.T) {
 	}
 }
 
+func TestRenewAgentDeniedByDenylist(t *testing.T) {
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+
+	_, err := test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            agentID.String(),
+		AttestationDataType: "t",
+		CertNotAfter:        12345,
+		CertSerialNumber:    "6789",
+	})
+	require.NoError(t, err)
+	require.NoError(t, test.denylist.SetPatterns([]string{agentID.String()}))
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	test.rateLimiter.count = 1
+	test.withCallerID = true
+	_, err = test.client.RenewAgent(ctx, &agentv1.RenewAgentRequest{
+		Params: &agentv1.AgentX509SVIDParams{Csr: csr},
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied,
+		fmt.Sprintf("SVID issuance is denied: SPIFFE ID matches denylist pattern %q", agentID.String()))
+}
+
 func TestCreateJoinToken(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
@@ -2700,49 +3123,17 @@ func TestAttestAgent(t *testing.T) {
 		},
 
 		{
-			name:       "ds: fails to fetch join token",
-			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
-			expectCode: codes.Internal,
-			expectMsg:  "failed to fetch join token",
-			dsError: []error{
-				errors.New("some error"),
-			},
-			expectLogs: []spiretest.LogEntry{
-				{
-					Level:   logrus.ErrorLevel,
-					Message: "Failed to fetch join token",
-					Data: logrus.Fields{
-						telemetry.NodeAttestorType: "join_token",
-						logrus.ErrorKey:            "some error",
-					},
-				},
-				{
-					Level:   logrus.InfoLevel,
-					Message: "API accessed",
-					Data: logrus.Fields{
-						telemetry.Status:           "error",
-						telemetry.Type:             "audit",
-						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to fetch join token: some error",
-						telemetry.NodeAttestorType: "join_token",
-					},
-				},
-			},
-		},
-
-		{
-			name:       "ds: fails to delete join token",
+			name:       "ds: fails to consume join token",
 			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
 			expectCode: codes.Internal,
-			expectMsg:  "failed to delete join token",
+			expectMsg:  "failed to consume join token",
 			dsError: []error{
-				nil,
 				errors.New("some error"),
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Failed to delete join token",
+					Message: "Failed to consume join token",
 					Data: logrus.Fields{
 						telemetry.NodeAttestorType: "join_token",
 						logrus.ErrorKey:            "some error",
@@ -2755,7 +3146,7 @@ func TestAttestAgent(t *testing.T) {
 						telemetry.Status:           "error",
 						telemetry.Type:             "audit",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to delete join token: some error",
+						telemetry.StatusMessage:    "failed to consume join token: some error",
 						telemetry.NodeAttestorType: "join_token",
 					},
 				},
@@ -2768,7 +3159,6 @@ func TestAttestAgent(t *testing.T) {
 			expectCode: codes.Internal,
 			expectMsg:  "failed to fetch agent",
 			dsError: []error{
-				nil,
 				nil,
 				errors.New("some error"),
 			},
@@ -2803,7 +3193,6 @@ func TestAttestAgent(t *testing.T) {
 			expectCode: codes.Internal,
 			expectMsg:  "failed to update selectors",
 			dsError: []error{
-				nil,
 				nil,
 				nil,
 				errors.New("some error"),
@@ -2843,7 +3232,6 @@ func TestAttestAgent(t *testing.T) {
 				nil,
 				nil,
 				nil,
-				nil,
 				errors.New("some error"),
 			},
 			expectLogs: []spiretest.LogEntry{
@@ -3031,17 +3419,200 @@ func TestAttestAgent(t *testing.T) {
 	}
 }
 
+func TestAttestAgentX509PoPTLS(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	leaf, _ := testca.CreateX509Certificate(t, caCert, caKey, testca.WithSubject(pkix.Name{CommonName: "node-1"}))
+	otherLeaf, _ := testca.CreateX509Certificate(t, caCert, caKey)
+
+	expectedID := spiffeid.RequireFromPath(td, "/spire/agent/x509pop_tls/"+x509pop.Fingerprint(leaf))
+	expectedSelectors := []*common.Selector{
+		{Type: "x509pop_tls", Value: "ca:fingerprint:" + x509pop.Fingerprint(caCert)},
+		{Type: "x509pop_tls", Value: "subject:cn:node-1"},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		chains     [][]*x509.Certificate
+		payload    []byte
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:    "success",
+			chains:  [][]*x509.Certificate{{leaf, caCert}},
+			payload: leaf.Raw,
+		},
+		{
+			name:       "no attestation client certificate",
+			payload:    leaf.Raw,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to attest: no client certificate issued by an attestation CA was presented",
+		},
+		{
+			name:       "payload does not match client certificate",
+			chains:     [][]*x509.Certificate{{leaf, caCert}},
+			payload:    otherLeaf.Raw,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to attest: attestation data does not match the client certificate",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+			test.attestationChains = tt.chains
+			test.rateLimiter.count = 1
+
+			stream, err := test.client.AttestAgent(ctx)
+			require.NoError(t, err)
+			result, err := attest(t, stream, getAttestAgentRequest("x509pop_tls", tt.payload, testCsr))
+			require.NoError(t, stream.CloseSend())
+
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			test.assertAttestAgentResult(t, expectedID, result)
+			test.assertAgentWasStored(t, expectedID.String(), expectedSelectors)
+		})
+	}
+}
+
+func TestAttestAgentNotifiesAttestation(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	leaf, _ := testca.CreateX509Certificate(t, caCert, caKey, testca.WithSubject(pkix.Name{CommonName: "node-1"}))
+	otherLeaf, _ := testca.CreateX509Certificate(t, caCert, caKey)
+
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+	test.attestationChains = [][]*x509.Certificate{{leaf, caCert}}
+	test.rateLimiter.count = 1
+
+	attestX509PoPTLS := func(payload []byte) {
+		stream, err := test.client.AttestAgent(ctx)
+		require.NoError(t, err)
+		_, _ = attest(t, stream, getAttestAgentRequest("x509pop_tls", payload, testCsr))
+		require.NoError(t, stream.CloseSend())
+	}
+
+	attestX509PoPTLS(otherLeaf.Raw)
+	attestX509PoPTLS(leaf.Raw)
+
+	events := test.notifier.Events()
+	require.Len(t, events, 2)
+	for i := range events {
+		require.NotEmpty(t, events[i].Address)
+		events[i].Address = ""
+	}
+	require.Equal(t, []attestationwebhook.Event{
+		{
+			Time:            test.clk.Now().UTC(),
+			Result:          attestationwebhook.ResultFailure,
+			AttestationType: "x509pop_tls",
+			Error:           "failed to attest: attestation data does not match the client certificate",
+		},
+		{
+			Time:            test.clk.Now().UTC(),
+			Result:          attestationwebhook.ResultSuccess,
+			AttestationType: "x509pop_tls",
+			AgentID:         spiffeid.RequireFromPath(td, "/spire/agent/x509pop_tls/"+x509pop.Fingerprint(leaf)).String(),
+			Selectors: []attestationwebhook.Selector{
+				{Type: "x509pop_tls", Value: "subject:cn:node-1"},
+				{Type: "x509pop_tls", Value: "ca:fingerprint:" + x509pop.Fingerprint(caCert)},
+			},
+		},
+	}, events)
+}
+
+func TestAttestAgentExpandsEntryTemplates(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testkey.MustEC256())
+	require.NoError(t, err)
+
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	leaf, _ := testca.CreateX509Certificate(t, caCert, caKey, testca.WithSubject(pkix.Name{CommonName: "node-1"}))
+	agentID := spiffeid.RequireFromPath(td, "/spire/agent/x509pop_tls/"+x509pop.Fingerprint(leaf))
+
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+	test.attestationChains = [][]*x509.Certificate{{leaf, caCert}}
+	test.rateLimiter.count = 1
+
+	createTemplate := func(nodeSelector, spiffeID string) *datastore.EntryTemplate {
+		template, err := test.ds.CreateEntryTemplate(ctx, &datastore.EntryTemplate{
+			NodeSelectors: []*common.Selector{{Type: "x509pop_tls", Value: nodeSelector}},
+			Entry: &common.RegistrationEntry{
+				SpiffeId:  spiffeID,
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+		})
+		require.NoError(t, err)
+		return template
+	}
+	matching := createTemplate("subject:cn:node-1", "spiffe://example.org/web{{ .AgentPath }}")
+	createTemplate("subject:cn:node-2", "spiffe://example.org/other{{ .AgentPath }}")
+	invalid := createTemplate("subject:cn:node-1", `spiffe://example.org/{{ selector "x509pop_tls:unknown" }}`)
+
+	// Entries are only created once, no matter how many times the agent
+	// attests
+	for i := 0; i < 2; i++ {
+		stream, err := test.client.AttestAgent(ctx)
+		require.NoError(t, err)
+		result, err := attest(t, stream, getAttestAgentRequest("x509pop_tls", leaf.Raw, testCsr))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.NoError(t, stream.CloseSend())
+	}
+
+	resp, err := test.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	entry := resp.Entries[0]
+	require.Equal(t, "spiffe://example.org/web"+agentID.Path(), entry.SpiffeId)
+	require.Equal(t, agentID.String(), entry.ParentId)
+	spiretest.AssertProtoListEqual(t, []*common.Selector{{Type: "unix", Value: "uid:1000"}}, entry.Selectors)
+
+	var created, failed int
+	for _, e := range test.logHook.AllEntries() {
+		switch e.Message {
+		case "Created entry from entry template":
+			created++
+			require.Equal(t, matching.ID, e.Data[telemetry.EntryTemplateID])
+		case "Failed to render entry template":
+			failed++
+			require.Equal(t, invalid.ID, e.Data[telemetry.EntryTemplateID])
+		}
+	}
+	require.Equal(t, 1, created)
+	require.Equal(t, 2, failed)
+}
+
 type serviceTest struct {
-	client       agentv1.AgentClient
-	done         func()
-	ds           *fakedatastore.DataStore
-	ca           *fakeserverca.CA
-	cat          *fakeservercatalog.Catalog
-	clk          clock.Clock
-	logHook      *test.Hook
-	rateLimiter  *fakeRateLimiter
-	withCallerID bool
-	pluginCloser func()
+	client          agentv1.AgentClient
+	renewalClient   agentrenewal.AgentRenewalClient
+	bootstrapClient agentbootstrap.AgentBootstrapClient
+	joinTokenClient jointoken.JoinTokensClient
+	countClient     count.AgentCountClient
+	done            func()
+	ds              *fakedatastore.DataStore
+	ca              *fakeserverca.CA
+	cat             *fakeservercatalog.Catalog
+	clk             *clock.Mock
+	logHook         *test.Hook
+	rateLimiter     *fakeRateLimiter
+	withCallerID    bool
+	pluginCloser    func()
+	notifier        *fakeAttestationNotifier
+	denylist        *ca.Denylist
+
+	attestationChains [][]*x509.Certificate
 }
 
 func (s *serviceTest) Cleanup() {
@@ -3051,19 +3622,27 @@ func (s *serviceTest) Cleanup() {
 	}
 }
 
+// agentMaxRenewalAge is the maximum renewal age the service is configured
+// with in tests.
+const agentMaxRenewalAge = time.Hour
+
 func setupServiceTest(t *testing.T, agentTTL time.Duration) *serviceTest {
-	ca := fakeserverca.New(t, td, &fakeserverca.Options{})
+	denylist := new(ca.Denylist)
+	ca := fakeserverca.New(t, td, &fakeserverca.Options{Denylist: denylist})
 	ds := fakedatastore.New(t)
 	cat := fakeservercatalog.New()
 	clk := clock.NewMock(t)
+	notifier := &fakeAttestationNotifier{}
 
 	service := agent.New(agent.Config{
-		ServerCA:    ca,
-		DataStore:   ds,
-		TrustDomain: td,
-		Clock:       clk,
-		Catalog:     cat,
-		AgentTTL:    agentTTL,
+		ServerCA:            ca,
+		DataStore:           ds,
+		TrustDomain:         td,
+		Clock:               clk,
+		Catalog:             cat,
+		AgentTTL:            agentTTL,
+		AttestationNotifier: notifier,
+		AgentMaxRenewalAge:  agentMaxRenewalAge,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -3081,6 +3660,8 @@ func setupServiceTest(t *testing.T, agentTTL time.Duration) *serviceTest {
 		clk:         clk,
 		logHook:     logHook,
 		rateLimiter: rateLimiter,
+		notifier:    notifier,
+		denylist:    denylist,
 	}
 
 	ppMiddleware := middleware.Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
@@ -3089,6 +3670,9 @@ func setupServiceTest(t *testing.T, agentTTL time.Duration) *serviceTest {
 		if test.withCallerID {
 			ctx = rpccontext.WithCallerID(ctx, agentID)
 		}
+		if test.attestationChains != nil {
+			ctx = rpccontext.WithCallerAttestationChains(ctx, test.attestationChains)
+		}
 		return ctx, nil
 	})
 	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.Chain(
@@ -3104,6 +3688,10 @@ func setupServiceTest(t *testing.T, agentTTL time.Duration) *serviceTest {
 	conn, done := spiretest.NewAPIServerWithMiddleware(t, registerFn, server)
 	test.done = done
 	test.client = agentv1.NewAgentClient(conn)
+	test.renewalClient = agentrenewal.NewAgentRenewalClient(conn)
+	test.bootstrapClient = agentbootstrap.NewAgentBootstrapClient(conn)
+	test.joinTokenClient = jointoken.NewJoinTokensClient(conn)
+	test.countClient = count.NewAgentCountClient(conn)
 
 	return test
 }
@@ -3228,6 +3816,7 @@ func (s *serviceTest) assertAgentWasStored(t *testing.T, expectedID string, expe
 	require.NoError(t, err)
 	require.NotNil(t, attestedAgent)
 	require.Equal(t, expectedID, attestedAgent.SpiffeId)
+	require.Equal(t, s.clk.Now().Unix(), attestedAgent.AttestedAt)
 
 	agentSelectors, err := s.ds.GetNodeSelectors(ctx, expectedID, datastore.RequireCurrent)
 	require.NoError(t, err)
@@ -3292,3 +3881,20 @@ func attest(t *testing.T, stream agentv1.Agent_AttestAgentClient, request *agent
 		return result, err
 	}
 }
+
+type fakeAttestationNotifier struct {
+	mtx    sync.Mutex
+	events []attestationwebhook.Event
+}
+
+func (n *fakeAttestationNotifier) Notify(event attestationwebhook.Event) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.events = append(n.events, event)
+}
+
+func (n *fakeAttestationNotifier) Events() []attestationwebhook.Event {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return append([]attestationwebhook.Event(nil), n.events...)
+}
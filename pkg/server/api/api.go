@@ -18,6 +18,14 @@ type AuthorizedEntryFetcher interface {
 	FetchAuthorizedEntries(ctx context.Context, id spiffeid.ID) ([]*types.Entry, error)
 }
 
+// AuthorizedEntryNotifier is implemented by AuthorizedEntryFetchers that can
+// tell when the authorized entries may have changed
+type AuthorizedEntryNotifier interface {
+	// AuthorizedEntriesChanged returns a channel that is closed the next
+	// time the authorized entries may change
+	AuthorizedEntriesChanged() <-chan struct{}
+}
+
 // AuthorizedEntryFetcherFunc is an implementation of AuthorizedEntryFetcher
 // using a function.
 type AuthorizedEntryFetcherFunc func(ctx context.Context, id spiffeid.ID) ([]*types.Entry, error)
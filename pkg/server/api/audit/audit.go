@@ -1,6 +1,9 @@
 package audit
 
 import (
+	"time"
+
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -23,6 +26,12 @@ type Logger interface {
 type logger struct {
 	fields logrus.Fields
 	log    logrus.FieldLogger
+
+	// The following are only set for loggers created with NewForCall
+	base  logrus.FieldLogger
+	sink  Sink
+	clk   clock.Clock
+	start time.Time
 }
 
 func New(l logrus.FieldLogger) Logger {
@@ -36,6 +45,18 @@ func New(l logrus.FieldLogger) Logger {
 	}
 }
 
+// NewForCall returns a Logger for an API call that started at the given
+// time. Audit records include the latency of the call and, if sink is not
+// nil, are also written to the sink regardless of the log level.
+func NewForCall(l logrus.FieldLogger, sink Sink, clk clock.Clock, start time.Time) Logger {
+	auditLog := New(l).(*logger)
+	auditLog.base = l
+	auditLog.sink = sink
+	auditLog.clk = clk
+	auditLog.start = start
+	return auditLog
+}
+
 func (l *logger) AddFields(fields logrus.Fields) {
 	for key, value := range fields {
 		l.fields[key] = value
@@ -43,21 +64,42 @@ func (l *logger) AddFields(fields logrus.Fields) {
 }
 
 func (l *logger) Audit() {
-	l.log.WithFields(l.fields).Info(message)
+	l.emit(l.log.WithFields(l.fields))
 }
 
 func (l *logger) AuditWithFields(fields logrus.Fields) {
-	l.log.WithFields(l.fields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(l.fields).WithFields(fields))
 }
 
 func (l *logger) AuditWithError(err error) {
 	fields := fieldsFromError(err)
-	l.log.WithFields(l.fields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(l.fields).WithFields(fields))
 }
 
 func (l *logger) AuditWithTypesStatus(fields logrus.Fields, s *types.Status) {
 	statusFields := fieldsFromStatus(s)
-	l.log.WithFields(statusFields).WithFields(fields).Info(message)
+	l.emit(l.log.WithFields(statusFields).WithFields(fields))
+}
+
+func (l *logger) emit(entry *logrus.Entry) {
+	if l.clk == nil {
+		entry.Info(message)
+		return
+	}
+
+	now := l.clk.Now()
+	entry = entry.WithField(telemetry.Latency, now.Sub(l.start).String())
+	entry.Info(message)
+
+	if l.sink == nil {
+		return
+	}
+	record := entry.WithTime(now)
+	record.Level = logrus.InfoLevel
+	record.Message = message
+	if err := l.sink.Write(record); err != nil {
+		l.base.WithError(err).Error("Failed to write audit record to audit log sink")
+	}
 }
 
 func fieldsFromStatus(s *types.Status) logrus.Fields {
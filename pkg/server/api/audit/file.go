@@ -0,0 +1,327 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const (
+	// TimestampsFileSuffix is appended to the path of the audit log file to
+	// get the path of the file the timestamps of the audit records are
+	// written to.
+	TimestampsFileSuffix = ".timestamps"
+
+	defaultTimestampBatchSize     = 100
+	defaultTimestampBatchInterval = time.Minute
+
+	// pendingTimestampBatches is the number of batches that can be waiting
+	// for a timestamp before new batches are dropped
+	pendingTimestampBatches = 16
+)
+
+// TimestampConfig configures RFC 3161 timestamping of the audit records
+// written to the audit log file.
+type TimestampConfig struct {
+	// TSAURL is the URL of the RFC 3161 time-stamping authority
+	TSAURL string
+
+	// BatchSize is the number of audit records timestamped together.
+	// Defaults to 100.
+	BatchSize int
+
+	// BatchInterval is how long audit records wait at most to be
+	// timestamped. Defaults to one minute.
+	BatchInterval time.Duration
+
+	// Log is used to report batches that could not be timestamped
+	Log logrus.FieldLogger
+}
+
+// TimestampRecord is written to the timestamps file for each batch of audit
+// records. The batch is the Length bytes of the audit log file starting at
+// Offset, and SHA256 is their digest.
+type TimestampRecord struct {
+	Offset       int64     `json:"offset"`
+	Length       int64     `json:"length"`
+	Records      int       `json:"records"`
+	SHA256       string    `json:"sha256"`
+	GenTime      time.Time `json:"gen_time"`
+	SerialNumber string    `json:"serial_number"`
+	Token        []byte    `json:"token"`
+}
+
+// FileSink is a Sink that writes audit records, as JSON lines, to a
+// dedicated file.
+type FileSink struct {
+	mtx       sync.Mutex
+	path      string
+	file      *os.File
+	formatter logrus.Formatter
+
+	// The following are only set when timestamping is enabled
+	ts      *timestamper
+	offset  int64
+	batch   timestampBatch
+	batches chan timestampBatch
+	done    chan struct{}
+	closed  bool
+}
+
+type timestampBatch struct {
+	offset  int64
+	length  int64
+	records int
+	hash    hash.Hash
+}
+
+type timestamper struct {
+	client   *TimestampClient
+	fileMtx  sync.Mutex
+	file     *os.File
+	log      logrus.FieldLogger
+	size     int
+	interval time.Duration
+}
+
+// NewFileSink opens (or creates) the file at the given path and returns a
+// sink that appends audit records to it. If timestamping is configured,
+// batches of audit records are timestamped by the TSA and the timestamps are
+// appended to the file at the path with the TimestampsFileSuffix.
+func NewFileSink(path string, timestamping *TimestampConfig) (*FileSink, error) {
+	file, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &FileSink{
+		path:      path,
+		file:      file,
+		formatter: &logrus.JSONFormatter{},
+	}
+	if timestamping == nil {
+		return h, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	tsFile, err := openFile(path + TimestampsFileSuffix)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	h.ts = &timestamper{
+		client:   NewTimestampClient(timestamping.TSAURL),
+		file:     tsFile,
+		log:      timestamping.Log,
+		size:     timestamping.BatchSize,
+		interval: timestamping.BatchInterval,
+	}
+	if h.ts.size <= 0 {
+		h.ts.size = defaultTimestampBatchSize
+	}
+	if h.ts.interval <= 0 {
+		h.ts.interval = defaultTimestampBatchInterval
+	}
+	h.offset = info.Size()
+	h.batches = make(chan timestampBatch, pendingTimestampBatches)
+	h.done = make(chan struct{})
+	go h.runTimestamping()
+
+	return h, nil
+}
+
+func (h *FileSink) Write(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	n, err := h.file.Write(line)
+	if h.ts != nil && n > 0 {
+		h.addToBatchLocked(line[:n])
+	}
+	return err
+}
+
+// Reopen reopens the audit log file, and the timestamps file if timestamping
+// is enabled, so they can be rotated. The pending audit records are
+// timestamped first, since their offsets are relative to the old file.
+func (h *FileSink) Reopen() error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	file, err := openFile(h.path)
+	if err != nil {
+		return fmt.Errorf("unable to reopen %s: %w", h.path, err)
+	}
+
+	if h.ts == nil {
+		_ = h.file.Close()
+		h.file = file
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("unable to reopen %s: %w", h.path, err)
+	}
+	tsFile, err := openFile(h.path + TimestampsFileSuffix)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("unable to reopen %s: %w", h.path+TimestampsFileSuffix, err)
+	}
+
+	h.flushBatchLocked()
+	_ = h.file.Close()
+	h.file = file
+	h.offset = info.Size()
+	h.ts.reopen(tsFile)
+	return nil
+}
+
+// Close closes the audit log file. If timestamping is enabled, the pending
+// audit records are timestamped first.
+func (h *FileSink) Close() error {
+	h.mtx.Lock()
+	if h.ts != nil && !h.closed {
+		h.flushBatchLocked()
+		h.closed = true
+		close(h.batches)
+		h.mtx.Unlock()
+		<-h.done
+		h.mtx.Lock()
+	}
+	defer h.mtx.Unlock()
+
+	err := h.file.Close()
+	if h.ts != nil {
+		if tsErr := h.ts.closeFile(); err == nil {
+			err = tsErr
+		}
+	}
+	return err
+}
+
+func (h *FileSink) addToBatchLocked(line []byte) {
+	if h.batch.hash == nil {
+		h.batch = timestampBatch{
+			offset: h.offset,
+			hash:   sha256.New(),
+		}
+	}
+	_, _ = h.batch.hash.Write(line)
+	h.batch.length += int64(len(line))
+	h.batch.records++
+	h.offset += int64(len(line))
+
+	if h.batch.records >= h.ts.size {
+		h.flushBatchLocked()
+	}
+}
+
+func (h *FileSink) flushBatchLocked() {
+	if h.closed || h.batch.records == 0 {
+		return
+	}
+	select {
+	case h.batches <- h.batch:
+	default:
+		h.ts.log.WithFields(logrus.Fields{
+			telemetry.Offset: h.batch.offset,
+			telemetry.Count:  h.batch.records,
+		}).Error("Dropped audit record batch; too many batches are waiting for a timestamp")
+	}
+	h.batch = timestampBatch{}
+}
+
+func (h *FileSink) runTimestamping() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.ts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch, ok := <-h.batches:
+			if !ok {
+				return
+			}
+			h.ts.timestamp(batch)
+		case <-ticker.C:
+			h.mtx.Lock()
+			h.flushBatchLocked()
+			h.mtx.Unlock()
+		}
+	}
+}
+
+func (t *timestamper) timestamp(batch timestampBatch) {
+	digest := batch.hash.Sum(nil)
+	log := t.log.WithFields(logrus.Fields{
+		telemetry.Offset: batch.offset,
+		telemetry.Count:  batch.records,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	timestamp, err := t.client.Timestamp(ctx, digest)
+	if err != nil {
+		log.WithError(err).Error("Failed to timestamp audit record batch")
+		return
+	}
+
+	line, err := json.Marshal(TimestampRecord{
+		Offset:       batch.offset,
+		Length:       batch.length,
+		Records:      batch.records,
+		SHA256:       hex.EncodeToString(digest),
+		GenTime:      timestamp.GenTime,
+		SerialNumber: timestamp.SerialNumber.String(),
+		Token:        timestamp.Token,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal audit record batch timestamp")
+		return
+	}
+	t.fileMtx.Lock()
+	defer t.fileMtx.Unlock()
+	if _, err := t.file.Write(append(line, '\n')); err != nil {
+		log.WithError(err).Error("Failed to write audit record batch timestamp")
+	}
+}
+
+// reopen replaces the timestamps file. Timestamps still pending for batches
+// of the old audit log file are written to the new timestamps file.
+func (t *timestamper) reopen(file *os.File) {
+	t.fileMtx.Lock()
+	defer t.fileMtx.Unlock()
+	_ = t.file.Close()
+	t.file = file
+}
+
+func (t *timestamper) closeFile() error {
+	t.fileMtx.Lock()
+	defer t.fileMtx.Unlock()
+	return t.file.Close()
+}
+
+func openFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+}
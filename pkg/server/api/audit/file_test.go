@@ -0,0 +1,78 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	sink, err := audit.NewFileSink(path, nil)
+	require.NoError(t, err)
+
+	// Audit records are written to the sink regardless of the log level
+	log, logHook := test.NewNullLogger()
+	log.SetLevel(logrus.WarnLevel)
+
+	clk := clock.NewMock()
+	start := clk.Now()
+	auditLog := audit.NewForCall(log, sink, clk, start)
+	clk.Add(1500 * time.Millisecond)
+	auditLog.AuditWithFields(logrus.Fields{"a": "1"})
+	require.Empty(t, logHook.AllEntries())
+
+	// Audit records are written to the new file once reopened
+	rotatedPath := filepath.Join(dir, "audit.log.1")
+	require.NoError(t, os.Rename(path, rotatedPath))
+	require.NoError(t, sink.Reopen())
+	auditLog.AuditWithFields(logrus.Fields{"a": "2"})
+	require.NoError(t, sink.Close())
+
+	requireRecords := func(path string, a string) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1)
+
+		var record map[string]string
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		require.Equal(t, "API accessed", record["msg"])
+		require.Equal(t, "info", record["level"])
+		require.Equal(t, "audit", record["type"])
+		require.Equal(t, "success", record["status"])
+		require.Equal(t, "1.5s", record["latency"])
+		require.Equal(t, a, record["a"])
+	}
+	requireRecords(rotatedPath, "1")
+	requireRecords(path, "2")
+}
+
+func TestOpenSinks(t *testing.T) {
+	sink, err := audit.OpenSinks(audit.SinkConfig{})
+	require.NoError(t, err)
+	require.Nil(t, sink)
+
+	sink, err = audit.OpenSinks(audit.SinkConfig{
+		File: filepath.Join(t.TempDir(), "audit.log"),
+	})
+	require.NoError(t, err)
+	require.IsType(t, &audit.FileSink{}, sink)
+	require.NoError(t, sink.Close())
+
+	_, err = audit.OpenSinks(audit.SinkConfig{
+		File: filepath.Join(t.TempDir(), "non-existent", "audit.log"),
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Sink receives every audit record, regardless of the level of the logger
+// the audit record is emitted to.
+type Sink interface {
+	// Write writes an audit record
+	Write(entry *logrus.Entry) error
+
+	// Reopen reopens the sink, e.g. after the audit log file was rotated
+	Reopen() error
+
+	// Close closes the sink
+	Close() error
+}
+
+// SinkConfig configures the sinks opened by OpenSinks.
+type SinkConfig struct {
+	// File is the path of the file to write audit records to, if any
+	File string
+
+	// Timestamping configures timestamping of the audit records written to
+	// File, if any
+	Timestamping *TimestampConfig
+
+	// Syslog configures writing audit records to syslog, if any
+	Syslog *SyslogConfig
+}
+
+// OpenSinks opens the configured sinks. It returns nil if no sink is
+// configured.
+func OpenSinks(config SinkConfig) (Sink, error) {
+	var sinks multiSink
+	if config.File != "" {
+		sink, err := NewFileSink(config.File, config.Timestamping)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if config.Syslog != nil {
+		sink, err := NewSyslogSink(*config.Syslog)
+		if err != nil {
+			_ = sinks.Close()
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	switch len(sinks) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sinks[0], nil
+	default:
+		return sinks, nil
+	}
+}
+
+// multiSink writes audit records to several sinks. Every sink is written to,
+// reopened or closed even if another fails; the first error is returned.
+type multiSink []Sink
+
+func (s multiSink) Write(entry *logrus.Entry) error {
+	var firstErr error
+	for _, sink := range s {
+		if err := sink.Write(entry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s multiSink) Reopen() error {
+	var firstErr error
+	for _, sink := range s {
+		if err := sink.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s multiSink) Close() error {
+	var firstErr error
+	for _, sink := range s {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,23 @@
+package audit
+
+const defaultSyslogTag = "spire-server"
+
+// SyslogConfig configures writing audit records to syslog.
+type SyslogConfig struct {
+	// Network and Address are the address of the syslog daemon, e.g. "udp"
+	// and "localhost:514". The local syslog daemon is used if both are
+	// empty.
+	Network string
+	Address string
+
+	// Tag is the syslog tag of the audit records. Defaults to
+	// "spire-server".
+	Tag string
+}
+
+func (c SyslogConfig) tag() string {
+	if c.Tag == "" {
+		return defaultSyslogTag
+	}
+	return c.Tag
+}
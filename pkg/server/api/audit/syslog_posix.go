@@ -0,0 +1,54 @@
+//go:build !windows
+
+package audit
+
+import (
+	"log/syslog"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyslogSink is a Sink that writes audit records, as JSON, to syslog with
+// the LOG_AUTH facility and the LOG_INFO severity.
+type SyslogSink struct {
+	mtx       sync.Mutex
+	writer    *syslog.Writer
+	formatter logrus.Formatter
+}
+
+// NewSyslogSink connects to the syslog daemon and returns a sink that writes
+// audit records to it.
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	writer, err := syslog.Dial(config.Network, config.Address, syslog.LOG_AUTH|syslog.LOG_INFO, config.tag())
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{
+		writer:    writer,
+		formatter: &logrus.JSONFormatter{},
+	}, nil
+}
+
+func (s *SyslogSink) Write(entry *logrus.Entry) error {
+	line, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.writer.Info(string(line))
+}
+
+// Reopen is a no-op; the syslog writer reconnects on its own when writes
+// fail.
+func (s *SyslogSink) Reopen() error {
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.writer.Close()
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package audit
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyslogSink is not supported on Windows.
+type SyslogSink struct{}
+
+// NewSyslogSink fails since syslog is not available on Windows.
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	return nil, errors.New("syslog audit log sink is not supported on Windows")
+}
+
+func (s *SyslogSink) Write(entry *logrus.Entry) error {
+	return nil
+}
+
+func (s *SyslogSink) Reopen() error {
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	return nil
+}
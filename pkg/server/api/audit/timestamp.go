@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	timestampQueryContentType = "application/timestamp-query"
+	timestampReplyContentType = "application/timestamp-reply"
+
+	// maxTimestampReplySize bounds the size of the replies read from the TSA
+	maxTimestampReplySize = 1 << 20
+)
+
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// The structures below are defined in RFC 3161 and RFC 5652 (CMS).
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+}
+
+// Timestamp is an RFC 3161 timestamp token obtained from a TSA.
+type Timestamp struct {
+	// GenTime is the time at which the TSA created the token
+	GenTime time.Time
+
+	// SerialNumber is the serial number assigned to the token by the TSA
+	SerialNumber *big.Int
+
+	// Token is the DER encoded timestamp token, a CMS SignedData
+	// structure that can be verified with e.g. `openssl ts -verify`.
+	Token []byte
+}
+
+// TimestampClient obtains RFC 3161 timestamps from a time-stamping
+// authority over HTTP.
+type TimestampClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewTimestampClient returns a client for the TSA at the given URL.
+func NewTimestampClient(url string) *TimestampClient {
+	return &TimestampClient{
+		url:        url,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Timestamp requests a timestamp over the SHA-256 digest. The token
+// returned by the TSA is checked to be over the digest and to carry the
+// request nonce; its signature is not verified.
+func (c *TimestampClient) Timestamp(ctx context.Context, digest []byte) (*Timestamp, error) {
+	if len(digest) != crypto.SHA256.Size() {
+		return nil, fmt.Errorf("expected a SHA-256 digest; got %d bytes", len(digest))
+	}
+
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+
+	imprint := messageImprint{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+		HashedMessage: digest,
+	}
+	query, err := asn1.Marshal(timeStampReq{
+		Version:        1,
+		MessageImprint: imprint,
+		Nonce:          nonce,
+		CertReq:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal timestamp request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", timestampQueryContentType)
+	req.Header.Set("Accept", timestampReplyContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	reply, err := io.ReadAll(io.LimitReader(resp.Body, maxTimestampReplySize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read timestamp reply: %w", err)
+	}
+
+	return parseTimestampReply(reply, imprint, nonce)
+}
+
+func parseTimestampReply(reply []byte, imprint messageImprint, nonce *big.Int) (*Timestamp, error) {
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(reply, &resp); err != nil {
+		return nil, fmt.Errorf("malformed timestamp reply: %w", err)
+	}
+
+	// PKIStatus granted (0) and grantedWithMods (1) carry a token
+	if resp.Status.Status != 0 && resp.Status.Status != 1 {
+		msg := fmt.Sprintf("timestamp request rejected with status %d", resp.Status.Status)
+		if len(resp.Status.StatusString) > 0 {
+			msg += ": " + strings.Join(resp.Status.StatusString, "; ")
+		}
+		return nil, errors.New(msg)
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, errors.New("timestamp reply does not include a token")
+	}
+
+	var token contentInfo
+	if _, err := asn1.Unmarshal(resp.TimeStampToken.FullBytes, &token); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token: %w", err)
+	}
+	if !token.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unexpected timestamp token content type %s", token.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(token.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token signed data: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("unexpected timestamp token encapsulated content type %s", sd.EncapContentInfo.EContentType)
+	}
+
+	timestamp, err := parseTSTInfo(sd.EncapContentInfo.EContent, imprint, nonce)
+	if err != nil {
+		return nil, err
+	}
+	timestamp.Token = resp.TimeStampToken.FullBytes
+	return timestamp, nil
+}
+
+// parseTSTInfo parses the TSTInfo structure of a timestamp token:
+//
+//	TSTInfo ::= SEQUENCE {
+//	    version        INTEGER,
+//	    policy         TSAPolicyId,
+//	    messageImprint MessageImprint,
+//	    serialNumber   INTEGER,
+//	    genTime        GeneralizedTime,
+//	    accuracy       Accuracy OPTIONAL,
+//	    ordering       BOOLEAN DEFAULT FALSE,
+//	    nonce          INTEGER OPTIONAL,
+//	    ... }
+//
+// The optional fields are matched by tag since they may be omitted.
+func parseTSTInfo(der []byte, imprint messageImprint, nonce *big.Int) (*Timestamp, error) {
+	var fields []asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &fields); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token info: %w", err)
+	}
+	if len(fields) < 5 {
+		return nil, errors.New("malformed timestamp token info: missing fields")
+	}
+
+	var tokenImprint messageImprint
+	if _, err := asn1.Unmarshal(fields[2].FullBytes, &tokenImprint); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token message imprint: %w", err)
+	}
+	if !tokenImprint.HashAlgorithm.Algorithm.Equal(imprint.HashAlgorithm.Algorithm) || !bytes.Equal(tokenImprint.HashedMessage, imprint.HashedMessage) {
+		return nil, errors.New("timestamp token message imprint does not match the request")
+	}
+
+	serialNumber := new(big.Int)
+	if _, err := asn1.Unmarshal(fields[3].FullBytes, &serialNumber); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token serial number: %w", err)
+	}
+
+	var genTime time.Time
+	if _, err := asn1.UnmarshalWithParams(fields[4].FullBytes, &genTime, "generalized"); err != nil {
+		return nil, fmt.Errorf("malformed timestamp token generation time: %w", err)
+	}
+
+	var tokenNonce *big.Int
+	for _, field := range fields[5:] {
+		if field.Class == asn1.ClassUniversal && field.Tag == asn1.TagInteger {
+			tokenNonce = new(big.Int)
+			if _, err := asn1.Unmarshal(field.FullBytes, &tokenNonce); err != nil {
+				return nil, fmt.Errorf("malformed timestamp token nonce: %w", err)
+			}
+			break
+		}
+	}
+	if tokenNonce == nil || tokenNonce.Cmp(nonce) != 0 {
+		return nil, errors.New("timestamp token nonce does not match the request")
+	}
+
+	return &Timestamp{
+		GenTime:      genTime,
+		SerialNumber: serialNumber,
+	}, nil
+}
@@ -0,0 +1,305 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var genTime = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+func TestTimestampClient(t *testing.T) {
+	digest := sha256.Sum256([]byte("audit records"))
+
+	for _, tt := range []struct {
+		name      string
+		reply     func(t *testing.T, req timeStampReq) []byte
+		status    int
+		expectErr string
+	}{
+		{
+			name:  "granted",
+			reply: grantedReply,
+		},
+		{
+			name: "rejected",
+			reply: func(t *testing.T, req timeStampReq) []byte {
+				return mustMarshal(t, timeStampResp{
+					Status: pkiStatusInfo{Status: 2, StatusString: []string{"bad algorithm"}},
+				})
+			},
+			expectErr: "timestamp request rejected with status 2: bad algorithm",
+		},
+		{
+			name: "granted without token",
+			reply: func(t *testing.T, req timeStampReq) []byte {
+				return mustMarshal(t, timeStampResp{})
+			},
+			expectErr: "timestamp reply does not include a token",
+		},
+		{
+			name: "message imprint mismatch",
+			reply: func(t *testing.T, req timeStampReq) []byte {
+				req.MessageImprint.HashedMessage = make([]byte, 32)
+				return grantedReply(t, req)
+			},
+			expectErr: "timestamp token message imprint does not match the request",
+		},
+		{
+			name: "nonce mismatch",
+			reply: func(t *testing.T, req timeStampReq) []byte {
+				req.Nonce = new(big.Int).Add(req.Nonce, big.NewInt(1))
+				return grantedReply(t, req)
+			},
+			expectErr: "timestamp token nonce does not match the request",
+		},
+		{
+			name:      "malformed reply",
+			reply:     func(t *testing.T, req timeStampReq) []byte { return []byte("malformed") },
+			expectErr: "malformed timestamp reply: ",
+		},
+		{
+			name:      "unexpected status code",
+			status:    http.StatusServiceUnavailable,
+			expectErr: "unexpected status code 503",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeTSA(t, tt.status, tt.reply)
+
+			timestamp, err := NewTimestampClient(server.URL).Timestamp(context.Background(), digest[:])
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, genTime, timestamp.GenTime)
+			require.Equal(t, big.NewInt(42), timestamp.SerialNumber)
+			require.NotEmpty(t, timestamp.Token)
+		})
+	}
+}
+
+func TestFileSinkTimestamping(t *testing.T) {
+	server := newFakeTSA(t, 0, grantedReply)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+
+	log, _ := test.NewNullLogger()
+	sink, err := NewFileSink(path, &TimestampConfig{
+		TSAURL:    server.URL,
+		BatchSize: 2,
+		Log:       log,
+	})
+	require.NoError(t, err)
+
+	// Two batches are timestamped: a full batch and the remainder on close
+	auditor := NewForCall(log, sink, clock.NewMock(), time.Time{})
+	auditor.AuditWithFields(logrus.Fields{"a": "1"})
+	auditor.AuditWithFields(logrus.Fields{"a": "2"})
+	auditor.AuditWithFields(logrus.Fields{"a": "3"})
+	require.NoError(t, sink.Close())
+
+	requireTimestamps(t, path, int64(len("existing\n")), []int{2, 1})
+}
+
+func TestFileSinkTimestampingReopen(t *testing.T) {
+	server := newFakeTSA(t, 0, grantedReply)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	rotatedPath := filepath.Join(dir, "audit.log.1")
+
+	log, _ := test.NewNullLogger()
+	sink, err := NewFileSink(path, &TimestampConfig{
+		TSAURL:    server.URL,
+		BatchSize: 2,
+		Log:       log,
+	})
+	require.NoError(t, err)
+
+	auditor := NewForCall(log, sink, clock.NewMock(), time.Time{})
+	auditor.AuditWithFields(logrus.Fields{"a": "1"})
+
+	// The pending batch is timestamped when the files are rotated, and the
+	// offsets of the following batches are relative to the new file
+	require.NoError(t, os.Rename(path, rotatedPath))
+	require.NoError(t, os.Rename(path+TimestampsFileSuffix, rotatedPath+TimestampsFileSuffix))
+	require.NoError(t, sink.Reopen())
+
+	auditor.AuditWithFields(logrus.Fields{"a": "2"})
+	require.NoError(t, sink.Close())
+
+	// The timestamp of the batch pending on rotation can land in either
+	// timestamps file, depending on when the TSA replies
+	rotatedRecords := readTimestamps(t, rotatedPath+TimestampsFileSuffix)
+	records := readTimestamps(t, path+TimestampsFileSuffix)
+	require.Len(t, append(rotatedRecords, records...), 2)
+	for _, record := range append(rotatedRecords, records...) {
+		require.Equal(t, int64(0), record.Offset)
+		require.Equal(t, 1, record.Records)
+	}
+}
+
+func requireTimestamps(t *testing.T, path string, offset int64, expectRecords []int) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	records := readTimestamps(t, path+TimestampsFileSuffix)
+	require.Len(t, records, len(expectRecords))
+	for i, record := range records {
+		require.Equal(t, offset, record.Offset)
+		require.Equal(t, expectRecords[i], record.Records)
+		digest := sha256.Sum256(data[record.Offset : record.Offset+record.Length])
+		require.Equal(t, hex.EncodeToString(digest[:]), record.SHA256)
+		require.Equal(t, genTime, record.GenTime)
+		require.Equal(t, "42", record.SerialNumber)
+		require.NotEmpty(t, record.Token)
+		offset += record.Length
+	}
+	require.Equal(t, int64(len(data)), offset)
+}
+
+func readTimestamps(t *testing.T, path string) []TimestampRecord {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []TimestampRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record TimestampRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestFileSinkTimestampingInterval(t *testing.T) {
+	timestamped := make(chan struct{}, 1)
+	server := newFakeTSA(t, 0, func(t *testing.T, req timeStampReq) []byte {
+		timestamped <- struct{}{}
+		return grantedReply(t, req)
+	})
+
+	log, _ := test.NewNullLogger()
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"), &TimestampConfig{
+		TSAURL:        server.URL,
+		BatchInterval: 10 * time.Millisecond,
+		Log:           log,
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	NewForCall(log, sink, clock.NewMock(), time.Time{}).AuditWithFields(logrus.Fields{"a": "1"})
+
+	select {
+	case <-timestamped:
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for the batch to be timestamped")
+	}
+}
+
+func newFakeTSA(t *testing.T, status int, reply func(*testing.T, timeStampReq) []byte) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, timestampQueryContentType, r.Header.Get("Content-Type"))
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var req timeStampReq
+		_, err = asn1.Unmarshal(body, &req)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 1, req.Version)
+		assert.True(t, req.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256))
+
+		w.Header().Set("Content-Type", timestampReplyContentType)
+		_, _ = w.Write(reply(t, req))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// grantedReply returns a reply granting the request. The token is not
+// signed, since signatures are not verified by the client.
+func grantedReply(t *testing.T, req timeStampReq) []byte {
+	tstInfo := mustMarshal(t, struct {
+		Version        int
+		Policy         asn1.ObjectIdentifier
+		MessageImprint messageImprint
+		SerialNumber   *big.Int
+		GenTime        time.Time `asn1:"generalized"`
+		Accuracy       struct {
+			Seconds int `asn1:"optional"`
+		}
+		Nonce *big.Int
+	}{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(42),
+		GenTime:        genTime,
+		Accuracy: struct {
+			Seconds int `asn1:"optional"`
+		}{Seconds: 1},
+		Nonce: req.Nonce,
+	})
+
+	sd := mustMarshal(t, struct {
+		Version          int
+		DigestAlgorithms []asn1.RawValue `asn1:"set"`
+		EncapContentInfo encapsulatedContentInfo
+		SignerInfos      []asn1.RawValue `asn1:"set"`
+	}{
+		Version:          3,
+		EncapContentInfo: encapsulatedContentInfo{EContentType: oidTSTInfo, EContent: tstInfo},
+	})
+
+	token := mustMarshal(t, contentInfo{
+		ContentType: oidSignedData,
+		// The content is explicitly tagged
+		Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+
+	return mustMarshal(t, timeStampResp{
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
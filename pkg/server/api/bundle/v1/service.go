@@ -8,14 +8,17 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -37,15 +40,23 @@ type Config struct {
 	DataStore         datastore.DataStore
 	TrustDomain       spiffeid.TrustDomain
 	UpstreamPublisher UpstreamPublisher
+
+	// ReadAfterWrite makes reads from admin and local callers bypass the
+	// bundle cache, so changes made through any server are visible
+	// immediately
+	ReadAfterWrite bool
 }
 
 // Service defines the v1 bundle service properties.
 type Service struct {
 	bundlev1.UnsafeBundleServer
+	count.UnsafeBundleCountServer
 
 	ds datastore.DataStore
 	td spiffeid.TrustDomain
 	up UpstreamPublisher
+
+	readAfterWrite bool
 }
 
 // New creates a new bundle service.
@@ -54,12 +65,15 @@ func New(config Config) *Service {
 		ds: config.DataStore,
 		td: config.TrustDomain,
 		up: config.UpstreamPublisher,
+
+		readAfterWrite: config.ReadAfterWrite,
 	}
 }
 
 // RegisterService registers the bundle service on the gRPC server.
 func RegisterService(s *grpc.Server, service *Service) {
 	bundlev1.RegisterBundleServer(s, service)
+	count.RegisterBundleCountServer(s, service)
 }
 
 // CountBundles returns the total number of bundles.
@@ -74,18 +88,45 @@ func (s *Service) CountBundles(ctx context.Context, req *bundlev1.CountBundlesRe
 	return &bundlev1.CountBundlesResponse{Count: count}, nil
 }
 
+// CountFederatedBundles returns the number of bundles, excluding the bundle
+// of the server trust domain.
+func (s *Service) CountFederatedBundles(ctx context.Context, req *count.CountFederatedBundlesRequest) (*count.CountFederatedBundlesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	total, err := s.ds.CountBundles(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to count bundles", err)
+	}
+
+	localBundle, err := s.ds.FetchBundle(ctx, s.td.IDString())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
+	}
+	if localBundle != nil {
+		total--
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &count.CountFederatedBundlesResponse{Count: total}, nil
+}
+
 // GetBundle returns the bundle associated with the given trust domain.
 func (s *Service) GetBundle(ctx context.Context, req *bundlev1.GetBundleRequest) (*types.Bundle, error) {
 	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.TrustDomainID: s.td.String()})
 	log := rpccontext.Logger(ctx)
 
-	commonBundle, err := s.ds.FetchBundle(dscache.WithCache(ctx), s.td.IDString())
+	fetchCtx := ctx
+	if !s.readAfterWrite || !(rpccontext.CallerIsAdmin(ctx) || rpccontext.CallerIsLocal(ctx)) {
+		fetchCtx = dscache.WithCache(ctx)
+	}
+
+	commonBundle, err := s.ds.FetchBundle(fetchCtx, s.td.IDString())
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
 	}
 
 	if commonBundle == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "bundle not found", nil)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonBundleNotFound, "bundle not found", nil)
 	}
 
 	bundle, err := api.BundleToProto(commonBundle)
@@ -95,7 +136,7 @@ func (s *Service) GetBundle(ctx context.Context, req *bundlev1.GetBundleRequest)
 
 	applyBundleMask(bundle, req.OutputMask)
 	rpccontext.AuditRPC(ctx)
-	return bundle, nil
+	return slimBundleIfUnchanged(ctx, log, bundle), nil
 }
 
 // AppendBundle appends the given authorities to the given bundlev1.
@@ -148,7 +189,7 @@ func (s *Service) AppendBundle(ctx context.Context, req *bundlev1.AppendBundleRe
 
 	applyBundleMask(bundle, req.OutputMask)
 	rpccontext.AuditRPC(ctx)
-	return bundle, nil
+	return slimBundleIfUnchanged(ctx, log, bundle), nil
 }
 
 // PublishJWTAuthority published the JWT key on the server.
@@ -258,7 +299,7 @@ func (s *Service) GetFederatedBundle(ctx context.Context, req *bundlev1.GetFeder
 	}
 
 	if commonBundle == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "bundle not found", nil)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonBundleNotFound, "bundle not found", nil)
 	}
 
 	bundle, err := api.BundleToProto(commonBundle)
@@ -269,7 +310,7 @@ func (s *Service) GetFederatedBundle(ctx context.Context, req *bundlev1.GetFeder
 	applyBundleMask(bundle, req.OutputMask)
 	rpccontext.AuditRPC(ctx)
 
-	return bundle, nil
+	return slimBundleIfUnchanged(ctx, log, bundle), nil
 }
 
 // BatchCreateFederatedBundle adds one or more bundles to the server.
@@ -573,3 +614,37 @@ func applyBundleMask(b *types.Bundle, mask *types.BundleMask) {
 		b.JwtAuthorities = nil
 	}
 }
+
+// slimBundleIfUnchanged supports callers, like agents, that send the digest
+// of the bundle they already have. The digest of the bundle is returned in
+// the response header and, when it matches the digest sent by the caller, only
+// the trust domain of the bundle is returned to save bandwidth. Callers that
+// do not send a digest always get the whole bundle.
+func slimBundleIfUnchanged(ctx context.Context, log logrus.FieldLogger, bundle *types.Bundle) *types.Bundle {
+	md, _ := metadata.FromIncomingContext(ctx)
+	callerDigests := md.Get(nodeutil.BundleDigestHeader)
+	if len(callerDigests) == 0 {
+		return bundle
+	}
+
+	digest, err := nodeutil.BundleDigest(bundle)
+	if err != nil {
+		log.WithError(err).Warn("Failed to calculate bundle digest")
+		return bundle
+	}
+
+	header := metadata.Pairs(nodeutil.BundleDigestHeader, digest)
+	unchanged := callerDigests[0] == digest
+	if unchanged {
+		header.Set(nodeutil.BundleUnchangedHeader, "true")
+	}
+	if err := grpc.SetHeader(ctx, header); err != nil {
+		log.WithError(err).Warn("Failed to set bundle digest header")
+		return bundle
+	}
+
+	if unchanged {
+		return &types.Bundle{TrustDomain: bundle.TrustDomain}
+	}
+	return bundle
+}
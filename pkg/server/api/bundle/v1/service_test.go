@@ -18,19 +18,24 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testca"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -291,6 +296,72 @@ func TestGetFederatedBundle(t *testing.T) {
 	}
 }
 
+func TestGetBundleReadAfterWrite(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		readAfterWrite bool
+		withCaller     func(context.Context) context.Context
+		expectFresh    bool
+	}{
+		{
+			name:       "admin caller served from cache",
+			withCaller: rpccontext.WithAdminCaller,
+		},
+		{
+			name:           "admin caller with read-after-write",
+			readAfterWrite: true,
+			withCaller:     rpccontext.WithAdminCaller,
+			expectFresh:    true,
+		},
+		{
+			name:           "local caller with read-after-write",
+			readAfterWrite: true,
+			withCaller:     rpccontext.WithLocalCaller,
+			expectFresh:    true,
+		},
+		{
+			name:           "agent caller with read-after-write served from cache",
+			readAfterWrite: true,
+			withCaller:     rpccontext.WithAgentCaller,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			service := bundle.New(bundle.Config{
+				DataStore:      dscache.New(ds, clock.NewMock(t), 0),
+				TrustDomain:    serverTrustDomain,
+				ReadAfterWrite: tt.readAfterWrite,
+			})
+
+			log, _ := test.NewNullLogger()
+			ctx := tt.withCaller(rpccontext.WithLogger(context.Background(), log))
+
+			original := makeValidCommonBundle(t, serverTrustDomain)
+			_, err := ds.CreateBundle(ctx, original)
+			require.NoError(t, err)
+
+			b, err := service.GetBundle(ctx, &bundlev1.GetBundleRequest{})
+			require.NoError(t, err)
+			require.Equal(t, original.RefreshHint, b.RefreshHint)
+
+			// Change the bundle behind the cache, as another server would
+			updated := makeValidCommonBundle(t, serverTrustDomain)
+			updated.RefreshHint = original.RefreshHint + 1
+			_, err = ds.UpdateBundle(ctx, updated, nil)
+			require.NoError(t, err)
+
+			b, err = service.GetBundle(ctx, &bundlev1.GetBundleRequest{})
+			require.NoError(t, err)
+			if tt.expectFresh {
+				require.Equal(t, updated.RefreshHint, b.RefreshHint)
+			} else {
+				require.Equal(t, original.RefreshHint, b.RefreshHint)
+			}
+		})
+	}
+}
+
 func TestGetBundle(t *testing.T) {
 	for _, tt := range []struct {
 		name       string
@@ -389,6 +460,42 @@ func TestGetBundle(t *testing.T) {
 	}
 }
 
+func TestGetBundleUnchanged(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	bundle := makeValidCommonBundle(t, serverTrustDomain)
+	test.setBundle(t, bundle)
+
+	getBundle := func(digest string) (*types.Bundle, metadata.MD) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(context.Background(), nodeutil.BundleDigestHeader, digest)
+		b, err := test.client.GetBundle(ctx, &bundlev1.GetBundleRequest{}, grpc.Header(&header))
+		require.NoError(t, err)
+		return b, header
+	}
+
+	// The whole bundle is returned along with its digest when the caller
+	// does not have it yet
+	b, header := getBundle("")
+	assertCommonBundleWithMask(t, bundle, b, nil)
+	digest, err := nodeutil.BundleDigest(b)
+	require.NoError(t, err)
+	require.Equal(t, []string{digest}, header.Get(nodeutil.BundleDigestHeader))
+	require.Empty(t, header.Get(nodeutil.BundleUnchangedHeader))
+
+	// Only the trust domain is returned when the caller is up to date
+	b, header = getBundle(digest)
+	spiretest.AssertProtoEqual(t, &types.Bundle{TrustDomain: serverTrustDomain.String()}, b)
+	require.Equal(t, []string{digest}, header.Get(nodeutil.BundleDigestHeader))
+	require.Equal(t, []string{"true"}, header.Get(nodeutil.BundleUnchangedHeader))
+
+	// The whole bundle is returned when the caller has a stale bundle
+	b, header = getBundle("stale")
+	assertCommonBundleWithMask(t, bundle, b, nil)
+	require.Empty(t, header.Get(nodeutil.BundleUnchangedHeader))
+}
+
 func TestAppendBundle(t *testing.T) {
 	ca := testca.New(t, serverTrustDomain)
 	rootCA := ca.X509Authorities()[0]
@@ -451,7 +558,7 @@ func TestAppendBundle(t *testing.T) {
 			expectBundle: &types.Bundle{
 				TrustDomain:     defaultBundle.TrustDomain,
 				RefreshHint:     defaultBundle.RefreshHint,
-				SequenceNumber:  defaultBundle.SequenceNumber,
+				SequenceNumber:  defaultBundle.SequenceNumber + 1,
 				X509Authorities: append(defaultBundle.X509Authorities, x509Cert),
 				JwtAuthorities:  append(defaultBundle.JwtAuthorities, jwtKey2),
 			},
@@ -502,7 +609,7 @@ func TestAppendBundle(t *testing.T) {
 			expectBundle: &types.Bundle{
 				TrustDomain:     defaultBundle.TrustDomain,
 				RefreshHint:     defaultBundle.RefreshHint,
-				SequenceNumber:  defaultBundle.SequenceNumber,
+				SequenceNumber:  defaultBundle.SequenceNumber + 1,
 				JwtAuthorities:  defaultBundle.JwtAuthorities,
 				X509Authorities: append(defaultBundle.X509Authorities, x509Cert),
 			},
@@ -524,7 +631,7 @@ func TestAppendBundle(t *testing.T) {
 			expectBundle: &types.Bundle{
 				TrustDomain:     defaultBundle.TrustDomain,
 				RefreshHint:     defaultBundle.RefreshHint,
-				SequenceNumber:  defaultBundle.SequenceNumber,
+				SequenceNumber:  defaultBundle.SequenceNumber + 1,
 				JwtAuthorities:  append(defaultBundle.JwtAuthorities, jwtKey2),
 				X509Authorities: defaultBundle.X509Authorities,
 			},
@@ -1718,6 +1825,69 @@ func TestCountBundles(t *testing.T) {
 	}
 }
 
+func TestCountFederatedBundles(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		tds         []spiffeid.TrustDomain
+		dsError     error
+		expectCount int32
+		expectCode  codes.Code
+		expectMsg   string
+	}{
+		{
+			name:        "no bundles",
+			expectCount: 0,
+		},
+		{
+			name:        "local bundle only",
+			tds:         []spiffeid.TrustDomain{serverTrustDomain},
+			expectCount: 0,
+		},
+		{
+			name: "local and federated bundles",
+			tds: []spiffeid.TrustDomain{
+				serverTrustDomain,
+				spiffeid.RequireTrustDomainFromString("td1.org"),
+				spiffeid.RequireTrustDomainFromString("td2.org"),
+			},
+			expectCount: 2,
+		},
+		{
+			name: "federated bundles only",
+			tds: []spiffeid.TrustDomain{
+				spiffeid.RequireTrustDomainFromString("td1.org"),
+			},
+			expectCount: 1,
+		},
+		{
+			name:       "ds error",
+			dsError:    errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to count bundles: ds error",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			for _, td := range tt.tds {
+				createBundle(t, test, td.IDString())
+			}
+
+			test.ds.SetNextError(tt.dsError)
+			resp, err := test.countClient.CountFederatedBundles(context.Background(), &count.CountFederatedBundlesRequest{})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectCount, resp.Count)
+		})
+	}
+}
+
 func createBundle(t *testing.T, test *serviceTest, td string) *common.Bundle {
 	b := &common.Bundle{
 		TrustDomainId: td,
@@ -2872,6 +3042,7 @@ func (c *serviceTest) setBundle(t *testing.T, b *common.Bundle) {
 
 type serviceTest struct {
 	client      bundlev1.BundleClient
+	countClient count.BundleCountClient
 	ds          *fakedatastore.DataStore
 	logHook     *test.Hook
 	up          *fakeUpstreamPublisher
@@ -2940,6 +3111,7 @@ func setupServiceTest(t *testing.T) *serviceTest {
 	conn, done := spiretest.NewAPIServerWithMiddleware(t, registerFn, server)
 	test.done = done
 	test.client = bundlev1.NewBundleClient(conn)
+	test.countClient = count.NewBundleCountClient(conn)
 
 	return test
 }
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+var (
+	oidExtensionBasicConstraints  = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionKeyUsage          = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName    = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionExtendedKeyUsage  = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionSubjectKeyID      = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionNameConstraints   = asn1.ObjectIdentifier{2, 5, 29, 30}
+	oidExtensionPolicyConstraints = asn1.ObjectIdentifier{2, 5, 29, 36}
+	oidExtensionInhibitAnyPolicy  = asn1.ObjectIdentifier{2, 5, 29, 54}
+
+	// caOnlyExtensions are extensions that only apply to CA certificates and
+	// are never allowed on a CSR for an X509-SVID
+	caOnlyExtensions = map[string]string{
+		oidExtensionNameConstraints.String():   "name constraints",
+		oidExtensionPolicyConstraints.String(): "policy constraints",
+		oidExtensionInhibitAnyPolicy.String():  "inhibit any policy",
+	}
+
+	// knownExtensions are extensions understood by the CA. Unknown critical
+	// extensions are rejected.
+	knownExtensions = map[string]bool{
+		oidExtensionBasicConstraints.String(): true,
+		oidExtensionKeyUsage.String():         true,
+		oidExtensionSubjectAltName.String():   true,
+		oidExtensionExtendedKeyUsage.String(): true,
+		oidExtensionSubjectKeyID.String():     true,
+	}
+)
+
+// VerifyX509SVIDCSR verifies that a CSR can be used to request an X509-SVID,
+// as defined by the X509-SVID specification. CSRs with more than one URI SAN,
+// requesting a CA certificate or certificate signing key usages, or
+// containing CA-only or unsupported critical extensions are rejected.
+// Critical extensions in allowedExtensions are supported, since they are
+// copied into the X509-SVID.
+func VerifyX509SVIDCSR(csr *x509.CertificateRequest, allowedExtensions []asn1.ObjectIdentifier) error {
+	if len(csr.URIs) > 1 {
+		return errors.New("more than one URI SAN")
+	}
+
+	for _, ext := range csr.Extensions {
+		oid := ext.Id.String()
+		if name, ok := caOnlyExtensions[oid]; ok {
+			return fmt.Errorf("%s extension is not allowed", name)
+		}
+
+		switch {
+		case ext.Id.Equal(oidExtensionBasicConstraints):
+			var constraints struct {
+				IsCA       bool `asn1:"optional"`
+				MaxPathLen int  `asn1:"optional,default:-1"`
+			}
+			if _, err := asn1.Unmarshal(ext.Value, &constraints); err != nil {
+				return fmt.Errorf("malformed basic constraints extension: %w", err)
+			}
+			if constraints.IsCA {
+				return errors.New("basic constraints extension requests a CA certificate")
+			}
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			var usage asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &usage); err != nil {
+				return fmt.Errorf("malformed key usage extension: %w", err)
+			}
+			// keyCertSign and cRLSign are bits 5 and 6 of the key usage
+			if usage.At(5) != 0 || usage.At(6) != 0 {
+				return errors.New("key usage extension requests certificate or CRL signing")
+			}
+		case ext.Critical && !knownExtensions[oid] && !containsOID(allowedExtensions, ext.Id):
+			return fmt.Errorf("unsupported critical extension %s", oid)
+		}
+	}
+	return nil
+}
+
+func containsOID(oids []asn1.ObjectIdentifier, oid asn1.ObjectIdentifier) bool {
+	for _, o := range oids {
+		if o.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package api_test
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net/url"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyX509SVIDCSR(t *testing.T) {
+	workloadURI := &url.URL{Scheme: "spiffe", Host: "example.org", Path: "/workload"}
+	otherURI := &url.URL{Scheme: "spiffe", Host: "example.org", Path: "/other"}
+
+	mustMarshal := func(v interface{}) []byte {
+		b, err := asn1.Marshal(v)
+		require.NoError(t, err)
+		return b
+	}
+
+	for _, tt := range []struct {
+		name       string
+		uris       []*url.URL
+		extensions []pkix.Extension
+		allowed    []asn1.ObjectIdentifier
+		err        string
+	}{
+		{
+			name: "no extensions",
+			uris: []*url.URL{workloadURI},
+		},
+		{
+			name: "no URI SAN",
+		},
+		{
+			name: "more than one URI SAN",
+			uris: []*url.URL{workloadURI, otherURI},
+			err:  "more than one URI SAN",
+		},
+		{
+			name: "leaf basic constraints",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 19}, Critical: true, Value: mustMarshal(struct{}{})},
+			},
+		},
+		{
+			name: "CA basic constraints",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 19}, Critical: true, Value: mustMarshal(struct{ IsCA bool }{IsCA: true})},
+			},
+			err: "basic constraints extension requests a CA certificate",
+		},
+		{
+			name: "malformed basic constraints",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 19}, Value: []byte("bad")},
+			},
+			err: "malformed basic constraints extension: ",
+		},
+		{
+			name: "digital signature key usage",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Critical: true, Value: mustMarshal(asn1.BitString{Bytes: []byte{0x80}, BitLength: 1})},
+			},
+		},
+		{
+			name: "certificate signing key usage",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Critical: true, Value: mustMarshal(asn1.BitString{Bytes: []byte{0x84}, BitLength: 6})},
+			},
+			err: "key usage extension requests certificate or CRL signing",
+		},
+		{
+			name: "CRL signing key usage",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Critical: true, Value: mustMarshal(asn1.BitString{Bytes: []byte{0x02}, BitLength: 7})},
+			},
+			err: "key usage extension requests certificate or CRL signing",
+		},
+		{
+			name: "name constraints",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{2, 5, 29, 30}, Value: mustMarshal(asn1.RawValue{Tag: asn1.TagSequence, Class: asn1.ClassUniversal, IsCompound: true})},
+			},
+			err: "name constraints extension is not allowed",
+		},
+		{
+			name: "unknown non-critical extension",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: mustMarshal("value")},
+			},
+		},
+		{
+			name: "unknown critical extension",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Critical: true, Value: mustMarshal("value")},
+			},
+			err: "unsupported critical extension 1.2.3.4",
+		},
+		{
+			name: "allowed critical extension",
+			uris: []*url.URL{workloadURI},
+			extensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Critical: true, Value: mustMarshal("value")},
+			},
+			allowed: []asn1.ObjectIdentifier{{1, 2, 3, 4}},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+				URIs:            tt.uris,
+				ExtraExtensions: tt.extensions,
+			}, testkey.NewEC256(t))
+			require.NoError(t, err)
+			csr, err := x509.ParseCertificateRequest(csrDER)
+			require.NoError(t, err)
+
+			err = api.VerifyX509SVIDCSR(csr, tt.allowed)
+			if tt.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
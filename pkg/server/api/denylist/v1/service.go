@@ -0,0 +1,91 @@
+package denylist
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/ca"
+	denylistpb "github.com/spiffe/spire/proto/private/server/denylist"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// RegisterService registers the service on the gRPC server.
+func RegisterService(s *grpc.Server, service *Service) {
+	denylistpb.RegisterDenylistServer(s, service)
+}
+
+// Config is the service configuration
+type Config struct {
+	// Denylist is the SVID denylist enforced by the server CA
+	Denylist *ca.Denylist
+}
+
+// New creates a new Denylist service
+func New(config Config) *Service {
+	return &Service{
+		denylist: config.Denylist,
+	}
+}
+
+// Service implements the private Denylist service. Changes made through the
+// service are not persisted; the patterns are replaced by the configured
+// ones when the server configuration is reloaded.
+type Service struct {
+	denylistpb.UnsafeDenylistServer
+
+	denylist *ca.Denylist
+}
+
+// ListPatterns lists the patterns of the SVID denylist.
+func (s *Service) ListPatterns(ctx context.Context, req *denylistpb.ListPatternsRequest) (*denylistpb.ListPatternsResponse, error) {
+	rpccontext.AuditRPC(ctx)
+
+	return &denylistpb.ListPatternsResponse{
+		Patterns: s.denylist.Patterns(),
+	}, nil
+}
+
+// AddPattern adds a pattern to the SVID denylist. SVIDs are no longer signed
+// for matching SPIFFE IDs as soon as the call returns.
+func (s *Service) AddPattern(ctx context.Context, req *denylistpb.AddPatternRequest) (*denylistpb.AddPatternResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.Pattern: req.Pattern})
+
+	if req.Pattern == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing pattern", nil)
+	}
+	if err := s.denylist.AddPattern(req.Pattern); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid pattern", err)
+	}
+
+	log.WithField(telemetry.Pattern, req.Pattern).Info("SVID denylist pattern added")
+	rpccontext.AuditRPC(ctx)
+
+	return &denylistpb.AddPatternResponse{
+		Patterns: s.denylist.Patterns(),
+	}, nil
+}
+
+// RemovePattern removes a pattern from the SVID denylist.
+func (s *Service) RemovePattern(ctx context.Context, req *denylistpb.RemovePatternRequest) (*denylistpb.RemovePatternResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.Pattern: req.Pattern})
+
+	if req.Pattern == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing pattern", nil)
+	}
+	if !s.denylist.RemovePattern(req.Pattern) {
+		return nil, api.MakeErr(log, codes.NotFound, "pattern not found", nil)
+	}
+
+	log.WithField(telemetry.Pattern, req.Pattern).Info("SVID denylist pattern removed")
+	rpccontext.AuditRPC(ctx)
+
+	return &denylistpb.RemovePatternResponse{
+		Patterns: s.denylist.Patterns(),
+	}, nil
+}
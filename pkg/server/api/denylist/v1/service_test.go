@@ -0,0 +1,88 @@
+package denylist_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/api/denylist/v1"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/ca"
+	denylistpb "github.com/spiffe/spire/proto/private/server/denylist"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var ctx = context.Background()
+
+func TestService(t *testing.T) {
+	log, logHook := test.NewNullLogger()
+
+	svidDenylist, err := ca.NewDenylist([]string{"spiffe://example.org/a"})
+	require.NoError(t, err)
+
+	service := denylist.New(denylist.Config{Denylist: svidDenylist})
+	conn, done := spiretest.NewAPIServer(t,
+		func(s *grpc.Server) {
+			denylist.RegisterService(s, service)
+		},
+		func(ctx context.Context) context.Context {
+			return rpccontext.WithLogger(ctx, log)
+		},
+	)
+	defer done()
+	client := denylistpb.NewDenylistClient(conn)
+
+	listResp, err := client.ListPatterns(ctx, &denylistpb.ListPatternsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"spiffe://example.org/a"}, listResp.Patterns)
+
+	addResp, err := client.AddPattern(ctx, &denylistpb.AddPatternRequest{Pattern: "spiffe://example.org/b/**"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"spiffe://example.org/a", "spiffe://example.org/b/**"}, addResp.Patterns)
+	_, denied := svidDenylist.Match(spiffeid.RequireFromString("spiffe://example.org/b/c/d"))
+	require.True(t, denied)
+
+	_, err = client.AddPattern(ctx, &denylistpb.AddPatternRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing pattern")
+
+	_, err = client.AddPattern(ctx, &denylistpb.AddPatternRequest{Pattern: "["})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, `invalid pattern: malformed pattern "[": syntax error in pattern`)
+
+	removeResp, err := client.RemovePattern(ctx, &denylistpb.RemovePatternRequest{Pattern: "spiffe://example.org/a"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"spiffe://example.org/b/**"}, removeResp.Patterns)
+
+	_, err = client.RemovePattern(ctx, &denylistpb.RemovePatternRequest{Pattern: "spiffe://example.org/a"})
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "pattern not found")
+
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "SVID denylist pattern added",
+			Data:    logrus.Fields{"pattern": "spiffe://example.org/b/**"},
+		},
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Invalid argument: missing pattern",
+		},
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Invalid argument: invalid pattern",
+			Data:    logrus.Fields{logrus.ErrorKey: `malformed pattern "[": syntax error in pattern`},
+		},
+		{
+			Level:   logrus.InfoLevel,
+			Message: "SVID denylist pattern removed",
+			Data:    logrus.Fields{"pattern": "spiffe://example.org/a"},
+		},
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Pattern not found",
+		},
+	})
+}
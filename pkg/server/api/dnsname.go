@@ -0,0 +1,24 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spiffe/spire/pkg/common/x509util"
+)
+
+// ValidateDNSNamePattern returns an error if the pattern is not a valid DNS
+// name, once "*" labels are replaced. A "*" label matches exactly one label.
+// Partial wildcard labels, e.g. "web-*.example.org", are not supported.
+func ValidateDNSNamePattern(pattern string) error {
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		switch {
+		case label == "*":
+			labels[i] = "placeholder"
+		case strings.Contains(label, "*"):
+			return fmt.Errorf("DNS name pattern %q has a partial wildcard label", pattern)
+		}
+	}
+	return x509util.ValidateDNS(strings.Join(labels, "."))
+}
@@ -0,0 +1,45 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDNSNamePattern(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		pattern string
+		err     string
+	}{
+		{
+			name:    "plain DNS name",
+			pattern: "foo.example.org",
+		},
+		{
+			name:    "wildcard label",
+			pattern: "*.web.example.org",
+		},
+		{
+			name:    "partial wildcard label",
+			pattern: "web-*.example.org",
+			err:     `DNS name pattern "web-*.example.org" has a partial wildcard label`,
+		},
+		{
+			name:    "invalid label",
+			pattern: "*.-web.example.org",
+			err:     "label does not match regex: -web",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := api.ValidateDNSNamePattern(tt.pattern)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -7,8 +7,8 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/dnstemplate"
 	"github.com/spiffe/spire/pkg/common/protoutil"
-	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
@@ -113,7 +113,7 @@ func ProtoToRegistrationEntryWithMask(ctx context.Context, td spiffeid.TrustDoma
 	if mask.DnsNames {
 		dnsNames = make([]string, 0, len(e.DnsNames))
 		for _, dnsName := range e.DnsNames {
-			if err := x509util.ValidateDNS(dnsName); err != nil {
+			if err := dnstemplate.Validate(dnsName); err != nil {
 				return nil, fmt.Errorf("invalid DNS name: %w", err)
 			}
 			dnsNames = append(dnsNames, dnsName)
@@ -151,6 +151,10 @@ func ProtoToRegistrationEntryWithMask(ctx context.Context, td spiffeid.TrustDoma
 		if err != nil {
 			return nil, err
 		}
+		if err := ValidateSelectorSchemas(selectors); err != nil {
+			return nil, err
+		}
+		SortSelectors(selectors)
 	}
 
 	var ttl int32
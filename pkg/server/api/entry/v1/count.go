@@ -0,0 +1,61 @@
+package entry
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"google.golang.org/grpc/codes"
+)
+
+// countPageSize is the page size used to walk the entries that match a
+// filter, since the datastore can only count entries by SPIFFE ID prefix.
+const countPageSize = 1000
+
+// CountFilteredEntries returns the number of entries that match the given
+// filter, which supports the same fields as the ListEntries filter.
+func (s *Service) CountFilteredEntries(ctx context.Context, req *count.CountFilteredEntriesRequest) (*count.CountFilteredEntriesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Filter == nil {
+		total, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{
+			BySpiffeIDPrefix: s.callerNamespacePrefix(ctx),
+		})
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to count entries", err)
+		}
+		rpccontext.AuditRPC(ctx)
+
+		return &count.CountFilteredEntriesResponse{Count: total}, nil
+	}
+
+	rpccontext.AddRPCAuditFields(ctx, fieldsFromListEntryFilter(ctx, s.td, req.Filter))
+
+	listReq := &datastore.ListRegistrationEntriesRequest{
+		BySpiffeIDPrefix: s.callerNamespacePrefix(ctx),
+		Pagination: &datastore.Pagination{
+			PageSize: countPageSize,
+		},
+	}
+	if err := s.applyListEntriesFilter(ctx, log, req.Filter, listReq); err != nil {
+		return nil, err
+	}
+
+	var total int32
+	for {
+		dsResp, err := s.ds.ListRegistrationEntries(ctx, listReq)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to count entries", err)
+		}
+		total += int32(len(dsResp.Entries))
+		if dsResp.Pagination == nil || dsResp.Pagination.Token == "" || len(dsResp.Entries) == 0 {
+			break
+		}
+		listReq.Pagination.Token = dsResp.Pagination.Token
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &count.CountFilteredEntriesResponse{Count: total}, nil
+}
@@ -0,0 +1,125 @@
+package entry_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCountFilteredEntries(t *testing.T) {
+	setup := func(t *testing.T, namespaces []entry.Namespace) *serviceTest {
+		ds := fakedatastore.New(t)
+		test := setupServiceTestWithNamespaces(t, ds, namespaces)
+		t.Cleanup(test.Cleanup)
+
+		createTestEntries(t, ds,
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-a/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-b/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/other-parent",
+				SpiffeId:  "spiffe://example.org/team-a/other-workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+		)
+		return test
+	}
+
+	for _, tt := range []struct {
+		name        string
+		filter      *entryv1.ListEntriesRequest_Filter
+		expectCount int32
+		expectCode  codes.Code
+		expectMsg   string
+	}{
+		{
+			name:        "no filter",
+			expectCount: 3,
+		},
+		{
+			name: "by parent ID",
+			filter: &entryv1.ListEntriesRequest_Filter{
+				ByParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+			},
+			expectCount: 2,
+		},
+		{
+			name: "by SPIFFE ID",
+			filter: &entryv1.ListEntriesRequest_Filter{
+				BySpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-b/workload"},
+			},
+			expectCount: 1,
+		},
+		{
+			name: "by selectors",
+			filter: &entryv1.ListEntriesRequest_Filter{
+				BySelectors: &types.SelectorMatch{
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+					Match:     types.SelectorMatch_MATCH_EXACT,
+				},
+			},
+			expectCount: 2,
+		},
+		{
+			name: "malformed selectors filter",
+			filter: &entryv1.ListEntriesRequest_Filter{
+				BySelectors: &types.SelectorMatch{},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed selectors filter: empty selector set",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test := setup(t, nil)
+
+			resp, err := test.countClient.CountFilteredEntries(ctx, &count.CountFilteredEntriesRequest{Filter: tt.filter})
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectCount, resp.Count)
+		})
+	}
+
+	t.Run("namespaced caller", func(t *testing.T) {
+		test := setup(t, []entry.Namespace{
+			{
+				Name:       "team-a",
+				AdminIDs:   []spiffeid.ID{agentID},
+				PathPrefix: "/team-a/",
+			},
+		})
+		test.withCallerID = true
+
+		resp, err := test.countClient.CountFilteredEntries(ctx, &count.CountFilteredEntriesRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), resp.Count)
+
+		resp, err = test.countClient.CountFilteredEntries(ctx, &count.CountFilteredEntriesRequest{
+			Filter: &entryv1.ListEntriesRequest_Filter{
+				ByParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(1), resp.Count)
+	})
+}
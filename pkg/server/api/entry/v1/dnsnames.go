@@ -0,0 +1,90 @@
+package entry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"google.golang.org/grpc/codes"
+)
+
+// GetDNSNamePatterns returns the patterns of the DNS names agents can request
+// for the X509-SVIDs of an entry.
+func (s *Service) GetDNSNamePatterns(ctx context.Context, req *entrydnsnames.GetDNSNamePatternsRequest) (*entrydnsnames.GetDNSNamePatternsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.EntryId == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing entry ID", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.RegistrationID: req.EntryId})
+	log = log.WithField(telemetry.RegistrationID, req.EntryId)
+
+	if err := s.checkDNSNamePatternsEntry(ctx, log, req.EntryId); err != nil {
+		return nil, err
+	}
+
+	patterns, err := s.ds.FetchEntryDNSNamePatterns(ctx, req.EntryId)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch DNS name patterns", err)
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &entrydnsnames.GetDNSNamePatternsResponse{
+		Patterns: patterns,
+	}, nil
+}
+
+// SetDNSNamePatterns sets the patterns of the DNS names agents can request
+// for the X509-SVIDs of an entry, replacing the previous ones. The patterns
+// are kept apart from the DNS names of the entry and are removed when the
+// entry is deleted.
+func (s *Service) SetDNSNamePatterns(ctx context.Context, req *entrydnsnames.SetDNSNamePatternsRequest) (*entrydnsnames.SetDNSNamePatternsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.EntryId == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing entry ID", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.RegistrationID: req.EntryId,
+		telemetry.Pattern:        strings.Join(req.Patterns, ","),
+	})
+	log = log.WithField(telemetry.RegistrationID, req.EntryId)
+
+	for _, pattern := range req.Patterns {
+		if err := api.ValidateDNSNamePattern(pattern); err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "invalid DNS name pattern", err)
+		}
+	}
+
+	if err := s.checkDNSNamePatternsEntry(ctx, log, req.EntryId); err != nil {
+		return nil, err
+	}
+
+	if err := s.ds.SetEntryDNSNamePatterns(ctx, req.EntryId, req.Patterns); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to set DNS name patterns", err)
+	}
+
+	log.WithField(telemetry.Pattern, strings.Join(req.Patterns, ",")).Info("Registration entry DNS name patterns set")
+	rpccontext.AuditRPC(ctx)
+
+	return &entrydnsnames.SetDNSNamePatternsResponse{
+		Patterns: req.Patterns,
+	}, nil
+}
+
+// checkDNSNamePatternsEntry returns an error if the entry does not exist or
+// is not in the namespace of the caller.
+func (s *Service) checkDNSNamePatternsEntry(ctx context.Context, log logrus.FieldLogger, id string) error {
+	entry, err := s.ds.FetchRegistrationEntry(ctx, id)
+	if err != nil {
+		return api.MakeErr(log, codes.Internal, "failed to fetch entry", err)
+	}
+	if entry == nil || !s.inCallerNamespace(ctx, entry.SpiffeId) {
+		return api.MakeErrWithReason(log, codes.NotFound, api.ReasonEntryNotFound, "entry not found", nil)
+	}
+	return nil
+}
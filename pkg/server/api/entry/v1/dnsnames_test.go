@@ -0,0 +1,161 @@
+package entry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDNSNamePatterns(t *testing.T) {
+	setup := func(t *testing.T, namespaces []entry.Namespace) (*serviceTest, *fakedatastore.DataStore, map[string]*common.RegistrationEntry) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTestWithNamespaces(t, ds, namespaces)
+		t.Cleanup(test.Cleanup)
+
+		entries := createTestEntries(t, ds,
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-a/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-b/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+		)
+		return test, ds, entries
+	}
+
+	t.Run("set and get", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+		id := entries["spiffe://example.org/team-a/workload"].EntryId
+
+		getResp, err := test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{EntryId: id})
+		require.NoError(t, err)
+		require.Empty(t, getResp.Patterns)
+
+		setResp, err := test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  id,
+			Patterns: []string{"*.web.example.org", "web.example.org"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"*.web.example.org", "web.example.org"}, setResp.Patterns)
+
+		getResp, err = test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{EntryId: id})
+		require.NoError(t, err)
+		require.Equal(t, []string{"*.web.example.org", "web.example.org"}, getResp.Patterns)
+
+		// The patterns of other entries are not affected
+		patterns, err := ds.FetchEntryDNSNamePatterns(ctx, entries["spiffe://example.org/team-b/workload"].EntryId)
+		require.NoError(t, err)
+		require.Empty(t, patterns)
+
+		// An empty list removes the patterns
+		_, err = test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{EntryId: id})
+		require.NoError(t, err)
+		getResp, err = test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{EntryId: id})
+		require.NoError(t, err)
+		require.Empty(t, getResp.Patterns)
+	})
+
+	t.Run("removed with the entry", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+		id := entries["spiffe://example.org/team-a/workload"].EntryId
+
+		_, err := test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  id,
+			Patterns: []string{"*.web.example.org"},
+		})
+		require.NoError(t, err)
+
+		_, err = test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: []string{id}})
+		require.NoError(t, err)
+
+		patterns, err := ds.FetchEntryDNSNamePatterns(ctx, id)
+		require.NoError(t, err)
+		require.Empty(t, patterns)
+	})
+
+	t.Run("missing entry ID", func(t *testing.T) {
+		test, _, _ := setup(t, nil)
+
+		_, err := test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing entry ID")
+
+		_, err = test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{Patterns: []string{"*.web.example.org"}})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing entry ID")
+	})
+
+	t.Run("unknown entry", func(t *testing.T) {
+		test, _, _ := setup(t, nil)
+
+		_, err := test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{EntryId: "unknown"})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry not found")
+
+		_, err = test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  "unknown",
+			Patterns: []string{"*.web.example.org"},
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry not found")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		test, _, entries := setup(t, nil)
+
+		_, err := test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  entries["spiffe://example.org/team-a/workload"].EntryId,
+			Patterns: []string{"web-*.example.org"},
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, `invalid DNS name pattern: DNS name pattern "web-*.example.org" has a partial wildcard label`)
+	})
+
+	t.Run("datastore failure", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+		id := entries["spiffe://example.org/team-a/workload"].EntryId
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err := test.dnsNamesClient.GetDNSNamePatterns(ctx, &entrydnsnames.GetDNSNamePatternsRequest{EntryId: id})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to fetch entry: oh no")
+
+		ds.AppendNextError(nil)
+		ds.AppendNextError(errors.New("oh no"))
+		_, err = test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  id,
+			Patterns: []string{"*.web.example.org"},
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to set DNS name patterns: oh no")
+	})
+
+	t.Run("scoped to the caller namespace", func(t *testing.T) {
+		test, _, entries := setup(t, []entry.Namespace{
+			{
+				Name:       "team-a",
+				AdminIDs:   []spiffeid.ID{agentID},
+				PathPrefix: "/team-a/",
+			},
+		})
+		test.withCallerID = true
+
+		_, err := test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  entries["spiffe://example.org/team-b/workload"].EntryId,
+			Patterns: []string{"*.web.example.org"},
+		})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry not found")
+
+		_, err = test.dnsNamesClient.SetDNSNamePatterns(ctx, &entrydnsnames.SetDNSNamePatternsRequest{
+			EntryId:  entries["spiffe://example.org/team-a/workload"].EntryId,
+			Patterns: []string{"*.web.example.org"},
+		})
+		require.NoError(t, err)
+	})
+}
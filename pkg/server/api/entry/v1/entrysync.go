@@ -0,0 +1,92 @@
+package entry
+
+import (
+	"sort"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// SyncAuthorizedEntries streams the entries authorized for the calling agent.
+// Every authorized entry is sent first. After that, the entries are fetched
+// again each time the entry fetcher reports that they may have changed, and
+// only the differences are sent.
+func (s *Service) SyncAuthorizedEntries(req *entrysync.SyncAuthorizedEntriesRequest, stream entrysync.EntrySync_SyncAuthorizedEntriesServer) error {
+	ctx := stream.Context()
+	log := rpccontext.Logger(ctx)
+
+	notifier, ok := s.ef.(api.AuthorizedEntryNotifier)
+	if !ok {
+		return api.MakeErr(log, codes.Unimplemented, "entry sync is not supported by the entry fetcher", nil)
+	}
+
+	// revisions holds the revision number of the entries sent so far
+	revisions := make(map[string]int64)
+	renewAgentSVID := false
+	for first := true; ; first = false {
+		// The channel is obtained before fetching the entries, so changes
+		// made while the entries are fetched are not missed
+		changed := notifier.AuthorizedEntriesChanged()
+
+		entries, err := s.fetchEntries(ctx, log)
+		if err != nil {
+			return err
+		}
+
+		resp := diffEntries(revisions, entries, req.OutputMask)
+		_, renew := s.agentRenewalRequested(ctx, log)
+		if first || renew != renewAgentSVID || len(resp.Entries) > 0 || len(resp.DeletedEntryIds) > 0 {
+			resp.RenewAgentSvid = renew
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			renewAgentSVID = renew
+		}
+		if first {
+			rpccontext.AuditRPC(ctx)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// diffEntries returns a response holding the entries that were not sent yet
+// or whose revision changed since they were sent, and the IDs of the entries
+// that were sent but are no longer authorized. The revisions are updated to
+// the ones of the given entries.
+func diffEntries(revisions map[string]int64, entries []*types.Entry, mask *types.EntryMask) *entrysync.SyncAuthorizedEntriesResponse {
+	resp := &entrysync.SyncAuthorizedEntriesResponse{}
+
+	authorized := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		authorized[entry.Id] = struct{}{}
+		if revision, ok := revisions[entry.Id]; ok && revision == entry.RevisionNumber {
+			continue
+		}
+		revisions[entry.Id] = entry.RevisionNumber
+
+		// The entries are shared with the entry cache, so they are cloned
+		// before the mask is applied
+		entry = proto.Clone(entry).(*types.Entry)
+		applyMask(entry, mask)
+		resp.Entries = append(resp.Entries, entry)
+	}
+
+	for id := range revisions {
+		if _, ok := authorized[id]; !ok {
+			delete(revisions, id)
+			resp.DeletedEntryIds = append(resp.DeletedEntryIds, id)
+		}
+	}
+	sort.Strings(resp.DeletedEntryIds)
+
+	return resp
+}
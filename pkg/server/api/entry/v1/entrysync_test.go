@@ -0,0 +1,142 @@
+package entry_test
+
+import (
+	"context"
+	"crypto/x509"
+	"math/big"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSyncAuthorizedEntries(t *testing.T) {
+	entry1 := &types.Entry{
+		Id:             "entry-1",
+		SpiffeId:       &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+		Selectors:      []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		DnsNames:       []string{"dns1"},
+		RevisionNumber: 1,
+	}
+	entry2 := &types.Entry{
+		Id:             "entry-2",
+		SpiffeId:       &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+		Selectors:      []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+		RevisionNumber: 1,
+	}
+	entry2Updated := &types.Entry{
+		Id:             "entry-2",
+		SpiffeId:       &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+		Selectors:      []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+		RevisionNumber: 2,
+	}
+	entry3 := &types.Entry{
+		Id:             "entry-3",
+		SpiffeId:       &types.SPIFFEID{TrustDomain: "example.org", Path: "/baz"},
+		Selectors:      []*types.Selector{{Type: "unix", Value: "uid:1003"}},
+		RevisionNumber: 1,
+	}
+	mask := &types.EntryMask{SpiffeId: true, Selectors: true, RevisionNumber: true}
+
+	test := setupServiceTest(t, fakedatastore.New(t))
+	defer test.Cleanup()
+	test.withCallerID = true
+	test.ef.entries = []*types.Entry{entry1, entry2}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := test.syncClient.SyncAuthorizedEntries(ctx, &entrysync.SyncAuthorizedEntriesRequest{
+		OutputMask: mask,
+	})
+	require.NoError(t, err)
+
+	// Every authorized entry is sent first, with the output mask applied
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrysync.SyncAuthorizedEntriesResponse{
+		Entries: []*types.Entry{
+			{Id: "entry-1", SpiffeId: entry1.SpiffeId, Selectors: entry1.Selectors, RevisionNumber: 1},
+			{Id: "entry-2", SpiffeId: entry2.SpiffeId, Selectors: entry2.Selectors, RevisionNumber: 1},
+		},
+	}, resp)
+
+	// The entries shared with the fetcher are not modified by the mask
+	require.Equal(t, []string{"dns1"}, entry1.DnsNames)
+
+	// Only the updated, added and deleted entries are sent after a change
+	test.ef.setEntries([]*types.Entry{entry2Updated, entry3})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrysync.SyncAuthorizedEntriesResponse{
+		Entries: []*types.Entry{
+			{Id: "entry-2", SpiffeId: entry2Updated.SpiffeId, Selectors: entry2Updated.Selectors, RevisionNumber: 2},
+			{Id: "entry-3", SpiffeId: entry3.SpiffeId, Selectors: entry3.Selectors, RevisionNumber: 1},
+		},
+		DeletedEntryIds: []string{"entry-1"},
+	}, resp)
+
+	// Nothing is sent when the entries did not change
+	test.ef.setEntries([]*types.Entry{entry2Updated, entry3})
+	test.ef.setEntries([]*types.Entry{entry3})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrysync.SyncAuthorizedEntriesResponse{
+		DeletedEntryIds: []string{"entry-2"},
+	}, resp)
+}
+
+func TestSyncAuthorizedEntriesAgentRenewal(t *testing.T) {
+	ds := fakedatastore.New(t)
+	require.NoError(t, ds.SetAgentRenewal(ctx, &datastore.AgentRenewal{SpiffeID: agentID.String(), SerialNumber: "1"}))
+
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+	test.withCallerID = true
+	test.agentSVID = &x509.Certificate{SerialNumber: big.NewInt(1)}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := test.syncClient.SyncAuthorizedEntries(ctx, &entrysync.SyncAuthorizedEntriesRequest{})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrysync.SyncAuthorizedEntriesResponse{RenewAgentSvid: true}, resp)
+
+	// The renewal is no longer requested once it targets another SVID
+	require.NoError(t, ds.SetAgentRenewal(ctx, &datastore.AgentRenewal{SpiffeID: agentID.String(), SerialNumber: "2"}))
+	test.ef.setEntries(nil)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &entrysync.SyncAuthorizedEntriesResponse{}, resp)
+}
+
+func TestSyncAuthorizedEntriesNotSupported(t *testing.T) {
+	test := setupServiceTestWithConfig(t, fakedatastore.New(t), func(c *entry.Config) {
+		c.EntryFetcher = staticEntryFetcher{}
+	})
+	defer test.Cleanup()
+	test.withCallerID = true
+
+	stream, err := test.syncClient.SyncAuthorizedEntries(ctx, &entrysync.SyncAuthorizedEntriesRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	spiretest.RequireGRPCStatus(t, err, codes.Unimplemented, "entry sync is not supported by the entry fetcher")
+}
+
+// staticEntryFetcher is an entry fetcher that cannot notify changes
+type staticEntryFetcher struct{}
+
+func (staticEntryFetcher) FetchAuthorizedEntries(context.Context, spiffeid.ID) ([]*types.Entry, error) {
+	return nil, nil
+}
@@ -0,0 +1,165 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateEntryTemplate creates an entry template. The entries of the template
+// are created when agents with all the node selectors of the template attest.
+func (s *Service) CreateEntryTemplate(ctx context.Context, req *entrytemplate.CreateEntryTemplateRequest) (*entrytemplate.CreateEntryTemplateResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if err := s.checkEntryTemplateAccess(ctx, log); err != nil {
+		return nil, err
+	}
+	if req.Template == nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing entry template", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: req.Template.SpiffeId})
+
+	t, err := entryTemplateFromProto(req.Template)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to convert entry template", err)
+	}
+	if err := api.ValidateEntryTemplate(s.td, t); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid entry template", err)
+	}
+
+	t, err = s.ds.CreateEntryTemplate(ctx, t)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to create entry template", err)
+	}
+
+	log.WithField(telemetry.EntryTemplateID, t.ID).Info("Entry template created")
+	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{telemetry.EntryTemplateID: t.ID})
+
+	return &entrytemplate.CreateEntryTemplateResponse{
+		Template: entryTemplateToProto(t),
+	}, nil
+}
+
+// ListEntryTemplates lists the entry templates.
+func (s *Service) ListEntryTemplates(ctx context.Context, req *entrytemplate.ListEntryTemplatesRequest) (*entrytemplate.ListEntryTemplatesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if err := s.checkEntryTemplateAccess(ctx, log); err != nil {
+		return nil, err
+	}
+
+	templates, err := s.ds.ListEntryTemplates(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list entry templates", err)
+	}
+
+	resp := &entrytemplate.ListEntryTemplatesResponse{}
+	for _, t := range templates {
+		resp.Templates = append(resp.Templates, entryTemplateToProto(t))
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// DeleteEntryTemplate deletes an entry template. The entries already created
+// from the template are kept.
+func (s *Service) DeleteEntryTemplate(ctx context.Context, req *entrytemplate.DeleteEntryTemplateRequest) (*entrytemplate.DeleteEntryTemplateResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if err := s.checkEntryTemplateAccess(ctx, log); err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing entry template ID", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.EntryTemplateID: req.Id})
+	log = log.WithField(telemetry.EntryTemplateID, req.Id)
+
+	t, err := s.ds.DeleteEntryTemplate(ctx, req.Id)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "entry template not found", nil)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to delete entry template", err)
+	}
+
+	log.Info("Entry template deleted")
+	rpccontext.AuditRPC(ctx)
+
+	return &entrytemplate.DeleteEntryTemplateResponse{
+		Template: entryTemplateToProto(t),
+	}, nil
+}
+
+// checkEntryTemplateAccess returns an error if the caller is scoped to a
+// namespace. Templates can render SPIFFE IDs out of any namespace, so they
+// are only available to unscoped administrators.
+func (s *Service) checkEntryTemplateAccess(ctx context.Context, log logrus.FieldLogger) error {
+	if s.callerNamespace(ctx) != nil {
+		return api.MakeErr(log, codes.PermissionDenied, "entry templates are not available to namespace administrators", nil)
+	}
+	return nil
+}
+
+func entryTemplateFromProto(t *entrytemplate.EntryTemplate) (*datastore.EntryTemplate, error) {
+	nodeSelectors, err := parseEntryTemplateSelectors(t.NodeSelectors)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node selector: %w", err)
+	}
+	selectors, err := parseEntryTemplateSelectors(t.Selectors)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	return &datastore.EntryTemplate{
+		NodeSelectors: nodeSelectors,
+		Entry: &common.RegistrationEntry{
+			SpiffeId:  t.SpiffeId,
+			Selectors: selectors,
+			Ttl:       t.Ttl,
+			DnsNames:  t.DnsNames,
+		},
+	}, nil
+}
+
+func parseEntryTemplateSelectors(in []string) ([]*common.Selector, error) {
+	var out []*common.Selector
+	for _, s := range in {
+		selectorType, value, ok := strings.Cut(s, ":")
+		if !ok || selectorType == "" || value == "" {
+			return nil, fmt.Errorf("%q is not of the form <type>:<value>", s)
+		}
+		out = append(out, &common.Selector{Type: selectorType, Value: value})
+	}
+	return out, nil
+}
+
+func entryTemplateToProto(t *datastore.EntryTemplate) *entrytemplate.EntryTemplate {
+	nodeSelectors := make([]string, 0, len(t.NodeSelectors))
+	for _, selector := range t.NodeSelectors {
+		nodeSelectors = append(nodeSelectors, selector.Type+":"+selector.Value)
+	}
+	selectors := make([]string, 0, len(t.Entry.Selectors))
+	for _, selector := range t.Entry.Selectors {
+		selectors = append(selectors, selector.Type+":"+selector.Value)
+	}
+	return &entrytemplate.EntryTemplate{
+		Id:            t.ID,
+		NodeSelectors: nodeSelectors,
+		SpiffeId:      t.Entry.SpiffeId,
+		Selectors:     selectors,
+		Ttl:           t.Entry.Ttl,
+		DnsNames:      t.Entry.DnsNames,
+	}
+}
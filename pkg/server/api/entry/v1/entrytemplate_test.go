@@ -0,0 +1,162 @@
+package entry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestEntryTemplates(t *testing.T) {
+	template := &entrytemplate.EntryTemplate{
+		NodeSelectors: []string{"k8s_psat:cluster:prod"},
+		SpiffeId:      `spiffe://example.org/ns/{{ selector "k8s_psat:agent_ns" }}/sa/default`,
+		Selectors:     []string{"k8s:ns:default", "k8s:sa:default"},
+		Ttl:           60,
+		DnsNames:      []string{"example.org"},
+	}
+
+	t.Run("create, list and delete", func(t *testing.T) {
+		test := setupServiceTest(t, fakedatastore.New(t))
+		defer test.Cleanup()
+
+		createResp, err := test.templateClient.CreateEntryTemplate(ctx, &entrytemplate.CreateEntryTemplateRequest{Template: template})
+		require.NoError(t, err)
+		created := createResp.Template
+		require.NotEmpty(t, created.Id)
+		expected := &entrytemplate.EntryTemplate{
+			Id:            created.Id,
+			NodeSelectors: template.NodeSelectors,
+			SpiffeId:      template.SpiffeId,
+			Selectors:     template.Selectors,
+			Ttl:           template.Ttl,
+			DnsNames:      template.DnsNames,
+		}
+		spiretest.AssertProtoEqual(t, expected, created)
+
+		listResp, err := test.templateClient.ListEntryTemplates(ctx, &entrytemplate.ListEntryTemplatesRequest{})
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, []*entrytemplate.EntryTemplate{expected}, listResp.Templates)
+
+		deleteResp, err := test.templateClient.DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{Id: created.Id})
+		require.NoError(t, err)
+		spiretest.AssertProtoEqual(t, expected, deleteResp.Template)
+
+		listResp, err = test.templateClient.ListEntryTemplates(ctx, &entrytemplate.ListEntryTemplatesRequest{})
+		require.NoError(t, err)
+		require.Empty(t, listResp.Templates)
+
+		_, err = test.templateClient.DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{Id: created.Id})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry template not found")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		test := setupServiceTest(t, fakedatastore.New(t))
+		defer test.Cleanup()
+
+		for _, tt := range []struct {
+			name      string
+			template  *entrytemplate.EntryTemplate
+			expectErr string
+		}{
+			{
+				name:      "missing template",
+				expectErr: "missing entry template",
+			},
+			{
+				name: "malformed node selector",
+				template: &entrytemplate.EntryTemplate{
+					NodeSelectors: []string{"k8s_psat"},
+					SpiffeId:      template.SpiffeId,
+					Selectors:     template.Selectors,
+				},
+				expectErr: `failed to convert entry template: invalid node selector: "k8s_psat" is not of the form <type>:<value>`,
+			},
+			{
+				name: "malformed selector",
+				template: &entrytemplate.EntryTemplate{
+					NodeSelectors: template.NodeSelectors,
+					SpiffeId:      template.SpiffeId,
+					Selectors:     []string{":default"},
+				},
+				expectErr: `failed to convert entry template: invalid selector: ":default" is not of the form <type>:<value>`,
+			},
+			{
+				name: "missing node selectors",
+				template: &entrytemplate.EntryTemplate{
+					SpiffeId:  template.SpiffeId,
+					Selectors: template.Selectors,
+				},
+				expectErr: "invalid entry template: missing node selectors",
+			},
+			{
+				name: "SPIFFE ID in another trust domain",
+				template: &entrytemplate.EntryTemplate{
+					NodeSelectors: template.NodeSelectors,
+					SpiffeId:      "spiffe://other.org/workload",
+					Selectors:     template.Selectors,
+				},
+				expectErr: `invalid entry template: entry template rendered SPIFFE ID "spiffe://other.org/workload" outside of trust domain "example.org"`,
+			},
+		} {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := test.templateClient.CreateEntryTemplate(ctx, &entrytemplate.CreateEntryTemplateRequest{Template: tt.template})
+				spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, tt.expectErr)
+			})
+		}
+	})
+
+	t.Run("missing entry template ID", func(t *testing.T) {
+		test := setupServiceTest(t, fakedatastore.New(t))
+		defer test.Cleanup()
+
+		_, err := test.templateClient.DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing entry template ID")
+	})
+
+	t.Run("datastore failure", func(t *testing.T) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTest(t, ds)
+		defer test.Cleanup()
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err := test.templateClient.CreateEntryTemplate(ctx, &entrytemplate.CreateEntryTemplateRequest{Template: template})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to create entry template: oh no")
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err = test.templateClient.ListEntryTemplates(ctx, &entrytemplate.ListEntryTemplatesRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list entry templates: oh no")
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err = test.templateClient.DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{Id: "id"})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to delete entry template: oh no")
+	})
+
+	t.Run("not available to namespace administrators", func(t *testing.T) {
+		test := setupServiceTestWithNamespaces(t, fakedatastore.New(t), []entry.Namespace{
+			{
+				Name:       "team-a",
+				AdminIDs:   []spiffeid.ID{agentID},
+				PathPrefix: "/team-a/",
+			},
+		})
+		defer test.Cleanup()
+		test.withCallerID = true
+
+		_, err := test.templateClient.CreateEntryTemplate(ctx, &entrytemplate.CreateEntryTemplateRequest{Template: template})
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "entry templates are not available to namespace administrators")
+
+		_, err = test.templateClient.ListEntryTemplates(ctx, &entrytemplate.ListEntryTemplatesRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "entry templates are not available to namespace administrators")
+
+		_, err = test.templateClient.DeleteEntryTemplate(ctx, &entrytemplate.DeleteEntryTemplateRequest{Id: "id"})
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "entry templates are not available to namespace administrators")
+	})
+}
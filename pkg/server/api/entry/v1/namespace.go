@@ -0,0 +1,128 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+)
+
+// Namespace scopes the entries that a set of admin callers can view and
+// modify through the entry API to those with a SPIFFE ID under a path prefix.
+type Namespace struct {
+	// Name is the name of the namespace
+	Name string
+
+	// AdminIDs are the IDs of the callers scoped to the namespace. They must
+	// also be granted admin access to call the entry API.
+	AdminIDs []spiffeid.ID
+
+	// PathPrefix is the SPIFFE ID path prefix of the entries in the
+	// namespace, e.g. "/team-a/".
+	PathPrefix string
+
+	// AgentPathPrefixes are the SPIFFE ID path prefixes of the agents that
+	// entries in the namespace can be parented to, e.g.
+	// "/spire/agent/k8s_psat/team-a/". Entries can always be parented to
+	// SPIFFE IDs in the namespace, e.g. node aliases.
+	AgentPathPrefixes []string
+}
+
+// contains returns true if the SPIFFE ID of the entry is in the namespace.
+func (n *Namespace) contains(td spiffeid.TrustDomain, spiffeID string) bool {
+	id, err := spiffeid.FromString(spiffeID)
+	if err != nil || id.TrustDomain() != td {
+		return false
+	}
+	return strings.HasPrefix(id.Path(), n.PathPrefix)
+}
+
+// spiffeIDPrefix returns the prefix of the SPIFFE IDs in the namespace, used
+// to filter entries in the datastore.
+func (n *Namespace) spiffeIDPrefix(td spiffeid.TrustDomain) string {
+	return td.IDString() + n.PathPrefix
+}
+
+// canParent returns true if entries in the namespace can be parented to the
+// given SPIFFE ID.
+func (n *Namespace) canParent(td spiffeid.TrustDomain, parentID string) bool {
+	if n.contains(td, parentID) {
+		return true
+	}
+	id, err := spiffeid.FromString(parentID)
+	if err != nil || id.TrustDomain() != td {
+		return false
+	}
+	for _, prefix := range n.AgentPathPrefixes {
+		if strings.HasPrefix(id.Path(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEntry returns an error if the fields of the entry selected by the
+// mask are not allowed in the namespace. A nil mask selects every field.
+// Admin and downstream entries are never allowed, since they would grant
+// access beyond the namespace.
+func (n *Namespace) checkEntry(td spiffeid.TrustDomain, entry *common.RegistrationEntry, mask *types.EntryMask) error {
+	switch {
+	case (mask == nil || mask.SpiffeId) && !n.contains(td, entry.SpiffeId):
+		return errors.New("entry SPIFFE ID is outside of the caller namespace")
+	case (mask == nil || mask.ParentId) && !n.canParent(td, entry.ParentId):
+		return errors.New("entry parent ID is outside of the caller namespace and its agents")
+	case (mask == nil || mask.Admin) && entry.Admin:
+		return errors.New("admin entries cannot be managed by callers scoped to a namespace")
+	case (mask == nil || mask.Downstream) && entry.Downstream:
+		return errors.New("downstream entries cannot be managed by callers scoped to a namespace")
+	}
+	return nil
+}
+
+// callerNamespace returns the namespace the caller is scoped to, or nil if
+// the caller can access every entry.
+func (s *Service) callerNamespace(ctx context.Context) *Namespace {
+	callerID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return nil
+	}
+	return s.namespaces[callerID]
+}
+
+// inCallerNamespace returns true if the caller can access entries with the
+// given SPIFFE ID.
+func (s *Service) inCallerNamespace(ctx context.Context, spiffeID string) bool {
+	ns := s.callerNamespace(ctx)
+	return ns == nil || ns.contains(s.td, spiffeID)
+}
+
+// callerNamespacePrefix returns the prefix of the SPIFFE IDs of the entries
+// the caller can access, or an empty string if the caller can access every
+// entry.
+func (s *Service) callerNamespacePrefix(ctx context.Context) string {
+	if ns := s.callerNamespace(ctx); ns != nil {
+		return ns.spiffeIDPrefix(s.td)
+	}
+	return ""
+}
+
+// checkEntryInNamespace returns a non-nil status if the entry does not exist
+// or is not in the namespace. Entries outside of the namespace are reported
+// as not found so their existence is not disclosed.
+func (s *Service) checkEntryInNamespace(ctx context.Context, log logrus.FieldLogger, ns *Namespace, id string) *types.Status {
+	entry, err := s.ds.FetchRegistrationEntry(ctx, id)
+	switch {
+	case err != nil:
+		return api.MakeStatus(log, codes.Internal, "failed to fetch entry", err)
+	case entry == nil, !ns.contains(s.td, entry.SpiffeId):
+		return api.MakeStatus(log, codes.NotFound, "entry not found", nil)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package entry_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestNamespaces(t *testing.T) {
+	namespaces := []entry.Namespace{
+		{
+			Name:              "team-a",
+			AdminIDs:          []spiffeid.ID{agentID},
+			PathPrefix:        "/team-a/",
+			AgentPathPrefixes: []string{"/spire/agent/team-a/"},
+		},
+	}
+
+	setup := func(t *testing.T, withCallerID bool) (*serviceTest, *common.RegistrationEntry, *common.RegistrationEntry) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTestWithNamespaces(t, ds, namespaces)
+		t.Cleanup(test.Cleanup)
+		test.withCallerID = withCallerID
+
+		entries := createTestEntries(t, ds,
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-a/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-b/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+		)
+		return test, entries["spiffe://example.org/team-a/workload"], entries["spiffe://example.org/team-b/workload"]
+	}
+
+	t.Run("count and list are scoped", func(t *testing.T) {
+		test, inside, _ := setup(t, true)
+
+		countResp, err := test.client.CountEntries(ctx, &entryv1.CountEntriesRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(1), countResp.Count)
+
+		listResp, err := test.client.ListEntries(ctx, &entryv1.ListEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 1)
+		require.Equal(t, inside.EntryId, listResp.Entries[0].Id)
+	})
+
+	t.Run("unscoped caller sees every entry", func(t *testing.T) {
+		test, _, _ := setup(t, false)
+
+		countResp, err := test.client.CountEntries(ctx, &entryv1.CountEntriesRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), countResp.Count)
+
+		listResp, err := test.client.ListEntries(ctx, &entryv1.ListEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 2)
+	})
+
+	t.Run("get entry outside of namespace", func(t *testing.T) {
+		test, inside, outside := setup(t, true)
+
+		_, err := test.client.GetEntry(ctx, &entryv1.GetEntryRequest{Id: inside.EntryId})
+		require.NoError(t, err)
+
+		_, err = test.client.GetEntry(ctx, &entryv1.GetEntryRequest{Id: outside.EntryId})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry not found")
+	})
+
+	t.Run("create entry outside of namespace", func(t *testing.T) {
+		test, _, _ := setup(t, true)
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-a/node1"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/other"},
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+				},
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/node-alias"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/other"},
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1003"}},
+				},
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-a/node1"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-b/other"},
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 3)
+		require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code)
+		require.Equal(t, int32(codes.OK), resp.Results[1].Status.Code)
+		require.Equal(t, int32(codes.PermissionDenied), resp.Results[2].Status.Code)
+		require.Equal(t, "entry is not allowed in the caller namespace: entry SPIFFE ID is outside of the caller namespace", resp.Results[2].Status.Message)
+	})
+
+	t.Run("create entry not allowed in namespace", func(t *testing.T) {
+		test, _, _ := setup(t, true)
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-b/node1"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/other"},
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+				},
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/node-alias"},
+					Selectors: []*types.Selector{{Type: "x509pop", Value: "subject:cn:node1"}},
+				},
+				{
+					ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-a/node1"},
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/admin"},
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+					Admin:     true,
+				},
+				{
+					ParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-a/node1"},
+					SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-a/downstream"},
+					Selectors:  []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+					Downstream: true,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		for i, expectMsg := range []string{
+			"entry is not allowed in the caller namespace: entry parent ID is outside of the caller namespace and its agents",
+			"entry is not allowed in the caller namespace: entry parent ID is outside of the caller namespace and its agents",
+			"entry is not allowed in the caller namespace: admin entries cannot be managed by callers scoped to a namespace",
+			"entry is not allowed in the caller namespace: downstream entries cannot be managed by callers scoped to a namespace",
+		} {
+			require.Equal(t, int32(codes.PermissionDenied), resp.Results[i].Status.Code)
+			require.Equal(t, expectMsg, resp.Results[i].Status.Message)
+		}
+	})
+
+	t.Run("update entry outside of namespace", func(t *testing.T) {
+		test, inside, outside := setup(t, true)
+
+		resp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{Id: inside.EntryId, Ttl: 60},
+				{Id: outside.EntryId, Ttl: 60},
+			},
+			InputMask: &types.EntryMask{Ttl: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code)
+		require.Equal(t, int32(codes.NotFound), resp.Results[1].Status.Code)
+
+		// Entries cannot be moved out of the namespace
+		resp, err = test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{Id: inside.EntryId, SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/team-b/moved"}},
+			},
+			InputMask: &types.EntryMask{SpiffeId: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		require.Equal(t, int32(codes.PermissionDenied), resp.Results[0].Status.Code)
+
+		// Entries cannot be made admin, downstream or be reparented outside
+		// of the namespace
+		for _, update := range []struct {
+			entry *types.Entry
+			mask  *types.EntryMask
+		}{
+			{entry: &types.Entry{Id: inside.EntryId, Admin: true}, mask: &types.EntryMask{Admin: true}},
+			{entry: &types.Entry{Id: inside.EntryId, Downstream: true}, mask: &types.EntryMask{Downstream: true}},
+			{
+				entry: &types.Entry{Id: inside.EntryId, ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/team-b/node1"}},
+				mask:  &types.EntryMask{ParentId: true},
+			},
+		} {
+			resp, err = test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+				Entries:   []*types.Entry{update.entry},
+				InputMask: update.mask,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.Results, 1)
+			require.Equal(t, int32(codes.PermissionDenied), resp.Results[0].Status.Code)
+		}
+	})
+
+	t.Run("delete entry outside of namespace", func(t *testing.T) {
+		test, inside, outside := setup(t, true)
+
+		resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
+			Ids: []string{inside.EntryId, outside.EntryId},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code)
+		require.Equal(t, int32(codes.NotFound), resp.Results[1].Status.Code)
+
+		entry, err := test.ds.FetchRegistrationEntry(ctx, outside.EntryId)
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+	})
+}
@@ -0,0 +1,122 @@
+package entry
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListDeletedEntries returns the deleted entries that can still be restored.
+// Entries are only kept after being deleted when the datastore is configured
+// with a deleted entry retention.
+func (s *Service) ListDeletedEntries(ctx context.Context, req *entryrestore.ListDeletedEntriesRequest) (*entryrestore.ListDeletedEntriesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	deleted, err := s.listDeletedEntries(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list deleted entries", err)
+	}
+
+	resp := &entryrestore.ListDeletedEntriesResponse{}
+	for _, d := range deleted {
+		resp.Entries = append(resp.Entries, &entryrestore.DeletedEntry{
+			Entry:     restoreEntryToProto(d.Entry),
+			DeletedAt: d.DeletedAt.Unix(),
+			ExpiresAt: d.ExpiresAt.Unix(),
+		})
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// RestoreEntry restores a deleted entry with its original entry ID.
+func (s *Service) RestoreEntry(ctx context.Context, req *entryrestore.RestoreEntryRequest) (*entryrestore.RestoreEntryResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Id == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing entry ID", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.RegistrationID: req.Id})
+	log = log.WithField(telemetry.RegistrationID, req.Id)
+
+	if s.callerNamespace(ctx) != nil {
+		// Deleted entries outside of the namespace are reported as not
+		// found so their existence is not disclosed
+		deleted, err := s.listDeletedEntries(ctx)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "failed to list deleted entries", err)
+		}
+		if !containsDeletedEntry(deleted, req.Id) {
+			return nil, api.MakeErr(log, codes.NotFound, "deleted entry not found", nil)
+		}
+	}
+
+	entry, err := s.ds.RestoreRegistrationEntry(ctx, req.Id)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "deleted entry not found", nil)
+	case codes.AlreadyExists:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "failed to restore entry", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to restore entry", err)
+	}
+
+	log.WithField(telemetry.SPIFFEID, entry.SpiffeId).Info("Registration entry restored")
+	rpccontext.AuditRPC(ctx)
+
+	return &entryrestore.RestoreEntryResponse{
+		Entry: restoreEntryToProto(entry),
+	}, nil
+}
+
+// listDeletedEntries lists the deleted entries the caller can access.
+func (s *Service) listDeletedEntries(ctx context.Context) ([]*datastore.DeletedRegistrationEntry, error) {
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := s.callerNamespace(ctx)
+	if ns == nil {
+		return deleted, nil
+	}
+	filtered := deleted[:0]
+	for _, d := range deleted {
+		if ns.contains(s.td, d.Entry.SpiffeId) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered, nil
+}
+
+func containsDeletedEntry(deleted []*datastore.DeletedRegistrationEntry, id string) bool {
+	for _, d := range deleted {
+		if d.Entry.EntryId == id {
+			return true
+		}
+	}
+	return false
+}
+
+func restoreEntryToProto(entry *common.RegistrationEntry) *entryrestore.Entry {
+	selectors := make([]string, 0, len(entry.Selectors))
+	for _, selector := range entry.Selectors {
+		selectors = append(selectors, selector.Type+":"+selector.Value)
+	}
+	return &entryrestore.Entry{
+		Id:        entry.EntryId,
+		SpiffeId:  entry.SpiffeId,
+		ParentId:  entry.ParentId,
+		Selectors: selectors,
+	}
+}
@@ -0,0 +1,140 @@
+package entry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire/pkg/server/api/entry/v1"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRestoreEntry(t *testing.T) {
+	setup := func(t *testing.T, namespaces []entry.Namespace) (*serviceTest, *fakedatastore.DataStore, map[string]*common.RegistrationEntry) {
+		ds := fakedatastore.New(t)
+		test := setupServiceTestWithNamespaces(t, ds, namespaces)
+		t.Cleanup(test.Cleanup)
+
+		entries := createTestEntries(t, ds,
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-a/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			&common.RegistrationEntry{
+				ParentId:  "spiffe://example.org/parent",
+				SpiffeId:  "spiffe://example.org/team-b/workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+		)
+
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.EntryId)
+		}
+		resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+		require.NoError(t, err)
+		for _, result := range resp.Results {
+			require.Equal(t, int32(codes.OK), result.Status.Code)
+		}
+		return test, ds, entries
+	}
+
+	t.Run("list and restore", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+		deleted := entries["spiffe://example.org/team-a/workload"]
+
+		listResp, err := test.restoreClient.ListDeletedEntries(ctx, &entryrestore.ListDeletedEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 2)
+		for _, d := range listResp.Entries {
+			require.Greater(t, d.ExpiresAt, d.DeletedAt)
+		}
+
+		restoreResp, err := test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: deleted.EntryId})
+		require.NoError(t, err)
+		spiretest.AssertProtoEqual(t, &entryrestore.Entry{
+			Id:        deleted.EntryId,
+			SpiffeId:  "spiffe://example.org/team-a/workload",
+			ParentId:  "spiffe://example.org/parent",
+			Selectors: []string{"unix:uid:1000"},
+		}, restoreResp.Entry)
+
+		restored, err := ds.FetchRegistrationEntry(ctx, deleted.EntryId)
+		require.NoError(t, err)
+		require.NotNil(t, restored)
+
+		listResp, err = test.restoreClient.ListDeletedEntries(ctx, &entryrestore.ListDeletedEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 1)
+		require.Equal(t, "spiffe://example.org/team-b/workload", listResp.Entries[0].Entry.SpiffeId)
+	})
+
+	t.Run("missing entry ID", func(t *testing.T) {
+		test, _, _ := setup(t, nil)
+
+		_, err := test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "missing entry ID")
+	})
+
+	t.Run("unknown entry", func(t *testing.T) {
+		test, _, _ := setup(t, nil)
+
+		_, err := test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: "unknown"})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "deleted entry not found")
+	})
+
+	t.Run("similar entry exists", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+		deleted := entries["spiffe://example.org/team-a/workload"]
+
+		similar := createTestEntries(t, ds, &common.RegistrationEntry{
+			ParentId:  deleted.ParentId,
+			SpiffeId:  deleted.SpiffeId,
+			Selectors: deleted.Selectors,
+		})[deleted.SpiffeId]
+
+		_, err := test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: deleted.EntryId})
+		spiretest.RequireGRPCStatusContains(t, err, codes.AlreadyExists, similar.EntryId)
+	})
+
+	t.Run("datastore failure", func(t *testing.T) {
+		test, ds, entries := setup(t, nil)
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err := test.restoreClient.ListDeletedEntries(ctx, &entryrestore.ListDeletedEntriesRequest{})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list deleted entries: oh no")
+
+		ds.SetNextError(errors.New("oh no"))
+		_, err = test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: entries["spiffe://example.org/team-a/workload"].EntryId})
+		spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to restore entry: oh no")
+	})
+
+	t.Run("scoped to the caller namespace", func(t *testing.T) {
+		test, _, entries := setup(t, []entry.Namespace{
+			{
+				Name:       "team-a",
+				AdminIDs:   []spiffeid.ID{agentID},
+				PathPrefix: "/team-a/",
+			},
+		})
+		test.withCallerID = true
+
+		listResp, err := test.restoreClient.ListDeletedEntries(ctx, &entryrestore.ListDeletedEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 1)
+		require.Equal(t, "spiffe://example.org/team-a/workload", listResp.Entries[0].Entry.SpiffeId)
+
+		_, err = test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: entries["spiffe://example.org/team-b/workload"].EntryId})
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, "deleted entry not found")
+
+		_, err = test.restoreClient.RestoreEntry(ctx, &entryrestore.RestoreEntryRequest{Id: entries["spiffe://example.org/team-a/workload"].EntryId})
+		require.NoError(t, err)
+	})
+}
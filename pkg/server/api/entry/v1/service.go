@@ -9,13 +9,20 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -24,34 +31,67 @@ type Config struct {
 	TrustDomain  spiffeid.TrustDomain
 	EntryFetcher api.AuthorizedEntryFetcher
 	DataStore    datastore.DataStore
+
+	// Namespaces scope the entries that admin callers can access. Callers
+	// not scoped to a namespace can access every entry.
+	Namespaces []Namespace
+
+	// IDPathPolicy, if set, restricts the SPIFFE IDs of the entries that can
+	// be created or updated.
+	IDPathPolicy *api.IDPathPolicy
 }
 
 // Service defines the v1 entry service.
 type Service struct {
 	entryv1.UnsafeEntryServer
+	count.UnsafeEntryCountServer
+	entryrestore.UnsafeEntryRestoreServer
+	entrytemplate.UnsafeEntryTemplatesServer
+	entrysync.UnsafeEntrySyncServer
+	entrydnsnames.UnsafeEntryDNSNamesServer
 
 	td spiffeid.TrustDomain
 	ds datastore.DataStore
 	ef api.AuthorizedEntryFetcher
+
+	namespaces map[spiffeid.ID]*Namespace
+	idPolicy   *api.IDPathPolicy
 }
 
 // New creates a new v1 entry service.
 func New(config Config) *Service {
+	namespaces := make(map[spiffeid.ID]*Namespace)
+	for i := range config.Namespaces {
+		ns := &config.Namespaces[i]
+		for _, adminID := range ns.AdminIDs {
+			namespaces[adminID] = ns
+		}
+	}
+
 	return &Service{
-		td: config.TrustDomain,
-		ds: config.DataStore,
-		ef: config.EntryFetcher,
+		td:         config.TrustDomain,
+		ds:         config.DataStore,
+		ef:         config.EntryFetcher,
+		namespaces: namespaces,
+		idPolicy:   config.IDPathPolicy,
 	}
 }
 
 // RegisterService registers the entry service on the gRPC server.
 func RegisterService(s *grpc.Server, service *Service) {
 	entryv1.RegisterEntryServer(s, service)
+	count.RegisterEntryCountServer(s, service)
+	entryrestore.RegisterEntryRestoreServer(s, service)
+	entrytemplate.RegisterEntryTemplatesServer(s, service)
+	entrysync.RegisterEntrySyncServer(s, service)
+	entrydnsnames.RegisterEntryDNSNamesServer(s, service)
 }
 
 // CountEntries returns the total number of entries.
 func (s *Service) CountEntries(ctx context.Context, req *entryv1.CountEntriesRequest) (*entryv1.CountEntriesResponse, error) {
-	count, err := s.ds.CountRegistrationEntries(ctx)
+	count, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{
+		BySpiffeIDPrefix: s.callerNamespacePrefix(ctx),
+	})
 	if err != nil {
 		log := rpccontext.Logger(ctx)
 		return nil, api.MakeErr(log, codes.Internal, "failed to count entries", err)
@@ -65,7 +105,9 @@ func (s *Service) CountEntries(ctx context.Context, req *entryv1.CountEntriesReq
 func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesRequest) (*entryv1.ListEntriesResponse, error) {
 	log := rpccontext.Logger(ctx)
 
-	listReq := &datastore.ListRegistrationEntriesRequest{}
+	listReq := &datastore.ListRegistrationEntriesRequest{
+		BySpiffeIDPrefix: s.callerNamespacePrefix(ctx),
+	}
 
 	if req.PageSize > 0 {
 		listReq.Pagination = &datastore.Pagination{
@@ -76,53 +118,8 @@ func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesReque
 
 	if req.Filter != nil {
 		rpccontext.AddRPCAuditFields(ctx, fieldsFromListEntryFilter(ctx, s.td, req.Filter))
-
-		if req.Filter.ByParentId != nil {
-			parentID, err := api.TrustDomainMemberIDFromProto(ctx, s.td, req.Filter.ByParentId)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed parent ID filter", err)
-			}
-			listReq.ByParentID = parentID.String()
-		}
-
-		if req.Filter.BySpiffeId != nil {
-			spiffeID, err := api.TrustDomainWorkloadIDFromProto(ctx, s.td, req.Filter.BySpiffeId)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed SPIFFE ID filter", err)
-			}
-			listReq.BySpiffeID = spiffeID.String()
-		}
-
-		if req.Filter.BySelectors != nil {
-			dsSelectors, err := api.SelectorsFromProto(req.Filter.BySelectors.Selectors)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", err)
-			}
-			if len(dsSelectors) == 0 {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", errors.New("empty selector set"))
-			}
-			listReq.BySelectors = &datastore.BySelectors{
-				Match:     datastore.MatchBehavior(req.Filter.BySelectors.Match),
-				Selectors: dsSelectors,
-			}
-		}
-
-		if req.Filter.ByFederatesWith != nil {
-			trustDomains := make([]string, 0, len(req.Filter.ByFederatesWith.TrustDomains))
-			for _, tdStr := range req.Filter.ByFederatesWith.TrustDomains {
-				td, err := spiffeid.TrustDomainFromString(tdStr)
-				if err != nil {
-					return nil, api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", err)
-				}
-				trustDomains = append(trustDomains, td.IDString())
-			}
-			if len(trustDomains) == 0 {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", errors.New("empty trust domain set"))
-			}
-			listReq.ByFederatesWith = &datastore.ByFederatesWith{
-				Match:        datastore.MatchBehavior(req.Filter.ByFederatesWith.Match),
-				TrustDomains: trustDomains,
-			}
+		if err := s.applyListEntriesFilter(ctx, log, req.Filter, listReq); err != nil {
+			return nil, err
 		}
 	}
 
@@ -150,6 +147,60 @@ func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesReque
 	return resp, nil
 }
 
+// applyListEntriesFilter sets the datastore filters that correspond to the
+// given ListEntries filter.
+func (s *Service) applyListEntriesFilter(ctx context.Context, log logrus.FieldLogger, filter *entryv1.ListEntriesRequest_Filter, listReq *datastore.ListRegistrationEntriesRequest) error {
+	if filter.ByParentId != nil {
+		parentID, err := api.TrustDomainMemberIDFromProto(ctx, s.td, filter.ByParentId)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed parent ID filter", err)
+		}
+		listReq.ByParentID = parentID.String()
+	}
+
+	if filter.BySpiffeId != nil {
+		spiffeID, err := api.TrustDomainWorkloadIDFromProto(ctx, s.td, filter.BySpiffeId)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed SPIFFE ID filter", err)
+		}
+		listReq.BySpiffeID = spiffeID.String()
+	}
+
+	if filter.BySelectors != nil {
+		dsSelectors, err := api.SelectorsFromProto(filter.BySelectors.Selectors)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", err)
+		}
+		if len(dsSelectors) == 0 {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", errors.New("empty selector set"))
+		}
+		listReq.BySelectors = &datastore.BySelectors{
+			Match:     datastore.MatchBehavior(filter.BySelectors.Match),
+			Selectors: dsSelectors,
+		}
+	}
+
+	if filter.ByFederatesWith != nil {
+		trustDomains := make([]string, 0, len(filter.ByFederatesWith.TrustDomains))
+		for _, tdStr := range filter.ByFederatesWith.TrustDomains {
+			td, err := spiffeid.TrustDomainFromString(tdStr)
+			if err != nil {
+				return api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", err)
+			}
+			trustDomains = append(trustDomains, td.IDString())
+		}
+		if len(trustDomains) == 0 {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", errors.New("empty trust domain set"))
+		}
+		listReq.ByFederatesWith = &datastore.ByFederatesWith{
+			Match:        datastore.MatchBehavior(filter.ByFederatesWith.Match),
+			TrustDomains: trustDomains,
+		}
+	}
+
+	return nil
+}
+
 // GetEntry returns the registration entry associated with the given SpiffeID
 func (s *Service) GetEntry(ctx context.Context, req *entryv1.GetEntryRequest) (*types.Entry, error) {
 	log := rpccontext.Logger(ctx)
@@ -164,8 +215,8 @@ func (s *Service) GetEntry(ctx context.Context, req *entryv1.GetEntryRequest) (*
 		return nil, api.MakeErr(log, codes.Internal, "failed to fetch entry", err)
 	}
 
-	if registrationEntry == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "entry not found", nil)
+	if registrationEntry == nil || !s.inCallerNamespace(ctx, registrationEntry.SpiffeId) {
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonEntryNotFound, "entry not found", nil)
 	}
 
 	entry, err := api.RegistrationEntryToProto(registrationEntry)
@@ -206,6 +257,20 @@ func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *t
 
 	log = log.WithField(telemetry.SPIFFEID, cEntry.SpiffeId)
 
+	if ns := s.callerNamespace(ctx); ns != nil {
+		if err := ns.checkEntry(s.td, cEntry, nil); err != nil {
+			return &entryv1.BatchCreateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.PermissionDenied, "entry is not allowed in the caller namespace", err),
+			}
+		}
+	}
+
+	if err := s.checkIDPathPolicy(cEntry.SpiffeId); err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "entry SPIFFE ID is not allowed", err),
+		}
+	}
+
 	resultStatus := api.OK()
 	regEntry, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, cEntry)
 	switch {
@@ -277,6 +342,15 @@ func (s *Service) deleteEntry(ctx context.Context, id string) *entryv1.BatchDele
 
 	log = log.WithField(telemetry.RegistrationID, id)
 
+	if ns := s.callerNamespace(ctx); ns != nil {
+		if st := s.checkEntryInNamespace(ctx, log, ns, id); st != nil {
+			return &entryv1.BatchDeleteEntryResponse_Result{
+				Id:     id,
+				Status: st,
+			}
+		}
+	}
+
 	_, err := s.ds.DeleteRegistrationEntry(ctx, id)
 	switch status.Code(err) {
 	case codes.OK:
@@ -310,6 +384,8 @@ func (s *Service) GetAuthorizedEntries(ctx context.Context, req *entryv1.GetAuth
 		entries[i] = entry
 	}
 
+	s.maybeRequestAgentRenewal(ctx, log)
+
 	resp := &entryv1.GetAuthorizedEntriesResponse{
 		Entries: entries,
 	}
@@ -333,6 +409,49 @@ func (s *Service) fetchEntries(ctx context.Context, log logrus.FieldLogger) ([]*
 	return entries, nil
 }
 
+// maybeRequestAgentRenewal asks the calling agent to renew its agent SVID
+// when an administrator requested the renewal of the SVID the agent is
+// calling with. Failures are logged but do not fail the request, since the
+// agent is asked again on its next sync.
+func (s *Service) maybeRequestAgentRenewal(ctx context.Context, log logrus.FieldLogger) {
+	serialNumber, ok := s.agentRenewalRequested(ctx, log)
+	if !ok {
+		return
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(nodeutil.RenewAgentSVIDHeader, "true")); err != nil {
+		log.WithError(err).Warn("Failed to request agent renewal")
+		return
+	}
+	log.WithField(telemetry.SerialNumber, serialNumber).Debug("Requested agent to renew its SVID")
+}
+
+// agentRenewalRequested returns the serial number of the SVID the calling
+// agent is calling with, and whether an administrator requested its renewal.
+func (s *Service) agentRenewalRequested(ctx context.Context, log logrus.FieldLogger) (string, bool) {
+	if !rpccontext.CallerIsAgent(ctx) {
+		return "", false
+	}
+	callerID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return "", false
+	}
+	svid, ok := rpccontext.CallerX509SVID(ctx)
+	if !ok {
+		return "", false
+	}
+
+	renewal, err := s.ds.FetchAgentRenewal(ctx, callerID.String())
+	switch {
+	case err != nil:
+		log.WithError(err).Warn("Failed to fetch agent renewal")
+		return "", false
+	case renewal == nil || renewal.SerialNumber != svid.SerialNumber.String():
+		return "", false
+	}
+	return renewal.SerialNumber, true
+}
+
 func applyMask(e *types.Entry, mask *types.EntryMask) {
 	if mask == nil {
 		return
@@ -394,23 +513,51 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 		}
 	}
 
+	if ns := s.callerNamespace(ctx); ns != nil {
+		if st := s.checkEntryInNamespace(ctx, log, ns, convEntry.EntryId); st != nil {
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: st,
+			}
+		}
+		if err := ns.checkEntry(s.td, convEntry, inputMask); err != nil {
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.PermissionDenied, "entry is not allowed in the caller namespace", err),
+			}
+		}
+	}
+
+	if inputMask == nil || inputMask.SpiffeId {
+		if err := s.checkIDPathPolicy(convEntry.SpiffeId); err != nil {
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.InvalidArgument, "entry SPIFFE ID is not allowed", err),
+			}
+		}
+	}
+
 	var mask *common.RegistrationEntryMask
 	if inputMask != nil {
 		mask = &common.RegistrationEntryMask{
-			SpiffeId:      inputMask.SpiffeId,
-			ParentId:      inputMask.ParentId,
-			Ttl:           inputMask.Ttl,
-			FederatesWith: inputMask.FederatesWith,
-			Admin:         inputMask.Admin,
-			Downstream:    inputMask.Downstream,
-			EntryExpiry:   inputMask.ExpiresAt,
-			DnsNames:      inputMask.DnsNames,
-			Selectors:     inputMask.Selectors,
-			StoreSvid:     inputMask.StoreSvid,
+			SpiffeId:       inputMask.SpiffeId,
+			ParentId:       inputMask.ParentId,
+			Ttl:            inputMask.Ttl,
+			FederatesWith:  inputMask.FederatesWith,
+			Admin:          inputMask.Admin,
+			Downstream:     inputMask.Downstream,
+			EntryExpiry:    inputMask.ExpiresAt,
+			DnsNames:       inputMask.DnsNames,
+			Selectors:      inputMask.Selectors,
+			StoreSvid:      inputMask.StoreSvid,
+			RevisionNumber: inputMask.RevisionNumber,
 		}
 	}
 	dsEntry, err := s.ds.UpdateRegistrationEntry(ctx, convEntry, mask)
-	if err != nil {
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.FailedPrecondition:
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.FailedPrecondition, "entry revision number is stale", err),
+		}
+	default:
 		return &entryv1.BatchUpdateEntryResponse_Result{
 			Status: api.MakeStatus(log, codes.Internal, "failed to update entry", err),
 		}
@@ -528,3 +675,16 @@ func fieldsFromListEntryFilter(ctx context.Context, td spiffeid.TrustDomain, fil
 
 	return fields
 }
+
+// checkIDPathPolicy returns an error if the entry SPIFFE ID is not allowed by
+// the SPIFFE ID path policy
+func (s *Service) checkIDPathPolicy(spiffeID string) error {
+	if s.idPolicy == nil {
+		return nil
+	}
+	id, err := spiffeid.FromString(spiffeID)
+	if err != nil {
+		return err
+	}
+	return s.idPolicy.Check(id)
+}
@@ -2,9 +2,12 @@ package entry_test
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,12 +16,18 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/entry/v1"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/spiretest"
@@ -26,6 +35,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
@@ -2545,6 +2555,55 @@ func TestGetAuthorizedEntries(t *testing.T) {
 	}
 }
 
+func TestGetAuthorizedEntriesAgentRenewal(t *testing.T) {
+	agentSVID := &x509.Certificate{SerialNumber: big.NewInt(1)}
+
+	for _, tt := range []struct {
+		name         string
+		renewal      *datastore.AgentRenewal
+		agentSVID    *x509.Certificate
+		expectHeader []string
+	}{
+		{
+			name:      "no renewal requested",
+			agentSVID: agentSVID,
+		},
+		{
+			name:         "renewal requested",
+			renewal:      &datastore.AgentRenewal{SpiffeID: agentID.String(), SerialNumber: "1"},
+			agentSVID:    agentSVID,
+			expectHeader: []string{"true"},
+		},
+		{
+			name:      "renewal requested for another SVID",
+			renewal:   &datastore.AgentRenewal{SpiffeID: agentID.String(), SerialNumber: "2"},
+			agentSVID: agentSVID,
+		},
+		{
+			name:    "caller is not an agent",
+			renewal: &datastore.AgentRenewal{SpiffeID: agentID.String(), SerialNumber: "1"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			if tt.renewal != nil {
+				require.NoError(t, ds.SetAgentRenewal(ctx, tt.renewal))
+			}
+
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+			test.withCallerID = true
+			test.agentSVID = tt.agentSVID
+
+			var header metadata.MD
+			_, err := test.client.GetAuthorizedEntries(ctx, &entryv1.GetAuthorizedEntriesRequest{}, grpc.Header(&header))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectHeader, header.Get(nodeutil.RenewAgentSVIDHeader))
+		})
+	}
+}
+
 func createFederatedBundles(t *testing.T, ds datastore.DataStore) {
 	_, err := ds.CreateBundle(ctx, &common.Bundle{
 		TrustDomainId: federatedTd.IDString(),
@@ -2580,12 +2639,18 @@ func createTestEntries(t *testing.T, ds datastore.DataStore, entry ...*common.Re
 }
 
 type serviceTest struct {
-	client       entryv1.EntryClient
-	ef           *entryFetcher
-	done         func()
-	ds           datastore.DataStore
-	logHook      *test.Hook
-	withCallerID bool
+	client         entryv1.EntryClient
+	countClient    count.EntryCountClient
+	dnsNamesClient entrydnsnames.EntryDNSNamesClient
+	restoreClient  entryrestore.EntryRestoreClient
+	syncClient     entrysync.EntrySyncClient
+	templateClient entrytemplate.EntryTemplatesClient
+	ef             *entryFetcher
+	done           func()
+	ds             datastore.DataStore
+	logHook        *test.Hook
+	withCallerID   bool
+	agentSVID      *x509.Certificate
 }
 
 func (s *serviceTest) Cleanup() {
@@ -2593,12 +2658,24 @@ func (s *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
+	return setupServiceTestWithNamespaces(t, ds, nil)
+}
+
+func setupServiceTestWithNamespaces(t *testing.T, ds datastore.DataStore, namespaces []entry.Namespace) *serviceTest {
+	return setupServiceTestWithConfig(t, ds, func(c *entry.Config) {
+		c.Namespaces = namespaces
+	})
+}
+
+func setupServiceTestWithConfig(t *testing.T, ds datastore.DataStore, configure func(*entry.Config)) *serviceTest {
 	ef := &entryFetcher{}
-	service := entry.New(entry.Config{
+	config := entry.Config{
 		TrustDomain:  td,
 		DataStore:    ds,
 		EntryFetcher: ef,
-	})
+	}
+	configure(&config)
+	service := entry.New(config)
 
 	log, logHook := test.NewNullLogger()
 	registerFn := func(s *grpc.Server) {
@@ -2616,6 +2693,10 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
 		if test.withCallerID {
 			ctx = rpccontext.WithCallerID(ctx, agentID)
 		}
+		if test.agentSVID != nil {
+			ctx = rpccontext.WithAgentCaller(ctx)
+			ctx = rpccontext.WithCallerX509SVID(ctx, test.agentSVID)
+		}
 		return ctx, nil
 	})
 
@@ -2632,10 +2713,77 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
 	conn, done := spiretest.NewAPIServerWithMiddleware(t, registerFn, server)
 	test.done = done
 	test.client = entryv1.NewEntryClient(conn)
+	test.countClient = count.NewEntryCountClient(conn)
+	test.dnsNamesClient = entrydnsnames.NewEntryDNSNamesClient(conn)
+	test.restoreClient = entryrestore.NewEntryRestoreClient(conn)
+	test.syncClient = entrysync.NewEntrySyncClient(conn)
+	test.templateClient = entrytemplate.NewEntryTemplatesClient(conn)
 
 	return test
 }
 
+func TestIDPathPolicy(t *testing.T) {
+	policy, err := api.NewIDPathPolicy([]string{"/ns/"}, nil)
+	require.NoError(t, err)
+
+	ds := fakedatastore.New(t)
+	test := setupServiceTestWithConfig(t, ds, func(c *entry.Config) {
+		c.IDPathPolicy = policy
+	})
+	defer test.Cleanup()
+
+	entries := createTestEntries(t, ds,
+		&common.RegistrationEntry{
+			ParentId:  "spiffe://example.org/parent",
+			SpiffeId:  "spiffe://example.org/ns/foo/workload",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+	)
+	existing := entries["spiffe://example.org/ns/foo/workload"]
+
+	createResp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+		Entries: []*types.Entry{
+			{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/foo/other"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+			{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/other"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, createResp.Results, 2)
+	require.Equal(t, int32(codes.OK), createResp.Results[0].Status.Code)
+	require.Equal(t, int32(codes.InvalidArgument), createResp.Results[1].Status.Code)
+	require.Equal(t, `entry SPIFFE ID is not allowed: path "/other" is not allowed by the SPIFFE ID path policy`, createResp.Results[1].Status.Message)
+
+	updateResp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+		Entries: []*types.Entry{
+			{Id: existing.EntryId, SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/other"}},
+		},
+		InputMask: &types.EntryMask{SpiffeId: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, updateResp.Results, 1)
+	require.Equal(t, int32(codes.InvalidArgument), updateResp.Results[0].Status.Code)
+	require.Equal(t, `entry SPIFFE ID is not allowed: path "/other" is not allowed by the SPIFFE ID path policy`, updateResp.Results[0].Status.Message)
+
+	// Updates that do not change the SPIFFE ID are not checked
+	updateResp, err = test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+		Entries: []*types.Entry{
+			{Id: existing.EntryId, Admin: true},
+		},
+		InputMask: &types.EntryMask{Admin: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, updateResp.Results, 1)
+	require.Equal(t, int32(codes.OK), updateResp.Results[0].Status.Code)
+}
+
 func TestBatchUpdateEntry(t *testing.T) {
 	parent := &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"}
 	entry1SpiffeID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"}
@@ -3534,6 +3682,107 @@ func TestBatchUpdateEntry(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Success Update TTL With Current Revision Number",
+			initialEntries: []*types.Entry{initialEntry},
+			inputMask: &types.EntryMask{
+				Ttl:            true,
+				RevisionNumber: true,
+			},
+			outputMask: &types.EntryMask{
+				Ttl:            true,
+				RevisionNumber: true,
+			},
+			updateEntries: []*types.Entry{
+				{
+					Ttl:            1000,
+					RevisionNumber: 0,
+				},
+			},
+			expectDsEntries: func(id string) []*types.Entry {
+				modifiedEntry := proto.Clone(initialEntry).(*types.Entry)
+				modifiedEntry.Id = id
+				modifiedEntry.Ttl = 1000
+				modifiedEntry.RevisionNumber = 1
+				return []*types.Entry{modifiedEntry}
+			},
+			expectResults: []*entryv1.BatchUpdateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+					Entry: &types.Entry{
+						Ttl:            1000,
+						RevisionNumber: 1,
+					},
+				},
+			},
+			expectLogs: func(m map[string]string) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "success",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: m[entry1SpiffeID.Path],
+							telemetry.TTL:            "1000",
+							telemetry.RevisionNumber: "0",
+						},
+					},
+				}
+			},
+		},
+		{
+			name:           "Fail Stale Revision Number",
+			initialEntries: []*types.Entry{initialEntry},
+			inputMask: &types.EntryMask{
+				Ttl:            true,
+				RevisionNumber: true,
+			},
+			updateEntries: []*types.Entry{
+				{
+					Ttl:            1000,
+					RevisionNumber: 5,
+				},
+			},
+			expectDsEntries: func(id string) []*types.Entry {
+				modifiedEntry := proto.Clone(initialEntry).(*types.Entry)
+				modifiedEntry.Id = id
+				return []*types.Entry{modifiedEntry}
+			},
+			expectResults: []*entryv1.BatchUpdateEntryResponse_Result{
+				{
+					Status: &types.Status{
+						Code:    int32(codes.FailedPrecondition),
+						Message: "entry revision number is stale: datastore-sql: entry revision number is 0, not 5",
+					},
+				},
+			},
+			expectLogs: func(m map[string]string) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Entry revision number is stale",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: m[entry1SpiffeID.Path],
+							logrus.ErrorKey:          "rpc error: code = FailedPrecondition desc = datastore-sql: entry revision number is 0, not 5",
+						},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: m[entry1SpiffeID.Path],
+							telemetry.StatusCode:     "FailedPrecondition",
+							telemetry.StatusMessage:  "entry revision number is stale: datastore-sql: entry revision number is 0, not 5",
+							telemetry.TTL:            "1000",
+							telemetry.RevisionNumber: "5",
+						},
+					},
+				}
+			},
+		},
 		{
 			name:           "Fail Datastore Error",
 			initialEntries: []*types.Entry{initialEntry},
@@ -3881,9 +4130,15 @@ func (f *fakeDS) CreateOrReturnRegistrationEntry(ctx context.Context, entry *com
 type entryFetcher struct {
 	err     string
 	entries []*types.Entry
+
+	mu      sync.Mutex
+	changed chan struct{}
 }
 
 func (f *entryFetcher) FetchAuthorizedEntries(ctx context.Context, agentID spiffeid.ID) ([]*types.Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	if f.err != "" {
 		return nil, status.Error(codes.Internal, f.err)
 	}
@@ -3899,3 +4154,25 @@ func (f *entryFetcher) FetchAuthorizedEntries(ctx context.Context, agentID spiff
 
 	return f.entries, nil
 }
+
+func (f *entryFetcher) AuthorizedEntriesChanged() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.changed == nil {
+		f.changed = make(chan struct{})
+	}
+	return f.changed
+}
+
+// setEntries replaces the authorized entries and notifies the change
+func (f *entryFetcher) setEntries(entries []*types.Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.entries = entries
+	if f.changed != nil {
+		close(f.changed)
+		f.changed = nil
+	}
+}
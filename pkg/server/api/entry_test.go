@@ -145,8 +145,8 @@ func TestProtoToRegistrationEntryWithMask(t *testing.T) {
 				SpiffeId: "spiffe://example.org/bar",
 				Ttl:      60,
 				Selectors: []*common.Selector{
-					{Type: "unix", Value: "uid:1000"},
 					{Type: "unix", Value: "gid:1000"},
+					{Type: "unix", Value: "uid:1000"},
 				},
 				FederatesWith: []string{
 					"spiffe://domain1.com",
@@ -160,6 +160,29 @@ func TestProtoToRegistrationEntryWithMask(t *testing.T) {
 			},
 			mask: protoutil.AllTrueEntryMask,
 		},
+		{
+			name: "DNS name template",
+			entry: &types.Entry{
+				Id:        "entry1",
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:payments"}},
+				DnsNames:  []string{"{{ .PodName }}.{{ .Namespace }}.svc"},
+			},
+			expectEntry: &common.RegistrationEntry{
+				EntryId:   "entry1",
+				ParentId:  "spiffe://example.org/foo",
+				SpiffeId:  "spiffe://example.org/bar",
+				Selectors: []*common.Selector{{Type: "k8s", Value: "ns:payments"}},
+				DnsNames:  []string{"{{ .PodName }}.{{ .Namespace }}.svc"},
+			},
+			mask: &types.EntryMask{
+				SpiffeId:  true,
+				ParentId:  true,
+				Selectors: true,
+				DnsNames:  true,
+			},
+		},
 		{
 			name: "mask off all fields",
 			entry: &types.Entry{
@@ -236,8 +259,8 @@ func TestProtoToRegistrationEntry(t *testing.T) {
 				SpiffeId: "spiffe://example.org/bar",
 				Ttl:      60,
 				Selectors: []*common.Selector{
-					{Type: "unix", Value: "uid:1000"},
 					{Type: "unix", Value: "gid:1000"},
+					{Type: "unix", Value: "uid:1000"},
 				},
 				FederatesWith: []string{
 					"spiffe://domain1.com",
@@ -294,6 +317,16 @@ func TestProtoToRegistrationEntry(t *testing.T) {
 				DnsNames:  []string{"abc-"},
 			},
 		},
+		{
+			name: "invalid DNS name template",
+			err:  "invalid DNS name: invalid DNS name template: template: dns:1: unclosed action",
+			entry: &types.Entry{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+				DnsNames:  []string{"{{ .PodName .svc"},
+			},
+		},
 		{
 			name: "malformed federated trust domain",
 			err:  "invalid federated trust domain: trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores",
@@ -337,6 +370,17 @@ func TestProtoToRegistrationEntry(t *testing.T) {
 			},
 			err: "missing selector value",
 		},
+		{
+			name: "unknown selector of a built-in attestor",
+			entry: &types.Entry{
+				ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/bar"},
+				Selectors: []*types.Selector{
+					{Type: "k8s", Value: "serviceaccount:default"},
+				},
+			},
+			err: `invalid selector k8s:serviceaccount:default: "serviceaccount" is not a known k8s selector`,
+		},
 		{
 			name: "no selectors",
 			entry: &types.Entry{
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// EntryTemplateData is the data available to the SPIFFE ID and the selector
+// values of entry templates. It is populated from the agent the template is
+// expanded for. Any agent selector can be referenced with the selector
+// function, which takes the selector type and key, e.g.
+// {{ selector "k8s_psat:agent_ns" }}.
+type EntryTemplateData struct {
+	// AgentID is the SPIFFE ID of the agent
+	AgentID string
+
+	// AgentPath is the path of the agent SPIFFE ID, including the leading
+	// slash, e.g. "/spire/agent/k8s_psat/prod/1234"
+	AgentPath string
+
+	// selectors holds the agent selectors as "<type>:<value>" strings. It
+	// is nil when validating a template.
+	selectors []string
+}
+
+func (d *EntryTemplateData) selector(typeAndKey string) (string, error) {
+	if d.selectors == nil {
+		// Validating the template; any selector is acceptable
+		return "placeholder", nil
+	}
+	if value, ok := lookupSelector(d.selectors, typeAndKey); ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("no %q selector on agent", typeAndKey)
+}
+
+// ValidateEntryTemplate validates an entry template. The SPIFFE ID and the
+// selector values of the entry are validated by rendering them with
+// placeholder values.
+func ValidateEntryTemplate(td spiffeid.TrustDomain, t *datastore.EntryTemplate) error {
+	switch {
+	case len(t.NodeSelectors) == 0:
+		return errors.New("missing node selectors")
+	case t.Entry == nil:
+		return errors.New("missing entry")
+	case len(t.Entry.Selectors) == 0:
+		return errors.New("missing selectors")
+	}
+
+	placeholderID, err := spiffeid.FromPath(td, "/spire/agent/placeholder")
+	if err != nil {
+		return err
+	}
+	entry, err := renderEntryTemplate(t, placeholderID, &EntryTemplateData{
+		AgentID:   placeholderID.String(),
+		AgentPath: placeholderID.Path(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dnsName := range entry.DnsNames {
+		if err := x509util.ValidateDNS(dnsName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EntryTemplateMatches returns true if the agent selectors include all the
+// node selectors of the template.
+func EntryTemplateMatches(t *datastore.EntryTemplate, agentSelectors []*common.Selector) bool {
+	for _, nodeSelector := range t.NodeSelectors {
+		if !containsSelector(agentSelectors, nodeSelector) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderEntryTemplate returns the entry of the template for the agent with
+// the given ID and selectors. The parent ID of the entry is the agent ID.
+func RenderEntryTemplate(t *datastore.EntryTemplate, agentID spiffeid.ID, agentSelectors []*common.Selector) (*common.RegistrationEntry, error) {
+	data := &EntryTemplateData{
+		AgentID:   agentID.String(),
+		AgentPath: agentID.Path(),
+		selectors: make([]string, 0, len(agentSelectors)),
+	}
+	for _, s := range agentSelectors {
+		data.selectors = append(data.selectors, s.Type+":"+s.Value)
+	}
+	return renderEntryTemplate(t, agentID, data)
+}
+
+func renderEntryTemplate(t *datastore.EntryTemplate, agentID spiffeid.ID, data *EntryTemplateData) (*common.RegistrationEntry, error) {
+	entry := proto.Clone(t.Entry).(*common.RegistrationEntry)
+	entry.EntryId = ""
+	entry.ParentId = agentID.String()
+
+	spiffeID, err := renderEntryTemplateField(entry.SpiffeId, data)
+	if err != nil {
+		return nil, err
+	}
+	id, err := spiffeid.FromString(spiffeID)
+	if err != nil {
+		return nil, fmt.Errorf("entry template rendered invalid SPIFFE ID %q: %w", spiffeID, err)
+	}
+	if id.TrustDomain() != agentID.TrustDomain() {
+		return nil, fmt.Errorf("entry template rendered SPIFFE ID %q outside of trust domain %q", spiffeID, agentID.TrustDomain())
+	}
+	entry.SpiffeId = id.String()
+
+	for _, s := range entry.Selectors {
+		s.Value, err = renderEntryTemplateField(s.Value, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+func renderEntryTemplateField(text string, data *EntryTemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("entry").
+		Funcs(template.FuncMap{"selector": data.selector}).
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid entry template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("unable to render entry template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// lookupSelector returns the remainder of the first of the "<type>:<value>"
+// selectors that starts with the given type and key.
+func lookupSelector(selectors []string, typeAndKey string) (string, bool) {
+	prefix := typeAndKey + ":"
+	for _, s := range selectors {
+		if strings.HasPrefix(s, prefix) {
+			return s[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+func containsSelector(selectors []*common.Selector, selector *common.Selector) bool {
+	for _, s := range selectors {
+		if s.Type == selector.Type && s.Value == selector.Value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	nodeSelectors  = []*common.Selector{{Type: "k8s_psat", Value: "cluster:prod"}}
+	agentSelectors = []*common.Selector{
+		{Type: "k8s_psat", Value: "cluster:prod"},
+		{Type: "k8s_psat", Value: "agent_ns:spire"},
+	}
+)
+
+func TestValidateEntryTemplate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		template *datastore.EntryTemplate
+		err      string
+	}{
+		{
+			name: "valid template",
+			template: &datastore.EntryTemplate{
+				NodeSelectors: nodeSelectors,
+				Entry: &common.RegistrationEntry{
+					SpiffeId:  `spiffe://example.org/{{ selector "k8s_psat:agent_ns" }}/web{{ .AgentPath }}`,
+					Selectors: []*common.Selector{{Type: "k8s", Value: `ns:{{ selector "k8s_psat:agent_ns" }}`}},
+					DnsNames:  []string{"web.svc"},
+				},
+			},
+		},
+		{
+			name: "missing node selectors",
+			template: &datastore.EntryTemplate{
+				Entry: &common.RegistrationEntry{
+					SpiffeId:  "spiffe://example.org/web",
+					Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+				},
+			},
+			err: "missing node selectors",
+		},
+		{
+			name:     "missing entry",
+			template: &datastore.EntryTemplate{NodeSelectors: nodeSelectors},
+			err:      "missing entry",
+		},
+		{
+			name: "missing selectors",
+			template: &datastore.EntryTemplate{
+				NodeSelectors: nodeSelectors,
+				Entry:         &common.RegistrationEntry{SpiffeId: "spiffe://example.org/web"},
+			},
+			err: "missing selectors",
+		},
+		{
+			name: "malformed template",
+			template: &datastore.EntryTemplate{
+				NodeSelectors: nodeSelectors,
+				Entry: &common.RegistrationEntry{
+					SpiffeId:  "spiffe://example.org/{{ .AgentPath",
+					Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+				},
+			},
+			err: "invalid entry template: template: entry:1: unclosed action",
+		},
+		{
+			name: "SPIFFE ID outside of trust domain",
+			template: &datastore.EntryTemplate{
+				NodeSelectors: nodeSelectors,
+				Entry: &common.RegistrationEntry{
+					SpiffeId:  "spiffe://other.org/web{{ .AgentPath }}",
+					Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+				},
+			},
+			err: `entry template rendered SPIFFE ID "spiffe://other.org/web/spire/agent/placeholder" outside of trust domain "example.org"`,
+		},
+		{
+			name: "invalid DNS name",
+			template: &datastore.EntryTemplate{
+				NodeSelectors: nodeSelectors,
+				Entry: &common.RegistrationEntry{
+					SpiffeId:  "spiffe://example.org/web",
+					Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+					DnsNames:  []string{"abc-"},
+				},
+			},
+			err: "label does not match regex: abc-",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := api.ValidateEntryTemplate(spiffeid.RequireTrustDomainFromString("example.org"), tt.template)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEntryTemplateMatches(t *testing.T) {
+	template := &datastore.EntryTemplate{NodeSelectors: nodeSelectors}
+	require.True(t, api.EntryTemplateMatches(template, agentSelectors))
+	require.False(t, api.EntryTemplateMatches(template, agentSelectors[1:]))
+	require.False(t, api.EntryTemplateMatches(template, nil))
+}
+
+func TestRenderEntryTemplate(t *testing.T) {
+	agentID := spiffeid.RequireFromString("spiffe://example.org/spire/agent/k8s_psat/prod/1234")
+	template := &datastore.EntryTemplate{
+		NodeSelectors: nodeSelectors,
+		Entry: &common.RegistrationEntry{
+			SpiffeId:  `spiffe://example.org/{{ selector "k8s_psat:agent_ns" }}/web{{ .AgentPath }}`,
+			Selectors: []*common.Selector{{Type: "k8s", Value: `ns:{{ selector "k8s_psat:agent_ns" }}`}},
+			DnsNames:  []string{"web.svc"},
+			Ttl:       60,
+		},
+	}
+
+	entry, err := api.RenderEntryTemplate(template, agentID, agentSelectors)
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/spire/web/spire/agent/k8s_psat/prod/1234",
+		ParentId:  "spiffe://example.org/spire/agent/k8s_psat/prod/1234",
+		Selectors: []*common.Selector{{Type: "k8s", Value: "ns:spire"}},
+		DnsNames:  []string{"web.svc"},
+		Ttl:       60,
+	}, entry)
+
+	// The template itself is left untouched
+	require.Equal(t, `ns:{{ selector "k8s_psat:agent_ns" }}`, template.Entry.Selectors[0].Value)
+
+	_, err = api.RenderEntryTemplate(template, agentID, nodeSelectors)
+	require.EqualError(t, err, `unable to render entry template: template: entry:1:24: executing "entry" at <selector "k8s_psat:agent_ns">: error calling selector: no "k8s_psat:agent_ns" selector on agent`)
+}
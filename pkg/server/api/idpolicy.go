@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+// IDPathPolicy restricts the SPIFFE ID paths that can be registered and
+// signed in the trust domain. A path is allowed if it starts with any of the
+// prefixes or fully matches any of the regular expression patterns, e.g.
+// "/ns/[^/]+/.+". A nil policy allows every path.
+type IDPathPolicy struct {
+	prefixes []string
+	patterns []*regexp.Regexp
+}
+
+// NewIDPathPolicy returns a policy with the given prefixes and patterns. It
+// returns nil if there are neither prefixes nor patterns.
+func NewIDPathPolicy(prefixes, patterns []string) (*IDPathPolicy, error) {
+	if len(prefixes) == 0 && len(patterns) == 0 {
+		return nil, nil
+	}
+
+	p := &IDPathPolicy{}
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("prefix %q must start with a slash", prefix)
+		}
+		p.prefixes = append(p.prefixes, prefix)
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, errors.New("pattern cannot be empty")
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("malformed pattern %q: %w", pattern, err)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+// Check returns an error if the path of the SPIFFE ID is not allowed by the
+// policy
+func (p *IDPathPolicy) Check(id spiffeid.ID) error {
+	if p == nil {
+		return nil
+	}
+
+	path := id.Path()
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return nil
+		}
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(path) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %q is not allowed by the SPIFFE ID path policy", path)
+}
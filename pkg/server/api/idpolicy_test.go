@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDPathPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		prefixes  []string
+		patterns  []string
+		expectNil bool
+		expectErr string
+	}{
+		{
+			name:      "empty",
+			expectNil: true,
+		},
+		{
+			name:     "prefixes and patterns",
+			prefixes: []string{"/ns/"},
+			patterns: []string{"/team-[a-z]+/.+"},
+		},
+		{
+			name:      "prefix without leading slash",
+			prefixes:  []string{"ns/"},
+			expectErr: `prefix "ns/" must start with a slash`,
+		},
+		{
+			name:      "empty pattern",
+			patterns:  []string{""},
+			expectErr: "pattern cannot be empty",
+		},
+		{
+			name:      "malformed pattern",
+			patterns:  []string{"/ns/[a-z"},
+			expectErr: "malformed pattern \"/ns/[a-z\": error parsing regexp: missing closing ]: `[a-z)$`",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := api.NewIDPathPolicy(tt.prefixes, tt.patterns)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				require.Nil(t, policy)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNil {
+				require.Nil(t, policy)
+			} else {
+				require.NotNil(t, policy)
+			}
+		})
+	}
+}
+
+func TestIDPathPolicyCheck(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("domain.test")
+
+	policy, err := api.NewIDPathPolicy([]string{"/ns/"}, []string{"/team-[a-z]+/[^/]+"})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		policy    *api.IDPathPolicy
+		path      string
+		expectErr string
+	}{
+		{
+			name:   "nil policy",
+			policy: nil,
+			path:   "/anything",
+		},
+		{
+			name:   "matches prefix",
+			policy: policy,
+			path:   "/ns/foo/workload",
+		},
+		{
+			name:   "matches pattern",
+			policy: policy,
+			path:   "/team-a/workload",
+		},
+		{
+			name:      "pattern must match the whole path",
+			policy:    policy,
+			path:      "/team-a/workload/extra",
+			expectErr: `path "/team-a/workload/extra" is not allowed by the SPIFFE ID path policy`,
+		},
+		{
+			name:      "not allowed",
+			policy:    policy,
+			path:      "/workload",
+			expectErr: `path "/workload" is not allowed by the SPIFFE ID path policy`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(spiffeid.RequireFromPath(td, tt.path))
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/version"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// unknownAgentVersion is the version reported for agents that predate
+	// version negotiation and do not send the AgentVersionHeader.
+	unknownAgentVersion = "unknown"
+
+	// agentVersionTTL is how long the version of an agent is tracked after
+	// its last call. Agents call the server on every synchronization, so
+	// agents that have not called in this long are gone, e.g. evicted or
+	// decommissioned, and no longer counted.
+	agentVersionTTL = time.Hour
+
+	// agentVersionGCInterval is the interval at which the versions of agents
+	// that have not called within agentVersionTTL are dropped.
+	agentVersionGCInterval = time.Minute
+)
+
+// WithAgentVersions returns a middleware that negotiates versions and
+// capabilities with the callers. It returns the server version and
+// capabilities in the response headers of every call, and keeps track of the
+// version reported by each agent, emitting a gauge with the number of agents
+// running each version whenever an agent reports a different version or
+// stops calling the server.
+//
+// The WithAgentVersions middleware depends on the Authorization middleware.
+func WithAgentVersions(metrics telemetry.Metrics, clk clock.Clock) Middleware {
+	return &agentVersionsMiddleware{
+		metrics:  metrics,
+		clk:      clk,
+		versions: make(map[string]agentVersion),
+		counts:   make(map[string]int),
+		lastGC:   clk.Now(),
+	}
+}
+
+type agentVersion struct {
+	version  string
+	lastSeen time.Time
+}
+
+type agentVersionsMiddleware struct {
+	metrics telemetry.Metrics
+	clk     clock.Clock
+
+	mtx sync.Mutex
+	// versions holds the last version reported by each agent, keyed by
+	// agent SPIFFE ID
+	versions map[string]agentVersion
+	// counts holds the number of agents running each version
+	counts map[string]int
+	// lastGC is the last time the versions of agents that stopped calling
+	// were dropped
+	lastGC time.Time
+}
+
+func (m *agentVersionsMiddleware) Preprocess(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	// Setting the header fails when the call has no transport stream (e.g.
+	// in unit tests), which is harmless.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(
+		nodeutil.ServerVersionHeader, version.Version(),
+		nodeutil.CapabilitiesHeader, nodeutil.Capabilities(),
+	))
+
+	if !rpccontext.CallerIsAgent(ctx) {
+		return ctx, nil
+	}
+	agentID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	agentVersion := unknownAgentVersion
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(nodeutil.AgentVersionHeader); len(values) > 0 && values[0] != "" {
+		agentVersion = values[0]
+	}
+
+	m.observe(agentID.String(), agentVersion)
+	return ctx, nil
+}
+
+func (m *agentVersionsMiddleware) Postprocess(ctx context.Context, fullMethod string, handlerInvoked bool, rpcErr error) {
+}
+
+func (m *agentVersionsMiddleware) observe(agentID, version string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := m.clk.Now()
+	if now.Sub(m.lastGC) >= agentVersionGCInterval {
+		m.gc(now)
+	}
+
+	previous, known := m.versions[agentID]
+	m.versions[agentID] = agentVersion{version: version, lastSeen: now}
+	if known && previous.version == version {
+		return
+	}
+
+	if known {
+		m.decrement(previous.version)
+	}
+	m.counts[version]++
+	m.setGauge(version)
+}
+
+// gc drops the versions of the agents that have not called the server within
+// agentVersionTTL.
+func (m *agentVersionsMiddleware) gc(now time.Time) {
+	for agentID, v := range m.versions {
+		if now.Sub(v.lastSeen) >= agentVersionTTL {
+			delete(m.versions, agentID)
+			m.decrement(v.version)
+		}
+	}
+	m.lastGC = now
+}
+
+func (m *agentVersionsMiddleware) decrement(version string) {
+	m.counts[version]--
+	m.setGauge(version)
+	if m.counts[version] == 0 {
+		delete(m.counts, version)
+	}
+}
+
+func (m *agentVersionsMiddleware) setGauge(version string) {
+	m.metrics.SetGaugeWithLabels([]string{telemetry.Agent, telemetry.Version}, float32(m.counts[version]), []telemetry.Label{
+		{Name: telemetry.Version, Value: version},
+	})
+}
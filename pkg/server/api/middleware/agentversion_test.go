@@ -0,0 +1,142 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/nodeutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/version"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithAgentVersionsSetsServerHeaders(t *testing.T) {
+	m := middleware.WithAgentVersions(fakemetrics.New(), clock.NewMock(t))
+
+	stream := &fakeServerTransportStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	_, err := m.Preprocess(ctx, fakeFullMethod, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{version.Version()}, stream.header.Get(nodeutil.ServerVersionHeader))
+	require.True(t, nodeutil.HasCapability(stream.header, nodeutil.CapabilityBundleDigest))
+
+	// Calls without a transport stream do not fail
+	_, err = m.Preprocess(context.Background(), fakeFullMethod, nil)
+	require.NoError(t, err)
+}
+
+func TestWithAgentVersionsGauge(t *testing.T) {
+	metrics := fakemetrics.New()
+	m := middleware.WithAgentVersions(metrics, clock.NewMock(t))
+
+	agentA := spiffeid.RequireFromString("spiffe://example.org/spire/agent/a")
+	agentB := spiffeid.RequireFromString("spiffe://example.org/spire/agent/b")
+
+	call := func(id spiffeid.ID, isAgent bool, agentVersion string) {
+		ctx := rpccontext.WithCallerID(context.Background(), id)
+		if isAgent {
+			ctx = rpccontext.WithAgentCaller(ctx)
+		}
+		if agentVersion != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(nodeutil.AgentVersionHeader, agentVersion))
+		}
+		_, err := m.Preprocess(ctx, fakeFullMethod, nil)
+		require.NoError(t, err)
+		m.Postprocess(ctx, fakeFullMethod, true, nil)
+	}
+
+	gauge := func(agentVersion string, val float32) fakemetrics.MetricItem {
+		return fakemetrics.MetricItem{
+			Type:   fakemetrics.SetGaugeWithLabelsType,
+			Key:    []string{telemetry.Agent, telemetry.Version},
+			Val:    val,
+			Labels: []telemetry.Label{{Name: telemetry.Version, Value: agentVersion}},
+		}
+	}
+
+	// Callers that are not agents are not tracked
+	call(agentA, false, "1.3.0")
+	require.Empty(t, metrics.AllMetrics())
+
+	// Agents that do not report their version are tracked as unknown
+	call(agentA, true, "")
+	require.Equal(t, []fakemetrics.MetricItem{gauge("unknown", 1)}, metrics.AllMetrics())
+
+	// Reporting the same version again does not emit the gauge
+	call(agentA, true, "")
+	require.Len(t, metrics.AllMetrics(), 1)
+
+	// An upgraded agent moves from one version to the other. Label values
+	// are sanitized by the metrics sink.
+	call(agentA, true, "1.3.2")
+	call(agentB, true, "1.3.2")
+	require.Equal(t, []fakemetrics.MetricItem{
+		gauge("unknown", 1),
+		gauge("unknown", 0),
+		gauge("1_3_2", 1),
+		gauge("1_3_2", 2),
+	}, metrics.AllMetrics())
+}
+
+func TestWithAgentVersionsDropsGoneAgents(t *testing.T) {
+	metrics := fakemetrics.New()
+	clk := clock.NewMock(t)
+	m := middleware.WithAgentVersions(metrics, clk)
+
+	agentA := spiffeid.RequireFromString("spiffe://example.org/spire/agent/a")
+	agentB := spiffeid.RequireFromString("spiffe://example.org/spire/agent/b")
+
+	call := func(id spiffeid.ID) {
+		ctx := rpccontext.WithAgentCaller(rpccontext.WithCallerID(context.Background(), id))
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(nodeutil.AgentVersionHeader, "1.3.2"))
+		_, err := m.Preprocess(ctx, fakeFullMethod, nil)
+		require.NoError(t, err)
+	}
+
+	gauge := func(val float32) fakemetrics.MetricItem {
+		return fakemetrics.MetricItem{
+			Type:   fakemetrics.SetGaugeWithLabelsType,
+			Key:    []string{telemetry.Agent, telemetry.Version},
+			Val:    val,
+			Labels: []telemetry.Label{{Name: telemetry.Version, Value: "1_3_2"}},
+		}
+	}
+
+	call(agentA)
+	call(agentB)
+
+	// Agent B keeps calling while agent A is gone
+	clk.Add(45 * time.Minute)
+	call(agentB)
+	clk.Add(30 * time.Minute)
+	call(agentB)
+
+	// Agent A is no longer counted. Agent B is still counted, and counted
+	// again if agent A comes back.
+	call(agentA)
+	require.Equal(t, []fakemetrics.MetricItem{
+		gauge(1),
+		gauge(2),
+		gauge(1),
+		gauge(2),
+	}, metrics.AllMetrics())
+}
+
+type fakeServerTransportStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *fakeServerTransportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
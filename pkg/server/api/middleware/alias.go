@@ -31,6 +31,10 @@ func WithLogger(log logrus.FieldLogger) Middleware {
 	return middleware.WithLogger(log)
 }
 
+func WithRequestID() Middleware {
+	return middleware.WithRequestID()
+}
+
 func WithMetrics(metrics telemetry.Metrics) Middleware {
 	return middleware.WithMetrics(metrics)
 }
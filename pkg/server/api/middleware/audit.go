@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/shirou/gopsutil/v3/process"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/common/peertracker"
@@ -19,13 +21,31 @@ func WithAuditLog(localTrackerEnabled bool) Middleware {
 	}
 }
 
+// WithAuditLogSink is like WithAuditLog, but the audit records also include
+// the latency of the call, and are written to the sink, if not nil,
+// regardless of the log level.
+func WithAuditLogSink(localTrackerEnabled bool, sink audit.Sink, clk clock.Clock) Middleware {
+	return auditLogMiddleware{
+		localTrackerEnabled: localTrackerEnabled,
+		sink:                sink,
+		clk:                 clk,
+	}
+}
+
 type auditLogMiddleware struct {
 	Middleware
 
 	localTrackerEnabled bool
+	sink                audit.Sink
+	clk                 clock.Clock
 }
 
 func (m auditLogMiddleware) Preprocess(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	var start time.Time
+	if m.clk != nil {
+		start = m.clk.Now()
+	}
+
 	log := rpccontext.Logger(ctx)
 	if rpccontext.CallerIsLocal(ctx) && m.localTrackerEnabled {
 		fields, err := fieldsFromTracker(ctx)
@@ -36,7 +56,12 @@ func (m auditLogMiddleware) Preprocess(ctx context.Context, fullMethod string, r
 		log = log.WithFields(fields)
 	}
 
-	auditLog := audit.New(log)
+	var auditLog audit.Logger
+	if m.clk != nil {
+		auditLog = audit.NewForCall(log, m.sink, m.clk, start)
+	} else {
+		auditLog = audit.New(log)
+	}
 
 	ctx = rpccontext.WithAuditLog(ctx, auditLog)
 
@@ -3,7 +3,6 @@ package middleware
 import (
 	"context"
 
-	"github.com/gofrs/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/api/middleware"
@@ -14,20 +13,26 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func WithAuthorization(authPolicyEngine *authpolicy.Engine, entryFetcher EntryFetcher, agentAuthorizer AgentAuthorizer, adminIDs []spiffeid.ID) middleware.Middleware {
+// WithAuthorization returns a middleware that authorizes callers using the
+// policy engine. Callers with one of the namespaced admin IDs are scoped to
+// an entry namespace, and are only authorized as admins for the methods the
+// policy allows namespaced admins to call.
+func WithAuthorization(authPolicyEngine *authpolicy.Engine, entryFetcher EntryFetcher, agentAuthorizer AgentAuthorizer, adminIDs, namespacedAdminIDs []spiffeid.ID) middleware.Middleware {
 	return &authorizationMiddleware{
-		authPolicyEngine: authPolicyEngine,
-		entryFetcher:     entryFetcher,
-		agentAuthorizer:  agentAuthorizer,
-		adminIDs:         adminIDSet(adminIDs),
+		authPolicyEngine:   authPolicyEngine,
+		entryFetcher:       entryFetcher,
+		agentAuthorizer:    agentAuthorizer,
+		adminIDs:           adminIDSet(adminIDs),
+		namespacedAdminIDs: adminIDSet(namespacedAdminIDs),
 	}
 }
 
 type authorizationMiddleware struct {
-	authPolicyEngine *authpolicy.Engine
-	entryFetcher     EntryFetcher
-	agentAuthorizer  AgentAuthorizer
-	adminIDs         map[spiffeid.ID]struct{}
+	authPolicyEngine   *authpolicy.Engine
+	entryFetcher       EntryFetcher
+	agentAuthorizer    AgentAuthorizer
+	adminIDs           map[spiffeid.ID]struct{}
+	namespacedAdminIDs map[spiffeid.ID]struct{}
 }
 
 func (m *authorizationMiddleware) Preprocess(ctx context.Context, methodName string, req interface{}) (context.Context, error) {
@@ -43,13 +48,6 @@ func (m *authorizationMiddleware) Preprocess(ctx context.Context, methodName str
 	if id, ok := rpccontext.CallerID(ctx); ok {
 		fields[telemetry.CallerID] = id.String()
 	}
-	// Add request ID to logger, it simplify debugging when calling batch endpints
-	requestID, err := uuid.NewV4()
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create request ID: %v", err)
-	}
-	fields[telemetry.RequestID] = requestID.String()
-
 	if len(fields) > 0 {
 		ctx = rpccontext.WithLogger(ctx, rpccontext.Logger(ctx).WithFields(fields))
 	}
@@ -59,8 +59,15 @@ func (m *authorizationMiddleware) reconcileResult(ctx context.Context, res authp
 		return ctx, true, nil
 	}
 
+	// Callers scoped to an entry namespace are only admins for the methods
+	// that enforce namespaces
+	allowIfAdmin := res.AllowIfAdmin
+	if allowIfAdmin && !res.AllowIfNamespacedAdmin && m.isNamespacedAdmin(ctx) {
+		allowIfAdmin = false
+	}
+
 	// Check statically configured admin entries
-	if res.AllowIfAdmin {
+	if allowIfAdmin {
 		if ctx, ok := isAdminViaConfig(ctx, m.adminIDs); ok {
 			ctx = setAuthorizationLogFields(ctx, "admin", "config")
 			return ctx, true, nil
@@ -68,13 +75,13 @@ func (m *authorizationMiddleware) reconcileResult(ctx context.Context, res authp
 	}
 
 	// Check entry-based admin and downstream auth
-	if res.AllowIfAdmin || res.AllowIfDownstream {
+	if allowIfAdmin || res.AllowIfDownstream {
 		ctx, entries, err := WithCallerEntries(ctx, m.entryFetcher)
 		if err != nil {
 			return ctx, false, err
 		}
 
-		if res.AllowIfAdmin {
+		if allowIfAdmin {
 			if ctx, ok := isAdminViaEntries(ctx, entries); ok {
 				ctx = setAuthorizationLogFields(ctx, "admin", "entries")
 				return ctx, true, nil
@@ -101,6 +108,15 @@ func (m *authorizationMiddleware) reconcileResult(ctx context.Context, res authp
 	return ctx, false, nil
 }
 
+func (m *authorizationMiddleware) isNamespacedAdmin(ctx context.Context) bool {
+	callerID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = m.namespacedAdminIDs[callerID]
+	return ok
+}
+
 func isAdminViaConfig(ctx context.Context, adminIDs map[spiffeid.ID]struct{}) (context.Context, bool) {
 	if callerID, ok := rpccontext.CallerID(ctx); ok {
 		if _, ok := adminIDs[callerID]; ok {
@@ -100,16 +100,17 @@ func TestWithAuthorizationPreprocess(t *testing.T) {
 	}
 
 	for _, tt := range []struct {
-		name            string
-		request         interface{}
-		fullMethod      string
-		peer            *peer.Peer
-		rego            string
-		agentAuthorizer middleware.AgentAuthorizer
-		adminIDs        []spiffeid.ID
-		authorizerErr   error
-		expectCode      codes.Code
-		expectMsg       string
+		name               string
+		request            interface{}
+		fullMethod         string
+		peer               *peer.Peer
+		rego               string
+		agentAuthorizer    middleware.AgentAuthorizer
+		adminIDs           []spiffeid.ID
+		namespacedAdminIDs []spiffeid.ID
+		authorizerErr      error
+		expectCode         codes.Code
+		expectMsg          string
 	}{
 		{
 			name:       "basic allow test",
@@ -168,6 +169,40 @@ func TestWithAuthorizationPreprocess(t *testing.T) {
 			}),
 			expectCode: codes.OK,
 		},
+		{
+			name:               "allow_if_admin namespaced admin caller test",
+			fullMethod:         fakeFullMethod,
+			peer:               adminPeer,
+			namespacedAdminIDs: []spiffeid.ID{adminID},
+			rego: simpleRego(map[string]bool{
+				"allow_if_admin": true,
+			}),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  fmt.Sprintf("authorization denied for method %s", fakeFullMethod),
+		},
+		{
+			name:               "allow_if_admin namespaced static admin caller test",
+			fullMethod:         fakeFullMethod,
+			peer:               staticAdminPeer,
+			adminIDs:           []spiffeid.ID{staticAdminID},
+			namespacedAdminIDs: []spiffeid.ID{staticAdminID},
+			rego: simpleRego(map[string]bool{
+				"allow_if_admin": true,
+			}),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  fmt.Sprintf("authorization denied for method %s", fakeFullMethod),
+		},
+		{
+			name:               "allow_if_namespaced_admin namespaced admin caller test",
+			fullMethod:         fakeFullMethod,
+			peer:               adminPeer,
+			namespacedAdminIDs: []spiffeid.ID{adminID},
+			rego: simpleRego(map[string]bool{
+				"allow_if_admin":            true,
+				"allow_if_namespaced_admin": true,
+			}),
+			expectCode: codes.OK,
+		},
 		{
 			name:       "allow_if_admin non-admin caller test",
 			fullMethod: fakeFullMethod,
@@ -290,7 +325,7 @@ func TestWithAuthorizationPreprocess(t *testing.T) {
 			if tt.agentAuthorizer == nil {
 				tt.agentAuthorizer = noAgentAuthorizer
 			}
-			m := middleware.WithAuthorization(policyEngine, entryFetcher, tt.agentAuthorizer, tt.adminIDs)
+			m := middleware.WithAuthorization(policyEngine, entryFetcher, tt.agentAuthorizer, tt.adminIDs, tt.namespacedAdminIDs)
 
 			// Set up the incoming context with a logger and optionally a peer.
 			log, _ := test.NewNullLogger()
@@ -317,7 +352,7 @@ func TestWithAuthorizationPostprocess(t *testing.T) {
 	ctx := context.Background()
 	policyEngine, err := authpolicy.DefaultAuthPolicy(ctx)
 	require.NoError(t, err, "failed to initialize policy engine")
-	m := middleware.WithAuthorization(policyEngine, entryFetcher, yesAgentAuthorizer, nil)
+	m := middleware.WithAuthorization(policyEngine, entryFetcher, yesAgentAuthorizer, nil, nil)
 
 	m.Postprocess(context.Background(), "", false, nil)
 	m.Postprocess(context.Background(), "", true, errors.New("ohno"))
@@ -394,10 +429,11 @@ func simpleRego(m map[string]bool) string {
       "allow_if_admin": %t,
       "allow_if_local": %t,
       "allow_if_downstream": %t,
-      "allow_if_agent": %t
+      "allow_if_agent": %t,
+      "allow_if_namespaced_admin": %t
     }`
 
-	return fmt.Sprintf(regoTemplate, m["allow"], m["allow_if_admin"], m["allow_if_local"], m["allow_if_downstream"], m["allow_if_agent"])
+	return fmt.Sprintf(regoTemplate, m["allow"], m["allow_if_admin"], m["allow_if_local"], m["allow_if_downstream"], m["allow_if_agent"], m["allow_if_namespaced_admin"])
 }
 
 func condCheckRego(cond string) string {
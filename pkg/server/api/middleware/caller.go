@@ -29,7 +29,23 @@ func callerContextFromContext(ctx context.Context) (context.Context, error) {
 	}
 }
 
+// AttestationTLSInfo is the authentication information of a TLS connection
+// where the client presented a certificate issued by an external CA trusted
+// for node attestation. Such a caller is never authenticated with the SPIFFE
+// ID of the certificate; the verified chains are only made available to the
+// node attestation flow.
+type AttestationTLSInfo struct {
+	credentials.TLSInfo
+}
+
 func tcpCallerContextFromPeer(ctx context.Context, p *peer.Peer) (context.Context, error) {
+	if attestationInfo, ok := p.AuthInfo.(AttestationTLSInfo); ok {
+		if !attestationInfo.State.HandshakeComplete {
+			return nil, status.Error(codes.Internal, "TLS handshake is not complete")
+		}
+		return rpccontext.WithCallerAttestationChains(ctx, attestationInfo.State.VerifiedChains), nil
+	}
+
 	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
 	if !ok {
 		// No TLS information. Return an unauthenticated TCP caller.
@@ -84,6 +84,23 @@ func TestCallerContextFromContext(t *testing.T) {
 			},
 		},
 	}
+	attestationChains := [][]*x509.Certificate{{workloadX509SVID, {}}}
+	attestationPeer := &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("1.1.1.1")},
+		AuthInfo: AttestationTLSInfo{
+			TLSInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{
+					HandshakeComplete: true,
+					PeerCertificates:  []*x509.Certificate{workloadX509SVID},
+					VerifiedChains:    attestationChains,
+				},
+			},
+		},
+	}
+	attestationPeerIncompleteHandshake := &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("1.1.1.1")},
+		AuthInfo: AttestationTLSInfo{},
+	}
 
 	for _, tt := range []struct {
 		name                 string
@@ -93,6 +110,7 @@ func TestCallerContextFromContext(t *testing.T) {
 		expectIsLocal        bool
 		expectCallerID       spiffeid.ID
 		expectCallerX509SVID *x509.Certificate
+		expectChains         [][]*x509.Certificate
 	}{
 		{
 			name:       "no peer",
@@ -164,6 +182,19 @@ func TestCallerContextFromContext(t *testing.T) {
 			expectCode: codes.Unauthenticated,
 			expectMsg:  "client certificate has a malformed URI SAN: scheme is missing or invalid",
 		},
+		{
+			// The SPIFFE ID of externally issued certificates is not trusted
+			name:         "attestation peer",
+			peer:         attestationPeer,
+			expectCode:   codes.OK,
+			expectChains: attestationChains,
+		},
+		{
+			name:       "attestation peer incomplete handshake",
+			peer:       attestationPeerIncompleteHandshake,
+			expectCode: codes.Internal,
+			expectMsg:  "TLS handshake is not complete",
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -190,6 +221,10 @@ func TestCallerContextFromContext(t *testing.T) {
 			callerX509SVID, ok := rpccontext.CallerX509SVID(ctxOut)
 			assert.Equal(t, tt.expectCallerX509SVID != nil, ok)
 			assert.Equal(t, tt.expectCallerX509SVID, callerX509SVID)
+
+			chains, ok := rpccontext.CallerAttestationChains(ctxOut)
+			assert.Equal(t, tt.expectChains != nil, ok)
+			assert.Equal(t, tt.expectChains, chains)
 		})
 	}
 }
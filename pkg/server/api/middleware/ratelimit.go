@@ -296,7 +296,7 @@ func waitN(ctx context.Context, limiter rawRateLimiter, count int) (err error) {
 	// strongly typed and is a little messy. Lifting this check so we can
 	// provide a clean error message.
 	if count > limiter.Burst() && limiter.Limit() != rate.Inf {
-		return status.Errorf(codes.ResourceExhausted, "rate (%d) exceeds burst size (%d)", count, limiter.Burst())
+		return api.WithReason(status.Errorf(codes.ResourceExhausted, "rate (%d) exceeds burst size (%d)", count, limiter.Burst()), api.ReasonRateLimited)
 	}
 
 	err = limiter.WaitN(ctx, count)
@@ -306,6 +306,6 @@ func waitN(ctx context.Context, limiter rawRateLimiter, count int) (err error) {
 	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
 		return ctx.Err()
 	default:
-		return status.Error(codes.ResourceExhausted, err.Error())
+		return api.WithReason(status.Error(codes.ResourceExhausted, err.Error()), api.ReasonRateLimited)
 	}
 }
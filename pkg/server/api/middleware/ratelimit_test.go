@@ -53,6 +53,7 @@ func TestPerCallLimit(t *testing.T) {
 	// Exceeds burst size.
 	err := m.RateLimit(context.Background(), 2)
 	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, "rate (2) exceeds burst size (1)")
+	require.Equal(t, api.ReasonRateLimited, api.ErrorReason(err))
 
 	// Within burst size.
 	require.NoError(t, m.RateLimit(context.Background(), 1))
@@ -77,6 +78,7 @@ func TestPerIPLimit(t *testing.T) {
 	// Once exceeding burst size for 1.1.1.1
 	err = m.RateLimit(tcpCallerContext("1.1.1.1"), 11)
 	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, "rate (11) exceeds burst size (10)")
+	require.Equal(t, api.ReasonRateLimited, api.ErrorReason(err))
 
 	// Once within burst size for 1.1.1.1
 	require.NoError(t, m.RateLimit(tcpCallerContext("1.1.1.1"), 1))
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details attached to the errors
+// returned by the server APIs.
+const ErrorDomain = "spire.spiffe.io"
+
+// Reasons attached to the errors returned by the server APIs, as the Reason
+// of an ErrorInfo detail, so clients can tell the errors apart without
+// matching the messages. The reasons are part of the API and must not change.
+// See doc/api_errors.md.
+//
+// The agent reasons of PermissionDenied errors match the reasons of the
+// PermissionDeniedDetails detail also attached to them.
+const (
+	ReasonAgentBanned                    = "AGENT_BANNED"
+	ReasonAgentExpired                   = "AGENT_EXPIRED"
+	ReasonAgentNotActive                 = "AGENT_NOT_ACTIVE"
+	ReasonAgentNotAttested               = "AGENT_NOT_ATTESTED"
+	ReasonAgentNotFound                  = "AGENT_NOT_FOUND"
+	ReasonBundleNotFound                 = "BUNDLE_NOT_FOUND"
+	ReasonCANotAvailable                 = "CA_NOT_AVAILABLE"
+	ReasonEntryNotFound                  = "ENTRY_NOT_FOUND"
+	ReasonFederationRelationshipNotFound = "FEDERATION_RELATIONSHIP_NOT_FOUND"
+	ReasonIssuanceQuotaExceeded          = "ISSUANCE_QUOTA_EXCEEDED"
+	ReasonRateLimited                    = "RATE_LIMITED"
+)
+
+// MakeErrWithReason logs and returns an error like MakeErr does, with an
+// ErrorInfo detail holding the reason.
+func MakeErrWithReason(log logrus.FieldLogger, code codes.Code, reason, msg string, err error) error {
+	return WithReason(MakeErr(log, code, msg, err), reason)
+}
+
+// WithReason attaches an ErrorInfo detail holding the reason to a status
+// error. Nil errors, and errors that are not a status, are returned as is.
+func WithReason(err error, reason string) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+	})
+	if detailsErr != nil {
+		return err
+	}
+	return withDetails.Err()
+}
+
+// ErrorReason returns the reason in the ErrorInfo detail of an error
+// returned by the server APIs, or an empty string if there is none.
+func ErrorReason(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			return info.Reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMakeErrWithReason(t *testing.T) {
+	l, hook := test.NewNullLogger()
+	err := api.MakeErrWithReason(l, codes.NotFound, api.ReasonEntryNotFound, "entry not found", nil)
+
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "entry not found")
+	require.Equal(t, api.ReasonEntryNotFound, api.ErrorReason(err))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	spiretest.RequireProtoEqual(t, &errdetails.ErrorInfo{
+		Reason: api.ReasonEntryNotFound,
+		Domain: api.ErrorDomain,
+	}, details[0].(*errdetails.ErrorInfo))
+
+	spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Entry not found",
+		},
+	})
+}
+
+func TestWithReason(t *testing.T) {
+	require.NoError(t, api.WithReason(nil, api.ReasonRateLimited))
+
+	err := errors.New("not a status")
+	require.Equal(t, err, api.WithReason(err, api.ReasonRateLimited))
+	require.Empty(t, api.ErrorReason(err))
+
+	err = status.Error(codes.ResourceExhausted, "rate exceeded")
+	require.Empty(t, api.ErrorReason(err))
+
+	err = api.WithReason(err, api.ReasonRateLimited)
+	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, "rate exceeded")
+	require.Equal(t, api.ReasonRateLimited, api.ErrorReason(err))
+}
+
+func TestErrorReasonIgnoresOtherDomains(t *testing.T) {
+	st, err := status.New(codes.NotFound, "not found").WithDetails(&errdetails.ErrorInfo{
+		Reason: api.ReasonEntryNotFound,
+		Domain: "example.org",
+	})
+	require.NoError(t, err)
+	require.Empty(t, api.ErrorReason(st.Err()))
+}
@@ -12,6 +12,7 @@ import (
 type callerAddrKey struct{}
 type callerIDKey struct{}
 type callerX509SVIDKey struct{}
+type callerAttestationChainsKey struct{}
 type callerDownstreamEntriesKey struct{}
 type callerAdminTagKey struct{}
 type callerLocalTagKey struct{}
@@ -49,6 +50,19 @@ func CallerX509SVID(ctx context.Context) (*x509.Certificate, bool) {
 	return x509SVID, ok
 }
 
+// WithCallerAttestationChains returns a context with the verified chains of
+// an externally issued client certificate presented for node attestation.
+func WithCallerAttestationChains(ctx context.Context, chains [][]*x509.Certificate) context.Context {
+	return context.WithValue(ctx, callerAttestationChainsKey{}, chains)
+}
+
+// CallerAttestationChains returns the verified chains of the externally
+// issued client certificate presented by the caller, if available.
+func CallerAttestationChains(ctx context.Context) ([][]*x509.Certificate, bool) {
+	chains, ok := ctx.Value(callerAttestationChainsKey{}).([][]*x509.Certificate)
+	return chains, ok
+}
+
 // WithCallerDownstreamEntries returns a context with the given entries.
 func WithCallerDownstreamEntries(ctx context.Context, entries []*types.Entry) context.Context {
 	return context.WithValue(ctx, callerDownstreamEntriesKey{}, entries)
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
@@ -32,6 +33,66 @@ func SelectorsFromProto(proto []*types.Selector) ([]*common.Selector, error) {
 	return selectors, nil
 }
 
+// selectorSchemas holds the selectors produced by the built-in workload
+// attestors, keyed by selector type. The value of a selector of one of these
+// types must start with one of the listed selector names followed by ':'.
+// Selector types that are not listed (e.g. those of node attestors or
+// external plugins) are not validated.
+var selectorSchemas = map[string][]string{
+	"docker": {"env", "image_id", "label"},
+	"k8s": {
+		"container-image", "container-name", "node-name", "ns", "pod-image",
+		"pod-image-count", "pod-init-image", "pod-init-image-count", "pod-label",
+		"pod-name", "pod-owner", "pod-owner-uid", "pod-uid", "sa",
+	},
+	"unix": {
+		"apparmor_mode", "apparmor_profile", "entrypoint_path",
+		"entrypoint_sha256", "gid", "group", "path", "selinux_context",
+		"selinux_type", "sha256", "supplementary_gid", "supplementary_group",
+		"uid", "user",
+	},
+	"windows": {"group_name", "group_sid", "path", "sha256", "user_name", "user_sid"},
+}
+
+// ValidateSelectorSchemas checks that the selectors of the types produced by
+// the built-in workload attestors are well formed, so typos like
+// "k8s:serviceaccount:default" are rejected instead of never matching.
+func ValidateSelectorSchemas(selectors []*common.Selector) error {
+	for _, s := range selectors {
+		names, ok := selectorSchemas[s.Type]
+		if !ok {
+			continue
+		}
+		name, value, found := strings.Cut(s.Value, ":")
+		if !found || value == "" {
+			return fmt.Errorf("invalid selector %s:%s: value must be of the form <name>:<value>", s.Type, s.Value)
+		}
+		if !containsString(names, name) {
+			return fmt.Errorf("invalid selector %s:%s: %q is not a known %s selector (expected one of %s)", s.Type, s.Value, name, s.Type, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// SortSelectors sorts the selectors by type and value, in place
+func SortSelectors(selectors []*common.Selector) {
+	sort.Slice(selectors, func(i, j int) bool {
+		if selectors[i].Type != selectors[j].Type {
+			return selectors[i].Type < selectors[j].Type
+		}
+		return selectors[i].Value < selectors[j].Value
+	})
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func ProtoFromSelectors(in []*common.Selector) []*types.Selector {
 	var out []*types.Selector
 	for _, s := range in {
@@ -89,3 +89,76 @@ func TestSelectorsFromProto(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSelectorSchemas(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		selectors []*common.Selector
+		err       string
+	}{
+		{
+			name: "known selectors",
+			selectors: []*common.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "k8s", Value: "sa:default"},
+				{Type: "k8s", Value: "pod-label:app:frontend"},
+				{Type: "docker", Value: "label:com.example.name:foo"},
+				{Type: "windows", Value: "group_sid:se_group_enabled:true:S-1-5-32-544"},
+			},
+		},
+		{
+			name: "types of other attestors are not validated",
+			selectors: []*common.Selector{
+				{Type: "k8s_psat", Value: "cluster:demo"},
+				{Type: "custom", Value: "anything"},
+			},
+		},
+		{
+			name: "unknown selector name",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "serviceaccount:default"},
+			},
+			err: `invalid selector k8s:serviceaccount:default: "serviceaccount" is not a known k8s selector (expected one of container-image, container-name, node-name, ns, pod-image, pod-image-count, pod-init-image, pod-init-image-count, pod-label, pod-name, pod-owner, pod-owner-uid, pod-uid, sa)`,
+		},
+		{
+			name: "missing selector name",
+			selectors: []*common.Selector{
+				{Type: "unix", Value: "1000"},
+			},
+			err: "invalid selector unix:1000: value must be of the form <name>:<value>",
+		},
+		{
+			name: "missing value",
+			selectors: []*common.Selector{
+				{Type: "unix", Value: "uid:"},
+			},
+			err: "invalid selector unix:uid:: value must be of the form <name>:<value>",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := api.ValidateSelectorSchemas(tt.selectors)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSortSelectors(t *testing.T) {
+	selectors := []*common.Selector{
+		{Type: "unix", Value: "uid:1000"},
+		{Type: "k8s", Value: "sa:default"},
+		{Type: "unix", Value: "gid:1000"},
+		{Type: "k8s", Value: "ns:default"},
+	}
+	api.SortSelectors(selectors)
+	require.Equal(t, []*common.Selector{
+		{Type: "k8s", Value: "ns:default"},
+		{Type: "k8s", Value: "sa:default"},
+		{Type: "unix", Value: "gid:1000"},
+		{Type: "unix", Value: "uid:1000"},
+	}, selectors)
+}
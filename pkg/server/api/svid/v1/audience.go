@@ -0,0 +1,53 @@
+package svid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+// AudienceRestriction limits the audiences that JWT-SVIDs can be signed for
+// on behalf of the registration entries with a SPIFFE ID under a path prefix,
+// limiting what a compromised workload can impersonate itself to.
+type AudienceRestriction struct {
+	// Name is the name of the restriction
+	Name string
+
+	// PathPrefix is the SPIFFE ID path prefix of the restricted entries,
+	// e.g. "/payments/".
+	PathPrefix string
+
+	// AllowedAudiences are the audiences JWT-SVIDs can be signed for
+	AllowedAudiences []string
+}
+
+// checkAudiences returns an error if any of the audiences is not allowed by
+// a restriction that applies to the entry. Entries under several restrictions
+// must satisfy all of them.
+func (s *Service) checkAudiences(entry *types.Entry, audiences []string) error {
+	if entry.SpiffeId == nil || entry.SpiffeId.TrustDomain != s.td.String() {
+		return nil
+	}
+
+	for _, restriction := range s.ar {
+		if !strings.HasPrefix(entry.SpiffeId.Path, restriction.PathPrefix) {
+			continue
+		}
+		for _, audience := range audiences {
+			if !restriction.allows(audience) {
+				return fmt.Errorf("audience %q is not allowed by jwt_audience_restriction %q", audience, restriction.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *AudienceRestriction) allows(audience string) bool {
+	for _, allowed := range r.AllowedAudiences {
+		if allowed == audience {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package svid
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/dnstemplate"
+	"github.com/spiffe/spire/pkg/common/x509util"
+)
+
+// addRequestedDNSNames adds the requested DNS names that match a DNS name
+// pattern set for the entry, or a DNS name template of the entry, to the DNS
+// names of the entry. Requested DNS names that do not match, or are already
+// included, are dropped. DNS name templates are never included as-is; the
+// agent renders them from the selectors of the workload and requests the
+// rendered names.
+func (s *Service) addRequestedDNSNames(ctx context.Context, entry *types.Entry, requested []string) ([]string, error) {
+	var dnsNames, templates []string
+	for _, dnsName := range entry.DnsNames {
+		if dnstemplate.IsTemplate(dnsName) {
+			templates = append(templates, dnsName)
+			continue
+		}
+		dnsNames = append(dnsNames, dnsName)
+	}
+
+	var unmatched []string
+	for _, dnsName := range requested {
+		if x509util.ValidateDNS(dnsName) != nil || containsDNSName(dnsNames, dnsName) || containsDNSName(unmatched, dnsName) {
+			continue
+		}
+		if matchDNSNameTemplates(templates, dnsName) {
+			dnsNames = append(dnsNames, dnsName)
+			continue
+		}
+		unmatched = append(unmatched, dnsName)
+	}
+	if len(unmatched) == 0 {
+		return dnsNames, nil
+	}
+
+	// The patterns are only fetched when the agent requests DNS names the
+	// entry does not already cover
+	patterns, err := s.ds.FetchEntryDNSNamePatterns(ctx, entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return dnsNames, nil
+	}
+	patternLabels := make([][]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		patternLabels = append(patternLabels, strings.Split(pattern, "."))
+	}
+	for _, dnsName := range unmatched {
+		if matchDNSNamePatterns(patternLabels, dnsName) {
+			dnsNames = append(dnsNames, dnsName)
+		}
+	}
+	return dnsNames, nil
+}
+
+func matchDNSNamePatterns(patterns [][]string, dnsName string) bool {
+	labels := strings.Split(dnsName, ".")
+	for _, pattern := range patterns {
+		if matchDNSNamePattern(pattern, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDNSNameTemplates(templates []string, dnsName string) bool {
+	for _, template := range templates {
+		if dnstemplate.Match(template, dnsName) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDNSNamePattern(pattern, labels []string) bool {
+	if len(pattern) != len(labels) {
+		return false
+	}
+	for i, label := range pattern {
+		if label != "*" && !strings.EqualFold(label, labels[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsDNSName(dnsNames []string, dnsName string) bool {
+	for _, name := range dnsNames {
+		if strings.EqualFold(name, dnsName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package svid
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// quotaGCInterval is the interval at which unused quota limiters are
+	// garbage collected. A limiter refills within a minute, so one that has
+	// been unused for longer is indistinguishable from a new one.
+	quotaGCInterval = time.Minute
+)
+
+// IssuanceQuotas limits the rate at which SVIDs are signed for agents and
+// registration entries. Zero values disable the corresponding quota.
+type IssuanceQuotas struct {
+	// SigningsPerMinutePerAgent is the maximum number of SVIDs signed per
+	// minute for the entries of a single agent (or downstream server).
+	SigningsPerMinutePerAgent int
+
+	// SigningsPerMinutePerEntry is the maximum number of SVIDs signed per
+	// minute for a single registration entry.
+	SigningsPerMinutePerEntry int
+}
+
+// quotaExceededError is returned when an issuance quota is exceeded
+type quotaExceededError struct {
+	quota string
+	key   string
+	limit int
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("%s %q exceeded its quota of %d signings per minute", e.quota, e.key, e.limit)
+}
+
+type issuanceQuotas struct {
+	metrics telemetry.Metrics
+	agents  *quotaLimiter
+	entries *quotaLimiter
+}
+
+func newIssuanceQuotas(config IssuanceQuotas, metrics telemetry.Metrics, clk clock.Clock) *issuanceQuotas {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &issuanceQuotas{
+		metrics: metrics,
+		agents:  newQuotaLimiter(config.SigningsPerMinutePerAgent, clk),
+		entries: newQuotaLimiter(config.SigningsPerMinutePerEntry, clk),
+	}
+}
+
+// Allow consumes one signing from the quotas of the agent and the entry.
+// Returns a *quotaExceededError, without consuming from either quota, if
+// either quota is exhausted.
+func (q *issuanceQuotas) Allow(agentID, entryID string) error {
+	agent, ok := q.agents.Reserve(agentID)
+	if !ok {
+		return q.exceeded(telemetry.Agent, agentID, q.agents.limit)
+	}
+	if _, ok := q.entries.Reserve(entryID); !ok {
+		agent.Cancel()
+		return q.exceeded(telemetry.Entry, entryID, q.entries.limit)
+	}
+	return nil
+}
+
+func (q *issuanceQuotas) exceeded(quota, key string, limit int) error {
+	if q.metrics != nil {
+		telemetry_server.IncrIssuanceQuotaExceededCounter(q.metrics, quota)
+	}
+	return &quotaExceededError{quota: quota, key: key, limit: limit}
+}
+
+// quotaLimiter keeps a token bucket for each key
+type quotaLimiter struct {
+	limit int
+	clk   clock.Clock
+
+	mtx      sync.Mutex
+	previous map[string]*rate.Limiter
+	current  map[string]*rate.Limiter
+	lastGC   time.Time
+}
+
+func newQuotaLimiter(limit int, clk clock.Clock) *quotaLimiter {
+	return &quotaLimiter{
+		limit:   limit,
+		clk:     clk,
+		current: make(map[string]*rate.Limiter),
+		lastGC:  clk.Now(),
+	}
+}
+
+// Reserve consumes one signing from the quota of the key, if available. The
+// returned reservation can be used to give the signing back.
+func (l *quotaLimiter) Reserve(key string) (quotaReservation, bool) {
+	if l.limit <= 0 {
+		return quotaReservation{}, true
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.clk.Now()
+	if now.Sub(l.lastGC) >= quotaGCInterval {
+		l.previous = l.current
+		l.current = make(map[string]*rate.Limiter)
+		l.lastGC = now
+	}
+
+	limiter, ok := l.current[key]
+	if !ok {
+		limiter, ok = l.previous[key]
+		if ok {
+			delete(l.previous, key)
+		} else {
+			limiter = rate.NewLimiter(rate.Limit(float64(l.limit)/time.Minute.Seconds()), l.limit)
+		}
+		l.current[key] = limiter
+	}
+
+	r := limiter.ReserveN(now, 1)
+	if r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return quotaReservation{}, false
+	}
+	return quotaReservation{r: r, now: now}, true
+}
+
+type quotaReservation struct {
+	r   *rate.Reservation
+	now time.Time
+}
+
+// Cancel gives the reserved signing back
+func (r quotaReservation) Cancel() {
+	if r.r != nil {
+		r.r.CancelAt(r.now)
+	}
+}
@@ -3,9 +3,11 @@ package svid
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"strings"
 	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
@@ -17,6 +19,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -33,6 +36,27 @@ type Config struct {
 	ServerCA     ca.ServerCA
 	TrustDomain  spiffeid.TrustDomain
 	DataStore    datastore.DataStore
+
+	// IDPathPolicy, if set, blocks the issuance of SVIDs for SPIFFE IDs with
+	// paths not allowed by the policy
+	IDPathPolicy *api.IDPathPolicy
+
+	// DownstreamAuthorizer, if set, must authorize every downstream X509 CA
+	// signing request in addition to the caller matching downstream entries
+	DownstreamAuthorizer downstreamwebhook.Authorizer
+
+	// CSRExtensionAllowlist lists the CSR extensions copied into workload
+	// X509-SVIDs by the CA
+	CSRExtensionAllowlist ca.CSRExtensionAllowlist
+
+	// AudienceRestrictions limit the audiences that JWT-SVIDs can be signed
+	// for on behalf of registration entries
+	AudienceRestrictions []AudienceRestriction
+
+	// Quotas limit the rate at which SVIDs are signed for agents and entries
+	Quotas  IssuanceQuotas
+	Metrics telemetry.Metrics
+	Clock   clock.Clock
 }
 
 // New creates a new SVID service
@@ -42,6 +66,11 @@ func New(config Config) *Service {
 		ef: config.EntryFetcher,
 		td: config.TrustDomain,
 		ds: config.DataStore,
+		ip: config.IDPathPolicy,
+		da: config.DownstreamAuthorizer,
+		ea: config.CSRExtensionAllowlist,
+		ar: config.AudienceRestrictions,
+		qt: newIssuanceQuotas(config.Quotas, config.Metrics, config.Clock),
 	}
 }
 
@@ -53,6 +82,11 @@ type Service struct {
 	ef api.AuthorizedEntryFetcher
 	td spiffeid.TrustDomain
 	ds datastore.DataStore
+	ip *api.IDPathPolicy
+	da downstreamwebhook.Authorizer
+	ea ca.CSRExtensionAllowlist
+	ar []AudienceRestriction
+	qt *issuanceQuotas
 }
 
 func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequest) (*svidv1.MintX509SVIDResponse, error) {
@@ -97,15 +131,24 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 		}
 	}
 
+	if err := api.VerifyX509SVIDCSR(csr, s.ea); err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "CSR is not valid for an X509-SVID", err)
+	}
+
+	if err := s.ip.Check(id); err != nil {
+		return nil, api.MakeErr(log, codes.PermissionDenied, "SVID issuance is denied", err)
+	}
+
 	x509SVID, err := s.ca.SignX509SVID(ctx, ca.X509SVIDParams{
-		SpiffeID:  id,
-		PublicKey: csr.PublicKey,
-		TTL:       time.Duration(req.Ttl) * time.Second,
-		DNSList:   csr.DNSNames,
-		Subject:   csr.Subject,
+		SpiffeID:      id,
+		PublicKey:     csr.PublicKey,
+		TTL:           time.Duration(req.Ttl) * time.Second,
+		DNSList:       csr.DNSNames,
+		Subject:       csr.Subject,
+		CSRExtensions: csr.Extensions,
 	})
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "failed to sign X509-SVID", err)
+		return nil, makeSignErr(log, "failed to sign X509-SVID", err)
 	}
 
 	commonX509SVIDLogFields := logrus.Fields{
@@ -241,6 +284,12 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 		}
 	}
 
+	if err := api.VerifyX509SVIDCSR(csr, s.ea); err != nil {
+		return &svidv1.BatchNewX509SVIDResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "CSR is not valid for an X509-SVID", err),
+		}
+	}
+
 	spiffeID, err := api.TrustDomainMemberIDFromProto(ctx, s.td, entry.SpiffeId)
 	if err != nil {
 		// This shouldn't be the case unless there is invalid data in the datastore
@@ -250,15 +299,35 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 	}
 	log = log.WithField(telemetry.SPIFFEID, spiffeID.String())
 
+	if err := s.ip.Check(spiffeID); err != nil {
+		return &svidv1.BatchNewX509SVIDResponse_Result{
+			Status: api.MakeStatus(log, codes.PermissionDenied, "SVID issuance is denied", err),
+		}
+	}
+
+	dnsNames, err := s.addRequestedDNSNames(ctx, entry, csr.DNSNames)
+	if err != nil {
+		return &svidv1.BatchNewX509SVIDResponse_Result{
+			Status: api.MakeStatus(log, codes.Internal, "failed to fetch DNS name patterns", err),
+		}
+	}
+
+	if err := s.allowIssuance(ctx, entry.Id); err != nil {
+		return &svidv1.BatchNewX509SVIDResponse_Result{
+			Status: api.MakeStatus(log, codes.ResourceExhausted, "issuance quota exceeded", err),
+		}
+	}
+
 	x509Svid, err := s.ca.SignX509SVID(ctx, ca.X509SVIDParams{
-		SpiffeID:  spiffeID,
-		PublicKey: csr.PublicKey,
-		DNSList:   entry.DnsNames,
-		TTL:       time.Duration(entry.Ttl) * time.Second,
+		SpiffeID:      spiffeID,
+		PublicKey:     csr.PublicKey,
+		DNSList:       dnsNames,
+		TTL:           time.Duration(entry.Ttl) * time.Second,
+		CSRExtensions: csr.Extensions,
 	})
 	if err != nil {
 		return &svidv1.BatchNewX509SVIDResponse_Result{
-			Status: api.MakeStatus(log, codes.Internal, "failed to sign X509-SVID", err),
+			Status: makeSignStatus(log, "failed to sign X509-SVID", err),
 		}
 	}
 
@@ -289,13 +358,17 @@ func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audi
 		return nil, api.MakeErr(log, codes.InvalidArgument, "at least one audience is required", nil)
 	}
 
+	if err := s.ip.Check(id); err != nil {
+		return nil, api.MakeErr(log, codes.PermissionDenied, "SVID issuance is denied", err)
+	}
+
 	token, err := s.ca.SignJWTSVID(ctx, ca.JWTSVIDParams{
 		SpiffeID: id,
 		TTL:      time.Duration(ttl) * time.Second,
 		Audience: audience,
 	})
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "failed to sign JWT-SVID", err)
+		return nil, makeSignErr(log, "failed to sign JWT-SVID", err)
 	}
 
 	issuedAt, expiresAt, err := jwtsvid.GetTokenExpiry(token)
@@ -335,7 +408,15 @@ func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest)
 
 	entry, ok := entriesMap[req.EntryId]
 	if !ok {
-		return nil, api.MakeErr(log, codes.NotFound, "entry not found or not authorized", nil)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonEntryNotFound, "entry not found or not authorized", nil)
+	}
+
+	if err := s.checkAudiences(entry, req.Audience); err != nil {
+		return nil, api.MakeErr(log, codes.PermissionDenied, "audience not allowed for entry", err)
+	}
+
+	if err := s.allowIssuance(ctx, entry.Id); err != nil {
+		return nil, api.MakeErrWithReason(log, codes.ResourceExhausted, api.ReasonIssuanceQuotaExceeded, "issuance quota exceeded", err)
 	}
 
 	jwtsvid, err := s.mintJWTSVID(ctx, entry.SpiffeId, req.Audience, entry.Ttl)
@@ -374,13 +455,19 @@ func (s *Service) NewDownstreamX509CA(ctx context.Context, req *svidv1.NewDownst
 		return nil, err
 	}
 
+	if err := s.authorizeDownstream(ctx, downstreamEntries); err != nil {
+		return nil, api.MakeErr(log, codes.PermissionDenied, "downstream X.509 CA signing is not authorized", err)
+	}
+
+	callerID, _ := rpccontext.CallerID(ctx)
 	x509CASvid, err := s.ca.SignX509CASVID(ctx, ca.X509CASVIDParams{
-		SpiffeID:  s.td.ID(),
-		PublicKey: csr.PublicKey,
-		TTL:       time.Duration(entry.Ttl) * time.Second,
+		SpiffeID:     s.td.ID(),
+		PublicKey:    csr.PublicKey,
+		TTL:          time.Duration(entry.Ttl) * time.Second,
+		DownstreamID: callerID,
 	})
 	if err != nil {
-		return nil, api.MakeErr(log, codes.Internal, "failed to sign downstream X.509 CA", err)
+		return nil, makeSignErr(log, "failed to sign downstream X.509 CA", err)
 	}
 
 	log.WithFields(logrus.Fields{
@@ -394,7 +481,7 @@ func (s *Service) NewDownstreamX509CA(ctx context.Context, req *svidv1.NewDownst
 	}
 
 	if bundle == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "bundle not found", nil)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonBundleNotFound, "bundle not found", nil)
 	}
 
 	rawRootCerts := make([][]byte, 0, len(bundle.RootCas))
@@ -411,6 +498,51 @@ func (s *Service) NewDownstreamX509CA(ctx context.Context, req *svidv1.NewDownst
 	}, nil
 }
 
+// authorizeDownstream returns an error if the downstream authorizer, if any,
+// does not authorize the caller to obtain a downstream X509 CA
+func (s *Service) authorizeDownstream(ctx context.Context, entries []*types.Entry) error {
+	if s.da == nil {
+		return nil
+	}
+
+	callerID, _ := rpccontext.CallerID(ctx)
+	req := downstreamwebhook.Request{
+		CallerID:    callerID.String(),
+		TrustDomain: s.td.String(),
+	}
+	for _, entry := range entries {
+		spiffeID, err := api.IDFromProto(ctx, entry.SpiffeId)
+		if err != nil {
+			return err
+		}
+		parentID, err := api.IDFromProto(ctx, entry.ParentId)
+		if err != nil {
+			return err
+		}
+		e := downstreamwebhook.Entry{
+			ID:       entry.Id,
+			SPIFFEID: spiffeID.String(),
+			ParentID: parentID.String(),
+			TTL:      entry.Ttl,
+		}
+		for _, selector := range entry.Selectors {
+			e.Selectors = append(e.Selectors, downstreamwebhook.Selector{
+				Type:  selector.Type,
+				Value: selector.Value,
+			})
+		}
+		req.Entries = append(req.Entries, e)
+	}
+	return s.da.AuthorizeDownstream(ctx, req)
+}
+
+// allowIssuance consumes one signing from the issuance quotas of the caller
+// and the entry, returning an error if either is exhausted
+func (s *Service) allowIssuance(ctx context.Context, entryID string) error {
+	callerID, _ := rpccontext.CallerID(ctx)
+	return s.qt.Allow(callerID.String(), entryID)
+}
+
 func (s Service) fieldsFromJWTSvidParams(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32) logrus.Fields {
 	fields := logrus.Fields{
 		telemetry.TTL: ttl,
@@ -444,3 +576,28 @@ func parseAndCheckCSR(ctx context.Context, csrBytes []byte) (*x509.CertificateRe
 
 	return csr, nil
 }
+
+// makeSignErr logs and returns the error for a failure to sign. The CA not
+// being able to sign yet is reported as Unavailable, so clients can retry.
+// SPIFFE IDs denied by the CA are reported as PermissionDenied.
+func makeSignErr(log logrus.FieldLogger, msg string, err error) error {
+	switch {
+	case errors.Is(err, ca.ErrNotAvailable):
+		return api.MakeErrWithReason(log, codes.Unavailable, api.ReasonCANotAvailable, msg, err)
+	case errors.Is(err, ca.ErrDenied):
+		return api.MakeErr(log, codes.PermissionDenied, "SVID issuance is denied", err)
+	}
+	return api.MakeErr(log, codes.Internal, msg, err)
+}
+
+// makeSignStatus logs and returns the status for a failure to sign, mapped
+// like makeSignErr.
+func makeSignStatus(log logrus.FieldLogger, msg string, err error) *types.Status {
+	switch {
+	case errors.Is(err, ca.ErrNotAvailable):
+		return api.MakeStatus(log, codes.Unavailable, msg, err)
+	case errors.Is(err, ca.ErrDenied):
+		return api.MakeStatus(log, codes.PermissionDenied, "SVID issuance is denied", err)
+	}
+	return api.MakeStatus(log, codes.Internal, msg, err)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"errors"
 	"fmt"
 	"net/url"
@@ -25,9 +26,13 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	svid "github.com/spiffe/spire/pkg/server/api/svid/v1"
+	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/fakes/fakeserverca"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
@@ -494,12 +499,51 @@ func TestServiceMintX509SVID(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "CSR requests a CA certificate",
+			csrTemplate: &x509.CertificateRequest{
+				URIs: []*url.URL{workloadID.URL()},
+				ExtraExtensions: []pkix.Extension{
+					{
+						// Basic constraints with the CA flag set
+						Id:       asn1.ObjectIdentifier{2, 5, 29, 19},
+						Critical: true,
+						Value:    []byte{0x30, 0x03, 0x01, 0x01, 0xff},
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  "CSR is not valid for an X509-SVID: basic constraints extension requests a CA certificate",
+			expectLogs: func(csr []byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Invalid argument: CSR is not valid for an X509-SVID",
+						Data: logrus.Fields{
+							logrus.ErrorKey: "basic constraints extension requests a CA certificate",
+						},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:        "error",
+							telemetry.Type:          "audit",
+							telemetry.StatusCode:    "InvalidArgument",
+							telemetry.StatusMessage: "CSR is not valid for an X509-SVID: basic constraints extension requests a CA certificate",
+							telemetry.Csr:           api.HashByte(csr),
+							telemetry.TTL:           "0",
+						},
+					},
+				}
+			},
+		},
 		{
 			name: "signing fails",
 			csrTemplate: &x509.CertificateRequest{
 				URIs: []*url.URL{workloadID.URL()},
 			},
-			code:        codes.Internal,
+			code:        codes.Unavailable,
 			err:         "failed to sign X509-SVID: X509 CA is not available for signing",
 			failMinting: true,
 			expectLogs: func(csr []byte) []spiretest.LogEntry {
@@ -517,7 +561,7 @@ func TestServiceMintX509SVID(t *testing.T) {
 						Data: logrus.Fields{
 							telemetry.Status:        "error",
 							telemetry.Type:          "audit",
-							telemetry.StatusCode:    "Internal",
+							telemetry.StatusCode:    "Unavailable",
 							telemetry.StatusMessage: "failed to sign X509-SVID: X509 CA is not available for signing",
 							telemetry.Csr:           api.HashByte(csr),
 							telemetry.TTL:           "0",
@@ -554,6 +598,9 @@ func TestServiceMintX509SVID(t *testing.T) {
 			spiretest.AssertLogs(t, test.logHook.AllEntries(), expectLogs)
 			if tt.err != "" {
 				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.err)
+				if tt.failMinting {
+					require.Equal(t, api.ReasonCANotAvailable, api.ErrorReason(err))
+				}
 				require.Nil(t, resp)
 				return
 			}
@@ -758,7 +805,7 @@ func TestServiceMintJWTSVID(t *testing.T) {
 		},
 		{
 			name:        "fails minting",
-			code:        codes.Internal,
+			code:        codes.Unavailable,
 			audience:    []string{"AUDIENCE"},
 			err:         "failed to sign JWT-SVID: JWT key is not available for signing",
 			failMinting: true,
@@ -779,7 +826,7 @@ func TestServiceMintJWTSVID(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
-						telemetry.StatusCode:    "Internal",
+						telemetry.StatusCode:    "Unavailable",
 						telemetry.StatusMessage: "failed to sign JWT-SVID: JWT key is not available for signing",
 						telemetry.Audience:      "AUDIENCE",
 						telemetry.SPIFFEID:      "spiffe://example.org/workload1",
@@ -1036,7 +1083,7 @@ func TestServiceNewJWTSVID(t *testing.T) {
 		},
 		{
 			name:        "fails minting",
-			code:        codes.Internal,
+			code:        codes.Unavailable,
 			audience:    []string{"AUDIENCE"},
 			entry:       entry,
 			err:         "failed to sign JWT-SVID: JWT key is not available for signing",
@@ -1056,7 +1103,7 @@ func TestServiceNewJWTSVID(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:         "error",
 						telemetry.Type:           "audit",
-						telemetry.StatusCode:     "Internal",
+						telemetry.StatusCode:     "Unavailable",
 						telemetry.StatusMessage:  "failed to sign JWT-SVID: JWT key is not available for signing",
 						telemetry.Audience:       "AUDIENCE",
 						telemetry.RegistrationID: "agent-entry-id",
@@ -1121,6 +1168,18 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/dns"},
 		DnsNames: []string{"entryDNS1", "entryDNS2"},
 	}
+	dnsPatternEntry := &types.Entry{
+		Id:       "dns-pattern",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/dns-pattern"},
+		DnsNames: []string{"entryDNS1"},
+	}
+	dnsTemplateEntry := &types.Entry{
+		Id:       "dns-template",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/dns-template"},
+		DnsNames: []string{"entryDNS1", "{{ .PodName }}.{{ .Namespace }}.svc"},
+	}
 	ttlEntry := &types.Entry{
 		Id:       "ttl",
 		ParentId: api.ProtoFromID(agentID),
@@ -1131,7 +1190,8 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 		Id:       "invalid",
 		ParentId: api.ProtoFromID(agentID),
 	}
-	test.ef.entries = []*types.Entry{workloadEntry, dnsEntry, ttlEntry, invalidEntry}
+	test.ef.entries = []*types.Entry{workloadEntry, dnsEntry, dnsPatternEntry, dnsTemplateEntry, ttlEntry, invalidEntry}
+	require.NoError(t, test.ds.SetEntryDNSNamePatterns(context.Background(), dnsPatternEntry.Id, []string{"*.web.example.org"}))
 
 	x509CA := test.ca.X509CA()
 	now := test.ca.Clock().Now().UTC()
@@ -1145,8 +1205,9 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 	require.Error(t, invalidCsrErr)
 
 	type expectResult struct {
-		entry  *types.Entry
-		status *types.Status
+		entry     *types.Entry
+		expectDNS []string
+		status    *types.Status
 	}
 
 	for _, tt := range []struct {
@@ -1160,6 +1221,8 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 		failCallerID   bool
 		fetcherErr     string
 		mutateCSR      func([]byte) []byte
+		csrDNSNames    []string
+		dataStoreErr   error
 		rateLimiterErr error
 	}{
 		{
@@ -1231,6 +1294,118 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 					},
 				}
 			},
+		}, {
+			name:        "requested dns",
+			reqs:        []string{dnsPatternEntry.Id},
+			csrDNSNames: []string{"host1.web.example.org", "host1.example.org", "a.b.web.example.org", "HOST1.WEB.example.org", "*.web.example.org"},
+			expectResults: []*expectResult{
+				{
+					entry:     dnsPatternEntry,
+					expectDNS: []string{"entryDNS1", "host1.web.example.org"},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "success",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "dns-pattern",
+							telemetry.Csr:            api.HashByte(m["dns-pattern"]),
+							telemetry.ExpiresAt:      expiresAtFromCAStr,
+						},
+					},
+				}
+			},
+		}, {
+			name:         "requested dns fails to fetch patterns",
+			reqs:         []string{dnsPatternEntry.Id},
+			csrDNSNames:  []string{"host1.web.example.org"},
+			dataStoreErr: errors.New("oh no"),
+			expectResults: []*expectResult{
+				{
+					status: &types.Status{
+						Code:    int32(codes.Internal),
+						Message: "failed to fetch DNS name patterns: oh no",
+					},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Failed to fetch DNS name patterns",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: "dns-pattern",
+							logrus.ErrorKey:          "oh no",
+							telemetry.SPIFFEID:       "spiffe://example.org/dns-pattern",
+						},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "dns-pattern",
+							telemetry.Csr:            api.HashByte(m["dns-pattern"]),
+							telemetry.StatusCode:     "Internal",
+							telemetry.StatusMessage:  "failed to fetch DNS name patterns: oh no",
+						},
+					},
+				}
+			},
+		}, {
+			name:        "requested dns from template",
+			reqs:        []string{dnsTemplateEntry.Id},
+			csrDNSNames: []string{"api-0.payments.svc", "api-0.svc", "api-0.payments.example.org"},
+			expectResults: []*expectResult{
+				{
+					entry:     dnsTemplateEntry,
+					expectDNS: []string{"entryDNS1", "api-0.payments.svc"},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "success",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "dns-template",
+							telemetry.Csr:            api.HashByte(m["dns-template"]),
+							telemetry.ExpiresAt:      expiresAtFromCAStr,
+						},
+					},
+				}
+			},
+		}, {
+			name: "dns template without requested dns",
+			reqs: []string{dnsTemplateEntry.Id},
+			expectResults: []*expectResult{
+				{
+					entry:     dnsTemplateEntry,
+					expectDNS: []string{"entryDNS1"},
+				},
+			},
+			expectLogs: func(m map[string][]byte) []spiretest.LogEntry {
+				return []spiretest.LogEntry{
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "success",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "dns-template",
+							telemetry.Csr:            api.HashByte(m["dns-template"]),
+							telemetry.ExpiresAt:      expiresAtFromCAStr,
+						},
+					},
+				}
+			},
 		}, {
 			name: "keep request order",
 			reqs: []string{workloadEntry.Id, invalidEntry.Id, dnsEntry.Id},
@@ -1614,7 +1789,7 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 			expectResults: []*expectResult{
 				{
 					status: &types.Status{
-						Code:    int32(codes.Internal),
+						Code:    int32(codes.Unavailable),
 						Message: "failed to sign X509-SVID: X509 CA is not available for signing",
 					},
 				},
@@ -1639,7 +1814,7 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 							telemetry.Type:           "audit",
 							telemetry.RegistrationID: "workload",
 							telemetry.Csr:            api.HashByte(m["workload"]),
-							telemetry.StatusCode:     "Internal",
+							telemetry.StatusCode:     "Unavailable",
 							telemetry.StatusMessage:  "failed to sign X509-SVID: X509 CA is not available for signing",
 						},
 					},
@@ -1663,12 +1838,15 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 
 			test.withCallerID = !tt.failCallerID
 			test.ef.err = tt.fetcherErr
+			if tt.dataStoreErr != nil {
+				test.ds.SetNextError(tt.dataStoreErr)
+			}
 
 			csrMap := make(map[string][]byte, len(tt.reqs))
 			var params []*svidv1.NewX509SVIDParams
 			for _, entryID := range tt.reqs {
 				// Create CSR
-				csr := createCSR(t, &x509.CertificateRequest{})
+				csr := createCSR(t, &x509.CertificateRequest{DNSNames: tt.csrDNSNames})
 				if tt.mutateCSR != nil {
 					csr = tt.mutateCSR(csr)
 				}
@@ -1732,11 +1910,15 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 				require.Equal(t, expiresAt, svid.NotAfter)
 				require.Equal(t, expiresAt.UTC().Unix(), result.Svid.ExpiresAt)
 
-				require.Equal(t, entry.DnsNames, svid.DNSNames)
+				expectDNS := entry.DnsNames
+				if expect.expectDNS != nil {
+					expectDNS = expect.expectDNS
+				}
+				require.Equal(t, expectDNS, svid.DNSNames)
 
 				expectedSubject := &pkix.Name{Country: []string{"US"}, Organization: []string{"SPIRE"}}
-				if len(entry.DnsNames) > 0 {
-					name := entry.DnsNames[0]
+				if len(expectDNS) > 0 {
+					name := expectDNS[0]
 
 					expectedSubject.CommonName = name
 					require.Equal(t, name, svid.Subject.CommonName)
@@ -2002,6 +2184,343 @@ func TestNewDownstreamX509CA(t *testing.T) {
 	}
 }
 
+func TestNewDownstreamX509CAAuthorizer(t *testing.T) {
+	authorizer := &fakeDownstreamAuthorizer{}
+	test := setupServiceTestWithConfig(t, func(c *svid.Config) {
+		c.DownstreamAuthorizer = authorizer
+	})
+	defer test.Cleanup()
+	test.withCallerID = true
+	test.rateLimiter.count = 1
+	test.downstream.entries = []*types.Entry{
+		{
+			Id:         "downstreamCA1",
+			ParentId:   api.ProtoFromID(agentID),
+			SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/downstream"},
+			Selectors:  []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			Ttl:        3600,
+			Downstream: true,
+		},
+	}
+	_, err := test.ds.AppendBundle(context.Background(), &common.Bundle{
+		TrustDomainId: td.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: []byte("RootCa1")}},
+	})
+	require.NoError(t, err)
+	csr := createCSR(t, &x509.CertificateRequest{})
+
+	authorizer.err = errors.New("path /payments is not delegated")
+	_, err = test.client.NewDownstreamX509CA(context.Background(), &svidv1.NewDownstreamX509CARequest{Csr: csr})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "downstream X.509 CA signing is not authorized: path /payments is not delegated")
+	require.Equal(t, downstreamwebhook.Request{
+		CallerID:    agentID.String(),
+		TrustDomain: "example.org",
+		Entries: []downstreamwebhook.Entry{
+			{
+				ID:        "downstreamCA1",
+				SPIFFEID:  "spiffe://example.org/downstream",
+				ParentID:  agentID.String(),
+				Selectors: []downstreamwebhook.Selector{{Type: "unix", Value: "uid:1000"}},
+				TTL:       3600,
+			},
+		},
+	}, authorizer.req)
+
+	authorizer.err = nil
+	resp, err := test.client.NewDownstreamX509CA(context.Background(), &svidv1.NewDownstreamX509CARequest{Csr: csr})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.CaCertChain)
+}
+
+func TestServiceDenylist(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+	test.withCallerID = true
+	ctx := context.Background()
+
+	deniedEntry := &types.Entry{
+		Id:       "denied-entry-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/compromised/workload"},
+	}
+	allowedEntry := &types.Entry{
+		Id:       "allowed-entry-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload1"},
+	}
+	test.ef.entries = []*types.Entry{deniedEntry, allowedEntry}
+	require.NoError(t, test.denylist.SetPatterns([]string{"spiffe://example.org/compromised/*"}))
+
+	const deniedMsg = `SVID issuance is denied: SPIFFE ID matches denylist pattern "spiffe://example.org/compromised/*"`
+	deniedID := spiffeid.RequireFromPath(td, "/compromised/workload")
+
+	// Minting an X509-SVID
+	_, err := test.client.MintX509SVID(ctx, &svidv1.MintX509SVIDRequest{
+		Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{deniedID.URL()}}),
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, deniedMsg)
+
+	// Minting a JWT-SVID
+	_, err = test.client.MintJWTSVID(ctx, &svidv1.MintJWTSVIDRequest{
+		Id:       api.ProtoFromID(deniedID),
+		Audience: []string{"AUDIENCE"},
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, deniedMsg)
+
+	// Signing a JWT-SVID for an entry
+	test.rateLimiter.count = 1
+	_, err = test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+		EntryId:  deniedEntry.Id,
+		Audience: []string{"AUDIENCE"},
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, deniedMsg)
+
+	// Signing X509-SVIDs for entries only fails for the denied entry
+	test.rateLimiter.count = 2
+	resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{
+		Params: []*svidv1.NewX509SVIDParams{
+			{EntryId: deniedEntry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+			{EntryId: allowedEntry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, int32(codes.PermissionDenied), resp.Results[0].Status.Code)
+	require.Equal(t, deniedMsg, resp.Results[0].Status.Message)
+	require.Nil(t, resp.Results[0].Svid)
+	require.Equal(t, int32(codes.OK), resp.Results[1].Status.Code)
+	require.NotNil(t, resp.Results[1].Svid)
+
+	// Signing a downstream X509 CA is denied for a denied caller
+	require.NoError(t, test.denylist.SetPatterns([]string{agentID.String()}))
+	test.downstream.entries = []*types.Entry{
+		{
+			Id:         "downstream-entry-id",
+			ParentId:   api.ProtoFromID(agentID),
+			SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/downstream"},
+			Downstream: true,
+		},
+	}
+	test.rateLimiter.count = 1
+	_, err = test.client.NewDownstreamX509CA(ctx, &svidv1.NewDownstreamX509CARequest{
+		Csr: createCSR(t, &x509.CertificateRequest{}),
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied,
+		fmt.Sprintf("SVID issuance is denied: SPIFFE ID matches denylist pattern %q", agentID.String()))
+	test.downstream.entries = nil
+
+	// Issuance resumes once the pattern is removed
+	require.NoError(t, test.denylist.SetPatterns(nil))
+	test.rateLimiter.count = 1
+	_, err = test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+		EntryId:  deniedEntry.Id,
+		Audience: []string{"AUDIENCE"},
+	})
+	require.NoError(t, err)
+}
+
+func TestServiceIDPathPolicy(t *testing.T) {
+	policy, err := api.NewIDPathPolicy([]string{"/ns/"}, nil)
+	require.NoError(t, err)
+
+	test := setupServiceTestWithConfig(t, func(c *svid.Config) {
+		c.IDPathPolicy = policy
+	})
+	defer test.Cleanup()
+	test.withCallerID = true
+	ctx := context.Background()
+
+	deniedEntry := &types.Entry{
+		Id:       "denied-entry-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload1"},
+	}
+	allowedEntry := &types.Entry{
+		Id:       "allowed-entry-id",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/foo/workload"},
+	}
+	test.ef.entries = []*types.Entry{deniedEntry, allowedEntry}
+
+	const deniedMsg = `SVID issuance is denied: path "/workload1" is not allowed by the SPIFFE ID path policy`
+	deniedID := spiffeid.RequireFromPath(td, "/workload1")
+
+	_, err = test.client.MintX509SVID(ctx, &svidv1.MintX509SVIDRequest{
+		Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{deniedID.URL()}}),
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, deniedMsg)
+
+	_, err = test.client.MintJWTSVID(ctx, &svidv1.MintJWTSVIDRequest{
+		Id:       api.ProtoFromID(deniedID),
+		Audience: []string{"AUDIENCE"},
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, deniedMsg)
+
+	test.rateLimiter.count = 2
+	resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{
+		Params: []*svidv1.NewX509SVIDParams{
+			{EntryId: deniedEntry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+			{EntryId: allowedEntry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, int32(codes.PermissionDenied), resp.Results[0].Status.Code)
+	require.Equal(t, deniedMsg, resp.Results[0].Status.Message)
+	require.Equal(t, int32(codes.OK), resp.Results[1].Status.Code)
+	require.NotNil(t, resp.Results[1].Svid)
+}
+
+func TestServiceIssuanceQuotas(t *testing.T) {
+	clk := clock.NewMock(t)
+	metrics := fakemetrics.New()
+	test := setupServiceTestWithConfig(t, func(c *svid.Config) {
+		c.Quotas = svid.IssuanceQuotas{
+			SigningsPerMinutePerAgent: 3,
+			SigningsPerMinutePerEntry: 2,
+		}
+		c.Metrics = metrics
+		c.Clock = clk
+	})
+	defer test.Cleanup()
+	test.withCallerID = true
+	ctx := context.Background()
+
+	entryA := &types.Entry{
+		Id:       "entry-a",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-a"},
+	}
+	entryB := &types.Entry{
+		Id:       "entry-b",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload-b"},
+	}
+	test.ef.entries = []*types.Entry{entryA, entryB}
+
+	newX509SVIDs := func(entryIDs ...string) []codes.Code {
+		var params []*svidv1.NewX509SVIDParams
+		for _, entryID := range entryIDs {
+			params = append(params, &svidv1.NewX509SVIDParams{
+				EntryId: entryID,
+				Csr:     createCSR(t, &x509.CertificateRequest{}),
+			})
+		}
+		test.rateLimiter.count = len(params)
+		resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{Params: params})
+		require.NoError(t, err)
+		var results []codes.Code
+		for _, result := range resp.Results {
+			results = append(results, codes.Code(result.Status.Code))
+		}
+		return results
+	}
+
+	// The third signing for entry A exceeds the entry quota
+	require.Equal(t, []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted}, newX509SVIDs("entry-a", "entry-a", "entry-a"))
+
+	// The agent has one signing left
+	test.rateLimiter.count = 1
+	_, err := test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{EntryId: "entry-b", Audience: []string{"AUDIENCE"}})
+	require.NoError(t, err)
+	test.rateLimiter.count = 1
+	_, err = test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{EntryId: "entry-b", Audience: []string{"AUDIENCE"}})
+	spiretest.RequireGRPCStatus(t, err, codes.ResourceExhausted, `issuance quota exceeded: agent "spiffe://example.org/agent" exceeded its quota of 3 signings per minute`)
+
+	// The quotas are replenished over time
+	clk.Add(time.Minute)
+	require.Equal(t, []codes.Code{codes.OK, codes.OK, codes.OK}, newX509SVIDs("entry-a", "entry-b", "entry-b"))
+
+	var exceeded []string
+	for _, metric := range metrics.AllMetrics() {
+		if metric.Type == fakemetrics.IncrCounterWithLabelsType {
+			require.Equal(t, []string{"svid", "issuance_quota", "exceeded"}, metric.Key)
+			exceeded = append(exceeded, metric.Labels[0].Value)
+		}
+	}
+	require.Equal(t, []string{"entry", "agent"}, exceeded)
+}
+
+func TestServiceAudienceRestrictions(t *testing.T) {
+	test := setupServiceTestWithConfig(t, func(c *svid.Config) {
+		c.AudienceRestrictions = []svid.AudienceRestriction{
+			{Name: "payments", PathPrefix: "/payments/", AllowedAudiences: []string{"bank", "ledger"}},
+			{Name: "ledger-only", PathPrefix: "/payments/ledger/", AllowedAudiences: []string{"ledger"}},
+		}
+	})
+	defer test.Cleanup()
+	test.withCallerID = true
+	ctx := context.Background()
+
+	test.ef.entries = []*types.Entry{
+		{
+			Id:       "payments",
+			ParentId: api.ProtoFromID(agentID),
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/payments/api"},
+		},
+		{
+			Id:       "ledger",
+			ParentId: api.ProtoFromID(agentID),
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/payments/ledger/writer"},
+		},
+		{
+			Id:       "unrestricted",
+			ParentId: api.ProtoFromID(agentID),
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		entryID   string
+		audience  []string
+		expectErr string
+	}{
+		{
+			name:     "allowed audiences",
+			entryID:  "payments",
+			audience: []string{"bank", "ledger"},
+		},
+		{
+			name:      "audience outside of the list",
+			entryID:   "payments",
+			audience:  []string{"bank", "attacker"},
+			expectErr: `audience not allowed for entry: audience "attacker" is not allowed by jwt_audience_restriction "payments"`,
+		},
+		{
+			name:     "entry under several restrictions",
+			entryID:  "ledger",
+			audience: []string{"ledger"},
+		},
+		{
+			name:      "entry under several restrictions must satisfy all",
+			entryID:   "ledger",
+			audience:  []string{"bank"},
+			expectErr: `audience not allowed for entry: audience "bank" is not allowed by jwt_audience_restriction "ledger-only"`,
+		},
+		{
+			name:     "unrestricted entry",
+			entryID:  "unrestricted",
+			audience: []string{"anything"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.rateLimiter.count = 1
+			resp, err := test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+				EntryId:  tt.entryID,
+				Audience: tt.audience,
+			})
+			if tt.expectErr != "" {
+				spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, resp.Svid)
+		})
+	}
+}
+
 type serviceTest struct {
 	client       svidv1.SVIDClient
 	ef           *entryFetcher // Stores entries explicitly fetched using FetchAuthorizedEntries
@@ -2011,6 +2530,7 @@ type serviceTest struct {
 	logHook      *test.Hook
 	rateLimiter  *fakeRateLimiter
 	withCallerID bool
+	denylist     *ca.Denylist
 	done         func()
 }
 
@@ -2019,19 +2539,29 @@ func (c *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T) *serviceTest {
+	return setupServiceTestWithConfig(t, nil)
+}
+
+func setupServiceTestWithConfig(t *testing.T, configure func(*svid.Config)) *serviceTest {
 	trustDomain := spiffeid.RequireTrustDomainFromString("example.org")
-	ca := fakeserverca.New(t, trustDomain, &fakeserverca.Options{})
+	denylist, err := ca.NewDenylist(nil)
+	require.NoError(t, err)
+	serverCA := fakeserverca.New(t, trustDomain, &fakeserverca.Options{Denylist: denylist})
 	ef := &entryFetcher{}
 	downstream := &entryFetcher{}
 	ds := fakedatastore.New(t)
 
 	rateLimiter := &fakeRateLimiter{}
-	service := svid.New(svid.Config{
+	config := svid.Config{
 		EntryFetcher: ef,
-		ServerCA:     ca,
+		ServerCA:     serverCA,
 		TrustDomain:  trustDomain,
 		DataStore:    ds,
-	})
+	}
+	if configure != nil {
+		configure(&config)
+	}
+	service := svid.New(config)
 
 	log, logHook := test.NewNullLogger()
 	registerFn := func(s *grpc.Server) {
@@ -2039,12 +2569,13 @@ func setupServiceTest(t *testing.T) *serviceTest {
 	}
 
 	test := &serviceTest{
-		ca:          ca,
+		ca:          serverCA,
 		ef:          ef,
 		downstream:  downstream,
 		ds:          ds,
 		logHook:     logHook,
 		rateLimiter: rateLimiter,
+		denylist:    denylist,
 	}
 
 	ppMiddleware := middleware.Preprocess(func(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
@@ -2137,6 +2668,16 @@ func (f *entryFetcher) FetchAuthorizedEntries(ctx context.Context, agentID spiff
 	return f.entries, nil
 }
 
+type fakeDownstreamAuthorizer struct {
+	req downstreamwebhook.Request
+	err error
+}
+
+func (a *fakeDownstreamAuthorizer) AuthorizeDownstream(ctx context.Context, req downstreamwebhook.Request) error {
+	a.req = req
+	return a.err
+}
+
 type fakeRateLimiter struct {
 	count int
 	err   error
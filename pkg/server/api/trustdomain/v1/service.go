@@ -109,7 +109,7 @@ func (s *Service) GetFederationRelationship(ctx context.Context, req *trustdomai
 
 	// if the entry is not found, FetchFederationRelationship returns nil, nil
 	if dsResp == nil {
-		return nil, api.MakeErr(log, codes.NotFound, "federation relationship does not exist", err)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonFederationRelationshipNotFound, "federation relationship does not exist", err)
 	}
 
 	tFederationRelationship, err := api.FederationRelationshipToProto(dsResp, req.OutputMask)
@@ -206,7 +206,7 @@ func (s *Service) RefreshBundle(ctx context.Context, req *trustdomainv1.RefreshB
 		return nil, api.MakeErr(log, codes.Internal, "failed to refresh bundle", err)
 	}
 	if !isManagedByBm {
-		return nil, api.MakeErr(log, codes.NotFound, fmt.Sprintf("no relationship with trust domain %q", trustDomain), nil)
+		return nil, api.MakeErrWithReason(log, codes.NotFound, api.ReasonFederationRelationshipNotFound, fmt.Sprintf("no relationship with trust domain %q", trustDomain), nil)
 	}
 
 	log.Debug("Bundle refreshed")
@@ -0,0 +1,146 @@
+package attestationwebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTimeout is the timeout applied to webhook requests when the
+	// webhook configuration does not set one.
+	DefaultTimeout = 5 * time.Second
+
+	// queueSize is the number of events that can be pending delivery before
+	// new events are dropped.
+	queueSize = 1024
+)
+
+// Result is the result of a node attestation
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+// Selector is a selector of an attested agent
+type Selector struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Event is the JSON body posted to the webhooks for every node attestation.
+type Event struct {
+	Time            time.Time  `json:"time"`
+	Result          Result     `json:"result"`
+	AttestationType string     `json:"attestation_type"`
+	AgentID         string     `json:"agent_id,omitempty"`
+	Selectors       []Selector `json:"selectors,omitempty"`
+	Address         string     `json:"address,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// Notifier is notified of node attestation events
+type Notifier interface {
+	// Notify queues the event for delivery. It does not block.
+	Notify(event Event)
+}
+
+// Config is the configuration of a webhook
+type Config struct {
+	// Name identifies the webhook in logs
+	Name string
+
+	// URL receives a POST request with the JSON encoded event
+	URL string
+
+	// Timeout for each request. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Webhooks delivers node attestation events to a set of webhooks
+type Webhooks struct {
+	log    logrus.FieldLogger
+	client *http.Client
+	hooks  []Config
+	events chan Event
+}
+
+// New creates webhooks for the given configurations. Events are delivered
+// while Run is running.
+func New(log logrus.FieldLogger, hooks []Config) *Webhooks {
+	return &Webhooks{
+		log:    log,
+		client: &http.Client{},
+		hooks:  hooks,
+		events: make(chan Event, queueSize),
+	}
+}
+
+// Notify queues the event for delivery. If the queue is full, the event is
+// dropped.
+func (w *Webhooks) Notify(event Event) {
+	select {
+	case w.events <- event:
+	default:
+		w.log.WithField("agent_id", event.AgentID).Warn("Attestation webhook queue is full; dropping event")
+	}
+}
+
+// Run delivers the queued events until the context is done.
+func (w *Webhooks) Run(ctx context.Context) error {
+	for {
+		select {
+		case event := <-w.events:
+			w.deliver(ctx, event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (w *Webhooks) deliver(ctx context.Context, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		w.log.WithError(err).Error("Failed to marshal attestation event")
+		return
+	}
+
+	for _, hook := range w.hooks {
+		if err := w.post(ctx, hook, body); err != nil {
+			w.log.WithError(err).WithField("webhook", hook.Name).Warn("Failed to deliver attestation event")
+		}
+	}
+}
+
+func (w *Webhooks) post(ctx context.Context, hook Config, body []byte) error {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package attestationwebhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	received := make(chan attestationwebhook.Event, 2)
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var event attestationwebhook.Event
+		assert.NoError(t, json.Unmarshal(body, &event))
+		received <- event
+	}))
+	defer okServer.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	log, hook := test.NewNullLogger()
+	webhooks := attestationwebhook.New(log, []attestationwebhook.Config{
+		{Name: "failing", URL: failingServer.URL},
+		{Name: "ok", URL: okServer.URL, Timeout: time.Second},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- webhooks.Run(ctx) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	success := attestationwebhook.Event{
+		Time:            time.Unix(1000, 0).UTC(),
+		Result:          attestationwebhook.ResultSuccess,
+		AttestationType: "join_token",
+		AgentID:         "spiffe://example.org/spire/agent/join_token/token",
+		Selectors:       []attestationwebhook.Selector{{Type: "join_token", Value: "token"}},
+		Address:         "127.0.0.1:1234",
+	}
+	failure := attestationwebhook.Event{
+		Time:            time.Unix(1001, 0).UTC(),
+		Result:          attestationwebhook.ResultFailure,
+		AttestationType: "join_token",
+		Error:           "failed to attest: join token does not exist or has already been used",
+	}
+	webhooks.Notify(success)
+	webhooks.Notify(failure)
+
+	assert.Equal(t, success, <-received)
+	assert.Equal(t, failure, <-received)
+
+	// Delivery to the failing webhook is logged
+	require.Eventually(t, func() bool {
+		n := 0
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.WarnLevel && entry.Data["webhook"] == "failing" {
+				n++
+			}
+		}
+		return n == 2
+	}, time.Second, 10*time.Millisecond)
+}
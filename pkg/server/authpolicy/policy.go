@@ -18,6 +18,8 @@ const (
 	allowIfDownstreamKey = "allow_if_downstream"
 	allowIfAgentKey      = "allow_if_agent"
 	allowIfLocalKey      = "allow_if_local"
+
+	allowIfNamespacedAdminKey = "allow_if_namespaced_admin"
 )
 
 // Engine drives policy management.
@@ -54,6 +56,10 @@ type Result struct {
 	AllowIfLocal      bool `json:"allow_if_local"`
 	AllowIfDownstream bool `json:"allow_if_downstream"`
 	AllowIfAgent      bool `json:"allow_if_agent"`
+
+	// AllowIfNamespacedAdmin, if true, authorizes admin callers scoped to an
+	// entry namespace when AllowIfAdmin is also true.
+	AllowIfNamespacedAdmin bool `json:"allow_if_namespaced_admin"`
 }
 
 // NewEngineFromConfigOrDefault returns a new policy engine. Or if no
@@ -173,5 +179,13 @@ func (e *Engine) Eval(ctx context.Context, input Input) (result Result, err erro
 		return Result{}, err
 	}
 
+	// The namespaced admin value is optional so policies written before entry
+	// namespaces keep working. Namespaced admins are denied by them.
+	if _, ok := resultMap[allowIfNamespacedAdminKey]; ok {
+		if result.AllowIfNamespacedAdmin, err = getBoolValue(allowIfNamespacedAdminKey); err != nil {
+			return Result{}, err
+		}
+	}
+
 	return result, nil
 }
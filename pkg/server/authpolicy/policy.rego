@@ -12,6 +12,9 @@ package spire
 #   only if the caller has a downstream SPIFFE ID
 # - `allow_if_agent`: a boolean that if true, will authorize the call only if
 #   the caller is an agent
+# - `allow_if_namespaced_admin`: a boolean that if true, will authorize admin
+#   callers scoped to an entry namespace when `allow_if_admin` is also true.
+#   Optional, defaults to false.
 
 result = {
   "allow": allow, 
@@ -19,6 +22,7 @@ result = {
   "allow_if_local": allow_if_local,
   "allow_if_downstream": allow_if_downstream,
   "allow_if_agent": allow_if_agent,
+  "allow_if_namespaced_admin": allow_if_namespaced_admin,
 }
 
 
@@ -28,6 +32,7 @@ default allow_if_admin = false
 default allow_if_downstream = false
 default allow_if_local = false
 default allow_if_agent = false
+default allow_if_namespaced_admin = false
 default allow = false 
 
 
@@ -65,6 +70,14 @@ allow_if_agent = true {
     r.allow_agent
 }
 
+# Namespaced admin allow check
+allow_if_namespaced_admin = true { 
+    r := data.apis[_]
+    r.full_method == input.full_method 
+    
+    r.allow_namespaced_admin
+}
+
 # Any allow check
 allow = true { 
     r := data.apis[_]
@@ -255,6 +255,36 @@ func TestPolicy(t *testing.T) {
 	}
 }
 
+// TestDefaultPolicyNamespacedAdmin tests that the default policy only lets
+// admins scoped to an entry namespace call the entry API methods that enforce
+// namespaces.
+func TestDefaultPolicyNamespacedAdmin(t *testing.T) {
+	ctx := context.Background()
+	pe, err := authpolicy.DefaultAuthPolicy(ctx)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		fullMethod string
+		expect     bool
+	}{
+		{fullMethod: "/spire.api.server.entry.v1.Entry/ListEntries", expect: true},
+		{fullMethod: "/spire.api.server.entry.v1.Entry/BatchCreateEntry", expect: true},
+		{fullMethod: "/spire.api.server.svid.v1.SVID/MintX509SVID", expect: false},
+		{fullMethod: "/spire.api.server.agent.v1.Agent/CreateJoinToken", expect: false},
+		{fullMethod: "/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle", expect: false},
+		{fullMethod: "/spire.private.server.agentbootstrap.AgentBootstrap/MintAgentX509SVID", expect: false},
+		{fullMethod: "/spire.private.server.entryrestore.EntryRestore/RestoreEntry", expect: false},
+	} {
+		tt := tt
+		t.Run(tt.fullMethod, func(t *testing.T) {
+			res, err := pe.Eval(ctx, authpolicy.Input{FullMethod: tt.fullMethod})
+			require.NoError(t, err)
+			require.True(t, res.AllowIfAdmin)
+			require.Equal(t, tt.expect, res.AllowIfNamespacedAdmin)
+		})
+	}
+}
+
 // TestNewEngineFromConfig tests creation of a policy engine from a EngineConfig
 // using NewEngineFromConfigOrDefault where the construction of the EngineConfig may not
 // be correct, this details the handling of different edge cases in the
@@ -83,6 +83,15 @@ func (u *bundleUpdater) UpdateBundle(ctx context.Context) (*bundleutil.Bundle, *
 		return localFederatedBundleOrNil, nil, nil
 	}
 
+	// A bundle with a lower sequence number than the local one is older
+	// than it, e.g. served by a lagging replica of the bundle endpoint.
+	// Bundles without a sequence number cannot be compared.
+	if localFederatedBundleOrNil != nil && fetchedFederatedBundle.SequenceNumber() > 0 &&
+		fetchedFederatedBundle.SequenceNumber() < localFederatedBundleOrNil.SequenceNumber() {
+		return localFederatedBundleOrNil, nil, fmt.Errorf("fetched federated bundle is stale: sequence number %d is lower than the local sequence number %d",
+			fetchedFederatedBundle.SequenceNumber(), localFederatedBundleOrNil.SequenceNumber())
+	}
+
 	_, err = u.ds.SetBundle(ctx, fetchedFederatedBundle.Proto())
 	if err != nil {
 		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to store fetched federated bundle: %w", err)
@@ -21,6 +21,10 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 	bundle1 := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "bundle1"))
 	bundle2 := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "bundle2"))
 	bundle2.SetRefreshHint(time.Minute)
+	sequencedBundle1 := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "bundle1"))
+	sequencedBundle1.SetSequenceNumber(5)
+	sequencedBundle2 := bundleutil.BundleFromRootCA(trustDomain, createCACertificate(t, "bundle2"))
+	sequencedBundle2.SetSequenceNumber(4)
 
 	testCases := []struct {
 		// name of the test
@@ -63,6 +67,27 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 				bundle: bundle2,
 			},
 		},
+		{
+			name:           "bundle is stale",
+			trustDomain:    trustDomain,
+			localBundle:    sequencedBundle1,
+			endpointBundle: nil,
+			storedBundle:   sequencedBundle1,
+			client: fakeClient{
+				bundle: sequencedBundle2,
+			},
+			err: "fetched federated bundle is stale: sequence number 4 is lower than the local sequence number 5",
+		},
+		{
+			name:           "bundle without sequence number",
+			trustDomain:    trustDomain,
+			localBundle:    sequencedBundle1,
+			endpointBundle: bundle2,
+			storedBundle:   bundle2,
+			client: fakeClient{
+				bundle: bundle2,
+			},
+		},
 		{
 			name:           "bundle fails to download",
 			trustDomain:    trustDomain,
@@ -6,8 +6,10 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -32,6 +34,10 @@ const (
 	DefaultJWTSVIDTTL = time.Minute * 5
 )
 
+// ErrNotAvailable is wrapped by the errors returned when the CA has no X509
+// CA or JWT key to sign with, e.g. before the first one has been prepared.
+var ErrNotAvailable = errors.New("not available for signing")
+
 // ServerCA is an interface for Server CAs
 type ServerCA interface {
 	SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.Certificate, error)
@@ -57,6 +63,11 @@ type X509SVIDParams struct {
 
 	// Subject of the SVID. Default subject is used if it is empty.
 	Subject pkix.Name
+
+	// CSRExtensions are the extensions of the CSR. Only the extensions
+	// allowed by the CSR extension allowlist of the CA are copied into the
+	// SVID.
+	CSRExtensions []pkix.Extension
 }
 
 // X509CASVIDParams are parameters relevant to X509 CA SVID creation
@@ -70,6 +81,11 @@ type X509CASVIDParams struct {
 	// TTL is the desired time-to-live of the SVID. Regardless of the TTL, the
 	// lifetime of the certificate will be capped to that of the signing cert.
 	TTL time.Duration
+
+	// DownstreamID, if set, is the SPIFFE ID of the downstream workload the
+	// CA SVID is signed for. Like SpiffeID, it is checked against the
+	// denylist of the CA.
+	DownstreamID spiffeid.ID
 }
 
 // JWTSVIDParams are parameters relevant to JWT SVID creation
@@ -119,9 +135,40 @@ type Config struct {
 	Clock         clock.Clock
 	CASubject     pkix.Name
 	HealthChecker health.Checker
+
+	// KeyManagerName is the name of the KeyManager holding the keys of the
+	// CA. It labels the signing latency metrics.
+	KeyManagerName string
+
+	// CAPathLen, if set, is the maximum number of downstream CA levels
+	// allowed below the server CA, regardless of the pathLenConstraint of
+	// the server CA certificate.
+	CAPathLen *int
+
+	// DownstreamCAPathLen, if set, is the pathLenConstraint set on the CA
+	// SVIDs signed for downstream servers. It is capped by the path length
+	// allowed below the server CA.
+	DownstreamCAPathLen *int
+
+	// CSRExtensionAllowlist lists the CSR extensions copied into
+	// X509-SVIDs. No extension is copied if empty.
+	CSRExtensionAllowlist CSRExtensionAllowlist
+
+	// MaxSVIDTTL, if positive, caps the TTL of the X509-SVIDs and JWT-SVIDs
+	// signed by the CA, including the default TTLs.
+	MaxSVIDTTL time.Duration
+
+	// Denylist, if set, blocks the signing of SVIDs for matching SPIFFE IDs.
+	// It applies to every SVID signed by the CA, including agent SVIDs and
+	// downstream CA SVIDs.
+	Denylist *Denylist
 }
 
 type CA struct {
+	// pendingX509SVIDs is the number of X509-SVIDs currently being signed.
+	// It is accessed atomically and kept first for 64-bit alignment.
+	pendingX509SVIDs int64
+
 	c Config
 
 	mu     sync.RWMutex
@@ -182,18 +229,35 @@ func (ca *CA) SetJWTKey(jwtKey *JWTKey) {
 	ca.jwtKey = jwtKey
 }
 
-func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) (_ []*x509.Certificate, err error) {
+	defer ca.measureSign(telemetry.X509SVID, time.Now(), &err)
+
+	if err := ca.c.Denylist.check(params.SpiffeID); err != nil {
+		return nil, err
+	}
+
 	x509CA := ca.X509CA()
 	if x509CA == nil {
-		return nil, errs.New("X509 CA is not available for signing")
+		return nil, fmt.Errorf("X509 CA is %w", ErrNotAvailable)
 	}
 
+	telemetry_server.SetServerCAPendingX509SVIDGauge(ca.c.Metrics, atomic.AddInt64(&ca.pendingX509SVIDs, 1))
+	defer func() {
+		telemetry_server.SetServerCAPendingX509SVIDGauge(ca.c.Metrics, atomic.AddInt64(&ca.pendingX509SVIDs, -1))
+	}()
+
 	if params.TTL <= 0 {
 		params.TTL = ca.c.X509SVIDTTL
 	}
+	params.TTL = ca.capTTL(params.TTL)
 
 	notBefore, notAfter := ca.capLifetime(params.TTL, x509CA.Certificate.NotAfter)
 
+	params.CSRExtensions, err = ca.c.CSRExtensionAllowlist.filter(params.CSRExtensions)
+	if err != nil {
+		return nil, errs.New("unable to filter CSR extensions: %v", err)
+	}
+
 	x509SVID, err := signX509SVID(ca.c.TrustDomain, x509CA, params, notBefore, notAfter)
 	if err != nil {
 		return nil, err
@@ -203,10 +267,21 @@ func (ca *CA) SignX509SVID(ctx context.Context, params X509SVIDParams) ([]*x509.
 	return x509SVID, nil
 }
 
-func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) (_ []*x509.Certificate, err error) {
+	defer ca.measureSign(telemetry.X509CASVID, time.Now(), &err)
+
+	if err := ca.c.Denylist.check(params.SpiffeID); err != nil {
+		return nil, err
+	}
+	if !params.DownstreamID.IsZero() {
+		if err := ca.c.Denylist.check(params.DownstreamID); err != nil {
+			return nil, err
+		}
+	}
+
 	x509CA := ca.X509CA()
 	if x509CA == nil {
-		return nil, errs.New("X509 CA is not available for signing")
+		return nil, fmt.Errorf("X509 CA is %w", ErrNotAvailable)
 	}
 
 	if params.TTL <= 0 {
@@ -229,6 +304,13 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	if err != nil {
 		return nil, err
 	}
+	pathLen, err := ca.downstreamPathLen(x509CA)
+	if err != nil {
+		return nil, err
+	}
+	if pathLen >= 0 {
+		setPathLen(template, pathLen)
+	}
 	// Explicitly set the AKI on the signed certificate, otherwise it won't be
 	// added if the subject and issuer match name matches (unlikely due to the
 	// OU override below, but just to be safe).
@@ -244,10 +326,38 @@ func (ca *CA) SignX509CASVID(ctx context.Context, params X509CASVIDParams) ([]*x
 	return makeSVIDCertChain(x509CA, cert), nil
 }
 
-func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, error) {
+// downstreamPathLen returns the pathLenConstraint for a CA SVID signed by
+// the X509 CA, or -1 if unconstrained. Fails if the X509 CA is not allowed
+// to sign CA certificates.
+func (ca *CA) downstreamPathLen(x509CA *X509CA) (int, error) {
+	allowed := pathLenOf(x509CA.Certificate)
+	if ca.c.CAPathLen != nil {
+		allowed = minPathLen(allowed, *ca.c.CAPathLen)
+	}
+	if allowed == 0 {
+		return 0, errs.New("path length constraint of the X509 CA does not allow signing downstream CA SVIDs")
+	}
+
+	pathLen := -1
+	if allowed > 0 {
+		pathLen = allowed - 1
+	}
+	if ca.c.DownstreamCAPathLen != nil {
+		pathLen = minPathLen(pathLen, *ca.c.DownstreamCAPathLen)
+	}
+	return pathLen, nil
+}
+
+func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (_ string, err error) {
+	defer ca.measureSign(telemetry.JWTSVID, time.Now(), &err)
+
+	if err := ca.c.Denylist.check(params.SpiffeID); err != nil {
+		return "", err
+	}
+
 	jwtKey := ca.JWTKey()
 	if jwtKey == nil {
-		return "", errs.New("JWT key is not available for signing")
+		return "", fmt.Errorf("JWT key is %w", ErrNotAvailable)
 	}
 
 	if err := api.VerifyTrustDomainWorkloadID(ca.c.TrustDomain, params.SpiffeID); err != nil {
@@ -258,6 +368,7 @@ func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, er
 	if ttl <= 0 {
 		ttl = ca.c.JWTSVIDTTL
 	}
+	ttl = ca.capTTL(ttl)
 	_, expiresAt := ca.capLifetime(ttl, jwtKey.NotAfter)
 
 	token, err := ca.jwtSigner.SignToken(params.SpiffeID, params.Audience, expiresAt, jwtKey.Signer, jwtKey.Kid)
@@ -269,6 +380,18 @@ func (ca *CA) SignJWTSVID(ctx context.Context, params JWTSVIDParams) (string, er
 	return token, nil
 }
 
+func (ca *CA) measureSign(kind string, start time.Time, errp *error) {
+	telemetry_server.MeasureServerCASign(ca.c.Metrics, kind, ca.c.KeyManagerName, start, *errp)
+}
+
+// capTTL caps the TTL of an SVID to the configured maximum, if any.
+func (ca *CA) capTTL(ttl time.Duration) time.Duration {
+	if ca.c.MaxSVIDTTL > 0 && ttl > ca.c.MaxSVIDTTL {
+		return ca.c.MaxSVIDTTL
+	}
+	return ttl
+}
+
 func (ca *CA) capLifetime(ttl time.Duration, expirationCap time.Time) (notBefore, notAfter time.Time) {
 	now := ca.c.Clock.Now()
 	notBefore = now.Add(-backdate)
@@ -310,11 +433,19 @@ func signX509SVID(td spiffeid.TrustDomain, x509CA *X509CA, params X509SVIDParams
 		template.DNSNames = params.DNSList
 	}
 
+	if err := addCSRExtensions(template, params.CSRExtensions); err != nil {
+		return nil, errs.New("unable to add CSR extensions: %v", err)
+	}
+
 	cert, err := createCertificate(template, x509CA.Certificate, template.PublicKey, x509CA.Signer)
 	if err != nil {
 		return nil, errs.New("unable to create X509 SVID: %v", err)
 	}
 
+	if err := validateX509SVID(cert); err != nil {
+		return nil, errs.New("X509 SVID does not conform to the X509-SVID specification: %v", err)
+	}
+
 	return makeSVIDCertChain(x509CA, cert), nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"math/big"
+	"net/url"
 	"testing"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -112,6 +114,43 @@ func (s *CATestSuite) TestSignX509SVID() {
 	s.Equal("O=SPIRE,C=US", svid.Subject.String())
 }
 
+func (s *CATestSuite) TestSignX509SVIDEmitsPendingGauge() {
+	metrics := fakemetrics.New()
+	s.ca.c.Metrics = metrics
+
+	_, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+
+	key := []string{telemetry.ServerCA, telemetry.Sign, telemetry.X509SVID, telemetry.Pending}
+	s.Require().Equal([]fakemetrics.MetricItem{
+		{Type: fakemetrics.SetGaugeType, Key: key, Val: 1},
+		{Type: fakemetrics.IncrCounterType, Key: []string{telemetry.ServerCA, telemetry.Sign, telemetry.X509SVID}, Val: 1},
+		{Type: fakemetrics.SetGaugeType, Key: key, Val: 0},
+		signLatencyMetric(telemetry.X509SVID, "OK", ""),
+	}, metrics.AllMetrics())
+}
+
+func (s *CATestSuite) TestSignEmitsLatencyMetrics() {
+	metrics := fakemetrics.New()
+	s.ca.c.Metrics = metrics
+	s.ca.c.KeyManagerName = "memory"
+
+	_, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	_, err = s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().NoError(err)
+	_, err = s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainFoo, 0))
+	s.Require().Error(err)
+
+	s.Require().Equal([]fakemetrics.MetricItem{
+		{Type: fakemetrics.IncrCounterType, Key: []string{telemetry.ServerCA, telemetry.Sign, telemetry.X509CASVID}, Val: 1},
+		signLatencyMetric(telemetry.X509CASVID, "OK", "memory"),
+		{Type: fakemetrics.IncrCounterType, Key: []string{telemetry.ServerCA, telemetry.Sign, telemetry.JWTSVID}, Val: 1},
+		signLatencyMetric(telemetry.JWTSVID, "OK", "memory"),
+		signLatencyMetric(telemetry.JWTSVID, "Unknown", "memory"),
+	}, metrics.AllMetrics())
+}
+
 func (s *CATestSuite) TestSignX509SVIDCannotSignTrustDomainID() {
 	params := X509SVIDParams{
 		SpiffeID:  spiffeid.RequireFromString("spiffe://example.org"),
@@ -246,6 +285,23 @@ func (s *CATestSuite) TestSignX509SVIDCapsTTLToCATTL() {
 	s.Require().Equal(s.clock.Now().Add(10*time.Minute), svid[0].NotAfter)
 }
 
+func (s *CATestSuite) TestSignX509SVIDCapsTTLToMaxSVIDTTL() {
+	s.ca.c.MaxSVIDTTL = 30 * time.Second
+
+	params := s.createX509SVIDParams()
+	params.TTL = 5 * time.Minute
+	svid, err := s.ca.SignX509SVID(ctx, params)
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Require().Equal(s.clock.Now().Add(30*time.Second), svid[0].NotAfter)
+
+	// The default TTL is capped too
+	svid, err = s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Require().Equal(s.clock.Now().Add(30*time.Second), svid[0].NotAfter)
+}
+
 func (s *CATestSuite) TestSignX509SVIDValidatesTrustDomain() {
 	_, err := s.ca.SignX509SVID(ctx, s.createX509SVIDParamsInDomain(trustDomainFoo))
 	s.Require().EqualError(err, `"spiffe://foo.com/workload" is not a member of trust domain "example.org"`)
@@ -294,6 +350,18 @@ func (s *CATestSuite) TestSignJWTSVIDCapsTTLToKeyExpiry() {
 	s.Require().Equal(s.clock.Now().Add(10*time.Minute), expiresAt)
 }
 
+func (s *CATestSuite) TestSignJWTSVIDCapsTTLToMaxSVIDTTL() {
+	s.ca.c.MaxSVIDTTL = 30 * time.Second
+
+	for _, ttl := range []time.Duration{0, 5 * time.Minute} {
+		token, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, ttl))
+		s.Require().NoError(err)
+		_, expiresAt, err := jwtsvid.GetTokenExpiry(token)
+		s.Require().NoError(err)
+		s.Require().Equal(s.clock.Now().Add(30*time.Second), expiresAt)
+	}
+}
+
 func (s *CATestSuite) TestSignJWTSVIDValidatesJSR() {
 	// spiffe id for wrong trust domain
 	_, err := s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainFoo, 0))
@@ -345,11 +413,90 @@ func (s *CATestSuite) TestSignX509CASVIDUsesDefaultTTLIfTTLUnspecified() {
 	s.Require().Equal(s.clock.Now().Add(time.Minute), svid[0].NotAfter)
 }
 
+func (s *CATestSuite) TestSignX509CASVIDUnconstrainedPathLen() {
+	svid, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Equal(-1, pathLenOf(svid[0]))
+}
+
+func (s *CATestSuite) TestSignX509CASVIDInheritsCAPathLen() {
+	s.ca.c.CAPathLen = intPtr(2)
+	svid, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Equal(1, svid[0].MaxPathLen)
+	s.False(svid[0].MaxPathLenZero)
+}
+
+func (s *CATestSuite) TestSignX509CASVIDUsesDownstreamCAPathLen() {
+	s.ca.c.CAPathLen = intPtr(2)
+	s.ca.c.DownstreamCAPathLen = intPtr(0)
+	svid, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Equal(0, svid[0].MaxPathLen)
+	s.True(svid[0].MaxPathLenZero)
+}
+
+func (s *CATestSuite) TestSignX509CASVIDCapsDownstreamCAPathLen() {
+	s.ca.c.CAPathLen = intPtr(2)
+	s.ca.c.DownstreamCAPathLen = intPtr(5)
+	svid, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().NoError(err)
+	s.Require().Len(svid, 1)
+	s.Equal(1, svid[0].MaxPathLen)
+}
+
+func (s *CATestSuite) TestSignX509CASVIDFailsIfPathLenExhausted() {
+	s.ca.c.CAPathLen = intPtr(0)
+	_, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainExample))
+	s.Require().EqualError(err, "path length constraint of the X509 CA does not allow signing downstream CA SVIDs")
+}
+
 func (s *CATestSuite) TestSignCAX509SVIDValidatesTrustDomain() {
 	_, err := s.ca.SignX509CASVID(ctx, s.createX509CASVIDParams(trustDomainFoo))
 	s.Require().EqualError(err, `"spiffe://foo.com" is not a member of trust domain "example.org"`)
 }
 
+func (s *CATestSuite) TestSignDeniedByDenylist() {
+	denylist, err := NewDenylist([]string{
+		"spiffe://example.org/workload",
+		"spiffe://example.org/spire/agent/*/compromised",
+		"spiffe://example.org/downstream",
+	})
+	s.Require().NoError(err)
+	s.ca.c.Denylist = denylist
+
+	_, err = s.ca.SignX509SVID(ctx, s.createX509SVIDParams())
+	s.Require().ErrorIs(err, ErrDenied)
+	s.Require().EqualError(err, `SPIFFE ID matches denylist pattern "spiffe://example.org/workload"`)
+
+	_, err = s.ca.SignJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().ErrorIs(err, ErrDenied)
+
+	// Agent SVIDs are signed through the same path
+	agentParams := s.createX509SVIDParams()
+	agentParams.SpiffeID = spiffeid.RequireFromPath(trustDomainExample, "/spire/agent/x509pop/compromised")
+	_, err = s.ca.SignX509SVID(ctx, agentParams)
+	s.Require().ErrorIs(err, ErrDenied)
+
+	agentParams.SpiffeID = spiffeid.RequireFromPath(trustDomainExample, "/spire/agent/x509pop/healthy")
+	_, err = s.ca.SignX509SVID(ctx, agentParams)
+	s.Require().NoError(err)
+
+	// CA SVIDs are denied for denied downstream workloads
+	caParams := s.createX509CASVIDParams(trustDomainExample)
+	caParams.DownstreamID = spiffeid.RequireFromPath(trustDomainExample, "/downstream")
+	_, err = s.ca.SignX509CASVID(ctx, caParams)
+	s.Require().ErrorIs(err, ErrDenied)
+	s.Require().EqualError(err, `SPIFFE ID matches denylist pattern "spiffe://example.org/downstream"`)
+
+	caParams.DownstreamID = spiffeid.RequireFromPath(trustDomainExample, "/other-downstream")
+	_, err = s.ca.SignX509CASVID(ctx, caParams)
+	s.Require().NoError(err)
+}
+
 func (s *CATestSuite) TestHealthChecks() {
 	// Successful health check
 	s.Equal(map[string]health.State{
@@ -408,6 +555,17 @@ func (s *CATestSuite) createX509SVIDParamsInDomain(trustDomain spiffeid.TrustDom
 	}
 }
 
+func signLatencyMetric(kind, status, keyManager string) fakemetrics.MetricItem {
+	return fakemetrics.MetricItem{
+		Type: fakemetrics.MeasureSinceWithLabelsType,
+		Key:  []string{telemetry.ServerCA, telemetry.Sign, kind, telemetry.ElapsedTime},
+		Labels: []telemetry.Label{
+			{Name: telemetry.Status, Value: status},
+			{Name: telemetry.KeyManager, Value: keyManager},
+		},
+	}
+}
+
 func (s *CATestSuite) createX509CASVIDParams(trustDomain spiffeid.TrustDomain) X509CASVIDParams {
 	return X509CASVIDParams{
 		SpiffeID:  trustDomain.ID(),
@@ -446,3 +604,80 @@ func (s *CATestSuite) createCACertificate(cn string, parent *x509.Certificate) *
 	s.Require().NoError(err)
 	return cert
 }
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestValidateX509SVID(t *testing.T) {
+	valid := func() *x509.Certificate {
+		return &x509.Certificate{
+			URIs:        []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/workload"}},
+			KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(*x509.Certificate)
+		err    string
+	}{
+		{
+			name:   "valid",
+			modify: func(*x509.Certificate) {},
+		},
+		{
+			name:   "no URI SAN",
+			modify: func(c *x509.Certificate) { c.URIs = nil },
+			err:    "expected exactly one URI SAN; got 0",
+		},
+		{
+			name:   "more than one URI SAN",
+			modify: func(c *x509.Certificate) { c.URIs = append(c.URIs, c.URIs[0]) },
+			err:    "expected exactly one URI SAN; got 2",
+		},
+		{
+			name:   "URI SAN is not a SPIFFE ID",
+			modify: func(c *x509.Certificate) { c.URIs[0].Scheme = "https" },
+			err:    "URI SAN is not a valid SPIFFE ID: scheme is missing or invalid",
+		},
+		{
+			name:   "CA",
+			modify: func(c *x509.Certificate) { c.IsCA = true },
+			err:    "CA flag is set",
+		},
+		{
+			name:   "no digital signature",
+			modify: func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageKeyEncipherment },
+			err:    "digital signature key usage is missing",
+		},
+		{
+			name:   "certificate signing",
+			modify: func(c *x509.Certificate) { c.KeyUsage |= x509.KeyUsageCertSign },
+			err:    "certificate or CRL signing key usage is set",
+		},
+		{
+			name:   "no server authentication",
+			modify: func(c *x509.Certificate) { c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth} },
+			err:    "server authentication extended key usage is missing",
+		},
+		{
+			name:   "no client authentication",
+			modify: func(c *x509.Certificate) { c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth} },
+			err:    "client authentication extended key usage is missing",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cert := valid()
+			tt.modify(cert)
+			err := validateX509SVID(cert)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
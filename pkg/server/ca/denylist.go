@@ -0,0 +1,170 @@
+package ca
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+// ErrDenied is wrapped by the errors returned when the SPIFFE ID of an SVID
+// matches a pattern of the denylist of the CA.
+var ErrDenied = errors.New("SPIFFE ID matches denylist pattern")
+
+// Denylist blocks the issuance of SVIDs for the SPIFFE IDs that match any of
+// a set of glob patterns, even if a registration entry for the SPIFFE ID
+// exists. Patterns use path.Match syntax against the SPIFFE ID string, e.g.
+// "spiffe://example.org/ns/compromised/*". Note that "*" does not match "/",
+// so that pattern does not match "spiffe://example.org/ns/compromised/a/b".
+// A pattern ending with "/**" matches every SPIFFE ID below the path matched
+// by the rest of the pattern, at any depth, e.g.
+// "spiffe://example.org/ns/compromised/**". The patterns can be replaced
+// while the server is running.
+type Denylist struct {
+	mtx      sync.RWMutex
+	patterns []string
+}
+
+// NewDenylist returns a denylist with the given patterns
+func NewDenylist(patterns []string) (*Denylist, error) {
+	d := new(Denylist)
+	if err := d.SetPatterns(patterns); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ValidateDenylistPatterns returns an error if any of the patterns is malformed
+func ValidateDenylistPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("malformed pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validatePattern(pattern string) error {
+	prefix, subtree := splitSubtreePattern(pattern)
+	if strings.Contains(prefix, "**") {
+		return errors.New(`"**" is only supported as the last path segment`)
+	}
+	if subtree && prefix == "" {
+		return errors.New(`"/**" must follow a pattern`)
+	}
+	_, err := path.Match(prefix, "")
+	return err
+}
+
+// splitSubtreePattern returns the pattern without the trailing "/**", if
+// any, and whether the pattern had it.
+func splitSubtreePattern(pattern string) (string, bool) {
+	if strings.HasSuffix(pattern, "/**") {
+		return strings.TrimSuffix(pattern, "/**"), true
+	}
+	return pattern, false
+}
+
+// matchPattern returns true if the SPIFFE ID matches the pattern. Patterns
+// are validated when set, so match errors are ignored.
+func matchPattern(pattern string, id spiffeid.ID) bool {
+	prefix, subtree := splitSubtreePattern(pattern)
+	if !subtree {
+		matched, _ := path.Match(pattern, id.String())
+		return matched
+	}
+
+	// Match the prefix against every ancestor of the SPIFFE ID, from the
+	// trust domain down to the parent of the SPIFFE ID.
+	tdID := id.TrustDomain().IDString()
+	idPath := id.Path()
+	for i := 0; i < len(idPath); i++ {
+		if idPath[i] != '/' {
+			continue
+		}
+		if matched, _ := path.Match(prefix, tdID+idPath[:i]); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPatterns replaces the patterns of the denylist. If any of the patterns is
+// malformed, the denylist is left unchanged.
+func (d *Denylist) SetPatterns(patterns []string) error {
+	if err := ValidateDenylistPatterns(patterns); err != nil {
+		return err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.patterns = append([]string(nil), patterns...)
+	return nil
+}
+
+// AddPattern adds a pattern to the denylist. Adding a pattern that is
+// already in the denylist is a no-op.
+func (d *Denylist) AddPattern(pattern string) error {
+	if err := ValidateDenylistPatterns([]string{pattern}); err != nil {
+		return err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for _, p := range d.patterns {
+		if p == pattern {
+			return nil
+		}
+	}
+	d.patterns = append(d.patterns, pattern)
+	return nil
+}
+
+// RemovePattern removes a pattern from the denylist. It returns false if the
+// pattern was not in the denylist.
+func (d *Denylist) RemovePattern(pattern string) bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for i, p := range d.patterns {
+		if p == pattern {
+			d.patterns = append(d.patterns[:i:i], d.patterns[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Patterns returns the patterns of the denylist
+func (d *Denylist) Patterns() []string {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return append([]string(nil), d.patterns...)
+}
+
+// Match returns the first pattern matching the given SPIFFE ID, if any
+func (d *Denylist) Match(id spiffeid.ID) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	for _, pattern := range d.patterns {
+		if matchPattern(pattern, id) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// check returns an error wrapping ErrDenied if the given SPIFFE ID matches a
+// pattern of the denylist
+func (d *Denylist) check(id spiffeid.ID) error {
+	if pattern, denied := d.Match(id); denied {
+		return fmt.Errorf("%w %q", ErrDenied, pattern)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package ca
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenylist(t *testing.T) {
+	_, err := NewDenylist([]string{"spiffe://example.org/["})
+	require.EqualError(t, err, `malformed pattern "spiffe://example.org/[": syntax error in pattern`)
+
+	denylist, err := NewDenylist([]string{"spiffe://example.org/a/*", "spiffe://example.org/b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"spiffe://example.org/a/*", "spiffe://example.org/b"}, denylist.Patterns())
+
+	pattern, denied := denylist.Match(spiffeid.RequireFromPath(trustDomainExample, "/a/workload"))
+	require.True(t, denied)
+	require.Equal(t, "spiffe://example.org/a/*", pattern)
+
+	_, denied = denylist.Match(spiffeid.RequireFromPath(trustDomainExample, "/a/workload/nested"))
+	require.False(t, denied)
+
+	_, denied = denylist.Match(spiffeid.RequireFromPath(trustDomainExample, "/b"))
+	require.True(t, denied)
+
+	// Malformed patterns leave the denylist unchanged
+	require.Error(t, denylist.SetPatterns([]string{"["}))
+	require.Equal(t, []string{"spiffe://example.org/a/*", "spiffe://example.org/b"}, denylist.Patterns())
+}
+
+func TestDenylistSubtreePatterns(t *testing.T) {
+	for _, pattern := range []string{"/**", "spiffe://example.org/**/a", "spiffe://example.org/a**"} {
+		_, err := NewDenylist([]string{pattern})
+		require.Error(t, err, pattern)
+	}
+
+	denylist, err := NewDenylist([]string{"spiffe://example.org/ns/*/sa/**", "spiffe://other.org/**"})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		id      spiffeid.ID
+		pattern string
+	}{
+		{id: spiffeid.RequireFromPath(trustDomainExample, "/ns/prod/sa/web"), pattern: "spiffe://example.org/ns/*/sa/**"},
+		{id: spiffeid.RequireFromPath(trustDomainExample, "/ns/prod/sa/web/canary/1"), pattern: "spiffe://example.org/ns/*/sa/**"},
+		{id: spiffeid.RequireFromPath(trustDomainExample, "/ns/prod/sa")},
+		{id: spiffeid.RequireFromPath(trustDomainExample, "/ns/prod/other/web")},
+		{id: spiffeid.RequireFromString("spiffe://other.org/a/b"), pattern: "spiffe://other.org/**"},
+		{id: spiffeid.RequireFromString("spiffe://other.org.test/a")},
+	} {
+		pattern, denied := denylist.Match(tt.id)
+		require.Equal(t, tt.pattern != "", denied, tt.id.String())
+		require.Equal(t, tt.pattern, pattern, tt.id.String())
+	}
+}
+
+func TestDenylistAddRemovePattern(t *testing.T) {
+	denylist, err := NewDenylist([]string{"spiffe://example.org/a"})
+	require.NoError(t, err)
+
+	require.EqualError(t, denylist.AddPattern("["), `malformed pattern "[": syntax error in pattern`)
+	require.NoError(t, denylist.AddPattern("spiffe://example.org/b/**"))
+	require.NoError(t, denylist.AddPattern("spiffe://example.org/b/**"))
+	require.Equal(t, []string{"spiffe://example.org/a", "spiffe://example.org/b/**"}, denylist.Patterns())
+
+	require.True(t, denylist.RemovePattern("spiffe://example.org/a"))
+	require.False(t, denylist.RemovePattern("spiffe://example.org/a"))
+	require.Equal(t, []string{"spiffe://example.org/b/**"}, denylist.Patterns())
+}
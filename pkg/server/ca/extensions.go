@@ -0,0 +1,165 @@
+package ca
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// oidCertificateExtension is the id-ce arc of the standard certificate
+	// extensions, which are managed by the CA
+	oidCertificateExtension    = asn1.ObjectIdentifier{2, 5, 29}
+	oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+const (
+	// otherName and the types of SAN set by the CA, as tagged in the
+	// GeneralName choice
+	sanTagOtherName = 0
+	sanTagDNSName   = 2
+	sanTagURI       = 6
+)
+
+// CSRExtensionAllowlist lists the OIDs of the CSR extensions copied into
+// X509-SVIDs. An OID either matches a CSR extension, which is copied as is,
+// or the type of an otherName SAN in the CSR, e.g. the Microsoft UPN
+// (1.3.6.1.4.1.311.20.2.3), which is added to the SANs of the X509-SVID.
+type CSRExtensionAllowlist []asn1.ObjectIdentifier
+
+// ParseCSRExtensionAllowlist parses dotted OIDs into an allowlist. Standard
+// certificate extensions are managed by the CA and cannot be allowed.
+func ParseCSRExtensionAllowlist(oids []string) (CSRExtensionAllowlist, error) {
+	var allowlist CSRExtensionAllowlist
+	for _, s := range oids {
+		oid, err := parseOID(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(oid) > len(oidCertificateExtension) && oid[:len(oidCertificateExtension)].Equal(oidCertificateExtension) {
+			return nil, fmt.Errorf("OID %s is a certificate extension managed by the CA", s)
+		}
+		allowlist = append(allowlist, oid)
+	}
+	return allowlist, nil
+}
+
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	oid := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
+
+func (a CSRExtensionAllowlist) allows(oid asn1.ObjectIdentifier) bool {
+	for _, allowed := range a {
+		if allowed.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the CSR extensions allowed by the allowlist. The SAN
+// extension is reduced to the allowed otherName SANs, and dropped if there
+// are none.
+func (a CSRExtensionAllowlist) filter(extensions []pkix.Extension) ([]pkix.Extension, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+
+	var filtered []pkix.Extension
+	for _, ext := range extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			if a.allows(ext.Id) {
+				filtered = append(filtered, ext)
+			}
+			continue
+		}
+
+		names, err := parseGeneralNames(ext.Value)
+		if err != nil {
+			return nil, err
+		}
+		var otherNames []asn1.RawValue
+		for _, name := range names {
+			if name.Class != asn1.ClassContextSpecific || name.Tag != sanTagOtherName {
+				continue
+			}
+			var typeID asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(name.Bytes, &typeID); err != nil {
+				return nil, fmt.Errorf("malformed otherName SAN: %w", err)
+			}
+			if a.allows(typeID) {
+				otherNames = append(otherNames, name)
+			}
+		}
+		if len(otherNames) > 0 {
+			value, err := asn1.Marshal(otherNames)
+			if err != nil {
+				return nil, err
+			}
+			filtered = append(filtered, pkix.Extension{Id: oidExtensionSubjectAltName, Value: value})
+		}
+	}
+	return filtered, nil
+}
+
+// addCSRExtensions adds the CSR extensions to the template. The otherName
+// SANs of a SAN extension are merged with the SANs set by the CA.
+func addCSRExtensions(template *x509.Certificate, extensions []pkix.Extension) error {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			template.ExtraExtensions = append(template.ExtraExtensions, ext)
+			continue
+		}
+
+		otherNames, err := parseGeneralNames(ext.Value)
+		if err != nil {
+			return err
+		}
+
+		// The SAN extension replaces the one that would be generated from
+		// the template, so it has to include the SANs set by the CA.
+		var names []asn1.RawValue
+		for _, uri := range template.URIs {
+			names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: sanTagURI, Bytes: []byte(uri.String())})
+		}
+		for _, dnsName := range template.DNSNames {
+			names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: sanTagDNSName, Bytes: []byte(dnsName)})
+		}
+		names = append(names, otherNames...)
+
+		value, err := asn1.Marshal(names)
+		if err != nil {
+			return err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: oidExtensionSubjectAltName, Value: value})
+	}
+	return nil
+}
+
+func parseGeneralNames(value []byte) ([]asn1.RawValue, error) {
+	var names []asn1.RawValue
+	rest, err := asn1.Unmarshal(value, &names)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("malformed SAN extension: %w", err)
+	case len(rest) > 0:
+		return nil, errors.New("malformed SAN extension: trailing data")
+	}
+	return names, nil
+}
@@ -0,0 +1,194 @@
+package ca
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oidCustomExtension = asn1.ObjectIdentifier{1, 2, 3, 4}
+	oidUPN             = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+)
+
+func TestParseCSRExtensionAllowlist(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		oids      []string
+		expect    CSRExtensionAllowlist
+		expectErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "valid OIDs",
+			oids:   []string{"1.2.3.4", "1.3.6.1.4.1.311.20.2.3"},
+			expect: CSRExtensionAllowlist{oidCustomExtension, oidUPN},
+		},
+		{
+			name:      "single arc",
+			oids:      []string{"1"},
+			expectErr: `invalid OID "1"`,
+		},
+		{
+			name:      "not a number",
+			oids:      []string{"1.2.foo"},
+			expectErr: `invalid OID "1.2.foo"`,
+		},
+		{
+			name:      "negative arc",
+			oids:      []string{"1.-2.3"},
+			expectErr: `invalid OID "1.-2.3"`,
+		},
+		{
+			name:      "certificate extension",
+			oids:      []string{"2.5.29.17"},
+			expectErr: "OID 2.5.29.17 is a certificate extension managed by the CA",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			allowlist, err := ParseCSRExtensionAllowlist(tt.oids)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, allowlist)
+		})
+	}
+}
+
+func TestCSRExtensionAllowlistFilter(t *testing.T) {
+	customExtension := pkix.Extension{Id: oidCustomExtension, Value: mustMarshal(t, "custom")}
+	otherExtension := pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3, 5}, Value: mustMarshal(t, "other")}
+	upn := otherNameSAN(t, oidUPN, "workload@example.org")
+	san := pkix.Extension{
+		Id: oidExtensionSubjectAltName,
+		Value: mustMarshal(t, []asn1.RawValue{
+			{Class: asn1.ClassContextSpecific, Tag: sanTagURI, Bytes: []byte("spiffe://example.org/workload")},
+			upn,
+			otherNameSAN(t, asn1.ObjectIdentifier{1, 2, 3, 6}, "other"),
+		}),
+	}
+
+	for _, tt := range []struct {
+		name      string
+		allowlist CSRExtensionAllowlist
+		exts      []pkix.Extension
+		expect    []pkix.Extension
+		expectErr string
+	}{
+		{
+			name: "empty allowlist",
+			exts: []pkix.Extension{customExtension, san},
+		},
+		{
+			name:      "allowed extension",
+			allowlist: CSRExtensionAllowlist{oidCustomExtension},
+			exts:      []pkix.Extension{customExtension, otherExtension, san},
+			expect:    []pkix.Extension{customExtension},
+		},
+		{
+			name:      "allowed otherName SAN",
+			allowlist: CSRExtensionAllowlist{oidUPN},
+			exts:      []pkix.Extension{customExtension, san},
+			expect: []pkix.Extension{
+				{Id: oidExtensionSubjectAltName, Value: mustMarshal(t, []asn1.RawValue{upn})},
+			},
+		},
+		{
+			name:      "malformed SAN",
+			allowlist: CSRExtensionAllowlist{oidUPN},
+			exts:      []pkix.Extension{{Id: oidExtensionSubjectAltName, Value: []byte("bad")}},
+			expectErr: "malformed SAN extension: ",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := tt.allowlist.filter(tt.exts)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, mustMarshal(t, tt.expect), mustMarshal(t, filtered))
+		})
+	}
+}
+
+func (s *CATestSuite) TestSignX509SVIDCopiesAllowlistedCSRExtensions() {
+	s.ca.c.CSRExtensionAllowlist = CSRExtensionAllowlist{oidCustomExtension, oidUPN}
+
+	customExtension := pkix.Extension{Id: oidCustomExtension, Value: mustMarshal(s.T(), "custom")}
+	upn := otherNameSAN(s.T(), oidUPN, "workload@example.org")
+
+	params := s.createX509SVIDParams()
+	params.DNSList = []string{"example.org"}
+	params.CSRExtensions = []pkix.Extension{
+		customExtension,
+		{Id: asn1.ObjectIdentifier{1, 2, 3, 5}, Value: mustMarshal(s.T(), "other")},
+		{Id: oidExtensionSubjectAltName, Value: mustMarshal(s.T(), []asn1.RawValue{upn})},
+	}
+
+	svidChain, err := s.ca.SignX509SVID(ctx, params)
+	s.Require().NoError(err)
+	svid := svidChain[0]
+
+	// The SAN set by the CA is preserved alongside the otherName SAN
+	if s.Len(svid.URIs, 1) {
+		s.Equal("spiffe://example.org/workload", svid.URIs[0].String())
+	}
+	s.Equal([]string{"example.org"}, svid.DNSNames)
+
+	var copied []pkix.Extension
+	var names []asn1.RawValue
+	for _, ext := range svid.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionSubjectAltName):
+			names, err = parseGeneralNames(ext.Value)
+			s.Require().NoError(err)
+		case ext.Id.Equal(oidCustomExtension), ext.Id.Equal(asn1.ObjectIdentifier{1, 2, 3, 5}):
+			copied = append(copied, ext)
+		}
+	}
+	s.Equal([]pkix.Extension{customExtension}, copied)
+	s.Require().Len(names, 3)
+	s.Equal(upn.FullBytes, names[2].FullBytes)
+}
+
+func (s *CATestSuite) TestSignX509SVIDIgnoresCSRExtensionsWithoutAllowlist() {
+	params := s.createX509SVIDParams()
+	params.CSRExtensions = []pkix.Extension{
+		{Id: oidCustomExtension, Value: mustMarshal(s.T(), "custom")},
+	}
+
+	svidChain, err := s.ca.SignX509SVID(ctx, params)
+	s.Require().NoError(err)
+	for _, ext := range svidChain[0].Extensions {
+		s.False(ext.Id.Equal(oidCustomExtension), "CSR extension was copied")
+	}
+}
+
+// otherNameSAN returns an otherName GeneralName with a UTF8String value
+func otherNameSAN(t *testing.T, typeID asn1.ObjectIdentifier, value string) asn1.RawValue {
+	inner := append(mustMarshal(t, typeID), mustMarshal(t, asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      mustMarshal(t, asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte(value)}),
+	})...)
+	name := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: sanTagOtherName, IsCompound: true, Bytes: inner}
+	name.FullBytes = mustMarshal(t, name)
+	return name
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/lease"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/server/plugin/notifier"
 	"github.com/spiffe/spire/proto/private/server/journal"
@@ -51,6 +52,8 @@ const (
 	activationThresholdDivisor = 6
 
 	publishJWKTimeout = 5 * time.Second
+
+	bundlePruningLease = "bundle_pruning"
 )
 
 type ManagedCA interface {
@@ -66,11 +69,45 @@ type ManagerConfig struct {
 	X509CAKeyType keymanager.KeyType
 	JWTKeyType    keymanager.KeyType
 	CASubject     pkix.Name
+	CAPathLen     *int
+
+	// PreparationLeadTime is how long before the current X509 CA or JWT key
+	// expires that the next one is prepared. If zero, the next one is
+	// prepared halfway through the lifetime of the current one, up to thirty
+	// days before it expires.
+	PreparationLeadTime time.Duration
+
+	// ActivationOverlap is how long before the current X509 CA or JWT key
+	// expires that the next one is activated, i.e. how long the old one
+	// overlaps with its replacement. If zero, the next one is activated when
+	// a sixth of the lifetime of the current one remains, up to seven days.
+	// It is limited to the preparation lead time.
+	ActivationOverlap time.Duration
+
+	// UpstreamFallback, if true, self-signs the next X509 CA when the
+	// UpstreamAuthority fails to sign it once the current X509 CA is past its
+	// fallback threshold, halfway between the preparation and activation
+	// thresholds, so the trust domain survives an unreachable
+	// UpstreamAuthority. Before that, failures are retried on the next
+	// rotation. Self-signed X509 CAs are replaced as soon as the
+	// UpstreamAuthority signs again. Workloads that only trust the upstream
+	// root do not trust the self-signed X509 CA.
+	UpstreamFallback bool
+
+	// BundleRefreshHint is the refresh hint stored with the trust bundle. If
+	// zero, the bundle has no refresh hint and consumers calculate it from
+	// the bundle contents.
+	BundleRefreshHint time.Duration
+
 	Dir           string
 	Log           logrus.FieldLogger
 	Metrics       telemetry.Metrics
 	Clock         clock.Clock
 	HealthChecker health.Checker
+
+	// Leases coordinates bundle pruning with the other servers sharing the
+	// datastore. If nil, the bundle is pruned by every server.
+	Leases *lease.Coordinator
 }
 
 type Manager struct {
@@ -78,6 +115,7 @@ type Manager struct {
 	bundleUpdatedCh    chan struct{}
 	upstreamClient     *UpstreamClient
 	upstreamPluginName string
+	schedule           rotationSchedule
 
 	currentX509CA *x509CASlot
 	nextX509CA    *x509CASlot
@@ -104,6 +142,10 @@ func NewManager(c ManagerConfig) *Manager {
 	m := &Manager{
 		c:               c,
 		bundleUpdatedCh: make(chan struct{}, 1),
+		schedule: rotationSchedule{
+			preparationLeadTime: c.PreparationLeadTime,
+			activationOverlap:   c.ActivationOverlap,
+		},
 	}
 
 	if upstreamAuthority, ok := c.Catalog.GetUpstreamAuthority(); ok {
@@ -128,7 +170,13 @@ func (m *Manager) Initialize(ctx context.Context) error {
 	if err := m.loadJournal(ctx); err != nil {
 		return err
 	}
-	return m.rotate(ctx)
+	if err := m.rotate(ctx); err != nil {
+		return err
+	}
+	if err := m.checkKeySeparation(); err != nil {
+		return err
+	}
+	return m.setBundleRefreshHint(ctx)
 }
 
 func (m *Manager) Run(ctx context.Context) error {
@@ -206,15 +254,17 @@ func (m *Manager) rotateX509CA(ctx context.Context) error {
 		m.activateX509CA()
 	}
 
+	m.recoverFromUpstreamFallback(ctx)
+
 	// if there is no next keypair set and the current is within the
 	// preparation threshold, generate one.
-	if m.nextX509CA.IsEmpty() && m.currentX509CA.ShouldPrepareNext(now) {
+	if m.nextX509CA.IsEmpty() && m.currentX509CA.ShouldPrepareNext(now, m.schedule) {
 		if err := m.prepareX509CA(ctx, m.nextX509CA); err != nil {
 			return err
 		}
 	}
 
-	if m.currentX509CA.ShouldActivateNext(now) {
+	if m.currentX509CA.ShouldActivateNext(now, m.schedule) {
 		m.currentX509CA, m.nextX509CA = m.nextX509CA, m.currentX509CA
 		m.nextX509CA.Reset()
 		m.activateX509CA()
@@ -223,6 +273,67 @@ func (m *Manager) rotateX509CA(ctx context.Context) error {
 	return nil
 }
 
+// recoverFromUpstreamFallback replaces self-signed fallback X509 CAs as soon
+// as the UpstreamAuthority signs again. A fallback next X509 CA is signed
+// again by the UpstreamAuthority, with the same key. A fallback current X509
+// CA is replaced right away by a next X509 CA signed by the UpstreamAuthority,
+// which chains to the upstream root the trust domain already trusts. Failures
+// are logged and retried on the next rotation, and do not hold back the
+// regular rotation, which may still need to activate a fallback X509 CA.
+func (m *Manager) recoverFromUpstreamFallback(ctx context.Context) {
+	switch {
+	case m.isUpstreamFallback(m.nextX509CA):
+		if err := m.upstreamSignX509CA(ctx, m.nextX509CA, m.nextX509CA.x509CA.Signer); err != nil {
+			m.c.Log.WithError(err).WithField(telemetry.Slot, m.nextX509CA.id).Warn("UpstreamAuthority still fails to sign X509 CA; keeping the self-signed X509 CA")
+			return
+		}
+	case m.isUpstreamFallback(m.currentX509CA) && m.nextX509CA.IsEmpty():
+		if err := m.prepareX509CA(ctx, m.nextX509CA); err != nil {
+			m.c.Log.WithError(err).WithField(telemetry.Slot, m.nextX509CA.id).Warn("UpstreamAuthority still fails to sign X509 CA; keeping the self-signed X509 CA")
+			return
+		}
+	}
+
+	if m.isUpstreamFallback(m.currentX509CA) && !m.nextX509CA.IsEmpty() && !m.isUpstreamFallback(m.nextX509CA) {
+		m.currentX509CA, m.nextX509CA = m.nextX509CA, m.currentX509CA
+		m.nextX509CA.Reset()
+		m.activateX509CA()
+	}
+}
+
+// isUpstreamFallback returns true if the slot holds a self-signed X509 CA
+// while an UpstreamAuthority is configured, i.e. a fallback X509 CA. This is
+// derived from the X509 CA itself, so it holds for X509 CAs loaded from the
+// journal as well.
+func (m *Manager) isUpstreamFallback(slot *x509CASlot) bool {
+	return m.c.UpstreamFallback && m.upstreamClient != nil && !slot.IsEmpty() && len(slot.x509CA.UpstreamChain) == 0
+}
+
+// upstreamSignX509CA replaces the X509 CA of the slot with one signed by the
+// UpstreamAuthority for the given key.
+func (m *Manager) upstreamSignX509CA(ctx context.Context, slot *x509CASlot, signer crypto.Signer) error {
+	now := m.c.Clock.Now()
+	x509CA, err := UpstreamSignX509CA(ctx, signer, m.c.TrustDomain, m.c.CASubject, m.upstreamClient, m.c.CATTL)
+	if err != nil {
+		return err
+	}
+	m.checkUpstreamX509CALifetime(m.c.Log.WithField(telemetry.Slot, slot.id), now, x509CA)
+
+	slot.issuedAt = now
+	slot.x509CA = x509CA
+
+	if err := m.journal.AppendX509CA(slot.id, slot.issuedAt, slot.x509CA); err != nil {
+		m.c.Log.WithError(err).Error("Unable to append X509 CA to journal")
+	}
+
+	m.c.Log.WithFields(logrus.Fields{
+		telemetry.Slot:       slot.id,
+		telemetry.IssuedAt:   timeField(slot.issuedAt),
+		telemetry.Expiration: timeField(slot.x509CA.Certificate.NotAfter),
+	}).Info("Self-signed X509 CA replaced by one signed by the UpstreamAuthority")
+	return nil
+}
+
 func (m *Manager) failedRotationResult() uint64 {
 	return atomic.LoadUint64(&m.failedRotationNum)
 }
@@ -242,18 +353,36 @@ func (m *Manager) prepareX509CA(ctx context.Context, slot *x509CASlot) (err erro
 	if err != nil {
 		return err
 	}
+	if m.isJWTKey(signer.Public()) {
+		return errs.New("key manager key %q is already used for JWT signing", slot.KmKeyID())
+	}
 
 	var x509CA *X509CA
+	selfSigned := m.upstreamClient == nil
 	if m.upstreamClient != nil {
 		x509CA, err = UpstreamSignX509CA(ctx, signer, m.c.TrustDomain, m.c.CASubject, m.upstreamClient, m.c.CATTL)
-		if err != nil {
+		switch {
+		case err == nil:
+			m.checkUpstreamX509CALifetime(log, now, x509CA)
+		case m.c.UpstreamFallback && m.currentX509CA.ShouldFallBack(now, m.schedule):
+			// Failures are retried on the next rotation while the current
+			// X509 CA has time left. Past the fallback threshold, rather
+			// than letting it expire, fall back to a self-signed X509 CA,
+			// early enough for its root to be published in the trust
+			// bundle before it is activated. It is replaced as soon as the
+			// UpstreamAuthority recovers.
+			log.WithError(err).Warn("UpstreamAuthority failed to sign X509 CA; falling back to a self-signed X509 CA")
+			telemetry_server.IncrX509CAUpstreamFallbackCounter(m.c.Metrics)
+			selfSigned = true
+		default:
 			return err
 		}
-	} else {
+	}
+	if selfSigned {
 		notBefore := now.Add(-backdate)
 		notAfter := now.Add(m.c.CATTL)
 		var trustBundle []*x509.Certificate
-		x509CA, trustBundle, err = SelfSignX509CA(ctx, signer, m.c.TrustDomain, m.c.CASubject, notBefore, notAfter)
+		x509CA, trustBundle, err = SelfSignX509CA(ctx, signer, m.c.TrustDomain, m.c.CASubject, notBefore, notAfter, m.c.CAPathLen)
 		if err != nil {
 			return err
 		}
@@ -273,11 +402,34 @@ func (m *Manager) prepareX509CA(ctx context.Context, slot *x509CASlot) (err erro
 		telemetry.Slot:       slot.id,
 		telemetry.IssuedAt:   timeField(slot.issuedAt),
 		telemetry.Expiration: timeField(slot.x509CA.Certificate.NotAfter),
-		telemetry.SelfSigned: m.upstreamClient == nil,
+		telemetry.SelfSigned: selfSigned,
 	}).Info("X509 CA prepared")
 	return nil
 }
 
+// checkUpstreamX509CALifetime warns when the X509 CA minted by the upstream
+// authority, or its upstream chain, expires before the configured CA TTL. The
+// X509 CA is still used, but it is rotated more often than expected and the
+// lifetime of the SVIDs it signs may be cut short.
+func (m *Manager) checkUpstreamX509CALifetime(log logrus.FieldLogger, issuedAt time.Time, x509CA *X509CA) {
+	notAfter := x509CA.Certificate.NotAfter
+	for _, cert := range x509CA.UpstreamChain {
+		if cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+
+	if notAfter.Sub(issuedAt) >= m.c.CATTL-upstreamClockSkew {
+		return
+	}
+
+	telemetry_server.IncrX509CATTLShortenedCounter(m.c.Metrics)
+	log.WithFields(logrus.Fields{
+		telemetry.Expiration: timeField(notAfter),
+		telemetry.TTL:        m.c.CATTL.String(),
+	}).Warn("X509 CA minted by upstream authority expires before the configured CA TTL; check the upstream authority maximum TTL and the lifetime of its CA")
+}
+
 func (m *Manager) activateX509CA() {
 	m.c.Log.WithFields(logrus.Fields{
 		telemetry.Slot:       m.currentX509CA.id,
@@ -309,13 +461,13 @@ func (m *Manager) rotateJWTKey(ctx context.Context) error {
 
 	// if there is no next keypair set and the current is within the
 	// preparation threshold, generate one.
-	if m.nextJWTKey.IsEmpty() && m.currentJWTKey.ShouldPrepareNext(now) {
+	if m.nextJWTKey.IsEmpty() && m.currentJWTKey.ShouldPrepareNext(now, m.schedule) {
 		if err := m.prepareJWTKey(ctx, m.nextJWTKey); err != nil {
 			return err
 		}
 	}
 
-	if m.currentJWTKey.ShouldActivateNext(now) {
+	if m.currentJWTKey.ShouldActivateNext(now, m.schedule) {
 		m.currentJWTKey, m.nextJWTKey = m.nextJWTKey, m.currentJWTKey
 		m.nextJWTKey.Reset()
 		m.activateJWTKey()
@@ -341,6 +493,9 @@ func (m *Manager) prepareJWTKey(ctx context.Context, slot *jwtKeySlot) (err erro
 	if err != nil {
 		return err
 	}
+	if m.isX509CAKey(signer.Public()) {
+		return errs.New("key manager key %q is already used for X509 CA signing", slot.KmKeyID())
+	}
 
 	jwtKey, err := newJWTKey(signer, notAfter)
 	if err != nil {
@@ -443,6 +598,12 @@ func (m *Manager) pruneBundleEvery(ctx context.Context, interval time.Duration)
 }
 
 func (m *Manager) pruneBundle(ctx context.Context) (err error) {
+	// Only the server holding the lease prunes the bundle. The lease outlives
+	// the pruning interval so the holder renews it before it expires.
+	if !m.c.Leases.Acquire(ctx, bundlePruningLease, 2*pruneInterval) {
+		return nil
+	}
+
 	counter := telemetry_server.StartCAManagerPruneBundleCall(m.c.Metrics)
 	defer counter.Done(&err)
 
@@ -486,6 +647,30 @@ func (m *Manager) appendBundle(ctx context.Context, caChain []*x509.Certificate,
 	return res, nil
 }
 
+// setBundleRefreshHint stores the configured refresh hint with the trust
+// bundle, so it is served along with the bundle by the server APIs and the
+// federation endpoint.
+func (m *Manager) setBundleRefreshHint(ctx context.Context) error {
+	refreshHint := int64((m.c.BundleRefreshHint + (time.Second - 1)) / time.Second)
+
+	ds := m.c.Catalog.GetDataStore()
+	bundle, err := ds.FetchBundle(ctx, m.c.TrustDomain.IDString())
+	if err != nil {
+		return err
+	}
+	if bundle == nil || bundle.RefreshHint == refreshHint {
+		return nil
+	}
+
+	bundle.RefreshHint = refreshHint
+	if _, err := ds.UpdateBundle(ctx, bundle, &common.BundleMask{RefreshHint: true}); err != nil {
+		return fmt.Errorf("failed to set the bundle refresh hint: %w", err)
+	}
+	m.c.Log.WithField(telemetry.RefreshHint, m.c.BundleRefreshHint).Info("Bundle refresh hint updated")
+	m.bundleUpdated()
+	return nil
+}
+
 func (m *Manager) loadJournal(ctx context.Context) error {
 	jsonPath := filepath.Join(m.c.Dir, "certs.json")
 	if ok, err := migrateJSONFile(jsonPath, m.journalPath()); err != nil {
@@ -544,7 +729,7 @@ func (m *Manager) loadJournal(ctx context.Context) error {
 		m.nextX509CA = newX509CASlot("B")
 	}
 
-	if !m.currentX509CA.IsEmpty() && !m.currentX509CA.ShouldActivateNext(now) {
+	if !m.currentX509CA.IsEmpty() && !m.currentX509CA.ShouldActivateNext(now, m.schedule) {
 		// activate the X509CA immediately if it is set and not within
 		// activation time of the next X509CA.
 		m.activateX509CA()
@@ -575,7 +760,7 @@ func (m *Manager) loadJournal(ctx context.Context) error {
 		m.nextJWTKey = newJWTKeySlot("B")
 	}
 
-	if !m.currentJWTKey.IsEmpty() && !m.currentJWTKey.ShouldActivateNext(now) {
+	if !m.currentJWTKey.IsEmpty() && !m.currentJWTKey.ShouldActivateNext(now, m.schedule) {
 		// activate the JWT key immediately if it is set and not within
 		// activation time of the next JWT key.
 		m.activateJWTKey()
@@ -684,6 +869,10 @@ func (m *Manager) loadJWTKeySlotFromEntry(ctx context.Context, entry *JWTKeyEntr
 		return nil, "no key manager key", nil
 	case !publicKeyEqual(publicKey, signer.Public()):
 		return nil, "public key does not match key manager key", nil
+	case m.isX509CAKey(publicKey):
+		// Deployments that shared a key between the X509 CA and JWT signing
+		// get a fresh JWT key on the next rotation.
+		return nil, "public key is shared with an X509 CA", nil
 	}
 
 	return &jwtKeySlot{
@@ -964,12 +1153,16 @@ func (s *x509CASlot) Reset() {
 	s.x509CA = nil
 }
 
-func (s *x509CASlot) ShouldPrepareNext(now time.Time) bool {
-	return s.x509CA != nil && now.After(preparationThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
+func (s *x509CASlot) ShouldPrepareNext(now time.Time, schedule rotationSchedule) bool {
+	return s.x509CA != nil && now.After(schedule.preparationThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
 }
 
-func (s *x509CASlot) ShouldActivateNext(now time.Time) bool {
-	return s.x509CA != nil && now.After(keyActivationThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
+func (s *x509CASlot) ShouldActivateNext(now time.Time, schedule rotationSchedule) bool {
+	return s.x509CA != nil && now.After(schedule.activationThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
+}
+
+func (s *x509CASlot) ShouldFallBack(now time.Time, schedule rotationSchedule) bool {
+	return s.x509CA != nil && now.After(schedule.fallbackThreshold(s.issuedAt, s.x509CA.Certificate.NotAfter))
 }
 
 type jwtKeySlot struct {
@@ -996,12 +1189,12 @@ func (s *jwtKeySlot) Reset() {
 	s.jwtKey = nil
 }
 
-func (s *jwtKeySlot) ShouldPrepareNext(now time.Time) bool {
-	return s.jwtKey == nil || now.After(preparationThreshold(s.issuedAt, s.jwtKey.NotAfter))
+func (s *jwtKeySlot) ShouldPrepareNext(now time.Time, schedule rotationSchedule) bool {
+	return s.jwtKey == nil || now.After(schedule.preparationThreshold(s.issuedAt, s.jwtKey.NotAfter))
 }
 
-func (s *jwtKeySlot) ShouldActivateNext(now time.Time) bool {
-	return s.jwtKey == nil || now.After(keyActivationThreshold(s.issuedAt, s.jwtKey.NotAfter))
+func (s *jwtKeySlot) ShouldActivateNext(now time.Time, schedule rotationSchedule) bool {
+	return s.jwtKey == nil || now.After(schedule.activationThreshold(s.issuedAt, s.jwtKey.NotAfter))
 }
 
 func otherSlotID(id string) string {
@@ -1011,6 +1204,39 @@ func otherSlotID(id string) string {
 	return "A"
 }
 
+// checkKeySeparation fails if any of the JWT key slots shares its key manager
+// key with an X509 CA slot.
+func (m *Manager) checkKeySeparation() error {
+	for _, slot := range []*jwtKeySlot{m.currentJWTKey, m.nextJWTKey} {
+		if slot != nil && !slot.IsEmpty() && m.isX509CAKey(slot.jwtKey.Signer.Public()) {
+			return errs.New("key manager key %q is shared between X509 CA and JWT signing", slot.KmKeyID())
+		}
+	}
+	return nil
+}
+
+// isX509CAKey returns true if the public key belongs to the current or next
+// X509 CA.
+func (m *Manager) isX509CAKey(publicKey crypto.PublicKey) bool {
+	for _, slot := range []*x509CASlot{m.currentX509CA, m.nextX509CA} {
+		if slot != nil && !slot.IsEmpty() && publicKeyEqual(publicKey, slot.x509CA.Signer.Public()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJWTKey returns true if the public key belongs to the current or next JWT
+// key.
+func (m *Manager) isJWTKey(publicKey crypto.PublicKey) bool {
+	for _, slot := range []*jwtKeySlot{m.currentJWTKey, m.nextJWTKey} {
+		if slot != nil && !slot.IsEmpty() && publicKeyEqual(publicKey, slot.jwtKey.Signer.Public()) {
+			return true
+		}
+	}
+	return false
+}
+
 func publicKeyEqual(a, b crypto.PublicKey) bool {
 	matches, err := cryptoutil.PublicKeyEqual(a, b)
 	if err != nil {
@@ -1035,7 +1261,9 @@ func GenerateServerCACSR(signer crypto.Signer, trustDomain spiffeid.TrustDomain,
 	return csr, nil
 }
 
-func SelfSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiffeid.TrustDomain, subject pkix.Name, notBefore, notAfter time.Time) (*X509CA, []*x509.Certificate, error) {
+// SelfSignX509CA creates a self-signed X509 CA. If pathLen is set, it is
+// used as the pathLenConstraint of the CA certificate.
+func SelfSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiffeid.TrustDomain, subject pkix.Name, notBefore, notAfter time.Time, pathLen *int) (*X509CA, []*x509.Certificate, error) {
 	serialNumber, err := x509util.NewSerialNumber()
 	if err != nil {
 		return nil, nil, err
@@ -1045,6 +1273,9 @@ func SelfSignX509CA(ctx context.Context, signer crypto.Signer, trustDomain spiff
 	if err != nil {
 		return nil, nil, err
 	}
+	if pathLen != nil {
+		setPathLen(template, *pathLen)
+	}
 
 	certDER, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
 	if err != nil {
@@ -1114,22 +1345,55 @@ func MinCATTLForSVIDTTL(svidTTL time.Duration) time.Duration {
 	return svidTTL * activationThresholdDivisor
 }
 
-func preparationThreshold(issuedAt, notAfter time.Time) time.Time {
+// rotationSchedule determines when the next X509 CA or JWT key is prepared
+// and activated, relative to the expiration of the current one.
+type rotationSchedule struct {
+	preparationLeadTime time.Duration
+	activationOverlap   time.Duration
+}
+
+func (r rotationSchedule) preparationThreshold(issuedAt, notAfter time.Time) time.Time {
+	return notAfter.Add(-r.preparationLeadTimeFor(notAfter.Sub(issuedAt)))
+}
+
+func (r rotationSchedule) activationThreshold(issuedAt, notAfter time.Time) time.Time {
 	lifetime := notAfter.Sub(issuedAt)
-	threshold := lifetime / preparationThresholdDivisor
-	if threshold > preparationThresholdCap {
-		threshold = preparationThresholdCap
+	threshold := r.activationOverlap
+	if threshold <= 0 {
+		threshold = lifetime / activationThresholdDivisor
+		if threshold > activationThresholdCap {
+			threshold = activationThresholdCap
+		}
+	}
+	// The next key cannot be activated before it is prepared.
+	if leadTime := r.preparationLeadTimeFor(lifetime); threshold > leadTime {
+		threshold = leadTime
 	}
 	return notAfter.Add(-threshold)
 }
 
-func keyActivationThreshold(issuedAt, notAfter time.Time) time.Time {
-	lifetime := notAfter.Sub(issuedAt)
-	threshold := lifetime / activationThresholdDivisor
-	if threshold > activationThresholdCap {
-		threshold = activationThresholdCap
+// fallbackThreshold is halfway between the preparation and activation
+// thresholds. Past it, the next X509 CA is self-signed if the UpstreamAuthority
+// still fails, leaving the second half of the window to publish the
+// self-signed root before the X509 CA is activated.
+func (r rotationSchedule) fallbackThreshold(issuedAt, notAfter time.Time) time.Time {
+	preparation := r.preparationThreshold(issuedAt, notAfter)
+	activation := r.activationThreshold(issuedAt, notAfter)
+	return preparation.Add(activation.Sub(preparation) / 2)
+}
+
+func (r rotationSchedule) preparationLeadTimeFor(lifetime time.Duration) time.Duration {
+	if r.preparationLeadTime <= 0 {
+		threshold := lifetime / preparationThresholdDivisor
+		if threshold > preparationThresholdCap {
+			threshold = preparationThresholdCap
+		}
+		return threshold
 	}
-	return notAfter.Add(-threshold)
+	if r.preparationLeadTime > lifetime {
+		return lifetime
+	}
+	return r.preparationLeadTime
 }
 
 func newJWTKey(signer crypto.Signer, expiresAt time.Time) (*JWTKey, error) {
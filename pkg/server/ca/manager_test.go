@@ -12,6 +12,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -19,8 +20,10 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
+	"github.com/spiffe/spire/pkg/server/lease"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 	"github.com/spiffe/spire/pkg/server/plugin/notifier"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
@@ -43,6 +46,7 @@ const (
 	testCATTL     = time.Hour
 	activateAfter = testCATTL - (testCATTL / 6)
 	prepareAfter  = testCATTL - (testCATTL / 2)
+	fallbackAfter = prepareAfter + (activateAfter-prepareAfter)/2
 )
 
 var (
@@ -136,6 +140,42 @@ func (s *ManagerSuite) TestPersistenceFailsIfJournalLost() {
 	s.requireJWTKeyNotEqual(jwtKey, s.currentJWTKey())
 }
 
+func (s *ManagerSuite) TestRotationFailsIfKeyManagerSharesKeys() {
+	s.cat.SetKeyManager(sharedKeyManager{KeyManager: s.km})
+	s.m = NewManager(s.selfSignedConfig())
+	err := s.m.Initialize(context.Background())
+	s.RequireErrorContains(err, `key manager key "JWT-Signer-A" is already used for X509 CA signing`)
+}
+
+func (s *ManagerSuite) TestKeySeparationIsCheckedAtStartup() {
+	s.initSelfSignedManager()
+	s.Require().NoError(s.m.checkKeySeparation())
+
+	// Force the JWT key slot to use the X509 CA key, which the rotation
+	// checks would otherwise prevent.
+	s.m.currentJWTKey.jwtKey.Signer = s.m.currentX509CA.x509CA.Signer
+	s.RequireErrorContains(s.m.checkKeySeparation(), `key manager key "JWT-Signer-A" is shared between X509 CA and JWT signing`)
+}
+
+func (s *ManagerSuite) TestSharedJWTKeyIsDiscardedOnLoad() {
+	s.initSelfSignedManager()
+
+	// Simulate a deployment where the JWT key slot shares the key of the
+	// X509 CA. The slot is discarded so a distinct key is generated.
+	s.cat.SetKeyManager(sharedKeyManager{KeyManager: s.km})
+	publicKey, err := x509.MarshalPKIXPublicKey(s.currentX509CA().Signer.Public())
+	s.Require().NoError(err)
+	slot, badReason, err := s.m.loadJWTKeySlotFromEntry(context.Background(), &JWTKeyEntry{
+		SlotId:    "A",
+		PublicKey: publicKey,
+		Kid:       "KID",
+		NotAfter:  s.clock.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	s.Require().Nil(slot)
+	s.Require().Equal("public key is shared with an X509 CA", badReason)
+}
+
 func (s *ManagerSuite) TestSelfSigning() {
 	s.initSelfSignedManager()
 
@@ -152,6 +192,38 @@ func (s *ManagerSuite) TestSelfSigning() {
 	validateSelfSignedX509CA(s.T(), x509CA.Certificate, x509CA.Signer)
 }
 
+func (s *ManagerSuite) TestBundleRefreshHint() {
+	s.initSelfSignedManager()
+	s.Require().Zero(s.fetchBundle().RefreshHint)
+
+	// the configured refresh hint is stored with the bundle, without changing
+	// its contents or sequence number
+	sequenceNumber := s.fetchBundle().SequenceNumber
+	c := s.selfSignedConfig()
+	c.BundleRefreshHint = 5 * time.Minute
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	s.Require().Equal(int64(300), s.fetchBundle().RefreshHint)
+	s.Require().Equal(sequenceNumber, s.fetchBundle().SequenceNumber)
+
+	// the refresh hint is removed when no longer configured
+	s.initSelfSignedManager()
+	s.Require().Zero(s.fetchBundle().RefreshHint)
+}
+
+func (s *ManagerSuite) TestSelfSigningWithPathLen() {
+	s.cat.SetUpstreamAuthority(nil)
+	c := s.selfSignedConfig()
+	c.CAPathLen = intPtr(1)
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	x509CA := s.currentX509CA()
+	s.Require().NotNil(x509CA.Certificate)
+	s.Equal(1, x509CA.Certificate.MaxPathLen)
+	validateSelfSignedX509CA(s.T(), x509CA.Certificate, x509CA.Signer)
+}
+
 func (s *ManagerSuite) TestUpstreamSigned() {
 	upstreamAuthority, fakeUA := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
 		TrustDomain:           testTrustDomain,
@@ -207,6 +279,218 @@ func (s *ManagerSuite) TestUpstreamSignedProducesInvalidChain() {
 	s.RequireGRPCStatus(s.m.Initialize(context.Background()), codes.InvalidArgument, `X509 CA minted by upstream authority is invalid: X509 CA produced an invalid X509-SVID chain: x509svid: could not verify leaf certificate: x509: certificate signed by unknown authority (possibly because of "x509: invalid signature: parent certificate cannot sign this kind of certificate" while trying to verify candidate authority certificate "FAKEUPSTREAMAUTHORITY-ROOT")`)
 }
 
+func (s *ManagerSuite) TestUpstreamFallback() {
+	var upstreamFails bool
+	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishJWTKey: true,
+		MutateMintX509CAResponse: func(resp *upstreamauthorityv1.MintX509CAResponse) {
+			if upstreamFails {
+				resp.X509CaChain = nil
+			}
+		},
+	})
+	s.cat.SetUpstreamAuthority(upstreamAuthority)
+
+	// Without a current X509 CA there is nothing to fall back from
+	upstreamFails = true
+	c := s.selfSignedConfig()
+	c.UpstreamFallback = true
+	s.m = NewManager(c)
+	s.Require().Error(s.m.Initialize(context.Background()))
+
+	upstreamFails = false
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	first := s.currentX509CA()
+	s.Require().NotEmpty(first.UpstreamChain)
+
+	// Failures are retried while the current X509 CA is not past the
+	// fallback threshold
+	upstreamFails = true
+	s.clock.Add(prepareAfter + time.Minute)
+	s.Require().Error(s.m.rotateX509CA(context.Background()))
+	s.Require().Nil(s.nextX509CA())
+	s.Require().Zero(s.countLogEntries(logrus.WarnLevel, "UpstreamAuthority failed to sign X509 CA; falling back to a self-signed X509 CA"))
+
+	// The next X509 CA is self-signed past the fallback threshold, and its
+	// root is published before it is activated
+	s.addTimeAndRotateX509CA(fallbackAfter - prepareAfter)
+	s.Require().Equal(first, s.currentX509CA())
+	second := s.nextX509CA()
+	s.Require().NotNil(second)
+	s.Require().Empty(second.UpstreamChain)
+	s.Require().NoError(second.Certificate.CheckSignatureFrom(second.Certificate))
+	s.Require().Equal(1, s.countLogEntries(logrus.WarnLevel, "UpstreamAuthority failed to sign X509 CA; falling back to a self-signed X509 CA"))
+	s.Require().Contains(s.fetchBundle().RootCas, &common.Certificate{DerBytes: second.Certificate.Raw})
+
+	// The self-signed X509 CA is activated on schedule
+	s.addTimeAndRotateX509CA(activateAfter - fallbackAfter)
+	s.Require().Equal(second, s.currentX509CA())
+	s.Require().Nil(s.nextX509CA())
+}
+
+func (s *ManagerSuite) TestUpstreamFallbackRecoveryOfNextX509CA() {
+	upstreamFails, fakeUA := s.initUpstreamFallbackManager()
+	first := s.currentX509CA()
+
+	// Fall back to a self-signed next X509 CA
+	*upstreamFails = true
+	s.clock.Add(fallbackAfter + time.Minute)
+	s.Require().NoError(s.m.rotateX509CA(context.Background()))
+	fallback := s.nextX509CA()
+	s.Require().Empty(fallback.UpstreamChain)
+
+	// The self-signed next X509 CA is kept while the UpstreamAuthority fails
+	s.addTimeAndRotateX509CA(time.Minute)
+	s.Require().Equal(fallback, s.nextX509CA())
+	s.Require().Equal(1, s.countLogEntries(logrus.WarnLevel, "UpstreamAuthority still fails to sign X509 CA; keeping the self-signed X509 CA"))
+
+	// The self-signed next X509 CA is signed again by the UpstreamAuthority,
+	// with the same key, as soon as it recovers
+	*upstreamFails = false
+	s.addTimeAndRotateX509CA(time.Minute)
+	s.Require().Equal(first, s.currentX509CA())
+	recovered := s.nextX509CA()
+	s.Require().NotEmpty(recovered.UpstreamChain)
+	s.Require().Equal(fakeUA.X509Root().Subject, recovered.Certificate.Issuer)
+	s.Require().Equal(fallback.Signer.Public(), recovered.Signer.Public())
+	s.Require().Equal(1, s.countLogEntries(logrus.InfoLevel, "Self-signed X509 CA replaced by one signed by the UpstreamAuthority"))
+
+	// The recovered X509 CA is activated on schedule
+	s.addTimeAndRotateX509CA(activateAfter - fallbackAfter)
+	s.Require().Equal(recovered, s.currentX509CA())
+}
+
+func (s *ManagerSuite) TestUpstreamFallbackRecoveryOfCurrentX509CA() {
+	upstreamFails, fakeUA := s.initUpstreamFallbackManager()
+
+	// Fall back to a self-signed X509 CA and activate it
+	*upstreamFails = true
+	s.clock.Add(fallbackAfter + time.Minute)
+	s.Require().NoError(s.m.rotateX509CA(context.Background()))
+	s.addTimeAndRotateX509CA(activateAfter - fallbackAfter)
+	fallback := s.currentX509CA()
+	s.Require().Empty(fallback.UpstreamChain)
+	s.Require().Nil(s.nextX509CA())
+
+	// The UpstreamAuthority is retried while it fails
+	s.addTimeAndRotateX509CA(time.Minute)
+	s.Require().Equal(fallback, s.currentX509CA())
+	s.Require().Nil(s.nextX509CA())
+
+	// The self-signed current X509 CA is replaced right away by one signed
+	// by the UpstreamAuthority once it recovers
+	*upstreamFails = false
+	s.addTimeAndRotateX509CA(time.Minute)
+	current := s.currentX509CA()
+	s.Require().NotEmpty(current.UpstreamChain)
+	s.Require().Equal(fakeUA.X509Root().Subject, current.Certificate.Issuer)
+	s.Require().Nil(s.nextX509CA())
+}
+
+// initUpstreamFallbackManager initializes a manager with an UpstreamAuthority
+// and the fallback enabled. The UpstreamAuthority fails while the returned
+// bool is set.
+func (s *ManagerSuite) initUpstreamFallbackManager() (*bool, *fakeupstreamauthority.UpstreamAuthority) {
+	upstreamFails := new(bool)
+	upstreamAuthority, fakeUA := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishJWTKey: true,
+		MutateMintX509CAResponse: func(resp *upstreamauthorityv1.MintX509CAResponse) {
+			if *upstreamFails {
+				resp.X509CaChain = nil
+			}
+		},
+	})
+	s.cat.SetUpstreamAuthority(upstreamAuthority)
+
+	c := s.selfSignedConfig()
+	c.UpstreamFallback = true
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	return upstreamFails, fakeUA
+}
+
+func (s *ManagerSuite) TestUpstreamFallbackTransientFailure() {
+	var upstreamFails bool
+	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishJWTKey: true,
+		MutateMintX509CAResponse: func(resp *upstreamauthorityv1.MintX509CAResponse) {
+			if upstreamFails {
+				resp.X509CaChain = nil
+			}
+		},
+	})
+	s.cat.SetUpstreamAuthority(upstreamAuthority)
+
+	c := s.selfSignedConfig()
+	c.UpstreamFallback = true
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+	bundle := s.fetchBundle()
+
+	// A transient failure leaves the next slot empty
+	upstreamFails = true
+	s.clock.Add(prepareAfter + time.Minute)
+	s.Require().Error(s.m.rotateX509CA(context.Background()))
+	s.Require().Nil(s.nextX509CA())
+
+	// The next rotation is signed by the upstream authority and the bundle
+	// does not gain a self-signed root
+	upstreamFails = false
+	s.addTimeAndRotateX509CA(time.Minute)
+	next := s.nextX509CA()
+	s.Require().NotNil(next)
+	s.Require().NotEmpty(next.UpstreamChain)
+	s.Require().Zero(s.countLogEntries(logrus.WarnLevel, "UpstreamAuthority failed to sign X509 CA; falling back to a self-signed X509 CA"))
+	s.RequireProtoEqual(bundle, s.fetchBundle())
+}
+
+func (s *ManagerSuite) TestUpstreamFailureWithoutFallback() {
+	var upstreamFails bool
+	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishJWTKey: true,
+		MutateMintX509CAResponse: func(resp *upstreamauthorityv1.MintX509CAResponse) {
+			if upstreamFails {
+				resp.X509CaChain = nil
+			}
+		},
+	})
+	s.initUpstreamSignedManager(upstreamAuthority)
+
+	upstreamFails = true
+	s.clock.Add(prepareAfter + time.Minute)
+	s.Require().Error(s.m.rotateX509CA(context.Background()))
+	s.Require().Nil(s.nextX509CA())
+}
+
+func (s *ManagerSuite) TestUpstreamSignedWithShortenedTTL() {
+	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
+		TrustDomain:           testTrustDomain,
+		DisallowPublishJWTKey: true,
+	})
+	s.cat.SetUpstreamAuthority(upstreamAuthority)
+
+	// The upstream root of the fake upstream authority expires in one hour,
+	// which caps the lifetime of the X509 CA it mints.
+	metrics := fakemetrics.New()
+	c := s.selfSignedConfig()
+	c.CATTL = 2 * testCATTL
+	c.Metrics = metrics
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	s.Equal(1, s.countLogEntries(logrus.WarnLevel, "X509 CA minted by upstream authority expires before the configured CA TTL; check the upstream authority maximum TTL and the lifetime of its CA"))
+	s.Contains(metrics.AllMetrics(), fakemetrics.MetricItem{
+		Type: fakemetrics.IncrCounterType,
+		Key:  []string{telemetry.CA, telemetry.Manager, telemetry.X509CA, telemetry.TTL, telemetry.Shortened},
+		Val:  1,
+	})
+}
+
 func (s *ManagerSuite) TestUpstreamIntermediateSigned() {
 	upstreamAuthority, fakeUA := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
 		TrustDomain:           testTrustDomain,
@@ -330,6 +614,33 @@ func (s *ManagerSuite) TestX509CARotation() {
 	s.Nil(s.nextX509CA())
 }
 
+func (s *ManagerSuite) TestX509CARotationWithConfiguredSchedule() {
+	c := s.selfSignedConfig()
+	c.PreparationLeadTime = 20 * time.Minute
+	c.ActivationOverlap = 5 * time.Minute
+	s.m = NewManager(c)
+	s.Require().NoError(s.m.Initialize(context.Background()))
+
+	// CA TTL is an hour so we should be preparing after forty minutes and
+	// activating after 55 minutes.
+	initTime := s.clock.Now()
+	first := s.currentX509CA()
+
+	s.setTimeAndRotateX509CA(initTime.Add(40 * time.Minute))
+	s.Nil(s.nextX509CA(), "second X509CA should not be prepared yet")
+
+	s.addTimeAndRotateX509CA(time.Minute)
+	second := s.nextX509CA()
+	s.NotNil(second, "second X509CA should have been prepared")
+
+	s.setTimeAndRotateX509CA(initTime.Add(55 * time.Minute))
+	s.requireX509CAEqual(first, s.currentX509CA())
+
+	s.addTimeAndRotateX509CA(time.Minute)
+	s.requireX509CAEqual(second, s.currentX509CA())
+	s.Nil(s.nextX509CA())
+}
+
 func (s *ManagerSuite) TestX509CARotationMetric() {
 	s.initSelfSignedManager()
 
@@ -428,6 +739,35 @@ func (s *ManagerSuite) TestJWTKeyRotation() {
 	s.Nil(s.nextJWTKey())
 }
 
+func (s *ManagerSuite) TestPruneRequiresLease() {
+	config := s.selfSignedConfig()
+	config.Leases = lease.New(lease.Config{DataStore: s.ds, Log: s.log, Clock: s.clock, HolderID: "this"})
+	s.m = NewManager(config)
+	s.NoError(s.m.Initialize(context.Background()))
+
+	initTime := s.clock.Now()
+	prepareSecondTime := initTime.Add(prepareAfter)
+	firstExpiresTime := initTime.Add(testCATTL)
+
+	// rotate so that we have two in the bundle
+	s.setTimeAndRotate(prepareSecondTime.Add(time.Minute))
+	firstX509CA := s.currentX509CA()
+	secondX509CA := s.nextX509CA()
+	s.requireBundleRootCAs(firstX509CA.Certificate, secondX509CA.Certificate)
+
+	// advance beyond the safety threshold of the first while another server
+	// holds the lease. nothing should be pruned.
+	s.clock.Set(firstExpiresTime.Add(time.Minute + safetyThreshold))
+	other := lease.New(lease.Config{DataStore: s.ds, Log: s.log, Clock: s.clock, HolderID: "other"})
+	s.Require().True(other.Acquire(context.Background(), bundlePruningLease, time.Minute))
+	s.Require().NoError(s.m.pruneBundle(context.Background()))
+	s.requireBundleRootCAs(firstX509CA.Certificate, secondX509CA.Certificate)
+
+	// once the lease of the other server expires, the first is pruned
+	s.addTimeAndPrune(time.Minute)
+	s.requireBundleRootCAs(secondX509CA.Certificate)
+}
+
 func (s *ManagerSuite) TestPrune() {
 	notifier, notifyCh := fakenotifier.NotifyBundleUpdatedWaiter(s.T())
 	s.setNotifier(notifier)
@@ -547,7 +887,7 @@ func (s *ManagerSuite) TestPreparationThresholdCap() {
 
 	// Expect the preparation threshold to get capped since 1/2 of the lifetime
 	// exceeds the thirty day cap.
-	threshold := preparationThreshold(issuedAt, notAfter)
+	threshold := rotationSchedule{}.preparationThreshold(issuedAt, notAfter)
 	s.Require().Equal(thirtyDays, notAfter.Sub(threshold))
 }
 
@@ -557,10 +897,35 @@ func (s *ManagerSuite) TestActivationThreshholdCap() {
 
 	// Expect the activation threshold to get capped since 1/6 of the lifetime
 	// exceeds the seven day cap.
-	threshold := keyActivationThreshold(issuedAt, notAfter)
+	threshold := rotationSchedule{}.activationThreshold(issuedAt, notAfter)
 	s.Require().Equal(sevenDays, notAfter.Sub(threshold))
 }
 
+func (s *ManagerSuite) TestConfiguredRotationSchedule() {
+	issuedAt := time.Now()
+	notAfter := issuedAt.Add(365 * 24 * time.Hour)
+
+	schedule := rotationSchedule{
+		preparationLeadTime: 90 * 24 * time.Hour,
+		activationOverlap:   14 * 24 * time.Hour,
+	}
+	s.Require().Equal(90*24*time.Hour, notAfter.Sub(schedule.preparationThreshold(issuedAt, notAfter)))
+	s.Require().Equal(14*24*time.Hour, notAfter.Sub(schedule.activationThreshold(issuedAt, notAfter)))
+
+	// The lead time cannot exceed the lifetime and the overlap cannot
+	// exceed the lead time.
+	notAfter = issuedAt.Add(30 * 24 * time.Hour)
+	s.Require().Equal(issuedAt, schedule.preparationThreshold(issuedAt, notAfter))
+	schedule.preparationLeadTime = 10 * 24 * time.Hour
+	s.Require().Equal(10*24*time.Hour, notAfter.Sub(schedule.activationThreshold(issuedAt, notAfter)))
+
+	schedule.activationOverlap = 0
+	s.Require().Equal(5*24*time.Hour, notAfter.Sub(schedule.activationThreshold(issuedAt, notAfter)))
+
+	schedule = rotationSchedule{activationOverlap: 20 * 24 * time.Hour}
+	s.Require().Equal(15*24*time.Hour, notAfter.Sub(schedule.activationThreshold(issuedAt, notAfter)))
+}
+
 func (s *ManagerSuite) TestAlternateKeyTypes() {
 	upstreamAuthority, _ := fakeupstreamauthority.Load(s.T(), fakeupstreamauthority.Config{
 		TrustDomain: testTrustDomain,
@@ -973,3 +1338,24 @@ func validateSelfSignedX509CA(t *testing.T, ca *x509.Certificate, signer crypto.
 	}
 	require.NoError(t, validator.ValidateSelfSignedX509CA(ca))
 }
+
+// sharedKeyManager wraps a key manager, serving the X509 CA keys for the JWT
+// key slots.
+type sharedKeyManager struct {
+	keymanager.KeyManager
+}
+
+func (km sharedKeyManager) GenerateKey(ctx context.Context, id string, keyType keymanager.KeyType) (keymanager.Key, error) {
+	if strings.HasPrefix(id, "JWT-Signer-") {
+		return km.KeyManager.GetKey(ctx, sharedKeyID(id))
+	}
+	return km.KeyManager.GenerateKey(ctx, id, keyType)
+}
+
+func (km sharedKeyManager) GetKey(ctx context.Context, id string) (keymanager.Key, error) {
+	return km.KeyManager.GetKey(ctx, sharedKeyID(id))
+}
+
+func sharedKeyID(id string) string {
+	return strings.Replace(id, "JWT-Signer-", "x509-CA-", 1)
+}
@@ -73,6 +73,36 @@ func CreateX509SVIDTemplate(spiffeID spiffeid.ID, publicKey crypto.PublicKey, tr
 	}, nil
 }
 
+// setPathLen sets the pathLenConstraint of a CA certificate template
+func setPathLen(template *x509.Certificate, pathLen int) {
+	template.MaxPathLen = pathLen
+	template.MaxPathLenZero = pathLen == 0
+}
+
+// pathLenOf returns the pathLenConstraint of a CA certificate, or -1 if the
+// certificate is unconstrained
+func pathLenOf(cert *x509.Certificate) int {
+	if cert.MaxPathLen > 0 || (cert.MaxPathLen == 0 && cert.MaxPathLenZero) {
+		return cert.MaxPathLen
+	}
+	return -1
+}
+
+// minPathLen returns the most restrictive of two path lengths, where -1
+// means unconstrained
+func minPathLen(a, b int) int {
+	switch {
+	case a < 0:
+		return b
+	case b < 0:
+		return a
+	case a < b:
+		return a
+	default:
+		return b
+	}
+}
+
 func verifySameTrustDomain(td spiffeid.TrustDomain, id spiffeid.ID) error {
 	if !id.MemberOf(td) {
 		return fmt.Errorf("%q is not a member of trust domain %q", id, td)
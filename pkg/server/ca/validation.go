@@ -3,7 +3,9 @@ package ca
 import (
 	"crypto"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -18,14 +20,22 @@ MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEzLY1/SRlsMJExTnuvzBO292RjGjU
 -----END PUBLIC KEY-----`))
 )
 
+// upstreamClockSkew is the clock skew tolerated when checking the validity
+// period of the X509 CA chain minted by the upstream authority.
+const upstreamClockSkew = time.Minute
+
 type X509CAValidator struct {
 	TrustDomain spiffeid.TrustDomain
 	Signer      crypto.Signer
 }
 
 func (v X509CAValidator) ValidateUpstreamX509CA(x509CA, upstreamRoots []*x509.Certificate) error {
+	if err := validateX509CAChainLifetime(x509CA, time.Now()); err != nil {
+		return err
+	}
 	return v.validateX509CA(x509CA[0], upstreamRoots, x509CA)
 }
+
 func (v X509CAValidator) ValidateSelfSignedX509CA(x509CA *x509.Certificate) error {
 	return v.validateX509CA(x509CA, []*x509.Certificate{x509CA}, nil)
 }
@@ -56,3 +66,52 @@ func (v X509CAValidator) validateX509CA(x509CA *x509.Certificate, x509Roots, ups
 	}
 	return nil
 }
+
+// validateX509CAChainLifetime verifies that every certificate in the X509 CA
+// chain has a sane validity period that includes the current time.
+func validateX509CAChainLifetime(chain []*x509.Certificate, now time.Time) error {
+	for i, cert := range chain {
+		switch {
+		case !cert.NotAfter.After(cert.NotBefore):
+			return fmt.Errorf("certificate %d in the X509 CA chain has an empty validity period", i)
+		case !now.Before(cert.NotAfter):
+			return fmt.Errorf("certificate %d in the X509 CA chain expired at %s", i, cert.NotAfter.UTC().Format(time.RFC3339))
+		case cert.NotBefore.After(now.Add(upstreamClockSkew)):
+			return fmt.Errorf("certificate %d in the X509 CA chain is not valid until %s", i, cert.NotBefore.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// validateX509SVID verifies that a signed X509-SVID conforms to the X509-SVID
+// specification before it is handed out.
+func validateX509SVID(cert *x509.Certificate) error {
+	switch {
+	case len(cert.URIs) != 1:
+		return fmt.Errorf("expected exactly one URI SAN; got %d", len(cert.URIs))
+	case cert.IsCA:
+		return errors.New("CA flag is set")
+	case cert.KeyUsage&x509.KeyUsageDigitalSignature == 0:
+		return errors.New("digital signature key usage is missing")
+	case cert.KeyUsage&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign) != 0:
+		return errors.New("certificate or CRL signing key usage is set")
+	case !hasExtKeyUsage(cert, x509.ExtKeyUsageServerAuth):
+		return errors.New("server authentication extended key usage is missing")
+	case !hasExtKeyUsage(cert, x509.ExtKeyUsageClientAuth):
+		return errors.New("client authentication extended key usage is missing")
+	}
+
+	if _, err := spiffeid.FromURI(cert.URIs[0]); err != nil {
+		return fmt.Errorf("URI SAN is not a valid SPIFFE ID: %w", err)
+	}
+	return nil
+}
+
+func hasExtKeyUsage(cert *x509.Certificate, usage x509.ExtKeyUsage) bool {
+	for _, u := range cert.ExtKeyUsage {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package ca
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateX509CAChainLifetime(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := &x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)}
+
+	for _, tt := range []struct {
+		name      string
+		chain     []*x509.Certificate
+		expectErr string
+	}{
+		{
+			name:  "valid",
+			chain: []*x509.Certificate{valid, valid},
+		},
+		{
+			name:  "not yet valid within clock skew",
+			chain: []*x509.Certificate{{NotBefore: now.Add(30 * time.Second), NotAfter: now.Add(time.Hour)}},
+		},
+		{
+			name:      "empty validity period",
+			chain:     []*x509.Certificate{{NotBefore: now.Add(time.Hour), NotAfter: now.Add(-time.Hour)}},
+			expectErr: "certificate 0 in the X509 CA chain has an empty validity period",
+		},
+		{
+			name:      "expired intermediate",
+			chain:     []*x509.Certificate{valid, {NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(-time.Hour)}},
+			expectErr: "certificate 1 in the X509 CA chain expired at 2020-12-31T23:00:00Z",
+		},
+		{
+			name:      "not yet valid",
+			chain:     []*x509.Certificate{{NotBefore: now.Add(time.Hour), NotAfter: now.Add(2 * time.Hour)}},
+			expectErr: "certificate 0 in the X509 CA chain is not valid until 2021-01-01T01:00:00Z",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateX509CAChainLifetime(tt.chain, now)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -11,7 +11,9 @@ import (
 )
 
 const (
-	datastoreCacheExpiry = time.Second
+	// DefaultExpiry is how long cached bundles and node selectors are served
+	// by default
+	DefaultExpiry = time.Second
 )
 
 type useCache struct{}
@@ -26,19 +28,38 @@ type bundleEntry struct {
 	bundle *common.Bundle
 }
 
+type nodeSelectorsEntry struct {
+	mu        sync.Mutex
+	ts        time.Time
+	selectors []*common.Selector
+}
+
 type DatastoreCache struct {
 	datastore.DataStore
-	clock clock.Clock
+	clock  clock.Clock
+	expiry time.Duration
 
 	bundlesMu sync.Mutex
 	bundles   map[string]*bundleEntry
+
+	nodeSelectorsMu sync.Mutex
+	nodeSelectors   map[string]*nodeSelectorsEntry
 }
 
-func New(ds datastore.DataStore, clock clock.Clock) *DatastoreCache {
+// New returns a datastore that caches bundles and node selectors for the
+// given expiry when fetched with a context returned by WithCache. A zero
+// expiry means DefaultExpiry.
+func New(ds datastore.DataStore, clock clock.Clock, expiry time.Duration) *DatastoreCache {
+	if expiry == 0 {
+		expiry = DefaultExpiry
+	}
 	return &DatastoreCache{
 		DataStore: ds,
 		clock:     clock,
+		expiry:    expiry,
 		bundles:   make(map[string]*bundleEntry),
+
+		nodeSelectors: make(map[string]*nodeSelectorsEntry),
 	}
 }
 
@@ -53,7 +74,7 @@ func (ds *DatastoreCache) FetchBundle(ctx context.Context, trustDomain string) (
 
 	entry.mu.Lock()
 	defer entry.mu.Unlock()
-	if entry.ts.IsZero() || ds.clock.Now().Sub(entry.ts) >= datastoreCacheExpiry || ctx.Value(useCache{}) == nil {
+	if entry.ts.IsZero() || ds.clock.Now().Sub(entry.ts) >= ds.expiry || ctx.Value(useCache{}) == nil {
 		bundle, err := ds.DataStore.FetchBundle(ctx, trustDomain)
 		if err != nil {
 			return nil, err
@@ -108,3 +129,82 @@ func (ds *DatastoreCache) invalidateBundleEntry(trustDomainID string) {
 	delete(ds.bundles, trustDomainID)
 	ds.bundlesMu.Unlock()
 }
+
+func (ds *DatastoreCache) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency) ([]*common.Selector, error) {
+	if ctx.Value(useCache{}) == nil {
+		return ds.DataStore.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+	}
+
+	ds.nodeSelectorsMu.Lock()
+	entry, ok := ds.nodeSelectors[spiffeID]
+	if !ok {
+		entry = &nodeSelectorsEntry{}
+		ds.nodeSelectors[spiffeID] = entry
+	}
+	ds.nodeSelectorsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.ts.IsZero() || ds.clock.Now().Sub(entry.ts) >= ds.expiry {
+		selectors, err := ds.DataStore.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+		if err != nil {
+			return nil, err
+		}
+		entry.selectors = selectors
+		entry.ts = ds.clock.Now()
+	}
+	// Callers are free to modify the returned selectors
+	return copySelectors(entry.selectors), nil
+}
+
+// SetNodeSelectors sets the selectors of the node. When called with a context
+// returned by WithCache, the cache is updated with the new selectors, so they
+// are served without a round trip to the datastore, e.g. right after the
+// agent attests. Otherwise, the cached selectors are invalidated.
+func (ds *DatastoreCache) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) (err error) {
+	if err = ds.DataStore.SetNodeSelectors(ctx, spiffeID, selectors); err == nil {
+		if ctx.Value(useCache{}) != nil {
+			ds.setNodeSelectorsEntry(spiffeID, selectors)
+		} else {
+			ds.invalidateNodeSelectorsEntry(spiffeID)
+		}
+	}
+	return
+}
+
+func (ds *DatastoreCache) DeleteAttestedNode(ctx context.Context, spiffeID string) (node *common.AttestedNode, err error) {
+	if node, err = ds.DataStore.DeleteAttestedNode(ctx, spiffeID); err == nil {
+		ds.invalidateNodeSelectorsEntry(spiffeID)
+	}
+	return
+}
+
+func (ds *DatastoreCache) setNodeSelectorsEntry(spiffeID string, selectors []*common.Selector) {
+	entry := &nodeSelectorsEntry{
+		ts:        ds.clock.Now(),
+		selectors: copySelectors(selectors),
+	}
+	ds.nodeSelectorsMu.Lock()
+	ds.nodeSelectors[spiffeID] = entry
+	ds.nodeSelectorsMu.Unlock()
+}
+
+func (ds *DatastoreCache) invalidateNodeSelectorsEntry(spiffeID string) {
+	ds.nodeSelectorsMu.Lock()
+	delete(ds.nodeSelectors, spiffeID)
+	ds.nodeSelectorsMu.Unlock()
+}
+
+func copySelectors(selectors []*common.Selector) []*common.Selector {
+	if selectors == nil {
+		return nil
+	}
+	out := make([]*common.Selector, 0, len(selectors))
+	for _, selector := range selectors {
+		out = append(out, &common.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	return out
+}
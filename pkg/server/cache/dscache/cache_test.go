@@ -24,7 +24,7 @@ func TestFetchBundleCache(t *testing.T) {
 	bundle2 := &common.Bundle{TrustDomainId: "spiffe://domain.test", RefreshHint: 2}
 	ds := fakedatastore.New(t)
 	clock := clock.NewMock(t)
-	cache := New(ds, clock)
+	cache := New(ds, clock, 0)
 	ctxWithCache := WithCache(context.Background())
 	ctxWithoutCache := context.Background()
 
@@ -53,7 +53,7 @@ func TestFetchBundleCache(t *testing.T) {
 	spiretest.RequireProtoEqual(t, bundle1, bundle)
 
 	// If caches expires by time, FetchBundle must fetch a fresh bundle
-	clock.Add(datastoreCacheExpiry)
+	clock.Add(DefaultExpiry)
 	bundle, err = cache.FetchBundle(ctxWithCache, td)
 	require.NoError(t, err)
 	spiretest.RequireProtoEqual(t, bundle2, bundle)
@@ -72,6 +72,36 @@ func TestFetchBundleCache(t *testing.T) {
 	spiretest.RequireProtoEqual(t, bundle1, bundle)
 }
 
+func TestFetchBundleCacheExpiry(t *testing.T) {
+	td := "spiffe://domain.test"
+	bundle1 := &common.Bundle{TrustDomainId: "spiffe://domain.test", RefreshHint: 1}
+	bundle2 := &common.Bundle{TrustDomainId: "spiffe://domain.test", RefreshHint: 2}
+	ds := fakedatastore.New(t)
+	clock := clock.NewMock(t)
+	cache := New(ds, clock, 5*time.Second)
+	ctxWithCache := WithCache(context.Background())
+
+	_, err := ds.SetBundle(ctxWithCache, bundle1)
+	require.NoError(t, err)
+	bundle, err := cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+
+	_, err = ds.SetBundle(context.Background(), bundle2)
+	require.NoError(t, err)
+
+	// The cached bundle is still served past the default expiry
+	clock.Add(DefaultExpiry)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+
+	clock.Add(5*time.Second - DefaultExpiry)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle2, bundle)
+}
+
 func TestBundleInvalidations(t *testing.T) {
 	td := "spiffe://domain.test"
 	bundle1, bundle2 := getBundles(t, "spiffe://domain.test")
@@ -151,7 +181,7 @@ func TestBundleInvalidations(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create datastore and cache
 			ds := fakedatastore.New(t)
-			cache := New(ds, clock.NewMock(t))
+			cache := New(ds, clock.NewMock(t), 0)
 			ctxWithCache := WithCache(context.Background())
 
 			// Add bundle (bundle1)
@@ -192,6 +222,162 @@ func TestBundleInvalidations(t *testing.T) {
 	}
 }
 
+func TestGetNodeSelectorsCache(t *testing.T) {
+	agentID := "spiffe://domain.test/spire/agent/foo"
+	selectors1 := []*common.Selector{{Type: "a", Value: "1"}}
+	selectors2 := []*common.Selector{{Type: "b", Value: "2"}}
+	ds := fakedatastore.New(t)
+	clock := clock.NewMock(t)
+	cache := New(ds, clock, 0)
+	ctxWithCache := WithCache(context.Background())
+
+	require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors1))
+	selectors, err := cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, selectors1, selectors)
+
+	// Change selectors behind the cache
+	require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors2))
+
+	// Assert selectors unchanged since cache is still valid
+	selectors, err = cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, selectors1, selectors)
+
+	// A context without cache fetches fresh selectors
+	selectors, err = cache.GetNodeSelectors(context.Background(), agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, selectors2, selectors)
+
+	// Change selectors behind the cache
+	require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors1))
+
+	// If caches expires by time, GetNodeSelectors must fetch fresh selectors
+	clock.Add(DefaultExpiry)
+	selectors, err = cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, selectors1, selectors)
+}
+
+func TestGetNodeSelectorsWithoutCache(t *testing.T) {
+	agentID := "spiffe://domain.test/spire/agent/foo"
+	ds := fakedatastore.New(t)
+	cache := New(ds, clock.NewMock(t), 0)
+
+	require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, []*common.Selector{{Type: "a", Value: "1"}}))
+	_, err := cache.GetNodeSelectors(context.Background(), agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	require.Empty(t, cache.nodeSelectors)
+}
+
+func TestGetNodeSelectorsReturnsCopy(t *testing.T) {
+	agentID := "spiffe://domain.test/spire/agent/foo"
+	selectors1 := []*common.Selector{{Type: "a", Value: "1"}}
+	ds := fakedatastore.New(t)
+	cache := New(ds, clock.NewMock(t), 0)
+	ctxWithCache := WithCache(context.Background())
+
+	require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors1))
+	selectors, err := cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	selectors[0].Value = "2"
+	_ = append(selectors[:0], &common.Selector{Type: "b", Value: "2"})
+
+	selectors, err = cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, selectors1, selectors)
+}
+
+func TestNodeSelectorsInvalidation(t *testing.T) {
+	agentID := "spiffe://domain.test/spire/agent/foo"
+	selectors1 := []*common.Selector{{Type: "a", Value: "1"}}
+	selectors2 := []*common.Selector{{Type: "b", Value: "2"}}
+	selectors3 := []*common.Selector{{Type: "c", Value: "3"}}
+
+	for _, tt := range []struct {
+		name       string
+		dsFailure  bool
+		invalidate func(cache *DatastoreCache)
+		expected   []*common.Selector
+	}{
+		{
+			name: "SetNodeSelectors invalidates cache if succeeds",
+			invalidate: func(cache *DatastoreCache) {
+				_ = cache.SetNodeSelectors(context.Background(), agentID, selectors3)
+			},
+			expected: selectors2,
+		},
+		{
+			name:      "SetNodeSelectors keeps cache if fails",
+			dsFailure: true,
+			invalidate: func(cache *DatastoreCache) {
+				_ = cache.SetNodeSelectors(context.Background(), agentID, selectors3)
+			},
+			expected: selectors1,
+		},
+		{
+			name: "SetNodeSelectors with cache updates cache if succeeds",
+			invalidate: func(cache *DatastoreCache) {
+				_ = cache.SetNodeSelectors(WithCache(context.Background()), agentID, selectors3)
+			},
+			expected: selectors3,
+		},
+		{
+			name:      "SetNodeSelectors with cache keeps cache if fails",
+			dsFailure: true,
+			invalidate: func(cache *DatastoreCache) {
+				_ = cache.SetNodeSelectors(WithCache(context.Background()), agentID, selectors3)
+			},
+			expected: selectors1,
+		},
+		{
+			name: "DeleteAttestedNode invalidates cache if succeeds",
+			invalidate: func(cache *DatastoreCache) {
+				_, _ = cache.DeleteAttestedNode(context.Background(), agentID)
+			},
+			expected: selectors2,
+		},
+		{
+			name:      "DeleteAttestedNode keeps cache if fails",
+			dsFailure: true,
+			invalidate: func(cache *DatastoreCache) {
+				_, _ = cache.DeleteAttestedNode(context.Background(), agentID)
+			},
+			expected: selectors1,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			cache := New(ds, clock.NewMock(t), 0)
+			ctxWithCache := WithCache(context.Background())
+
+			_, err := ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
+				SpiffeId:            agentID,
+				AttestationDataType: "test",
+				CertSerialNumber:    "1",
+				CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+			})
+			require.NoError(t, err)
+			require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors1))
+			_, err = cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+			require.NoError(t, err)
+
+			if tt.dsFailure {
+				ds.SetNextError(fmt.Errorf("failure"))
+			}
+			tt.invalidate(cache)
+
+			// Change selectors behind the cache
+			require.NoError(t, ds.SetNodeSelectors(context.Background(), agentID, selectors2))
+
+			selectors, err := cache.GetNodeSelectors(ctxWithCache, agentID, datastore.RequireCurrent)
+			require.NoError(t, err)
+			spiretest.RequireProtoListEqual(t, tt.expected, selectors)
+		})
+	}
+}
+
 // getBundles returns two different bundles with the same trust domain.
 func getBundles(t *testing.T, td string) (*common.Bundle, *common.Bundle) {
 	roots, keys := getRoots(t, td), getKeys(t)
@@ -28,6 +28,8 @@ var _ Cache = (*FullEntryCache)(nil)
 // at a particular moment in time.
 type Cache interface {
 	GetAuthorizedEntries(agentID spiffeid.ID) []*types.Entry
+	GetAuthorizedEntriesWithSelectors(agentID spiffeid.ID, selectors []*types.Selector) []*types.Entry
+	HasAgent(agentID spiffeid.ID) bool
 }
 
 // Selector is a key-value attribute of a node or workload.
@@ -87,6 +89,8 @@ type Agent struct {
 type FullEntryCache struct {
 	aliases map[spiffeID][]aliasEntry
 	entries map[spiffeID][]*types.Entry
+	agents  seenSet
+	bysel   map[Selector][]aliasInfo
 }
 
 type selectorSet map[Selector]struct{}
@@ -105,13 +109,14 @@ type aliasEntry struct {
 	entry *types.Entry
 }
 
+type aliasInfo struct {
+	aliasEntry
+	selectors selectorSet
+}
+
 // Build queries the data source for all registration entries and Agent selectors and builds an in-memory
 // representation of the data that can be used for efficient lookups.
 func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator) (*FullEntryCache, error) {
-	type aliasInfo struct {
-		aliasEntry
-		selectors selectorSet
-	}
 	bysel := make(map[Selector][]aliasInfo)
 
 	entries := make(map[spiffeID][]*types.Entry)
@@ -141,23 +146,13 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	defer freeStringSet(aliasSeen)
 
 	aliases := make(map[spiffeID][]aliasEntry)
+	agents := make(seenSet)
 	for agentIter.Next(ctx) {
 		agent := agentIter.Agent()
 		agentID := spiffeIDFromID(agent.ID)
-		agentSelectors := selectorSetFromProto(agent.Selectors)
-		// track which aliases we've evaluated so far to make sure we don't
-		// add one twice.
-		clearStringSet(aliasSeen)
-		for s := range agentSelectors {
-			for _, alias := range bysel[s] {
-				if _, ok := aliasSeen[alias.entry.Id]; ok {
-					continue
-				}
-				aliasSeen[alias.entry.Id] = struct{}{}
-				if isSubset(alias.selectors, agentSelectors) {
-					aliases[agentID] = append(aliases[agentID], alias.aliasEntry)
-				}
-			}
+		agents[agentID] = struct{}{}
+		if agentAliases := resolveAliases(bysel, selectorSetFromProto(agent.Selectors), aliasSeen); len(agentAliases) > 0 {
+			aliases[agentID] = agentAliases
 		}
 	}
 	if err := agentIter.Err(); err != nil {
@@ -167,6 +162,8 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	return &FullEntryCache{
 		aliases: aliases,
 		entries: entries,
+		agents:  agents,
+		bysel:   bysel,
 	}, nil
 }
 
@@ -178,13 +175,37 @@ func (c *FullEntryCache) GetAuthorizedEntries(agentID spiffeid.ID) []*types.Entr
 	return c.getAuthorizedEntries(spiffeIDFromID(agentID), seen)
 }
 
+// HasAgent returns true if the selectors of the given Agent were part of the snapshot.
+func (c *FullEntryCache) HasAgent(agentID spiffeid.ID) bool {
+	_, ok := c.agents[spiffeIDFromID(agentID)]
+	return ok
+}
+
+// GetAuthorizedEntriesWithSelectors gets all authorized registration entries for a given Agent
+// SPIFFE ID, matching node aliases against the given Agent selectors instead of the selectors in
+// the snapshot. It is used for Agents that attested after the snapshot was taken.
+func (c *FullEntryCache) GetAuthorizedEntriesWithSelectors(agentID spiffeid.ID, selectors []*types.Selector) []*types.Entry {
+	seen := allocSeenSet()
+	defer freeSeenSet(seen)
+
+	aliasSeen := allocStringSet()
+	defer freeStringSet(aliasSeen)
+
+	aliases := resolveAliases(c.bysel, selectorSetFromProto(selectors), aliasSeen)
+	return c.getAuthorizedEntriesWithAliases(spiffeIDFromID(agentID), aliases, seen)
+}
+
 func (c *FullEntryCache) getAuthorizedEntries(id spiffeID, seen map[spiffeID]struct{}) []*types.Entry {
+	return c.getAuthorizedEntriesWithAliases(id, c.aliases[id], seen)
+}
+
+func (c *FullEntryCache) getAuthorizedEntriesWithAliases(id spiffeID, aliases []aliasEntry, seen map[spiffeID]struct{}) []*types.Entry {
 	entries := c.crawl(id, seen)
 	for _, descendant := range entries {
 		entries = append(entries, c.getAuthorizedEntries(spiffeIDFromProto(descendant.SpiffeId), seen)...)
 	}
 
-	for _, alias := range c.aliases[id] {
+	for _, alias := range aliases {
 		entries = append(entries, alias.entry)
 		entries = append(entries, c.getAuthorizedEntries(alias.id, seen)...)
 	}
@@ -205,6 +226,28 @@ func (c *FullEntryCache) crawl(parentID spiffeID, seen map[spiffeID]struct{}) []
 	return entries
 }
 
+// resolveAliases returns the node aliases whose selectors are a subset of the
+// given Agent selectors.
+func resolveAliases(bysel map[Selector][]aliasInfo, agentSelectors selectorSet, aliasSeen stringSet) []aliasEntry {
+	// track which aliases we've evaluated so far to make sure we don't
+	// add one twice.
+	clearStringSet(aliasSeen)
+
+	var aliases []aliasEntry
+	for s := range agentSelectors {
+		for _, alias := range bysel[s] {
+			if _, ok := aliasSeen[alias.entry.Id]; ok {
+				continue
+			}
+			aliasSeen[alias.entry.Id] = struct{}{}
+			if isSubset(alias.selectors, agentSelectors) {
+				aliases = append(aliases, alias.aliasEntry)
+			}
+		}
+	}
+	return aliases
+}
+
 func spiffeIDFromID(id spiffeid.ID) spiffeID {
 	return spiffeID{
 		TrustDomain: id.TrustDomain().String(),
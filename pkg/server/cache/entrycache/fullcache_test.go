@@ -218,6 +218,17 @@ func TestFullCacheNodeAliasing(t *testing.T) {
 	assertAuthorizedEntries(agentIDs[0], append(nodeAliasEntries, workloadEntries[:2]...)...)
 	assertAuthorizedEntries(agentIDs[1], nodeAliasEntries[1], workloadEntries[1])
 	assertAuthorizedEntries(agentIDs[2], workloadEntries[2])
+
+	// Agents that attested after the cache was built have their node aliases
+	// matched against the given selectors
+	newAgentID := spiffeid.RequireFromString("spiffe://example.org/spire/agent/agent4")
+	assert.True(t, cache.HasAgent(agentIDs[0]))
+	assert.False(t, cache.HasAgent(newAgentID))
+
+	expected, err := api.RegistrationEntriesToProto([]*common.RegistrationEntry{nodeAliasEntries[1], workloadEntries[1]})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, expected, cache.GetAuthorizedEntriesWithSelectors(newAgentID, api.ProtoFromSelectors([]*common.Selector{s1, s3})))
+	assert.Empty(t, cache.GetAuthorizedEntriesWithSelectors(newAgentID, api.ProtoFromSelectors([]*common.Selector{s2})))
 }
 
 func TestFullCacheExcludesNodeSelectorMappedEntriesForExpiredAgents(t *testing.T) {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
+	"time"
 
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
@@ -59,6 +59,18 @@ type Config struct {
 	TrustDomain  spiffeid.TrustDomain
 	PluginConfig HCLPluginConfigMap
 
+	// RequirePluginChecksums, if true, fails to load external plugins that
+	// do not have a checksum configured.
+	RequirePluginChecksums bool
+
+	// BundleCacheExpiry is how long bundles and node selectors fetched from
+	// the datastore are cached. Zero means the default expiry.
+	BundleCacheExpiry time.Duration
+
+	// DataStoreSlowOperationThreshold, if positive, is how long a datastore
+	// operation can take before it is logged as slow.
+	DataStoreSlowOperationThreshold time.Duration
+
 	Metrics          telemetry.Metrics
 	IdentityProvider *identityprovider.IdentityProvider
 	AgentStore       *agentstore.AgentStore
@@ -75,9 +87,10 @@ type Repository struct {
 	notifierRepository
 	upstreamAuthorityRepository
 
-	log             logrus.FieldLogger
-	dataStoreCloser io.Closer
-	catalogCloser   io.Closer
+	log           logrus.FieldLogger
+	sqlDataStore  *ds_sql.Plugin
+	dataStoreData string
+	plugins       *catalog.LoadedPlugins
 }
 
 func (repo *Repository) Plugins() map[string]catalog.PluginRepo {
@@ -97,26 +110,57 @@ func (repo *Repository) Services() []catalog.ServiceRepo {
 func (repo *Repository) Close() {
 	// Must close in reverse initialization order!
 
-	repo.log.Debug("Closing catalog")
-	if err := repo.catalogCloser.Close(); err == nil {
-		repo.log.Info("Catalog closed")
-	} else {
-		repo.log.WithError(err).Error("Failed to close catalog")
+	if repo.plugins != nil {
+		repo.log.Debug("Closing catalog")
+		if err := repo.plugins.Close(); err == nil {
+			repo.log.Info("Catalog closed")
+		} else {
+			repo.log.WithError(err).Error("Failed to close catalog")
+		}
 	}
 
-	repo.log.Debug("Closing DataStore")
-	if err := repo.dataStoreCloser.Close(); err == nil {
-		repo.log.Info("DataStore closed")
-	} else {
-		repo.log.WithError(err).Error("Failed to close DataStore")
+	if repo.sqlDataStore != nil {
+		repo.log.Debug("Closing DataStore")
+		if err := repo.sqlDataStore.Close(); err == nil {
+			repo.log.Info("DataStore closed")
+		} else {
+			repo.log.WithError(err).Error("Failed to close DataStore")
+		}
 	}
 }
 
+// Reconfigure reconfigures the DataStore and the loaded plugins using the
+// given plugin configuration. Only the plugin data can be changed; see
+// catalog.LoadedPlugins.Reconfigure for details. Plugins that fail to
+// reconfigure keep running with their current configuration.
+func (repo *Repository) Reconfigure(ctx context.Context, pluginConfig HCLPluginConfigMap) error {
+	pluginConfig = stripJoinTokenOverride(pluginConfig)
+
+	sqlConfig, err := sqlDataStoreConfig(pluginConfig[dataStoreType])
+	if err != nil {
+		return err
+	}
+	if sqlConfig.Data != repo.dataStoreData {
+		if err := repo.sqlDataStore.Configure(ctx, sqlConfig.Data); err != nil {
+			repo.log.WithError(err).Error("Failed to reconfigure DataStore; keeping current configuration")
+			return fmt.Errorf("failed to reconfigure DataStore: %w", err)
+		}
+		repo.dataStoreData = sqlConfig.Data
+		repo.log.Info("DataStore reconfigured")
+	}
+
+	pluginConfigs, err := catalog.PluginConfigsFromHCL(withoutDataStore(pluginConfig))
+	if err != nil {
+		return err
+	}
+	return repo.plugins.Reconfigure(ctx, pluginConfigs)
+}
+
 func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	// DEPRECATE: make this an error in SPIRE 1.5
 	if c, ok := config.PluginConfig[nodeAttestorType][jointoken.PluginName]; ok && c.IsEnabled() && c.IsExternal() {
 		config.Log.Warn("The built-in join_token node attestor cannot be overridden by an external plugin. The external plugin will be ignored; this will be a configuration error in a future release.")
-		config.PluginConfig[nodeAttestorType][jointoken.PluginName] = catalog.HCLPluginConfig{}
+		config.PluginConfig = stripJoinTokenOverride(config.PluginConfig)
 	}
 
 	repo := &Repository{
@@ -130,25 +174,30 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 
 	// Strip out the Datastore plugin configuration and load the SQL plugin
 	// directly. This allows us to bypass gRPC and get rid of response limits.
-	dataStoreConfig := config.PluginConfig[dataStoreType]
-	delete(config.PluginConfig, dataStoreType)
-	sqlDataStore, err := loadSQLDataStore(ctx, config.Log, dataStoreConfig)
+	sqlConfig, err := sqlDataStoreConfig(config.PluginConfig[dataStoreType])
+	if err != nil {
+		return nil, err
+	}
+	sqlDataStore, err := loadSQLDataStore(ctx, config.Log, sqlConfig)
 	if err != nil {
 		return nil, err
 	}
-	repo.dataStoreCloser = sqlDataStore
+	repo.sqlDataStore = sqlDataStore
+	repo.dataStoreData = sqlConfig.Data
+	sqlDataStore.ReportPoolMetrics(ctx, config.Metrics)
 
-	pluginConfigs, err := catalog.PluginConfigsFromHCL(config.PluginConfig)
+	pluginConfigs, err := catalog.PluginConfigsFromHCL(withoutDataStore(config.PluginConfig))
 	if err != nil {
 		return nil, err
 	}
 
-	repo.catalogCloser, err = catalog.Load(ctx, catalog.Config{
+	plugins, err := catalog.Load(ctx, catalog.Config{
 		Log: config.Log,
 		CoreConfig: catalog.CoreConfig{
 			TrustDomain: config.TrustDomain,
 		},
-		PluginConfigs: pluginConfigs,
+		PluginConfigs:          pluginConfigs,
+		RequirePluginChecksums: config.RequirePluginChecksums,
 		HostServices: []pluginsdk.ServiceServer{
 			identityproviderv1.IdentityProviderServiceServer(config.IdentityProvider.V1()),
 			agentstorev1.AgentStoreServiceServer(config.AgentStore.V1()),
@@ -158,14 +207,15 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	if err != nil {
 		return nil, err
 	}
+	repo.plugins = plugins
 
 	var dataStore datastore.DataStore = sqlDataStore
 	_ = config.HealthChecker.AddCheck("catalog.datastore", &datastore.Health{
 		DataStore: dataStore,
 	})
 
-	dataStore = ds_telemetry.WithMetrics(dataStore, config.Metrics)
-	dataStore = dscache.New(dataStore, clock.New())
+	dataStore = ds_telemetry.WithMetrics(dataStore, config.Metrics, config.Log, config.DataStoreSlowOperationThreshold)
+	dataStore = dscache.New(dataStore, clock.New(), config.BundleCacheExpiry)
 
 	repo.SetDataStore(dataStore)
 	repo.SetKeyManager(km_telemetry.WithMetrics(repo.GetKeyManager(), config.Metrics))
@@ -173,32 +223,66 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	return repo, nil
 }
 
-func loadSQLDataStore(ctx context.Context, log logrus.FieldLogger, datastoreConfig map[string]catalog.HCLPluginConfig) (*ds_sql.Plugin, error) {
+// stripJoinTokenOverride returns a copy of the plugin configuration where an
+// external join_token node attestor is replaced by the built-in one.
+func stripJoinTokenOverride(pluginConfig HCLPluginConfigMap) HCLPluginConfigMap {
+	if c, ok := pluginConfig[nodeAttestorType][jointoken.PluginName]; !ok || !c.IsEnabled() || !c.IsExternal() {
+		return pluginConfig
+	}
+	stripped := make(HCLPluginConfigMap, len(pluginConfig))
+	for pluginType, pluginsForType := range pluginConfig {
+		stripped[pluginType] = pluginsForType
+	}
+	nodeAttestors := make(map[string]catalog.HCLPluginConfig, len(pluginConfig[nodeAttestorType]))
+	for name, c := range pluginConfig[nodeAttestorType] {
+		nodeAttestors[name] = c
+	}
+	nodeAttestors[jointoken.PluginName] = catalog.HCLPluginConfig{}
+	stripped[nodeAttestorType] = nodeAttestors
+	return stripped
+}
+
+// withoutDataStore returns a copy of the plugin configuration without the
+// DataStore plugin configuration.
+func withoutDataStore(pluginConfig HCLPluginConfigMap) HCLPluginConfigMap {
+	stripped := make(HCLPluginConfigMap, len(pluginConfig))
+	for pluginType, pluginsForType := range pluginConfig {
+		if pluginType != dataStoreType {
+			stripped[pluginType] = pluginsForType
+		}
+	}
+	return stripped
+}
+
+func loadSQLDataStore(ctx context.Context, log logrus.FieldLogger, sqlConfig catalog.PluginConfig) (*ds_sql.Plugin, error) {
+	ds := ds_sql.New(log.WithField(telemetry.SubsystemName, sqlConfig.Name))
+	if err := ds.Configure(ctx, sqlConfig.Data); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func sqlDataStoreConfig(datastoreConfig map[string]catalog.HCLPluginConfig) (catalog.PluginConfig, error) {
 	switch {
 	case len(datastoreConfig) == 0:
-		return nil, errors.New("expecting a DataStore plugin")
+		return catalog.PluginConfig{}, errors.New("expecting a DataStore plugin")
 	case len(datastoreConfig) > 1:
-		return nil, errors.New("only one DataStore plugin is allowed")
+		return catalog.PluginConfig{}, errors.New("only one DataStore plugin is allowed")
 	}
 
 	sqlHCLConfig, ok := datastoreConfig[ds_sql.PluginName]
 	if !ok {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
+		return catalog.PluginConfig{}, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
 	}
 
 	sqlConfig, err := catalog.PluginConfigFromHCL(dataStoreType, ds_sql.PluginName, sqlHCLConfig)
 	if err != nil {
-		return nil, err
+		return catalog.PluginConfig{}, err
 	}
 
 	// Is the plugin external?
 	if sqlConfig.Path != "" {
-		return nil, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
-	}
-
-	ds := ds_sql.New(log.WithField(telemetry.SubsystemName, sqlConfig.Name))
-	if err := ds.Configure(ctx, sqlConfig.Data); err != nil {
-		return nil, err
+		return catalog.PluginConfig{}, fmt.Errorf("pluggability for the DataStore is deprecated; only the built-in %q plugin is supported", ds_sql.PluginName)
 	}
-	return ds, nil
+	return sqlConfig, nil
 }
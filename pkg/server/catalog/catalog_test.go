@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
@@ -23,6 +24,7 @@ func TestJoinTokenNodeAttestorCannotBeOverriden(t *testing.T) {
 	minimalConfig := func() catalog.Config {
 		return catalog.Config{
 			Log:           log,
+			Metrics:       telemetry.Blackhole{},
 			HealthChecker: fakeHealthChecker{},
 			PluginConfig: catalog.HCLPluginConfigMap{
 				"DataStore": {
@@ -61,6 +63,59 @@ func TestJoinTokenNodeAttestorCannotBeOverriden(t *testing.T) {
 	})
 }
 
+func TestReconfigure(t *testing.T) {
+	dir := t.TempDir()
+	log, hook := test.NewNullLogger()
+
+	pluginConfig := func(dataStoreData string) catalog.HCLPluginConfigMap {
+		return catalog.HCLPluginConfigMap{
+			"DataStore": {
+				"sql": {
+					PluginData: astPrintf(t, dataStoreData, filepath.Join(dir, "test.sql")),
+				},
+			},
+			"KeyManager": {
+				"memory": {},
+			},
+		}
+	}
+
+	repo, err := catalog.Load(context.Background(), catalog.Config{
+		Log:           log,
+		Metrics:       telemetry.Blackhole{},
+		HealthChecker: fakeHealthChecker{},
+		PluginConfig:  pluginConfig(`database_type = "sqlite3" connection_string = %q`),
+	})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	t.Run("unchanged", func(t *testing.T) {
+		hook.Reset()
+		err := repo.Reconfigure(context.Background(), pluginConfig(`database_type = "sqlite3" connection_string = %q`))
+		require.NoError(t, err)
+		require.Empty(t, hook.AllEntries())
+	})
+
+	t.Run("datastore changed", func(t *testing.T) {
+		hook.Reset()
+		err := repo.Reconfigure(context.Background(), pluginConfig(`database_type = "sqlite3" connection_string = %q max_open_conns = 5`))
+		require.NoError(t, err)
+		spiretest.AssertLogsContainEntries(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "DataStore reconfigured",
+			},
+		})
+	})
+
+	t.Run("datastore configure fails", func(t *testing.T) {
+		hook.Reset()
+		err := repo.Reconfigure(context.Background(), pluginConfig(`database_type = "bogus" connection_string = %q`))
+		require.ErrorContains(t, err, "failed to reconfigure DataStore")
+		require.NotNil(t, repo.GetDataStore())
+	})
+}
+
 type fakeHealthChecker struct{}
 
 func (fakeHealthChecker) AddCheck(name string, checkable health.Checkable) error { return nil }
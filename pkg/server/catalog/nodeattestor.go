@@ -9,8 +9,10 @@ import (
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/jointoken"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/k8s/psat"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/k8s/sat"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/oidc"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/sshpop"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/tpmdevid"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/vsphere"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor/x509pop"
 )
 
@@ -40,8 +42,10 @@ func (repo *nodeAttestorRepository) BuiltIns() []catalog.BuiltIn {
 		jointoken.BuiltIn(),
 		psat.BuiltIn(),
 		sat.BuiltIn(),
+		oidc.BuiltIn(),
 		sshpop.BuiltIn(),
 		tpmdevid.BuiltIn(),
+		vsphere.BuiltIn(),
 		x509pop.BuiltIn(),
 	}
 }
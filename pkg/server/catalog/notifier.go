@@ -3,8 +3,10 @@ package catalog
 import (
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/server/plugin/notifier"
+	"github.com/spiffe/spire/pkg/server/plugin/notifier/awsbundle"
 	"github.com/spiffe/spire/pkg/server/plugin/notifier/gcsbundle"
 	"github.com/spiffe/spire/pkg/server/plugin/notifier/k8sbundle"
+	"github.com/spiffe/spire/pkg/server/plugin/notifier/webhook"
 )
 
 type notifierRepository struct {
@@ -27,8 +29,10 @@ func (repo *notifierRepository) Versions() []catalog.Version {
 
 func (repo *notifierRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
+		awsbundle.BuiltIn(),
 		gcsbundle.BuiltIn(),
 		k8sbundle.BuiltIn(),
+		webhook.BuiltIn(),
 	}
 }
 
@@ -6,9 +6,13 @@ import (
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/awspca"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/awssecret"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/certmanager"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/cfssl"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/digicert"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/disk"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/gcpcas"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/k8scsr"
 	spireplugin "github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/spire"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/stepca"
 	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/vault"
 )
 
@@ -39,6 +43,10 @@ func (repo *upstreamAuthorityRepository) BuiltIns() []catalog.BuiltIn {
 		spireplugin.BuiltIn(),
 		disk.BuiltIn(),
 		certmanager.BuiltIn(),
+		k8scsr.BuiltIn(),
+		stepca.BuiltIn(),
+		digicert.BuiltIn(),
+		cfssl.BuiltIn(),
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/x509/pkix"
 	"net"
+	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,8 +12,16 @@ import (
 	common "github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
+	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
 	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
@@ -22,20 +31,50 @@ type Config struct {
 	// Configurations for server plugins
 	PluginConfigs common.HCLPluginConfigMap
 
+	// If true, external plugins without a configured checksum fail to load
+	RequirePluginChecksums bool
+
 	Log logrus.FieldLogger
 
 	// LogReopener facilitates handling a signal to rotate log file.
 	LogReopener func(context.Context) error
 
+	// ReloadConfig, if set, is used to re-read the configuration when the
+	// server is signaled to reload it.
+	ReloadConfig func() (*ReloadableConfig, error)
+
 	// If true enables audit logs
 	AuditLogEnabled bool
 
+	// AuditLogSinks configures the sinks the audit records are written to
+	// regardless of the log level. They are opened when the server runs.
+	AuditLogSinks audit.SinkConfig
+
 	// Address of SPIRE server
 	BindAddress *net.TCPAddr
 
+	// BindAddressTLSPolicy restricts the TLS versions and cipher suites
+	// accepted on BindAddress
+	BindAddressTLSPolicy tlspolicy.Policy
+
+	// AdditionalListeners are additional TCP listeners for the SPIRE server,
+	// each with its own TLS settings
+	AdditionalListeners []endpoints.TCPListener
+
+	// GRPC holds options to tune the gRPC servers
+	GRPC endpoints.GRPCConfig
+
 	// Address of SPIRE Server to be reached locally
 	BindLocalAddress net.Addr
 
+	// BindLocalAddressMode is the file mode applied to the local socket. If
+	// zero, the socket is only accessible by the server user and group.
+	BindLocalAddressMode os.FileMode
+
+	// BindLocalAddressGroup, if set, is the group (name or ID) that owns the
+	// local socket.
+	BindLocalAddressGroup string
+
 	// Directory to store runtime data
 	DataDir string
 
@@ -59,13 +98,45 @@ type Config struct {
 	// AgentTTL is time-to-live for agent SVIDs
 	AgentTTL time.Duration
 
+	// AgentMaxRenewalAge, if positive, is how long after their last node
+	// attestation agents that can re-attest keep renewing their SVID before
+	// being asked to re-attest.
+	AgentMaxRenewalAge time.Duration
+
+	// ShutdownDrainTimeout is how long the server waits for in-flight RPCs
+	// to finish when shutting down before they are cancelled. If zero,
+	// in-flight RPCs are cancelled immediately.
+	ShutdownDrainTimeout time.Duration
+
 	// SVIDTTL is default time-to-live for SVIDs
 	SVIDTTL time.Duration
 
+	// MaxSVIDTTL, if positive, caps the time-to-live of X509-SVIDs and
+	// JWT-SVIDs, regardless of the TTL of the entry or the request.
+	MaxSVIDTTL time.Duration
+
+	// BundleRefreshHint, if positive, is the refresh hint of the trust
+	// bundle, advertised wherever the bundle is served. If zero, consumers
+	// calculate it from the bundle contents.
+	BundleRefreshHint time.Duration
+
 	// CATTL is the time-to-live for the server CA. This only applies to
 	// self-signed CA certificates, otherwise it is up to the upstream CA.
 	CATTL time.Duration
 
+	// CAUpstreamFallback, if true, self-signs the next X509 CA when the
+	// UpstreamAuthority fails to sign it halfway between the preparation and
+	// activation of the next X509 CA.
+	CAUpstreamFallback bool
+
+	// CAPreparationLeadTime, if positive, is how long before the current X509
+	// CA or JWT key expires that the next one is prepared.
+	CAPreparationLeadTime time.Duration
+
+	// CAActivationOverlap, if positive, is how long before the current X509
+	// CA or JWT key expires that the next one is activated.
+	CAActivationOverlap time.Duration
+
 	// JWTIssuer is used as the issuer claim in JWT-SVIDs minted by the server.
 	// If unset, the JWT-SVID will not have an issuer claim.
 	JWTIssuer string
@@ -73,6 +144,14 @@ type Config struct {
 	// CASubject is the subject used in the CA certificate
 	CASubject pkix.Name
 
+	// CAPathLen, if set, limits the number of downstream CA levels allowed
+	// below the server CA
+	CAPathLen *int
+
+	// DownstreamCAPathLen, if set, is the pathLenConstraint of the CA SVIDs
+	// signed for downstream servers
+	DownstreamCAPathLen *int
+
 	// Telemetry provides the configuration for metrics exporting
 	Telemetry telemetry.FileConfig
 
@@ -95,12 +174,58 @@ type Config struct {
 	// CacheReloadInterval controls how often the in-memory entry cache reloads
 	CacheReloadInterval time.Duration
 
+	// BundleCacheExpiry controls how long bundles and node selectors are
+	// cached in memory
+	BundleCacheExpiry time.Duration
+
+	// DataStoreSlowOperationThreshold, if positive, is how long a datastore
+	// operation can take before it is logged as slow.
+	DataStoreSlowOperationThreshold time.Duration
+
+	// AdminReadAfterWrite makes admin API reads bypass the in-memory caches
+	AdminReadAfterWrite bool
+
+	// LeaderElection elects, through a lease in the datastore, a single
+	// server to prune the bundle and registration entries of the trust domain
+	LeaderElection bool
+
 	// AuthPolicyEngineConfig determines the config for authz policy
 	AuthOpaPolicyEngineConfig *authpolicy.OpaEngineConfig
 
 	// AdminIDs are a list of fixed IDs that when presented by a caller in an
 	// X509-SVID, are granted admin rights.
 	AdminIDs []spiffeid.ID
+
+	// EntryNamespaces scope the registration entries that admin callers can
+	// access through the entry API.
+	EntryNamespaces []entryv1.Namespace
+
+	// SVIDDenylist holds glob patterns of SPIFFE IDs for which SVIDs are not
+	// issued, even if a registration entry exists.
+	SVIDDenylist []string
+
+	// IDPathPolicy, if set, restricts the SPIFFE ID paths that can be
+	// registered and signed in the trust domain.
+	IDPathPolicy *api.IDPathPolicy
+
+	// CSRExtensionAllowlist lists the CSR extensions copied into workload
+	// X509-SVIDs.
+	CSRExtensionAllowlist ca.CSRExtensionAllowlist
+
+	// IssuanceQuotas limit the rate at which SVIDs are signed per agent and
+	// per registration entry.
+	IssuanceQuotas svidv1.IssuanceQuotas
+
+	// JWTAudienceRestrictions limit the audiences that JWT-SVIDs can be
+	// signed for on behalf of registration entries.
+	JWTAudienceRestrictions []svidv1.AudienceRestriction
+
+	// AttestationWebhooks receive the result of every node attestation.
+	AttestationWebhooks []attestationwebhook.Config
+
+	// DownstreamAuthorizationWebhook, if set, must authorize every downstream
+	// X509 CA signing request.
+	DownstreamAuthorizationWebhook *downstreamwebhook.Config
 }
 
 type ExperimentalConfig struct {
@@ -116,6 +241,7 @@ type FederationConfig struct {
 
 func New(config Config) *Server {
 	return &Server{
-		config: config,
+		config:   config,
+		denylist: new(ca.Denylist),
 	}
 }
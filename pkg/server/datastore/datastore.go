@@ -24,7 +24,7 @@ type DataStore interface {
 	UpdateBundle(context.Context, *common.Bundle, *common.BundleMask) (*common.Bundle, error)
 
 	// Entries
-	CountRegistrationEntries(context.Context) (int32, error)
+	CountRegistrationEntries(context.Context, *CountRegistrationEntriesRequest) (int32, error)
 	CreateRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, error)
 	CreateOrReturnRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, bool, error)
 	DeleteRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
@@ -33,6 +33,15 @@ type DataStore interface {
 	PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) error
 	UpdateRegistrationEntry(context.Context, *common.RegistrationEntry, *common.RegistrationEntryMask) (*common.RegistrationEntry, error)
 
+	// Deleted entries
+	ListDeletedRegistrationEntries(context.Context) ([]*DeletedRegistrationEntry, error)
+	RestoreRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
+
+	// Entry templates
+	CreateEntryTemplate(context.Context, *EntryTemplate) (*EntryTemplate, error)
+	DeleteEntryTemplate(ctx context.Context, templateID string) (*EntryTemplate, error)
+	ListEntryTemplates(context.Context) ([]*EntryTemplate, error)
+
 	// Nodes
 	CountAttestedNodes(context.Context) (int32, error)
 	CreateAttestedNode(context.Context, *common.AttestedNode) (*common.AttestedNode, error)
@@ -47,9 +56,11 @@ type DataStore interface {
 	SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) error
 
 	// Tokens
+	ConsumeJoinToken(ctx context.Context, token string, now time.Time) (*JoinToken, error)
 	CreateJoinToken(context.Context, *JoinToken) error
 	DeleteJoinToken(ctx context.Context, token string) error
 	FetchJoinToken(ctx context.Context, token string) (*JoinToken, error)
+	ListJoinTokens(context.Context) ([]*JoinToken, error)
 	PruneJoinTokens(context.Context, time.Time) error
 
 	// Federation Relationships
@@ -58,6 +69,17 @@ type DataStore interface {
 	ListFederationRelationships(context.Context, *ListFederationRelationshipsRequest) (*ListFederationRelationshipsResponse, error)
 	DeleteFederationRelationship(context.Context, spiffeid.TrustDomain) error
 	UpdateFederationRelationship(context.Context, *FederationRelationship, *types.FederationRelationshipMask) (*FederationRelationship, error)
+
+	// Leases
+	AcquireLease(ctx context.Context, lease *Lease, now time.Time) (*Lease, error)
+
+	// Agent renewals
+	FetchAgentRenewal(ctx context.Context, spiffeID string) (*AgentRenewal, error)
+	SetAgentRenewal(context.Context, *AgentRenewal) error
+
+	// Entry DNS name patterns
+	FetchEntryDNSNamePatterns(ctx context.Context, entryID string) ([]string, error)
+	SetEntryDNSNamePatterns(ctx context.Context, entryID string, patterns []string) error
 }
 
 // DataConsistency indicates the required data consistency for a read operation.
@@ -123,6 +145,44 @@ type JoinToken struct {
 	Expiry time.Time
 }
 
+// Lease is a time-limited claim of a named duty by one of the servers that
+// share the datastore.
+type Lease struct {
+	Name      string
+	HolderID  string
+	ExpiresAt time.Time
+}
+
+// AgentRenewal is a request for an agent to renew the agent SVID with the
+// given serial number.
+type AgentRenewal struct {
+	SpiffeID     string
+	SerialNumber string
+}
+
+// DeletedRegistrationEntry is a registration entry that was deleted and can
+// be restored until it expires.
+type DeletedRegistrationEntry struct {
+	Entry     *common.RegistrationEntry
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
+
+// EntryTemplate is expanded to a registration entry for each agent that has
+// all of the node selectors of the template when it attests.
+type EntryTemplate struct {
+	ID string
+
+	// NodeSelectors are the selectors an agent must have for the template
+	// to be expanded
+	NodeSelectors []*common.Selector
+
+	// Entry is the entry created for each matching agent. The SPIFFE ID and
+	// the selector values can be templates rendered from the agent ID and
+	// selectors. The parent ID is set to the agent ID.
+	Entry *common.RegistrationEntry
+}
+
 type Pagination struct {
 	Token    string
 	PageSize int32
@@ -161,13 +221,18 @@ type ListNodeSelectorsResponse struct {
 	Selectors map[string][]*common.Selector
 }
 
+type CountRegistrationEntriesRequest struct {
+	BySpiffeIDPrefix string
+}
+
 type ListRegistrationEntriesRequest struct {
-	DataConsistency DataConsistency
-	ByParentID      string
-	BySelectors     *BySelectors
-	BySpiffeID      string
-	Pagination      *Pagination
-	ByFederatesWith *ByFederatesWith
+	DataConsistency  DataConsistency
+	ByParentID       string
+	BySelectors      *BySelectors
+	BySpiffeID       string
+	BySpiffeIDPrefix string
+	Pagination       *Pagination
+	ByFederatesWith  *ByFederatesWith
 }
 
 type ListRegistrationEntriesResponse struct {
@@ -0,0 +1,195 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+const (
+	// awsIAMTokenRefresh is how long an RDS IAM authentication token is used
+	// to open new connections. Tokens are valid for 15 minutes, so they are
+	// refreshed well before they expire.
+	awsIAMTokenRefresh = 10 * time.Minute
+)
+
+// awsIAMAuthConfig configures AWS RDS IAM database authentication. When set,
+// the password in the connection string is replaced by an authentication
+// token generated for each new connection.
+type awsIAMAuthConfig struct {
+	Region          string `hcl:"region" json:"region"`
+	AccessKeyID     string `hcl:"access_key_id" json:"access_key_id"`
+	SecretAccessKey string `hcl:"secret_access_key" json:"secret_access_key"`
+}
+
+func (c *awsIAMAuthConfig) credentials() (*credentials.Credentials, error) {
+	awsConfig := &aws.Config{
+		Region: aws.String(c.Region),
+	}
+	if c.AccessKeyID != "" && c.SecretAccessKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, "")
+	}
+
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, sqlError.New("failed to create AWS session: %v", err)
+	}
+	return awsSession.Config.Credentials, nil
+}
+
+func validateAWSIAMAuthConfig(cfg *configuration) error {
+	if cfg.AWSIAMAuth == nil {
+		return nil
+	}
+	switch {
+	case cfg.DatabaseType != MySQL && cfg.DatabaseType != PostgreSQL:
+		return sqlError.New("aws_iam_auth is only supported by mysql and postgres")
+	case cfg.AWSIAMAuth.Region == "":
+		return sqlError.New("aws_iam_auth region must be set")
+	case (cfg.AWSIAMAuth.AccessKeyID == "") != (cfg.AWSIAMAuth.SecretAccessKey == ""):
+		return sqlError.New("aws_iam_auth access_key_id and secret_access_key must be set together")
+	}
+	return nil
+}
+
+// awsIAMTokenProvider generates RDS IAM authentication tokens for a database
+// user, caching them until they are due for refresh.
+type awsIAMTokenProvider struct {
+	endpoint string
+	region   string
+	user     string
+	creds    *credentials.Credentials
+
+	buildToken func(endpoint, region, user string, creds *credentials.Credentials) (string, error)
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+func newAWSIAMTokenProvider(config *awsIAMAuthConfig, endpoint, user string) (*awsIAMTokenProvider, error) {
+	creds, err := config.credentials()
+	if err != nil {
+		return nil, err
+	}
+	return &awsIAMTokenProvider{
+		endpoint:   endpoint,
+		region:     config.Region,
+		user:       user,
+		creds:      creds,
+		buildToken: rdsutils.BuildAuthToken,
+		now:        time.Now,
+	}, nil
+}
+
+func (p *awsIAMTokenProvider) getToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.token != "" && now.Before(p.refreshAt) {
+		return p.token, nil
+	}
+
+	token, err := p.buildToken(p.endpoint, p.region, p.user, p.creds)
+	if err != nil {
+		return "", sqlError.New("failed to build AWS IAM authentication token: %v", err)
+	}
+	p.token = token
+	p.refreshAt = now.Add(awsIAMTokenRefresh)
+	return token, nil
+}
+
+// awsIAMConnector is a driver.Connector that authenticates every new
+// connection with a current RDS IAM authentication token.
+type awsIAMConnector struct {
+	tokens       *awsIAMTokenProvider
+	driver       driver.Driver
+	newConnector func(token string) (driver.Connector, error)
+}
+
+func (c *awsIAMConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := c.tokens.getToken()
+	if err != nil {
+		return nil, err
+	}
+	connector, err := c.newConnector(token)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *awsIAMConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func newMySQLIAMConnector(config *awsIAMAuthConfig, connString string) (driver.Connector, error) {
+	opts, err := mysql.ParseDSN(connString)
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	if opts.Net != "tcp" {
+		return nil, sqlError.New("invalid mysql config: aws_iam_auth requires a tcp connection")
+	}
+
+	tokens, err := newAWSIAMTokenProvider(config, opts.Addr, opts.User)
+	if err != nil {
+		return nil, err
+	}
+	return &awsIAMConnector{
+		tokens: tokens,
+		driver: mysql.MySQLDriver{},
+		newConnector: func(token string) (driver.Connector, error) {
+			cfg := opts.Clone()
+			cfg.Passwd = token
+			// RDS IAM authentication sends the token using the cleartext
+			// authentication plugin, which must be explicitly allowed.
+			cfg.AllowCleartextPasswords = true
+			return mysql.NewConnector(cfg)
+		},
+	}, nil
+}
+
+func newPostgresIAMConnector(config *awsIAMAuthConfig, connString string) (driver.Connector, error) {
+	opts, err := parsePostgresConnString(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	host := opts["host"]
+	if host == "" {
+		host = "localhost"
+	}
+	if strings.HasPrefix(host, "/") {
+		return nil, sqlError.New("invalid postgres config: aws_iam_auth requires a tcp connection")
+	}
+	port := opts["port"]
+	if port == "" {
+		port = "5432"
+	}
+
+	tokens, err := newAWSIAMTokenProvider(config, host+":"+port, opts["user"])
+	if err != nil {
+		return nil, err
+	}
+	return &awsIAMConnector{
+		tokens: tokens,
+		driver: &pq.Driver{},
+		newConnector: func(token string) (driver.Connector, error) {
+			// Later values take precedence over earlier ones, so the token
+			// replaces any password in the connection string.
+			return pq.NewConnector(connString + " password=" + quotePostgresValue(token))
+		},
+	}, nil
+}
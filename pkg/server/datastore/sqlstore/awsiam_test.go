@@ -0,0 +1,213 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSIAMTokenProvider(t *testing.T) {
+	now := time.Now()
+	var built []string
+	provider := &awsIAMTokenProvider{
+		endpoint: "db.example.org:3306",
+		region:   "us-east-1",
+		user:     "spire",
+		buildToken: func(endpoint, region, user string, creds *credentials.Credentials) (string, error) {
+			require.Equal(t, "db.example.org:3306", endpoint)
+			require.Equal(t, "us-east-1", region)
+			require.Equal(t, "spire", user)
+			token := now.Format(time.RFC3339)
+			built = append(built, token)
+			return token, nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	// The first token is built and then cached
+	token, err := provider.getToken()
+	require.NoError(t, err)
+	require.Equal(t, now.Format(time.RFC3339), token)
+	now = now.Add(awsIAMTokenRefresh - time.Second)
+	token, err = provider.getToken()
+	require.NoError(t, err)
+	require.Len(t, built, 1)
+	require.Equal(t, built[0], token)
+
+	// A new token is built once the cached one is due for refresh
+	now = now.Add(time.Second)
+	token, err = provider.getToken()
+	require.NoError(t, err)
+	require.Len(t, built, 2)
+	require.Equal(t, now.Format(time.RFC3339), token)
+
+	// Failures are not cached
+	provider.refreshAt = now
+	provider.buildToken = func(string, string, string, *credentials.Credentials) (string, error) {
+		return "", errors.New("oh no")
+	}
+	_, err = provider.getToken()
+	require.EqualError(t, err, "datastore-sql: failed to build AWS IAM authentication token: oh no")
+}
+
+func TestAWSIAMConnector(t *testing.T) {
+	var tokens []string
+	connector := &awsIAMConnector{
+		tokens: &awsIAMTokenProvider{
+			buildToken: func(string, string, string, *credentials.Credentials) (string, error) {
+				return "token", nil
+			},
+			now: time.Now,
+		},
+		newConnector: func(token string) (driver.Connector, error) {
+			tokens = append(tokens, token)
+			return nil, errors.New("not connecting")
+		},
+	}
+
+	_, err := connector.Connect(context.Background())
+	require.EqualError(t, err, "not connecting")
+	require.Equal(t, []string{"token"}, tokens)
+}
+
+func TestNewIAMConnectorRequiresTCP(t *testing.T) {
+	config := &awsIAMAuthConfig{Region: "us-east-1"}
+
+	_, err := newMySQLIAMConnector(config, "spire@unix(/var/run/mysqld/mysqld.sock)/spire?parseTime=true")
+	require.EqualError(t, err, "datastore-sql: invalid mysql config: aws_iam_auth requires a tcp connection")
+
+	_, err = newPostgresIAMConnector(config, "dbname=spire user=spire host=/var/run/postgresql")
+	require.EqualError(t, err, "datastore-sql: invalid postgres config: aws_iam_auth requires a tcp connection")
+}
+
+func TestValidateAWSIAMAuthConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		cfg       *configuration
+		expectErr string
+	}{
+		{
+			name: "not configured",
+			cfg:  &configuration{DatabaseType: SQLite},
+		},
+		{
+			name: "mysql",
+			cfg:  &configuration{DatabaseType: MySQL, AWSIAMAuth: &awsIAMAuthConfig{Region: "us-east-1"}},
+		},
+		{
+			name: "postgres with static credentials",
+			cfg:  &configuration{DatabaseType: PostgreSQL, AWSIAMAuth: &awsIAMAuthConfig{Region: "us-east-1", AccessKeyID: "id", SecretAccessKey: "secret"}},
+		},
+		{
+			name:      "sqlite3",
+			cfg:       &configuration{DatabaseType: SQLite, AWSIAMAuth: &awsIAMAuthConfig{Region: "us-east-1"}},
+			expectErr: "datastore-sql: aws_iam_auth is only supported by mysql and postgres",
+		},
+		{
+			name:      "missing region",
+			cfg:       &configuration{DatabaseType: MySQL, AWSIAMAuth: &awsIAMAuthConfig{}},
+			expectErr: "datastore-sql: aws_iam_auth region must be set",
+		},
+		{
+			name:      "partial static credentials",
+			cfg:       &configuration{DatabaseType: MySQL, AWSIAMAuth: &awsIAMAuthConfig{Region: "us-east-1", AccessKeyID: "id"}},
+			expectErr: "datastore-sql: aws_iam_auth access_key_id and secret_access_key must be set together",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAWSIAMAuthConfig(tt.cfg)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigurePostgresConnection(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		cfg      *configuration
+		expected map[string]string
+	}{
+		{
+			name: "key/value without TLS",
+			cfg:  &configuration{ConnectionString: "dbname=spire user=spire host=db.example.org"},
+			expected: map[string]string{
+				"dbname": "spire",
+				"user":   "spire",
+				"host":   "db.example.org",
+			},
+		},
+		{
+			name: "key/value with TLS",
+			cfg: &configuration{
+				ConnectionString: "dbname=spire user=spire host=db.example.org",
+				RootCAPath:       "/path/to/root ca.pem",
+				ClientCertPath:   "/path/to/client.pem",
+				ClientKeyPath:    `/path/to/client's.key`,
+			},
+			expected: map[string]string{
+				"dbname":      "spire",
+				"user":        "spire",
+				"host":        "db.example.org",
+				"sslrootcert": "/path/to/root ca.pem",
+				"sslcert":     "/path/to/client.pem",
+				"sslkey":      `/path/to/client's.key`,
+			},
+		},
+		{
+			name: "URL with Root CA",
+			cfg: &configuration{
+				ConnectionString: "postgres://spire@db.example.org:5433/spire?sslmode=verify-full",
+				RootCAPath:       "/path/to/root.pem",
+			},
+			expected: map[string]string{
+				"dbname":      "spire",
+				"user":        "spire",
+				"host":        "db.example.org",
+				"port":        "5433",
+				"sslmode":     "verify-full",
+				"sslrootcert": "/path/to/root.pem",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			connString, err := configurePostgresConnection(tt.cfg, false)
+			require.NoError(t, err)
+			opts, err := parsePostgresConnString(connString)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, opts)
+		})
+	}
+}
+
+func TestParsePostgresConnString(t *testing.T) {
+	opts, err := parsePostgresConnString(`user=spire  password='p@ss \'word\'' host = db.example.org empty=`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"user":     "spire",
+		"password": "p@ss 'word'",
+		"host":     "db.example.org",
+		"empty":    "",
+	}, opts)
+
+	// Values quoted for the connection string round trip
+	opts, err = parsePostgresConnString("password=" + quotePostgresValue(`a\b'c d`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"password": `a\b'c d`}, opts)
+
+	_, err = parsePostgresConnString("user")
+	require.EqualError(t, err, `datastore-sql: invalid postgres config: missing "=" after "user" in connection string`)
+
+	_, err = parsePostgresConnString("password='unterminated")
+	require.EqualError(t, err, "datastore-sql: invalid postgres config: unterminated quoted string in connection string")
+}
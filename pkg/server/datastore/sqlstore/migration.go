@@ -130,11 +130,19 @@ import (
 // | v1.3.0  |        |                                                                           |
 // |---------|        |                                                                           |
 // | v1.3.1  |        |                                                                           |
+// |---------|--------|---------------------------------------------------------------------------|
+// | v1.3.2  | 19     | Added leases table                                                        |
+// |         | 20     | Added agent_renewals table                                                |
+// |         | 21     | Replaced selectors (type, value) index with a covering index              |
+// |         | 22     | Added deleted_registered_entries table                                    |
+// |         | 23     | Added entry_templates table                                               |
+// |         | 24     | Added attested_at column to attested nodes                                |
+// |         | 25     | Added entry_dns_name_patterns table                                       |
 // ================================================================================================
 
 const (
 	// the latest schema version of the database in the code
-	latestSchemaVersion = 18
+	latestSchemaVersion = 25
 
 	// lastMinorReleaseSchemaVersion is the schema version supported by the
 	// last minor release. When the migrations are opportunistically pruned
@@ -296,6 +304,11 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		&Migration{},
 		&DNSName{},
 		&FederatedTrustDomain{},
+		&Lease{},
+		&AgentRenewal{},
+		&DeletedRegisteredEntry{},
+		&EntryTemplate{},
+		&EntryDNSNamePatterns{},
 	}
 
 	if err := tableOptionsForDialect(tx, dbType).AutoMigrate(tables...).Error; err != nil {
@@ -315,6 +328,10 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		return err
 	}
 
+	if err := addSelectorsTypeValueEntryIndex(tx); err != nil {
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return sqlError.Wrap(err)
 	}
@@ -353,6 +370,20 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 	switch currVersion {
 	case 17:
 		err = migrateToV18(tx)
+	case 18:
+		err = migrateToV19(tx)
+	case 19:
+		err = migrateToV20(tx)
+	case 20:
+		err = migrateToV21(tx)
+	case 21:
+		err = migrateToV22(tx)
+	case 22:
+		err = migrateToV23(tx)
+	case 23:
+		err = migrateToV24(tx)
+	case 24:
+		err = migrateToV25(tx)
 	default:
 		err = sqlError.New("no migration support for unknown schema version %d", currVersion)
 	}
@@ -373,6 +404,55 @@ func migrateToV18(tx *gorm.DB) error {
 	return nil
 }
 
+func migrateToV19(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Lease{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV20(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AgentRenewal{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV21(tx *gorm.DB) error {
+	if err := tx.Table("selectors").RemoveIndex("idx_selectors_type_value").Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return addSelectorsTypeValueEntryIndex(tx)
+}
+
+func migrateToV22(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&DeletedRegisteredEntry{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV23(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&EntryTemplate{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV24(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AttestedNode{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func migrateToV25(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&EntryDNSNamePatterns{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
 func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	// GORM creates the federated_registration_entries implicitly with a primary
 	// key tuple (bundle_id, registered_entry_id). Unfortunately, MySQL5 does
@@ -385,3 +465,16 @@ func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func addSelectorsTypeValueEntryIndex(tx *gorm.DB) error {
+	// Registration entries are listed by selector by looking up the IDs of
+	// the entries that have each selector. Including the registered_entry_id
+	// column in the (type, value) index lets the databases answer those
+	// lookups from the index alone, instead of reading a row for every entry
+	// that has a common selector. GORM orders index columns by struct field,
+	// so the index has to be created manually.
+	if err := tx.Table("selectors").AddIndex("idx_selectors_type_value_entry", "type", "value", "registered_entry_id").Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
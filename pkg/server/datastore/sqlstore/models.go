@@ -33,6 +33,7 @@ type AttestedNode struct {
 	NewSerialNumber string
 	NewExpiresAt    *time.Time
 	CanReattest     bool
+	AttestedAt      *time.Time
 
 	Selectors []*NodeSelector
 }
@@ -110,9 +111,12 @@ type JoinToken struct {
 type Selector struct {
 	Model
 
+	// The (type, value, registered_entry_id) index used to look up entries
+	// by selector is created by addSelectorsTypeValueEntryIndex, since GORM
+	// orders index columns by field.
 	RegisteredEntryID uint   `gorm:"unique_index:idx_selector_entry"`
-	Type              string `gorm:"unique_index:idx_selector_entry;index:idx_selectors_type_value"`
-	Value             string `gorm:"unique_index:idx_selector_entry;index:idx_selectors_type_value"`
+	Type              string `gorm:"unique_index:idx_selector_entry"`
+	Value             string `gorm:"unique_index:idx_selector_entry"`
 }
 
 // DNSName holds a DNS for a registration entry
@@ -159,6 +163,57 @@ func (FederatedTrustDomain) TableName() string {
 	return "federated_trust_domains"
 }
 
+// Lease holds a time-limited claim of a named duty by a server
+type Lease struct {
+	Model
+
+	Name      string `gorm:"unique_index"`
+	HolderID  string
+	ExpiresAt int64
+}
+
+// AgentRenewal holds a request for an agent to renew its agent SVID
+type AgentRenewal struct {
+	Model
+
+	SpiffeID     string `gorm:"unique_index"`
+	SerialNumber string
+}
+
+// EntryDNSNamePatterns holds the patterns of the DNS names agents can request
+// for the X509-SVIDs of a registration entry
+type EntryDNSNamePatterns struct {
+	Model
+
+	EntryID  string `gorm:"unique_index"`
+	Patterns []byte `gorm:"size:16777215"` // JSON encoded list of patterns
+}
+
+// TableName gets table name of EntryDNSNamePatterns
+func (EntryDNSNamePatterns) TableName() string {
+	return "entry_dns_name_patterns"
+}
+
+// DeletedRegisteredEntry holds a deleted registration entry until the
+// deleted entry retention elapses, so it can be restored
+type DeletedRegisteredEntry struct {
+	Model
+
+	EntryID   string `gorm:"unique_index"`
+	Data      []byte `gorm:"size:16777215"` // marshaled common.RegistrationEntry
+	ExpiresAt int64  `gorm:"index"`
+}
+
+// EntryTemplate holds an entry template, which is expanded to a registration
+// entry for each agent with the node selectors of the template
+type EntryTemplate struct {
+	Model
+
+	TemplateID    string `gorm:"unique_index"`
+	NodeSelectors []byte `gorm:"size:16777215"` // marshaled common.Selectors
+	Data          []byte `gorm:"size:16777215"` // marshaled common.RegistrationEntry
+}
+
 // Migration holds database schema version number, and
 // the SPIRE Code version number
 type Migration struct {
@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"database/sql"
 	"errors"
 	"os"
 
@@ -29,9 +30,22 @@ func (my mysqlDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, ver
 		return nil, "", false, err
 	}
 
-	db, err = gorm.Open("mysql", connString)
-	if err != nil {
-		return nil, "", false, err
+	if cfg.AWSIAMAuth != nil {
+		connector, err := newMySQLIAMConnector(cfg.AWSIAMAuth, connString)
+		if err != nil {
+			return nil, "", false, err
+		}
+		sqlDB := sql.OpenDB(connector)
+		db, err = gorm.Open("mysql", sqlDB)
+		if err != nil {
+			sqlDB.Close()
+			return nil, "", false, err
+		}
+	} else {
+		db, err = gorm.Open("mysql", connString)
+		if err != nil {
+			return nil, "", false, err
+		}
 	}
 
 	version, err = queryVersion(db, "SELECT VERSION()")
@@ -0,0 +1,45 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_datastore "github.com/spiffe/spire/pkg/common/telemetry/server/datastore"
+)
+
+// poolMetricsInterval is how often connection pool metrics are reported.
+const poolMetricsInterval = 10 * time.Second
+
+// ReportPoolMetrics starts a goroutine that periodically emits gauges
+// describing the utilization of the database connection pools until the
+// context is done.
+func (ds *Plugin) ReportPoolMetrics(ctx context.Context, metrics telemetry.Metrics) {
+	go ds.reportPoolMetrics(ctx, poolMetricsInterval, metrics)
+}
+
+func (ds *Plugin) reportPoolMetrics(ctx context.Context, interval time.Duration, metrics telemetry.Metrics) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		ds.emitPoolMetrics(metrics)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ds *Plugin) emitPoolMetrics(metrics telemetry.Metrics) {
+	ds.mu.Lock()
+	db, roDb := ds.db, ds.roDb
+	ds.mu.Unlock()
+
+	if db != nil {
+		telemetry_datastore.SetPoolGauges(metrics, false, db.raw.Stats())
+	}
+	if roDb != nil {
+		telemetry_datastore.SetPoolGauges(metrics, true, roDb.raw.Stats())
+	}
+}
@@ -1,7 +1,10 @@
 package sqlstore
 
 import (
+	"database/sql"
 	"errors"
+	"strings"
+	"unicode"
 
 	"github.com/jinzhu/gorm"
 	"github.com/lib/pq"
@@ -13,9 +16,27 @@ import (
 type postgresDB struct{}
 
 func (p postgresDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, version string, supportsCTE bool, err error) {
-	db, err = gorm.Open("postgres", getConnectionString(cfg, isReadOnly))
+	connString, err := configurePostgresConnection(cfg, isReadOnly)
 	if err != nil {
-		return nil, "", false, sqlError.Wrap(err)
+		return nil, "", false, err
+	}
+
+	if cfg.AWSIAMAuth != nil {
+		connector, err := newPostgresIAMConnector(cfg.AWSIAMAuth, connString)
+		if err != nil {
+			return nil, "", false, err
+		}
+		sqlDB := sql.OpenDB(connector)
+		db, err = gorm.Open("postgres", sqlDB)
+		if err != nil {
+			sqlDB.Close()
+			return nil, "", false, sqlError.Wrap(err)
+		}
+	} else {
+		db, err = gorm.Open("postgres", connString)
+		if err != nil {
+			return nil, "", false, sqlError.Wrap(err)
+		}
 	}
 
 	version, err = queryVersion(db, "SHOW server_version")
@@ -34,3 +55,94 @@ func (p postgresDB) isConstraintViolation(err error) bool {
 	// "23xxx" is the constraint violation class for PostgreSQL
 	return ok && e.Code.Class() == "23"
 }
+
+// configurePostgresConnection returns the connection string in the key/value
+// form, adding the TLS parameters for the Root CA and client certificate
+// configured in the plugin, if any.
+func configurePostgresConnection(cfg *configuration, isReadOnly bool) (string, error) {
+	connString := getConnectionString(cfg, isReadOnly)
+	if strings.HasPrefix(connString, "postgres://") || strings.HasPrefix(connString, "postgresql://") {
+		var err error
+		connString, err = pq.ParseURL(connString)
+		if err != nil {
+			return "", sqlError.Wrap(err)
+		}
+	}
+
+	if len(cfg.RootCAPath) > 0 {
+		connString += " sslrootcert=" + quotePostgresValue(cfg.RootCAPath)
+	}
+	if len(cfg.ClientCertPath) > 0 && len(cfg.ClientKeyPath) > 0 {
+		connString += " sslcert=" + quotePostgresValue(cfg.ClientCertPath)
+		connString += " sslkey=" + quotePostgresValue(cfg.ClientKeyPath)
+	}
+	return connString, nil
+}
+
+// parsePostgresConnString parses a connection string in the key/value form
+// with the same rules used by the lib/pq driver.
+func parsePostgresConnString(connString string) (map[string]string, error) {
+	opts := make(map[string]string)
+	s := []rune(connString)
+	i := 0
+	skipSpaces := func() {
+		for i < len(s) && unicode.IsSpace(s[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpaces()
+		if i >= len(s) {
+			return opts, nil
+		}
+
+		start := i
+		for i < len(s) && !unicode.IsSpace(s[i]) && s[i] != '=' {
+			i++
+		}
+		key := string(s[start:i])
+		skipSpaces()
+		if i >= len(s) || s[i] != '=' {
+			return nil, sqlError.New("invalid postgres config: missing \"=\" after %q in connection string", key)
+		}
+		i++
+		skipSpaces()
+
+		var value []rune
+		if i < len(s) && s[i] == '\'' {
+			i++
+			for {
+				if i >= len(s) {
+					return nil, sqlError.New("invalid postgres config: unterminated quoted string in connection string")
+				}
+				r := s[i]
+				i++
+				if r == '\'' {
+					break
+				}
+				if r == '\\' && i < len(s) {
+					r = s[i]
+					i++
+				}
+				value = append(value, r)
+			}
+		} else {
+			for i < len(s) && !unicode.IsSpace(s[i]) {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value = append(value, s[i])
+				i++
+			}
+		}
+		opts[key] = string(value)
+	}
+}
+
+// quotePostgresValue quotes a value for a key/value connection string.
+func quotePostgresValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
@@ -0,0 +1,111 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/require"
+)
+
+// The selector benchmarks list registration entries by selectors the way
+// workload attestation does, against databases where every entry shares some
+// selectors with the request. They run against SQLite by default. To run them
+// against a real MySQL or Postgres database, set the following flags in your
+// test run, substituting in the required connection string parameters for
+// each of the ldflags. The database must be empty.
+// -bench 'BenchmarkListRegistrationEntriesBySelectors' -ldflags "-X github.com/spiffe/spire/pkg/server/datastore/sqlstore.TestDialect=<mysql|postgres> -X github.com/spiffe/spire/pkg/server/datastore/sqlstore.TestConnString=<CONNECTION_STRING_HERE> -X github.com/spiffe/spire/pkg/server/datastore/sqlstore.TestROConnString=<CONNECTION_STRING_HERE>"
+func BenchmarkListRegistrationEntriesBySelectors(b *testing.B) {
+	ds := newBenchmarkPlugin(b)
+	defer ds.Close()
+
+	created := 0
+	for _, numEntries := range []int{1000, 10000} {
+		createBenchmarkEntries(b, ds, created, numEntries)
+		created = numEntries
+
+		for _, tt := range []struct {
+			name  string
+			match datastore.MatchBehavior
+		}{
+			{name: "subset", match: datastore.Subset},
+			{name: "exact", match: datastore.Exact},
+			{name: "superset", match: datastore.Superset},
+			{name: "match-any", match: datastore.MatchAny},
+		} {
+			match := tt.match
+			b.Run(fmt.Sprintf("%s/%d", tt.name, numEntries), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					entry := i % numEntries
+					selectors := benchmarkEntrySelectors(entry)
+					if match == datastore.Subset {
+						// Workloads usually have more selectors than their entries
+						selectors = append(selectors, &common.Selector{Type: "k8s", Value: "container-name:workload"})
+					}
+
+					resp, err := ds.ListRegistrationEntries(context.Background(), &datastore.ListRegistrationEntriesRequest{
+						BySelectors: &datastore.BySelectors{
+							Selectors: selectors,
+							Match:     match,
+						},
+					})
+					require.NoError(b, err)
+					if match != datastore.MatchAny {
+						require.Len(b, resp.Entries, 1)
+					}
+				}
+			})
+		}
+	}
+}
+
+func newBenchmarkPlugin(b *testing.B) *Plugin {
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+
+	var config string
+	switch TestDialect {
+	case "":
+		config = fmt.Sprintf(`
+			database_type = "sqlite3"
+			connection_string = %q
+		`, filepath.ToSlash(filepath.Join(b.TempDir(), "benchmark.sqlite3")))
+	case "mysql", "postgres":
+		require.NotEmpty(b, TestConnString, "connection string must be set")
+		config = fmt.Sprintf(`
+			database_type = %q
+			connection_string = %q
+			ro_connection_string = %q
+		`, TestDialect, TestConnString, TestROConnString)
+	default:
+		require.FailNowf(b, "Unsupported external test dialect", "%q", TestDialect)
+	}
+	require.NoError(b, ds.Configure(context.Background(), config))
+	return ds
+}
+
+// createBenchmarkEntries creates the entries in the [from, to) range. All the
+// entries share the namespace selector, groups of entries share the service
+// account selector, and each entry has a selector of its own.
+func createBenchmarkEntries(b *testing.B, ds *Plugin, from, to int) {
+	for i := from; i < to; i++ {
+		_, err := ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+			ParentId:  "spiffe://example.org/node",
+			SpiffeId:  fmt.Sprintf("spiffe://example.org/workload-%d", i),
+			Selectors: benchmarkEntrySelectors(i),
+		})
+		require.NoError(b, err)
+	}
+}
+
+func benchmarkEntrySelectors(i int) []*common.Selector {
+	return []*common.Selector{
+		{Type: "k8s", Value: "ns:default"},
+		{Type: "k8s", Value: fmt.Sprintf("sa:sa-%d", i%100)},
+		{Type: "k8s", Value: fmt.Sprintf("pod-label:app:app-%d", i)},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -48,16 +49,19 @@ const (
 // Configuration for the sql datastore implementation.
 // Pointer values are used to distinguish between "unset" and "zero" values.
 type configuration struct {
-	DatabaseType       string  `hcl:"database_type" json:"database_type"`
-	ConnectionString   string  `hcl:"connection_string" json:"connection_string"`
-	RoConnectionString string  `hcl:"ro_connection_string" json:"ro_connection_string"`
-	RootCAPath         string  `hcl:"root_ca_path" json:"root_ca_path"`
-	ClientCertPath     string  `hcl:"client_cert_path" json:"client_cert_path"`
-	ClientKeyPath      string  `hcl:"client_key_path" json:"client_key_path"`
-	ConnMaxLifetime    *string `hcl:"conn_max_lifetime" json:"conn_max_lifetime"`
-	MaxOpenConns       *int    `hcl:"max_open_conns" json:"max_open_conns"`
-	MaxIdleConns       *int    `hcl:"max_idle_conns" json:"max_idle_conns"`
-	DisableMigration   bool    `hcl:"disable_migration" json:"disable_migration"`
+	DatabaseType       string            `hcl:"database_type" json:"database_type"`
+	ConnectionString   string            `hcl:"connection_string" json:"connection_string"`
+	RoConnectionString string            `hcl:"ro_connection_string" json:"ro_connection_string"`
+	RootCAPath         string            `hcl:"root_ca_path" json:"root_ca_path"`
+	ClientCertPath     string            `hcl:"client_cert_path" json:"client_cert_path"`
+	ClientKeyPath      string            `hcl:"client_key_path" json:"client_key_path"`
+	AWSIAMAuth         *awsIAMAuthConfig `hcl:"aws_iam_auth" json:"aws_iam_auth"`
+	ConnMaxLifetime    *string           `hcl:"conn_max_lifetime" json:"conn_max_lifetime"`
+	MaxOpenConns       *int              `hcl:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns       *int              `hcl:"max_idle_conns" json:"max_idle_conns"`
+	DisableMigration   bool              `hcl:"disable_migration" json:"disable_migration"`
+
+	DeletedEntryRetention string `hcl:"deleted_entry_retention" json:"deleted_entry_retention"`
 
 	// Undocumented flags
 	LogSQL bool `hcl:"log_sql" json:"log_sql"`
@@ -92,6 +96,10 @@ type Plugin struct {
 	db   *sqlDB
 	roDb *sqlDB
 	log  logrus.FieldLogger
+
+	// deletedEntryRetention is how long deleted registration entries are
+	// kept so they can be restored. Deleted entries are not kept when zero.
+	deletedEntryRetention time.Duration
 }
 
 // New creates a new sql plugin struct. Configure must be called
@@ -342,9 +350,9 @@ func (ds *Plugin) FetchRegistrationEntry(ctx context.Context,
 }
 
 // CounCountRegistrationEntries counts all registrations (pagination available)
-func (ds *Plugin) CountRegistrationEntries(ctx context.Context) (count int32, err error) {
+func (ds *Plugin) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (count int32, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
-		count, err = countRegistrationEntries(tx)
+		count, err = countRegistrationEntries(tx, req)
 		return err
 	}); err != nil {
 		return 0, err
@@ -376,9 +384,16 @@ func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.Registr
 // DeleteRegistrationEntry deletes the given registration
 func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 	entryID string) (registrationEntry *common.RegistrationEntry, err error) {
+	ds.mu.Lock()
+	retention := ds.deletedEntryRetention
+	ds.mu.Unlock()
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		registrationEntry, err = deleteRegistrationEntry(tx, entryID)
-		return err
+		if err != nil || retention == 0 {
+			return err
+		}
+		return keepDeletedRegistrationEntry(tx, registrationEntry, time.Now(), retention)
 	}); err != nil {
 		return nil, err
 	}
@@ -394,6 +409,68 @@ func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore ti
 	})
 }
 
+// ListDeletedRegistrationEntries lists the deleted registration entries that
+// can still be restored
+func (ds *Plugin) ListDeletedRegistrationEntries(ctx context.Context) (entries []*datastore.DeletedRegistrationEntry, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entries, err = listDeletedRegistrationEntries(tx, time.Now())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RestoreRegistrationEntry restores a deleted registration entry, keeping
+// its entry ID
+func (ds *Plugin) RestoreRegistrationEntry(ctx context.Context, entryID string) (registrationEntry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		registrationEntry, err = restoreRegistrationEntry(ctx, ds.db, tx, entryID, time.Now())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return registrationEntry, nil
+}
+
+// CreateEntryTemplate stores a new entry template, assigning it an ID
+func (ds *Plugin) CreateEntryTemplate(ctx context.Context, template *datastore.EntryTemplate) (created *datastore.EntryTemplate, err error) {
+	if err := validateEntryTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		created, err = createEntryTemplate(tx, template)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteEntryTemplate deletes the entry template with the given ID. The
+// entries already expanded from the template are not deleted.
+func (ds *Plugin) DeleteEntryTemplate(ctx context.Context, templateID string) (deleted *datastore.EntryTemplate, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		deleted, err = deleteEntryTemplate(tx, templateID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListEntryTemplates lists all the entry templates
+func (ds *Plugin) ListEntryTemplates(ctx context.Context) (templates []*datastore.EntryTemplate, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		templates, err = listEntryTemplates(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
 // CreateJoinToken takes a Token message and stores it
 func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
 	if token == nil || token.Token == "" || token.Expiry.IsZero() {
@@ -419,6 +496,34 @@ func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datas
 	return resp, nil
 }
 
+// ListJoinTokens lists the join tokens that have not been consumed or
+// pruned, ordered by expiry
+func (ds *Plugin) ListJoinTokens(ctx context.Context) (resp []*datastore.JoinToken, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listJoinTokens(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ConsumeJoinToken deletes the given join token and returns it, unless it
+// has expired at the given time. The token is only returned to one caller,
+// even if multiple servers consume it concurrently. Nil is returned if the
+// token does not exist or has already been consumed.
+func (ds *Plugin) ConsumeJoinToken(ctx context.Context, token string, now time.Time) (resp *datastore.JoinToken, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = consumeJoinToken(tx, token, now)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // DeleteJoinToken deletes the given join token
 func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error) {
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -436,6 +541,81 @@ func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) (err er
 	})
 }
 
+// AcquireLease grants the lease to its holder until its expiration if the
+// lease is not held, has expired at the given time, or is already held by the
+// same holder. It returns the current lease, which has a different holder if
+// the lease could not be acquired.
+func (ds *Plugin) AcquireLease(ctx context.Context, lease *datastore.Lease, now time.Time) (current *datastore.Lease, err error) {
+	if lease == nil || lease.Name == "" || lease.HolderID == "" || lease.ExpiresAt.IsZero() {
+		return nil, errors.New("name, holder ID and expiry are required")
+	}
+
+	err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		current, err = acquireLease(tx, lease, now)
+		return err
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		// The lease was concurrently created by another holder
+		err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+			current, err = fetchLease(tx, lease.Name)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// FetchAgentRenewal fetches the renewal requested for the agent with the given
+// SPIFFE ID, or nil if no renewal was requested.
+func (ds *Plugin) FetchAgentRenewal(ctx context.Context, spiffeID string) (renewal *datastore.AgentRenewal, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		renewal, err = fetchAgentRenewal(tx, spiffeID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return renewal, nil
+}
+
+// SetAgentRenewal requests the agent to renew the agent SVID with the given
+// serial number, replacing any renewal previously requested for the agent.
+func (ds *Plugin) SetAgentRenewal(ctx context.Context, renewal *datastore.AgentRenewal) error {
+	if renewal == nil || renewal.SpiffeID == "" || renewal.SerialNumber == "" {
+		return errors.New("SPIFFE ID and serial number are required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return setAgentRenewal(tx, renewal)
+	})
+}
+
+// FetchEntryDNSNamePatterns fetches the patterns of the DNS names agents can
+// request for the X509-SVIDs of the registration entry with the given ID.
+func (ds *Plugin) FetchEntryDNSNamePatterns(ctx context.Context, entryID string) (patterns []string, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		patterns, err = fetchEntryDNSNamePatterns(tx, entryID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// SetEntryDNSNamePatterns sets the patterns of the DNS names agents can
+// request for the X509-SVIDs of the registration entry with the given ID,
+// replacing the previous ones. The patterns are removed when empty.
+func (ds *Plugin) SetEntryDNSNamePatterns(ctx context.Context, entryID string, patterns []string) error {
+	if entryID == "" {
+		return errors.New("entry ID is required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return setEntryDNSNamePatterns(tx, entryID, patterns)
+	})
+}
+
 // CreateFederationRelationship creates a new federation relationship. If the bundle endpoint
 // profile is 'https_spiffe' and the given federation relationship contains a bundle, the current
 // stored bundle is overridden.
@@ -522,11 +702,34 @@ func (ds *Plugin) Configure(ctx context.Context, hclConfiguration string) error
 		return err
 	}
 
+	if err := ds.setDeletedEntryRetention(config); err != nil {
+		return err
+	}
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return pruneOrphanedRecords(tx, ds.log)
 	})
 }
 
+func (ds *Plugin) setDeletedEntryRetention(config *configuration) error {
+	var retention time.Duration
+	if config.DeletedEntryRetention != "" {
+		var err error
+		retention, err = time.ParseDuration(config.DeletedEntryRetention)
+		if err != nil {
+			return sqlError.New("invalid deleted_entry_retention: %v", err)
+		}
+		if retention < 0 {
+			return sqlError.New("deleted_entry_retention must not be negative")
+		}
+	}
+
+	ds.mu.Lock()
+	ds.deletedEntryRetention = retention
+	ds.mu.Unlock()
+	return nil
+}
+
 func (ds *Plugin) openConnections(config *configuration) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
@@ -798,6 +1001,8 @@ func applyBundleMask(model *Bundle, newBundle *common.Bundle, inputMask *common.
 
 	if inputMask == nil {
 		inputMask = protoutil.AllTrueCommonBundleMask
+		// The sequence number is only replaced along with the whole bundle
+		bundle.SequenceNumber = newBundle.SequenceNumber
 	}
 
 	if inputMask.RefreshHint {
@@ -873,6 +1078,7 @@ func appendBundle(tx *gorm.DB, b *common.Bundle) (*common.Bundle, error) {
 
 	bundle, changed := bundleutil.MergeBundles(bundle, b)
 	if changed {
+		bundle.SequenceNumber++
 		newModel, err := bundleToModel(bundle)
 		if err != nil {
 			return nil, err
@@ -1024,6 +1230,7 @@ func pruneBundle(tx *gorm.DB, trustDomainID string, expiry time.Time, log logrus
 
 	// Update only if bundle was modified
 	if changed {
+		newBundle.SequenceNumber++
 		_, err := updateBundle(tx, newBundle, nil)
 		if err != nil {
 			return false, fmt.Errorf("unable to write new bundle: %w", err)
@@ -1042,6 +1249,7 @@ func createAttestedNode(tx *gorm.DB, node *common.AttestedNode) (*common.Atteste
 		NewSerialNumber: node.NewCertSerialNumber,
 		NewExpiresAt:    nullableUnixTimeToDBTime(node.NewCertNotAfter),
 		CanReattest:     node.CanReattest,
+		AttestedAt:      nullableUnixTimeToDBTime(node.AttestedAt),
 	}
 
 	if err := tx.Create(&model).Error; err != nil {
@@ -1320,7 +1528,8 @@ SELECT
 	expires_at,
 	new_serial_number,
 	new_expires_at,
-	can_reattest,`)
+	can_reattest,
+	attested_at,`)
 
 	// Add "optional" fields for selectors
 	if fetchSelectors {
@@ -1454,7 +1663,8 @@ SELECT
 	N.expires_at,
 	N.new_serial_number,
 	N.new_expires_at,
-	N.can_reattest,`)
+	N.can_reattest,
+	N.attested_at,`)
 	// Add "optional" fields for selectors
 	if fetchSelectors {
 		builder.WriteString(`
@@ -1623,6 +1833,9 @@ func updateAttestedNode(tx *gorm.DB, n *common.AttestedNode, mask *common.Attest
 	if mask.CanReattest {
 		updates["can_reattest"] = n.CanReattest
 	}
+	if mask.AttestedAt {
+		updates["attested_at"] = nullableUnixTimeToDBTime(n.AttestedAt)
+	}
 	if err := tx.Model(&model).Updates(updates).Error; err != nil {
 		return nil, sqlError.Wrap(err)
 	}
@@ -1640,6 +1853,11 @@ func deleteAttestedNode(tx *gorm.DB, spiffeID string) (*common.AttestedNode, err
 		return nil, sqlError.Wrap(err)
 	}
 
+	// Renewals requested for the agent no longer apply
+	if err := tx.Where("spiffe_id = ?", spiffeID).Delete(&AgentRenewal{}).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
 	return modelToAttestedNode(model), nil
 }
 
@@ -1791,6 +2009,12 @@ func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*com
 		StoreSvid:  entry.StoreSvid,
 	}
 
+	return insertRegistrationEntry(tx, newRegisteredEntry, entry)
+}
+
+// insertRegistrationEntry inserts the given entry model along with the
+// federated trust domains, selectors and DNS names of the entry.
+func insertRegistrationEntry(tx *gorm.DB, newRegisteredEntry RegisteredEntry, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	if err := tx.Create(&newRegisteredEntry).Error; err != nil {
 		return nil, sqlError.Wrap(err)
 	}
@@ -2103,9 +2327,14 @@ ORDER BY selector_id, dns_name_id
 	return query, []interface{}{entryID}, nil
 }
 
-func countRegistrationEntries(tx *gorm.DB) (int32, error) {
+func countRegistrationEntries(tx *gorm.DB, req *datastore.CountRegistrationEntriesRequest) (int32, error) {
+	query := tx.Model(&RegisteredEntry{})
+	if req.BySpiffeIDPrefix != "" {
+		query = query.Where("substr(spiffe_id, 1, ?) = ?", len(req.BySpiffeIDPrefix), req.BySpiffeIDPrefix)
+	}
+
 	var count int
-	if err := tx.Model(&RegisteredEntry{}).Count(&count).Error; err != nil {
+	if err := query.Count(&count).Error; err != nil {
 		return 0, sqlError.Wrap(err)
 	}
 
@@ -2706,20 +2935,25 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 
 	root := idFilterNode{idColumn: "id"}
 
-	if req.ByParentID != "" || req.BySpiffeID != "" {
-		subquery := new(strings.Builder)
-		subquery.WriteString("SELECT id AS e_id FROM registered_entries WHERE ")
+	if req.ByParentID != "" || req.BySpiffeID != "" || req.BySpiffeIDPrefix != "" {
+		var conditions []string
 		if req.ByParentID != "" {
-			subquery.WriteString("parent_id = ?")
+			conditions = append(conditions, "parent_id = ?")
 			args = append(args, req.ByParentID)
 		}
 		if req.BySpiffeID != "" {
-			if req.ByParentID != "" {
-				subquery.WriteString(" AND ")
-			}
-			subquery.WriteString("spiffe_id = ?")
+			conditions = append(conditions, "spiffe_id = ?")
 			args = append(args, req.BySpiffeID)
 		}
+		if req.BySpiffeIDPrefix != "" {
+			// substr is used rather than LIKE since LIKE is case-insensitive
+			// in SQLite and would need the prefix wildcards escaped.
+			conditions = append(conditions, "substr(spiffe_id, 1, ?) = ?")
+			args = append(args, len(req.BySpiffeIDPrefix), req.BySpiffeIDPrefix)
+		}
+		subquery := new(strings.Builder)
+		subquery.WriteString("SELECT id AS e_id FROM registered_entries WHERE ")
+		subquery.WriteString(strings.Join(conditions, " AND "))
 		root.children = append(root.children, idFilterNode{
 			idColumn: "id",
 			query:    []string{subquery.String()},
@@ -2727,9 +2961,37 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 	}
 
 	if req.BySelectors != nil && len(req.BySelectors.Selectors) > 0 {
+		var selectorArgs []interface{}
+		for _, selector := range req.BySelectors.Selectors {
+			selectorArgs = append(selectorArgs, selector.Type, selector.Value)
+		}
+
 		switch req.BySelectors.Match {
-		case datastore.Subset, datastore.MatchAny:
-			// subset needs a union, so we need to group them and add the group
+		case datastore.Subset:
+			// subset only matches entries whose selectors are all in the
+			// request. The candidates are looked up through the selectors
+			// index and the entries with any other selector are filtered out
+			// by the database, so entries sharing a common selector with the
+			// request are not fetched only to be discarded afterwards.
+			filterNode := idFilterNode{
+				idColumn: "registered_entry_id",
+			}
+			filterNode.query = append(filterNode.query, "SELECT registered_entry_id AS e_id FROM selectors WHERE registered_entry_id IN (")
+			matches := make([]string, 0, len(req.BySelectors.Selectors))
+			for i := range req.BySelectors.Selectors {
+				if i > 0 {
+					filterNode.query = append(filterNode.query, "\tUNION")
+				}
+				filterNode.query = append(filterNode.query, "\tSELECT registered_entry_id FROM selectors WHERE type = ? AND value = ?")
+				matches = append(matches, "(type = ? AND value = ?)")
+			}
+			filterNode.query = append(filterNode.query, ")")
+			filterNode.query = append(filterNode.query, "GROUP BY registered_entry_id")
+			filterNode.query = append(filterNode.query, "HAVING COUNT(CASE WHEN "+strings.Join(matches, " OR ")+" THEN NULL ELSE 1 END) = 0")
+			root.children = append(root.children, filterNode)
+			args = append(args, selectorArgs...)
+		case datastore.MatchAny:
+			// match any needs a union, so we need to group them and add the group
 			// as a child to the root.
 			if len(req.BySelectors.Selectors) < 2 {
 				root.children = append(root.children, idFilterNode{
@@ -2761,9 +3023,7 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 		default:
 			return false, nil, errs.New("unhandled selectors match behavior %q", req.BySelectors.Match)
 		}
-		for _, selector := range req.BySelectors.Selectors {
-			args = append(args, selector.Type, selector.Value)
-		}
+		args = append(args, selectorArgs...)
 	}
 
 	if req.ByFederatesWith != nil && len(req.ByFederatesWith.TrustDomains) > 0 {
@@ -2918,6 +3178,7 @@ type nodeRow struct {
 	NewSerialNumber sql.NullString
 	NewExpiresAt    sql.NullTime
 	CanReattest     sql.NullBool
+	AttestedAt      sql.NullTime
 	SelectorType    sql.NullString
 	SelectorValue   sql.NullString
 }
@@ -2932,6 +3193,7 @@ func scanNodeRow(rs *sql.Rows, r *nodeRow) error {
 		&r.NewSerialNumber,
 		&r.NewExpiresAt,
 		&r.CanReattest,
+		&r.AttestedAt,
 		&r.SelectorType,
 		&r.SelectorValue,
 	))
@@ -2976,6 +3238,10 @@ func fillNodeFromRow(node *common.AttestedNode, r *nodeRow) error {
 		node.CanReattest = r.CanReattest.Bool
 	}
 
+	if r.AttestedAt.Valid {
+		node.AttestedAt = r.AttestedAt.Time.Unix()
+	}
+
 	return nil
 }
 
@@ -3119,6 +3385,12 @@ func updateRegistrationEntry(tx *gorm.DB, e *common.RegistrationEntry, mask *com
 	if err := tx.Find(&entry, "entry_id = ?", e.EntryId).Error; err != nil {
 		return nil, sqlError.Wrap(err)
 	}
+
+	// When requested, reject the update if the entry has been modified since
+	// the caller last read it
+	if mask != nil && mask.RevisionNumber && e.RevisionNumber != entry.RevisionNumber {
+		return nil, status.Newf(codes.FailedPrecondition, "datastore-sql: entry revision number is %d, not %d", entry.RevisionNumber, e.RevisionNumber).Err()
+	}
 	if mask == nil || mask.StoreSvid {
 		entry.StoreSvid = e.StoreSvid
 	}
@@ -3227,6 +3499,193 @@ func deleteRegistrationEntry(tx *gorm.DB, entryID string) (*common.RegistrationE
 	return registrationEntry, nil
 }
 
+// keepDeletedRegistrationEntry keeps a deleted entry so it can be restored
+// until the retention elapses. Deleted entries that already expired are
+// pruned at the same time.
+func keepDeletedRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry, now time.Time, retention time.Duration) error {
+	if err := tx.Where("expires_at < ? OR entry_id = ?", now.Unix(), entry.EntryId).Delete(&DeletedRegisteredEntry{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return sqlError.Wrap(err)
+	}
+
+	model := DeletedRegisteredEntry{
+		EntryID:   entry.EntryId,
+		Data:      data,
+		ExpiresAt: now.Add(retention).Unix(),
+	}
+	if err := tx.Create(&model).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func listDeletedRegistrationEntries(tx *gorm.DB, now time.Time) ([]*datastore.DeletedRegistrationEntry, error) {
+	var models []DeletedRegisteredEntry
+	if err := tx.Where("expires_at >= ?", now.Unix()).Order("id").Find(&models).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	entries := make([]*datastore.DeletedRegistrationEntry, 0, len(models))
+	for _, model := range models {
+		entry, err := modelToDeletedEntry(model)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func restoreRegistrationEntry(ctx context.Context, db *sqlDB, tx *gorm.DB, entryID string, now time.Time) (*common.RegistrationEntry, error) {
+	var model DeletedRegisteredEntry
+	if err := tx.Find(&model, "entry_id = ? AND expires_at >= ?", entryID, now.Unix()).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	deleted, err := modelToDeletedEntry(model)
+	if err != nil {
+		return nil, err
+	}
+	entry := deleted.Entry
+
+	similarEntry, err := lookupSimilarEntry(ctx, db, tx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if similarEntry != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "datastore-sql: a similar registration entry already exists with entry ID %q", similarEntry.EntryId)
+	}
+
+	restored, err := insertRegistrationEntry(tx, RegisteredEntry{
+		EntryID:    entry.EntryId,
+		SpiffeID:   entry.SpiffeId,
+		ParentID:   entry.ParentId,
+		TTL:        entry.Ttl,
+		Admin:      entry.Admin,
+		Downstream: entry.Downstream,
+		Expiry:     entry.EntryExpiry,
+		StoreSvid:  entry.StoreSvid,
+		// The revision is bumped so the restored entry supersedes the
+		// deleted one wherever it is still cached
+		RevisionNumber: entry.RevisionNumber + 1,
+	}, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&model).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return restored, nil
+}
+
+func modelToDeletedEntry(model DeletedRegisteredEntry) (*datastore.DeletedRegistrationEntry, error) {
+	entry := new(common.RegistrationEntry)
+	if err := proto.Unmarshal(model.Data, entry); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return &datastore.DeletedRegistrationEntry{
+		Entry:     entry,
+		DeletedAt: model.CreatedAt,
+		ExpiresAt: time.Unix(model.ExpiresAt, 0),
+	}, nil
+}
+
+func validateEntryTemplate(template *datastore.EntryTemplate) error {
+	switch {
+	case template == nil:
+		return sqlError.New("invalid request: missing entry template")
+	case len(template.NodeSelectors) == 0:
+		return sqlError.New("invalid entry template: missing node selectors")
+	case template.Entry == nil:
+		return sqlError.New("invalid entry template: missing entry")
+	case template.Entry.SpiffeId == "":
+		return sqlError.New("invalid entry template: missing SPIFFE ID")
+	case len(template.Entry.Selectors) == 0:
+		return sqlError.New("invalid entry template: missing selector list")
+	}
+	return nil
+}
+
+func createEntryTemplate(tx *gorm.DB, template *datastore.EntryTemplate) (*datastore.EntryTemplate, error) {
+	templateID, err := newRegistrationEntryID()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeSelectors, err := proto.Marshal(&common.Selectors{Entries: template.NodeSelectors})
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	// The entry of the template never has an ID or a parent ID of its own
+	entry := proto.Clone(template.Entry).(*common.RegistrationEntry)
+	entry.EntryId = ""
+	entry.ParentId = ""
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	model := EntryTemplate{
+		TemplateID:    templateID,
+		NodeSelectors: nodeSelectors,
+		Data:          data,
+	}
+	if err := tx.Create(&model).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return modelToEntryTemplate(model)
+}
+
+func deleteEntryTemplate(tx *gorm.DB, templateID string) (*datastore.EntryTemplate, error) {
+	var model EntryTemplate
+	if err := tx.Find(&model, "template_id = ?", templateID).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	if err := tx.Delete(&model).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return modelToEntryTemplate(model)
+}
+
+func listEntryTemplates(tx *gorm.DB) ([]*datastore.EntryTemplate, error) {
+	var models []EntryTemplate
+	if err := tx.Order("id").Find(&models).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	templates := make([]*datastore.EntryTemplate, 0, len(models))
+	for _, model := range models {
+		template, err := modelToEntryTemplate(model)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func modelToEntryTemplate(model EntryTemplate) (*datastore.EntryTemplate, error) {
+	nodeSelectors := new(common.Selectors)
+	if err := proto.Unmarshal(model.NodeSelectors, nodeSelectors); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	entry := new(common.RegistrationEntry)
+	if err := proto.Unmarshal(model.Data, entry); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return &datastore.EntryTemplate{
+		ID:            model.TemplateID,
+		NodeSelectors: nodeSelectors.Entries,
+		Entry:         entry,
+	}, nil
+}
+
 func deleteRegistrationEntrySupport(tx *gorm.DB, entry RegisteredEntry) error {
 	if err := tx.Model(&entry).Association("FederatesWith").Clear().Error; err != nil {
 		return err
@@ -3246,6 +3705,11 @@ func deleteRegistrationEntrySupport(tx *gorm.DB, entry RegisteredEntry) error {
 		return sqlError.Wrap(err)
 	}
 
+	// Delete the patterns of the DNS names agents can request
+	if err := tx.Where("entry_id = ?", entry.EntryID).Delete(&EntryDNSNamePatterns{}).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+
 	return nil
 }
 
@@ -3294,6 +3758,19 @@ func fetchJoinToken(tx *gorm.DB, token string) (*datastore.JoinToken, error) {
 	return modelToJoinToken(model), nil
 }
 
+func listJoinTokens(tx *gorm.DB) ([]*datastore.JoinToken, error) {
+	var models []JoinToken
+	if err := tx.Order("expiry, token").Find(&models).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	tokens := make([]*datastore.JoinToken, 0, len(models))
+	for _, model := range models {
+		tokens = append(tokens, modelToJoinToken(model))
+	}
+	return tokens, nil
+}
+
 func deleteJoinToken(tx *gorm.DB, token string) error {
 	var model JoinToken
 	if err := tx.Find(&model, "token = ?", token).Error; err != nil {
@@ -3307,6 +3784,33 @@ func deleteJoinToken(tx *gorm.DB, token string) error {
 	return nil
 }
 
+func consumeJoinToken(tx *gorm.DB, token string, now time.Time) (*datastore.JoinToken, error) {
+	var model JoinToken
+	err := tx.Find(&model, "token = ?", token).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, sqlError.Wrap(err)
+	case model.Expiry < now.Unix():
+		// Expired tokens are left for pruning
+		return nil, status.Error(codes.FailedPrecondition, "datastore-sql: join token expired")
+	}
+
+	// Only the transaction whose delete affects the row consumes the token.
+	// Concurrent transactions that read the row before it was deleted
+	// affect no rows.
+	res := tx.Where("token = ?", token).Delete(&JoinToken{})
+	switch {
+	case res.Error != nil:
+		return nil, sqlError.Wrap(res.Error)
+	case res.RowsAffected == 0:
+		return nil, nil
+	}
+
+	return modelToJoinToken(model), nil
+}
+
 func pruneJoinTokens(tx *gorm.DB, expiresBefore time.Time) error {
 	if err := tx.Where("expiry < ?", expiresBefore.Unix()).Delete(&JoinToken{}).Error; err != nil {
 		return sqlError.Wrap(err)
@@ -3315,6 +3819,133 @@ func pruneJoinTokens(tx *gorm.DB, expiresBefore time.Time) error {
 	return nil
 }
 
+func acquireLease(tx *gorm.DB, lease *datastore.Lease, now time.Time) (*datastore.Lease, error) {
+	if err := tx.Model(&Lease{}).
+		Where("name = ? AND (holder_id = ? OR expires_at <= ?)", lease.Name, lease.HolderID, now.Unix()).
+		Updates(map[string]interface{}{
+			"holder_id":  lease.HolderID,
+			"expires_at": lease.ExpiresAt.Unix(),
+		}).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+
+	var model Lease
+	err := tx.Find(&model, "name = ?", lease.Name).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model = Lease{
+			Name:      lease.Name,
+			HolderID:  lease.HolderID,
+			ExpiresAt: lease.ExpiresAt.Unix(),
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return nil, sqlError.Wrap(err)
+		}
+	case err != nil:
+		return nil, sqlError.Wrap(err)
+	}
+
+	return modelToLease(model), nil
+}
+
+func fetchLease(tx *gorm.DB, name string) (*datastore.Lease, error) {
+	var model Lease
+	if err := tx.Find(&model, "name = ?", name).Error; err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return modelToLease(model), nil
+}
+
+func fetchAgentRenewal(tx *gorm.DB, spiffeID string) (*datastore.AgentRenewal, error) {
+	var model AgentRenewal
+	err := tx.Find(&model, "spiffe_id = ?", spiffeID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, sqlError.Wrap(err)
+	}
+	return &datastore.AgentRenewal{
+		SpiffeID:     model.SpiffeID,
+		SerialNumber: model.SerialNumber,
+	}, nil
+}
+
+func setAgentRenewal(tx *gorm.DB, renewal *datastore.AgentRenewal) error {
+	var model AgentRenewal
+	err := tx.Find(&model, "spiffe_id = ?", renewal.SpiffeID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model = AgentRenewal{
+			SpiffeID:     renewal.SpiffeID,
+			SerialNumber: renewal.SerialNumber,
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return sqlError.Wrap(err)
+		}
+		return nil
+	case err != nil:
+		return sqlError.Wrap(err)
+	}
+
+	if err := tx.Model(&model).Update("serial_number", renewal.SerialNumber).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
+func fetchEntryDNSNamePatterns(tx *gorm.DB, entryID string) ([]string, error) {
+	var model EntryDNSNamePatterns
+	err := tx.Find(&model, "entry_id = ?", entryID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, sqlError.Wrap(err)
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(model.Patterns, &patterns); err != nil {
+		return nil, sqlError.Wrap(err)
+	}
+	return patterns, nil
+}
+
+func setEntryDNSNamePatterns(tx *gorm.DB, entryID string, patterns []string) error {
+	if len(patterns) == 0 {
+		if err := tx.Where("entry_id = ?", entryID).Delete(&EntryDNSNamePatterns{}).Error; err != nil {
+			return sqlError.Wrap(err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(patterns)
+	if err != nil {
+		return sqlError.Wrap(err)
+	}
+
+	var model EntryDNSNamePatterns
+	err = tx.Find(&model, "entry_id = ?", entryID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model = EntryDNSNamePatterns{
+			EntryID:  entryID,
+			Patterns: data,
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return sqlError.Wrap(err)
+		}
+		return nil
+	case err != nil:
+		return sqlError.Wrap(err)
+	}
+
+	if err := tx.Model(&model).Update("patterns", data).Error; err != nil {
+		return sqlError.Wrap(err)
+	}
+	return nil
+}
+
 func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
 	model := FederatedTrustDomain{
 		TrustDomain:           fr.TrustDomain.String(),
@@ -3679,6 +4310,7 @@ func modelToAttestedNode(model AttestedNode) *common.AttestedNode {
 		NewCertSerialNumber: model.NewSerialNumber,
 		NewCertNotAfter:     nullableDBTimeToUnixTime(model.NewExpiresAt),
 		CanReattest:         model.CanReattest,
+		AttestedAt:          nullableDBTimeToUnixTime(model.AttestedAt),
 	}
 }
 
@@ -3689,6 +4321,14 @@ func modelToJoinToken(model JoinToken) *datastore.JoinToken {
 	}
 }
 
+func modelToLease(model Lease) *datastore.Lease {
+	return &datastore.Lease{
+		Name:      model.Name,
+		HolderID:  model.HolderID,
+		ExpiresAt: time.Unix(model.ExpiresAt, 0),
+	}
+}
+
 func makeFederatesWith(tx *gorm.DB, ids []string) ([]*Bundle, error) {
 	var bundles []*Bundle
 	if err := tx.Where("trust_domain in (?)", ids).Find(&bundles).Error; err != nil {
@@ -3753,7 +4393,7 @@ func (cfg *configuration) Validate() error {
 		}
 	}
 
-	return nil
+	return validateAWSIAMAuthConfig(cfg)
 }
 
 // getConnectionString returns the connection string corresponding to the database connection.
@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/spiretest"
 	testutil "github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -192,6 +194,25 @@ func (s *PluginSuite) TestInvalidPluginConfiguration() {
 	s.RequireErrorContains(err, "datastore-sql: unsupported database_type: wrong")
 }
 
+func (s *PluginSuite) TestEmitPoolMetrics() {
+	metrics := fakemetrics.New()
+	s.ds.emitPoolMetrics(metrics)
+
+	var keys [][]string
+	for _, item := range metrics.AllMetrics() {
+		s.Require().Equal(fakemetrics.SetGaugeWithLabelsType, item.Type)
+		if item.Labels[0] == (telemetry.Label{Name: telemetry.ReadOnly, Value: "false"}) {
+			keys = append(keys, item.Key)
+		}
+	}
+	s.Require().Equal([][]string{
+		{telemetry.Datastore, telemetry.Pool, telemetry.OpenConnections},
+		{telemetry.Datastore, telemetry.Pool, telemetry.InUse},
+		{telemetry.Datastore, telemetry.Pool, telemetry.Idle},
+		{telemetry.Datastore, telemetry.Pool, telemetry.WaitCount},
+	}, keys)
+}
+
 func (s *PluginSuite) TestInvalidMySQLConfiguration() {
 	err := s.ds.Configure(ctx, `
 		database_type = "mysql"
@@ -211,6 +232,24 @@ func (s *PluginSuite) TestInvalidMySQLConfiguration() {
 	s.RequireErrorContains(err, "datastore-sql: connection_string must be set")
 }
 
+func (s *PluginSuite) TestInvalidAWSIAMAuthConfiguration() {
+	err := s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		aws_iam_auth {
+			region = "us-east-1"
+		}
+	`)
+	s.RequireErrorContains(err, "datastore-sql: aws_iam_auth is only supported by mysql and postgres")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "postgres"
+		connection_string = "dbname=spire user=spire host=db.example.org"
+		aws_iam_auth {}
+	`)
+	s.RequireErrorContains(err, "datastore-sql: aws_iam_auth region must be set")
+}
+
 func (s *PluginSuite) TestBundleCRUD() {
 	bundle := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert)
 
@@ -249,6 +288,8 @@ func (s *PluginSuite) TestBundleCRUD() {
 	bundle2 := bundleutil.BundleProtoFromRootCA(bundle.TrustDomainId, s.cacert)
 	appendedBundle := bundleutil.BundleProtoFromRootCAs(bundle.TrustDomainId,
 		[]*x509.Certificate{s.cert, s.cacert})
+	// appending to a bundle bumps its sequence number
+	appendedBundle.SequenceNumber = 1
 
 	// append
 	ab, err := s.ds.AppendBundle(ctx, bundle2)
@@ -268,7 +309,8 @@ func (s *PluginSuite) TestBundleCRUD() {
 	s.Require().NoError(err)
 	s.AssertProtoEqual(bundle3, ab)
 
-	// update with mask: RootCas
+	// update with mask: RootCas. The sequence number is kept.
+	bundle.SequenceNumber = 1
 	updatedBundle, err := s.ds.UpdateBundle(ctx, bundle, &common.BundleMask{
 		RootCas: true,
 	})
@@ -493,7 +535,7 @@ func (s *PluginSuite) TestCountAttestedNodes() {
 
 func (s *PluginSuite) TestCountRegistrationEntries() {
 	// Count empty registration entries
-	count, err := s.ds.CountRegistrationEntries(ctx)
+	count, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
 	s.Require().NoError(err)
 	s.Require().Equal(int32(0), count)
 
@@ -515,9 +557,16 @@ func (s *PluginSuite) TestCountRegistrationEntries() {
 	s.Require().NoError(err)
 
 	// Count all
-	count, err = s.ds.CountRegistrationEntries(ctx)
+	count, err = s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
 	s.Require().NoError(err)
 	s.Require().Equal(int32(2), count)
+
+	// Count by SPIFFE ID prefix
+	count, err = s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{
+		BySpiffeIDPrefix: "spiffe://example.org/f",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int32(1), count)
 }
 
 func (s *PluginSuite) TestSetBundle() {
@@ -586,6 +635,7 @@ func (s *PluginSuite) TestBundlePrune() {
 	// Fetch and verify pruned bundle is the expected
 	expectedPrunedBundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert})
 	expectedPrunedBundle.JwtSigningKeys = []*common.PublicKey{{NotAfter: nonExpiredKeyTime.Unix()}}
+	expectedPrunedBundle.SequenceNumber = 1
 	fb, err := s.ds.FetchBundle(ctx, "spiffe://foo")
 	s.Require().NoError(err)
 	s.AssertProtoEqual(expectedPrunedBundle, fb)
@@ -597,6 +647,7 @@ func (s *PluginSuite) TestCreateAttestedNode() {
 		AttestationDataType: "aws-tag",
 		CertSerialNumber:    "badcafe",
 		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		AttestedAt:          time.Now().Unix(),
 	}
 
 	attestedNode, err := s.ds.CreateAttestedNode(ctx, node)
@@ -629,6 +680,7 @@ func (s *PluginSuite) TestListAttestedNodes() {
 			CertSerialNumber:    sn,
 			CertNotAfter:        notAfter.Unix(),
 			CanReattest:         canReattest,
+			AttestedAt:          now.Unix(),
 			Selectors:           makeSelectors(selectors...),
 		}
 	}
@@ -975,12 +1027,14 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 	expires := int64(1)
 	newSerial := "new-cert-serial-number"
 	newExpires := int64(2)
+	attestedAt := int64(10)
 
 	// Updated nodes values
 	updatedSerial := "cert-serial-number-2"
 	updatedExpires := int64(3)
 	updatedNewSerial := ""
 	updatedNewExpires := int64(0)
+	updatedAttestedAt := int64(20)
 
 	// This connection is never used, each plugin is creating a connection to a new database
 	s.ds.Close()
@@ -1011,6 +1065,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        updatedExpires,
 				NewCertNotAfter:     updatedNewExpires,
 				NewCertSerialNumber: updatedNewSerial,
+				AttestedAt:          updatedAttestedAt,
 			},
 			updateNodeMask: &common.AttestedNodeMask{},
 			expUpdatedNode: &common.AttestedNode{
@@ -1020,6 +1075,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        expires,
 				NewCertNotAfter:     newExpires,
 				NewCertSerialNumber: newSerial,
+				AttestedAt:          attestedAt,
 			},
 		},
 		{
@@ -1030,6 +1086,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        updatedExpires,
 				NewCertNotAfter:     updatedNewExpires,
 				NewCertSerialNumber: updatedNewSerial,
+				AttestedAt:          updatedAttestedAt,
 			},
 			updateNodeMask: &common.AttestedNodeMask{
 				CertSerialNumber: true,
@@ -1042,6 +1099,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        expires,
 				NewCertNotAfter:     updatedNewExpires,
 				NewCertSerialNumber: newSerial,
+				AttestedAt:          attestedAt,
 			},
 		},
 		{
@@ -1052,6 +1110,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        updatedExpires,
 				NewCertNotAfter:     updatedNewExpires,
 				NewCertSerialNumber: updatedNewSerial,
+				AttestedAt:          updatedAttestedAt,
 			},
 			expUpdatedNode: &common.AttestedNode{
 				SpiffeId:            nodeID,
@@ -1060,6 +1119,26 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        updatedExpires,
 				NewCertNotAfter:     updatedNewExpires,
 				NewCertSerialNumber: updatedNewSerial,
+				AttestedAt:          updatedAttestedAt,
+			},
+		},
+		{
+			name: "update attested node attestation time",
+			updateNode: &common.AttestedNode{
+				SpiffeId:   nodeID,
+				AttestedAt: updatedAttestedAt,
+			},
+			updateNodeMask: &common.AttestedNodeMask{
+				AttestedAt: true,
+			},
+			expUpdatedNode: &common.AttestedNode{
+				SpiffeId:            nodeID,
+				AttestationDataType: attestationType,
+				CertSerialNumber:    serial,
+				CertNotAfter:        expires,
+				NewCertNotAfter:     newExpires,
+				NewCertSerialNumber: newSerial,
+				AttestedAt:          updatedAttestedAt,
 			},
 		},
 	} {
@@ -1075,6 +1154,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:        expires,
 				NewCertNotAfter:     newExpires,
 				NewCertSerialNumber: newSerial,
+				AttestedAt:          attestedAt,
 			})
 			s.Require().NoError(err)
 
@@ -1538,6 +1618,7 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 		pageSize              int32
 		byParentID            string
 		bySpiffeID            string
+		bySpiffeIDPrefix      string
 		bySelectors           *datastore.BySelectors
 		byFederatesWith       *datastore.ByFederatesWith
 		expectEntriesOut      []*common.RegistrationEntry
@@ -1841,6 +1922,32 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 			expectPagedTokensIn:   []string{"", "7", "9"},
 			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazbarAD12}, {bazbarCD12}, {}},
 		},
+		// by SPIFFE ID prefix
+		{
+			test:                  "by SPIFFE ID prefix",
+			entries:               []*common.RegistrationEntry{foobarB, zizzazX, bazbuzB},
+			bySpiffeIDPrefix:      makeID("b"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarB, bazbuzB},
+			expectPagedTokensIn:   []string{"", "1", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarB}, {bazbuzB}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID prefix is case sensitive",
+			entries:               []*common.RegistrationEntry{foobarB, zizzazX, bazbuzB},
+			bySpiffeIDPrefix:      makeID("B"),
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		{
+			test:                  "by parentID and SPIFFE ID prefix",
+			entries:               []*common.RegistrationEntry{foobarB, zizzazX, bazbuzB},
+			byParentID:            makeID("baz"),
+			bySpiffeIDPrefix:      makeID("b"),
+			expectEntriesOut:      []*common.RegistrationEntry{bazbuzB},
+			expectPagedTokensIn:   []string{"", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazbuzB}, {}},
+		},
 		// by SPIFFE ID and selector
 		{
 			test:                  "by SPIFFE ID and exact selector",
@@ -2101,11 +2208,12 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 				var tokensIn []string
 				var actualIDsOut [][]string
 				req := &datastore.ListRegistrationEntriesRequest{
-					Pagination:      pagination,
-					ByParentID:      tt.byParentID,
-					BySpiffeID:      tt.bySpiffeID,
-					BySelectors:     tt.bySelectors,
-					ByFederatesWith: tt.byFederatesWith,
+					Pagination:       pagination,
+					ByParentID:       tt.byParentID,
+					BySpiffeID:       tt.bySpiffeID,
+					BySpiffeIDPrefix: tt.bySpiffeIDPrefix,
+					BySelectors:      tt.bySelectors,
+					ByFederatesWith:  tt.byFederatesWith,
 				}
 
 				for i := 0; ; i++ {
@@ -2262,6 +2370,38 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithStoreSvid() {
 	s.Require().EqualError(err, "rpc error: code = Unknown desc = datastore-sql: invalid registration entry: selector types must be the same when store SVID is enabled")
 }
 
+func (s *PluginSuite) TestUpdateRegistrationEntryWithRevisionNumber() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/foo",
+		ParentId:  "spiffe://example.org/bar",
+		Ttl:       1,
+	})
+	s.Require().Equal(int64(0), entry.RevisionNumber)
+
+	mask := &common.RegistrationEntryMask{Ttl: true, RevisionNumber: true}
+
+	// Update with the current revision number succeeds
+	entry.Ttl = 2
+	updated, err := s.ds.UpdateRegistrationEntry(ctx, entry, mask)
+	s.Require().NoError(err)
+	s.Require().Equal(int32(2), updated.Ttl)
+	s.Require().Equal(int64(1), updated.RevisionNumber)
+
+	// Update with a stale revision number is rejected
+	entry.Ttl = 3
+	_, err = s.ds.UpdateRegistrationEntry(ctx, entry, mask)
+	s.RequireGRPCStatus(err, codes.FailedPrecondition, "datastore-sql: entry revision number is 1, not 0")
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(updated, fetched)
+
+	// Revision number is not checked unless it is in the mask
+	_, err = s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{Ttl: true})
+	s.Require().NoError(err)
+}
+
 func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 	// There are 9 fields in a registration entry. Of these, 3 have some validation in the SQL
 	// layer. In this test, we update each of the 9 fields and make sure update works, and also check
@@ -2531,6 +2671,168 @@ func (s *PluginSuite) TestDeleteRegistrationEntry() {
 	s.Require().Nil(deletedEntry)
 }
 
+func (s *PluginSuite) TestDeletedRegistrationEntries() {
+	newEntry := func(name string) *common.RegistrationEntry {
+		return s.createRegistrationEntry(&common.RegistrationEntry{
+			Selectors: []*common.Selector{
+				{Type: "Type1", Value: name},
+			},
+			SpiffeId: "spiffe://example.org/" + name,
+			ParentId: "spiffe://example.org/parent",
+			DnsNames: []string{name + ".example.org"},
+			Ttl:      1,
+		})
+	}
+
+	// Deleted entries are not kept by default
+	entry := newEntry("foo")
+	_, err := s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	deleted, err := s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(deleted)
+
+	s.ds.deletedEntryRetention = time.Hour
+
+	// Restoring an unknown entry fails
+	_, err = s.ds.RestoreRegistrationEntry(ctx, "badid")
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	// Deleted entries are listed until restored
+	entry = newEntry("bar")
+	deletedAt := time.Now()
+	_, err = s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	deleted, err = s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+	s.AssertProtoEqual(entry, deleted[0].Entry)
+	s.Require().WithinDuration(deletedAt, deleted[0].DeletedAt, time.Minute)
+	s.Require().WithinDuration(deletedAt.Add(time.Hour), deleted[0].ExpiresAt, time.Minute)
+
+	restored, err := s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	expected := proto.Clone(entry).(*common.RegistrationEntry)
+	expected.RevisionNumber++
+	s.AssertProtoEqual(expected, restored)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(expected, fetched)
+
+	deleted, err = s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(deleted)
+
+	// Entries cannot be restored when a similar entry exists
+	_, err = s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	similar := newEntry("bar")
+	_, err = s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.AlreadyExists, fmt.Sprintf("datastore-sql: a similar registration entry already exists with entry ID %q", similar.EntryId))
+
+	// Expired deleted entries are neither listed nor restored
+	s.Require().NoError(s.ds.db.Model(&DeletedRegisteredEntry{}).Where("entry_id = ?", entry.EntryId).Update("expires_at", time.Now().Add(-time.Minute).Unix()).Error)
+	deleted, err = s.ds.ListDeletedRegistrationEntries(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(deleted)
+	_, err = s.ds.RestoreRegistrationEntry(ctx, entry.EntryId)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	// Expired deleted entries are pruned when other entries are deleted
+	_, err = s.ds.DeleteRegistrationEntry(ctx, similar.EntryId)
+	s.Require().NoError(err)
+	var count int
+	s.Require().NoError(s.ds.db.Model(&DeletedRegisteredEntry{}).Count(&count).Error)
+	s.Require().Equal(1, count)
+}
+
+func (s *PluginSuite) TestEntryTemplates() {
+	template := &datastore.EntryTemplate{
+		NodeSelectors: []*common.Selector{
+			{Type: "k8s_psat", Value: "cluster:prod"},
+		},
+		Entry: &common.RegistrationEntry{
+			SpiffeId:  "spiffe://example.org/{{ .AgentPath }}/web",
+			ParentId:  "spiffe://example.org/ignored",
+			EntryId:   "ignored",
+			Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+			Ttl:       60,
+		},
+	}
+
+	// Invalid templates are rejected
+	for _, tt := range []struct {
+		template *datastore.EntryTemplate
+		expErr   string
+	}{
+		{
+			template: nil,
+			expErr:   "datastore-sql: invalid request: missing entry template",
+		},
+		{
+			template: &datastore.EntryTemplate{Entry: template.Entry},
+			expErr:   "datastore-sql: invalid entry template: missing node selectors",
+		},
+		{
+			template: &datastore.EntryTemplate{NodeSelectors: template.NodeSelectors},
+			expErr:   "datastore-sql: invalid entry template: missing entry",
+		},
+		{
+			template: &datastore.EntryTemplate{NodeSelectors: template.NodeSelectors, Entry: &common.RegistrationEntry{Selectors: template.Entry.Selectors}},
+			expErr:   "datastore-sql: invalid entry template: missing SPIFFE ID",
+		},
+		{
+			template: &datastore.EntryTemplate{NodeSelectors: template.NodeSelectors, Entry: &common.RegistrationEntry{SpiffeId: template.Entry.SpiffeId}},
+			expErr:   "datastore-sql: invalid entry template: missing selector list",
+		},
+	} {
+		_, err := s.ds.CreateEntryTemplate(ctx, tt.template)
+		s.Require().EqualError(err, tt.expErr)
+	}
+
+	templates, err := s.ds.ListEntryTemplates(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(templates)
+
+	created, err := s.ds.CreateEntryTemplate(ctx, template)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(created.ID)
+	s.AssertProtoListEqual(template.NodeSelectors, created.NodeSelectors)
+	s.AssertProtoEqual(&common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/{{ .AgentPath }}/web",
+		Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+		Ttl:       60,
+	}, created.Entry)
+
+	templates, err = s.ds.ListEntryTemplates(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(templates, 1)
+	s.Require().Equal(created.ID, templates[0].ID)
+	s.AssertProtoListEqual(created.NodeSelectors, templates[0].NodeSelectors)
+	s.AssertProtoEqual(created.Entry, templates[0].Entry)
+
+	deleted, err := s.ds.DeleteEntryTemplate(ctx, created.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(created.ID, deleted.ID)
+
+	_, err = s.ds.DeleteEntryTemplate(ctx, created.ID)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	templates, err = s.ds.ListEntryTemplates(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(templates)
+}
+
+func (s *PluginSuite) TestInvalidDeletedEntryRetention() {
+	err := s.ds.Configure(ctx, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+		deleted_entry_retention = "-1h"
+	`, filepath.Join(s.dir, "retention.sqlite3")))
+	s.RequireErrorContains(err, "deleted_entry_retention must not be negative")
+}
+
 func (s *PluginSuite) TestListParentIDEntries() {
 	allEntries := make([]*common.RegistrationEntry, 0)
 	s.getTestDataFromJSONFile(filepath.Join("testdata", "entries.json"), &allEntries)
@@ -3149,6 +3451,141 @@ func (s *PluginSuite) TestDeleteBundleDissociateRegistrationEntries() {
 	s.Require().Empty(entry.FederatesWith)
 }
 
+func (s *PluginSuite) TestAcquireLease() {
+	now := time.Now().Truncate(time.Second)
+	leaseA := &datastore.Lease{
+		Name:      "duty",
+		HolderID:  "server-a",
+		ExpiresAt: now.Add(time.Minute),
+	}
+	leaseB := &datastore.Lease{
+		Name:      "duty",
+		HolderID:  "server-b",
+		ExpiresAt: now.Add(time.Minute),
+	}
+
+	// Invalid leases are rejected
+	_, err := s.ds.AcquireLease(ctx, &datastore.Lease{Name: "duty"}, now)
+	s.Require().EqualError(err, "name, holder ID and expiry are required")
+
+	// The first holder acquires the lease
+	current, err := s.ds.AcquireLease(ctx, leaseA, now)
+	s.Require().NoError(err)
+	s.Equal(leaseA, current)
+
+	// Another holder cannot acquire a lease that has not expired
+	current, err = s.ds.AcquireLease(ctx, leaseB, now)
+	s.Require().NoError(err)
+	s.Equal(leaseA, current)
+
+	// The holder renews the lease
+	leaseA.ExpiresAt = now.Add(2 * time.Minute)
+	current, err = s.ds.AcquireLease(ctx, leaseA, now.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Equal(leaseA, current)
+
+	// Another holder acquires the lease once it expires
+	leaseB.ExpiresAt = now.Add(3 * time.Minute)
+	current, err = s.ds.AcquireLease(ctx, leaseB, now.Add(2*time.Minute))
+	s.Require().NoError(err)
+	s.Equal(leaseB, current)
+
+	// Leases with different names are independent
+	other := &datastore.Lease{
+		Name:      "other-duty",
+		HolderID:  "server-a",
+		ExpiresAt: now.Add(time.Minute),
+	}
+	current, err = s.ds.AcquireLease(ctx, other, now)
+	s.Require().NoError(err)
+	s.Equal(other, current)
+}
+
+func (s *PluginSuite) TestAgentRenewal() {
+	node := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/foo",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	}
+	_, err := s.ds.CreateAttestedNode(ctx, node)
+	s.Require().NoError(err)
+
+	// Invalid renewals are rejected
+	err = s.ds.SetAgentRenewal(ctx, &datastore.AgentRenewal{SpiffeID: node.SpiffeId})
+	s.Require().EqualError(err, "SPIFFE ID and serial number are required")
+
+	// No renewal has been requested yet
+	renewal, err := s.ds.FetchAgentRenewal(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Nil(renewal)
+
+	// Request a renewal
+	expected := &datastore.AgentRenewal{SpiffeID: node.SpiffeId, SerialNumber: "badcafe"}
+	s.Require().NoError(s.ds.SetAgentRenewal(ctx, expected))
+	renewal, err = s.ds.FetchAgentRenewal(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Equal(expected, renewal)
+
+	// A new request replaces the previous one
+	expected = &datastore.AgentRenewal{SpiffeID: node.SpiffeId, SerialNumber: "deadbeef"}
+	s.Require().NoError(s.ds.SetAgentRenewal(ctx, expected))
+	renewal, err = s.ds.FetchAgentRenewal(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Equal(expected, renewal)
+
+	// Deleting the agent drops the renewal
+	_, err = s.ds.DeleteAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	renewal, err = s.ds.FetchAgentRenewal(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Nil(renewal)
+}
+
+func (s *PluginSuite) TestEntryDNSNamePatterns() {
+	entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/web",
+		ParentId:  "spiffe://example.org/spire/agent/foo",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	s.Require().NoError(err)
+
+	// Setting patterns requires an entry ID
+	err = s.ds.SetEntryDNSNamePatterns(ctx, "", []string{"*.web.example.org"})
+	s.Require().EqualError(err, "entry ID is required")
+
+	// No patterns have been set yet
+	patterns, err := s.ds.FetchEntryDNSNamePatterns(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Nil(patterns)
+
+	// Set patterns
+	s.Require().NoError(s.ds.SetEntryDNSNamePatterns(ctx, entry.EntryId, []string{"*.web.example.org"}))
+	patterns, err = s.ds.FetchEntryDNSNamePatterns(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Equal([]string{"*.web.example.org"}, patterns)
+
+	// New patterns replace the previous ones
+	s.Require().NoError(s.ds.SetEntryDNSNamePatterns(ctx, entry.EntryId, []string{"*.api.example.org", "web.example.org"}))
+	patterns, err = s.ds.FetchEntryDNSNamePatterns(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Equal([]string{"*.api.example.org", "web.example.org"}, patterns)
+
+	// Empty patterns remove them
+	s.Require().NoError(s.ds.SetEntryDNSNamePatterns(ctx, entry.EntryId, nil))
+	patterns, err = s.ds.FetchEntryDNSNamePatterns(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Nil(patterns)
+
+	// Deleting the entry drops the patterns
+	s.Require().NoError(s.ds.SetEntryDNSNamePatterns(ctx, entry.EntryId, []string{"*.web.example.org"}))
+	_, err = s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	patterns, err = s.ds.FetchEntryDNSNamePatterns(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Nil(patterns)
+}
+
 func (s *PluginSuite) TestCreateJoinToken() {
 	req := &datastore.JoinToken{
 		Token:  "foobar",
@@ -3178,6 +3615,28 @@ func (s *PluginSuite) TestCreateAndFetchJoinToken() {
 	s.Equal(now, res.Expiry)
 }
 
+func (s *PluginSuite) TestListJoinTokens() {
+	tokens, err := s.ds.ListJoinTokens(ctx)
+	s.Require().NoError(err)
+	s.Empty(tokens)
+
+	now := time.Now().Truncate(time.Second)
+	joinToken1 := &datastore.JoinToken{
+		Token:  "foobar",
+		Expiry: now.Add(time.Hour),
+	}
+	joinToken2 := &datastore.JoinToken{
+		Token:  "batbaz",
+		Expiry: now,
+	}
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, joinToken1))
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, joinToken2))
+
+	tokens, err = s.ds.ListJoinTokens(ctx)
+	s.Require().NoError(err)
+	s.Equal([]*datastore.JoinToken{joinToken2, joinToken1}, tokens)
+}
+
 func (s *PluginSuite) TestDeleteJoinToken() {
 	now := time.Now().Truncate(time.Second)
 	joinToken1 := &datastore.JoinToken{
@@ -3210,6 +3669,76 @@ func (s *PluginSuite) TestDeleteJoinToken() {
 	s.Equal(joinToken2, resp)
 }
 
+func (s *PluginSuite) TestConsumeJoinToken() {
+	now := time.Now().Truncate(time.Second)
+	joinToken := &datastore.JoinToken{
+		Token:  "foobar",
+		Expiry: now,
+	}
+	expiredToken := &datastore.JoinToken{
+		Token:  "batbaz",
+		Expiry: now.Add(-time.Second),
+	}
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, joinToken))
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, expiredToken))
+
+	// Tokens that do not exist are not consumed
+	resp, err := s.ds.ConsumeJoinToken(ctx, "unknown", now)
+	s.Require().NoError(err)
+	s.Nil(resp)
+
+	// Expired tokens are not consumed
+	resp, err = s.ds.ConsumeJoinToken(ctx, expiredToken.Token, now)
+	spiretest.RequireGRPCStatus(s.T(), err, codes.FailedPrecondition, "datastore-sql: join token expired")
+	s.Nil(resp)
+
+	// Tokens can be consumed up to their expiry
+	resp, err = s.ds.ConsumeJoinToken(ctx, joinToken.Token, now)
+	s.Require().NoError(err)
+	s.Equal(joinToken, resp)
+
+	// Tokens can only be consumed once
+	resp, err = s.ds.ConsumeJoinToken(ctx, joinToken.Token, now)
+	s.Require().NoError(err)
+	s.Nil(resp)
+
+	resp, err = s.ds.FetchJoinToken(ctx, joinToken.Token)
+	s.Require().NoError(err)
+	s.Nil(resp)
+}
+
+func (s *PluginSuite) TestConsumeJoinTokenConcurrently() {
+	now := time.Now().Truncate(time.Second)
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:  "foobar",
+		Expiry: now.Add(time.Minute),
+	}))
+
+	const consumers = 10
+	var wg sync.WaitGroup
+	results := make(chan *datastore.JoinToken, consumers)
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := s.ds.ConsumeJoinToken(ctx, "foobar", now)
+			if s.NoError(err) {
+				results <- resp
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	consumed := 0
+	for resp := range results {
+		if resp != nil {
+			consumed++
+		}
+	}
+	s.Equal(1, consumed, "join token must be consumed exactly once")
+}
+
 func (s *PluginSuite) TestPruneJoinTokens() {
 	now := time.Now().Truncate(time.Second)
 	joinToken := &datastore.JoinToken{
@@ -3940,6 +4469,28 @@ func (s *PluginSuite) TestMigration() {
 				prepareDB(true)
 				require.True(s.ds.db.Dialect().HasColumn("attested_node_entries", "can_reattest"))
 				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "hint"))
+			case 18:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("leases"))
+			case 19:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("agent_renewals"))
+			case 20:
+				prepareDB(true)
+				require.False(s.ds.db.Dialect().HasIndex("selectors", "idx_selectors_type_value"))
+				require.True(s.ds.db.Dialect().HasIndex("selectors", "idx_selectors_type_value_entry"))
+			case 21:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("deleted_registered_entries"))
+			case 22:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("entry_templates"))
+			case 23:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("attested_node_entries", "attested_at"))
+			case 24:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("entry_dns_name_patterns"))
 			default:
 				t.Fatalf("no migration test added for schema version %d", schemaVersion)
 			}
@@ -0,0 +1,124 @@
+package downstreamwebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout applied to webhook requests when the
+	// webhook configuration does not set one.
+	DefaultTimeout = 5 * time.Second
+
+	// maxResponseSize is the maximum size of a webhook response body
+	maxResponseSize = 64 * 1024
+)
+
+// Selector is a selector of a downstream entry
+type Selector struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Entry is a downstream registration entry matched by the caller
+type Entry struct {
+	ID        string     `json:"id"`
+	SPIFFEID  string     `json:"spiffe_id"`
+	ParentID  string     `json:"parent_id"`
+	Selectors []Selector `json:"selectors,omitempty"`
+	TTL       int32      `json:"ttl,omitempty"`
+}
+
+// Request is the JSON body posted to the webhook for every downstream X509 CA
+// signing request.
+type Request struct {
+	CallerID    string  `json:"caller_id"`
+	TrustDomain string  `json:"trust_domain"`
+	Entries     []Entry `json:"entries"`
+}
+
+// Response is the JSON body expected from the webhook
+type Response struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Authorizer authorizes downstream X509 CA signing requests
+type Authorizer interface {
+	// AuthorizeDownstream returns an error if the request is not authorized
+	AuthorizeDownstream(ctx context.Context, req Request) error
+}
+
+// Config is the configuration of the webhook
+type Config struct {
+	// URL receives a POST request with the JSON encoded request
+	URL string
+
+	// Timeout for each request. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Webhook authorizes downstream X509 CA signing requests by posting them to
+// an external policy service. Requests are denied if the webhook cannot be
+// reached or does not explicitly allow them.
+type Webhook struct {
+	c      Config
+	client *http.Client
+}
+
+// New creates a webhook for the given configuration
+func New(c Config) *Webhook {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return &Webhook{
+		c:      c,
+		client: &http.Client{},
+	}
+}
+
+// AuthorizeDownstream posts the request to the webhook and returns an error
+// unless the webhook allows it.
+func (w *Webhook) AuthorizeDownstream(ctx context.Context, req Request) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.c.Timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !result.Allow {
+		if result.Reason != "" {
+			return fmt.Errorf("denied by webhook: %s", result.Reason)
+		}
+		return errors.New("denied by webhook")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package downstreamwebhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeDownstream(t *testing.T) {
+	req := downstreamwebhook.Request{
+		CallerID:    "spiffe://example.org/downstream",
+		TrustDomain: "example.org",
+		Entries: []downstreamwebhook.Entry{
+			{
+				ID:        "entry-1",
+				SPIFFEID:  "spiffe://example.org/downstream",
+				ParentID:  "spiffe://example.org/spire/agent/join_token/token",
+				Selectors: []downstreamwebhook.Selector{{Type: "unix", Value: "uid:1000"}},
+				TTL:       3600,
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		status    int
+		body      string
+		delay     time.Duration
+		expectErr string
+	}{
+		{
+			name:   "allowed",
+			status: http.StatusOK,
+			body:   `{"allow": true}`,
+		},
+		{
+			name:      "denied",
+			status:    http.StatusOK,
+			body:      `{"allow": false}`,
+			expectErr: "denied by webhook",
+		},
+		{
+			name:      "denied with reason",
+			status:    http.StatusOK,
+			body:      `{"allow": false, "reason": "path /payments is not delegated"}`,
+			expectErr: "denied by webhook: path /payments is not delegated",
+		},
+		{
+			name:      "empty response",
+			status:    http.StatusOK,
+			body:      `{}`,
+			expectErr: "denied by webhook",
+		},
+		{
+			name:      "malformed response",
+			status:    http.StatusOK,
+			body:      `not json`,
+			expectErr: "failed to decode response: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:      "unexpected status",
+			status:    http.StatusInternalServerError,
+			body:      `{"allow": true}`,
+			expectErr: "unexpected status code 500",
+		},
+		{
+			name:      "timeout",
+			status:    http.StatusOK,
+			body:      `{"allow": true}`,
+			delay:     time.Second,
+			expectErr: "context deadline exceeded",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				var actual downstreamwebhook.Request
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+				assert.Equal(t, req, actual)
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			webhook := downstreamwebhook.New(downstreamwebhook.Config{
+				URL:     server.URL,
+				Timeout: 100 * time.Millisecond,
+			})
+			err := webhook.AuthorizeDownstream(context.Background(), req)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package endpoints
+
+import (
+	"crypto/x509"
+	"net"
+
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"google.golang.org/grpc/credentials"
+)
+
+// attestationCredentials wraps the TLS transport credentials of a listener
+// that trusts external CAs for node attestation. Connections whose client
+// certificate chains to one of those CAs are tagged with
+// middleware.AttestationTLSInfo so the caller is not authenticated with the
+// SPIFFE ID of the certificate.
+type attestationCredentials struct {
+	credentials.TransportCredentials
+	roots *x509.CertPool
+}
+
+func newAttestationCredentials(creds credentials.TransportCredentials, cas []*x509.Certificate) credentials.TransportCredentials {
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+	return &attestationCredentials{
+		TransportCredentials: creds,
+		roots:                roots,
+	}
+}
+
+func (c *attestationCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, authInfo, err := c.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsInfo, ok := authInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return conn, authInfo, nil
+	}
+
+	// The TLS stack already verified the client certificate against both the
+	// trust bundle and the attestation CAs. Verify it again against the
+	// attestation CAs alone to tell which one issued it.
+	peerCerts := tlsInfo.State.PeerCertificates
+	intermediates := x509.NewCertPool()
+	for _, cert := range peerCerts[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := peerCerts[0].Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		// Not issued by an attestation CA
+		return conn, authInfo, nil
+	}
+
+	tlsInfo.State.VerifiedChains = chains
+	return conn, middleware.AttestationTLSInfo{TLSInfo: tlsInfo}, nil
+}
+
+func (c *attestationCredentials) Clone() credentials.TransportCredentials {
+	return &attestationCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		roots:                c.roots,
+	}
+}
@@ -1,11 +1,24 @@
 package bundle
 
-import "net"
+import (
+	"net"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
+)
 
 type EndpointConfig struct {
 	// Address is the address on which to serve the federation bundle endpoint.
 	Address *net.TCPAddr
 
+	// RefreshHint is the refresh hint advertised in the served bundle. If
+	// zero, it is calculated from the bundle contents.
+	RefreshHint time.Duration
+
+	// TLSPolicy restricts the TLS versions and cipher suites accepted by the
+	// bundle endpoint.
+	TLSPolicy tlspolicy.Policy
+
 	// ACME is the ACME configuration for the bundle endpoint.
 	// If unset, the bundle endpoint will use SPIFFE auth.
 	ACME *ACMEConfig
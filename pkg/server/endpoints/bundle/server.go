@@ -6,9 +6,11 @@ import (
 	"crypto/x509"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/zeebo/errs"
 )
 
@@ -32,6 +34,13 @@ type ServerConfig struct {
 	Getter     Getter
 	ServerAuth ServerAuth
 
+	// RefreshHint overrides the refresh hint calculated from the bundle
+	// when non-zero.
+	RefreshHint time.Duration
+
+	// TLSPolicy restricts the TLS versions and cipher suites accepted.
+	TLSPolicy tlspolicy.Policy
+
 	// test hooks
 	listen func(network, address string) (net.Listener, error)
 }
@@ -57,9 +66,10 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 		return errs.Wrap(err)
 	}
 
-	// Set up the TLS config, setting TLS 1.2 as the minimum.
+	// Set up the TLS config, setting TLS 1.2 as the minimum unless the
+	// policy says otherwise.
 	tlsConfig := s.c.ServerAuth.GetTLSConfig()
-	tlsConfig.MinVersion = tls.VersionTLS12
+	tlspolicy.ApplyPolicy(tlsConfig, s.c.TLSPolicy)
 
 	server := &http.Server{
 		Handler:   http.HandlerFunc(s.serveHTTP),
@@ -97,9 +107,12 @@ func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	refreshHint := bundleutil.CalculateRefreshHint(b)
+	refreshHint := s.c.RefreshHint
+	if refreshHint == 0 {
+		refreshHint = bundleutil.CalculateRefreshHint(b)
+	}
 
-	// TODO: bundle sequence number?
+	// The sequence number of the bundle is served as is
 	opts := []bundleutil.MarshalOption{
 		bundleutil.OverrideRefreshHint(refreshHint),
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle/internal/acmetest"
 	"github.com/spiffe/spire/test/fakes/fakeserverkeymanager"
 	"github.com/spiffe/spire/test/spiretest"
@@ -41,6 +42,11 @@ func TestServer(t *testing.T) {
 	bundle := bundleutil.New(trustDomain)
 	bundle.AppendRootCA(serverCert)
 
+	sequencedBundle := bundleutil.New(trustDomain)
+	sequencedBundle.AppendRootCA(serverCert)
+	sequencedBundle.SetRefreshHint(5 * time.Minute)
+	sequencedBundle.SetSequenceNumber(7)
+
 	// even though this will be SPIFFE authentication in production, there is
 	// no functional change in the code based on the server certificate
 	// returned from the getter, so for test purposes we'll just use a
@@ -62,9 +68,10 @@ func TestServer(t *testing.T) {
 		path       string
 		status     int
 		body       string
-		bundle     *bundleutil.Bundle
-		serverCert *x509.Certificate
-		reqErr     string
+		bundle      *bundleutil.Bundle
+		serverCert  *x509.Certificate
+		refreshHint time.Duration
+		reqErr      string
 	}{
 		{
 			name:   "success",
@@ -87,6 +94,50 @@ func TestServer(t *testing.T) {
 			bundle:     bundle,
 			serverCert: serverCert,
 		},
+		{
+			name:   "success with configured refresh hint",
+			method: "GET",
+			path:   "/",
+			status: http.StatusOK,
+			body: fmt.Sprintf(`{
+				"keys": [
+					{
+						"crv":"P-256",
+						"kty":"EC",
+						"use":"x509-svid",
+						"x":"kkEn5E2Hd_rvCRDCVMNj3deN0ADij9uJVmN-El0CJz0",
+						"y":"qNrnjhtzrtTR0bRgI2jPIC1nEgcWNX63YcZOEzyo1iA",
+						"x5c": [%q]
+					}
+				],
+				"spiffe_refresh_hint": 60
+			}`, base64.StdEncoding.EncodeToString(serverCert.Raw)),
+			bundle:      bundle,
+			serverCert:  serverCert,
+			refreshHint: time.Minute,
+		},
+		{
+			name:   "success with bundle refresh hint and sequence number",
+			method: "GET",
+			path:   "/",
+			status: http.StatusOK,
+			body: fmt.Sprintf(`{
+				"keys": [
+					{
+						"crv":"P-256",
+						"kty":"EC",
+						"use":"x509-svid",
+						"x":"kkEn5E2Hd_rvCRDCVMNj3deN0ADij9uJVmN-El0CJz0",
+						"y":"qNrnjhtzrtTR0bRgI2jPIC1nEgcWNX63YcZOEzyo1iA",
+						"x5c": [%q]
+					}
+				],
+				"spiffe_refresh_hint": 300,
+				"spiffe_sequence": 7
+			}`, base64.StdEncoding.EncodeToString(serverCert.Raw)),
+			bundle:     sequencedBundle,
+			serverCert: serverCert,
+		},
 		{
 			name:       "invalid method",
 			method:     "POST",
@@ -123,6 +174,8 @@ func TestServer(t *testing.T) {
 			addr, done := newTestServer(t,
 				testGetter(testCase.bundle),
 				testSPIFFEAuth(testCase.serverCert, serverKey),
+				testCase.refreshHint,
+				tlspolicy.Policy{},
 			)
 			defer done()
 
@@ -152,6 +205,40 @@ func TestServer(t *testing.T) {
 	}
 }
 
+func TestServerTLSPolicy(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+	bundle := bundleutil.New(trustDomain)
+	bundle.AppendRootCA(serverCert)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCert)
+
+	addr, done := newTestServer(t,
+		testGetter(bundle),
+		testSPIFFEAuth(serverCert, serverKey),
+		0,
+		tlspolicy.Policy{MinVersion: tls.VersionTLS13},
+	)
+	defer done()
+
+	// Clients must support the minimum TLS version of the policy
+	_, err := tls.Dial("tcp", addr.String(), &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS12,
+	})
+	require.Error(t, err)
+
+	conn, err := tls.Dial("tcp", addr.String(), &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, uint16(tls.VersionTLS13), conn.ConnectionState().Version)
+}
+
 func TestACMEAuth(t *testing.T) {
 	dir := spiretest.TempDir(t)
 
@@ -184,6 +271,8 @@ func TestACMEAuth(t *testing.T) {
 				Email:        "admin@domain.test",
 				ToSAccepted:  false,
 			}),
+			0,
+			tlspolicy.Policy{},
 		)
 		defer done()
 
@@ -216,6 +305,8 @@ func TestACMEAuth(t *testing.T) {
 				Email:        "admin@domain.test",
 				ToSAccepted:  true,
 			}),
+			0,
+			tlspolicy.Policy{},
 		)
 		defer done()
 
@@ -264,6 +355,8 @@ func TestACMEAuth(t *testing.T) {
 				Email:        "admin@domain.test",
 				ToSAccepted:  true,
 			}),
+			0,
+			tlspolicy.Policy{},
 		)
 		defer done()
 
@@ -275,7 +368,7 @@ func TestACMEAuth(t *testing.T) {
 	})
 }
 
-func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth) (net.Addr, func()) {
+func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth, refreshHint time.Duration, tlsPolicy tlspolicy.Policy) (net.Addr, func()) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	addrCh := make(chan net.Addr, 1)
@@ -293,8 +386,10 @@ func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth) (net.Addr
 		Log:        log,
 		Address:    "localhost:0",
 		Getter:     getter,
-		ServerAuth: serverAuth,
-		listen:     listen,
+		ServerAuth:  serverAuth,
+		RefreshHint: refreshHint,
+		TLSPolicy:   tlsPolicy,
+		listen:      listen,
 	})
 
 	errCh := make(chan error, 1)
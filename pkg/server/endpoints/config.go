@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"net"
+	"os"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -12,19 +13,24 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/server/api"
 	agentv1 "github.com/spiffe/spire/pkg/server/api/agent/v1"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	bundlev1 "github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	debugv1 "github.com/spiffe/spire/pkg/server/api/debug/v1"
+	denylistv1 "github.com/spiffe/spire/pkg/server/api/denylist/v1"
 	entryv1 "github.com/spiffe/spire/pkg/server/api/entry/v1"
 	healthv1 "github.com/spiffe/spire/pkg/server/api/health/v1"
 	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
 	trustdomainv1 "github.com/spiffe/spire/pkg/server/api/trustdomain/v1"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
 	"golang.org/x/net/context"
@@ -35,9 +41,28 @@ type Config struct {
 	// TPCAddr is the address to bind the TCP listener to.
 	TCPAddr *net.TCPAddr
 
+	// TCPTLSPolicy restricts the TLS versions and cipher suites accepted by
+	// the TCP listener.
+	TCPTLSPolicy tlspolicy.Policy
+
+	// AdditionalTCPListeners are additional TCP listeners serving the Server
+	// APIs, each with its own TLS settings.
+	AdditionalTCPListeners []TCPListener
+
 	// LocalAddr is the local address to bind the listener to.
 	LocalAddr net.Addr
 
+	// GRPC holds options to tune the gRPC servers.
+	GRPC GRPCConfig
+
+	// LocalAddrMode is the file mode applied to the local UDS (Unix only).
+	// Defaults to 0770 when unset.
+	LocalAddrMode os.FileMode
+
+	// LocalAddrGroup, if set, is the group (name or ID) that owns the local
+	// UDS (Unix only).
+	LocalAddrGroup string
+
 	// The svid rotator used to obtain the latest server credentials
 	SVIDObserver svid.Observer
 
@@ -53,6 +78,10 @@ type Config struct {
 	// TTL to use when signing agent SVIDs
 	AgentTTL time.Duration
 
+	// Maximum time since their last node attestation that agents able to
+	// re-attest can keep renewing their SVID
+	AgentMaxRenewalAge time.Duration
+
 	// Bundle endpoint configuration
 	BundleEndpoint bundle.EndpointConfig
 
@@ -75,15 +104,69 @@ type Config struct {
 	// CacheReloadInterval controls how often the in-memory entry cache reloads
 	CacheReloadInterval time.Duration
 
+	// AdminReadAfterWrite makes admin API reads bypass the in-memory caches
+	AdminReadAfterWrite bool
+
 	AuditLogEnabled bool
 
+	// AuditLogSink, if not nil, receives the audit records regardless of
+	// the log level
+	AuditLogSink audit.Sink
+
+	// ShutdownDrainTimeout is how long to wait for in-flight RPCs to finish
+	// when the endpoints are stopped. If zero, in-flight RPCs are cancelled
+	// immediately.
+	ShutdownDrainTimeout time.Duration
+
 	// AdminIDs are a list of fixed IDs that when presented by a caller in an
 	// X509-SVID, are granted admin rights.
 	AdminIDs []spiffeid.ID
 
+	// EntryNamespaces scope the registration entries that admin callers can
+	// access through the entry API.
+	EntryNamespaces []entryv1.Namespace
+
+	// IDPathPolicy, if set, restricts the SPIFFE ID paths that can be
+	// registered and signed in the trust domain.
+	IDPathPolicy *api.IDPathPolicy
+
+	// IssuanceQuotas limit the rate at which SVIDs are signed per agent and
+	// per registration entry.
+	IssuanceQuotas svidv1.IssuanceQuotas
+
+	// JWTAudienceRestrictions limit the audiences that JWT-SVIDs can be
+	// signed for on behalf of registration entries.
+	JWTAudienceRestrictions []svidv1.AudienceRestriction
+
+	// AttestationNotifier, if set, is notified of the result of every node
+	// attestation.
+	AttestationNotifier attestationwebhook.Notifier
+
+	// DownstreamAuthorizer, if set, must authorize every downstream X509 CA
+	// signing request.
+	DownstreamAuthorizer downstreamwebhook.Authorizer
+
+	// CSRExtensionAllowlist lists the CSR extensions copied into workload
+	// X509-SVIDs by the CA.
+	CSRExtensionAllowlist ca.CSRExtensionAllowlist
+
+	// SVIDDenylist is the SVID denylist enforced by the server CA. Its
+	// patterns can be managed through the denylist API.
+	SVIDDenylist *ca.Denylist
+
 	BundleManager *bundle_client.Manager
 }
 
+// namespacedAdminIDs returns the IDs of the admin callers that are scoped to
+// an entry namespace.
+func (c *Config) namespacedAdminIDs() []spiffeid.ID {
+	var ids []spiffeid.ID
+	for _, ns := range c.EntryNamespaces {
+		ids = append(ids, ns.AdminIDs...)
+	}
+	return ids
+}
+
 func (c *Config) maybeMakeBundleEndpointServer() Server {
 	if c.BundleEndpoint.Address == nil {
 		return nil
@@ -102,8 +185,10 @@ func (c *Config) maybeMakeBundleEndpointServer() Server {
 
 	ds := c.Catalog.GetDataStore()
 	return bundle.NewServer(bundle.ServerConfig{
-		Log:     c.Log.WithField(telemetry.SubsystemName, "bundle_endpoint"),
-		Address: c.BundleEndpoint.Address.String(),
+		Log:         c.Log.WithField(telemetry.SubsystemName, "bundle_endpoint"),
+		Address:     c.BundleEndpoint.Address.String(),
+		RefreshHint: c.BundleEndpoint.RefreshHint,
+		TLSPolicy:   c.BundleEndpoint.TLSPolicy,
 		Getter: bundle.GetterFunc(func(ctx context.Context) (*bundleutil.Bundle, error) {
 			commonBundle, err := ds.FetchBundle(dscache.WithCache(ctx), c.TrustDomain.IDString())
 			if err != nil {
@@ -122,20 +207,40 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 	ds := c.Catalog.GetDataStore()
 	upstreamPublisher := UpstreamPublisher(c.Manager)
 
+	agentServer := agentv1.New(agentv1.Config{
+		DataStore:   ds,
+		ServerCA:    c.ServerCA,
+		AgentTTL:    c.AgentTTL,
+		TrustDomain: c.TrustDomain,
+		Catalog:     c.Catalog,
+		Clock:       c.Clock,
+
+		AttestationNotifier: c.AttestationNotifier,
+		AgentMaxRenewalAge:  c.AgentMaxRenewalAge,
+	})
+
+	entryServer := entryv1.New(entryv1.Config{
+		TrustDomain:  c.TrustDomain,
+		DataStore:    ds,
+		EntryFetcher: entryFetcher,
+		Namespaces:   c.EntryNamespaces,
+		IDPathPolicy: c.IDPathPolicy,
+	})
+
+	bundleServer := bundlev1.New(bundlev1.Config{
+		TrustDomain:       c.TrustDomain,
+		DataStore:         ds,
+		UpstreamPublisher: upstreamPublisher,
+		ReadAfterWrite:    c.AdminReadAfterWrite,
+	})
+
 	return APIServers{
-		AgentServer: agentv1.New(agentv1.Config{
-			DataStore:   ds,
-			ServerCA:    c.ServerCA,
-			AgentTTL:    c.AgentTTL,
-			TrustDomain: c.TrustDomain,
-			Catalog:     c.Catalog,
-			Clock:       c.Clock,
-		}),
-		BundleServer: bundlev1.New(bundlev1.Config{
-			TrustDomain:       c.TrustDomain,
-			DataStore:         ds,
-			UpstreamPublisher: upstreamPublisher,
-		}),
+		AgentServer:          agentServer,
+		AgentBootstrapServer: agentServer,
+		AgentRenewalServer:   agentServer,
+		AgentCountServer:     agentServer,
+		BundleServer:         bundleServer,
+		BundleCountServer:    bundleServer,
 		DebugServer: debugv1.New(debugv1.Config{
 			TrustDomain:  c.TrustDomain,
 			Clock:        c.Clock,
@@ -143,20 +248,32 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			SVIDObserver: c.SVIDObserver,
 			Uptime:       c.Uptime,
 		}),
-		EntryServer: entryv1.New(entryv1.Config{
-			TrustDomain:  c.TrustDomain,
-			DataStore:    ds,
-			EntryFetcher: entryFetcher,
+		DenylistServer: denylistv1.New(denylistv1.Config{
+			Denylist: c.SVIDDenylist,
 		}),
+		EntryServer:          entryServer,
+		EntryCountServer:     entryServer,
+		EntryDNSNamesServer:  entryServer,
+		EntryRestoreServer:   entryServer,
+		EntrySyncServer:      entryServer,
+		EntryTemplatesServer: entryServer,
+		JoinTokensServer:     agentServer,
 		HealthServer: healthv1.New(healthv1.Config{
 			TrustDomain: c.TrustDomain,
 			DataStore:   ds,
 		}),
 		SVIDServer: svidv1.New(svidv1.Config{
-			TrustDomain:  c.TrustDomain,
-			EntryFetcher: entryFetcher,
-			ServerCA:     c.ServerCA,
-			DataStore:    ds,
+			TrustDomain:           c.TrustDomain,
+			EntryFetcher:          entryFetcher,
+			ServerCA:              c.ServerCA,
+			DataStore:             ds,
+			IDPathPolicy:          c.IDPathPolicy,
+			Quotas:                c.IssuanceQuotas,
+			AudienceRestrictions:  c.JWTAudienceRestrictions,
+			Metrics:               c.Metrics,
+			Clock:                 c.Clock,
+			DownstreamAuthorizer:  c.DownstreamAuthorizer,
+			CSRExtensionAllowlist: c.CSRExtensionAllowlist,
 		}),
 		TrustDomainServer: trustdomainv1.New(trustdomainv1.Config{
 			TrustDomain:     c.TrustDomain,
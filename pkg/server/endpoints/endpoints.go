@@ -15,6 +15,7 @@ import (
 	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // register the gzip compressor for agents that enable compression
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 
@@ -30,12 +31,23 @@ import (
 	"github.com/spiffe/spire/pkg/common/auth"
 	"github.com/spiffe/spire/pkg/common/peertracker"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/denylist"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 )
 
 const (
@@ -47,6 +59,10 @@ const (
 	// This is the default amount of time between two reloads of the in-memory
 	// entry cache.
 	defaultCacheReloadInterval = 5 * time.Second
+
+	// This is the default file mode of the local UDS, which restricts access
+	// to processes running as the same user or group as the server.
+	defaultLocalAddrMode os.FileMode = 0770
 )
 
 // Server manages gRPC and HTTP endpoint lifecycle
@@ -58,9 +74,77 @@ type Server interface {
 	ListenAndServe(ctx context.Context) error
 }
 
+// TCPListener configures a TCP listener serving the Server APIs.
+type TCPListener struct {
+	// Addr is the address to bind the listener to.
+	Addr *net.TCPAddr
+
+	// TLSPolicy restricts the TLS versions and cipher suites accepted by
+	// the listener.
+	TLSPolicy tlspolicy.Policy
+
+	// RequireClientCert, if true, rejects TLS connections that do not
+	// present a client certificate. Agents must already be attested to
+	// connect to such a listener.
+	RequireClientCert bool
+
+	// AttestationCAs are external CAs trusted to issue client certificates
+	// that agents present to attest with the "x509pop_tls" attestation type.
+	// Callers presenting such a certificate are never authenticated with
+	// the SPIFFE ID of the certificate.
+	AttestationCAs []*x509.Certificate
+}
+
+// GRPCConfig holds options to tune the gRPC servers. Zero values use the
+// gRPC defaults.
+type GRPCConfig struct {
+	// KeepaliveTime is how long a TCP connection can be idle before the
+	// server pings the client.
+	KeepaliveTime time.Duration
+
+	// KeepaliveTimeout is how long the server waits for a ping response
+	// before closing the connection.
+	KeepaliveTimeout time.Duration
+
+	// KeepaliveMinTime is the minimum amount of time clients should wait
+	// between pings. Clients that ping more often are disconnected.
+	KeepaliveMinTime time.Duration
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams per
+	// connection.
+	MaxConcurrentStreams uint32
+
+	// MaxRecvMsgSize is the maximum size, in bytes, of received messages.
+	MaxRecvMsgSize int
+
+	// MaxSendMsgSize is the maximum size, in bytes, of sent messages.
+	MaxSendMsgSize int
+}
+
+// serverOptions returns the gRPC server options common to the TCP and UDS
+// servers.
+func (c GRPCConfig) serverOptions() []grpc.ServerOption {
+	var options []grpc.ServerOption
+	if c.MaxConcurrentStreams > 0 {
+		options = append(options, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	if c.MaxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	return options
+}
+
 type Endpoints struct {
 	TCPAddr                      *net.TCPAddr
+	TCPTLSPolicy                 tlspolicy.Policy
+	AdditionalTCPListeners       []TCPListener
+	GRPC                         GRPCConfig
 	LocalAddr                    net.Addr
+	LocalAddrMode                os.FileMode
+	LocalAddrGroup               string
 	SVIDObserver                 svid.Observer
 	TrustDomain                  spiffeid.TrustDomain
 	DataStore                    datastore.DataStore
@@ -71,18 +155,32 @@ type Endpoints struct {
 	RateLimit                    RateLimitConfig
 	EntryFetcherCacheRebuildTask func(context.Context) error
 	AuditLogEnabled              bool
+	AuditLogSink                 audit.Sink
 	AuthPolicyEngine             *authpolicy.Engine
 	AdminIDs                     []spiffeid.ID
+	NamespacedAdminIDs           []spiffeid.ID
+	ShutdownDrainTimeout         time.Duration
 }
 
 type APIServers struct {
-	AgentServer       agentv1.AgentServer
-	BundleServer      bundlev1.BundleServer
-	DebugServer       debugv1_pb.DebugServer
-	EntryServer       entryv1.EntryServer
-	HealthServer      grpc_health_v1.HealthServer
-	SVIDServer        svidv1.SVIDServer
-	TrustDomainServer trustdomainv1.TrustDomainServer
+	AgentServer          agentv1.AgentServer
+	AgentBootstrapServer agentbootstrap.AgentBootstrapServer
+	AgentRenewalServer   agentrenewal.AgentRenewalServer
+	AgentCountServer     count.AgentCountServer
+	BundleServer         bundlev1.BundleServer
+	BundleCountServer    count.BundleCountServer
+	DebugServer          debugv1_pb.DebugServer
+	DenylistServer       denylist.DenylistServer
+	EntryServer          entryv1.EntryServer
+	EntryCountServer     count.EntryCountServer
+	EntryDNSNamesServer  entrydnsnames.EntryDNSNamesServer
+	EntryRestoreServer   entryrestore.EntryRestoreServer
+	EntrySyncServer      entrysync.EntrySyncServer
+	EntryTemplatesServer entrytemplate.EntryTemplatesServer
+	HealthServer         grpc_health_v1.HealthServer
+	JoinTokensServer     jointoken.JoinTokensServer
+	SVIDServer           svidv1.SVIDServer
+	TrustDomainServer    trustdomainv1.TrustDomainServer
 }
 
 // RateLimitConfig holds rate limiting configurations.
@@ -110,18 +208,27 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		return entrycache.BuildFromDataStore(ctx, c.Catalog.GetDataStore())
 	}
 
+	if c.LocalAddrMode == 0 {
+		c.LocalAddrMode = defaultLocalAddrMode
+	}
+
 	if c.CacheReloadInterval == 0 {
 		c.CacheReloadInterval = defaultCacheReloadInterval
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, c.Log, c.Clock, c.CacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, c.Catalog.GetDataStore(), c.Log, c.Clock, c.CacheReloadInterval)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Endpoints{
 		TCPAddr:                      c.TCPAddr,
+		TCPTLSPolicy:                 c.TCPTLSPolicy,
+		AdditionalTCPListeners:       c.AdditionalTCPListeners,
+		GRPC:                         c.GRPC,
 		LocalAddr:                    c.LocalAddr,
+		LocalAddrMode:                c.LocalAddrMode,
+		LocalAddrGroup:               c.LocalAddrGroup,
 		SVIDObserver:                 c.SVIDObserver,
 		TrustDomain:                  c.TrustDomain,
 		DataStore:                    c.Catalog.GetDataStore(),
@@ -132,8 +239,11 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		RateLimit:                    c.RateLimit,
 		EntryFetcherCacheRebuildTask: ef.RunRebuildCacheTask,
 		AuditLogEnabled:              c.AuditLogEnabled,
+		AuditLogSink:                 c.AuditLogSink,
 		AuthPolicyEngine:             c.AuthPolicyEngine,
 		AdminIDs:                     c.AdminIDs,
+		NamespacedAdminIDs:           c.namespacedAdminIDs(),
+		ShutdownDrainTimeout:         c.ShutdownDrainTimeout,
 	}, nil
 }
 
@@ -145,35 +255,30 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	e.Log.Debug("Initializing API endpoints")
 	unaryInterceptor, streamInterceptor := e.makeInterceptors()
 
-	tcpServer := e.createTCPServer(ctx, unaryInterceptor, streamInterceptor)
 	udsServer := e.createUDSServer(unaryInterceptor, streamInterceptor)
-
-	// New APIs
-	agentv1.RegisterAgentServer(tcpServer, e.APIServers.AgentServer)
-	agentv1.RegisterAgentServer(udsServer, e.APIServers.AgentServer)
-	bundlev1.RegisterBundleServer(tcpServer, e.APIServers.BundleServer)
-	bundlev1.RegisterBundleServer(udsServer, e.APIServers.BundleServer)
-	entryv1.RegisterEntryServer(tcpServer, e.APIServers.EntryServer)
-	entryv1.RegisterEntryServer(udsServer, e.APIServers.EntryServer)
-	svidv1.RegisterSVIDServer(tcpServer, e.APIServers.SVIDServer)
-	svidv1.RegisterSVIDServer(udsServer, e.APIServers.SVIDServer)
-	trustdomainv1.RegisterTrustDomainServer(tcpServer, e.APIServers.TrustDomainServer)
-	trustdomainv1.RegisterTrustDomainServer(udsServer, e.APIServers.TrustDomainServer)
+	e.registerAPIServers(udsServer)
 
 	// Register Health and Debug only on UDS server
 	grpc_health_v1.RegisterHealthServer(udsServer, e.APIServers.HealthServer)
 	debugv1_pb.RegisterDebugServer(udsServer, e.APIServers.DebugServer)
 
 	tasks := []func(context.Context) error{
-		func(ctx context.Context) error {
-			return e.runTCPServer(ctx, tcpServer)
-		},
 		func(ctx context.Context) error {
 			return e.runLocalAccess(ctx, udsServer)
 		},
 		e.EntryFetcherCacheRebuildTask,
 	}
 
+	tcpListeners := append([]TCPListener{{Addr: e.TCPAddr, TLSPolicy: e.TCPTLSPolicy}}, e.AdditionalTCPListeners...)
+	for _, tcpListener := range tcpListeners {
+		tcpListener := tcpListener
+		tcpServer := e.createTCPServer(ctx, tcpListener, unaryInterceptor, streamInterceptor)
+		e.registerAPIServers(tcpServer)
+		tasks = append(tasks, func(ctx context.Context) error {
+			return e.runTCPServer(ctx, tcpListener.Addr, tcpServer)
+		})
+	}
+
 	if e.BundleEndpointServer != nil {
 		tasks = append(tasks, e.BundleEndpointServer.ListenAndServe)
 	}
@@ -185,19 +290,55 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	return err
 }
 
-func (e *Endpoints) createTCPServer(ctx context.Context, unaryInterceptor grpc.UnaryServerInterceptor, streamInterceptor grpc.StreamServerInterceptor) *grpc.Server {
+// registerAPIServers registers the APIs served over both TCP and UDS.
+func (e *Endpoints) registerAPIServers(server *grpc.Server) {
+	agentv1.RegisterAgentServer(server, e.APIServers.AgentServer)
+	agentbootstrap.RegisterAgentBootstrapServer(server, e.APIServers.AgentBootstrapServer)
+	agentrenewal.RegisterAgentRenewalServer(server, e.APIServers.AgentRenewalServer)
+	count.RegisterAgentCountServer(server, e.APIServers.AgentCountServer)
+	bundlev1.RegisterBundleServer(server, e.APIServers.BundleServer)
+	count.RegisterBundleCountServer(server, e.APIServers.BundleCountServer)
+	denylist.RegisterDenylistServer(server, e.APIServers.DenylistServer)
+	entryv1.RegisterEntryServer(server, e.APIServers.EntryServer)
+	count.RegisterEntryCountServer(server, e.APIServers.EntryCountServer)
+	entrydnsnames.RegisterEntryDNSNamesServer(server, e.APIServers.EntryDNSNamesServer)
+	entryrestore.RegisterEntryRestoreServer(server, e.APIServers.EntryRestoreServer)
+	entrysync.RegisterEntrySyncServer(server, e.APIServers.EntrySyncServer)
+	entrytemplate.RegisterEntryTemplatesServer(server, e.APIServers.EntryTemplatesServer)
+	jointoken.RegisterJoinTokensServer(server, e.APIServers.JoinTokensServer)
+	svidv1.RegisterSVIDServer(server, e.APIServers.SVIDServer)
+	trustdomainv1.RegisterTrustDomainServer(server, e.APIServers.TrustDomainServer)
+}
+
+func (e *Endpoints) createTCPServer(ctx context.Context, tcpListener TCPListener, unaryInterceptor grpc.UnaryServerInterceptor, streamInterceptor grpc.StreamServerInterceptor) *grpc.Server {
 	tlsConfig := &tls.Config{ //nolint: gosec // False positive, getTLSConfig is setting MinVersion
-		GetConfigForClient: e.getTLSConfig(ctx),
+		GetConfigForClient: e.getTLSConfig(ctx, tcpListener),
 	}
 
-	return grpc.NewServer(
+	creds := credentials.NewTLS(tlsConfig)
+	if len(tcpListener.AttestationCAs) > 0 {
+		creds = newAttestationCredentials(creds, tcpListener.AttestationCAs)
+	}
+
+	options := []grpc.ServerOption{
 		grpc.UnaryInterceptor(unaryInterceptor),
 		grpc.StreamInterceptor(streamInterceptor),
-		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.Creds(creds),
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			MaxConnectionAge: defaultMaxConnectionAge,
+			Time:             e.GRPC.KeepaliveTime,
+			Timeout:          e.GRPC.KeepaliveTimeout,
 		}),
-	)
+	}
+	if e.GRPC.KeepaliveMinTime > 0 {
+		options = append(options, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             e.GRPC.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}))
+	}
+	options = append(options, e.GRPC.serverOptions()...)
+
+	return grpc.NewServer(options...)
 }
 
 func (e *Endpoints) createUDSServer(unaryInterceptor grpc.UnaryServerInterceptor, streamInterceptor grpc.StreamServerInterceptor) *grpc.Server {
@@ -211,13 +352,14 @@ func (e *Endpoints) createUDSServer(unaryInterceptor grpc.UnaryServerInterceptor
 	} else {
 		options = append(options, grpc.Creds(auth.UntrackedUDSCredentials()))
 	}
+	options = append(options, e.GRPC.serverOptions()...)
 
 	return grpc.NewServer(options...)
 }
 
 // runTCPServer will start the server and block until it exits or we are dying.
-func (e *Endpoints) runTCPServer(ctx context.Context, server *grpc.Server) error {
-	l, err := net.Listen(e.TCPAddr.Network(), e.TCPAddr.String())
+func (e *Endpoints) runTCPServer(ctx context.Context, addr *net.TCPAddr, server *grpc.Server) error {
+	l, err := net.Listen(addr.Network(), addr.String())
 	if err != nil {
 		return err
 	}
@@ -237,7 +379,7 @@ func (e *Endpoints) runTCPServer(ctx context.Context, server *grpc.Server) error
 		return err
 	case <-ctx.Done():
 		log.Info("Stopping Server APIs")
-		server.Stop()
+		e.stopServer(log, server)
 		<-errChan
 		log.Info("Server APIs have stopped")
 		return nil
@@ -280,34 +422,69 @@ func (e *Endpoints) runLocalAccess(ctx context.Context, server *grpc.Server) err
 		return err
 	case <-ctx.Done():
 		log.Info("Stopping Server APIs")
-		server.Stop()
+		e.stopServer(log, server)
 		<-errChan
 		log.Info("Server APIs have stopped")
 		return nil
 	}
 }
 
+// stopServer stops the gRPC server. If a drain timeout is configured, the
+// server stops accepting new RPCs and waits for in-flight RPCs to finish,
+// cancelling any that remain when the timeout elapses.
+func (e *Endpoints) stopServer(log logrus.FieldLogger, server *grpc.Server) {
+	if e.ShutdownDrainTimeout <= 0 {
+		server.Stop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(e.ShutdownDrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		log.WithField(telemetry.Timeout, e.ShutdownDrainTimeout).Warn("Timed out draining in-flight RPCs; cancelling them")
+		server.Stop()
+		<-done
+	}
+}
+
 // getTLSConfig returns a TLS Config hook for the gRPC server
-func (e *Endpoints) getTLSConfig(ctx context.Context) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+func (e *Endpoints) getTLSConfig(ctx context.Context, tcpListener TCPListener) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	// Not all server APIs required a client certificate. Though if one is
+	// presented, verify it.
+	clientAuth := tls.VerifyClientCertIfGiven
+	if tcpListener.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
 	return func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
 		certs, roots, err := e.getCerts(ctx)
 		if err != nil {
 			e.Log.WithError(err).WithField(telemetry.Address, hello.Conn.RemoteAddr().String()).Error("Could not generate TLS config for gRPC client")
 			return nil, err
 		}
+		for _, ca := range tcpListener.AttestationCAs {
+			roots.AddCert(ca)
+		}
 
-		return &tls.Config{
-			// Not all server APIs required a client certificate. Though if one
-			// is presented, verify it.
-			ClientAuth: tls.VerifyClientCertIfGiven,
+		tlsConfig := &tls.Config{ //nolint: gosec // MinVersion is set by the TLS policy
+			ClientAuth: clientAuth,
 
 			Certificates: certs,
 			ClientCAs:    roots,
 
-			MinVersion: tls.VersionTLS12,
-
 			NextProtos: []string{http2.NextProtoTLS},
-		}, nil
+		}
+		tlspolicy.ApplyPolicy(tlsConfig, tcpListener.TLSPolicy)
+		return tlsConfig, nil
 	}
 }
 
@@ -354,5 +531,5 @@ func (e *Endpoints) getCerts(ctx context.Context) ([]tls.Certificate, *x509.Cert
 func (e *Endpoints) makeInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
 	log := e.Log.WithField(telemetry.SubsystemName, "api")
 
-	return middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, clock.New(), e.RateLimit, e.AuthPolicyEngine, e.AuditLogEnabled, e.AdminIDs))
+	return middleware.Interceptors(Middleware(log, e.Metrics, e.DataStore, clock.New(), e.RateLimit, e.AuthPolicyEngine, e.AuditLogEnabled, e.AuditLogSink, e.AdminIDs, e.NamespacedAdminIDs))
 }
@@ -6,9 +6,9 @@ package endpoints
 import (
 	"fmt"
 	"net"
-	"os"
 
 	"github.com/spiffe/spire/pkg/common/peertracker"
+	"github.com/spiffe/spire/pkg/common/util"
 )
 
 func (e *Endpoints) listen() (net.Listener, error) {
@@ -28,6 +28,6 @@ func (e *Endpoints) listenWithAuditLog() (*peertracker.Listener, error) {
 
 func (e *Endpoints) restrictLocalAddr() error {
 	// Restrict access to the UDS to processes running as the same user or
-	// group as the server.
-	return os.Chmod(e.LocalAddr.String(), 0770)
+	// group as the server, unless configured otherwise.
+	return util.SetSocketPermissions(e.LocalAddr.String(), e.LocalAddrMode, e.LocalAddrGroup)
 }
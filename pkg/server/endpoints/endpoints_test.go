@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"os"
 	"reflect"
 	"sync"
 	"testing"
@@ -20,6 +21,7 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/ca"
@@ -27,6 +29,15 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
+	"github.com/spiffe/spire/proto/private/server/agentbootstrap"
+	"github.com/spiffe/spire/proto/private/server/agentrenewal"
+	"github.com/spiffe/spire/proto/private/server/count"
+	"github.com/spiffe/spire/proto/private/server/denylist"
+	"github.com/spiffe/spire/proto/private/server/entrydnsnames"
+	"github.com/spiffe/spire/proto/private/server/entryrestore"
+	"github.com/spiffe/spire/proto/private/server/entrysync"
+	"github.com/spiffe/spire/proto/private/server/entrytemplate"
+	"github.com/spiffe/spire/proto/private/server/jointoken"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -41,6 +52,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 )
@@ -106,6 +118,7 @@ func TestNew(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, tcpAddr, endpoints.TCPAddr)
 	assert.Equal(t, localAddr, endpoints.LocalAddr)
+	assert.Equal(t, os.FileMode(0770), endpoints.LocalAddrMode)
 	assert.Equal(t, svidObserver, endpoints.SVIDObserver)
 	assert.Equal(t, testTD, endpoints.TrustDomain)
 	assert.NotNil(t, endpoints.APIServers.AgentServer)
@@ -173,6 +186,17 @@ func TestNewErrorCreatingAuthorizedEntryFetcher(t *testing.T) {
 	assert.Nil(t, endpoints)
 }
 
+func TestGRPCConfigServerOptions(t *testing.T) {
+	assert.Empty(t, GRPCConfig{}.serverOptions())
+	assert.Len(t, GRPCConfig{MaxRecvMsgSize: 1024}.serverOptions(), 1)
+	assert.Len(t, GRPCConfig{
+		KeepaliveTime:        time.Minute,
+		MaxConcurrentStreams: 10,
+		MaxRecvMsgSize:       1024,
+		MaxSendMsgSize:       1024,
+	}.serverOptions(), 3)
+}
+
 func TestListenAndServe(t *testing.T) {
 	ctx := context.Background()
 	ca := testca.New(t, testTD)
@@ -181,10 +205,22 @@ func TestListenAndServe(t *testing.T) {
 	adminSVID := ca.CreateX509SVID(adminID)
 	downstreamSVID := ca.CreateX509SVID(downstreamID)
 
+	// Externally issued certificate claiming the admin ID
+	externalCA := testca.New(t, testTD)
+	externalAdminSVID := externalCA.CreateX509SVID(adminID)
+
 	listener, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
 	require.NoError(t, listener.Close())
 
+	additionalListener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	require.NoError(t, additionalListener.Close())
+
+	attestationListener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	require.NoError(t, attestationListener.Close())
+
 	ds := fakedatastore.New(t)
 	log, _ := test.NewNullLogger()
 	metrics := fakemetrics.New()
@@ -196,26 +232,49 @@ func TestListenAndServe(t *testing.T) {
 		return entrycache.BuildFromDataStore(ctx, ds)
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(context.Background(), buildCacheFn, log, clk, defaultCacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(context.Background(), buildCacheFn, ds, log, clk, defaultCacheReloadInterval)
 	require.NoError(t, err)
 
 	pe, err := authpolicy.DefaultAuthPolicy(ctx)
 	require.NoError(t, err)
 
 	endpoints := Endpoints{
-		TCPAddr:      listener.Addr().(*net.TCPAddr),
-		LocalAddr:    getLocalAddr(t),
-		SVIDObserver: newSVIDObserver(serverSVID),
-		TrustDomain:  testTD,
-		DataStore:    ds,
+		TCPAddr: listener.Addr().(*net.TCPAddr),
+		AdditionalTCPListeners: []TCPListener{
+			{
+				Addr:              additionalListener.Addr().(*net.TCPAddr),
+				TLSPolicy:         tlspolicy.Policy{MinVersion: tls.VersionTLS13},
+				RequireClientCert: true,
+			},
+			{
+				Addr:           attestationListener.Addr().(*net.TCPAddr),
+				AttestationCAs: externalCA.X509Authorities(),
+			},
+		},
+		LocalAddr:     getLocalAddr(t),
+		LocalAddrMode: 0770,
+		SVIDObserver:  newSVIDObserver(serverSVID),
+		TrustDomain:   testTD,
+		DataStore:     ds,
 		APIServers: APIServers{
-			AgentServer:       &agentv1.UnimplementedAgentServer{},
-			BundleServer:      &bundlev1.UnimplementedBundleServer{},
-			DebugServer:       &debugv1.UnimplementedDebugServer{},
-			EntryServer:       &entryv1.UnimplementedEntryServer{},
-			HealthServer:      &grpc_health_v1.UnimplementedHealthServer{},
-			SVIDServer:        &svidv1.UnimplementedSVIDServer{},
-			TrustDomainServer: &trustdomainv1.UnimplementedTrustDomainServer{},
+			AgentServer:          &agentv1.UnimplementedAgentServer{},
+			AgentBootstrapServer: &agentbootstrap.UnimplementedAgentBootstrapServer{},
+			AgentRenewalServer:   &agentrenewal.UnimplementedAgentRenewalServer{},
+			AgentCountServer:     &count.UnimplementedAgentCountServer{},
+			BundleServer:         &bundlev1.UnimplementedBundleServer{},
+			BundleCountServer:    &count.UnimplementedBundleCountServer{},
+			DebugServer:          &debugv1.UnimplementedDebugServer{},
+			DenylistServer:       &denylist.UnimplementedDenylistServer{},
+			EntryServer:          &entryv1.UnimplementedEntryServer{},
+			EntryCountServer:     &count.UnimplementedEntryCountServer{},
+			EntryDNSNamesServer:  &entrydnsnames.UnimplementedEntryDNSNamesServer{},
+			EntryRestoreServer:   &entryrestore.UnimplementedEntryRestoreServer{},
+			EntrySyncServer:      &entrysync.UnimplementedEntrySyncServer{},
+			EntryTemplatesServer: &entrytemplate.UnimplementedEntryTemplatesServer{},
+			HealthServer:         &grpc_health_v1.UnimplementedHealthServer{},
+			JoinTokensServer:     &jointoken.UnimplementedJoinTokensServer{},
+			SVIDServer:           &svidv1.UnimplementedSVIDServer{},
+			TrustDomainServer:    &trustdomainv1.UnimplementedTrustDomainServer{},
 		},
 		BundleEndpointServer:         bundleEndpointServer,
 		Log:                          log,
@@ -284,9 +343,64 @@ func TestListenAndServe(t *testing.T) {
 		}
 	})
 
+	t.Run("Additional Listener", func(t *testing.T) {
+		addr := endpoints.AdditionalTCPListeners[0].Addr.String()
+
+		// Clients must present a certificate
+		noauthTLSConn, err := tls.Dial("tcp", addr, tlsconfig.TLSClientConfig(ca.X509Bundle(), tlsconfig.AuthorizeID(serverID)))
+		require.NoError(t, err)
+		defer noauthTLSConn.Close()
+		_, err = noauthTLSConn.Read(make([]byte, 1))
+		require.Error(t, err)
+
+		// Clients must support the minimum TLS version
+		tls12Config := tlsconfig.MTLSClientConfig(agentSVID, ca.X509Bundle(), tlsconfig.AuthorizeID(serverID))
+		tls12Config.MaxVersion = tls.VersionTLS12
+		_, err = tls.Dial("tcp", addr, tls12Config)
+		require.Error(t, err)
+
+		conn, err := grpc.DialContext(ctx, addr,
+			grpc.WithBlock(),
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsconfig.MTLSClientConfig(adminSVID, ca.X509Bundle(), tlsconfig.AuthorizeID(serverID)))),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = agentv1.NewAgentClient(conn).ListAgents(ctx, &agentv1.ListAgentsRequest{})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("Attestation Listener", func(t *testing.T) {
+		addr := endpoints.AdditionalTCPListeners[1].Addr.String()
+
+		listAgents := func(svid *x509svid.SVID) error {
+			conn, err := grpc.DialContext(ctx, addr,
+				grpc.WithBlock(),
+				grpc.WithTransportCredentials(credentials.NewTLS(tlsconfig.MTLSClientConfig(svid, ca.X509Bundle(), tlsconfig.AuthorizeID(serverID)))),
+			)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = agentv1.NewAgentClient(conn).ListAgents(ctx, &agentv1.ListAgentsRequest{})
+			return err
+		}
+
+		// SPIRE issued certificates are still authenticated
+		require.Equal(t, codes.Unimplemented, status.Code(listAgents(adminSVID)))
+
+		// Externally issued certificates are not authenticated with their SPIFFE ID
+		require.Equal(t, codes.PermissionDenied, status.Code(listAgents(externalAdminSVID)))
+	})
+
 	t.Run("Agent", func(t *testing.T) {
 		testAgentAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
+	t.Run("AgentBootstrap", func(t *testing.T) {
+		testAgentBootstrapAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("AgentRenewal", func(t *testing.T) {
+		testAgentRenewalAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
 	t.Run("Debug", func(t *testing.T) {
 		testDebugAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
@@ -299,6 +413,27 @@ func TestListenAndServe(t *testing.T) {
 	t.Run("Entry", func(t *testing.T) {
 		testEntryAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
+	t.Run("EntryDNSNames", func(t *testing.T) {
+		testEntryDNSNamesAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("EntryRestore", func(t *testing.T) {
+		testEntryRestoreAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("EntryTemplates", func(t *testing.T) {
+		testEntryTemplatesAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("EntrySync", func(t *testing.T) {
+		testEntrySyncAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("Denylist", func(t *testing.T) {
+		testDenylistAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("JoinTokens", func(t *testing.T) {
+		testJoinTokensAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
+	t.Run("Count", func(t *testing.T) {
+		testCountAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
+	})
 	t.Run("SVID", func(t *testing.T) {
 		testSVIDAPI(ctx, t, localConn, noauthConn, agentConn, adminConn, downstreamConn)
 	})
@@ -418,6 +553,359 @@ func testAgentAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentC
 	})
 }
 
+func testAgentBootstrapAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbootstrap.NewAgentBootstrapClient(udsConn), map[string]bool{
+			"MintAgentX509SVID": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbootstrap.NewAgentBootstrapClient(noauthConn), map[string]bool{
+			"MintAgentX509SVID": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbootstrap.NewAgentBootstrapClient(agentConn), map[string]bool{
+			"MintAgentX509SVID": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbootstrap.NewAgentBootstrapClient(adminConn), map[string]bool{
+			"MintAgentX509SVID": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbootstrap.NewAgentBootstrapClient(downstreamConn), map[string]bool{
+			"MintAgentX509SVID": false,
+		})
+	})
+}
+
+func testAgentRenewalAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, agentrenewal.NewAgentRenewalClient(udsConn), map[string]bool{
+			"RequestAgentRenewal": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, agentrenewal.NewAgentRenewalClient(noauthConn), map[string]bool{
+			"RequestAgentRenewal": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, agentrenewal.NewAgentRenewalClient(agentConn), map[string]bool{
+			"RequestAgentRenewal": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, agentrenewal.NewAgentRenewalClient(adminConn), map[string]bool{
+			"RequestAgentRenewal": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, agentrenewal.NewAgentRenewalClient(downstreamConn), map[string]bool{
+			"RequestAgentRenewal": false,
+		})
+	})
+}
+
+func testEntryDNSNamesAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydnsnames.NewEntryDNSNamesClient(udsConn), map[string]bool{
+			"GetDNSNamePatterns": true,
+			"SetDNSNamePatterns": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydnsnames.NewEntryDNSNamesClient(noauthConn), map[string]bool{
+			"GetDNSNamePatterns": false,
+			"SetDNSNamePatterns": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydnsnames.NewEntryDNSNamesClient(agentConn), map[string]bool{
+			"GetDNSNamePatterns": false,
+			"SetDNSNamePatterns": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydnsnames.NewEntryDNSNamesClient(adminConn), map[string]bool{
+			"GetDNSNamePatterns": true,
+			"SetDNSNamePatterns": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydnsnames.NewEntryDNSNamesClient(downstreamConn), map[string]bool{
+			"GetDNSNamePatterns": false,
+			"SetDNSNamePatterns": false,
+		})
+	})
+}
+
+func testEntryRestoreAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, entryrestore.NewEntryRestoreClient(udsConn), map[string]bool{
+			"ListDeletedEntries": true,
+			"RestoreEntry":       true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, entryrestore.NewEntryRestoreClient(noauthConn), map[string]bool{
+			"ListDeletedEntries": false,
+			"RestoreEntry":       false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, entryrestore.NewEntryRestoreClient(agentConn), map[string]bool{
+			"ListDeletedEntries": false,
+			"RestoreEntry":       false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entryrestore.NewEntryRestoreClient(adminConn), map[string]bool{
+			"ListDeletedEntries": true,
+			"RestoreEntry":       true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, entryrestore.NewEntryRestoreClient(downstreamConn), map[string]bool{
+			"ListDeletedEntries": false,
+			"RestoreEntry":       false,
+		})
+	})
+}
+
+func testEntryTemplatesAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplate.NewEntryTemplatesClient(udsConn), map[string]bool{
+			"CreateEntryTemplate": true,
+			"ListEntryTemplates":  true,
+			"DeleteEntryTemplate": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplate.NewEntryTemplatesClient(noauthConn), map[string]bool{
+			"CreateEntryTemplate": false,
+			"ListEntryTemplates":  false,
+			"DeleteEntryTemplate": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplate.NewEntryTemplatesClient(agentConn), map[string]bool{
+			"CreateEntryTemplate": false,
+			"ListEntryTemplates":  false,
+			"DeleteEntryTemplate": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplate.NewEntryTemplatesClient(adminConn), map[string]bool{
+			"CreateEntryTemplate": true,
+			"ListEntryTemplates":  true,
+			"DeleteEntryTemplate": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, entrytemplate.NewEntryTemplatesClient(downstreamConn), map[string]bool{
+			"CreateEntryTemplate": false,
+			"ListEntryTemplates":  false,
+			"DeleteEntryTemplate": false,
+		})
+	})
+}
+
+func testDenylistAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, denylist.NewDenylistClient(udsConn), map[string]bool{
+			"ListPatterns":  true,
+			"AddPattern":    true,
+			"RemovePattern": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, denylist.NewDenylistClient(noauthConn), map[string]bool{
+			"ListPatterns":  false,
+			"AddPattern":    false,
+			"RemovePattern": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, denylist.NewDenylistClient(agentConn), map[string]bool{
+			"ListPatterns":  false,
+			"AddPattern":    false,
+			"RemovePattern": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, denylist.NewDenylistClient(adminConn), map[string]bool{
+			"ListPatterns":  true,
+			"AddPattern":    true,
+			"RemovePattern": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, denylist.NewDenylistClient(downstreamConn), map[string]bool{
+			"ListPatterns":  false,
+			"AddPattern":    false,
+			"RemovePattern": false,
+		})
+	})
+}
+
+func testJoinTokensAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, jointoken.NewJoinTokensClient(udsConn), map[string]bool{
+			"ListJoinTokens":  true,
+			"RevokeJoinToken": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, jointoken.NewJoinTokensClient(noauthConn), map[string]bool{
+			"ListJoinTokens":  false,
+			"RevokeJoinToken": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, jointoken.NewJoinTokensClient(agentConn), map[string]bool{
+			"ListJoinTokens":  false,
+			"RevokeJoinToken": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, jointoken.NewJoinTokensClient(adminConn), map[string]bool{
+			"ListJoinTokens":  true,
+			"RevokeJoinToken": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, jointoken.NewJoinTokensClient(downstreamConn), map[string]bool{
+			"ListJoinTokens":  false,
+			"RevokeJoinToken": false,
+		})
+	})
+}
+
+func testEntrySyncAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, entrysync.NewEntrySyncClient(udsConn), map[string]bool{
+			"SyncAuthorizedEntries": false,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, entrysync.NewEntrySyncClient(noauthConn), map[string]bool{
+			"SyncAuthorizedEntries": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, entrysync.NewEntrySyncClient(agentConn), map[string]bool{
+			"SyncAuthorizedEntries": true,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entrysync.NewEntrySyncClient(adminConn), map[string]bool{
+			"SyncAuthorizedEntries": false,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, entrysync.NewEntrySyncClient(downstreamConn), map[string]bool{
+			"SyncAuthorizedEntries": false,
+		})
+	})
+}
+
+func testCountAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
+	t.Run("UDS", func(t *testing.T) {
+		testAuthorization(ctx, t, count.NewEntryCountClient(udsConn), map[string]bool{
+			"CountFilteredEntries": true,
+		})
+		testAuthorization(ctx, t, count.NewAgentCountClient(udsConn), map[string]bool{
+			"CountFilteredAgents": true,
+		})
+		testAuthorization(ctx, t, count.NewBundleCountClient(udsConn), map[string]bool{
+			"CountFederatedBundles": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, count.NewEntryCountClient(noauthConn), map[string]bool{
+			"CountFilteredEntries": false,
+		})
+		testAuthorization(ctx, t, count.NewAgentCountClient(noauthConn), map[string]bool{
+			"CountFilteredAgents": false,
+		})
+		testAuthorization(ctx, t, count.NewBundleCountClient(noauthConn), map[string]bool{
+			"CountFederatedBundles": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, count.NewEntryCountClient(agentConn), map[string]bool{
+			"CountFilteredEntries": false,
+		})
+		testAuthorization(ctx, t, count.NewAgentCountClient(agentConn), map[string]bool{
+			"CountFilteredAgents": false,
+		})
+		testAuthorization(ctx, t, count.NewBundleCountClient(agentConn), map[string]bool{
+			"CountFederatedBundles": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, count.NewEntryCountClient(adminConn), map[string]bool{
+			"CountFilteredEntries": true,
+		})
+		testAuthorization(ctx, t, count.NewAgentCountClient(adminConn), map[string]bool{
+			"CountFilteredAgents": true,
+		})
+		testAuthorization(ctx, t, count.NewBundleCountClient(adminConn), map[string]bool{
+			"CountFederatedBundles": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, count.NewEntryCountClient(downstreamConn), map[string]bool{
+			"CountFilteredEntries": false,
+		})
+		testAuthorization(ctx, t, count.NewAgentCountClient(downstreamConn), map[string]bool{
+			"CountFilteredAgents": false,
+		})
+		testAuthorization(ctx, t, count.NewBundleCountClient(downstreamConn), map[string]bool{
+			"CountFederatedBundles": false,
+		})
+	})
+}
+
 func testHealthAPI(ctx context.Context, t *testing.T, udsConn, noauthConn, agentConn, adminConn, downstreamConn *grpc.ClientConn) {
 	t.Run("UDS", func(t *testing.T) {
 		testAuthorization(ctx, t, grpc_health_v1.NewHealthClient(udsConn), map[string]bool{
@@ -857,3 +1345,93 @@ func (o *svidObserver) State() svid.State {
 		Key:  o.svid.PrivateKey,
 	}
 }
+
+func TestStopServer(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		drainTimeout time.Duration
+		release      bool
+		expectCode   codes.Code
+		expectWarn   bool
+	}{
+		{
+			name:       "no drain timeout cancels in-flight RPCs",
+			expectCode: codes.Unavailable,
+		},
+		{
+			name:         "in-flight RPCs finish within the drain timeout",
+			drainTimeout: time.Minute,
+			release:      true,
+			expectCode:   codes.OK,
+		},
+		{
+			name:         "in-flight RPCs are cancelled after the drain timeout",
+			drainTimeout: 10 * time.Millisecond,
+			expectCode:   codes.Unavailable,
+			expectWarn:   true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			e := &Endpoints{ShutdownDrainTimeout: tt.drainTimeout}
+
+			healthServer := &blockingHealthServer{
+				entered: make(chan struct{}),
+				release: make(chan struct{}),
+			}
+			server := grpc.NewServer()
+			grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+			listener, err := net.Listen("tcp", "localhost:0")
+			require.NoError(t, err)
+			go func() { _ = server.Serve(listener) }()
+
+			conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			rpcErr := make(chan error, 1)
+			go func() {
+				_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+				rpcErr <- err
+			}()
+			<-healthServer.entered
+
+			stopped := make(chan struct{})
+			go func() {
+				e.stopServer(log, server)
+				close(stopped)
+			}()
+			if tt.release {
+				close(healthServer.release)
+			}
+			<-stopped
+
+			require.Equal(t, tt.expectCode, status.Code(<-rpcErr))
+			if tt.expectWarn {
+				require.Len(t, hook.AllEntries(), 1)
+				require.Equal(t, "Timed out draining in-flight RPCs; cancelling them", hook.LastEntry().Message)
+			} else {
+				require.Empty(t, hook.AllEntries())
+			}
+		})
+	}
+}
+
+type blockingHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingHealthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	close(s.entered)
+	select {
+	case <-s.release:
+		return &grpc_health_v1.HealthCheckResponse{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -10,23 +10,33 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
+	"github.com/spiffe/spire/pkg/server/datastore"
 )
 
-var _ api.AuthorizedEntryFetcher = (*AuthorizedEntryFetcherWithFullCache)(nil)
+var (
+	_ api.AuthorizedEntryFetcher  = (*AuthorizedEntryFetcherWithFullCache)(nil)
+	_ api.AuthorizedEntryNotifier = (*AuthorizedEntryFetcherWithFullCache)(nil)
+)
 
 type entryCacheBuilderFn func(ctx context.Context) (entrycache.Cache, error)
 
 type AuthorizedEntryFetcherWithFullCache struct {
 	buildCache          entryCacheBuilderFn
 	cache               entrycache.Cache
+	ds                  datastore.DataStore
 	clk                 clock.Clock
 	log                 logrus.FieldLogger
 	mu                  sync.RWMutex
 	cacheReloadInterval time.Duration
+
+	// changed is closed and replaced every time the cache is rebuilt.
+	// Protected by mu.
+	changed chan struct{}
 }
 
-func NewAuthorizedEntryFetcherWithFullCache(ctx context.Context, buildCache entryCacheBuilderFn, log logrus.FieldLogger, clk clock.Clock, cacheReloadInterval time.Duration) (*AuthorizedEntryFetcherWithFullCache, error) {
+func NewAuthorizedEntryFetcherWithFullCache(ctx context.Context, buildCache entryCacheBuilderFn, ds datastore.DataStore, log logrus.FieldLogger, clk clock.Clock, cacheReloadInterval time.Duration) (*AuthorizedEntryFetcherWithFullCache, error) {
 	log.Info("Building in-memory entry cache")
 	cache, err := buildCache(ctx)
 	if err != nil {
@@ -37,16 +47,38 @@ func NewAuthorizedEntryFetcherWithFullCache(ctx context.Context, buildCache entr
 	return &AuthorizedEntryFetcherWithFullCache{
 		buildCache:          buildCache,
 		cache:               cache,
+		ds:                  ds,
 		clk:                 clk,
 		log:                 log,
 		cacheReloadInterval: cacheReloadInterval,
+		changed:             make(chan struct{}),
 	}, nil
 }
 
 func (a *AuthorizedEntryFetcherWithFullCache) FetchAuthorizedEntries(ctx context.Context, agentID spiffeid.ID) ([]*types.Entry, error) {
+	a.mu.RLock()
+	cache := a.cache
+	a.mu.RUnlock()
+
+	if cache.HasAgent(agentID) {
+		return cache.GetAuthorizedEntries(agentID), nil
+	}
+
+	// The Agent attested after the cache was built, so its node aliases are
+	// matched against its current selectors until the next rebuild.
+	selectors, err := a.ds.GetNodeSelectors(dscache.WithCache(ctx), agentID.String(), datastore.RequireCurrent)
+	if err != nil {
+		return nil, err
+	}
+	return cache.GetAuthorizedEntriesWithSelectors(agentID, api.ProtoFromSelectors(selectors)), nil
+}
+
+// AuthorizedEntriesChanged returns a channel that is closed the next time the
+// in-memory entry cache is rebuilt.
+func (a *AuthorizedEntryFetcherWithFullCache) AuthorizedEntriesChanged() <-chan struct{} {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return a.cache.GetAuthorizedEntries(agentID), nil
+	return a.changed
 }
 
 // RunRebuildCacheTask starts a ticker which rebuilds the in-memory entry cache.
@@ -58,6 +90,8 @@ func (a *AuthorizedEntryFetcherWithFullCache) RunRebuildCacheTask(ctx context.Co
 		} else {
 			a.mu.Lock()
 			a.cache = cache
+			close(a.changed)
+			a.changed = make(chan struct{})
 			a.mu.Unlock()
 		}
 	}
@@ -10,9 +10,12 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,6 +34,14 @@ func (sef *staticEntryCache) GetAuthorizedEntries(agentID spiffeid.ID) []*types.
 	return sef.entries[agentID]
 }
 
+func (sef *staticEntryCache) GetAuthorizedEntriesWithSelectors(agentID spiffeid.ID, selectors []*types.Selector) []*types.Entry {
+	return sef.entries[agentID]
+}
+
+func (sef *staticEntryCache) HasAgent(agentID spiffeid.ID) bool {
+	return true
+}
+
 func newStaticEntryCache(entries map[spiffeid.ID][]*types.Entry) *staticEntryCache {
 	return &staticEntryCache{
 		entries: entries,
@@ -46,7 +57,7 @@ func TestNewAuthorizedEntryFetcherWithFullCache(t *testing.T) {
 		return newStaticEntryCache(entries), nil
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, log, clk, defaultCacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, fakedatastore.New(t), log, clk, defaultCacheReloadInterval)
 	assert.NoError(t, err)
 	assert.NotNil(t, ef)
 }
@@ -60,7 +71,7 @@ func TestNewAuthorizedEntryFetcherWithFullCacheErrorBuildingCache(t *testing.T)
 		return nil, errors.New("some cache build error")
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, log, clk, defaultCacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, fakedatastore.New(t), log, clk, defaultCacheReloadInterval)
 	assert.Error(t, err)
 	assert.Nil(t, ef)
 }
@@ -80,7 +91,7 @@ func TestFetchRegistrationEntries(t *testing.T) {
 		return newStaticEntryCache(entries), nil
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, log, clk, defaultCacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCacheFn, fakedatastore.New(t), log, clk, defaultCacheReloadInterval)
 	require.NoError(t, err)
 	require.NotNil(t, ef)
 
@@ -89,6 +100,53 @@ func TestFetchRegistrationEntries(t *testing.T) {
 	assert.Equal(t, expected, entries)
 }
 
+func TestFetchRegistrationEntriesForAgentAttestedAfterBuild(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := dscache.New(fakedatastore.New(t), clk, time.Minute)
+	agentID := spiffeid.RequireFromPath(trustDomain, "/spire/agent/test/1")
+
+	alias, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  spiffeid.RequireFromPath(trustDomain, "/spire/server").String(),
+		SpiffeId:  spiffeid.RequireFromPath(trustDomain, "/alias").String(),
+		Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+	})
+	require.NoError(t, err)
+	workload, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  alias.SpiffeId,
+		SpiffeId:  spiffeid.RequireFromPath(trustDomain, "/workload").String(),
+		Selectors: []*common.Selector{{Type: "b", Value: "2"}},
+	})
+	require.NoError(t, err)
+	expected, err := api.RegistrationEntriesToProto([]*common.RegistrationEntry{alias, workload})
+	require.NoError(t, err)
+
+	buildCache := func(ctx context.Context) (entrycache.Cache, error) {
+		return entrycache.BuildFromDataStore(ctx, ds)
+	}
+
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, ds, log, clk, defaultCacheReloadInterval)
+	require.NoError(t, err)
+
+	// The agent attests after the cache was built
+	_, err = ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            agentID.String(),
+		CertSerialNumber:    "1",
+		CertNotAfter:        clk.Now().Add(time.Hour).Unix(),
+		AttestationDataType: "test",
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.SetNodeSelectors(dscache.WithCache(ctx), agentID.String(), []*common.Selector{
+		{Type: "a", Value: "1"},
+		{Type: "c", Value: "3"},
+	}))
+
+	entries, err := ef.FetchAuthorizedEntries(ctx, agentID)
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, expected, entries)
+}
+
 func TestRunRebuildCacheTask(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	watchErr := make(chan error, 1)
@@ -149,7 +207,7 @@ func TestRunRebuildCacheTask(t *testing.T) {
 		}
 	}
 
-	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, log, clk, defaultCacheReloadInterval)
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, fakedatastore.New(t), log, clk, defaultCacheReloadInterval)
 	require.NoError(t, err)
 	require.NotNil(t, ef)
 
@@ -192,10 +250,16 @@ func TestRunRebuildCacheTask(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, entries)
 	buildCacheErr := errors.New("some cache build error")
+	changed := ef.AuthorizedEntriesChanged()
 	sendResult(req, nil, buildCacheErr)
 
 	// Verify that rebuild task gracefully handles downstream errors and retries after the reload interval elapses again
 	req = waitForRequest()
+	select {
+	case <-changed:
+		t.Fatal("entries reported as changed after a failed rebuild")
+	default:
+	}
 	entries, err = ef.FetchAuthorizedEntries(ctx, agentID)
 	assert.NoError(t, err)
 	assert.Empty(t, entries)
@@ -209,6 +273,11 @@ func TestRunRebuildCacheTask(t *testing.T) {
 	// When the rebuild task is able to complete successfully,
 	// the cache should now contain the Agent's new authorized entries
 	req = waitForRequest()
+	select {
+	case <-changed:
+	default:
+		t.Fatal("entries not reported as changed after a rebuild")
+	}
 	entries, err = ef.FetchAuthorizedEntries(ctx, agentID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedEntries, entries)
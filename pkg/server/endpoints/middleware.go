@@ -8,6 +8,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	"github.com/spiffe/spire/pkg/server/api/limits"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
@@ -22,17 +23,19 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, rlConf RateLimitConfig, policyEngine *authpolicy.Engine, auditLogEnabled bool, adminIDs []spiffeid.ID) middleware.Middleware {
+func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, rlConf RateLimitConfig, policyEngine *authpolicy.Engine, auditLogEnabled bool, auditLogSink audit.Sink, adminIDs, namespacedAdminIDs []spiffeid.ID) middleware.Middleware {
 	chain := []middleware.Middleware{
 		middleware.WithLogger(log),
+		middleware.WithRequestID(),
 		middleware.WithMetrics(metrics),
-		middleware.WithAuthorization(policyEngine, EntryFetcher(ds), AgentAuthorizer(log, ds, clk), adminIDs),
+		middleware.WithAuthorization(policyEngine, EntryFetcher(ds), AgentAuthorizer(log, ds, clk), adminIDs, namespacedAdminIDs),
 		middleware.WithRateLimits(RateLimits(rlConf), metrics),
+		middleware.WithAgentVersions(metrics, clk),
 	}
 
 	if auditLogEnabled {
 		// Add audit log with local tracking enabled
-		chain = append(chain, middleware.WithAuditLog(true))
+		chain = append(chain, middleware.WithAuditLogSink(true, auditLogSink, clk))
 	}
 
 	return middleware.Chain(
@@ -68,7 +71,7 @@ func AgentAuthorizer(log logrus.FieldLogger, ds datastore.DataStore, clk clock.C
 			}); err == nil {
 				st = detailed
 			}
-			return st.Err()
+			return api.WithReason(st.Err(), reason.String())
 		}
 
 		if clk.Now().After(agentSVID.NotAfter) {
@@ -97,6 +100,8 @@ func AgentAuthorizer(log logrus.FieldLogger, ds datastore.DataStore, clk clock.C
 				SpiffeId:         attestedNode.SpiffeId,
 				CertNotAfter:     attestedNode.NewCertNotAfter,
 				CertSerialNumber: attestedNode.NewCertSerialNumber,
+				CanReattest:      attestedNode.CanReattest,
+				AttestedAt:       attestedNode.AttestedAt,
 			}, nil)
 			if err != nil {
 				log.WithFields(logrus.Fields{
@@ -168,6 +173,24 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.agent.v1.Agent/AttestAgent":                                   attestLimit,
 		"/spire.api.server.agent.v1.Agent/RenewAgent":                                    csrLimit,
 		"/spire.api.server.agent.v1.Agent/CreateJoinToken":                               noLimit,
+		"/spire.private.server.agentbootstrap.AgentBootstrap/MintAgentX509SVID":          noLimit,
+		"/spire.private.server.agentrenewal.AgentRenewal/RequestAgentRenewal":            noLimit,
+		"/spire.private.server.entrydnsnames.EntryDNSNames/GetDNSNamePatterns":           noLimit,
+		"/spire.private.server.entrydnsnames.EntryDNSNames/SetDNSNamePatterns":           noLimit,
+		"/spire.private.server.entryrestore.EntryRestore/ListDeletedEntries":             noLimit,
+		"/spire.private.server.entryrestore.EntryRestore/RestoreEntry":                   noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/CreateEntryTemplate":         noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/ListEntryTemplates":          noLimit,
+		"/spire.private.server.entrytemplate.EntryTemplates/DeleteEntryTemplate":         noLimit,
+		"/spire.private.server.entrysync.EntrySync/SyncAuthorizedEntries":                noLimit,
+		"/spire.private.server.denylist.Denylist/ListPatterns":                           noLimit,
+		"/spire.private.server.denylist.Denylist/AddPattern":                             noLimit,
+		"/spire.private.server.denylist.Denylist/RemovePattern":                          noLimit,
+		"/spire.private.server.jointoken.JoinTokens/ListJoinTokens":                      noLimit,
+		"/spire.private.server.jointoken.JoinTokens/RevokeJoinToken":                     noLimit,
+		"/spire.private.server.count.EntryCount/CountFilteredEntries":                    noLimit,
+		"/spire.private.server.count.AgentCount/CountFilteredAgents":                     noLimit,
+		"/spire.private.server.count.BundleCount/CountFederatedBundles":                  noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/ListFederationRelationships":       noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/GetFederationRelationship":         noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/BatchCreateFederationRelationship": noLimit,
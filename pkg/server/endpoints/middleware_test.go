@@ -54,7 +54,7 @@ func TestAuthorizedEntryFetcherWithFullCache(t *testing.T) {
 		return newStaticEntryCache(entryMap), nil
 	}
 
-	f, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, log, clk, defaultCacheReloadInterval)
+	f, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, ds, log, clk, defaultCacheReloadInterval)
 	require.NoError(t, err)
 
 	entries, err := f.FetchAuthorizedEntries(context.Background(), agentID)
@@ -186,6 +186,8 @@ func TestAgentAuthorizer(t *testing.T) {
 				SpiffeId:            agentID.String(),
 				CertSerialNumber:    "CURRENT",
 				NewCertSerialNumber: agentSVID.SerialNumber.String(),
+				CanReattest:         true,
+				AttestedAt:          1234,
 			},
 			expectedCode: codes.OK,
 		},
@@ -258,12 +260,13 @@ func TestAgentAuthorizer(t *testing.T) {
 			case codes.PermissionDenied:
 				// Assert that the expected permission denied reason is returned
 				details := status.Convert(err).Details()
-				require.Len(t, details, 1, "expecting permission denied detail")
+				require.Len(t, details, 2, "expecting permission denied and error info details")
 				detail, ok := details[0].(proto.Message)
 				require.True(t, ok, "detail is not a proto message")
 				spiretest.RequireProtoEqual(t, &types.PermissionDeniedDetails{
 					Reason: tt.expectedReason,
 				}, detail)
+				require.Equal(t, tt.expectedReason.String(), api.ErrorReason(err))
 				return
 			case codes.Internal:
 				return
@@ -276,6 +279,8 @@ func TestAgentAuthorizer(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, agentSVID.SerialNumber.String(), attestedNode.CertSerialNumber)
 			require.Empty(t, attestedNode.NewCertSerialNumber)
+			require.Equal(t, tt.node.CanReattest, attestedNode.CanReattest)
+			require.Equal(t, tt.node.AttestedAt, attestedNode.AttestedAt)
 		})
 	}
 }
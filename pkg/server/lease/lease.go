@@ -0,0 +1,89 @@
+// Package lease coordinates the duties that only one of the servers sharing a
+// datastore needs to perform, such as pruning the trust domain bundle, using
+// leases stored in the datastore.
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
+)
+
+type Config struct {
+	DataStore datastore.DataStore
+	Log       logrus.FieldLogger
+	Clock     clock.Clock
+
+	// HolderID identifies this server as the holder of leases. It must be
+	// unique among the servers sharing the datastore.
+	HolderID string
+}
+
+// Coordinator acquires leases on behalf of the server
+type Coordinator struct {
+	c Config
+
+	mtx  sync.Mutex
+	held map[string]bool
+}
+
+// New returns a new coordinator
+func New(c Config) *Coordinator {
+	if c.Clock == nil {
+		c.Clock = clock.New()
+	}
+	return &Coordinator{
+		c:    c,
+		held: make(map[string]bool),
+	}
+}
+
+// Acquire acquires or renews the named lease for the given duration and
+// returns true if the server holds it. The duration should be longer than the
+// interval at which the lease is renewed, so that the holder keeps it for as
+// long as it is running. If the lease cannot be acquired due to an error, the
+// server is assumed not to hold it. A nil coordinator always returns true, so
+// that every server performs the duty when coordination is disabled.
+func (c *Coordinator) Acquire(ctx context.Context, name string, ttl time.Duration) bool {
+	if c == nil {
+		return true
+	}
+
+	log := c.c.Log.WithField(telemetry.Lease, name)
+
+	now := c.c.Clock.Now()
+	current, err := c.c.DataStore.AcquireLease(ctx, &datastore.Lease{
+		Name:      name,
+		HolderID:  c.c.HolderID,
+		ExpiresAt: now.Add(ttl),
+	}, now)
+	if err != nil {
+		log.WithError(err).Error("Failed to acquire lease")
+		c.setHeld(log, name, false)
+		return false
+	}
+
+	held := current.HolderID == c.c.HolderID
+	c.setHeld(log.WithField(telemetry.LeaseHolder, current.HolderID), name, held)
+	return held
+}
+
+func (c *Coordinator) setHeld(log logrus.FieldLogger, name string, held bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	switch wasHeld := c.held[name]; {
+	case held && !wasHeld:
+		log.Info("Lease acquired")
+	case !held && wasHeld:
+		log.Warn("Lease lost")
+	case !held:
+		log.Debug("Lease held by another server")
+	}
+	c.held[name] = held
+}
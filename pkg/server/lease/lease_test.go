@@ -0,0 +1,92 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	ds := fakedatastore.New(t)
+	clk := clock.NewMock(t)
+
+	log1, hook1 := test.NewNullLogger()
+	log1.Level = logrus.DebugLevel
+	c1 := New(Config{DataStore: ds, Log: log1, Clock: clk, HolderID: "server-1"})
+	log2, hook2 := test.NewNullLogger()
+	c2 := New(Config{DataStore: ds, Log: log2, Clock: clk, HolderID: "server-2"})
+
+	// The first server to ask acquires the lease
+	require.True(t, c1.Acquire(ctx, "duty", time.Minute))
+	require.False(t, c2.Acquire(ctx, "duty", time.Minute))
+	spiretest.AssertLastLogs(t, hook1.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Lease acquired",
+			Data: logrus.Fields{
+				"lease":        "duty",
+				"lease_holder": "server-1",
+			},
+		},
+	})
+
+	// Leases are independent of each other
+	require.True(t, c2.Acquire(ctx, "other-duty", time.Minute))
+
+	// The holder renews the lease before it expires
+	clk.Add(30 * time.Second)
+	require.True(t, c1.Acquire(ctx, "duty", time.Minute))
+	clk.Add(45 * time.Second)
+	require.False(t, c2.Acquire(ctx, "duty", time.Minute))
+
+	// Once expired, another server takes over the lease
+	clk.Add(time.Minute)
+	require.True(t, c2.Acquire(ctx, "duty", time.Minute))
+	require.False(t, c1.Acquire(ctx, "duty", time.Minute))
+	spiretest.AssertLastLogs(t, hook1.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Lease lost",
+			Data: logrus.Fields{
+				"lease":        "duty",
+				"lease_holder": "server-2",
+			},
+		},
+	})
+	hook2.Reset()
+
+	// The lease is not held if it cannot be acquired
+	ds.SetNextError(errors.New("oh no"))
+	require.False(t, c2.Acquire(ctx, "duty", time.Minute))
+	spiretest.AssertLogs(t, hook2.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Failed to acquire lease",
+			Data: logrus.Fields{
+				"lease":         "duty",
+				logrus.ErrorKey: "oh no",
+			},
+		},
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Lease lost",
+			Data: logrus.Fields{
+				"lease": "duty",
+			},
+		},
+	})
+}
+
+func TestAcquireWithNilCoordinator(t *testing.T) {
+	var c *Coordinator
+	require.True(t, c.Acquire(context.Background(), "duty", time.Minute))
+}
@@ -70,6 +70,7 @@ type IITAttestorConfig struct {
 	trustDomain         spiffeid.TrustDomain
 	allowedLabelKeys    map[string]bool
 	allowedMetadataKeys map[string]bool
+	allowedClockSkew    time.Duration
 
 	ProjectIDAllowList   []string `hcl:"projectid_allow_list"`
 	AgentPathTemplate    string   `hcl:"agent_path_template"`
@@ -78,6 +79,7 @@ type IITAttestorConfig struct {
 	AllowedMetadataKeys  []string `hcl:"allowed_metadata_keys"`
 	MaxMetadataValueSize int      `hcl:"max_metadata_value_size"`
 	ServiceAccountFile   string   `hcl:"service_account_file"`
+	AllowedClockSkew     string   `hcl:"allowed_clock_skew"`
 }
 
 // New creates a new IITAttestorPlugin.
@@ -95,17 +97,17 @@ func (p *IITAttestorPlugin) SetLogger(log hclog.Logger) {
 
 // Attest implements the server side logic for the gcp iit node attestation plugin.
 func (p *IITAttestorPlugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
-	jwks, err := p.jwksRetriever.retrieveJWKS(stream.Context())
+	c, err := p.getConfig()
 	if err != nil {
 		return err
 	}
 
-	identityMetadata, err := validateAttestationAndExtractIdentityMetadata(stream, jwks)
+	jwks, err := p.jwksRetriever.retrieveJWKS(stream.Context())
 	if err != nil {
 		return err
 	}
 
-	c, err := p.getConfig()
+	identityMetadata, err := validateAttestationAndExtractIdentityMetadata(stream, jwks, c.allowedClockSkew)
 	if err != nil {
 		return err
 	}
@@ -217,6 +219,16 @@ func (p *IITAttestorPlugin) Configure(ctx context.Context, req *configv1.Configu
 		hclConfig.MaxMetadataValueSize = defaultMaxMetadataValueSize
 	}
 
+	if hclConfig.AllowedClockSkew != "" {
+		hclConfig.allowedClockSkew, err = time.ParseDuration(hclConfig.AllowedClockSkew)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to parse allowed_clock_skew: %v", err)
+		}
+		if hclConfig.allowedClockSkew < 0 {
+			return nil, status.Error(codes.InvalidArgument, "allowed_clock_skew cannot be negative")
+		}
+	}
+
 	hclConfig.idPathTemplate = tmpl
 	hclConfig.trustDomain = trustDomain
 
@@ -265,7 +277,10 @@ type keyValue struct {
 	value string
 }
 
-func validateAttestationAndExtractIdentityMetadata(stream nodeattestorv1.NodeAttestor_AttestServer, jwks *jose.JSONWebKeySet) (gcp.ComputeEngine, error) {
+// validateAttestationAndExtractIdentityMetadata validates the identity token
+// received over the stream. The allowed clock skew is tolerated, in addition
+// to the default leeway of one minute, when validating the token times.
+func validateAttestationAndExtractIdentityMetadata(stream nodeattestorv1.NodeAttestor_AttestServer, jwks *jose.JSONWebKeySet, clockSkew time.Duration) (gcp.ComputeEngine, error) {
 	req, err := stream.Recv()
 	if err != nil {
 		return gcp.ComputeEngine{}, err
@@ -286,10 +301,10 @@ func validateAttestationAndExtractIdentityMetadata(stream nodeattestorv1.NodeAtt
 		return gcp.ComputeEngine{}, status.Errorf(codes.InvalidArgument, "failed to validate the identity token signature: %v", err)
 	}
 
-	if err := identityToken.Validate(jwt.Expected{
+	if err := identityToken.ValidateWithLeeway(jwt.Expected{
 		Audience: []string{tokenAudience},
 		Time:     time.Now(),
-	}); err != nil {
+	}, jwt.DefaultLeeway+clockSkew); err != nil {
 		return gcp.ComputeEngine{}, status.Errorf(codes.PermissionDenied, "failed to validate the identity token claims: %v", err)
 	}
 
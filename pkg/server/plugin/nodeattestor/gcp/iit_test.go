@@ -89,6 +89,21 @@ func (s *IITAttestorSuite) TestErrorOnInvalidClaims() {
 	s.requireAttestError(s.T(), payload, codes.PermissionDenied, "nodeattestor(gcp_iit): failed to validate the identity token claims: square/go-jose/jwt: validation failed, token is expired (exp)")
 }
 
+func (s *IITAttestorSuite) TestAttestSuccessWithinAllowedClockSkew() {
+	s.attestor = s.loadPluginWithConfig(`
+projectid_allow_list = ["test-project"]
+allowed_clock_skew = "5m"
+`)
+
+	claims := buildDefaultClaims()
+	claims.Expiry = jwt.NewNumericDate(time.Now().Add(-3 * time.Minute))
+	payload := s.signToken(testKey, "kid", claims)
+
+	result, err := s.attestor.Attest(context.Background(), payload, expectNoChallenge)
+	s.Require().NoError(err)
+	s.Require().Equal(testAgentID, result.AgentID)
+}
+
 func (s *IITAttestorSuite) TestErrorOnInvalidAudience() {
 	claims := buildClaims(testProject, "invalid")
 
@@ -298,6 +313,22 @@ agent_path_template = "/{{ .InstanceID "
 		spiretest.AssertGRPCStatusContains(t, err, codes.InvalidArgument, "failed to parse agent path template")
 	})
 
+	s.T().Run("bad allowed clock skew", func(t *testing.T) {
+		err := doConfig(t, coreConfig, `
+projectid_allow_list = ["test-project"]
+allowed_clock_skew = "forever"
+`)
+		spiretest.AssertGRPCStatusContains(t, err, codes.InvalidArgument, "failed to parse allowed_clock_skew")
+	})
+
+	s.T().Run("negative allowed clock skew", func(t *testing.T) {
+		err := doConfig(t, coreConfig, `
+projectid_allow_list = ["test-project"]
+allowed_clock_skew = "-1m"
+`)
+		spiretest.AssertGRPCStatusContains(t, err, codes.InvalidArgument, "allowed_clock_skew cannot be negative")
+	})
+
 	s.T().Run("success", func(t *testing.T) {
 		err := doConfig(t, coreConfig, `
 projectid_allow_list = ["bar"]
@@ -307,7 +338,7 @@ projectid_allow_list = ["bar"]
 }
 
 func (s *IITAttestorSuite) TestFailToRecvStream() {
-	_, err := validateAttestationAndExtractIdentityMetadata(&recvFailStream{}, nil)
+	_, err := validateAttestationAndExtractIdentityMetadata(&recvFailStream{}, nil, 0)
 	s.Require().EqualError(err, "failed to recv from stream")
 }
 
@@ -0,0 +1,367 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/jwtutil"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	nodeattestorbase "github.com/spiffe/spire/pkg/server/plugin/nodeattestor/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	// Give a little leeway when validating the time based claims in case
+	// the clocks of the issuer and the server are not in sync.
+	tokenLeeway = time.Minute * 5
+
+	keySetRefreshInterval = time.Hour
+
+	defaultAgentIDClaim = "sub"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(oidc.PluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// Config contains a map of issuers keyed by name. The name is part of the
+// agent SPIFFE ID and of the "issuer" selector.
+type Config struct {
+	Issuers map[string]*IssuerConfig `hcl:"issuers"`
+}
+
+// IssuerConfig holds the configuration of an issuer trusted to issue tokens
+// for nodes.
+type IssuerConfig struct {
+	// Issuer is the issuer URL. It must match the "iss" claim of tokens.
+	Issuer string `hcl:"issuer"`
+
+	// JWKSURI is the URI of the key set of the issuer. If empty, it is
+	// discovered from the OpenID configuration of the issuer.
+	JWKSURI string `hcl:"jwks_uri"`
+
+	// Audience holds the audiences accepted. Tokens must be issued for at
+	// least one of them.
+	Audience []string `hcl:"audience"`
+
+	// AgentIDClaim is the claim that identifies the node in the agent
+	// SPIFFE ID.
+	AgentIDClaim string `hcl:"agent_id_claim"`
+
+	// ClaimSelectors maps claims to the names of the selectors they are
+	// emitted as.
+	ClaimSelectors map[string]string `hcl:"claim_selectors"`
+
+	// RequiredClaims maps claims to the values they must have.
+	RequiredClaims map[string]string `hcl:"required_claims"`
+}
+
+type config struct {
+	trustDomain spiffeid.TrustDomain
+	issuers     map[string]*issuerConfig
+}
+
+type issuerConfig struct {
+	*IssuerConfig
+	name   string
+	keySet jwtutil.KeySetProvider
+}
+
+// Plugin implements node attestation for agents presenting an OIDC ID token
+// from one of the configured issuers.
+type Plugin struct {
+	nodeattestorbase.Base
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	mu     sync.RWMutex
+	config *config
+
+	hooks struct {
+		now          func() time.Time
+		newKeySetFor func(issuer, jwksURI string) jwtutil.KeySetProvider
+	}
+}
+
+var _ nodeattestorv1.NodeAttestorServer = (*Plugin)(nil)
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.now = time.Now
+	p.hooks.newKeySetFor = newKeySetFor
+	return p
+}
+
+func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	payload := req.GetPayload()
+	if payload == nil {
+		return status.Error(codes.InvalidArgument, "missing attestation payload")
+	}
+
+	attestationData := new(oidc.AttestationData)
+	if err := json.Unmarshal(payload, attestationData); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to unmarshal data payload: %v", err)
+	}
+	if attestationData.Token == "" {
+		return status.Error(codes.InvalidArgument, "missing token from attestation data")
+	}
+
+	token, err := jwt.ParseSigned(attestationData.Token)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to parse token: %v", err)
+	}
+
+	// The issuer is only used to pick the keys the token is verified with.
+	unverified := new(jwt.Claims)
+	if err := token.UnsafeClaimsWithoutVerification(unverified); err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to parse token claims: %v", err)
+	}
+	issuer, ok := config.issuers[unverified.Issuer]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "issuer %q is not authorized", unverified.Issuer)
+	}
+
+	keySet, err := issuer.keySet.GetKeySet(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to obtain JWKS for issuer %q: %v", issuer.name, err)
+	}
+
+	keyID, ok := getTokenKeyID(token)
+	if !ok {
+		return status.Error(codes.InvalidArgument, "token missing key id")
+	}
+	keys := keySet.Key(keyID)
+	if len(keys) == 0 {
+		return status.Errorf(codes.InvalidArgument, "key id %q not found", keyID)
+	}
+
+	claims := new(jwt.Claims)
+	allClaims := make(map[string]interface{})
+	if err := token.Claims(&keys[0], claims, &allClaims); err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to verify token: %v", err)
+	}
+
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer: issuer.Issuer,
+		Time:   p.hooks.now(),
+	}, tokenLeeway); err != nil {
+		return status.Errorf(codes.PermissionDenied, "unable to validate token claims: %v", err)
+	}
+	if claims.Expiry == nil {
+		return status.Error(codes.InvalidArgument, "token missing expiry claim")
+	}
+	if !containsAudience(claims.Audience, issuer.Audience) {
+		return status.Errorf(codes.PermissionDenied, "token audience %q is not authorized", []string(claims.Audience))
+	}
+
+	for name, expected := range issuer.RequiredClaims {
+		values := claimValues(allClaims[name])
+		if len(values) != 1 || values[0] != expected {
+			return status.Errorf(codes.PermissionDenied, "token claim %q does not have the required value", name)
+		}
+	}
+
+	ids := claimValues(allClaims[issuer.AgentIDClaim])
+	if len(ids) != 1 || ids[0] == "" {
+		return status.Errorf(codes.InvalidArgument, "token claim %q must have a single value to be used as agent ID", issuer.AgentIDClaim)
+	}
+	agentID, err := oidc.MakeAgentID(config.trustDomain, issuer.name, ids[0])
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to make agent ID: %v", err)
+	}
+
+	attested, err := p.IsAttested(stream.Context(), agentID.String())
+	switch {
+	case err != nil:
+		return err
+	case attested:
+		return status.Error(codes.PermissionDenied, "OIDC token has already been used to attest an agent")
+	}
+
+	return stream.Send(&nodeattestorv1.AttestResponse{
+		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
+			AgentAttributes: &nodeattestorv1.AgentAttributes{
+				SpiffeId:       agentID.String(),
+				SelectorValues: buildSelectorValues(issuer, claims, allClaims),
+				CanReattest:    false,
+			},
+		},
+	})
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	hclConfig := new(Config)
+	if err := hcl.Decode(hclConfig, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+	if req.CoreConfiguration == nil {
+		return nil, status.Error(codes.InvalidArgument, "core configuration is required")
+	}
+	if req.CoreConfiguration.TrustDomain == "" {
+		return nil, status.Error(codes.InvalidArgument, "core configuration missing trust domain")
+	}
+	trustDomain, err := spiffeid.TrustDomainFromString(req.CoreConfiguration.TrustDomain)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "core configuration has invalid trust domain: %v", err)
+	}
+
+	if len(hclConfig.Issuers) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "configuration must have at least one issuer")
+	}
+
+	config := &config{
+		trustDomain: trustDomain,
+		issuers:     make(map[string]*issuerConfig),
+	}
+	for name, issuer := range hclConfig.Issuers {
+		if _, err := oidc.MakeAgentID(trustDomain, name, "id"); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "issuer name %q is invalid: %v", name, err)
+		}
+		switch {
+		case issuer.Issuer == "":
+			return nil, status.Errorf(codes.InvalidArgument, "issuer %q is missing the issuer URL", name)
+		case len(issuer.Audience) == 0:
+			return nil, status.Errorf(codes.InvalidArgument, "issuer %q must have at least one audience", name)
+		}
+		if _, ok := config.issuers[issuer.Issuer]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "issuer URL %q is configured more than once", issuer.Issuer)
+		}
+		if issuer.AgentIDClaim == "" {
+			issuer.AgentIDClaim = defaultAgentIDClaim
+		}
+		config.issuers[issuer.Issuer] = &issuerConfig{
+			IssuerConfig: issuer,
+			name:         name,
+			keySet:       p.hooks.newKeySetFor(issuer.Issuer, issuer.JWKSURI),
+		}
+	}
+
+	p.setConfig(config)
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*config, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) setConfig(config *config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+}
+
+func newKeySetFor(issuer, jwksURI string) jwtutil.KeySetProvider {
+	if jwksURI == "" {
+		return jwtutil.NewCachingKeySetProvider(jwtutil.OIDCIssuer(issuer), keySetRefreshInterval)
+	}
+	return jwtutil.NewCachingKeySetProvider(jwtutil.KeySetProviderFunc(func(ctx context.Context) (*jose.JSONWebKeySet, error) {
+		return jwtutil.FetchKeySet(ctx, jwksURI)
+	}), keySetRefreshInterval)
+}
+
+func buildSelectorValues(issuer *issuerConfig, claims *jwt.Claims, allClaims map[string]interface{}) []string {
+	selectorValues := []string{"issuer:" + issuer.name}
+	if claims.Subject != "" {
+		selectorValues = append(selectorValues, "subject:"+claims.Subject)
+	}
+
+	// Iterate the claims in order so selectors are emitted deterministically.
+	claimNames := make([]string, 0, len(issuer.ClaimSelectors))
+	for claimName := range issuer.ClaimSelectors {
+		claimNames = append(claimNames, claimName)
+	}
+	sort.Strings(claimNames)
+	for _, claimName := range claimNames {
+		for _, value := range claimValues(allClaims[claimName]) {
+			selectorValues = append(selectorValues, issuer.ClaimSelectors[claimName]+":"+value)
+		}
+	}
+	return selectorValues
+}
+
+// claimValues returns the string representation of the values of a claim.
+// Claims that are objects, or arrays holding objects, have no values.
+func claimValues(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case []interface{}:
+		var values []string
+		for _, item := range claim {
+			value, ok := scalarClaimValue(item)
+			if !ok {
+				return nil
+			}
+			values = append(values, value)
+		}
+		return values
+	default:
+		if value, ok := scalarClaimValue(claim); ok {
+			return []string{value}
+		}
+		return nil
+	}
+}
+
+func scalarClaimValue(claim interface{}) (string, bool) {
+	switch claim := claim.(type) {
+	case string:
+		return claim, true
+	case float64:
+		return strconv.FormatFloat(claim, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(claim), true
+	default:
+		return "", false
+	}
+}
+
+func containsAudience(audience jwt.Audience, allowed []string) bool {
+	for _, aud := range allowed {
+		if audience.Contains(aud) {
+			return true
+		}
+	}
+	return false
+}
+
+func getTokenKeyID(token *jwt.JSONWebToken) (string, bool) {
+	for _, h := range token.Headers {
+		if h.KeyID != "" {
+			return h.KeyID, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,396 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/agentstore/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/jwtutil"
+	"github.com/spiffe/spire/pkg/common/plugin/oidc"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/test/fakes/fakeagentstore"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	testKeyID  = "KEYID"
+	testIssuer = "https://token.example.org"
+	testConfig = `
+		issuers = {
+			"ci" = {
+				issuer = "https://token.example.org"
+				audience = ["spire-server", "other"]
+				claim_selectors = {
+					"project_path" = "project"
+					"groups" = "group"
+				}
+				required_claims = {
+					"namespace" = "infra"
+				}
+			}
+			"cloud" = {
+				issuer = "https://cloud.example.org"
+				jwks_uri = "https://cloud.example.org/keys"
+				audience = ["spire-server"]
+				agent_id_claim = "instance_id"
+			}
+		}
+	`
+	testAgentID = "spiffe://example.org/spire/agent/oidc/ci/runner-1"
+)
+
+var (
+	testKey = testkey.MustEC256()
+	now     = time.Now().Truncate(time.Second)
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		payload     []byte
+		attested    bool
+		keySetErr   error
+		expectCode  codes.Code
+		expectMsg   string
+		expectAgent string
+		expectSel   []string
+	}{
+		{
+			name:       "missing payload",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "payload cannot be empty",
+		},
+		{
+			name:       "malformed payload",
+			payload:    []byte("{"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): failed to unmarshal data payload",
+		},
+		{
+			name:       "missing token",
+			payload:    []byte("{}"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): missing token from attestation data",
+		},
+		{
+			name:       "malformed token",
+			payload:    makePayload("blah"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to parse token",
+		},
+		{
+			name: "unknown issuer",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				claims["iss"] = "https://evil.example.org"
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): issuer "https://evil.example.org" is not authorized`,
+		},
+		{
+			name:       "key set unavailable",
+			payload:    signPayload(t, testKeyID, makeClaims()),
+			keySetErr:  errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  `nodeattestor(oidc): unable to obtain JWKS for issuer "ci": oh no`,
+		},
+		{
+			name:       "missing key ID",
+			payload:    signPayload(t, "", makeClaims()),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): token missing key id",
+		},
+		{
+			name:       "unknown key ID",
+			payload:    signPayload(t, "OTHERKEYID", makeClaims()),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `nodeattestor(oidc): key id "OTHERKEYID" not found`,
+		},
+		{
+			name: "expired",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				claims["exp"] = now.Add(-tokenLeeway - time.Second).Unix()
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): unable to validate token claims: square/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		{
+			name: "missing expiry",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				delete(claims, "exp")
+			})),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): token missing expiry claim",
+		},
+		{
+			name: "audience not authorized",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				claims["aud"] = "somebody-else"
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token audience ["somebody-else"] is not authorized`,
+		},
+		{
+			name: "required claim missing",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				delete(claims, "namespace")
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token claim "namespace" does not have the required value`,
+		},
+		{
+			name: "required claim mismatch",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				claims["namespace"] = "apps"
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(oidc): token claim "namespace" does not have the required value`,
+		},
+		{
+			name: "agent ID claim missing",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				delete(claims, "sub")
+			})),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `nodeattestor(oidc): token claim "sub" must have a single value to be used as agent ID`,
+		},
+		{
+			name: "agent ID claim not a valid path segment",
+			payload: signPayload(t, testKeyID, makeClaims(func(claims map[string]interface{}) {
+				claims["sub"] = "project/runner"
+			})),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(oidc): unable to make agent ID: agent ID claim value cannot contain a slash",
+		},
+		{
+			name:       "already attested",
+			payload:    signPayload(t, testKeyID, makeClaims()),
+			attested:   true,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(oidc): OIDC token has already been used to attest an agent",
+		},
+		{
+			name:        "success",
+			payload:     signPayload(t, testKeyID, makeClaims()),
+			expectAgent: testAgentID,
+			expectSel: []string{
+				"issuer:ci",
+				"subject:runner-1",
+				"group:admins",
+				"group:devs",
+				"project:infra/spire",
+			},
+		},
+		{
+			name: "success with custom agent ID claim",
+			payload: signPayload(t, testKeyID, map[string]interface{}{
+				"iss":         "https://cloud.example.org",
+				"aud":         "spire-server",
+				"exp":         now.Add(time.Minute).Unix(),
+				"sub":         "service-account",
+				"instance_id": 1234,
+			}),
+			expectAgent: "spiffe://example.org/spire/agent/oidc/cloud/1234",
+			expectSel: []string{
+				"issuer:cloud",
+				"subject:service-account",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			agentStore := fakeagentstore.New()
+			if tt.attested {
+				agentStore.SetAgentInfo(&agentstorev1.AgentInfo{AgentId: testAgentID})
+			}
+			attestor := loadPlugin(t, agentStore, tt.keySetErr)
+
+			result, err := attestor.Attest(context.Background(), tt.payload, expectNoChallenge)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAgent, result.AgentID)
+
+			var selectors []string
+			for _, selector := range result.Selectors {
+				require.Equal(t, oidc.PluginName, selector.Type)
+				selectors = append(selectors, selector.Value)
+			}
+			require.Equal(t, tt.expectSel, selectors)
+		})
+	}
+}
+
+func TestAttestFailsWhenNotConfigured(t *testing.T) {
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), attestor,
+		plugintest.HostServices(agentstorev1.AgentStoreServiceServer(fakeagentstore.New())),
+	)
+
+	result, err := attestor.Attest(context.Background(), []byte("payload"), expectNoChallenge)
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "nodeattestor(oidc): not configured")
+	require.Nil(t, result)
+}
+
+func TestConfigure(t *testing.T) {
+	coreConfig := catalog.CoreConfig{
+		TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+	}
+
+	for _, tt := range []struct {
+		name       string
+		coreConfig catalog.CoreConfig
+		config     string
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			coreConfig: coreConfig,
+			config:     "blah",
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name:      "missing trust domain",
+			config:    testConfig,
+			expectMsg: "core configuration missing trust domain",
+		},
+		{
+			name:       "missing issuers",
+			coreConfig: coreConfig,
+			expectMsg:  "configuration must have at least one issuer",
+		},
+		{
+			name:       "invalid issuer name",
+			coreConfig: coreConfig,
+			config:     `issuers = { "c:i" = { issuer = "https://token.example.org" audience = ["spire-server"] } }`,
+			expectMsg:  `issuer name "c:i" is invalid`,
+		},
+		{
+			name:       "missing issuer URL",
+			coreConfig: coreConfig,
+			config:     `issuers = { "ci" = { audience = ["spire-server"] } }`,
+			expectMsg:  `issuer "ci" is missing the issuer URL`,
+		},
+		{
+			name:       "missing audience",
+			coreConfig: coreConfig,
+			config:     `issuers = { "ci" = { issuer = "https://token.example.org" } }`,
+			expectMsg:  `issuer "ci" must have at least one audience`,
+		},
+		{
+			name:       "duplicate issuer URL",
+			coreConfig: coreConfig,
+			config: `issuers = {
+				"a" = { issuer = "https://token.example.org" audience = ["spire-server"] }
+				"b" = { issuer = "https://token.example.org" audience = ["spire-server"] }
+			}`,
+			expectMsg: `issuer URL "https://token.example.org" is configured more than once`,
+		},
+		{
+			name:       "success",
+			coreConfig: coreConfig,
+			config:     testConfig,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, BuiltIn(), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.HostServices(agentstorev1.AgentStoreServiceServer(fakeagentstore.New())),
+				plugintest.CoreConfig(tt.coreConfig),
+				plugintest.Configure(tt.config),
+			)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, agentStore *fakeagentstore.AgentStore, keySetErr error) nodeattestor.NodeAttestor {
+	p := New()
+	p.hooks.now = func() time.Time {
+		return now
+	}
+	p.hooks.newKeySetFor = func(issuer, jwksURI string) jwtutil.KeySetProvider {
+		return jwtutil.KeySetProviderFunc(func(ctx context.Context) (*jose.JSONWebKeySet, error) {
+			if keySetErr != nil {
+				return nil, keySetErr
+			}
+			return &jose.JSONWebKeySet{
+				Keys: []jose.JSONWebKey{
+					{
+						Key:   testKey.Public(),
+						KeyID: testKeyID,
+					},
+				},
+			}, nil
+		})
+	}
+
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), attestor,
+		plugintest.HostServices(agentstorev1.AgentStoreServiceServer(agentStore)),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(testConfig),
+	)
+	return attestor
+}
+
+func makeClaims(modifiers ...func(map[string]interface{})) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":          testIssuer,
+		"aud":          []string{"spire-server"},
+		"nbf":          now.Unix(),
+		"exp":          now.Add(time.Minute).Unix(),
+		"sub":          "runner-1",
+		"namespace":    "infra",
+		"project_path": "infra/spire",
+		"groups":       []string{"admins", "devs"},
+	}
+	for _, modifier := range modifiers {
+		modifier(claims)
+	}
+	return claims
+}
+
+func signPayload(t *testing.T, keyID string, claims map[string]interface{}) []byte {
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.ES256,
+		Key: jose.JSONWebKey{
+			Key:   testKey,
+			KeyID: keyID,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return makePayload(token)
+}
+
+func makePayload(token string) []byte {
+	return []byte(fmt.Sprintf(`{"token": %q}`, token))
+}
+
+func expectNoChallenge(ctx context.Context, challenge []byte) ([]byte, error) {
+	return nil, errors.New("challenge is not expected")
+}
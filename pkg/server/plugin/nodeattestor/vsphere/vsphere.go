@@ -0,0 +1,270 @@
+package vsphere
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/nodeattestor/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/vsphere"
+	nodeattestorbase "github.com/spiffe/spire/pkg/server/plugin/nodeattestor/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	// Identity documents are minted by the provisioning automation, which
+	// may not share a clock with the server. Give a little leeway when
+	// validating the time based claims.
+	documentLeeway = time.Minute * 5
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(vsphere.PluginName,
+		nodeattestorv1.NodeAttestorPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// JWKSPath is the path to the JSON Web Key Set with the keys used to
+	// sign identity documents.
+	JWKSPath string `hcl:"jwks_path"`
+
+	// Audience is the audience identity documents must be issued for.
+	Audience string `hcl:"audience"`
+
+	// DatacenterAllowList, if set, restricts attestation to VMs in the
+	// listed datacenters.
+	DatacenterAllowList []string `hcl:"datacenter_allow_list"`
+}
+
+type config struct {
+	trustDomain spiffeid.TrustDomain
+	keySet      *jose.JSONWebKeySet
+	audience    string
+	datacenters map[string]bool
+}
+
+// Plugin implements node attestation for agents running in vSphere VMs. The
+// agent presents the identity document stored in the guestinfo of the VM,
+// which is verified against the configured signing keys.
+type Plugin struct {
+	nodeattestorbase.Base
+	nodeattestorv1.UnsafeNodeAttestorServer
+	configv1.UnsafeConfigServer
+
+	mu     sync.RWMutex
+	config *config
+
+	hooks struct {
+		now func() time.Time
+	}
+}
+
+var _ nodeattestorv1.NodeAttestorServer = (*Plugin)(nil)
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.now = time.Now
+	return p
+}
+
+func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	payload := req.GetPayload()
+	if payload == nil {
+		return status.Error(codes.InvalidArgument, "missing attestation payload")
+	}
+
+	token, err := jwt.ParseSigned(string(payload))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to parse identity document: %v", err)
+	}
+
+	doc, err := verifyDocument(token, config.keySet)
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "unable to verify identity document: %v", err)
+	}
+
+	if err := doc.ValidateWithLeeway(jwt.Expected{
+		Audience: []string{config.audience},
+		Time:     p.hooks.now(),
+	}, documentLeeway); err != nil {
+		return status.Errorf(codes.PermissionDenied, "unable to validate identity document claims: %v", err)
+	}
+	if doc.Expiry == nil {
+		return status.Error(codes.InvalidArgument, "identity document missing expiry claim")
+	}
+	if doc.VMUUID == "" {
+		return status.Error(codes.InvalidArgument, "identity document missing VM UUID claim")
+	}
+	if config.datacenters != nil && !config.datacenters[doc.Datacenter] {
+		return status.Errorf(codes.PermissionDenied, "datacenter %q is not authorized", doc.Datacenter)
+	}
+
+	agentID, err := vsphere.MakeAgentID(config.trustDomain, doc.VMUUID)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to make agent ID: %v", err)
+	}
+
+	attested, err := p.IsAttested(stream.Context(), agentID.String())
+	switch {
+	case err != nil:
+		return err
+	case attested:
+		return status.Error(codes.PermissionDenied, "identity document has already been used to attest an agent")
+	}
+
+	return stream.Send(&nodeattestorv1.AttestResponse{
+		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
+			AgentAttributes: &nodeattestorv1.AgentAttributes{
+				SpiffeId:       agentID.String(),
+				SelectorValues: buildSelectorValues(doc),
+				CanReattest:    false,
+			},
+		},
+	})
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	hclConfig := new(Config)
+	if err := hcl.Decode(hclConfig, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+	if req.CoreConfiguration == nil {
+		return nil, status.Error(codes.InvalidArgument, "core configuration is required")
+	}
+	if req.CoreConfiguration.TrustDomain == "" {
+		return nil, status.Error(codes.InvalidArgument, "core configuration missing trust domain")
+	}
+	trustDomain, err := spiffeid.TrustDomainFromString(req.CoreConfiguration.TrustDomain)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "core configuration has invalid trust domain: %v", err)
+	}
+
+	if hclConfig.JWKSPath == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwks_path is required")
+	}
+	keySet, err := loadKeySet(hclConfig.JWKSPath)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to load JWKS: %v", err)
+	}
+
+	config := &config{
+		trustDomain: trustDomain,
+		keySet:      keySet,
+		audience:    hclConfig.Audience,
+	}
+	if config.audience == "" {
+		config.audience = vsphere.DefaultAudience
+	}
+	if len(hclConfig.DatacenterAllowList) > 0 {
+		config.datacenters = make(map[string]bool)
+		for _, datacenter := range hclConfig.DatacenterAllowList {
+			config.datacenters[datacenter] = true
+		}
+	}
+
+	p.setConfig(config)
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*config, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) setConfig(config *config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+}
+
+func loadKeySet(path string) (*jose.JSONWebKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keySet := new(jose.JSONWebKeySet)
+	if err := json.Unmarshal(data, keySet); err != nil {
+		return nil, err
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no keys found")
+	}
+	return keySet, nil
+}
+
+// verifyDocument verifies the signature of the identity document using the
+// key identified by the document. If the document does not identify a key,
+// every key in the key set is tried.
+func verifyDocument(token *jwt.JSONWebToken, keySet *jose.JSONWebKeySet) (*vsphere.IdentityDocument, error) {
+	keys := keySet.Keys
+	if keyID, ok := getTokenKeyID(token); ok {
+		keys = keySet.Key(keyID)
+		if len(keys) == 0 {
+			return nil, status.Errorf(codes.PermissionDenied, "key id %q not found", keyID)
+		}
+	}
+
+	var err error
+	for i := range keys {
+		doc := new(vsphere.IdentityDocument)
+		if err = token.Claims(&keys[i], doc); err == nil {
+			return doc, nil
+		}
+	}
+	return nil, err
+}
+
+func buildSelectorValues(doc *vsphere.IdentityDocument) []string {
+	selectorValues := []string{"vm_uuid:" + doc.VMUUID}
+	if doc.Datacenter != "" {
+		selectorValues = append(selectorValues, "datacenter:"+doc.Datacenter)
+	}
+	if doc.Cluster != "" {
+		selectorValues = append(selectorValues, "cluster:"+doc.Cluster)
+	}
+	if doc.ResourcePool != "" {
+		selectorValues = append(selectorValues, "resource_pool:"+doc.ResourcePool)
+	}
+	for _, tag := range doc.Tags {
+		selectorValues = append(selectorValues, "tag:"+tag)
+	}
+	return selectorValues
+}
+
+func getTokenKeyID(token *jwt.JSONWebToken) (string, bool) {
+	for _, h := range token.Headers {
+		if h.KeyID != "" {
+			return h.KeyID, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,342 @@
+package vsphere
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	agentstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/agentstore/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/plugin/vsphere"
+	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	"github.com/spiffe/spire/test/fakes/fakeagentstore"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	testKeyID  = "KEYID"
+	testVMUUID = "4207a5a2-1a2b-3c4d-5e6f-0123456789ab"
+	testAgent  = "spiffe://example.org/spire/agent/vsphere/" + testVMUUID
+)
+
+var (
+	testKey      = testkey.MustEC256()
+	otherTestKey = testkey.MustEC384()
+	now          = time.Now().Truncate(time.Second)
+)
+
+func TestAttest(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		config      string
+		payload     []byte
+		attested    bool
+		expectCode  codes.Code
+		expectMsg   string
+		expectAgent string
+		expectSel   []string
+	}{
+		{
+			name:       "missing payload",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "payload cannot be empty",
+		},
+		{
+			name:       "malformed document",
+			payload:    []byte("blah"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(vsphere): unable to parse identity document",
+		},
+		{
+			name:       "unknown key ID",
+			payload:    signDocument(t, testKey, "OTHERKEYID", makeDocument()),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(vsphere): unable to verify identity document: rpc error: code = PermissionDenied desc = key id "OTHERKEYID" not found`,
+		},
+		{
+			name:       "signed by another key",
+			payload:    signDocument(t, otherTestKey, "", makeDocument()),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(vsphere): unable to verify identity document",
+		},
+		{
+			name: "wrong audience",
+			payload: signDocument(t, testKey, testKeyID, makeDocument(func(doc *vsphere.IdentityDocument) {
+				doc.Audience = jwt.Audience{"other"}
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(vsphere): unable to validate identity document claims: square/go-jose/jwt: validation failed, invalid audience claim (aud)",
+		},
+		{
+			name: "expired",
+			payload: signDocument(t, testKey, testKeyID, makeDocument(func(doc *vsphere.IdentityDocument) {
+				doc.Expiry = jwt.NewNumericDate(now.Add(-documentLeeway - time.Second))
+			})),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(vsphere): unable to validate identity document claims: square/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		{
+			name: "missing expiry",
+			payload: signDocument(t, testKey, testKeyID, makeDocument(func(doc *vsphere.IdentityDocument) {
+				doc.Expiry = nil
+			})),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(vsphere): identity document missing expiry claim",
+		},
+		{
+			name: "missing VM UUID",
+			payload: signDocument(t, testKey, testKeyID, makeDocument(func(doc *vsphere.IdentityDocument) {
+				doc.VMUUID = ""
+			})),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "nodeattestor(vsphere): identity document missing VM UUID claim",
+		},
+		{
+			name:       "datacenter not allowed",
+			config:     `datacenter_allow_list = ["dc-2"]`,
+			payload:    signDocument(t, testKey, testKeyID, makeDocument()),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  `nodeattestor(vsphere): datacenter "dc-1" is not authorized`,
+		},
+		{
+			name:       "already attested",
+			payload:    signDocument(t, testKey, testKeyID, makeDocument()),
+			attested:   true,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "nodeattestor(vsphere): identity document has already been used to attest an agent",
+		},
+		{
+			name:        "success",
+			config:      `datacenter_allow_list = ["dc-1"]`,
+			payload:     signDocument(t, testKey, testKeyID, makeDocument()),
+			expectAgent: testAgent,
+			expectSel: []string{
+				"vm_uuid:" + testVMUUID,
+				"datacenter:dc-1",
+				"cluster:cluster-1",
+				"resource_pool:pool-1",
+				"tag:env:prod",
+				"tag:tier:web",
+			},
+		},
+		{
+			name:        "success without key ID",
+			payload:     signDocument(t, testKey, "", makeDocument()),
+			expectAgent: testAgent,
+			expectSel: []string{
+				"vm_uuid:" + testVMUUID,
+				"datacenter:dc-1",
+				"cluster:cluster-1",
+				"resource_pool:pool-1",
+				"tag:env:prod",
+				"tag:tier:web",
+			},
+		},
+		{
+			name: "success with only VM UUID",
+			payload: signDocument(t, testKey, testKeyID, makeDocument(func(doc *vsphere.IdentityDocument) {
+				doc.Datacenter = ""
+				doc.Cluster = ""
+				doc.ResourcePool = ""
+				doc.Tags = nil
+			})),
+			expectAgent: testAgent,
+			expectSel:   []string{"vm_uuid:" + testVMUUID},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			agentStore := fakeagentstore.New()
+			if tt.attested {
+				agentStore.SetAgentInfo(&agentstorev1.AgentInfo{AgentId: testAgent})
+			}
+			attestor := loadPlugin(t, agentStore, tt.config)
+
+			result, err := attestor.Attest(context.Background(), tt.payload, expectNoChallenge)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAgent, result.AgentID)
+
+			var selectors []string
+			for _, selector := range result.Selectors {
+				require.Equal(t, vsphere.PluginName, selector.Type)
+				selectors = append(selectors, selector.Value)
+			}
+			require.Equal(t, tt.expectSel, selectors)
+		})
+	}
+}
+
+func TestAttestFailsWhenNotConfigured(t *testing.T) {
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, BuiltIn(), attestor,
+		plugintest.HostServices(agentstorev1.AgentStoreServiceServer(fakeagentstore.New())),
+	)
+
+	result, err := attestor.Attest(context.Background(), []byte("payload"), expectNoChallenge)
+	spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "nodeattestor(vsphere): not configured")
+	require.Nil(t, result)
+}
+
+func TestConfigure(t *testing.T) {
+	jwksPath := writeKeySet(t)
+	emptyJWKSPath := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(emptyJWKSPath, []byte(`{"keys":[]}`), 0600))
+
+	coreConfig := catalog.CoreConfig{
+		TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+	}
+
+	for _, tt := range []struct {
+		name       string
+		coreConfig catalog.CoreConfig
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			coreConfig: coreConfig,
+			config:     "blah",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode configuration",
+		},
+		{
+			name:       "missing trust domain",
+			config:     `jwks_path = "` + jwksPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "core configuration missing trust domain",
+		},
+		{
+			name:       "missing JWKS path",
+			coreConfig: coreConfig,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "jwks_path is required",
+		},
+		{
+			name:       "JWKS does not exist",
+			coreConfig: coreConfig,
+			config:     `jwks_path = "` + filepath.Join(t.TempDir(), "missing.json") + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load JWKS",
+		},
+		{
+			name:       "JWKS has no keys",
+			coreConfig: coreConfig,
+			config:     `jwks_path = "` + emptyJWKSPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load JWKS: rpc error: code = InvalidArgument desc = no keys found",
+		},
+		{
+			name:       "success",
+			coreConfig: coreConfig,
+			config:     `jwks_path = "` + jwksPath + `"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, BuiltIn(), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.HostServices(agentstorev1.AgentStoreServiceServer(fakeagentstore.New())),
+				plugintest.CoreConfig(tt.coreConfig),
+				plugintest.Configure(tt.config),
+			)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func loadPlugin(t *testing.T, agentStore *fakeagentstore.AgentStore, config string) nodeattestor.NodeAttestor {
+	p := New()
+	p.hooks.now = func() time.Time {
+		return now
+	}
+
+	attestor := new(nodeattestor.V1)
+	plugintest.Load(t, builtin(p), attestor,
+		plugintest.HostServices(agentstorev1.AgentStoreServiceServer(agentStore)),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configure(`jwks_path = "`+writeKeySet(t)+`"`+"\n"+config),
+	)
+	return attestor
+}
+
+func writeKeySet(t *testing.T) string {
+	data, err := json.Marshal(jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{
+			{
+				Key:   testKey.Public(),
+				KeyID: testKeyID,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func makeDocument(modifiers ...func(*vsphere.IdentityDocument)) *vsphere.IdentityDocument {
+	doc := &vsphere.IdentityDocument{
+		Claims: jwt.Claims{
+			Audience:  jwt.Audience{vsphere.DefaultAudience},
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+		VMUUID:       testVMUUID,
+		Datacenter:   "dc-1",
+		Cluster:      "cluster-1",
+		ResourcePool: "pool-1",
+		Tags:         []string{"env:prod", "tier:web"},
+	}
+	for _, modifier := range modifiers {
+		modifier(doc)
+	}
+	return doc
+}
+
+func signDocument(t *testing.T, key interface{}, keyID string, doc *vsphere.IdentityDocument) []byte {
+	algorithm := jose.ES256
+	if key == otherTestKey {
+		algorithm = jose.ES384
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: algorithm,
+		Key: jose.JSONWebKey{
+			Key:   key,
+			KeyID: keyID,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(doc).CompactSerialize()
+	require.NoError(t, err)
+	return []byte(token)
+}
+
+func expectNoChallenge(ctx context.Context, challenge []byte) ([]byte, error) {
+	return nil, errors.New("challenge is not expected")
+}
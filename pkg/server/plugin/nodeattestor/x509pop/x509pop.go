@@ -149,7 +149,7 @@ func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
 		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
 			AgentAttributes: &nodeattestorv1.AgentAttributes{
 				SpiffeId:       spiffeid.String(),
-				SelectorValues: buildSelectorValues(leaf, chains),
+				SelectorValues: x509pop.BuildSelectorValues(leaf, chains),
 				CanReattest:    true,
 			},
 		},
@@ -238,30 +238,3 @@ func (p *Plugin) setConfiguration(config *configuration) {
 	defer p.m.Unlock()
 	p.config = config
 }
-
-func buildSelectorValues(leaf *x509.Certificate, chains [][]*x509.Certificate) []string {
-	selectorValues := []string{}
-
-	if leaf.Subject.CommonName != "" {
-		selectorValues = append(selectorValues, "subject:cn:"+leaf.Subject.CommonName)
-	}
-
-	// Used to avoid duplicating selectors.
-	fingerprints := map[string]*x509.Certificate{}
-	for _, chain := range chains {
-		// Iterate over all the certs in the chain (skip leaf at the 0 index)
-		for _, cert := range chain[1:] {
-			fp := x509pop.Fingerprint(cert)
-			// If the same fingerprint is generated, continue with the next certificate, because
-			// a selector should have been already created for it.
-			if _, ok := fingerprints[fp]; ok {
-				continue
-			}
-			fingerprints[fp] = cert
-
-			selectorValues = append(selectorValues, "ca:fingerprint:"+fp)
-		}
-	}
-
-	return selectorValues
-}
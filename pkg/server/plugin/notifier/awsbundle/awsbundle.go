@@ -0,0 +1,229 @@
+package awsbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	identityproviderv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/identityprovider/v1"
+	notifierv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/notifier/v1"
+	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/coretypes/bundle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "aws_bundle"
+
+	formatPEM    = "pem"
+	formatSPIFFE = "spiffe"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtIn(New())
+}
+
+func builtIn(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		notifierv1.NotifierPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type pluginConfig struct {
+	Region          string `hcl:"region"`
+	AccessKeyID     string `hcl:"access_key_id"`
+	SecretAccessKey string `hcl:"secret_access_key"`
+	AssumeRoleARN   string `hcl:"assume_role_arn"`
+
+	// Format is the format the bundle is published in, either "pem"
+	// (default) or "spiffe".
+	Format string `hcl:"format"`
+
+	// Bucket and ObjectKey identify the S3 object the bundle is written to.
+	Bucket    string `hcl:"bucket"`
+	ObjectKey string `hcl:"object_key"`
+
+	// ParameterName is the name of the SSM parameter the bundle is written
+	// to.
+	ParameterName string `hcl:"parameter_name"`
+}
+
+type Plugin struct {
+	notifierv1.UnsafeNotifierServer
+	configv1.UnsafeConfigServer
+
+	mu               sync.RWMutex
+	log              hclog.Logger
+	config           *pluginConfig
+	client           awsClient
+	identityProvider identityproviderv1.IdentityProviderServiceClient
+
+	hooks struct {
+		newClient func(config *pluginConfig) (awsClient, error)
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.newClient = newAWSClient
+	return p
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) BrokerHostServices(broker pluginsdk.ServiceBroker) error {
+	if !broker.BrokerClient(&p.identityProvider) {
+		return status.Errorf(codes.FailedPrecondition, "IdentityProvider host service is required")
+	}
+	return nil
+}
+
+func (p *Plugin) Notify(ctx context.Context, req *notifierv1.NotifyRequest) (*notifierv1.NotifyResponse, error) {
+	config, client, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := req.Event.(*notifierv1.NotifyRequest_BundleUpdated); ok {
+		// ignore the bundle presented in the request. see publishBundle for details on why.
+		if err := p.publishBundle(ctx, config, client); err != nil {
+			return nil, err
+		}
+	}
+	return &notifierv1.NotifyResponse{}, nil
+}
+
+func (p *Plugin) NotifyAndAdvise(ctx context.Context, req *notifierv1.NotifyAndAdviseRequest) (*notifierv1.NotifyAndAdviseResponse, error) {
+	config, client, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := req.Event.(*notifierv1.NotifyAndAdviseRequest_BundleLoaded); ok {
+		// ignore the bundle presented in the request. see publishBundle for details on why.
+		if err := p.publishBundle(ctx, config, client); err != nil {
+			return nil, err
+		}
+	}
+	return &notifierv1.NotifyAndAdviseResponse{}, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(pluginConfig)
+	if err := hcl.Decode(&config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+
+	if config.Region == "" {
+		return nil, status.Error(codes.InvalidArgument, "region must be set")
+	}
+	switch config.Format {
+	case "":
+		config.Format = formatPEM
+	case formatPEM, formatSPIFFE:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "format must be %q or %q", formatPEM, formatSPIFFE)
+	}
+	if (config.Bucket == "") != (config.ObjectKey == "") {
+		return nil, status.Error(codes.InvalidArgument, "bucket and object_key must be set together")
+	}
+	if config.Bucket == "" && config.ParameterName == "" {
+		return nil, status.Error(codes.InvalidArgument, "at least one of bucket or parameter_name must be set")
+	}
+
+	client, err := p.hooks.newClient(config)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to create AWS client: %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+	p.client = client
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, awsClient, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, p.client, nil
+}
+
+func (p *Plugin) publishBundle(ctx context.Context, c *pluginConfig, client awsClient) error {
+	// Load the latest bundle from the identity provider instead of using the
+	// one in the notification, so a notification that is delivered late
+	// cannot overwrite a newer bundle.
+	resp, err := p.identityProvider.FetchX509Identity(ctx, &identityproviderv1.FetchX509IdentityRequest{})
+	if err != nil {
+		st := status.Convert(err)
+		return status.Errorf(st.Code(), "unable to fetch bundle from SPIRE server: %v", st.Message())
+	}
+
+	data, contentType, err := formatBundle(resp.Bundle, c.Format)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to format bundle: %v", err)
+	}
+
+	if c.Bucket != "" {
+		if err := client.PutObject(ctx, c.Bucket, c.ObjectKey, data, contentType); err != nil {
+			return status.Errorf(codes.Unknown, "unable to update bundle object %s/%s: %v", c.Bucket, c.ObjectKey, err)
+		}
+		p.log.Debug("Bundle object updated", "bucket", c.Bucket, "object_key", c.ObjectKey)
+	}
+
+	if c.ParameterName != "" {
+		if err := client.PutParameter(ctx, c.ParameterName, data); err != nil {
+			return status.Errorf(codes.Unknown, "unable to update bundle parameter %s: %v", c.ParameterName, err)
+		}
+		p.log.Debug("Bundle parameter updated", "parameter_name", c.ParameterName)
+	}
+	return nil
+}
+
+// formatBundle formats the bundle for publishing, returning the data and its
+// content type.
+func formatBundle(b *plugintypes.Bundle, format string) ([]byte, string, error) {
+	switch format {
+	case formatSPIFFE:
+		commonBundle, err := bundle.ToCommonFromPluginProto(b)
+		if err != nil {
+			return nil, "", err
+		}
+		spiffeBundle, err := bundleutil.BundleFromProto(commonBundle)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := bundleutil.Marshal(spiffeBundle)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, "application/json", nil
+	case formatPEM:
+		bundleData := new(bytes.Buffer)
+		for _, x509Authority := range b.X509Authorities {
+			// no need to check the error since we're encoding into a memory buffer
+			_ = pem.Encode(bundleData, &pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: x509Authority.Asn1,
+			})
+		}
+		return bundleData.Bytes(), "application/x-pem-file", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported format %q", format)
+	}
+}
@@ -0,0 +1,320 @@
+package awsbundle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	identityproviderv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/identityprovider/v1"
+	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	"github.com/spiffe/spire/pkg/server/plugin/notifier"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakeidentityprovider"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRequiresIdentityProvider(t *testing.T) {
+	var err error
+	plugintest.Load(t, BuiltIn(), nil, plugintest.CaptureLoadError(&err))
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "IdentityProvider host service is required")
+}
+
+func TestConfigure(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         string
+		newClientErr   error
+		code           codes.Code
+		desc           string
+		expectedConfig *pluginConfig
+	}{
+		{
+			name:   "malformed",
+			config: "MALFORMED",
+			code:   codes.InvalidArgument,
+			desc:   "unable to decode configuration",
+		},
+		{
+			name: "missing region",
+			config: `
+				parameter_name = "bundle"
+			`,
+			code: codes.InvalidArgument,
+			desc: "region must be set",
+		},
+		{
+			name: "invalid format",
+			config: `
+				region = "us-east-1"
+				parameter_name = "bundle"
+				format = "der"
+			`,
+			code: codes.InvalidArgument,
+			desc: `format must be "pem" or "spiffe"`,
+		},
+		{
+			name: "bucket without object key",
+			config: `
+				region = "us-east-1"
+				bucket = "the-bucket"
+			`,
+			code: codes.InvalidArgument,
+			desc: "bucket and object_key must be set together",
+		},
+		{
+			name: "no destination",
+			config: `
+				region = "us-east-1"
+			`,
+			code: codes.InvalidArgument,
+			desc: "at least one of bucket or parameter_name must be set",
+		},
+		{
+			name: "failed to create client",
+			config: `
+				region = "us-east-1"
+				parameter_name = "bundle"
+			`,
+			newClientErr: errors.New("ohno"),
+			code:         codes.Internal,
+			desc:         "unable to create AWS client: ohno",
+		},
+		{
+			name: "success with defaults",
+			config: `
+				region = "us-east-1"
+				bucket = "the-bucket"
+				object_key = "bundle.pem"
+			`,
+			code: codes.OK,
+			expectedConfig: &pluginConfig{
+				Region:    "us-east-1",
+				Format:    "pem",
+				Bucket:    "the-bucket",
+				ObjectKey: "bundle.pem",
+			},
+		},
+		{
+			name: "success with all options",
+			config: `
+				region = "us-east-1"
+				access_key_id = "access-key-id"
+				secret_access_key = "secret-access-key"
+				assume_role_arn = "role-arn"
+				format = "spiffe"
+				bucket = "the-bucket"
+				object_key = "bundle.json"
+				parameter_name = "bundle"
+			`,
+			code: codes.OK,
+			expectedConfig: &pluginConfig{
+				Region:          "us-east-1",
+				AccessKeyID:     "access-key-id",
+				SecretAccessKey: "secret-access-key",
+				AssumeRoleARN:   "role-arn",
+				Format:          "spiffe",
+				Bucket:          "the-bucket",
+				ObjectKey:       "bundle.json",
+				ParameterName:   "bundle",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var gotConfig *pluginConfig
+			raw := New()
+			raw.hooks.newClient = func(config *pluginConfig) (awsClient, error) {
+				gotConfig = config
+				if tt.newClientErr != nil {
+					return nil, tt.newClientErr
+				}
+				return newFakeClient(), nil
+			}
+
+			var err error
+			plugintest.Load(t, builtIn(raw), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err),
+				plugintest.HostServices(identityproviderv1.IdentityProviderServiceServer(fakeidentityprovider.New())))
+			if tt.code != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.desc)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedConfig, gotConfig)
+		})
+	}
+}
+
+func TestNotifyBundleUpdated(t *testing.T) {
+	testPublishBundle(t, func(n notifier.Notifier) error {
+		return n.NotifyBundleUpdated(context.Background(), &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	})
+}
+
+func TestNotifyAndAdviseBundleLoaded(t *testing.T) {
+	testPublishBundle(t, func(n notifier.Notifier) error {
+		return n.NotifyAndAdviseBundleLoaded(context.Background(), &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	})
+}
+
+func testPublishBundle(t *testing.T, notify func(notifier.Notifier) error) {
+	bundle := &plugintypes.Bundle{
+		TrustDomain:     "example.org",
+		X509Authorities: []*plugintypes.X509Certificate{{Asn1: []byte("1")}},
+	}
+	pemBundle, _, err := formatBundle(bundle, formatPEM)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name            string
+		bundle          *plugintypes.Bundle
+		skipConfigure   bool
+		putObjectErr    error
+		putParameterErr error
+		code            codes.Code
+		desc            string
+		expectedObject  []byte
+		expectedParam   []byte
+	}{
+		{
+			name:          "not configured",
+			skipConfigure: true,
+			code:          codes.FailedPrecondition,
+			desc:          "notifier(aws_bundle): not configured",
+		},
+		{
+			name: "failed to fetch bundle from identity provider",
+			code: codes.Unknown,
+			desc: "notifier(aws_bundle): unable to fetch bundle from SPIRE server: no bundle",
+		},
+		{
+			name:         "failed to put object",
+			bundle:       bundle,
+			putObjectErr: errors.New("ohno"),
+			code:         codes.Unknown,
+			desc:         "notifier(aws_bundle): unable to update bundle object the-bucket/bundle.pem: ohno",
+		},
+		{
+			name:            "failed to put parameter",
+			bundle:          bundle,
+			putParameterErr: errors.New("ohno"),
+			code:            codes.Unknown,
+			desc:            "notifier(aws_bundle): unable to update bundle parameter the-parameter: ohno",
+		},
+		{
+			name:           "success",
+			bundle:         bundle,
+			code:           codes.OK,
+			expectedObject: pemBundle,
+			expectedParam:  pemBundle,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient()
+			client.putObjectErr = tt.putObjectErr
+			client.putParameterErr = tt.putParameterErr
+
+			raw := New()
+			raw.hooks.newClient = func(*pluginConfig) (awsClient, error) {
+				return client, nil
+			}
+
+			idp := fakeidentityprovider.New()
+			if tt.bundle != nil {
+				idp.AppendBundle(tt.bundle)
+			}
+
+			options := []plugintest.Option{
+				plugintest.HostServices(identityproviderv1.IdentityProviderServiceServer(idp)),
+			}
+			if !tt.skipConfigure {
+				options = append(options, plugintest.Configure(`
+					region = "us-east-1"
+					bucket = "the-bucket"
+					object_key = "bundle.pem"
+					parameter_name = "the-parameter"
+				`))
+			}
+
+			plugin := new(notifier.V1)
+			plugintest.Load(t, builtIn(raw), plugin, options...)
+
+			err := notify(plugin)
+			if tt.code != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.desc)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedObject, client.objects["the-bucket/bundle.pem"])
+			require.Equal(t, "application/x-pem-file", client.contentTypes["the-bucket/bundle.pem"])
+			require.Equal(t, tt.expectedParam, client.parameters["the-parameter"])
+		})
+	}
+}
+
+func TestFormatBundleSPIFFE(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+
+	data, contentType, err := formatBundle(&plugintypes.Bundle{
+		TrustDomain: "example.org",
+		X509Authorities: []*plugintypes.X509Certificate{
+			{Asn1: ca.X509Authorities()[0].Raw},
+		},
+	}, formatSPIFFE)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", contentType)
+
+	parsed, err := spiffebundle.Parse(td, data)
+	require.NoError(t, err)
+	require.Equal(t, ca.X509Authorities(), parsed.X509Authorities())
+}
+
+type fakeClient struct {
+	mu              sync.Mutex
+	objects         map[string][]byte
+	contentTypes    map[string]string
+	parameters      map[string][]byte
+	putObjectErr    error
+	putParameterErr error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		objects:      make(map[string][]byte),
+		contentTypes: make(map[string]string),
+		parameters:   make(map[string][]byte),
+	}
+}
+
+func (c *fakeClient) PutObject(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.putObjectErr != nil {
+		return c.putObjectErr
+	}
+	c.objects[bucket+"/"+key] = append([]byte(nil), data...)
+	c.contentTypes[bucket+"/"+key] = contentType
+	return nil
+}
+
+func (c *fakeClient) PutParameter(ctx context.Context, name string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.putParameterErr != nil {
+		return c.putParameterErr
+	}
+	c.parameters[name] = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,79 @@
+package awsbundle
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+type awsClient interface {
+	PutObject(ctx context.Context, bucket, key string, data []byte, contentType string) error
+	PutParameter(ctx context.Context, name string, data []byte) error
+}
+
+type sdkClient struct {
+	s3  *s3.S3
+	ssm *ssm.SSM
+}
+
+func newAWSClient(config *pluginConfig) (awsClient, error) {
+	awsConfig := &aws.Config{
+		Region: aws.String(config.Region),
+	}
+
+	if config.SecretAccessKey != "" && config.AccessKeyID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, "")
+	}
+
+	// Optional: Assuming role
+	if config.AssumeRoleARN != "" {
+		staticsess, err := session.NewSession(&aws.Config{Credentials: awsConfig.Credentials})
+		if err != nil {
+			return nil, err
+		}
+		awsConfig.Credentials = credentials.NewCredentials(&stscreds.AssumeRoleProvider{
+			Client:   sts.New(staticsess),
+			RoleARN:  config.AssumeRoleARN,
+			Duration: 15 * time.Minute,
+		})
+	}
+
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdkClient{
+		s3:  s3.New(awsSession),
+		ssm: ssm.New(awsSession),
+	}, nil
+}
+
+func (c *sdkClient) PutObject(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	_, err := c.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (c *sdkClient) PutParameter(ctx context.Context, name string, data []byte) error {
+	_, err := c.ssm.PutParameterWithContext(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Value:     aws.String(string(data)),
+		Type:      aws.String(ssm.ParameterTypeString),
+		Tier:      aws.String(ssm.ParameterTierIntelligentTiering),
+		Overwrite: aws.Bool(true),
+	})
+	return err
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	notifierv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/notifier/v1"
+	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "webhook"
+
+	// defaultTimeout is the timeout applied to each request when the
+	// configuration does not set one.
+	defaultTimeout = 5 * time.Second
+
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request
+	// body, computed with the configured secret.
+	SignatureHeader = "X-Spire-Signature"
+
+	// EventBundleLoaded is sent when the server loads the bundle on startup
+	EventBundleLoaded = "bundle_loaded"
+
+	// EventBundleUpdated is sent when the bundle is updated, e.g. when a
+	// CA or JWT key is prepared, or when a bundle is pruned
+	EventBundleUpdated = "bundle_updated"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtIn(New())
+}
+
+func builtIn(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		notifierv1.NotifierPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// Event is the JSON body posted to the webhook
+type Event struct {
+	Event       string    `json:"event"`
+	Time        time.Time `json:"time"`
+	TrustDomain string    `json:"trust_domain"`
+	Bundle      Bundle    `json:"bundle"`
+}
+
+// Bundle is the trust bundle carried by an event
+type Bundle struct {
+	X509Authorities []string       `json:"x509_authorities"`
+	JWTAuthorities  []JWTAuthority `json:"jwt_authorities"`
+	RefreshHint     int64          `json:"refresh_hint,omitempty"`
+	SequenceNumber  uint64         `json:"sequence_number,omitempty"`
+}
+
+// JWTAuthority is a JWT authority of the bundle
+type JWTAuthority struct {
+	KeyID string `json:"key_id"`
+	// PublicKey is the base64 encoded PKIX public key
+	PublicKey []byte `json:"public_key"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+type pluginConfig struct {
+	URL     string `hcl:"url"`
+	Secret  string `hcl:"secret"`
+	Timeout string `hcl:"timeout"`
+
+	timeout time.Duration
+}
+
+type Plugin struct {
+	notifierv1.UnsafeNotifierServer
+	configv1.UnsafeConfigServer
+
+	mu     sync.RWMutex
+	log    hclog.Logger
+	config *pluginConfig
+	client *http.Client
+
+	hooks struct {
+		now func() time.Time
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{
+		client: &http.Client{},
+	}
+	p.hooks.now = time.Now
+	return p
+}
+
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Notify(ctx context.Context, req *notifierv1.NotifyRequest) (*notifierv1.NotifyResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if event, ok := req.Event.(*notifierv1.NotifyRequest_BundleUpdated); ok {
+		if err := p.post(ctx, config, EventBundleUpdated, event.BundleUpdated.Bundle); err != nil {
+			return nil, err
+		}
+	}
+	return &notifierv1.NotifyResponse{}, nil
+}
+
+func (p *Plugin) NotifyAndAdvise(ctx context.Context, req *notifierv1.NotifyAndAdviseRequest) (*notifierv1.NotifyAndAdviseResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if event, ok := req.Event.(*notifierv1.NotifyAndAdviseRequest_BundleLoaded); ok {
+		if err := p.post(ctx, config, EventBundleLoaded, event.BundleLoaded.Bundle); err != nil {
+			return nil, err
+		}
+	}
+	return &notifierv1.NotifyAndAdviseResponse{}, nil
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(pluginConfig)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to decode configuration: %v", err)
+	}
+
+	if config.URL == "" {
+		return nil, status.Error(codes.InvalidArgument, "url must be set")
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, status.Errorf(codes.InvalidArgument, "url scheme must be http or https; got %q", u.Scheme)
+	}
+
+	config.timeout = defaultTimeout
+	if config.Timeout != "" {
+		config.timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to parse timeout: %v", err)
+		}
+		if config.timeout <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "timeout must be positive")
+		}
+	}
+
+	p.setConfig(config)
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) getConfig() (*pluginConfig, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) setConfig(config *pluginConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+}
+
+func (p *Plugin) post(ctx context.Context, config *pluginConfig, event string, bundle *plugintypes.Bundle) error {
+	body, err := json.Marshal(Event{
+		Event:       event,
+		Time:        p.hooks.now().UTC(),
+		TrustDomain: bundle.TrustDomain,
+		Bundle:      bundleFromPluginProto(bundle),
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to marshal event: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign([]byte(config.Secret), body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "unable to post %s event: %v", event, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return status.Errorf(codes.Unknown, "unable to post %s event: unexpected status code %d", event, resp.StatusCode)
+	}
+	p.log.Debug("Event posted to webhook", "event", event)
+	return nil
+}
+
+// Sign returns the value of the signature header for the given body, i.e.
+// "sha256=" followed by the hex encoded HMAC-SHA256 of the body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func bundleFromPluginProto(bundle *plugintypes.Bundle) Bundle {
+	b := Bundle{
+		X509Authorities: []string{},
+		JWTAuthorities:  []JWTAuthority{},
+		RefreshHint:     bundle.RefreshHint,
+		SequenceNumber:  bundle.SequenceNumber,
+	}
+	for _, x509Authority := range bundle.X509Authorities {
+		b.X509Authorities = append(b.X509Authorities, string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: x509Authority.Asn1,
+		})))
+	}
+	for _, jwtAuthority := range bundle.JwtAuthorities {
+		b.JWTAuthorities = append(b.JWTAuthorities, JWTAuthority{
+			KeyID:     jwtAuthority.KeyId,
+			PublicKey: jwtAuthority.PublicKey,
+			ExpiresAt: jwtAuthority.ExpiresAt,
+		})
+	}
+	return b
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spiffe/spire/pkg/server/plugin/notifier"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	now = time.Date(2022, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	bundle = &common.Bundle{
+		TrustDomainId:  "spiffe://example.org",
+		RootCas:        []*common.Certificate{{DerBytes: []byte("1")}},
+		JwtSigningKeys: []*common.PublicKey{{Kid: "KID", PkixBytes: []byte("2"), NotAfter: 1234}},
+		RefreshHint:    60,
+		SequenceNumber: 3,
+	}
+)
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config string
+		code   codes.Code
+		desc   string
+	}{
+		{
+			name:   "malformed",
+			config: `MALFORMED`,
+			code:   codes.InvalidArgument,
+			desc:   "unable to decode configuration",
+		},
+		{
+			name:   "missing url",
+			config: `secret = "s3cr3t"`,
+			code:   codes.InvalidArgument,
+			desc:   "url must be set",
+		},
+		{
+			name:   "unsupported url scheme",
+			config: `url = "ftp://example.org/hook"`,
+			code:   codes.InvalidArgument,
+			desc:   `url scheme must be http or https; got "ftp"`,
+		},
+		{
+			name: "invalid timeout",
+			config: `
+				url = "https://example.org/hook"
+				timeout = "foo"
+			`,
+			code: codes.InvalidArgument,
+			desc: "unable to parse timeout",
+		},
+		{
+			name: "non-positive timeout",
+			config: `
+				url = "https://example.org/hook"
+				timeout = "0s"
+			`,
+			code: codes.InvalidArgument,
+			desc: "timeout must be positive",
+		},
+		{
+			name: "success",
+			config: `
+				url = "https://example.org/hook"
+				secret = "s3cr3t"
+				timeout = "10s"
+			`,
+			code: codes.OK,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, BuiltIn(), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CaptureConfigureError(&err))
+			if tt.code != codes.OK {
+				spiretest.RequireGRPCStatusContains(t, err, tt.code, tt.desc)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNotifyBundleUpdated(t *testing.T) {
+	testPostEvent(t, EventBundleUpdated, func(n notifier.Notifier) error {
+		return n.NotifyBundleUpdated(context.Background(), bundle)
+	})
+}
+
+func TestNotifyAndAdviseBundleLoaded(t *testing.T) {
+	testPostEvent(t, EventBundleLoaded, func(n notifier.Notifier) error {
+		return n.NotifyAndAdviseBundleLoaded(context.Background(), bundle)
+	})
+}
+
+func testPostEvent(t *testing.T, event string, notify func(notifier.Notifier) error) {
+	expectedBody := fmt.Sprintf(`{
+		"event": %q,
+		"time": "2022-05-06T07:08:09Z",
+		"trust_domain": "example.org",
+		"bundle": {
+			"x509_authorities": ["-----BEGIN CERTIFICATE-----\nMQ==\n-----END CERTIFICATE-----\n"],
+			"jwt_authorities": [{"key_id": "KID", "public_key": %q, "expires_at": 1234}],
+			"refresh_hint": 60,
+			"sequence_number": 3
+		}
+	}`, event, base64.StdEncoding.EncodeToString([]byte("2")))
+
+	for _, tt := range []struct {
+		name            string
+		secret          string
+		statusCode      int
+		skipConfigure   bool
+		code            codes.Code
+		desc            string
+		expectSignature bool
+	}{
+		{
+			name:          "not configured",
+			skipConfigure: true,
+			code:          codes.FailedPrecondition,
+			desc:          "notifier(webhook): not configured",
+		},
+		{
+			name:       "webhook fails",
+			statusCode: http.StatusInternalServerError,
+			code:       codes.Unknown,
+			desc:       fmt.Sprintf("notifier(webhook): unable to post %s event: unexpected status code 500", event),
+		},
+		{
+			name:       "success without secret",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:            "success with secret",
+			secret:          "s3cr3t",
+			statusCode:      http.StatusOK,
+			expectSignature: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				body, err := io.ReadAll(req.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				assert.JSONEq(t, expectedBody, string(body))
+				if tt.expectSignature {
+					assert.Equal(t, Sign([]byte(tt.secret), body), req.Header.Get(SignatureHeader))
+				} else {
+					assert.Empty(t, req.Header.Get(SignatureHeader))
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			raw := New()
+			raw.hooks.now = func() time.Time { return now }
+
+			var options []plugintest.Option
+			if !tt.skipConfigure {
+				options = append(options, plugintest.Configure(fmt.Sprintf(`
+					url = %q
+					secret = %q
+				`, server.URL, tt.secret)))
+			}
+
+			plugin := new(notifier.V1)
+			plugintest.Load(t, builtIn(raw), plugin, options...)
+
+			err := notify(plugin)
+			if tt.code != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.desc)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, requests)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Known answer computed with: printf 'body' | openssl dgst -sha256 -hmac 'secret'
+	assert.Equal(t, "sha256=dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355", Sign([]byte("secret"), []byte("body")))
+}
@@ -25,6 +25,10 @@ import (
 
 const (
 	pluginName = "awssecret"
+
+	// defaultRefreshInterval is how often the CA credentials are read again
+	// from AWS Secrets Manager, unless configured otherwise.
+	defaultRefreshInterval = time.Hour
 )
 
 func BuiltIn() catalog.BuiltIn {
@@ -46,6 +50,9 @@ type Configuration struct {
 	SecretAccessKey string `hcl:"secret_access_key" json:"secret_access_key"`
 	SecurityToken   string `hcl:"secret_token" json:"secret_token"`
 	AssumeRoleARN   string `hcl:"assume_role_arn" json:"assume_role_arn"`
+	RefreshInterval string `hcl:"refresh_interval" json:"refresh_interval"`
+
+	refreshInterval time.Duration
 }
 
 type Plugin struct {
@@ -54,9 +61,13 @@ type Plugin struct {
 
 	log hclog.Logger
 
-	mtx        sync.RWMutex
-	cert       *x509.Certificate
-	upstreamCA *x509svid.UpstreamCA
+	mtx         sync.Mutex
+	config      *Configuration
+	sm          secretsManagerClient
+	trustDomain spiffeid.TrustDomain
+	cert        *x509.Certificate
+	upstreamCA  *x509svid.UpstreamCA
+	refreshAt   time.Time
 
 	hooks struct {
 		clock     clock.Clock
@@ -107,13 +118,10 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
-	p.cert = cert
-	p.upstreamCA = x509svid.NewUpstreamCA(
-		x509util.NewMemoryKeypair(cert, key),
-		trustDomain,
-		x509svid.UpstreamCAOptions{
-			Clock: p.hooks.clock,
-		})
+	p.config = config
+	p.sm = sm
+	p.trustDomain = trustDomain
+	p.setCA(key, cert)
 
 	return &configv1.ConfigureResponse{}, nil
 }
@@ -121,14 +129,13 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 // MintX509CAAndSubscribe mints an X509CA by signing presented CSR with root CA fetched from AWS Secrets Manager
 func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CARequest, stream upstreamauthorityv1.UpstreamAuthority_MintX509CAAndSubscribeServer) error {
 	ctx := stream.Context()
-	p.mtx.RLock()
-	defer p.mtx.RUnlock()
 
-	if p.upstreamCA == nil {
-		return status.Error(codes.FailedPrecondition, "not configured")
+	upstreamCA, upstreamCert, err := p.reloadCA(ctx)
+	if err != nil {
+		return err
 	}
 
-	cert, err := p.upstreamCA.SignCSR(ctx, request.Csr, time.Second*time.Duration(request.PreferredTtl))
+	cert, err := upstreamCA.SignCSR(ctx, request.Csr, time.Second*time.Duration(request.PreferredTtl))
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to sign CSR: %v", err)
 	}
@@ -138,7 +145,7 @@ func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CAR
 		return status.Errorf(codes.Internal, "unable to form response X.509 CA chain: %v", err)
 	}
 
-	upstreamX509Roots, err := x509certificate.ToPluginProtos([]*x509.Certificate{p.cert})
+	upstreamX509Roots, err := x509certificate.ToPluginProtos([]*x509.Certificate{upstreamCert})
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to form response upstream X.509 roots: %v", err)
 	}
@@ -154,6 +161,43 @@ func (p *Plugin) PublishJWTKeyAndSubscribe(*upstreamauthorityv1.PublishJWTKeyReq
 	return status.Error(codes.Unimplemented, "publishing upstream is unsupported")
 }
 
+// reloadCA returns the upstream CA, reading the CA credentials again from
+// AWS Secrets Manager if they are due for refresh. If they cannot be read,
+// the cached upstream CA is used and reading them is retried on the next
+// call.
+func (p *Plugin) reloadCA(ctx context.Context) (*x509svid.UpstreamCA, *x509.Certificate, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.upstreamCA == nil {
+		return nil, nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	if p.config.refreshInterval > 0 && !p.hooks.clock.Now().Before(p.refreshAt) {
+		key, cert, err := fetchFromSecretsManager(ctx, p.config, p.sm)
+		if err != nil {
+			p.log.Warn("Unable to refresh the upstream CA from AWS Secrets Manager; using cached CA", "error", err)
+		} else {
+			p.setCA(key, cert)
+		}
+	}
+
+	return p.upstreamCA, p.cert, nil
+}
+
+// setCA sets the upstream CA and schedules the next refresh. The caller must
+// hold the mutex.
+func (p *Plugin) setCA(key crypto.PrivateKey, cert *x509.Certificate) {
+	p.cert = cert
+	p.upstreamCA = x509svid.NewUpstreamCA(
+		x509util.NewMemoryKeypair(cert, key),
+		p.trustDomain,
+		x509svid.UpstreamCAOptions{
+			Clock: p.hooks.clock,
+		})
+	p.refreshAt = p.hooks.clock.Now().Add(p.config.refreshInterval)
+}
+
 func fetchFromSecretsManager(ctx context.Context, config *Configuration, sm secretsManagerClient) (crypto.PrivateKey, *x509.Certificate, error) {
 	keyPEMstr, err := readARN(ctx, sm, config.KeyFileARN)
 	if err != nil {
@@ -219,5 +263,17 @@ func (p *Plugin) validateConfig(req *configv1.ConfigureRequest) (*Configuration,
 		return nil, status.Error(codes.InvalidArgument, "configuration missing both cert ARN and key ARN")
 	}
 
+	config.refreshInterval = defaultRefreshInterval
+	if config.RefreshInterval != "" {
+		refreshInterval, err := time.ParseDuration(config.RefreshInterval)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to parse refresh_interval: %v", err)
+		}
+		if refreshInterval < 0 {
+			return nil, status.Error(codes.InvalidArgument, "refresh_interval must not be negative")
+		}
+		config.refreshInterval = refreshInterval
+	}
+
 	return config, nil
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"io"
+	"math/big"
 	"testing"
 	"time"
 
@@ -41,6 +42,7 @@ func TestConfigure(t *testing.T) {
 		secretAccessKey string
 		securityToken   string
 		assumeRoleARN   string
+		refreshInterval string
 	}{
 		{
 			test:            "success",
@@ -169,6 +171,26 @@ func TestConfigure(t *testing.T) {
 			expectCode:      codes.InvalidArgument,
 			expectMsgPrefix: "certificate and private key does not match",
 		},
+		{
+			test:            "malformed refresh interval",
+			region:          "region_1",
+			certFileARN:     "cert",
+			keyFileARN:      "key",
+			securityToken:   "security_token",
+			refreshInterval: "often",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to parse refresh_interval:",
+		},
+		{
+			test:            "negative refresh interval",
+			region:          "region_1",
+			certFileARN:     "cert",
+			keyFileARN:      "key",
+			securityToken:   "security_token",
+			refreshInterval: "-1h",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "refresh_interval must not be negative",
+		},
 	} {
 		tt := tt
 		t.Run(tt.test, func(t *testing.T) {
@@ -197,6 +219,7 @@ func TestConfigure(t *testing.T) {
 					SecretAccessKey: tt.secretAccessKey,
 					SecurityToken:   tt.securityToken,
 					AssumeRoleARN:   tt.assumeRoleARN,
+					RefreshInterval: tt.refreshInterval,
 				}))
 			}
 
@@ -335,6 +358,74 @@ func TestMintX509CA(t *testing.T) {
 	}
 }
 
+func TestMintX509CARefreshesCA(t *testing.T) {
+	clk := clock.NewMock()
+	csrKey := testkey.NewEC256(t)
+	csr, err := util.NewCSRTemplateWithKey("spiffe://example.org", csrKey)
+	require.NoError(t, err)
+
+	initialAuthority, err := pemutil.LoadCertificates("testdata/keys/EC/cert.pem")
+	require.NoError(t, err)
+
+	rotatedKey := testkey.NewEC256(t)
+	rotatedAuthority := spiretest.SelfSignCertificateWithKey(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		NotAfter:              clk.Now().Add(24 * time.Hour),
+	}, rotatedKey)
+	rotatedKeyPEM, err := pemutil.EncodePKCS8PrivateKey(rotatedKey)
+	require.NoError(t, err)
+
+	var sm *fakeSecretsManagerClient
+	p := new(Plugin)
+	p.hooks.clock = clk
+	p.hooks.getenv = func(s string) string {
+		return ""
+	}
+	p.hooks.newClient = func(config *Configuration, region string) (secretsManagerClient, error) {
+		client, err := newFakeSecretsManagerClient(config, region)
+		if err != nil {
+			return nil, err
+		}
+		sm = client.(*fakeSecretsManagerClient)
+		return sm, nil
+	}
+
+	ua := new(upstreamauthority.V1)
+	plugintest.Load(t, builtin(p), ua,
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.ConfigureJSON(&Configuration{
+			Region:          "region_1",
+			CertFileARN:     "cert",
+			KeyFileARN:      "key",
+			RefreshInterval: "1h",
+		}),
+	)
+
+	mintAuthorities := func() []*x509.Certificate {
+		_, x509Authorities, stream, err := ua.MintX509CA(context.Background(), csr, 0)
+		require.NoError(t, err)
+		stream.Close()
+		return x509Authorities
+	}
+
+	// The rotated CA is not read before the refresh interval elapses
+	sm.storage["cert"] = string(pemutil.EncodeCertificate(rotatedAuthority))
+	sm.storage["key"] = string(rotatedKeyPEM)
+	require.Equal(t, initialAuthority, mintAuthorities())
+
+	clk.Add(time.Hour)
+	require.Equal(t, []*x509.Certificate{rotatedAuthority}, mintAuthorities())
+
+	// The cached CA keeps being used when the secrets cannot be read
+	sm.storage["cert"] = "invalid"
+	clk.Add(time.Hour)
+	require.Equal(t, []*x509.Certificate{rotatedAuthority}, mintAuthorities())
+}
+
 func TestPublishJWTKey(t *testing.T) {
 	p := new(Plugin)
 	p.hooks.clock = clock.NewMock()
@@ -0,0 +1,218 @@
+package cfssl
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "cfssl"
+
+	// envAuthKey is the environment variable used for the auth key when it
+	// is not set in the configuration
+	envAuthKey = "CFSSL_AUTH_KEY"
+)
+
+// BuiltIn constructs a catalog.BuiltIn using a new instance of this plugin.
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		upstreamauthorityv1.UpstreamAuthorityPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// URL is the base URL of the CFSSL API server
+	URL string `hcl:"url" json:"url"`
+
+	// AuthKey is the hex encoded key used to authenticate sign requests,
+	// matching the "standard" auth key of the signing profile. Defaults to
+	// ${CFSSL_AUTH_KEY}. Requests are not authenticated if empty.
+	AuthKey string `hcl:"auth_key" json:"auth_key"`
+
+	// Label selects the signer of a multi-root CFSSL server
+	Label string `hcl:"label" json:"label"`
+
+	// Profile is the signing profile used to issue intermediate CA
+	// certificates
+	Profile string `hcl:"profile" json:"profile"`
+
+	// RootCertPath is the path to the roots used to authenticate a CFSSL
+	// server served over https. Defaults to the system roots.
+	RootCertPath string `hcl:"root_cert_path" json:"root_cert_path"`
+
+	// UpstreamBundlePath is the path to the PEM encoded roots of the
+	// signer. Defaults to the signer certificate when it is self-signed.
+	UpstreamBundlePath string `hcl:"upstream_bundle_path" json:"upstream_bundle_path"`
+}
+
+type Plugin struct {
+	// gRPC requires embedding either the "Unimplemented" or "Unsafe" stub as
+	// a way of opting in or out of forward build compatibility.
+	upstreamauthorityv1.UnsafeUpstreamAuthorityServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+	mtx sync.RWMutex
+
+	config      *Config
+	client      *client
+	upstreamCAs []*x509.Certificate
+
+	hooks struct {
+		getenv func(string) string
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.getenv = os.Getenv
+	return p
+}
+
+// SetLogger will be called by the catalog system to provide the plugin with
+// a logger when it is loaded. The logger is wired up to the SPIRE core
+// logger
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to decode configuration file: %v", err)
+	}
+
+	if config.AuthKey == "" {
+		config.AuthKey = p.hooks.getenv(envAuthKey)
+	}
+
+	if config.URL == "" {
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty url property")
+	}
+	baseURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse url: %v", err)
+	}
+	if (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, status.Error(codes.InvalidArgument, "url must be an http or https URL")
+	}
+
+	var authKey []byte
+	if config.AuthKey != "" {
+		authKey, err = hex.DecodeString(strings.TrimSpace(config.AuthKey))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to decode auth_key: %v", err)
+		}
+	}
+
+	var roots []*x509.Certificate
+	if config.RootCertPath != "" {
+		roots, err = pemutil.LoadCertificates(config.RootCertPath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load root certificates: %v", err)
+		}
+	}
+
+	var upstreamCAs []*x509.Certificate
+	if config.UpstreamBundlePath != "" {
+		upstreamCAs, err = pemutil.LoadCertificates(config.UpstreamBundlePath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load upstream bundle: %v", err)
+		}
+	}
+
+	if authKey == nil {
+		p.log.Warn("No auth_key configured; sign requests to CFSSL will not be authenticated")
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = config
+	p.client = newClient(baseURL, authKey, roots)
+	p.upstreamCAs = upstreamCAs
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CARequest, stream upstreamauthorityv1.UpstreamAuthority_MintX509CAAndSubscribeServer) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.config == nil {
+		return status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	// The lifetime of the certificate is set by the expiry of the CFSSL
+	// signing profile; the remote signing API does not take a TTL.
+	cert, err := p.client.Sign(stream.Context(), request.Csr, p.config.Label, p.config.Profile)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to sign CSR: %v", err)
+	}
+
+	// The signer certificate is fetched on every request to pick up a
+	// rotated signer.
+	signer, err := p.client.Info(stream.Context(), p.config.Label, p.config.Profile)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to fetch signer certificate: %v", err)
+	}
+
+	caChain := []*x509.Certificate{cert}
+	var roots []*x509.Certificate
+	if isSelfSigned(signer) {
+		roots = []*x509.Certificate{signer}
+	} else {
+		caChain = append(caChain, signer)
+	}
+	if p.upstreamCAs != nil {
+		roots = p.upstreamCAs
+	}
+	if len(roots) == 0 {
+		return status.Error(codes.Internal, "signer certificate is not a root; upstream_bundle_path must be configured")
+	}
+
+	x509CAChain, err := x509certificate.ToPluginProtos(caChain)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response X.509 CA chain: %v", err)
+	}
+
+	upstreamX509Roots, err := x509certificate.ToPluginProtos(roots)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response upstream X.509 roots: %v", err)
+	}
+
+	return stream.Send(&upstreamauthorityv1.MintX509CAResponse{
+		X509CaChain:       x509CAChain,
+		UpstreamX509Roots: upstreamX509Roots,
+	})
+}
+
+// PublishJWTKeyAndSubscribe is not implemented by the wrapper and returns a codes.Unimplemented status
+func (*Plugin) PublishJWTKeyAndSubscribe(*upstreamauthorityv1.PublishJWTKeyRequest, upstreamauthorityv1.UpstreamAuthority_PublishJWTKeyAndSubscribeServer) error {
+	return status.Error(codes.Unimplemented, "publishing upstream is unsupported")
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
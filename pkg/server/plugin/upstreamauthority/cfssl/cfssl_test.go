@@ -0,0 +1,303 @@
+package cfssl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/spiffe/spire/test/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+	authKey     = []byte("0123456789abcdef")
+)
+
+func TestMintX509CA(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	csrDER, _, err := util.NewCSRTemplate(trustDomain.IDString())
+	require.NoError(t, err)
+
+	rootCert, rootKey := testca.CreateCACertificate(t, nil, nil)
+	intermediateCert, intermediateKey := testca.CreateCACertificate(t, rootCert, rootKey)
+	caCert, _ := testca.CreateCACertificate(t, intermediateCert, intermediateKey)
+
+	upstreamBundlePath := filepath.Join(dir, "bundle.pem")
+	require.NoError(t, os.WriteFile(upstreamBundlePath, pemutil.EncodeCertificate(rootCert), 0600))
+
+	for _, tt := range []struct {
+		name            string
+		config          Config
+		signer          *x509.Certificate
+		signStatus      int
+		signResponse    *response
+		infoStatus      int
+		expectEndpoint  string
+		expectCode      codes.Code
+		expectMsg       string
+		expectX509CA    []*x509.Certificate
+		expectAuthority []*x509.Certificate
+	}{
+		{
+			name:            "root signer",
+			signer:          rootCert,
+			expectEndpoint:  "/api/v1/cfssl/sign",
+			expectX509CA:    []*x509.Certificate{intermediateCert},
+			expectAuthority: []*x509.Certificate{rootCert},
+		},
+		{
+			name:            "authenticated sign request",
+			config:          Config{AuthKey: hex.EncodeToString(authKey)},
+			signer:          rootCert,
+			expectEndpoint:  "/api/v1/cfssl/authsign",
+			expectX509CA:    []*x509.Certificate{intermediateCert},
+			expectAuthority: []*x509.Certificate{rootCert},
+		},
+		{
+			name:            "intermediate signer with upstream bundle",
+			config:          Config{UpstreamBundlePath: upstreamBundlePath},
+			signer:          intermediateCert,
+			expectEndpoint:  "/api/v1/cfssl/sign",
+			expectX509CA:    []*x509.Certificate{caCert, intermediateCert},
+			expectAuthority: []*x509.Certificate{rootCert},
+		},
+		{
+			name:           "intermediate signer without upstream bundle",
+			signer:         intermediateCert,
+			expectEndpoint: "/api/v1/cfssl/sign",
+			expectCode:     codes.Internal,
+			expectMsg:      "upstreamauthority(cfssl): signer certificate is not a root; upstream_bundle_path must be configured",
+		},
+		{
+			name:           "sign fails",
+			signer:         rootCert,
+			signStatus:     http.StatusBadRequest,
+			signResponse:   &response{Errors: []responseError{{Code: 2400, Message: "invalid token"}}},
+			expectEndpoint: "/api/v1/cfssl/sign",
+			expectCode:     codes.Internal,
+			expectMsg:      "upstreamauthority(cfssl): failed to sign CSR: unexpected status code 400: invalid token (code 2400)",
+		},
+		{
+			name:           "fetching signer fails",
+			signer:         rootCert,
+			infoStatus:     http.StatusInternalServerError,
+			expectEndpoint: "/api/v1/cfssl/sign",
+			expectCode:     codes.Internal,
+			expectMsg:      "upstreamauthority(cfssl): failed to fetch signer certificate: unexpected status code 500",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// The signed certificate is the one issued by the signer
+			signed := intermediateCert
+			if tt.signer == intermediateCert {
+				signed = caCert
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+
+				switch r.URL.Path {
+				case "/api/v1/cfssl/sign", "/api/v1/cfssl/authsign":
+					assert.Equal(t, tt.expectEndpoint, r.URL.Path)
+					if r.URL.Path == "/api/v1/cfssl/authsign" {
+						var authReq authenticatedRequest
+						assert.NoError(t, json.Unmarshal(body, &authReq))
+						mac := hmac.New(sha256.New, authKey)
+						_, _ = mac.Write(authReq.Request)
+						assert.True(t, hmac.Equal(mac.Sum(nil), authReq.Token), "token does not match request")
+						body = authReq.Request
+					}
+
+					var req signRequest
+					assert.NoError(t, json.Unmarshal(body, &req))
+					block, _ := pem.Decode([]byte(req.CertificateRequest))
+					if assert.NotNil(t, block) {
+						assert.Equal(t, csrDER, block.Bytes)
+					}
+					assert.Equal(t, "intermediate", req.Profile)
+					assert.Equal(t, "primary", req.Label)
+
+					status := http.StatusOK
+					if tt.signStatus != 0 {
+						status = tt.signStatus
+					}
+					resp := tt.signResponse
+					if resp == nil {
+						resp = successResponse(t, signed)
+					}
+					writeResponse(w, status, resp)
+				case "/api/v1/cfssl/info":
+					var req infoRequest
+					assert.NoError(t, json.Unmarshal(body, &req))
+					assert.Equal(t, infoRequest{Label: "primary", Profile: "intermediate"}, req)
+					if tt.infoStatus != 0 {
+						w.WriteHeader(tt.infoStatus)
+						return
+					}
+					writeResponse(w, http.StatusOK, successResponse(t, tt.signer))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.URL = server.URL
+			config.Label = "primary"
+			config.Profile = "intermediate"
+
+			p := New()
+			p.hooks.getenv = func(string) string { return "" }
+
+			ua := new(upstreamauthority.V1)
+			plugintest.Load(t, builtin(p), ua,
+				plugintest.ConfigureJSON(config),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+			)
+
+			x509CA, x509Authorities, stream, err := ua.MintX509CA(context.Background(), csrDER, time.Hour)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			require.Equal(t, tt.expectX509CA, x509CA)
+			require.Equal(t, tt.expectAuthority, x509Authorities)
+
+			// Plugin does not support streaming back changes so assert the
+			// stream returns EOF.
+			_, streamErr := stream.RecvUpstreamX509Authorities()
+			assert.True(t, errors.Is(streamErr, io.EOF))
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	dir := spiretest.TempDir(t)
+
+	rootCertPath := filepath.Join(dir, "root.pem")
+	require.NoError(t, os.WriteFile(rootCertPath, pemutil.EncodeCertificate(testca.New(t, trustDomain).X509Authorities()[0]), 0600))
+
+	for _, tt := range []struct {
+		name       string
+		config     string
+		env        map[string]string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "MALFORMED",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to decode configuration file: ",
+		},
+		{
+			name:       "missing URL",
+			config:     `profile = "intermediate"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty url property",
+		},
+		{
+			name:       "URL is not http",
+			config:     `url = "ftp://cfssl.example.org"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "url must be an http or https URL",
+		},
+		{
+			name:       "auth key is not hex",
+			config:     `url = "https://cfssl.example.org" auth_key = "not-hex"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode auth_key: ",
+		},
+		{
+			name:       "auth key from environment is not hex",
+			config:     `url = "https://cfssl.example.org"`,
+			env:        map[string]string{envAuthKey: "not-hex"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to decode auth_key: ",
+		},
+		{
+			name:       "root cert does not exist",
+			config:     `url = "https://cfssl.example.org" root_cert_path = "/does/not/exist"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load root certificates: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:       "upstream bundle does not exist",
+			config:     `url = "https://cfssl.example.org" upstream_bundle_path = "/does/not/exist"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load upstream bundle: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:   "unauthenticated",
+			config: `url = "http://cfssl.example.org:8888"`,
+		},
+		{
+			name:   "auth key",
+			config: `url = "https://cfssl.example.org" auth_key = "0123456789ABCDEF" root_cert_path = "` + rootCertPath + `" label = "primary" profile = "intermediate"`,
+		},
+		{
+			name:   "auth key from environment",
+			config: `url = "https://cfssl.example.org"`,
+			env:    map[string]string{envAuthKey: "0123456789ABCDEF"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.hooks.getenv = func(key string) string {
+				return tt.env[key]
+			}
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+				plugintest.CaptureConfigureError(&err),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func successResponse(t *testing.T, cert *x509.Certificate) *response {
+	result, err := json.Marshal(certificateResult{
+		Certificate: string(pemutil.EncodeCertificate(cert)),
+	})
+	require.NoError(t, err)
+	return &response{Success: true, Result: result}
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,195 @@
+package cfssl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/pemutil"
+)
+
+const (
+	// maxResponseSize is the maximum size of a CFSSL response body
+	maxResponseSize = 1024 * 1024
+)
+
+type signRequest struct {
+	CertificateRequest string `json:"certificate_request"`
+	Profile            string `json:"profile,omitempty"`
+	Label              string `json:"label,omitempty"`
+}
+
+// authenticatedRequest wraps a request authenticated with an auth key. The
+// token is the HMAC-SHA256 of the request bytes. Both are base64 encoded by
+// the JSON encoder.
+type authenticatedRequest struct {
+	Token   []byte `json:"token"`
+	Request []byte `json:"request"`
+}
+
+type infoRequest struct {
+	Label   string `json:"label,omitempty"`
+	Profile string `json:"profile,omitempty"`
+}
+
+type certificateResult struct {
+	Certificate string `json:"certificate"`
+}
+
+type response struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []responseError `json:"errors"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// client is a minimal client of the CFSSL remote signing API
+type client struct {
+	baseURL    *url.URL
+	authKey    []byte
+	httpClient *http.Client
+}
+
+func newClient(baseURL *url.URL, authKey []byte, roots []*x509.Certificate) *client {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(roots) > 0 {
+		pool := x509.NewCertPool()
+		for _, root := range roots {
+			pool.AddCert(root)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &client{
+		baseURL: baseURL,
+		authKey: authKey,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: time.Minute,
+		},
+	}
+}
+
+// Sign requests CFSSL to sign the CSR with the signer selected by the label
+// and profile. Requests are sent to the authsign endpoint when an auth key is
+// configured.
+func (c *client) Sign(ctx context.Context, csr []byte, label, profile string) (*x509.Certificate, error) {
+	req, err := json.Marshal(signRequest{
+		CertificateRequest: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE REQUEST",
+			Bytes: csr,
+		})),
+		Profile: profile,
+		Label:   label,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "sign"
+	if c.authKey != nil {
+		endpoint = "authsign"
+		mac := hmac.New(sha256.New, c.authKey)
+		_, _ = mac.Write(req)
+		req, err = json.Marshal(authenticatedRequest{
+			Token:   mac.Sum(nil),
+			Request: req,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := new(certificateResult)
+	if err := c.post(ctx, endpoint, req, result); err != nil {
+		return nil, err
+	}
+	return parseCertificate(result.Certificate)
+}
+
+// Info returns the certificate of the signer selected by the label and
+// profile.
+func (c *client) Info(ctx context.Context, label, profile string) (*x509.Certificate, error) {
+	req, err := json.Marshal(infoRequest{
+		Label:   label,
+		Profile: profile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(certificateResult)
+	if err := c.post(ctx, "info", req, result); err != nil {
+		return nil, err
+	}
+	return parseCertificate(result.Certificate)
+}
+
+func (c *client) post(ctx context.Context, endpoint string, body []byte, result interface{}) error {
+	u := c.baseURL.ResolveReference(&url.URL{Path: "/api/v1/cfssl/" + endpoint})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// CFSSL returns the same envelope on success and on failure
+	r := new(response)
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(r); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !r.Success || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var messages []string
+		for _, e := range r.Errors {
+			messages = append(messages, fmt.Sprintf("%s (code %d)", e.Message, e.Code))
+		}
+		if len(messages) == 0 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("failed to decode response result: %w", err)
+	}
+	return nil
+}
+
+func parseCertificate(certPEM string) (*x509.Certificate, error) {
+	if certPEM == "" {
+		return nil, errors.New("no certificate returned")
+	}
+	cert, err := pemutil.ParseCertificate([]byte(certPEM))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %w", err)
+	}
+	return cert, nil
+}
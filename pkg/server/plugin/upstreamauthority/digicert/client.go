@@ -0,0 +1,151 @@
+package digicert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// maxResponseSize is the maximum size of a CertCentral response body
+	maxResponseSize = 1024 * 1024
+
+	orderStatusIssued = "issued"
+)
+
+type orderRequest struct {
+	Certificate  orderCertificate  `json:"certificate"`
+	Organization orderOrganization `json:"organization"`
+	ValidityDays int               `json:"validity_days,omitempty"`
+}
+
+type orderCertificate struct {
+	CommonName    string `json:"common_name"`
+	CSR           string `json:"csr"`
+	SignatureHash string `json:"signature_hash"`
+	ProfileOption string `json:"profile_option,omitempty"`
+	CACertID      string `json:"ca_cert_id,omitempty"`
+}
+
+type orderOrganization struct {
+	ID int `json:"id"`
+}
+
+type orderResponse struct {
+	ID int `json:"id"`
+}
+
+type order struct {
+	ID          int    `json:"id"`
+	Status      string `json:"status"`
+	Certificate struct {
+		ID int `json:"id"`
+	} `json:"certificate"`
+}
+
+type errorResponse struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// client is a minimal client of the CertCentral services API
+type client struct {
+	apiURL     string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func newClient(apiURL, apiKey string) *client {
+	return &client{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		apiKey: apiKey,
+		httpClient: &http.Client{
+			Timeout: time.Minute,
+		},
+	}
+}
+
+// OrderCertificate orders a certificate for the given product and returns
+// the order ID.
+func (c *client) OrderCertificate(ctx context.Context, productNameID string, req orderRequest) (int, error) {
+	resp := new(orderResponse)
+	if err := c.doJSON(ctx, http.MethodPost, "/order/certificate/"+productNameID, req, resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// GetOrder returns the order with the given ID
+func (c *client) GetOrder(ctx context.Context, orderID int) (*order, error) {
+	resp := new(order)
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/order/certificate/%d", orderID), nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DownloadCertificateChain returns the PEM encoded certificate chain of an
+// issued certificate, starting with the certificate itself.
+func (c *client) DownloadCertificateChain(ctx context.Context, certificateID int) ([]byte, error) {
+	body, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/certificate/%d/download/format/pem_all", certificateID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(io.LimitReader(body, maxResponseSize))
+}
+
+func (c *client) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	body, err := c.do(ctx, method, path, reqBody)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(body, maxResponseSize)).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *client) do(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-DC-DEVKEY", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		errResp := new(errorResponse)
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(errResp); err == nil && len(errResp.Errors) > 0 {
+			return nil, fmt.Errorf("unexpected status code %d: %s: %s", resp.StatusCode, errResp.Errors[0].Code, errResp.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
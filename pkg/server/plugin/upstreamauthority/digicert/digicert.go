@@ -0,0 +1,288 @@
+package digicert
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "digicert"
+
+	// envAPIKey is the environment variable used for the API key when it is
+	// not set in the configuration
+	envAPIKey = "DIGICERT_API_KEY"
+
+	defaultAPIURL       = "https://www.digicert.com/services/v2"
+	defaultPollInterval = 10 * time.Second
+	defaultTimeout      = 5 * time.Minute
+
+	day = 24 * time.Hour
+)
+
+// BuiltIn constructs a catalog.BuiltIn using a new instance of this plugin.
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		upstreamauthorityv1.UpstreamAuthorityPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// APIURL is the base URL of the CertCentral services API
+	APIURL string `hcl:"api_url" json:"api_url"`
+
+	// APIKey is the CertCentral API key. Defaults to ${DIGICERT_API_KEY}.
+	APIKey string `hcl:"api_key" json:"api_key"`
+
+	// OrganizationID is the ID of the organization ordering the certificates
+	OrganizationID int `hcl:"organization_id" json:"organization_id"`
+
+	// ProductNameID is the product ordered, e.g. "private_ssl_plus"
+	ProductNameID string `hcl:"product_name_id" json:"product_name_id"`
+
+	// ProfileOption is the custom certificate profile of the product used
+	// to issue intermediate CA certificates
+	ProfileOption string `hcl:"profile_option" json:"profile_option"`
+
+	// CACertID is the ID of the issuing CA
+	CACertID string `hcl:"ca_cert_id" json:"ca_cert_id"`
+
+	// UpstreamBundlePath is the path to the PEM encoded roots of the issuing
+	// CA. Defaults to the self-signed certificates of the issued chain.
+	UpstreamBundlePath string `hcl:"upstream_bundle_path" json:"upstream_bundle_path"`
+}
+
+type Plugin struct {
+	// gRPC requires embedding either the "Unimplemented" or "Unsafe" stub as
+	// a way of opting in or out of forward build compatibility.
+	upstreamauthorityv1.UnsafeUpstreamAuthorityServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+	mtx sync.RWMutex
+
+	config      *Config
+	trustDomain string
+	client      *client
+	upstreamCAs []*x509.Certificate
+
+	hooks struct {
+		getenv       func(string) string
+		pollInterval time.Duration
+		timeout      time.Duration
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.getenv = os.Getenv
+	p.hooks.pollInterval = defaultPollInterval
+	p.hooks.timeout = defaultTimeout
+	return p
+}
+
+// SetLogger will be called by the catalog system to provide the plugin with
+// a logger when it is loaded. The logger is wired up to the SPIRE core
+// logger
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to decode configuration file: %v", err)
+	}
+
+	if config.APIURL == "" {
+		config.APIURL = defaultAPIURL
+	}
+	if config.APIKey == "" {
+		config.APIKey = p.hooks.getenv(envAPIKey)
+	}
+
+	switch {
+	case config.APIKey == "":
+		return nil, status.Errorf(codes.InvalidArgument, "configuration has empty api_key property and %s is not set", envAPIKey)
+	case config.OrganizationID == 0:
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty organization_id property")
+	case config.ProductNameID == "":
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty product_name_id property")
+	}
+
+	if req.CoreConfiguration == nil || req.CoreConfiguration.TrustDomain == "" {
+		return nil, status.Error(codes.InvalidArgument, "trust_domain is required")
+	}
+
+	var upstreamCAs []*x509.Certificate
+	if config.UpstreamBundlePath != "" {
+		var err error
+		upstreamCAs, err = pemutil.LoadCertificates(config.UpstreamBundlePath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load upstream bundle: %v", err)
+		}
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = config
+	p.trustDomain = req.CoreConfiguration.TrustDomain
+	p.client = newClient(config.APIURL, config.APIKey)
+	p.upstreamCAs = upstreamCAs
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CARequest, stream upstreamauthorityv1.UpstreamAuthority_MintX509CAAndSubscribeServer) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.config == nil {
+		return status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), p.hooks.timeout)
+	defer cancel()
+
+	orderID, err := p.client.OrderCertificate(ctx, p.config.ProductNameID, p.buildOrderRequest(request))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to order certificate: %v", err)
+	}
+
+	log := p.log.With("order_id", orderID)
+	log.Info("Waiting for certificate order to be issued")
+	certificateID, err := p.waitForOrder(ctx, log, orderID)
+	if err != nil {
+		return err
+	}
+
+	chainPEM, err := p.client.DownloadCertificateChain(ctx, certificateID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to download certificate chain: %v", err)
+	}
+
+	certs, err := pemutil.ParseCertificates(chainPEM)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to parse certificate chain: %v", err)
+	}
+
+	// The downloaded chain ends with the root, which is returned as an
+	// upstream root unless the roots are configured.
+	var caChain, roots []*x509.Certificate
+	for _, cert := range certs {
+		if isSelfSigned(cert) {
+			roots = append(roots, cert)
+			continue
+		}
+		caChain = append(caChain, cert)
+	}
+	if p.upstreamCAs != nil {
+		roots = p.upstreamCAs
+	}
+	if len(roots) == 0 {
+		return status.Error(codes.Internal, "certificate chain does not include a root; upstream_bundle_path must be configured")
+	}
+
+	x509CAChain, err := x509certificate.ToPluginProtos(caChain)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response X.509 CA chain: %v", err)
+	}
+
+	upstreamX509Roots, err := x509certificate.ToPluginProtos(roots)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response upstream X.509 roots: %v", err)
+	}
+
+	return stream.Send(&upstreamauthorityv1.MintX509CAResponse{
+		X509CaChain:       x509CAChain,
+		UpstreamX509Roots: upstreamX509Roots,
+	})
+}
+
+// PublishJWTKeyAndSubscribe is not implemented by the wrapper and returns a codes.Unimplemented status
+func (*Plugin) PublishJWTKeyAndSubscribe(*upstreamauthorityv1.PublishJWTKeyRequest, upstreamauthorityv1.UpstreamAuthority_PublishJWTKeyAndSubscribeServer) error {
+	return status.Error(codes.Unimplemented, "publishing upstream is unsupported")
+}
+
+func (p *Plugin) buildOrderRequest(request *upstreamauthorityv1.MintX509CARequest) orderRequest {
+	req := orderRequest{
+		Certificate: orderCertificate{
+			CommonName: p.trustDomain,
+			CSR: string(pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE REQUEST",
+				Bytes: request.Csr,
+			})),
+			SignatureHash: "sha256",
+			ProfileOption: p.config.ProfileOption,
+			CACertID:      p.config.CACertID,
+		},
+		Organization: orderOrganization{
+			ID: p.config.OrganizationID,
+		},
+	}
+
+	// CertCentral validity is expressed in days. Round up so the issued
+	// certificate is never shorter than requested.
+	if request.PreferredTtl > 0 {
+		ttl := time.Duration(request.PreferredTtl) * time.Second
+		req.ValidityDays = int((ttl + day - 1) / day)
+	}
+	return req
+}
+
+// waitForOrder polls the order until it has been issued and returns the ID
+// of the issued certificate.
+func (p *Plugin) waitForOrder(ctx context.Context, log hclog.Logger, orderID int) (int, error) {
+	ticker := time.NewTicker(p.hooks.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		order, err := p.client.GetOrder(ctx, orderID)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			log.Error("Failed to wait for certificate order to be issued in time")
+			return 0, status.Error(codes.DeadlineExceeded, "certificate order was not issued in time")
+		case err != nil:
+			return 0, status.Errorf(codes.Internal, "failed to get certificate order: %v", err)
+		}
+
+		switch order.Status {
+		case orderStatusIssued:
+			return order.Certificate.ID, nil
+		case "rejected", "canceled", "revoked", "expired":
+			log.Error("Certificate order was not issued", "status", order.Status)
+			return 0, status.Errorf(codes.Internal, "certificate order is %s", order.Status)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Error("Failed to wait for certificate order to be issued in time", "status", order.Status)
+			return 0, status.Error(codes.DeadlineExceeded, "certificate order was not issued in time")
+		}
+	}
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
@@ -0,0 +1,285 @@
+package digicert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/spiffe/spire/test/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestMintX509CA(t *testing.T) {
+	csrDER, _, err := util.NewCSRTemplate(trustDomain.IDString())
+	require.NoError(t, err)
+
+	rootCert, rootKey := testca.CreateCACertificate(t, nil, nil)
+	intermediateCert, intermediateKey := testca.CreateCACertificate(t, rootCert, rootKey)
+	caCert, _ := testca.CreateCACertificate(t, intermediateCert, intermediateKey)
+	expectedChain := []*x509.Certificate{caCert, intermediateCert}
+
+	otherRoot, _ := testca.CreateCACertificate(t, nil, nil)
+	bundlePath := filepath.Join(spiretest.TempDir(t), "bundle.pem")
+	require.NoError(t, os.WriteFile(bundlePath, pemutil.EncodeCertificate(otherRoot), 0600))
+
+	for _, tt := range []struct {
+		name               string
+		upstreamBundlePath string
+		ttl                time.Duration
+		orderStatus        int
+		orderResponse      string
+		statuses           []string
+		downloadChain      []*x509.Certificate
+		expectValidityDays int
+		expectCode         codes.Code
+		expectMsg          string
+		expectRoots        []*x509.Certificate
+	}{
+		{
+			name:               "issued",
+			ttl:                time.Hour,
+			statuses:           []string{"issued"},
+			expectValidityDays: 1,
+			expectRoots:        []*x509.Certificate{rootCert},
+		},
+		{
+			name:               "issued after approval",
+			ttl:                25 * time.Hour,
+			statuses:           []string{"pending", "needs_approval", "issued"},
+			expectValidityDays: 2,
+			expectRoots:        []*x509.Certificate{rootCert},
+		},
+		{
+			name:               "upstream bundle",
+			upstreamBundlePath: bundlePath,
+			ttl:                time.Hour,
+			statuses:           []string{"issued"},
+			downloadChain:      expectedChain,
+			expectValidityDays: 1,
+			expectRoots:        []*x509.Certificate{otherRoot},
+		},
+		{
+			name:               "no root in chain",
+			ttl:                time.Hour,
+			statuses:           []string{"issued"},
+			downloadChain:      expectedChain,
+			expectValidityDays: 1,
+			expectCode:         codes.Internal,
+			expectMsg:          "upstreamauthority(digicert): certificate chain does not include a root; upstream_bundle_path must be configured",
+		},
+		{
+			name:               "order fails",
+			ttl:                time.Hour,
+			orderStatus:        http.StatusBadRequest,
+			orderResponse:      `{"errors": [{"code": "invalid_product", "message": "Invalid product."}]}`,
+			expectValidityDays: 1,
+			expectCode:         codes.Internal,
+			expectMsg:          "upstreamauthority(digicert): failed to order certificate: unexpected status code 400: invalid_product: Invalid product.",
+		},
+		{
+			name:               "order rejected",
+			ttl:                time.Hour,
+			statuses:           []string{"pending", "rejected"},
+			expectValidityDays: 1,
+			expectCode:         codes.Internal,
+			expectMsg:          "upstreamauthority(digicert): certificate order is rejected",
+		},
+		{
+			name:               "not issued in time",
+			ttl:                time.Hour,
+			statuses:           []string{"pending"},
+			expectValidityDays: 1,
+			expectCode:         codes.DeadlineExceeded,
+			expectMsg:          "upstreamauthority(digicert): certificate order was not issued in time",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			downloadChain := tt.downloadChain
+			if downloadChain == nil {
+				downloadChain = []*x509.Certificate{caCert, intermediateCert, rootCert}
+			}
+
+			var polls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "api-key", r.Header.Get("X-DC-DEVKEY"))
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/services/v2/order/certificate/private_ssl_plus":
+					var req orderRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+					block, _ := pem.Decode([]byte(req.Certificate.CSR))
+					if assert.NotNil(t, block) {
+						assert.Equal(t, csrDER, block.Bytes)
+					}
+					req.Certificate.CSR = ""
+					assert.Equal(t, orderRequest{
+						Certificate: orderCertificate{
+							CommonName:    "example.org",
+							SignatureHash: "sha256",
+							ProfileOption: "spire_intermediate",
+							CACertID:      "ABCDEF",
+						},
+						Organization: orderOrganization{ID: 1234},
+						ValidityDays: tt.expectValidityDays,
+					}, req)
+
+					if tt.orderStatus != 0 {
+						w.WriteHeader(tt.orderStatus)
+						_, _ = w.Write([]byte(tt.orderResponse))
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id": 42}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/services/v2/order/certificate/42":
+					status := tt.statuses[len(tt.statuses)-1]
+					if polls < len(tt.statuses) {
+						status = tt.statuses[polls]
+					}
+					polls++
+					_, _ = fmt.Fprintf(w, `{"id": 42, "status": %q, "certificate": {"id": 7}}`, status)
+				case r.Method == http.MethodGet && r.URL.Path == "/services/v2/certificate/7/download/format/pem_all":
+					_, _ = w.Write(pemutil.EncodeCertificates(downloadChain))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			p := New()
+			p.hooks.getenv = func(string) string { return "" }
+			p.hooks.pollInterval = 10 * time.Millisecond
+			p.hooks.timeout = 500 * time.Millisecond
+
+			ua := new(upstreamauthority.V1)
+			plugintest.Load(t, builtin(p), ua,
+				plugintest.ConfigureJSON(&Config{
+					APIURL:             server.URL + "/services/v2",
+					APIKey:             "api-key",
+					OrganizationID:     1234,
+					ProductNameID:      "private_ssl_plus",
+					ProfileOption:      "spire_intermediate",
+					CACertID:           "ABCDEF",
+					UpstreamBundlePath: tt.upstreamBundlePath,
+				}),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+			)
+
+			x509CA, x509Authorities, stream, err := ua.MintX509CA(context.Background(), csrDER, tt.ttl)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			require.Equal(t, expectedChain, x509CA)
+			require.Equal(t, tt.expectRoots, x509Authorities)
+
+			// Plugin does not support streaming back changes so assert the
+			// stream returns EOF.
+			_, streamErr := stream.RecvUpstreamX509Authorities()
+			assert.True(t, errors.Is(streamErr, io.EOF))
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		config     string
+		env        map[string]string
+		coreConfig *catalog.CoreConfig
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "MALFORMED",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to decode configuration file: ",
+		},
+		{
+			name:       "missing API key",
+			config:     `organization_id = 1234 product_name_id = "private_ssl_plus"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty api_key property and DIGICERT_API_KEY is not set",
+		},
+		{
+			name:       "missing organization ID",
+			config:     `api_key = "api-key" product_name_id = "private_ssl_plus"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty organization_id property",
+		},
+		{
+			name:       "missing product name ID",
+			config:     `api_key = "api-key" organization_id = 1234`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty product_name_id property",
+		},
+		{
+			name:       "no trust domain",
+			config:     `api_key = "api-key" organization_id = 1234 product_name_id = "private_ssl_plus"`,
+			coreConfig: &catalog.CoreConfig{},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "trust_domain is required",
+		},
+		{
+			name:       "upstream bundle does not exist",
+			config:     `api_key = "api-key" organization_id = 1234 product_name_id = "private_ssl_plus" upstream_bundle_path = "/does/not/exist"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load upstream bundle: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:   "API key from configuration",
+			config: `api_key = "api-key" organization_id = 1234 product_name_id = "private_ssl_plus"`,
+		},
+		{
+			name:   "API key from environment",
+			config: `organization_id = 1234 product_name_id = "private_ssl_plus"`,
+			env:    map[string]string{"DIGICERT_API_KEY": "api-key"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.hooks.getenv = func(key string) string {
+				return tt.env[key]
+			}
+
+			coreConfig := catalog.CoreConfig{TrustDomain: trustDomain}
+			if tt.coreConfig != nil {
+				coreConfig = *tt.coreConfig
+			}
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CoreConfig(coreConfig),
+				plugintest.CaptureConfigureError(&err),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
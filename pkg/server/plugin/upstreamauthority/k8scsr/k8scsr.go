@@ -0,0 +1,324 @@
+package k8scsr
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
+	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	pluginName = "k8s_csr"
+
+	// trustDomainLabel labels the CertificateSigningRequests created by the
+	// plugin with the trust domain of the server
+	trustDomainLabel = "spire.spiffe.io/trust-domain"
+
+	// minExpirationSeconds is the minimum expiration that can be requested
+	// through the CertificateSigningRequest API
+	minExpirationSeconds = 600
+
+	defaultPollInterval = time.Second
+	defaultTimeout      = 5 * time.Minute
+)
+
+// BuiltIn constructs a catalog.BuiltIn using a new instance of this plugin.
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		upstreamauthorityv1.UpstreamAuthorityPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// File path to the kubeconfig used to build the Kubernetes client.
+	KubeConfigFilePath string `hcl:"kube_config_file" json:"kube_config_file"`
+
+	// SignerName is the signer requested to sign the CertificateSigningRequests
+	SignerName string `hcl:"signer_name" json:"signer_name"`
+
+	// AutoApprove approves the CertificateSigningRequests on creation. The
+	// Kubernetes client needs permission to approve requests for the signer.
+	AutoApprove bool `hcl:"auto_approve" json:"auto_approve"`
+
+	// UpstreamBundlePath is the path to the PEM encoded roots of the signer.
+	// Defaults to the CA of the Kubernetes cluster.
+	UpstreamBundlePath string `hcl:"upstream_bundle_path" json:"upstream_bundle_path"`
+}
+
+// hooks used by unit tests
+type hooks struct {
+	newClient    func(configPath string) (kubernetes.Interface, []byte, error)
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+type Plugin struct {
+	// gRPC requires embedding either the "Unimplemented" or "Unsafe" stub as
+	// a way of opting in or out of forward build compatibility.
+	upstreamauthorityv1.UnsafeUpstreamAuthorityServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+	mtx sync.RWMutex
+
+	config      *Config
+	trustDomain string
+	client      kubernetes.Interface
+	upstreamCAs []*plugintypes.X509Certificate
+
+	hooks hooks
+}
+
+func New() *Plugin {
+	return &Plugin{
+		hooks: hooks{
+			newClient:    newKubeClient,
+			pollInterval: defaultPollInterval,
+			timeout:      defaultTimeout,
+		},
+	}
+}
+
+// SetLogger will be called by the catalog system to provide the plugin with
+// a logger when it is loaded. The logger is wired up to the SPIRE core
+// logger
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to decode configuration file: %v", err)
+	}
+
+	if config.SignerName == "" {
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty signer_name property")
+	}
+
+	if req.CoreConfiguration == nil || req.CoreConfiguration.TrustDomain == "" {
+		return nil, status.Error(codes.InvalidArgument, "trust_domain is required")
+	}
+
+	client, clusterCA, err := p.hooks.newClient(config.KubeConfigFilePath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create Kubernetes client: %v", err)
+	}
+
+	bundlePEM := clusterCA
+	if config.UpstreamBundlePath != "" {
+		bundlePEM, err = os.ReadFile(config.UpstreamBundlePath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to read upstream bundle: %v", err)
+		}
+	}
+	if len(bundlePEM) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "upstream_bundle_path is required when the Kubernetes client has no cluster CA")
+	}
+	upstreamCerts, err := pemutil.ParseCertificates(bundlePEM)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse upstream bundle: %v", err)
+	}
+	upstreamCAs, err := x509certificate.ToPluginProtos(upstreamCerts)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to form upstream bundle: %v", err)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = config
+	p.trustDomain = req.CoreConfiguration.TrustDomain
+	p.client = client
+	p.upstreamCAs = upstreamCAs
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CARequest, stream upstreamauthorityv1.UpstreamAuthority_MintX509CAAndSubscribeServer) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.config == nil {
+		return status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), p.hooks.timeout)
+	defer cancel()
+
+	csrs := p.client.CertificatesV1().CertificateSigningRequests()
+	csr, err := csrs.Create(ctx, p.buildCertificateSigningRequest(request), metav1.CreateOptions{})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to create CertificateSigningRequest: %v", err)
+	}
+
+	log := p.log.With("name", csr.Name, "signer_name", p.config.SignerName)
+	defer func() {
+		// Signed requests are garbage collected by Kubernetes after an hour,
+		// but there is no need to keep them around.
+		if err := csrs.Delete(context.Background(), csr.Name, metav1.DeleteOptions{}); err != nil {
+			log.Warn("Failed to delete CertificateSigningRequest", "error", err.Error())
+		}
+	}()
+
+	if p.config.AutoApprove {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:    certificatesv1.CertificateApproved,
+			Status:  corev1.ConditionTrue,
+			Reason:  "SPIREAutoApproved",
+			Message: "Approved by the SPIRE Server k8s_csr UpstreamAuthority plugin",
+		})
+		if _, err := csrs.UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{}); err != nil {
+			return status.Errorf(codes.Internal, "failed to approve CertificateSigningRequest: %v", err)
+		}
+	}
+
+	log.Info("Waiting for CertificateSigningRequest to be signed")
+	certPEM, err := p.waitForCertificate(ctx, log, csr.Name)
+	if err != nil {
+		return err
+	}
+
+	caChain, err := pemutil.ParseCertificates(certPEM)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to parse certificate: %v", err)
+	}
+
+	x509CAChain, err := x509certificate.ToPluginProtos(caChain)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response X.509 CA chain: %v", err)
+	}
+
+	return stream.Send(&upstreamauthorityv1.MintX509CAResponse{
+		X509CaChain:       x509CAChain,
+		UpstreamX509Roots: p.upstreamCAs,
+	})
+}
+
+// PublishJWTKeyAndSubscribe is not implemented by the wrapper and returns a codes.Unimplemented status
+func (*Plugin) PublishJWTKeyAndSubscribe(*upstreamauthorityv1.PublishJWTKeyRequest, upstreamauthorityv1.UpstreamAuthority_PublishJWTKeyAndSubscribeServer) error {
+	return status.Error(codes.Unimplemented, "publishing upstream is unsupported")
+}
+
+func (p *Plugin) buildCertificateSigningRequest(request *upstreamauthorityv1.MintX509CARequest) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "spire-ca-",
+			Labels: map[string]string{
+				trustDomainLabel: p.trustDomain,
+			},
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request: pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE REQUEST",
+				Bytes: request.Csr,
+			}),
+			SignerName: p.config.SignerName,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageCertSign,
+				certificatesv1.UsageCRLSign,
+			},
+		},
+	}
+
+	if request.PreferredTtl > 0 {
+		expirationSeconds := request.PreferredTtl
+		if expirationSeconds < minExpirationSeconds {
+			expirationSeconds = minExpirationSeconds
+		}
+		csr.Spec.ExpirationSeconds = &expirationSeconds
+	}
+	return csr
+}
+
+// waitForCertificate polls the CertificateSigningRequest until it has been
+// signed, denied or failed.
+func (p *Plugin) waitForCertificate(ctx context.Context, log hclog.Logger, name string) ([]byte, error) {
+	ticker := time.NewTicker(p.hooks.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Error("Failed to wait for CertificateSigningRequest to be signed in time")
+			return nil, status.Error(codes.DeadlineExceeded, "request was not signed in time")
+		}
+
+		csr, err := p.client.CertificatesV1().CertificateSigningRequests().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get CertificateSigningRequest: %v", err)
+		}
+
+		for _, cond := range csr.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case certificatesv1.CertificateDenied:
+				log.Error("CertificateSigningRequest has been denied", "reason", cond.Reason, "message", cond.Message)
+				return nil, status.Error(codes.PermissionDenied, "request has been denied")
+			case certificatesv1.CertificateFailed:
+				log.Error("CertificateSigningRequest has failed", "reason", cond.Reason, "message", cond.Message)
+				return nil, status.Error(codes.Internal, "request has failed")
+			}
+		}
+
+		if len(csr.Status.Certificate) > 0 {
+			return csr.Status.Certificate, nil
+		}
+	}
+}
+
+func newKubeClient(configPath string) (kubernetes.Interface, []byte, error) {
+	config, err := getKubeConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clusterCA := config.TLSClientConfig.CAData
+	if len(clusterCA) == 0 && config.TLSClientConfig.CAFile != "" {
+		clusterCA, err = os.ReadFile(config.TLSClientConfig.CAFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("unable to read cluster CA: %w", err)
+		}
+	}
+	return client, clusterCA, nil
+}
+
+func getKubeConfig(configPath string) (*rest.Config, error) {
+	if configPath != "" {
+		return clientcmd.BuildConfigFromFlags("", configPath)
+	}
+	return rest.InClusterConfig()
+}
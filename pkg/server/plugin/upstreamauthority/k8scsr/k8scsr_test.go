@@ -0,0 +1,289 @@
+package k8scsr
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestMintX509CA(t *testing.T) {
+	csrDER, _, err := util.NewCSRTemplate(trustDomain.IDString())
+	require.NoError(t, err)
+
+	ca, caPEM := testingCAPEM(t)
+
+	for _, tt := range []struct {
+		name         string
+		autoApprove  bool
+		updateCSR    func(csr *certificatesv1.CertificateSigningRequest)
+		expectCode   codes.Code
+		expectMsg    string
+		expectX509CA []*x509.Certificate
+	}{
+		{
+			name: "signed",
+			updateCSR: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = caPEM
+			},
+			expectX509CA: []*x509.Certificate{ca},
+		},
+		{
+			name:        "signed with auto approval",
+			autoApprove: true,
+			updateCSR: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = caPEM
+			},
+			expectX509CA: []*x509.Certificate{ca},
+		},
+		{
+			name: "denied",
+			updateCSR: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+					Type:   certificatesv1.CertificateDenied,
+					Status: corev1.ConditionTrue,
+				})
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "upstreamauthority(k8s_csr): request has been denied",
+		},
+		{
+			name: "failed",
+			updateCSR: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+					Type:   certificatesv1.CertificateFailed,
+					Status: corev1.ConditionTrue,
+				})
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "upstreamauthority(k8s_csr): request has failed",
+		},
+		{
+			name: "invalid certificate",
+			updateCSR: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Status.Certificate = []byte("bad certificate")
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "upstreamauthority(k8s_csr): failed to parse certificate: no PEM blocks",
+		},
+		{
+			name:       "not signed in time",
+			expectCode: codes.DeadlineExceeded,
+			expectMsg:  "upstreamauthority(k8s_csr): request was not signed in time",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			created := make(chan string, 1)
+			client.PrependReactor("create", "certificatesigningrequests", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				// The fake clientset does not generate names
+				csr := action.(k8stesting.CreateAction).GetObject().(*certificatesv1.CertificateSigningRequest)
+				csr.Name = csr.GenerateName + "test"
+				created <- csr.Name
+				return false, nil, nil
+			})
+
+			p := New()
+			p.hooks.newClient = func(configPath string) (kubernetes.Interface, []byte, error) {
+				return client, caPEM, nil
+			}
+			p.hooks.pollInterval = 10 * time.Millisecond
+			p.hooks.timeout = time.Second
+
+			ua := new(upstreamauthority.V1)
+			plugintest.Load(t, builtin(p), ua,
+				plugintest.ConfigureJSON(&Config{
+					SignerName:  "example.org/spire",
+					AutoApprove: tt.autoApprove,
+				}),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+			)
+
+			signed := make(chan struct{})
+			go func() {
+				defer close(signed)
+				name := <-created
+				csrs := client.CertificatesV1().CertificateSigningRequests()
+
+				var csr *certificatesv1.CertificateSigningRequest
+				assert.Eventually(t, func() bool {
+					var err error
+					csr, err = csrs.Get(context.Background(), name, metav1.GetOptions{})
+					return assert.NoError(t, err) && (!tt.autoApprove || len(csr.Status.Conditions) > 0)
+				}, time.Second, 10*time.Millisecond)
+
+				assert.Equal(t, "example.org/spire", csr.Spec.SignerName)
+				assert.Equal(t, map[string]string{trustDomainLabel: "example.org"}, csr.Labels)
+				assert.Equal(t, []certificatesv1.KeyUsage{certificatesv1.UsageCertSign, certificatesv1.UsageCRLSign}, csr.Spec.Usages)
+				if assert.NotNil(t, csr.Spec.ExpirationSeconds) {
+					assert.Equal(t, int32(3600), *csr.Spec.ExpirationSeconds)
+				}
+				block, _ := pem.Decode(csr.Spec.Request)
+				if assert.NotNil(t, block) {
+					assert.Equal(t, csrDER, block.Bytes)
+				}
+				if tt.autoApprove {
+					assert.Equal(t, certificatesv1.CertificateApproved, csr.Status.Conditions[0].Type)
+					assert.Equal(t, corev1.ConditionTrue, csr.Status.Conditions[0].Status)
+				}
+
+				if tt.updateCSR != nil {
+					tt.updateCSR(csr)
+					_, err := csrs.UpdateStatus(context.Background(), csr, metav1.UpdateOptions{})
+					assert.NoError(t, err)
+				}
+			}()
+
+			x509CA, x509Authorities, stream, err := ua.MintX509CA(context.Background(), csrDER, time.Hour)
+			<-signed
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode == codes.OK {
+				require.Equal(t, tt.expectX509CA, x509CA)
+				require.Equal(t, []*x509.Certificate{ca}, x509Authorities)
+
+				// Plugin does not support streaming back changes so assert the
+				// stream returns EOF.
+				_, streamErr := stream.RecvUpstreamX509Authorities()
+				assert.True(t, errors.Is(streamErr, io.EOF))
+			}
+
+			// The CertificateSigningRequest is deleted once done
+			list, err := client.CertificatesV1().CertificateSigningRequests().List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Empty(t, list.Items)
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	_, caPEM := testingCAPEM(t)
+	bundlePath := filepath.Join(spiretest.TempDir(t), "bundle.pem")
+	require.NoError(t, os.WriteFile(bundlePath, caPEM, 0600))
+
+	for _, tt := range []struct {
+		name         string
+		config       string
+		coreConfig   *catalog.CoreConfig
+		clusterCA    []byte
+		newClientErr error
+		expectCode   codes.Code
+		expectMsg    string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "MALFORMED",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to decode configuration file: ",
+		},
+		{
+			name:       "missing signer name",
+			config:     "",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty signer_name property",
+		},
+		{
+			name:       "no trust domain",
+			config:     `signer_name = "example.org/spire"`,
+			coreConfig: &catalog.CoreConfig{},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "trust_domain is required",
+		},
+		{
+			name:         "failed to create client",
+			config:       `signer_name = "example.org/spire"`,
+			newClientErr: errors.New("oh no"),
+			expectCode:   codes.Internal,
+			expectMsg:    "failed to create Kubernetes client: oh no",
+		},
+		{
+			name:       "no upstream bundle",
+			config:     `signer_name = "example.org/spire"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "upstream_bundle_path is required when the Kubernetes client has no cluster CA",
+		},
+		{
+			name:       "malformed cluster CA",
+			config:     `signer_name = "example.org/spire"`,
+			clusterCA:  []byte("bad certificate"),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to parse upstream bundle: no PEM blocks",
+		},
+		{
+			name:       "upstream bundle does not exist",
+			config:     `signer_name = "example.org/spire" upstream_bundle_path = "/does/not/exist"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to read upstream bundle: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:      "cluster CA",
+			config:    `signer_name = "example.org/spire"`,
+			clusterCA: caPEM,
+		},
+		{
+			name:   "upstream bundle",
+			config: `signer_name = "example.org/spire" upstream_bundle_path = "` + bundlePath + `"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.hooks.newClient = func(configPath string) (kubernetes.Interface, []byte, error) {
+				if tt.newClientErr != nil {
+					return nil, nil, tt.newClientErr
+				}
+				return fake.NewSimpleClientset(), tt.clusterCA, nil
+			}
+
+			coreConfig := catalog.CoreConfig{TrustDomain: trustDomain}
+			if tt.coreConfig != nil {
+				coreConfig = *tt.coreConfig
+			}
+
+			var err error
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CoreConfig(coreConfig),
+				plugintest.CaptureConfigureError(&err),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func testingCAPEM(t *testing.T) (*x509.Certificate, []byte) {
+	ca, _, err := util.LoadCAFixture()
+	require.NoError(t, err)
+	return ca, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ca.Raw,
+	})
+}
@@ -15,7 +15,7 @@ import (
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
-	"github.com/spiffe/spire/pkg/common/util"
+	common_workloadapi "github.com/spiffe/spire/pkg/common/workloadapi"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -49,12 +49,10 @@ type serverClient struct {
 
 // start initializes spire-server endpoints client, it uses X509 source to keep an active connection
 func (c *serverClient) start(ctx context.Context) error {
-	clientOption, err := util.GetWorkloadAPIClientOption(c.workloadAPIAddr)
-	if err != nil {
-		return status.Errorf(codes.Internal, "could not get Workload API client options: %v", err)
-	}
-	source, err := workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(clientOption,
-		workloadapi.WithLogger(c.log)))
+	source, err := common_workloadapi.NewX509Source(ctx, common_workloadapi.Config{
+		Addr: c.workloadAPIAddr,
+		Log:  c.log,
+	})
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to create X509Source: %v", err)
 	}
@@ -0,0 +1,165 @@
+package stepca
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/pemutil"
+)
+
+const (
+	// maxResponseSize is the maximum size of a step-ca response body
+	maxResponseSize = 1024 * 1024
+)
+
+type signRequest struct {
+	CSR      string `json:"csr"`
+	OTT      string `json:"ott"`
+	NotAfter string `json:"notAfter,omitempty"`
+}
+
+type signResponse struct {
+	Crt       string   `json:"crt"`
+	CA        string   `json:"ca"`
+	CertChain []string `json:"certChain"`
+}
+
+type rootsResponse struct {
+	Certificates []string `json:"crts"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// client is a minimal client of the step-ca HTTP API
+type client struct {
+	caURL      *url.URL
+	httpClient *http.Client
+}
+
+func newClient(caURL *url.URL, roots []*x509.Certificate) *client {
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root)
+	}
+	return &client{
+		caURL: caURL,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:    pool,
+					MinVersion: tls.VersionTLS12,
+				},
+			},
+			Timeout: time.Minute,
+		},
+	}
+}
+
+// Sign requests step-ca to sign the CSR using the provisioner token and
+// returns the certificate chain.
+func (c *client) Sign(ctx context.Context, csr []byte, token string, ttl time.Duration) ([]*x509.Certificate, error) {
+	req := signRequest{
+		CSR: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE REQUEST",
+			Bytes: csr,
+		})),
+		OTT: token,
+	}
+	if ttl > 0 {
+		req.NotAfter = ttl.String()
+	}
+
+	resp := new(signResponse)
+	if err := c.do(ctx, http.MethodPost, "/1.0/sign", req, resp); err != nil {
+		return nil, err
+	}
+
+	// Older step-ca versions do not return the certificate chain
+	chainPEM := resp.CertChain
+	if len(chainPEM) == 0 {
+		chainPEM = []string{resp.Crt, resp.CA}
+	}
+
+	var chain []*x509.Certificate
+	for _, certPEM := range chainPEM {
+		certs, err := pemutil.ParseCertificates([]byte(certPEM))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate chain: %w", err)
+		}
+		chain = append(chain, certs...)
+	}
+	return chain, nil
+}
+
+// Roots returns the root certificates of step-ca
+func (c *client) Roots(ctx context.Context) ([]*x509.Certificate, error) {
+	resp := new(rootsResponse)
+	if err := c.do(ctx, http.MethodGet, "/roots", nil, resp); err != nil {
+		return nil, err
+	}
+
+	var roots []*x509.Certificate
+	for _, rootPEM := range resp.Certificates {
+		certs, err := pemutil.ParseCertificates([]byte(rootPEM))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse roots: %w", err)
+		}
+		roots = append(roots, certs...)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no roots returned")
+	}
+	return roots, nil
+}
+
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := c.caURL.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errResp := new(errorResponse)
+		if err := dec.Decode(errResp); err == nil && errResp.Message != "" {
+			return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package stepca
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/coretypes/x509certificate"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	pluginName = "step_ca"
+
+	// tokenLifetime is the lifetime of the one-time tokens used to
+	// authenticate against the provisioner
+	tokenLifetime = 5 * time.Minute
+)
+
+// BuiltIn constructs a catalog.BuiltIn using a new instance of this plugin.
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		upstreamauthorityv1.UpstreamAuthorityPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+type Config struct {
+	// CAURL is the URL of the step-ca server
+	CAURL string `hcl:"ca_url" json:"ca_url"`
+
+	// RootCertPath is the path to the root certificate of the step-ca
+	// server, used to authenticate the server.
+	RootCertPath string `hcl:"root_cert_path" json:"root_cert_path"`
+
+	// ProvisionerName is the name of the JWK or X5C provisioner
+	ProvisionerName string `hcl:"provisioner_name" json:"provisioner_name"`
+
+	// JWKKeyPath is the path to the private key of a JWK provisioner. The
+	// key can be a JSON Web Key or a JWE encrypted JSON Web Key, as stored
+	// by step-ca in the provisioner configuration.
+	JWKKeyPath string `hcl:"jwk_key_path" json:"jwk_key_path"`
+
+	// JWKPasswordPath is the path to the password used to decrypt the JWK
+	// provisioner key.
+	JWKPasswordPath string `hcl:"jwk_password_path" json:"jwk_password_path"`
+
+	// X5CCertPath is the path to the certificate chain of an X5C provisioner
+	X5CCertPath string `hcl:"x5c_cert_path" json:"x5c_cert_path"`
+
+	// X5CKeyPath is the path to the private key of an X5C provisioner
+	X5CKeyPath string `hcl:"x5c_key_path" json:"x5c_key_path"`
+}
+
+// tokenClaims are the claims of the one-time tokens used to authorize sign
+// requests
+type tokenClaims struct {
+	jwt.Claims
+	SANs []string `json:"sans"`
+}
+
+type Plugin struct {
+	// gRPC requires embedding either the "Unimplemented" or "Unsafe" stub as
+	// a way of opting in or out of forward build compatibility.
+	upstreamauthorityv1.UnsafeUpstreamAuthorityServer
+	configv1.UnsafeConfigServer
+
+	log hclog.Logger
+	mtx sync.RWMutex
+
+	config   *Config
+	client   *client
+	audience string
+	jwk      *jose.JSONWebKey
+
+	hooks struct {
+		clock clock.Clock
+	}
+}
+
+func New() *Plugin {
+	p := &Plugin{}
+	p.hooks.clock = clock.New()
+	return p
+}
+
+// SetLogger will be called by the catalog system to provide the plugin with
+// a logger when it is loaded. The logger is wired up to the SPIRE core
+// logger
+func (p *Plugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	config := new(Config)
+	if err := hcl.Decode(config, req.HclConfiguration); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to decode configuration file: %v", err)
+	}
+
+	switch {
+	case config.CAURL == "":
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty ca_url property")
+	case config.RootCertPath == "":
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty root_cert_path property")
+	case config.ProvisionerName == "":
+		return nil, status.Error(codes.InvalidArgument, "configuration has empty provisioner_name property")
+	}
+
+	caURL, err := url.Parse(config.CAURL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse ca_url: %v", err)
+	}
+	if caURL.Scheme != "https" || caURL.Host == "" {
+		return nil, status.Error(codes.InvalidArgument, "ca_url must be an https URL")
+	}
+
+	roots, err := pemutil.LoadCertificates(config.RootCertPath)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to load root certificates: %v", err)
+	}
+
+	var jwk *jose.JSONWebKey
+	switch {
+	case config.JWKKeyPath != "" && config.X5CCertPath != "":
+		return nil, status.Error(codes.InvalidArgument, "only one of jwk_key_path or x5c_cert_path can be configured")
+	case config.JWKKeyPath != "":
+		jwk, err = loadJWK(config.JWKKeyPath, config.JWKPasswordPath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load JWK provisioner key: %v", err)
+		}
+	case config.X5CCertPath != "":
+		if config.X5CKeyPath == "" {
+			return nil, status.Error(codes.InvalidArgument, "x5c_key_path is required when x5c_cert_path is configured")
+		}
+		// Credentials are loaded on every request so that renewed
+		// provisioner certificates are picked up. Load them once to fail
+		// early on misconfiguration.
+		if _, _, err := loadX5C(config.X5CCertPath, config.X5CKeyPath); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load X5C provisioner credentials: %v", err)
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "one of jwk_key_path or x5c_cert_path is required")
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = config
+	p.client = newClient(caURL, roots)
+	p.audience = caURL.ResolveReference(&url.URL{Path: "/1.0/sign"}).String()
+	p.jwk = jwk
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) MintX509CAAndSubscribe(request *upstreamauthorityv1.MintX509CARequest, stream upstreamauthorityv1.UpstreamAuthority_MintX509CAAndSubscribeServer) error {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if p.config == nil {
+		return status.Error(codes.FailedPrecondition, "not configured")
+	}
+
+	csr, err := x509.ParseCertificateRequest(request.Csr)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "unable to parse CSR: %v", err)
+	}
+
+	token, err := p.newToken(csr)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to create provisioner token: %v", err)
+	}
+
+	caChain, err := p.client.Sign(stream.Context(), request.Csr, token, time.Duration(request.PreferredTtl)*time.Second)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to sign CSR: %v", err)
+	}
+
+	// Roots are fetched on every request to pick up roots added to step-ca
+	// during a root rotation.
+	roots, err := p.client.Roots(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to fetch roots: %v", err)
+	}
+
+	x509CAChain, err := x509certificate.ToPluginProtos(caChain)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response X.509 CA chain: %v", err)
+	}
+
+	upstreamX509Roots, err := x509certificate.ToPluginProtos(roots)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to form response upstream X.509 roots: %v", err)
+	}
+
+	return stream.Send(&upstreamauthorityv1.MintX509CAResponse{
+		X509CaChain:       x509CAChain,
+		UpstreamX509Roots: upstreamX509Roots,
+	})
+}
+
+// PublishJWTKeyAndSubscribe is not implemented by the wrapper and returns a codes.Unimplemented status
+func (*Plugin) PublishJWTKeyAndSubscribe(*upstreamauthorityv1.PublishJWTKeyRequest, upstreamauthorityv1.UpstreamAuthority_PublishJWTKeyAndSubscribeServer) error {
+	return status.Error(codes.Unimplemented, "publishing upstream is unsupported")
+}
+
+// newToken creates a one-time token authorizing the provisioner to sign the
+// CSR. The subject and SANs of the token must match the ones in the CSR.
+func (p *Plugin) newToken(csr *x509.CertificateRequest) (string, error) {
+	var key crypto.PrivateKey
+	opts := new(jose.SignerOptions).WithType("JWT")
+	if p.jwk != nil {
+		key = p.jwk.Key
+		opts = opts.WithHeader("kid", p.jwk.KeyID)
+	} else {
+		chain, x5cKey, err := loadX5C(p.config.X5CCertPath, p.config.X5CKeyPath)
+		if err != nil {
+			return "", err
+		}
+		var x5c []string
+		for _, cert := range chain {
+			x5c = append(x5c, base64.StdEncoding.EncodeToString(cert.Raw))
+		}
+		key = x5cKey
+		opts = opts.WithHeader("x5c", x5c)
+	}
+
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+	if p.jwk != nil && p.jwk.Algorithm != "" {
+		alg = jose.SignatureAlgorithm(p.jwk.Algorithm)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	sans := csrSANs(csr)
+	subject := csr.Subject.CommonName
+	if subject == "" && len(sans) > 0 {
+		subject = sans[0]
+	}
+
+	jti, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	now := p.hooks.clock.Now()
+	return jwt.Signed(signer).Claims(tokenClaims{
+		Claims: jwt.Claims{
+			ID:        jti.String(),
+			Issuer:    p.config.ProvisionerName,
+			Subject:   subject,
+			Audience:  jwt.Audience{p.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(tokenLifetime)),
+		},
+		SANs: sans,
+	}).CompactSerialize()
+}
+
+// csrSANs returns the SANs of the CSR in the format expected by step-ca
+func csrSANs(csr *x509.CertificateRequest) []string {
+	var sans []string
+	for _, uri := range csr.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, csr.DNSNames...)
+	for _, ip := range csr.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, csr.EmailAddresses...)
+	return sans
+}
+
+func signatureAlgorithm(key crypto.PrivateKey) (jose.SignatureAlgorithm, error) {
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		default:
+			return "", fmt.Errorf("unsupported elliptic curve %q", key.Curve.Params().Name)
+		}
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+func loadJWK(keyPath, passwordPath string) (*jose.JSONWebKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// step-ca stores provisioner keys encrypted in compact JWE form
+	if passwordPath != "" {
+		password, err := os.ReadFile(passwordPath)
+		if err != nil {
+			return nil, err
+		}
+		jwe, err := jose.ParseEncrypted(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse encrypted key: %w", err)
+		}
+		data, err = jwe.Decrypt([]byte(strings.TrimSpace(string(password))))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt key: %w", err)
+		}
+	}
+
+	jwk := new(jose.JSONWebKey)
+	if err := jwk.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unable to parse key: %w", err)
+	}
+	if jwk.IsPublic() {
+		return nil, errors.New("key is not a private key")
+	}
+	return jwk, nil
+}
+
+func loadX5C(certPath, keyPath string) ([]*x509.Certificate, crypto.Signer, error) {
+	chain, err := pemutil.LoadCertificates(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := pemutil.LoadSigner(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain, key, nil
+}
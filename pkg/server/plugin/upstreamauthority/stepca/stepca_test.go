@@ -0,0 +1,400 @@
+package stepca
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/plugin/upstreamauthority"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/spiffe/spire/test/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	trustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestMintX509CA(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	csrDER, _, err := util.NewCSRTemplate(trustDomain.IDString())
+	require.NoError(t, err)
+
+	rootCert, rootKey := testca.CreateCACertificate(t, nil, nil)
+	intermediateCert, intermediateKey := testca.CreateCACertificate(t, rootCert, rootKey)
+	caCert, _ := testca.CreateCACertificate(t, intermediateCert, intermediateKey)
+	expectedChain := []*x509.Certificate{caCert, intermediateCert}
+
+	jwkKey := testkey.NewEC256(t)
+	jwk := jose.JSONWebKey{Key: jwkKey, KeyID: "jwk-key"}
+	jwkPath := writeJSON(t, dir, "jwk.json", jwk)
+
+	encryptedJWKPath, passwordPath := writeEncryptedJWK(t, dir, jwk, "password")
+
+	x5cCA := testca.New(t, trustDomain)
+	x5cCertPath, x5cKeyPath := writeX5C(t, dir, x5cCA)
+
+	clk := clock.NewMock(t)
+
+	for _, tt := range []struct {
+		name         string
+		config       Config
+		signStatus   int
+		signResponse interface{}
+		rootsStatus  int
+		verifyKey    func(t *testing.T, header jose.Header) crypto.PublicKey
+		expectCode   codes.Code
+		expectMsg    string
+		expectX509CA []*x509.Certificate
+	}{
+		{
+			name:   "JWK provisioner",
+			config: Config{JWKKeyPath: jwkPath},
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				assert.Equal(t, "jwk-key", header.KeyID)
+				return jwkKey.Public()
+			},
+			expectX509CA: expectedChain,
+		},
+		{
+			name:   "encrypted JWK provisioner",
+			config: Config{JWKKeyPath: encryptedJWKPath, JWKPasswordPath: passwordPath},
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				assert.Equal(t, "jwk-key", header.KeyID)
+				return jwkKey.Public()
+			},
+			expectX509CA: expectedChain,
+		},
+		{
+			name:   "X5C provisioner",
+			config: Config{X5CCertPath: x5cCertPath, X5CKeyPath: x5cKeyPath},
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				roots := x509.NewCertPool()
+				roots.AddCert(x5cCA.X509Authorities()[0])
+				chains, err := header.Certificates(x509.VerifyOptions{
+					Roots:     roots,
+					KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+				})
+				require.NoError(t, err)
+				return chains[0][0].PublicKey
+			},
+			expectX509CA: expectedChain,
+		},
+		{
+			name:   "legacy sign response",
+			config: Config{JWKKeyPath: jwkPath},
+			signResponse: signResponse{
+				Crt: string(pemutil.EncodeCertificate(expectedChain[0])),
+				CA:  string(pemutil.EncodeCertificate(expectedChain[1])),
+			},
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				return jwkKey.Public()
+			},
+			expectX509CA: expectedChain,
+		},
+		{
+			name:         "sign fails",
+			config:       Config{JWKKeyPath: jwkPath},
+			signStatus:   http.StatusUnauthorized,
+			signResponse: errorResponse{Message: "the request is unauthorized"},
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				return jwkKey.Public()
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "upstreamauthority(step_ca): failed to sign CSR: unexpected status code 401: the request is unauthorized",
+		},
+		{
+			name:        "fetching roots fails",
+			config:      Config{JWKKeyPath: jwkPath},
+			rootsStatus: http.StatusInternalServerError,
+			verifyKey: func(t *testing.T, header jose.Header) crypto.PublicKey {
+				return jwkKey.Public()
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "upstreamauthority(step_ca): failed to fetch roots: unexpected status code 500",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/1.0/sign":
+					assert.Equal(t, http.MethodPost, r.Method)
+
+					var req signRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+					block, _ := pem.Decode([]byte(req.CSR))
+					if assert.NotNil(t, block) {
+						assert.Equal(t, csrDER, block.Bytes)
+					}
+					assert.Equal(t, "1h0m0s", req.NotAfter)
+
+					token, err := jwt.ParseSigned(req.OTT)
+					if assert.NoError(t, err) && assert.Len(t, token.Headers, 1) {
+						claims := new(tokenClaims)
+						assert.NoError(t, token.Claims(tt.verifyKey(t, token.Headers[0]), claims))
+						assert.Equal(t, "provisioner", claims.Issuer)
+						assert.Equal(t, "spiffe://example.org", claims.Subject)
+						assert.Equal(t, jwt.Audience{server.URL + "/1.0/sign"}, claims.Audience)
+						assert.Equal(t, []string{"spiffe://example.org"}, claims.SANs)
+						assert.NotEmpty(t, claims.ID)
+						assert.NoError(t, claims.ValidateWithLeeway(jwt.Expected{Time: clk.Now()}, 0))
+					}
+
+					status := http.StatusCreated
+					if tt.signStatus != 0 {
+						status = tt.signStatus
+					}
+					resp := tt.signResponse
+					if resp == nil {
+						resp = signResponse{
+							Crt: string(pemutil.EncodeCertificate(expectedChain[0])),
+							CA:  string(pemutil.EncodeCertificate(expectedChain[1])),
+							CertChain: []string{
+								string(pemutil.EncodeCertificate(expectedChain[0])),
+								string(pemutil.EncodeCertificate(expectedChain[1])),
+							},
+						}
+					}
+					writeResponse(w, status, resp)
+				case "/roots":
+					assert.Equal(t, http.MethodGet, r.Method)
+					if tt.rootsStatus != 0 {
+						w.WriteHeader(tt.rootsStatus)
+						return
+					}
+					writeResponse(w, http.StatusOK, rootsResponse{
+						Certificates: []string{string(pemutil.EncodeCertificate(rootCert))},
+					})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			rootCertPath := filepath.Join(dir, "root.pem")
+			require.NoError(t, os.WriteFile(rootCertPath, pemutil.EncodeCertificate(server.Certificate()), 0600))
+
+			config := tt.config
+			config.CAURL = server.URL
+			config.RootCertPath = rootCertPath
+			config.ProvisionerName = "provisioner"
+
+			p := New()
+			p.hooks.clock = clk
+
+			ua := new(upstreamauthority.V1)
+			plugintest.Load(t, builtin(p), ua,
+				plugintest.ConfigureJSON(config),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+			)
+
+			x509CA, x509Authorities, stream, err := ua.MintX509CA(context.Background(), csrDER, time.Hour)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+			require.Equal(t, tt.expectX509CA, x509CA)
+			require.Equal(t, []*x509.Certificate{rootCert}, x509Authorities)
+
+			// Plugin does not support streaming back changes so assert the
+			// stream returns EOF.
+			_, streamErr := stream.RecvUpstreamX509Authorities()
+			assert.True(t, errors.Is(streamErr, io.EOF))
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	dir := spiretest.TempDir(t)
+
+	rootCertPath := filepath.Join(dir, "root.pem")
+	require.NoError(t, os.WriteFile(rootCertPath, pemutil.EncodeCertificate(testca.New(t, trustDomain).X509Authorities()[0]), 0600))
+
+	jwk := jose.JSONWebKey{Key: testkey.NewEC256(t), KeyID: "jwk-key"}
+	jwkPath := writeJSON(t, dir, "jwk.json", jwk)
+	publicJWKPath := writeJSON(t, dir, "public-jwk.json", jwk.Public())
+	encryptedJWKPath, passwordPath := writeEncryptedJWK(t, dir, jwk, "password")
+	wrongPasswordPath := filepath.Join(dir, "wrong-password")
+	require.NoError(t, os.WriteFile(wrongPasswordPath, []byte("wrong"), 0600))
+
+	x5cCertPath, x5cKeyPath := writeX5C(t, dir, testca.New(t, trustDomain))
+
+	for _, tt := range []struct {
+		name       string
+		config     string
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "malformed configuration",
+			config:     "MALFORMED",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to decode configuration file: ",
+		},
+		{
+			name:       "missing CA URL",
+			config:     `root_cert_path = "` + rootCertPath + `" provisioner_name = "spire"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty ca_url property",
+		},
+		{
+			name:       "missing root cert path",
+			config:     `ca_url = "https://ca.example.org" provisioner_name = "spire"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty root_cert_path property",
+		},
+		{
+			name:       "missing provisioner name",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "configuration has empty provisioner_name property",
+		},
+		{
+			name:       "CA URL is not https",
+			config:     `ca_url = "http://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + jwkPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "ca_url must be an https URL",
+		},
+		{
+			name:       "root cert does not exist",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "/does/not/exist" provisioner_name = "spire" jwk_key_path = "` + jwkPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load root certificates: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:       "no provisioner credentials",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "one of jwk_key_path or x5c_cert_path is required",
+		},
+		{
+			name:       "both provisioner credentials",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + jwkPath + `" x5c_cert_path = "` + x5cCertPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "only one of jwk_key_path or x5c_cert_path can be configured",
+		},
+		{
+			name:       "public JWK",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + publicJWKPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load JWK provisioner key: key is not a private key",
+		},
+		{
+			name:       "wrong JWK password",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + encryptedJWKPath + `" jwk_password_path = "` + wrongPasswordPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load JWK provisioner key: unable to decrypt key: ",
+		},
+		{
+			name:       "missing X5C key",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" x5c_cert_path = "` + x5cCertPath + `"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "x5c_key_path is required when x5c_cert_path is configured",
+		},
+		{
+			name:       "X5C key does not exist",
+			config:     `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" x5c_cert_path = "` + x5cCertPath + `" x5c_key_path = "/does/not/exist"`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "unable to load X5C provisioner credentials: open /does/not/exist: no such file or directory",
+		},
+		{
+			name:   "JWK provisioner",
+			config: `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + jwkPath + `"`,
+		},
+		{
+			name:   "encrypted JWK provisioner",
+			config: `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" jwk_key_path = "` + encryptedJWKPath + `" jwk_password_path = "` + passwordPath + `"`,
+		},
+		{
+			name:   "X5C provisioner",
+			config: `ca_url = "https://ca.example.org" root_cert_path = "` + rootCertPath + `" provisioner_name = "spire" x5c_cert_path = "` + x5cCertPath + `" x5c_key_path = "` + x5cKeyPath + `"`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, BuiltIn(), nil,
+				plugintest.Configure(tt.config),
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: trustDomain,
+				}),
+				plugintest.CaptureConfigureError(&err),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeJSON(t *testing.T, dir, name string, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// writeEncryptedJWK encrypts the key the same way step-ca stores provisioner
+// keys and returns the paths to the encrypted key and the password.
+func writeEncryptedJWK(t *testing.T, dir string, jwk jose.JSONWebKey, password string) (string, string) {
+	data, err := json.Marshal(jwk)
+	require.NoError(t, err)
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{
+		Algorithm: jose.PBES2_HS256_A128KW,
+		Key:       []byte(password),
+	}, nil)
+	require.NoError(t, err)
+	jwe, err := encrypter.Encrypt(data)
+	require.NoError(t, err)
+	encrypted, err := jwe.CompactSerialize()
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(dir, "encrypted-jwk")
+	require.NoError(t, os.WriteFile(keyPath, []byte(encrypted), 0600))
+	passwordPath := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordPath, []byte(password+"\n"), 0600))
+	return keyPath, passwordPath
+}
+
+func writeX5C(t *testing.T, dir string, ca *testca.CA) (string, string) {
+	chain, key := ca.CreateX509Certificate()
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "x5c.pem")
+	require.NoError(t, os.WriteFile(certPath, pemutil.EncodeCertificates(chain), 0600))
+	keyPath := filepath.Join(dir, "x5c-key.pem")
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0600))
+	return certPath, keyPath
+}
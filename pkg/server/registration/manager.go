@@ -9,10 +9,12 @@ import (
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/lease"
 )
 
 const (
 	_pruningCandence = 5 * time.Minute
+	_pruningLease    = "registration_entry_pruning"
 )
 
 // ManagerConfig is the config for the registration manager
@@ -23,6 +25,10 @@ type ManagerConfig struct {
 	Metrics telemetry.Metrics
 
 	Clock clock.Clock
+
+	// Leases coordinates entry pruning with the other servers sharing the
+	// datastore. If nil, entries are pruned by every server.
+	Leases *lease.Coordinator
 }
 
 // Manager is the manager of registrations
@@ -68,6 +74,10 @@ func (m *Manager) pruneEvery(ctx context.Context) error {
 }
 
 func (m *Manager) prune(ctx context.Context) (err error) {
+	if !m.c.Leases.Acquire(ctx, _pruningLease, 2*_pruningCandence) {
+		return nil
+	}
+
 	counter := telemetry_server.StartRegistrationManagerPruneEntryCall(m.c.Metrics)
 	defer counter.Done(&err)
 
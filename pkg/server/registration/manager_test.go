@@ -8,6 +8,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/lease"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -126,6 +127,41 @@ func (s *ManagerSuite) TestPruning() {
 	s.Empty(listResp.Entries)
 }
 
+func (s *ManagerSuite) TestPruningRequiresLease() {
+	other := lease.New(lease.Config{DataStore: s.ds, Log: s.log, Clock: s.clock, HolderID: "other"})
+	s.Require().True(other.Acquire(context.Background(), _pruningLease, 2*_pruningCandence))
+
+	s.m = NewManager(ManagerConfig{
+		Clock:     s.clock,
+		DataStore: s.ds,
+		Log:       s.log,
+		Metrics:   s.metrics,
+		Leases:    lease.New(lease.Config{DataStore: s.ds, Log: s.log, Clock: s.clock, HolderID: "this"}),
+	})
+
+	entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:    "spiffe://test.test/testA",
+		SpiffeId:    "spiffe://test.test/testA/test1",
+		Selectors:   []*common.Selector{{Type: "type", Value: "value"}},
+		EntryExpiry: s.clock.Now().Unix(),
+	})
+	s.Require().NoError(err)
+
+	// the entry is not pruned while another server holds the lease
+	s.clock.Add(_pruningCandence)
+	s.NoError(s.m.prune(context.Background()))
+	listResp, err := s.ds.ListRegistrationEntries(context.Background(), &datastore.ListRegistrationEntriesRequest{})
+	s.NoError(err)
+	s.Equal([]*common.RegistrationEntry{entry}, listResp.Entries)
+
+	// the entry is pruned once the lease of the other server expires
+	s.clock.Add(_pruningCandence + time.Second)
+	s.NoError(s.m.prune(context.Background()))
+	listResp, err = s.ds.ListRegistrationEntries(context.Background(), &datastore.ListRegistrationEntriesRequest{})
+	s.NoError(err)
+	s.Empty(listResp.Entries)
+}
+
 func (s *ManagerSuite) setupAndRunManager() func() {
 	s.m = NewManager(ManagerConfig{
 		Clock:     s.clock,
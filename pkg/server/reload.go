@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/catalog"
+)
+
+// ReloadableConfig is the portion of the server configuration that is
+// re-read when the server is asked to reload its configuration.
+type ReloadableConfig struct {
+	// LogLevel is applied to the running server logger.
+	LogLevel logrus.Level
+
+	// PluginConfigs are the configurations for the server plugins.
+	PluginConfigs catalog.HCLPluginConfigMap
+
+	// SVIDDenylist replaces the patterns of the SVID denylist.
+	SVIDDenylist []string
+}
+
+// levelSetter is implemented by loggers that support changing their level
+// at runtime (e.g. *logrus.Logger).
+type levelSetter interface {
+	SetLevel(logrus.Level)
+}
+
+// pluginReconfigurer reconfigures the loaded plugins.
+type pluginReconfigurer interface {
+	Reconfigure(ctx context.Context, pluginConfig catalog.HCLPluginConfigMap) error
+}
+
+// reloadOnSignal returns a task that reloads the server configuration
+// each time a value is received on signalCh.
+func (s *Server) reloadOnSignal(signalCh <-chan os.Signal, plugins pluginReconfigurer) func(context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-signalCh:
+				rc, err := s.config.ReloadConfig()
+				if err != nil {
+					// Never fail; keep running with the current configuration
+					s.config.Log.WithError(err).Error("Failed to reload configuration; keeping current configuration")
+					continue
+				}
+				s.reload(ctx, rc, plugins)
+			}
+		}
+	}
+}
+
+// reload applies the given configuration to the running server. Plugins are
+// reconfigured in place, so signing is not interrupted. Plugins that fail to
+// reconfigure keep running with their current configuration.
+func (s *Server) reload(ctx context.Context, rc *ReloadableConfig, plugins pluginReconfigurer) {
+	log := s.config.Log.WithField(telemetry.SubsystemName, telemetry.Reloader)
+
+	if setter, ok := s.config.Log.(levelSetter); ok {
+		setter.SetLevel(rc.LogLevel)
+		log.WithField(telemetry.LogLevel, rc.LogLevel.String()).Info("Log level reloaded")
+	}
+
+	if err := s.denylist.SetPatterns(rc.SVIDDenylist); err != nil {
+		log.WithError(err).Error("Failed to reload SVID denylist")
+	} else {
+		log.WithField(telemetry.Count, len(rc.SVIDDenylist)).Info("SVID denylist reloaded")
+	}
+
+	if err := plugins.Reconfigure(ctx, rc.PluginConfigs); err != nil {
+		log.WithError(err).Error("Failed to reconfigure one or more plugins")
+	}
+
+	log.Info("Configuration reloaded")
+}
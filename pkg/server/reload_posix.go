@@ -0,0 +1,21 @@
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reloadConfigOnSignal returns a task that reloads the server configuration
+// when the server receives a SIGHUP.
+func (s *Server) reloadConfigOnSignal(plugins pluginReconfigurer) func(context.Context) error {
+	return func(ctx context.Context) error {
+		signalCh := make(chan os.Signal, 1)
+		signal.Notify(signalCh, syscall.SIGHUP)
+		defer signal.Stop(signalCh)
+		return s.reloadOnSignal(signalCh, plugins)(ctx)
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	log.SetLevel(logrus.InfoLevel)
+
+	s := New(Config{
+		Log: log,
+	})
+
+	t.Run("success", func(t *testing.T) {
+		hook.Reset()
+		plugins := &fakeReconfigurer{}
+		pluginConfigs := catalog.HCLPluginConfigMap{"KeyManager": {"memory": {}}}
+		s.reload(context.Background(), &ReloadableConfig{
+			LogLevel:      logrus.DebugLevel,
+			PluginConfigs: pluginConfigs,
+			SVIDDenylist:  []string{"spiffe://example.org/compromised/*"},
+		}, plugins)
+		require.Equal(t, logrus.DebugLevel, log.Level)
+		require.Equal(t, pluginConfigs, plugins.pluginConfig)
+		require.Equal(t, []string{"spiffe://example.org/compromised/*"}, s.denylist.Patterns())
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "debug",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "SVID denylist reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"count":          "1",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+
+	t.Run("plugins fail to reconfigure", func(t *testing.T) {
+		hook.Reset()
+		s.reload(context.Background(), &ReloadableConfig{
+			LogLevel: logrus.InfoLevel,
+		}, &fakeReconfigurer{err: errors.New("oh no")})
+		require.Empty(t, s.denylist.Patterns())
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "info",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "SVID denylist reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"count":          "0",
+				},
+			},
+			{
+				Level:   logrus.ErrorLevel,
+				Message: "Failed to reconfigure one or more plugins",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					logrus.ErrorKey:  "oh no",
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+
+	t.Run("denylist is invalid", func(t *testing.T) {
+		hook.Reset()
+		require.NoError(t, s.denylist.SetPatterns([]string{"spiffe://example.org/compromised/*"}))
+		s.reload(context.Background(), &ReloadableConfig{
+			LogLevel:     logrus.InfoLevel,
+			SVIDDenylist: []string{"["},
+		}, &fakeReconfigurer{})
+		require.Equal(t, []string{"spiffe://example.org/compromised/*"}, s.denylist.Patterns())
+		spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Log level reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					"log_level":      "info",
+				},
+			},
+			{
+				Level:   logrus.ErrorLevel,
+				Message: "Failed to reload SVID denylist",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+					logrus.ErrorKey:  `malformed pattern "[": syntax error in pattern`,
+				},
+			},
+			{
+				Level:   logrus.InfoLevel,
+				Message: "Configuration reloaded",
+				Data: logrus.Fields{
+					"subsystem_name": "reloader",
+				},
+			},
+		})
+	})
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	log, hook := test.NewNullLogger()
+
+	reloaded := make(chan struct{}, 1)
+	s := New(Config{
+		Log: log,
+		ReloadConfig: func() (*ReloadableConfig, error) {
+			reloaded <- struct{}{}
+			return nil, errors.New("oh no")
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalCh := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.reloadOnSignal(signalCh, &fakeReconfigurer{})(ctx)
+	}()
+
+	signalCh <- syscall.SIGHUP
+	select {
+	case <-reloaded:
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for reload")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	spiretest.AssertLogs(t, hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Failed to reload configuration; keeping current configuration",
+			Data: logrus.Fields{
+				logrus.ErrorKey: "oh no",
+			},
+		},
+	})
+}
+
+type fakeReconfigurer struct {
+	pluginConfig catalog.HCLPluginConfigMap
+	err          error
+}
+
+func (r *fakeReconfigurer) Reconfigure(ctx context.Context, pluginConfig catalog.HCLPluginConfigMap) error {
+	r.pluginConfig = pluginConfig
+	return r.err
+}
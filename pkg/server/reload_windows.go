@@ -0,0 +1,16 @@
+//go:build windows
+
+package server
+
+import (
+	"context"
+)
+
+// reloadConfigOnSignal returns a noop task since windows does not have
+// signals as on *nix.
+func (s *Server) reloadConfigOnSignal(plugins pluginReconfigurer) func(context.Context) error {
+	return func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+}
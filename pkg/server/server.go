@@ -12,22 +12,29 @@ import (
 	"sync"
 
 	"github.com/andres-erbsen/clock"
+	"github.com/gofrs/uuid"
 	"github.com/sirupsen/logrus"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	server_util "github.com/spiffe/spire/cmd/spire-server/util"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/log"
 	"github.com/spiffe/spire/pkg/common/profiling"
+	"github.com/spiffe/spire/pkg/common/systemd"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/uptime"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api/audit"
+	"github.com/spiffe/spire/pkg/server/attestationwebhook"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/downstreamwebhook"
 	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/identityprovider"
+	"github.com/spiffe/spire/pkg/server/lease"
 	"github.com/spiffe/spire/pkg/server/registration"
 	"github.com/spiffe/spire/pkg/server/svid"
 	"google.golang.org/grpc"
@@ -47,6 +54,10 @@ const (
 
 type Server struct {
 	config Config
+
+	// denylist blocks the issuance of SVIDs. Its patterns are replaced when
+	// the configuration is reloaded.
+	denylist *ca.Denylist
 }
 
 // Run the server
@@ -115,11 +126,16 @@ func (s *Server) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	serverCA := s.newCA(metrics, healthChecker)
+	serverCA := s.newCA(cat, metrics, healthChecker)
+
+	leases, err := s.newLeaseCoordinator(cat)
+	if err != nil {
+		return err
+	}
 
 	// CA manager needs to be initialized before the rotator, otherwise the
 	// server CA plugin won't be able to sign CSRs
-	caManager, err := s.newCAManager(ctx, cat, metrics, serverCA, healthChecker)
+	caManager, err := s.newCAManager(ctx, cat, metrics, serverCA, healthChecker, leases)
 	if err != nil {
 		return err
 	}
@@ -136,7 +152,28 @@ func (s *Server) run(ctx context.Context) (err error) {
 
 	bundleManager := s.newBundleManager(cat, metrics)
 
-	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager)
+	if err := s.denylist.SetPatterns(s.config.SVIDDenylist); err != nil {
+		return fmt.Errorf("invalid SVID denylist: %w", err)
+	}
+
+	var attestationWebhooks *attestationwebhook.Webhooks
+	if len(s.config.AttestationWebhooks) > 0 {
+		attestationWebhooks = attestationwebhook.New(s.config.Log.WithField(telemetry.SubsystemName, "attestation_webhook"), s.config.AttestationWebhooks)
+	}
+
+	auditLogSink, err := s.openAuditLogSinks()
+	if err != nil {
+		return err
+	}
+	if auditLogSink != nil {
+		defer func() {
+			if err := auditLogSink.Close(); err != nil {
+				s.config.Log.WithError(err).Error("Failed to close audit log sinks")
+			}
+		}()
+	}
+
+	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager, attestationWebhooks, auditLogSink)
 	if err != nil {
 		return err
 	}
@@ -163,7 +200,7 @@ func (s *Server) run(ctx context.Context) (err error) {
 		return fmt.Errorf("failed setting AgentStore deps: %w", err)
 	}
 
-	registrationManager := s.newRegistrationManager(cat, metrics)
+	registrationManager := s.newRegistrationManager(cat, metrics, leases)
 
 	if err := healthChecker.AddCheck("server", s); err != nil {
 		return fmt.Errorf("failed adding healthcheck: %w", err)
@@ -176,14 +213,26 @@ func (s *Server) run(ctx context.Context) (err error) {
 		metrics.ListenAndServe,
 		bundleManager.Run,
 		registrationManager.Run,
-		util.SerialRun(s.waitForTestDial, healthChecker.ListenAndServe),
+		util.SerialRun(s.waitForTestDial, s.notifyReady, healthChecker.ListenAndServe),
 		scanForBadEntries(s.config.Log, metrics, cat.GetDataStore()),
 	}
 
+	if attestationWebhooks != nil {
+		tasks = append(tasks, attestationWebhooks.Run)
+	}
+
 	if s.config.LogReopener != nil {
 		tasks = append(tasks, s.config.LogReopener)
 	}
 
+	if auditLogSink != nil {
+		tasks = append(tasks, log.ReopenOnSignal(s.config.Log, auditLogSink))
+	}
+
+	if s.config.ReloadConfig != nil {
+		tasks = append(tasks, s.reloadConfigOnSignal(cat))
+	}
+
 	err = util.RunTasks(ctx, tasks...)
 	if errors.Is(err, context.Canceled) {
 		err = nil
@@ -250,40 +299,80 @@ func (s *Server) setupProfiling(ctx context.Context) (stop func()) {
 func (s *Server) loadCatalog(ctx context.Context, metrics telemetry.Metrics, identityProvider *identityprovider.IdentityProvider, agentStore *agentstore.AgentStore,
 	healthChecker health.Checker) (*catalog.Repository, error) {
 	return catalog.Load(ctx, catalog.Config{
-		Log:              s.config.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
-		Metrics:          metrics,
-		TrustDomain:      s.config.TrustDomain,
-		PluginConfig:     s.config.PluginConfigs,
-		IdentityProvider: identityProvider,
-		AgentStore:       agentStore,
-		HealthChecker:    healthChecker,
+		Log:                    s.config.Log.WithField(telemetry.SubsystemName, telemetry.Catalog),
+		Metrics:                metrics,
+		TrustDomain:            s.config.TrustDomain,
+		PluginConfig:           s.config.PluginConfigs,
+		RequirePluginChecksums: s.config.RequirePluginChecksums,
+		IdentityProvider:       identityProvider,
+		AgentStore:             agentStore,
+		HealthChecker:          healthChecker,
+		BundleCacheExpiry:      s.config.BundleCacheExpiry,
+
+		DataStoreSlowOperationThreshold: s.config.DataStoreSlowOperationThreshold,
 	})
 }
 
-func (s *Server) newCA(metrics telemetry.Metrics, healthChecker health.Checker) *ca.CA {
+func (s *Server) newCA(cat catalog.Catalog, metrics telemetry.Metrics, healthChecker health.Checker) *ca.CA {
 	return ca.NewCA(ca.Config{
-		Metrics:       metrics,
-		X509SVIDTTL:   s.config.SVIDTTL,
-		JWTIssuer:     s.config.JWTIssuer,
-		TrustDomain:   s.config.TrustDomain,
-		CASubject:     s.config.CASubject,
-		HealthChecker: healthChecker,
+		Metrics:        metrics,
+		X509SVIDTTL:    s.config.SVIDTTL,
+		MaxSVIDTTL:     s.config.MaxSVIDTTL,
+		JWTIssuer:      s.config.JWTIssuer,
+		TrustDomain:    s.config.TrustDomain,
+		CASubject:      s.config.CASubject,
+		HealthChecker:  healthChecker,
+		KeyManagerName: cat.GetKeyManager().Name(),
+
+		CAPathLen:           s.config.CAPathLen,
+		DownstreamCAPathLen: s.config.DownstreamCAPathLen,
+
+		CSRExtensionAllowlist: s.config.CSRExtensionAllowlist,
+		Denylist:              s.denylist,
 	})
 }
 
-func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, serverCA *ca.CA, healthChecker health.Checker) (*ca.Manager, error) {
+// newLeaseCoordinator returns the coordinator used to elect the server that
+// performs the duties shared by the servers of the trust domain, or nil if
+// leader election is disabled.
+func (s *Server) newLeaseCoordinator(cat catalog.Catalog) (*lease.Coordinator, error) {
+	if !s.config.LeaderElection {
+		return nil, nil
+	}
+
+	holderID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate lease holder ID: %w", err)
+	}
+
+	log := s.config.Log.WithField(telemetry.SubsystemName, telemetry.LeaseCoordinator)
+	log.WithField(telemetry.LeaseHolder, holderID.String()).Info("Leader election enabled")
+	return lease.New(lease.Config{
+		DataStore: cat.GetDataStore(),
+		Log:       log,
+		HolderID:  holderID.String(),
+	}), nil
+}
+
+func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, serverCA *ca.CA, healthChecker health.Checker, leases *lease.Coordinator) (*ca.Manager, error) {
 	caManager := ca.NewManager(ca.ManagerConfig{
-		CA:            serverCA,
-		Catalog:       cat,
-		TrustDomain:   s.config.TrustDomain,
-		Log:           s.config.Log.WithField(telemetry.SubsystemName, telemetry.CAManager),
-		Metrics:       metrics,
-		CATTL:         s.config.CATTL,
-		CASubject:     s.config.CASubject,
-		Dir:           s.config.DataDir,
-		X509CAKeyType: s.config.CAKeyType,
-		JWTKeyType:    s.config.JWTKeyType,
-		HealthChecker: healthChecker,
+		CA:                  serverCA,
+		Catalog:             cat,
+		TrustDomain:         s.config.TrustDomain,
+		Log:                 s.config.Log.WithField(telemetry.SubsystemName, telemetry.CAManager),
+		Metrics:             metrics,
+		CATTL:               s.config.CATTL,
+		CASubject:           s.config.CASubject,
+		CAPathLen:           s.config.CAPathLen,
+		PreparationLeadTime: s.config.CAPreparationLeadTime,
+		ActivationOverlap:   s.config.CAActivationOverlap,
+		UpstreamFallback:    s.config.CAUpstreamFallback,
+		BundleRefreshHint:   s.config.BundleRefreshHint,
+		Dir:                 s.config.DataDir,
+		X509CAKeyType:       s.config.CAKeyType,
+		JWTKeyType:          s.config.JWTKeyType,
+		HealthChecker:       healthChecker,
+		Leases:              leases,
 	})
 	if err := caManager.Initialize(ctx); err != nil {
 		return nil, err
@@ -291,11 +380,12 @@ func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics
 	return caManager, nil
 }
 
-func (s *Server) newRegistrationManager(cat catalog.Catalog, metrics telemetry.Metrics) *registration.Manager {
+func (s *Server) newRegistrationManager(cat catalog.Catalog, metrics telemetry.Metrics, leases *lease.Coordinator) *registration.Manager {
 	registrationManager := registration.NewManager(registration.ManagerConfig{
 		DataStore: cat.GetDataStore(),
 		Log:       s.config.Log.WithField(telemetry.SubsystemName, telemetry.RegistrationManager),
 		Metrics:   metrics,
+		Leases:    leases,
 	})
 	return registrationManager
 }
@@ -314,30 +404,70 @@ func (s *Server) newSVIDRotator(ctx context.Context, serverCA ca.ServerCA, metri
 	return svidRotator, nil
 }
 
-func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, caManager *ca.Manager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager) (endpoints.Server, error) {
+// openAuditLogSinks opens the configured audit log sinks. It returns nil if
+// no sink is configured.
+func (s *Server) openAuditLogSinks() (audit.Sink, error) {
+	config := s.config.AuditLogSinks
+	if config.Timestamping != nil {
+		timestamping := *config.Timestamping
+		timestamping.Log = s.config.Log.WithField(telemetry.SubsystemName, "audit_log")
+		config.Timestamping = &timestamping
+	}
+
+	sink, err := audit.OpenSinks(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log sinks: %w", err)
+	}
+	return sink, nil
+}
+
+func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, caManager *ca.Manager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager, attestationWebhooks *attestationwebhook.Webhooks, auditLogSink audit.Sink) (endpoints.Server, error) {
 	config := endpoints.Config{
-		TCPAddr:             s.config.BindAddress,
-		LocalAddr:           s.config.BindLocalAddress,
-		SVIDObserver:        svidObserver,
-		TrustDomain:         s.config.TrustDomain,
-		Catalog:             catalog,
-		ServerCA:            serverCA,
-		AgentTTL:            s.config.AgentTTL,
-		Log:                 s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
-		Metrics:             metrics,
-		Manager:             caManager,
-		RateLimit:           s.config.RateLimit,
-		Uptime:              uptime.Uptime,
-		Clock:               clock.New(),
-		CacheReloadInterval: s.config.CacheReloadInterval,
-		AuditLogEnabled:     s.config.AuditLogEnabled,
-		AuthPolicyEngine:    authPolicyEngine,
-		BundleManager:       bundleManager,
-		AdminIDs:            s.config.AdminIDs,
+		TCPAddr:                 s.config.BindAddress,
+		TCPTLSPolicy:            s.config.BindAddressTLSPolicy,
+		AdditionalTCPListeners:  s.config.AdditionalListeners,
+		GRPC:                    s.config.GRPC,
+		LocalAddr:               s.config.BindLocalAddress,
+		LocalAddrMode:           s.config.BindLocalAddressMode,
+		LocalAddrGroup:          s.config.BindLocalAddressGroup,
+		SVIDObserver:            svidObserver,
+		TrustDomain:             s.config.TrustDomain,
+		Catalog:                 catalog,
+		ServerCA:                serverCA,
+		AgentTTL:                s.config.AgentTTL,
+		AgentMaxRenewalAge:      s.config.AgentMaxRenewalAge,
+		Log:                     s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
+		Metrics:                 metrics,
+		Manager:                 caManager,
+		RateLimit:               s.config.RateLimit,
+		Uptime:                  uptime.Uptime,
+		Clock:                   clock.New(),
+		CacheReloadInterval:     s.config.CacheReloadInterval,
+		AdminReadAfterWrite:     s.config.AdminReadAfterWrite,
+		AuditLogEnabled:         s.config.AuditLogEnabled,
+		AuditLogSink:            auditLogSink,
+		AuthPolicyEngine:        authPolicyEngine,
+		BundleManager:           bundleManager,
+		AdminIDs:                s.config.AdminIDs,
+		EntryNamespaces:         s.config.EntryNamespaces,
+		ShutdownDrainTimeout:    s.config.ShutdownDrainTimeout,
+		IDPathPolicy:            s.config.IDPathPolicy,
+		IssuanceQuotas:          s.config.IssuanceQuotas,
+		JWTAudienceRestrictions: s.config.JWTAudienceRestrictions,
+		CSRExtensionAllowlist:   s.config.CSRExtensionAllowlist,
+		SVIDDenylist:            s.denylist,
+	}
+	if attestationWebhooks != nil {
+		config.AttestationNotifier = attestationWebhooks
+	}
+	if s.config.DownstreamAuthorizationWebhook != nil {
+		config.DownstreamAuthorizer = downstreamwebhook.New(*s.config.DownstreamAuthorizationWebhook)
 	}
 	if s.config.Federation.BundleEndpoint != nil {
 		config.BundleEndpoint.Address = s.config.Federation.BundleEndpoint.Address
 		config.BundleEndpoint.ACME = s.config.Federation.BundleEndpoint.ACME
+		config.BundleEndpoint.RefreshHint = s.config.Federation.BundleEndpoint.RefreshHint
+		config.BundleEndpoint.TLSPolicy = s.config.Federation.BundleEndpoint.TLSPolicy
 	}
 	return endpoints.New(ctx, config)
 }
@@ -405,6 +535,16 @@ func (s *Server) validateTrustDomain(ctx context.Context, ds datastore.DataStore
 	return nil
 }
 
+// notifyReady notifies systemd (when started with Type=notify) that the
+// server is ready. It is run once the CA is loaded and the SPIRE Server API
+// is being served. This function always returns nil.
+func (s *Server) notifyReady(context.Context) error {
+	if err := systemd.Notify(systemd.Ready); err != nil {
+		s.config.Log.WithError(err).Warn("Failed to notify systemd of readiness")
+	}
+	return nil
+}
+
 // waitForTestDial calls health.WaitForTestDial to wait for a connection to the
 // SPIRE Server API socket. This function always returns nil, even if
 // health.WaitForTestDial exited due to a timeout.
@@ -37,6 +37,7 @@ type CRDMode struct {
 	PodController              bool              `hcl:"pod_controller"`
 	WebhookCertDir             string            `hcl:"webhook_cert_dir"`
 	WebhookEnabled             bool              `hcl:"webhook_enabled"`
+	WebhookNamespacedIDs       bool              `hcl:"webhook_namespaced_ids"`
 	WebhookPort                int               `hcl:"webhook_port"`
 	WebhookServiceName         string            `hcl:"webhook_service_name"`
 	IdentityTemplate           string            `hcl:"identity_template"`
@@ -168,11 +169,12 @@ func (c *CRDMode) Run(ctx context.Context) error {
 			}()
 		}
 		err = spiffeidv1beta1.AddSpiffeIDWebhook(spiffeidv1beta1.SpiffeIDWebhook{
-			E:           entryClient,
-			Log:         log,
-			Mgr:         mgr,
-			Namespace:   myPodNamespace,
-			TrustDomain: c.TrustDomain,
+			E:             entryClient,
+			Log:           log,
+			Mgr:           mgr,
+			Namespace:     myPodNamespace,
+			NamespacedIDs: c.WebhookNamespacedIDs,
+			TrustDomain:   c.TrustDomain,
 		})
 		if err != nil {
 			return err
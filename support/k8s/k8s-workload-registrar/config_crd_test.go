@@ -115,6 +115,7 @@ func TestLoadModeCRD(t *testing.T) {
 				metrics_bind_addr = "addr"
 				pod_controller = true
 				webhook_enabled = false
+				webhook_namespaced_ids = true
 				mode = "crd"
 				identity_template = "IDENTITYTEMPLATE"
 				dns_name_templates = ["DNSNAMETEMPLATE"]
@@ -137,6 +138,7 @@ func TestLoadModeCRD(t *testing.T) {
 				MetricsBindAddr:            "addr",
 				PodController:              true,
 				WebhookEnabled:             false,
+				WebhookNamespacedIDs:       true,
 				WebhookCertDir:             defaultWebhookCertDir,
 				WebhookPort:                defaultWebhookPort,
 				WebhookServiceName:         defaultWebhookServiceName,
@@ -31,11 +31,14 @@ import (
 )
 
 type SpiffeIDWebhook struct {
-	E           entryv1.EntryClient
-	Log         logrus.FieldLogger
-	Mgr         ctrl.Manager
-	Namespace   string
-	TrustDomain string
+	E         entryv1.EntryClient
+	Log       logrus.FieldLogger
+	Mgr       ctrl.Manager
+	Namespace string
+	// NamespacedIDs restricts SpiffeID resources outside of the registrar
+	// namespace to SPIFFE IDs under spiffe://<trust domain>/ns/<namespace>/
+	NamespacedIDs bool
+	TrustDomain   string
 }
 
 func AddSpiffeIDWebhook(w SpiffeIDWebhook) error {
@@ -130,6 +133,14 @@ func (w SpiffeIDWebhook) validateSpiffeID(s *SpiffeID) error {
 		if s.ObjectMeta.Namespace != s.Spec.Selector.Namespace {
 			return errors.New("spec.Selector.Namespace must match namespace of resource")
 		}
+
+		// Ensure the SPIFFE ID is scoped to the namespace of the resource
+		if w.NamespacedIDs {
+			namespacePrefix := spiffeIDPrefix + "/ns/" + s.ObjectMeta.Namespace + "/"
+			if !strings.HasPrefix(s.Spec.SpiffeId, namespacePrefix) {
+				return errors.New("spec.spiffeId must begin with " + namespacePrefix)
+			}
+		}
 	}
 
 	for _, dnsName := range s.Spec.DnsNames {
@@ -0,0 +1,111 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateUpdate(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		namespacedIDs bool
+		namespace     string
+		spec          SpiffeIDSpec
+		expectErr     string
+	}{
+		{
+			name:      "valid",
+			namespace: "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/workload",
+				Selector: Selector{Namespace: "foo"},
+			},
+		},
+		{
+			name:      "parent ID outside of trust domain",
+			namespace: "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://other.org/parent",
+				SpiffeId: "spiffe://example.org/workload",
+				Selector: Selector{Namespace: "foo"},
+			},
+			expectErr: "spec.parentId must begin with spiffe://example.org",
+		},
+		{
+			name:      "namespace selector does not match resource namespace",
+			namespace: "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/workload",
+				Selector: Selector{Namespace: "bar"},
+			},
+			expectErr: "spec.Selector.Namespace must match namespace of resource",
+		},
+		{
+			name:          "namespaced ID",
+			namespacedIDs: true,
+			namespace:     "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/ns/foo/sa/default",
+				Selector: Selector{Namespace: "foo"},
+			},
+		},
+		{
+			name:          "ID outside of namespace",
+			namespacedIDs: true,
+			namespace:     "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/ns/bar/sa/default",
+				Selector: Selector{Namespace: "foo"},
+			},
+			expectErr: "spec.spiffeId must begin with spiffe://example.org/ns/foo/",
+		},
+		{
+			name:          "ID sharing a prefix with the namespace",
+			namespacedIDs: true,
+			namespace:     "foo",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/ns/foobar/sa/default",
+				Selector: Selector{Namespace: "foo"},
+			},
+			expectErr: "spec.spiffeId must begin with spiffe://example.org/ns/foo/",
+		},
+		{
+			name:          "registrar namespace is not restricted",
+			namespacedIDs: true,
+			namespace:     "spire",
+			spec: SpiffeIDSpec{
+				ParentId: "spiffe://example.org/parent",
+				SpiffeId: "spiffe://example.org/k8s-workload-registrar/cluster/node",
+				Selector: Selector{Cluster: "cluster", AgentNodeUid: "uid"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := SpiffeIDWebhook{
+				Namespace:     "spire",
+				NamespacedIDs: tt.namespacedIDs,
+				TrustDomain:   "example.org",
+			}
+			s := &SpiffeID{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace},
+				Spec:       tt.spec,
+			}
+
+			err := w.ValidateUpdate(context.Background(), nil, s)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/spiffe/spire/pkg/common/telemetry"
-	"github.com/spiffe/spire/pkg/common/util"
+	common_workloadapi "github.com/spiffe/spire/pkg/common/workloadapi"
 	"github.com/zeebo/errs"
 	"gopkg.in/square/go-jose.v2"
 )
@@ -51,21 +51,14 @@ func NewWorkloadAPISource(config WorkloadAPISourceConfig) (*WorkloadAPISource, e
 	if config.Clock == nil {
 		config.Clock = clock.New()
 	}
-	var opts []workloadapi.ClientOption
-	if config.Addr != nil {
-		o, err := util.GetWorkloadAPIClientOption(config.Addr)
-		if err != nil {
-			return nil, errs.Wrap(err)
-		}
-		opts = append(opts, o)
-	}
-
 	trustDomain, err := spiffeid.TrustDomainFromString(config.TrustDomain)
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
 
-	client, err := workloadapi.New(context.Background(), opts...)
+	client, err := common_workloadapi.NewClient(context.Background(), common_workloadapi.Config{
+		Addr: config.Addr,
+	})
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
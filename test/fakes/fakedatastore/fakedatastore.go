@@ -40,6 +40,7 @@ func New(tb testing.TB) *DataStore {
 	err := ds.Configure(ctx, fmt.Sprintf(`
 		database_type = "sqlite3"
 		connection_string = "file:memdb%d?mode=memory&cache=shared"
+		deleted_entry_retention = "1h"
 	`, atomic.AddUint32(&nextID, 1)))
 	require.NoError(tb, err)
 
@@ -187,11 +188,11 @@ func (s *DataStore) GetNodeSelectors(ctx context.Context, spiffeID string, dataC
 	return selectors, err
 }
 
-func (s *DataStore) CountRegistrationEntries(ctx context.Context) (int32, error) {
+func (s *DataStore) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (int32, error) {
 	if err := s.getNextError(); err != nil {
 		return 0, err
 	}
-	return s.ds.CountRegistrationEntries(ctx)
+	return s.ds.CountRegistrationEntries(ctx, req)
 }
 
 func (s *DataStore) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
@@ -262,6 +263,20 @@ func (s *DataStore) FetchJoinToken(ctx context.Context, token string) (*datastor
 	return s.ds.FetchJoinToken(ctx, token)
 }
 
+func (s *DataStore) ListJoinTokens(ctx context.Context) ([]*datastore.JoinToken, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListJoinTokens(ctx)
+}
+
+func (s *DataStore) ConsumeJoinToken(ctx context.Context, token string, now time.Time) (*datastore.JoinToken, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ConsumeJoinToken(ctx, token, now)
+}
+
 func (s *DataStore) DeleteJoinToken(ctx context.Context, token string) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -311,6 +326,76 @@ func (s *DataStore) UpdateFederationRelationship(ctx context.Context, fr *datast
 	return s.ds.UpdateFederationRelationship(ctx, fr, mask)
 }
 
+func (s *DataStore) AcquireLease(ctx context.Context, lease *datastore.Lease, now time.Time) (*datastore.Lease, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.AcquireLease(ctx, lease, now)
+}
+
+func (s *DataStore) FetchAgentRenewal(ctx context.Context, spiffeID string) (*datastore.AgentRenewal, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FetchAgentRenewal(ctx, spiffeID)
+}
+
+func (s *DataStore) SetAgentRenewal(ctx context.Context, renewal *datastore.AgentRenewal) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetAgentRenewal(ctx, renewal)
+}
+
+func (s *DataStore) FetchEntryDNSNamePatterns(ctx context.Context, entryID string) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FetchEntryDNSNamePatterns(ctx, entryID)
+}
+
+func (s *DataStore) SetEntryDNSNamePatterns(ctx context.Context, entryID string, patterns []string) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetEntryDNSNamePatterns(ctx, entryID, patterns)
+}
+
+func (s *DataStore) ListDeletedRegistrationEntries(ctx context.Context) ([]*datastore.DeletedRegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListDeletedRegistrationEntries(ctx)
+}
+
+func (s *DataStore) RestoreRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.RestoreRegistrationEntry(ctx, entryID)
+}
+
+func (s *DataStore) CreateEntryTemplate(ctx context.Context, template *datastore.EntryTemplate) (*datastore.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.CreateEntryTemplate(ctx, template)
+}
+
+func (s *DataStore) DeleteEntryTemplate(ctx context.Context, templateID string) (*datastore.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.DeleteEntryTemplate(ctx, templateID)
+}
+
+func (s *DataStore) ListEntryTemplates(ctx context.Context) ([]*datastore.EntryTemplate, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListEntryTemplates(ctx)
+}
+
 func (s *DataStore) SetNextError(err error) {
 	s.errs = []error{err}
 }
@@ -32,6 +32,7 @@ type Options struct {
 	Clock       clock.Clock
 	X509SVIDTTL time.Duration
 	JWTSVIDTTL  time.Duration
+	Denylist    *ca.Denylist
 }
 
 type CA struct {
@@ -62,7 +63,7 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 	var x509CA *ca.X509CA
 	var bundle []*x509.Certificate
 	var err error
-	x509CA, bundle, err = ca.SelfSignX509CA(context.Background(), signer, trustDomain, subject, notBefore, notAfter)
+	x509CA, bundle, err = ca.SelfSignX509CA(context.Background(), signer, trustDomain, subject, notBefore, notAfter, nil)
 	require.NoError(t, err)
 
 	healthChecker := fakehealthchecker.New()
@@ -75,6 +76,7 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 		JWTSVIDTTL:    options.JWTSVIDTTL,
 		Clock:         options.Clock,
 		HealthChecker: healthChecker,
+		Denylist:      options.Denylist,
 	})
 	serverCA.SetX509CA(x509CA)
 	serverCA.SetJWTKey(&ca.JWTKey{